@@ -0,0 +1,120 @@
+// Package allinone runs the control plane as one process (cmd/animus) for dev
+// machines and small on-prem installs: the gateway serves the public API and
+// reaches dataset-registry, experiments, lineage and audit in-process, all on
+// one database pool and one object store client.
+package allinone
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/audit"
+	datasetregistry "github.com/animus-labs/animus-go/closed/dataset-registry"
+	"github.com/animus-labs/animus-go/closed/experiments"
+	"github.com/animus-labs/animus-go/closed/gateway"
+	"github.com/animus-labs/animus-go/closed/internal/platform/env"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpserver"
+	"github.com/animus-labs/animus-go/closed/internal/platform/inproc"
+	"github.com/animus-labs/animus-go/closed/internal/platform/migrate"
+	"github.com/animus-labs/animus-go/closed/internal/platform/postgres"
+	"github.com/animus-labs/animus-go/closed/lineage"
+	"github.com/animus-labs/animus-go/closed/migrations"
+)
+
+func Main() {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	ctx := context.Background()
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	addr := env.String("ANIMUS_HTTP_ADDR", ":8080")
+	shutdownTimeout, err := env.Duration("ANIMUS_SHUTDOWN_TIMEOUT", 10*time.Second)
+	if err != nil {
+		logger.Error("invalid env", "error", err)
+		os.Exit(2)
+	}
+
+	// The gateway signs identity headers for the services it calls. When they
+	// all live here nobody else needs the secret, so a per-process one will do.
+	if strings.TrimSpace(os.Getenv("ANIMUS_INTERNAL_AUTH_SECRET")) == "" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			logger.Error("internal auth secret generation failed", "error", err)
+			os.Exit(1)
+		}
+		_ = os.Setenv("ANIMUS_INTERNAL_AUTH_SECRET", hex.EncodeToString(secret))
+	}
+
+	dbCfg, err := postgres.ConfigFromEnv("")
+	if err != nil {
+		logger.Error("invalid database config", "error", err)
+		os.Exit(2)
+	}
+	db, err := postgres.Open(ctx, dbCfg)
+	if err != nil {
+		logger.Error("database unavailable", "error", err)
+		os.Exit(1)
+	}
+	defer func() { _ = db.Close() }()
+
+	migrator, err := migrate.New(db, migrations.Files, logger)
+	if err != nil {
+		logger.Error("invalid migrations", "error", err)
+		os.Exit(2)
+	}
+	if done, err := migrate.Startup(ctx, migrator, os.Args[1:], os.Stdout); err != nil {
+		logger.Error("migrations failed", "error", err)
+		os.Exit(1)
+	} else if done {
+		return
+	}
+
+	replicaCfg, err := postgres.ReplicaConfigFromEnv("")
+	if err != nil {
+		logger.Error("invalid read replica config", "error", err)
+		os.Exit(2)
+	}
+	reads, err := postgres.OpenReplica(db, dbCfg, replicaCfg, logger)
+	if err != nil {
+		logger.Error("read replica init failed", "error", err)
+		os.Exit(2)
+	}
+	defer func() { _ = reads.Close() }()
+	httpserver.RegisterMetricsProvider(postgres.PrometheusMetrics("animus", reads.Pools()))
+
+	services := inproc.NewTransport(nil)
+	res := &inproc.Resources{DB: db, Reads: reads, Migrator: migrator, Services: services}
+	for _, service := range []struct {
+		name string
+		new  func(context.Context, *slog.Logger, *inproc.Resources) http.Handler
+	}{
+		{"dataset-registry", datasetregistry.NewHandler},
+		{"experiments", experiments.NewHandler},
+		{"lineage", lineage.NewHandler},
+		{"audit", audit.NewHandler},
+	} {
+		services.Register(service.name, httpserver.Wrap(logger, service.name, service.new(ctx, logger, res)))
+	}
+	handler := gateway.NewHandler(ctx, logger, res)
+
+	cfg := httpserver.Config{
+		Service:         "animus",
+		Addr:            addr,
+		ShutdownTimeout: shutdownTimeout,
+	}
+
+	if err := httpserver.Run(ctx, logger, cfg, httpserver.Wrap(logger, "gateway", handler)); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Error("server failed", "error", err)
+		os.Exit(1)
+	}
+}
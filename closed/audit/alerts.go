@@ -0,0 +1,577 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/integrations/digests"
+	"github.com/animus-labs/animus-go/closed/internal/integrations/notify"
+	"github.com/animus-labs/animus-go/closed/internal/integrations/webhooks"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
+	"github.com/animus-labs/animus-go/closed/internal/platform/env"
+	repopg "github.com/animus-labs/animus-go/closed/internal/repo/postgres"
+	"github.com/google/uuid"
+)
+
+const (
+	alertEngineActor        = "system:audit-alerts"
+	alertStatusOpen         = "open"
+	alertStatusAcknowledged = "acknowledged"
+	alertBatchSize          = 500
+)
+
+// authDenyActions are the denials written by auth.Middleware (auth.<reason>),
+// the RBAC authorizer (access.denied) and the password login.
+var authDenyActions = []string{
+	"access.denied",
+	"auth.forbidden",
+	"auth.invalid_token",
+	"auth.login_failed",
+	"auth.unauthenticated",
+}
+
+// alertRule raises an alert once Threshold events with one of Actions share
+// a GroupBy key (ip, actor, project_id) within Window. Later matches less
+// than Window after the alert's last event extend it instead of raising a
+// new one.
+type alertRule struct {
+	Name      string
+	Severity  string
+	Actions   []string
+	GroupBy   []string
+	Threshold int
+	Window    time.Duration
+}
+
+type alertsConfig struct {
+	Enabled         bool
+	PollInterval    time.Duration
+	Rules           []alertRule
+	SlackWebhookURL string
+	EmailTo         []string
+}
+
+func alertsConfigFromEnv() (alertsConfig, error) {
+	enabled, err := env.Bool("AUDIT_ALERTS_ENABLED", true)
+	if err != nil {
+		return alertsConfig{}, err
+	}
+	pollInterval, err := env.Duration("AUDIT_ALERT_POLL_INTERVAL", 15*time.Second)
+	if err != nil {
+		return alertsConfig{}, err
+	}
+	denyThreshold, err := env.Int("AUDIT_ALERT_AUTH_DENY_THRESHOLD", 20)
+	if err != nil {
+		return alertsConfig{}, err
+	}
+	denyWindow, err := env.Duration("AUDIT_ALERT_AUTH_DENY_WINDOW", 5*time.Minute)
+	if err != nil {
+		return alertsConfig{}, err
+	}
+	downloadThreshold, err := env.Int("AUDIT_ALERT_DOWNLOAD_THRESHOLD", 100)
+	if err != nil {
+		return alertsConfig{}, err
+	}
+	downloadWindow, err := env.Duration("AUDIT_ALERT_DOWNLOAD_WINDOW", 10*time.Minute)
+	if err != nil {
+		return alertsConfig{}, err
+	}
+	selfApproval, err := env.Bool("AUDIT_ALERT_SELF_APPROVAL", true)
+	if err != nil {
+		return alertsConfig{}, err
+	}
+	var emailTo []string
+	for _, part := range strings.Split(env.String("AUDIT_ALERT_NOTIFY_EMAIL_TO", ""), ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			emailTo = append(emailTo, part)
+		}
+	}
+	if len(emailTo) > 0 {
+		emailTo, err = digests.NormalizeRecipients(emailTo)
+		if err != nil {
+			return alertsConfig{}, errors.New("AUDIT_ALERT_NOTIFY_EMAIL_TO: " + err.Error())
+		}
+	}
+
+	var rules []alertRule
+	if denyThreshold > 0 {
+		rules = append(rules, alertRule{
+			Name:      "auth_deny_burst",
+			Severity:  "high",
+			Actions:   authDenyActions,
+			GroupBy:   []string{"ip"},
+			Threshold: denyThreshold,
+			Window:    denyWindow,
+		})
+	}
+	if selfApproval {
+		rules = append(rules, alertRule{
+			Name:      "self_approval_attempt",
+			Severity:  "high",
+			Actions:   []string{"policy.approval.self_approval_denied"},
+			GroupBy:   []string{"actor"},
+			Threshold: 1,
+			Window:    time.Hour,
+		})
+	}
+	if downloadThreshold > 0 {
+		rules = append(rules, alertRule{
+			Name:      "mass_download",
+			Severity:  "medium",
+			Actions:   []string{"artifact.download_url_issued", "dataset_version.download"},
+			GroupBy:   []string{"actor", "project_id"},
+			Threshold: downloadThreshold,
+			Window:    downloadWindow,
+		})
+	}
+	cfg := alertsConfig{
+		Enabled:         enabled,
+		PollInterval:    pollInterval,
+		Rules:           rules,
+		SlackWebhookURL: strings.TrimSpace(env.String("AUDIT_ALERT_NOTIFY_SLACK_WEBHOOK_URL", "")),
+		EmailTo:         emailTo,
+	}
+	return cfg, cfg.Validate()
+}
+
+func (c alertsConfig) Validate() error {
+	if c.PollInterval <= 0 {
+		return errors.New("AUDIT_ALERT_POLL_INTERVAL must be positive")
+	}
+	for _, rule := range c.Rules {
+		if rule.Window <= 0 {
+			return fmt.Errorf("alert rule %s: window must be positive", rule.Name)
+		}
+	}
+	if c.SlackWebhookURL != "" && !strings.HasPrefix(c.SlackWebhookURL, "https://") {
+		return errors.New("AUDIT_ALERT_NOTIFY_SLACK_WEBHOOK_URL must be an https URL")
+	}
+	return nil
+}
+
+// Senders builds the notification channels. Email recipients require mailer.
+func (c alertsConfig) Senders(mailer *digests.Mailer) ([]notify.Sender, error) {
+	var out []notify.Sender
+	if len(c.EmailTo) > 0 {
+		if mailer == nil {
+			return nil, errors.New("AUDIT_ALERT_NOTIFY_EMAIL_TO requires ANIMUS_DIGEST_SMTP_ADDR")
+		}
+		out = append(out, notify.NewEmailSender(mailer, c.EmailTo))
+	}
+	if c.SlackWebhookURL != "" {
+		out = append(out, notify.NewSlackSender(c.SlackWebhookURL, 5*time.Second))
+	}
+	return out, nil
+}
+
+func (rule alertRule) matches(action string) bool {
+	for _, candidate := range rule.Actions {
+		if candidate == action {
+			return true
+		}
+	}
+	return false
+}
+
+// alertEvent is the part of an audit event the rules look at.
+type alertEvent struct {
+	EventID    int64
+	OccurredAt time.Time
+	Actor      string
+	Action     string
+	IP         string
+	ProjectID  string
+}
+
+// key returns the rule's grouping fields for ev and the alert key built from
+// them. Events without one of the fields (no client IP) are not grouped.
+func (rule alertRule) key(ev alertEvent) (map[string]string, string, bool) {
+	fields := make(map[string]string, len(rule.GroupBy))
+	parts := make([]string, 0, len(rule.GroupBy))
+	for _, name := range rule.GroupBy {
+		var value string
+		switch name {
+		case "ip":
+			value = ev.IP
+		case "actor":
+			value = ev.Actor
+		case "project_id":
+			value = ev.ProjectID
+		}
+		if value == "" && name != "project_id" {
+			return nil, "", false
+		}
+		fields[name] = value
+		parts = append(parts, name+"="+value)
+	}
+	return fields, strings.Join(parts, ","), true
+}
+
+// windowQuery counts the rule's events with ev's key in the window ending at
+// ev, and finds the first of them.
+func (rule alertRule) windowQuery(fields map[string]string, ev alertEvent) (string, []any) {
+	args := make([]any, 0, len(rule.Actions)+len(rule.GroupBy)+3)
+	placeholders := make([]string, 0, len(rule.Actions))
+	for _, action := range rule.Actions {
+		args = append(args, action)
+		placeholders = append(placeholders, "$"+strconv.Itoa(len(args)))
+	}
+	where := []string{"action IN (" + strings.Join(placeholders, ", ") + ")"}
+	add := func(clause string, value any) {
+		args = append(args, value)
+		where = append(where, fmt.Sprintf(clause, len(args)))
+	}
+	for _, name := range rule.GroupBy {
+		switch name {
+		case "ip":
+			add("ip = $%d::inet", fields[name])
+		case "actor":
+			add("actor = $%d", fields[name])
+		case "project_id":
+			add("COALESCE(payload->>'project_id', '') = $%d", fields[name])
+		}
+	}
+	add("occurred_at > $%d", ev.OccurredAt.Add(-rule.Window))
+	add("occurred_at <= $%d", ev.OccurredAt)
+	add("event_id <= $%d", ev.EventID)
+	return "SELECT count(*), COALESCE(MIN(event_id), 0), MIN(occurred_at) FROM audit_events WHERE " + strings.Join(where, " AND "), args
+}
+
+// alertEngine tails audit_events and applies the rules. The cursor row is
+// locked for the whole batch, so replicas evaluate each event once.
+// Notifications go out after the batch commits: an alert is always kept,
+// but a crash between commit and send loses its notification.
+type alertEngine struct {
+	db       *sql.DB
+	logger   *slog.Logger
+	rules    []alertRule
+	notifier *notify.Notifier
+	webhooks bool
+	now      func() time.Time
+}
+
+func (e *alertEngine) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for {
+				raised, more, err := e.evaluate(ctx)
+				if err != nil {
+					if e.logger != nil && ctx.Err() == nil {
+						e.logger.Warn("audit alert evaluation failed", "error", err)
+					}
+					break
+				}
+				e.announce(ctx, raised)
+				if !more {
+					break
+				}
+			}
+		}
+	}
+}
+
+func (e *alertEngine) actions() []string {
+	seen := map[string]struct{}{}
+	var out []string
+	for _, rule := range e.rules {
+		for _, action := range rule.Actions {
+			if _, ok := seen[action]; ok {
+				continue
+			}
+			seen[action] = struct{}{}
+			out = append(out, action)
+		}
+	}
+	return out
+}
+
+// evaluate applies the rules to the next batch of events after the cursor.
+// It reports whether more events are waiting.
+func (e *alertEngine) evaluate(ctx context.Context) ([]auditAlert, bool, error) {
+	actions := e.actions()
+	if len(actions) == 0 {
+		return nil, false, nil
+	}
+	tx, err := e.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var cursor, head int64
+	if err := tx.QueryRowContext(ctx, `SELECT last_event_id FROM audit_alert_cursor WHERE id = 1 FOR UPDATE`).Scan(&cursor); err != nil {
+		return nil, false, fmt.Errorf("read alert cursor: %w", err)
+	}
+	if err := tx.QueryRowContext(ctx, `SELECT COALESCE(MAX(event_id), 0) FROM audit_events`).Scan(&head); err != nil {
+		return nil, false, err
+	}
+	if head <= cursor {
+		return nil, false, nil
+	}
+
+	args := []any{cursor, head}
+	placeholders := make([]string, 0, len(actions))
+	for _, action := range actions {
+		args = append(args, action)
+		placeholders = append(placeholders, "$"+strconv.Itoa(len(args)))
+	}
+	args = append(args, alertBatchSize)
+	rows, err := tx.QueryContext(ctx,
+		`SELECT event_id, occurred_at, actor, action, COALESCE(host(ip), ''), COALESCE(payload->>'project_id', '')
+		 FROM audit_events
+		 WHERE event_id > $1 AND event_id <= $2 AND action IN (`+strings.Join(placeholders, ", ")+`)
+		 ORDER BY event_id
+		 LIMIT $`+strconv.Itoa(len(args)),
+		args...,
+	)
+	if err != nil {
+		return nil, false, err
+	}
+	events := make([]alertEvent, 0, alertBatchSize)
+	for rows.Next() {
+		var ev alertEvent
+		if err := rows.Scan(&ev.EventID, &ev.OccurredAt, &ev.Actor, &ev.Action, &ev.IP, &ev.ProjectID); err != nil {
+			_ = rows.Close()
+			return nil, false, err
+		}
+		events = append(events, ev)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, false, err
+	}
+
+	next, more := head, false
+	if len(events) == alertBatchSize {
+		next, more = events[len(events)-1].EventID, true
+	}
+	var raised []auditAlert
+	for _, ev := range events {
+		for _, rule := range e.rules {
+			if !rule.matches(ev.Action) {
+				continue
+			}
+			alert, ok, err := e.apply(ctx, tx, rule, ev)
+			if err != nil {
+				return nil, false, fmt.Errorf("rule %s: %w", rule.Name, err)
+			}
+			if ok {
+				raised = append(raised, alert)
+			}
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE audit_alert_cursor SET last_event_id = $1, updated_at = $2 WHERE id = 1`, next, e.now().UTC()); err != nil {
+		return nil, false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, false, err
+	}
+	return raised, more, nil
+}
+
+// apply extends the rule's current alert for ev's key, or raises a new one
+// when the window holds Threshold events. It reports whether an alert was
+// raised.
+func (e *alertEngine) apply(ctx context.Context, tx *sql.Tx, rule alertRule, ev alertEvent) (auditAlert, bool, error) {
+	fields, groupKey, ok := rule.key(ev)
+	if !ok {
+		return auditAlert{}, false, nil
+	}
+	var alertID string
+	err := tx.QueryRowContext(ctx,
+		`SELECT alert_id FROM audit_alerts
+		 WHERE rule = $1 AND group_key = $2 AND last_seen_at > $3
+		 ORDER BY last_seen_at DESC
+		 LIMIT 1
+		 FOR UPDATE`,
+		rule.Name, groupKey, ev.OccurredAt.Add(-rule.Window),
+	).Scan(&alertID)
+	if err == nil {
+		_, err = tx.ExecContext(ctx,
+			`UPDATE audit_alerts
+			 SET event_count = event_count + 1,
+			     last_event_id = GREATEST(last_event_id, $2),
+			     last_seen_at = GREATEST(last_seen_at, $3)
+			 WHERE alert_id = $1`,
+			alertID, ev.EventID, ev.OccurredAt,
+		)
+		return auditAlert{}, false, err
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return auditAlert{}, false, err
+	}
+
+	query, args := rule.windowQuery(fields, ev)
+	var (
+		count   int
+		firstID int64
+		firstAt sql.NullTime
+	)
+	if err := tx.QueryRowContext(ctx, query, args...).Scan(&count, &firstID, &firstAt); err != nil {
+		return auditAlert{}, false, err
+	}
+	if count < rule.Threshold {
+		return auditAlert{}, false, nil
+	}
+
+	details := map[string]any{
+		"actions":   rule.Actions,
+		"threshold": rule.Threshold,
+		"window":    rule.Window.String(),
+	}
+	for name, value := range fields {
+		details[name] = value
+	}
+	detailsJSON, err := json.Marshal(details)
+	if err != nil {
+		return auditAlert{}, false, err
+	}
+	now := e.now().UTC()
+	alert := auditAlert{
+		AlertID:      uuid.NewString(),
+		Rule:         rule.Name,
+		Severity:     rule.Severity,
+		GroupKey:     groupKey,
+		ProjectID:    fields["project_id"],
+		Status:       alertStatusOpen,
+		EventCount:   count,
+		FirstEventID: firstID,
+		LastEventID:  ev.EventID,
+		FirstSeenAt:  firstAt.Time.UTC(),
+		LastSeenAt:   ev.OccurredAt.UTC(),
+		Details:      detailsJSON,
+		CreatedAt:    now,
+	}
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO audit_alerts (
+			alert_id, rule, severity, group_key, project_id, status, event_count,
+			first_event_id, last_event_id, first_seen_at, last_seen_at, details, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`,
+		alert.AlertID, alert.Rule, alert.Severity, alert.GroupKey, alert.ProjectID, alert.Status, alert.EventCount,
+		alert.FirstEventID, alert.LastEventID, alert.FirstSeenAt, alert.LastSeenAt, detailsJSON, alert.CreatedAt,
+	); err != nil {
+		return auditAlert{}, false, err
+	}
+
+	payload := map[string]any{
+		"service":     "audit",
+		"rule":        alert.Rule,
+		"severity":    alert.Severity,
+		"event_count": alert.EventCount,
+	}
+	for name, value := range fields {
+		payload[name] = value
+	}
+	if err := auditlog.Enqueue(ctx, tx, auditlog.Event{
+		OccurredAt:   now,
+		Actor:        alertEngineActor,
+		Action:       "audit.alert.raised",
+		ResourceType: "audit_alert",
+		ResourceID:   alert.AlertID,
+		Payload:      payload,
+	}); err != nil {
+		return auditAlert{}, false, err
+	}
+	return alert, true, nil
+}
+
+// announce sends raised alerts to the notification channels and, for alerts
+// tied to a project, to its AuditAlertRaised webhook subscriptions.
+func (e *alertEngine) announce(ctx context.Context, alerts []auditAlert) {
+	for _, alert := range alerts {
+		if err := e.notifier.Send(ctx, alertMessage(alert, e.now().UTC())); err != nil && e.logger != nil {
+			e.logger.Warn("audit alert notification failed", "alert_id", alert.AlertID, "error", err)
+		}
+		if !e.webhooks || alert.ProjectID == "" {
+			continue
+		}
+		if err := e.enqueueWebhooks(ctx, alert); err != nil && e.logger != nil {
+			e.logger.Warn("audit alert webhook enqueue failed", "alert_id", alert.AlertID, "error", err)
+		}
+	}
+}
+
+func alertMessage(alert auditAlert, now time.Time) notify.Message {
+	return notify.Message{
+		Event:   notify.EventAuditAlert,
+		Subject: fmt.Sprintf("Audit alert [%s]: %s (%s)", alert.Severity, alert.Rule, alert.GroupKey),
+		Text: fmt.Sprintf(
+			"%d events between %s and %s.\nAlert: /api/audit/alerts/%s",
+			alert.EventCount,
+			alert.FirstSeenAt.Format(time.RFC3339),
+			alert.LastSeenAt.Format(time.RFC3339),
+			alert.AlertID,
+		),
+		SentAt: now,
+	}
+}
+
+func (e *alertEngine) enqueueWebhooks(ctx context.Context, alert auditAlert) error {
+	payload, err := webhooks.AuditAlertRaisedPayload(alert.ProjectID, alert.AlertID, alert.Rule, alert.CreatedAt)
+	if err != nil {
+		return err
+	}
+	payloadJSON, err := webhooks.PayloadJSON(payload)
+	if err != nil {
+		return err
+	}
+	subStore := repopg.NewWebhookSubscriptionStore(e.db)
+	deliveryStore := repopg.NewWebhookDeliveryStore(e.db)
+	if subStore == nil || deliveryStore == nil {
+		return errors.New("webhook store unavailable")
+	}
+	subs, err := subStore.ListEnabledByEvent(ctx, alert.ProjectID, webhooks.EventAuditAlertRaised)
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	for _, sub := range subs {
+		now := e.now().UTC()
+		record, inserted, err := deliveryStore.Enqueue(ctx, webhooks.Delivery{
+			ID:             uuid.NewString(),
+			ProjectID:      alert.ProjectID,
+			SubscriptionID: sub.ID,
+			EventID:        payload.EventID,
+			EventType:      payload.EventType,
+			Payload:        payloadJSON,
+			Status:         webhooks.DeliveryStatusPending,
+			NextAttemptAt:  now,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		})
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if inserted {
+			_ = auditlog.Enqueue(ctx, e.db, auditlog.Event{
+				OccurredAt:   now,
+				Actor:        alertEngineActor,
+				Action:       "webhook.delivery.enqueued",
+				ResourceType: "webhook_delivery",
+				ResourceID:   record.ID,
+				Payload: map[string]any{
+					"service":         "audit",
+					"project_id":      alert.ProjectID,
+					"subscription_id": record.SubscriptionID,
+					"event_id":        record.EventID,
+					"event_type":      record.EventType.String(),
+				},
+			})
+		}
+	}
+	return lastErr
+}
@@ -0,0 +1,262 @@
+package audit
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/domain"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+)
+
+type auditAlert struct {
+	AlertID        string          `json:"alert_id"`
+	Rule           string          `json:"rule"`
+	Severity       string          `json:"severity"`
+	GroupKey       string          `json:"group_key"`
+	ProjectID      string          `json:"project_id,omitempty"`
+	Status         string          `json:"status"`
+	EventCount     int             `json:"event_count"`
+	FirstEventID   int64           `json:"first_event_id"`
+	LastEventID    int64           `json:"last_event_id"`
+	FirstSeenAt    time.Time       `json:"first_seen_at"`
+	LastSeenAt     time.Time       `json:"last_seen_at"`
+	Details        json.RawMessage `json:"details"`
+	CreatedAt      time.Time       `json:"created_at"`
+	AcknowledgedAt *time.Time      `json:"acknowledged_at,omitempty"`
+	AcknowledgedBy string          `json:"acknowledged_by,omitempty"`
+	Redacted       bool            `json:"redacted,omitempty"`
+}
+
+const selectAuditAlertColumns = `alert_id, rule, severity, group_key, project_id, status, event_count,
+	first_event_id, last_event_id, first_seen_at, last_seen_at, details, created_at, acknowledged_at, acknowledged_by`
+
+type alertScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanAuditAlert(row alertScanner) (auditAlert, error) {
+	var (
+		alert          auditAlert
+		details        []byte
+		acknowledgedAt sql.NullTime
+		acknowledgedBy sql.NullString
+	)
+	if err := row.Scan(
+		&alert.AlertID,
+		&alert.Rule,
+		&alert.Severity,
+		&alert.GroupKey,
+		&alert.ProjectID,
+		&alert.Status,
+		&alert.EventCount,
+		&alert.FirstEventID,
+		&alert.LastEventID,
+		&alert.FirstSeenAt,
+		&alert.LastSeenAt,
+		&details,
+		&alert.CreatedAt,
+		&acknowledgedAt,
+		&acknowledgedBy,
+	); err != nil {
+		return auditAlert{}, err
+	}
+	alert.Details = httpapi.NormalizeJSON(details)
+	if acknowledgedAt.Valid {
+		t := acknowledgedAt.Time.UTC()
+		alert.AcknowledgedAt = &t
+	}
+	alert.AcknowledgedBy = strings.TrimSpace(acknowledgedBy.String)
+	return alert, nil
+}
+
+// handleListAlerts serves GET /alerts, newest first. status, rule and
+// project_id narrow the list; created_before pages back in time.
+func (api *auditAPI) handleListAlerts(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	where := make([]string, 0, 4)
+	args := make([]any, 0, 5)
+	add := func(clause string, value any) {
+		args = append(args, value)
+		where = append(where, fmt.Sprintf(clause, len(args)))
+	}
+	if status := strings.TrimSpace(q.Get("status")); status != "" {
+		if status != alertStatusOpen && status != alertStatusAcknowledged {
+			api.writeError(w, r, http.StatusBadRequest, "invalid_alert_filter")
+			return
+		}
+		add("status = $%d", status)
+	}
+	if rule := strings.TrimSpace(q.Get("rule")); rule != "" {
+		add("rule = $%d", rule)
+	}
+	if projectID := strings.TrimSpace(q.Get("project_id")); projectID != "" {
+		add("project_id = $%d", projectID)
+	}
+	if raw := strings.TrimSpace(q.Get("created_before")); raw != "" {
+		before, err := time.Parse(time.RFC3339Nano, raw)
+		if err != nil {
+			api.writeError(w, r, http.StatusBadRequest, "invalid_alert_filter")
+			return
+		}
+		add("created_at < $%d", before.UTC())
+	}
+	limit := httpapi.Limit(r, 100, 500)
+	args = append(args, limit)
+	query := "SELECT " + selectAuditAlertColumns + " FROM audit_alerts"
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY created_at DESC, alert_id DESC LIMIT $" + strconv.Itoa(len(args))
+
+	rows, err := api.db.QueryContext(r.Context(), query, args...)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer rows.Close()
+
+	identity, _ := auth.IdentityFromContext(r.Context())
+	redact := api.redaction.appliesTo(identity)
+	alerts := make([]auditAlert, 0, limit)
+	for rows.Next() {
+		alert, err := scanAuditAlert(rows)
+		if err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+		if redact {
+			api.redaction.redactAlert(&alert)
+		}
+		alerts = append(alerts, alert)
+	}
+	if err := rows.Err(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	api.writeJSON(w, http.StatusOK, map[string]any{"alerts": alerts})
+}
+
+func (api *auditAPI) handleGetAlert(w http.ResponseWriter, r *http.Request) {
+	alertID := strings.TrimSpace(r.PathValue("alert_id"))
+	if alertID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "alert_id_required")
+		return
+	}
+	alert, err := scanAuditAlert(api.db.QueryRowContext(r.Context(),
+		"SELECT "+selectAuditAlertColumns+" FROM audit_alerts WHERE alert_id = $1", alertID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if identity, _ := auth.IdentityFromContext(r.Context()); api.redaction.appliesTo(identity) {
+		api.redaction.redactAlert(&alert)
+	}
+	api.writeJSON(w, http.StatusOK, alert)
+}
+
+// handleAcknowledgeAlert serves POST /alerts/{alert_id}/acknowledge. It is
+// idempotent: acknowledging an acknowledged alert returns it unchanged.
+func (api *auditAPI) handleAcknowledgeAlert(w http.ResponseWriter, r *http.Request) {
+	identity, ok := auth.IdentityFromContext(r.Context())
+	if !ok || strings.TrimSpace(identity.Subject) == "" {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	alertID := strings.TrimSpace(r.PathValue("alert_id"))
+	if alertID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "alert_id_required")
+		return
+	}
+
+	now := time.Now().UTC()
+	alert, err := scanAuditAlert(api.db.QueryRowContext(r.Context(),
+		`UPDATE audit_alerts
+		 SET status = $2, acknowledged_at = $3, acknowledged_by = $4
+		 WHERE alert_id = $1 AND status = $5
+		 RETURNING `+selectAuditAlertColumns,
+		alertID, alertStatusAcknowledged, now, identity.Subject, alertStatusOpen))
+	if errors.Is(err, sql.ErrNoRows) {
+		alert, err = scanAuditAlert(api.db.QueryRowContext(r.Context(),
+			"SELECT "+selectAuditAlertColumns+" FROM audit_alerts WHERE alert_id = $1", alertID))
+		if errors.Is(err, sql.ErrNoRows) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return
+		}
+		if err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+		api.writeJSON(w, http.StatusOK, alert)
+		return
+	}
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	if api.audit != nil {
+		_, _ = api.audit.Append(r.Context(), domain.AuditEvent{
+			OccurredAt:   now,
+			Actor:        identity.Subject,
+			Action:       "audit.alert.acknowledged",
+			ResourceType: "audit_alert",
+			ResourceID:   alert.AlertID,
+			RequestID:    r.Header.Get("X-Request-Id"),
+			IP:           httpapi.RequestIP(r.RemoteAddr),
+			UserAgent:    r.UserAgent(),
+			Payload: domain.Metadata{
+				"service":    "audit",
+				"rule":       alert.Rule,
+				"project_id": alert.ProjectID,
+			},
+		})
+	}
+	api.writeJSON(w, http.StatusOK, alert)
+}
+
+// redactAlert applies the payload paths to the alert details, which hold the
+// grouping fields (ip, actor, project_id), and masks the same fields in
+// group_key.
+func (a auditRedaction) redactAlert(alert *auditAlert) {
+	decoder := json.NewDecoder(bytes.NewReader(alert.Details))
+	decoder.UseNumber()
+	var details map[string]any
+	if err := decoder.Decode(&details); err != nil || details == nil {
+		return
+	}
+	masked := false
+	for _, segments := range a.paths {
+		if redactPath(details, segments) {
+			masked = true
+		}
+	}
+	if !masked {
+		return
+	}
+	raw, err := json.Marshal(details)
+	if err != nil {
+		return
+	}
+	alert.Details = raw
+	parts := strings.Split(alert.GroupKey, ",")
+	for i, part := range parts {
+		name, _, _ := strings.Cut(part, "=")
+		if details[name] == redactedValue {
+			parts[i] = name + "=" + redactedValue
+		}
+	}
+	alert.GroupKey = strings.Join(parts, ",")
+	alert.Redacted = true
+}
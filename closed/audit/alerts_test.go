@@ -0,0 +1,108 @@
+package audit
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAlertsConfigFromEnv(t *testing.T) {
+	t.Setenv("AUDIT_ALERT_AUTH_DENY_THRESHOLD", "5")
+	t.Setenv("AUDIT_ALERT_DOWNLOAD_THRESHOLD", "0")
+	cfg, err := alertsConfigFromEnv()
+	if err != nil {
+		t.Fatalf("alertsConfigFromEnv: %v", err)
+	}
+	names := make([]string, 0, len(cfg.Rules))
+	for _, rule := range cfg.Rules {
+		names = append(names, rule.Name)
+	}
+	if strings.Join(names, ",") != "auth_deny_burst,self_approval_attempt" || cfg.Rules[0].Threshold != 5 {
+		t.Fatalf("rules=%+v", cfg.Rules)
+	}
+
+	t.Setenv("AUDIT_ALERT_NOTIFY_SLACK_WEBHOOK_URL", "http://hooks.example/x")
+	if _, err := alertsConfigFromEnv(); err == nil {
+		t.Fatal("expected plain http slack url to be rejected")
+	}
+	t.Setenv("AUDIT_ALERT_NOTIFY_SLACK_WEBHOOK_URL", "")
+	t.Setenv("AUDIT_ALERT_NOTIFY_EMAIL_TO", "secops@lab.local")
+	cfg, err = alertsConfigFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cfg.Senders(nil); err == nil {
+		t.Fatal("email recipients without smtp must be rejected")
+	}
+}
+
+func TestAlertRuleKeyAndWindow(t *testing.T) {
+	rule := alertRule{
+		Name:      "mass_download",
+		Actions:   []string{"artifact.download_url_issued", "dataset_version.download"},
+		GroupBy:   []string{"actor", "project_id"},
+		Threshold: 3,
+		Window:    10 * time.Minute,
+	}
+	ev := alertEvent{
+		EventID:    42,
+		OccurredAt: time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC),
+		Actor:      "alice",
+		Action:     "dataset_version.download",
+		ProjectID:  "p-1",
+	}
+	if !rule.matches(ev.Action) || rule.matches("dataset_version.create") {
+		t.Fatal("matches")
+	}
+	fields, key, ok := rule.key(ev)
+	if !ok || key != "actor=alice,project_id=p-1" || fields["project_id"] != "p-1" {
+		t.Fatalf("key=%q fields=%v ok=%v", key, fields, ok)
+	}
+	query, args := rule.windowQuery(fields, ev)
+	for _, want := range []string{
+		"action IN ($1, $2)",
+		"actor = $3",
+		"COALESCE(payload->>'project_id', '') = $4",
+		"occurred_at > $5",
+		"occurred_at <= $6",
+		"event_id <= $7",
+	} {
+		if !strings.Contains(query, want) {
+			t.Fatalf("query missing %q:\n%s", want, query)
+		}
+	}
+	if len(args) != 7 || args[4] != ev.OccurredAt.Add(-10*time.Minute) || args[6] != int64(42) {
+		t.Fatalf("args=%v", args)
+	}
+
+	byIP := alertRule{Name: "auth_deny_burst", Actions: authDenyActions, GroupBy: []string{"ip"}, Threshold: 1, Window: time.Minute}
+	if _, _, ok := byIP.key(alertEvent{Actor: "anonymous", Action: "auth.unauthenticated"}); ok {
+		t.Fatal("events without an ip must not be grouped by ip")
+	}
+}
+
+func TestRedactAlert(t *testing.T) {
+	redaction, err := parseRedactPaths(defaultRedactPaths)
+	if err != nil {
+		t.Fatal(err)
+	}
+	alert := auditAlert{
+		GroupKey: "ip=10.0.0.7",
+		Details:  json.RawMessage(`{"ip":"10.0.0.7","threshold":20,"window":"5m0s"}`),
+	}
+	redaction.redactAlert(&alert)
+	var details map[string]any
+	if err := json.Unmarshal(alert.Details, &details); err != nil {
+		t.Fatal(err)
+	}
+	if details["ip"] != redactedValue || details["window"] != "5m0s" || alert.GroupKey != "ip="+redactedValue || !alert.Redacted {
+		t.Fatalf("alert=%+v details=%s", alert, alert.Details)
+	}
+
+	alert = auditAlert{GroupKey: "actor=alice", Details: json.RawMessage(`{"actor":"alice"}`)}
+	redaction.redactAlert(&alert)
+	if alert.GroupKey != "actor=alice" || alert.Redacted {
+		t.Fatalf("unlisted fields changed: %+v", alert)
+	}
+}
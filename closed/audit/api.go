@@ -1,4 +1,4 @@
-package main
+package audit
 
 import (
 	"context"
@@ -15,36 +15,59 @@ import (
 
 	"github.com/animus-labs/animus-go/closed/internal/auditexport"
 	"github.com/animus-labs/animus-go/closed/internal/domain"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	"github.com/animus-labs/animus-go/closed/internal/platform/postgres"
 	"github.com/animus-labs/animus-go/closed/internal/repo"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 type auditAPI struct {
-	logger     *slog.Logger
-	db         *sql.DB
+	logger *slog.Logger
+	db     *sql.DB
+	// reads serves event lists, exports and statistics; it is the read
+	// replica when one is configured and db otherwise.
+	reads      postgres.Querier
 	exportCfg  auditexport.Config
 	audit      repo.AuditEventAppender
 	sinks      auditexport.SinkStore
 	deliveries auditexport.DeliveryStore
 	attempts   auditexport.AttemptStore
 	replays    auditexport.ReplayStore
+	redaction  auditRedaction
+	stats      statsConfig
+	statsCache *statsCache
+	// worm reads exported batches back for verification; nil when the WORM
+	// export is disabled.
+	worm wormStore
 }
 
 func newAuditAPI(logger *slog.Logger, db *sql.DB, exportCfg auditexport.Config, auditAppender repo.AuditEventAppender, sinks auditexport.SinkStore, deliveries auditexport.DeliveryStore, attempts auditexport.AttemptStore, replays auditexport.ReplayStore) *auditAPI {
 	return &auditAPI{
 		logger:     logger,
 		db:         db,
+		reads:      db,
 		exportCfg:  exportCfg,
 		audit:      auditAppender,
 		sinks:      sinks,
 		deliveries: deliveries,
 		attempts:   attempts,
 		replays:    replays,
+		statsCache: &statsCache{},
 	}
 }
 
 func (api *auditAPI) register(mux *http.ServeMux) {
 	mux.HandleFunc("GET /events", api.handleListEvents)
 	mux.HandleFunc("GET /events/{event_id}", api.handleGetEvent)
+	mux.HandleFunc("GET /stats", api.handleStats)
+	mux.HandleFunc("GET /alerts", api.handleListAlerts)
+	mux.HandleFunc("GET /alerts/{alert_id}", api.handleGetAlert)
+	mux.HandleFunc("POST /alerts/{alert_id}/acknowledge", api.handleAcknowledgeAlert)
+	mux.HandleFunc("GET /worm/batches", api.handleListWormBatches)
+	mux.HandleFunc("GET /worm/batches/{seq}", api.handleGetWormBatch)
+	mux.HandleFunc("GET /worm/signing-keys", api.handleListWormSigningKeys)
+	mux.HandleFunc("GET /worm/verify", api.handleVerifyWorm)
 	mux.HandleFunc("POST /export", api.handleExport)
 	mux.HandleFunc("GET /admin/audit/exports/sinks", api.handleListExportSinks)
 	mux.HandleFunc("GET /admin/audit/exports/deliveries", api.handleListExportDeliveries)
@@ -68,8 +91,8 @@ func (api *auditAPI) handleExport(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req exportRequest
-	if err := decodeJSON(r, &req); err != nil {
-		api.writeError(w, r, http.StatusBadRequest, "invalid_json")
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
 		return
 	}
 	projectID := strings.TrimSpace(req.ProjectID)
@@ -83,7 +106,7 @@ func (api *auditAPI) handleExport(w http.ResponseWriter, r *http.Request) {
 	}
 
 	query, args := buildExportQuery(projectID, req.StartTime, req.EndTime)
-	rows, err := api.db.QueryContext(r.Context(), query, args...)
+	rows, err := api.reads.QueryContext(r.Context(), query, args...)
 	if err != nil {
 		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
 		return
@@ -183,7 +206,7 @@ func (api *auditAPI) handleListExportSinks(w http.ResponseWriter, r *http.Reques
 		api.writeError(w, r, http.StatusServiceUnavailable, "export_unavailable")
 		return
 	}
-	limit := clampInt(parseIntQuery(r, "limit", 200), 1, 500)
+	limit := httpapi.Limit(r, 200, 500)
 	records, err := api.sinks.ListSinks(r.Context(), limit)
 	if err != nil {
 		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
@@ -225,7 +248,7 @@ func (api *auditAPI) handleListExportDeliveries(w http.ResponseWriter, r *http.R
 	}
 	status := strings.TrimSpace(r.URL.Query().Get("status"))
 	sinkID := strings.TrimSpace(r.URL.Query().Get("sink_id"))
-	limit := clampInt(parseIntQuery(r, "limit", 200), 1, 500)
+	limit := httpapi.Limit(r, 200, 500)
 	records, err := api.deliveries.List(r.Context(), status, sinkID, limit)
 	if err != nil {
 		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
@@ -262,7 +285,7 @@ func (api *auditAPI) handleListExportAttempts(w http.ResponseWriter, r *http.Req
 		api.writeError(w, r, http.StatusBadRequest, "invalid_delivery_id")
 		return
 	}
-	limit := clampInt(parseIntQuery(r, "limit", 200), 1, 500)
+	limit := httpapi.Limit(r, 200, 500)
 	records, err := api.attempts.List(r.Context(), deliveryID, limit)
 	if err != nil {
 		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
@@ -299,8 +322,8 @@ func (api *auditAPI) handleReplayExportDelivery(w http.ResponseWriter, r *http.R
 	token := strings.TrimSpace(r.Header.Get("Idempotency-Key"))
 	if token == "" && r.ContentLength != 0 {
 		var req replayRequest
-		if err := decodeJSON(r, &req); err != nil {
-			api.writeError(w, r, http.StatusBadRequest, "invalid_json")
+		if err := httpapi.DecodeJSON(r, &req); err != nil {
+			httpapi.WriteDecodeError(w, r, err)
 			return
 		}
 		token = strings.TrimSpace(req.ReplayToken)
@@ -336,6 +359,7 @@ type auditEvent struct {
 	UserAgent       string          `json:"user_agent,omitempty"`
 	Payload         json.RawMessage `json:"payload"`
 	IntegritySHA256 string          `json:"integrity_sha256"`
+	Redacted        bool            `json:"redacted,omitempty"`
 }
 
 type exportRequest struct {
@@ -345,58 +369,31 @@ type exportRequest struct {
 }
 
 func (api *auditAPI) handleListEvents(w http.ResponseWriter, r *http.Request) {
-	limit := clampInt(parseIntQuery(r, "limit", 100), 1, 500)
-	beforeID := parseInt64Query(r, "before_event_id", 0)
-
-	actor := strings.TrimSpace(r.URL.Query().Get("actor"))
-	action := strings.TrimSpace(r.URL.Query().Get("action"))
-	resourceType := strings.TrimSpace(r.URL.Query().Get("resource_type"))
-	resourceID := strings.TrimSpace(r.URL.Query().Get("resource_id"))
-	requestID := strings.TrimSpace(r.URL.Query().Get("request_id"))
-
-	where := make([]string, 0, 6)
-	args := make([]any, 0, 8)
-
-	if beforeID > 0 {
-		args = append(args, beforeID)
-		where = append(where, "event_id < $"+strconv.Itoa(len(args)))
-	}
-	if actor != "" {
-		args = append(args, actor)
-		where = append(where, "actor = $"+strconv.Itoa(len(args)))
-	}
-	if action != "" {
-		args = append(args, action)
-		where = append(where, "action = $"+strconv.Itoa(len(args)))
-	}
-	if resourceType != "" {
-		args = append(args, resourceType)
-		where = append(where, "resource_type = $"+strconv.Itoa(len(args)))
-	}
-	if resourceID != "" {
-		args = append(args, resourceID)
-		where = append(where, "resource_id = $"+strconv.Itoa(len(args)))
-	}
-	if requestID != "" {
-		args = append(args, requestID)
-		where = append(where, "request_id = $"+strconv.Itoa(len(args)))
+	limit := httpapi.Limit(r, 100, 500)
+	filter, code := parseEventFilter(r.URL.Query())
+	if code != "" {
+		api.writeError(w, r, http.StatusBadRequest, code)
+		return
 	}
-
-	args = append(args, limit)
-	query := `SELECT event_id, occurred_at, actor, action, resource_type, resource_id, request_id, ip, user_agent, payload, integrity_sha256
-		FROM audit_events`
-	if len(where) > 0 {
-		query += " WHERE " + strings.Join(where, " AND ")
+	if identity, _ := auth.IdentityFromContext(r.Context()); !api.redaction.allowsPayloadFilter(identity, filter) {
+		api.writeError(w, r, http.StatusForbidden, "payload_filter_forbidden")
+		return
 	}
-	query += " ORDER BY event_id DESC LIMIT $" + strconv.Itoa(len(args))
+	query, args := filter.query(limit)
 
-	rows, err := api.db.QueryContext(r.Context(), query, args...)
+	rows, err := api.reads.QueryContext(r.Context(), query, args...)
 	if err != nil {
+		if filter.PayloadPath != "" && isJSONPathError(err) {
+			api.writeError(w, r, http.StatusBadRequest, "invalid_payload_filter")
+			return
+		}
 		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
 		return
 	}
 	defer rows.Close()
 
+	identity, _ := auth.IdentityFromContext(r.Context())
+	redact := api.redaction.appliesTo(identity)
 	events := make([]auditEvent, 0, limit)
 	for rows.Next() {
 		var (
@@ -426,7 +423,10 @@ func (api *auditAPI) handleListEvents(w http.ResponseWriter, r *http.Request) {
 		ev.RequestID = strings.TrimSpace(reqID.String)
 		ev.IP = strings.TrimSpace(ip.String)
 		ev.UserAgent = strings.TrimSpace(userAgent.String)
-		ev.Payload = normalizeJSON(payloadRaw)
+		ev.Payload = httpapi.NormalizeJSON(payloadRaw)
+		if redact {
+			api.redaction.redact(&ev)
+		}
 		events = append(events, ev)
 	}
 	if err := rows.Err(); err != nil {
@@ -437,6 +437,7 @@ func (api *auditAPI) handleListEvents(w http.ResponseWriter, r *http.Request) {
 	resp := map[string]any{"events": events}
 	if len(events) > 0 {
 		resp["next_before_event_id"] = events[len(events)-1].EventID
+		resp["next_cursor"] = httpapi.EncodeCursor(eventCursor{BeforeEventID: events[len(events)-1].EventID})
 	}
 	api.writeJSON(w, http.StatusOK, resp)
 }
@@ -491,17 +492,16 @@ func (api *auditAPI) handleGetEvent(w http.ResponseWriter, r *http.Request) {
 	ev.RequestID = strings.TrimSpace(reqID.String)
 	ev.IP = strings.TrimSpace(ip.String)
 	ev.UserAgent = strings.TrimSpace(userAgent.String)
-	ev.Payload = normalizeJSON(payloadRaw)
+	ev.Payload = httpapi.NormalizeJSON(payloadRaw)
+	if identity, _ := auth.IdentityFromContext(r.Context()); api.redaction.appliesTo(identity) {
+		api.redaction.redact(&ev)
+	}
 
 	api.writeJSON(w, http.StatusOK, ev)
 }
 
 func (api *auditAPI) writeJSON(w http.ResponseWriter, status int, body any) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	enc := json.NewEncoder(w)
-	enc.SetEscapeHTML(true)
-	_ = enc.Encode(body)
+	httpapi.WriteJSON(w, status, body)
 }
 
 func (api *auditAPI) auditExportAccess(ctx context.Context, action string, resourceID string, payload domain.Metadata) {
@@ -526,22 +526,7 @@ func (api *auditAPI) auditExportAccess(ctx context.Context, action string, resou
 }
 
 func (api *auditAPI) writeError(w http.ResponseWriter, r *http.Request, status int, code string) {
-	api.writeJSON(w, status, map[string]any{
-		"error":      code,
-		"request_id": r.Header.Get("X-Request-Id"),
-	})
-}
-
-func decodeJSON(r *http.Request, dst any) error {
-	dec := json.NewDecoder(r.Body)
-	dec.DisallowUnknownFields()
-	if err := dec.Decode(dst); err != nil {
-		return err
-	}
-	if err := dec.Decode(&struct{}{}); err == nil {
-		return errors.New("multiple JSON values")
-	}
-	return nil
+	httpapi.WriteError(w, r, status, code)
 }
 
 func buildExportQuery(projectID string, startTime *time.Time, endTime *time.Time) (string, []any) {
@@ -567,7 +552,7 @@ func buildExportQuery(projectID string, startTime *time.Time, endTime *time.Time
 }
 
 func decodePayload(raw []byte) domain.Metadata {
-	raw = normalizeJSON(raw)
+	raw = httpapi.NormalizeJSON(raw)
 	var payload map[string]any
 	if err := json.Unmarshal(raw, &payload); err != nil {
 		return domain.Metadata{}
@@ -578,48 +563,12 @@ func decodePayload(raw []byte) domain.Metadata {
 	return domain.Metadata(payload)
 }
 
-func normalizeJSON(raw []byte) json.RawMessage {
-	raw = bytesTrimSpace(raw)
-	if len(raw) == 0 || string(raw) == "null" {
-		return []byte("{}")
-	}
-	return raw
-}
-
-func bytesTrimSpace(in []byte) []byte {
-	return []byte(strings.TrimSpace(string(in)))
-}
-
-func parseIntQuery(r *http.Request, key string, def int) int {
-	v := strings.TrimSpace(r.URL.Query().Get(key))
-	if v == "" {
-		return def
-	}
-	parsed, err := strconv.Atoi(v)
-	if err != nil {
-		return def
-	}
-	return parsed
-}
-
-func parseInt64Query(r *http.Request, key string, def int64) int64 {
-	v := strings.TrimSpace(r.URL.Query().Get(key))
-	if v == "" {
-		return def
-	}
-	parsed, err := strconv.ParseInt(v, 10, 64)
-	if err != nil {
-		return def
-	}
-	return parsed
-}
-
-func clampInt(v int, min int, max int) int {
-	if v < min {
-		return min
-	}
-	if v > max {
-		return max
+// isJSONPathError reports a payload_path the database could not parse or
+// evaluate: a syntax error or an SQL/JSON data exception (class 22).
+func isJSONPathError(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == "42601" || strings.HasPrefix(pgErr.Code, "22")
 	}
-	return v
+	return false
 }
@@ -0,0 +1,167 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+)
+
+const maxPayloadFilterLen = 1024
+
+// eventFilter is the server-side filter of GET /events. Every field narrows
+// the result; the zero value lists everything.
+type eventFilter struct {
+	BeforeID       int64
+	Actor          string
+	Action         string
+	ActionPrefix   string
+	ResourceType   string
+	ResourceID     string
+	RequestID      string
+	OccurredAfter  *time.Time
+	OccurredBefore *time.Time
+	// PayloadContains is a JSON object matched with the JSONB containment
+	// operator (payload @> ...).
+	PayloadContains string
+	// PayloadPath is an SQL/JSON path matched with payload @? ..., e.g.
+	// $.params ? (@.lr > 0.01).
+	PayloadPath string
+}
+
+// eventCursor is the page position behind the cursor of GET /events.
+type eventCursor struct {
+	BeforeEventID int64 `json:"before_event_id"`
+}
+
+// parseEventFilter reads the filter from the query string. It returns the
+// error code to answer with when a parameter is malformed.
+func parseEventFilter(q url.Values) (eventFilter, string) {
+	f := eventFilter{
+		Actor:        strings.TrimSpace(q.Get("actor")),
+		Action:       strings.TrimSpace(q.Get("action")),
+		ActionPrefix: strings.TrimSpace(q.Get("action_prefix")),
+		ResourceType: strings.TrimSpace(q.Get("resource_type")),
+		ResourceID:   strings.TrimSpace(q.Get("resource_id")),
+		RequestID:    strings.TrimSpace(q.Get("request_id")),
+		PayloadPath:  strings.TrimSpace(q.Get("payload_path")),
+	}
+	if raw := strings.TrimSpace(q.Get("before_event_id")); raw != "" {
+		if id, err := strconv.ParseInt(raw, 10, 64); err == nil && id > 0 {
+			f.BeforeID = id
+		}
+	}
+	if raw := q.Get("cursor"); raw != "" {
+		var pos eventCursor
+		if httpapi.DecodeCursor(raw, &pos) != nil || pos.BeforeEventID <= 0 {
+			return eventFilter{}, "invalid_cursor"
+		}
+		f.BeforeID = pos.BeforeEventID
+	}
+	for name, dst := range map[string]**time.Time{
+		"occurred_after":  &f.OccurredAfter,
+		"occurred_before": &f.OccurredBefore,
+	} {
+		raw := strings.TrimSpace(q.Get(name))
+		if raw == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339Nano, raw)
+		if err != nil {
+			return eventFilter{}, "invalid_time_range"
+		}
+		parsed = parsed.UTC()
+		*dst = &parsed
+	}
+	if f.OccurredAfter != nil && f.OccurredBefore != nil && !f.OccurredBefore.After(*f.OccurredAfter) {
+		return eventFilter{}, "invalid_time_range"
+	}
+	if raw := strings.TrimSpace(q.Get("payload_contains")); raw != "" {
+		var obj map[string]any
+		if len(raw) > maxPayloadFilterLen || json.Unmarshal([]byte(raw), &obj) != nil || obj == nil {
+			return eventFilter{}, "invalid_payload_filter"
+		}
+		f.PayloadContains = raw
+	}
+	if f.PayloadPath != "" && (len(f.PayloadPath) > maxPayloadFilterLen || !validJSONPathPrefix(f.PayloadPath)) {
+		return eventFilter{}, "invalid_payload_filter"
+	}
+	return f, ""
+}
+
+// validJSONPathPrefix rejects obvious non-paths early; the database parses the
+// rest and its syntax errors are mapped to invalid_payload_filter too.
+func validJSONPathPrefix(raw string) bool {
+	for _, mode := range []string{"strict ", "lax "} {
+		raw = strings.TrimSpace(strings.TrimPrefix(raw, mode))
+	}
+	return strings.HasPrefix(raw, "$")
+}
+
+// conditions returns the WHERE clauses of the filter and their arguments,
+// numbered from $1.
+func (f eventFilter) conditions() ([]string, []any) {
+	where := make([]string, 0, 10)
+	args := make([]any, 0, 11)
+	add := func(clause string, value any) {
+		args = append(args, value)
+		where = append(where, fmt.Sprintf(clause, len(args)))
+	}
+
+	if f.BeforeID > 0 {
+		add("event_id < $%d", f.BeforeID)
+	}
+	if f.Actor != "" {
+		add("actor = $%d", f.Actor)
+	}
+	if f.Action != "" {
+		add("action = $%d", f.Action)
+	}
+	if f.ActionPrefix != "" {
+		add(`action LIKE $%d ESCAPE '\'`, escapeLike(f.ActionPrefix)+"%")
+	}
+	if f.ResourceType != "" {
+		add("resource_type = $%d", f.ResourceType)
+	}
+	if f.ResourceID != "" {
+		add("resource_id = $%d", f.ResourceID)
+	}
+	if f.RequestID != "" {
+		add("request_id = $%d", f.RequestID)
+	}
+	if f.OccurredAfter != nil {
+		add("occurred_at >= $%d", *f.OccurredAfter)
+	}
+	if f.OccurredBefore != nil {
+		add("occurred_at < $%d", *f.OccurredBefore)
+	}
+	if f.PayloadContains != "" {
+		add("payload @> $%d::jsonb", f.PayloadContains)
+	}
+	if f.PayloadPath != "" {
+		add("payload @? $%d::jsonpath", f.PayloadPath)
+	}
+	return where, args
+}
+
+func (f eventFilter) query(limit int) (string, []any) {
+	where, args := f.conditions()
+	args = append(args, limit)
+	query := `SELECT event_id, occurred_at, actor, action, resource_type, resource_id, request_id, ip, user_agent, payload, integrity_sha256
+		FROM audit_events`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY event_id DESC LIMIT $" + strconv.Itoa(len(args))
+	return query, args
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+func escapeLike(value string) string {
+	return likeEscaper.Replace(value)
+}
@@ -0,0 +1,79 @@
+package audit
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+)
+
+func TestEventFilterQuery(t *testing.T) {
+	q := url.Values{
+		"actor":            {"alice"},
+		"action_prefix":    {"auth.session_"},
+		"resource_type":    {"experiment_run"},
+		"occurred_after":   {"2026-01-01T00:00:00Z"},
+		"occurred_before":  {"2026-02-01T00:00:00+03:00"},
+		"payload_contains": {`{"project_id":"p-1"}`},
+		"payload_path":     {`$.params ? (@.lr > 0.01)`},
+		"before_event_id":  {"900"},
+	}
+	filter, code := parseEventFilter(q)
+	if code != "" {
+		t.Fatalf("parseEventFilter: %s", code)
+	}
+	query, args := filter.query(50)
+	for _, want := range []string{
+		"event_id < $1",
+		"actor = $2",
+		`action LIKE $3 ESCAPE '\'`,
+		"resource_type = $4",
+		"occurred_at >= $5",
+		"occurred_at < $6",
+		"payload @> $7::jsonb",
+		"payload @? $8::jsonpath",
+		"LIMIT $9",
+	} {
+		if !strings.Contains(query, want) {
+			t.Fatalf("query missing %q:\n%s", want, query)
+		}
+	}
+	if args[2] != `auth.session\_%` || args[len(args)-1] != 50 {
+		t.Fatalf("args=%v", args)
+	}
+	if got := filter.OccurredBefore.Format("15:04"); got != "21:00" {
+		t.Fatalf("occurred_before not normalized to UTC: %s", got)
+	}
+}
+
+func TestEventFilterRejectsMalformedParams(t *testing.T) {
+	for _, tc := range []struct {
+		q    url.Values
+		code string
+	}{
+		{url.Values{"occurred_after": {"yesterday"}}, "invalid_time_range"},
+		{url.Values{"occurred_after": {"2026-02-01T00:00:00Z"}, "occurred_before": {"2026-01-01T00:00:00Z"}}, "invalid_time_range"},
+		{url.Values{"payload_contains": {`["a"]`}}, "invalid_payload_filter"},
+		{url.Values{"payload_path": {"params.lr"}}, "invalid_payload_filter"},
+		{url.Values{"cursor": {"not-a-cursor"}}, "invalid_cursor"},
+	} {
+		if _, code := parseEventFilter(tc.q); code != tc.code {
+			t.Fatalf("%v: code=%q, want %q", tc.q, code, tc.code)
+		}
+	}
+	if _, code := parseEventFilter(url.Values{"payload_path": {"strict $.ip"}}); code != "" {
+		t.Fatalf("strict path rejected: %s", code)
+	}
+}
+
+func TestEventFilterCursor(t *testing.T) {
+	q := url.Values{
+		"before_event_id": {"900"},
+		"cursor":          {httpapi.EncodeCursor(eventCursor{BeforeEventID: 42})},
+	}
+	filter, code := parseEventFilter(q)
+	if code != "" || filter.BeforeID != 42 {
+		t.Fatalf("filter=%+v code=%q", filter, code)
+	}
+}
@@ -1,4 +1,4 @@
-package main
+package audit
 
 import (
 	"context"
@@ -11,17 +11,28 @@ import (
 	"time"
 
 	"github.com/animus-labs/animus-go/closed/internal/auditexport"
+	"github.com/animus-labs/animus-go/closed/internal/integrations/digests"
+	"github.com/animus-labs/animus-go/closed/internal/integrations/notify"
+	"github.com/animus-labs/animus-go/closed/internal/integrations/webhooks"
 	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
 	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
 	"github.com/animus-labs/animus-go/closed/internal/platform/env"
 	"github.com/animus-labs/animus-go/closed/internal/platform/httpserver"
+	"github.com/animus-labs/animus-go/closed/internal/platform/inproc"
+	"github.com/animus-labs/animus-go/closed/internal/platform/migrate"
+	"github.com/animus-labs/animus-go/closed/internal/platform/objectstore"
+	"github.com/animus-labs/animus-go/closed/internal/platform/outbox"
+	"github.com/animus-labs/animus-go/closed/internal/platform/outbox/servicerelay"
 	"github.com/animus-labs/animus-go/closed/internal/platform/postgres"
 	"github.com/animus-labs/animus-go/closed/internal/platform/rbac"
 	"github.com/animus-labs/animus-go/closed/internal/platform/secrets"
 	repopg "github.com/animus-labs/animus-go/closed/internal/repo/postgres"
+	"github.com/animus-labs/animus-go/closed/migrations"
+	"github.com/animus-labs/animus-go/core/contracts/openapi"
 )
 
-func main() {
+// Main runs audit as a standalone service.
+func Main() {
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
 
 	ctx := context.Background()
@@ -34,36 +45,124 @@ func main() {
 		logger.Error("invalid env", "error", err)
 		os.Exit(2)
 	}
+
+	dbCfg, err := postgres.ConfigFromEnv("audit")
+	if err != nil {
+		logger.Error("invalid database config", "error", err)
+		os.Exit(2)
+	}
+	db, err := postgres.Open(ctx, dbCfg)
+	if err != nil {
+		logger.Error("database unavailable", "error", err)
+		os.Exit(1)
+	}
+	defer func() { _ = db.Close() }()
+
+	migrator, err := migrate.New(db, migrations.Files, logger)
+	if err != nil {
+		logger.Error("invalid migrations", "error", err)
+		os.Exit(2)
+	}
+	if done, err := migrate.Startup(ctx, migrator, os.Args[1:], os.Stdout); err != nil {
+		logger.Error("migrations failed", "error", err)
+		os.Exit(1)
+	} else if done {
+		return
+	}
+
+	replicaCfg, err := postgres.ReplicaConfigFromEnv("audit")
+	if err != nil {
+		logger.Error("invalid read replica config", "error", err)
+		os.Exit(2)
+	}
+	reads, err := postgres.OpenReplica(db, dbCfg, replicaCfg, logger)
+	if err != nil {
+		logger.Error("read replica init failed", "error", err)
+		os.Exit(2)
+	}
+	defer func() { _ = reads.Close() }()
+	httpserver.RegisterMetricsProvider(postgres.PrometheusMetrics("audit", reads.Pools()))
+
+	internalMTLSCfg, err := auth.InternalMTLSConfigFromEnv()
+	if err != nil {
+		logger.Error("invalid internal mtls config", "error", err)
+		os.Exit(2)
+	}
+	internalServerTLS, err := internalMTLSCfg.ServerTLSConfig()
+	if err != nil {
+		logger.Error("internal mtls init failed", "error", err)
+		os.Exit(2)
+	}
+	handler := NewHandler(ctx, logger, &inproc.Resources{DB: db, Reads: reads, Migrator: migrator})
+
+	cfg := httpserver.Config{
+		Service:         "audit",
+		Addr:            addr,
+		ShutdownTimeout: shutdownTimeout,
+		TLSConfig:       internalServerTLS,
+	}
+
+	if err := httpserver.Run(ctx, logger, cfg, httpserver.Wrap(logger, "audit", handler)); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Error("server failed", "error", err)
+		os.Exit(1)
+	}
+}
+
+// NewHandler builds the audit API on res and starts the export, alert and
+// WORM workers, which stop with ctx. Invalid configuration exits the process,
+// as it does in Main.
+func NewHandler(ctx context.Context, logger *slog.Logger, res *inproc.Resources) http.Handler {
+	db, reads := res.DB, res.Reads
 	rbacAllowDirect, err := env.Bool("AUTH_RBAC_ALLOW_DIRECT_ROLES", true)
 	if err != nil {
 		logger.Error("invalid env", "error", err)
 		os.Exit(2)
 	}
 
-	exportCfg, err := auditexport.ConfigFromEnv()
+	alertsCfg, err := alertsConfigFromEnv()
 	if err != nil {
-		logger.Error("invalid audit export config", "error", err)
+		logger.Error("invalid audit alerts config", "error", err)
+		os.Exit(2)
+	}
+	webhookCfg, err := webhooks.ConfigFromEnv()
+	if err != nil {
+		logger.Error("invalid webhook config", "error", err)
+		os.Exit(2)
+	}
+	smtpCfg, err := digests.SMTPConfigFromEnv()
+	if err != nil {
+		logger.Error("invalid smtp config", "error", err)
+		os.Exit(2)
+	}
+	alertSenders, err := alertsCfg.Senders(digests.NewMailer(smtpCfg))
+	if err != nil {
+		logger.Error("invalid audit alerts config", "error", err)
 		os.Exit(2)
 	}
 
-	dbCfg, err := postgres.ConfigFromEnv()
+	wormCfg, err := wormConfigFromEnv()
 	if err != nil {
-		logger.Error("invalid database config", "error", err)
+		logger.Error("invalid audit worm export config", "error", err)
 		os.Exit(2)
 	}
-	db, err := postgres.Open(ctx, dbCfg)
+
+	exportCfg, err := auditexport.ConfigFromEnv()
 	if err != nil {
-		logger.Error("database unavailable", "error", err)
-		os.Exit(1)
+		logger.Error("invalid audit export config", "error", err)
+		os.Exit(2)
 	}
-	defer func() { _ = db.Close() }()
 
-	internalAuthSecret := env.String("ANIMUS_INTERNAL_AUTH_SECRET", "")
-	headersAuth, err := auth.NewGatewayHeadersAuthenticator(internalAuthSecret)
+	headersAuth, err := auth.NewGatewayHeadersAuthenticator(env.String("ANIMUS_INTERNAL_AUTH_SECRET", ""))
 	if err != nil {
 		logger.Error("invalid internal auth config", "error", err)
 		os.Exit(2)
 	}
+	internalMTLSCfg, err := auth.InternalMTLSConfigFromEnv()
+	if err != nil {
+		logger.Error("invalid internal mtls config", "error", err)
+		os.Exit(2)
+	}
+	headersAuth.Peers = internalMTLSCfg.PeerVerifier()
 
 	auditAppender := repopg.NewAuditAppender(db, nil)
 	exportStore := repopg.NewAuditExportStore(db)
@@ -116,16 +215,44 @@ func main() {
 		)
 		go worker.Run(ctx)
 	}
+	if alertsCfg.Enabled {
+		engine := &alertEngine{
+			db:       db,
+			logger:   logger,
+			rules:    alertsCfg.Rules,
+			notifier: notify.New(alertSenders...),
+			webhooks: webhookCfg.Enabled(),
+			now:      time.Now,
+		}
+		go engine.run(ctx, alertsCfg.PollInterval)
+	}
+	outboxCfg, err := outbox.ConfigFromEnv()
+	if err != nil {
+		logger.Error("invalid outbox config", "error", err)
+		os.Exit(2)
+	}
+	servicerelay.Start(ctx, logger, db, outboxCfg, nil, "")
+	rbacPermissionsTTL, err := env.Duration("AUTH_RBAC_PERMISSIONS_CACHE_TTL", 30*time.Second)
+	if err != nil {
+		logger.Error("invalid env", "error", err)
+		os.Exit(2)
+	}
 	authorizer := rbac.Authorizer{
 		Audit:       auditAppender,
 		AllowDirect: rbacAllowDirect,
 		RequiredRoleFor: func(r *http.Request) string {
 			return auth.RoleAdmin
 		},
+		Permissions: rbac.NewPermissionCache(repopg.NewRBACPermissionStore(db), "audit", rbacPermissionsTTL),
+		// Auditors read events, alerts and WORM batches; acknowledging alerts
+		// and export sink and delivery configuration stay with admins.
+		AuditorScope: rbac.AuditorRoutes("/events", "/events/*", "/stats", "/alerts", "/alerts/*", "/worm/**"),
 	}
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/healthz", httpserver.Healthz("audit"))
+	mux.HandleFunc("/version", httpserver.BuildInfo("audit"))
+	mux.Handle("GET "+openapi.ServicePath, openapi.Handler(openapi.Audit))
 	mux.HandleFunc(
 		"/readyz",
 		httpserver.ReadyzWithChecks(
@@ -143,10 +270,58 @@ func main() {
 	httpserver.RegisterMetricsProvider(auditexport.PrometheusMetrics(deliveryStore))
 	httpserver.RegisterMetrics(mux, "audit")
 
+	redaction, err := parseRedactPaths(env.String("AUDIT_REDACT_PATHS", defaultRedactPaths))
+	if err != nil {
+		logger.Error("invalid env", "error", err)
+		os.Exit(2)
+	}
+
 	api := newAuditAPI(logger, db, exportCfg, auditAppender, exportStore, deliveryStore, attemptStore, replayStore)
+	api.redaction = redaction
+	api.reads = reads
+	statsMaxWindow, err := env.Duration("AUDIT_STATS_MAX_WINDOW", 366*24*time.Hour)
+	if err != nil {
+		logger.Error("invalid env", "error", err)
+		os.Exit(2)
+	}
+	statsCacheTTL, err := env.Duration("AUDIT_STATS_CACHE_TTL", time.Minute)
+	if err != nil {
+		logger.Error("invalid env", "error", err)
+		os.Exit(2)
+	}
+	api.stats = statsConfig{MaxWindow: statsMaxWindow, CacheTTL: statsCacheTTL}
+	if wormCfg.Enabled {
+		signer, err := loadWormSigner(wormCfg.SigningKeyFile)
+		if err != nil {
+			logger.Error("audit worm signing key unavailable", "error", err)
+			os.Exit(2)
+		}
+		storeClient, storeCfg, err := res.ObjectStore()
+		if err != nil {
+			logger.Error("object store init failed", "error", err)
+			os.Exit(2)
+		}
+		startupCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		locked, err := objectstore.EnsureLockedBucket(startupCtx, storeClient, wormCfg.Bucket, storeCfg.Region)
+		if err == nil {
+			err = registerWormSigningKey(startupCtx, db, signer)
+		}
+		cancel()
+		if err != nil {
+			logger.Error("audit worm export init failed", "error", err)
+			os.Exit(1)
+		}
+		if !locked {
+			logger.Warn("audit worm bucket has no object lock; batches are stored without retention", "bucket", wormCfg.Bucket)
+		}
+		store := &minioWormStore{client: storeClient, bucket: wormCfg.Bucket, locked: locked, mode: wormCfg.RetentionMode}
+		api.worm = store
+		exporter := &wormExporter{db: db, logger: logger, store: store, signer: signer, cfg: wormCfg, now: time.Now}
+		go exporter.run(ctx)
+	}
 	api.register(mux)
 
-	handler := auth.Middleware{
+	return auth.Middleware{
 		Logger:        logger,
 		Authenticator: headersAuth,
 		Authorize:     authorizer.Authorize,
@@ -155,17 +330,6 @@ func main() {
 			defer cancel()
 			return auditlog.InsertAuthDeny(auditCtx, db, "audit", event)
 		},
-		SkipPrefixes: []string{"/healthz", "/readyz"},
+		SkipPrefixes: []string{"/healthz", "/readyz", "/version", openapi.ServicePath},
 	}.Wrap(mux)
-
-	cfg := httpserver.Config{
-		Service:         "audit",
-		Addr:            addr,
-		ShutdownTimeout: shutdownTimeout,
-	}
-
-	if err := httpserver.Run(ctx, logger, cfg, httpserver.Wrap(logger, "audit", handler)); err != nil && !errors.Is(err, http.ErrServerClosed) {
-		logger.Error("server failed", "error", err)
-		os.Exit(1)
-	}
 }
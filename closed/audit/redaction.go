@@ -0,0 +1,128 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+)
+
+const (
+	defaultRedactPaths = "params.*,ip,user_agent"
+	redactedValue      = "[redacted]"
+)
+
+// auditRedaction masks payload fields for readers below admin. Paths are
+// dotted payload paths whose segments are path.Match patterns, so params.*
+// masks every parameter value while keeping the keys. Arrays are walked
+// transparently. The single-segment paths ip and user_agent also mask the
+// event's own ip and user_agent columns.
+//
+// integrity_sha256 is always returned as stored: it covers the original
+// content, so only an unredacted read can be verified against it.
+type auditRedaction struct {
+	paths [][]string
+}
+
+func parseRedactPaths(raw string) (auditRedaction, error) {
+	var out auditRedaction
+	for _, item := range strings.Split(raw, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		segments := strings.Split(item, ".")
+		for _, segment := range segments {
+			if segment == "" {
+				return auditRedaction{}, fmt.Errorf("AUDIT_REDACT_PATHS: empty segment in %q", item)
+			}
+			if _, err := path.Match(segment, ""); err != nil {
+				return auditRedaction{}, fmt.Errorf("AUDIT_REDACT_PATHS: %q: %w", item, err)
+			}
+		}
+		out.paths = append(out.paths, segments)
+	}
+	return out, nil
+}
+
+// appliesTo reports whether events read by identity are redacted.
+func (a auditRedaction) appliesTo(identity auth.Identity) bool {
+	return len(a.paths) > 0 && !auth.HasAtLeast(identity.Roles, auth.RoleAdmin)
+}
+
+// allowsPayloadFilter reports whether identity may filter on the payload.
+// Containment and path filters match the stored payload, so a redacted
+// reader could otherwise probe masked values one guess at a time.
+func (a auditRedaction) allowsPayloadFilter(identity auth.Identity, f eventFilter) bool {
+	return !a.appliesTo(identity) || (f.PayloadContains == "" && f.PayloadPath == "")
+}
+
+func (a auditRedaction) redact(ev *auditEvent) {
+	redacted := false
+	for _, segments := range a.paths {
+		if len(segments) != 1 {
+			continue
+		}
+		if ok, _ := path.Match(segments[0], "ip"); ok && ev.IP != "" {
+			ev.IP = redactedValue
+			redacted = true
+		}
+		if ok, _ := path.Match(segments[0], "user_agent"); ok && ev.UserAgent != "" {
+			ev.UserAgent = redactedValue
+			redacted = true
+		}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(ev.Payload))
+	decoder.UseNumber()
+	var payload any
+	if err := decoder.Decode(&payload); err == nil {
+		masked := false
+		for _, segments := range a.paths {
+			if redactPath(payload, segments) {
+				masked = true
+			}
+		}
+		if masked {
+			if raw, err := json.Marshal(payload); err == nil {
+				ev.Payload = raw
+				redacted = true
+			}
+		}
+	}
+	ev.Redacted = redacted
+}
+
+func redactPath(value any, segments []string) bool {
+	switch typed := value.(type) {
+	case map[string]any:
+		changed := false
+		for key, child := range typed {
+			if ok, _ := path.Match(segments[0], key); !ok {
+				continue
+			}
+			if len(segments) == 1 {
+				typed[key] = redactedValue
+				changed = true
+				continue
+			}
+			if redactPath(child, segments[1:]) {
+				changed = true
+			}
+		}
+		return changed
+	case []any:
+		changed := false
+		for _, item := range typed {
+			if redactPath(item, segments) {
+				changed = true
+			}
+		}
+		return changed
+	default:
+		return false
+	}
+}
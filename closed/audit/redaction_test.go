@@ -0,0 +1,88 @@
+package audit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+)
+
+func TestAuditRedaction(t *testing.T) {
+	redaction, err := parseRedactPaths(defaultRedactPaths + ",steps.secret")
+	if err != nil {
+		t.Fatalf("parseRedactPaths: %v", err)
+	}
+	ev := auditEvent{
+		IP:              "10.0.0.7",
+		UserAgent:       "curl/8",
+		Payload:         json.RawMessage(`{"params":{"lr":0.1,"token":"abc"},"ip":"10.0.0.7","steps":[{"secret":"x","name":"train"}],"project_id":"p-1"}`),
+		IntegritySHA256: "deadbeef",
+	}
+	redaction.redact(&ev)
+
+	var payload map[string]any
+	if err := json.Unmarshal(ev.Payload, &payload); err != nil {
+		t.Fatal(err)
+	}
+	params := payload["params"].(map[string]any)
+	step := payload["steps"].([]any)[0].(map[string]any)
+	if params["lr"] != redactedValue || params["token"] != redactedValue || payload["ip"] != redactedValue || step["secret"] != redactedValue {
+		t.Fatalf("payload not redacted: %s", ev.Payload)
+	}
+	if step["name"] != "train" || payload["project_id"] != "p-1" {
+		t.Fatalf("unlisted fields changed: %s", ev.Payload)
+	}
+	if ev.IP != redactedValue || ev.UserAgent != redactedValue || !ev.Redacted || ev.IntegritySHA256 != "deadbeef" {
+		t.Fatalf("event=%+v", ev)
+	}
+
+	if redaction.appliesTo(auth.Identity{Roles: []string{auth.RoleAdmin}}) {
+		t.Fatal("admins must read unredacted events")
+	}
+	if !redaction.appliesTo(auth.Identity{Roles: []string{auth.RoleAuditor}}) {
+		t.Fatal("auditors must read redacted events")
+	}
+	if _, err := parseRedactPaths("params..x"); err == nil {
+		t.Fatal("expected empty segment error")
+	}
+}
+
+// A redacted reader must not learn masked values by filtering on them.
+func TestPayloadFiltersForbiddenWhenRedacted(t *testing.T) {
+	redaction, err := parseRedactPaths(defaultRedactPaths)
+	if err != nil {
+		t.Fatalf("parseRedactPaths: %v", err)
+	}
+	api := &auditAPI{redaction: redaction}
+	auditor := auth.Identity{Subject: "auditor-1", Roles: []string{auth.RoleAuditor}}
+	probes := []url.Values{
+		{"payload_contains": {`{"params":{"token":"abc"}}`}},
+		{"payload_path": {`$.params ? (@.token == "abc")`}},
+	}
+	for _, probe := range probes {
+		for path, handler := range map[string]http.HandlerFunc{"/events": api.handleListEvents, "/stats": api.handleStats} {
+			req := httptest.NewRequest(http.MethodGet, path+"?"+probe.Encode(), nil)
+			req = req.WithContext(auth.ContextWithIdentity(req.Context(), auditor))
+			rec := httptest.NewRecorder()
+			handler(rec, req)
+			if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), "payload_filter_forbidden") {
+				t.Fatalf("%s?%s: status=%d body=%s", path, probe.Encode(), rec.Code, rec.Body.String())
+			}
+		}
+	}
+
+	filter, _ := parseEventFilter(probes[0])
+	if !redaction.allowsPayloadFilter(auth.Identity{Roles: []string{auth.RoleAdmin}}, filter) {
+		t.Fatal("admins may filter on the payload")
+	}
+	if !redaction.allowsPayloadFilter(auditor, eventFilter{Action: "run.create"}) {
+		t.Fatal("filters off the payload stay open to auditors")
+	}
+	if !(auditRedaction{}).allowsPayloadFilter(auditor, filter) {
+		t.Fatal("without redaction paths every reader may filter on the payload")
+	}
+}
@@ -0,0 +1,241 @@
+package audit
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+)
+
+const (
+	defaultStatsWindow   = 30 * 24 * time.Hour
+	maxStatsGroupBy      = 3
+	maxStatsCacheEntries = 256
+)
+
+// statsDimensions maps the group_by names of GET /stats to SQL expressions.
+// service is the emitting service when the payload records one (auth
+// denials), otherwise the action namespace: quality_gate.block counts under
+// quality_gate.
+var statsDimensions = map[string]string{
+	"action":        "action",
+	"actor":         "actor",
+	"resource_type": "resource_type",
+	"service":       "COALESCE(NULLIF(payload->>'service', ''), split_part(action, '.', 1))",
+	"project":       "COALESCE(payload->>'project_id', '')",
+	"day":           "to_char(occurred_at AT TIME ZONE 'UTC', 'YYYY-MM-DD')",
+}
+
+type statsConfig struct {
+	MaxWindow time.Duration
+	CacheTTL  time.Duration
+}
+
+type statsGroup map[string]any
+
+type statsResponse struct {
+	From        time.Time    `json:"from"`
+	To          time.Time    `json:"to"`
+	GroupBy     []string     `json:"group_by"`
+	Groups      []statsGroup `json:"groups"`
+	TotalEvents int64        `json:"total_events"`
+	TotalGroups int64        `json:"total_groups"`
+	Truncated   bool         `json:"truncated"`
+	GeneratedAt time.Time    `json:"generated_at"`
+}
+
+// handleStats serves GET /stats: event counts grouped by up to three
+// dimensions over a window (default: the last 30 days). It accepts every
+// filter of GET /events except the pagination cursor.
+func (api *auditAPI) handleStats(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	groupBy, ok := parseStatsGroupBy(q.Get("group_by"))
+	if !ok {
+		api.writeError(w, r, http.StatusBadRequest, "invalid_group_by")
+		return
+	}
+	filter, code := parseEventFilter(q)
+	if code != "" {
+		api.writeError(w, r, http.StatusBadRequest, code)
+		return
+	}
+	if identity, _ := auth.IdentityFromContext(r.Context()); !api.redaction.allowsPayloadFilter(identity, filter) {
+		api.writeError(w, r, http.StatusForbidden, "payload_filter_forbidden")
+		return
+	}
+	filter.BeforeID = 0
+
+	// Default bounds are minute-aligned so repeated dashboard reads share a
+	// cache entry.
+	now := time.Now().UTC().Truncate(time.Minute)
+	if filter.OccurredBefore == nil {
+		to := now.Add(time.Minute)
+		filter.OccurredBefore = &to
+	}
+	if filter.OccurredAfter == nil {
+		from := filter.OccurredBefore.Add(-defaultStatsWindow)
+		filter.OccurredAfter = &from
+	}
+	if !filter.OccurredBefore.After(*filter.OccurredAfter) {
+		api.writeError(w, r, http.StatusBadRequest, "invalid_time_range")
+		return
+	}
+	if api.stats.MaxWindow > 0 && filter.OccurredBefore.Sub(*filter.OccurredAfter) > api.stats.MaxWindow {
+		api.writeError(w, r, http.StatusBadRequest, "stats_window_too_large")
+		return
+	}
+	limit := httpapi.Limit(r, 1000, 10000)
+
+	query, args := statsQuery(filter, groupBy, limit)
+	key := query + "\x00" + fmt.Sprint(args...)
+	if cached, ok := api.statsCache.get(key, time.Now()); ok {
+		w.Header().Set("X-Cache", "hit")
+		api.writeJSON(w, http.StatusOK, cached)
+		return
+	}
+
+	rows, err := api.reads.QueryContext(r.Context(), query, args...)
+	if err != nil {
+		if filter.PayloadPath != "" && isJSONPathError(err) {
+			api.writeError(w, r, http.StatusBadRequest, "invalid_payload_filter")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer rows.Close()
+
+	resp := statsResponse{
+		From:        *filter.OccurredAfter,
+		To:          *filter.OccurredBefore,
+		GroupBy:     groupBy,
+		Groups:      make([]statsGroup, 0),
+		GeneratedAt: time.Now().UTC(),
+	}
+	values := make([]string, len(groupBy))
+	dest := make([]any, 0, len(groupBy)+3)
+	for i := range values {
+		dest = append(dest, &values[i])
+	}
+	var count int64
+	dest = append(dest, &count, &resp.TotalEvents, &resp.TotalGroups)
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+		group := make(statsGroup, len(groupBy)+1)
+		for i, name := range groupBy {
+			group[name] = values[i]
+		}
+		group["count"] = count
+		resp.Groups = append(resp.Groups, group)
+	}
+	if err := rows.Err(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	resp.Truncated = resp.TotalGroups > int64(len(resp.Groups))
+
+	api.statsCache.put(key, resp, time.Now(), api.stats.CacheTTL)
+	w.Header().Set("X-Cache", "miss")
+	api.writeJSON(w, http.StatusOK, resp)
+}
+
+func parseStatsGroupBy(raw string) ([]string, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return []string{"action"}, true
+	}
+	out := make([]string, 0, maxStatsGroupBy)
+	for _, item := range strings.Split(raw, ",") {
+		item = strings.ToLower(strings.TrimSpace(item))
+		if _, ok := statsDimensions[item]; !ok {
+			return nil, false
+		}
+		for _, seen := range out {
+			if seen == item {
+				return nil, false
+			}
+		}
+		out = append(out, item)
+	}
+	if len(out) > maxStatsGroupBy {
+		return nil, false
+	}
+	return out, true
+}
+
+// statsQuery groups the filtered events server-side. The window functions
+// run over the grouped rows before LIMIT, so the totals cover every group.
+func statsQuery(filter eventFilter, groupBy []string, limit int) (string, []any) {
+	where, args := filter.conditions()
+	exprs := make([]string, 0, len(groupBy))
+	positions := make([]string, 0, len(groupBy))
+	for i, name := range groupBy {
+		exprs = append(exprs, statsDimensions[name]+" AS "+name)
+		positions = append(positions, strconv.Itoa(i+1))
+	}
+	args = append(args, limit)
+	query := "SELECT " + strings.Join(exprs, ", ") +
+		", count(*) AS events, (sum(count(*)) OVER ())::bigint AS total_events, count(*) OVER () AS total_groups FROM audit_events"
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " GROUP BY " + strings.Join(positions, ", ") +
+		" ORDER BY events DESC, " + strings.Join(positions, ", ") +
+		" LIMIT $" + strconv.Itoa(len(args))
+	return query, args
+}
+
+// statsCache keeps recent /stats answers for CacheTTL. Audit events are
+// append-only, so a cached answer is at most TTL behind.
+type statsCache struct {
+	mu      sync.Mutex
+	entries map[string]statsCacheEntry
+}
+
+type statsCacheEntry struct {
+	resp      statsResponse
+	expiresAt time.Time
+}
+
+func (c *statsCache) get(key string, now time.Time) (statsResponse, bool) {
+	if c == nil {
+		return statsResponse{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || !now.Before(entry.expiresAt) {
+		return statsResponse{}, false
+	}
+	return entry.resp, true
+}
+
+func (c *statsCache) put(key string, resp statsResponse, now time.Time, ttl time.Duration) {
+	if c == nil || ttl <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]statsCacheEntry)
+	}
+	if len(c.entries) >= maxStatsCacheEntries {
+		for k, entry := range c.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= maxStatsCacheEntries {
+			clear(c.entries)
+		}
+	}
+	c.entries[key] = statsCacheEntry{resp: resp, expiresAt: now.Add(ttl)}
+}
@@ -0,0 +1,65 @@
+package audit
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseStatsGroupBy(t *testing.T) {
+	if got, ok := parseStatsGroupBy(""); !ok || len(got) != 1 || got[0] != "action" {
+		t.Fatalf("default group_by=%v ok=%v", got, ok)
+	}
+	if got, ok := parseStatsGroupBy(" Project, day "); !ok || strings.Join(got, ",") != "project,day" {
+		t.Fatalf("group_by=%v ok=%v", got, ok)
+	}
+	for _, raw := range []string{"payload", "day,day", "action,actor,service,day"} {
+		if _, ok := parseStatsGroupBy(raw); ok {
+			t.Fatalf("%q: expected rejection", raw)
+		}
+	}
+}
+
+func TestStatsQuery(t *testing.T) {
+	filter, code := parseEventFilter(url.Values{
+		"action":          {"quality_gate.block"},
+		"occurred_after":  {"2026-10-01T00:00:00Z"},
+		"occurred_before": {"2026-11-01T00:00:00Z"},
+	})
+	if code != "" {
+		t.Fatal(code)
+	}
+	query, args := statsQuery(filter, []string{"project", "day"}, 100)
+	for _, want := range []string{
+		"COALESCE(payload->>'project_id', '') AS project",
+		"AS day",
+		"action = $1",
+		"occurred_at >= $2",
+		"occurred_at < $3",
+		"GROUP BY 1, 2 ORDER BY events DESC, 1, 2 LIMIT $4",
+	} {
+		if !strings.Contains(query, want) {
+			t.Fatalf("query missing %q:\n%s", want, query)
+		}
+	}
+	if len(args) != 4 || args[3] != 100 {
+		t.Fatalf("args=%v", args)
+	}
+}
+
+func TestStatsCache(t *testing.T) {
+	cache := &statsCache{}
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	cache.put("k", statsResponse{TotalEvents: 7}, now, time.Minute)
+	if got, ok := cache.get("k", now.Add(30*time.Second)); !ok || got.TotalEvents != 7 {
+		t.Fatalf("expected hit, got %+v ok=%v", got, ok)
+	}
+	if _, ok := cache.get("k", now.Add(time.Minute)); ok {
+		t.Fatal("expected expiry after TTL")
+	}
+	cache.put("off", statsResponse{}, now, 0)
+	if _, ok := cache.get("off", now); ok {
+		t.Fatal("zero TTL must disable caching")
+	}
+}
@@ -0,0 +1,337 @@
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+)
+
+const maxWormVerifyBatches = 1000
+
+type wormBatch struct {
+	Seq             int64      `json:"seq"`
+	FirstEventID    int64      `json:"first_event_id"`
+	LastEventID     int64      `json:"last_event_id"`
+	EventCount      int        `json:"event_count"`
+	Bucket          string     `json:"bucket"`
+	ObjectKey       string     `json:"object_key"`
+	VersionID       string     `json:"version_id,omitempty"`
+	ContentSHA256   string     `json:"content_sha256"`
+	PrevChainSHA256 string     `json:"prev_chain_sha256"`
+	ChainSHA256     string     `json:"chain_sha256"`
+	Signature       string     `json:"signature"`
+	KeyID           string     `json:"key_id"`
+	ObjectLocked    bool       `json:"object_locked"`
+	RetainUntil     *time.Time `json:"retain_until,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+const selectWormBatchColumns = `seq, first_event_id, last_event_id, event_count, bucket, object_key, version_id,
+	content_sha256, prev_chain_sha256, chain_sha256, signature, key_id, object_locked, retain_until, created_at`
+
+func scanWormBatch(row alertScanner) (wormBatch, error) {
+	var (
+		batch       wormBatch
+		retainUntil sql.NullTime
+	)
+	if err := row.Scan(
+		&batch.Seq,
+		&batch.FirstEventID,
+		&batch.LastEventID,
+		&batch.EventCount,
+		&batch.Bucket,
+		&batch.ObjectKey,
+		&batch.VersionID,
+		&batch.ContentSHA256,
+		&batch.PrevChainSHA256,
+		&batch.ChainSHA256,
+		&batch.Signature,
+		&batch.KeyID,
+		&batch.ObjectLocked,
+		&retainUntil,
+		&batch.CreatedAt,
+	); err != nil {
+		return wormBatch{}, err
+	}
+	if retainUntil.Valid {
+		t := retainUntil.Time.UTC()
+		batch.RetainUntil = &t
+	}
+	return batch, nil
+}
+
+// wormBatchCursor is the page position behind the cursor of GET
+// /worm/batches.
+type wormBatchCursor struct {
+	BeforeSeq int64 `json:"before_seq"`
+}
+
+func (api *auditAPI) handleListWormBatches(w http.ResponseWriter, r *http.Request) {
+	limit := httpapi.Limit(r, 100, 500)
+	args := []any{}
+	query := "SELECT " + selectWormBatchColumns + " FROM audit_worm_batches"
+	var before int64
+	if raw := strings.TrimSpace(r.URL.Query().Get("before_seq")); raw != "" {
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || parsed <= 0 {
+			api.writeError(w, r, http.StatusBadRequest, "invalid_batch_seq")
+			return
+		}
+		before = parsed
+	}
+	if raw := r.URL.Query().Get("cursor"); raw != "" {
+		var pos wormBatchCursor
+		if httpapi.DecodeCursor(raw, &pos) != nil || pos.BeforeSeq <= 0 {
+			api.writeError(w, r, http.StatusBadRequest, "invalid_cursor")
+			return
+		}
+		before = pos.BeforeSeq
+	}
+	if before > 0 {
+		args = append(args, before)
+		query += " WHERE seq < $1"
+	}
+	args = append(args, limit)
+	query += " ORDER BY seq DESC LIMIT $" + strconv.Itoa(len(args))
+
+	rows, err := api.db.QueryContext(r.Context(), query, args...)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer rows.Close()
+	batches := make([]wormBatch, 0, limit)
+	for rows.Next() {
+		batch, err := scanWormBatch(rows)
+		if err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+		batches = append(batches, batch)
+	}
+	if err := rows.Err(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	resp := map[string]any{"batches": batches}
+	if len(batches) > 0 {
+		resp["next_before_seq"] = batches[len(batches)-1].Seq
+		resp["next_cursor"] = httpapi.EncodeCursor(wormBatchCursor{BeforeSeq: batches[len(batches)-1].Seq})
+	}
+	api.writeJSON(w, http.StatusOK, resp)
+}
+
+func (api *auditAPI) handleGetWormBatch(w http.ResponseWriter, r *http.Request) {
+	seq, err := strconv.ParseInt(strings.TrimSpace(r.PathValue("seq")), 10, 64)
+	if err != nil || seq <= 0 {
+		api.writeError(w, r, http.StatusBadRequest, "invalid_batch_seq")
+		return
+	}
+	batch, err := scanWormBatch(api.db.QueryRowContext(r.Context(),
+		"SELECT "+selectWormBatchColumns+" FROM audit_worm_batches WHERE seq = $1", seq))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	api.writeJSON(w, http.StatusOK, batch)
+}
+
+func (api *auditAPI) handleListWormSigningKeys(w http.ResponseWriter, r *http.Request) {
+	rows, err := api.db.QueryContext(r.Context(),
+		`SELECT key_id, algorithm, public_key_pem, created_at FROM audit_worm_signing_keys ORDER BY created_at DESC, key_id`)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer rows.Close()
+	keys := make([]wormSigningKey, 0)
+	for rows.Next() {
+		var key wormSigningKey
+		if err := rows.Scan(&key.KeyID, &key.Algorithm, &key.PublicKeyPEM, &key.CreatedAt); err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	api.writeJSON(w, http.StatusOK, map[string]any{"keys": keys})
+}
+
+type wormVerifyFailure struct {
+	Seq    int64  `json:"seq"`
+	Reason string `json:"reason"`
+}
+
+type wormVerifyResponse struct {
+	Verified       bool                `json:"verified"`
+	FromSeq        int64               `json:"from_seq"`
+	ToSeq          int64               `json:"to_seq"`
+	BatchesChecked int                 `json:"batches_checked"`
+	Failures       []wormVerifyFailure `json:"failures"`
+	// NextFromSeq is set when the range held more than one call verifies.
+	NextFromSeq int64 `json:"next_from_seq,omitempty"`
+}
+
+// handleVerifyWorm serves GET /worm/verify: it re-reads the batches from the
+// object store and checks content hashes, chain links and signatures for
+// from_seq..to_seq (default: from the first batch, at most 1000 per call).
+func (api *auditAPI) handleVerifyWorm(w http.ResponseWriter, r *http.Request) {
+	if api.worm == nil {
+		api.writeError(w, r, http.StatusServiceUnavailable, "worm_export_unavailable")
+		return
+	}
+	q := r.URL.Query()
+	fromSeq, toSeq := int64(1), int64(0)
+	for name, dst := range map[string]*int64{"from_seq": &fromSeq, "to_seq": &toSeq} {
+		raw := strings.TrimSpace(q.Get(name))
+		if raw == "" {
+			continue
+		}
+		value, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || value <= 0 {
+			api.writeError(w, r, http.StatusBadRequest, "invalid_batch_seq")
+			return
+		}
+		*dst = value
+	}
+	if toSeq > 0 && toSeq < fromSeq {
+		api.writeError(w, r, http.StatusBadRequest, "invalid_batch_seq")
+		return
+	}
+
+	prevChain := ""
+	if fromSeq > 1 {
+		err := api.db.QueryRowContext(r.Context(), `SELECT chain_sha256 FROM audit_worm_batches WHERE seq = $1`, fromSeq-1).Scan(&prevChain)
+		if errors.Is(err, sql.ErrNoRows) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return
+		}
+		if err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+	}
+	query := "SELECT " + selectWormBatchColumns + " FROM audit_worm_batches WHERE seq >= $1"
+	args := []any{fromSeq}
+	if toSeq > 0 {
+		args = append(args, toSeq)
+		query += " AND seq <= $2"
+	}
+	args = append(args, maxWormVerifyBatches+1)
+	query += " ORDER BY seq LIMIT $" + strconv.Itoa(len(args))
+	rows, err := api.db.QueryContext(r.Context(), query, args...)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	var batches []wormBatch
+	for rows.Next() {
+		batch, err := scanWormBatch(rows)
+		if err != nil {
+			_ = rows.Close()
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+		batches = append(batches, batch)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	resp := wormVerifyResponse{FromSeq: fromSeq, ToSeq: toSeq, Failures: make([]wormVerifyFailure, 0)}
+	if len(batches) > maxWormVerifyBatches {
+		resp.NextFromSeq = batches[maxWormVerifyBatches].Seq
+		batches = batches[:maxWormVerifyBatches]
+	}
+	if len(batches) > 0 {
+		resp.ToSeq = batches[len(batches)-1].Seq
+	}
+	keys, err := api.wormPublicKeys(r.Context())
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	resp.BatchesChecked = len(batches)
+	resp.Failures = append(resp.Failures, verifyWormBatches(r.Context(), api.worm, batches, prevChain, fromSeq, keys)...)
+	resp.Verified = len(resp.Failures) == 0
+	api.writeJSON(w, http.StatusOK, resp)
+}
+
+func (api *auditAPI) wormPublicKeys(ctx context.Context) (map[string]string, error) {
+	rows, err := api.db.QueryContext(ctx, `SELECT key_id, public_key_pem FROM audit_worm_signing_keys`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	keys := map[string]string{}
+	for rows.Next() {
+		var keyID, publicKey string
+		if err := rows.Scan(&keyID, &publicKey); err != nil {
+			return nil, err
+		}
+		keys[keyID] = publicKey
+	}
+	return keys, rows.Err()
+}
+
+// verifyWormBatches checks consecutive batches starting at fromSeq, whose
+// predecessor has chain hash prevChain. Each batch is checked against the
+// stored object, the recomputed chain and its signature.
+func verifyWormBatches(ctx context.Context, store wormStore, batches []wormBatch, prevChain string, fromSeq int64, keys map[string]string) []wormVerifyFailure {
+	var failures []wormVerifyFailure
+	fail := func(seq int64, reason string) {
+		failures = append(failures, wormVerifyFailure{Seq: seq, Reason: reason})
+	}
+	expectedSeq := fromSeq
+	for _, batch := range batches {
+		if batch.Seq != expectedSeq {
+			fail(expectedSeq, "batch_missing")
+		}
+		expectedSeq = batch.Seq + 1
+		if batch.PrevChainSHA256 != prevChain {
+			fail(batch.Seq, "chain_broken")
+		}
+		prevChain = batch.ChainSHA256
+
+		content, err := store.Get(ctx, batch.ObjectKey, batch.VersionID)
+		if err != nil {
+			fail(batch.Seq, "object_unreadable")
+			continue
+		}
+		sum := sha256.Sum256(content)
+		if hex.EncodeToString(sum[:]) != batch.ContentSHA256 {
+			fail(batch.Seq, "content_mismatch")
+			continue
+		}
+		if wormChainSHA256(batch.Seq, batch.FirstEventID, batch.LastEventID, batch.ContentSHA256, batch.PrevChainSHA256) != batch.ChainSHA256 {
+			fail(batch.Seq, "chain_mismatch")
+			continue
+		}
+		publicKey, ok := keys[batch.KeyID]
+		if !ok {
+			fail(batch.Seq, "unknown_signing_key")
+			continue
+		}
+		if !verifyWormSignature(publicKey, batch.ChainSHA256, batch.Signature) {
+			fail(batch.Seq, "signature_invalid")
+		}
+	}
+	return failures
+}
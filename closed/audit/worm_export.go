@@ -0,0 +1,422 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/env"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	"github.com/minio/minio-go/v7"
+)
+
+const (
+	wormChainVersion      = "animus-audit-worm/v1"
+	wormSignatureEd25519  = "ed25519"
+	wormObjectPrefix      = "audit-events"
+	wormMetadataSeq       = "Animus-Seq"
+	wormMetadataChain     = "Animus-Chain-Sha256"
+	wormMetadataPrevChain = "Animus-Prev-Chain-Sha256"
+	wormMetadataSignature = "Animus-Signature"
+	wormMetadataKeyID     = "Animus-Key-Id"
+)
+
+type wormConfig struct {
+	Enabled       bool
+	Bucket        string
+	Interval      time.Duration
+	BatchSize     int
+	Lag           time.Duration
+	RetentionMode minio.RetentionMode
+	Retention     time.Duration
+	// SigningKeyFile holds a PKCS#8 PEM Ed25519 private key.
+	SigningKeyFile string
+}
+
+func wormConfigFromEnv() (wormConfig, error) {
+	enabled, err := env.Bool("AUDIT_WORM_EXPORT_ENABLED", false)
+	if err != nil {
+		return wormConfig{}, err
+	}
+	interval, err := env.Duration("AUDIT_WORM_EXPORT_INTERVAL", time.Minute)
+	if err != nil {
+		return wormConfig{}, err
+	}
+	batchSize, err := env.Int("AUDIT_WORM_BATCH_SIZE", 1000)
+	if err != nil {
+		return wormConfig{}, err
+	}
+	lag, err := env.Duration("AUDIT_WORM_EXPORT_LAG", 30*time.Second)
+	if err != nil {
+		return wormConfig{}, err
+	}
+	retention, err := env.Duration("AUDIT_WORM_RETENTION", 7*365*24*time.Hour)
+	if err != nil {
+		return wormConfig{}, err
+	}
+	cfg := wormConfig{
+		Enabled:        enabled,
+		Bucket:         strings.TrimSpace(env.String("AUDIT_WORM_BUCKET", "audit-worm")),
+		Interval:       interval,
+		BatchSize:      batchSize,
+		Lag:            lag,
+		RetentionMode:  minio.RetentionMode(strings.ToUpper(strings.TrimSpace(env.String("AUDIT_WORM_RETENTION_MODE", string(minio.Compliance))))),
+		Retention:      retention,
+		SigningKeyFile: strings.TrimSpace(env.String("AUDIT_WORM_SIGNING_KEY_FILE", "")),
+	}
+	return cfg, cfg.Validate()
+}
+
+func (c wormConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.Bucket == "" {
+		return errors.New("AUDIT_WORM_BUCKET is required")
+	}
+	if c.Interval <= 0 {
+		return errors.New("AUDIT_WORM_EXPORT_INTERVAL must be positive")
+	}
+	if c.BatchSize <= 0 || c.BatchSize > 100000 {
+		return errors.New("AUDIT_WORM_BATCH_SIZE must be between 1 and 100000")
+	}
+	if c.Lag < 0 {
+		return errors.New("AUDIT_WORM_EXPORT_LAG must not be negative")
+	}
+	if !c.RetentionMode.IsValid() {
+		return fmt.Errorf("AUDIT_WORM_RETENTION_MODE must be COMPLIANCE or GOVERNANCE (got %q)", c.RetentionMode)
+	}
+	if c.Retention <= 0 {
+		return errors.New("AUDIT_WORM_RETENTION must be positive")
+	}
+	if c.SigningKeyFile == "" {
+		return errors.New("AUDIT_WORM_SIGNING_KEY_FILE is required when AUDIT_WORM_EXPORT_ENABLED is true")
+	}
+	return nil
+}
+
+// wormSigningKey is the public half of the batch signing key.
+type wormSigningKey struct {
+	KeyID        string    `json:"key_id"`
+	Algorithm    string    `json:"algorithm"`
+	PublicKeyPEM string    `json:"public_key_pem"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type wormSigner struct {
+	key     wormSigningKey
+	private ed25519.PrivateKey
+}
+
+func loadWormSigner(path string) (*wormSigner, error) {
+	keyPEM, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read worm signing key: %w", err)
+	}
+	return newWormSigner(keyPEM)
+}
+
+func newWormSigner(keyPEM []byte) (*wormSigner, error) {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return nil, errors.New("worm signing key must be a PKCS#8 PEM private key")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse worm signing key: %w", err)
+	}
+	private, ok := parsed.(ed25519.PrivateKey)
+	if !ok {
+		return nil, errors.New("worm signing key is not an Ed25519 key")
+	}
+	spki, err := x509.MarshalPKIXPublicKey(private.Public())
+	if err != nil {
+		return nil, fmt.Errorf("marshal worm signing public key: %w", err)
+	}
+	sum := sha256.Sum256(spki)
+	return &wormSigner{
+		key: wormSigningKey{
+			KeyID:        hex.EncodeToString(sum[:]),
+			Algorithm:    wormSignatureEd25519,
+			PublicKeyPEM: string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: spki})),
+		},
+		private: private,
+	}, nil
+}
+
+// Sign signs the hex chain hash of a batch.
+func (s *wormSigner) Sign(chainSHA string) string {
+	return base64.RawURLEncoding.EncodeToString(ed25519.Sign(s.private, []byte(chainSHA)))
+}
+
+func verifyWormSignature(publicKeyPEM, chainSHA, signature string) bool {
+	block, _ := pem.Decode([]byte(publicKeyPEM))
+	if block == nil {
+		return false
+	}
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return false
+	}
+	public, ok := parsed.(ed25519.PublicKey)
+	if !ok {
+		return false
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	return ed25519.Verify(public, []byte(chainSHA), sig)
+}
+
+// wormChainSHA256 links a batch to its predecessor. The first batch has an
+// empty prevChain.
+func wormChainSHA256(seq, firstEventID, lastEventID int64, contentSHA, prevChain string) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		wormChainVersion,
+		strconv.FormatInt(seq, 10),
+		strconv.FormatInt(firstEventID, 10),
+		strconv.FormatInt(lastEventID, 10),
+		contentSHA,
+		prevChain,
+	}, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+func wormObjectKey(seq int64, createdAt time.Time) string {
+	return fmt.Sprintf("%s/%s/%012d.ndjson", wormObjectPrefix, createdAt.UTC().Format("2006/01/02"), seq)
+}
+
+// wormObject is a stored batch as the object store reports it.
+type wormObject struct {
+	VersionID string
+	Locked    bool
+}
+
+// wormStore writes batch objects once and reads them back for verification.
+type wormStore interface {
+	Put(ctx context.Context, key string, content []byte, metadata map[string]string, retainUntil time.Time) (wormObject, error)
+	Get(ctx context.Context, key, versionID string) ([]byte, error)
+	Bucket() string
+}
+
+// minioWormStore puts objects with a retention lock when the bucket has
+// object lock enabled, and as plain objects otherwise.
+type minioWormStore struct {
+	client *minio.Client
+	bucket string
+	locked bool
+	mode   minio.RetentionMode
+}
+
+func (s *minioWormStore) Bucket() string { return s.bucket }
+
+func (s *minioWormStore) Put(ctx context.Context, key string, content []byte, metadata map[string]string, retainUntil time.Time) (wormObject, error) {
+	opts := minio.PutObjectOptions{
+		ContentType:    "application/x-ndjson",
+		UserMetadata:   metadata,
+		SendContentMd5: true,
+	}
+	if s.locked {
+		opts.Mode = s.mode
+		opts.RetainUntilDate = retainUntil
+	}
+	info, err := s.client.PutObject(ctx, s.bucket, key, bytes.NewReader(content), int64(len(content)), opts)
+	if err != nil {
+		return wormObject{}, err
+	}
+	return wormObject{VersionID: info.VersionID, Locked: s.locked}, nil
+}
+
+func (s *minioWormStore) Get(ctx context.Context, key, versionID string) ([]byte, error) {
+	obj, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{VersionID: versionID})
+	if err != nil {
+		return nil, err
+	}
+	defer obj.Close()
+	return io.ReadAll(obj)
+}
+
+// wormExporter appends audit events to the object store in signed,
+// hash-chained batches. The state row is locked for the whole batch, so
+// replicas never write the same sequence number. Events are exported once
+// they are Lag old, which leaves time for transactions holding lower event
+// IDs to commit.
+type wormExporter struct {
+	db     *sql.DB
+	logger *slog.Logger
+	store  wormStore
+	signer *wormSigner
+	cfg    wormConfig
+	now    func() time.Time
+}
+
+func (e *wormExporter) run(ctx context.Context) {
+	ticker := time.NewTicker(e.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for {
+				more, err := e.exportOnce(ctx)
+				if err != nil {
+					if e.logger != nil && ctx.Err() == nil {
+						e.logger.Warn("audit worm export failed", "error", err)
+					}
+					break
+				}
+				if !more {
+					break
+				}
+			}
+		}
+	}
+}
+
+func registerWormSigningKey(ctx context.Context, db *sql.DB, signer *wormSigner) error {
+	_, err := db.ExecContext(ctx,
+		`INSERT INTO audit_worm_signing_keys (key_id, algorithm, public_key_pem)
+		 VALUES ($1, $2, $3)
+		 ON CONFLICT (key_id) DO NOTHING`,
+		signer.key.KeyID, signer.key.Algorithm, signer.key.PublicKeyPEM,
+	)
+	if err != nil {
+		return fmt.Errorf("register worm signing key: %w", err)
+	}
+	return nil
+}
+
+// exportOnce writes the next batch. It reports whether a full batch was
+// written, i.e. more events may be waiting.
+func (e *wormExporter) exportOnce(ctx context.Context) (bool, error) {
+	tx, err := e.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var (
+		lastSeq, lastEventID int64
+		prevChain            string
+	)
+	if err := tx.QueryRowContext(ctx,
+		`SELECT last_seq, last_event_id, chain_sha256 FROM audit_worm_state WHERE id = 1 FOR UPDATE`,
+	).Scan(&lastSeq, &lastEventID, &prevChain); err != nil {
+		return false, fmt.Errorf("read worm state: %w", err)
+	}
+
+	now := e.now().UTC()
+	rows, err := tx.QueryContext(ctx,
+		`SELECT event_id, occurred_at, actor, action, resource_type, resource_id, request_id, ip, user_agent, payload, integrity_sha256
+		 FROM audit_events
+		 WHERE event_id > $1 AND occurred_at < $2
+		 ORDER BY event_id
+		 LIMIT $3`,
+		lastEventID, now.Add(-e.cfg.Lag), e.cfg.BatchSize,
+	)
+	if err != nil {
+		return false, err
+	}
+	var (
+		content bytes.Buffer
+		count   int
+		firstID int64
+		lastID  int64
+	)
+	for rows.Next() {
+		var (
+			ev         auditEvent
+			reqID      sql.NullString
+			ip         sql.NullString
+			userAgent  sql.NullString
+			payloadRaw []byte
+		)
+		if err := rows.Scan(&ev.EventID, &ev.OccurredAt, &ev.Actor, &ev.Action, &ev.ResourceType, &ev.ResourceID, &reqID, &ip, &userAgent, &payloadRaw, &ev.IntegritySHA256); err != nil {
+			_ = rows.Close()
+			return false, err
+		}
+		ev.RequestID = strings.TrimSpace(reqID.String)
+		ev.IP = strings.TrimSpace(ip.String)
+		ev.UserAgent = strings.TrimSpace(userAgent.String)
+		ev.Payload = httpapi.NormalizeJSON(payloadRaw)
+		line, err := json.Marshal(ev)
+		if err != nil {
+			_ = rows.Close()
+			return false, err
+		}
+		content.Write(line)
+		content.WriteByte('\n')
+		if count == 0 {
+			firstID = ev.EventID
+		}
+		lastID = ev.EventID
+		count++
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return false, err
+	}
+	if count == 0 {
+		return false, nil
+	}
+
+	seq := lastSeq + 1
+	sum := sha256.Sum256(content.Bytes())
+	contentSHA := hex.EncodeToString(sum[:])
+	chain := wormChainSHA256(seq, firstID, lastID, contentSHA, prevChain)
+	signature := e.signer.Sign(chain)
+	key := wormObjectKey(seq, now)
+	retainUntil := now.Add(e.cfg.Retention)
+
+	obj, err := e.store.Put(ctx, key, content.Bytes(), map[string]string{
+		wormMetadataSeq:       strconv.FormatInt(seq, 10),
+		wormMetadataChain:     chain,
+		wormMetadataPrevChain: prevChain,
+		wormMetadataSignature: signature,
+		wormMetadataKeyID:     e.signer.key.KeyID,
+	}, retainUntil)
+	if err != nil {
+		return false, fmt.Errorf("put worm batch %d: %w", seq, err)
+	}
+	var retainUntilArg any
+	if obj.Locked {
+		retainUntilArg = retainUntil
+	}
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO audit_worm_batches (
+			seq, first_event_id, last_event_id, event_count, bucket, object_key, version_id,
+			content_sha256, prev_chain_sha256, chain_sha256, signature, key_id, object_locked, retain_until, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)`,
+		seq, firstID, lastID, count, e.store.Bucket(), key, obj.VersionID,
+		contentSHA, prevChain, chain, signature, e.signer.key.KeyID, obj.Locked, retainUntilArg, now,
+	); err != nil {
+		return false, err
+	}
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE audit_worm_state SET last_seq = $1, last_event_id = $2, chain_sha256 = $3, updated_at = $4 WHERE id = 1`,
+		seq, lastID, chain, now,
+	); err != nil {
+		return false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+	return count == e.cfg.BatchSize, nil
+}
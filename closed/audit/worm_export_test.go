@@ -0,0 +1,150 @@
+package audit
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"testing"
+	"time"
+)
+
+type memWormStore struct {
+	objects map[string][]byte
+}
+
+func (s *memWormStore) Bucket() string { return "audit-worm" }
+
+func (s *memWormStore) Put(_ context.Context, key string, content []byte, _ map[string]string, _ time.Time) (wormObject, error) {
+	if s.objects == nil {
+		s.objects = map[string][]byte{}
+	}
+	s.objects[key] = append([]byte(nil), content...)
+	return wormObject{Locked: true}, nil
+}
+
+func (s *memWormStore) Get(_ context.Context, key, _ string) ([]byte, error) {
+	content, ok := s.objects[key]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return content, nil
+}
+
+func testWormSigner(t *testing.T) *wormSigner {
+	t.Helper()
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := newWormSigner(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	if err != nil {
+		t.Fatalf("newWormSigner: %v", err)
+	}
+	return signer
+}
+
+// wormFixture writes n chained batches the way the exporter does.
+func wormFixture(t *testing.T, signer *wormSigner, n int) (*memWormStore, []wormBatch) {
+	t.Helper()
+	store := &memWormStore{}
+	created := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	prev := ""
+	var batches []wormBatch
+	for i := 1; i <= n; i++ {
+		seq := int64(i)
+		content := []byte(`{"event_id":` + hex.EncodeToString([]byte{byte(i)}) + "}\n")
+		sum := sha256.Sum256(content)
+		contentSHA := hex.EncodeToString(sum[:])
+		chain := wormChainSHA256(seq, seq*10, seq*10+9, contentSHA, prev)
+		key := wormObjectKey(seq, created)
+		if _, err := store.Put(context.Background(), key, content, nil, created); err != nil {
+			t.Fatal(err)
+		}
+		batches = append(batches, wormBatch{
+			Seq:             seq,
+			FirstEventID:    seq * 10,
+			LastEventID:     seq*10 + 9,
+			ObjectKey:       key,
+			ContentSHA256:   contentSHA,
+			PrevChainSHA256: prev,
+			ChainSHA256:     chain,
+			Signature:       signer.Sign(chain),
+			KeyID:           signer.key.KeyID,
+		})
+		prev = chain
+	}
+	return store, batches
+}
+
+func TestVerifyWormBatches(t *testing.T) {
+	signer := testWormSigner(t)
+	keys := map[string]string{signer.key.KeyID: signer.key.PublicKeyPEM}
+	ctx := context.Background()
+
+	store, batches := wormFixture(t, signer, 3)
+	if failures := verifyWormBatches(ctx, store, batches, "", 1, keys); len(failures) != 0 {
+		t.Fatalf("intact chain: %+v", failures)
+	}
+	if failures := verifyWormBatches(ctx, store, batches[1:], batches[0].ChainSHA256, 2, keys); len(failures) != 0 {
+		t.Fatalf("partial range: %+v", failures)
+	}
+
+	store.objects[batches[1].ObjectKey] = []byte("{\"event_id\":0}\n")
+	failures := verifyWormBatches(ctx, store, batches, "", 1, keys)
+	if len(failures) != 1 || failures[0].Seq != 2 || failures[0].Reason != "content_mismatch" {
+		t.Fatalf("tampered object: %+v", failures)
+	}
+
+	store, batches = wormFixture(t, signer, 3)
+	failures = verifyWormBatches(ctx, store, []wormBatch{batches[0], batches[2]}, "", 1, keys)
+	if len(failures) != 2 || failures[0].Reason != "batch_missing" || failures[1].Reason != "chain_broken" {
+		t.Fatalf("removed batch: %+v", failures)
+	}
+
+	batches[2].Signature = testWormSigner(t).Sign(batches[2].ChainSHA256)
+	failures = verifyWormBatches(ctx, store, batches, "", 1, keys)
+	if len(failures) != 1 || failures[0].Reason != "signature_invalid" {
+		t.Fatalf("foreign signature: %+v", failures)
+	}
+	if failures := verifyWormBatches(ctx, store, batches[:1], "", 1, map[string]string{}); len(failures) != 1 || failures[0].Reason != "unknown_signing_key" {
+		t.Fatalf("unknown key: %+v", failures)
+	}
+}
+
+func TestWormConfigValidate(t *testing.T) {
+	cfg := wormConfig{
+		Enabled:        true,
+		Bucket:         "audit-worm",
+		Interval:       time.Minute,
+		BatchSize:      1000,
+		RetentionMode:  "COMPLIANCE",
+		Retention:      time.Hour,
+		SigningKeyFile: "/etc/animus/worm.pem",
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("valid config: %v", err)
+	}
+	for name, mutate := range map[string]func(*wormConfig){
+		"no key":   func(c *wormConfig) { c.SigningKeyFile = "" },
+		"bad mode": func(c *wormConfig) { c.RetentionMode = "LEGAL" },
+		"no batch": func(c *wormConfig) { c.BatchSize = 0 },
+	} {
+		bad := cfg
+		mutate(&bad)
+		if err := bad.Validate(); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+	if err := (wormConfig{}).Validate(); err != nil {
+		t.Fatalf("disabled config must validate: %v", err)
+	}
+}
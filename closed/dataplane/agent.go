@@ -0,0 +1,672 @@
+package dataplane
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/dataplane"
+	"github.com/animus-labs/animus-go/closed/internal/domain"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/env"
+	"github.com/animus-labs/animus-go/closed/internal/platform/secrets"
+	"github.com/animus-labs/animus-go/closed/internal/runtimeexec"
+	"github.com/google/uuid"
+)
+
+// agentContainerLabel holds the id of the agent that started a container, so
+// agents sharing a Docker daemon only reap their own containers.
+const agentContainerLabel = "animus.agent_id"
+
+// errRunStopped reports that the control plane no longer accepts events for
+// a run, e.g. after it was canceled or timed out.
+var errRunStopped = errors.New("run stopped by control plane")
+
+// agentRuntime is the part of runtimeexec.DockerExecutor the agent uses.
+type agentRuntime interface {
+	Submit(ctx context.Context, spec runtimeexec.JobSpec) error
+	Inspect(ctx context.Context, execution runtimeexec.Execution) (runtimeexec.Observation, error)
+	Cancel(ctx context.Context, execution runtimeexec.Execution) error
+	Logs(ctx context.Context, execution runtimeexec.Execution, since time.Time) ([]byte, error)
+	ReapExited(ctx context.Context, olderThan time.Duration, labels map[string]string, report func(context.Context, runtimeexec.ReapedContainer) error) (int, error)
+}
+
+type agentConfig struct {
+	AgentID           string
+	Labels            []string
+	PollWait          time.Duration
+	PollInterval      time.Duration
+	HeartbeatInterval time.Duration
+	EgressMode        string
+	Concurrency       int
+	// ContainerTTL is how long an exited run container is kept before the
+	// reaper reports and removes it.
+	ContainerTTL time.Duration
+	ReapInterval time.Duration
+	// DatasetCacheDir is the host directory bind-mounted into run
+	// containers as the dataset cache; empty disables it.
+	DatasetCacheDir string
+}
+
+// runAgent executes runs on the local Docker daemon. It only calls out to
+// the control plane, claiming runs assigned to its labels, so it works in
+// networks the control plane cannot reach.
+type runAgent struct {
+	logger  *slog.Logger
+	cp      *controlPlaneClient
+	runtime agentRuntime
+	secrets secrets.Manager
+	cfg     agentConfig
+}
+
+// AgentMain runs the remote run agent.
+func AgentMain() {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	ctx := context.Background()
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	headersAuth, err := auth.NewGatewayHeadersAuthenticator(env.String("ANIMUS_INTERNAL_AUTH_SECRET", ""))
+	if err != nil {
+		logger.Error("invalid internal auth config", "error", err)
+		os.Exit(2)
+	}
+	internalMTLSCfg, err := auth.InternalMTLSConfigFromEnv()
+	if err != nil {
+		logger.Error("invalid internal mtls config", "error", err)
+		os.Exit(2)
+	}
+	internalTransport, err := internalMTLSCfg.Transport()
+	if err != nil {
+		logger.Error("internal mtls init failed", "error", err)
+		os.Exit(2)
+	}
+
+	cpBaseURL := env.String("ANIMUS_CONTROL_PLANE_URL", "")
+	if cpBaseURL == "" {
+		logger.Error("missing control plane url", "env", "ANIMUS_CONTROL_PLANE_URL")
+		os.Exit(2)
+	}
+	agentID := env.String("ANIMUS_AGENT_ID", "")
+	if agentID == "" {
+		agentID, _ = os.Hostname()
+	}
+	if strings.TrimSpace(agentID) == "" {
+		logger.Error("missing agent id", "env", "ANIMUS_AGENT_ID")
+		os.Exit(2)
+	}
+	pollWait, err := env.Duration("ANIMUS_AGENT_POLL_WAIT", 25*time.Second)
+	if err != nil {
+		logger.Error("invalid agent poll wait", "error", err)
+		os.Exit(2)
+	}
+	pollInterval, err := env.Duration("ANIMUS_AGENT_STATUS_POLL_INTERVAL", 5*time.Second)
+	if err != nil {
+		logger.Error("invalid status poll interval", "error", err)
+		os.Exit(2)
+	}
+	heartbeatInterval, err := env.Duration("ANIMUS_AGENT_HEARTBEAT_INTERVAL", 15*time.Second)
+	if err != nil {
+		logger.Error("invalid heartbeat interval", "error", err)
+		os.Exit(2)
+	}
+	concurrency, err := env.Int("ANIMUS_AGENT_CONCURRENCY", 1)
+	if err != nil || concurrency <= 0 {
+		logger.Error("invalid agent concurrency", "error", err)
+		os.Exit(2)
+	}
+	containerTTL, err := env.Duration("ANIMUS_AGENT_CONTAINER_TTL", time.Hour)
+	if err != nil || containerTTL <= 0 {
+		logger.Error("invalid container ttl", "error", err)
+		os.Exit(2)
+	}
+	egressMode, err := normalizeEgressMode(env.String("ANIMUS_DP_EGRESS_MODE", "deny"))
+	if err != nil {
+		logger.Error("invalid dp egress mode", "error", err)
+		os.Exit(2)
+	}
+	datasetCacheDir, err := normalizeDatasetCacheDir(env.String("ANIMUS_DATASET_CACHE_DIR", ""))
+	if err != nil {
+		logger.Error("invalid dataset cache dir", "error", err)
+		os.Exit(2)
+	}
+
+	secretsCfg, err := secrets.ConfigFromEnv()
+	if err != nil {
+		logger.Error("invalid secrets config", "error", err)
+		os.Exit(2)
+	}
+	secretsManager, err := secrets.NewManager(secretsCfg)
+	if err != nil {
+		logger.Error("secrets manager init failed", "error", err)
+		os.Exit(2)
+	}
+	docker, err := runtimeexec.NewDockerExecutor(env.String("ANIMUS_AGENT_DOCKER_BIN", "docker"))
+	if err != nil {
+		logger.Error("docker init failed", "error", err)
+		os.Exit(2)
+	}
+	cpClient, err := newControlPlaneClient(cpBaseURL, headersAuth.Secret, internalTransport)
+	if err != nil {
+		logger.Error("control plane client init failed", "error", err)
+		os.Exit(2)
+	}
+	// The claim call is held open for up to pollWait.
+	cpClient.httpClient.Timeout = pollWait + 10*time.Second
+
+	agent := newRunAgent(logger, cpClient, docker, secretsManager, agentConfig{
+		AgentID:           agentID,
+		Labels:            splitAgentLabels(env.String("ANIMUS_AGENT_LABELS", "")),
+		PollWait:          pollWait,
+		PollInterval:      pollInterval,
+		HeartbeatInterval: heartbeatInterval,
+		EgressMode:        egressMode,
+		Concurrency:       concurrency,
+		ContainerTTL:      containerTTL,
+		DatasetCacheDir:   datasetCacheDir,
+	})
+	logger.Info("agent started", "agent_id", agentID, "labels", agent.cfg.Labels, "concurrency", concurrency)
+	agent.run(ctx)
+}
+
+func newRunAgent(logger *slog.Logger, cp *controlPlaneClient, runtime agentRuntime, secretsManager secrets.Manager, cfg agentConfig) *runAgent {
+	if cfg.PollWait <= 0 {
+		cfg.PollWait = 25 * time.Second
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = 5 * time.Second
+	}
+	if cfg.HeartbeatInterval <= 0 {
+		cfg.HeartbeatInterval = 15 * time.Second
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 1
+	}
+	if cfg.ContainerTTL <= 0 {
+		cfg.ContainerTTL = time.Hour
+	}
+	if cfg.ReapInterval <= 0 {
+		cfg.ReapInterval = time.Minute
+	}
+	return &runAgent{
+		logger:  logger,
+		cp:      cp,
+		runtime: runtime,
+		secrets: secretsManager,
+		cfg:     cfg,
+	}
+}
+
+func splitAgentLabels(raw string) []string {
+	out := []string{}
+	for _, label := range strings.Split(raw, ",") {
+		if label = strings.TrimSpace(label); label != "" {
+			out = append(out, label)
+		}
+	}
+	return out
+}
+
+// run claims and executes runs with cfg.Concurrency workers until ctx ends.
+// Containers still running at shutdown are left alone; the control plane
+// fails their runs once heartbeats stop, and the reaper reports their exit
+// codes once they finish.
+func (a *runAgent) run(ctx context.Context) {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		a.reapLoop(ctx)
+	}()
+	for range a.cfg.Concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.work(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+func (a *runAgent) work(ctx context.Context) {
+	backoff := time.Second
+	for ctx.Err() == nil {
+		req, ok, err := a.cp.ClaimRun(ctx, a.cfg.AgentID, dataplane.AgentClaimRequest{
+			Labels:      a.cfg.Labels,
+			WaitSeconds: int(a.cfg.PollWait / time.Second),
+		}, "")
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			if a.logger != nil {
+				a.logger.Warn("claim run failed", "agent_id", a.cfg.AgentID, "error", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(2*backoff, 30*time.Second)
+			continue
+		}
+		backoff = time.Second
+		if ok {
+			a.execute(ctx, req)
+		}
+	}
+}
+
+// execute starts the claimed run's container and follows it to the end. A
+// run that cannot be started is reported failed; the claim has already
+// accepted it.
+func (a *runAgent) execute(ctx context.Context, req dataplane.RunExecutionRequest) {
+	tracker := &runTracker{
+		RunID:      req.RunID,
+		ProjectID:  req.ProjectID,
+		DispatchID: req.DispatchID,
+		JobName:    jobNameForRun(req.RunID),
+		StartedAt:  time.Now().UTC(),
+	}
+	if a.logger != nil {
+		a.logger.Info("run claimed", "run_id", req.RunID, "dispatch_id", req.DispatchID)
+	}
+	spec, reason := a.prepare(ctx, req, tracker)
+	if reason != "" {
+		a.sendTerminal(ctx, tracker, jobStateFailed, reason, nil, nil)
+		return
+	}
+	spec.Labels = map[string]string{
+		"animus.project_id":  req.ProjectID,
+		"animus.dispatch_id": req.DispatchID,
+		agentContainerLabel:  a.cfg.AgentID,
+	}
+	execution := runtimeexec.Execution{
+		RunID:           req.RunID,
+		Executor:        "docker",
+		DockerContainer: tracker.JobName,
+	}
+	var cacheEntries []dataplane.DatasetCacheEntry
+	if a.cfg.DatasetCacheDir != "" && len(req.Datasets) > 0 {
+		cacheEntries = datasetCacheEntries(a.cfg.DatasetCacheDir, req.Datasets)
+	}
+	if err := a.runtime.Submit(ctx, spec); err != nil {
+		if a.logger != nil {
+			a.logger.Warn("container start failed", "run_id", req.RunID, "error", err)
+		}
+		a.sendTerminal(ctx, tracker, jobStateFailed, "container_start_failed", nil, nil)
+		return
+	}
+	if len(cacheEntries) > 0 {
+		a.reportDatasetCache(ctx, req, cacheEntries)
+	}
+	var deadline time.Time
+	if req.MaxDurationSeconds > 0 {
+		deadline = tracker.StartedAt.Add(time.Duration(req.MaxDurationSeconds) * time.Second)
+	}
+	a.monitor(ctx, tracker, execution, deadline)
+}
+
+// prepare loads the run's reproducibility bundle and builds its container
+// spec with the same checks as the data plane. It returns the failure
+// reason when the run must not start.
+func (a *runAgent) prepare(ctx context.Context, req dataplane.RunExecutionRequest, tracker *runTracker) (runtimeexec.JobSpec, string) {
+	bundle, _, err := a.cp.GetReproBundle(ctx, req.ProjectID, req.RunID, req.CorrelationID)
+	if err != nil {
+		return runtimeexec.JobSpec{}, "repro_bundle_unavailable"
+	}
+	if strings.TrimSpace(bundle.ProjectID) != strings.TrimSpace(req.ProjectID) || strings.TrimSpace(bundle.RunID) != strings.TrimSpace(req.RunID) {
+		return runtimeexec.JobSpec{}, "bundle_mismatch"
+	}
+	runSpec, err := parseRunSpec(bundle.RunSpec)
+	if err != nil {
+		return runtimeexec.JobSpec{}, "invalid_run_spec"
+	}
+	if strings.TrimSpace(runSpec.ProjectID) != strings.TrimSpace(req.ProjectID) {
+		return runtimeexec.JobSpec{}, "project_mismatch"
+	}
+	if err := validateEgressPolicy(a.cfg.EgressMode, runSpec.EnvLock); err != nil {
+		return runtimeexec.JobSpec{}, "network_policy_required"
+	}
+	if err := validateRunCheckpoint(runSpec, req.Checkpoint); err != nil {
+		return runtimeexec.JobSpec{}, "checkpoint_mismatch"
+	}
+	if err := validateRunDatasets(runSpec, req.Datasets); err != nil {
+		return runtimeexec.JobSpec{}, "dataset_mismatch"
+	}
+	tracker.EnvLockID = runSpec.EnvLock.LockID
+	tracker.PolicySHA = runSpec.PolicySnapshot.SnapshotSHA256
+
+	secretEnv, code := leaseRunSecrets(ctx, a.secrets, a.cp, req, runSpec, req.CorrelationID)
+	if code != "" {
+		return runtimeexec.JobSpec{}, code
+	}
+	spec, err := buildAgentJobSpec(runSpec, req.RunID, tracker.JobName, secretEnv, runInputs{
+		Checkpoint: req.Checkpoint,
+		Datasets:   req.Datasets,
+		CacheDir:   a.cfg.DatasetCacheDir,
+		RunToken:   req.RunToken,
+	})
+	if err != nil {
+		return runtimeexec.JobSpec{}, "job_build_failed"
+	}
+	return spec, ""
+}
+
+// buildAgentJobSpec is buildJobSpec for a Docker container. Docker only
+// knows limits, so the env lock's resource limits win over the requests.
+func buildAgentJobSpec(runSpec domain.RunSpec, runID, containerName string, secretEnv map[string]string, inputs runInputs) (runtimeexec.JobSpec, error) {
+	steps := runSpec.PipelineSpec.Spec.Steps
+	if len(steps) != 1 {
+		return runtimeexec.JobSpec{}, errors.New("single step pipeline required")
+	}
+	step := steps[0]
+
+	image := resolveStepImage(step.Image, runSpec.EnvLock.Images)
+	if strings.TrimSpace(image) == "" {
+		return runtimeexec.JobSpec{}, errors.New("image resolution failed")
+	}
+
+	requests := mergeResources(step.Resources, runSpec.EnvLock.ResourceDefaults)
+	limits := runSpec.EnvLock.ResourceLimits
+	resources := map[string]any{}
+	if cpu := firstNonEmpty(limits.CPU, requests.CPU); cpu != "" {
+		resources["cpu"] = cpu
+	}
+	if memory := firstNonEmpty(limits.Memory, requests.Memory); memory != "" {
+		resources["memory"] = memory
+	}
+	if gpus := max(limits.GPU, requests.GPU); gpus > 0 {
+		resources["gpus"] = gpus
+	}
+
+	envVars := buildEnvVars(runSpec, runID, step, secretEnv, inputs)
+	envMap := make(map[string]string, len(envVars))
+	for _, envVar := range envVars {
+		envMap[envVar.Name] = envVar.Value
+	}
+
+	spec := runtimeexec.JobSpec{
+		RunID:      runID,
+		ImageRef:   image,
+		DockerName: containerName,
+		Token:      inputs.RunToken,
+		Resources:  resources,
+		Env:        envMap,
+		Command:    step.Command,
+		Args:       step.Args,
+	}
+	if inputs.CacheDir != "" {
+		spec.Mounts = []runtimeexec.Mount{{Source: inputs.CacheDir, Target: datasetCacheMountPath}}
+	}
+	return spec, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// monitor polls the container until it exits, relaying logs and sending
+// heartbeats. The container is removed when the run ends, when it outlives
+// deadline, or when the control plane rejects its heartbeat.
+func (a *runAgent) monitor(ctx context.Context, tracker *runTracker, execution runtimeexec.Execution, deadline time.Time) {
+	ticker := time.NewTicker(a.cfg.PollInterval)
+	defer ticker.Stop()
+	lastHeartbeat := time.Time{}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		obs, err := a.runtime.Inspect(ctx, execution)
+		if err != nil {
+			if a.logger != nil {
+				a.logger.Warn("container inspect failed", "run_id", tracker.RunID, "error", err)
+			}
+			continue
+		}
+		if obs.Message == "container_not_found" {
+			tracker.missingCount++
+			if tracker.missingCount >= 3 {
+				a.sendTerminal(ctx, tracker, jobStateFailed, "container_not_found", nil, nil)
+				return
+			}
+			continue
+		}
+		tracker.missingCount = 0
+		a.relayLogs(ctx, tracker, execution)
+
+		switch obs.Status {
+		case runtimeexec.StatusSucceeded, runtimeexec.StatusFailed:
+			var finishedAt *time.Time
+			if at, ok := obs.Details["finished_at"].(time.Time); ok && !at.IsZero() {
+				at = at.UTC()
+				finishedAt = &at
+			}
+			var exitCode *int
+			if code, ok := obs.Details["exit_code"].(int); ok {
+				exitCode = &code
+			}
+			a.sendTerminal(ctx, tracker, obs.Status, obs.Message, finishedAt, exitCode)
+			a.removeContainer(ctx, tracker, execution)
+			return
+		}
+
+		if !deadline.IsZero() && !time.Now().Before(deadline) {
+			a.removeContainer(ctx, tracker, execution)
+			a.sendTerminal(ctx, tracker, jobStateFailed, "timeout", nil, nil)
+			return
+		}
+		now := time.Now().UTC()
+		if lastHeartbeat.IsZero() || now.Sub(lastHeartbeat) >= a.cfg.HeartbeatInterval {
+			if err := a.sendHeartbeat(ctx, tracker, obs); errors.Is(err, errRunStopped) {
+				if a.logger != nil {
+					a.logger.Info("run stopped by control plane", "run_id", tracker.RunID)
+				}
+				a.removeContainer(ctx, tracker, execution)
+				return
+			}
+			lastHeartbeat = now
+		}
+	}
+}
+
+func (a *runAgent) removeContainer(ctx context.Context, tracker *runTracker, execution runtimeexec.Execution) {
+	if err := a.runtime.Cancel(ctx, execution); err != nil && a.logger != nil {
+		a.logger.Warn("container remove failed", "run_id", tracker.RunID, "error", err)
+	}
+}
+
+// sendHeartbeat returns errRunStopped when the control plane refuses the
+// heartbeat because the run is already terminal.
+func (a *runAgent) sendHeartbeat(ctx context.Context, tracker *runTracker, obs runtimeexec.Observation) error {
+	_, status, err := a.cp.SendHeartbeat(ctx, dataplane.RunHeartbeat{
+		EventID:   uuid.NewString(),
+		RunID:     tracker.RunID,
+		ProjectID: tracker.ProjectID,
+		EmittedAt: time.Now().UTC(),
+		Details: map[string]any{
+			"job_state": obs.Status,
+			"reason":    obs.Message,
+			"container": tracker.JobName,
+			"agent_id":  a.cfg.AgentID,
+		},
+	}, "")
+	if status == http.StatusConflict || status == http.StatusNotFound {
+		return errRunStopped
+	}
+	return err
+}
+
+// reportDatasetCache records the run's dataset cache hits in the control
+// plane ledger. The report is informational, so failures are only logged.
+func (a *runAgent) reportDatasetCache(ctx context.Context, req dataplane.RunExecutionRequest, entries []dataplane.DatasetCacheEntry) {
+	report := dataplane.DatasetCacheReport{
+		EventID:       datasetCacheEventID(req.RunID, req.ProjectID, req.DispatchID),
+		RunID:         req.RunID,
+		ProjectID:     req.ProjectID,
+		EmittedAt:     time.Now().UTC(),
+		CorrelationID: req.CorrelationID,
+		Node:          a.cfg.AgentID,
+		Entries:       entries,
+	}
+	if _, _, err := a.cp.SendDatasetCacheReport(ctx, report, req.CorrelationID); err != nil && a.logger != nil {
+		a.logger.Warn("dataset cache report failed", "run_id", req.RunID, "error", err)
+	}
+}
+
+// sendTerminal retries until the control plane answers, giving up on client
+// errors such as a run that is already terminal.
+func (a *runAgent) sendTerminal(ctx context.Context, tracker *runTracker, state, reason string, finishedAt *time.Time, exitCode *int) {
+	event := dataplane.RunTerminalState{
+		EventID:    uuid.NewString(),
+		RunID:      tracker.RunID,
+		ProjectID:  tracker.ProjectID,
+		State:      mapJobStateToTerminal(state),
+		FinishedAt: finishedAt,
+		Reason:     strings.TrimSpace(reason),
+		ExitCode:   exitCode,
+		Details: map[string]any{
+			"container":  tracker.JobName,
+			"agent_id":   a.cfg.AgentID,
+			"env_lock":   tracker.EnvLockID,
+			"policy_sha": tracker.PolicySHA,
+		},
+	}
+	for {
+		event.EmittedAt = time.Now().UTC()
+		_, status, err := a.cp.SendTerminal(ctx, event, "")
+		if err == nil || (status >= 400 && status < 500) {
+			return
+		}
+		if a.logger != nil {
+			a.logger.Warn("send terminal failed", "run_id", tracker.RunID, "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
+
+// relayLogs ships container output written since the previous pass, like
+// the data plane does for pods.
+func (a *runAgent) relayLogs(ctx context.Context, tracker *runTracker, execution runtimeexec.Execution) {
+	if tracker.logSince == nil {
+		tracker.logSince = map[string]time.Time{}
+	}
+	since := tracker.logSince[tracker.JobName]
+	raw, err := a.runtime.Logs(ctx, execution, since)
+	if err != nil {
+		if a.logger != nil {
+			a.logger.Warn("read run logs failed", "run_id", tracker.RunID, "error", err)
+		}
+		return
+	}
+	truncated := len(raw) > logFetchMaxBytes
+	if truncated {
+		raw = raw[:logFetchMaxBytes]
+	}
+	for _, chunk := range chunkLogLines(parseLogLines(raw, since, truncated), logChunkMaxBytes) {
+		_, _, err := a.cp.SendLogChunk(ctx, dataplane.RunLogChunk{
+			EventID:     uuid.NewString(),
+			RunID:       tracker.RunID,
+			ProjectID:   tracker.ProjectID,
+			PodName:     tracker.JobName,
+			Container:   runContainerName,
+			Content:     chunk.Content,
+			FirstLineAt: chunk.First,
+			LastLineAt:  chunk.Last,
+			EmittedAt:   time.Now().UTC(),
+		}, "")
+		if err != nil {
+			if a.logger != nil {
+				a.logger.Warn("send run logs failed", "run_id", tracker.RunID, "error", err)
+			}
+			return
+		}
+		tracker.logSince[tracker.JobName] = chunk.Last
+	}
+}
+
+// reapLoop periodically removes exited run containers older than
+// cfg.ContainerTTL. Containers are normally removed by monitor; the reaper
+// catches those left behind by an agent restart and reports their exit
+// codes so the runs do not end as agent_heartbeat_lost.
+func (a *runAgent) reapLoop(ctx context.Context) {
+	ticker := time.NewTicker(a.cfg.ReapInterval)
+	defer ticker.Stop()
+	for {
+		a.reapOnce(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (a *runAgent) reapOnce(ctx context.Context) {
+	reaped, err := a.runtime.ReapExited(ctx, a.cfg.ContainerTTL, map[string]string{agentContainerLabel: a.cfg.AgentID}, a.reportReaped)
+	if err != nil && ctx.Err() == nil && a.logger != nil {
+		a.logger.Warn("container reap failed", "agent_id", a.cfg.AgentID, "error", err)
+	}
+	if reaped > 0 && a.logger != nil {
+		a.logger.Info("containers reaped", "agent_id", a.cfg.AgentID, "count", reaped)
+	}
+}
+
+// reportReaped sends the exit code of a reaped container once. A client
+// error means the run is already terminal and counts as reported.
+func (a *runAgent) reportReaped(ctx context.Context, container runtimeexec.ReapedContainer) error {
+	runID := strings.TrimSpace(container.Execution.RunID)
+	projectID := strings.TrimSpace(container.Labels["animus.project_id"])
+	if runID == "" || projectID == "" {
+		return nil
+	}
+	obs := container.Observation
+	var finishedAt *time.Time
+	if at, ok := obs.Details["finished_at"].(time.Time); ok && !at.IsZero() {
+		at = at.UTC()
+		finishedAt = &at
+	}
+	var exitCode *int
+	if code, ok := obs.Details["exit_code"].(int); ok {
+		exitCode = &code
+	}
+	_, status, err := a.cp.SendTerminal(ctx, dataplane.RunTerminalState{
+		EventID:    uuid.NewString(),
+		RunID:      runID,
+		ProjectID:  projectID,
+		State:      mapJobStateToTerminal(obs.Status),
+		FinishedAt: finishedAt,
+		Reason:     strings.TrimSpace(obs.Message),
+		ExitCode:   exitCode,
+		EmittedAt:  time.Now().UTC(),
+		Details: map[string]any{
+			"container": container.Execution.DockerContainer,
+			"agent_id":  a.cfg.AgentID,
+			"reaped":    true,
+		},
+	}, "")
+	if err != nil && (status < 400 || status >= 500) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+package dataplane
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/dataplane"
+	"github.com/animus-labs/animus-go/closed/internal/domain"
+	"github.com/animus-labs/animus-go/closed/internal/runtimeexec"
+)
+
+type fakeAgentRuntime struct {
+	mu       sync.Mutex
+	status   string
+	canceled bool
+}
+
+func (f *fakeAgentRuntime) Submit(context.Context, runtimeexec.JobSpec) error { return nil }
+
+func (f *fakeAgentRuntime) Inspect(context.Context, runtimeexec.Execution) (runtimeexec.Observation, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return runtimeexec.Observation{Status: f.status, Message: "exited", Details: map[string]any{"exit_code": 3}}, nil
+}
+
+func (f *fakeAgentRuntime) Cancel(context.Context, runtimeexec.Execution) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.canceled = true
+	return nil
+}
+
+func (f *fakeAgentRuntime) Logs(context.Context, runtimeexec.Execution, time.Time) ([]byte, error) {
+	return []byte("2024-01-02T03:04:05.000000001Z epoch 1\n"), nil
+}
+
+func (f *fakeAgentRuntime) ReapExited(ctx context.Context, _ time.Duration, labels map[string]string, report func(context.Context, runtimeexec.ReapedContainer) error) (int, error) {
+	if labels[agentContainerLabel] != "agent-1" {
+		return 0, nil
+	}
+	err := report(ctx, runtimeexec.ReapedContainer{
+		Execution:   runtimeexec.Execution{RunID: "run-1", DockerContainer: "animus-run-run-1"},
+		Observation: runtimeexec.Observation{Status: runtimeexec.StatusSucceeded, Message: "exited", Details: map[string]any{"exit_code": 0}},
+		Labels:      map[string]string{"animus.project_id": "proj-1"},
+	})
+	if err != nil {
+		return 0, err
+	}
+	return 1, nil
+}
+
+func TestBuildAgentJobSpec(t *testing.T) {
+	runSpec := minimalRunSpec("runtime", []domain.EnvironmentImage{
+		{Name: "runtime", Ref: validImageRef, Digest: validDigest},
+	})
+	runSpec.PipelineSpec.Spec.Steps[0].Resources.GPU = 1
+
+	spec, err := buildAgentJobSpec(runSpec, "run-1", "animus-run-run-1", map[string]string{"API_KEY": "k"}, runInputs{})
+	if err != nil {
+		t.Fatalf("buildAgentJobSpec() err=%v", err)
+	}
+	if spec.ImageRef != validImageRef+"@"+validDigest || spec.DockerName != "animus-run-run-1" {
+		t.Fatalf("spec=%+v", spec)
+	}
+	if spec.Resources["cpu"] != "4" || spec.Resources["memory"] != "2Gi" || spec.Resources["gpus"] != 1 {
+		t.Fatalf("resources=%v", spec.Resources)
+	}
+	if spec.Env["ANIMUS_RUN_ID"] != "run-1" || spec.Env["API_KEY"] != "k" || spec.Env["CUSTOM_VAR"] != "ok" {
+		t.Fatalf("env=%v", spec.Env)
+	}
+	if strings.Join(spec.Command, " ") != "/bin/echo" || strings.Join(spec.Args, " ") != "ok" {
+		t.Fatalf("command=%v args=%v", spec.Command, spec.Args)
+	}
+
+	runSpec.PipelineSpec.Spec.Steps[0].Image = "unknown"
+	if _, err := buildAgentJobSpec(runSpec, "run-1", "c", nil, runInputs{}); err == nil {
+		t.Fatalf("expected image resolution error")
+	}
+}
+
+func TestAgentMonitorReportsExitCode(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		terminal dataplane.RunTerminalState
+		logs     []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/internal/cp/runs/run-1/logs":
+			var chunk dataplane.RunLogChunk
+			_ = json.NewDecoder(r.Body).Decode(&chunk)
+			logs = append(logs, chunk.Content)
+		case "/internal/cp/runs/run-1/terminal":
+			_ = json.NewDecoder(r.Body).Decode(&terminal)
+		}
+		_, _ = w.Write([]byte(`{"accepted":true}`))
+	}))
+	defer srv.Close()
+
+	cp, err := newControlPlaneClient(srv.URL, "secret", nil)
+	if err != nil {
+		t.Fatalf("newControlPlaneClient() err=%v", err)
+	}
+	runtime := &fakeAgentRuntime{status: runtimeexec.StatusFailed}
+	agent := newRunAgent(nil, cp, runtime, nil, agentConfig{AgentID: "agent-1", PollInterval: time.Millisecond})
+	tracker := &runTracker{RunID: "run-1", ProjectID: "proj-1", JobName: "animus-run-run-1"}
+	agent.monitor(context.Background(), tracker, runtimeexec.Execution{DockerContainer: tracker.JobName}, time.Time{})
+
+	if terminal.State != "failed" || terminal.ExitCode == nil || *terminal.ExitCode != 3 {
+		t.Fatalf("terminal=%+v", terminal)
+	}
+	if len(logs) != 1 || logs[0] != "epoch 1\n" {
+		t.Fatalf("logs=%q", logs)
+	}
+	if !runtime.canceled {
+		t.Fatalf("expected container removal")
+	}
+}
+
+func TestAgentMonitorStopsCanceledRun(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/heartbeat") {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		_, _ = w.Write([]byte(`{"accepted":true}`))
+	}))
+	defer srv.Close()
+
+	cp, _ := newControlPlaneClient(srv.URL, "secret", nil)
+	runtime := &fakeAgentRuntime{status: runtimeexec.StatusRunning}
+	agent := newRunAgent(nil, cp, runtime, nil, agentConfig{AgentID: "agent-1", PollInterval: time.Millisecond})
+	tracker := &runTracker{RunID: "run-1", ProjectID: "proj-1", JobName: "animus-run-run-1"}
+	agent.monitor(context.Background(), tracker, runtimeexec.Execution{DockerContainer: tracker.JobName}, time.Time{})
+	if !runtime.canceled {
+		t.Fatalf("expected container removal after rejected heartbeat")
+	}
+}
+
+func TestControlPlaneClaimRun(t *testing.T) {
+	claims := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/internal/cp/agents/agent-1/claim" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var req dataplane.AgentClaimRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if len(req.Labels) != 1 || req.Labels[0] != "gpu" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		claims++
+		if claims == 1 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		_, _ = w.Write([]byte(`{"runId":"run-1","projectId":"proj-1","dispatchId":"d-1","maxDurationSeconds":60}`))
+	}))
+	defer srv.Close()
+
+	cp, _ := newControlPlaneClient(srv.URL, "secret", nil)
+	claimReq := dataplane.AgentClaimRequest{Labels: []string{"gpu"}, WaitSeconds: 1}
+	if _, ok, err := cp.ClaimRun(context.Background(), "agent-1", claimReq, ""); ok || err != nil {
+		t.Fatalf("ClaimRun() ok=%v err=%v", ok, err)
+	}
+	req, ok, err := cp.ClaimRun(context.Background(), "agent-1", claimReq, "")
+	if !ok || err != nil || req.RunID != "run-1" || req.MaxDurationSeconds != 60 {
+		t.Fatalf("ClaimRun() req=%+v ok=%v err=%v", req, ok, err)
+	}
+}
+
+func TestAgentReportsReapedContainers(t *testing.T) {
+	var (
+		status   = http.StatusOK
+		terminal dataplane.RunTerminalState
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/internal/cp/runs/run-1/terminal" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&terminal)
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"accepted":true}`))
+	}))
+	defer srv.Close()
+
+	cp, _ := newControlPlaneClient(srv.URL, "secret", nil)
+	agent := newRunAgent(nil, cp, &fakeAgentRuntime{}, nil, agentConfig{AgentID: "agent-1"})
+	reaped, err := agent.runtime.ReapExited(context.Background(), time.Hour, map[string]string{agentContainerLabel: "agent-1"}, agent.reportReaped)
+	if err != nil || reaped != 1 {
+		t.Fatalf("reaped=%d err=%v", reaped, err)
+	}
+	if terminal.State != "succeeded" || terminal.ProjectID != "proj-1" || terminal.ExitCode == nil || *terminal.ExitCode != 0 {
+		t.Fatalf("terminal=%+v", terminal)
+	}
+
+	// An already terminal run counts as reported; a control plane outage
+	// keeps the container for the next pass.
+	status = http.StatusConflict
+	if reaped, err := agent.runtime.ReapExited(context.Background(), time.Hour, map[string]string{agentContainerLabel: "agent-1"}, agent.reportReaped); err != nil || reaped != 1 {
+		t.Fatalf("conflict: reaped=%d err=%v", reaped, err)
+	}
+	status = http.StatusBadGateway
+	if reaped, err := agent.runtime.ReapExited(context.Background(), time.Hour, map[string]string{agentContainerLabel: "agent-1"}, agent.reportReaped); err == nil || reaped != 0 {
+		t.Fatalf("outage: reaped=%d err=%v", reaped, err)
+	}
+}
@@ -1,11 +1,10 @@
-package main
+package dataplane
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
-	"encoding/json"
 	"errors"
-	"io"
 	"log/slog"
 	"net/http"
 	"strconv"
@@ -14,8 +13,11 @@ import (
 	"time"
 
 	"github.com/animus-labs/animus-go/closed/internal/dataplane"
+	"github.com/animus-labs/animus-go/closed/internal/domain"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
 	"github.com/animus-labs/animus-go/closed/internal/platform/k8s"
 	"github.com/animus-labs/animus-go/closed/internal/platform/secrets"
+	"github.com/animus-labs/animus-go/closed/internal/runtimeexec"
 )
 
 type dataplaneConfig struct {
@@ -32,6 +34,12 @@ type dataplaneConfig struct {
 	HeartbeatInterval             time.Duration
 	PollInterval                  time.Duration
 	EgressMode                    string
+	// DatasetCacheDir is the node directory mounted into run pods as the
+	// dataset cache; empty disables it.
+	DatasetCacheDir string
+	// Executor is executorKubernetes or the runtimeexec plugin kind that
+	// runs are launched with.
+	Executor string
 }
 
 type dataplaneAPI struct {
@@ -40,6 +48,9 @@ type dataplaneAPI struct {
 	k8s     *k8s.Client
 	cfg     dataplaneConfig
 	secrets secrets.Manager
+	// plugin launches runs when cfg.Executor is a plugin kind; nil for
+	// Kubernetes Jobs. Dev environments always use Kubernetes.
+	plugin runtimeexec.Plugin
 
 	mu       sync.Mutex
 	trackers map[string]*runTracker
@@ -80,6 +91,7 @@ func newDataplaneAPI(logger *slog.Logger, cp *controlPlaneClient, k8sClient *k8s
 func (api *dataplaneAPI) register(mux *http.ServeMux) {
 	mux.HandleFunc("POST /internal/dp/runs/{run_id}:execute", api.handleExecuteRun)
 	mux.HandleFunc("GET /internal/dp/runs/{run_id}/status", api.handleGetRunStatus)
+	mux.HandleFunc("POST /internal/dp/runs/{run_id}:cancel", api.handleCancelRun)
 	mux.HandleFunc("POST /internal/dp/dev-envs/{dev_env_id}:create", api.handleCreateDevEnv)
 	mux.HandleFunc("POST /internal/dp/dev-envs/{dev_env_id}:delete", api.handleDeleteDevEnv)
 	mux.HandleFunc("POST /internal/dp/dev-envs/{dev_env_id}/access", api.handleAccessDevEnv)
@@ -88,36 +100,48 @@ func (api *dataplaneAPI) register(mux *http.ServeMux) {
 func (api *dataplaneAPI) handleExecuteRun(w http.ResponseWriter, r *http.Request) {
 	runID := strings.TrimSpace(r.PathValue("run_id"))
 	if runID == "" {
-		writeError(w, http.StatusBadRequest, "run_id_required", r.Header.Get("X-Request-Id"))
+		httpapi.WriteError(w, r, http.StatusBadRequest, "run_id_required")
 		return
 	}
 
 	var req dataplane.RunExecutionRequest
-	if err := decodeJSON(r, &req); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_json", r.Header.Get("X-Request-Id"))
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
 		return
 	}
 	if strings.TrimSpace(req.RunID) == "" || strings.TrimSpace(req.ProjectID) == "" || strings.TrimSpace(req.DispatchID) == "" {
-		writeError(w, http.StatusBadRequest, "missing_fields", r.Header.Get("X-Request-Id"))
+		httpapi.WriteError(w, r, http.StatusBadRequest, "missing_fields")
 		return
 	}
 	if !strings.EqualFold(runID, req.RunID) {
-		writeError(w, http.StatusBadRequest, "run_id_mismatch", r.Header.Get("X-Request-Id"))
+		httpapi.WriteError(w, r, http.StatusBadRequest, "run_id_mismatch")
 		return
 	}
 	if req.EmittedAt.IsZero() {
-		writeError(w, http.StatusBadRequest, "emitted_at_required", r.Header.Get("X-Request-Id"))
+		httpapi.WriteError(w, r, http.StatusBadRequest, "emitted_at_required")
 		return
 	}
+	stepName := ""
+	if req.Step != nil {
+		stepName = strings.TrimSpace(req.Step.Name)
+		if stepName == "" || req.Step.Attempt < 1 {
+			httpapi.WriteError(w, r, http.StatusBadRequest, "invalid_step")
+			return
+		}
+	}
 
 	api.mu.Lock()
-	if existing, ok := api.trackers[runID]; ok {
+	if existing, ok := api.trackers[trackerKey(runID, stepName)]; ok {
 		api.mu.Unlock()
 		if existing.DispatchID != req.DispatchID {
-			writeError(w, http.StatusConflict, "dispatch_id_conflict", r.Header.Get("X-Request-Id"))
+			httpapi.WriteError(w, r, http.StatusConflict, "dispatch_id_conflict")
+			return
+		}
+		if req.Step != nil && existing.Attempt != req.Step.Attempt {
+			httpapi.WriteError(w, r, http.StatusConflict, "step_attempt_conflict")
 			return
 		}
-		writeJSON(w, http.StatusOK, dataplane.RunExecutionResponse{
+		httpapi.WriteJSON(w, http.StatusOK, dataplane.RunExecutionResponse{
 			RunID:      runID,
 			ProjectID:  existing.ProjectID,
 			DispatchID: existing.DispatchID,
@@ -132,100 +156,107 @@ func (api *dataplaneAPI) handleExecuteRun(w http.ResponseWriter, r *http.Request
 	bundle, statusCode, err := api.cp.GetReproBundle(r.Context(), req.ProjectID, runID, r.Header.Get("X-Request-Id"))
 	if err != nil {
 		if statusCode == http.StatusNotFound {
-			writeError(w, http.StatusNotFound, "not_found", r.Header.Get("X-Request-Id"))
+			httpapi.WriteError(w, r, http.StatusNotFound, "not_found")
 			return
 		}
-		writeError(w, http.StatusBadGateway, "repro_bundle_unavailable", r.Header.Get("X-Request-Id"))
+		httpapi.WriteError(w, r, http.StatusBadGateway, "repro_bundle_unavailable")
 		return
 	}
 	if strings.TrimSpace(bundle.ProjectID) != strings.TrimSpace(req.ProjectID) || strings.TrimSpace(bundle.RunID) != strings.TrimSpace(runID) {
-		writeError(w, http.StatusConflict, "bundle_mismatch", r.Header.Get("X-Request-Id"))
+		httpapi.WriteError(w, r, http.StatusConflict, "bundle_mismatch")
 		return
 	}
 
 	runSpec, err := parseRunSpec(bundle.RunSpec)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_run_spec", r.Header.Get("X-Request-Id"))
+		httpapi.WriteError(w, r, http.StatusBadRequest, "invalid_run_spec")
 		return
 	}
 	if strings.TrimSpace(runSpec.ProjectID) != strings.TrimSpace(req.ProjectID) {
-		writeError(w, http.StatusConflict, "project_mismatch", r.Header.Get("X-Request-Id"))
+		httpapi.WriteError(w, r, http.StatusConflict, "project_mismatch")
 		return
 	}
 	if err := validateEgressPolicy(api.cfg.EgressMode, runSpec.EnvLock); err != nil {
-		writeError(w, http.StatusUnprocessableEntity, "network_policy_required", r.Header.Get("X-Request-Id"))
+		httpapi.WriteError(w, r, http.StatusUnprocessableEntity, "network_policy_required")
 		return
 	}
 
-	secretEnv := map[string]string{}
-	if api.secrets != nil && strings.TrimSpace(runSpec.EnvLock.SecretAccessClassRef) != "" {
-		lease, err := api.secrets.Fetch(r.Context(), secrets.Request{
-			ProjectID: runSpec.ProjectID,
-			RunID:     runID,
-			Subject:   runSpec.PolicySnapshot.RBAC.Subject,
-			ClassRef:  runSpec.EnvLock.SecretAccessClassRef,
+	if err := validateRunCheckpoint(runSpec, req.Checkpoint); err != nil {
+		httpapi.WriteError(w, r, http.StatusConflict, "checkpoint_mismatch")
+		return
+	}
+	if err := validateRunDatasets(runSpec, req.Datasets); err != nil {
+		httpapi.WriteError(w, r, http.StatusConflict, "dataset_mismatch")
+		return
+	}
+
+	secretEnv, code := leaseRunSecrets(r.Context(), api.secrets, api.cp, req, runSpec, r.Header.Get("X-Request-Id"))
+	if code != "" {
+		httpapi.WriteError(w, r, http.StatusBadGateway, code)
+		return
+	}
+
+	jobName := jobNameForRun(runID)
+	if req.Step != nil {
+		jobName = jobNameForStep(runID, stepName, req.Step.Attempt)
+	}
+	var (
+		namespace string
+		execution *runtimeexec.Execution
+	)
+	if api.plugin != nil {
+		// Plugin backends run the job outside the cluster, so there is no
+		// node dataset cache to mount.
+		spec, err := buildAgentJobSpec(runSpec, runID, jobName, secretEnv, runInputs{
+			Checkpoint: req.Checkpoint,
+			Datasets:   req.Datasets,
+			Step:       req.Step,
+			RunToken:   req.RunToken,
 		})
 		if err != nil {
-			writeError(w, http.StatusBadGateway, "secret_fetch_failed", r.Header.Get("X-Request-Id"))
+			httpapi.WriteError(w, r, http.StatusConflict, "job_build_failed")
 			return
 		}
-		if !lease.ExpiresAt.IsZero() && lease.ExpiresAt.Before(time.Now().UTC()) {
-			writeError(w, http.StatusBadGateway, "secret_lease_expired", r.Header.Get("X-Request-Id"))
+		spec.JobName = jobName
+		// A repeated dispatch, also after a restart, finds the job by its
+		// name instead of launching it twice.
+		existing, err := api.findPluginRun(r.Context(), runID, stepName, jobName)
+		if err != nil {
+			httpapi.WriteError(w, r, http.StatusBadGateway, "job_create_failed")
 			return
 		}
-		for k, v := range lease.Env {
-			secretEnv[k] = v
+		if existing != nil {
+			execution = existing.Execution
+		} else {
+			launched, err := api.plugin.Launch(r.Context(), spec)
+			if err != nil {
+				httpapi.WriteError(w, r, http.StatusBadGateway, "job_create_failed")
+				return
+			}
+			execution = &launched
 		}
-		if lease.LeaseID != "" {
-			secretEnv["ANIMUS_SECRETS_LEASE_ID"] = lease.LeaseID
+	} else {
+		namespace = strings.TrimSpace(api.cfg.Namespace)
+		if namespace == "" {
+			namespace = strings.TrimSpace(api.k8s.Namespace())
 		}
-		if !lease.ExpiresAt.IsZero() {
-			secretEnv["ANIMUS_SECRETS_EXPIRES_AT"] = lease.ExpiresAt.UTC().Format(time.RFC3339)
-		}
-		if api.cp == nil {
-			writeError(w, http.StatusBadGateway, "control_plane_unavailable", r.Header.Get("X-Request-Id"))
+		job, err := buildJobSpec(runSpec, runID, jobName, namespace, api.cfg.JobTTLSeconds, api.cfg.JobServiceAccount, req.DispatchID, req.MaxDurationSeconds, secretEnv, runInputs{
+			Checkpoint: req.Checkpoint,
+			Datasets:   req.Datasets,
+			CacheDir:   api.cfg.DatasetCacheDir,
+			Step:       req.Step,
+			RunToken:   req.RunToken,
+		})
+		if err != nil {
+			httpapi.WriteError(w, r, http.StatusConflict, "job_build_failed")
 			return
 		}
-		secretEvent := dataplane.SecretAccessed{
-			EventID:       secretAccessEventID(runID, req.ProjectID, req.DispatchID, runSpec.EnvLock.SecretAccessClassRef, lease.LeaseID),
-			RunID:         runID,
-			ProjectID:     req.ProjectID,
-			ClassRef:      runSpec.EnvLock.SecretAccessClassRef,
-			LeaseID:       lease.LeaseID,
-			Subject:       runSpec.PolicySnapshot.RBAC.Subject,
-			EmittedAt:     time.Now().UTC(),
-			CorrelationID: req.CorrelationID,
-			Details: map[string]any{
-				"dispatch_id": req.DispatchID,
-			},
-		}
-		if !lease.ExpiresAt.IsZero() {
-			secretEvent.Details["lease_expires_at"] = lease.ExpiresAt.UTC().Format(time.RFC3339)
-		}
-		_, _, err = api.cp.SendSecretAccessed(r.Context(), secretEvent, r.Header.Get("X-Request-Id"))
-		if err != nil {
-			writeError(w, http.StatusBadGateway, "secret_audit_failed", r.Header.Get("X-Request-Id"))
+		if err := api.k8s.CreateJob(r.Context(), namespace, job); err != nil && !errors.Is(err, k8s.ErrAlreadyExists) {
+			httpapi.WriteError(w, r, http.StatusBadGateway, "job_create_failed")
 			return
 		}
 	}
 
-	jobName := jobNameForRun(runID)
-	namespace := strings.TrimSpace(api.cfg.Namespace)
-	if namespace == "" {
-		namespace = strings.TrimSpace(api.k8s.Namespace())
-	}
-
-	job, err := buildJobSpec(runSpec, runID, jobName, namespace, api.cfg.JobTTLSeconds, api.cfg.JobServiceAccount, req.DispatchID, secretEnv)
-	if err != nil {
-		writeError(w, http.StatusConflict, "job_build_failed", r.Header.Get("X-Request-Id"))
-		return
-	}
-
-	if err := api.k8s.CreateJob(r.Context(), namespace, job); err != nil && !errors.Is(err, k8s.ErrAlreadyExists) {
-		writeError(w, http.StatusBadGateway, "job_create_failed", r.Header.Get("X-Request-Id"))
-		return
-	}
-
 	tracker := &runTracker{
 		RunID:      runID,
 		ProjectID:  req.ProjectID,
@@ -235,11 +266,16 @@ func (api *dataplaneAPI) handleExecuteRun(w http.ResponseWriter, r *http.Request
 		EnvLockID:  runSpec.EnvLock.LockID,
 		PolicySHA:  runSpec.PolicySnapshot.SnapshotSHA256,
 		StartedAt:  time.Now().UTC(),
+		StepName:   stepName,
+		Execution:  execution,
+	}
+	if req.Step != nil {
+		tracker.Attempt = req.Step.Attempt
 	}
 	api.addTracker(tracker)
 	go api.monitorRun(tracker)
 
-	writeJSON(w, http.StatusOK, dataplane.RunExecutionResponse{
+	httpapi.WriteJSON(w, http.StatusOK, dataplane.RunExecutionResponse{
 		RunID:      runID,
 		ProjectID:  req.ProjectID,
 		DispatchID: req.DispatchID,
@@ -253,30 +289,57 @@ func (api *dataplaneAPI) handleGetRunStatus(w http.ResponseWriter, r *http.Reque
 	runID := strings.TrimSpace(r.PathValue("run_id"))
 	projectID := strings.TrimSpace(r.URL.Query().Get("project_id"))
 	if runID == "" {
-		writeError(w, http.StatusBadRequest, "run_id_required", r.Header.Get("X-Request-Id"))
+		httpapi.WriteError(w, r, http.StatusBadRequest, "run_id_required")
 		return
 	}
 	if projectID == "" {
-		writeError(w, http.StatusBadRequest, "project_id_required", r.Header.Get("X-Request-Id"))
+		httpapi.WriteError(w, r, http.StatusBadRequest, "project_id_required")
 		return
 	}
 
+	// step and attempt select one attempt of a pipeline step.
 	jobName := jobNameForRun(runID)
-	namespace := strings.TrimSpace(api.cfg.Namespace)
-	if namespace == "" {
-		namespace = strings.TrimSpace(api.k8s.Namespace())
+	stepName := strings.TrimSpace(r.URL.Query().Get("step"))
+	attempt := 0
+	if stepName != "" {
+		var err error
+		attempt, err = strconv.Atoi(r.URL.Query().Get("attempt"))
+		if err != nil || attempt < 1 {
+			httpapi.WriteError(w, r, http.StatusBadRequest, "invalid_step")
+			return
+		}
+		jobName = jobNameForStep(runID, stepName, attempt)
+	}
+	tracker := &runTracker{JobName: jobName}
+	if api.plugin != nil {
+		var err error
+		tracker, err = api.findPluginRun(r.Context(), runID, stepName, jobName)
+		if err != nil {
+			httpapi.WriteError(w, r, http.StatusBadGateway, "job_status_failed")
+			return
+		}
+		if tracker == nil {
+			httpapi.WriteError(w, r, http.StatusNotFound, "not_found")
+			return
+		}
+	} else {
+		tracker.Namespace = strings.TrimSpace(api.cfg.Namespace)
+		if tracker.Namespace == "" {
+			tracker.Namespace = strings.TrimSpace(api.k8s.Namespace())
+		}
 	}
-	status, err := inspectJob(r.Context(), api.k8s, namespace, jobName)
+	namespace := tracker.Namespace
+	status, err := api.inspectRun(r.Context(), tracker)
 	if err != nil {
 		if errors.Is(err, errJobNotFound) {
-			writeError(w, http.StatusNotFound, "not_found", r.Header.Get("X-Request-Id"))
+			httpapi.WriteError(w, r, http.StatusNotFound, "not_found")
 			return
 		}
-		writeError(w, http.StatusBadGateway, "job_status_failed", r.Header.Get("X-Request-Id"))
+		httpapi.WriteError(w, r, http.StatusBadGateway, "job_status_failed")
 		return
 	}
 
-	writeJSON(w, http.StatusOK, dataplane.RunExecutionStatus{
+	httpapi.WriteJSON(w, http.StatusOK, dataplane.RunExecutionStatus{
 		RunID:      runID,
 		ProjectID:  projectID,
 		State:      status.State,
@@ -285,19 +348,119 @@ func (api *dataplaneAPI) handleGetRunStatus(w http.ResponseWriter, r *http.Reque
 		StartedAt:  status.StartedAt,
 		FinishedAt: status.FinishedAt,
 		Reason:     status.Reason,
+		StepName:   stepName,
+		Attempt:    attempt,
 	})
 }
 
+// handleCancelRun deletes the run's Job together with its pods and stops
+// monitoring it; the control plane records the terminal state itself.
+func (api *dataplaneAPI) handleCancelRun(w http.ResponseWriter, r *http.Request) {
+	runID := strings.TrimSpace(r.PathValue("run_id"))
+	if runID == "" {
+		httpapi.WriteError(w, r, http.StatusBadRequest, "run_id_required")
+		return
+	}
+
+	var req dataplane.RunCancelRequest
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
+		return
+	}
+	if strings.TrimSpace(req.RunID) == "" || strings.TrimSpace(req.ProjectID) == "" {
+		httpapi.WriteError(w, r, http.StatusBadRequest, "missing_fields")
+		return
+	}
+	if !strings.EqualFold(runID, req.RunID) {
+		httpapi.WriteError(w, r, http.StatusBadRequest, "run_id_mismatch")
+		return
+	}
+	if req.EmittedAt.IsZero() {
+		httpapi.WriteError(w, r, http.StatusBadRequest, "emitted_at_required")
+		return
+	}
+
+	api.mu.Lock()
+	tracker := api.trackers[runID]
+	api.mu.Unlock()
+	if tracker != nil && strings.TrimSpace(req.DispatchID) != "" && tracker.DispatchID != req.DispatchID {
+		httpapi.WriteError(w, r, http.StatusConflict, "dispatch_id_conflict")
+		return
+	}
+
+	if api.plugin != nil {
+		api.cancelPluginRun(w, r, req, tracker)
+		return
+	}
+
+	jobName := jobNameForRun(runID)
+	namespace := strings.TrimSpace(api.cfg.Namespace)
+	if namespace == "" {
+		namespace = strings.TrimSpace(api.k8s.Namespace())
+	}
+	// Steps of a multi-step run have a Job per attempt; delete the tracked
+	// ones before the run's own Job.
+	for _, step := range api.stepTrackers(runID) {
+		if err := api.k8s.DeleteJob(r.Context(), step.Namespace, step.JobName); err != nil && !errors.Is(err, k8s.ErrNotFound) {
+			httpapi.WriteError(w, r, http.StatusBadGateway, "job_delete_failed")
+			return
+		}
+		api.removeTracker(step.key())
+	}
+	err := api.k8s.DeleteJob(r.Context(), namespace, jobName)
+	if err != nil && !errors.Is(err, k8s.ErrNotFound) {
+		httpapi.WriteError(w, r, http.StatusBadGateway, "job_delete_failed")
+		return
+	}
+	api.removeTracker(runID)
+	if api.logger != nil {
+		api.logger.Info("run canceled", "run_id", runID, "dispatch_id", req.DispatchID, "reason", req.Reason, "job_found", err == nil)
+	}
+
+	resp := dataplane.RunCancelResponse{
+		RunID:     runID,
+		ProjectID: req.ProjectID,
+		Canceled:  err == nil,
+		JobName:   jobName,
+		Namespace: namespace,
+	}
+	if err != nil {
+		resp.Message = "job_not_found"
+	}
+	httpapi.WriteJSON(w, http.StatusOK, resp)
+}
+
 func (api *dataplaneAPI) addTracker(tracker *runTracker) {
 	api.mu.Lock()
 	defer api.mu.Unlock()
-	api.trackers[tracker.RunID] = tracker
+	api.trackers[tracker.key()] = tracker
+}
+
+func (api *dataplaneAPI) removeTracker(key string) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	delete(api.trackers, key)
+}
+
+// stepTrackers returns the trackers of the steps of runID in flight.
+func (api *dataplaneAPI) stepTrackers(runID string) []*runTracker {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	var out []*runTracker
+	for _, tracker := range api.trackers {
+		if tracker.RunID == runID && tracker.StepName != "" {
+			out = append(out, tracker)
+		}
+	}
+	return out
 }
 
-func (api *dataplaneAPI) removeTracker(runID string) {
+// tracking reports whether tracker is still registered, i.e. the run has
+// not been canceled.
+func (api *dataplaneAPI) tracking(tracker *runTracker) bool {
 	api.mu.Lock()
 	defer api.mu.Unlock()
-	delete(api.trackers, runID)
+	return api.trackers[tracker.key()] == tracker
 }
 
 func secretAccessEventID(runID, projectID, dispatchID, classRef, leaseID string) string {
@@ -312,29 +475,56 @@ func secretAccessEventID(runID, projectID, dispatchID, classRef, leaseID string)
 	return "secret-access-" + hex.EncodeToString(sum[:])
 }
 
-func writeJSON(w http.ResponseWriter, status int, body any) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	enc := json.NewEncoder(w)
-	enc.SetEscapeHTML(true)
-	_ = enc.Encode(body)
-}
-
-func writeError(w http.ResponseWriter, status int, code string, requestID string) {
-	writeJSON(w, status, map[string]any{
-		"error":      code,
-		"request_id": requestID,
+// leaseRunSecrets fetches the secrets of runSpec's secret access class and
+// audits the access with the control plane. On failure it returns the error
+// code to report; the run must not start then.
+func leaseRunSecrets(ctx context.Context, manager secrets.Manager, cp *controlPlaneClient, req dataplane.RunExecutionRequest, runSpec domain.RunSpec, requestID string) (map[string]string, string) {
+	secretEnv := map[string]string{}
+	if manager == nil || strings.TrimSpace(runSpec.EnvLock.SecretAccessClassRef) == "" {
+		return secretEnv, ""
+	}
+	lease, err := manager.Fetch(ctx, secrets.Request{
+		ProjectID: runSpec.ProjectID,
+		RunID:     req.RunID,
+		Subject:   runSpec.PolicySnapshot.RBAC.Subject,
+		ClassRef:  runSpec.EnvLock.SecretAccessClassRef,
 	})
-}
-
-func decodeJSON(r *http.Request, dst any) error {
-	dec := json.NewDecoder(io.LimitReader(r.Body, 1<<20))
-	dec.DisallowUnknownFields()
-	if err := dec.Decode(dst); err != nil {
-		return err
+	if err != nil {
+		return nil, "secret_fetch_failed"
+	}
+	if !lease.ExpiresAt.IsZero() && lease.ExpiresAt.Before(time.Now().UTC()) {
+		return nil, "secret_lease_expired"
+	}
+	for k, v := range lease.Env {
+		secretEnv[k] = v
+	}
+	if lease.LeaseID != "" {
+		secretEnv["ANIMUS_SECRETS_LEASE_ID"] = lease.LeaseID
+	}
+	if !lease.ExpiresAt.IsZero() {
+		secretEnv["ANIMUS_SECRETS_EXPIRES_AT"] = lease.ExpiresAt.UTC().Format(time.RFC3339)
+	}
+	if cp == nil {
+		return nil, "control_plane_unavailable"
+	}
+	secretEvent := dataplane.SecretAccessed{
+		EventID:       secretAccessEventID(req.RunID, req.ProjectID, req.DispatchID, runSpec.EnvLock.SecretAccessClassRef, lease.LeaseID),
+		RunID:         req.RunID,
+		ProjectID:     req.ProjectID,
+		ClassRef:      runSpec.EnvLock.SecretAccessClassRef,
+		LeaseID:       lease.LeaseID,
+		Subject:       runSpec.PolicySnapshot.RBAC.Subject,
+		EmittedAt:     time.Now().UTC(),
+		CorrelationID: req.CorrelationID,
+		Details: map[string]any{
+			"dispatch_id": req.DispatchID,
+		},
+	}
+	if !lease.ExpiresAt.IsZero() {
+		secretEvent.Details["lease_expires_at"] = lease.ExpiresAt.UTC().Format(time.RFC3339)
 	}
-	if err := dec.Decode(&struct{}{}); err != io.EOF {
-		return errors.New("multiple JSON values")
+	if _, _, err := cp.SendSecretAccessed(ctx, secretEvent, requestID); err != nil {
+		return nil, "secret_audit_failed"
 	}
-	return nil
+	return secretEnv, ""
 }
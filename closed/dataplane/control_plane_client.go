@@ -1,4 +1,4 @@
-package main
+package dataplane
 
 import (
 	"bytes"
@@ -26,7 +26,7 @@ type controlPlaneClient struct {
 	httpClient *http.Client
 }
 
-func newControlPlaneClient(baseURL, secret string) (*controlPlaneClient, error) {
+func newControlPlaneClient(baseURL, secret string, transport http.RoundTripper) (*controlPlaneClient, error) {
 	baseURL = strings.TrimRight(strings.TrimSpace(baseURL), "/")
 	if baseURL == "" {
 		return nil, errors.New("control plane base url is required")
@@ -38,7 +38,8 @@ func newControlPlaneClient(baseURL, secret string) (*controlPlaneClient, error)
 		baseURL: baseURL,
 		secret:  secret,
 		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
+			Timeout:   10 * time.Second,
+			Transport: transport,
 		},
 	}, nil
 }
@@ -89,6 +90,32 @@ func (c *controlPlaneClient) SendSecretAccessed(ctx context.Context, event datap
 	return out, status, err
 }
 
+func (c *controlPlaneClient) SendDatasetCacheReport(ctx context.Context, report dataplane.DatasetCacheReport, requestID string) (dataplane.DatasetCacheReportResponse, int, error) {
+	path := fmt.Sprintf("/internal/cp/runs/%s/dataset-cache", strings.TrimSpace(report.RunID))
+	var out dataplane.DatasetCacheReportResponse
+	status, err := c.postJSON(ctx, path, report, requestID, &out)
+	return out, status, err
+}
+
+func (c *controlPlaneClient) SendLogChunk(ctx context.Context, chunk dataplane.RunLogChunk, requestID string) (dataplane.RunLogChunkResponse, int, error) {
+	path := fmt.Sprintf("/internal/cp/runs/%s/logs", strings.TrimSpace(chunk.RunID))
+	var out dataplane.RunLogChunkResponse
+	status, err := c.postJSON(ctx, path, chunk, requestID, &out)
+	return out, status, err
+}
+
+// ClaimRun long-polls for a run assigned to agents with req.Labels. ok is
+// false when the wait ended without one.
+func (c *controlPlaneClient) ClaimRun(ctx context.Context, agentID string, req dataplane.AgentClaimRequest, requestID string) (dataplane.RunExecutionRequest, bool, error) {
+	path := fmt.Sprintf("/internal/cp/agents/%s/claim", strings.TrimSpace(agentID))
+	var out dataplane.RunExecutionRequest
+	status, err := c.postJSON(ctx, path, req, requestID, &out)
+	if err != nil || status == http.StatusNoContent {
+		return dataplane.RunExecutionRequest{}, false, err
+	}
+	return out, true, nil
+}
+
 func (c *controlPlaneClient) postJSON(ctx context.Context, path string, payload any, requestID string, out any) (int, error) {
 	body, err := json.Marshal(payload)
 	if err != nil {
@@ -138,7 +165,7 @@ func (c *controlPlaneClient) doJSON(req *http.Request, requestID string, out any
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		return resp.StatusCode, fmt.Errorf("control plane error status: %d", resp.StatusCode)
 	}
-	if out == nil {
+	if out == nil || resp.StatusCode == http.StatusNoContent {
 		return resp.StatusCode, nil
 	}
 	dec := json.NewDecoder(resp.Body)
@@ -0,0 +1,131 @@
+package dataplane
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/animus-labs/animus-go/closed/internal/dataplane"
+	"github.com/animus-labs/animus-go/closed/internal/domain"
+)
+
+// datasetCacheMountPath is where the node-local dataset cache appears inside
+// run containers.
+const datasetCacheMountPath = "/animus/dataset-cache"
+
+const datasetCacheVolumeName = "dataset-cache"
+
+var datasetCacheKeyPattern = regexp.MustCompile(`^sha256/[0-9a-f]{64}$`)
+
+// errDatasetMismatch reports that the execution request's datasets do not
+// match the run spec's dataset bindings.
+var errDatasetMismatch = errors.New("dataset mismatch")
+
+// normalizeDatasetCacheDir checks ANIMUS_DATASET_CACHE_DIR: empty or an
+// absolute host path without commas, which Docker mount specs cannot hold.
+func normalizeDatasetCacheDir(raw string) (string, error) {
+	dir := strings.TrimSpace(raw)
+	if dir == "" {
+		return "", nil
+	}
+	if !filepath.IsAbs(dir) || strings.Contains(dir, ",") {
+		return "", errors.New("dataset cache dir must be an absolute path")
+	}
+	return filepath.Clean(dir), nil
+}
+
+// runInputs is what a run container receives besides its run spec.
+type runInputs struct {
+	Checkpoint *dataplane.RunCheckpoint
+	Datasets   []dataplane.RunDataset
+	// CacheDir is the host directory of the node-local dataset cache; empty
+	// disables the cache.
+	CacheDir string
+	// Step is the pipeline step attempt to run; nil runs the only step.
+	Step *dataplane.RunStep
+	// RunToken is set as ANIMUS_RUN_TOKEN.
+	RunToken string
+}
+
+// validateRunDatasets checks that every dataset of the request is the
+// version bound under its binding name and carries a well-formed cache key.
+// Bindings without a dataset, such as encrypted versions, are fetched by
+// the run itself from ANIMUS_DATASET_BINDINGS.
+func validateRunDatasets(runSpec domain.RunSpec, datasets []dataplane.RunDataset) error {
+	seen := make(map[string]struct{}, len(datasets))
+	for _, dataset := range datasets {
+		versionID, ok := runSpec.DatasetBindings[dataset.Binding]
+		if !ok || versionID != dataset.VersionID || strings.TrimSpace(dataset.URL) == "" {
+			return errDatasetMismatch
+		}
+		if _, dup := seen[dataset.Binding]; dup {
+			return errDatasetMismatch
+		}
+		seen[dataset.Binding] = struct{}{}
+		if !datasetCacheKeyPattern.MatchString(dataset.CacheKey) || dataset.CacheKey != "sha256/"+strings.ToLower(dataset.SHA256) {
+			return errDatasetMismatch
+		}
+	}
+	return nil
+}
+
+type runDatasetEnv struct {
+	Name      string `json:"name"`
+	VersionID string `json:"versionId"`
+	SHA256    string `json:"sha256"`
+	URL       string `json:"url"`
+	CachePath string `json:"cachePath,omitempty"`
+}
+
+// datasetsEnvValue is the ANIMUS_DATASETS value: the run's datasets with
+// their download URLs and, when the cache is mounted, the path the content
+// is cached under inside the container.
+func datasetsEnvValue(datasets []dataplane.RunDataset, cached bool) string {
+	out := make([]runDatasetEnv, 0, len(datasets))
+	for _, dataset := range datasets {
+		item := runDatasetEnv{
+			Name:      dataset.Binding,
+			VersionID: dataset.VersionID,
+			SHA256:    dataset.SHA256,
+			URL:       dataset.URL,
+		}
+		if cached {
+			item.CachePath = path.Join(datasetCacheMountPath, dataset.CacheKey)
+		}
+		out = append(out, item)
+	}
+	raw, _ := json.Marshal(out)
+	return string(raw)
+}
+
+// datasetCacheEntries reports for each dataset whether its content is
+// already present in the cache directory on this node.
+func datasetCacheEntries(cacheDir string, datasets []dataplane.RunDataset) []dataplane.DatasetCacheEntry {
+	out := make([]dataplane.DatasetCacheEntry, 0, len(datasets))
+	for _, dataset := range datasets {
+		_, err := os.Stat(filepath.Join(cacheDir, filepath.FromSlash(dataset.CacheKey)))
+		out = append(out, dataplane.DatasetCacheEntry{
+			VersionID: dataset.VersionID,
+			SHA256:    dataset.SHA256,
+			Hit:       err == nil,
+			SizeBytes: dataset.SizeBytes,
+		})
+	}
+	return out
+}
+
+func datasetCacheEventID(runID, projectID, dispatchID string) string {
+	parts := []string{
+		strings.TrimSpace(runID),
+		strings.TrimSpace(projectID),
+		strings.TrimSpace(dispatchID),
+	}
+	sum := sha256.Sum256([]byte(strings.Join(parts, "|")))
+	return "dataset-cache-" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,75 @@
+package dataplane
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/animus-labs/animus-go/closed/internal/dataplane"
+)
+
+func TestDatasetCacheInputs(t *testing.T) {
+	runSpec := minimalRunSpec("ghcr.io/acme/train@"+validDigest, nil)
+	runSpec.DatasetBindings = map[string]string{"train": "dv-1"}
+	sha := strings.Repeat("b", 64)
+	datasets := []dataplane.RunDataset{{Binding: "train", VersionID: "dv-1", SHA256: sha, URL: "https://minio/dv-1?sig", CacheKey: "sha256/" + sha}}
+	if err := validateRunDatasets(runSpec, datasets); err != nil {
+		t.Fatalf("validateRunDatasets() err=%v", err)
+	}
+	if err := validateRunDatasets(runSpec, nil); err != nil {
+		t.Fatalf("expected request without datasets to be accepted, err=%v", err)
+	}
+	for _, bad := range []dataplane.RunDataset{
+		{Binding: "train", VersionID: "dv-2", SHA256: sha, URL: "https://minio/x", CacheKey: "sha256/" + sha},
+		{Binding: "train", VersionID: "dv-1", SHA256: sha, URL: "https://minio/x", CacheKey: "sha256/../etc"},
+		{Binding: "train", VersionID: "dv-1", SHA256: sha, CacheKey: "sha256/" + sha},
+	} {
+		if err := validateRunDatasets(runSpec, []dataplane.RunDataset{bad}); err == nil {
+			t.Fatalf("expected %+v to be rejected", bad)
+		}
+	}
+
+	job, err := buildJobSpec(runSpec, "run-1", "job-1", "", 0, "", "dispatch-1", 0, nil, runInputs{Datasets: datasets, CacheDir: "/var/cache/animus"})
+	if err != nil {
+		t.Fatalf("buildJobSpec() err=%v", err)
+	}
+	pod := job.Spec.Template.Spec
+	if len(pod.Volumes) != 1 || pod.Volumes[0].HostPath == nil || pod.Volumes[0].HostPath.Path != "/var/cache/animus" {
+		t.Fatalf("volumes=%+v", pod.Volumes)
+	}
+	env := map[string]string{}
+	for _, v := range pod.Containers[0].Env {
+		env[v.Name] = v.Value
+	}
+	if env["ANIMUS_DATASET_CACHE_DIR"] != datasetCacheMountPath || !strings.Contains(env["ANIMUS_DATASETS"], `"cachePath":"/animus/dataset-cache/sha256/`+sha+`"`) {
+		t.Fatalf("env=%v", env)
+	}
+
+	spec, err := buildAgentJobSpec(runSpec, "run-1", "c", nil, runInputs{Datasets: datasets})
+	if err != nil || len(spec.Mounts) != 0 || strings.Contains(spec.Env["ANIMUS_DATASETS"], "cachePath") {
+		t.Fatalf("uncached spec=%+v err=%v", spec, err)
+	}
+}
+
+func TestDatasetCacheEntries(t *testing.T) {
+	dir := t.TempDir()
+	hit, miss := strings.Repeat("c", 64), strings.Repeat("d", 64)
+	if err := os.MkdirAll(filepath.Join(dir, "sha256", hit), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	entries := datasetCacheEntries(dir, []dataplane.RunDataset{
+		{VersionID: "dv-1", SHA256: hit, CacheKey: "sha256/" + hit},
+		{VersionID: "dv-2", SHA256: miss, CacheKey: "sha256/" + miss},
+	})
+	if len(entries) != 2 || !entries[0].Hit || entries[1].Hit {
+		t.Fatalf("entries=%+v", entries)
+	}
+
+	if _, err := normalizeDatasetCacheDir("cache"); err == nil {
+		t.Fatalf("expected relative cache dir to be rejected")
+	}
+	if dir, err := normalizeDatasetCacheDir(" /var/cache/animus/ "); err != nil || dir != "/var/cache/animus" {
+		t.Fatalf("dir=%q err=%v", dir, err)
+	}
+}
@@ -1,4 +1,4 @@
-package main
+package dataplane
 
 import (
 	"crypto/sha256"
@@ -10,6 +10,7 @@ import (
 
 	"github.com/animus-labs/animus-go/closed/internal/dataplane"
 	"github.com/animus-labs/animus-go/closed/internal/domain"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
 	"github.com/animus-labs/animus-go/closed/internal/platform/k8s"
 )
 
@@ -18,33 +19,33 @@ const devEnvTTLMinimumSeconds int64 = 60
 func (api *dataplaneAPI) handleCreateDevEnv(w http.ResponseWriter, r *http.Request) {
 	devEnvID := strings.TrimSpace(r.PathValue("dev_env_id"))
 	if devEnvID == "" {
-		writeError(w, http.StatusBadRequest, "dev_env_id_required", r.Header.Get("X-Request-Id"))
+		httpapi.WriteError(w, r, http.StatusBadRequest, "dev_env_id_required")
 		return
 	}
 
 	var req dataplane.DevEnvProvisionRequest
-	if err := decodeJSON(r, &req); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_json", r.Header.Get("X-Request-Id"))
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
 		return
 	}
 	if strings.TrimSpace(req.DevEnvID) == "" || strings.TrimSpace(req.ProjectID) == "" || strings.TrimSpace(req.TemplateRef) == "" {
-		writeError(w, http.StatusBadRequest, "missing_fields", r.Header.Get("X-Request-Id"))
+		httpapi.WriteError(w, r, http.StatusBadRequest, "missing_fields")
 		return
 	}
 	if req.DevEnvID != devEnvID {
-		writeError(w, http.StatusBadRequest, "dev_env_id_mismatch", r.Header.Get("X-Request-Id"))
+		httpapi.WriteError(w, r, http.StatusBadRequest, "dev_env_id_mismatch")
 		return
 	}
 	if req.EmittedAt.IsZero() {
-		writeError(w, http.StatusBadRequest, "emitted_at_required", r.Header.Get("X-Request-Id"))
+		httpapi.WriteError(w, r, http.StatusBadRequest, "emitted_at_required")
 		return
 	}
 	if strings.TrimSpace(req.ImageRef) == "" {
-		writeError(w, http.StatusBadRequest, "image_ref_required", r.Header.Get("X-Request-Id"))
+		httpapi.WriteError(w, r, http.StatusBadRequest, "image_ref_required")
 		return
 	}
 	if req.TTLSeconds < devEnvTTLMinimumSeconds {
-		writeError(w, http.StatusBadRequest, "ttl_seconds_too_small", r.Header.Get("X-Request-Id"))
+		httpapi.WriteError(w, r, http.StatusBadRequest, "ttl_seconds_too_small")
 		return
 	}
 	repoURL := strings.TrimSpace(req.RepoURL)
@@ -52,17 +53,17 @@ func (api *dataplaneAPI) handleCreateDevEnv(w http.ResponseWriter, r *http.Reque
 	refValue := strings.TrimSpace(req.RefValue)
 	if repoURL != "" {
 		if refType == "" || refValue == "" {
-			writeError(w, http.StatusBadRequest, "repo_ref_required", r.Header.Get("X-Request-Id"))
+			httpapi.WriteError(w, r, http.StatusBadRequest, "repo_ref_required")
 			return
 		}
 		if !validDevEnvRefType(refType) {
-			writeError(w, http.StatusBadRequest, "invalid_ref_type", r.Header.Get("X-Request-Id"))
+			httpapi.WriteError(w, r, http.StatusBadRequest, "invalid_ref_type")
 			return
 		}
 	}
 
 	if err := validateEgressPolicy(api.cfg.EgressMode, domain.EnvLock{NetworkClassRef: req.NetworkClassRef}); err != nil {
-		writeError(w, http.StatusUnprocessableEntity, "network_policy_required", r.Header.Get("X-Request-Id"))
+		httpapi.WriteError(w, r, http.StatusUnprocessableEntity, "network_policy_required")
 		return
 	}
 
@@ -81,19 +82,19 @@ func (api *dataplaneAPI) handleCreateDevEnv(w http.ResponseWriter, r *http.Reque
 		api.cfg.DevEnvCodeServerPort,
 	)
 	if err != nil {
-		writeError(w, http.StatusConflict, "devenv_job_build_failed", r.Header.Get("X-Request-Id"))
+		httpapi.WriteError(w, r, http.StatusConflict, "devenv_job_build_failed")
 		return
 	}
 
 	service, err := buildDevEnvServiceSpec(req, jobName, namespace, api.cfg.DevEnvCodeServerPort)
 	if err != nil {
-		writeError(w, http.StatusConflict, "devenv_service_build_failed", r.Header.Get("X-Request-Id"))
+		httpapi.WriteError(w, r, http.StatusConflict, "devenv_service_build_failed")
 		return
 	}
 	serviceCreated := false
 	if err := api.k8s.CreateService(r.Context(), namespace, service); err != nil {
 		if !errors.Is(err, k8s.ErrAlreadyExists) {
-			writeError(w, http.StatusBadGateway, "devenv_service_create_failed", r.Header.Get("X-Request-Id"))
+			httpapi.WriteError(w, r, http.StatusBadGateway, "devenv_service_create_failed")
 			return
 		}
 	} else {
@@ -104,11 +105,11 @@ func (api *dataplaneAPI) handleCreateDevEnv(w http.ResponseWriter, r *http.Reque
 		if serviceCreated {
 			_ = api.k8s.DeleteService(r.Context(), namespace, jobName)
 		}
-		writeError(w, http.StatusBadGateway, "devenv_job_create_failed", r.Header.Get("X-Request-Id"))
+		httpapi.WriteError(w, r, http.StatusBadGateway, "devenv_job_create_failed")
 		return
 	}
 
-	writeJSON(w, http.StatusOK, dataplane.DevEnvProvisionResponse{
+	httpapi.WriteJSON(w, http.StatusOK, dataplane.DevEnvProvisionResponse{
 		DevEnvID:  devEnvID,
 		ProjectID: req.ProjectID,
 		Accepted:  true,
@@ -120,25 +121,25 @@ func (api *dataplaneAPI) handleCreateDevEnv(w http.ResponseWriter, r *http.Reque
 func (api *dataplaneAPI) handleDeleteDevEnv(w http.ResponseWriter, r *http.Request) {
 	devEnvID := strings.TrimSpace(r.PathValue("dev_env_id"))
 	if devEnvID == "" {
-		writeError(w, http.StatusBadRequest, "dev_env_id_required", r.Header.Get("X-Request-Id"))
+		httpapi.WriteError(w, r, http.StatusBadRequest, "dev_env_id_required")
 		return
 	}
 
 	var req dataplane.DevEnvDeleteRequest
-	if err := decodeJSON(r, &req); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_json", r.Header.Get("X-Request-Id"))
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
 		return
 	}
 	if strings.TrimSpace(req.DevEnvID) == "" || strings.TrimSpace(req.ProjectID) == "" {
-		writeError(w, http.StatusBadRequest, "missing_fields", r.Header.Get("X-Request-Id"))
+		httpapi.WriteError(w, r, http.StatusBadRequest, "missing_fields")
 		return
 	}
 	if req.DevEnvID != devEnvID {
-		writeError(w, http.StatusBadRequest, "dev_env_id_mismatch", r.Header.Get("X-Request-Id"))
+		httpapi.WriteError(w, r, http.StatusBadRequest, "dev_env_id_mismatch")
 		return
 	}
 	if req.EmittedAt.IsZero() {
-		writeError(w, http.StatusBadRequest, "emitted_at_required", r.Header.Get("X-Request-Id"))
+		httpapi.WriteError(w, r, http.StatusBadRequest, "emitted_at_required")
 		return
 	}
 
@@ -146,16 +147,16 @@ func (api *dataplaneAPI) handleDeleteDevEnv(w http.ResponseWriter, r *http.Reque
 	namespace := devEnvNamespace(api.cfg, api.k8s)
 	err := api.k8s.DeleteJob(r.Context(), namespace, jobName)
 	if err != nil && !errors.Is(err, k8s.ErrNotFound) {
-		writeError(w, http.StatusBadGateway, "devenv_job_delete_failed", r.Header.Get("X-Request-Id"))
+		httpapi.WriteError(w, r, http.StatusBadGateway, "devenv_job_delete_failed")
 		return
 	}
 	serviceErr := api.k8s.DeleteService(r.Context(), namespace, jobName)
 	if serviceErr != nil && !errors.Is(serviceErr, k8s.ErrNotFound) {
-		writeError(w, http.StatusBadGateway, "devenv_service_delete_failed", r.Header.Get("X-Request-Id"))
+		httpapi.WriteError(w, r, http.StatusBadGateway, "devenv_service_delete_failed")
 		return
 	}
 
-	writeJSON(w, http.StatusOK, dataplane.DevEnvDeleteResponse{
+	httpapi.WriteJSON(w, http.StatusOK, dataplane.DevEnvDeleteResponse{
 		DevEnvID:  devEnvID,
 		ProjectID: req.ProjectID,
 		Deleted:   true,
@@ -165,25 +166,25 @@ func (api *dataplaneAPI) handleDeleteDevEnv(w http.ResponseWriter, r *http.Reque
 func (api *dataplaneAPI) handleAccessDevEnv(w http.ResponseWriter, r *http.Request) {
 	devEnvID := strings.TrimSpace(r.PathValue("dev_env_id"))
 	if devEnvID == "" {
-		writeError(w, http.StatusBadRequest, "dev_env_id_required", r.Header.Get("X-Request-Id"))
+		httpapi.WriteError(w, r, http.StatusBadRequest, "dev_env_id_required")
 		return
 	}
 
 	var req dataplane.DevEnvAccessRequest
-	if err := decodeJSON(r, &req); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_json", r.Header.Get("X-Request-Id"))
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
 		return
 	}
 	if strings.TrimSpace(req.DevEnvID) == "" || strings.TrimSpace(req.ProjectID) == "" || strings.TrimSpace(req.SessionID) == "" {
-		writeError(w, http.StatusBadRequest, "missing_fields", r.Header.Get("X-Request-Id"))
+		httpapi.WriteError(w, r, http.StatusBadRequest, "missing_fields")
 		return
 	}
 	if req.DevEnvID != devEnvID {
-		writeError(w, http.StatusBadRequest, "dev_env_id_mismatch", r.Header.Get("X-Request-Id"))
+		httpapi.WriteError(w, r, http.StatusBadRequest, "dev_env_id_mismatch")
 		return
 	}
 	if req.EmittedAt.IsZero() {
-		writeError(w, http.StatusBadRequest, "emitted_at_required", r.Header.Get("X-Request-Id"))
+		httpapi.WriteError(w, r, http.StatusBadRequest, "emitted_at_required")
 		return
 	}
 
@@ -193,10 +194,10 @@ func (api *dataplaneAPI) handleAccessDevEnv(w http.ResponseWriter, r *http.Reque
 	status, err := inspectJob(r.Context(), api.k8s, namespace, jobName)
 	if err != nil {
 		if errors.Is(err, errJobNotFound) {
-			writeError(w, http.StatusNotFound, "not_found", r.Header.Get("X-Request-Id"))
+			httpapi.WriteError(w, r, http.StatusNotFound, "not_found")
 			return
 		}
-		writeError(w, http.StatusBadGateway, "devenv_status_unavailable", r.Header.Get("X-Request-Id"))
+		httpapi.WriteError(w, r, http.StatusBadGateway, "devenv_status_unavailable")
 		return
 	}
 
@@ -206,7 +207,7 @@ func (api *dataplaneAPI) handleAccessDevEnv(w http.ResponseWriter, r *http.Reque
 		message = status.State
 	}
 
-	writeJSON(w, http.StatusOK, dataplane.DevEnvAccessResponse{
+	httpapi.WriteJSON(w, http.StatusOK, dataplane.DevEnvAccessResponse{
 		DevEnvID:  devEnvID,
 		ProjectID: req.ProjectID,
 		Ready:     ready,
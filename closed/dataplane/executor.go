@@ -0,0 +1,119 @@
+package dataplane
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/animus-labs/animus-go/closed/internal/dataplane"
+	"github.com/animus-labs/animus-go/closed/internal/platform/env"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	"github.com/animus-labs/animus-go/closed/internal/runtimeexec"
+)
+
+// executorKubernetes runs each run as a Job in the data plane's cluster. The
+// other executors are runtimeexec plugins, which assign their own job ids.
+const executorKubernetes = "kubernetes"
+
+// runExecutorFromEnv reads ANIMUS_DATAPLANE_EXECUTOR. Kubernetes, the
+// default, has no plugin; a plugin kind is built from its
+// ANIMUS_EXECUTOR_<KIND>_* settings here, so a misconfigured backend stops
+// the service at startup rather than failing its first run.
+func runExecutorFromEnv() (string, runtimeexec.Plugin, error) {
+	kind := strings.ToLower(strings.TrimSpace(env.String("ANIMUS_DATAPLANE_EXECUTOR", "")))
+	if kind == "" || kind == executorKubernetes {
+		return executorKubernetes, nil, nil
+	}
+	if !slices.Contains(runtimeexec.PluginKinds(), kind) {
+		return "", nil, fmt.Errorf("ANIMUS_DATAPLANE_EXECUTOR: unknown executor %q (want %s or one of %s)", kind, executorKubernetes, strings.Join(runtimeexec.PluginKinds(), ", "))
+	}
+	plugin, err := runtimeexec.NewPlugin(kind, runtimeexec.PluginSettingsFromEnv(kind))
+	if err != nil {
+		return "", nil, fmt.Errorf("ANIMUS_DATAPLANE_EXECUTOR=%s: %w", kind, err)
+	}
+	return kind, plugin, nil
+}
+
+// inspectRun reports the state of the tracked job, from the plugin for runs
+// it launched and from Kubernetes otherwise.
+func (api *dataplaneAPI) inspectRun(ctx context.Context, tracker *runTracker) (jobStatus, error) {
+	if tracker.Execution == nil {
+		return inspectJob(ctx, api.k8s, tracker.Namespace, tracker.JobName)
+	}
+	observation, err := api.plugin.Inspect(ctx, *tracker.Execution)
+	if err != nil {
+		return jobStatus{}, err
+	}
+	details := observation.Details
+	if details == nil {
+		details = map[string]any{}
+	}
+	details["external_id"] = tracker.Execution.ExternalID
+	status := jobStatus{State: observation.Status, Details: details}
+	if observation.Status == jobStateFailed {
+		status.Reason = observation.Message
+	}
+	return status, nil
+}
+
+// findPluginRun returns the tracker of the plugin job jobName of a run or one
+// of its steps. A job this process does not track, for example one launched
+// before a restart, is looked up on the backend by its name; the result is
+// nil when the backend has none either.
+func (api *dataplaneAPI) findPluginRun(ctx context.Context, runID, stepName, jobName string) (*runTracker, error) {
+	api.mu.Lock()
+	tracker := api.trackers[trackerKey(runID, stepName)]
+	api.mu.Unlock()
+	if tracker != nil && tracker.JobName == jobName && tracker.Execution != nil {
+		return tracker, nil
+	}
+	execution, err := api.plugin.Find(ctx, runtimeexec.JobSpec{RunID: runID, JobName: jobName})
+	if errors.Is(err, runtimeexec.ErrExecutionNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &runTracker{RunID: runID, JobName: jobName, StepName: stepName, Execution: &execution}, nil
+}
+
+// cancelPluginRun is handleCancelRun for plugin runs: it cancels the tracked
+// jobs of the run, its steps first. Without a tracker the run's job is
+// looked up on the backend; untracked step jobs are not.
+func (api *dataplaneAPI) cancelPluginRun(w http.ResponseWriter, r *http.Request, req dataplane.RunCancelRequest, tracker *runTracker) {
+	runID := strings.TrimSpace(req.RunID)
+	for _, step := range api.stepTrackers(runID) {
+		if err := api.plugin.Cancel(r.Context(), *step.Execution); err != nil {
+			httpapi.WriteError(w, r, http.StatusBadGateway, "job_delete_failed")
+			return
+		}
+		api.removeTracker(step.key())
+	}
+	resp := dataplane.RunCancelResponse{RunID: runID, ProjectID: req.ProjectID, JobName: jobNameForRun(runID)}
+	if tracker == nil {
+		found, err := api.findPluginRun(r.Context(), runID, "", resp.JobName)
+		if err != nil {
+			httpapi.WriteError(w, r, http.StatusBadGateway, "job_delete_failed")
+			return
+		}
+		tracker = found
+	}
+	if tracker == nil {
+		resp.Message = "job_not_found"
+		httpapi.WriteJSON(w, http.StatusOK, resp)
+		return
+	}
+	if err := api.plugin.Cancel(r.Context(), *tracker.Execution); err != nil {
+		httpapi.WriteError(w, r, http.StatusBadGateway, "job_delete_failed")
+		return
+	}
+	api.removeTracker(runID)
+	if api.logger != nil {
+		api.logger.Info("run canceled", "run_id", runID, "dispatch_id", req.DispatchID, "reason", req.Reason, "external_id", tracker.Execution.ExternalID)
+	}
+	resp.Canceled = true
+	httpapi.WriteJSON(w, http.StatusOK, resp)
+}
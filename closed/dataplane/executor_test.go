@@ -0,0 +1,113 @@
+package dataplane
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/animus-labs/animus-go/closed/internal/runtimeexec"
+)
+
+func TestRunExecutorFromEnv(t *testing.T) {
+	t.Setenv("ANIMUS_DATAPLANE_EXECUTOR", "")
+	kind, plugin, err := runExecutorFromEnv()
+	if err != nil || kind != executorKubernetes || plugin != nil {
+		t.Fatalf("default: kind=%q plugin=%v err=%v", kind, plugin, err)
+	}
+
+	t.Setenv("ANIMUS_DATAPLANE_EXECUTOR", "nomad")
+	if _, _, err := runExecutorFromEnv(); err == nil {
+		t.Fatal("expected an unknown executor to be rejected")
+	}
+
+	t.Setenv("ANIMUS_DATAPLANE_EXECUTOR", "Slurm")
+	t.Setenv("ANIMUS_EXECUTOR_SLURM_URL", "")
+	t.Setenv("ANIMUS_EXECUTOR_SLURM_USER", "animus")
+	if _, _, err := runExecutorFromEnv(); err == nil {
+		t.Fatal("expected slurm without a url to be rejected")
+	}
+
+	t.Setenv("ANIMUS_EXECUTOR_SLURM_URL", "https://slurm.example:6820")
+	kind, plugin, err = runExecutorFromEnv()
+	if err != nil || kind != runtimeexec.SlurmKind || plugin == nil {
+		t.Fatalf("slurm: kind=%q plugin=%v err=%v", kind, plugin, err)
+	}
+}
+
+type fakePlugin struct {
+	runtimeexec.Plugin
+	observation runtimeexec.Observation
+	jobs        map[string]runtimeexec.Execution
+}
+
+func (p *fakePlugin) Find(_ context.Context, spec runtimeexec.JobSpec) (runtimeexec.Execution, error) {
+	execution, ok := p.jobs[spec.JobName]
+	if !ok {
+		return runtimeexec.Execution{}, runtimeexec.ErrExecutionNotFound
+	}
+	return execution, nil
+}
+
+func (p *fakePlugin) Inspect(_ context.Context, _ runtimeexec.Execution) (runtimeexec.Observation, error) {
+	return p.observation, nil
+}
+
+func TestInspectRunFromPlugin(t *testing.T) {
+	plugin := &fakePlugin{observation: runtimeexec.Observation{Status: runtimeexec.StatusFailed, Message: "OUT_OF_MEMORY"}}
+	api := &dataplaneAPI{plugin: plugin}
+	tracker := &runTracker{RunID: "run-1", JobName: "job-1", Execution: &runtimeexec.Execution{RunID: "run-1", Executor: runtimeexec.SlurmKind, ExternalID: "4242"}}
+	status, err := api.inspectRun(context.Background(), tracker)
+	if err != nil {
+		t.Fatalf("inspectRun() err=%v", err)
+	}
+	if status.State != jobStateFailed || status.Reason != "OUT_OF_MEMORY" || status.Details["external_id"] != "4242" {
+		t.Fatalf("status=%+v", status)
+	}
+
+	plugin.observation = runtimeexec.Observation{Status: runtimeexec.StatusRunning, Message: "RUNNING"}
+	status, err = api.inspectRun(context.Background(), tracker)
+	if err != nil || status.State != jobStateRunning || status.Reason != "" {
+		t.Fatalf("running: status=%+v err=%v", status, err)
+	}
+}
+
+func TestPluginRunStatusAfterRestart(t *testing.T) {
+	plugin := &fakePlugin{
+		observation: runtimeexec.Observation{Status: runtimeexec.StatusRunning, Message: "RUNNING"},
+		jobs: map[string]runtimeexec.Execution{
+			jobNameForRun("run-1"): {RunID: "run-1", Executor: runtimeexec.SlurmKind, ExternalID: "4242"},
+		},
+	}
+	api := newDataplaneAPI(nil, nil, nil, dataplaneConfig{Executor: runtimeexec.SlurmKind}, nil)
+	api.plugin = plugin
+	status := func(runID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/internal/dp/runs/"+runID+"/status?project_id=p1", nil)
+		req.SetPathValue("run_id", runID)
+		rec := httptest.NewRecorder()
+		api.handleGetRunStatus(rec, req)
+		return rec
+	}
+
+	rec := status("run-1")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+	var body struct {
+		State   string `json:"state"`
+		JobName string `json:"jobName"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || body.State != jobStateRunning || body.JobName != jobNameForRun("run-1") {
+		t.Fatalf("body=%s err=%v", rec.Body.String(), err)
+	}
+
+	if rec := status("run-2"); rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown run: status=%d", rec.Code)
+	}
+
+	tracker, err := api.findPluginRun(context.Background(), "run-1", "", jobNameForRun("run-1"))
+	if err != nil || tracker == nil || tracker.Execution.ExternalID != "4242" {
+		t.Fatalf("findPluginRun() tracker=%+v err=%v", tracker, err)
+	}
+}
@@ -0,0 +1,159 @@
+package dataplane
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/dataplane"
+	"github.com/animus-labs/animus-go/closed/internal/platform/k8s"
+	"github.com/google/uuid"
+)
+
+const (
+	// logFetchMaxBytes bounds one read of a pod's log.
+	logFetchMaxBytes = 1 << 20
+	// logChunkMaxBytes keeps a chunk well inside the control plane's JSON
+	// body limit after escaping.
+	logChunkMaxBytes = 256 << 10
+)
+
+type logLine struct {
+	At   time.Time
+	Text string
+}
+
+type logChunk struct {
+	Content string
+	First   time.Time
+	Last    time.Time
+}
+
+// relayLogs ships log lines written since the previous pass to the control
+// plane. The per-pod cursor only advances past chunks the control plane
+// accepted, so a failed send is re-read on the next poll. Runs of executor
+// plugins have no pods; their logs stay with the backend.
+func (api *dataplaneAPI) relayLogs(ctx context.Context, tracker *runTracker) {
+	if api.cp == nil || api.k8s == nil || tracker.Execution != nil {
+		return
+	}
+	pods, err := api.k8s.ListPods(ctx, tracker.Namespace, "job-name="+tracker.JobName)
+	if err != nil {
+		if api.logger != nil {
+			api.logger.Warn("list run pods failed", "run_id", tracker.RunID, "error", err)
+		}
+		return
+	}
+	if tracker.logSince == nil {
+		tracker.logSince = map[string]time.Time{}
+	}
+	for _, pod := range pods {
+		podName := strings.TrimSpace(pod.Metadata.Name)
+		if podName == "" || strings.EqualFold(pod.Status.Phase, "Pending") {
+			continue
+		}
+		since := tracker.logSince[podName]
+		raw, err := api.k8s.GetPodLogs(ctx, tracker.Namespace, podName, k8s.PodLogOptions{
+			Container:  runContainerName,
+			SinceTime:  since,
+			Timestamps: true,
+			LimitBytes: logFetchMaxBytes,
+		})
+		if err != nil {
+			if api.logger != nil {
+				api.logger.Warn("read run logs failed", "run_id", tracker.RunID, "pod", podName, "error", err)
+			}
+			continue
+		}
+		lines := parseLogLines(raw, since, len(raw) >= logFetchMaxBytes)
+		for _, chunk := range chunkLogLines(lines, logChunkMaxBytes) {
+			_, _, err := api.cp.SendLogChunk(ctx, dataplane.RunLogChunk{
+				EventID:     uuid.NewString(),
+				RunID:       tracker.RunID,
+				ProjectID:   tracker.ProjectID,
+				PodName:     podName,
+				Container:   runContainerName,
+				Content:     chunk.Content,
+				FirstLineAt: chunk.First,
+				LastLineAt:  chunk.Last,
+				EmittedAt:   time.Now().UTC(),
+			}, "")
+			if err != nil {
+				if api.logger != nil {
+					api.logger.Warn("send run logs failed", "run_id", tracker.RunID, "pod", podName, "error", err)
+				}
+				break
+			}
+			tracker.logSince[podName] = chunk.Last
+		}
+	}
+}
+
+// parseLogLines splits kubelet output fetched with timestamps=true and drops
+// lines at or before after: sinceTime is truncated to seconds, so a read
+// overlaps the previous one. A line without a timestamp continues the one
+// before it. When the read hit its byte limit the trailing line may be cut
+// and is left for the next read.
+func parseLogLines(raw []byte, after time.Time, truncated bool) []logLine {
+	if truncated {
+		if idx := bytes.LastIndexByte(raw, '\n'); idx >= 0 {
+			raw = raw[:idx+1]
+		}
+	}
+	var out []logLine
+	for _, line := range strings.Split(strings.TrimSuffix(string(raw), "\n"), "\n") {
+		if line == "" {
+			continue
+		}
+		stamp, text, _ := strings.Cut(line, " ")
+		at, err := time.Parse(time.RFC3339Nano, stamp)
+		if err != nil {
+			if len(out) > 0 {
+				out[len(out)-1].Text += "\n" + line
+			}
+			continue
+		}
+		if !at.After(after) {
+			continue
+		}
+		out = append(out, logLine{At: at.UTC(), Text: text})
+	}
+	return out
+}
+
+// chunkLogLines packs lines into chunks of at most maxBytes; a longer line
+// is cut to fit.
+func chunkLogLines(lines []logLine, maxBytes int) []logChunk {
+	var (
+		out     []logChunk
+		current logChunk
+		buf     strings.Builder
+	)
+	flush := func() {
+		if buf.Len() == 0 {
+			return
+		}
+		current.Content = buf.String()
+		out = append(out, current)
+		current = logChunk{}
+		buf.Reset()
+	}
+	for _, line := range lines {
+		text := line.Text
+		if len(text)+1 > maxBytes {
+			text = text[:maxBytes-1]
+		}
+		if buf.Len()+len(text)+1 > maxBytes {
+			flush()
+		}
+		if buf.Len() == 0 {
+			current.First = line.At
+		}
+		buf.WriteString(text)
+		buf.WriteByte('\n')
+		current.Last = line.At
+	}
+	flush()
+	return out
+}
@@ -0,0 +1,55 @@
+package dataplane
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseLogLinesSkipsSeenLines(t *testing.T) {
+	raw := []byte("2024-05-01T10:00:00.100000000Z epoch 1\n" +
+		"2024-05-01T10:00:00.200000000Z epoch 2\n" +
+		"2024-05-01T10:00:01.000000000Z epoch 3\n")
+	after := time.Date(2024, 5, 1, 10, 0, 0, 100000000, time.UTC)
+
+	lines := parseLogLines(raw, after, false)
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d", len(lines))
+	}
+	if lines[0].Text != "epoch 2" || lines[1].Text != "epoch 3" {
+		t.Fatalf("unexpected lines: %+v", lines)
+	}
+}
+
+func TestParseLogLinesDropsCutTrailingLine(t *testing.T) {
+	raw := []byte("2024-05-01T10:00:00Z complete\n2024-05-01T10:00:01Z parti")
+
+	lines := parseLogLines(raw, time.Time{}, true)
+	if len(lines) != 1 || lines[0].Text != "complete" {
+		t.Fatalf("unexpected lines: %+v", lines)
+	}
+	lines = parseLogLines(raw, time.Time{}, false)
+	if len(lines) != 2 {
+		t.Fatalf("expected trailing line kept when not truncated, got %d", len(lines))
+	}
+}
+
+func TestChunkLogLinesRespectsLimit(t *testing.T) {
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	lines := []logLine{
+		{At: base, Text: "aaaa"},
+		{At: base.Add(time.Second), Text: "bbbb"},
+		{At: base.Add(2 * time.Second), Text: strings.Repeat("c", 20)},
+	}
+
+	chunks := chunkLogLines(lines, 10)
+	if len(chunks) != 2 {
+		t.Fatalf("expected 2 chunks, got %d", len(chunks))
+	}
+	if chunks[0].Content != "aaaa\nbbbb\n" || !chunks[0].First.Equal(base) || !chunks[0].Last.Equal(base.Add(time.Second)) {
+		t.Fatalf("unexpected first chunk: %+v", chunks[0])
+	}
+	if len(chunks[1].Content) != 10 {
+		t.Fatalf("expected long line cut to limit, got %d bytes", len(chunks[1].Content))
+	}
+}
@@ -1,4 +1,4 @@
-package main
+package dataplane
 
 import (
 	"context"
@@ -14,10 +14,13 @@ import (
 	"github.com/animus-labs/animus-go/closed/internal/platform/env"
 	"github.com/animus-labs/animus-go/closed/internal/platform/httpserver"
 	"github.com/animus-labs/animus-go/closed/internal/platform/k8s"
+	"github.com/animus-labs/animus-go/closed/internal/platform/rbac"
 	"github.com/animus-labs/animus-go/closed/internal/platform/secrets"
+	"github.com/animus-labs/animus-go/core/contracts/openapi"
 )
 
-func main() {
+// Main runs the data plane service.
+func Main() {
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
 
 	ctx := context.Background()
@@ -31,12 +34,29 @@ func main() {
 		os.Exit(2)
 	}
 
-	internalAuthSecret := env.String("ANIMUS_INTERNAL_AUTH_SECRET", "")
-	headersAuth, err := auth.NewGatewayHeadersAuthenticator(internalAuthSecret)
+	headersAuth, err := auth.NewGatewayHeadersAuthenticator(env.String("ANIMUS_INTERNAL_AUTH_SECRET", ""))
 	if err != nil {
 		logger.Error("invalid internal auth config", "error", err)
 		os.Exit(2)
 	}
+	// Outgoing internal calls are signed with the newest secret only.
+	internalAuthSecret := headersAuth.Secret
+	internalMTLSCfg, err := auth.InternalMTLSConfigFromEnv()
+	if err != nil {
+		logger.Error("invalid internal mtls config", "error", err)
+		os.Exit(2)
+	}
+	headersAuth.Peers = internalMTLSCfg.PeerVerifier()
+	internalServerTLS, err := internalMTLSCfg.ServerTLSConfig()
+	if err != nil {
+		logger.Error("internal mtls init failed", "error", err)
+		os.Exit(2)
+	}
+	internalTransport, err := internalMTLSCfg.Transport()
+	if err != nil {
+		logger.Error("internal mtls init failed", "error", err)
+		os.Exit(2)
+	}
 
 	cpBaseURL := env.String("ANIMUS_CONTROL_PLANE_URL", "")
 	if cpBaseURL == "" {
@@ -86,6 +106,16 @@ func main() {
 		logger.Error("invalid dp egress mode", "error", err)
 		os.Exit(2)
 	}
+	datasetCacheDir, err := normalizeDatasetCacheDir(env.String("ANIMUS_DATASET_CACHE_DIR", ""))
+	if err != nil {
+		logger.Error("invalid dataset cache dir", "error", err)
+		os.Exit(2)
+	}
+	executor, plugin, err := runExecutorFromEnv()
+	if err != nil {
+		logger.Error("invalid executor config", "error", err)
+		os.Exit(2)
+	}
 
 	secretsCfg, err := secrets.ConfigFromEnv()
 	if err != nil {
@@ -98,7 +128,7 @@ func main() {
 		os.Exit(2)
 	}
 
-	cpClient, err := newControlPlaneClient(cpBaseURL, internalAuthSecret)
+	cpClient, err := newControlPlaneClient(cpBaseURL, internalAuthSecret, internalTransport)
 	if err != nil {
 		logger.Error("control plane client init failed", "error", err)
 		os.Exit(2)
@@ -118,27 +148,33 @@ func main() {
 		HeartbeatInterval:             heartbeatInterval,
 		PollInterval:                  pollInterval,
 		EgressMode:                    egressMode,
+		DatasetCacheDir:               datasetCacheDir,
+		Executor:                      executor,
 	}, secretsManager)
+	api.plugin = plugin
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/healthz", httpserver.Healthz("dataplane"))
+	mux.HandleFunc("/version", httpserver.BuildInfo("dataplane"))
+	mux.Handle("GET "+openapi.ServicePath, openapi.Handler(openapi.Dataplane))
 	mux.HandleFunc("/readyz", httpserver.Readyz("dataplane"))
 	httpserver.RegisterMetrics(mux, "dataplane")
 	api.register(mux)
 
-	authorizer := auth.MethodRoleAuthorizer()
+	authorizer := auth.MethodRoleAuthorizer(rbac.AuditorRoutes("/internal/dp/runs/*/status"))
 
 	handler := auth.Middleware{
 		Logger:        logger,
 		Authenticator: headersAuth,
 		Authorize:     authorizer,
-		SkipPrefixes:  []string{"/healthz", "/readyz"},
+		SkipPrefixes:  []string{"/healthz", "/readyz", "/version", openapi.ServicePath},
 	}.Wrap(mux)
 
 	cfg := httpserver.Config{
 		Service:         "dataplane",
 		Addr:            addr,
 		ShutdownTimeout: shutdownTimeout,
+		TLSConfig:       internalServerTLS,
 	}
 
 	if err := httpserver.Run(ctx, logger, cfg, httpserver.Wrap(logger, "dataplane", handler)); err != nil && !errors.Is(err, http.ErrServerClosed) {
@@ -1,4 +1,4 @@
-package main
+package dataplane
 
 import (
 	"encoding/json"
@@ -19,6 +19,7 @@ type runSpecPayload struct {
 	EnvLock         domain.EnvLock        `json:"envLock"`
 	Parameters      map[string]any        `json:"parameters"`
 	PolicySnapshot  domain.PolicySnapshot `json:"policySnapshot"`
+	ResumeFrom      *domain.CheckpointRef `json:"resumeFrom,omitempty"`
 	CreatedAt       time.Time             `json:"createdAt"`
 	CreatedBy       string                `json:"createdBy,omitempty"`
 }
@@ -60,6 +61,7 @@ func parseRunSpec(raw json.RawMessage) (domain.RunSpec, error) {
 		EnvLock:         payload.EnvLock,
 		Parameters:      domain.Metadata(params),
 		PolicySnapshot:  payload.PolicySnapshot,
+		ResumeFrom:      payload.ResumeFrom,
 		CreatedAt:       payload.CreatedAt,
 		CreatedBy:       strings.TrimSpace(payload.CreatedBy),
 	}
@@ -1,4 +1,4 @@
-package main
+package dataplane
 
 import (
 	"context"
@@ -13,11 +13,15 @@ import (
 	"github.com/animus-labs/animus-go/closed/internal/dataplane"
 	"github.com/animus-labs/animus-go/closed/internal/domain"
 	"github.com/animus-labs/animus-go/closed/internal/platform/k8s"
+	"github.com/animus-labs/animus-go/closed/internal/runtimeexec"
 	"github.com/google/uuid"
 )
 
 var errJobNotFound = errors.New("job not found")
 
+// runContainerName names the single container of a run's Job pod.
+const runContainerName = "runner"
+
 const (
 	jobStatePending   = "pending"
 	jobStateRunning   = "running"
@@ -34,8 +38,28 @@ type runTracker struct {
 	EnvLockID  string
 	PolicySHA  string
 	StartedAt  time.Time
+	// StepName and Attempt are set for one step of a multi-step run.
+	StepName string
+	Attempt  int
+	// Execution is the job of a run launched by an executor plugin; nil for
+	// Kubernetes Jobs.
+	Execution *runtimeexec.Execution
 
 	missingCount int
+	logSince     map[string]time.Time
+}
+
+// key identifies the tracker: the run, or the step of a multi-step run,
+// which runs one attempt at a time.
+func (t *runTracker) key() string {
+	return trackerKey(t.RunID, t.StepName)
+}
+
+func trackerKey(runID, stepName string) string {
+	if stepName == "" {
+		return runID
+	}
+	return runID + "/" + stepName
 }
 
 type jobStatus struct {
@@ -56,13 +80,16 @@ func (api *dataplaneAPI) monitorRun(tracker *runTracker) {
 	defer ticker.Stop()
 
 	for range ticker.C {
-		status, err := inspectJob(context.Background(), api.k8s, tracker.Namespace, tracker.JobName)
+		if !api.tracking(tracker) {
+			return
+		}
+		status, err := api.inspectRun(context.Background(), tracker)
 		if err != nil {
 			if errors.Is(err, errJobNotFound) {
 				tracker.missingCount++
 				if tracker.missingCount >= 3 {
 					api.emitTerminal(tracker, jobStateFailed, "job_not_found", nil)
-					api.removeTracker(tracker.RunID)
+					api.removeTracker(tracker.key())
 					return
 				}
 			} else if api.logger != nil {
@@ -71,6 +98,7 @@ func (api *dataplaneAPI) monitorRun(tracker *runTracker) {
 			continue
 		}
 		tracker.missingCount = 0
+		api.relayLogs(context.Background(), tracker)
 
 		now := time.Now().UTC()
 		if lastHeartbeat.IsZero() || now.Sub(lastHeartbeat) >= api.cfg.HeartbeatInterval {
@@ -81,11 +109,11 @@ func (api *dataplaneAPI) monitorRun(tracker *runTracker) {
 		switch status.State {
 		case jobStateSucceeded:
 			api.emitTerminal(tracker, jobStateSucceeded, status.Reason, status.FinishedAt)
-			api.removeTracker(tracker.RunID)
+			api.removeTracker(tracker.key())
 			return
 		case jobStateFailed:
 			api.emitTerminal(tracker, jobStateFailed, status.Reason, status.FinishedAt)
-			api.removeTracker(tracker.RunID)
+			api.removeTracker(tracker.key())
 			return
 		}
 	}
@@ -107,6 +135,8 @@ func (api *dataplaneAPI) emitHeartbeat(tracker *runTracker, status jobStatus) {
 			"namespace": tracker.Namespace,
 			"details":   status.Details,
 		},
+		StepName: tracker.StepName,
+		Attempt:  tracker.Attempt,
 	}
 	_, _, _ = api.cp.SendHeartbeat(context.Background(), event, "")
 }
@@ -132,6 +162,8 @@ func (api *dataplaneAPI) emitTerminal(tracker *runTracker, state string, reason
 				"env_lock":   tracker.EnvLockID,
 				"policy_sha": tracker.PolicySHA,
 			},
+			StepName: tracker.StepName,
+			Attempt:  tracker.Attempt,
 		}
 		if _, _, err := api.cp.SendTerminal(context.Background(), event, ""); err == nil {
 			return
@@ -219,6 +251,12 @@ func jobNameForRun(runID string) string {
 	return base[:trim] + "-" + suffix
 }
 
+// jobNameForStep names the Job of one attempt of a pipeline step, so a
+// retry never collides with the Job of the attempt it replaces.
+func jobNameForStep(runID, stepName string, attempt int) string {
+	return jobNameForRun(runID + "-" + stepName + "-" + strconv.Itoa(attempt))
+}
+
 func sanitizeName(value string) string {
 	value = strings.ToLower(strings.TrimSpace(value))
 	if value == "" {
@@ -251,12 +289,11 @@ func outToString(runes []rune) string {
 	return string(runes)
 }
 
-func buildJobSpec(runSpec domain.RunSpec, runID, jobName, namespace string, ttlSeconds int32, serviceAccount, dispatchID string, secretEnv map[string]string) (k8s.Job, error) {
-	steps := runSpec.PipelineSpec.Spec.Steps
-	if len(steps) != 1 {
-		return k8s.Job{}, errors.New("single step pipeline required")
+func buildJobSpec(runSpec domain.RunSpec, runID, jobName, namespace string, ttlSeconds int32, serviceAccount, dispatchID string, maxDurationSeconds int64, secretEnv map[string]string, inputs runInputs) (k8s.Job, error) {
+	step, err := selectRunStep(runSpec.PipelineSpec.Spec.Steps, inputs.Step)
+	if err != nil {
+		return k8s.Job{}, err
 	}
-	step := steps[0]
 
 	image := resolveStepImage(step.Image, runSpec.EnvLock.Images)
 	if strings.TrimSpace(image) == "" {
@@ -281,20 +318,34 @@ func buildJobSpec(runSpec domain.RunSpec, runID, jobName, namespace string, ttlS
 	if strings.TrimSpace(runSpec.EnvLock.SecretAccessClassRef) != "" {
 		labels["animus.secret_access_class_ref"] = strings.TrimSpace(runSpec.EnvLock.SecretAccessClassRef)
 	}
+	if inputs.Step != nil {
+		labels["animus.step_name"] = sanitizeName(step.Name)
+		labels["animus.step_attempt"] = strconv.Itoa(inputs.Step.Attempt)
+	}
 	labels = filterLabelLength(labels)
 
 	container := k8s.Container{
-		Name:      "runner",
+		Name:      runContainerName,
 		Image:     image,
 		Command:   step.Command,
 		Args:      step.Args,
-		Env:       buildEnvVars(runSpec, runID, step, secretEnv),
+		Env:       buildEnvVars(runSpec, runID, step, secretEnv, inputs),
 		Resources: containerResources,
 	}
 
+	var volumes []k8s.Volume
+	if inputs.CacheDir != "" {
+		container.VolumeMounts = []k8s.VolumeMount{{Name: datasetCacheVolumeName, MountPath: datasetCacheMountPath}}
+		volumes = []k8s.Volume{{
+			Name:     datasetCacheVolumeName,
+			HostPath: &k8s.HostPathVolumeSource{Path: inputs.CacheDir, Type: "DirectoryOrCreate"},
+		}}
+	}
+
 	podSpec := k8s.PodSpec{
 		RestartPolicy: "Never",
 		Containers:    []k8s.Container{container},
+		Volumes:       volumes,
 	}
 	if strings.TrimSpace(serviceAccount) != "" {
 		podSpec.ServiceAccountName = strings.TrimSpace(serviceAccount)
@@ -305,6 +356,10 @@ func buildJobSpec(runSpec domain.RunSpec, runID, jobName, namespace string, ttlS
 	if ttlSeconds > 0 {
 		ttl = &ttlSeconds
 	}
+	var activeDeadline *int64
+	if maxDurationSeconds > 0 {
+		activeDeadline = &maxDurationSeconds
+	}
 
 	job := k8s.Job{
 		Metadata: k8s.ObjectMeta{
@@ -319,11 +374,29 @@ func buildJobSpec(runSpec domain.RunSpec, runID, jobName, namespace string, ttlS
 				Spec:     podSpec,
 			},
 			TTLSecondsAfterFinished: ttl,
+			ActiveDeadlineSeconds:   activeDeadline,
 		},
 	}
 	return job, nil
 }
 
+// selectRunStep returns the step the request names, or the only step of a
+// single-step pipeline.
+func selectRunStep(steps []domain.PipelineStep, requested *dataplane.RunStep) (domain.PipelineStep, error) {
+	if requested == nil {
+		if len(steps) != 1 {
+			return domain.PipelineStep{}, errors.New("single step pipeline required")
+		}
+		return steps[0], nil
+	}
+	for _, step := range steps {
+		if step.Name == requested.Name {
+			return step, nil
+		}
+	}
+	return domain.PipelineStep{}, errors.New("step not found")
+}
+
 func resolveStepImage(stepImage string, images []domain.EnvironmentImage) string {
 	stepImage = strings.TrimSpace(stepImage)
 	if stepImage == "" {
@@ -399,7 +472,7 @@ func buildResourceRequirements(requests domain.EnvironmentResources, limits doma
 	return out
 }
 
-func buildEnvVars(runSpec domain.RunSpec, runID string, step domain.PipelineStep, secretEnv map[string]string) []k8s.EnvVar {
+func buildEnvVars(runSpec domain.RunSpec, runID string, step domain.PipelineStep, secretEnv map[string]string, inputs runInputs) []k8s.EnvVar {
 	out := []k8s.EnvVar{}
 	appendEnv := func(name, value string) {
 		name = strings.TrimSpace(name)
@@ -420,11 +493,33 @@ func buildEnvVars(runSpec domain.RunSpec, runID string, step domain.PipelineStep
 	appendEnv("ANIMUS_CODE_PATH", runSpec.CodeRef.Path)
 	appendEnv("ANIMUS_CODE_SCM", runSpec.CodeRef.SCMType)
 	appendEnv("ANIMUS_STEP_NAME", step.Name)
+	if inputs.RunToken != "" {
+		appendEnv("ANIMUS_RUN_TOKEN", inputs.RunToken)
+	}
 
 	bindingsJSON, _ := json.Marshal(runSpec.DatasetBindings)
 	appendEnv("ANIMUS_DATASET_BINDINGS", string(bindingsJSON))
 	paramsJSON, _ := json.Marshal(runSpec.Parameters)
 	appendEnv("ANIMUS_PARAMETERS", string(paramsJSON))
+	if checkpoint := inputs.Checkpoint; checkpoint != nil {
+		appendEnv("ANIMUS_CHECKPOINT_URL", checkpoint.URL)
+		appendEnv("ANIMUS_CHECKPOINT_SHA256", checkpoint.SHA256)
+		appendEnv("ANIMUS_CHECKPOINT_RUN_ID", checkpoint.RunID)
+		appendEnv("ANIMUS_CHECKPOINT_ARTIFACT_ID", checkpoint.ArtifactID)
+	}
+	if len(inputs.Datasets) > 0 {
+		appendEnv("ANIMUS_DATASETS", datasetsEnvValue(inputs.Datasets, inputs.CacheDir != ""))
+	}
+	if inputs.CacheDir != "" {
+		appendEnv("ANIMUS_DATASET_CACHE_DIR", datasetCacheMountPath)
+	}
+	if inputs.Step != nil {
+		appendEnv("ANIMUS_STEP_ATTEMPT", strconv.Itoa(inputs.Step.Attempt))
+		if len(inputs.Step.Inputs) > 0 {
+			stepInputsJSON, _ := json.Marshal(inputs.Step.Inputs)
+			appendEnv("ANIMUS_STEP_INPUTS", string(stepInputsJSON))
+		}
+	}
 
 	reserved := map[string]struct{}{}
 	for _, env := range out {
@@ -459,6 +554,26 @@ func buildEnvVars(runSpec domain.RunSpec, runID string, step domain.PipelineStep
 	return out
 }
 
+// errCheckpointMismatch reports that the execution request does not carry
+// the checkpoint the run spec resumes from.
+var errCheckpointMismatch = errors.New("checkpoint mismatch")
+
+// validateRunCheckpoint checks that checkpoint is exactly the one runSpec
+// resumes from, so a resumed run never silently starts from scratch.
+func validateRunCheckpoint(runSpec domain.RunSpec, checkpoint *dataplane.RunCheckpoint) error {
+	ref := runSpec.ResumeFrom
+	if ref == nil && checkpoint == nil {
+		return nil
+	}
+	if ref == nil || checkpoint == nil || strings.TrimSpace(checkpoint.URL) == "" {
+		return errCheckpointMismatch
+	}
+	if checkpoint.ArtifactID != ref.ArtifactID || checkpoint.RunID != ref.RunID || !strings.EqualFold(checkpoint.SHA256, ref.SHA256) {
+		return errCheckpointMismatch
+	}
+	return nil
+}
+
 func filterLabelLength(labels map[string]string) map[string]string {
 	out := make(map[string]string, len(labels))
 	for key, value := range labels {
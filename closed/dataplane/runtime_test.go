@@ -1,8 +1,10 @@
-package main
+package dataplane
 
 import (
+	"strings"
 	"testing"
 
+	"github.com/animus-labs/animus-go/closed/internal/dataplane"
 	"github.com/animus-labs/animus-go/closed/internal/domain"
 )
 
@@ -18,7 +20,7 @@ func TestBuildJobSpecUsesEnvLockDigest(t *testing.T) {
 	runSpec.EnvLock.NetworkClassRef = "net-class"
 	runSpec.EnvLock.SecretAccessClassRef = "secret-class"
 
-	job, err := buildJobSpec(runSpec, "run-1", "job-1", "ns", 0, "", "dispatch-1", nil)
+	job, err := buildJobSpec(runSpec, "run-1", "job-1", "ns", 0, "", "dispatch-1", 0, nil, runInputs{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -87,15 +89,55 @@ func TestBuildJobSpecRejectsMultipleSteps(t *testing.T) {
 	runSpec := minimalRunSpec("runtime", []domain.EnvironmentImage{{Name: "runtime", Ref: validImageRef, Digest: validDigest}})
 	runSpec.PipelineSpec.Spec.Steps = append(runSpec.PipelineSpec.Spec.Steps, runSpec.PipelineSpec.Spec.Steps[0])
 
-	if _, err := buildJobSpec(runSpec, "run-1", "job-1", "", 0, "", "dispatch-1", nil); err == nil {
+	if _, err := buildJobSpec(runSpec, "run-1", "job-1", "", 0, "", "dispatch-1", 0, nil, runInputs{}); err == nil {
 		t.Fatalf("expected error for multiple steps")
 	}
 }
 
+func TestBuildJobSpecSelectsPipelineStep(t *testing.T) {
+	runSpec := minimalRunSpec("runtime", []domain.EnvironmentImage{{Name: "runtime", Ref: validImageRef, Digest: validDigest}})
+	train := runSpec.PipelineSpec.Spec.Steps[0]
+	train.Name = "train"
+	train.Args = []string{"train"}
+	runSpec.PipelineSpec.Spec.Steps = append(runSpec.PipelineSpec.Spec.Steps, train)
+	step := &dataplane.RunStep{
+		Name:    "train",
+		Attempt: 2,
+		Inputs:  []dataplane.RunStepInput{{Name: "features", FromStep: "step-a", Artifact: "features.parquet", URL: "https://store/features"}},
+	}
+
+	job, err := buildJobSpec(runSpec, "run-1", jobNameForStep("run-1", "train", 2), "", 0, "", "dispatch-1", 0, nil, runInputs{Step: step})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	container := job.Spec.Template.Spec.Containers[0]
+	if strings.Join(container.Args, " ") != "train" {
+		t.Fatalf("args = %v", container.Args)
+	}
+	if job.Metadata.Labels["animus.step_name"] != "train" || job.Metadata.Labels["animus.step_attempt"] != "2" {
+		t.Fatalf("labels = %v", job.Metadata.Labels)
+	}
+	env := map[string]string{}
+	for _, entry := range container.Env {
+		env[entry.Name] = entry.Value
+	}
+	if env["ANIMUS_STEP_NAME"] != "train" || env["ANIMUS_STEP_ATTEMPT"] != "2" || !strings.Contains(env["ANIMUS_STEP_INPUTS"], `"fromStep":"step-a"`) {
+		t.Fatalf("step env = %v", env)
+	}
+	if job.Metadata.Name == jobNameForStep("run-1", "train", 1) {
+		t.Fatalf("attempts must not share a job name")
+	}
+
+	step.Name = "missing"
+	if _, err := buildJobSpec(runSpec, "run-1", "job-1", "", 0, "", "dispatch-1", 0, nil, runInputs{Step: step}); err == nil {
+		t.Fatalf("expected error for unknown step")
+	}
+}
+
 func TestBuildJobSpecRejectsUnresolvedImage(t *testing.T) {
 	runSpec := minimalRunSpec("ghcr.io/acme/train:latest", nil)
 
-	if _, err := buildJobSpec(runSpec, "run-1", "job-1", "", 0, "", "dispatch-1", nil); err == nil {
+	if _, err := buildJobSpec(runSpec, "run-1", "job-1", "", 0, "", "dispatch-1", 0, nil, runInputs{}); err == nil {
 		t.Fatalf("expected error for unresolved image")
 	}
 }
@@ -104,13 +146,63 @@ func TestBuildJobSpecAcceptsDigestImage(t *testing.T) {
 	pinned := "ghcr.io/acme/train@" + validDigest
 	runSpec := minimalRunSpec(pinned, nil)
 
-	job, err := buildJobSpec(runSpec, "run-1", "job-1", "", 0, "", "dispatch-1", nil)
+	job, err := buildJobSpec(runSpec, "run-1", "job-1", "", 0, "", "dispatch-1", 0, nil, runInputs{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 	if got := job.Spec.Template.Spec.Containers[0].Image; got != pinned {
 		t.Fatalf("expected pinned image, got %s", got)
 	}
+	if job.Spec.ActiveDeadlineSeconds != nil {
+		t.Fatalf("expected no active deadline without max duration")
+	}
+}
+
+func TestBuildJobSpecSetsActiveDeadline(t *testing.T) {
+	runSpec := minimalRunSpec("ghcr.io/acme/train@"+validDigest, nil)
+
+	job, err := buildJobSpec(runSpec, "run-1", "job-1", "", 0, "", "dispatch-1", 7200, nil, runInputs{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if job.Spec.ActiveDeadlineSeconds == nil || *job.Spec.ActiveDeadlineSeconds != 7200 {
+		t.Fatalf("expected active deadline 7200, got %+v", job.Spec.ActiveDeadlineSeconds)
+	}
+}
+
+func TestBuildJobSpecInjectsCheckpoint(t *testing.T) {
+	runSpec := minimalRunSpec("ghcr.io/acme/train@"+validDigest, nil)
+	runSpec.ResumeFrom = &domain.CheckpointRef{RunID: "run-0", ArtifactID: "art-1", SHA256: strings.Repeat("a", 64)}
+	checkpoint := &dataplane.RunCheckpoint{RunID: "run-0", ArtifactID: "art-1", SHA256: strings.Repeat("A", 64), URL: "https://minio/ckpt?sig"}
+	if err := validateRunCheckpoint(runSpec, checkpoint); err != nil {
+		t.Fatalf("validateRunCheckpoint() err=%v", err)
+	}
+
+	job, err := buildJobSpec(runSpec, "run-1", "job-1", "", 0, "", "dispatch-1", 0, nil, runInputs{Checkpoint: checkpoint})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	env := map[string]string{}
+	for _, v := range job.Spec.Template.Spec.Containers[0].Env {
+		env[v.Name] = v.Value
+	}
+	if env["ANIMUS_CHECKPOINT_URL"] != checkpoint.URL || env["ANIMUS_CHECKPOINT_ARTIFACT_ID"] != "art-1" || env["ANIMUS_CHECKPOINT_RUN_ID"] != "run-0" {
+		t.Fatalf("env=%v", env)
+	}
+
+	for _, bad := range []*dataplane.RunCheckpoint{
+		nil,
+		{RunID: "run-0", ArtifactID: "art-2", SHA256: strings.Repeat("a", 64), URL: "https://minio/ckpt"},
+		{RunID: "run-0", ArtifactID: "art-1", SHA256: strings.Repeat("a", 64)},
+	} {
+		if err := validateRunCheckpoint(runSpec, bad); err == nil {
+			t.Fatalf("expected %+v to be rejected", bad)
+		}
+	}
+	runSpec.ResumeFrom = nil
+	if err := validateRunCheckpoint(runSpec, checkpoint); err == nil {
+		t.Fatalf("expected unexpected checkpoint to be rejected")
+	}
 }
 
 func minimalRunSpec(stepImage string, images []domain.EnvironmentImage) domain.RunSpec {
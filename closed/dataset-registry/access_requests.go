@@ -0,0 +1,1125 @@
+package datasetregistry
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	"github.com/animus-labs/animus-go/closed/internal/platform/policy"
+	"github.com/google/uuid"
+)
+
+const (
+	accessRequestPending  = "pending"
+	accessRequestApproved = "approved"
+	accessRequestRejected = "rejected"
+
+	accessVoteApprove = "approve"
+	accessVoteReject  = "reject"
+
+	accessGrantActive  = "active"
+	accessGrantExpired = "expired"
+	accessGrantRevoked = "revoked"
+
+	defaultAccessMaxDuration  = 30 * 24 * time.Hour
+	maxAccessDuration         = 365 * 24 * time.Hour
+	maxAccessJustificationLen = 4096
+	maxAccessListLimit        = 500
+)
+
+// datasetAccessPolicy marks a dataset as restricted. Versions of a restricted
+// dataset are readable by admins and by subjects holding an active grant;
+// grants come from access requests approved through Approval.
+type datasetAccessPolicy struct {
+	Restricted  bool                    `json:"restricted"`
+	Approval    policy.ApprovalWorkflow `json:"approval"`
+	MaxDuration string                  `json:"max_duration"`
+	UpdatedAt   *time.Time              `json:"updated_at,omitempty"`
+	UpdatedBy   string                  `json:"updated_by,omitempty"`
+
+	maxDuration time.Duration
+}
+
+type setDatasetAccessPolicyRequest struct {
+	Restricted  bool                     `json:"restricted"`
+	Approval    *policy.ApprovalWorkflow `json:"approval,omitempty"`
+	MaxDuration string                   `json:"max_duration,omitempty"`
+}
+
+type createDatasetAccessRequest struct {
+	Justification string `json:"justification"`
+	Duration      string `json:"duration"`
+}
+
+type datasetAccessDecisionRequest struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+type datasetAccessRequest struct {
+	RequestID       string                  `json:"request_id"`
+	DatasetID       string                  `json:"dataset_id"`
+	RequestedBy     string                  `json:"requested_by"`
+	Justification   string                  `json:"justification"`
+	DurationSeconds int64                   `json:"duration_seconds"`
+	Workflow        policy.ApprovalWorkflow `json:"workflow"`
+	CurrentStage    int                     `json:"current_stage"`
+	StageName       string                  `json:"stage_name,omitempty"`
+	Status          string                  `json:"status"`
+	RequestedAt     time.Time               `json:"requested_at"`
+	DecidedAt       *time.Time              `json:"decided_at,omitempty"`
+	DecidedBy       string                  `json:"decided_by,omitempty"`
+	Votes           []datasetAccessVote     `json:"votes,omitempty"`
+	Grant           *datasetAccessGrant     `json:"grant,omitempty"`
+}
+
+func (req datasetAccessRequest) stage() policy.ApprovalStage {
+	if req.CurrentStage < 0 || req.CurrentStage >= len(req.Workflow.Stages) {
+		return policy.ApprovalStage{}
+	}
+	return req.Workflow.Stages[req.CurrentStage]
+}
+
+func (req datasetAccessRequest) lastStage() bool {
+	return req.CurrentStage >= len(req.Workflow.Stages)-1
+}
+
+type datasetAccessVote struct {
+	VoteID    string    `json:"vote_id"`
+	Stage     int       `json:"stage"`
+	StageName string    `json:"stage_name"`
+	Voter     string    `json:"voter"`
+	Vote      string    `json:"vote"`
+	Reason    string    `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type datasetAccessGrant struct {
+	GrantID      string     `json:"grant_id"`
+	RequestID    string     `json:"request_id"`
+	DatasetID    string     `json:"dataset_id"`
+	Subject      string     `json:"subject"`
+	Status       string     `json:"status"`
+	GrantedAt    time.Time  `json:"granted_at"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+	RevokedBy    string     `json:"revoked_by,omitempty"`
+	RevokeReason string     `json:"revoke_reason,omitempty"`
+}
+
+// accessGrantStatus is revoked, expired or active at now.
+func accessGrantStatus(revokedAt *time.Time, expiresAt, now time.Time) string {
+	if revokedAt != nil {
+		return accessGrantRevoked
+	}
+	if !now.Before(expiresAt) {
+		return accessGrantExpired
+	}
+	return accessGrantActive
+}
+
+// datasetAccess is what lets a subject read versions of a dataset.
+type datasetAccess struct {
+	Restricted bool
+	// Bypass is set for admins, who read restricted datasets without a grant.
+	Bypass bool
+	Grant  *datasetAccessGrant
+}
+
+func (a datasetAccess) allowed() bool {
+	return !a.Restricted || a.Bypass || a.Grant != nil
+}
+
+func (api *datasetRegistryAPI) registerAccessRequests(mux *http.ServeMux) {
+	mux.HandleFunc("GET /datasets/{dataset_id}/access-policy", api.handleGetDatasetAccessPolicy)
+	mux.HandleFunc("PUT /datasets/{dataset_id}/access-policy", api.handleSetDatasetAccessPolicy)
+	mux.HandleFunc("POST /datasets/{dataset_id}/access-requests", api.handleCreateDatasetAccessRequest)
+	mux.HandleFunc("GET /datasets/{dataset_id}/access-requests", api.handleListDatasetAccessRequests)
+	mux.HandleFunc("GET /datasets/{dataset_id}/access-requests/{request_id}", api.handleGetDatasetAccessRequest)
+	mux.HandleFunc("POST /datasets/{dataset_id}/access-requests/{request_id}/approve", api.handleApproveDatasetAccessRequest)
+	mux.HandleFunc("POST /datasets/{dataset_id}/access-requests/{request_id}/reject", api.handleRejectDatasetAccessRequest)
+	mux.HandleFunc("GET /datasets/{dataset_id}/access-grants", api.handleListDatasetAccessGrants)
+	mux.HandleFunc("POST /datasets/{dataset_id}/access-grants/{grant_id}/revoke", api.handleRevokeDatasetAccessGrant)
+}
+
+// normalizeAccessWorkflow validates the approval workflow of an access
+// policy; nil selects the single admin review. Escalations are refused
+// because nothing watches access requests for stale stages.
+func normalizeAccessWorkflow(workflow *policy.ApprovalWorkflow) (policy.ApprovalWorkflow, bool) {
+	if workflow == nil {
+		return policy.DefaultApprovalWorkflow(), true
+	}
+	if err := workflow.Validate(); err != nil {
+		return policy.ApprovalWorkflow{}, false
+	}
+	out := policy.ApprovalWorkflow{Stages: make([]policy.ApprovalStage, 0, len(workflow.Stages))}
+	for _, stage := range workflow.Stages {
+		if stage.Escalation != nil {
+			return policy.ApprovalWorkflow{}, false
+		}
+		stage.Name = strings.TrimSpace(stage.Name)
+		out.Stages = append(out.Stages, stage)
+	}
+	return out, true
+}
+
+// parseAccessDuration reads a Go duration and bounds it to (0, limit],
+// truncated to whole seconds.
+func parseAccessDuration(raw string, limit time.Duration) (time.Duration, bool) {
+	d, err := time.ParseDuration(strings.TrimSpace(raw))
+	if err != nil {
+		return 0, false
+	}
+	d = d.Truncate(time.Second)
+	if d <= 0 || d > limit {
+		return 0, false
+	}
+	return d, true
+}
+
+// accessVoterError returns the error code refusing identity a vote in the
+// current stage of req, or "" when it may vote. It matches the rules of
+// run approvals: requesters never vote, restricted stages need one of their
+// roles and other stages need an admin.
+func accessVoterError(req datasetAccessRequest, identity auth.Identity) string {
+	if req.RequestedBy == identity.Subject {
+		return "approval_requires_second_reviewer"
+	}
+	stage := req.stage()
+	if stage.Restricted() {
+		if !stage.Allows(identity.Roles) {
+			return "approval_stage_role_required"
+		}
+		return ""
+	}
+	if !auth.HasAtLeast(identity.Roles, auth.RoleAdmin) {
+		return "approval_requires_admin"
+	}
+	return ""
+}
+
+func decodeAccessWorkflow(raw []byte) policy.ApprovalWorkflow {
+	if len(raw) == 0 || string(raw) == "null" {
+		return policy.DefaultApprovalWorkflow()
+	}
+	var workflow policy.ApprovalWorkflow
+	if err := json.Unmarshal(raw, &workflow); err != nil || workflow.Validate() != nil {
+		return policy.DefaultApprovalWorkflow()
+	}
+	return workflow
+}
+
+// datasetAccessPolicy returns the access policy of a dataset; datasets
+// without one are unrestricted.
+func (api *datasetRegistryAPI) datasetAccessPolicy(ctx context.Context, datasetID string) (datasetAccessPolicy, error) {
+	var (
+		out        datasetAccessPolicy
+		workflow   []byte
+		maxSeconds int64
+		updatedAt  time.Time
+	)
+	err := api.db.QueryRowContext(
+		ctx,
+		`SELECT restricted, workflow, max_duration_seconds, updated_at, updated_by
+		 FROM dataset_access_policies
+		 WHERE dataset_id = $1`,
+		datasetID,
+	).Scan(&out.Restricted, &workflow, &maxSeconds, &updatedAt, &out.UpdatedBy)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return datasetAccessPolicy{
+				Approval:    policy.DefaultApprovalWorkflow(),
+				MaxDuration: defaultAccessMaxDuration.String(),
+				maxDuration: defaultAccessMaxDuration,
+			}, nil
+		}
+		return datasetAccessPolicy{}, err
+	}
+	updatedAt = updatedAt.UTC()
+	out.UpdatedAt = &updatedAt
+	out.Approval = decodeAccessWorkflow(workflow)
+	out.maxDuration = time.Duration(maxSeconds) * time.Second
+	out.MaxDuration = out.maxDuration.String()
+	return out, nil
+}
+
+// datasetAccessFor resolves how identity may read versions of a dataset.
+func (api *datasetRegistryAPI) datasetAccessFor(ctx context.Context, datasetID string, identity auth.Identity, now time.Time) (datasetAccess, error) {
+	accessPolicy, err := api.datasetAccessPolicy(ctx, datasetID)
+	if err != nil {
+		return datasetAccess{}, err
+	}
+	if !accessPolicy.Restricted {
+		return datasetAccess{}, nil
+	}
+	access := datasetAccess{Restricted: true}
+	if auth.HasAtLeast(identity.Roles, auth.RoleAdmin) {
+		access.Bypass = true
+		return access, nil
+	}
+	grant, err := scanDatasetAccessGrant(api.db.QueryRowContext(
+		ctx,
+		`SELECT `+datasetAccessGrantColumns+`
+		 FROM dataset_access_grants
+		 WHERE dataset_id = $1 AND subject = $2 AND revoked_at IS NULL AND expires_at > $3
+		 ORDER BY expires_at DESC
+		 LIMIT 1`,
+		datasetID,
+		identity.Subject,
+		now,
+	), now)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return access, nil
+		}
+		return datasetAccess{}, err
+	}
+	access.Grant = &grant
+	return access, nil
+}
+
+// datasetAccessGate refuses reads of restricted datasets without a grant.
+// Refusals are audited; the caller writes nothing further on false.
+func (api *datasetRegistryAPI) datasetAccessGate(w http.ResponseWriter, r *http.Request, identity auth.Identity, datasetID, versionID string, now time.Time) (datasetAccess, bool) {
+	access, err := api.datasetAccessFor(r.Context(), datasetID, identity, now)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return datasetAccess{}, false
+	}
+	if !access.allowed() {
+		api.denyDatasetAccess(w, r, identity.Subject, datasetID, versionID, now)
+		return datasetAccess{}, false
+	}
+	return access, true
+}
+
+// datasetShareAccessAllowed re-checks a one-time share of a restricted
+// dataset at redemption: the grant its creator read under must still be
+// active, unless the creator was an admin.
+func (api *datasetRegistryAPI) datasetShareAccessAllowed(ctx context.Context, datasetID, grantID string, bypass bool, now time.Time) (bool, error) {
+	accessPolicy, err := api.datasetAccessPolicy(ctx, datasetID)
+	if err != nil {
+		return false, err
+	}
+	if !accessPolicy.Restricted || bypass {
+		return true, nil
+	}
+	if grantID == "" {
+		return false, nil
+	}
+	grant, err := scanDatasetAccessGrant(api.db.QueryRowContext(
+		ctx,
+		`SELECT `+datasetAccessGrantColumns+` FROM dataset_access_grants WHERE grant_id = $1`,
+		grantID,
+	), now)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	return grant.Status == accessGrantActive, nil
+}
+
+func (api *datasetRegistryAPI) denyDatasetAccess(w http.ResponseWriter, r *http.Request, actor, datasetID, versionID string, now time.Time) {
+	_ = auditlog.Enqueue(r.Context(), api.db, auditlog.Event{
+		OccurredAt:   now,
+		Actor:        actor,
+		Action:       "dataset_access.deny",
+		ResourceType: "dataset_version",
+		ResourceID:   versionID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload: map[string]any{
+			"service":            "dataset-registry",
+			"dataset_id":         datasetID,
+			"dataset_version_id": versionID,
+			"reason":             "access_not_granted",
+		},
+	})
+	api.writeError(w, r, http.StatusForbidden, "dataset_access_not_granted")
+}
+
+func (api *datasetRegistryAPI) handleGetDatasetAccessPolicy(w http.ResponseWriter, r *http.Request) {
+	_, _, item, ok := api.dataContractDataset(w, r)
+	if !ok {
+		return
+	}
+	accessPolicy, err := api.datasetAccessPolicy(r.Context(), item.ID)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	api.writeJSON(w, http.StatusOK, accessPolicy)
+}
+
+func (api *datasetRegistryAPI) handleSetDatasetAccessPolicy(w http.ResponseWriter, r *http.Request) {
+	identity, projectID, item, ok := api.dataContractDataset(w, r)
+	if !ok {
+		return
+	}
+	var req setDatasetAccessPolicyRequest
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
+		return
+	}
+	workflow, ok := normalizeAccessWorkflow(req.Approval)
+	if !ok {
+		api.writeError(w, r, http.StatusBadRequest, "access_workflow_invalid")
+		return
+	}
+	maxDuration := defaultAccessMaxDuration
+	if strings.TrimSpace(req.MaxDuration) != "" {
+		maxDuration, ok = parseAccessDuration(req.MaxDuration, maxAccessDuration)
+		if !ok {
+			api.writeError(w, r, http.StatusBadRequest, "max_duration_invalid")
+			return
+		}
+	}
+	workflowJSON, err := json.Marshal(workflow)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	now := time.Now().UTC()
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	_, err = tx.ExecContext(
+		r.Context(),
+		`INSERT INTO dataset_access_policies (dataset_id, project_id, restricted, workflow, max_duration_seconds, updated_at, updated_by)
+		 VALUES ($1,$2,$3,$4,$5,$6,$7)
+		 ON CONFLICT (dataset_id) DO UPDATE
+		 SET restricted = EXCLUDED.restricted,
+			 workflow = EXCLUDED.workflow,
+			 max_duration_seconds = EXCLUDED.max_duration_seconds,
+			 updated_at = EXCLUDED.updated_at,
+			 updated_by = EXCLUDED.updated_by`,
+		item.ID,
+		projectID,
+		req.Restricted,
+		workflowJSON,
+		int64(maxDuration/time.Second),
+		now,
+		identity.Subject,
+	)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if err := auditlog.Enqueue(r.Context(), tx, auditlog.Event{
+		OccurredAt:   now,
+		Actor:        identity.Subject,
+		Action:       "dataset.access_policy_set",
+		ResourceType: "dataset",
+		ResourceID:   item.ID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload: map[string]any{
+			"service":      "dataset-registry",
+			"project_id":   projectID,
+			"dataset_id":   item.ID,
+			"restricted":   req.Restricted,
+			"approval":     workflow,
+			"max_duration": maxDuration.String(),
+		},
+	}); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	api.writeJSON(w, http.StatusOK, datasetAccessPolicy{
+		Restricted:  req.Restricted,
+		Approval:    workflow,
+		MaxDuration: maxDuration.String(),
+		UpdatedAt:   &now,
+		UpdatedBy:   identity.Subject,
+	})
+}
+
+// handleCreateDatasetAccessRequest opens a request for the caller. The
+// policy's workflow is snapshotted so later policy edits do not change how
+// an open request is decided.
+func (api *datasetRegistryAPI) handleCreateDatasetAccessRequest(w http.ResponseWriter, r *http.Request) {
+	identity, projectID, item, ok := api.dataContractDataset(w, r)
+	if !ok {
+		return
+	}
+	var req createDatasetAccessRequest
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
+		return
+	}
+	justification := strings.TrimSpace(req.Justification)
+	if justification == "" || len(justification) > maxAccessJustificationLen {
+		api.writeError(w, r, http.StatusBadRequest, "justification_required")
+		return
+	}
+	accessPolicy, err := api.datasetAccessPolicy(r.Context(), item.ID)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if !accessPolicy.Restricted {
+		api.writeError(w, r, http.StatusConflict, "dataset_not_restricted")
+		return
+	}
+	duration, ok := parseAccessDuration(req.Duration, accessPolicy.maxDuration)
+	if !ok {
+		api.writeError(w, r, http.StatusBadRequest, "duration_invalid")
+		return
+	}
+	workflowJSON, err := json.Marshal(accessPolicy.Approval)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	now := time.Now().UTC()
+	out := datasetAccessRequest{
+		RequestID:       uuid.NewString(),
+		DatasetID:       item.ID,
+		RequestedBy:     identity.Subject,
+		Justification:   justification,
+		DurationSeconds: int64(duration / time.Second),
+		Workflow:        accessPolicy.Approval,
+		Status:          accessRequestPending,
+		RequestedAt:     now,
+	}
+	out.StageName = out.stage().Name
+
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	_, err = tx.ExecContext(
+		r.Context(),
+		`INSERT INTO dataset_access_requests (request_id, dataset_id, project_id, requested_by, justification, duration_seconds, workflow, current_stage, status, requested_at)
+		 VALUES ($1,$2,$3,$4,$5,$6,$7,0,$8,$9)`,
+		out.RequestID,
+		item.ID,
+		projectID,
+		identity.Subject,
+		justification,
+		out.DurationSeconds,
+		workflowJSON,
+		accessRequestPending,
+		now,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			api.writeError(w, r, http.StatusConflict, "access_request_pending")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if err := auditlog.Enqueue(r.Context(), tx, auditlog.Event{
+		OccurredAt:   now,
+		Actor:        identity.Subject,
+		Action:       "dataset_access_request.create",
+		ResourceType: "dataset_access_request",
+		ResourceID:   out.RequestID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload: map[string]any{
+			"service":          "dataset-registry",
+			"project_id":       projectID,
+			"dataset_id":       item.ID,
+			"justification":    justification,
+			"duration_seconds": out.DurationSeconds,
+		},
+	}); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	api.writeJSON(w, http.StatusCreated, out)
+}
+
+func (api *datasetRegistryAPI) handleListDatasetAccessRequests(w http.ResponseWriter, r *http.Request) {
+	_, _, item, ok := api.dataContractDataset(w, r)
+	if !ok {
+		return
+	}
+	status := strings.TrimSpace(r.URL.Query().Get("status"))
+	switch status {
+	case "", accessRequestPending, accessRequestApproved, accessRequestRejected:
+	default:
+		api.writeError(w, r, http.StatusBadRequest, "invalid_status")
+		return
+	}
+	rows, err := api.db.QueryContext(
+		r.Context(),
+		`SELECT `+datasetAccessRequestColumns+`
+		 FROM dataset_access_requests
+		 WHERE dataset_id = $1 AND ($2 = '' OR status = $2)
+		 ORDER BY requested_at DESC
+		 LIMIT $3`,
+		item.ID,
+		status,
+		maxAccessListLimit,
+	)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer rows.Close()
+
+	out := make([]datasetAccessRequest, 0)
+	for rows.Next() {
+		req, err := scanDatasetAccessRequest(rows)
+		if err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+		out = append(out, req)
+	}
+	if err := rows.Err(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	api.writeJSON(w, http.StatusOK, map[string]any{"requests": out})
+}
+
+func (api *datasetRegistryAPI) handleGetDatasetAccessRequest(w http.ResponseWriter, r *http.Request) {
+	_, _, item, ok := api.dataContractDataset(w, r)
+	if !ok {
+		return
+	}
+	req, err := scanDatasetAccessRequest(api.db.QueryRowContext(
+		r.Context(),
+		`SELECT `+datasetAccessRequestColumns+` FROM dataset_access_requests WHERE request_id = $1 AND dataset_id = $2`,
+		strings.TrimSpace(r.PathValue("request_id")),
+		item.ID,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if err := api.loadDatasetAccessRequestDetail(r.Context(), &req, time.Now().UTC()); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	api.writeJSON(w, http.StatusOK, req)
+}
+
+func (api *datasetRegistryAPI) handleApproveDatasetAccessRequest(w http.ResponseWriter, r *http.Request) {
+	api.voteDatasetAccessRequest(w, r, accessVoteApprove)
+}
+
+func (api *datasetRegistryAPI) handleRejectDatasetAccessRequest(w http.ResponseWriter, r *http.Request) {
+	api.voteDatasetAccessRequest(w, r, accessVoteReject)
+}
+
+// voteDatasetAccessRequest records a vote in the current stage. A reject
+// closes the request; an approve that completes the last stage issues the
+// grant, which expires after the requested duration.
+func (api *datasetRegistryAPI) voteDatasetAccessRequest(w http.ResponseWriter, r *http.Request, vote string) {
+	identity, projectID, item, ok := api.dataContractDataset(w, r)
+	if !ok {
+		return
+	}
+	var body datasetAccessDecisionRequest
+	if r.ContentLength != 0 {
+		if err := httpapi.DecodeJSON(r, &body); err != nil {
+			httpapi.WriteDecodeError(w, r, err)
+			return
+		}
+	}
+	reason := strings.TrimSpace(body.Reason)
+
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	req, err := scanDatasetAccessRequest(tx.QueryRowContext(
+		r.Context(),
+		`SELECT `+datasetAccessRequestColumns+` FROM dataset_access_requests WHERE request_id = $1 AND dataset_id = $2 FOR UPDATE`,
+		strings.TrimSpace(r.PathValue("request_id")),
+		item.ID,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if req.Status != accessRequestPending {
+		api.writeError(w, r, http.StatusConflict, "access_request_not_pending")
+		return
+	}
+	if code := accessVoterError(req, identity); code != "" {
+		api.writeError(w, r, http.StatusForbidden, code)
+		return
+	}
+	var voted bool
+	if err := tx.QueryRowContext(
+		r.Context(),
+		`SELECT EXISTS (SELECT 1 FROM dataset_access_request_votes WHERE request_id = $1 AND voter = $2)`,
+		req.RequestID,
+		identity.Subject,
+	).Scan(&voted); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if voted {
+		api.writeError(w, r, http.StatusConflict, "approval_already_voted")
+		return
+	}
+
+	now := time.Now().UTC()
+	stage := req.stage()
+	cast := datasetAccessVote{
+		VoteID:    uuid.NewString(),
+		Stage:     req.CurrentStage,
+		StageName: stage.Name,
+		Voter:     identity.Subject,
+		Vote:      vote,
+		Reason:    reason,
+		CreatedAt: now,
+	}
+	type integrityInput struct {
+		VoteID    string    `json:"vote_id"`
+		RequestID string    `json:"request_id"`
+		Stage     int       `json:"stage"`
+		StageName string    `json:"stage_name"`
+		Voter     string    `json:"voter"`
+		Vote      string    `json:"vote"`
+		Reason    string    `json:"reason,omitempty"`
+		CreatedAt time.Time `json:"created_at"`
+	}
+	integrity, err := integritySHA256(integrityInput{
+		VoteID:    cast.VoteID,
+		RequestID: req.RequestID,
+		Stage:     cast.Stage,
+		StageName: cast.StageName,
+		Voter:     cast.Voter,
+		Vote:      cast.Vote,
+		Reason:    cast.Reason,
+		CreatedAt: now,
+	})
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if _, err := tx.ExecContext(
+		r.Context(),
+		`INSERT INTO dataset_access_request_votes (vote_id, request_id, stage, stage_name, voter, vote, reason, created_at, integrity_sha256)
+		 VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)`,
+		cast.VoteID,
+		req.RequestID,
+		cast.Stage,
+		cast.StageName,
+		cast.Voter,
+		cast.Vote,
+		nullString(reason),
+		now,
+		integrity,
+	); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	stageComplete := false
+	if vote == accessVoteApprove {
+		var approvals int
+		if err := tx.QueryRowContext(
+			r.Context(),
+			`SELECT COUNT(*) FROM dataset_access_request_votes WHERE request_id = $1 AND stage = $2 AND vote = $3`,
+			req.RequestID,
+			req.CurrentStage,
+			accessVoteApprove,
+		).Scan(&approvals); err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+		stageComplete = approvals >= stage.Approvers()
+	}
+
+	switch {
+	case vote == accessVoteReject:
+		req.Status = accessRequestRejected
+	case stageComplete && req.lastStage():
+		req.Status = accessRequestApproved
+	case stageComplete:
+		req.CurrentStage++
+		req.StageName = req.stage().Name
+	}
+	if req.Status != accessRequestPending {
+		req.DecidedAt = &now
+		req.DecidedBy = identity.Subject
+	}
+	if _, err := tx.ExecContext(
+		r.Context(),
+		`UPDATE dataset_access_requests
+		 SET status = $2, current_stage = $3, decided_at = $4, decided_by = $5
+		 WHERE request_id = $1`,
+		req.RequestID,
+		req.Status,
+		req.CurrentStage,
+		req.DecidedAt,
+		nullString(req.DecidedBy),
+	); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	if req.Status == accessRequestApproved {
+		grant := datasetAccessGrant{
+			GrantID:   uuid.NewString(),
+			RequestID: req.RequestID,
+			DatasetID: item.ID,
+			Subject:   req.RequestedBy,
+			Status:    accessGrantActive,
+			GrantedAt: now,
+			ExpiresAt: now.Add(time.Duration(req.DurationSeconds) * time.Second),
+		}
+		if _, err := tx.ExecContext(
+			r.Context(),
+			`INSERT INTO dataset_access_grants (grant_id, request_id, dataset_id, project_id, subject, granted_at, expires_at)
+			 VALUES ($1,$2,$3,$4,$5,$6,$7)`,
+			grant.GrantID,
+			grant.RequestID,
+			grant.DatasetID,
+			projectID,
+			grant.Subject,
+			grant.GrantedAt,
+			grant.ExpiresAt,
+		); err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+		if err := auditlog.Enqueue(r.Context(), tx, auditlog.Event{
+			OccurredAt:   now,
+			Actor:        identity.Subject,
+			Action:       "dataset_access.grant",
+			ResourceType: "dataset_access_grant",
+			ResourceID:   grant.GrantID,
+			RequestID:    r.Header.Get("X-Request-Id"),
+			IP:           httpapi.RequestIP(r.RemoteAddr),
+			UserAgent:    r.UserAgent(),
+			Payload: map[string]any{
+				"service":           "dataset-registry",
+				"project_id":        projectID,
+				"dataset_id":        item.ID,
+				"access_request_id": req.RequestID,
+				"subject":           grant.Subject,
+				"expires_at":        grant.ExpiresAt.Format(time.RFC3339Nano),
+			},
+		}); err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+		req.Grant = &grant
+	}
+
+	if err := auditlog.Enqueue(r.Context(), tx, auditlog.Event{
+		OccurredAt:   now,
+		Actor:        identity.Subject,
+		Action:       "dataset_access_request." + vote,
+		ResourceType: "dataset_access_request",
+		ResourceID:   req.RequestID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload: map[string]any{
+			"service":      "dataset-registry",
+			"project_id":   projectID,
+			"dataset_id":   item.ID,
+			"requested_by": req.RequestedBy,
+			"stage":        cast.Stage,
+			"stage_name":   cast.StageName,
+			"status":       req.Status,
+			"reason":       reason,
+		},
+	}); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	if err := api.loadDatasetAccessRequestDetail(r.Context(), &req, now); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	api.writeJSON(w, http.StatusOK, req)
+}
+
+func (api *datasetRegistryAPI) handleListDatasetAccessGrants(w http.ResponseWriter, r *http.Request) {
+	_, _, item, ok := api.dataContractDataset(w, r)
+	if !ok {
+		return
+	}
+	now := time.Now().UTC()
+	rows, err := api.db.QueryContext(
+		r.Context(),
+		`SELECT `+datasetAccessGrantColumns+`
+		 FROM dataset_access_grants
+		 WHERE dataset_id = $1
+		 ORDER BY granted_at DESC
+		 LIMIT $2`,
+		item.ID,
+		maxAccessListLimit,
+	)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer rows.Close()
+
+	out := make([]datasetAccessGrant, 0)
+	for rows.Next() {
+		grant, err := scanDatasetAccessGrant(rows, now)
+		if err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+		out = append(out, grant)
+	}
+	if err := rows.Err(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	api.writeJSON(w, http.StatusOK, map[string]any{"grants": out})
+}
+
+// handleRevokeDatasetAccessGrant ends a grant before it expires. One-time
+// shares created under the grant stop redeeming with it.
+func (api *datasetRegistryAPI) handleRevokeDatasetAccessGrant(w http.ResponseWriter, r *http.Request) {
+	identity, projectID, item, ok := api.dataContractDataset(w, r)
+	if !ok {
+		return
+	}
+	var body datasetAccessDecisionRequest
+	if r.ContentLength != 0 {
+		if err := httpapi.DecodeJSON(r, &body); err != nil {
+			httpapi.WriteDecodeError(w, r, err)
+			return
+		}
+	}
+	reason := strings.TrimSpace(body.Reason)
+
+	now := time.Now().UTC()
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	grant, err := scanDatasetAccessGrant(tx.QueryRowContext(
+		r.Context(),
+		`SELECT `+datasetAccessGrantColumns+` FROM dataset_access_grants WHERE grant_id = $1 AND dataset_id = $2 FOR UPDATE`,
+		strings.TrimSpace(r.PathValue("grant_id")),
+		item.ID,
+	), now)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if grant.Status != accessGrantActive {
+		api.writeError(w, r, http.StatusConflict, "access_grant_not_active")
+		return
+	}
+	if _, err := tx.ExecContext(
+		r.Context(),
+		`UPDATE dataset_access_grants SET revoked_at = $2, revoked_by = $3, revoke_reason = $4 WHERE grant_id = $1`,
+		grant.GrantID,
+		now,
+		identity.Subject,
+		nullString(reason),
+	); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if err := auditlog.Enqueue(r.Context(), tx, auditlog.Event{
+		OccurredAt:   now,
+		Actor:        identity.Subject,
+		Action:       "dataset_access.revoke",
+		ResourceType: "dataset_access_grant",
+		ResourceID:   grant.GrantID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload: map[string]any{
+			"service":           "dataset-registry",
+			"project_id":        projectID,
+			"dataset_id":        item.ID,
+			"access_request_id": grant.RequestID,
+			"subject":           grant.Subject,
+			"reason":            reason,
+		},
+	}); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	grant.Status = accessGrantRevoked
+	grant.RevokedAt = &now
+	grant.RevokedBy = identity.Subject
+	grant.RevokeReason = reason
+	api.writeJSON(w, http.StatusOK, grant)
+}
+
+// loadDatasetAccessRequestDetail fills the votes and grant of req.
+func (api *datasetRegistryAPI) loadDatasetAccessRequestDetail(ctx context.Context, req *datasetAccessRequest, now time.Time) error {
+	rows, err := api.db.QueryContext(
+		ctx,
+		`SELECT vote_id, stage, stage_name, voter, vote, reason, created_at
+		 FROM dataset_access_request_votes
+		 WHERE request_id = $1
+		 ORDER BY created_at ASC`,
+		req.RequestID,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	req.Votes = make([]datasetAccessVote, 0)
+	for rows.Next() {
+		var (
+			vote   datasetAccessVote
+			reason sql.NullString
+		)
+		if err := rows.Scan(&vote.VoteID, &vote.Stage, &vote.StageName, &vote.Voter, &vote.Vote, &reason, &vote.CreatedAt); err != nil {
+			return err
+		}
+		vote.Reason = reason.String
+		vote.CreatedAt = vote.CreatedAt.UTC()
+		req.Votes = append(req.Votes, vote)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	grant, err := scanDatasetAccessGrant(api.db.QueryRowContext(
+		ctx,
+		`SELECT `+datasetAccessGrantColumns+` FROM dataset_access_grants WHERE request_id = $1`,
+		req.RequestID,
+	), now)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+	req.Grant = &grant
+	return nil
+}
+
+const datasetAccessRequestColumns = `request_id, dataset_id, requested_by, justification, duration_seconds, workflow, current_stage, status,
+	requested_at, decided_at, decided_by`
+
+func scanDatasetAccessRequest(row dataContractScanner) (datasetAccessRequest, error) {
+	var (
+		out       datasetAccessRequest
+		workflow  []byte
+		decidedAt sql.NullTime
+		decidedBy sql.NullString
+	)
+	if err := row.Scan(
+		&out.RequestID,
+		&out.DatasetID,
+		&out.RequestedBy,
+		&out.Justification,
+		&out.DurationSeconds,
+		&workflow,
+		&out.CurrentStage,
+		&out.Status,
+		&out.RequestedAt,
+		&decidedAt,
+		&decidedBy,
+	); err != nil {
+		return datasetAccessRequest{}, err
+	}
+	out.Workflow = decodeAccessWorkflow(workflow)
+	if out.Status == accessRequestPending {
+		out.StageName = out.stage().Name
+	}
+	out.RequestedAt = out.RequestedAt.UTC()
+	if decidedAt.Valid {
+		t := decidedAt.Time.UTC()
+		out.DecidedAt = &t
+	}
+	out.DecidedBy = decidedBy.String
+	return out, nil
+}
+
+const datasetAccessGrantColumns = `grant_id, request_id, dataset_id, subject, granted_at, expires_at, revoked_at, revoked_by, revoke_reason`
+
+func scanDatasetAccessGrant(row dataContractScanner, now time.Time) (datasetAccessGrant, error) {
+	var (
+		out          datasetAccessGrant
+		revokedAt    sql.NullTime
+		revokedBy    sql.NullString
+		revokeReason sql.NullString
+	)
+	if err := row.Scan(
+		&out.GrantID,
+		&out.RequestID,
+		&out.DatasetID,
+		&out.Subject,
+		&out.GrantedAt,
+		&out.ExpiresAt,
+		&revokedAt,
+		&revokedBy,
+		&revokeReason,
+	); err != nil {
+		return datasetAccessGrant{}, err
+	}
+	out.GrantedAt = out.GrantedAt.UTC()
+	out.ExpiresAt = out.ExpiresAt.UTC()
+	if revokedAt.Valid {
+		t := revokedAt.Time.UTC()
+		out.RevokedAt = &t
+	}
+	out.RevokedBy = revokedBy.String
+	out.RevokeReason = revokeReason.String
+	out.Status = accessGrantStatus(out.RevokedAt, out.ExpiresAt, now)
+	return out, nil
+}
@@ -0,0 +1,85 @@
+package datasetregistry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/policy"
+)
+
+func TestAccessVoterError(t *testing.T) {
+	req := datasetAccessRequest{
+		RequestedBy: "alice",
+		Workflow: policy.ApprovalWorkflow{Stages: []policy.ApprovalStage{
+			{Name: "owner", Roles: []string{"data-owner"}},
+			{Name: "security"},
+		}},
+	}
+	cases := []struct {
+		stage    int
+		identity auth.Identity
+		want     string
+	}{
+		{0, auth.Identity{Subject: "alice", Roles: []string{auth.RoleAdmin, "data-owner"}}, "approval_requires_second_reviewer"},
+		{0, auth.Identity{Subject: "bob", Roles: []string{auth.RoleAdmin}}, "approval_stage_role_required"},
+		{0, auth.Identity{Subject: "bob", Roles: []string{auth.RoleViewer, "Data-Owner"}}, ""},
+		{1, auth.Identity{Subject: "carol", Roles: []string{auth.RoleEditor}}, "approval_requires_admin"},
+		{1, auth.Identity{Subject: "carol", Roles: []string{auth.RoleAdmin}}, ""},
+	}
+	for _, tc := range cases {
+		req.CurrentStage = tc.stage
+		if got := accessVoterError(req, tc.identity); got != tc.want {
+			t.Errorf("stage %d %s: got %q, want %q", tc.stage, tc.identity.Subject, got, tc.want)
+		}
+	}
+}
+
+func TestNormalizeAccessWorkflow(t *testing.T) {
+	if got, ok := normalizeAccessWorkflow(nil); !ok || len(got.Stages) != 1 || got.Stages[0].Name != "review" {
+		t.Fatalf("default workflow = %+v, %v", got, ok)
+	}
+	escalating := &policy.ApprovalWorkflow{Stages: []policy.ApprovalStage{
+		{Name: "review", Escalation: &policy.ApprovalEscalation{After: "24h", Action: policy.EscalationDeny}},
+	}}
+	if _, ok := normalizeAccessWorkflow(escalating); ok {
+		t.Fatal("escalating workflow accepted")
+	}
+	if _, ok := normalizeAccessWorkflow(&policy.ApprovalWorkflow{}); ok {
+		t.Fatal("empty workflow accepted")
+	}
+}
+
+func TestParseAccessDuration(t *testing.T) {
+	for raw, want := range map[string]time.Duration{
+		"72h":      72 * time.Hour,
+		"90m30.5s": 90*time.Minute + 30*time.Second,
+		"721h":     0,
+		"0s":       0,
+		"-1h":      0,
+		"500ms":    0,
+		"a week":   0,
+	} {
+		got, ok := parseAccessDuration(raw, defaultAccessMaxDuration)
+		if got != want || ok != (want > 0) {
+			t.Errorf("parseAccessDuration(%q) = %v, %v", raw, got, ok)
+		}
+	}
+}
+
+func TestAccessGrantStatus(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	revoked := now.Add(-time.Hour)
+	if got := accessGrantStatus(nil, now.Add(time.Hour), now); got != accessGrantActive {
+		t.Fatalf("status = %q", got)
+	}
+	if got := accessGrantStatus(nil, now, now); got != accessGrantExpired {
+		t.Fatalf("status at expiry = %q", got)
+	}
+	if got := accessGrantStatus(&revoked, now.Add(time.Hour), now); got != accessGrantRevoked {
+		t.Fatalf("revoked status = %q", got)
+	}
+	if (datasetAccess{Restricted: true}).allowed() || !(datasetAccess{Restricted: true, Bypass: true}).allowed() {
+		t.Fatal("datasetAccess.allowed")
+	}
+}
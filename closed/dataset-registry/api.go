@@ -1,4 +1,4 @@
-package main
+package datasetregistry
 
 import (
 	"context"
@@ -10,20 +10,25 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
-	"net"
 	"net/http"
 	"path"
-	"strconv"
 	"strings"
 	"time"
 
 	"github.com/animus-labs/animus-go/closed/internal/domain"
+	"github.com/animus-labs/animus-go/closed/internal/integrations/eventbus"
+	"github.com/animus-labs/animus-go/closed/internal/integrations/webhooks"
 	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
 	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/dataprofile"
+	"github.com/animus-labs/animus-go/closed/internal/platform/envelope"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
 	"github.com/animus-labs/animus-go/closed/internal/platform/lineageevent"
 	"github.com/animus-labs/animus-go/closed/internal/platform/objectstore"
+	"github.com/animus-labs/animus-go/closed/internal/platform/outbox/servicerelay"
 	"github.com/animus-labs/animus-go/closed/internal/platform/redaction"
 	"github.com/animus-labs/animus-go/closed/internal/repo"
+	repopg "github.com/animus-labs/animus-go/closed/internal/repo/postgres"
 	artifactsvc "github.com/animus-labs/animus-go/closed/internal/service/artifacts"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -31,17 +36,28 @@ import (
 )
 
 type datasetRegistryAPI struct {
-	logger         *slog.Logger
-	db             *sql.DB
-	store          *minio.Client
-	storeCfg       objectstore.Config
-	uploadMaxBytes int64
-	uploadTimeout  time.Duration
-	svc            *datasetService
-	artifactSvc    *artifactsvc.Service
+	logger            *slog.Logger
+	db                *sql.DB
+	store             *minio.Client
+	storeCfg          objectstore.Config
+	uploadMaxBytes    int64
+	uploadTimeout     time.Duration
+	svc               *datasetService
+	artifactSvc       *artifactsvc.Service
+	webhookCfg        webhooks.Config
+	encrypter         *envelope.Encrypter
+	shareCfg          shareConfig
+	replicationCfg    replicationConfig
+	externalSourceCfg externalSourceConfig
+	referenceCfg      referenceConfig
+	// profileUploads is the default for the optional "profile" upload field.
+	profileUploads bool
+	// bus enqueues dataset version and data contract events for the
+	// message bus.
+	bus servicerelay.Bus
 }
 
-func newDatasetRegistryAPI(logger *slog.Logger, db *sql.DB, store *minio.Client, storeCfg objectstore.Config, uploadMaxBytes int64, uploadTimeout time.Duration, svc *datasetService, artifactSvc *artifactsvc.Service) *datasetRegistryAPI {
+func newDatasetRegistryAPI(logger *slog.Logger, db *sql.DB, store *minio.Client, storeCfg objectstore.Config, uploadMaxBytes int64, uploadTimeout time.Duration, svc *datasetService, artifactSvc *artifactsvc.Service, webhookCfg webhooks.Config, encrypter *envelope.Encrypter) *datasetRegistryAPI {
 	if uploadMaxBytes <= 0 {
 		uploadMaxBytes = int64(2) << 30 // 2 GiB
 	}
@@ -57,6 +73,8 @@ func newDatasetRegistryAPI(logger *slog.Logger, db *sql.DB, store *minio.Client,
 		uploadTimeout:  uploadTimeout,
 		svc:            svc,
 		artifactSvc:    artifactSvc,
+		webhookCfg:     webhookCfg,
+		encrypter:      encrypter,
 	}
 }
 
@@ -71,10 +89,25 @@ func (api *datasetRegistryAPI) register(mux *http.ServeMux) {
 
 	mux.HandleFunc("GET /datasets/{dataset_id}/versions", api.handleListDatasetVersions)
 	mux.HandleFunc("POST /datasets/{dataset_id}/versions/upload", api.handleUploadDatasetVersion)
+	mux.HandleFunc("GET /datasets/{dataset_id}/versions/{version_id}/download", api.handleDownloadDatasetVersion)
 
 	mux.HandleFunc("GET /dataset-versions/{version_id}", api.handleGetDatasetVersion)
 	mux.HandleFunc("GET /dataset-versions/{version_id}/download", api.handleDownloadDatasetVersion)
 
+	api.registerDataContracts(mux)
+	api.registerDataContractMigrations(mux)
+	api.registerFreshness(mux)
+	api.registerProtection(mux)
+	api.registerShares(mux)
+	api.registerReplication(mux)
+	api.registerExternalSources(mux)
+	api.registerReferenceVersions(mux)
+	api.registerAccessRequests(mux)
+	api.registerLifecycle(mux)
+	api.registerProjects(mux)
+	api.registerProfiles(mux)
+	api.registerDrift(mux)
+
 	mux.HandleFunc("POST /projects/{project_id}/artifacts", api.handleCreateArtifact)
 	mux.HandleFunc("GET /projects/{project_id}/artifacts/{artifact_id}", api.handleGetArtifact)
 	mux.HandleFunc("GET /projects/{project_id}/artifacts/{artifact_id}/download", api.handleDownloadArtifact)
@@ -88,6 +121,12 @@ type dataset struct {
 	Metadata    json.RawMessage `json:"metadata"`
 	CreatedAt   time.Time       `json:"created_at"`
 	CreatedBy   string          `json:"created_by"`
+	// LifecycleState is active, deprecated or archived.
+	LifecycleState string `json:"lifecycle_state"`
+	// Freshness is set when the dataset has a freshness expectation.
+	Freshness *datasetFreshness `json:"freshness,omitempty"`
+	// Protection is set when the dataset has classification labels or residency.
+	Protection *datasetProtection `json:"protection,omitempty"`
 }
 
 type datasetVersion struct {
@@ -102,6 +141,26 @@ type datasetVersion struct {
 	Metadata      json.RawMessage `json:"metadata"`
 	CreatedAt     time.Time       `json:"created_at"`
 	CreatedBy     string          `json:"created_by"`
+	// DataContractEvaluation is set on upload when the dataset has an approved contract.
+	DataContractEvaluation *dataContractEvaluation `json:"data_contract_evaluation,omitempty"`
+	Encryption             *objectEncryption       `json:"encryption,omitempty"`
+	Recall                 *datasetVersionRecall   `json:"recall,omitempty"`
+	// Profile is set on upload when the version was profiled.
+	Profile *datasetVersionProfile `json:"profile,omitempty"`
+}
+
+// objectEncryption exposes which key protects a stored object; the wrapped
+// data key never leaves the registry.
+type objectEncryption struct {
+	Algorithm string `json:"algorithm"`
+	KeyID     string `json:"key_id"`
+}
+
+func objectEncryptionResponse(value domain.ObjectEncryption) *objectEncryption {
+	if !value.Enabled() {
+		return nil
+	}
+	return &objectEncryption{Algorithm: value.Algorithm, KeyID: value.KeyID}
 }
 
 type artifact struct {
@@ -126,6 +185,10 @@ type project struct {
 	Metadata    json.RawMessage `json:"metadata"`
 	CreatedAt   time.Time       `json:"created_at"`
 	CreatedBy   string          `json:"created_by"`
+	UpdatedAt   *time.Time      `json:"updated_at,omitempty"`
+	UpdatedBy   string          `json:"updated_by,omitempty"`
+	ArchivedAt  *time.Time      `json:"archived_at,omitempty"`
+	ArchivedBy  string          `json:"archived_by,omitempty"`
 }
 
 type projectListResponse struct {
@@ -166,8 +229,8 @@ func (api *datasetRegistryAPI) handleCreateProject(w http.ResponseWriter, r *htt
 	}
 
 	var req createProjectRequest
-	if err := decodeJSON(r, &req); err != nil {
-		api.writeError(w, r, http.StatusBadRequest, "invalid_json")
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
 		return
 	}
 	name := strings.TrimSpace(req.Name)
@@ -188,16 +251,8 @@ func (api *datasetRegistryAPI) handleCreateProject(w http.ResponseWriter, r *htt
 		return
 	}
 
-	metaJSON, _ := json.Marshal(proj.Metadata)
 	w.Header().Set("Location", "/projects/"+proj.ID)
-	api.writeJSON(w, http.StatusCreated, project{
-		ProjectID:   proj.ID,
-		Name:        proj.Name,
-		Description: proj.Description,
-		Metadata:    metaJSON,
-		CreatedAt:   proj.CreatedAt,
-		CreatedBy:   proj.CreatedBy,
-	})
+	api.writeJSON(w, http.StatusCreated, projectResponse(proj))
 }
 
 func (api *datasetRegistryAPI) handleListProjects(w http.ResponseWriter, r *http.Request) {
@@ -211,8 +266,9 @@ func (api *datasetRegistryAPI) handleListProjects(w http.ResponseWriter, r *http
 		return
 	}
 
-	limit := clampInt(parseIntQuery(r, "limit", 100), 1, 500)
-	projects, err := api.svc.ListProjects(r.Context(), identity.Subject, limit)
+	limit := httpapi.Limit(r, 100, 500)
+	includeArchived := strings.EqualFold(strings.TrimSpace(r.URL.Query().Get("include_archived")), "true")
+	projects, err := api.svc.ListProjects(r.Context(), identity.Subject, includeArchived, limit)
 	if err != nil {
 		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
 		return
@@ -220,15 +276,7 @@ func (api *datasetRegistryAPI) handleListProjects(w http.ResponseWriter, r *http
 
 	out := make([]project, 0, len(projects))
 	for _, proj := range projects {
-		metaJSON, _ := json.Marshal(proj.Metadata)
-		out = append(out, project{
-			ProjectID:   proj.ID,
-			Name:        proj.Name,
-			Description: proj.Description,
-			Metadata:    metaJSON,
-			CreatedAt:   proj.CreatedAt,
-			CreatedBy:   proj.CreatedBy,
-		})
+		out = append(out, projectResponse(proj))
 	}
 	api.writeJSON(w, http.StatusOK, projectListResponse{Projects: out})
 }
@@ -253,15 +301,7 @@ func (api *datasetRegistryAPI) handleGetProject(w http.ResponseWriter, r *http.R
 		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
 		return
 	}
-	metaJSON, _ := json.Marshal(proj.Metadata)
-	api.writeJSON(w, http.StatusOK, project{
-		ProjectID:   proj.ID,
-		Name:        proj.Name,
-		Description: proj.Description,
-		Metadata:    metaJSON,
-		CreatedAt:   proj.CreatedAt,
-		CreatedBy:   proj.CreatedBy,
-	})
+	api.writeJSON(w, http.StatusOK, projectResponse(proj))
 }
 
 func (api *datasetRegistryAPI) handleCreateDataset(w http.ResponseWriter, r *http.Request) {
@@ -281,9 +321,13 @@ func (api *datasetRegistryAPI) handleCreateDataset(w http.ResponseWriter, r *htt
 		return
 	}
 
+	if _, ok := api.activeProjectSettings(w, r, projectID); !ok {
+		return
+	}
+
 	var req createDatasetRequest
-	if err := decodeJSON(r, &req); err != nil {
-		api.writeError(w, r, http.StatusBadRequest, "invalid_json")
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
 		return
 	}
 	name := strings.TrimSpace(req.Name)
@@ -318,7 +362,7 @@ func (api *datasetRegistryAPI) handleCreateDataset(w http.ResponseWriter, r *htt
 }
 
 func (api *datasetRegistryAPI) handleListDatasets(w http.ResponseWriter, r *http.Request) {
-	limit := clampInt(parseIntQuery(r, "limit", 100), 1, 500)
+	limit := httpapi.Limit(r, 100, 500)
 	if api.svc == nil {
 		api.writeError(w, r, http.StatusInternalServerError, "service_unavailable")
 		return
@@ -329,23 +373,62 @@ func (api *datasetRegistryAPI) handleListDatasets(w http.ResponseWriter, r *http
 		return
 	}
 
+	// stale filters the returned page; datasets without an expectation are never stale.
+	staleFilter := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("stale")))
+	if staleFilter != "" && staleFilter != "true" && staleFilter != "false" {
+		api.writeError(w, r, http.StatusBadRequest, "stale_invalid")
+		return
+	}
+	lifecycleFilter := domain.DatasetLifecycleState(strings.ToLower(strings.TrimSpace(r.URL.Query().Get("lifecycle_state"))))
+	if lifecycleFilter != "" && !lifecycleFilter.Valid() {
+		api.writeError(w, r, http.StatusBadRequest, "lifecycle_state_invalid")
+		return
+	}
+
 	items, err := api.svc.ListDatasets(r.Context(), projectID, limit)
 	if err != nil {
 		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
 		return
 	}
+	freshness, err := api.projectFreshness(r.Context(), projectID, time.Now().UTC())
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	lifecycleStates, err := api.projectLifecycleStates(r.Context(), projectID)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
 
 	out := make([]dataset, 0, len(items))
 	for _, item := range items {
+		var itemFreshness *datasetFreshness
+		if value, ok := freshness[item.ID]; ok {
+			itemFreshness = &value
+		}
+		stale := itemFreshness != nil && itemFreshness.Stale
+		if (staleFilter == "true" && !stale) || (staleFilter == "false" && stale) {
+			continue
+		}
+		state, ok := lifecycleStates[item.ID]
+		if !ok {
+			state = domain.DatasetLifecycleActive
+		}
+		if lifecycleFilter != "" && state != lifecycleFilter {
+			continue
+		}
 		metaJSON, _ := json.Marshal(item.Metadata)
 		out = append(out, dataset{
-			DatasetID:   item.ID,
-			ProjectID:   item.ProjectID,
-			Name:        item.Name,
-			Description: item.Description,
-			Metadata:    metaJSON,
-			CreatedAt:   item.CreatedAt,
-			CreatedBy:   item.CreatedBy,
+			DatasetID:      item.ID,
+			ProjectID:      item.ProjectID,
+			Name:           item.Name,
+			Description:    item.Description,
+			Metadata:       metaJSON,
+			CreatedAt:      item.CreatedAt,
+			CreatedBy:      item.CreatedBy,
+			LifecycleState: string(state),
+			Freshness:      itemFreshness,
 		})
 	}
 
@@ -377,15 +460,33 @@ func (api *datasetRegistryAPI) handleGetDataset(w http.ResponseWriter, r *http.R
 		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
 		return
 	}
+	freshness, err := api.datasetFreshness(r.Context(), projectID, item.ID, time.Now().UTC())
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	protection, err := api.datasetProtection(r.Context(), item.ID)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	lifecycle, err := api.datasetLifecycle(r.Context(), item.ID)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
 	metaJSON, _ := json.Marshal(item.Metadata)
 	api.writeJSON(w, http.StatusOK, dataset{
-		DatasetID:   item.ID,
-		ProjectID:   item.ProjectID,
-		Name:        item.Name,
-		Description: item.Description,
-		Metadata:    metaJSON,
-		CreatedAt:   item.CreatedAt,
-		CreatedBy:   item.CreatedBy,
+		DatasetID:      item.ID,
+		ProjectID:      item.ProjectID,
+		Name:           item.Name,
+		Description:    item.Description,
+		Metadata:       metaJSON,
+		CreatedAt:      item.CreatedAt,
+		CreatedBy:      item.CreatedBy,
+		LifecycleState: string(lifecycle.State),
+		Freshness:      freshness,
+		Protection:     protection,
 	})
 }
 
@@ -396,7 +497,7 @@ func (api *datasetRegistryAPI) handleListDatasetVersions(w http.ResponseWriter,
 		return
 	}
 
-	limit := clampInt(parseIntQuery(r, "limit", 100), 1, 500)
+	limit := httpapi.Limit(r, 100, 500)
 	if api.svc == nil {
 		api.writeError(w, r, http.StatusInternalServerError, "service_unavailable")
 		return
@@ -428,6 +529,7 @@ func (api *datasetRegistryAPI) handleListDatasetVersions(w http.ResponseWriter,
 			Metadata:      metaJSON,
 			CreatedAt:     version.CreatedAt,
 			CreatedBy:     version.CreatedBy,
+			Encryption:    objectEncryptionResponse(version.Encryption),
 		})
 	}
 
@@ -477,6 +579,24 @@ func (api *datasetRegistryAPI) handleUploadDatasetVersion(w http.ResponseWriter,
 		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
 		return
 	}
+	settings, ok := api.activeProjectSettings(w, r, projectID)
+	if !ok {
+		return
+	}
+	lifecycle, err := api.datasetLifecycle(r.Context(), datasetID)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if lifecycle.State == domain.DatasetLifecycleArchived {
+		api.writeError(w, r, http.StatusConflict, "dataset_archived")
+		return
+	}
+	contract, err := api.activeDataContract(r.Context(), datasetID)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
 
 	now := time.Now().UTC()
 	versionID := uuid.NewString()
@@ -504,7 +624,16 @@ func (api *datasetRegistryAPI) handleUploadDatasetVersion(w http.ResponseWriter,
 		filename          string
 		contentType       string
 		qualityRuleID     string
+		encryption        domain.ObjectEncryption
+		head              = &headWriter{limit: dataContractHeaderBytes}
+		profileEnabled    = api.profileUploads
+		profiler          *dataprofile.Profiler
 	)
+	defer func() { profiler.Abort() }()
+	// Typed or non-nullable contract columns are checked against the profile.
+	if contract != nil && contract.Schema.observesValues() {
+		profileEnabled = true
+	}
 
 	for {
 		part, err := mr.NextPart()
@@ -564,6 +693,16 @@ func (api *datasetRegistryAPI) handleUploadDatasetVersion(w http.ResponseWriter,
 				return
 			}
 			qualityRuleID = strings.TrimSpace(string(raw))
+		case "profile":
+			raw, err := io.ReadAll(io.LimitReader(part, 16))
+			_ = part.Close()
+			enabled, ok := parseProfileField(raw)
+			// The stream is profiled while it is stored, so the flag must precede the file.
+			if err != nil || !ok || uploadedObjectKey != "" {
+				api.writeError(w, r, http.StatusBadRequest, "invalid_profile")
+				return
+			}
+			profileEnabled = enabled
 		case "file":
 			if uploadedObjectKey != "" {
 				_ = part.Close()
@@ -580,7 +719,34 @@ func (api *datasetRegistryAPI) handleUploadDatasetVersion(w http.ResponseWriter,
 			uploadedObjectKey = fmt.Sprintf("%s/%s/%s", datasetID, versionID, filename)
 			hasher := sha256.New()
 			counter := &countingWriter{}
-			reader := io.TeeReader(part, io.MultiWriter(hasher, counter))
+			sinks := []io.Writer{hasher, counter, head}
+			if profileEnabled {
+				if profiler = dataprofile.New(dataprofile.DetectFormat(filename, contentType)); profiler != nil {
+					sinks = append(sinks, profiler)
+				}
+			}
+			var reader io.Reader = io.TeeReader(part, io.MultiWriter(sinks...))
+			storedContentType := contentType
+			if api.encrypter.Enabled() {
+				objectKey, err := api.encrypter.NewObjectKey(r.Context())
+				if err != nil {
+					_ = part.Close()
+					api.writeError(w, r, http.StatusBadGateway, "encryption_key_unavailable")
+					return
+				}
+				reader, err = envelope.EncryptReader(objectKey.DataKey, reader)
+				if err != nil {
+					_ = part.Close()
+					api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+					return
+				}
+				encryption = domain.ObjectEncryption{
+					Algorithm:  objectKey.Algorithm,
+					KeyID:      objectKey.KeyID,
+					WrappedKey: objectKey.WrappedKey,
+				}
+				storedContentType = "application/octet-stream"
+			}
 
 			uploadCtx, cancel := context.WithTimeout(r.Context(), api.uploadTimeout)
 			_, putErr := api.store.PutObject(
@@ -589,7 +755,7 @@ func (api *datasetRegistryAPI) handleUploadDatasetVersion(w http.ResponseWriter,
 				uploadedObjectKey,
 				reader,
 				-1,
-				minio.PutObjectOptions{ContentType: contentType},
+				minio.PutObjectOptions{ContentType: storedContentType},
 			)
 			cancel()
 			_ = part.Close()
@@ -616,7 +782,17 @@ func (api *datasetRegistryAPI) handleUploadDatasetVersion(w http.ResponseWriter,
 		api.writeError(w, r, http.StatusBadRequest, "file_required")
 		return
 	}
+	var (
+		profileResult dataprofile.Profile
+		profileErr    error
+	)
+	if profiler != nil {
+		profileResult, profileErr = profiler.Finish()
+	}
 
+	if qualityRuleID == "" {
+		qualityRuleID = settings.DefaultQualityRuleID
+	}
 	if qualityRuleID != "" {
 		var exists string
 		if err := api.db.QueryRowContext(r.Context(), `SELECT rule_id FROM quality_rules WHERE rule_id = $1`, qualityRuleID).Scan(&exists); err != nil {
@@ -630,6 +806,15 @@ func (api *datasetRegistryAPI) handleUploadDatasetVersion(w http.ResponseWriter,
 		}
 	}
 
+	var contractViolations []dataContractViolation
+	if contract != nil {
+		var observedProfile *dataprofile.Profile
+		if profiler != nil && profileErr == nil {
+			observedProfile = &profileResult
+		}
+		contractViolations = evaluateDataContract(*contract, observeDatasetVersion(metadataMap, head.buf, filename, contentType, observedProfile), now)
+	}
+
 	metadataMap["filename"] = filename
 	metadataMap["content_type"] = contentType
 	metadataMap["content_sha256"] = contentSHA256
@@ -648,7 +833,18 @@ func (api *datasetRegistryAPI) handleUploadDatasetVersion(w http.ResponseWriter,
 		return
 	}
 
-	version, err := api.svc.CreateDatasetVersion(r.Context(), domain.DatasetVersion{
+	// The version, its audit event and its lineage edge commit together; the
+	// lineage edge reaches lineage_events through the outbox relay.
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		_ = api.store.RemoveObject(r.Context(), api.storeCfg.BucketDatasets, uploadedObjectKey, minio.RemoveObjectOptions{})
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	txSvc := api.svc.withRepositories(repopg.NewDatasetStore(tx), repopg.NewAuditAppender(tx, nil))
+	version, err := txSvc.CreateDatasetVersion(r.Context(), domain.DatasetVersion{
 		ID:            versionID,
 		ProjectID:     projectID,
 		DatasetID:     datasetID,
@@ -657,6 +853,7 @@ func (api *datasetRegistryAPI) handleUploadDatasetVersion(w http.ResponseWriter,
 		ContentSHA256: contentSHA256,
 		ObjectKey:     uploadedObjectKey,
 		SizeBytes:     sizeBytes,
+		Encryption:    encryption,
 	}, metadataMap, buildAuditContext(r, identity))
 	if err != nil {
 		_ = api.store.RemoveObject(r.Context(), api.storeCfg.BucketDatasets, uploadedObjectKey, minio.RemoveObjectOptions{})
@@ -668,7 +865,7 @@ func (api *datasetRegistryAPI) handleUploadDatasetVersion(w http.ResponseWriter,
 		return
 	}
 
-	_, err = lineageevent.Insert(r.Context(), api.db, lineageevent.Event{
+	err = lineageevent.Enqueue(r.Context(), tx, lineageevent.Event{
 		OccurredAt:  now,
 		Actor:       identity.Subject,
 		RequestID:   r.Header.Get("X-Request-Id"),
@@ -690,20 +887,64 @@ func (api *datasetRegistryAPI) handleUploadDatasetVersion(w http.ResponseWriter,
 		api.writeError(w, r, http.StatusInternalServerError, "lineage_write_failed")
 		return
 	}
+	err = api.bus.Enqueue(r.Context(), tx, eventbus.EventDatasetVersionCreated, version.ID, projectID, now,
+		map[string]string{"dataset_id": datasetID, "dataset_version_id": version.ID},
+		map[string]any{
+			"ordinal":        version.Ordinal,
+			"content_sha256": version.ContentSHA256,
+			"size_bytes":     version.SizeBytes,
+		},
+	)
+	if err != nil {
+		_ = api.store.RemoveObject(r.Context(), api.storeCfg.BucketDatasets, uploadedObjectKey, minio.RemoveObjectOptions{})
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		_ = api.store.RemoveObject(r.Context(), api.storeCfg.BucketDatasets, uploadedObjectKey, minio.RemoveObjectOptions{})
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	var evaluation *dataContractEvaluation
+	if contract != nil {
+		recorded, err := api.recordDataContractEvaluation(r.Context(), r, *contract, version, contractViolations, now)
+		if err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "data_contract_evaluation_failed")
+			return
+		}
+		evaluation = &recorded
+	}
+
+	var profile *datasetVersionProfile
+	if profiler != nil {
+		record := newDatasetVersionProfile(version, profiler.Format(), profileResult, profileErr, now)
+		// The profile is advisory: the version is already committed.
+		if err := api.insertDatasetVersionProfile(r.Context(), projectID, record); err != nil {
+			if api.logger != nil {
+				api.logger.Warn("dataset version profile write failed", "version_id", version.ID, "error", err)
+			}
+		} else {
+			profile = &record
+		}
+	}
 
 	w.Header().Set("Location", "/dataset-versions/"+version.ID)
 	api.writeJSON(w, http.StatusCreated, datasetVersion{
-		VersionID:     version.ID,
-		DatasetID:     version.DatasetID,
-		ProjectID:     version.ProjectID,
-		QualityRuleID: version.QualityRuleID,
-		Ordinal:       version.Ordinal,
-		ContentSHA256: version.ContentSHA256,
-		ObjectKey:     version.ObjectKey,
-		SizeBytes:     version.SizeBytes,
-		Metadata:      metadataJSON,
-		CreatedAt:     version.CreatedAt,
-		CreatedBy:     version.CreatedBy,
+		VersionID:              version.ID,
+		DatasetID:              version.DatasetID,
+		ProjectID:              version.ProjectID,
+		QualityRuleID:          version.QualityRuleID,
+		Ordinal:                version.Ordinal,
+		ContentSHA256:          version.ContentSHA256,
+		ObjectKey:              version.ObjectKey,
+		SizeBytes:              version.SizeBytes,
+		Metadata:               metadataJSON,
+		CreatedAt:              version.CreatedAt,
+		CreatedBy:              version.CreatedBy,
+		DataContractEvaluation: evaluation,
+		Encryption:             objectEncryptionResponse(version.Encryption),
+		Profile:                profile,
 	})
 }
 
@@ -732,6 +973,11 @@ func (api *datasetRegistryAPI) handleGetDatasetVersion(w http.ResponseWriter, r
 		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
 		return
 	}
+	protection, err := api.versionProtection(r.Context(), version.ID)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
 	metaJSON, _ := json.Marshal(version.Metadata)
 	api.writeJSON(w, http.StatusOK, datasetVersion{
 		VersionID:     version.ID,
@@ -745,10 +991,16 @@ func (api *datasetRegistryAPI) handleGetDatasetVersion(w http.ResponseWriter, r
 		Metadata:      metaJSON,
 		CreatedAt:     version.CreatedAt,
 		CreatedBy:     version.CreatedBy,
+		Encryption:    objectEncryptionResponse(version.Encryption),
+		Recall:        recallResponse(protection.Recall),
 	})
 }
 
 func (api *datasetRegistryAPI) handleDownloadDatasetVersion(w http.ResponseWriter, r *http.Request) {
+	if identity, ok := auth.IdentityFromContext(r.Context()); ok && auth.AuditorOnly(identity.Roles) {
+		api.writeError(w, r, http.StatusForbidden, "auditor_read_only")
+		return
+	}
 	versionID := strings.TrimSpace(r.PathValue("version_id"))
 	if versionID == "" {
 		api.writeError(w, r, http.StatusBadRequest, "version_id_required")
@@ -773,9 +1025,13 @@ func (api *datasetRegistryAPI) handleDownloadDatasetVersion(w http.ResponseWrite
 		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
 		return
 	}
+	if datasetID := strings.TrimSpace(r.PathValue("dataset_id")); datasetID != "" && datasetID != version.DatasetID {
+		api.writeError(w, r, http.StatusNotFound, "not_found")
+		return
+	}
 
 	metaJSON, _ := json.Marshal(version.Metadata)
-	meta := normalizeJSON(metaJSON)
+	meta := httpapi.NormalizeJSON(metaJSON)
 	filename := jsonFieldString(meta, "filename")
 	if filename == "" {
 		filename = "dataset.bin"
@@ -792,26 +1048,189 @@ func (api *datasetRegistryAPI) handleDownloadDatasetVersion(w http.ResponseWrite
 	}
 
 	now := time.Now().UTC()
+	if _, ok := api.datasetAccessGate(w, r, identity, version.DatasetID, version.ID, now); !ok {
+		return
+	}
+	if !api.datasetVersionGate(w, r, identity, version, now) {
+		return
+	}
+
+	var dataKey []byte
+	if version.Encryption.Enabled() {
+		dataKey, err = api.encrypter.DataKey(r.Context(), version.Encryption.Algorithm, version.Encryption.KeyID, version.Encryption.WrappedKey)
+		if err != nil {
+			api.writeError(w, r, http.StatusBadGateway, "encryption_key_unavailable")
+			return
+		}
+	}
+
+	bucket, key, readOpts, err := api.datasetObjectReadOptions(r.Context(), version)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	obj, err := api.store.GetObject(r.Context(), bucket, key, readOpts)
+	if err != nil {
+		api.writeError(w, r, http.StatusBadGateway, "object_store_error")
+		return
+	}
+	defer obj.Close()
+
+	if _, err := obj.Stat(); err != nil {
+		if isReferenceChanged(err) {
+			api.writeError(w, r, http.StatusConflict, "dataset_version_reference_changed")
+			return
+		}
+		api.writeError(w, r, http.StatusNotFound, "not_found")
+		return
+	}
+	var content io.ReadSeeker = obj
+	if dataKey != nil {
+		content, err = envelope.NewDecryptSeeker(dataKey, obj, version.SizeBytes)
+		if err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+	}
+
+	payload := map[string]any{
+		"service":            "dataset-registry",
+		"project_id":         version.ProjectID,
+		"dataset_id":         version.DatasetID,
+		"dataset_version_id": versionID,
+		"content_sha256":     version.ContentSHA256,
+		"size_bytes":         version.SizeBytes,
+	}
+	if rangeHeader := strings.TrimSpace(r.Header.Get("Range")); rangeHeader != "" {
+		payload["range"] = rangeHeader
+	}
+	_ = auditlog.Enqueue(r.Context(), api.db, auditlog.Event{
+		OccurredAt:   now,
+		Actor:        identity.Subject,
+		Action:       "dataset_version.download",
+		ResourceType: "dataset_version",
+		ResourceID:   versionID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload:      payload,
+	})
+
+	// ServeContent answers Range, If-Range and If-None-Match against the ETag;
+	// the version is immutable, so its content hash is a strong validator.
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	if sha := strings.TrimSpace(version.ContentSHA256); sha != "" {
+		w.Header().Set("ETag", httpapi.ETag(sha))
+	}
+	http.ServeContent(w, r, "", version.CreatedAt, content)
+}
+
+// datasetVersionGate blocks recalled versions and versions that did not pass
+// their quality rule or data contract, and audits the decision. It writes the
+// error response itself.
+func (api *datasetRegistryAPI) datasetVersionGate(w http.ResponseWriter, r *http.Request, identity auth.Identity, version domain.DatasetVersion, now time.Time) bool {
+	protection, err := api.versionProtection(r.Context(), version.ID)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return false
+	}
+	lifecycle, err := api.datasetLifecycle(r.Context(), version.DatasetID)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return false
+	}
+	if protection.Recall != nil {
+		_ = auditlog.Enqueue(r.Context(), api.db, auditlog.Event{
+			OccurredAt:   now,
+			Actor:        identity.Subject,
+			Action:       "quality_gate.block",
+			ResourceType: "dataset_version",
+			ResourceID:   version.ID,
+			RequestID:    r.Header.Get("X-Request-Id"),
+			IP:           httpapi.RequestIP(r.RemoteAddr),
+			UserAgent:    r.UserAgent(),
+			Payload: map[string]any{
+				"service":            "dataset-registry",
+				"dataset_id":         version.DatasetID,
+				"dataset_version_id": version.ID,
+				"data_protection":    dataProtectionPayload(protection),
+				"reason":             "recalled",
+			},
+		})
+		api.writeError(w, r, http.StatusConflict, "dataset_version_recalled")
+		return false
+	}
+	if isReferenceObjectKey(version.ObjectKey) {
+		ref, err := api.datasetVersionReference(r.Context(), version.ID, version.ProjectID)
+		if err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return false
+		}
+		if ref.Status.State != referenceStateVerified {
+			_ = auditlog.Enqueue(r.Context(), api.db, auditlog.Event{
+				OccurredAt:   now,
+				Actor:        identity.Subject,
+				Action:       "quality_gate.block",
+				ResourceType: "dataset_version",
+				ResourceID:   version.ID,
+				RequestID:    r.Header.Get("X-Request-Id"),
+				IP:           httpapi.RequestIP(r.RemoteAddr),
+				UserAgent:    r.UserAgent(),
+				Payload: map[string]any{
+					"service":            "dataset-registry",
+					"dataset_id":         version.DatasetID,
+					"dataset_version_id": version.ID,
+					"reference_state":    ref.Status.State,
+					"reason":             "reference_changed",
+				},
+			})
+			api.writeError(w, r, http.StatusConflict, "dataset_version_reference_changed")
+			return false
+		}
+	}
+	if lifecycle.State == domain.DatasetLifecycleArchived {
+		_ = auditlog.Enqueue(r.Context(), api.db, auditlog.Event{
+			OccurredAt:   now,
+			Actor:        identity.Subject,
+			Action:       "quality_gate.block",
+			ResourceType: "dataset_version",
+			ResourceID:   version.ID,
+			RequestID:    r.Header.Get("X-Request-Id"),
+			IP:           httpapi.RequestIP(r.RemoteAddr),
+			UserAgent:    r.UserAgent(),
+			Payload: map[string]any{
+				"service":            "dataset-registry",
+				"dataset_id":         version.DatasetID,
+				"dataset_version_id": version.ID,
+				"lifecycle_state":    string(lifecycle.State),
+				"reason":             "archived",
+			},
+		})
+		api.writeError(w, r, http.StatusConflict, "dataset_archived")
+		return false
+	}
+
 	ruleID := strings.TrimSpace(version.QualityRuleID)
 	if ruleID == "" {
-		_, _ = auditlog.Insert(r.Context(), api.db, auditlog.Event{
+		_ = auditlog.Enqueue(r.Context(), api.db, auditlog.Event{
 			OccurredAt:   now,
 			Actor:        identity.Subject,
 			Action:       "quality_gate.block",
 			ResourceType: "dataset_version",
-			ResourceID:   versionID,
+			ResourceID:   version.ID,
 			RequestID:    r.Header.Get("X-Request-Id"),
-			IP:           requestIP(r.RemoteAddr),
+			IP:           httpapi.RequestIP(r.RemoteAddr),
 			UserAgent:    r.UserAgent(),
 			Payload: map[string]any{
 				"service":            "dataset-registry",
 				"dataset_id":         version.DatasetID,
-				"dataset_version_id": versionID,
+				"dataset_version_id": version.ID,
 				"reason":             "no_rule",
 			},
 		})
 		api.writeError(w, r, http.StatusConflict, "quality_rule_not_set")
-		return
+		return false
 	}
 
 	var (
@@ -825,49 +1244,49 @@ func (api *datasetRegistryAPI) handleDownloadDatasetVersion(w http.ResponseWrite
 		 WHERE dataset_version_id = $1 AND rule_id = $2
 		 ORDER BY evaluated_at DESC
 		 LIMIT 1`,
-		versionID,
+		version.ID,
 		ruleID,
 	).Scan(&evalID, &evalStatus)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			_, _ = auditlog.Insert(r.Context(), api.db, auditlog.Event{
+			_ = auditlog.Enqueue(r.Context(), api.db, auditlog.Event{
 				OccurredAt:   now,
 				Actor:        identity.Subject,
 				Action:       "quality_gate.block",
 				ResourceType: "dataset_version",
-				ResourceID:   versionID,
+				ResourceID:   version.ID,
 				RequestID:    r.Header.Get("X-Request-Id"),
-				IP:           requestIP(r.RemoteAddr),
+				IP:           httpapi.RequestIP(r.RemoteAddr),
 				UserAgent:    r.UserAgent(),
 				Payload: map[string]any{
 					"service":            "dataset-registry",
 					"dataset_id":         version.DatasetID,
-					"dataset_version_id": versionID,
+					"dataset_version_id": version.ID,
 					"rule_id":            ruleID,
 					"reason":             "not_evaluated",
 				},
 			})
 			api.writeError(w, r, http.StatusConflict, "quality_not_evaluated")
-			return
+			return false
 		}
 		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
-		return
+		return false
 	}
 
 	if strings.ToLower(strings.TrimSpace(evalStatus)) != "pass" {
-		_, _ = auditlog.Insert(r.Context(), api.db, auditlog.Event{
+		_ = auditlog.Enqueue(r.Context(), api.db, auditlog.Event{
 			OccurredAt:   now,
 			Actor:        identity.Subject,
 			Action:       "quality_gate.block",
 			ResourceType: "dataset_version",
-			ResourceID:   versionID,
+			ResourceID:   version.ID,
 			RequestID:    r.Header.Get("X-Request-Id"),
-			IP:           requestIP(r.RemoteAddr),
+			IP:           httpapi.RequestIP(r.RemoteAddr),
 			UserAgent:    r.UserAgent(),
 			Payload: map[string]any{
 				"service":            "dataset-registry",
 				"dataset_id":         version.DatasetID,
-				"dataset_version_id": versionID,
+				"dataset_version_id": version.ID,
 				"rule_id":            ruleID,
 				"evaluation_id":      evalID,
 				"status":             evalStatus,
@@ -875,47 +1294,63 @@ func (api *datasetRegistryAPI) handleDownloadDatasetVersion(w http.ResponseWrite
 			},
 		})
 		api.writeError(w, r, http.StatusConflict, "quality_gate_failed")
-		return
+		return false
 	}
 
-	_, _ = auditlog.Insert(r.Context(), api.db, auditlog.Event{
+	contractEvaluationID, violations, breached, err := api.dataContractBreached(r.Context(), version.ID)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return false
+	}
+	if breached {
+		_ = auditlog.Enqueue(r.Context(), api.db, auditlog.Event{
+			OccurredAt:   now,
+			Actor:        identity.Subject,
+			Action:       "quality_gate.block",
+			ResourceType: "dataset_version",
+			ResourceID:   version.ID,
+			RequestID:    r.Header.Get("X-Request-Id"),
+			IP:           httpapi.RequestIP(r.RemoteAddr),
+			UserAgent:    r.UserAgent(),
+			Payload: map[string]any{
+				"service":                     "dataset-registry",
+				"dataset_id":                  version.DatasetID,
+				"dataset_version_id":          version.ID,
+				"rule_id":                     ruleID,
+				"evaluation_id":               evalID,
+				"data_contract_evaluation_id": contractEvaluationID,
+				"reason":                      "data_contract_breached",
+			},
+		})
+		api.writeErrorWithDetails(w, r, http.StatusConflict, "data_contract_breached", map[string]any{
+			"data_contract_evaluation_id": contractEvaluationID,
+			"violations":                  violations,
+			"column_diff":                 dataContractColumnDiffs(violations),
+		})
+		return false
+	}
+
+	_ = auditlog.Enqueue(r.Context(), api.db, auditlog.Event{
 		OccurredAt:   now,
 		Actor:        identity.Subject,
 		Action:       "quality_gate.allow",
 		ResourceType: "dataset_version",
-		ResourceID:   versionID,
+		ResourceID:   version.ID,
 		RequestID:    r.Header.Get("X-Request-Id"),
-		IP:           requestIP(r.RemoteAddr),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
 		UserAgent:    r.UserAgent(),
 		Payload: map[string]any{
 			"service":            "dataset-registry",
 			"dataset_id":         version.DatasetID,
-			"dataset_version_id": versionID,
+			"dataset_version_id": version.ID,
 			"rule_id":            ruleID,
 			"evaluation_id":      evalID,
 			"status":             evalStatus,
+			"data_protection":    dataProtectionPayload(protection),
+			"lifecycle_state":    string(lifecycle.State),
 		},
 	})
-
-	obj, err := api.store.GetObject(r.Context(), api.storeCfg.BucketDatasets, version.ObjectKey, minio.GetObjectOptions{})
-	if err != nil {
-		api.writeError(w, r, http.StatusBadGateway, "object_store_error")
-		return
-	}
-	defer obj.Close()
-
-	if _, err := obj.Stat(); err != nil {
-		api.writeError(w, r, http.StatusNotFound, "not_found")
-		return
-	}
-
-	w.Header().Set("Content-Type", contentType)
-	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
-	if version.SizeBytes > 0 {
-		w.Header().Set("Content-Length", strconv.FormatInt(version.SizeBytes, 10))
-	}
-	w.WriteHeader(http.StatusOK)
-	_, _ = io.Copy(w, obj)
+	return true
 }
 
 func (api *datasetRegistryAPI) handleCreateArtifact(w http.ResponseWriter, r *http.Request) {
@@ -934,9 +1369,14 @@ func (api *datasetRegistryAPI) handleCreateArtifact(w http.ResponseWriter, r *ht
 		return
 	}
 
+	settings, ok := api.activeProjectSettings(w, r, projectID)
+	if !ok {
+		return
+	}
+
 	var req createArtifactRequest
-	if err := decodeJSON(r, &req); err != nil {
-		api.writeError(w, r, http.StatusBadRequest, "invalid_json")
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
 		return
 	}
 
@@ -948,7 +1388,7 @@ func (api *datasetRegistryAPI) handleCreateArtifact(w http.ResponseWriter, r *ht
 			ContentType:    strings.TrimSpace(req.ContentType),
 			SizeBytes:      req.SizeBytes,
 			SHA256:         strings.TrimSpace(req.SHA256),
-			RetentionUntil: req.RetentionUntil,
+			RetentionUntil: artifactRetentionUntil(req.RetentionUntil, settings, time.Now()),
 			LegalHold:      req.LegalHold,
 			Metadata:       redaction.RedactMetadata(req.Metadata),
 		},
@@ -1015,6 +1455,10 @@ func (api *datasetRegistryAPI) handleDownloadArtifact(w http.ResponseWriter, r *
 		api.writeError(w, r, http.StatusUnauthorized, "unauthorized")
 		return
 	}
+	if auth.AuditorOnly(identity.Roles) {
+		api.writeError(w, r, http.StatusForbidden, "auditor_read_only")
+		return
+	}
 
 	result, err := api.artifactSvc.GetArtifactDownload(r.Context(), projectID, artifactID, buildArtifactAuditContext(r, identity))
 	if err != nil {
@@ -1059,23 +1503,11 @@ func (w *countingWriter) Write(p []byte) (int, error) {
 	return len(p), nil
 }
 
-func decodeJSON(r *http.Request, dst any) error {
-	dec := json.NewDecoder(io.LimitReader(r.Body, 1<<20))
-	dec.DisallowUnknownFields()
-	if err := dec.Decode(dst); err != nil {
-		return err
-	}
-	if err := dec.Decode(&struct{}{}); err != io.EOF {
-		return errors.New("multiple JSON values")
-	}
-	return nil
-}
-
 func buildAuditContext(r *http.Request, identity auth.Identity) auditContext {
 	return auditContext{
 		Actor:     strings.TrimSpace(identity.Subject),
 		RequestID: r.Header.Get("X-Request-Id"),
-		IP:        requestIP(r.RemoteAddr),
+		IP:        httpapi.RequestIP(r.RemoteAddr),
 		UserAgent: r.UserAgent(),
 		Path:      r.URL.Path,
 		Service:   "dataset-registry",
@@ -1086,7 +1518,7 @@ func buildArtifactAuditContext(r *http.Request, identity auth.Identity) artifact
 	return artifactsvc.AuditContext{
 		Actor:     strings.TrimSpace(identity.Subject),
 		RequestID: r.Header.Get("X-Request-Id"),
-		IP:        requestIP(r.RemoteAddr),
+		IP:        httpapi.RequestIP(r.RemoteAddr),
 		UserAgent: r.UserAgent(),
 		Path:      r.URL.Path,
 		Service:   "dataset-registry",
@@ -1107,34 +1539,15 @@ func requireProjectScope(r *http.Request) (string, bool) {
 }
 
 func (api *datasetRegistryAPI) writeJSON(w http.ResponseWriter, status int, body any) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	enc := json.NewEncoder(w)
-	enc.SetEscapeHTML(true)
-	_ = enc.Encode(body)
+	httpapi.WriteJSON(w, status, body)
 }
 
 func (api *datasetRegistryAPI) writeError(w http.ResponseWriter, r *http.Request, status int, code string) {
-	api.writeJSON(w, status, map[string]any{
-		"error":      code,
-		"request_id": r.Header.Get("X-Request-Id"),
-	})
+	httpapi.WriteError(w, r, status, code)
 }
 
 func (api *datasetRegistryAPI) writeErrorWithDetails(w http.ResponseWriter, r *http.Request, status int, code string, details any) {
-	api.writeJSON(w, status, map[string]any{
-		"error":      code,
-		"request_id": r.Header.Get("X-Request-Id"),
-		"details":    details,
-	})
-}
-
-func requestIP(remoteAddr string) net.IP {
-	host, _, err := net.SplitHostPort(remoteAddr)
-	if err != nil {
-		return nil
-	}
-	return net.ParseIP(host)
+	httpapi.WriteErrorWithDetails(w, r, status, code, details)
 }
 
 func jsonFieldString(raw json.RawMessage, key string) string {
@@ -1150,14 +1563,6 @@ func jsonFieldString(raw json.RawMessage, key string) string {
 	return strings.TrimSpace(s)
 }
 
-func normalizeJSON(raw []byte) json.RawMessage {
-	raw = bytesTrimSpace(raw)
-	if len(raw) == 0 || string(raw) == "null" {
-		return []byte("{}")
-	}
-	return raw
-}
-
 func bytesTrimSpace(in []byte) []byte {
 	return []byte(strings.TrimSpace(string(in)))
 }
@@ -1185,25 +1590,3 @@ func isUniqueViolation(err error) bool {
 	}
 	return false
 }
-
-func parseIntQuery(r *http.Request, key string, def int) int {
-	v := strings.TrimSpace(r.URL.Query().Get(key))
-	if v == "" {
-		return def
-	}
-	parsed, err := strconv.Atoi(v)
-	if err != nil {
-		return def
-	}
-	return parsed
-}
-
-func clampInt(v int, min int, max int) int {
-	if v < min {
-		return min
-	}
-	if v > max {
-		return max
-	}
-	return v
-}
@@ -1,9 +1,11 @@
-package main
+package datasetregistry
 
 import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
 )
 
 func TestIntegritySHA256_Deterministic(t *testing.T) {
@@ -41,7 +43,7 @@ func TestSanitizeFilename(t *testing.T) {
 func TestDecodeJSON_RejectsExtraValue(t *testing.T) {
 	req := httptest.NewRequest("POST", "http://example.test/", strings.NewReader("{\"name\":\"a\"} {\"name\":\"b\"}"))
 	var dst createDatasetRequest
-	if err := decodeJSON(req, &dst); err == nil {
+	if err := httpapi.DecodeJSON(req, &dst); err == nil {
 		t.Fatalf("expected error")
 	}
 }
@@ -49,7 +51,7 @@ func TestDecodeJSON_RejectsExtraValue(t *testing.T) {
 func TestDecodeJSON_DisallowUnknownFields(t *testing.T) {
 	req := httptest.NewRequest("POST", "http://example.test/", strings.NewReader("{\"name\":\"a\",\"extra\":1}"))
 	var dst createDatasetRequest
-	if err := decodeJSON(req, &dst); err == nil {
+	if err := httpapi.DecodeJSON(req, &dst); err == nil {
 		t.Fatalf("expected error")
 	}
 }
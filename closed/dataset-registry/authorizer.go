@@ -1,12 +1,40 @@
-package main
+package datasetregistry
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
 	"github.com/animus-labs/animus-go/closed/internal/platform/rbac"
 )
 
+// datasetRegistryAuditorRoutes are the read-only routes open to the auditor
+// role: project, dataset and version metadata, data contracts, freshness and
+// protection. Downloads, profiles (sample values), artifacts and project
+// settings stay closed.
+var datasetRegistryAuditorRoutes = rbac.AuditorRoutes(
+	"/projects",
+	"/projects/*",
+	"/datasets",
+	"/datasets/*",
+	"/datasets/*/versions",
+	"/datasets/*/contracts",
+	"/datasets/*/contracts/**",
+	"/datasets/*/freshness",
+	"/datasets/*/freshness/breaches",
+	"/datasets/*/lifecycle",
+	"/datasets/*/protection",
+	"/datasets/*/access-policy",
+	"/datasets/*/access-requests",
+	"/datasets/*/access-requests/*",
+	"/datasets/*/access-grants",
+	"/dataset-versions/*",
+	"/dataset-versions/*/contract-evaluations",
+	"/dataset-versions/*/replica",
+	"/dataset-versions/*/external-source",
+	"/dataset-versions/*/reference",
+)
+
 func requiredRoleForDatasetRegistry(r *http.Request) string {
 	if r == nil {
 		return auth.RoleEditor
@@ -14,5 +42,76 @@ func requiredRoleForDatasetRegistry(r *http.Request) string {
 	if r.Method == http.MethodPost && r.URL.Path == "/projects" {
 		return auth.RoleAdmin
 	}
+	if r.Method == http.MethodPost && isDataContractDecisionPath(r.URL.Path) {
+		return auth.RoleAdmin
+	}
+	if isProjectManagementRequest(r) {
+		return auth.RoleAdmin
+	}
+	if r.Method == http.MethodPost && isReplicationPath(r.URL.Path) {
+		return auth.RoleAdmin
+	}
+	if role, ok := accessRequestRole(r); ok {
+		return role
+	}
 	return rbac.RequiredRoleFromRequest(r)
 }
+
+// isDataContractDecisionPath matches /datasets/{dataset_id}/contracts/{version}/{approve|reject}.
+func isDataContractDecisionPath(path string) bool {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) != 5 || parts[0] != "datasets" || parts[2] != "contracts" {
+		return false
+	}
+	return parts[4] == "approve" || parts[4] == "reject"
+}
+
+// isProjectManagementRequest matches PATCH and DELETE /projects/{project_id}
+// and PUT /projects/{project_id}/settings.
+func isProjectManagementRequest(r *http.Request) bool {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) < 2 || parts[0] != "projects" {
+		return false
+	}
+	switch len(parts) {
+	case 2:
+		return r.Method == http.MethodPatch || r.Method == http.MethodDelete
+	case 3:
+		return parts[2] == "settings" && r.Method == http.MethodPut
+	}
+	return false
+}
+
+// isReplicationPath matches /datasets/{dataset_id}/versions/{version_id}/replica-package
+// and /datasets/{dataset_id}/versions/replicas, which move data across deployments.
+func isReplicationPath(path string) bool {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) < 4 || parts[0] != "datasets" || parts[2] != "versions" {
+		return false
+	}
+	return (len(parts) == 4 && parts[3] == "replicas") || (len(parts) == 5 && parts[4] == "replica-package")
+}
+
+// accessRequestRole covers the dataset access routes. Access policies and
+// revocations are admin-only. Requesting access and voting are open to
+// viewers, who may need to read a restricted dataset; the handler checks
+// who may vote in each stage.
+func accessRequestRole(r *http.Request) (string, bool) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) < 3 || parts[0] != "datasets" {
+		return "", false
+	}
+	switch {
+	case len(parts) == 3 && parts[2] == "access-policy" && r.Method == http.MethodPut:
+		return auth.RoleAdmin, true
+	case len(parts) == 5 && parts[2] == "access-grants" && parts[4] == "revoke" && r.Method == http.MethodPost:
+		return auth.RoleAdmin, true
+	case parts[2] != "access-requests" || r.Method != http.MethodPost:
+		return "", false
+	case len(parts) == 3:
+		return auth.RoleViewer, true
+	case len(parts) == 5 && (parts[4] == "approve" || parts[4] == "reject"):
+		return auth.RoleViewer, true
+	}
+	return "", false
+}
@@ -1,4 +1,4 @@
-package main
+package datasetregistry
 
 import (
 	"net/http"
@@ -24,3 +24,80 @@ func TestRequiredRoleForDatasetRegistry(t *testing.T) {
 		t.Fatalf("POST /datasets role=%q, want %q", got, auth.RoleEditor)
 	}
 }
+
+func TestRequiredRoleForDataContractDecisions(t *testing.T) {
+	for path, want := range map[string]string{
+		"/datasets/ds-1/contracts":           auth.RoleEditor,
+		"/datasets/ds-1/contracts/2/approve": auth.RoleAdmin,
+		"/datasets/ds-1/contracts/2/reject":  auth.RoleAdmin,
+	} {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		if got := requiredRoleForDatasetRegistry(req); got != want {
+			t.Fatalf("POST %s role=%q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestRequiredRoleForProjectManagement(t *testing.T) {
+	cases := []struct {
+		method string
+		path   string
+		want   string
+	}{
+		{http.MethodPatch, "/projects/p-1", auth.RoleAdmin},
+		{http.MethodDelete, "/projects/p-1", auth.RoleAdmin},
+		{http.MethodPut, "/projects/p-1/settings", auth.RoleAdmin},
+		{http.MethodGet, "/projects/p-1/settings", auth.RoleViewer},
+		{http.MethodGet, "/projects/p-1", auth.RoleViewer},
+		{http.MethodPost, "/projects/p-1/artifacts", auth.RoleEditor},
+		{http.MethodPost, "/datasets/ds-1/versions/v-1/replica-package", auth.RoleAdmin},
+		{http.MethodPost, "/datasets/ds-1/versions/replicas", auth.RoleAdmin},
+		{http.MethodPost, "/datasets/ds-1/versions/upload", auth.RoleEditor},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(tc.method, tc.path, nil)
+		if got := requiredRoleForDatasetRegistry(req); got != tc.want {
+			t.Fatalf("%s %s role=%q, want %q", tc.method, tc.path, got, tc.want)
+		}
+	}
+}
+
+func TestDatasetRegistryAuditorRoutes(t *testing.T) {
+	for path, want := range map[string]bool{
+		"/datasets":                           true,
+		"/datasets/ds-1/versions":             true,
+		"/dataset-versions/v-1":               true,
+		"/dataset-versions/v-1/replica":       true,
+		"/datasets/ds-1/contracts/2/diff":     true,
+		"/dataset-versions/v-1/download":      false,
+		"/datasets/ds-1/versions/v-1/profile": false,
+		"/projects/p-1/artifacts/a-1":         false,
+		"/projects/p-1/settings":              false,
+	} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if got := datasetRegistryAuditorRoutes(req); got != want {
+			t.Fatalf("GET %s: auditor scope=%v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestRequiredRoleForDatasetAccess(t *testing.T) {
+	cases := []struct {
+		method string
+		path   string
+		want   string
+	}{
+		{http.MethodPut, "/datasets/ds-1/access-policy", auth.RoleAdmin},
+		{http.MethodGet, "/datasets/ds-1/access-policy", auth.RoleViewer},
+		{http.MethodPost, "/datasets/ds-1/access-requests", auth.RoleViewer},
+		{http.MethodPost, "/datasets/ds-1/access-requests/r-1/approve", auth.RoleViewer},
+		{http.MethodPost, "/datasets/ds-1/access-requests/r-1/reject", auth.RoleViewer},
+		{http.MethodPost, "/datasets/ds-1/access-grants/g-1/revoke", auth.RoleAdmin},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(tc.method, tc.path, nil)
+		if got := requiredRoleForDatasetRegistry(req); got != tc.want {
+			t.Fatalf("%s %s role=%q, want %q", tc.method, tc.path, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,355 @@
+package datasetregistry
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+)
+
+const (
+	dataContractOpAddColumn         = "add_column"
+	dataContractOpDropColumn        = "drop_column"
+	dataContractOpRenameColumn      = "rename_column"
+	dataContractOpSetType           = "set_type"
+	dataContractOpSetRequired       = "set_required"
+	dataContractOpSetNullable       = "set_nullable"
+	dataContractOpAllowExtraColumns = "set_allow_extra_columns"
+
+	maxDataContractMigrationOps = 128
+)
+
+// dataContractMigrationOp is one step of a contract migration. Column names
+// the column the step applies to; the other fields are read per op.
+type dataContractMigrationOp struct {
+	Op                string              `json:"op"`
+	Column            string              `json:"column,omitempty"`
+	Definition        *dataContractColumn `json:"definition,omitempty"`
+	NewName           string              `json:"new_name,omitempty"`
+	Type              string              `json:"type,omitempty"`
+	Required          *bool               `json:"required,omitempty"`
+	Nullable          *bool               `json:"nullable,omitempty"`
+	AllowExtraColumns *bool               `json:"allow_extra_columns,omitempty"`
+}
+
+// dataContractMigrationRequest derives the next contract version from an
+// existing one. Owner, freshness SLO and consumers are inherited unless set.
+type dataContractMigrationRequest struct {
+	Changes             []dataContractMigrationOp `json:"changes"`
+	Owner               string                    `json:"owner,omitempty"`
+	FreshnessSLOSeconds *int64                    `json:"freshness_slo_seconds,omitempty"`
+	Consumers           []dataContractConsumer    `json:"consumers,omitempty"`
+	Reason              string                    `json:"reason,omitempty"`
+	DryRun              bool                      `json:"dry_run,omitempty"`
+}
+
+// dataContractSchemaChange is one column-level difference between two
+// contract versions. Breaking changes can reject versions the older contract
+// accepted.
+type dataContractSchemaChange struct {
+	Column   string `json:"column,omitempty"`
+	Change   string `json:"change"`
+	From     string `json:"from,omitempty"`
+	To       string `json:"to,omitempty"`
+	Breaking bool   `json:"breaking"`
+}
+
+type dataContractDiff struct {
+	FromVersion int                        `json:"from_version"`
+	ToVersion   int                        `json:"to_version,omitempty"`
+	Changes     []dataContractSchemaChange `json:"changes"`
+	Breaking    bool                       `json:"breaking"`
+}
+
+type dataContractMigrationResponse struct {
+	// Contract is the created pending version; omitted on dry runs.
+	Contract *dataContract      `json:"contract,omitempty"`
+	Schema   dataContractSchema `json:"schema"`
+	Diff     dataContractDiff   `json:"diff"`
+}
+
+func (api *datasetRegistryAPI) registerDataContractMigrations(mux *http.ServeMux) {
+	mux.HandleFunc("POST /datasets/{dataset_id}/contracts/{version}/migrate", api.handleMigrateDataContract)
+	mux.HandleFunc("GET /datasets/{dataset_id}/contracts/{version}/diff", api.handleDiffDataContract)
+}
+
+// applyDataContractMigration applies ops in order to a copy of schema and
+// returns an error code for the first op that does not apply.
+func applyDataContractMigration(schema dataContractSchema, ops []dataContractMigrationOp) (dataContractSchema, string) {
+	if len(ops) == 0 {
+		return dataContractSchema{}, "changes_required"
+	}
+	if len(ops) > maxDataContractMigrationOps {
+		return dataContractSchema{}, "too_many_changes"
+	}
+	out := dataContractSchema{
+		Columns:           append([]dataContractColumn(nil), schema.Columns...),
+		AllowExtraColumns: schema.AllowExtraColumns,
+	}
+	find := func(name string) int {
+		for i, column := range out.Columns {
+			if column.Name == name {
+				return i
+			}
+		}
+		return -1
+	}
+	for _, op := range ops {
+		name := strings.TrimSpace(op.Column)
+		switch strings.ToLower(strings.TrimSpace(op.Op)) {
+		case dataContractOpAddColumn:
+			if op.Definition == nil {
+				return dataContractSchema{}, "column_definition_required"
+			}
+			column := *op.Definition
+			column.Name = strings.TrimSpace(column.Name)
+			if column.Name == "" {
+				column.Name = name
+			}
+			if find(column.Name) >= 0 {
+				return dataContractSchema{}, "duplicate_column"
+			}
+			out.Columns = append(out.Columns, column)
+			continue
+		case dataContractOpAllowExtraColumns:
+			if op.AllowExtraColumns == nil {
+				return dataContractSchema{}, "allow_extra_columns_required"
+			}
+			out.AllowExtraColumns = *op.AllowExtraColumns
+			continue
+		case dataContractOpDropColumn, dataContractOpRenameColumn, dataContractOpSetType, dataContractOpSetRequired, dataContractOpSetNullable:
+		default:
+			return dataContractSchema{}, "change_op_invalid"
+		}
+
+		i := find(name)
+		if i < 0 {
+			return dataContractSchema{}, "column_not_found"
+		}
+		column := &out.Columns[i]
+		switch strings.ToLower(strings.TrimSpace(op.Op)) {
+		case dataContractOpDropColumn:
+			out.Columns = append(out.Columns[:i], out.Columns[i+1:]...)
+		case dataContractOpRenameColumn:
+			newName := strings.TrimSpace(op.NewName)
+			if newName == "" {
+				return dataContractSchema{}, "new_name_required"
+			}
+			if newName != name && find(newName) >= 0 {
+				return dataContractSchema{}, "duplicate_column"
+			}
+			column.Name = newName
+		case dataContractOpSetType:
+			column.Type = op.Type
+		case dataContractOpSetRequired:
+			if op.Required == nil {
+				return dataContractSchema{}, "required_required"
+			}
+			column.Required = *op.Required
+		case dataContractOpSetNullable:
+			column.Nullable = op.Nullable
+		}
+	}
+	return out, ""
+}
+
+// diffDataContractSchemas lists column changes from one schema to the next:
+// removed columns and changes to kept ones in from order, then added columns
+// in to order. Renames show up as a removal and an addition.
+func diffDataContractSchemas(from, to dataContractSchema) []dataContractSchemaChange {
+	changes := []dataContractSchemaChange{}
+	next := make(map[string]dataContractColumn, len(to.Columns))
+	for _, column := range to.Columns {
+		next[column.Name] = column
+	}
+	prev := make(map[string]struct{}, len(from.Columns))
+	for _, old := range from.Columns {
+		prev[old.Name] = struct{}{}
+		column, ok := next[old.Name]
+		if !ok {
+			// Versions still carrying the column now breach unless extras are allowed.
+			changes = append(changes, dataContractSchemaChange{Column: old.Name, Change: "removed", Breaking: !to.AllowExtraColumns})
+			continue
+		}
+		if old.Type != column.Type {
+			breaking := column.Type != "" && !(old.Type == "integer" && column.Type == "number")
+			changes = append(changes, dataContractSchemaChange{Column: old.Name, Change: "type_changed", From: old.Type, To: column.Type, Breaking: breaking})
+		}
+		if old.Required != column.Required {
+			changes = append(changes, dataContractSchemaChange{
+				Column:   old.Name,
+				Change:   "required_changed",
+				From:     strconv.FormatBool(old.Required),
+				To:       strconv.FormatBool(column.Required),
+				Breaking: column.Required,
+			})
+		}
+		if oldNullable, newNullable := contractNullable(old), contractNullable(column); oldNullable != newNullable {
+			changes = append(changes, dataContractSchemaChange{
+				Column:   old.Name,
+				Change:   "nullable_changed",
+				From:     oldNullable,
+				To:       newNullable,
+				Breaking: newNullable == "false",
+			})
+		}
+	}
+	for _, column := range to.Columns {
+		if _, ok := prev[column.Name]; !ok {
+			changes = append(changes, dataContractSchemaChange{Column: column.Name, Change: "added", To: column.Type, Breaking: column.Required})
+		}
+	}
+	if from.AllowExtraColumns != to.AllowExtraColumns {
+		changes = append(changes, dataContractSchemaChange{
+			Change:   "allow_extra_columns_changed",
+			From:     strconv.FormatBool(from.AllowExtraColumns),
+			To:       strconv.FormatBool(to.AllowExtraColumns),
+			Breaking: !to.AllowExtraColumns,
+		})
+	}
+	return changes
+}
+
+// contractNullable renders the nullability of a column; unset means nulls
+// are not checked.
+func contractNullable(column dataContractColumn) string {
+	if column.Nullable == nil {
+		return "unchecked"
+	}
+	return strconv.FormatBool(*column.Nullable)
+}
+
+func newDataContractDiff(fromVersion, toVersion int, from, to dataContractSchema) dataContractDiff {
+	diff := dataContractDiff{FromVersion: fromVersion, ToVersion: toVersion, Changes: diffDataContractSchemas(from, to)}
+	for _, change := range diff.Changes {
+		diff.Breaking = diff.Breaking || change.Breaking
+	}
+	return diff
+}
+
+func (api *datasetRegistryAPI) handleMigrateDataContract(w http.ResponseWriter, r *http.Request) {
+	identity, projectID, item, ok := api.dataContractDataset(w, r)
+	if !ok {
+		return
+	}
+	base, ok := api.dataContractFromPath(w, r, item.ID)
+	if !ok {
+		return
+	}
+	var req dataContractMigrationRequest
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
+		return
+	}
+	schema, code := applyDataContractMigration(base.Schema, req.Changes)
+	if code != "" {
+		api.writeError(w, r, http.StatusBadRequest, code)
+		return
+	}
+	next := createDataContractRequest{
+		Schema:              schema,
+		FreshnessSLOSeconds: base.FreshnessSLOSeconds,
+		Owner:               base.Owner,
+		Consumers:           base.Consumers,
+	}
+	if owner := strings.TrimSpace(req.Owner); owner != "" {
+		next.Owner = owner
+	}
+	if req.FreshnessSLOSeconds != nil {
+		next.FreshnessSLOSeconds = *req.FreshnessSLOSeconds
+	}
+	if req.Consumers != nil {
+		next.Consumers = req.Consumers
+	}
+	if code := validateDataContractRequest(&next); code != "" {
+		api.writeError(w, r, http.StatusBadRequest, code)
+		return
+	}
+
+	diff := newDataContractDiff(base.Version, 0, base.Schema, next.Schema)
+	if req.DryRun {
+		api.writeJSON(w, http.StatusOK, dataContractMigrationResponse{Schema: next.Schema, Diff: diff})
+		return
+	}
+
+	contract, ok := api.insertDataContractVersion(w, r, identity, projectID, item.ID, next, map[string]any{
+		"migrated_from_version": base.Version,
+		"changes":               diff.Changes,
+		"breaking":              diff.Breaking,
+		"reason":                strings.TrimSpace(req.Reason),
+	})
+	if !ok {
+		return
+	}
+	diff.ToVersion = contract.Version
+	w.Header().Set("Location", fmt.Sprintf("/datasets/%s/contracts/%d", item.ID, contract.Version))
+	api.writeJSON(w, http.StatusCreated, dataContractMigrationResponse{Contract: &contract, Schema: contract.Schema, Diff: diff})
+}
+
+// handleDiffDataContract compares a contract version with an earlier one,
+// by default the previous version.
+func (api *datasetRegistryAPI) handleDiffDataContract(w http.ResponseWriter, r *http.Request) {
+	_, _, item, ok := api.dataContractDataset(w, r)
+	if !ok {
+		return
+	}
+	to, ok := api.dataContractFromPath(w, r, item.ID)
+	if !ok {
+		return
+	}
+	fromVersion := to.Version - 1
+	if raw := strings.TrimSpace(r.URL.Query().Get("from")); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			api.writeError(w, r, http.StatusBadRequest, "from_invalid")
+			return
+		}
+		fromVersion = parsed
+	}
+	if fromVersion <= 0 {
+		api.writeError(w, r, http.StatusBadRequest, "from_required")
+		return
+	}
+	from, err := api.loadDataContractVersion(r.Context(), item.ID, fromVersion)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			api.writeError(w, r, http.StatusNotFound, "from_not_found")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	api.writeJSON(w, http.StatusOK, newDataContractDiff(from.Version, to.Version, from.Schema, to.Schema))
+}
+
+func (api *datasetRegistryAPI) dataContractFromPath(w http.ResponseWriter, r *http.Request, datasetID string) (dataContract, bool) {
+	version, err := strconv.Atoi(strings.TrimSpace(r.PathValue("version")))
+	if err != nil || version <= 0 {
+		api.writeError(w, r, http.StatusBadRequest, "version_invalid")
+		return dataContract{}, false
+	}
+	contract, err := api.loadDataContractVersion(r.Context(), datasetID, version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return dataContract{}, false
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return dataContract{}, false
+	}
+	return contract, true
+}
+
+func (api *datasetRegistryAPI) loadDataContractVersion(ctx context.Context, datasetID string, version int) (dataContract, error) {
+	return scanDataContract(api.db.QueryRowContext(
+		ctx,
+		`SELECT `+dataContractColumns+` FROM data_contracts WHERE dataset_id = $1 AND version = $2`,
+		datasetID,
+		version,
+	))
+}
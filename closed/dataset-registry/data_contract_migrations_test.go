@@ -0,0 +1,85 @@
+package datasetregistry
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApplyDataContractMigration(t *testing.T) {
+	base := testDataContract().Schema
+	notNull := false
+	required := true
+	schema, code := applyDataContractMigration(base, []dataContractMigrationOp{
+		{Op: "rename_column", Column: "note", NewName: "comment"},
+		{Op: "set_type", Column: "amount", Type: "integer"},
+		{Op: "set_nullable", Column: "id", Nullable: &notNull},
+		{Op: "add_column", Definition: &dataContractColumn{Name: "currency", Type: "string", Required: true}},
+		{Op: "set_required", Column: "comment", Required: &required},
+	})
+	if code != "" {
+		t.Fatalf("unexpected code %s", code)
+	}
+	want := []dataContractColumn{
+		{Name: "id", Type: "string", Required: true, Nullable: &notNull},
+		{Name: "amount", Type: "integer", Required: true},
+		{Name: "comment", Type: "string", Required: true},
+		{Name: "currency", Type: "string", Required: true},
+	}
+	if !reflect.DeepEqual(schema.Columns, want) {
+		t.Fatalf("columns=%+v", schema.Columns)
+	}
+	if len(base.Columns) != 3 || base.Columns[2].Name != "note" {
+		t.Fatalf("base schema mutated: %+v", base.Columns)
+	}
+
+	cases := []struct {
+		ops  []dataContractMigrationOp
+		code string
+	}{
+		{ops: nil, code: "changes_required"},
+		{ops: []dataContractMigrationOp{{Op: "truncate", Column: "id"}}, code: "change_op_invalid"},
+		{ops: []dataContractMigrationOp{{Op: "drop_column", Column: "missing"}}, code: "column_not_found"},
+		{ops: []dataContractMigrationOp{{Op: "rename_column", Column: "id", NewName: "amount"}}, code: "duplicate_column"},
+		{ops: []dataContractMigrationOp{{Op: "add_column", Definition: &dataContractColumn{Name: "id"}}}, code: "duplicate_column"},
+		{ops: []dataContractMigrationOp{{Op: "add_column", Column: "x"}}, code: "column_definition_required"},
+		{ops: []dataContractMigrationOp{{Op: "set_required", Column: "id"}}, code: "required_required"},
+	}
+	for _, tc := range cases {
+		if _, code := applyDataContractMigration(base, tc.ops); code != tc.code {
+			t.Fatalf("%+v: expected %s, got %q", tc.ops, tc.code, code)
+		}
+	}
+}
+
+func TestDiffDataContractSchemas(t *testing.T) {
+	notNull := false
+	from := testDataContract().Schema
+	to := dataContractSchema{Columns: []dataContractColumn{
+		{Name: "id", Type: "string", Required: true, Nullable: &notNull},
+		{Name: "amount", Type: "number"},
+		{Name: "comment", Type: "string"},
+	}, AllowExtraColumns: true}
+
+	diff := newDataContractDiff(1, 2, from, to)
+	want := []dataContractSchemaChange{
+		{Column: "id", Change: "nullable_changed", From: "unchecked", To: "false", Breaking: true},
+		{Column: "amount", Change: "required_changed", From: "true", To: "false"},
+		{Column: "note", Change: "removed"},
+		{Column: "comment", Change: "added", To: "string"},
+		{Change: "allow_extra_columns_changed", From: "false", To: "true"},
+	}
+	if !reflect.DeepEqual(diff.Changes, want) {
+		t.Fatalf("changes=%+v", diff.Changes)
+	}
+	if !diff.Breaking {
+		t.Fatalf("expected breaking diff")
+	}
+
+	widened := newDataContractDiff(1, 2,
+		dataContractSchema{Columns: []dataContractColumn{{Name: "n", Type: "integer"}}},
+		dataContractSchema{Columns: []dataContractColumn{{Name: "n", Type: "number"}}},
+	)
+	if widened.Breaking || len(widened.Changes) != 1 {
+		t.Fatalf("integer to number must not break: %+v", widened)
+	}
+}
@@ -0,0 +1,1184 @@
+package datasetregistry
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/domain"
+	"github.com/animus-labs/animus-go/closed/internal/integrations/eventbus"
+	"github.com/animus-labs/animus-go/closed/internal/integrations/webhooks"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/dataprofile"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+	repopg "github.com/animus-labs/animus-go/closed/internal/repo/postgres"
+	"github.com/google/uuid"
+)
+
+const (
+	dataContractStatusPending    = "pending"
+	dataContractStatusApproved   = "approved"
+	dataContractStatusRejected   = "rejected"
+	dataContractStatusSuperseded = "superseded"
+
+	dataContractEvaluationPass   = "pass"
+	dataContractEvaluationBreach = "breach"
+
+	maxDataContractColumns   = 512
+	maxDataContractConsumers = 64
+
+	// dataContractHeaderBytes bounds how much of an upload is kept to read the CSV header.
+	dataContractHeaderBytes = 64 << 10
+)
+
+var dataContractColumnTypes = map[string]struct{}{
+	"string":    {},
+	"integer":   {},
+	"number":    {},
+	"boolean":   {},
+	"timestamp": {},
+}
+
+type dataContractColumn struct {
+	Name     string `json:"name"`
+	Type     string `json:"type,omitempty"`
+	Required bool   `json:"required,omitempty"`
+	// Nullable false forbids empty values; unset leaves nulls unchecked.
+	Nullable *bool `json:"nullable,omitempty"`
+}
+
+type dataContractSchema struct {
+	Columns           []dataContractColumn `json:"columns"`
+	AllowExtraColumns bool                 `json:"allow_extra_columns,omitempty"`
+}
+
+// observesValues reports whether checking the contract needs more than the
+// column names: typed or non-nullable columns are checked against the upload
+// profile.
+func (s dataContractSchema) observesValues() bool {
+	for _, column := range s.Columns {
+		if column.Type != "" || (column.Nullable != nil && !*column.Nullable) {
+			return true
+		}
+	}
+	return false
+}
+
+type dataContractConsumer struct {
+	Name                  string `json:"name"`
+	WebhookSubscriptionID string `json:"webhook_subscription_id,omitempty"`
+}
+
+type dataContract struct {
+	ContractID          string                 `json:"contract_id"`
+	ProjectID           string                 `json:"project_id"`
+	DatasetID           string                 `json:"dataset_id"`
+	Version             int                    `json:"version"`
+	Status              string                 `json:"status"`
+	Schema              dataContractSchema     `json:"schema"`
+	FreshnessSLOSeconds int64                  `json:"freshness_slo_seconds,omitempty"`
+	Owner               string                 `json:"owner"`
+	Consumers           []dataContractConsumer `json:"consumers"`
+	CreatedAt           time.Time              `json:"created_at"`
+	CreatedBy           string                 `json:"created_by"`
+	DecidedAt           *time.Time             `json:"decided_at,omitempty"`
+	DecidedBy           string                 `json:"decided_by,omitempty"`
+	DecisionReason      string                 `json:"decision_reason,omitempty"`
+	IntegritySHA256     string                 `json:"integrity_sha256"`
+}
+
+type createDataContractRequest struct {
+	Schema              dataContractSchema     `json:"schema"`
+	FreshnessSLOSeconds int64                  `json:"freshness_slo_seconds,omitempty"`
+	Owner               string                 `json:"owner"`
+	Consumers           []dataContractConsumer `json:"consumers,omitempty"`
+}
+
+type dataContractDecisionRequest struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+type dataContractViolation struct {
+	Code     string `json:"code"`
+	Column   string `json:"column,omitempty"`
+	Expected string `json:"expected,omitempty"`
+	Observed string `json:"observed,omitempty"`
+}
+
+type dataContractEvaluation struct {
+	EvaluationID     string                  `json:"evaluation_id"`
+	ContractID       string                  `json:"contract_id"`
+	ContractVersion  int                     `json:"contract_version"`
+	DatasetVersionID string                  `json:"dataset_version_id"`
+	Status           string                  `json:"status"`
+	Violations       []dataContractViolation `json:"violations"`
+	EvaluatedAt      time.Time               `json:"evaluated_at"`
+	EvaluatedBy      string                  `json:"evaluated_by"`
+}
+
+type observedColumn struct {
+	Name string
+	Type string
+	// Inferred types come from CSV profiling, which picks the narrowest type
+	// that fits; such a column still satisfies a string contract type.
+	Inferred  bool
+	NullCount *int64
+	// Empty columns hold only nulls, so their inferred type says nothing.
+	Empty bool
+}
+
+// observedDataset is what an upload tells us about its content: columns come
+// from the producer-declared metadata.schema or, failing that, the upload
+// profile or the CSV header. Null counts come from the profile.
+type observedDataset struct {
+	Columns  []observedColumn
+	DataAsOf *time.Time
+}
+
+func (api *datasetRegistryAPI) registerDataContracts(mux *http.ServeMux) {
+	mux.HandleFunc("GET /datasets/{dataset_id}/contracts", api.handleListDataContracts)
+	mux.HandleFunc("POST /datasets/{dataset_id}/contracts", api.handleCreateDataContract)
+	mux.HandleFunc("GET /datasets/{dataset_id}/contracts/{version}", api.handleGetDataContract)
+	mux.HandleFunc("POST /datasets/{dataset_id}/contracts/{version}/approve", api.handleApproveDataContract)
+	mux.HandleFunc("POST /datasets/{dataset_id}/contracts/{version}/reject", api.handleRejectDataContract)
+
+	mux.HandleFunc("GET /dataset-versions/{version_id}/contract-evaluations", api.handleListDataContractEvaluations)
+}
+
+func validateDataContractRequest(req *createDataContractRequest) string {
+	req.Owner = strings.TrimSpace(req.Owner)
+	if req.Owner == "" {
+		return "owner_required"
+	}
+	if len(req.Schema.Columns) == 0 {
+		return "schema_columns_required"
+	}
+	if len(req.Schema.Columns) > maxDataContractColumns {
+		return "too_many_columns"
+	}
+	seen := make(map[string]struct{}, len(req.Schema.Columns))
+	for i := range req.Schema.Columns {
+		column := &req.Schema.Columns[i]
+		column.Name = strings.TrimSpace(column.Name)
+		column.Type = strings.ToLower(strings.TrimSpace(column.Type))
+		if column.Name == "" {
+			return "column_name_required"
+		}
+		if _, ok := seen[column.Name]; ok {
+			return "duplicate_column"
+		}
+		seen[column.Name] = struct{}{}
+		if column.Type != "" {
+			if _, ok := dataContractColumnTypes[column.Type]; !ok {
+				return "column_type_invalid"
+			}
+		}
+	}
+	if req.FreshnessSLOSeconds < 0 {
+		return "freshness_slo_invalid"
+	}
+	if len(req.Consumers) > maxDataContractConsumers {
+		return "too_many_consumers"
+	}
+	for i := range req.Consumers {
+		consumer := &req.Consumers[i]
+		consumer.Name = strings.TrimSpace(consumer.Name)
+		consumer.WebhookSubscriptionID = strings.TrimSpace(consumer.WebhookSubscriptionID)
+		if consumer.Name == "" {
+			return "consumer_name_required"
+		}
+	}
+	if req.Consumers == nil {
+		req.Consumers = []dataContractConsumer{}
+	}
+	return ""
+}
+
+// evaluateDataContract checks an incoming version against the contract. The
+// result is deterministic: contract columns in declared order, then extra
+// columns sorted by name, then freshness.
+func evaluateDataContract(contract dataContract, observed observedDataset, now time.Time) []dataContractViolation {
+	violations := []dataContractViolation{}
+
+	if len(observed.Columns) == 0 {
+		violations = append(violations, dataContractViolation{Code: "schema_not_observable"})
+	} else {
+		observedByName := make(map[string]observedColumn, len(observed.Columns))
+		for _, column := range observed.Columns {
+			observedByName[column.Name] = column
+		}
+		expected := make(map[string]struct{}, len(contract.Schema.Columns))
+		for _, column := range contract.Schema.Columns {
+			expected[column.Name] = struct{}{}
+			got, ok := observedByName[column.Name]
+			if !ok {
+				if column.Required {
+					violations = append(violations, dataContractViolation{Code: "missing_column", Column: column.Name})
+				}
+				continue
+			}
+			if column.Type != "" && got.Type != "" && !got.Empty && !dataContractTypeCompatible(column.Type, got) {
+				violations = append(violations, dataContractViolation{
+					Code:     "type_mismatch",
+					Column:   column.Name,
+					Expected: column.Type,
+					Observed: got.Type,
+				})
+			}
+			if column.Nullable != nil && !*column.Nullable && got.NullCount != nil && *got.NullCount > 0 {
+				violations = append(violations, dataContractViolation{
+					Code:     "null_values",
+					Column:   column.Name,
+					Expected: "0",
+					Observed: strconv.FormatInt(*got.NullCount, 10),
+				})
+			}
+		}
+		if !contract.Schema.AllowExtraColumns {
+			extra := []string{}
+			for _, column := range observed.Columns {
+				if _, ok := expected[column.Name]; !ok {
+					extra = append(extra, column.Name)
+				}
+			}
+			sort.Strings(extra)
+			for _, name := range extra {
+				violations = append(violations, dataContractViolation{Code: "unexpected_column", Column: name})
+			}
+		}
+	}
+
+	if contract.FreshnessSLOSeconds > 0 {
+		slo := time.Duration(contract.FreshnessSLOSeconds) * time.Second
+		switch {
+		case observed.DataAsOf == nil:
+			violations = append(violations, dataContractViolation{Code: "freshness_not_reported"})
+		case now.Sub(*observed.DataAsOf) > slo:
+			violations = append(violations, dataContractViolation{
+				Code:     "freshness_slo_exceeded",
+				Expected: slo.String(),
+				Observed: now.Sub(*observed.DataAsOf).Truncate(time.Second).String(),
+			})
+		}
+	}
+	return violations
+}
+
+func dataContractTypeCompatible(expected string, observed observedColumn) bool {
+	if expected == observed.Type {
+		return true
+	}
+	if observed.Inferred && expected == "string" {
+		return true
+	}
+	return expected == "number" && observed.Type == "integer"
+}
+
+// observeDatasetVersion reads the declared schema and data_as_of from the
+// upload metadata, falling back to the profile and then the CSV header for
+// columns. A nil profile means the upload was not (or failed to be) profiled.
+func observeDatasetVersion(metadata map[string]any, head []byte, filename, contentType string, profile *dataprofile.Profile) observedDataset {
+	out := observedDataset{}
+	if schema, ok := metadata["schema"].(map[string]any); ok {
+		if columns, ok := schema["columns"].([]any); ok {
+			for _, raw := range columns {
+				entry, ok := raw.(map[string]any)
+				if !ok {
+					continue
+				}
+				name, _ := entry["name"].(string)
+				typ, _ := entry["type"].(string)
+				name = strings.TrimSpace(name)
+				if name == "" {
+					continue
+				}
+				out.Columns = append(out.Columns, observedColumn{Name: name, Type: strings.ToLower(strings.TrimSpace(typ))})
+			}
+		}
+	}
+	if len(out.Columns) == 0 && profile != nil {
+		for _, column := range profile.Columns {
+			out.Columns = append(out.Columns, observedColumn{Name: column.Name, Type: column.Type, Inferred: profile.Format == dataprofile.FormatCSV})
+		}
+	}
+	if profile != nil {
+		nulls := make(map[string]*int64, len(profile.Columns))
+		for _, column := range profile.Columns {
+			nulls[column.Name] = column.NullCount
+		}
+		for i := range out.Columns {
+			column := &out.Columns[i]
+			if count, ok := nulls[column.Name]; ok && count != nil {
+				column.NullCount = count
+				column.Empty = *count == profile.RowCount
+			}
+		}
+	}
+	if len(out.Columns) == 0 {
+		for _, name := range csvHeaderColumns(head, filename, contentType) {
+			out.Columns = append(out.Columns, observedColumn{Name: name})
+		}
+	}
+	if raw, ok := metadata["data_as_of"].(string); ok {
+		if parsed, err := time.Parse(time.RFC3339, strings.TrimSpace(raw)); err == nil {
+			parsed = parsed.UTC()
+			out.DataAsOf = &parsed
+		}
+	}
+	return out
+}
+
+func csvHeaderColumns(head []byte, filename, contentType string) []string {
+	isCSV := strings.EqualFold(path.Ext(filename), ".csv") || strings.Contains(strings.ToLower(contentType), "csv")
+	if !isCSV || len(head) == 0 {
+		return nil
+	}
+	head = bytes.TrimPrefix(head, []byte("\xef\xbb\xbf"))
+	line := head
+	if idx := bytes.IndexByte(head, '\n'); idx >= 0 {
+		line = head[:idx+1]
+	} else if len(head) >= dataContractHeaderBytes {
+		return nil
+	}
+	reader := csv.NewReader(bytes.NewReader(line))
+	reader.TrimLeadingSpace = true
+	record, err := reader.Read()
+	if err != nil {
+		return nil
+	}
+	out := make([]string, 0, len(record))
+	for _, name := range record {
+		if name = strings.TrimSpace(name); name != "" {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+// headWriter keeps the first limit bytes written to it.
+type headWriter struct {
+	limit int
+	buf   []byte
+}
+
+func (w *headWriter) Write(p []byte) (int, error) {
+	if remaining := w.limit - len(w.buf); remaining > 0 {
+		if len(p) < remaining {
+			remaining = len(p)
+		}
+		w.buf = append(w.buf, p[:remaining]...)
+	}
+	return len(p), nil
+}
+
+func (api *datasetRegistryAPI) handleCreateDataContract(w http.ResponseWriter, r *http.Request) {
+	identity, projectID, item, ok := api.dataContractDataset(w, r)
+	if !ok {
+		return
+	}
+	var req createDataContractRequest
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
+		return
+	}
+	if code := validateDataContractRequest(&req); code != "" {
+		api.writeError(w, r, http.StatusBadRequest, code)
+		return
+	}
+
+	contract, ok := api.insertDataContractVersion(w, r, identity, projectID, item.ID, req, nil)
+	if !ok {
+		return
+	}
+	w.Header().Set("Location", fmt.Sprintf("/datasets/%s/contracts/%d", item.ID, contract.Version))
+	api.writeJSON(w, http.StatusCreated, contract)
+}
+
+// insertDataContractVersion stores req as the next pending contract version
+// of the dataset and audits data_contract.create with auditExtra merged into
+// the payload. On failure it writes the error response.
+func (api *datasetRegistryAPI) insertDataContractVersion(w http.ResponseWriter, r *http.Request, identity auth.Identity, projectID, datasetID string, req createDataContractRequest, auditExtra map[string]any) (dataContract, bool) {
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return dataContract{}, false
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var version int
+	if err := tx.QueryRowContext(
+		r.Context(),
+		`SELECT COALESCE(MAX(version), 0) + 1 FROM data_contracts WHERE dataset_id = $1`,
+		datasetID,
+	).Scan(&version); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return dataContract{}, false
+	}
+
+	now := time.Now().UTC()
+	contract := dataContract{
+		ContractID:          uuid.NewString(),
+		ProjectID:           projectID,
+		DatasetID:           datasetID,
+		Version:             version,
+		Status:              dataContractStatusPending,
+		Schema:              req.Schema,
+		FreshnessSLOSeconds: req.FreshnessSLOSeconds,
+		Owner:               req.Owner,
+		Consumers:           req.Consumers,
+		CreatedAt:           now,
+		CreatedBy:           identity.Subject,
+	}
+	type integrityInput struct {
+		ContractID          string                 `json:"contract_id"`
+		ProjectID           string                 `json:"project_id"`
+		DatasetID           string                 `json:"dataset_id"`
+		Version             int                    `json:"version"`
+		Schema              dataContractSchema     `json:"schema"`
+		FreshnessSLOSeconds int64                  `json:"freshness_slo_seconds,omitempty"`
+		Owner               string                 `json:"owner"`
+		Consumers           []dataContractConsumer `json:"consumers"`
+		CreatedAt           time.Time              `json:"created_at"`
+		CreatedBy           string                 `json:"created_by"`
+	}
+	contract.IntegritySHA256, err = integritySHA256(integrityInput{
+		ContractID:          contract.ContractID,
+		ProjectID:           contract.ProjectID,
+		DatasetID:           contract.DatasetID,
+		Version:             contract.Version,
+		Schema:              contract.Schema,
+		FreshnessSLOSeconds: contract.FreshnessSLOSeconds,
+		Owner:               contract.Owner,
+		Consumers:           contract.Consumers,
+		CreatedAt:           contract.CreatedAt,
+		CreatedBy:           contract.CreatedBy,
+	})
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return dataContract{}, false
+	}
+	schemaJSON, _ := json.Marshal(contract.Schema)
+	consumersJSON, _ := json.Marshal(contract.Consumers)
+
+	_, err = tx.ExecContext(
+		r.Context(),
+		`INSERT INTO data_contracts (
+			contract_id,
+			project_id,
+			dataset_id,
+			version,
+			status,
+			schema,
+			freshness_slo_seconds,
+			owner,
+			consumers,
+			created_at,
+			created_by,
+			integrity_sha256
+		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12)`,
+		contract.ContractID,
+		contract.ProjectID,
+		contract.DatasetID,
+		contract.Version,
+		contract.Status,
+		schemaJSON,
+		nullInt64(contract.FreshnessSLOSeconds),
+		contract.Owner,
+		consumersJSON,
+		contract.CreatedAt,
+		contract.CreatedBy,
+		contract.IntegritySHA256,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			api.writeError(w, r, http.StatusConflict, "contract_version_conflict")
+			return dataContract{}, false
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return dataContract{}, false
+	}
+
+	payload := map[string]any{
+		"service":               "dataset-registry",
+		"project_id":            projectID,
+		"dataset_id":            datasetID,
+		"contract_version":      contract.Version,
+		"owner":                 contract.Owner,
+		"consumers":             len(contract.Consumers),
+		"freshness_slo_seconds": contract.FreshnessSLOSeconds,
+		"integrity_sha256":      contract.IntegritySHA256,
+	}
+	for key, value := range auditExtra {
+		payload[key] = value
+	}
+	if err := auditlog.Enqueue(r.Context(), tx, auditlog.Event{
+		OccurredAt:   now,
+		Actor:        identity.Subject,
+		Action:       "data_contract.create",
+		ResourceType: "data_contract",
+		ResourceID:   contract.ContractID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload:      payload,
+	}); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "audit_write_failed")
+		return dataContract{}, false
+	}
+	if err := tx.Commit(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return dataContract{}, false
+	}
+
+	return contract, true
+}
+
+func (api *datasetRegistryAPI) handleListDataContracts(w http.ResponseWriter, r *http.Request) {
+	_, _, item, ok := api.dataContractDataset(w, r)
+	if !ok {
+		return
+	}
+	rows, err := api.db.QueryContext(
+		r.Context(),
+		`SELECT `+dataContractColumns+`
+		 FROM data_contracts
+		 WHERE dataset_id = $1
+		 ORDER BY version DESC`,
+		item.ID,
+	)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer rows.Close()
+
+	out := []dataContract{}
+	for rows.Next() {
+		contract, err := scanDataContract(rows)
+		if err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+		out = append(out, contract)
+	}
+	if err := rows.Err(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	api.writeJSON(w, http.StatusOK, map[string]any{"contracts": out})
+}
+
+func (api *datasetRegistryAPI) handleGetDataContract(w http.ResponseWriter, r *http.Request) {
+	_, _, item, ok := api.dataContractDataset(w, r)
+	if !ok {
+		return
+	}
+	contract, ok := api.dataContractFromPath(w, r, item.ID)
+	if !ok {
+		return
+	}
+	api.writeJSON(w, http.StatusOK, contract)
+}
+
+func (api *datasetRegistryAPI) handleApproveDataContract(w http.ResponseWriter, r *http.Request) {
+	api.decideDataContract(w, r, dataContractStatusApproved)
+}
+
+func (api *datasetRegistryAPI) handleRejectDataContract(w http.ResponseWriter, r *http.Request) {
+	api.decideDataContract(w, r, dataContractStatusRejected)
+}
+
+// decideDataContract moves a pending contract version to approved or rejected.
+// Approval requires a reviewer other than the author and supersedes the
+// previously approved version of the dataset.
+func (api *datasetRegistryAPI) decideDataContract(w http.ResponseWriter, r *http.Request, decision string) {
+	identity, projectID, item, ok := api.dataContractDataset(w, r)
+	if !ok {
+		return
+	}
+	version, err := strconv.Atoi(strings.TrimSpace(r.PathValue("version")))
+	if err != nil || version <= 0 {
+		api.writeError(w, r, http.StatusBadRequest, "version_invalid")
+		return
+	}
+	var req dataContractDecisionRequest
+	if r.ContentLength != 0 {
+		if err := httpapi.DecodeJSON(r, &req); err != nil {
+			httpapi.WriteDecodeError(w, r, err)
+			return
+		}
+	}
+	reason := strings.TrimSpace(req.Reason)
+
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	contract, err := scanDataContract(tx.QueryRowContext(
+		r.Context(),
+		`SELECT `+dataContractColumns+` FROM data_contracts WHERE dataset_id = $1 AND version = $2 FOR UPDATE`,
+		item.ID,
+		version,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if contract.Status != dataContractStatusPending {
+		api.writeError(w, r, http.StatusConflict, "contract_not_pending")
+		return
+	}
+	if decision == dataContractStatusApproved && contract.CreatedBy == identity.Subject {
+		api.writeError(w, r, http.StatusForbidden, "approval_requires_second_reviewer")
+		return
+	}
+
+	now := time.Now().UTC()
+	supersededVersion := 0
+	if decision == dataContractStatusApproved {
+		err := tx.QueryRowContext(
+			r.Context(),
+			`UPDATE data_contracts
+			 SET status = $2
+			 WHERE dataset_id = $1 AND status = $3
+			 RETURNING version`,
+			item.ID,
+			dataContractStatusSuperseded,
+			dataContractStatusApproved,
+		).Scan(&supersededVersion)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+	}
+	if _, err := tx.ExecContext(
+		r.Context(),
+		`UPDATE data_contracts
+		 SET status = $2, decided_at = $3, decided_by = $4, decision_reason = $5
+		 WHERE contract_id = $1`,
+		contract.ContractID,
+		decision,
+		now,
+		identity.Subject,
+		nullString(reason),
+	); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	action := "data_contract.approve"
+	if decision == dataContractStatusRejected {
+		action = "data_contract.reject"
+	}
+	payload := map[string]any{
+		"service":          "dataset-registry",
+		"project_id":       projectID,
+		"dataset_id":       item.ID,
+		"contract_version": contract.Version,
+		"requested_by":     contract.CreatedBy,
+		"reason":           reason,
+	}
+	if supersededVersion > 0 {
+		payload["superseded_version"] = supersededVersion
+	}
+	if err := auditlog.Enqueue(r.Context(), tx, auditlog.Event{
+		OccurredAt:   now,
+		Actor:        identity.Subject,
+		Action:       action,
+		ResourceType: "data_contract",
+		ResourceID:   contract.ContractID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload:      payload,
+	}); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "audit_write_failed")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	contract.Status = decision
+	contract.DecidedAt = &now
+	contract.DecidedBy = identity.Subject
+	contract.DecisionReason = reason
+	api.writeJSON(w, http.StatusOK, contract)
+}
+
+func (api *datasetRegistryAPI) handleListDataContractEvaluations(w http.ResponseWriter, r *http.Request) {
+	versionID := strings.TrimSpace(r.PathValue("version_id"))
+	if versionID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "version_id_required")
+		return
+	}
+	if api.svc == nil {
+		api.writeError(w, r, http.StatusInternalServerError, "service_unavailable")
+		return
+	}
+	projectID, ok := auth.ProjectIDFromContext(r.Context())
+	if !ok || strings.TrimSpace(projectID) == "" {
+		api.writeError(w, r, http.StatusBadRequest, "project_id_required")
+		return
+	}
+	if _, err := api.svc.GetDatasetVersion(r.Context(), projectID, versionID); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	rows, err := api.db.QueryContext(
+		r.Context(),
+		`SELECT e.evaluation_id, e.contract_id, c.version, e.dataset_version_id, e.status, e.violations, e.evaluated_at, e.evaluated_by
+		 FROM data_contract_evaluations e
+		 JOIN data_contracts c ON c.contract_id = e.contract_id
+		 WHERE e.dataset_version_id = $1
+		 ORDER BY e.evaluated_at DESC`,
+		versionID,
+	)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer rows.Close()
+
+	out := []dataContractEvaluation{}
+	for rows.Next() {
+		var (
+			evaluation dataContractEvaluation
+			violations []byte
+		)
+		if err := rows.Scan(
+			&evaluation.EvaluationID,
+			&evaluation.ContractID,
+			&evaluation.ContractVersion,
+			&evaluation.DatasetVersionID,
+			&evaluation.Status,
+			&violations,
+			&evaluation.EvaluatedAt,
+			&evaluation.EvaluatedBy,
+		); err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+		evaluation.Violations = []dataContractViolation{}
+		_ = json.Unmarshal(violations, &evaluation.Violations)
+		evaluation.EvaluatedAt = evaluation.EvaluatedAt.UTC()
+		out = append(out, evaluation)
+	}
+	if err := rows.Err(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	api.writeJSON(w, http.StatusOK, map[string]any{"evaluations": out})
+}
+
+func (api *datasetRegistryAPI) dataContractDataset(w http.ResponseWriter, r *http.Request) (auth.Identity, string, domain.Dataset, bool) {
+	identity, ok := auth.IdentityFromContext(r.Context())
+	if !ok || strings.TrimSpace(identity.Subject) == "" {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return auth.Identity{}, "", domain.Dataset{}, false
+	}
+	datasetID := strings.TrimSpace(r.PathValue("dataset_id"))
+	if datasetID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "dataset_id_required")
+		return auth.Identity{}, "", domain.Dataset{}, false
+	}
+	if api.svc == nil || api.db == nil {
+		api.writeError(w, r, http.StatusInternalServerError, "service_unavailable")
+		return auth.Identity{}, "", domain.Dataset{}, false
+	}
+	projectID, ok := auth.ProjectIDFromContext(r.Context())
+	if !ok || strings.TrimSpace(projectID) == "" {
+		api.writeError(w, r, http.StatusBadRequest, "project_id_required")
+		return auth.Identity{}, "", domain.Dataset{}, false
+	}
+	item, err := api.svc.GetDataset(r.Context(), projectID, datasetID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return auth.Identity{}, "", domain.Dataset{}, false
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return auth.Identity{}, "", domain.Dataset{}, false
+	}
+	return identity, projectID, item, true
+}
+
+// activeDataContract returns the approved contract of the dataset, if any.
+func (api *datasetRegistryAPI) activeDataContract(ctx context.Context, datasetID string) (*dataContract, error) {
+	contract, err := scanDataContract(api.db.QueryRowContext(
+		ctx,
+		`SELECT `+dataContractColumns+` FROM data_contracts WHERE dataset_id = $1 AND status = $2`,
+		datasetID,
+		dataContractStatusApproved,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &contract, nil
+}
+
+// recordDataContractEvaluation stores the evaluation of a freshly uploaded
+// version and, on breach, notifies the contract's consumers.
+func (api *datasetRegistryAPI) recordDataContractEvaluation(ctx context.Context, r *http.Request, contract dataContract, version domain.DatasetVersion, violations []dataContractViolation, evaluatedAt time.Time) (dataContractEvaluation, error) {
+	status := dataContractEvaluationPass
+	if len(violations) > 0 {
+		status = dataContractEvaluationBreach
+	}
+	evaluation := dataContractEvaluation{
+		EvaluationID:     uuid.NewString(),
+		ContractID:       contract.ContractID,
+		ContractVersion:  contract.Version,
+		DatasetVersionID: version.ID,
+		Status:           status,
+		Violations:       violations,
+		EvaluatedAt:      evaluatedAt,
+		EvaluatedBy:      version.CreatedBy,
+	}
+	violationsJSON, err := json.Marshal(violations)
+	if err != nil {
+		return dataContractEvaluation{}, err
+	}
+	integrity, err := integritySHA256(struct {
+		EvaluationID     string          `json:"evaluation_id"`
+		ContractID       string          `json:"contract_id"`
+		DatasetVersionID string          `json:"dataset_version_id"`
+		Status           string          `json:"status"`
+		Violations       json.RawMessage `json:"violations"`
+		EvaluatedAt      time.Time       `json:"evaluated_at"`
+		EvaluatedBy      string          `json:"evaluated_by"`
+	}{
+		EvaluationID:     evaluation.EvaluationID,
+		ContractID:       evaluation.ContractID,
+		DatasetVersionID: evaluation.DatasetVersionID,
+		Status:           evaluation.Status,
+		Violations:       violationsJSON,
+		EvaluatedAt:      evaluation.EvaluatedAt,
+		EvaluatedBy:      evaluation.EvaluatedBy,
+	})
+	if err != nil {
+		return dataContractEvaluation{}, err
+	}
+
+	tx, err := api.db.BeginTx(ctx, nil)
+	if err != nil {
+		return dataContractEvaluation{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(
+		ctx,
+		`INSERT INTO data_contract_evaluations (
+			evaluation_id,
+			contract_id,
+			project_id,
+			dataset_id,
+			dataset_version_id,
+			status,
+			violations,
+			evaluated_at,
+			evaluated_by,
+			integrity_sha256
+		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)`,
+		evaluation.EvaluationID,
+		evaluation.ContractID,
+		version.ProjectID,
+		version.DatasetID,
+		evaluation.DatasetVersionID,
+		evaluation.Status,
+		violationsJSON,
+		evaluation.EvaluatedAt,
+		evaluation.EvaluatedBy,
+		integrity,
+	); err != nil {
+		return dataContractEvaluation{}, err
+	}
+
+	action := "data_contract.pass"
+	if status == dataContractEvaluationBreach {
+		action = "data_contract.breach"
+	}
+	if err := auditlog.Enqueue(ctx, tx, auditlog.Event{
+		OccurredAt:   evaluatedAt,
+		Actor:        version.CreatedBy,
+		Action:       action,
+		ResourceType: "dataset_version",
+		ResourceID:   version.ID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload: map[string]any{
+			"service":          "dataset-registry",
+			"project_id":       version.ProjectID,
+			"dataset_id":       version.DatasetID,
+			"contract_id":      contract.ContractID,
+			"contract_version": contract.Version,
+			"evaluation_id":    evaluation.EvaluationID,
+			"violations":       violations,
+		},
+	}); err != nil {
+		return dataContractEvaluation{}, err
+	}
+	if err := api.bus.Enqueue(ctx, tx, eventbus.EventDataContractEvaluated, evaluation.EvaluationID, version.ProjectID, evaluatedAt,
+		map[string]string{
+			"data_contract_id":   contract.ContractID,
+			"dataset_id":         version.DatasetID,
+			"dataset_version_id": version.ID,
+		},
+		map[string]any{
+			"status":           status,
+			"contract_version": contract.Version,
+			"violations":       len(violations),
+		},
+	); err != nil {
+		return dataContractEvaluation{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return dataContractEvaluation{}, err
+	}
+
+	if status == dataContractEvaluationBreach {
+		if err := api.notifyDataContractConsumers(ctx, r, contract, version, evaluatedAt); err != nil && api.logger != nil {
+			api.logger.Warn("data contract breach webhook enqueue failed", "dataset_version_id", version.ID, "error", err)
+		}
+	}
+	return evaluation, nil
+}
+
+func (api *datasetRegistryAPI) notifyDataContractConsumers(ctx context.Context, r *http.Request, contract dataContract, version domain.DatasetVersion, emittedAt time.Time) error {
+	if !api.webhookCfg.Enabled() {
+		return nil
+	}
+	payload, err := webhooks.DataContractBreachedPayload(version.ProjectID, version.ID, contract.ContractID, emittedAt)
+	if err != nil {
+		return err
+	}
+	payloadJSON, err := webhooks.PayloadJSON(payload)
+	if err != nil {
+		return err
+	}
+	subStore := repopg.NewWebhookSubscriptionStore(api.db)
+	deliveryStore := repopg.NewWebhookDeliveryStore(api.db)
+	if subStore == nil || deliveryStore == nil {
+		return errors.New("webhook store unavailable")
+	}
+
+	var lastErr error
+	for _, consumer := range contract.Consumers {
+		if consumer.WebhookSubscriptionID == "" {
+			continue
+		}
+		sub, err := subStore.Get(ctx, version.ProjectID, consumer.WebhookSubscriptionID)
+		if err != nil {
+			if !errors.Is(err, repo.ErrNotFound) {
+				lastErr = err
+			}
+			continue
+		}
+		if !sub.Enabled {
+			continue
+		}
+		now := time.Now().UTC()
+		record, inserted, err := deliveryStore.Enqueue(ctx, webhooks.Delivery{
+			ID:             uuid.NewString(),
+			ProjectID:      version.ProjectID,
+			SubscriptionID: sub.ID,
+			EventID:        payload.EventID,
+			EventType:      payload.EventType,
+			Payload:        payloadJSON,
+			Status:         webhooks.DeliveryStatusPending,
+			NextAttemptAt:  now,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		})
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if inserted {
+			_ = auditlog.Enqueue(ctx, api.db, auditlog.Event{
+				OccurredAt:   now,
+				Actor:        version.CreatedBy,
+				Action:       "webhook.delivery.enqueued",
+				ResourceType: "webhook_delivery",
+				ResourceID:   record.ID,
+				RequestID:    r.Header.Get("X-Request-Id"),
+				Payload: map[string]any{
+					"service":         "dataset-registry",
+					"project_id":      version.ProjectID,
+					"subscription_id": record.SubscriptionID,
+					"event_id":        record.EventID,
+					"event_type":      record.EventType.String(),
+					"consumer":        consumer.Name,
+				},
+			})
+		}
+	}
+	return lastErr
+}
+
+// dataContractBreached reports whether the latest contract evaluation of the
+// version is a breach, with its violations. Versions without an evaluation
+// are not blocked.
+func (api *datasetRegistryAPI) dataContractBreached(ctx context.Context, versionID string) (string, []dataContractViolation, bool, error) {
+	var (
+		evaluationID, status string
+		violationsJSON       []byte
+	)
+	err := api.db.QueryRowContext(
+		ctx,
+		`SELECT evaluation_id, status, violations
+		 FROM data_contract_evaluations
+		 WHERE dataset_version_id = $1
+		 ORDER BY evaluated_at DESC
+		 LIMIT 1`,
+		versionID,
+	).Scan(&evaluationID, &status, &violationsJSON)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", nil, false, nil
+		}
+		return "", nil, false, err
+	}
+	if status != dataContractEvaluationBreach {
+		return evaluationID, nil, false, nil
+	}
+	violations := []dataContractViolation{}
+	if len(violationsJSON) > 0 {
+		if err := json.Unmarshal(violationsJSON, &violations); err != nil {
+			return "", nil, false, fmt.Errorf("decode contract violations: %w", err)
+		}
+	}
+	return evaluationID, violations, true, nil
+}
+
+// dataContractColumnDiff describes one column of a breached contract: missing,
+// unexpected, or changed with the expected and observed type and null count.
+type dataContractColumnDiff struct {
+	Column       string `json:"column"`
+	Change       string `json:"change"`
+	ExpectedType string `json:"expected_type,omitempty"`
+	ObservedType string `json:"observed_type,omitempty"`
+	NullCount    string `json:"null_count,omitempty"`
+}
+
+// dataContractColumnDiffs groups column violations by column, in violation
+// order, for gate responses.
+func dataContractColumnDiffs(violations []dataContractViolation) []dataContractColumnDiff {
+	out := []dataContractColumnDiff{}
+	index := map[string]int{}
+	for _, v := range violations {
+		if v.Column == "" {
+			continue
+		}
+		i, ok := index[v.Column]
+		if !ok {
+			i = len(out)
+			index[v.Column] = i
+			out = append(out, dataContractColumnDiff{Column: v.Column, Change: "changed"})
+		}
+		diff := &out[i]
+		switch v.Code {
+		case "missing_column":
+			diff.Change = "missing"
+		case "unexpected_column":
+			diff.Change = "unexpected"
+		case "type_mismatch":
+			diff.ExpectedType = v.Expected
+			diff.ObservedType = v.Observed
+		case "null_values":
+			diff.NullCount = v.Observed
+		}
+	}
+	return out
+}
+
+const dataContractColumns = `contract_id, project_id, dataset_id, version, status, schema, freshness_slo_seconds, owner, consumers,
+	created_at, created_by, decided_at, decided_by, decision_reason, integrity_sha256`
+
+type dataContractScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanDataContract(row dataContractScanner) (dataContract, error) {
+	var (
+		contract       dataContract
+		schemaJSON     []byte
+		consumersJSON  []byte
+		freshnessSLO   sql.NullInt64
+		decidedAt      sql.NullTime
+		decidedBy      sql.NullString
+		decisionReason sql.NullString
+	)
+	if err := row.Scan(
+		&contract.ContractID,
+		&contract.ProjectID,
+		&contract.DatasetID,
+		&contract.Version,
+		&contract.Status,
+		&schemaJSON,
+		&freshnessSLO,
+		&contract.Owner,
+		&consumersJSON,
+		&contract.CreatedAt,
+		&contract.CreatedBy,
+		&decidedAt,
+		&decidedBy,
+		&decisionReason,
+		&contract.IntegritySHA256,
+	); err != nil {
+		return dataContract{}, err
+	}
+	if err := json.Unmarshal(schemaJSON, &contract.Schema); err != nil {
+		return dataContract{}, fmt.Errorf("decode contract schema: %w", err)
+	}
+	contract.Consumers = []dataContractConsumer{}
+	if len(consumersJSON) > 0 {
+		if err := json.Unmarshal(consumersJSON, &contract.Consumers); err != nil {
+			return dataContract{}, fmt.Errorf("decode contract consumers: %w", err)
+		}
+	}
+	contract.FreshnessSLOSeconds = freshnessSLO.Int64
+	contract.CreatedAt = contract.CreatedAt.UTC()
+	if decidedAt.Valid {
+		t := decidedAt.Time.UTC()
+		contract.DecidedAt = &t
+	}
+	contract.DecidedBy = strings.TrimSpace(decidedBy.String)
+	contract.DecisionReason = strings.TrimSpace(decisionReason.String)
+	return contract, nil
+}
+
+func nullInt64(value int64) sql.NullInt64 {
+	if value <= 0 {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: value, Valid: true}
+}
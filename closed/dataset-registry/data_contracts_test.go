@@ -0,0 +1,195 @@
+package datasetregistry
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/dataprofile"
+)
+
+func testDataContract() dataContract {
+	return dataContract{
+		Schema: dataContractSchema{Columns: []dataContractColumn{
+			{Name: "id", Type: "string", Required: true},
+			{Name: "amount", Type: "number", Required: true},
+			{Name: "note", Type: "string"},
+		}},
+		FreshnessSLOSeconds: 3600,
+	}
+}
+
+func TestEvaluateDataContract(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	fresh := now.Add(-10 * time.Minute)
+	stale := now.Add(-2 * time.Hour)
+
+	cases := []struct {
+		name     string
+		observed observedDataset
+		want     []string
+	}{
+		{
+			name: "pass with integer for number",
+			observed: observedDataset{
+				Columns:  []observedColumn{{Name: "id", Type: "string"}, {Name: "amount", Type: "integer"}},
+				DataAsOf: &fresh,
+			},
+			want: []string{},
+		},
+		{
+			name: "untyped csv header",
+			observed: observedDataset{
+				Columns:  []observedColumn{{Name: "id"}, {Name: "amount"}, {Name: "note"}},
+				DataAsOf: &fresh,
+			},
+			want: []string{},
+		},
+		{
+			name: "missing, mismatched, extra and stale",
+			observed: observedDataset{
+				Columns:  []observedColumn{{Name: "amount", Type: "string"}, {Name: "zeta"}, {Name: "alpha"}},
+				DataAsOf: &stale,
+			},
+			want: []string{"missing_column", "type_mismatch", "unexpected_column", "unexpected_column", "freshness_slo_exceeded"},
+		},
+		{
+			name:     "nothing observable",
+			observed: observedDataset{},
+			want:     []string{"schema_not_observable", "freshness_not_reported"},
+		},
+	}
+	for _, tc := range cases {
+		violations := evaluateDataContract(testDataContract(), tc.observed, now)
+		got := []string{}
+		for _, v := range violations {
+			got = append(got, v.Code)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Fatalf("%s: got %v want %v", tc.name, got, tc.want)
+		}
+	}
+
+	contract := testDataContract()
+	contract.Schema.AllowExtraColumns = true
+	contract.FreshnessSLOSeconds = 0
+	observed := observedDataset{Columns: []observedColumn{{Name: "id"}, {Name: "amount"}, {Name: "extra"}}}
+	if violations := evaluateDataContract(contract, observed, now); len(violations) != 0 {
+		t.Fatalf("expected extra columns to be allowed, got %v", violations)
+	}
+}
+
+func TestObserveDatasetVersion(t *testing.T) {
+	declared := map[string]any{
+		"schema": map[string]any{"columns": []any{
+			map[string]any{"name": "id", "type": "String"},
+			map[string]any{"name": "amount", "type": "number"},
+		}},
+		"data_as_of": "2026-03-01T11:00:00Z",
+	}
+	observed := observeDatasetVersion(declared, []byte("ignored,header\n"), "data.csv", "text/csv", nil)
+	if want := []observedColumn{{Name: "id", Type: "string"}, {Name: "amount", Type: "number"}}; !reflect.DeepEqual(observed.Columns, want) {
+		t.Fatalf("declared columns=%v", observed.Columns)
+	}
+	if observed.DataAsOf == nil || !observed.DataAsOf.Equal(time.Date(2026, 3, 1, 11, 0, 0, 0, time.UTC)) {
+		t.Fatalf("data_as_of=%v", observed.DataAsOf)
+	}
+
+	observed = observeDatasetVersion(map[string]any{}, []byte("\xef\xbb\xbfid, \"amount\"\n1,2\n"), "data.csv", "", nil)
+	if want := []observedColumn{{Name: "id"}, {Name: "amount"}}; !reflect.DeepEqual(observed.Columns, want) {
+		t.Fatalf("csv columns=%v", observed.Columns)
+	}
+
+	if cols := csvHeaderColumns([]byte("id,amount\r\n1,2\r\n"), "data.csv", ""); !reflect.DeepEqual(cols, []string{"id", "amount"}) {
+		t.Fatalf("crlf header=%q", cols)
+	}
+	if cols := csvHeaderColumns([]byte("id,amount\n"), "data.parquet", "application/octet-stream"); cols != nil {
+		t.Fatalf("expected non-csv upload to have no header, got %v", cols)
+	}
+}
+
+func TestHeadWriterKeepsPrefix(t *testing.T) {
+	w := &headWriter{limit: 4}
+	for _, chunk := range []string{"ab", "cdef", "gh"} {
+		if n, err := w.Write([]byte(chunk)); err != nil || n != len(chunk) {
+			t.Fatalf("Write(%q)=%d,%v", chunk, n, err)
+		}
+	}
+	if string(w.buf) != "abcd" {
+		t.Fatalf("buf=%q", w.buf)
+	}
+}
+
+func TestValidateDataContractRequest(t *testing.T) {
+	valid := func() createDataContractRequest {
+		return createDataContractRequest{
+			Owner:  " data-platform ",
+			Schema: dataContractSchema{Columns: []dataContractColumn{{Name: "id", Type: "String", Required: true}}},
+		}
+	}
+	req := valid()
+	if code := validateDataContractRequest(&req); code != "" {
+		t.Fatalf("unexpected code %q", code)
+	}
+	if req.Owner != "data-platform" || req.Schema.Columns[0].Type != "string" || req.Consumers == nil {
+		t.Fatalf("request not normalized: %+v", req)
+	}
+
+	for want, mutate := range map[string]func(*createDataContractRequest){
+		"owner_required":          func(r *createDataContractRequest) { r.Owner = "" },
+		"schema_columns_required": func(r *createDataContractRequest) { r.Schema.Columns = nil },
+		"duplicate_column": func(r *createDataContractRequest) {
+			r.Schema.Columns = append(r.Schema.Columns, dataContractColumn{Name: "id"})
+		},
+		"column_type_invalid":   func(r *createDataContractRequest) { r.Schema.Columns[0].Type = "decimal" },
+		"freshness_slo_invalid": func(r *createDataContractRequest) { r.FreshnessSLOSeconds = -1 },
+		"consumer_name_required": func(r *createDataContractRequest) {
+			r.Consumers = []dataContractConsumer{{WebhookSubscriptionID: "sub-1"}}
+		},
+	} {
+		req := valid()
+		mutate(&req)
+		if code := validateDataContractRequest(&req); code != want {
+			t.Fatalf("got %q want %q", code, want)
+		}
+	}
+}
+
+func TestEvaluateDataContractWithProfile(t *testing.T) {
+	notNull := false
+	contract := dataContract{Schema: dataContractSchema{Columns: []dataContractColumn{
+		{Name: "id", Type: "string", Required: true, Nullable: &notNull},
+		{Name: "amount", Type: "integer", Required: true},
+		{Name: "created_at", Type: "timestamp"},
+		{Name: "note", Type: "integer"},
+	}}}
+	if !contract.Schema.observesValues() {
+		t.Fatalf("typed contract must observe values")
+	}
+	nulls := func(n int64) *int64 { return &n }
+	profile := &dataprofile.Profile{Format: dataprofile.FormatCSV, RowCount: 10, Columns: []dataprofile.Column{
+		{Name: "id", Type: "integer", NullCount: nulls(2)},
+		{Name: "amount", Type: "number", NullCount: nulls(0)},
+		{Name: "created_at", Type: "timestamp", NullCount: nulls(0)},
+		{Name: "note", Type: "string", NullCount: nulls(10)},
+	}}
+	observed := observeDatasetVersion(map[string]any{}, []byte("id,amount,created_at,note\n"), "data.csv", "text/csv", profile)
+	violations := evaluateDataContract(contract, observed, time.Now())
+	want := []dataContractViolation{
+		{Code: "null_values", Column: "id", Expected: "0", Observed: "2"},
+		{Code: "type_mismatch", Column: "amount", Expected: "integer", Observed: "number"},
+	}
+	if !reflect.DeepEqual(violations, want) {
+		t.Fatalf("violations=%+v", violations)
+	}
+
+	diffs := dataContractColumnDiffs(append(violations, dataContractViolation{Code: "missing_column", Column: "extra"}, dataContractViolation{Code: "freshness_not_reported"}))
+	wantDiffs := []dataContractColumnDiff{
+		{Column: "id", Change: "changed", NullCount: "2"},
+		{Column: "amount", Change: "changed", ExpectedType: "integer", ObservedType: "number"},
+		{Column: "extra", Change: "missing"},
+	}
+	if !reflect.DeepEqual(diffs, wantDiffs) {
+		t.Fatalf("diffs=%+v", diffs)
+	}
+}
@@ -0,0 +1,405 @@
+package datasetregistry
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/domain"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/dataprofile"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+	"github.com/google/uuid"
+)
+
+const (
+	driftStatusStable  = "stable"
+	driftStatusDrifted = "drifted"
+)
+
+// defaultDriftThreshold is the score at which a report counts as drifted; a
+// PSI above 0.2 is commonly read as a significant shift.
+const defaultDriftThreshold = 0.2
+
+const maxDriftThreshold = 10
+
+// createDriftReportRequest names exactly one baseline: a dataset version, or
+// a model whose latest approved version was trained on this dataset.
+type createDriftReportRequest struct {
+	BaselineVersionID string   `json:"baseline_version_id,omitempty"`
+	ModelID           string   `json:"model_id,omitempty"`
+	Threshold         *float64 `json:"threshold,omitempty"`
+}
+
+type datasetDriftReport struct {
+	ReportID               string            `json:"report_id"`
+	DatasetID              string            `json:"dataset_id"`
+	VersionID              string            `json:"version_id"`
+	BaselineVersionID      string            `json:"baseline_version_id"`
+	BaselineModelVersionID string            `json:"baseline_model_version_id,omitempty"`
+	Score                  float64           `json:"score"`
+	Threshold              float64           `json:"threshold"`
+	Status                 string            `json:"status"`
+	Drift                  dataprofile.Drift `json:"drift"`
+	CreatedAt              time.Time         `json:"created_at"`
+	CreatedBy              string            `json:"created_by"`
+	IntegritySHA256        string            `json:"integrity_sha256"`
+}
+
+func (api *datasetRegistryAPI) registerDrift(mux *http.ServeMux) {
+	mux.HandleFunc("GET /datasets/{dataset_id}/versions/{version_id}/drift-reports", api.handleListDriftReports)
+	mux.HandleFunc("POST /datasets/{dataset_id}/versions/{version_id}/drift-reports", api.handleCreateDriftReport)
+	mux.HandleFunc("GET /datasets/{dataset_id}/versions/{version_id}/drift-reports/{report_id}", api.handleGetDriftReport)
+}
+
+// driftThreshold resolves the requested threshold; nil selects the default.
+func driftThreshold(requested *float64) (float64, bool) {
+	if requested == nil {
+		return defaultDriftThreshold, true
+	}
+	value := *requested
+	if math.IsNaN(value) || value <= 0 || value > maxDriftThreshold {
+		return 0, false
+	}
+	return value, true
+}
+
+func driftStatus(score, threshold float64) string {
+	if score >= threshold {
+		return driftStatusDrifted
+	}
+	return driftStatusStable
+}
+
+// driftVersion loads the dataset version addressed by the path.
+func (api *datasetRegistryAPI) driftVersion(w http.ResponseWriter, r *http.Request) (auth.Identity, string, domain.DatasetVersion, bool) {
+	identity, projectID, item, ok := api.dataContractDataset(w, r)
+	if !ok {
+		return auth.Identity{}, "", domain.DatasetVersion{}, false
+	}
+	versionID := strings.TrimSpace(r.PathValue("version_id"))
+	if versionID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "version_id_required")
+		return auth.Identity{}, "", domain.DatasetVersion{}, false
+	}
+	version, err := api.svc.GetDatasetVersion(r.Context(), projectID, versionID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return auth.Identity{}, "", domain.DatasetVersion{}, false
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return auth.Identity{}, "", domain.DatasetVersion{}, false
+	}
+	if version.DatasetID != item.ID {
+		api.writeError(w, r, http.StatusNotFound, "not_found")
+		return auth.Identity{}, "", domain.DatasetVersion{}, false
+	}
+	return identity, projectID, version, true
+}
+
+// deployedModelDatasetVersion returns the latest approved version of modelID
+// and the version of datasetID it was trained on.
+func (api *datasetRegistryAPI) deployedModelDatasetVersion(ctx context.Context, projectID, modelID, datasetID string) (string, string, error) {
+	var modelVersionID, datasetVersionID string
+	err := api.db.QueryRowContext(
+		ctx,
+		`WITH deployed AS (
+			SELECT model_version_id
+			FROM model_versions
+			WHERE project_id = $1 AND model_id = $2 AND status = 'approved'
+			ORDER BY created_at DESC
+			LIMIT 1
+		 )
+		 SELECT d.model_version_id, d.dataset_version_id
+		 FROM deployed
+		 JOIN model_version_datasets d ON d.model_version_id = deployed.model_version_id AND d.project_id = $1
+		 JOIN dataset_versions v ON v.version_id = d.dataset_version_id
+		 WHERE v.dataset_id = $3
+		 ORDER BY v.created_at DESC
+		 LIMIT 1`,
+		projectID,
+		modelID,
+		datasetID,
+	).Scan(&modelVersionID, &datasetVersionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", "", repo.ErrNotFound
+	}
+	return modelVersionID, datasetVersionID, err
+}
+
+// comparableProfile returns the stored profile of a version, or nil when the
+// version was not profiled successfully.
+func (api *datasetRegistryAPI) comparableProfile(ctx context.Context, projectID, versionID string) (*dataprofile.Profile, error) {
+	record, err := api.getDatasetVersionProfile(ctx, projectID, versionID)
+	if errors.Is(err, repo.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if record.Status != profileStatusOK {
+		return nil, nil
+	}
+	return record.Profile, nil
+}
+
+func (api *datasetRegistryAPI) handleCreateDriftReport(w http.ResponseWriter, r *http.Request) {
+	identity, projectID, version, ok := api.driftVersion(w, r)
+	if !ok {
+		return
+	}
+	var req createDriftReportRequest
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
+		return
+	}
+	baselineVersionID := strings.TrimSpace(req.BaselineVersionID)
+	modelID := strings.TrimSpace(req.ModelID)
+	if (baselineVersionID == "") == (modelID == "") || baselineVersionID == version.ID {
+		api.writeError(w, r, http.StatusBadRequest, "drift_baseline_invalid")
+		return
+	}
+	threshold, ok := driftThreshold(req.Threshold)
+	if !ok {
+		api.writeError(w, r, http.StatusBadRequest, "invalid_threshold")
+		return
+	}
+
+	var baselineModelVersionID string
+	if modelID != "" {
+		var err error
+		baselineModelVersionID, baselineVersionID, err = api.deployedModelDatasetVersion(r.Context(), projectID, modelID, version.DatasetID)
+		if err != nil {
+			if errors.Is(err, repo.ErrNotFound) {
+				api.writeError(w, r, http.StatusNotFound, "baseline_not_found")
+				return
+			}
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+		if baselineVersionID == version.ID {
+			api.writeError(w, r, http.StatusBadRequest, "drift_baseline_invalid")
+			return
+		}
+	} else if _, err := api.svc.GetDatasetVersion(r.Context(), projectID, baselineVersionID); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			api.writeError(w, r, http.StatusNotFound, "baseline_not_found")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	baseline, err := api.comparableProfile(r.Context(), projectID, baselineVersionID)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	current, err := api.comparableProfile(r.Context(), projectID, version.ID)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if baseline == nil || current == nil {
+		api.writeError(w, r, http.StatusConflict, "profile_not_available")
+		return
+	}
+
+	drift := dataprofile.CompareProfiles(*baseline, *current)
+	now := time.Now().UTC()
+	report := datasetDriftReport{
+		ReportID:               uuid.NewString(),
+		DatasetID:              version.DatasetID,
+		VersionID:              version.ID,
+		BaselineVersionID:      baselineVersionID,
+		BaselineModelVersionID: baselineModelVersionID,
+		Score:                  drift.Score,
+		Threshold:              threshold,
+		Status:                 driftStatus(drift.Score, threshold),
+		Drift:                  drift,
+		CreatedAt:              now,
+		CreatedBy:              identity.Subject,
+	}
+	report.IntegritySHA256, err = integritySHA256(report)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	driftJSON, err := json.Marshal(drift)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(
+		r.Context(),
+		`INSERT INTO dataset_drift_reports (
+			report_id, project_id, dataset_id, version_id, baseline_version_id, baseline_model_version_id,
+			score, threshold, status, report, created_at, created_by, integrity_sha256
+		 ) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13)`,
+		report.ReportID,
+		projectID,
+		report.DatasetID,
+		report.VersionID,
+		report.BaselineVersionID,
+		nullString(report.BaselineModelVersionID),
+		report.Score,
+		report.Threshold,
+		report.Status,
+		driftJSON,
+		now,
+		identity.Subject,
+		report.IntegritySHA256,
+	); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	payload := map[string]any{
+		"service":             "dataset-registry",
+		"project_id":          projectID,
+		"dataset_id":          report.DatasetID,
+		"version_id":          report.VersionID,
+		"baseline_version_id": report.BaselineVersionID,
+		"score":               report.Score,
+		"threshold":           report.Threshold,
+		"status":              report.Status,
+	}
+	if report.BaselineModelVersionID != "" {
+		payload["baseline_model_version_id"] = report.BaselineModelVersionID
+	}
+	if err := auditlog.Enqueue(r.Context(), tx, auditlog.Event{
+		OccurredAt:   now,
+		Actor:        identity.Subject,
+		Action:       "dataset_version.drift_reported",
+		ResourceType: "dataset_version",
+		ResourceID:   report.VersionID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload:      payload,
+	}); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	api.writeJSON(w, http.StatusCreated, report)
+}
+
+const driftReportColumns = `report_id, dataset_id, version_id, baseline_version_id, baseline_model_version_id,
+	score, threshold, status, report, created_at, created_by, integrity_sha256`
+
+func scanDriftReport(row interface{ Scan(...any) error }) (datasetDriftReport, error) {
+	var (
+		out          datasetDriftReport
+		modelVersion sql.NullString
+		driftJSON    []byte
+	)
+	if err := row.Scan(
+		&out.ReportID,
+		&out.DatasetID,
+		&out.VersionID,
+		&out.BaselineVersionID,
+		&modelVersion,
+		&out.Score,
+		&out.Threshold,
+		&out.Status,
+		&driftJSON,
+		&out.CreatedAt,
+		&out.CreatedBy,
+		&out.IntegritySHA256,
+	); err != nil {
+		return datasetDriftReport{}, err
+	}
+	if err := json.Unmarshal(driftJSON, &out.Drift); err != nil {
+		return datasetDriftReport{}, err
+	}
+	out.BaselineModelVersionID = modelVersion.String
+	out.CreatedAt = out.CreatedAt.UTC()
+	return out, nil
+}
+
+func (api *datasetRegistryAPI) handleListDriftReports(w http.ResponseWriter, r *http.Request) {
+	_, projectID, version, ok := api.driftVersion(w, r)
+	if !ok {
+		return
+	}
+	limit := httpapi.Limit(r, 50, 200)
+	rows, err := api.db.QueryContext(
+		r.Context(),
+		`SELECT `+driftReportColumns+`
+		 FROM dataset_drift_reports
+		 WHERE project_id = $1 AND version_id = $2
+		 ORDER BY created_at DESC
+		 LIMIT $3`,
+		projectID,
+		version.ID,
+		limit,
+	)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer rows.Close()
+	reports := []datasetDriftReport{}
+	for rows.Next() {
+		report, err := scanDriftReport(rows)
+		if err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+		reports = append(reports, report)
+	}
+	if err := rows.Err(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	api.writeJSON(w, http.StatusOK, map[string]any{"reports": reports})
+}
+
+func (api *datasetRegistryAPI) handleGetDriftReport(w http.ResponseWriter, r *http.Request) {
+	_, projectID, version, ok := api.driftVersion(w, r)
+	if !ok {
+		return
+	}
+	reportID := strings.TrimSpace(r.PathValue("report_id"))
+	if reportID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "report_id_required")
+		return
+	}
+	report, err := scanDriftReport(api.db.QueryRowContext(
+		r.Context(),
+		`SELECT `+driftReportColumns+`
+		 FROM dataset_drift_reports
+		 WHERE project_id = $1 AND version_id = $2 AND report_id = $3`,
+		projectID,
+		version.ID,
+		reportID,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	api.writeJSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,24 @@
+package datasetregistry
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDriftThreshold(t *testing.T) {
+	value := func(v float64) *float64 { return &v }
+	if got, ok := driftThreshold(nil); !ok || got != defaultDriftThreshold {
+		t.Fatalf("default threshold=%v ok=%v", got, ok)
+	}
+	if got, ok := driftThreshold(value(0.1)); !ok || got != 0.1 {
+		t.Fatalf("threshold=%v ok=%v", got, ok)
+	}
+	for _, bad := range []float64{0, -1, maxDriftThreshold + 1, math.NaN()} {
+		if _, ok := driftThreshold(value(bad)); ok {
+			t.Fatalf("expected %v to be rejected", bad)
+		}
+	}
+	if driftStatus(0.2, 0.2) != driftStatusDrifted || driftStatus(0.19, 0.2) != driftStatusStable {
+		t.Fatalf("unexpected drift status at the threshold boundary")
+	}
+}
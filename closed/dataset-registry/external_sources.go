@@ -0,0 +1,900 @@
+package datasetregistry
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/domain"
+	"github.com/animus-labs/animus-go/closed/internal/integrations/eventbus"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/dataprofile"
+	"github.com/animus-labs/animus-go/closed/internal/platform/envelope"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	"github.com/animus-labs/animus-go/closed/internal/platform/lineageevent"
+	"github.com/animus-labs/animus-go/closed/internal/platform/redaction"
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+	repopg "github.com/animus-labs/animus-go/closed/internal/repo/postgres"
+	"github.com/google/uuid"
+	"github.com/minio/minio-go/v7"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	externalSourceDVC    = "dvc"
+	externalSourceGitLFS = "git-lfs"
+
+	gitLFSPointerVersion = "https://git-lfs.github.com/spec/v1"
+	gitLFSMediaType      = "application/vnd.git-lfs+json"
+	maxExternalPointer   = 64 << 10
+	maxGitLFSBatchBytes  = 1 << 20
+)
+
+var (
+	errExternalPointerInvalid = errors.New("invalid external pointer")
+	errExternalHostNotAllowed = errors.New("external host not allowed")
+	errExternalFetchFailed    = errors.New("external object fetch failed")
+)
+
+// externalSourceConfig controls where pointer ingestion may fetch from.
+// Ingestion is off while AllowedHosts is empty.
+type externalSourceConfig struct {
+	// AllowedHosts lists host names, or "*.domain" suffixes, that remotes,
+	// Git LFS download links and their redirects must resolve to.
+	AllowedHosts []string
+	Client       *http.Client
+}
+
+func (c externalSourceConfig) enabled() bool {
+	return len(c.AllowedHosts) > 0
+}
+
+func (c externalSourceConfig) allows(u *url.URL) bool {
+	if u == nil || (u.Scheme != "https" && u.Scheme != "http") || u.User != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	if host == "" {
+		return false
+	}
+	for _, allowed := range c.AllowedHosts {
+		allowed = strings.ToLower(strings.TrimSpace(allowed))
+		if suffix, ok := strings.CutPrefix(allowed, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+			continue
+		}
+		if host == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+func (c externalSourceConfig) parseURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || !c.allows(u) {
+		return nil, errExternalHostNotAllowed
+	}
+	return u, nil
+}
+
+// newExternalSourceClient returns a client that refuses redirects leaving
+// the allowed hosts.
+func newExternalSourceClient(cfg externalSourceConfig) *http.Client {
+	return &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return errors.New("stopped after 10 redirects")
+			}
+			if !cfg.allows(req.URL) {
+				return errExternalHostNotAllowed
+			}
+			return nil
+		},
+	}
+}
+
+// externalPointer is the object a DVC or Git LFS pointer names.
+type externalPointer struct {
+	Kind      string
+	DigestAlg string
+	Digest    string
+	SizeBytes int64
+	// Path is the workspace path recorded in the pointer; Git LFS pointers
+	// do not carry one.
+	Path string
+	// Legacy marks DVC 2.x outputs, stored in the remote without the
+	// files/md5 prefix.
+	Legacy bool
+}
+
+// parseGitLFSPointer parses a Git LFS pointer file: a version line followed
+// by "key value" lines, of which oid (sha256) and size are required.
+func parseGitLFSPointer(raw []byte) (externalPointer, error) {
+	pointer := externalPointer{Kind: externalSourceGitLFS, DigestAlg: "sha256", SizeBytes: -1}
+	scanner := bufio.NewScanner(bytes.NewReader(raw))
+	first := true
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(line, " ")
+		if !ok {
+			return externalPointer{}, fmt.Errorf("%w: malformed line %q", errExternalPointerInvalid, line)
+		}
+		if first {
+			if key != "version" || value != gitLFSPointerVersion {
+				return externalPointer{}, fmt.Errorf("%w: unsupported version", errExternalPointerInvalid)
+			}
+			first = false
+			continue
+		}
+		switch key {
+		case "oid":
+			digest, ok := strings.CutPrefix(value, "sha256:")
+			if !ok || !isSHA256Hex(digest) {
+				return externalPointer{}, fmt.Errorf("%w: oid must be sha256:<hex>", errExternalPointerInvalid)
+			}
+			pointer.Digest = strings.ToLower(digest)
+		case "size":
+			size, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || size < 0 {
+				return externalPointer{}, fmt.Errorf("%w: invalid size", errExternalPointerInvalid)
+			}
+			pointer.SizeBytes = size
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return externalPointer{}, fmt.Errorf("%w: %v", errExternalPointerInvalid, err)
+	}
+	if pointer.Digest == "" || pointer.SizeBytes < 0 {
+		return externalPointer{}, fmt.Errorf("%w: oid and size are required", errExternalPointerInvalid)
+	}
+	return pointer, nil
+}
+
+type dvcFile struct {
+	Outs []struct {
+		MD5  string `yaml:"md5"`
+		Size *int64 `yaml:"size"`
+		Hash string `yaml:"hash"`
+		Path string `yaml:"path"`
+	} `yaml:"outs"`
+}
+
+// parseDVCPointer parses a .dvc file and returns the output at outPath, or
+// the only output when outPath is empty. Directory outputs are rejected:
+// a dataset version is a single object.
+func parseDVCPointer(raw []byte, outPath string) (externalPointer, error) {
+	var file dvcFile
+	if err := yaml.Unmarshal(raw, &file); err != nil {
+		return externalPointer{}, fmt.Errorf("%w: %v", errExternalPointerInvalid, err)
+	}
+	outPath = strings.TrimSpace(outPath)
+	if outPath == "" && len(file.Outs) != 1 {
+		return externalPointer{}, fmt.Errorf("%w: path is required unless the file has exactly one output", errExternalPointerInvalid)
+	}
+	for _, out := range file.Outs {
+		if outPath != "" && path.Clean(out.Path) != path.Clean(outPath) {
+			continue
+		}
+		digest := strings.ToLower(strings.TrimSpace(out.MD5))
+		if strings.HasSuffix(digest, ".dir") {
+			return externalPointer{}, fmt.Errorf("%w: directory outputs are not supported", errExternalPointerInvalid)
+		}
+		if len(digest) != 32 || !isHex(digest) {
+			return externalPointer{}, fmt.Errorf("%w: md5 is required", errExternalPointerInvalid)
+		}
+		// Without a size the fetched object could not be bounded.
+		if out.Size == nil || *out.Size < 0 {
+			return externalPointer{}, fmt.Errorf("%w: size is required", errExternalPointerInvalid)
+		}
+		return externalPointer{
+			Kind:      externalSourceDVC,
+			DigestAlg: "md5",
+			Digest:    digest,
+			SizeBytes: *out.Size,
+			Path:      out.Path,
+			Legacy:    out.Hash != "md5",
+		}, nil
+	}
+	return externalPointer{}, fmt.Errorf("%w: no output %q", errExternalPointerInvalid, outPath)
+}
+
+func isHex(value string) bool {
+	_, err := hex.DecodeString(value)
+	return err == nil
+}
+
+// dvcObjectURL locates an object in a DVC HTTP remote: DVC 3 keeps outputs
+// under files/md5/, DVC 2 at the remote root.
+func dvcObjectURL(remote *url.URL, pointer externalPointer) *url.URL {
+	prefix := "files/md5/"
+	if pointer.Legacy {
+		prefix = ""
+	}
+	return remote.JoinPath(prefix+pointer.Digest[:2], pointer.Digest[2:])
+}
+
+// gitLFSEndpoint derives the LFS server URL from a repository URL the way
+// the git-lfs client does.
+func gitLFSEndpoint(remote *url.URL) *url.URL {
+	trimmed := strings.TrimSuffix(remote.Path, "/")
+	switch {
+	case strings.HasSuffix(trimmed, "/info/lfs"):
+	case strings.HasSuffix(trimmed, ".git"):
+		trimmed += "/info/lfs"
+	default:
+		trimmed += ".git/info/lfs"
+	}
+	endpoint := *remote
+	endpoint.Path = trimmed
+	endpoint.RawPath = ""
+	endpoint.RawQuery = ""
+	endpoint.Fragment = ""
+	return &endpoint
+}
+
+type gitLFSBatchObject struct {
+	OID     string `json:"oid"`
+	Size    int64  `json:"size"`
+	Actions struct {
+		Download *struct {
+			Href   string            `json:"href"`
+			Header map[string]string `json:"header"`
+		} `json:"download"`
+	} `json:"actions"`
+	Error *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// openExternalObject starts the download of the object a pointer names.
+func (c externalSourceConfig) openExternalObject(ctx context.Context, pointer externalPointer, remote *url.URL, revision string) (io.ReadCloser, error) {
+	var req *http.Request
+	switch pointer.Kind {
+	case externalSourceDVC:
+		var err error
+		if req, err = http.NewRequestWithContext(ctx, http.MethodGet, dvcObjectURL(remote, pointer).String(), nil); err != nil {
+			return nil, err
+		}
+	case externalSourceGitLFS:
+		var err error
+		if req, err = c.gitLFSDownloadRequest(ctx, pointer, remote, revision); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errExternalPointerInvalid
+	}
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errExternalFetchFailed, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("%w: %s returned %d", errExternalFetchFailed, req.URL.Host, resp.StatusCode)
+	}
+	return resp.Body, nil
+}
+
+// gitLFSDownloadRequest asks the LFS batch API for a basic-transfer download
+// link and returns the request for it.
+func (c externalSourceConfig) gitLFSDownloadRequest(ctx context.Context, pointer externalPointer, remote *url.URL, revision string) (*http.Request, error) {
+	batch := map[string]any{
+		"operation": "download",
+		"transfers": []string{"basic"},
+		"objects":   []map[string]any{{"oid": pointer.Digest, "size": pointer.SizeBytes}},
+	}
+	if strings.HasPrefix(revision, "refs/") {
+		batch["ref"] = map[string]string{"name": revision}
+	}
+	body, err := json.Marshal(batch)
+	if err != nil {
+		return nil, err
+	}
+	endpoint := gitLFSEndpoint(remote).JoinPath("objects", "batch")
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", gitLFSMediaType)
+	req.Header.Set("Content-Type", gitLFSMediaType)
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errExternalFetchFailed, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: lfs batch returned %d", errExternalFetchFailed, resp.StatusCode)
+	}
+	var out struct {
+		Objects []gitLFSBatchObject `json:"objects"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxGitLFSBatchBytes)).Decode(&out); err != nil {
+		return nil, fmt.Errorf("%w: lfs batch response: %v", errExternalFetchFailed, err)
+	}
+	for _, object := range out.Objects {
+		if object.OID != pointer.Digest {
+			continue
+		}
+		if object.Error != nil {
+			return nil, fmt.Errorf("%w: lfs object error %d: %s", errExternalFetchFailed, object.Error.Code, object.Error.Message)
+		}
+		if object.Actions.Download == nil {
+			return nil, fmt.Errorf("%w: lfs batch returned no download action", errExternalFetchFailed)
+		}
+		href, err := c.parseURL(object.Actions.Download.Href)
+		if err != nil {
+			return nil, err
+		}
+		download, err := http.NewRequestWithContext(ctx, http.MethodGet, href.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range object.Actions.Download.Header {
+			download.Header.Set(key, value)
+		}
+		return download, nil
+	}
+	return nil, fmt.Errorf("%w: lfs batch omitted the object", errExternalFetchFailed)
+}
+
+func newPointerHash(alg string) hash.Hash {
+	if alg == "md5" {
+		return md5.New()
+	}
+	return sha256.New()
+}
+
+type createExternalVersionRequest struct {
+	Kind          string         `json:"kind"`
+	Pointer       string         `json:"pointer"`
+	SourceURL     string         `json:"source_url"`
+	Revision      string         `json:"revision"`
+	RemoteURL     string         `json:"remote_url,omitempty"`
+	Path          string         `json:"path,omitempty"`
+	QualityRuleID string         `json:"quality_rule_id,omitempty"`
+	Metadata      map[string]any `json:"metadata,omitempty"`
+}
+
+// datasetVersionExternalSource records which remote object a version was
+// materialized from. Digest is the pointer's own digest (md5 for DVC,
+// sha256 for Git LFS) and was checked against the fetched bytes.
+type datasetVersionExternalSource struct {
+	SourceID  string    `json:"source_id"`
+	VersionID string    `json:"version_id"`
+	DatasetID string    `json:"dataset_id"`
+	ProjectID string    `json:"project_id"`
+	Kind      string    `json:"kind"`
+	SourceURL string    `json:"source_url"`
+	Revision  string    `json:"revision"`
+	Path      string    `json:"path,omitempty"`
+	RemoteURL string    `json:"remote_url"`
+	Pointer   string    `json:"pointer"`
+	DigestAlg string    `json:"digest_alg"`
+	Digest    string    `json:"digest"`
+	SizeBytes int64     `json:"size_bytes"`
+	CreatedAt time.Time `json:"created_at"`
+	CreatedBy string    `json:"created_by"`
+}
+
+type createExternalVersionResponse struct {
+	Version        datasetVersion               `json:"version"`
+	ExternalSource datasetVersionExternalSource `json:"external_source"`
+}
+
+func (api *datasetRegistryAPI) registerExternalSources(mux *http.ServeMux) {
+	mux.HandleFunc("POST /datasets/{dataset_id}/versions/external", api.handleCreateExternalDatasetVersion)
+	mux.HandleFunc("GET /dataset-versions/{version_id}/external-source", api.handleGetDatasetVersionExternalSource)
+}
+
+func (api *datasetRegistryAPI) handleCreateExternalDatasetVersion(w http.ResponseWriter, r *http.Request) {
+	datasetID := strings.TrimSpace(r.PathValue("dataset_id"))
+	if datasetID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "dataset_id_required")
+		return
+	}
+	if api.svc == nil || api.db == nil || api.store == nil {
+		api.writeError(w, r, http.StatusInternalServerError, "service_unavailable")
+		return
+	}
+	if !api.externalSourceCfg.enabled() {
+		api.writeError(w, r, http.StatusServiceUnavailable, "external_sources_not_configured")
+		return
+	}
+	projectID, ok := auth.ProjectIDFromContext(r.Context())
+	if !ok || strings.TrimSpace(projectID) == "" {
+		api.writeError(w, r, http.StatusBadRequest, "project_id_required")
+		return
+	}
+	identity, ok := auth.IdentityFromContext(r.Context())
+	if !ok || strings.TrimSpace(identity.Subject) == "" {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	var req createExternalVersionRequest
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
+		return
+	}
+	req.Kind = strings.TrimSpace(req.Kind)
+	req.SourceURL = strings.TrimSpace(req.SourceURL)
+	req.Revision = strings.TrimSpace(req.Revision)
+	req.RemoteURL = strings.TrimSpace(req.RemoteURL)
+	if req.SourceURL == "" || req.Revision == "" {
+		api.writeError(w, r, http.StatusBadRequest, "source_url_and_revision_required")
+		return
+	}
+	if len(req.Pointer) == 0 || len(req.Pointer) > maxExternalPointer {
+		api.writeError(w, r, http.StatusBadRequest, "invalid_external_pointer")
+		return
+	}
+
+	var (
+		pointer externalPointer
+		err     error
+	)
+	switch req.Kind {
+	case externalSourceDVC:
+		pointer, err = parseDVCPointer([]byte(req.Pointer), req.Path)
+		if err == nil && req.RemoteURL == "" {
+			api.writeError(w, r, http.StatusBadRequest, "remote_url_required")
+			return
+		}
+	case externalSourceGitLFS:
+		pointer, err = parseGitLFSPointer([]byte(req.Pointer))
+		pointer.Path = strings.TrimSpace(req.Path)
+		// The LFS server hangs off the repository itself.
+		if req.RemoteURL == "" {
+			req.RemoteURL = req.SourceURL
+		}
+	default:
+		api.writeError(w, r, http.StatusBadRequest, "external_source_kind_invalid")
+		return
+	}
+	if err != nil {
+		api.writeErrorWithDetails(w, r, http.StatusBadRequest, "invalid_external_pointer", map[string]any{"reason": err.Error()})
+		return
+	}
+	remote, err := api.externalSourceCfg.parseURL(req.RemoteURL)
+	if err != nil {
+		api.writeError(w, r, http.StatusBadRequest, "external_host_not_allowed")
+		return
+	}
+	if pointer.SizeBytes > api.uploadMaxBytes {
+		api.writeErrorWithDetails(w, r, http.StatusRequestEntityTooLarge, "upload_too_large", map[string]any{
+			"max_bytes":     api.uploadMaxBytes,
+			"max_mebibytes": api.uploadMaxBytes >> 20,
+			"size_bytes":    pointer.SizeBytes,
+		})
+		return
+	}
+
+	if _, err := api.svc.GetDataset(r.Context(), projectID, datasetID); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	settings, ok := api.activeProjectSettings(w, r, projectID)
+	if !ok {
+		return
+	}
+	lifecycle, err := api.datasetLifecycle(r.Context(), datasetID)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if lifecycle.State == domain.DatasetLifecycleArchived {
+		api.writeError(w, r, http.StatusConflict, "dataset_archived")
+		return
+	}
+	contract, err := api.activeDataContract(r.Context(), datasetID)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	qualityRuleID := strings.TrimSpace(req.QualityRuleID)
+	if qualityRuleID == "" {
+		qualityRuleID = settings.DefaultQualityRuleID
+	}
+	if qualityRuleID != "" {
+		var exists string
+		if err := api.db.QueryRowContext(r.Context(), `SELECT rule_id FROM quality_rules WHERE rule_id = $1`, qualityRuleID).Scan(&exists); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				api.writeError(w, r, http.StatusNotFound, "quality_rule_not_found")
+				return
+			}
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+	}
+
+	now := time.Now().UTC()
+	versionID := uuid.NewString()
+	filename := pointer.Digest
+	if pointer.Path != "" {
+		filename = sanitizeFilename(pointer.Path)
+	}
+	contentType := mime.TypeByExtension(path.Ext(filename))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	var (
+		encryption domain.ObjectEncryption
+		dataKey    *envelope.ObjectKey
+		head       = &headWriter{limit: dataContractHeaderBytes}
+		profiler   *dataprofile.Profiler
+	)
+	defer func() { profiler.Abort() }()
+	if contract != nil && contract.Schema.observesValues() {
+		profiler = dataprofile.New(dataprofile.DetectFormat(filename, contentType))
+	}
+	if api.encrypter.Enabled() {
+		key, err := api.encrypter.NewObjectKey(r.Context())
+		if err != nil {
+			api.writeError(w, r, http.StatusBadGateway, "encryption_key_unavailable")
+			return
+		}
+		dataKey = &key
+		encryption = domain.ObjectEncryption{Algorithm: key.Algorithm, KeyID: key.KeyID, WrappedKey: key.WrappedKey}
+	}
+
+	fetchCtx, cancel := context.WithTimeout(r.Context(), api.uploadTimeout)
+	defer cancel()
+	body, err := api.externalSourceCfg.openExternalObject(fetchCtx, pointer, remote, req.Revision)
+	if err != nil {
+		if errors.Is(err, errExternalHostNotAllowed) {
+			api.writeError(w, r, http.StatusBadRequest, "external_host_not_allowed")
+			return
+		}
+		if api.logger != nil {
+			api.logger.Warn("external object fetch failed", "dataset_id", datasetID, "kind", pointer.Kind, "error", err)
+		}
+		api.writeErrorWithDetails(w, r, http.StatusBadGateway, "external_fetch_failed", map[string]any{"reason": err.Error()})
+		return
+	}
+	defer body.Close()
+
+	// One byte past the declared size is enough to tell a longer object apart.
+	pointerHash := newPointerHash(pointer.DigestAlg)
+	content := io.TeeReader(io.LimitReader(body, pointer.SizeBytes+1), pointerHash)
+	objectKey := fmt.Sprintf("%s/%s/%s", datasetID, versionID, filename)
+	removeObject := func() {
+		_ = api.store.RemoveObject(r.Context(), api.storeCfg.BucketDatasets, objectKey, minio.RemoveObjectOptions{})
+	}
+	contentSHA256, sizeBytes, err := api.putReplicaObject(fetchCtx, objectKey, content, contentType, dataKey, head, profiler)
+	if err != nil {
+		removeObject()
+		api.writeErrorWithDetails(w, r, http.StatusBadGateway, "external_fetch_failed", map[string]any{"reason": err.Error()})
+		return
+	}
+	if sizeBytes != pointer.SizeBytes || hex.EncodeToString(pointerHash.Sum(nil)) != pointer.Digest {
+		removeObject()
+		api.writeErrorWithDetails(w, r, http.StatusUnprocessableEntity, "external_checksum_mismatch", map[string]any{
+			"digest_alg":          pointer.DigestAlg,
+			"expected_digest":     pointer.Digest,
+			"expected_size_bytes": pointer.SizeBytes,
+		})
+		return
+	}
+
+	metadataMap := req.Metadata
+	if metadataMap == nil {
+		metadataMap = map[string]any{}
+	}
+	var contractViolations []dataContractViolation
+	if contract != nil {
+		var observedProfile *dataprofile.Profile
+		if profiler != nil {
+			if profileResult, err := profiler.Finish(); err == nil {
+				observedProfile = &profileResult
+			}
+		}
+		contractViolations = evaluateDataContract(*contract, observeDatasetVersion(metadataMap, head.buf, filename, contentType, observedProfile), now)
+	}
+
+	source := datasetVersionExternalSource{
+		SourceID:  uuid.NewString(),
+		VersionID: versionID,
+		DatasetID: datasetID,
+		ProjectID: projectID,
+		Kind:      pointer.Kind,
+		SourceURL: req.SourceURL,
+		Revision:  req.Revision,
+		Path:      pointer.Path,
+		RemoteURL: remote.String(),
+		Pointer:   req.Pointer,
+		DigestAlg: pointer.DigestAlg,
+		Digest:    pointer.Digest,
+		SizeBytes: pointer.SizeBytes,
+		CreatedAt: now,
+		CreatedBy: identity.Subject,
+	}
+	metadataMap["filename"] = filename
+	metadataMap["content_type"] = contentType
+	metadataMap["content_sha256"] = contentSHA256
+	metadataMap["external_source"] = map[string]any{
+		"kind":       source.Kind,
+		"source_url": source.SourceURL,
+		"revision":   source.Revision,
+		"path":       source.Path,
+		"digest":     source.DigestAlg + ":" + source.Digest,
+	}
+	metadataMap = redaction.RedactMetadata(metadataMap)
+	metadataJSON, err := json.Marshal(metadataMap)
+	if err != nil {
+		removeObject()
+		api.writeError(w, r, http.StatusBadRequest, "invalid_metadata")
+		return
+	}
+
+	ordinal, err := api.svc.NextDatasetVersionOrdinal(r.Context(), projectID, datasetID)
+	if err != nil {
+		removeObject()
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		removeObject()
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	txSvc := api.svc.withRepositories(repopg.NewDatasetStore(tx), repopg.NewAuditAppender(tx, nil))
+	version, err := txSvc.CreateDatasetVersion(r.Context(), domain.DatasetVersion{
+		ID:            versionID,
+		ProjectID:     projectID,
+		DatasetID:     datasetID,
+		QualityRuleID: qualityRuleID,
+		Ordinal:       ordinal,
+		ContentSHA256: contentSHA256,
+		ObjectKey:     objectKey,
+		SizeBytes:     sizeBytes,
+		Encryption:    encryption,
+	}, metadataMap, buildAuditContext(r, identity))
+	if err != nil {
+		removeObject()
+		if isUniqueViolation(err) {
+			api.writeError(w, r, http.StatusConflict, "duplicate_content")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if err := insertDatasetVersionExternalSource(r.Context(), tx, source); err != nil {
+		removeObject()
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if err := enqueueExternalSourceLineage(r.Context(), tx, r.Header.Get("X-Request-Id"), identity.Subject, version, source, now); err != nil {
+		removeObject()
+		api.writeError(w, r, http.StatusInternalServerError, "lineage_write_failed")
+		return
+	}
+	if err := auditlog.Enqueue(r.Context(), tx, auditlog.Event{
+		OccurredAt:   now,
+		Actor:        identity.Subject,
+		Action:       "dataset_version.external_ingest",
+		ResourceType: "dataset_version",
+		ResourceID:   version.ID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload: map[string]any{
+			"service":            "dataset-registry",
+			"project_id":         projectID,
+			"dataset_id":         datasetID,
+			"dataset_version_id": version.ID,
+			"source_id":          source.SourceID,
+			"kind":               source.Kind,
+			"source_url":         source.SourceURL,
+			"revision":           source.Revision,
+			"remote_url":         source.RemoteURL,
+			"digest":             source.DigestAlg + ":" + source.Digest,
+			"content_sha256":     version.ContentSHA256,
+		},
+	}); err != nil {
+		removeObject()
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	err = api.bus.Enqueue(r.Context(), tx, eventbus.EventDatasetVersionCreated, version.ID, projectID, now,
+		map[string]string{"dataset_id": datasetID, "dataset_version_id": version.ID},
+		map[string]any{
+			"ordinal":        version.Ordinal,
+			"content_sha256": version.ContentSHA256,
+			"size_bytes":     version.SizeBytes,
+		},
+	)
+	if err != nil {
+		removeObject()
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		removeObject()
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	var evaluation *dataContractEvaluation
+	if contract != nil {
+		recorded, err := api.recordDataContractEvaluation(r.Context(), r, *contract, version, contractViolations, now)
+		if err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "data_contract_evaluation_failed")
+			return
+		}
+		evaluation = &recorded
+	}
+
+	w.Header().Set("Location", "/dataset-versions/"+version.ID)
+	api.writeJSON(w, http.StatusCreated, createExternalVersionResponse{
+		Version: datasetVersion{
+			VersionID:              version.ID,
+			DatasetID:              version.DatasetID,
+			ProjectID:              version.ProjectID,
+			QualityRuleID:          version.QualityRuleID,
+			Ordinal:                version.Ordinal,
+			ContentSHA256:          version.ContentSHA256,
+			ObjectKey:              version.ObjectKey,
+			SizeBytes:              version.SizeBytes,
+			Metadata:               metadataJSON,
+			CreatedAt:              version.CreatedAt,
+			CreatedBy:              version.CreatedBy,
+			DataContractEvaluation: evaluation,
+			Encryption:             objectEncryptionResponse(version.Encryption),
+		},
+		ExternalSource: source,
+	})
+}
+
+func insertDatasetVersionExternalSource(ctx context.Context, tx *sql.Tx, source datasetVersionExternalSource) error {
+	integrity, err := integritySHA256(struct {
+		SourceID  string    `json:"source_id"`
+		VersionID string    `json:"version_id"`
+		Kind      string    `json:"kind"`
+		SourceURL string    `json:"source_url"`
+		Revision  string    `json:"revision"`
+		Path      string    `json:"path"`
+		RemoteURL string    `json:"remote_url"`
+		DigestAlg string    `json:"digest_alg"`
+		Digest    string    `json:"digest"`
+		SizeBytes int64     `json:"size_bytes"`
+		CreatedAt time.Time `json:"created_at"`
+		CreatedBy string    `json:"created_by"`
+	}{source.SourceID, source.VersionID, source.Kind, source.SourceURL, source.Revision, source.Path, source.RemoteURL, source.DigestAlg, source.Digest, source.SizeBytes, source.CreatedAt, source.CreatedBy})
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(
+		ctx,
+		`INSERT INTO dataset_version_external_sources (source_id, version_id, dataset_id, project_id, kind, source_url, revision, path, remote_url, pointer, digest_alg, digest, size_bytes, created_at, created_by, integrity_sha256)
+		 VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16)`,
+		source.SourceID,
+		source.VersionID,
+		source.DatasetID,
+		source.ProjectID,
+		source.Kind,
+		source.SourceURL,
+		source.Revision,
+		source.Path,
+		source.RemoteURL,
+		source.Pointer,
+		source.DigestAlg,
+		source.Digest,
+		source.SizeBytes,
+		source.CreatedAt,
+		source.CreatedBy,
+		integrity,
+	)
+	return err
+}
+
+// enqueueExternalSourceLineage records the version under its dataset and
+// links it to a node for the remote object, keyed by its pointer digest.
+func enqueueExternalSourceLineage(ctx context.Context, tx *sql.Tx, requestID, actor string, version domain.DatasetVersion, source datasetVersionExternalSource, now time.Time) error {
+	err := lineageevent.Enqueue(ctx, tx, lineageevent.Event{
+		OccurredAt:  now,
+		Actor:       actor,
+		RequestID:   requestID,
+		SubjectType: "dataset",
+		SubjectID:   version.DatasetID,
+		Predicate:   "has_version",
+		ObjectType:  "dataset_version",
+		ObjectID:    version.ID,
+		Metadata: map[string]any{
+			"ordinal":         version.Ordinal,
+			"content_sha256":  version.ContentSHA256,
+			"quality_rule_id": version.QualityRuleID,
+			"size_bytes":      version.SizeBytes,
+			"object_key":      version.ObjectKey,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return lineageevent.Enqueue(ctx, tx, lineageevent.Event{
+		OccurredAt:  now,
+		Actor:       actor,
+		RequestID:   requestID,
+		SubjectType: "dataset_version",
+		SubjectID:   version.ID,
+		Predicate:   "materialized_from",
+		ObjectType:  "external_object",
+		ObjectID:    source.DigestAlg + ":" + source.Digest,
+		Metadata: map[string]any{
+			"kind":       source.Kind,
+			"source_url": source.SourceURL,
+			"revision":   source.Revision,
+			"path":       source.Path,
+			"remote_url": source.RemoteURL,
+		},
+	})
+}
+
+func (api *datasetRegistryAPI) handleGetDatasetVersionExternalSource(w http.ResponseWriter, r *http.Request) {
+	versionID := strings.TrimSpace(r.PathValue("version_id"))
+	if versionID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "version_id_required")
+		return
+	}
+	if api.db == nil {
+		api.writeError(w, r, http.StatusInternalServerError, "service_unavailable")
+		return
+	}
+	projectID, ok := auth.ProjectIDFromContext(r.Context())
+	if !ok || strings.TrimSpace(projectID) == "" {
+		api.writeError(w, r, http.StatusBadRequest, "project_id_required")
+		return
+	}
+
+	var source datasetVersionExternalSource
+	err := api.db.QueryRowContext(
+		r.Context(),
+		`SELECT source_id, version_id, dataset_id, project_id, kind, source_url, revision, path, remote_url, pointer, digest_alg, digest, size_bytes, created_at, created_by
+		 FROM dataset_version_external_sources
+		 WHERE version_id = $1 AND project_id = $2`,
+		versionID,
+		projectID,
+	).Scan(&source.SourceID, &source.VersionID, &source.DatasetID, &source.ProjectID, &source.Kind, &source.SourceURL, &source.Revision, &source.Path, &source.RemoteURL, &source.Pointer, &source.DigestAlg, &source.Digest, &source.SizeBytes, &source.CreatedAt, &source.CreatedBy)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	source.CreatedAt = source.CreatedAt.UTC()
+	api.writeJSON(w, http.StatusOK, source)
+}
@@ -0,0 +1,610 @@
+package datasetregistry
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/integrations/webhooks"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	repopg "github.com/animus-labs/animus-go/closed/internal/repo/postgres"
+	"github.com/google/uuid"
+)
+
+const (
+	maxFreshnessAgeDays = 3650
+
+	freshnessMonitorActor = "system:freshness-monitor"
+)
+
+// datasetFreshness is the freshness state of a dataset with an expectation.
+// Staleness is computed at read time, so it does not lag behind the monitor.
+type datasetFreshness struct {
+	MaxAgeDays    int        `json:"max_age_days"`
+	LastVersionID string     `json:"last_version_id,omitempty"`
+	LastVersionAt *time.Time `json:"last_version_at,omitempty"`
+	DueAt         time.Time  `json:"due_at"`
+	Stale         bool       `json:"stale"`
+	BreachID      string     `json:"breach_id,omitempty"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	UpdatedBy     string     `json:"updated_by"`
+}
+
+type datasetFreshnessBreach struct {
+	BreachID          string     `json:"breach_id"`
+	DatasetID         string     `json:"dataset_id"`
+	MaxAgeDays        int        `json:"max_age_days"`
+	LastVersionID     string     `json:"last_version_id,omitempty"`
+	LastVersionAt     *time.Time `json:"last_version_at,omitempty"`
+	DetectedAt        time.Time  `json:"detected_at"`
+	ResolvedAt        *time.Time `json:"resolved_at,omitempty"`
+	ResolvedVersionID string     `json:"resolved_version_id,omitempty"`
+}
+
+type setDatasetFreshnessRequest struct {
+	MaxAgeDays int `json:"max_age_days"`
+}
+
+// freshnessRow joins an expectation with the latest version and open breach
+// of its dataset.
+type freshnessRow struct {
+	DatasetID        string
+	ProjectID        string
+	MaxAgeDays       int
+	UpdatedAt        time.Time
+	UpdatedBy        string
+	DatasetCreatedAt time.Time
+	LastVersionID    string
+	LastVersionAt    *time.Time
+	OpenBreachID     string
+}
+
+const freshnessRowsQuery = `SELECT e.dataset_id,
+		e.project_id,
+		e.max_age_days,
+		e.updated_at,
+		e.updated_by,
+		d.created_at,
+		lv.version_id,
+		lv.created_at,
+		b.breach_id
+	 FROM dataset_freshness_expectations e
+	 JOIN datasets d ON d.dataset_id = e.dataset_id
+	 LEFT JOIN LATERAL (
+		SELECT version_id, created_at
+		FROM dataset_versions
+		WHERE dataset_id = e.dataset_id
+		ORDER BY created_at DESC, ordinal DESC
+		LIMIT 1
+	 ) lv ON true
+	 LEFT JOIN dataset_freshness_breaches b ON b.dataset_id = e.dataset_id AND b.resolved_at IS NULL
+	 WHERE ($1 = '' OR e.project_id = $1)
+	   AND ($2 = '' OR e.dataset_id = $2)
+	 ORDER BY e.dataset_id`
+
+func (api *datasetRegistryAPI) registerFreshness(mux *http.ServeMux) {
+	mux.HandleFunc("GET /datasets/{dataset_id}/freshness", api.handleGetDatasetFreshness)
+	mux.HandleFunc("PUT /datasets/{dataset_id}/freshness", api.handleSetDatasetFreshness)
+	mux.HandleFunc("DELETE /datasets/{dataset_id}/freshness", api.handleDeleteDatasetFreshness)
+	mux.HandleFunc("GET /datasets/{dataset_id}/freshness/breaches", api.handleListDatasetFreshnessBreaches)
+}
+
+// evaluateFreshness measures age from the latest version, or from the dataset
+// itself when nothing has been uploaded yet.
+func evaluateFreshness(row freshnessRow, now time.Time) datasetFreshness {
+	reference := row.DatasetCreatedAt
+	if row.LastVersionAt != nil {
+		reference = *row.LastVersionAt
+	}
+	dueAt := reference.UTC().Add(time.Duration(row.MaxAgeDays) * 24 * time.Hour)
+	return datasetFreshness{
+		MaxAgeDays:    row.MaxAgeDays,
+		LastVersionID: row.LastVersionID,
+		LastVersionAt: row.LastVersionAt,
+		DueAt:         dueAt,
+		Stale:         now.After(dueAt),
+		BreachID:      row.OpenBreachID,
+		UpdatedAt:     row.UpdatedAt,
+		UpdatedBy:     row.UpdatedBy,
+	}
+}
+
+func (api *datasetRegistryAPI) freshnessRows(ctx context.Context, projectID, datasetID string) ([]freshnessRow, error) {
+	rows, err := api.db.QueryContext(ctx, freshnessRowsQuery, strings.TrimSpace(projectID), strings.TrimSpace(datasetID))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []freshnessRow{}
+	for rows.Next() {
+		var (
+			row           freshnessRow
+			lastVersionID sql.NullString
+			lastVersionAt sql.NullTime
+			openBreachID  sql.NullString
+		)
+		if err := rows.Scan(
+			&row.DatasetID,
+			&row.ProjectID,
+			&row.MaxAgeDays,
+			&row.UpdatedAt,
+			&row.UpdatedBy,
+			&row.DatasetCreatedAt,
+			&lastVersionID,
+			&lastVersionAt,
+			&openBreachID,
+		); err != nil {
+			return nil, err
+		}
+		row.UpdatedAt = row.UpdatedAt.UTC()
+		row.DatasetCreatedAt = row.DatasetCreatedAt.UTC()
+		row.LastVersionID = strings.TrimSpace(lastVersionID.String)
+		if lastVersionAt.Valid {
+			t := lastVersionAt.Time.UTC()
+			row.LastVersionAt = &t
+		}
+		row.OpenBreachID = strings.TrimSpace(openBreachID.String)
+		out = append(out, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// projectFreshness maps dataset IDs of the project to their freshness state.
+func (api *datasetRegistryAPI) projectFreshness(ctx context.Context, projectID string, now time.Time) (map[string]datasetFreshness, error) {
+	rows, err := api.freshnessRows(ctx, projectID, "")
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]datasetFreshness, len(rows))
+	for _, row := range rows {
+		out[row.DatasetID] = evaluateFreshness(row, now)
+	}
+	return out, nil
+}
+
+func (api *datasetRegistryAPI) datasetFreshness(ctx context.Context, projectID, datasetID string, now time.Time) (*datasetFreshness, error) {
+	rows, err := api.freshnessRows(ctx, projectID, datasetID)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	freshness := evaluateFreshness(rows[0], now)
+	return &freshness, nil
+}
+
+func (api *datasetRegistryAPI) handleGetDatasetFreshness(w http.ResponseWriter, r *http.Request) {
+	_, projectID, item, ok := api.dataContractDataset(w, r)
+	if !ok {
+		return
+	}
+	freshness, err := api.datasetFreshness(r.Context(), projectID, item.ID, time.Now().UTC())
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if freshness == nil {
+		api.writeError(w, r, http.StatusNotFound, "freshness_not_configured")
+		return
+	}
+	api.writeJSON(w, http.StatusOK, freshness)
+}
+
+func (api *datasetRegistryAPI) handleSetDatasetFreshness(w http.ResponseWriter, r *http.Request) {
+	identity, projectID, item, ok := api.dataContractDataset(w, r)
+	if !ok {
+		return
+	}
+	var req setDatasetFreshnessRequest
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
+		return
+	}
+	if req.MaxAgeDays <= 0 || req.MaxAgeDays > maxFreshnessAgeDays {
+		api.writeError(w, r, http.StatusBadRequest, "max_age_days_invalid")
+		return
+	}
+
+	now := time.Now().UTC()
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	_, err = tx.ExecContext(
+		r.Context(),
+		`INSERT INTO dataset_freshness_expectations (dataset_id, project_id, max_age_days, updated_at, updated_by)
+		 VALUES ($1,$2,$3,$4,$5)
+		 ON CONFLICT (dataset_id) DO UPDATE
+		 SET max_age_days = EXCLUDED.max_age_days,
+			 updated_at = EXCLUDED.updated_at,
+			 updated_by = EXCLUDED.updated_by`,
+		item.ID,
+		projectID,
+		req.MaxAgeDays,
+		now,
+		identity.Subject,
+	)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if err := auditlog.Enqueue(r.Context(), tx, auditlog.Event{
+		OccurredAt:   now,
+		Actor:        identity.Subject,
+		Action:       "dataset.freshness_expectation_set",
+		ResourceType: "dataset",
+		ResourceID:   item.ID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload: map[string]any{
+			"service":      "dataset-registry",
+			"project_id":   projectID,
+			"dataset_id":   item.ID,
+			"max_age_days": req.MaxAgeDays,
+		},
+	}); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	freshness, err := api.datasetFreshness(r.Context(), projectID, item.ID, now)
+	if err != nil || freshness == nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	api.writeJSON(w, http.StatusOK, freshness)
+}
+
+func (api *datasetRegistryAPI) handleDeleteDatasetFreshness(w http.ResponseWriter, r *http.Request) {
+	identity, projectID, item, ok := api.dataContractDataset(w, r)
+	if !ok {
+		return
+	}
+
+	now := time.Now().UTC()
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.ExecContext(r.Context(), `DELETE FROM dataset_freshness_expectations WHERE dataset_id = $1`, item.ID)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		api.writeError(w, r, http.StatusNotFound, "freshness_not_configured")
+		return
+	}
+	// Without an expectation there is nothing left to breach.
+	if _, err := tx.ExecContext(
+		r.Context(),
+		`UPDATE dataset_freshness_breaches SET resolved_at = $2 WHERE dataset_id = $1 AND resolved_at IS NULL`,
+		item.ID,
+		now,
+	); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if err := auditlog.Enqueue(r.Context(), tx, auditlog.Event{
+		OccurredAt:   now,
+		Actor:        identity.Subject,
+		Action:       "dataset.freshness_expectation_deleted",
+		ResourceType: "dataset",
+		ResourceID:   item.ID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload: map[string]any{
+			"service":    "dataset-registry",
+			"project_id": projectID,
+			"dataset_id": item.ID,
+		},
+	}); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (api *datasetRegistryAPI) handleListDatasetFreshnessBreaches(w http.ResponseWriter, r *http.Request) {
+	_, _, item, ok := api.dataContractDataset(w, r)
+	if !ok {
+		return
+	}
+	limit := httpapi.Limit(r, 100, 500)
+	rows, err := api.db.QueryContext(
+		r.Context(),
+		`SELECT breach_id, dataset_id, max_age_days, last_version_id, last_version_at, detected_at, resolved_at, resolved_version_id
+		 FROM dataset_freshness_breaches
+		 WHERE dataset_id = $1
+		 ORDER BY detected_at DESC
+		 LIMIT $2`,
+		item.ID,
+		limit,
+	)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer rows.Close()
+
+	out := []datasetFreshnessBreach{}
+	for rows.Next() {
+		var (
+			breach            datasetFreshnessBreach
+			lastVersionID     sql.NullString
+			lastVersionAt     sql.NullTime
+			resolvedAt        sql.NullTime
+			resolvedVersionID sql.NullString
+		)
+		if err := rows.Scan(
+			&breach.BreachID,
+			&breach.DatasetID,
+			&breach.MaxAgeDays,
+			&lastVersionID,
+			&lastVersionAt,
+			&breach.DetectedAt,
+			&resolvedAt,
+			&resolvedVersionID,
+		); err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+		breach.DetectedAt = breach.DetectedAt.UTC()
+		breach.LastVersionID = strings.TrimSpace(lastVersionID.String)
+		if lastVersionAt.Valid {
+			t := lastVersionAt.Time.UTC()
+			breach.LastVersionAt = &t
+		}
+		if resolvedAt.Valid {
+			t := resolvedAt.Time.UTC()
+			breach.ResolvedAt = &t
+		}
+		breach.ResolvedVersionID = strings.TrimSpace(resolvedVersionID.String)
+		out = append(out, breach)
+	}
+	if err := rows.Err(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	api.writeJSON(w, http.StatusOK, map[string]any{"breaches": out})
+}
+
+func startFreshnessMonitor(ctx context.Context, logger *slog.Logger, api *datasetRegistryAPI, interval time.Duration) {
+	if api == nil || api.db == nil {
+		return
+	}
+	if interval <= 0 {
+		interval = 15 * time.Minute
+	}
+
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := api.checkDatasetFreshness(ctx, time.Now().UTC()); err != nil && logger != nil {
+					logger.Warn("dataset freshness check failed", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// checkDatasetFreshness opens a breach for every dataset that went stale and
+// resolves the open breach of every dataset that is fresh again.
+func (api *datasetRegistryAPI) checkDatasetFreshness(ctx context.Context, now time.Time) error {
+	rows, err := api.freshnessRows(ctx, "", "")
+	if err != nil {
+		return err
+	}
+	var lastErr error
+	for _, row := range rows {
+		freshness := evaluateFreshness(row, now)
+		switch {
+		case freshness.Stale && row.OpenBreachID == "":
+			lastErr = errors.Join(lastErr, api.openFreshnessBreach(ctx, row, freshness, now))
+		case !freshness.Stale && row.OpenBreachID != "":
+			lastErr = errors.Join(lastErr, api.resolveFreshnessBreach(ctx, row, now))
+		}
+	}
+	return lastErr
+}
+
+func (api *datasetRegistryAPI) openFreshnessBreach(ctx context.Context, row freshnessRow, freshness datasetFreshness, now time.Time) error {
+	breachID := uuid.NewString()
+	tx, err := api.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// The partial unique index keeps a single open breach per dataset even if
+	// several registry replicas run the monitor.
+	res, err := tx.ExecContext(
+		ctx,
+		`INSERT INTO dataset_freshness_breaches (
+			breach_id,
+			project_id,
+			dataset_id,
+			max_age_days,
+			last_version_id,
+			last_version_at,
+			detected_at
+		) VALUES ($1,$2,$3,$4,$5,$6,$7)
+		ON CONFLICT DO NOTHING`,
+		breachID,
+		row.ProjectID,
+		row.DatasetID,
+		row.MaxAgeDays,
+		nullString(row.LastVersionID),
+		row.LastVersionAt,
+		now,
+	)
+	if err != nil {
+		return err
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return nil
+	}
+	payload := map[string]any{
+		"service":      "dataset-registry",
+		"project_id":   row.ProjectID,
+		"dataset_id":   row.DatasetID,
+		"breach_id":    breachID,
+		"max_age_days": row.MaxAgeDays,
+		"due_at":       freshness.DueAt.Format(time.RFC3339Nano),
+	}
+	if row.LastVersionID != "" {
+		payload["last_version_id"] = row.LastVersionID
+	}
+	if err := auditlog.Enqueue(ctx, tx, auditlog.Event{
+		OccurredAt:   now,
+		Actor:        freshnessMonitorActor,
+		Action:       "dataset.freshness_breach",
+		ResourceType: "dataset",
+		ResourceID:   row.DatasetID,
+		Payload:      payload,
+	}); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	return api.notifyDatasetStale(ctx, row, breachID, now)
+}
+
+func (api *datasetRegistryAPI) resolveFreshnessBreach(ctx context.Context, row freshnessRow, now time.Time) error {
+	tx, err := api.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.ExecContext(
+		ctx,
+		`UPDATE dataset_freshness_breaches
+		 SET resolved_at = $2, resolved_version_id = $3
+		 WHERE breach_id = $1 AND resolved_at IS NULL`,
+		row.OpenBreachID,
+		now,
+		nullString(row.LastVersionID),
+	)
+	if err != nil {
+		return err
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return nil
+	}
+	payload := map[string]any{
+		"service":    "dataset-registry",
+		"project_id": row.ProjectID,
+		"dataset_id": row.DatasetID,
+		"breach_id":  row.OpenBreachID,
+	}
+	if row.LastVersionID != "" {
+		payload["resolved_version_id"] = row.LastVersionID
+	}
+	if err := auditlog.Enqueue(ctx, tx, auditlog.Event{
+		OccurredAt:   now,
+		Actor:        freshnessMonitorActor,
+		Action:       "dataset.freshness_restored",
+		ResourceType: "dataset",
+		ResourceID:   row.DatasetID,
+		Payload:      payload,
+	}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// notifyDatasetStale enqueues DatasetStale for every enabled project
+// subscription that asked for it.
+func (api *datasetRegistryAPI) notifyDatasetStale(ctx context.Context, row freshnessRow, breachID string, emittedAt time.Time) error {
+	if !api.webhookCfg.Enabled() {
+		return nil
+	}
+	payload, err := webhooks.DatasetStalePayload(row.ProjectID, row.DatasetID, breachID, emittedAt)
+	if err != nil {
+		return err
+	}
+	payloadJSON, err := webhooks.PayloadJSON(payload)
+	if err != nil {
+		return err
+	}
+	subStore := repopg.NewWebhookSubscriptionStore(api.db)
+	deliveryStore := repopg.NewWebhookDeliveryStore(api.db)
+	if subStore == nil || deliveryStore == nil {
+		return errors.New("webhook store unavailable")
+	}
+	subs, err := subStore.ListEnabledByEvent(ctx, row.ProjectID, webhooks.EventDatasetStale)
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	for _, sub := range subs {
+		now := time.Now().UTC()
+		record, inserted, err := deliveryStore.Enqueue(ctx, webhooks.Delivery{
+			ID:             uuid.NewString(),
+			ProjectID:      row.ProjectID,
+			SubscriptionID: sub.ID,
+			EventID:        payload.EventID,
+			EventType:      payload.EventType,
+			Payload:        payloadJSON,
+			Status:         webhooks.DeliveryStatusPending,
+			NextAttemptAt:  now,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		})
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if inserted {
+			_ = auditlog.Enqueue(ctx, api.db, auditlog.Event{
+				OccurredAt:   now,
+				Actor:        freshnessMonitorActor,
+				Action:       "webhook.delivery.enqueued",
+				ResourceType: "webhook_delivery",
+				ResourceID:   record.ID,
+				Payload: map[string]any{
+					"service":         "dataset-registry",
+					"project_id":      row.ProjectID,
+					"subscription_id": record.SubscriptionID,
+					"event_id":        record.EventID,
+					"event_type":      record.EventType.String(),
+				},
+			})
+		}
+	}
+	return lastErr
+}
@@ -0,0 +1,43 @@
+package datasetregistry
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEvaluateFreshness(t *testing.T) {
+	now := time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC)
+	lastVersionAt := now.Add(-3 * 24 * time.Hour)
+	row := freshnessRow{
+		DatasetID:        "ds-1",
+		MaxAgeDays:       7,
+		DatasetCreatedAt: now.Add(-90 * 24 * time.Hour),
+		LastVersionID:    "dv-1",
+		LastVersionAt:    &lastVersionAt,
+	}
+
+	fresh := evaluateFreshness(row, now)
+	if fresh.Stale {
+		t.Fatalf("expected fresh dataset")
+	}
+	if want := lastVersionAt.Add(7 * 24 * time.Hour); !fresh.DueAt.Equal(want) {
+		t.Fatalf("DueAt=%s, want %s", fresh.DueAt, want)
+	}
+
+	row.MaxAgeDays = 2
+	if !evaluateFreshness(row, now).Stale {
+		t.Fatalf("expected stale dataset")
+	}
+
+	// Without versions, age is measured from the dataset itself.
+	row.MaxAgeDays = 30
+	row.LastVersionID = ""
+	row.LastVersionAt = nil
+	empty := evaluateFreshness(row, now)
+	if !empty.Stale {
+		t.Fatalf("expected stale dataset without versions")
+	}
+	if empty.LastVersionAt != nil {
+		t.Fatalf("unexpected last_version_at")
+	}
+}
@@ -1,4 +1,4 @@
-package main
+package datasetregistry
 
 import (
 	"crypto/sha256"
@@ -0,0 +1,231 @@
+package datasetregistry
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/domain"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	"github.com/animus-labs/animus-go/closed/internal/platform/objectstore"
+	repopg "github.com/animus-labs/animus-go/closed/internal/repo/postgres"
+)
+
+const maxLifecycleReasonLen = 1024
+
+type datasetLifecycle struct {
+	State     string     `json:"state"`
+	Reason    string     `json:"reason,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+	UpdatedBy string     `json:"updated_by,omitempty"`
+}
+
+type setDatasetLifecycleRequest struct {
+	State  string `json:"state"`
+	Reason string `json:"reason,omitempty"`
+}
+
+func (api *datasetRegistryAPI) registerLifecycle(mux *http.ServeMux) {
+	mux.HandleFunc("GET /datasets/{dataset_id}/lifecycle", api.handleGetDatasetLifecycle)
+	mux.HandleFunc("PUT /datasets/{dataset_id}/lifecycle", api.handleSetDatasetLifecycle)
+}
+
+func datasetLifecycleResponse(value domain.DatasetLifecycle) datasetLifecycle {
+	return datasetLifecycle{
+		State:     string(value.State),
+		Reason:    value.Reason,
+		UpdatedAt: value.UpdatedAt,
+		UpdatedBy: value.UpdatedBy,
+	}
+}
+
+func (api *datasetRegistryAPI) datasetLifecycle(ctx context.Context, datasetID string) (domain.DatasetLifecycle, error) {
+	return repopg.NewDatasetLifecycleStore(api.db).Get(ctx, datasetID)
+}
+
+// projectLifecycleStates maps dataset IDs of the project to their state;
+// datasets missing from the map are active.
+func (api *datasetRegistryAPI) projectLifecycleStates(ctx context.Context, projectID string) (map[string]domain.DatasetLifecycleState, error) {
+	rows, err := api.db.QueryContext(ctx, `SELECT dataset_id, state FROM dataset_lifecycle WHERE project_id = $1`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := map[string]domain.DatasetLifecycleState{}
+	for rows.Next() {
+		var datasetID, state string
+		if err := rows.Scan(&datasetID, &state); err != nil {
+			return nil, err
+		}
+		out[datasetID] = domain.DatasetLifecycleState(state)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// retagDatasetObjects moves the dataset's objects into or out of the archive
+// tier by toggling the tag the bucket lifecycle rule filters on. Tagging is
+// idempotent, so a failed transition can simply be retried.
+func (api *datasetRegistryAPI) retagDatasetObjects(ctx context.Context, datasetID string, archived bool) (int, error) {
+	rows, err := api.db.QueryContext(ctx, `SELECT object_key FROM dataset_versions WHERE dataset_id = $1 ORDER BY ordinal`, datasetID)
+	if err != nil {
+		return 0, err
+	}
+	keys := []string{}
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			_ = rows.Close()
+			return 0, err
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		_ = rows.Close()
+		return 0, err
+	}
+	_ = rows.Close()
+
+	for _, key := range keys {
+		// Referenced objects belong to another team's bucket and keep its tiering.
+		if isReferenceObjectKey(key) {
+			continue
+		}
+		if err := objectstore.SetArchivedTag(ctx, api.store, api.storeCfg.BucketDatasets, key, archived); err != nil {
+			return 0, err
+		}
+	}
+	return len(keys), nil
+}
+
+func (api *datasetRegistryAPI) handleGetDatasetLifecycle(w http.ResponseWriter, r *http.Request) {
+	_, _, item, ok := api.dataContractDataset(w, r)
+	if !ok {
+		return
+	}
+	lifecycle, err := api.datasetLifecycle(r.Context(), item.ID)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	api.writeJSON(w, http.StatusOK, datasetLifecycleResponse(lifecycle))
+}
+
+// handleSetDatasetLifecycle transitions a dataset between active, deprecated
+// and archived. Archiving tags every version object for the archive tier;
+// leaving archived removes the tag again.
+func (api *datasetRegistryAPI) handleSetDatasetLifecycle(w http.ResponseWriter, r *http.Request) {
+	identity, projectID, item, ok := api.dataContractDataset(w, r)
+	if !ok {
+		return
+	}
+	var req setDatasetLifecycleRequest
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
+		return
+	}
+	state := domain.DatasetLifecycleState(strings.ToLower(strings.TrimSpace(req.State)))
+	if !state.Valid() {
+		api.writeError(w, r, http.StatusBadRequest, "invalid_state")
+		return
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" && state != domain.DatasetLifecycleActive {
+		api.writeError(w, r, http.StatusBadRequest, "reason_required")
+		return
+	}
+	if len(reason) > maxLifecycleReasonLen {
+		api.writeError(w, r, http.StatusBadRequest, "reason_too_long")
+		return
+	}
+	if api.store == nil {
+		api.writeError(w, r, http.StatusInternalServerError, "service_unavailable")
+		return
+	}
+
+	now := time.Now().UTC()
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	current, err := repopg.NewDatasetLifecycleStore(tx).Get(r.Context(), item.ID)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if current.State == state {
+		api.writeError(w, r, http.StatusConflict, "lifecycle_unchanged")
+		return
+	}
+
+	_, err = tx.ExecContext(
+		r.Context(),
+		`INSERT INTO dataset_lifecycle (dataset_id, project_id, state, reason, updated_at, updated_by)
+		 VALUES ($1,$2,$3,$4,$5,$6)
+		 ON CONFLICT (dataset_id) DO UPDATE
+		 SET state = EXCLUDED.state,
+			 reason = EXCLUDED.reason,
+			 updated_at = EXCLUDED.updated_at,
+			 updated_by = EXCLUDED.updated_by`,
+		item.ID,
+		projectID,
+		string(state),
+		nullString(reason),
+		now,
+		identity.Subject,
+	)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	payload := map[string]any{
+		"service":    "dataset-registry",
+		"project_id": projectID,
+		"dataset_id": item.ID,
+		"from":       string(current.State),
+		"to":         string(state),
+		"reason":     reason,
+	}
+	if state == domain.DatasetLifecycleArchived || current.State == domain.DatasetLifecycleArchived {
+		tagged, err := api.retagDatasetObjects(r.Context(), item.ID, state == domain.DatasetLifecycleArchived)
+		if err != nil {
+			api.writeError(w, r, http.StatusBadGateway, "object_store_error")
+			return
+		}
+		payload["objects_retagged"] = tagged
+	}
+	if err := auditlog.Enqueue(r.Context(), tx, auditlog.Event{
+		OccurredAt:   now,
+		Actor:        identity.Subject,
+		Action:       "dataset.lifecycle_transition",
+		ResourceType: "dataset",
+		ResourceID:   item.ID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload:      payload,
+	}); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	api.writeJSON(w, http.StatusOK, datasetLifecycle{
+		State:     string(state),
+		Reason:    reason,
+		UpdatedAt: &now,
+		UpdatedBy: identity.Subject,
+	})
+}
@@ -1,28 +1,40 @@
-package main
+package datasetregistry
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/animus-labs/animus-go/closed/internal/integrations/eventbus"
+	"github.com/animus-labs/animus-go/closed/internal/integrations/webhooks"
 	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
 	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
 	"github.com/animus-labs/animus-go/closed/internal/platform/env"
+	"github.com/animus-labs/animus-go/closed/internal/platform/envelope"
 	"github.com/animus-labs/animus-go/closed/internal/platform/httpserver"
+	"github.com/animus-labs/animus-go/closed/internal/platform/inproc"
+	"github.com/animus-labs/animus-go/closed/internal/platform/migrate"
 	"github.com/animus-labs/animus-go/closed/internal/platform/objectstore"
+	"github.com/animus-labs/animus-go/closed/internal/platform/outbox"
+	"github.com/animus-labs/animus-go/closed/internal/platform/outbox/servicerelay"
 	"github.com/animus-labs/animus-go/closed/internal/platform/postgres"
 	"github.com/animus-labs/animus-go/closed/internal/platform/rbac"
 	repopg "github.com/animus-labs/animus-go/closed/internal/repo/postgres"
 	artifactsvc "github.com/animus-labs/animus-go/closed/internal/service/artifacts"
 	storageobjectstore "github.com/animus-labs/animus-go/closed/internal/storage/objectstore"
+	"github.com/animus-labs/animus-go/closed/migrations"
+	"github.com/animus-labs/animus-go/core/contracts/openapi"
 )
 
-func main() {
+// Main runs the dataset registry as a standalone service.
+func Main() {
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
 
 	ctx := context.Background()
@@ -36,13 +48,7 @@ func main() {
 		os.Exit(2)
 	}
 
-	rbacAllowDirect, err := env.Bool("AUTH_RBAC_ALLOW_DIRECT_ROLES", true)
-	if err != nil {
-		logger.Error("invalid env", "error", err)
-		os.Exit(2)
-	}
-
-	dbCfg, err := postgres.ConfigFromEnv()
+	dbCfg, err := postgres.ConfigFromEnv("dataset-registry")
 	if err != nil {
 		logger.Error("invalid database config", "error", err)
 		os.Exit(2)
@@ -54,30 +60,95 @@ func main() {
 	}
 	defer func() { _ = db.Close() }()
 
-	storeCfg, err := objectstore.ConfigFromEnv()
+	migrator, err := migrate.New(db, migrations.Files, logger)
+	if err != nil {
+		logger.Error("invalid migrations", "error", err)
+		os.Exit(2)
+	}
+	if done, err := migrate.Startup(ctx, migrator, os.Args[1:], os.Stdout); err != nil {
+		logger.Error("migrations failed", "error", err)
+		os.Exit(1)
+	} else if done {
+		return
+	}
+	httpserver.RegisterMetricsProvider(postgres.PrometheusMetrics("dataset-registry", map[string]*sql.DB{"primary": db}))
+
+	internalMTLSCfg, err := auth.InternalMTLSConfigFromEnv()
+	if err != nil {
+		logger.Error("invalid internal mtls config", "error", err)
+		os.Exit(2)
+	}
+	internalServerTLS, err := internalMTLSCfg.ServerTLSConfig()
+	if err != nil {
+		logger.Error("internal mtls init failed", "error", err)
+		os.Exit(2)
+	}
+	handler := NewHandler(ctx, logger, &inproc.Resources{DB: db, Migrator: migrator})
+
+	cfg := httpserver.Config{
+		Service:         "dataset-registry",
+		Addr:            addr,
+		ShutdownTimeout: shutdownTimeout,
+		TLSConfig:       internalServerTLS,
+	}
+
+	if err := httpserver.Run(ctx, logger, cfg, httpserver.Wrap(logger, "dataset-registry", handler)); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Error("server failed", "error", err)
+		os.Exit(1)
+	}
+}
+
+// NewHandler builds the dataset registry API on res and starts the freshness
+// monitor and the outbox relay, which stop with ctx. Invalid configuration
+// exits the process, as it does in Main.
+func NewHandler(ctx context.Context, logger *slog.Logger, res *inproc.Resources) http.Handler {
+	db := res.DB
+	rbacAllowDirect, err := env.Bool("AUTH_RBAC_ALLOW_DIRECT_ROLES", true)
 	if err != nil {
-		logger.Error("invalid object store config", "error", err)
+		logger.Error("invalid env", "error", err)
 		os.Exit(2)
 	}
-	storeClient, err := objectstore.NewMinIOClient(storeCfg)
+
+	storeClient, storeCfg, err := res.ObjectStore()
 	if err != nil {
 		logger.Error("object store client init failed", "error", err)
 		os.Exit(2)
 	}
+	archiveTierCfg, err := objectstore.ArchiveTierConfigFromEnv()
+	if err != nil {
+		logger.Error("invalid archive tier config", "error", err)
+		os.Exit(2)
+	}
 	startupCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	if err := objectstore.EnsureBuckets(startupCtx, storeClient, storeCfg); err != nil {
 		cancel()
 		logger.Error("object store unavailable", "error", err)
 		os.Exit(1)
 	}
+	if err := objectstore.EnsureArchiveLifecycle(startupCtx, storeClient, storeCfg.BucketDatasets, archiveTierCfg); err != nil {
+		cancel()
+		logger.Error("archive tier setup failed", "error", err)
+		os.Exit(1)
+	}
 	cancel()
 
-	internalAuthSecret := env.String("ANIMUS_INTERNAL_AUTH_SECRET", "")
-	headersAuth, err := auth.NewGatewayHeadersAuthenticator(internalAuthSecret)
+	headersAuth, err := auth.NewGatewayHeadersAuthenticator(env.String("ANIMUS_INTERNAL_AUTH_SECRET", ""))
 	if err != nil {
 		logger.Error("invalid internal auth config", "error", err)
 		os.Exit(2)
 	}
+	internalMTLSCfg, err := auth.InternalMTLSConfigFromEnv()
+	if err != nil {
+		logger.Error("invalid internal mtls config", "error", err)
+		os.Exit(2)
+	}
+	headersAuth.Peers = internalMTLSCfg.PeerVerifier()
+
+	rbacPermissionsTTL, err := env.Duration("AUTH_RBAC_PERMISSIONS_CACHE_TTL", 30*time.Second)
+	if err != nil {
+		logger.Error("invalid env", "error", err)
+		os.Exit(2)
+	}
 
 	roleBindings := repopg.NewRoleBindingStore(db)
 	authAuditAppender := repopg.NewAuditAppender(db, nil)
@@ -88,6 +159,8 @@ func main() {
 		RequiredRoleFor: func(r *http.Request) string {
 			return requiredRoleForDatasetRegistry(r)
 		},
+		Permissions:  rbac.NewPermissionCache(repopg.NewRBACPermissionStore(db), "dataset-registry", rbacPermissionsTTL),
+		AuditorScope: datasetRegistryAuditorRoutes,
 	}
 	authorize := func(r *http.Request, identity auth.Identity) error {
 		if r != nil && r.URL.Path == "/projects" && (r.Method == http.MethodPost || r.Method == http.MethodGet) {
@@ -102,6 +175,8 @@ func main() {
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/healthz", httpserver.Healthz("dataset-registry"))
+	mux.HandleFunc("/version", httpserver.BuildInfo("dataset-registry"))
+	mux.Handle("GET "+openapi.ServicePath, openapi.Handler(openapi.DatasetRegistry))
 	mux.HandleFunc(
 		"/readyz",
 		httpserver.ReadyzWithChecks(
@@ -160,8 +235,101 @@ func main() {
 		os.Exit(2)
 	}
 
-	api := newDatasetRegistryAPI(logger, db, storeClient, storeCfg, int64(uploadMaxMiB)<<20, uploadTimeout, service, artifactService)
+	outboxCfg, err := outbox.ConfigFromEnv()
+	if err != nil {
+		logger.Error("invalid outbox config", "error", err)
+		os.Exit(2)
+	}
+	busCfg, err := eventbus.ConfigFromEnv()
+	if err != nil {
+		logger.Error("invalid event bus config", "error", err)
+		os.Exit(2)
+	}
+	busPublisher, err := busCfg.NewPublisher()
+	if err != nil {
+		logger.Error("event bus init failed", "error", err)
+		os.Exit(2)
+	}
+	webhookCfg, err := webhooks.ConfigFromEnv()
+	if err != nil {
+		logger.Error("invalid webhook config", "error", err)
+		os.Exit(2)
+	}
+
+	freshnessCheckInterval, err := env.Duration("DATASET_REGISTRY_FRESHNESS_CHECK_INTERVAL", 15*time.Minute)
+	if err != nil {
+		logger.Error("invalid env", "error", err)
+		os.Exit(2)
+	}
+
+	encryptionCfg, err := envelope.ConfigFromEnv()
+	if err != nil {
+		logger.Error("invalid encryption config", "error", err)
+		os.Exit(2)
+	}
+	encrypter, err := envelope.New(encryptionCfg)
+	if err != nil {
+		logger.Error("encryption init failed", "error", err)
+		os.Exit(2)
+	}
+
+	shareDefaultTTL, err := env.Duration("DATASET_REGISTRY_SHARE_DEFAULT_TTL", time.Hour)
+	if err != nil {
+		logger.Error("invalid env", "error", err)
+		os.Exit(2)
+	}
+	shareMaxTTL, err := env.Duration("DATASET_REGISTRY_SHARE_MAX_TTL", 7*24*time.Hour)
+	if err != nil {
+		logger.Error("invalid env", "error", err)
+		os.Exit(2)
+	}
+	// S3 presigned URLs cannot outlive seven days.
+	if shareDefaultTTL <= 0 || shareMaxTTL <= 0 || shareMaxTTL > 7*24*time.Hour || shareDefaultTTL > shareMaxTTL {
+		logger.Error("invalid env", "error", "DATASET_REGISTRY_SHARE_DEFAULT_TTL must not exceed DATASET_REGISTRY_SHARE_MAX_TTL (at most 168h)")
+		os.Exit(2)
+	}
+
+	referenceVerifyInterval, err := env.Duration("DATASET_REGISTRY_REFERENCE_VERIFY_INTERVAL", time.Hour)
+	if err != nil {
+		logger.Error("invalid env", "error", err)
+		os.Exit(2)
+	}
+
+	profileUploads, err := env.Bool("DATASET_REGISTRY_PROFILE_UPLOADS", false)
+	if err != nil {
+		logger.Error("invalid env", "error", err)
+		os.Exit(2)
+	}
+
+	api := newDatasetRegistryAPI(logger, db, storeClient, storeCfg, int64(uploadMaxMiB)<<20, uploadTimeout, service, artifactService, webhookCfg, encrypter)
+	api.shareCfg = shareConfig{
+		DefaultTTL:    shareDefaultTTL,
+		MaxTTL:        shareMaxTTL,
+		PublicBaseURL: env.String("DATASET_REGISTRY_SHARE_BASE_URL", ""),
+	}
+	api.replicationCfg = replicationConfig{
+		InstanceID: strings.TrimSpace(env.String("ANIMUS_INSTANCE_ID", "")),
+		Secret:     env.String("DATASET_REGISTRY_REPLICATION_SECRET", ""),
+	}
+	api.externalSourceCfg = externalSourceConfig{}
+	for _, host := range strings.Split(env.String("DATASET_REGISTRY_EXTERNAL_SOURCE_HOSTS", ""), ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			api.externalSourceCfg.AllowedHosts = append(api.externalSourceCfg.AllowedHosts, host)
+		}
+	}
+	api.externalSourceCfg.Client = newExternalSourceClient(api.externalSourceCfg)
+	api.referenceCfg = referenceConfig{VerifyInterval: referenceVerifyInterval}
+	for _, bucket := range strings.Split(env.String("DATASET_REGISTRY_REFERENCE_BUCKETS", ""), ",") {
+		if bucket = strings.TrimSpace(bucket); bucket != "" {
+			api.referenceCfg.Buckets = append(api.referenceCfg.Buckets, bucket)
+		}
+	}
+	api.profileUploads = profileUploads
+	api.bus = servicerelay.Bus{Enabled: busPublisher != nil}
 	api.register(mux)
+	startFreshnessMonitor(ctx, logger, api, freshnessCheckInterval)
+	startReferenceVerifier(ctx, logger, api)
+	servicerelay.Start(ctx, logger, db, outboxCfg, busPublisher, busCfg.TopicPrefix)
 
 	projectResolver := func(r *http.Request, identity auth.Identity) (string, error) {
 		if r.Method == http.MethodPost && r.URL.Path == "/projects" {
@@ -170,10 +338,10 @@ func main() {
 		if r.Method == http.MethodGet && r.URL.Path == "/projects" {
 			return "", nil
 		}
-		return auth.RequireProjectIDResolver([]string{"/healthz", "/readyz"})(r, identity)
+		return auth.RequireProjectIDResolver([]string{"/healthz", "/readyz", "/version", openapi.ServicePath})(r, identity)
 	}
 
-	handler := auth.Middleware{
+	return auth.Middleware{
 		Logger:         logger,
 		Authenticator:  headersAuth,
 		Authorize:      authorize,
@@ -183,17 +351,6 @@ func main() {
 			defer cancel()
 			return auditlog.InsertAuthDeny(auditCtx, db, "dataset-registry", event)
 		},
-		SkipPrefixes: []string{"/healthz", "/readyz"},
+		SkipPrefixes: []string{"/healthz", "/readyz", "/version", openapi.ServicePath, shareRedeemPrefix},
 	}.Wrap(mux)
-
-	cfg := httpserver.Config{
-		Service:         "dataset-registry",
-		Addr:            addr,
-		ShutdownTimeout: shutdownTimeout,
-	}
-
-	if err := httpserver.Run(ctx, logger, cfg, httpserver.Wrap(logger, "dataset-registry", handler)); err != nil && !errors.Is(err, http.ErrServerClosed) {
-		logger.Error("server failed", "error", err)
-		os.Exit(1)
-	}
 }
@@ -0,0 +1,170 @@
+package datasetregistry
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/domain"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/dataprofile"
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+)
+
+const (
+	profileStatusOK     = "ok"
+	profileStatusFailed = "failed"
+)
+
+// maxProfileErrorLen bounds the stored failure reason of a profile.
+const maxProfileErrorLen = 512
+
+type datasetVersionProfile struct {
+	VersionID string               `json:"version_id"`
+	DatasetID string               `json:"dataset_id"`
+	Format    string               `json:"format"`
+	Status    string               `json:"status"`
+	Profile   *dataprofile.Profile `json:"profile,omitempty"`
+	Error     string               `json:"error,omitempty"`
+	CreatedAt time.Time            `json:"created_at"`
+}
+
+func (api *datasetRegistryAPI) registerProfiles(mux *http.ServeMux) {
+	mux.HandleFunc("GET /datasets/{dataset_id}/versions/{version_id}/profile", api.handleGetDatasetVersionProfile)
+}
+
+// parseProfileField reads the optional "profile" multipart field.
+func parseProfileField(raw []byte) (bool, bool) {
+	value := strings.TrimSpace(string(raw))
+	if value == "" {
+		return false, false
+	}
+	enabled, err := strconv.ParseBool(value)
+	return enabled, err == nil
+}
+
+// newDatasetVersionProfile turns the profiler outcome into the stored record;
+// a failed profile keeps the reason instead of failing the upload.
+func newDatasetVersionProfile(version domain.DatasetVersion, format string, profile dataprofile.Profile, profileErr error, now time.Time) datasetVersionProfile {
+	out := datasetVersionProfile{
+		VersionID: version.ID,
+		DatasetID: version.DatasetID,
+		Format:    format,
+		Status:    profileStatusOK,
+		CreatedAt: now,
+	}
+	if profileErr != nil {
+		out.Status = profileStatusFailed
+		out.Error = profileErr.Error()
+		if len(out.Error) > maxProfileErrorLen {
+			out.Error = out.Error[:maxProfileErrorLen]
+		}
+		return out
+	}
+	out.Profile = &profile
+	return out
+}
+
+func (api *datasetRegistryAPI) insertDatasetVersionProfile(ctx context.Context, projectID string, record datasetVersionProfile) error {
+	var profileJSON any
+	if record.Profile != nil {
+		encoded, err := json.Marshal(record.Profile)
+		if err != nil {
+			return err
+		}
+		profileJSON = encoded
+	}
+	_, err := api.db.ExecContext(
+		ctx,
+		`INSERT INTO dataset_version_profiles (version_id, dataset_id, project_id, format, status, profile, error, created_at)
+		 VALUES ($1,$2,$3,$4,$5,$6,$7,$8)`,
+		record.VersionID,
+		record.DatasetID,
+		projectID,
+		record.Format,
+		record.Status,
+		profileJSON,
+		nullString(record.Error),
+		record.CreatedAt,
+	)
+	return err
+}
+
+func (api *datasetRegistryAPI) getDatasetVersionProfile(ctx context.Context, projectID, versionID string) (datasetVersionProfile, error) {
+	var (
+		out         datasetVersionProfile
+		profileJSON []byte
+		errText     sql.NullString
+	)
+	err := api.db.QueryRowContext(
+		ctx,
+		`SELECT version_id, dataset_id, format, status, profile, error, created_at
+		 FROM dataset_version_profiles
+		 WHERE project_id = $1 AND version_id = $2`,
+		projectID,
+		versionID,
+	).Scan(&out.VersionID, &out.DatasetID, &out.Format, &out.Status, &profileJSON, &errText, &out.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return datasetVersionProfile{}, repo.ErrNotFound
+		}
+		return datasetVersionProfile{}, err
+	}
+	if len(profileJSON) > 0 {
+		var profile dataprofile.Profile
+		if err := json.Unmarshal(profileJSON, &profile); err != nil {
+			return datasetVersionProfile{}, err
+		}
+		out.Profile = &profile
+	}
+	out.Error = errText.String
+	out.CreatedAt = out.CreatedAt.UTC()
+	return out, nil
+}
+
+func (api *datasetRegistryAPI) handleGetDatasetVersionProfile(w http.ResponseWriter, r *http.Request) {
+	datasetID := strings.TrimSpace(r.PathValue("dataset_id"))
+	versionID := strings.TrimSpace(r.PathValue("version_id"))
+	if datasetID == "" || versionID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "version_id_required")
+		return
+	}
+	if api.db == nil {
+		api.writeError(w, r, http.StatusInternalServerError, "service_unavailable")
+		return
+	}
+	projectID, ok := auth.ProjectIDFromContext(r.Context())
+	if !ok || strings.TrimSpace(projectID) == "" {
+		api.writeError(w, r, http.StatusBadRequest, "project_id_required")
+		return
+	}
+
+	profile, err := api.getDatasetVersionProfile(r.Context(), projectID, versionID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			api.writeError(w, r, http.StatusNotFound, "profile_not_found")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if profile.DatasetID != datasetID {
+		api.writeError(w, r, http.StatusNotFound, "profile_not_found")
+		return
+	}
+	// Profiles carry sample values, so restricted datasets need a grant.
+	identity, ok := auth.IdentityFromContext(r.Context())
+	if !ok || strings.TrimSpace(identity.Subject) == "" {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if _, ok := api.datasetAccessGate(w, r, identity, datasetID, versionID, time.Now().UTC()); !ok {
+		return
+	}
+	api.writeJSON(w, http.StatusOK, profile)
+}
@@ -0,0 +1,40 @@
+package datasetregistry
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/domain"
+	"github.com/animus-labs/animus-go/closed/internal/platform/dataprofile"
+)
+
+func TestParseProfileField(t *testing.T) {
+	if enabled, ok := parseProfileField([]byte(" true\n")); !ok || !enabled {
+		t.Fatalf("profile=true parsed as %v, %v", enabled, ok)
+	}
+	if enabled, ok := parseProfileField([]byte("0")); !ok || enabled {
+		t.Fatalf("profile=0 parsed as %v, %v", enabled, ok)
+	}
+	for _, raw := range []string{"", "yes"} {
+		if _, ok := parseProfileField([]byte(raw)); ok {
+			t.Fatalf("expected %q to be rejected", raw)
+		}
+	}
+}
+
+func TestNewDatasetVersionProfile(t *testing.T) {
+	version := domain.DatasetVersion{ID: "v1", DatasetID: "d1"}
+	now := time.Date(2026, 3, 4, 10, 0, 0, 0, time.UTC)
+
+	ok := newDatasetVersionProfile(version, dataprofile.FormatCSV, dataprofile.Profile{Format: dataprofile.FormatCSV, RowCount: 2}, nil, now)
+	if ok.Status != profileStatusOK || ok.Profile == nil || ok.Profile.RowCount != 2 || ok.Error != "" {
+		t.Fatalf("unexpected profile: %+v", ok)
+	}
+
+	failed := newDatasetVersionProfile(version, dataprofile.FormatParquet, dataprofile.Profile{}, errors.New(strings.Repeat("x", 2*maxProfileErrorLen)), now)
+	if failed.Status != profileStatusFailed || failed.Profile != nil || len(failed.Error) != maxProfileErrorLen {
+		t.Fatalf("unexpected failed profile: status=%s profile=%v error_len=%d", failed.Status, failed.Profile, len(failed.Error))
+	}
+}
@@ -0,0 +1,361 @@
+package datasetregistry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/domain"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	"github.com/animus-labs/animus-go/closed/internal/platform/redaction"
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+	repopg "github.com/animus-labs/animus-go/closed/internal/repo/postgres"
+)
+
+const (
+	maxProjectDefaultPolicies = 64
+	maxArtifactRetentionDays  = 36500
+)
+
+type updateProjectRequest struct {
+	Name        *string         `json:"name,omitempty"`
+	Description *string         `json:"description,omitempty"`
+	Metadata    *map[string]any `json:"metadata,omitempty"`
+}
+
+type projectSettings struct {
+	ProjectID             string     `json:"project_id"`
+	DefaultQualityRuleID  string     `json:"default_quality_rule_id,omitempty"`
+	DefaultPolicyIDs      []string   `json:"default_policy_ids"`
+	ArtifactRetentionDays int        `json:"artifact_retention_days,omitempty"`
+	UpdatedAt             *time.Time `json:"updated_at,omitempty"`
+	UpdatedBy             string     `json:"updated_by,omitempty"`
+}
+
+type putProjectSettingsRequest struct {
+	DefaultQualityRuleID  string   `json:"default_quality_rule_id,omitempty"`
+	DefaultPolicyIDs      []string `json:"default_policy_ids,omitempty"`
+	ArtifactRetentionDays int      `json:"artifact_retention_days,omitempty"`
+}
+
+func (api *datasetRegistryAPI) registerProjects(mux *http.ServeMux) {
+	mux.HandleFunc("PATCH /projects/{project_id}", api.handleUpdateProject)
+	mux.HandleFunc("DELETE /projects/{project_id}", api.handleArchiveProject)
+	mux.HandleFunc("GET /projects/{project_id}/settings", api.handleGetProjectSettings)
+	mux.HandleFunc("PUT /projects/{project_id}/settings", api.handlePutProjectSettings)
+}
+
+func projectResponse(proj domain.Project) project {
+	metaJSON, _ := json.Marshal(proj.Metadata)
+	return project{
+		ProjectID:   proj.ID,
+		Name:        proj.Name,
+		Description: proj.Description,
+		Metadata:    metaJSON,
+		CreatedAt:   proj.CreatedAt,
+		CreatedBy:   proj.CreatedBy,
+		UpdatedAt:   proj.UpdatedAt,
+		UpdatedBy:   proj.UpdatedBy,
+		ArchivedAt:  proj.ArchivedAt,
+		ArchivedBy:  proj.ArchivedBy,
+	}
+}
+
+func projectSettingsResponse(settings domain.ProjectSettings) projectSettings {
+	policyIDs := settings.DefaultPolicyIDs
+	if policyIDs == nil {
+		policyIDs = []string{}
+	}
+	return projectSettings{
+		ProjectID:             settings.ProjectID,
+		DefaultQualityRuleID:  settings.DefaultQualityRuleID,
+		DefaultPolicyIDs:      policyIDs,
+		ArtifactRetentionDays: settings.ArtifactRetentionDays,
+		UpdatedAt:             settings.UpdatedAt,
+		UpdatedBy:             settings.UpdatedBy,
+	}
+}
+
+// pathProject loads the project named in the path, which must match the
+// project scope of the request; it writes the error response itself and
+// reports false when the handler should stop.
+func (api *datasetRegistryAPI) pathProject(w http.ResponseWriter, r *http.Request) (auth.Identity, domain.Project, bool) {
+	identity, ok := auth.IdentityFromContext(r.Context())
+	if !ok || strings.TrimSpace(identity.Subject) == "" {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return auth.Identity{}, domain.Project{}, false
+	}
+	projectID, ok := requireProjectScope(r)
+	if !ok {
+		api.writeError(w, r, http.StatusBadRequest, "project_id_required")
+		return auth.Identity{}, domain.Project{}, false
+	}
+	if api.svc == nil {
+		api.writeError(w, r, http.StatusInternalServerError, "service_unavailable")
+		return auth.Identity{}, domain.Project{}, false
+	}
+	proj, err := api.svc.GetProject(r.Context(), projectID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return auth.Identity{}, domain.Project{}, false
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return auth.Identity{}, domain.Project{}, false
+	}
+	return identity, proj, true
+}
+
+// activeProjectSettings returns the defaults that writes into projectID
+// inherit. Archived projects reject the write with 409 project_archived.
+func (api *datasetRegistryAPI) activeProjectSettings(w http.ResponseWriter, r *http.Request, projectID string) (domain.ProjectSettings, bool) {
+	if api.svc == nil {
+		api.writeError(w, r, http.StatusInternalServerError, "service_unavailable")
+		return domain.ProjectSettings{}, false
+	}
+	proj, err := api.svc.GetProject(r.Context(), projectID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			api.writeError(w, r, http.StatusNotFound, "project_not_found")
+			return domain.ProjectSettings{}, false
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return domain.ProjectSettings{}, false
+	}
+	if proj.Archived() {
+		api.writeError(w, r, http.StatusConflict, "project_archived")
+		return domain.ProjectSettings{}, false
+	}
+	settings, err := repopg.NewProjectSettingsStore(api.db).Get(r.Context(), projectID)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return domain.ProjectSettings{}, false
+	}
+	return settings, true
+}
+
+// artifactRetentionUntil applies the project retention to artifacts created
+// without an explicit retention_until.
+func artifactRetentionUntil(requested *time.Time, settings domain.ProjectSettings, now time.Time) *time.Time {
+	if requested != nil || settings.ArtifactRetentionDays <= 0 {
+		return requested
+	}
+	until := now.UTC().AddDate(0, 0, settings.ArtifactRetentionDays)
+	return &until
+}
+
+func (api *datasetRegistryAPI) handleUpdateProject(w http.ResponseWriter, r *http.Request) {
+	identity, proj, ok := api.pathProject(w, r)
+	if !ok {
+		return
+	}
+	if proj.Archived() {
+		api.writeError(w, r, http.StatusConflict, "project_archived")
+		return
+	}
+
+	var req updateProjectRequest
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
+		return
+	}
+	if req.Name == nil && req.Description == nil && req.Metadata == nil {
+		api.writeError(w, r, http.StatusBadRequest, "changes_required")
+		return
+	}
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			api.writeError(w, r, http.StatusBadRequest, "name_required")
+			return
+		}
+		proj.Name = name
+	}
+	if req.Description != nil {
+		proj.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.Metadata != nil {
+		proj.Metadata = domain.Metadata(redaction.RedactMetadata(*req.Metadata))
+	}
+
+	updated, err := api.svc.UpdateProject(r.Context(), proj, buildAuditContext(r, identity))
+	if err != nil {
+		switch {
+		case isUniqueViolation(err):
+			api.writeError(w, r, http.StatusConflict, "project_name_exists")
+		case errors.Is(err, repo.ErrNotFound):
+			// Archived between the read and the update.
+			api.writeError(w, r, http.StatusConflict, "project_archived")
+		default:
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		}
+		return
+	}
+	api.writeJSON(w, http.StatusOK, projectResponse(updated))
+}
+
+// handleArchiveProject deletes a project by archiving it: datasets, runs
+// and audit history keep referencing the row, while new writes are refused.
+func (api *datasetRegistryAPI) handleArchiveProject(w http.ResponseWriter, r *http.Request) {
+	identity, proj, ok := api.pathProject(w, r)
+	if !ok {
+		return
+	}
+	if proj.Archived() {
+		api.writeError(w, r, http.StatusConflict, "project_archived")
+		return
+	}
+	archived, err := api.svc.ArchiveProject(r.Context(), proj, buildAuditContext(r, identity))
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			api.writeError(w, r, http.StatusConflict, "project_archived")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	api.writeJSON(w, http.StatusOK, projectResponse(archived))
+}
+
+func (api *datasetRegistryAPI) handleGetProjectSettings(w http.ResponseWriter, r *http.Request) {
+	_, proj, ok := api.pathProject(w, r)
+	if !ok {
+		return
+	}
+	settings, err := repopg.NewProjectSettingsStore(api.db).Get(r.Context(), proj.ID)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	api.writeJSON(w, http.StatusOK, projectSettingsResponse(settings))
+}
+
+// handlePutProjectSettings replaces the project defaults. Uploads without a
+// quality_rule_id use the default rule, artifacts without retention_until get
+// the retention, and runs are governed by the default policy set.
+func (api *datasetRegistryAPI) handlePutProjectSettings(w http.ResponseWriter, r *http.Request) {
+	identity, proj, ok := api.pathProject(w, r)
+	if !ok {
+		return
+	}
+	if proj.Archived() {
+		api.writeError(w, r, http.StatusConflict, "project_archived")
+		return
+	}
+
+	var req putProjectSettingsRequest
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
+		return
+	}
+	settings, code := normalizeProjectSettings(proj.ID, req)
+	if code != "" {
+		api.writeError(w, r, http.StatusBadRequest, code)
+		return
+	}
+	if code, err := api.checkProjectSettingsRefs(r.Context(), settings); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	} else if code != "" {
+		api.writeError(w, r, http.StatusNotFound, code)
+		return
+	}
+
+	now := time.Now().UTC()
+	settings.UpdatedAt = &now
+	settings.UpdatedBy = identity.Subject
+
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := repopg.NewProjectSettingsStore(tx).Put(r.Context(), settings); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if err := auditlog.Enqueue(r.Context(), tx, auditlog.Event{
+		OccurredAt:   now,
+		Actor:        identity.Subject,
+		Action:       "project.settings_update",
+		ResourceType: "project",
+		ResourceID:   proj.ID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload: map[string]any{
+			"service":                 "dataset-registry",
+			"project_id":              proj.ID,
+			"default_quality_rule_id": settings.DefaultQualityRuleID,
+			"default_policy_ids":      settings.DefaultPolicyIDs,
+			"artifact_retention_days": settings.ArtifactRetentionDays,
+		},
+	}); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	api.writeJSON(w, http.StatusOK, projectSettingsResponse(settings))
+}
+
+func normalizeProjectSettings(projectID string, req putProjectSettingsRequest) (domain.ProjectSettings, string) {
+	if req.ArtifactRetentionDays < 0 || req.ArtifactRetentionDays > maxArtifactRetentionDays {
+		return domain.ProjectSettings{}, "artifact_retention_days_invalid"
+	}
+	if len(req.DefaultPolicyIDs) > maxProjectDefaultPolicies {
+		return domain.ProjectSettings{}, "too_many_default_policies"
+	}
+	policyIDs := make([]string, 0, len(req.DefaultPolicyIDs))
+	seen := map[string]bool{}
+	for _, raw := range req.DefaultPolicyIDs {
+		id := strings.TrimSpace(raw)
+		if id == "" {
+			return domain.ProjectSettings{}, "default_policy_id_invalid"
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		policyIDs = append(policyIDs, id)
+	}
+	return domain.ProjectSettings{
+		ProjectID:             projectID,
+		DefaultQualityRuleID:  strings.TrimSpace(req.DefaultQualityRuleID),
+		DefaultPolicyIDs:      policyIDs,
+		ArtifactRetentionDays: req.ArtifactRetentionDays,
+	}, ""
+}
+
+// checkProjectSettingsRefs returns quality_rule_not_found or
+// policy_not_found when a default points at a missing rule or policy.
+func (api *datasetRegistryAPI) checkProjectSettingsRefs(ctx context.Context, settings domain.ProjectSettings) (string, error) {
+	if settings.DefaultQualityRuleID != "" {
+		var exists bool
+		if err := api.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM quality_rules WHERE rule_id = $1)`, settings.DefaultQualityRuleID).Scan(&exists); err != nil {
+			return "", err
+		}
+		if !exists {
+			return "quality_rule_not_found", nil
+		}
+	}
+	for _, policyID := range settings.DefaultPolicyIDs {
+		var exists bool
+		if err := api.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM policies WHERE policy_id = $1)`, policyID).Scan(&exists); err != nil {
+			return "", err
+		}
+		if !exists {
+			return "policy_not_found", nil
+		}
+	}
+	return "", nil
+}
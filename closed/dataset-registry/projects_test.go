@@ -0,0 +1,82 @@
+package datasetregistry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/domain"
+)
+
+func TestNormalizeProjectSettings(t *testing.T) {
+	settings, code := normalizeProjectSettings("p-1", putProjectSettingsRequest{
+		DefaultQualityRuleID:  " rule-1 ",
+		DefaultPolicyIDs:      []string{"pol-a", " pol-b", "pol-a"},
+		ArtifactRetentionDays: 30,
+	})
+	if code != "" {
+		t.Fatalf("unexpected code %s", code)
+	}
+	if settings.DefaultQualityRuleID != "rule-1" || len(settings.DefaultPolicyIDs) != 2 || settings.DefaultPolicyIDs[1] != "pol-b" {
+		t.Fatalf("settings=%+v", settings)
+	}
+
+	cases := []struct {
+		req  putProjectSettingsRequest
+		code string
+	}{
+		{req: putProjectSettingsRequest{ArtifactRetentionDays: -1}, code: "artifact_retention_days_invalid"},
+		{req: putProjectSettingsRequest{ArtifactRetentionDays: maxArtifactRetentionDays + 1}, code: "artifact_retention_days_invalid"},
+		{req: putProjectSettingsRequest{DefaultPolicyIDs: []string{"pol-a", " "}}, code: "default_policy_id_invalid"},
+		{req: putProjectSettingsRequest{DefaultPolicyIDs: make([]string, maxProjectDefaultPolicies+1)}, code: "too_many_default_policies"},
+	}
+	for _, tc := range cases {
+		if _, code := normalizeProjectSettings("p-1", tc.req); code != tc.code {
+			t.Fatalf("%+v: expected %s, got %q", tc.req, tc.code, code)
+		}
+	}
+}
+
+func TestArtifactRetentionUntil(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	settings := domain.ProjectSettings{ArtifactRetentionDays: 90}
+
+	got := artifactRetentionUntil(nil, settings, now)
+	if got == nil || !got.Equal(now.AddDate(0, 0, 90)) {
+		t.Fatalf("expected inherited retention, got %v", got)
+	}
+	explicit := now.Add(time.Hour)
+	if got := artifactRetentionUntil(&explicit, settings, now); got != &explicit {
+		t.Fatalf("explicit retention must win, got %v", got)
+	}
+	if got := artifactRetentionUntil(nil, domain.ProjectSettings{}, now); got != nil {
+		t.Fatalf("expected no retention without settings, got %v", got)
+	}
+}
+
+func TestProjectIntegrityStableUntilUpdated(t *testing.T) {
+	created := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	proj := domain.Project{ID: "p-1", Name: "fraud", Metadata: domain.Metadata{}, CreatedAt: created, CreatedBy: "alice"}
+	before, err := projectIntegrity(proj)
+	if err != nil {
+		t.Fatalf("integrity: %v", err)
+	}
+	legacy, _ := integritySHA256(struct {
+		ProjectID   string    `json:"project_id"`
+		Name        string    `json:"name"`
+		Description string    `json:"description,omitempty"`
+		Metadata    any       `json:"metadata"`
+		CreatedAt   time.Time `json:"created_at"`
+		CreatedBy   string    `json:"created_by"`
+	}{ProjectID: "p-1", Name: "fraud", Metadata: map[string]any{}, CreatedAt: created, CreatedBy: "alice"})
+	if before != legacy {
+		t.Fatalf("hash of never-updated project changed")
+	}
+
+	updated := created.Add(time.Hour)
+	proj.UpdatedAt = &updated
+	proj.UpdatedBy = "bob"
+	after, _ := projectIntegrity(proj)
+	if after == before {
+		t.Fatalf("expected update to change the hash")
+	}
+}
@@ -0,0 +1,350 @@
+package datasetregistry
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/domain"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+	repopg "github.com/animus-labs/animus-go/closed/internal/repo/postgres"
+)
+
+const (
+	maxClassificationLabels = 32
+	maxProtectionValueLen   = 64
+	maxRecallReasonLen      = 1024
+)
+
+// datasetProtection holds the classification labels and residency of a
+// dataset; both are inherited by every version of it.
+type datasetProtection struct {
+	ClassificationLabels []string  `json:"classification_labels"`
+	Residency            string    `json:"residency,omitempty"`
+	UpdatedAt            time.Time `json:"updated_at"`
+	UpdatedBy            string    `json:"updated_by"`
+}
+
+type setDatasetProtectionRequest struct {
+	ClassificationLabels []string `json:"classification_labels"`
+	Residency            string   `json:"residency,omitempty"`
+}
+
+type datasetVersionRecall struct {
+	Reason     string    `json:"reason"`
+	RecalledAt time.Time `json:"recalled_at"`
+	RecalledBy string    `json:"recalled_by"`
+}
+
+type recallDatasetVersionRequest struct {
+	Reason string `json:"reason"`
+}
+
+func (api *datasetRegistryAPI) registerProtection(mux *http.ServeMux) {
+	mux.HandleFunc("GET /datasets/{dataset_id}/protection", api.handleGetDatasetProtection)
+	mux.HandleFunc("PUT /datasets/{dataset_id}/protection", api.handleSetDatasetProtection)
+	mux.HandleFunc("POST /dataset-versions/{version_id}/recall", api.handleRecallDatasetVersion)
+}
+
+// normalizeClassificationLabels lowercases, dedupes and sorts labels so that
+// policies can match them with eq/contains regardless of how they were entered.
+func normalizeClassificationLabels(labels []string) ([]string, bool) {
+	seen := make(map[string]struct{}, len(labels))
+	out := make([]string, 0, len(labels))
+	for _, label := range labels {
+		label = strings.ToLower(strings.TrimSpace(label))
+		if label == "" || len(label) > maxProtectionValueLen {
+			return nil, false
+		}
+		if _, ok := seen[label]; ok {
+			continue
+		}
+		seen[label] = struct{}{}
+		out = append(out, label)
+	}
+	if len(out) > maxClassificationLabels {
+		return nil, false
+	}
+	sort.Strings(out)
+	return out, true
+}
+
+func recallResponse(recall *domain.DatasetVersionRecall) *datasetVersionRecall {
+	if recall == nil {
+		return nil
+	}
+	return &datasetVersionRecall{Reason: recall.Reason, RecalledAt: recall.RecalledAt, RecalledBy: recall.RecalledBy}
+}
+
+// dataProtectionPayload is the posture recorded with quality gate decisions.
+func dataProtectionPayload(protection domain.DataProtection) map[string]any {
+	labels := protection.ClassificationLabels
+	if labels == nil {
+		labels = []string{}
+	}
+	out := map[string]any{
+		"encrypted":             protection.Encrypted,
+		"classification_labels": labels,
+		"recalled":              protection.Recall != nil,
+	}
+	if protection.EncryptionKeyID != "" {
+		out["encryption_key_id"] = protection.EncryptionKeyID
+	}
+	if protection.Residency != "" {
+		out["residency"] = protection.Residency
+	}
+	if protection.Recall != nil {
+		out["recall_reason"] = protection.Recall.Reason
+		out["recalled_at"] = protection.Recall.RecalledAt.Format(time.RFC3339Nano)
+	}
+	return out
+}
+
+func (api *datasetRegistryAPI) versionProtection(ctx context.Context, versionID string) (domain.DataProtection, error) {
+	return repopg.NewDataProtectionStore(api.db).Get(ctx, versionID)
+}
+
+func (api *datasetRegistryAPI) datasetProtection(ctx context.Context, datasetID string) (*datasetProtection, error) {
+	var (
+		out       datasetProtection
+		labels    []byte
+		residency sql.NullString
+	)
+	err := api.db.QueryRowContext(
+		ctx,
+		`SELECT classification_labels, residency, updated_at, updated_by
+		 FROM dataset_protection
+		 WHERE dataset_id = $1`,
+		datasetID,
+	).Scan(&labels, &residency, &out.UpdatedAt, &out.UpdatedBy)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(labels, &out.ClassificationLabels); err != nil {
+		return nil, err
+	}
+	if out.ClassificationLabels == nil {
+		out.ClassificationLabels = []string{}
+	}
+	out.Residency = residency.String
+	out.UpdatedAt = out.UpdatedAt.UTC()
+	return &out, nil
+}
+
+func (api *datasetRegistryAPI) handleGetDatasetProtection(w http.ResponseWriter, r *http.Request) {
+	_, _, item, ok := api.dataContractDataset(w, r)
+	if !ok {
+		return
+	}
+	protection, err := api.datasetProtection(r.Context(), item.ID)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if protection == nil {
+		api.writeError(w, r, http.StatusNotFound, "protection_not_configured")
+		return
+	}
+	api.writeJSON(w, http.StatusOK, protection)
+}
+
+func (api *datasetRegistryAPI) handleSetDatasetProtection(w http.ResponseWriter, r *http.Request) {
+	identity, projectID, item, ok := api.dataContractDataset(w, r)
+	if !ok {
+		return
+	}
+	var req setDatasetProtectionRequest
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
+		return
+	}
+	labels, ok := normalizeClassificationLabels(req.ClassificationLabels)
+	if !ok {
+		api.writeError(w, r, http.StatusBadRequest, "classification_labels_invalid")
+		return
+	}
+	residency := strings.ToLower(strings.TrimSpace(req.Residency))
+	if len(residency) > maxProtectionValueLen {
+		api.writeError(w, r, http.StatusBadRequest, "residency_invalid")
+		return
+	}
+	labelsJSON, err := json.Marshal(labels)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	now := time.Now().UTC()
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	_, err = tx.ExecContext(
+		r.Context(),
+		`INSERT INTO dataset_protection (dataset_id, project_id, classification_labels, residency, updated_at, updated_by)
+		 VALUES ($1,$2,$3,$4,$5,$6)
+		 ON CONFLICT (dataset_id) DO UPDATE
+		 SET classification_labels = EXCLUDED.classification_labels,
+			 residency = EXCLUDED.residency,
+			 updated_at = EXCLUDED.updated_at,
+			 updated_by = EXCLUDED.updated_by`,
+		item.ID,
+		projectID,
+		labelsJSON,
+		nullString(residency),
+		now,
+		identity.Subject,
+	)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if err := auditlog.Enqueue(r.Context(), tx, auditlog.Event{
+		OccurredAt:   now,
+		Actor:        identity.Subject,
+		Action:       "dataset.protection_set",
+		ResourceType: "dataset",
+		ResourceID:   item.ID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload: map[string]any{
+			"service":               "dataset-registry",
+			"project_id":            projectID,
+			"dataset_id":            item.ID,
+			"classification_labels": labels,
+			"residency":             residency,
+		},
+	}); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	api.writeJSON(w, http.StatusOK, datasetProtection{
+		ClassificationLabels: labels,
+		Residency:            residency,
+		UpdatedAt:            now,
+		UpdatedBy:            identity.Subject,
+	})
+}
+
+// handleRecallDatasetVersion withdraws a version from use. Recalls are final:
+// recalled versions are blocked by the download and run gates.
+func (api *datasetRegistryAPI) handleRecallDatasetVersion(w http.ResponseWriter, r *http.Request) {
+	versionID := strings.TrimSpace(r.PathValue("version_id"))
+	if versionID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "version_id_required")
+		return
+	}
+	if api.svc == nil || api.db == nil {
+		api.writeError(w, r, http.StatusInternalServerError, "service_unavailable")
+		return
+	}
+	identity, ok := auth.IdentityFromContext(r.Context())
+	if !ok || strings.TrimSpace(identity.Subject) == "" {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	projectID, ok := auth.ProjectIDFromContext(r.Context())
+	if !ok || strings.TrimSpace(projectID) == "" {
+		api.writeError(w, r, http.StatusBadRequest, "project_id_required")
+		return
+	}
+	var req recallDatasetVersionRequest
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
+		return
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		api.writeError(w, r, http.StatusBadRequest, "reason_required")
+		return
+	}
+	if len(reason) > maxRecallReasonLen {
+		api.writeError(w, r, http.StatusBadRequest, "reason_too_long")
+		return
+	}
+
+	version, err := api.svc.GetDatasetVersion(r.Context(), projectID, versionID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	now := time.Now().UTC()
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.ExecContext(
+		r.Context(),
+		`INSERT INTO dataset_version_recalls (version_id, dataset_id, project_id, reason, recalled_at, recalled_by)
+		 VALUES ($1,$2,$3,$4,$5,$6)
+		 ON CONFLICT (version_id) DO NOTHING`,
+		version.ID,
+		version.DatasetID,
+		projectID,
+		reason,
+		now,
+		identity.Subject,
+	)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		api.writeError(w, r, http.StatusConflict, "already_recalled")
+		return
+	}
+	if err := auditlog.Enqueue(r.Context(), tx, auditlog.Event{
+		OccurredAt:   now,
+		Actor:        identity.Subject,
+		Action:       "dataset_version.recall",
+		ResourceType: "dataset_version",
+		ResourceID:   version.ID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload: map[string]any{
+			"service":            "dataset-registry",
+			"project_id":         projectID,
+			"dataset_id":         version.DatasetID,
+			"dataset_version_id": version.ID,
+			"reason":             reason,
+		},
+	}); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	api.writeJSON(w, http.StatusCreated, datasetVersionRecall{Reason: reason, RecalledAt: now, RecalledBy: identity.Subject})
+}
@@ -0,0 +1,22 @@
+package datasetregistry
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeClassificationLabels(t *testing.T) {
+	got, ok := normalizeClassificationLabels([]string{" PII ", "internal", "pii"})
+	if !ok || !reflect.DeepEqual(got, []string{"internal", "pii"}) {
+		t.Fatalf("got %v, %v", got, ok)
+	}
+	if got, ok := normalizeClassificationLabels(nil); !ok || len(got) != 0 {
+		t.Fatalf("expected empty labels, got %v, %v", got, ok)
+	}
+	for _, labels := range [][]string{{""}, {strings.Repeat("x", maxProtectionValueLen+1)}} {
+		if _, ok := normalizeClassificationLabels(labels); ok {
+			t.Fatalf("expected %q to be rejected", labels)
+		}
+	}
+}
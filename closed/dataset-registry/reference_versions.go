@@ -0,0 +1,752 @@
+package datasetregistry
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/domain"
+	"github.com/animus-labs/animus-go/closed/internal/integrations/eventbus"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	"github.com/animus-labs/animus-go/closed/internal/platform/lineageevent"
+	"github.com/animus-labs/animus-go/closed/internal/platform/objectstore"
+	"github.com/animus-labs/animus-go/closed/internal/platform/redaction"
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+	repopg "github.com/animus-labs/animus-go/closed/internal/repo/postgres"
+	"github.com/google/uuid"
+	"github.com/minio/minio-go/v7"
+)
+
+const (
+	referenceStateVerified = "verified"
+	referenceStateChanged  = "changed"
+	referenceStateMissing  = "missing"
+
+	// Where the content_sha256 of a reference version was confirmed.
+	referenceChecksumObject   = "object_checksum"
+	referenceChecksumMetadata = "object_metadata"
+	referenceChecksumDeclared = "declared"
+
+	referenceVerifyBatch   = 500
+	referenceVerifierActor = "system:dataset-reference-verifier"
+)
+
+// referenceConfig lists the buckets reference versions may point into.
+// Registration is off while Buckets is empty.
+type referenceConfig struct {
+	Buckets []string
+	// VerifyInterval is how often each reference is stat'ed again.
+	VerifyInterval time.Duration
+}
+
+func (c referenceConfig) enabled() bool {
+	return len(c.Buckets) > 0
+}
+
+// datasetVersionReference is the object a reference version points at, as
+// stat'ed at registration. The fingerprint (size, ETag and, in versioned
+// buckets, the object version) is what later checks compare against.
+type datasetVersionReference struct {
+	ReferenceID    string                        `json:"reference_id"`
+	VersionID      string                        `json:"version_id"`
+	DatasetID      string                        `json:"dataset_id"`
+	ProjectID      string                        `json:"project_id"`
+	URI            string                        `json:"uri"`
+	Bucket         string                        `json:"bucket"`
+	Key            string                        `json:"key"`
+	ETag           string                        `json:"etag"`
+	StoreVersionID string                        `json:"store_version_id,omitempty"`
+	SizeBytes      int64                         `json:"size_bytes"`
+	LastModified   time.Time                     `json:"last_modified"`
+	ContentSHA256  string                        `json:"content_sha256"`
+	ChecksumSource string                        `json:"checksum_source"`
+	CreatedAt      time.Time                     `json:"created_at"`
+	CreatedBy      string                        `json:"created_by"`
+	Status         datasetVersionReferenceStatus `json:"status"`
+}
+
+type datasetVersionReferenceStatus struct {
+	State     string         `json:"state"`
+	CheckedAt time.Time      `json:"checked_at"`
+	ChangedAt *time.Time     `json:"changed_at,omitempty"`
+	Detail    map[string]any `json:"detail,omitempty"`
+}
+
+type createReferenceVersionRequest struct {
+	URI           string         `json:"uri"`
+	ContentSHA256 string         `json:"content_sha256"`
+	SizeBytes     *int64         `json:"size_bytes"`
+	ETag          string         `json:"etag,omitempty"`
+	QualityRuleID string         `json:"quality_rule_id,omitempty"`
+	Metadata      map[string]any `json:"metadata,omitempty"`
+}
+
+type createReferenceVersionResponse struct {
+	Version   datasetVersion          `json:"version"`
+	Reference datasetVersionReference `json:"reference"`
+}
+
+func (api *datasetRegistryAPI) registerReferenceVersions(mux *http.ServeMux) {
+	mux.HandleFunc("POST /datasets/{dataset_id}/versions/reference", api.handleCreateReferenceVersion)
+	mux.HandleFunc("GET /dataset-versions/{version_id}/reference", api.handleGetDatasetVersionReference)
+}
+
+// referenceBucketAllowed rejects buckets the registry manages itself: their
+// objects follow dataset and artifact retention, not the owning team's.
+func (api *datasetRegistryAPI) referenceBucketAllowed(bucket string) bool {
+	if bucket == api.storeCfg.BucketDatasets || bucket == api.storeCfg.BucketArtifacts {
+		return false
+	}
+	return slices.Contains(api.referenceCfg.Buckets, bucket)
+}
+
+func trimETag(etag string) string {
+	return strings.Trim(strings.TrimSpace(etag), `"`)
+}
+
+// referenceChecksum returns the SHA-256 of the whole object when the store
+// knows it: a full-object checksum, or a sha256 the writer put in the
+// object's metadata. Composite checksums of multipart uploads ("...-N")
+// cover the parts, not the content, and are ignored.
+func referenceChecksum(info minio.ObjectInfo) (string, string) {
+	if value := strings.TrimSpace(info.ChecksumSHA256); value != "" && !strings.Contains(value, "-") && !strings.EqualFold(info.ChecksumMode, "COMPOSITE") {
+		if raw, err := base64.StdEncoding.DecodeString(value); err == nil && len(raw) == 32 {
+			return hex.EncodeToString(raw), referenceChecksumObject
+		}
+	}
+	for _, header := range []string{"X-Amz-Meta-Content-Sha256", "X-Amz-Meta-Sha256"} {
+		if value := strings.ToLower(strings.TrimSpace(info.Metadata.Get(header))); isSHA256Hex(value) {
+			return value, referenceChecksumMetadata
+		}
+	}
+	return "", referenceChecksumDeclared
+}
+
+// referenceChanges lists the fingerprint fields that differ between the
+// registered reference and a fresh stat.
+func referenceChanges(ref datasetVersionReference, info minio.ObjectInfo) map[string]any {
+	changes := map[string]any{}
+	if info.Size != ref.SizeBytes {
+		changes["size_bytes"] = info.Size
+	}
+	if etag := trimETag(info.ETag); etag != ref.ETag {
+		changes["etag"] = etag
+	}
+	if ref.StoreVersionID != "" && info.VersionID != ref.StoreVersionID {
+		changes["store_version_id"] = info.VersionID
+	}
+	return changes
+}
+
+func isNoSuchObject(err error) bool {
+	switch minio.ToErrorResponse(err).Code {
+	case "NoSuchKey", "NoSuchVersion", "NoSuchBucket":
+		return true
+	}
+	return false
+}
+
+// referenceReadOptions pins reads of a reference to the registered object:
+// its store version when the bucket is versioned, and its ETag, so content
+// replaced under the same key is refused rather than served.
+func referenceReadOptions(ref datasetVersionReference) minio.GetObjectOptions {
+	opts := minio.GetObjectOptions{VersionID: ref.StoreVersionID}
+	if ref.ETag != "" {
+		_ = opts.SetMatchETag(ref.ETag)
+	}
+	return opts
+}
+
+// datasetObjectReadOptions returns where a version's content is read from
+// and the options that pin reads of reference versions.
+func (api *datasetRegistryAPI) datasetObjectReadOptions(ctx context.Context, version domain.DatasetVersion) (string, string, minio.GetObjectOptions, error) {
+	bucket, key := api.storeCfg.DatasetObject(version.ObjectKey)
+	if !isReferenceObjectKey(version.ObjectKey) {
+		return bucket, key, minio.GetObjectOptions{}, nil
+	}
+	ref, err := api.datasetVersionReference(ctx, version.ID, version.ProjectID)
+	if err != nil {
+		return "", "", minio.GetObjectOptions{}, err
+	}
+	return bucket, key, referenceReadOptions(ref), nil
+}
+
+func isReferenceObjectKey(objectKey string) bool {
+	_, _, ok := objectstore.ParseObjectURI(objectKey)
+	return ok
+}
+
+// isReferenceChanged reports a read refused because the referenced object
+// no longer carries the registered ETag.
+func isReferenceChanged(err error) bool {
+	return minio.ToErrorResponse(err).Code == "PreconditionFailed"
+}
+
+func (api *datasetRegistryAPI) handleCreateReferenceVersion(w http.ResponseWriter, r *http.Request) {
+	datasetID := strings.TrimSpace(r.PathValue("dataset_id"))
+	if datasetID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "dataset_id_required")
+		return
+	}
+	if api.svc == nil || api.db == nil || api.store == nil {
+		api.writeError(w, r, http.StatusInternalServerError, "service_unavailable")
+		return
+	}
+	if !api.referenceCfg.enabled() {
+		api.writeError(w, r, http.StatusServiceUnavailable, "references_not_configured")
+		return
+	}
+	projectID, ok := auth.ProjectIDFromContext(r.Context())
+	if !ok || strings.TrimSpace(projectID) == "" {
+		api.writeError(w, r, http.StatusBadRequest, "project_id_required")
+		return
+	}
+	identity, ok := auth.IdentityFromContext(r.Context())
+	if !ok || strings.TrimSpace(identity.Subject) == "" {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	var req createReferenceVersionRequest
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
+		return
+	}
+	bucket, key, ok := objectstore.ParseObjectURI(req.URI)
+	if !ok {
+		api.writeError(w, r, http.StatusBadRequest, "reference_uri_invalid")
+		return
+	}
+	if !api.referenceBucketAllowed(bucket) {
+		api.writeError(w, r, http.StatusBadRequest, "reference_bucket_not_allowed")
+		return
+	}
+	contentSHA256 := strings.ToLower(strings.TrimSpace(req.ContentSHA256))
+	if !isSHA256Hex(contentSHA256) || req.SizeBytes == nil || *req.SizeBytes < 0 {
+		api.writeError(w, r, http.StatusBadRequest, "content_sha256_and_size_required")
+		return
+	}
+
+	if _, err := api.svc.GetDataset(r.Context(), projectID, datasetID); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	settings, ok := api.activeProjectSettings(w, r, projectID)
+	if !ok {
+		return
+	}
+	lifecycle, err := api.datasetLifecycle(r.Context(), datasetID)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if lifecycle.State == domain.DatasetLifecycleArchived {
+		api.writeError(w, r, http.StatusConflict, "dataset_archived")
+		return
+	}
+	contract, err := api.activeDataContract(r.Context(), datasetID)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	qualityRuleID := strings.TrimSpace(req.QualityRuleID)
+	if qualityRuleID == "" {
+		qualityRuleID = settings.DefaultQualityRuleID
+	}
+	if qualityRuleID != "" {
+		var exists string
+		if err := api.db.QueryRowContext(r.Context(), `SELECT rule_id FROM quality_rules WHERE rule_id = $1`, qualityRuleID).Scan(&exists); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				api.writeError(w, r, http.StatusNotFound, "quality_rule_not_found")
+				return
+			}
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+	}
+
+	info, err := api.store.StatObject(r.Context(), bucket, key, minio.StatObjectOptions{Checksum: true})
+	if err != nil {
+		if isNoSuchObject(err) {
+			api.writeError(w, r, http.StatusUnprocessableEntity, "reference_object_not_found")
+			return
+		}
+		api.writeError(w, r, http.StatusBadGateway, "object_store_error")
+		return
+	}
+	observedSHA256, checksumSource := referenceChecksum(info)
+	etag := trimETag(info.ETag)
+	mismatch := map[string]any{}
+	if info.Size != *req.SizeBytes {
+		mismatch["size_bytes"] = info.Size
+	}
+	if pinned := trimETag(req.ETag); pinned != "" && pinned != etag {
+		mismatch["etag"] = etag
+	}
+	if observedSHA256 != "" && observedSHA256 != contentSHA256 {
+		mismatch["content_sha256"] = observedSHA256
+	}
+	if len(mismatch) > 0 {
+		api.writeErrorWithDetails(w, r, http.StatusUnprocessableEntity, "reference_checksum_mismatch", map[string]any{"observed": mismatch})
+		return
+	}
+
+	now := time.Now().UTC()
+	versionID := uuid.NewString()
+	filename := sanitizeFilename(path.Base(key))
+	contentType := strings.TrimSpace(info.ContentType)
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	ref := datasetVersionReference{
+		ReferenceID:    uuid.NewString(),
+		VersionID:      versionID,
+		DatasetID:      datasetID,
+		ProjectID:      projectID,
+		URI:            objectstore.ObjectURI(bucket, key),
+		Bucket:         bucket,
+		Key:            key,
+		ETag:           etag,
+		StoreVersionID: info.VersionID,
+		SizeBytes:      info.Size,
+		LastModified:   info.LastModified.UTC(),
+		ContentSHA256:  contentSHA256,
+		ChecksumSource: checksumSource,
+		CreatedAt:      now,
+		CreatedBy:      identity.Subject,
+		Status:         datasetVersionReferenceStatus{State: referenceStateVerified, CheckedAt: now},
+	}
+
+	// The data contract sees the head of the object, read in place; the
+	// object is never copied.
+	metadataMap := req.Metadata
+	if metadataMap == nil {
+		metadataMap = map[string]any{}
+	}
+	var contractViolations []dataContractViolation
+	if contract != nil {
+		head := &headWriter{limit: dataContractHeaderBytes}
+		if info.Size > 0 {
+			opts := referenceReadOptions(ref)
+			if err := opts.SetRange(0, int64(dataContractHeaderBytes)-1); err == nil {
+				if obj, err := api.store.GetObject(r.Context(), bucket, key, opts); err == nil {
+					_, _ = io.Copy(head, obj)
+					_ = obj.Close()
+				}
+			}
+		}
+		contractViolations = evaluateDataContract(*contract, observeDatasetVersion(metadataMap, head.buf, filename, contentType, nil), now)
+	}
+
+	metadataMap["filename"] = filename
+	metadataMap["content_type"] = contentType
+	metadataMap["content_sha256"] = contentSHA256
+	metadataMap["reference"] = map[string]any{
+		"uri":             ref.URI,
+		"etag":            ref.ETag,
+		"checksum_source": ref.ChecksumSource,
+	}
+	metadataMap = redaction.RedactMetadata(metadataMap)
+	metadataJSON, err := json.Marshal(metadataMap)
+	if err != nil {
+		api.writeError(w, r, http.StatusBadRequest, "invalid_metadata")
+		return
+	}
+
+	ordinal, err := api.svc.NextDatasetVersionOrdinal(r.Context(), projectID, datasetID)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	txSvc := api.svc.withRepositories(repopg.NewDatasetStore(tx), repopg.NewAuditAppender(tx, nil))
+	version, err := txSvc.CreateDatasetVersion(r.Context(), domain.DatasetVersion{
+		ID:            versionID,
+		ProjectID:     projectID,
+		DatasetID:     datasetID,
+		QualityRuleID: qualityRuleID,
+		Ordinal:       ordinal,
+		ContentSHA256: contentSHA256,
+		ObjectKey:     ref.URI,
+		SizeBytes:     ref.SizeBytes,
+	}, metadataMap, buildAuditContext(r, identity))
+	if err != nil {
+		if isUniqueViolation(err) {
+			api.writeError(w, r, http.StatusConflict, "duplicate_content")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if err := insertDatasetVersionReference(r.Context(), tx, ref); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	err = lineageevent.Enqueue(r.Context(), tx, lineageevent.Event{
+		OccurredAt:  now,
+		Actor:       identity.Subject,
+		RequestID:   r.Header.Get("X-Request-Id"),
+		SubjectType: "dataset",
+		SubjectID:   datasetID,
+		Predicate:   "has_version",
+		ObjectType:  "dataset_version",
+		ObjectID:    version.ID,
+		Metadata: map[string]any{
+			"ordinal":         version.Ordinal,
+			"content_sha256":  version.ContentSHA256,
+			"quality_rule_id": version.QualityRuleID,
+			"size_bytes":      version.SizeBytes,
+			"object_key":      version.ObjectKey,
+			"reference":       true,
+		},
+	})
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "lineage_write_failed")
+		return
+	}
+	if err := auditlog.Enqueue(r.Context(), tx, auditlog.Event{
+		OccurredAt:   now,
+		Actor:        identity.Subject,
+		Action:       "dataset_version.reference",
+		ResourceType: "dataset_version",
+		ResourceID:   version.ID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload: map[string]any{
+			"service":            "dataset-registry",
+			"project_id":         projectID,
+			"dataset_id":         datasetID,
+			"dataset_version_id": version.ID,
+			"reference_id":       ref.ReferenceID,
+			"uri":                ref.URI,
+			"etag":               ref.ETag,
+			"store_version_id":   ref.StoreVersionID,
+			"content_sha256":     ref.ContentSHA256,
+			"checksum_source":    ref.ChecksumSource,
+		},
+	}); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	err = api.bus.Enqueue(r.Context(), tx, eventbus.EventDatasetVersionCreated, version.ID, projectID, now,
+		map[string]string{"dataset_id": datasetID, "dataset_version_id": version.ID},
+		map[string]any{
+			"ordinal":        version.Ordinal,
+			"content_sha256": version.ContentSHA256,
+			"size_bytes":     version.SizeBytes,
+		},
+	)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	var evaluation *dataContractEvaluation
+	if contract != nil {
+		recorded, err := api.recordDataContractEvaluation(r.Context(), r, *contract, version, contractViolations, now)
+		if err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "data_contract_evaluation_failed")
+			return
+		}
+		evaluation = &recorded
+	}
+
+	w.Header().Set("Location", "/dataset-versions/"+version.ID)
+	api.writeJSON(w, http.StatusCreated, createReferenceVersionResponse{
+		Version: datasetVersion{
+			VersionID:              version.ID,
+			DatasetID:              version.DatasetID,
+			ProjectID:              version.ProjectID,
+			QualityRuleID:          version.QualityRuleID,
+			Ordinal:                version.Ordinal,
+			ContentSHA256:          version.ContentSHA256,
+			ObjectKey:              version.ObjectKey,
+			SizeBytes:              version.SizeBytes,
+			Metadata:               metadataJSON,
+			CreatedAt:              version.CreatedAt,
+			CreatedBy:              version.CreatedBy,
+			DataContractEvaluation: evaluation,
+			Encryption:             objectEncryptionResponse(version.Encryption),
+		},
+		Reference: ref,
+	})
+}
+
+func insertDatasetVersionReference(ctx context.Context, tx *sql.Tx, ref datasetVersionReference) error {
+	integrity, err := integritySHA256(struct {
+		ReferenceID    string    `json:"reference_id"`
+		VersionID      string    `json:"version_id"`
+		URI            string    `json:"uri"`
+		ETag           string    `json:"etag"`
+		StoreVersionID string    `json:"store_version_id"`
+		SizeBytes      int64     `json:"size_bytes"`
+		ContentSHA256  string    `json:"content_sha256"`
+		ChecksumSource string    `json:"checksum_source"`
+		CreatedAt      time.Time `json:"created_at"`
+		CreatedBy      string    `json:"created_by"`
+	}{ref.ReferenceID, ref.VersionID, ref.URI, ref.ETag, ref.StoreVersionID, ref.SizeBytes, ref.ContentSHA256, ref.ChecksumSource, ref.CreatedAt, ref.CreatedBy})
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(
+		ctx,
+		`INSERT INTO dataset_version_references (reference_id, version_id, dataset_id, project_id, uri, bucket, object_key, etag, store_version_id, size_bytes, last_modified, content_sha256, checksum_source, created_at, created_by, integrity_sha256)
+		 VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16)`,
+		ref.ReferenceID,
+		ref.VersionID,
+		ref.DatasetID,
+		ref.ProjectID,
+		ref.URI,
+		ref.Bucket,
+		ref.Key,
+		ref.ETag,
+		ref.StoreVersionID,
+		ref.SizeBytes,
+		ref.LastModified,
+		ref.ContentSHA256,
+		ref.ChecksumSource,
+		ref.CreatedAt,
+		ref.CreatedBy,
+		integrity,
+	)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(
+		ctx,
+		`INSERT INTO dataset_version_reference_status (version_id, state, checked_at) VALUES ($1, $2, $3)`,
+		ref.VersionID,
+		ref.Status.State,
+		ref.Status.CheckedAt,
+	)
+	return err
+}
+
+const selectReferenceColumns = `r.reference_id, r.version_id, r.dataset_id, r.project_id, r.uri, r.bucket, r.object_key, r.etag, r.store_version_id,
+	r.size_bytes, r.last_modified, r.content_sha256, r.checksum_source, r.created_at, r.created_by,
+	s.state, s.checked_at, s.changed_at, s.detail`
+
+func scanDatasetVersionReference(row interface{ Scan(...any) error }) (datasetVersionReference, error) {
+	var (
+		ref       datasetVersionReference
+		changedAt sql.NullTime
+		detail    []byte
+	)
+	err := row.Scan(
+		&ref.ReferenceID, &ref.VersionID, &ref.DatasetID, &ref.ProjectID, &ref.URI, &ref.Bucket, &ref.Key, &ref.ETag, &ref.StoreVersionID,
+		&ref.SizeBytes, &ref.LastModified, &ref.ContentSHA256, &ref.ChecksumSource, &ref.CreatedAt, &ref.CreatedBy,
+		&ref.Status.State, &ref.Status.CheckedAt, &changedAt, &detail,
+	)
+	if err != nil {
+		return datasetVersionReference{}, err
+	}
+	ref.LastModified = ref.LastModified.UTC()
+	ref.CreatedAt = ref.CreatedAt.UTC()
+	ref.Status.CheckedAt = ref.Status.CheckedAt.UTC()
+	if changedAt.Valid {
+		at := changedAt.Time.UTC()
+		ref.Status.ChangedAt = &at
+	}
+	if len(detail) > 0 {
+		_ = json.Unmarshal(detail, &ref.Status.Detail)
+	}
+	return ref, nil
+}
+
+func (api *datasetRegistryAPI) datasetVersionReference(ctx context.Context, versionID, projectID string) (datasetVersionReference, error) {
+	row := api.db.QueryRowContext(
+		ctx,
+		`SELECT `+selectReferenceColumns+`
+		 FROM dataset_version_references r
+		 JOIN dataset_version_reference_status s ON s.version_id = r.version_id
+		 WHERE r.version_id = $1 AND r.project_id = $2`,
+		versionID,
+		projectID,
+	)
+	return scanDatasetVersionReference(row)
+}
+
+func (api *datasetRegistryAPI) handleGetDatasetVersionReference(w http.ResponseWriter, r *http.Request) {
+	versionID := strings.TrimSpace(r.PathValue("version_id"))
+	if versionID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "version_id_required")
+		return
+	}
+	if api.db == nil {
+		api.writeError(w, r, http.StatusInternalServerError, "service_unavailable")
+		return
+	}
+	projectID, ok := auth.ProjectIDFromContext(r.Context())
+	if !ok || strings.TrimSpace(projectID) == "" {
+		api.writeError(w, r, http.StatusBadRequest, "project_id_required")
+		return
+	}
+	ref, err := api.datasetVersionReference(r.Context(), versionID, projectID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	api.writeJSON(w, http.StatusOK, ref)
+}
+
+func startReferenceVerifier(ctx context.Context, logger *slog.Logger, api *datasetRegistryAPI) {
+	if api == nil || api.db == nil || api.store == nil || !api.referenceCfg.enabled() {
+		return
+	}
+	interval := api.referenceCfg.VerifyInterval
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := api.verifyDatasetReferences(ctx, time.Now().UTC(), interval); err != nil && logger != nil {
+					logger.Warn("dataset reference verification failed", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// verifyDatasetReferences stats every verified reference not checked within
+// interval and marks it changed or missing when its fingerprint no longer
+// holds. A reference that failed once stays failed: the registered content
+// can no longer be read.
+func (api *datasetRegistryAPI) verifyDatasetReferences(ctx context.Context, now time.Time, interval time.Duration) error {
+	rows, err := api.db.QueryContext(
+		ctx,
+		`SELECT `+selectReferenceColumns+`
+		 FROM dataset_version_references r
+		 JOIN dataset_version_reference_status s ON s.version_id = r.version_id
+		 WHERE s.state = $1 AND s.checked_at <= $2
+		 ORDER BY s.checked_at
+		 LIMIT $3`,
+		referenceStateVerified,
+		now.Add(-interval),
+		referenceVerifyBatch,
+	)
+	if err != nil {
+		return err
+	}
+	refs := []datasetVersionReference{}
+	for rows.Next() {
+		ref, err := scanDatasetVersionReference(rows)
+		if err != nil {
+			_ = rows.Close()
+			return err
+		}
+		refs = append(refs, ref)
+	}
+	if err := rows.Err(); err != nil {
+		_ = rows.Close()
+		return err
+	}
+	_ = rows.Close()
+
+	var lastErr error
+	for _, ref := range refs {
+		info, err := api.store.StatObject(ctx, ref.Bucket, ref.Key, minio.StatObjectOptions{VersionID: ref.StoreVersionID})
+		switch {
+		case err != nil && isNoSuchObject(err):
+			lastErr = errors.Join(lastErr, api.markReferenceFailed(ctx, ref, referenceStateMissing, map[string]any{"error": minio.ToErrorResponse(err).Code}, now))
+		case err != nil:
+			lastErr = errors.Join(lastErr, err)
+		default:
+			if changes := referenceChanges(ref, info); len(changes) > 0 {
+				lastErr = errors.Join(lastErr, api.markReferenceFailed(ctx, ref, referenceStateChanged, changes, now))
+				continue
+			}
+			_, err := api.db.ExecContext(ctx, `UPDATE dataset_version_reference_status SET checked_at = $2 WHERE version_id = $1 AND state = $3`, ref.VersionID, now, referenceStateVerified)
+			lastErr = errors.Join(lastErr, err)
+		}
+	}
+	return lastErr
+}
+
+func (api *datasetRegistryAPI) markReferenceFailed(ctx context.Context, ref datasetVersionReference, state string, detail map[string]any, now time.Time) error {
+	detailJSON, err := json.Marshal(detail)
+	if err != nil {
+		return err
+	}
+	tx, err := api.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Only the first replica to observe the change records it.
+	res, err := tx.ExecContext(
+		ctx,
+		`UPDATE dataset_version_reference_status
+		 SET state = $2, checked_at = $3, changed_at = $3, detail = $4
+		 WHERE version_id = $1 AND state = $5`,
+		ref.VersionID,
+		state,
+		now,
+		detailJSON,
+		referenceStateVerified,
+	)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return err
+	}
+	if err := auditlog.Enqueue(ctx, tx, auditlog.Event{
+		OccurredAt:   now,
+		Actor:        referenceVerifierActor,
+		Action:       "dataset_version_reference." + state,
+		ResourceType: "dataset_version",
+		ResourceID:   ref.VersionID,
+		Payload: map[string]any{
+			"service":            "dataset-registry",
+			"project_id":         ref.ProjectID,
+			"dataset_id":         ref.DatasetID,
+			"dataset_version_id": ref.VersionID,
+			"reference_id":       ref.ReferenceID,
+			"uri":                ref.URI,
+			"observed":           detail,
+		},
+	}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
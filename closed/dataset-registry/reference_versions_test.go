@@ -0,0 +1,52 @@
+package datasetregistry
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"testing"
+
+	"github.com/minio/minio-go/v7"
+)
+
+func TestReferenceChecksum(t *testing.T) {
+	sum := sha256.Sum256([]byte("a,b\n1,2\n"))
+	want := hex.EncodeToString(sum[:])
+
+	got, source := referenceChecksum(minio.ObjectInfo{ChecksumSHA256: base64.StdEncoding.EncodeToString(sum[:]), ChecksumMode: "FULL_OBJECT"})
+	if got != want || source != referenceChecksumObject {
+		t.Fatalf("full object checksum = %q %q", got, source)
+	}
+
+	// A multipart composite checksum covers the parts; metadata wins.
+	info := minio.ObjectInfo{
+		ChecksumSHA256: base64.StdEncoding.EncodeToString(sum[:]) + "-3",
+		Metadata:       http.Header{"X-Amz-Meta-Sha256": {want}},
+	}
+	if got, source := referenceChecksum(info); got != want || source != referenceChecksumMetadata {
+		t.Fatalf("composite checksum = %q %q", got, source)
+	}
+
+	if got, source := referenceChecksum(minio.ObjectInfo{ETag: `"abc-12"`}); got != "" || source != referenceChecksumDeclared {
+		t.Fatalf("no checksum = %q %q", got, source)
+	}
+}
+
+func TestReferenceChanges(t *testing.T) {
+	ref := datasetVersionReference{ETag: "abc-12", SizeBytes: 10, StoreVersionID: "v1"}
+	if changes := referenceChanges(ref, minio.ObjectInfo{ETag: `"abc-12"`, Size: 10, VersionID: "v1"}); len(changes) != 0 {
+		t.Fatalf("unchanged object reported %v", changes)
+	}
+	changes := referenceChanges(ref, minio.ObjectInfo{ETag: `"def-12"`, Size: 11, VersionID: "v2"})
+	if changes["etag"] != "def-12" || changes["size_bytes"] != int64(11) || changes["store_version_id"] != "v2" {
+		t.Fatalf("changes = %v", changes)
+	}
+}
+
+func TestReferenceReadOptionsPinObject(t *testing.T) {
+	opts := referenceReadOptions(datasetVersionReference{ETag: "abc-12", StoreVersionID: "v1"})
+	if opts.VersionID != "v1" || opts.Header().Get("If-Match") != `"abc-12"` {
+		t.Fatalf("opts = %+v, headers = %v", opts, opts.Header())
+	}
+}
@@ -0,0 +1,1044 @@
+package datasetregistry
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/domain"
+	"github.com/animus-labs/animus-go/closed/internal/integrations/eventbus"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/dataprofile"
+	"github.com/animus-labs/animus-go/closed/internal/platform/envelope"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	"github.com/animus-labs/animus-go/closed/internal/platform/lineageevent"
+	"github.com/animus-labs/animus-go/closed/internal/platform/redaction"
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+	repopg "github.com/animus-labs/animus-go/closed/internal/repo/postgres"
+	"github.com/google/uuid"
+	"github.com/minio/minio-go/v7"
+)
+
+const (
+	replicaSchema         = "animus.dataset-replica/v1"
+	replicaManifestName   = "replica.json"
+	replicaSignatureName  = "replica.sig.json"
+	replicaDataName       = "data"
+	replicaContentType    = "application/x-tar"
+	replicaSignatureAlg   = "hmac-sha256"
+	maxReplicaHeaderBytes = 8 << 20
+	maxReplicaLineage     = 1000
+)
+
+var (
+	errReplicaPackageInvalid   = errors.New("invalid replica package")
+	errReplicaSignatureInvalid = errors.New("replica signature invalid")
+)
+
+// replicationConfig identifies this deployment to the deployments it
+// exchanges dataset versions with. Replication is off unless both are set.
+type replicationConfig struct {
+	InstanceID string
+	// Secret signs outgoing packages and verifies incoming ones; deployments
+	// that trust each other share it.
+	Secret string
+}
+
+func (c replicationConfig) enabled() bool {
+	return strings.TrimSpace(c.InstanceID) != "" && strings.TrimSpace(c.Secret) != ""
+}
+
+type replicaSource struct {
+	InstanceID string `json:"instance_id"`
+	ProjectID  string `json:"project_id"`
+	DatasetID  string `json:"dataset_id"`
+	VersionID  string `json:"version_id"`
+	Ordinal    int64  `json:"ordinal"`
+}
+
+type replicaDataset struct {
+	Name                 string          `json:"name"`
+	Description          string          `json:"description,omitempty"`
+	Metadata             json.RawMessage `json:"metadata"`
+	ClassificationLabels []string        `json:"classification_labels"`
+	Residency            string          `json:"residency,omitempty"`
+}
+
+type replicaVersion struct {
+	ContentSHA256 string          `json:"content_sha256"`
+	SizeBytes     int64           `json:"size_bytes"`
+	Metadata      json.RawMessage `json:"metadata"`
+	QualityRuleID string          `json:"quality_rule_id,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+	CreatedBy     string          `json:"created_by"`
+}
+
+// replicaQualityEvaluation is a quality evaluation of the source version.
+// The rule digest lets the destination tell whether its rule of the same ID
+// is the same rule.
+type replicaQualityEvaluation struct {
+	EvaluationID        string          `json:"evaluation_id"`
+	RuleID              string          `json:"rule_id"`
+	RuleIntegritySHA256 string          `json:"rule_integrity_sha256"`
+	Status              string          `json:"status"`
+	EvaluatedAt         time.Time       `json:"evaluated_at"`
+	EvaluatedBy         string          `json:"evaluated_by"`
+	Summary             json.RawMessage `json:"summary"`
+	ReportSHA256        string          `json:"report_sha256"`
+	ReportSizeBytes     int64           `json:"report_size_bytes"`
+}
+
+type replicaLineageEdge struct {
+	OccurredAt  time.Time `json:"occurred_at"`
+	SubjectType string    `json:"subject_type"`
+	SubjectID   string    `json:"subject_id"`
+	Predicate   string    `json:"predicate"`
+	ObjectType  string    `json:"object_type"`
+	ObjectID    string    `json:"object_id"`
+}
+
+type replicaManifest struct {
+	Schema             string                     `json:"schema"`
+	Source             replicaSource              `json:"source"`
+	Dataset            replicaDataset             `json:"dataset"`
+	Version            replicaVersion             `json:"version"`
+	QualityEvaluations []replicaQualityEvaluation `json:"quality_evaluations"`
+	// Lineage holds the source edges touching the version, oldest first.
+	Lineage    []replicaLineageEdge `json:"lineage"`
+	ExportedAt time.Time            `json:"exported_at"`
+	ExportedBy string               `json:"exported_by"`
+}
+
+type replicaSignature struct {
+	ManifestSHA256 string `json:"manifest_sha256"`
+	Signature      string `json:"signature"`
+	SignatureAlg   string `json:"signature_alg"`
+	InstanceID     string `json:"instance_id"`
+}
+
+// datasetVersionReplica records where a replicated version came from. The
+// manifest is kept byte for byte, so manifest_sha256 and the signature can
+// be checked against it again.
+type datasetVersionReplica struct {
+	ReplicaID        string          `json:"replica_id"`
+	VersionID        string          `json:"version_id"`
+	DatasetID        string          `json:"dataset_id"`
+	ProjectID        string          `json:"project_id"`
+	SourceInstanceID string          `json:"source_instance_id"`
+	SourceVersionID  string          `json:"source_version_id"`
+	Manifest         json.RawMessage `json:"manifest"`
+	ManifestSHA256   string          `json:"manifest_sha256"`
+	Signature        string          `json:"signature"`
+	SignatureAlg     string          `json:"signature_alg"`
+	CreatedAt        time.Time       `json:"created_at"`
+	CreatedBy        string          `json:"created_by"`
+}
+
+type replicateDatasetVersionResponse struct {
+	Version datasetVersion        `json:"version"`
+	Replica datasetVersionReplica `json:"replica"`
+}
+
+func (api *datasetRegistryAPI) registerReplication(mux *http.ServeMux) {
+	mux.HandleFunc("POST /datasets/{dataset_id}/versions/{version_id}/replica-package", api.handleExportReplicaPackage)
+	mux.HandleFunc("POST /datasets/{dataset_id}/versions/replicas", api.handleReplicateDatasetVersion)
+	mux.HandleFunc("GET /dataset-versions/{version_id}/replica", api.handleGetDatasetVersionReplica)
+}
+
+func signReplicaManifest(secret, manifestSHA string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte(manifestSHA))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// newReplicaPackage encodes and signs manifest.
+func newReplicaPackage(manifest replicaManifest, cfg replicationConfig) ([]byte, replicaSignature, error) {
+	payload, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, replicaSignature{}, err
+	}
+	sum := sha256.Sum256(payload)
+	manifestSHA := hex.EncodeToString(sum[:])
+	return payload, replicaSignature{
+		ManifestSHA256: manifestSHA,
+		Signature:      signReplicaManifest(cfg.Secret, manifestSHA),
+		SignatureAlg:   replicaSignatureAlg,
+		InstanceID:     cfg.InstanceID,
+	}, nil
+}
+
+// writeReplicaPackage writes the tar package: manifest, signature, then the
+// version content, so a destination can verify the signature before it
+// stores any data.
+func writeReplicaPackage(w io.Writer, manifest []byte, signature replicaSignature, modified time.Time, content io.Reader, size int64) error {
+	sigJSON, err := json.Marshal(signature)
+	if err != nil {
+		return err
+	}
+	tw := tar.NewWriter(w)
+	for _, entry := range []struct {
+		name string
+		size int64
+		body io.Reader
+	}{
+		{replicaManifestName, int64(len(manifest)), bytes.NewReader(manifest)},
+		{replicaSignatureName, int64(len(sigJSON)), bytes.NewReader(sigJSON)},
+		{replicaDataName, size, content},
+	} {
+		if err := tw.WriteHeader(&tar.Header{Name: entry.name, Mode: 0o644, Size: entry.size, ModTime: modified, Format: tar.FormatPAX}); err != nil {
+			return err
+		}
+		if _, err := io.CopyN(tw, entry.body, entry.size); err != nil {
+			return fmt.Errorf("%s: %w", entry.name, err)
+		}
+	}
+	return tw.Close()
+}
+
+// openReplicaPackage reads and verifies the manifest and signature and
+// leaves tr at the start of the data entry, whose size matches the manifest.
+// The content digest can only be checked once the data has been read.
+func openReplicaPackage(tr *tar.Reader, secret string) (replicaManifest, []byte, replicaSignature, error) {
+	manifestJSON, err := readReplicaEntry(tr, replicaManifestName)
+	if err != nil {
+		return replicaManifest{}, nil, replicaSignature{}, err
+	}
+	sigJSON, err := readReplicaEntry(tr, replicaSignatureName)
+	if err != nil {
+		return replicaManifest{}, nil, replicaSignature{}, err
+	}
+	var signature replicaSignature
+	if err := json.Unmarshal(sigJSON, &signature); err != nil {
+		return replicaManifest{}, nil, replicaSignature{}, fmt.Errorf("%w: %s: %v", errReplicaPackageInvalid, replicaSignatureName, err)
+	}
+	sum := sha256.Sum256(manifestJSON)
+	manifestSHA := hex.EncodeToString(sum[:])
+	if signature.SignatureAlg != replicaSignatureAlg || signature.ManifestSHA256 != manifestSHA ||
+		!hmac.Equal([]byte(signature.Signature), []byte(signReplicaManifest(secret, manifestSHA))) {
+		return replicaManifest{}, nil, replicaSignature{}, errReplicaSignatureInvalid
+	}
+
+	var manifest replicaManifest
+	if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+		return replicaManifest{}, nil, replicaSignature{}, fmt.Errorf("%w: %s: %v", errReplicaPackageInvalid, replicaManifestName, err)
+	}
+	switch {
+	case manifest.Schema != replicaSchema:
+		return replicaManifest{}, nil, replicaSignature{}, fmt.Errorf("%w: unsupported schema %q", errReplicaPackageInvalid, manifest.Schema)
+	case manifest.Source.InstanceID != signature.InstanceID || strings.TrimSpace(manifest.Source.VersionID) == "":
+		return replicaManifest{}, nil, replicaSignature{}, fmt.Errorf("%w: source does not match signature", errReplicaPackageInvalid)
+	case !isSHA256Hex(manifest.Version.ContentSHA256) || manifest.Version.SizeBytes < 0:
+		return replicaManifest{}, nil, replicaSignature{}, fmt.Errorf("%w: version content", errReplicaPackageInvalid)
+	}
+
+	header, err := tr.Next()
+	if err != nil || header.Name != replicaDataName || header.Typeflag != tar.TypeReg {
+		return replicaManifest{}, nil, replicaSignature{}, fmt.Errorf("%w: %s missing", errReplicaPackageInvalid, replicaDataName)
+	}
+	if header.Size != manifest.Version.SizeBytes {
+		return replicaManifest{}, nil, replicaSignature{}, fmt.Errorf("%w: %s size %d, manifest %d", errReplicaPackageInvalid, replicaDataName, header.Size, manifest.Version.SizeBytes)
+	}
+	return manifest, manifestJSON, signature, nil
+}
+
+func readReplicaEntry(tr *tar.Reader, name string) ([]byte, error) {
+	header, err := tr.Next()
+	if err != nil || header.Name != name || header.Typeflag != tar.TypeReg {
+		return nil, fmt.Errorf("%w: %s missing", errReplicaPackageInvalid, name)
+	}
+	if header.Size > maxReplicaHeaderBytes {
+		return nil, fmt.Errorf("%w: %s too large", errReplicaPackageInvalid, name)
+	}
+	payload, err := io.ReadAll(tr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", errReplicaPackageInvalid, name, err)
+	}
+	return payload, nil
+}
+
+func isSHA256Hex(value string) bool {
+	if len(value) != sha256.Size*2 || strings.ToLower(value) != value {
+		return false
+	}
+	_, err := hex.DecodeString(value)
+	return err == nil
+}
+
+// handleExportReplicaPackage streams a signed replica package of a version
+// for another deployment. Only versions that pass their gate leave.
+func (api *datasetRegistryAPI) handleExportReplicaPackage(w http.ResponseWriter, r *http.Request) {
+	versionID := strings.TrimSpace(r.PathValue("version_id"))
+	if versionID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "version_id_required")
+		return
+	}
+	if api.svc == nil || api.db == nil || api.store == nil {
+		api.writeError(w, r, http.StatusInternalServerError, "service_unavailable")
+		return
+	}
+	if !api.replicationCfg.enabled() {
+		api.writeError(w, r, http.StatusServiceUnavailable, "replication_not_configured")
+		return
+	}
+	identity, ok := auth.IdentityFromContext(r.Context())
+	if !ok || strings.TrimSpace(identity.Subject) == "" {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	projectID, ok := auth.ProjectIDFromContext(r.Context())
+	if !ok || strings.TrimSpace(projectID) == "" {
+		api.writeError(w, r, http.StatusBadRequest, "project_id_required")
+		return
+	}
+
+	version, err := api.svc.GetDatasetVersion(r.Context(), projectID, versionID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if version.DatasetID != strings.TrimSpace(r.PathValue("dataset_id")) {
+		api.writeError(w, r, http.StatusNotFound, "not_found")
+		return
+	}
+	now := time.Now().UTC()
+	if !api.datasetVersionGate(w, r, identity, version, now) {
+		return
+	}
+
+	manifest, err := api.buildReplicaManifest(r.Context(), version, identity.Subject, now)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	manifestJSON, signature, err := newReplicaPackage(manifest, api.replicationCfg)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	var dataKey []byte
+	if version.Encryption.Enabled() {
+		dataKey, err = api.encrypter.DataKey(r.Context(), version.Encryption.Algorithm, version.Encryption.KeyID, version.Encryption.WrappedKey)
+		if err != nil {
+			api.writeError(w, r, http.StatusBadGateway, "encryption_key_unavailable")
+			return
+		}
+	}
+	bucket, key, readOpts, err := api.datasetObjectReadOptions(r.Context(), version)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	obj, err := api.store.GetObject(r.Context(), bucket, key, readOpts)
+	if err != nil {
+		api.writeError(w, r, http.StatusBadGateway, "object_store_error")
+		return
+	}
+	defer obj.Close()
+	if _, err := obj.Stat(); err != nil {
+		if isReferenceChanged(err) {
+			api.writeError(w, r, http.StatusConflict, "dataset_version_reference_changed")
+			return
+		}
+		api.writeError(w, r, http.StatusNotFound, "not_found")
+		return
+	}
+	var content io.Reader = obj
+	if dataKey != nil {
+		if content, err = envelope.NewDecryptSeeker(dataKey, obj, version.SizeBytes); err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+	}
+
+	if err := auditlog.Enqueue(r.Context(), api.db, auditlog.Event{
+		OccurredAt:   now,
+		Actor:        identity.Subject,
+		Action:       "dataset_version.replica_export",
+		ResourceType: "dataset_version",
+		ResourceID:   version.ID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload: map[string]any{
+			"service":            "dataset-registry",
+			"project_id":         projectID,
+			"dataset_id":         version.DatasetID,
+			"dataset_version_id": version.ID,
+			"content_sha256":     version.ContentSHA256,
+			"instance_id":        api.replicationCfg.InstanceID,
+			"manifest_sha256":    signature.ManifestSHA256,
+		},
+	}); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "audit_write_failed")
+		return
+	}
+
+	w.Header().Set("Content-Type", replicaContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", version.ID+".replica.tar"))
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	if err := writeReplicaPackage(w, manifestJSON, signature, now, content, version.SizeBytes); err != nil && api.logger != nil {
+		api.logger.Warn("replica package write failed", "version_id", version.ID, "error", err)
+	}
+}
+
+func (api *datasetRegistryAPI) buildReplicaManifest(ctx context.Context, version domain.DatasetVersion, actor string, now time.Time) (replicaManifest, error) {
+	item, err := api.svc.GetDataset(ctx, version.ProjectID, version.DatasetID)
+	if err != nil {
+		return replicaManifest{}, err
+	}
+	datasetMeta, err := json.Marshal(item.Metadata)
+	if err != nil {
+		return replicaManifest{}, err
+	}
+	versionMeta, err := json.Marshal(version.Metadata)
+	if err != nil {
+		return replicaManifest{}, err
+	}
+	protection, err := api.datasetProtection(ctx, version.DatasetID)
+	if err != nil {
+		return replicaManifest{}, err
+	}
+	labels := []string{}
+	residency := ""
+	if protection != nil {
+		labels, residency = protection.ClassificationLabels, protection.Residency
+	}
+	evaluations, err := api.replicaQualityEvaluations(ctx, version.ID)
+	if err != nil {
+		return replicaManifest{}, err
+	}
+	lineage, err := api.replicaLineage(ctx, version.ID)
+	if err != nil {
+		return replicaManifest{}, err
+	}
+	return replicaManifest{
+		Schema: replicaSchema,
+		Source: replicaSource{
+			InstanceID: api.replicationCfg.InstanceID,
+			ProjectID:  version.ProjectID,
+			DatasetID:  version.DatasetID,
+			VersionID:  version.ID,
+			Ordinal:    version.Ordinal,
+		},
+		Dataset: replicaDataset{
+			Name:                 item.Name,
+			Description:          item.Description,
+			Metadata:             datasetMeta,
+			ClassificationLabels: labels,
+			Residency:            residency,
+		},
+		Version: replicaVersion{
+			ContentSHA256: version.ContentSHA256,
+			SizeBytes:     version.SizeBytes,
+			Metadata:      versionMeta,
+			QualityRuleID: strings.TrimSpace(version.QualityRuleID),
+			CreatedAt:     version.CreatedAt.UTC(),
+			CreatedBy:     version.CreatedBy,
+		},
+		QualityEvaluations: evaluations,
+		Lineage:            lineage,
+		ExportedAt:         now,
+		ExportedBy:         actor,
+	}, nil
+}
+
+func (api *datasetRegistryAPI) replicaQualityEvaluations(ctx context.Context, versionID string) ([]replicaQualityEvaluation, error) {
+	rows, err := api.db.QueryContext(
+		ctx,
+		`SELECT e.evaluation_id, e.rule_id, r.integrity_sha256, e.status, e.evaluated_at, e.evaluated_by, e.summary, e.report_sha256, e.report_size_bytes
+		 FROM quality_evaluations e
+		 JOIN quality_rules r ON r.rule_id = e.rule_id
+		 WHERE e.dataset_version_id = $1
+		 ORDER BY e.evaluated_at, e.evaluation_id`,
+		versionID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []replicaQualityEvaluation{}
+	for rows.Next() {
+		var (
+			item    replicaQualityEvaluation
+			summary []byte
+		)
+		if err := rows.Scan(&item.EvaluationID, &item.RuleID, &item.RuleIntegritySHA256, &item.Status, &item.EvaluatedAt, &item.EvaluatedBy, &summary, &item.ReportSHA256, &item.ReportSizeBytes); err != nil {
+			return nil, err
+		}
+		item.EvaluatedAt = item.EvaluatedAt.UTC()
+		item.Summary = httpapi.NormalizeJSON(summary)
+		out = append(out, item)
+	}
+	return out, rows.Err()
+}
+
+func (api *datasetRegistryAPI) replicaLineage(ctx context.Context, versionID string) ([]replicaLineageEdge, error) {
+	rows, err := api.db.QueryContext(
+		ctx,
+		`SELECT occurred_at, subject_type, subject_id, predicate, object_type, object_id
+		 FROM lineage_events
+		 WHERE (subject_type = 'dataset_version' AND subject_id = $1)
+		    OR (object_type = 'dataset_version' AND object_id = $1)
+		 ORDER BY event_id
+		 LIMIT $2`,
+		versionID,
+		maxReplicaLineage,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []replicaLineageEdge{}
+	for rows.Next() {
+		var edge replicaLineageEdge
+		if err := rows.Scan(&edge.OccurredAt, &edge.SubjectType, &edge.SubjectID, &edge.Predicate, &edge.ObjectType, &edge.ObjectID); err != nil {
+			return nil, err
+		}
+		edge.OccurredAt = edge.OccurredAt.UTC()
+		out = append(out, edge)
+	}
+	return out, rows.Err()
+}
+
+// handleReplicateDatasetVersion stores a replica package from another
+// deployment as a new version of a local dataset. The signature is checked
+// before any data is stored and the content digest before the version is
+// created; the local quality rule and data contract apply as on upload.
+func (api *datasetRegistryAPI) handleReplicateDatasetVersion(w http.ResponseWriter, r *http.Request) {
+	datasetID := strings.TrimSpace(r.PathValue("dataset_id"))
+	if datasetID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "dataset_id_required")
+		return
+	}
+	if api.svc == nil || api.db == nil || api.store == nil {
+		api.writeError(w, r, http.StatusInternalServerError, "service_unavailable")
+		return
+	}
+	if !api.replicationCfg.enabled() {
+		api.writeError(w, r, http.StatusServiceUnavailable, "replication_not_configured")
+		return
+	}
+	projectID, ok := auth.ProjectIDFromContext(r.Context())
+	if !ok || strings.TrimSpace(projectID) == "" {
+		api.writeError(w, r, http.StatusBadRequest, "project_id_required")
+		return
+	}
+	identity, ok := auth.IdentityFromContext(r.Context())
+	if !ok || strings.TrimSpace(identity.Subject) == "" {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if _, err := api.svc.GetDataset(r.Context(), projectID, datasetID); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	settings, ok := api.activeProjectSettings(w, r, projectID)
+	if !ok {
+		return
+	}
+	lifecycle, err := api.datasetLifecycle(r.Context(), datasetID)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if lifecycle.State == domain.DatasetLifecycleArchived {
+		api.writeError(w, r, http.StatusConflict, "dataset_archived")
+		return
+	}
+	contract, err := api.activeDataContract(r.Context(), datasetID)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	now := time.Now().UTC()
+	versionID := uuid.NewString()
+
+	r.Body = http.MaxBytesReader(w, r.Body, api.uploadMaxBytes)
+	mr, err := r.MultipartReader()
+	if err != nil {
+		api.writeError(w, r, http.StatusBadRequest, "invalid_multipart")
+		return
+	}
+
+	var (
+		objectKey     string
+		manifest      replicaManifest
+		manifestJSON  []byte
+		signature     replicaSignature
+		metadataMap   map[string]any
+		filename      string
+		contentType   string
+		qualityRuleID string
+		encryption    domain.ObjectEncryption
+		head          = &headWriter{limit: dataContractHeaderBytes}
+		profiler      *dataprofile.Profiler
+	)
+	defer func() { profiler.Abort() }()
+	removeObject := func() {
+		if objectKey != "" {
+			_ = api.store.RemoveObject(r.Context(), api.storeCfg.BucketDatasets, objectKey, minio.RemoveObjectOptions{})
+		}
+	}
+
+	for {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			removeObject()
+			var maxErr *http.MaxBytesError
+			if errors.As(err, &maxErr) {
+				api.writeErrorWithDetails(w, r, http.StatusRequestEntityTooLarge, "upload_too_large", map[string]any{
+					"max_bytes":     api.uploadMaxBytes,
+					"max_mebibytes": api.uploadMaxBytes >> 20,
+				})
+				return
+			}
+			api.writeError(w, r, http.StatusBadRequest, "invalid_multipart")
+			return
+		}
+		switch part.FormName() {
+		case "quality_rule_id":
+			raw, err := io.ReadAll(io.LimitReader(part, 4096))
+			_ = part.Close()
+			if err != nil {
+				removeObject()
+				api.writeError(w, r, http.StatusBadRequest, "invalid_quality_rule_id")
+				return
+			}
+			qualityRuleID = strings.TrimSpace(string(raw))
+		case "file":
+			if objectKey != "" {
+				_ = part.Close()
+				removeObject()
+				api.writeError(w, r, http.StatusBadRequest, "multiple_files_not_supported")
+				return
+			}
+			tr := tar.NewReader(part)
+			manifest, manifestJSON, signature, err = openReplicaPackage(tr, api.replicationCfg.Secret)
+			if err != nil {
+				_ = part.Close()
+				api.writeReplicaError(w, r, err)
+				return
+			}
+			metadataMap = map[string]any{}
+			if err := json.Unmarshal(manifest.Version.Metadata, &metadataMap); err != nil || metadataMap == nil {
+				_ = part.Close()
+				api.writeReplicaError(w, r, fmt.Errorf("%w: version metadata", errReplicaPackageInvalid))
+				return
+			}
+			filename = sanitizeFilename(jsonFieldString(manifest.Version.Metadata, "filename"))
+			contentType = jsonFieldString(manifest.Version.Metadata, "content_type")
+			if contentType == "" {
+				contentType = "application/octet-stream"
+			}
+			if contract != nil && contract.Schema.observesValues() {
+				profiler = dataprofile.New(dataprofile.DetectFormat(filename, contentType))
+			}
+
+			var dataKey *envelope.ObjectKey
+			if api.encrypter.Enabled() {
+				key, err := api.encrypter.NewObjectKey(r.Context())
+				if err != nil {
+					_ = part.Close()
+					api.writeError(w, r, http.StatusBadGateway, "encryption_key_unavailable")
+					return
+				}
+				dataKey = &key
+				encryption = domain.ObjectEncryption{Algorithm: key.Algorithm, KeyID: key.KeyID, WrappedKey: key.WrappedKey}
+			}
+
+			objectKey = fmt.Sprintf("%s/%s/%s", datasetID, versionID, filename)
+			sha, size, err := api.putReplicaObject(r.Context(), objectKey, tr, contentType, dataKey, head, profiler)
+			_ = part.Close()
+			if err != nil {
+				removeObject()
+				var maxErr *http.MaxBytesError
+				if errors.As(err, &maxErr) {
+					api.writeErrorWithDetails(w, r, http.StatusRequestEntityTooLarge, "upload_too_large", map[string]any{
+						"max_bytes":     api.uploadMaxBytes,
+						"max_mebibytes": api.uploadMaxBytes >> 20,
+					})
+					return
+				}
+				api.writeError(w, r, http.StatusBadRequest, "upload_failed")
+				return
+			}
+			// The signature covers the manifest; the manifest covers the content.
+			if sha != manifest.Version.ContentSHA256 || size != manifest.Version.SizeBytes {
+				removeObject()
+				api.writeError(w, r, http.StatusUnprocessableEntity, "replica_checksum_mismatch")
+				return
+			}
+		default:
+			_ = part.Close()
+		}
+	}
+	if objectKey == "" {
+		api.writeError(w, r, http.StatusBadRequest, "file_required")
+		return
+	}
+
+	if qualityRuleID == "" {
+		qualityRuleID = settings.DefaultQualityRuleID
+	}
+	if qualityRuleID != "" {
+		var exists string
+		if err := api.db.QueryRowContext(r.Context(), `SELECT rule_id FROM quality_rules WHERE rule_id = $1`, qualityRuleID).Scan(&exists); err != nil {
+			removeObject()
+			if errors.Is(err, sql.ErrNoRows) {
+				api.writeError(w, r, http.StatusNotFound, "quality_rule_not_found")
+				return
+			}
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+	}
+
+	var contractViolations []dataContractViolation
+	if contract != nil {
+		var observedProfile *dataprofile.Profile
+		if profiler != nil {
+			if profileResult, err := profiler.Finish(); err == nil {
+				observedProfile = &profileResult
+			}
+		}
+		contractViolations = evaluateDataContract(*contract, observeDatasetVersion(metadataMap, head.buf, filename, contentType, observedProfile), now)
+	}
+
+	metadataMap["filename"] = filename
+	metadataMap["content_type"] = contentType
+	metadataMap["content_sha256"] = manifest.Version.ContentSHA256
+	metadataMap = redaction.RedactMetadata(metadataMap)
+	metadataJSON, err := json.Marshal(metadataMap)
+	if err != nil {
+		removeObject()
+		api.writeError(w, r, http.StatusBadRequest, "invalid_metadata")
+		return
+	}
+
+	ordinal, err := api.svc.NextDatasetVersionOrdinal(r.Context(), projectID, datasetID)
+	if err != nil {
+		removeObject()
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		removeObject()
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	txSvc := api.svc.withRepositories(repopg.NewDatasetStore(tx), repopg.NewAuditAppender(tx, nil))
+	version, err := txSvc.CreateDatasetVersion(r.Context(), domain.DatasetVersion{
+		ID:            versionID,
+		ProjectID:     projectID,
+		DatasetID:     datasetID,
+		QualityRuleID: qualityRuleID,
+		Ordinal:       ordinal,
+		ContentSHA256: manifest.Version.ContentSHA256,
+		ObjectKey:     objectKey,
+		SizeBytes:     manifest.Version.SizeBytes,
+		Encryption:    encryption,
+	}, metadataMap, buildAuditContext(r, identity))
+	if err != nil {
+		removeObject()
+		if isUniqueViolation(err) {
+			api.writeError(w, r, http.StatusConflict, "duplicate_content")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	replica := datasetVersionReplica{
+		ReplicaID:        uuid.NewString(),
+		VersionID:        version.ID,
+		DatasetID:        datasetID,
+		ProjectID:        projectID,
+		SourceInstanceID: manifest.Source.InstanceID,
+		SourceVersionID:  manifest.Source.VersionID,
+		Manifest:         manifestJSON,
+		ManifestSHA256:   signature.ManifestSHA256,
+		Signature:        signature.Signature,
+		SignatureAlg:     signature.SignatureAlg,
+		CreatedAt:        now,
+		CreatedBy:        identity.Subject,
+	}
+	if err := insertDatasetVersionReplica(r.Context(), tx, replica); err != nil {
+		removeObject()
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if err := enqueueReplicaLineage(r.Context(), tx, r.Header.Get("X-Request-Id"), identity.Subject, version, manifest, signature, now); err != nil {
+		removeObject()
+		api.writeError(w, r, http.StatusInternalServerError, "lineage_write_failed")
+		return
+	}
+	if err := auditlog.Enqueue(r.Context(), tx, auditlog.Event{
+		OccurredAt:   now,
+		Actor:        identity.Subject,
+		Action:       "dataset_version.replicate",
+		ResourceType: "dataset_version",
+		ResourceID:   version.ID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload: map[string]any{
+			"service":            "dataset-registry",
+			"project_id":         projectID,
+			"dataset_id":         datasetID,
+			"dataset_version_id": version.ID,
+			"replica_id":         replica.ReplicaID,
+			"source_instance_id": replica.SourceInstanceID,
+			"source_version_id":  replica.SourceVersionID,
+			"manifest_sha256":    replica.ManifestSHA256,
+			"content_sha256":     version.ContentSHA256,
+		},
+	}); err != nil {
+		removeObject()
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	err = api.bus.Enqueue(r.Context(), tx, eventbus.EventDatasetVersionCreated, version.ID, projectID, now,
+		map[string]string{"dataset_id": datasetID, "dataset_version_id": version.ID},
+		map[string]any{
+			"ordinal":        version.Ordinal,
+			"content_sha256": version.ContentSHA256,
+			"size_bytes":     version.SizeBytes,
+		},
+	)
+	if err != nil {
+		removeObject()
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		removeObject()
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	var evaluation *dataContractEvaluation
+	if contract != nil {
+		recorded, err := api.recordDataContractEvaluation(r.Context(), r, *contract, version, contractViolations, now)
+		if err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "data_contract_evaluation_failed")
+			return
+		}
+		evaluation = &recorded
+	}
+
+	w.Header().Set("Location", "/dataset-versions/"+version.ID)
+	api.writeJSON(w, http.StatusCreated, replicateDatasetVersionResponse{
+		Version: datasetVersion{
+			VersionID:              version.ID,
+			DatasetID:              version.DatasetID,
+			ProjectID:              version.ProjectID,
+			QualityRuleID:          version.QualityRuleID,
+			Ordinal:                version.Ordinal,
+			ContentSHA256:          version.ContentSHA256,
+			ObjectKey:              version.ObjectKey,
+			SizeBytes:              version.SizeBytes,
+			Metadata:               metadataJSON,
+			CreatedAt:              version.CreatedAt,
+			CreatedBy:              version.CreatedBy,
+			DataContractEvaluation: evaluation,
+			Encryption:             objectEncryptionResponse(version.Encryption),
+		},
+		Replica: replica,
+	})
+}
+
+// putReplicaObject stores the data entry, encrypted under objectKey when it
+// is set, and returns the digest and size of the plaintext.
+func (api *datasetRegistryAPI) putReplicaObject(ctx context.Context, key string, content io.Reader, contentType string, objectKey *envelope.ObjectKey, head *headWriter, profiler *dataprofile.Profiler) (string, int64, error) {
+	hasher := sha256.New()
+	counter := &countingWriter{}
+	sinks := []io.Writer{hasher, counter, head}
+	if profiler != nil {
+		sinks = append(sinks, profiler)
+	}
+	reader := io.TeeReader(content, io.MultiWriter(sinks...))
+	if objectKey != nil {
+		var err error
+		if reader, err = envelope.EncryptReader(objectKey.DataKey, reader); err != nil {
+			return "", 0, err
+		}
+		contentType = "application/octet-stream"
+	}
+
+	uploadCtx, cancel := context.WithTimeout(ctx, api.uploadTimeout)
+	defer cancel()
+	if _, err := api.store.PutObject(uploadCtx, api.storeCfg.BucketDatasets, key, reader, -1, minio.PutObjectOptions{ContentType: contentType}); err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), counter.n, nil
+}
+
+func insertDatasetVersionReplica(ctx context.Context, tx *sql.Tx, replica datasetVersionReplica) error {
+	integrity, err := integritySHA256(struct {
+		ReplicaID        string    `json:"replica_id"`
+		VersionID        string    `json:"version_id"`
+		SourceInstanceID string    `json:"source_instance_id"`
+		SourceVersionID  string    `json:"source_version_id"`
+		ManifestSHA256   string    `json:"manifest_sha256"`
+		Signature        string    `json:"signature"`
+		CreatedAt        time.Time `json:"created_at"`
+		CreatedBy        string    `json:"created_by"`
+	}{replica.ReplicaID, replica.VersionID, replica.SourceInstanceID, replica.SourceVersionID, replica.ManifestSHA256, replica.Signature, replica.CreatedAt, replica.CreatedBy})
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(
+		ctx,
+		`INSERT INTO dataset_version_replicas (replica_id, version_id, dataset_id, project_id, source_instance_id, source_version_id, manifest, manifest_sha256, signature, signature_alg, created_at, created_by, integrity_sha256)
+		 VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13)`,
+		replica.ReplicaID,
+		replica.VersionID,
+		replica.DatasetID,
+		replica.ProjectID,
+		replica.SourceInstanceID,
+		replica.SourceVersionID,
+		string(replica.Manifest),
+		replica.ManifestSHA256,
+		replica.Signature,
+		replica.SignatureAlg,
+		replica.CreatedAt,
+		replica.CreatedBy,
+		integrity,
+	)
+	return err
+}
+
+// enqueueReplicaLineage records the version under its dataset and links it
+// to a stub node for the source version; the source's own edges travel in
+// the manifest.
+func enqueueReplicaLineage(ctx context.Context, tx *sql.Tx, requestID, actor string, version domain.DatasetVersion, manifest replicaManifest, signature replicaSignature, now time.Time) error {
+	err := lineageevent.Enqueue(ctx, tx, lineageevent.Event{
+		OccurredAt:  now,
+		Actor:       actor,
+		RequestID:   requestID,
+		SubjectType: "dataset",
+		SubjectID:   version.DatasetID,
+		Predicate:   "has_version",
+		ObjectType:  "dataset_version",
+		ObjectID:    version.ID,
+		Metadata: map[string]any{
+			"ordinal":         version.Ordinal,
+			"content_sha256":  version.ContentSHA256,
+			"quality_rule_id": version.QualityRuleID,
+			"size_bytes":      version.SizeBytes,
+			"object_key":      version.ObjectKey,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return lineageevent.Enqueue(ctx, tx, lineageevent.Event{
+		OccurredAt:  now,
+		Actor:       actor,
+		RequestID:   requestID,
+		SubjectType: "dataset_version",
+		SubjectID:   version.ID,
+		Predicate:   "replicated_from",
+		ObjectType:  "external_dataset_version",
+		ObjectID:    manifest.Source.InstanceID + "/" + manifest.Source.VersionID,
+		Metadata: map[string]any{
+			"source_instance_id": manifest.Source.InstanceID,
+			"source_project_id":  manifest.Source.ProjectID,
+			"source_dataset_id":  manifest.Source.DatasetID,
+			"source_version_id":  manifest.Source.VersionID,
+			"source_ordinal":     manifest.Source.Ordinal,
+			"content_sha256":     manifest.Version.ContentSHA256,
+			"manifest_sha256":    signature.ManifestSHA256,
+			"exported_at":        manifest.ExportedAt.Format(time.RFC3339Nano),
+		},
+	})
+}
+
+func (api *datasetRegistryAPI) writeReplicaError(w http.ResponseWriter, r *http.Request, err error) {
+	var maxErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxErr):
+		api.writeErrorWithDetails(w, r, http.StatusRequestEntityTooLarge, "upload_too_large", map[string]any{
+			"max_bytes":     api.uploadMaxBytes,
+			"max_mebibytes": api.uploadMaxBytes >> 20,
+		})
+	case errors.Is(err, errReplicaSignatureInvalid):
+		api.writeError(w, r, http.StatusUnprocessableEntity, "replica_signature_invalid")
+	default:
+		api.writeError(w, r, http.StatusUnprocessableEntity, "invalid_replica_package")
+	}
+}
+
+func (api *datasetRegistryAPI) handleGetDatasetVersionReplica(w http.ResponseWriter, r *http.Request) {
+	versionID := strings.TrimSpace(r.PathValue("version_id"))
+	if versionID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "version_id_required")
+		return
+	}
+	if api.db == nil {
+		api.writeError(w, r, http.StatusInternalServerError, "service_unavailable")
+		return
+	}
+	projectID, ok := auth.ProjectIDFromContext(r.Context())
+	if !ok || strings.TrimSpace(projectID) == "" {
+		api.writeError(w, r, http.StatusBadRequest, "project_id_required")
+		return
+	}
+
+	var (
+		replica  datasetVersionReplica
+		manifest string
+	)
+	err := api.db.QueryRowContext(
+		r.Context(),
+		`SELECT replica_id, version_id, dataset_id, project_id, source_instance_id, source_version_id, manifest, manifest_sha256, signature, signature_alg, created_at, created_by
+		 FROM dataset_version_replicas
+		 WHERE version_id = $1 AND project_id = $2`,
+		versionID,
+		projectID,
+	).Scan(&replica.ReplicaID, &replica.VersionID, &replica.DatasetID, &replica.ProjectID, &replica.SourceInstanceID, &replica.SourceVersionID, &manifest, &replica.ManifestSHA256, &replica.Signature, &replica.SignatureAlg, &replica.CreatedAt, &replica.CreatedBy)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	replica.Manifest = json.RawMessage(manifest)
+	replica.CreatedAt = replica.CreatedAt.UTC()
+	api.writeJSON(w, http.StatusOK, replica)
+}
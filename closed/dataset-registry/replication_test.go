@@ -0,0 +1,68 @@
+package datasetregistry
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testReplicaPackage(t *testing.T, cfg replicationConfig, content string) []byte {
+	t.Helper()
+	sum := sha256.Sum256([]byte(content))
+	manifest := replicaManifest{
+		Schema: replicaSchema,
+		Source: replicaSource{InstanceID: cfg.InstanceID, ProjectID: "p-1", DatasetID: "ds-1", VersionID: "v-1", Ordinal: 3},
+		Version: replicaVersion{
+			ContentSHA256: hex.EncodeToString(sum[:]),
+			SizeBytes:     int64(len(content)),
+			Metadata:      []byte(`{"filename":"train.csv"}`),
+		},
+	}
+	payload, signature, err := newReplicaPackage(manifest, cfg)
+	if err != nil {
+		t.Fatalf("newReplicaPackage: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := writeReplicaPackage(&buf, payload, signature, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), strings.NewReader(content), int64(len(content))); err != nil {
+		t.Fatalf("writeReplicaPackage: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestReplicaPackageRoundTrip(t *testing.T) {
+	cfg := replicationConfig{InstanceID: "research", Secret: "shared"}
+	tr := tar.NewReader(bytes.NewReader(testReplicaPackage(t, cfg, "a,b\n1,2\n")))
+	manifest, _, signature, err := openReplicaPackage(tr, cfg.Secret)
+	if err != nil {
+		t.Fatalf("openReplicaPackage: %v", err)
+	}
+	if manifest.Source.VersionID != "v-1" || signature.InstanceID != "research" {
+		t.Fatalf("manifest = %+v, signature = %+v", manifest, signature)
+	}
+	data, err := io.ReadAll(tr)
+	if err != nil || string(data) != "a,b\n1,2\n" {
+		t.Fatalf("data = %q, %v", data, err)
+	}
+}
+
+func TestReplicaPackageRejectsForeignSignature(t *testing.T) {
+	pkg := testReplicaPackage(t, replicationConfig{InstanceID: "research", Secret: "shared"}, "x")
+	if _, _, _, err := openReplicaPackage(tar.NewReader(bytes.NewReader(pkg)), "other"); !errors.Is(err, errReplicaSignatureInvalid) {
+		t.Fatalf("err = %v, want signature invalid", err)
+	}
+
+	// A modified manifest no longer matches the signed digest.
+	tampered := bytes.Replace(pkg, []byte(`"ordinal":3`), []byte(`"ordinal":4`), 1)
+	if bytes.Equal(tampered, pkg) {
+		t.Fatal("manifest not found in package")
+	}
+	if _, _, _, err := openReplicaPackage(tar.NewReader(bytes.NewReader(tampered)), "shared"); !errors.Is(err, errReplicaSignatureInvalid) {
+		t.Fatalf("err = %v, want signature invalid", err)
+	}
+}
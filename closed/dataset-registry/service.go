@@ -1,4 +1,4 @@
-package main
+package datasetregistry
 
 import (
 	"context"
@@ -38,6 +38,15 @@ func newDatasetService(projects repo.ProjectRepository, datasets repo.DatasetRep
 	}
 }
 
+// withRepositories returns a copy of s that writes through datasets and
+// audit, e.g. stores bound to a transaction.
+func (s *datasetService) withRepositories(datasets repo.DatasetRepository, audit repo.AuditEventAppender) *datasetService {
+	clone := *s
+	clone.datasets = datasets
+	clone.audit = audit
+	return &clone
+}
+
 func (s *datasetService) CreateProject(ctx context.Context, projectID string, name string, description string, metadata map[string]any, auditCtx auditContext) (domain.Project, error) {
 	if s == nil || s.projects == nil {
 		return domain.Project{}, fmt.Errorf("project service not initialized")
@@ -52,41 +61,21 @@ func (s *datasetService) CreateProject(ctx context.Context, projectID string, na
 	if metadata == nil {
 		metadata = map[string]any{}
 	}
-	metadataJSON, err := json.Marshal(metadata)
-	if err != nil {
-		return domain.Project{}, fmt.Errorf("invalid metadata: %w", err)
-	}
 
 	now := s.now().UTC()
-	type integrityInput struct {
-		ProjectID   string          `json:"project_id"`
-		Name        string          `json:"name"`
-		Description string          `json:"description,omitempty"`
-		Metadata    json.RawMessage `json:"metadata"`
-		CreatedAt   time.Time       `json:"created_at"`
-		CreatedBy   string          `json:"created_by"`
-	}
-	integrity, err := integritySHA256(integrityInput{
-		ProjectID:   projectID,
+	project := domain.Project{
+		ID:          projectID,
 		Name:        name,
-		Description: description,
-		Metadata:    metadataJSON,
+		Description: strings.TrimSpace(description),
+		Metadata:    domain.Metadata(metadata),
 		CreatedAt:   now,
 		CreatedBy:   auditCtx.Actor,
-	})
+	}
+	integrity, err := projectIntegrity(project)
 	if err != nil {
 		return domain.Project{}, fmt.Errorf("integrity: %w", err)
 	}
-
-	project := domain.Project{
-		ID:              projectID,
-		Name:            name,
-		Description:     strings.TrimSpace(description),
-		Metadata:        domain.Metadata(metadata),
-		CreatedAt:       now,
-		CreatedBy:       auditCtx.Actor,
-		IntegritySHA256: integrity,
-	}
+	project.IntegritySHA256 = integrity
 	if err := s.projects.Create(ctx, project); err != nil {
 		return domain.Project{}, err
 	}
@@ -121,7 +110,7 @@ func (s *datasetService) GetProject(ctx context.Context, projectID string) (doma
 	return s.projects.Get(ctx, projectID)
 }
 
-func (s *datasetService) ListProjects(ctx context.Context, createdBy string, limit int) ([]domain.Project, error) {
+func (s *datasetService) ListProjects(ctx context.Context, createdBy string, includeArchived bool, limit int) ([]domain.Project, error) {
 	if s == nil || s.projects == nil {
 		return nil, fmt.Errorf("project service not initialized")
 	}
@@ -132,7 +121,107 @@ func (s *datasetService) ListProjects(ctx context.Context, createdBy string, lim
 	if limit <= 0 {
 		limit = 100
 	}
-	return s.projects.List(ctx, repo.ProjectFilter{CreatedBy: createdBy, Limit: limit})
+	return s.projects.List(ctx, repo.ProjectFilter{CreatedBy: createdBy, IncludeArchived: includeArchived, Limit: limit})
+}
+
+// UpdateProject applies the changed fields of an active project and
+// recomputes its integrity hash.
+func (s *datasetService) UpdateProject(ctx context.Context, project domain.Project, auditCtx auditContext) (domain.Project, error) {
+	if s == nil || s.projects == nil {
+		return domain.Project{}, fmt.Errorf("project service not initialized")
+	}
+	now := s.now().UTC()
+	project.UpdatedAt = &now
+	project.UpdatedBy = auditCtx.Actor
+	integrity, err := projectIntegrity(project)
+	if err != nil {
+		return domain.Project{}, fmt.Errorf("integrity: %w", err)
+	}
+	project.IntegritySHA256 = integrity
+	if err := s.projects.Update(ctx, project); err != nil {
+		return domain.Project{}, err
+	}
+	s.appendProjectAudit(ctx, now, "project.update", project.ID, auditCtx, map[string]any{
+		"name":        project.Name,
+		"description": project.Description,
+		"metadata":    map[string]any(project.Metadata),
+	})
+	return project, nil
+}
+
+// ArchiveProject soft-deletes an active project.
+func (s *datasetService) ArchiveProject(ctx context.Context, project domain.Project, auditCtx auditContext) (domain.Project, error) {
+	if s == nil || s.projects == nil {
+		return domain.Project{}, fmt.Errorf("project service not initialized")
+	}
+	now := s.now().UTC()
+	if err := s.projects.Archive(ctx, project.ID, auditCtx.Actor, now); err != nil {
+		return domain.Project{}, err
+	}
+	project.ArchivedAt = &now
+	project.ArchivedBy = auditCtx.Actor
+	s.appendProjectAudit(ctx, now, "project.archive", project.ID, auditCtx, map[string]any{
+		"name": project.Name,
+	})
+	return project, nil
+}
+
+func (s *datasetService) appendProjectAudit(ctx context.Context, now time.Time, action string, projectID string, auditCtx auditContext, fields map[string]any) {
+	if s.audit == nil {
+		return
+	}
+	payload := map[string]any{
+		"service":      auditCtx.Service,
+		"project_id":   projectID,
+		"request_path": auditCtx.Path,
+	}
+	for k, v := range fields {
+		payload[k] = v
+	}
+	_, _ = s.audit.Append(ctx, domain.AuditEvent{
+		OccurredAt:   now,
+		Actor:        auditCtx.Actor,
+		Action:       action,
+		ResourceType: "project",
+		ResourceID:   projectID,
+		RequestID:    auditCtx.RequestID,
+		IP:           auditCtx.IP,
+		UserAgent:    auditCtx.UserAgent,
+		Payload:      payload,
+	})
+}
+
+// projectIntegrity hashes the project fields; updated_* are omitted until the
+// first update so hashes of never-updated projects stay unchanged.
+func projectIntegrity(project domain.Project) (string, error) {
+	metadata := map[string]any(project.Metadata)
+	if metadata == nil {
+		metadata = map[string]any{}
+	}
+	metadataJSON, err := json.Marshal(metadata)
+	if err != nil {
+		return "", fmt.Errorf("invalid metadata: %w", err)
+	}
+	type integrityInput struct {
+		ProjectID   string          `json:"project_id"`
+		Name        string          `json:"name"`
+		Description string          `json:"description,omitempty"`
+		Metadata    json.RawMessage `json:"metadata"`
+		CreatedAt   time.Time       `json:"created_at"`
+		CreatedBy   string          `json:"created_by"`
+		UpdatedAt   *time.Time      `json:"updated_at,omitempty"`
+		UpdatedBy   string          `json:"updated_by,omitempty"`
+	}
+	return integritySHA256(integrityInput{
+		ProjectID:   project.ID,
+		Name:        project.Name,
+		Description: project.Description,
+		Metadata:    metadataJSON,
+		CreatedAt:   project.CreatedAt,
+		CreatedBy:   project.CreatedBy,
+		UpdatedAt:   project.UpdatedAt,
+		UpdatedBy:   project.UpdatedBy,
+	})
 }
 
 func (s *datasetService) CreateDataset(ctx context.Context, projectID string, name string, description string, metadata map[string]any, auditCtx auditContext) (domain.Dataset, error) {
@@ -263,6 +352,11 @@ func (s *datasetService) CreateDatasetVersion(ctx context.Context, version domai
 		Metadata      json.RawMessage `json:"metadata"`
 		CreatedAt     time.Time       `json:"created_at"`
 		CreatedBy     string          `json:"created_by"`
+		// Encryption fields are omitted for plaintext versions so their hashes
+		// are unchanged.
+		EncryptionAlg        string `json:"encryption_alg,omitempty"`
+		EncryptionKeyID      string `json:"encryption_key_id,omitempty"`
+		EncryptionWrappedKey string `json:"encryption_wrapped_key,omitempty"`
 	}
 	integrity, err := integritySHA256(integrityInput{
 		VersionID:     version.ID,
@@ -276,6 +370,10 @@ func (s *datasetService) CreateDatasetVersion(ctx context.Context, version domai
 		Metadata:      metadataJSON,
 		CreatedAt:     now,
 		CreatedBy:     auditCtx.Actor,
+
+		EncryptionAlg:        version.Encryption.Algorithm,
+		EncryptionKeyID:      version.Encryption.KeyID,
+		EncryptionWrappedKey: version.Encryption.WrappedKey,
 	})
 	if err != nil {
 		return domain.DatasetVersion{}, fmt.Errorf("integrity: %w", err)
@@ -290,6 +388,23 @@ func (s *datasetService) CreateDatasetVersion(ctx context.Context, version domai
 		return domain.DatasetVersion{}, err
 	}
 	if s.audit != nil {
+		payload := map[string]any{
+			"service":            auditCtx.Service,
+			"project_id":         version.ProjectID,
+			"dataset_id":         version.DatasetID,
+			"dataset_version_id": version.ID,
+			"quality_rule_id":    version.QualityRuleID,
+			"ordinal":            version.Ordinal,
+			"content_sha256":     version.ContentSHA256,
+			"object_key":         version.ObjectKey,
+			"size_bytes":         version.SizeBytes,
+			"metadata":           metadata,
+			"request_path":       auditCtx.Path,
+		}
+		if version.Encryption.Enabled() {
+			payload["encryption_alg"] = version.Encryption.Algorithm
+			payload["encryption_key_id"] = version.Encryption.KeyID
+		}
 		_, _ = s.audit.Append(ctx, domain.AuditEvent{
 			OccurredAt:   now,
 			Actor:        auditCtx.Actor,
@@ -299,19 +414,7 @@ func (s *datasetService) CreateDatasetVersion(ctx context.Context, version domai
 			RequestID:    auditCtx.RequestID,
 			IP:           auditCtx.IP,
 			UserAgent:    auditCtx.UserAgent,
-			Payload: map[string]any{
-				"service":            auditCtx.Service,
-				"project_id":         version.ProjectID,
-				"dataset_id":         version.DatasetID,
-				"dataset_version_id": version.ID,
-				"quality_rule_id":    version.QualityRuleID,
-				"ordinal":            version.Ordinal,
-				"content_sha256":     version.ContentSHA256,
-				"object_key":         version.ObjectKey,
-				"size_bytes":         version.SizeBytes,
-				"metadata":           metadata,
-				"request_path":       auditCtx.Path,
-			},
+			Payload:      payload,
 		})
 	}
 	return version, nil
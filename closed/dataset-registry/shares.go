@@ -0,0 +1,383 @@
+package datasetregistry
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/domain"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+	"github.com/google/uuid"
+)
+
+// shareRedeemPrefix is served without authentication: the token in the path
+// is the credential.
+const shareRedeemPrefix = "/dataset-shares/"
+
+// shareRedirectTTL bounds the presigned URL handed out on redemption; the
+// object store only checks it when the download starts.
+const shareRedirectTTL = time.Minute
+
+type shareConfig struct {
+	DefaultTTL time.Duration
+	MaxTTL     time.Duration
+	// PublicBaseURL prefixes one-time redemption links, e.g. the gateway URL.
+	PublicBaseURL string
+}
+
+type createDatasetVersionShareRequest struct {
+	TTLSeconds int64 `json:"ttl_seconds,omitempty"`
+	OneTime    bool  `json:"one_time,omitempty"`
+}
+
+type datasetVersionShare struct {
+	ShareID   string    `json:"share_id"`
+	DatasetID string    `json:"dataset_id"`
+	VersionID string    `json:"version_id"`
+	URL       string    `json:"url"`
+	OneTime   bool      `json:"one_time"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+	CreatedBy string    `json:"created_by"`
+}
+
+func (api *datasetRegistryAPI) registerShares(mux *http.ServeMux) {
+	mux.HandleFunc("POST /datasets/{dataset_id}/versions/{version_id}/share", api.handleCreateDatasetVersionShare)
+	mux.HandleFunc("GET "+shareRedeemPrefix+"{token}", api.handleRedeemDatasetVersionShare)
+}
+
+// shareTTL resolves the requested lifetime; zero selects the default.
+func (c shareConfig) shareTTL(seconds int64) (time.Duration, bool) {
+	if seconds == 0 {
+		return c.DefaultTTL, true
+	}
+	ttl := time.Duration(seconds) * time.Second
+	if seconds < 0 || ttl > c.MaxTTL {
+		return 0, false
+	}
+	return ttl, true
+}
+
+func newShareToken() (string, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+	return token, shareTokenHash(token), nil
+}
+
+func shareTokenHash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func (c shareConfig) redeemURL(token string) string {
+	return strings.TrimRight(c.PublicBaseURL, "/") + "/share" + shareRedeemPrefix + token
+}
+
+// presignDatasetVersion asks the object store for a GET URL that downloads
+// the version under its original filename.
+func (api *datasetRegistryAPI) presignDatasetVersion(r *http.Request, version domain.DatasetVersion, ttl time.Duration) (string, error) {
+	bucket, key, readOpts, err := api.datasetObjectReadOptions(r.Context(), version)
+	if err != nil {
+		return "", err
+	}
+	params := url.Values{}
+	if filename, _ := version.Metadata["filename"].(string); strings.TrimSpace(filename) != "" {
+		params.Set("response-content-disposition", fmt.Sprintf("attachment; filename=%q", strings.TrimSpace(filename)))
+	}
+	if contentType, _ := version.Metadata["content_type"].(string); strings.TrimSpace(contentType) != "" {
+		params.Set("response-content-type", strings.TrimSpace(contentType))
+	}
+	// A presigned URL cannot carry If-Match; in versioned buckets the
+	// reference is still pinned to its registered version.
+	if readOpts.VersionID != "" {
+		params.Set("versionId", readOpts.VersionID)
+	}
+	u, err := api.store.PresignedGetObject(r.Context(), bucket, key, ttl, params)
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+func (api *datasetRegistryAPI) handleCreateDatasetVersionShare(w http.ResponseWriter, r *http.Request) {
+	versionID := strings.TrimSpace(r.PathValue("version_id"))
+	if versionID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "version_id_required")
+		return
+	}
+	if api.svc == nil || api.db == nil || api.store == nil {
+		api.writeError(w, r, http.StatusInternalServerError, "service_unavailable")
+		return
+	}
+	identity, ok := auth.IdentityFromContext(r.Context())
+	if !ok || strings.TrimSpace(identity.Subject) == "" {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	projectID, ok := auth.ProjectIDFromContext(r.Context())
+	if !ok || strings.TrimSpace(projectID) == "" {
+		api.writeError(w, r, http.StatusBadRequest, "project_id_required")
+		return
+	}
+	var req createDatasetVersionShareRequest
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
+		return
+	}
+	ttl, ok := api.shareCfg.shareTTL(req.TTLSeconds)
+	if !ok {
+		api.writeError(w, r, http.StatusBadRequest, "ttl_invalid")
+		return
+	}
+
+	version, err := api.svc.GetDatasetVersion(r.Context(), projectID, versionID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if version.DatasetID != strings.TrimSpace(r.PathValue("dataset_id")) {
+		api.writeError(w, r, http.StatusNotFound, "not_found")
+		return
+	}
+	// A presigned URL bypasses the registry, so it would hand out ciphertext.
+	if version.Encryption.Enabled() {
+		api.writeError(w, r, http.StatusConflict, "encrypted_version_not_shareable")
+		return
+	}
+
+	now := time.Now().UTC()
+	access, ok := api.datasetAccessGate(w, r, identity, version.DatasetID, version.ID, now)
+	if !ok {
+		return
+	}
+	if !api.datasetVersionGate(w, r, identity, version, now) {
+		return
+	}
+	// A share never outlives the grant it was created under.
+	var accessGrantID string
+	if access.Grant != nil {
+		ttl = min(ttl, access.Grant.ExpiresAt.Sub(now))
+		accessGrantID = access.Grant.GrantID
+	}
+
+	share := datasetVersionShare{
+		ShareID:   uuid.NewString(),
+		DatasetID: version.DatasetID,
+		VersionID: version.ID,
+		OneTime:   req.OneTime,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+		CreatedBy: identity.Subject,
+	}
+	var tokenHash string
+	if req.OneTime {
+		token, hash, err := newShareToken()
+		if err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+		share.URL = api.shareCfg.redeemURL(token)
+		tokenHash = hash
+	} else {
+		share.URL, err = api.presignDatasetVersion(r, version, ttl)
+		if err != nil {
+			api.writeError(w, r, http.StatusBadGateway, "object_store_error")
+			return
+		}
+	}
+
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(
+		r.Context(),
+		`INSERT INTO dataset_version_shares (share_id, version_id, dataset_id, project_id, one_time, token_sha256, expires_at, created_at, created_by, access_grant_id, access_bypass)
+		 VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)`,
+		share.ShareID,
+		share.VersionID,
+		share.DatasetID,
+		projectID,
+		share.OneTime,
+		nullString(tokenHash),
+		share.ExpiresAt,
+		now,
+		identity.Subject,
+		nullString(accessGrantID),
+		access.Restricted && access.Bypass,
+	); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if err := auditlog.Enqueue(r.Context(), tx, auditlog.Event{
+		OccurredAt:   now,
+		Actor:        identity.Subject,
+		Action:       "dataset_version.share_create",
+		ResourceType: "dataset_version",
+		ResourceID:   version.ID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload: map[string]any{
+			"service":            "dataset-registry",
+			"project_id":         projectID,
+			"dataset_id":         version.DatasetID,
+			"dataset_version_id": version.ID,
+			"share_id":           share.ShareID,
+			"one_time":           share.OneTime,
+			"expires_at":         share.ExpiresAt.Format(time.RFC3339Nano),
+			"access_grant_id":    accessGrantID,
+		},
+	}); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	api.writeJSON(w, http.StatusCreated, share)
+}
+
+// handleRedeemDatasetVersionShare consumes a one-time share and redirects to
+// a short-lived presigned URL. The gate is evaluated again, so a version
+// recalled after sharing can no longer be fetched.
+func (api *datasetRegistryAPI) handleRedeemDatasetVersionShare(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimSpace(r.PathValue("token"))
+	if token == "" || api.svc == nil || api.db == nil || api.store == nil {
+		api.writeError(w, r, http.StatusNotFound, "share_not_found")
+		return
+	}
+	tokenHash := shareTokenHash(token)
+
+	var (
+		shareID       string
+		versionID     string
+		projectID     string
+		expiresAt     time.Time
+		redeemedAt    sql.NullTime
+		accessGrantID sql.NullString
+		accessBypass  bool
+	)
+	err := api.db.QueryRowContext(
+		r.Context(),
+		`SELECT share_id, version_id, project_id, expires_at, redeemed_at, access_grant_id, access_bypass
+		 FROM dataset_version_shares
+		 WHERE token_sha256 = $1`,
+		tokenHash,
+	).Scan(&shareID, &versionID, &projectID, &expiresAt, &redeemedAt, &accessGrantID, &accessBypass)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			api.writeError(w, r, http.StatusNotFound, "share_not_found")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	now := time.Now().UTC()
+	if redeemedAt.Valid || !now.Before(expiresAt) {
+		api.writeError(w, r, http.StatusGone, "share_unavailable")
+		return
+	}
+
+	version, err := api.svc.GetDatasetVersion(r.Context(), projectID, versionID)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	actor := auth.Identity{Subject: "dataset-share:" + shareID}
+	allowed, err := api.datasetShareAccessAllowed(r.Context(), version.DatasetID, accessGrantID.String, accessBypass, now)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if !allowed {
+		api.denyDatasetAccess(w, r, actor.Subject, version.DatasetID, version.ID, now)
+		return
+	}
+	if !api.datasetVersionGate(w, r, actor, version, now) {
+		return
+	}
+
+	ttl := min(shareRedirectTTL, expiresAt.Sub(now))
+	location, err := api.presignDatasetVersion(r, version, ttl)
+	if err != nil {
+		api.writeError(w, r, http.StatusBadGateway, "object_store_error")
+		return
+	}
+
+	// Consuming the share and its audit event commit together.
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.ExecContext(
+		r.Context(),
+		`UPDATE dataset_version_shares
+		 SET redeemed_at = $2
+		 WHERE token_sha256 = $1 AND redeemed_at IS NULL AND expires_at > $2`,
+		tokenHash,
+		now,
+	)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		api.writeError(w, r, http.StatusGone, "share_unavailable")
+		return
+	}
+
+	if err := auditlog.Enqueue(r.Context(), tx, auditlog.Event{
+		OccurredAt:   now,
+		Actor:        actor.Subject,
+		Action:       "dataset_version.share_redeem",
+		ResourceType: "dataset_version",
+		ResourceID:   version.ID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload: map[string]any{
+			"service":            "dataset-registry",
+			"project_id":         projectID,
+			"dataset_id":         version.DatasetID,
+			"dataset_version_id": version.ID,
+			"share_id":           shareID,
+		},
+	}); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "audit_write_failed")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	http.Redirect(w, r, location, http.StatusFound)
+}
@@ -0,0 +1,36 @@
+package datasetregistry
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestShareTTL(t *testing.T) {
+	cfg := shareConfig{DefaultTTL: time.Hour, MaxTTL: 24 * time.Hour}
+	if ttl, ok := cfg.shareTTL(0); !ok || ttl != time.Hour {
+		t.Fatalf("default ttl = %s, %v", ttl, ok)
+	}
+	if ttl, ok := cfg.shareTTL(600); !ok || ttl != 10*time.Minute {
+		t.Fatalf("ttl = %s, %v", ttl, ok)
+	}
+	for _, seconds := range []int64{-1, int64(25 * time.Hour / time.Second)} {
+		if _, ok := cfg.shareTTL(seconds); ok {
+			t.Fatalf("expected ttl_seconds=%d to be rejected", seconds)
+		}
+	}
+}
+
+func TestShareToken(t *testing.T) {
+	token, hash, err := newShareToken()
+	if err != nil {
+		t.Fatalf("newShareToken: %v", err)
+	}
+	if hash != shareTokenHash(token) || strings.Contains(hash, token) {
+		t.Fatalf("unexpected hash %q for token %q", hash, token)
+	}
+	cfg := shareConfig{PublicBaseURL: "https://animus.example.com/"}
+	if got, want := cfg.redeemURL(token), "https://animus.example.com/share/dataset-shares/"+token; got != want {
+		t.Fatalf("redeemURL = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,546 @@
+package experiments
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	"github.com/animus-labs/animus-go/closed/internal/platform/policy"
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultBootstrapProjectName = "default"
+	maxBootstrapProjectNameLen  = 64
+)
+
+// bootstrapQualityRuleSpec is the demo rule of open/cmd/demo: a non-empty CSV
+// with the demo columns and a verified checksum.
+var bootstrapQualityRuleSpec = map[string]any{
+	"schema": "animus.quality.rule.v1",
+	"checks": []any{
+		map[string]any{"id": "size", "type": "object_size_bytes", "min_bytes": 1},
+		map[string]any{"id": "content", "type": "content_type_in", "allowed": []string{"text/csv"}},
+		map[string]any{"id": "suffix", "type": "filename_suffix_in", "allowed": []string{".csv"}},
+		map[string]any{"id": "meta", "type": "metadata_required_keys", "keys": []string{"source"}},
+		map[string]any{"id": "csv", "type": "csv_header_has_columns", "columns": []string{"feature1", "feature2", "label"}, "delimiter": ","},
+		map[string]any{"id": "sha", "type": "verify_content_sha256"},
+	},
+}
+
+// bootstrapPolicySpec denies training on recalled or stale datasets and
+// allows everything else.
+const bootstrapPolicySpec = `schema: animus.policy.v1
+default_effect: allow
+rules:
+  - id: deny-recalled-dataset
+    description: Recalled dataset versions must not be used for training.
+    effect: deny
+    when:
+      all:
+        - field: dataset.recalled
+          op: eq
+          value: "true"
+  - id: deny-stale-dataset
+    description: Datasets past their freshness expectation must be refreshed first.
+    effect: deny
+    when:
+      all:
+        - field: dataset.stale
+          op: eq
+          value: "true"
+`
+
+const bootstrapExperimentDescription = "Sample experiment created by POST /admin/bootstrap. " +
+	"Upload a dataset version to the project (it inherits the default quality rule), " +
+	"evaluate it and register runs against it with POST /experiments/{experiment_id}/runs."
+
+type bootstrapRequest struct {
+	ProjectName string `json:"project_name,omitempty"`
+}
+
+// bootstrapResponse names the provisioned objects; Created lists the kinds
+// this call created, empty when everything already existed.
+type bootstrapResponse struct {
+	ProjectID       string   `json:"project_id"`
+	QualityRuleID   string   `json:"quality_rule_id"`
+	PolicyID        string   `json:"policy_id"`
+	PolicyVersionID string   `json:"policy_version_id"`
+	ExperimentID    string   `json:"experiment_id"`
+	Created         []string `json:"created"`
+}
+
+// bootstrapNames derives the names of the provisioned objects from the
+// project name, so that repeated calls find what earlier calls created.
+type bootstrapNames struct {
+	Project     string
+	QualityRule string
+	Policy      string
+	Experiment  string
+}
+
+func newBootstrapNames(projectName string) bootstrapNames {
+	return bootstrapNames{
+		Project:     projectName,
+		QualityRule: projectName + "-quality",
+		Policy:      projectName + "-baseline",
+		Experiment:  projectName + "-sample",
+	}
+}
+
+// handleAdminBootstrap provisions a project with a demo quality rule set as
+// its default, a baseline policy and a sample experiment. Objects are found
+// by name, so the call is idempotent: existing ones are returned untouched.
+func (api *experimentsAPI) handleAdminBootstrap(w http.ResponseWriter, r *http.Request) {
+	identity, ok := auth.IdentityFromContext(r.Context())
+	if !ok || strings.TrimSpace(identity.Subject) == "" {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	var req bootstrapRequest
+	if err := httpapi.DecodeJSON(r, &req); err != nil && !errors.Is(err, io.EOF) {
+		httpapi.WriteDecodeError(w, r, err)
+		return
+	}
+	projectName := strings.TrimSpace(req.ProjectName)
+	if projectName == "" {
+		projectName = defaultBootstrapProjectName
+	}
+	if len(projectName) > maxBootstrapProjectNameLen {
+		api.writeError(w, r, http.StatusBadRequest, "project_name_too_long")
+		return
+	}
+	names := newBootstrapNames(projectName)
+
+	specJSON, specSHA, err := bootstrapPolicySpecJSON()
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	b := bootstrapper{ctx: r.Context(), tx: tx, r: r, runs: api.runStore(tx), actor: identity.Subject, now: time.Now().UTC()}
+	out := bootstrapResponse{Created: []string{}}
+	fail := func(err error) {
+		var conflict bootstrapConflict
+		switch {
+		case errors.As(err, &conflict):
+			api.writeError(w, r, http.StatusConflict, string(conflict))
+		case isUniqueViolation(err), errors.Is(err, repo.ErrConflict):
+			// A concurrent bootstrap won the race; retrying returns its objects.
+			api.writeError(w, r, http.StatusConflict, "bootstrap_in_progress")
+		default:
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		}
+	}
+	record := func(kind string, created bool) {
+		if created {
+			out.Created = append(out.Created, kind)
+		}
+	}
+
+	var created bool
+	if out.ProjectID, created, err = b.ensureProject(names.Project); err != nil {
+		fail(err)
+		return
+	}
+	record("project", created)
+	if out.QualityRuleID, created, err = b.ensureQualityRule(names.QualityRule); err != nil {
+		fail(err)
+		return
+	}
+	record("quality_rule", created)
+	if created, err = b.ensureProjectSettings(out.ProjectID, out.QualityRuleID); err != nil {
+		fail(err)
+		return
+	}
+	record("project_settings", created)
+	if out.PolicyID, out.PolicyVersionID, created, err = b.ensurePolicy(names.Policy, specJSON, specSHA); err != nil {
+		fail(err)
+		return
+	}
+	record("policy", created)
+	if out.ExperimentID, created, err = b.ensureExperiment(out.ProjectID, names.Experiment, out.QualityRuleID, out.PolicyID); err != nil {
+		fail(err)
+		return
+	}
+	record("experiment", created)
+
+	err = auditlog.Enqueue(r.Context(), tx, auditlog.Event{
+		OccurredAt:   b.now,
+		Actor:        identity.Subject,
+		Action:       "admin.bootstrap",
+		ResourceType: "project",
+		ResourceID:   out.ProjectID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload: map[string]any{
+			"service":           "experiments",
+			"project_id":        out.ProjectID,
+			"quality_rule_id":   out.QualityRuleID,
+			"policy_id":         out.PolicyID,
+			"policy_version_id": out.PolicyVersionID,
+			"experiment_id":     out.ExperimentID,
+			"created":           out.Created,
+		},
+	})
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "audit_failed")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	status := http.StatusOK
+	if len(out.Created) > 0 {
+		status = http.StatusCreated
+	}
+	api.writeJSON(w, status, out)
+}
+
+func bootstrapPolicySpecJSON() ([]byte, string, error) {
+	spec, err := policy.ParseSpec([]byte(bootstrapPolicySpec))
+	if err != nil {
+		return nil, "", err
+	}
+	specJSON, err := json.Marshal(spec)
+	if err != nil {
+		return nil, "", err
+	}
+	return specJSON, sha256HexBytes(specJSON), nil
+}
+
+// bootstrapConflict is an error code for an object that exists under the
+// bootstrap name but cannot be reused.
+type bootstrapConflict string
+
+func (c bootstrapConflict) Error() string { return string(c) }
+
+type bootstrapper struct {
+	ctx   context.Context
+	tx    *sql.Tx
+	r     *http.Request
+	runs  repo.RunStore
+	actor string
+	now   time.Time
+}
+
+func (b bootstrapper) audit(action, resourceType, resourceID string, payload map[string]any) error {
+	payload["service"] = "experiments"
+	payload["bootstrap"] = true
+	err := auditlog.Enqueue(b.ctx, b.tx, auditlog.Event{
+		OccurredAt:   b.now,
+		Actor:        b.actor,
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		RequestID:    b.r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(b.r.RemoteAddr),
+		UserAgent:    b.r.UserAgent(),
+		Payload:      payload,
+	})
+	return err
+}
+
+// ensureProject mirrors dataset-registry project creation, integrity input
+// included, so the project verifies like any other.
+func (b bootstrapper) ensureProject(name string) (string, bool, error) {
+	var (
+		projectID  string
+		archivedAt sql.NullTime
+	)
+	err := b.tx.QueryRowContext(b.ctx, `SELECT project_id, archived_at FROM projects WHERE name = $1`, name).Scan(&projectID, &archivedAt)
+	switch {
+	case err == nil:
+		if archivedAt.Valid {
+			return "", false, bootstrapConflict("project_archived")
+		}
+		return projectID, false, nil
+	case !errors.Is(err, sql.ErrNoRows):
+		return "", false, err
+	}
+
+	projectID = uuid.NewString()
+	description := "Default project provisioned by bootstrap"
+	metadata := json.RawMessage(`{"bootstrap":true}`)
+	integrity, err := integritySHA256(struct {
+		ProjectID   string          `json:"project_id"`
+		Name        string          `json:"name"`
+		Description string          `json:"description,omitempty"`
+		Metadata    json.RawMessage `json:"metadata"`
+		CreatedAt   time.Time       `json:"created_at"`
+		CreatedBy   string          `json:"created_by"`
+	}{projectID, name, description, metadata, b.now, b.actor})
+	if err != nil {
+		return "", false, err
+	}
+	_, err = b.tx.ExecContext(
+		b.ctx,
+		`INSERT INTO projects (
+			project_id,
+			name,
+			description,
+			metadata,
+			created_at,
+			created_by,
+			integrity_sha256
+		) VALUES ($1,$2,$3,$4,$5,$6,$7)`,
+		projectID,
+		name,
+		description,
+		metadata,
+		b.now,
+		b.actor,
+		integrity,
+	)
+	if err != nil {
+		return "", false, err
+	}
+	err = b.audit("project.create", "project", projectID, map[string]any{
+		"project_id":  projectID,
+		"name":        name,
+		"description": description,
+	})
+	return projectID, true, err
+}
+
+func (b bootstrapper) ensureQualityRule(name string) (string, bool, error) {
+	var ruleID string
+	err := b.tx.QueryRowContext(b.ctx, `SELECT rule_id FROM quality_rules WHERE name = $1`, name).Scan(&ruleID)
+	switch {
+	case err == nil:
+		return ruleID, false, nil
+	case !errors.Is(err, sql.ErrNoRows):
+		return "", false, err
+	}
+
+	ruleID = uuid.NewString()
+	description := "Demo quality rule provisioned by bootstrap"
+	specJSON, err := json.Marshal(bootstrapQualityRuleSpec)
+	if err != nil {
+		return "", false, err
+	}
+	integrity, err := integritySHA256(struct {
+		RuleID      string          `json:"rule_id"`
+		Name        string          `json:"name"`
+		Description string          `json:"description,omitempty"`
+		Spec        json.RawMessage `json:"spec"`
+		CreatedAt   time.Time       `json:"created_at"`
+		CreatedBy   string          `json:"created_by"`
+	}{ruleID, name, description, specJSON, b.now, b.actor})
+	if err != nil {
+		return "", false, err
+	}
+	_, err = b.tx.ExecContext(
+		b.ctx,
+		`INSERT INTO quality_rules (rule_id, name, description, spec, created_at, created_by, integrity_sha256)
+		 VALUES ($1,$2,$3,$4,$5,$6,$7)`,
+		ruleID,
+		name,
+		description,
+		specJSON,
+		b.now,
+		b.actor,
+		integrity,
+	)
+	if err != nil {
+		return "", false, err
+	}
+	err = b.audit("quality_rule.create", "quality_rule", ruleID, map[string]any{
+		"rule_id": ruleID,
+		"name":    name,
+	})
+	return ruleID, true, err
+}
+
+// ensureProjectSettings makes the demo rule the project default, unless the
+// project already has settings.
+func (b bootstrapper) ensureProjectSettings(projectID, ruleID string) (bool, error) {
+	res, err := b.tx.ExecContext(
+		b.ctx,
+		`INSERT INTO project_settings (project_id, default_quality_rule_id, updated_at, updated_by)
+		 VALUES ($1,$2,$3,$4)
+		 ON CONFLICT (project_id) DO NOTHING`,
+		projectID,
+		ruleID,
+		b.now,
+		b.actor,
+	)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil || affected == 0 {
+		return false, err
+	}
+	err = b.audit("project.settings_update", "project", projectID, map[string]any{
+		"project_id":              projectID,
+		"default_quality_rule_id": ruleID,
+	})
+	return true, err
+}
+
+func (b bootstrapper) ensurePolicy(name string, specJSON []byte, specSHA string) (string, string, bool, error) {
+	var policyID, versionID string
+	err := b.tx.QueryRowContext(
+		b.ctx,
+		`SELECT p.policy_id, v.policy_version_id
+		 FROM policies p
+		 JOIN policy_versions v ON v.policy_id = p.policy_id
+		 WHERE p.name = $1
+		 ORDER BY v.version DESC
+		 LIMIT 1`,
+		name,
+	).Scan(&policyID, &versionID)
+	switch {
+	case err == nil:
+		return policyID, versionID, false, nil
+	case !errors.Is(err, sql.ErrNoRows):
+		return "", "", false, err
+	}
+
+	policyID = uuid.NewString()
+	versionID = uuid.NewString()
+	description := "Baseline policy provisioned by bootstrap"
+	const status = "active"
+	policyIntegrity, err := integritySHA256(struct {
+		PolicyID    string    `json:"policy_id"`
+		Name        string    `json:"name"`
+		Description string    `json:"description,omitempty"`
+		CreatedAt   time.Time `json:"created_at"`
+		CreatedBy   string    `json:"created_by"`
+	}{policyID, name, description, b.now, b.actor})
+	if err != nil {
+		return "", "", false, err
+	}
+	versionIntegrity, err := integritySHA256(struct {
+		PolicyVersionID string          `json:"policy_version_id"`
+		PolicyID        string          `json:"policy_id"`
+		Version         int             `json:"version"`
+		Status          string          `json:"status"`
+		SpecYAML        string          `json:"spec_yaml"`
+		Spec            json.RawMessage `json:"spec"`
+		SpecSHA256      string          `json:"spec_sha256"`
+		CreatedAt       time.Time       `json:"created_at"`
+		CreatedBy       string          `json:"created_by"`
+	}{versionID, policyID, 1, status, bootstrapPolicySpec, specJSON, specSHA, b.now, b.actor})
+	if err != nil {
+		return "", "", false, err
+	}
+
+	_, err = b.tx.ExecContext(
+		b.ctx,
+		`INSERT INTO policies (policy_id, name, description, created_at, created_by, integrity_sha256)
+		 VALUES ($1,$2,$3,$4,$5,$6)`,
+		policyID,
+		name,
+		description,
+		b.now,
+		b.actor,
+		policyIntegrity,
+	)
+	if err != nil {
+		return "", "", false, err
+	}
+	_, err = b.tx.ExecContext(
+		b.ctx,
+		`INSERT INTO policy_versions (
+			policy_version_id,
+			policy_id,
+			version,
+			status,
+			spec_yaml,
+			spec_json,
+			spec_sha256,
+			created_at,
+			created_by,
+			integrity_sha256
+		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)`,
+		versionID,
+		policyID,
+		1,
+		status,
+		bootstrapPolicySpec,
+		specJSON,
+		specSHA,
+		b.now,
+		b.actor,
+		versionIntegrity,
+	)
+	if err != nil {
+		return "", "", false, err
+	}
+	if err := b.audit("policy.create", "policy", policyID, map[string]any{
+		"policy_id":  policyID,
+		"name":       name,
+		"status":     status,
+		"version_id": versionID,
+	}); err != nil {
+		return "", "", false, err
+	}
+	err = b.audit("policy.version.create", "policy_version", versionID, map[string]any{
+		"policy_id":   policyID,
+		"version":     1,
+		"status":      status,
+		"spec_sha256": specSHA,
+	})
+	return policyID, versionID, true, err
+}
+
+func (b bootstrapper) ensureExperiment(projectID, name, ruleID, policyID string) (string, bool, error) {
+	var experimentID, experimentProjectID string
+	err := b.tx.QueryRowContext(b.ctx, `SELECT experiment_id, project_id FROM experiments WHERE name = $1`, name).Scan(&experimentID, &experimentProjectID)
+	switch {
+	case err == nil:
+		if experimentProjectID != projectID {
+			return "", false, bootstrapConflict("experiment_name_exists")
+		}
+		return experimentID, false, nil
+	case !errors.Is(err, sql.ErrNoRows):
+		return "", false, err
+	}
+
+	experimentID = uuid.NewString()
+	metadataMap := map[string]any{
+		"bootstrap":       true,
+		"quality_rule_id": ruleID,
+		"policy_id":       policyID,
+	}
+	metadataJSON, err := json.Marshal(metadataMap)
+	if err != nil {
+		return "", false, err
+	}
+	record, err := newExperimentRecord(experimentID, projectID, name, bootstrapExperimentDescription, metadataJSON, b.now, b.actor)
+	if err != nil {
+		return "", false, err
+	}
+	if err := b.runs.CreateExperiment(b.ctx, record); err != nil {
+		return "", false, err
+	}
+	err = b.audit("experiment.create", "experiment", experimentID, map[string]any{
+		"experiment_id": experimentID,
+		"project_id":    projectID,
+		"name":          name,
+		"metadata":      metadataMap,
+	})
+	return experimentID, true, err
+}
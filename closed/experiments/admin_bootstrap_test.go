@@ -0,0 +1,61 @@
+package experiments
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/policy"
+)
+
+func TestBootstrapPolicySpec(t *testing.T) {
+	spec, err := policy.ParseSpec([]byte(bootstrapPolicySpec))
+	if err != nil {
+		t.Fatalf("parse spec: %v", err)
+	}
+	yes, no := true, false
+	for name, tc := range map[string]struct {
+		dataset policy.DatasetContext
+		want    string
+	}{
+		"recalled": {dataset: policy.DatasetContext{Recalled: &yes, Stale: &no}, want: policy.EffectDeny},
+		"stale":    {dataset: policy.DatasetContext{Recalled: &no, Stale: &yes}, want: policy.EffectDeny},
+		"fresh":    {dataset: policy.DatasetContext{Recalled: &no, Stale: &no}, want: policy.EffectAllow},
+	} {
+		decision, err := policy.Evaluate(spec, policy.Context{Dataset: tc.dataset})
+		if err != nil {
+			t.Fatalf("%s: evaluate: %v", name, err)
+		}
+		if decision.Effect != tc.want {
+			t.Fatalf("%s: effect=%q want %q", name, decision.Effect, tc.want)
+		}
+	}
+	if _, sha, err := bootstrapPolicySpecJSON(); err != nil || len(sha) != 64 {
+		t.Fatalf("spec json: sha=%q err=%v", sha, err)
+	}
+}
+
+func TestBootstrapNames(t *testing.T) {
+	names := newBootstrapNames("acme")
+	if names.Project != "acme" || names.QualityRule != "acme-quality" || names.Policy != "acme-baseline" || names.Experiment != "acme-sample" {
+		t.Fatalf("unexpected names %+v", names)
+	}
+}
+
+func TestAdminBootstrapValidation(t *testing.T) {
+	api := &experimentsAPI{}
+	for body, want := range map[string]string{
+		`{"project":"x"}`: "invalid_json",
+		`{"project_name":"` + strings.Repeat("p", maxBootstrapProjectNameLen+1) + `"}`: "project_name_too_long",
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/admin/bootstrap", strings.NewReader(body))
+		req = req.WithContext(auth.ContextWithIdentity(req.Context(), auth.Identity{Subject: "admin-1", Roles: []string{auth.RoleAdmin}}))
+		rec := httptest.NewRecorder()
+		api.handleAdminBootstrap(rec, req)
+		if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), want) {
+			t.Fatalf("body %s: status=%d body=%s", body, rec.Code, rec.Body.String())
+		}
+	}
+}
@@ -1,24 +1,32 @@
-package main
+package experiments
 
 import (
 	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
-	"io"
+	"fmt"
 	"log/slog"
-	"net"
 	"net/http"
-	"strconv"
 	"strings"
 	"time"
 
+	"github.com/animus-labs/animus-go/closed/internal/domain"
+	"github.com/animus-labs/animus-go/closed/internal/integrations/digests"
+	"github.com/animus-labs/animus-go/closed/internal/integrations/eventbus"
+	"github.com/animus-labs/animus-go/closed/internal/integrations/notify"
 	"github.com/animus-labs/animus-go/closed/internal/integrations/registryverify"
+	"github.com/animus-labs/animus-go/closed/internal/integrations/vulnscan"
 	"github.com/animus-labs/animus-go/closed/internal/integrations/webhooks"
 	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
 	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
-	"github.com/animus-labs/animus-go/closed/internal/platform/lineageevent"
+	"github.com/animus-labs/animus-go/closed/internal/platform/envelope"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
 	"github.com/animus-labs/animus-go/closed/internal/platform/objectstore"
+	"github.com/animus-labs/animus-go/closed/internal/platform/outbox/servicerelay"
+	"github.com/animus-labs/animus-go/closed/internal/platform/policy"
+	"github.com/animus-labs/animus-go/closed/internal/platform/postgres"
+	"github.com/animus-labs/animus-go/closed/internal/platform/rbac"
 	"github.com/animus-labs/animus-go/closed/internal/platform/redaction"
 	"github.com/animus-labs/animus-go/closed/internal/repo"
 	"github.com/google/uuid"
@@ -27,8 +35,10 @@ import (
 )
 
 type experimentsAPI struct {
-	logger   *slog.Logger
-	db       *sql.DB
+	logger *slog.Logger
+	db     *sql.DB
+	// reads serves run listings; it is the read replica when configured.
+	reads    postgres.Querier
 	store    *minio.Client
 	storeCfg objectstore.Config
 
@@ -44,11 +54,38 @@ type experimentsAPI struct {
 	gitlabWebhookSecret   string
 
 	webhookConfig webhooks.Config
+	// digestMailer delivers email digests; nil disables the email channel.
+	digestMailer *digests.Mailer
+	// approvalNotifier announces policy approvals; nil disables it.
+	approvalNotifier     *notify.Notifier
+	approvalPendingAfter time.Duration
+	// runQueue limits concurrent runs; without limits runs dispatch directly.
+	runQueue runQueueConfig
 
 	registryPolicyResolver registryverify.PolicyResolver
 	registryVerifyTimeout  time.Duration
 	registryProviders      map[string]registryverify.Provider
 	registryStoreOverride  imageVerificationStore
+	// runImageVerify checks run image signatures and SBOMs before dispatch.
+	runImageVerify runImageVerifyConfig
+	// checkpointURLTTL is the lifetime of presigned checkpoint URLs sent
+	// with resumed runs.
+	checkpointURLTTL time.Duration
+	// datasetURLTTL is the lifetime of presigned dataset URLs sent with
+	// execution requests.
+	datasetURLTTL time.Duration
+	// evalPreviewSamples caps stored previews per evaluation when the
+	// evaluation does not set its own preview_samples.
+	evalPreviewSamples int
+	// previewRedactors mask evaluation preview documents before storage.
+	previewRedactors []previewRedactor
+	// energy estimates the energy and carbon footprint of finished runs.
+	energy energyConfig
+	// runConfigEnv is the run-relevant configuration recorded in run config
+	// manifests.
+	runConfigEnv map[string]string
+	// vulnScanner pulls vulnerability scans on demand; nil when not configured.
+	vulnScanner vulnscan.Scanner
 
 	devEnvDefaultTTL             time.Duration
 	devEnvAccessTTL              time.Duration
@@ -68,6 +105,26 @@ type experimentsAPI struct {
 	roleBindingStoreOverride roleBindingStore
 	roleBindingAuditOverride repo.AuditEventAppender
 
+	rbacPermissionStoreOverride rbacPermissionStore
+	permissionCache             *rbac.PermissionCache
+
+	metricAnomalies *metricAnomalyDetector
+
+	// internalTransport carries the mTLS client certificate for Data Plane
+	// calls; nil means http.DefaultTransport.
+	internalTransport http.RoundTripper
+
+	// evidenceSigner signs evidence bundles with an Ed25519 key; nil keeps
+	// HMAC signing with evidenceSigningSecret.
+	evidenceSigner *evidenceSigner
+	// encrypter protects evidence bundles at rest; nil disables encryption.
+	encrypter *envelope.Encrypter
+
+	// policyEvaluator routes animus.policy.opa.v1 specs to the OPA sidecar.
+	policyEvaluator policy.Evaluator
+	// bus enqueues run, evaluation and policy events for the message bus.
+	bus servicerelay.Bus
+
 	modelStoreOverride             modelStore
 	modelVersionStoreOverride      modelVersionStore
 	modelVersionTransitionOverride modelVersionTransitionStore
@@ -78,6 +135,11 @@ type experimentsAPI struct {
 	modelRunSpecOverride           runSpecStore
 	modelAuditOverride             modelAuditAppender
 	modelLineageOverride           modelLineageAppender
+
+	runStoreOverride      repo.RunStore
+	metricStoreOverride   repo.MetricStore
+	policyStoreOverride   repo.PolicyStore
+	evidenceStoreOverride repo.EvidenceStore
 }
 
 func newExperimentsAPI(
@@ -115,6 +177,7 @@ func newExperimentsAPI(
 	return &experimentsAPI{
 		logger:                    logger,
 		db:                        db,
+		reads:                     db,
 		store:                     store,
 		storeCfg:                  storeCfg,
 		ciWebhookSecret:           strings.TrimSpace(ciWebhookSecret),
@@ -142,19 +205,53 @@ func (api *experimentsAPI) register(mux *http.ServeMux) {
 	mux.HandleFunc("GET /experiments", api.handleListExperiments)
 	mux.HandleFunc("POST /experiments", api.handleCreateExperiment)
 	mux.HandleFunc("GET /experiments/{experiment_id}", api.handleGetExperiment)
+	mux.HandleFunc("POST /experiments/{experiment_id}", api.handleExperimentAction)
+	mux.HandleFunc("POST /experiments:import", api.handleImportExperiment)
+	mux.HandleFunc("GET /experiments/{experiment_id}/import", api.handleGetExperimentImport)
 
 	mux.HandleFunc("POST /projects/{project_id}/runs", api.handleCreateRun)
 	mux.HandleFunc("GET /projects/{project_id}/runs/{run_id}", api.handleGetRun)
 	mux.HandleFunc("GET /projects/{project_id}/runs/{run_id}/policy-snapshot", api.handleGetRunPolicySnapshot)
 	mux.HandleFunc("GET /projects/{project_id}/runs/{run_id}/reproducibility-bundle", api.handleGetRunReproducibilityBundle)
 	mux.HandleFunc("POST /projects/{project_id}/runs/{run_id}:dispatch", api.handleDispatchRun)
+	mux.HandleFunc("GET /projects/{project_id}/run-queue", api.handleListRunQueue)
+	mux.HandleFunc("GET /projects/{project_id}/artifact-retention", api.handleGetProjectArtifactRetention)
+	mux.HandleFunc("PUT /projects/{project_id}/artifact-retention", api.handleSetProjectArtifactRetention)
+	mux.HandleFunc("DELETE /projects/{project_id}/artifact-retention", api.handleDeleteProjectArtifactRetention)
+	mux.HandleFunc("POST /projects/{project_id}/evaluation-suites", api.handleCreateEvaluationSuite)
+	mux.HandleFunc("GET /projects/{project_id}/evaluation-suites", api.handleListEvaluationSuites)
+	mux.HandleFunc("GET /projects/{project_id}/evaluation-suites/{suite_id}", api.handleGetEvaluationSuite)
+	mux.HandleFunc("POST /projects/{project_id}/evaluation-suites/{suite_id}/results", api.handleCreateEvaluationResult)
+	mux.HandleFunc("GET /projects/{project_id}/evaluation-suites/{suite_id}/results", api.handleListEvaluationResults)
+	mux.HandleFunc("GET /projects/{project_id}/evaluation-suites/{suite_id}/compare", api.handleCompareEvaluationResults)
+	mux.HandleFunc("GET /projects/{project_id}/evaluation-results/{result_id}", api.handleGetEvaluationResult)
+	mux.HandleFunc("POST /projects/{project_id}/evaluation-results/{result_id}/samples", api.handleAddEvaluationSamples)
+	mux.HandleFunc("GET /projects/{project_id}/evaluation-results/{result_id}/samples", api.handleListEvaluationSamples)
 	mux.HandleFunc("POST /projects/{project_id}/runs/{run_id}:plan", api.handlePlanRun)
 	mux.HandleFunc("GET /projects/{project_id}/runs/{run_id}:plan", api.handleGetRunPlan)
 	mux.HandleFunc("POST /projects/{project_id}/runs/{run_id}:dry-run", api.handleDryRun)
 	mux.HandleFunc("GET /projects/{project_id}/runs/{run_id}:dry-run", api.handleGetDryRun)
+	mux.HandleFunc("GET /projects/{project_id}/runs/{run_id}/dry-run/report", api.handleGetDryRunReport)
+	mux.HandleFunc("GET /projects/{project_id}/runs/{run_id}/steps", api.handleListRunSteps)
+	mux.HandleFunc("GET /projects/{project_id}/runs/{run_id}/steps/{step_name}", api.handleGetRunStep)
+	mux.HandleFunc("POST /projects/{project_id}/runs/{run_id}/steps/{step_name}/rerun", api.handleRerunRunStep)
+	mux.HandleFunc("GET /projects/{project_id}/runs/{run_id}/pipeline", api.handleGetRunPipeline)
+	mux.HandleFunc("POST /projects/{project_id}/runs/{run_id}/cancel", api.handleCancelRun)
 	mux.HandleFunc("POST /projects/{project_id}/role-bindings", api.handleUpsertRoleBinding)
 	mux.HandleFunc("GET /projects/{project_id}/role-bindings", api.handleListRoleBindings)
 	mux.HandleFunc("POST /projects/{project_id}/role-bindings/{binding_id}:delete", api.handleDeleteRoleBinding)
+	mux.HandleFunc("GET /projects/{project_id}/members", api.handleListRoleBindings)
+	mux.HandleFunc("POST /projects/{project_id}/members", api.handleUpsertRoleBinding)
+	mux.HandleFunc("DELETE /projects/{project_id}/members/{binding_id}", api.handleDeleteRoleBinding)
+	mux.HandleFunc("POST /admin/bootstrap", api.handleAdminBootstrap)
+	mux.HandleFunc("GET /rbac/roles", api.handleListRBACRoles)
+	mux.HandleFunc("POST /rbac/roles", api.handleCreateRBACRole)
+	mux.HandleFunc("GET /rbac/roles/{role_name}", api.handleGetRBACRole)
+	mux.HandleFunc("PUT /rbac/roles/{role_name}", api.handleUpdateRBACRole)
+	mux.HandleFunc("POST /rbac/roles/{role_name}:delete", api.handleDeleteRBACRole)
+	mux.HandleFunc("GET /rbac/route-permissions", api.handleListRBACRoutePermissions)
+	mux.HandleFunc("POST /rbac/route-permissions", api.handleCreateRBACRoutePermission)
+	mux.HandleFunc("POST /rbac/route-permissions/{route_id}:delete", api.handleDeleteRBACRoutePermission)
 
 	mux.HandleFunc("POST /projects/{project_id}/environment-definitions", api.handleCreateEnvironmentDefinition)
 	mux.HandleFunc("GET /projects/{project_id}/environment-definitions", api.handleListEnvironmentDefinitions)
@@ -175,6 +272,10 @@ func (api *experimentsAPI) register(mux *http.ServeMux) {
 	mux.HandleFunc("POST /projects/{project_id}/model-versions/{model_version_id}:approve", api.handleApproveModelVersion)
 	mux.HandleFunc("POST /projects/{project_id}/model-versions/{model_version_id}:deprecate", api.handleDeprecateModelVersion)
 	mux.HandleFunc("POST /projects/{project_id}/model-versions/{model_version_id}:export", api.handleExportModelVersion)
+	mux.HandleFunc("POST /projects/{project_id}/model-versions/{model_version_id}/deployments", api.handleCreateModelDeployment)
+	mux.HandleFunc("GET /projects/{project_id}/deployments", api.handleListModelDeployments)
+	mux.HandleFunc("GET /projects/{project_id}/deployments/{deployment_id}", api.handleGetModelDeployment)
+	mux.HandleFunc("GET /projects/{project_id}/energy-usage", api.handleGetEnergyUsage)
 	mux.HandleFunc("POST /projects/{project_id}/dev-environments", api.handleCreateDevEnvironment)
 	mux.HandleFunc("GET /projects/{project_id}/dev-environments", api.handleListDevEnvironments)
 	mux.HandleFunc("GET /projects/{project_id}/dev-environments/{dev_env_id}", api.handleGetDevEnvironment)
@@ -193,24 +294,77 @@ func (api *experimentsAPI) register(mux *http.ServeMux) {
 	mux.HandleFunc("GET /projects/{project_id}/webhooks/deliveries", api.handleListWebhookDeliveries)
 	mux.HandleFunc("GET /projects/{project_id}/webhooks/deliveries/{delivery_id}/attempts", api.handleListWebhookDeliveryAttempts)
 	mux.HandleFunc("POST /projects/{project_id}/webhooks/deliveries/{delivery_id}:replay", api.handleReplayWebhookDelivery)
+	mux.HandleFunc("POST /projects/{project_id}/notification-digests", api.handleCreateNotificationDigest)
+	mux.HandleFunc("GET /projects/{project_id}/notification-digests", api.handleListNotificationDigests)
+	mux.HandleFunc("GET /projects/{project_id}/notification-digests/{digest_id}", api.handleGetNotificationDigest)
+	mux.HandleFunc("PATCH /projects/{project_id}/notification-digests/{digest_id}", api.handleUpdateNotificationDigest)
+	mux.HandleFunc("DELETE /projects/{project_id}/notification-digests/{digest_id}", api.handleDeleteNotificationDigest)
+	mux.HandleFunc("GET /projects/{project_id}/notification-digests/{digest_id}/preview", api.handlePreviewNotificationDigest)
+	mux.HandleFunc("GET /tags", api.handleListTags)
+	mux.HandleFunc("POST /tags", api.handleCreateTag)
+	mux.HandleFunc("GET /tags/{tag_id}", api.handleGetTag)
+	mux.HandleFunc("PATCH /tags/{tag_id}", api.handleUpdateTag)
+	mux.HandleFunc("DELETE /tags/{tag_id}", api.handleDeleteTag)
+	mux.HandleFunc("GET /tags/{tag_id}/resources", api.handleListTagResources)
+	mux.HandleFunc("POST /tags/{tag_id}/resources", api.handleAttachTag)
+	mux.HandleFunc("DELETE /tags/{tag_id}/resources/{resource_type}/{resource_id}", api.handleDetachTag)
+	mux.HandleFunc("GET /search", api.handleSearch)
+	mux.HandleFunc("GET /saved-searches", api.handleListSavedSearches)
+	mux.HandleFunc("POST /saved-searches", api.handleCreateSavedSearch)
+	mux.HandleFunc("GET /saved-searches/{search_id}", api.handleGetSavedSearch)
+	mux.HandleFunc("DELETE /saved-searches/{search_id}", api.handleDeleteSavedSearch)
+	mux.HandleFunc("GET /saved-searches/{search_id}/results", api.handleRunSavedSearch)
 
 	mux.HandleFunc("GET /experiments/{experiment_id}/runs", api.handleListExperimentRuns)
+	mux.HandleFunc("GET /experiments/{experiment_id}/leaderboard", api.handleGetExperimentLeaderboard)
 	mux.HandleFunc("POST /experiments/{experiment_id}/runs", api.handleCreateExperimentRun)
+	mux.HandleFunc("POST /experiments/{experiment_id}/runs:sweep", api.handleCreateSweep)
+	mux.HandleFunc("GET /sweeps/{sweep_id}", api.handleGetSweep)
+	mux.HandleFunc("GET /experiments/{experiment_id}/schedules", api.handleListRunSchedules)
+	mux.HandleFunc("POST /experiments/{experiment_id}/schedules", api.handleCreateRunSchedule)
+	mux.HandleFunc("GET /schedules/{schedule_id}", api.handleGetRunSchedule)
+	mux.HandleFunc("POST /schedules/{schedule_id}:pause", api.handlePauseRunSchedule)
+	mux.HandleFunc("POST /schedules/{schedule_id}:resume", api.handleResumeRunSchedule)
+	mux.HandleFunc("GET /schedules/{schedule_id}/firings", api.handleListRunScheduleFirings)
+	mux.HandleFunc("GET /projects/{project_id}/run-templates", api.handleListRunTemplates)
+	mux.HandleFunc("POST /projects/{project_id}/run-templates", api.handleCreateRunTemplate)
+	mux.HandleFunc("GET /run-templates/{template_id}", api.handleGetRunTemplate)
+	mux.HandleFunc("POST /run-templates/{template_id}:instantiate", api.handleInstantiateRunTemplate)
+	mux.HandleFunc("GET /experiments/{experiment_id}/run-fencing", api.handleGetRunFencing)
+	mux.HandleFunc("PUT /experiments/{experiment_id}/run-fencing", api.handleSetRunFencing)
+	mux.HandleFunc("DELETE /experiments/{experiment_id}/run-fencing", api.handleDeleteRunFencing)
+	mux.HandleFunc("GET /experiments/{experiment_id}/artifact-retention", api.handleGetExperimentArtifactRetention)
+	mux.HandleFunc("PUT /experiments/{experiment_id}/artifact-retention", api.handleSetExperimentArtifactRetention)
+	mux.HandleFunc("DELETE /experiments/{experiment_id}/artifact-retention", api.handleDeleteExperimentArtifactRetention)
+	mux.HandleFunc("GET /experiments/{experiment_id}/artifact-retention/preview", api.handlePreviewExperimentArtifactRetention)
 	mux.HandleFunc("POST /experiments/runs:execute", api.handleExecuteExperimentRun)
 	mux.HandleFunc("GET /experiment-runs", api.handleListAllExperimentRuns)
 	mux.HandleFunc("GET /experiment-runs/{run_id}", api.handleGetExperimentRun)
+	mux.HandleFunc("GET /experiment-runs/{run_id}/config-manifest", api.handleGetRunConfigManifest)
+	mux.HandleFunc("GET /experiment-runs/{run_id}/column-lineage", api.handleGetRunColumnLineage)
+	mux.HandleFunc("POST /experiment-runs/{run_id}/column-lineage", api.handleDeclareRunColumnLineage)
 	mux.HandleFunc("GET /experiment-runs/{run_id}/metrics", api.handleListExperimentRunMetrics)
 	mux.HandleFunc("POST /experiment-runs/{run_id}/metrics", api.handleIngestExperimentRunMetrics)
+	mux.HandleFunc("GET /experiment-runs/{run_id}/metric-imports", api.handleListExperimentRunMetricImports)
+	mux.HandleFunc("POST /experiment-runs/{run_id}/metric-imports", api.handleCreateExperimentRunMetricImport)
+	mux.HandleFunc("GET /experiment-runs/{run_id}/metric-imports/{import_id}", api.handleGetExperimentRunMetricImport)
 	mux.HandleFunc("GET /experiment-runs/{run_id}/artifacts", api.handleListExperimentRunArtifacts)
 	mux.HandleFunc("POST /experiment-runs/{run_id}/artifacts", api.handleCreateExperimentRunArtifact)
 	mux.HandleFunc("GET /experiment-runs/{run_id}/artifacts/{artifact_id}", api.handleGetExperimentRunArtifact)
 	mux.HandleFunc("GET /experiment-runs/{run_id}/artifacts/{artifact_id}/download", api.handleDownloadExperimentRunArtifact)
+	mux.HandleFunc("GET /experiment-runs/{run_id}/evaluations/{evaluation_id}/previews", api.handleListEvaluationPreviews)
+	mux.HandleFunc("POST /experiment-runs/{run_id}/evaluations/{evaluation_id}/previews", api.handleCreateEvaluationPreview)
+	mux.HandleFunc("GET /experiment-runs/{run_id}/evaluations/{evaluation_id}/previews/{preview_id}", api.handleGetEvaluationPreview)
 	mux.HandleFunc("GET /experiment-runs/{run_id}/evidence-bundles", api.handleListEvidenceBundles)
 	mux.HandleFunc("POST /experiment-runs/{run_id}/evidence-bundles", api.handleCreateEvidenceBundle)
 	mux.HandleFunc("GET /experiment-runs/{run_id}/evidence-bundles/{bundle_id}", api.handleGetEvidenceBundle)
+	mux.HandleFunc("POST /experiment-runs/{run_id}/evidence-bundles/{bundle_id}", api.handleEvidenceBundleAction)
 	mux.HandleFunc("GET /experiment-runs/{run_id}/evidence-bundles/{bundle_id}/download", api.handleDownloadEvidenceBundle)
 	mux.HandleFunc("GET /experiment-runs/{run_id}/evidence-bundles/{bundle_id}/report", api.handleDownloadEvidenceReport)
+	mux.HandleFunc("GET /experiment-runs/{run_id}/evidence-bundles/{bundle_id}/provenance", api.handleDownloadEvidenceProvenance)
+	mux.HandleFunc("GET /evidence-signing-keys", api.handleListEvidenceSigningKeys)
 	mux.HandleFunc("GET /experiment-runs/{run_id}/stream", api.handleStreamExperimentRun)
+	mux.HandleFunc("GET /experiment-runs/{run_id}/logs", api.handleGetExperimentRunLogs)
 	mux.HandleFunc("GET /experiment-runs/{run_id}/events", api.handleListExperimentRunEvents)
 	mux.HandleFunc("POST /experiment-runs/{run_id}/events", api.handleCreateExperimentRunEvent)
 	mux.HandleFunc("GET /experiment-runs/{run_id}/execution", api.handleGetExperimentRunExecution)
@@ -222,23 +376,41 @@ func (api *experimentsAPI) register(mux *http.ServeMux) {
 	mux.HandleFunc("POST /internal/cp/runs/{run_id}/terminal", api.handleDPTerminal)
 	mux.HandleFunc("POST /internal/cp/runs/{run_id}/artifact-committed", api.handleDPArtifactCommitted)
 	mux.HandleFunc("POST /internal/cp/runs/{run_id}/secrets-accessed", api.handleDPSecretAccessed)
+	mux.HandleFunc("POST /internal/cp/runs/{run_id}/dataset-cache", api.handleDPDatasetCache)
+	mux.HandleFunc("POST /internal/cp/runs/{run_id}/logs", api.handleDPRunLogs)
+	mux.HandleFunc("POST /internal/cp/agents/{agent_id}/claim", api.handleAgentClaim)
 
 	mux.HandleFunc("GET /policies", api.handleListPolicies)
 	mux.HandleFunc("POST /policies", api.handleCreatePolicy)
 	mux.HandleFunc("GET /policies/{policy_id}", api.handleGetPolicy)
 	mux.HandleFunc("GET /policies/{policy_id}/versions", api.handleListPolicyVersions)
 	mux.HandleFunc("POST /policies/{policy_id}/versions", api.handleCreatePolicyVersion)
+	mux.HandleFunc("GET /policies/{policy_id}/bindings", api.handleListPolicyBindings)
+	mux.HandleFunc("POST /policies/{policy_id}/bindings", api.handleCreatePolicyBinding)
+	mux.HandleFunc("DELETE /policies/{policy_id}/bindings/{binding_id}", api.handleDeletePolicyBinding)
+	mux.HandleFunc("GET /policy-bindings", api.handleSearchPolicyBindings)
 	mux.HandleFunc("GET /policy-decisions", api.handleListPolicyDecisions)
 	mux.HandleFunc("GET /policy-decisions/{decision_id}", api.handleGetPolicyDecision)
 	mux.HandleFunc("GET /policy-approvals", api.handleListPolicyApprovals)
 	mux.HandleFunc("GET /policy-approvals/{approval_id}", api.handleGetPolicyApproval)
 	mux.HandleFunc("POST /policy-approvals/{approval_id}/approve", api.handleApprovePolicyApproval)
 	mux.HandleFunc("POST /policy-approvals/{approval_id}/deny", api.handleDenyPolicyApproval)
+	mux.HandleFunc("POST /policy-approvals/{approval_id}/reassign", api.handleReassignPolicyApproval)
+	mux.HandleFunc("GET /policy-approval-delegations", api.handleListPolicyApprovalDelegations)
+	mux.HandleFunc("POST /policy-approval-delegations", api.handleCreatePolicyApprovalDelegation)
+	mux.HandleFunc("DELETE /policy-approval-delegations/{delegation_id}", api.handleRevokePolicyApprovalDelegation)
 
 	mux.HandleFunc("POST /ci/webhook", api.handleCIWebhook)
 	mux.HandleFunc("POST /ci/report", api.handleCIReport)
+	mux.HandleFunc("GET /ci/reports", api.handleListCIReports)
+	mux.HandleFunc("GET /ci/reports/{report_id}", api.handleGetCIReport)
 	mux.HandleFunc("GET /model-images", api.handleListModelImages)
 	mux.HandleFunc("GET /model-images/{image_digest}", api.handleGetModelImage)
+	mux.HandleFunc("GET /model-images/{image_digest}/vulnerability-scans", api.handleListVulnerabilityScans)
+	mux.HandleFunc("POST /model-images/{image_digest}/vulnerability-scans", api.handlePushVulnerabilityScan)
+	mux.HandleFunc("POST /model-images/{image_digest}/vulnerability-scans/pull", api.handlePullVulnerabilityScan)
+
+	api.registerMLflow(mux)
 }
 
 type experiment struct {
@@ -270,8 +442,8 @@ func (api *experimentsAPI) handleCreateExperiment(w http.ResponseWriter, r *http
 	}
 
 	var req createExperimentRequest
-	if err := decodeJSON(r, &req); err != nil {
-		api.writeError(w, r, http.StatusBadRequest, "invalid_json")
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
 		return
 	}
 	name := strings.TrimSpace(req.Name)
@@ -292,9 +464,52 @@ func (api *experimentsAPI) handleCreateExperiment(w http.ResponseWriter, r *http
 		return
 	}
 
-	now := time.Now().UTC()
-	experimentID := uuid.NewString()
+	record, err := newExperimentRecord(uuid.NewString(), projectID, name, description, metadataJSON, time.Now().UTC(), identity.Subject)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := api.createExperiment(r, tx, record, metadataMap); err != nil {
+		switch {
+		case errors.Is(err, repo.ErrConflict):
+			api.writeError(w, r, http.StatusConflict, "experiment_name_exists")
+		case errors.Is(err, errExperimentAuditFailed):
+			api.writeError(w, r, http.StatusInternalServerError, "audit_failed")
+		default:
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		}
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
 
+	experimentID := record.ExperimentID
+	w.Header().Set("Location", "/experiments/"+experimentID)
+	api.writeJSON(w, http.StatusCreated, experiment{
+		ExperimentID: experimentID,
+		Name:         name,
+		Description:  description,
+		Metadata:     metadataJSON,
+		CreatedAt:    record.CreatedAt,
+		CreatedBy:    identity.Subject,
+	})
+}
+
+var errExperimentAuditFailed = errors.New("experiment audit failed")
+
+// newExperimentRecord builds an experiment row and its integrity hash.
+func newExperimentRecord(experimentID, projectID, name, description string, metadata json.RawMessage, createdAt time.Time, createdBy string) (repo.ExperimentRecord, error) {
 	type integrityInput struct {
 		ExperimentID string          `json:"experiment_id"`
 		ProjectID    string          `json:"project_id"`
@@ -309,91 +524,59 @@ func (api *experimentsAPI) handleCreateExperiment(w http.ResponseWriter, r *http
 		ProjectID:    projectID,
 		Name:         name,
 		Description:  description,
-		Metadata:     metadataJSON,
-		CreatedAt:    now,
-		CreatedBy:    identity.Subject,
+		Metadata:     metadata,
+		CreatedAt:    createdAt,
+		CreatedBy:    createdBy,
 	})
 	if err != nil {
-		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
-		return
-	}
-
-	tx, err := api.db.BeginTx(r.Context(), nil)
-	if err != nil {
-		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
-		return
-	}
-	defer func() { _ = tx.Rollback() }()
+		return repo.ExperimentRecord{}, err
+	}
+	return repo.ExperimentRecord{
+		ExperimentID:    experimentID,
+		ProjectID:       projectID,
+		Name:            name,
+		Description:     description,
+		Metadata:        metadata,
+		CreatedAt:       createdAt,
+		CreatedBy:       createdBy,
+		IntegritySHA256: integrity,
+	}, nil
+}
 
-	_, err = tx.ExecContext(
-		r.Context(),
-		`INSERT INTO experiments (
-			experiment_id,
-			project_id,
-			name,
-			description,
-			metadata,
-			created_at,
-			created_by,
-			integrity_sha256
-		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8)`,
-		experimentID,
-		projectID,
-		name,
-		nullString(description),
-		metadataJSON,
-		now,
-		identity.Subject,
-		integrity,
-	)
-	if err != nil {
-		if isUniqueViolation(err) {
-			api.writeError(w, r, http.StatusConflict, "experiment_name_exists")
-			return
-		}
-		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
-		return
+// createExperiment inserts record in tx together with its experiment.create
+// audit event and experiment.created bus event. A taken name is
+// repo.ErrConflict; a failed audit wraps errExperimentAuditFailed.
+func (api *experimentsAPI) createExperiment(r *http.Request, tx *sql.Tx, record repo.ExperimentRecord, metadata map[string]any) error {
+	if err := api.runStore(tx).CreateExperiment(r.Context(), record); err != nil {
+		return err
 	}
-
-	_, err = auditlog.Insert(r.Context(), tx, auditlog.Event{
-		OccurredAt:   now,
-		Actor:        identity.Subject,
+	err := auditlog.Enqueue(r.Context(), tx, auditlog.Event{
+		OccurredAt:   record.CreatedAt,
+		Actor:        record.CreatedBy,
 		Action:       "experiment.create",
 		ResourceType: "experiment",
-		ResourceID:   experimentID,
+		ResourceID:   record.ExperimentID,
 		RequestID:    r.Header.Get("X-Request-Id"),
-		IP:           requestIP(r.RemoteAddr),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
 		UserAgent:    r.UserAgent(),
 		Payload: map[string]any{
 			"service":        "experiments",
-			"experiment_id":  experimentID,
-			"name":           name,
-			"description":    description,
-			"metadata":       metadataMap,
-			"created_by":     identity.Subject,
+			"experiment_id":  record.ExperimentID,
+			"name":           record.Name,
+			"description":    record.Description,
+			"metadata":       metadata,
+			"created_by":     record.CreatedBy,
 			"request_path":   r.URL.Path,
 			"request_method": r.Method,
 		},
 	})
 	if err != nil {
-		api.writeError(w, r, http.StatusInternalServerError, "audit_failed")
-		return
+		return fmt.Errorf("%w: %v", errExperimentAuditFailed, err)
 	}
-
-	if err := tx.Commit(); err != nil {
-		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
-		return
-	}
-
-	w.Header().Set("Location", "/experiments/"+experimentID)
-	api.writeJSON(w, http.StatusCreated, experiment{
-		ExperimentID: experimentID,
-		Name:         name,
-		Description:  description,
-		Metadata:     metadataJSON,
-		CreatedAt:    now,
-		CreatedBy:    identity.Subject,
-	})
+	return api.bus.Enqueue(r.Context(), tx, eventbus.EventExperimentCreated, record.ExperimentID, record.ProjectID, record.CreatedAt,
+		map[string]string{"experiment_id": record.ExperimentID},
+		map[string]any{"name": record.Name, "created_by": record.CreatedBy},
+	)
 }
 
 func (api *experimentsAPI) handleListExperiments(w http.ResponseWriter, r *http.Request) {
@@ -402,69 +585,21 @@ func (api *experimentsAPI) handleListExperiments(w http.ResponseWriter, r *http.
 		api.writeError(w, r, http.StatusBadRequest, "project_id_required")
 		return
 	}
-	limit := clampInt(parseIntQuery(r, "limit", 100), 1, 500)
+	limit := httpapi.Limit(r, 100, 500)
 	nameFilter := strings.TrimSpace(r.URL.Query().Get("name"))
 
-	var (
-		rows *sql.Rows
-		err  error
-	)
-	if nameFilter != "" {
-		rows, err = api.db.QueryContext(
-			r.Context(),
-			`SELECT experiment_id, name, description, metadata, created_at, created_by
-			 FROM experiments
-			 WHERE project_id = $1 AND name = $2
-			 ORDER BY created_at DESC
-			 LIMIT $3`,
-			projectID,
-			nameFilter,
-			limit,
-		)
-	} else {
-		rows, err = api.db.QueryContext(
-			r.Context(),
-			`SELECT experiment_id, name, description, metadata, created_at, created_by
-			 FROM experiments
-			 WHERE project_id = $1
-			 ORDER BY created_at DESC
-			 LIMIT $2`,
-			projectID,
-			limit,
-		)
-	}
+	records, err := api.runStore(api.db).ListExperiments(r.Context(), repo.ExperimentFilter{
+		ProjectID: projectID,
+		Name:      nameFilter,
+		Limit:     limit,
+	})
 	if err != nil {
 		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
 		return
 	}
-	defer rows.Close()
-
-	out := make([]experiment, 0, limit)
-	for rows.Next() {
-		var (
-			experimentID string
-			name         string
-			description  sql.NullString
-			metadata     []byte
-			createdAt    time.Time
-			createdBy    string
-		)
-		if err := rows.Scan(&experimentID, &name, &description, &metadata, &createdAt, &createdBy); err != nil {
-			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
-			return
-		}
-		out = append(out, experiment{
-			ExperimentID: experimentID,
-			Name:         name,
-			Description:  description.String,
-			Metadata:     normalizeJSON(metadata),
-			CreatedAt:    createdAt,
-			CreatedBy:    createdBy,
-		})
-	}
-	if err := rows.Err(); err != nil {
-		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
-		return
+	out := make([]experiment, 0, len(records))
+	for _, record := range records {
+		out = append(out, experimentFromRecord(record))
 	}
 
 	api.writeJSON(w, http.StatusOK, map[string]any{"experiments": out})
@@ -483,23 +618,9 @@ func (api *experimentsAPI) handleGetExperiment(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	var (
-		name        string
-		description sql.NullString
-		metadata    []byte
-		createdAt   time.Time
-		createdBy   string
-	)
-	err := api.db.QueryRowContext(
-		r.Context(),
-		`SELECT name, description, metadata, created_at, created_by
-		 FROM experiments
-		 WHERE experiment_id = $1 AND project_id = $2`,
-		experimentID,
-		projectID,
-	).Scan(&name, &description, &metadata, &createdAt, &createdBy)
+	record, err := api.runStore(api.db).GetExperiment(r.Context(), projectID, experimentID)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
+		if errors.Is(err, repo.ErrNotFound) {
 			api.writeError(w, r, http.StatusNotFound, "not_found")
 			return
 		}
@@ -507,42 +628,48 @@ func (api *experimentsAPI) handleGetExperiment(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	api.writeJSON(w, http.StatusOK, experiment{
-		ExperimentID: experimentID,
-		Name:         name,
-		Description:  description.String,
-		Metadata:     normalizeJSON(metadata),
-		CreatedAt:    createdAt,
-		CreatedBy:    createdBy,
-	})
+	api.writeJSON(w, http.StatusOK, experimentFromRecord(record))
+}
+
+func experimentFromRecord(record repo.ExperimentRecord) experiment {
+	return experiment{
+		ExperimentID: record.ExperimentID,
+		Name:         record.Name,
+		Description:  record.Description,
+		Metadata:     normalizeJSON(record.Metadata),
+		CreatedAt:    record.CreatedAt,
+		CreatedBy:    record.CreatedBy,
+	}
 }
 
 type experimentRun struct {
-	RunID            string          `json:"run_id"`
-	ExperimentID     string          `json:"experiment_id"`
-	DatasetVersionID string          `json:"dataset_version_id,omitempty"`
-	Status           string          `json:"status"`
-	StartedAt        time.Time       `json:"started_at"`
-	EndedAt          *time.Time      `json:"ended_at,omitempty"`
-	GitRepo          string          `json:"git_repo,omitempty"`
-	GitCommit        string          `json:"git_commit,omitempty"`
-	GitRef           string          `json:"git_ref,omitempty"`
-	Params           json.RawMessage `json:"params"`
-	Metrics          json.RawMessage `json:"metrics"`
-	ArtifactsPrefix  string          `json:"artifacts_prefix,omitempty"`
+	RunID             string          `json:"run_id"`
+	ExperimentID      string          `json:"experiment_id"`
+	DatasetVersionID  string          `json:"dataset_version_id,omitempty"`
+	DatasetVersionIDs []string        `json:"dataset_version_ids,omitempty"`
+	Status            string          `json:"status"`
+	StartedAt         time.Time       `json:"started_at"`
+	EndedAt           *time.Time      `json:"ended_at,omitempty"`
+	GitRepo           string          `json:"git_repo,omitempty"`
+	GitCommit         string          `json:"git_commit,omitempty"`
+	GitRef            string          `json:"git_ref,omitempty"`
+	Params            json.RawMessage `json:"params"`
+	Metrics           json.RawMessage `json:"metrics"`
+	ArtifactsPrefix   string          `json:"artifacts_prefix,omitempty"`
 }
 
 type createExperimentRunRequest struct {
-	DatasetVersionID string         `json:"dataset_version_id,omitempty"`
-	Status           string         `json:"status"`
-	StartedAt        *time.Time     `json:"started_at,omitempty"`
-	EndedAt          *time.Time     `json:"ended_at,omitempty"`
-	GitRepo          string         `json:"git_repo,omitempty"`
-	GitCommit        string         `json:"git_commit,omitempty"`
-	GitRef           string         `json:"git_ref,omitempty"`
-	Params           map[string]any `json:"params,omitempty"`
-	Metrics          map[string]any `json:"metrics,omitempty"`
-	ArtifactsPrefix  string         `json:"artifacts_prefix,omitempty"`
+	DatasetVersionID  string         `json:"dataset_version_id,omitempty"`
+	DatasetVersionIDs []string       `json:"dataset_version_ids,omitempty"`
+	Status            string         `json:"status"`
+	StartedAt         *time.Time     `json:"started_at,omitempty"`
+	EndedAt           *time.Time     `json:"ended_at,omitempty"`
+	GitRepo           string         `json:"git_repo,omitempty"`
+	GitCommit         string         `json:"git_commit,omitempty"`
+	GitRef            string         `json:"git_ref,omitempty"`
+	Params            map[string]any `json:"params,omitempty"`
+	Metrics           map[string]any `json:"metrics,omitempty"`
+	ArtifactsPrefix   string         `json:"artifacts_prefix,omitempty"`
 }
 
 var allowedRunStatuses = map[string]struct{}{
@@ -571,6 +698,9 @@ func (api *experimentsAPI) handleCreateExperimentRun(w http.ResponseWriter, r *h
 		api.writeError(w, r, http.StatusNotFound, "not_found")
 		return
 	}
+	if !api.requireActiveProject(w, r, projectID) {
+		return
+	}
 
 	exists, err := api.experimentExists(r.Context(), experimentID)
 	if err != nil {
@@ -583,8 +713,8 @@ func (api *experimentsAPI) handleCreateExperimentRun(w http.ResponseWriter, r *h
 	}
 
 	var req createExperimentRunRequest
-	if err := decodeJSON(r, &req); err != nil {
-		api.writeError(w, r, http.StatusBadRequest, "invalid_json")
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
 		return
 	}
 
@@ -598,14 +728,28 @@ func (api *experimentsAPI) handleCreateExperimentRun(w http.ResponseWriter, r *h
 		return
 	}
 
-	datasetVersionID := strings.TrimSpace(req.DatasetVersionID)
-	var gate gateDecision
-	if datasetVersionID != "" {
-		var ok bool
-		gate, ok = api.requireQualityGatePass(w, r, identity, datasetVersionID, experimentID)
+	datasetVersionIDs := runDatasetVersionIDs(req.DatasetVersionID, req.DatasetVersionIDs)
+	if len(datasetVersionIDs) > maxRunDatasetVersions {
+		api.writeError(w, r, http.StatusBadRequest, "too_many_dataset_versions")
+		return
+	}
+	datasets := make([]runDataset, 0, len(datasetVersionIDs))
+	for _, versionID := range datasetVersionIDs {
+		gate, ok := api.requireQualityGatePass(w, r, identity, versionID, experimentID)
 		if !ok {
 			return
 		}
+		policies, ok := api.requireDatasetPolicyAllow(w, r, identity, projectID, versionID, experimentID, gate)
+		if !ok {
+			return
+		}
+		datasets = append(datasets, runDataset{VersionID: versionID, Gate: gate, Policies: policies})
+	}
+	// The first dataset stays in experiment_runs.dataset_version_id so that
+	// single-dataset readers keep working.
+	datasetVersionID := ""
+	if len(datasetVersionIDs) > 0 {
+		datasetVersionID = datasetVersionIDs[0]
 	}
 
 	now := time.Now().UTC()
@@ -647,211 +791,68 @@ func (api *experimentsAPI) handleCreateExperimentRun(w http.ResponseWriter, r *h
 	gitRef := strings.TrimSpace(req.GitRef)
 	artifactsPrefix := strings.TrimSpace(req.ArtifactsPrefix)
 
-	runID := uuid.NewString()
-
-	type integrityInput struct {
-		RunID            string          `json:"run_id"`
-		ExperimentID     string          `json:"experiment_id"`
-		ProjectID        string          `json:"project_id"`
-		DatasetVersionID string          `json:"dataset_version_id,omitempty"`
-		Status           string          `json:"status"`
-		StartedAt        time.Time       `json:"started_at"`
-		EndedAt          *time.Time      `json:"ended_at,omitempty"`
-		GitRepo          string          `json:"git_repo,omitempty"`
-		GitCommit        string          `json:"git_commit,omitempty"`
-		GitRef           string          `json:"git_ref,omitempty"`
-		Params           json.RawMessage `json:"params"`
-		Metrics          json.RawMessage `json:"metrics"`
-		ArtifactsPrefix  string          `json:"artifacts_prefix,omitempty"`
-	}
-	integrity, err := integritySHA256(integrityInput{
-		RunID:            runID,
-		ExperimentID:     experimentID,
-		ProjectID:        projectID,
-		DatasetVersionID: datasetVersionID,
-		Status:           status,
-		StartedAt:        startedAt,
-		EndedAt:          endedAt,
-		GitRepo:          gitRepo,
-		GitCommit:        gitCommit,
-		GitRef:           gitRef,
-		Params:           paramsJSON,
-		Metrics:          metricsJSON,
-		ArtifactsPrefix:  artifactsPrefix,
-	})
+	run := experimentRunInsert{
+		RunID:             uuid.NewString(),
+		ExperimentID:      experimentID,
+		ProjectID:         projectID,
+		DatasetVersionID:  datasetVersionID,
+		DatasetVersionIDs: datasetVersionIDs,
+		Datasets:          datasets,
+		Status:            status,
+		StartedAt:         startedAt,
+		EndedAt:           endedAt,
+		GitRepo:           gitRepo,
+		GitCommit:         gitCommit,
+		GitRef:            gitRef,
+		Params:            paramsMap,
+		ParamsJSON:        paramsJSON,
+		Metrics:           metricsMap,
+		MetricsJSON:       metricsJSON,
+		ArtifactsPrefix:   artifactsPrefix,
+		CreatedAt:         now,
+	}
+	runID := run.RunID
+	integrity, err := experimentRunIntegrity(run)
 	if err != nil {
 		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
 		return
 	}
+	run.IntegritySHA256 = integrity
 
-	tx, err := api.db.BeginTx(r.Context(), nil)
+	execution, err := api.evaluateExecutionPolicy(r.Context(), runExecutionPolicyInput(identity, run))
 	if err != nil {
 		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
 		return
 	}
-	defer func() { _ = tx.Rollback() }()
 
-	_, err = tx.ExecContext(
-		r.Context(),
-		`INSERT INTO experiment_runs (
-			run_id,
-			experiment_id,
-			project_id,
-			dataset_version_id,
-			status,
-			started_at,
-			ended_at,
-			git_repo,
-			git_commit,
-			git_ref,
-			params,
-			metrics,
-			artifacts_prefix,
-			integrity_sha256
-		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14)`,
-		runID,
-		experimentID,
-		projectID,
-		nullString(datasetVersionID),
-		status,
-		startedAt,
-		nullTimePtr(endedAt),
-		nullString(gitRepo),
-		nullString(gitCommit),
-		nullString(gitRef),
-		paramsJSON,
-		metricsJSON,
-		nullString(artifactsPrefix),
-		integrity,
-	)
+	tx, err := api.db.BeginTx(r.Context(), nil)
 	if err != nil {
-		if isForeignKeyViolation(err) {
-			api.writeError(w, r, http.StatusNotFound, "not_found")
-			return
-		}
 		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
 		return
 	}
+	defer func() { _ = tx.Rollback() }()
 
-	_, err = lineageevent.Insert(r.Context(), tx, lineageevent.Event{
-		OccurredAt:  now,
-		Actor:       identity.Subject,
-		RequestID:   r.Header.Get("X-Request-Id"),
-		SubjectType: "experiment",
-		SubjectID:   experimentID,
-		Predicate:   "has_run",
-		ObjectType:  "experiment_run",
-		ObjectID:    runID,
-		Metadata: map[string]any{
-			"status":             status,
-			"dataset_version_id": datasetVersionID,
-			"git_commit":         gitCommit,
-		},
-	})
+	fenceMode, fencedRunID, err := fenceExperimentRun(r.Context(), tx, experimentID, datasetVersionIDs, status, paramsJSON, gitCommit)
 	if err != nil {
-		api.writeError(w, r, http.StatusInternalServerError, "lineage_write_failed")
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
 		return
 	}
-
-	if datasetVersionID != "" {
-		_, err = lineageevent.Insert(r.Context(), tx, lineageevent.Event{
-			OccurredAt:  now,
-			Actor:       identity.Subject,
-			RequestID:   r.Header.Get("X-Request-Id"),
-			SubjectType: "dataset_version",
-			SubjectID:   datasetVersionID,
-			Predicate:   "used_by",
-			ObjectType:  "experiment_run",
-			ObjectID:    runID,
-			Metadata: map[string]any{
-				"dataset_id":    gate.DatasetID,
-				"experiment_id": experimentID,
-				"rule_id":       gate.RuleID,
-				"evaluation_id": gate.EvaluationID,
-				"status":        gate.Status,
-			},
-		})
-		if err != nil {
-			api.writeError(w, r, http.StatusInternalServerError, "lineage_write_failed")
-			return
-		}
+	if fencedRunID != "" {
+		_ = tx.Rollback()
+		api.writeFencedRun(w, r, identity, experimentID, fenceMode, fencedRunID)
+		return
 	}
 
-	if gitCommit != "" {
-		_, err = lineageevent.Insert(r.Context(), tx, lineageevent.Event{
-			OccurredAt:  now,
-			Actor:       identity.Subject,
-			RequestID:   r.Header.Get("X-Request-Id"),
-			SubjectType: "experiment_run",
-			SubjectID:   runID,
-			Predicate:   "built_from",
-			ObjectType:  "git_commit",
-			ObjectID:    gitCommit,
-			Metadata: map[string]any{
-				"git_repo": gitRepo,
-				"git_ref":  gitRef,
-			},
-		})
-		if err != nil {
-			api.writeError(w, r, http.StatusInternalServerError, "lineage_write_failed")
-			return
-		}
+	if err := api.prepareRunConfigManifest(r.Context(), tx, &run); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
 	}
-
-	if datasetVersionID != "" {
-		_, err = auditlog.Insert(r.Context(), tx, auditlog.Event{
-			OccurredAt:   now,
-			Actor:        identity.Subject,
-			Action:       "quality_gate.allow",
-			ResourceType: "dataset_version",
-			ResourceID:   datasetVersionID,
-			RequestID:    r.Header.Get("X-Request-Id"),
-			IP:           requestIP(r.RemoteAddr),
-			UserAgent:    r.UserAgent(),
-			Payload: map[string]any{
-				"service":            "experiments",
-				"dataset_id":         gate.DatasetID,
-				"dataset_version_id": datasetVersionID,
-				"rule_id":            gate.RuleID,
-				"evaluation_id":      gate.EvaluationID,
-				"status":             gate.Status,
-				"experiment_id":      experimentID,
-				"run_id":             runID,
-			},
-		})
-		if err != nil {
-			api.writeError(w, r, http.StatusInternalServerError, "audit_failed")
-			return
-		}
+	if err := insertExperimentRun(r, tx, identity.Subject, run); err != nil {
+		api.writeExperimentRunInsertError(w, r, err)
+		return
 	}
-
-	_, err = auditlog.Insert(r.Context(), tx, auditlog.Event{
-		OccurredAt:   now,
-		Actor:        identity.Subject,
-		Action:       "experiment_run.create",
-		ResourceType: "experiment_run",
-		ResourceID:   runID,
-		RequestID:    r.Header.Get("X-Request-Id"),
-		IP:           requestIP(r.RemoteAddr),
-		UserAgent:    r.UserAgent(),
-		Payload: map[string]any{
-			"service":            "experiments",
-			"run_id":             runID,
-			"experiment_id":      experimentID,
-			"dataset_version_id": datasetVersionID,
-			"status":             status,
-			"started_at":         startedAt.Format(time.RFC3339Nano),
-			"ended_at":           formatTimePtr(endedAt),
-			"git_repo":           gitRepo,
-			"git_commit":         gitCommit,
-			"git_ref":            gitRef,
-			"params":             paramsMap,
-			"metrics":            metricsMap,
-			"artifacts_prefix":   artifactsPrefix,
-		},
-	})
-	if err != nil {
-		api.writeError(w, r, http.StatusInternalServerError, "audit_failed")
+	if _, err := api.recordExecutionPolicy(r.Context(), tx, runID, identity, execution); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
 		return
 	}
 
@@ -862,18 +863,19 @@ func (api *experimentsAPI) handleCreateExperimentRun(w http.ResponseWriter, r *h
 
 	w.Header().Set("Location", "/experiment-runs/"+runID)
 	api.writeJSON(w, http.StatusCreated, experimentRun{
-		RunID:            runID,
-		ExperimentID:     experimentID,
-		DatasetVersionID: datasetVersionID,
-		Status:           status,
-		StartedAt:        startedAt,
-		EndedAt:          endedAt,
-		GitRepo:          gitRepo,
-		GitCommit:        gitCommit,
-		GitRef:           gitRef,
-		Params:           paramsJSON,
-		Metrics:          metricsJSON,
-		ArtifactsPrefix:  artifactsPrefix,
+		RunID:             runID,
+		ExperimentID:      experimentID,
+		DatasetVersionID:  datasetVersionID,
+		DatasetVersionIDs: datasetVersionIDs,
+		Status:            status,
+		StartedAt:         startedAt,
+		EndedAt:           endedAt,
+		GitRepo:           gitRepo,
+		GitCommit:         gitCommit,
+		GitRef:            gitRef,
+		Params:            paramsJSON,
+		Metrics:           metricsJSON,
+		ArtifactsPrefix:   artifactsPrefix,
 	})
 }
 
@@ -883,88 +885,18 @@ func (api *experimentsAPI) handleListExperimentRuns(w http.ResponseWriter, r *ht
 		api.writeError(w, r, http.StatusBadRequest, "experiment_id_required")
 		return
 	}
-	limit := clampInt(parseIntQuery(r, "limit", 100), 1, 500)
-
-	rows, err := api.db.QueryContext(
-		r.Context(),
-		`SELECT r.run_id,
-				r.dataset_version_id,
-				COALESCE(s.status, r.status) AS status,
-				r.started_at,
-				COALESCE(r.ended_at, CASE WHEN s.status IN ('succeeded','failed','canceled') THEN s.observed_at END) AS ended_at,
-				r.git_repo,
-				r.git_commit,
-				r.git_ref,
-				r.params,
-				r.metrics,
-				r.artifacts_prefix
-		 FROM experiment_runs r
-		 LEFT JOIN LATERAL (
-			SELECT status, observed_at
-			FROM experiment_run_state_events
-			WHERE run_id = r.run_id
-			ORDER BY observed_at DESC
-			LIMIT 1
-		 ) s ON true
-		 WHERE r.experiment_id = $1
-		 ORDER BY r.started_at DESC
-		 LIMIT $2`,
-		experimentID,
-		limit,
-	)
-	if err != nil {
-		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
-		return
-	}
-	defer rows.Close()
-
-	out := make([]experimentRun, 0, limit)
-	for rows.Next() {
-		var (
-			runID            string
-			datasetVersionID sql.NullString
-			status           string
-			startedAt        time.Time
-			endedAt          sql.NullTime
-			gitRepo          sql.NullString
-			gitCommit        sql.NullString
-			gitRef           sql.NullString
-			params           []byte
-			metrics          []byte
-			artifactsPrefix  sql.NullString
-		)
-		if err := rows.Scan(&runID, &datasetVersionID, &status, &startedAt, &endedAt, &gitRepo, &gitCommit, &gitRef, &params, &metrics, &artifactsPrefix); err != nil {
-			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
-			return
-		}
-
-		var endedAtPtr *time.Time
-		if endedAt.Valid && !endedAt.Time.IsZero() {
-			t := endedAt.Time.UTC()
-			endedAtPtr = &t
-		}
+	limit := httpapi.Limit(r, 100, 500)
 
-		out = append(out, experimentRun{
-			RunID:            runID,
-			ExperimentID:     experimentID,
-			DatasetVersionID: strings.TrimSpace(datasetVersionID.String),
-			Status:           status,
-			StartedAt:        startedAt,
-			EndedAt:          endedAtPtr,
-			GitRepo:          strings.TrimSpace(gitRepo.String),
-			GitCommit:        strings.TrimSpace(gitCommit.String),
-			GitRef:           strings.TrimSpace(gitRef.String),
-			Params:           normalizeJSON(params),
-			Metrics:          normalizeJSON(metrics),
-			ArtifactsPrefix:  strings.TrimSpace(artifactsPrefix.String),
-		})
-	}
-	if err := rows.Err(); err != nil {
+	runs, err := api.runStore(api.db).ListRunSummaries(r.Context(), repo.RunSummaryFilter{
+		ExperimentID: experimentID,
+		Limit:        limit,
+	})
+	if err != nil {
 		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
 		return
 	}
 
-	api.writeJSON(w, http.StatusOK, map[string]any{"runs": out})
+	api.writeJSON(w, http.StatusOK, map[string]any{"runs": experimentRunsFromSummaries(runs)})
 }
 
 func (api *experimentsAPI) handleListAllExperimentRuns(w http.ResponseWriter, r *http.Request) {
@@ -973,7 +905,7 @@ func (api *experimentsAPI) handleListAllExperimentRuns(w http.ResponseWriter, r
 		api.writeError(w, r, http.StatusBadRequest, "project_id_required")
 		return
 	}
-	limit := clampInt(parseIntQuery(r, "limit", 100), 1, 500)
+	limit := httpapi.Limit(r, 100, 500)
 
 	statusFilter := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("status")))
 	if statusFilter != "" {
@@ -992,92 +924,18 @@ func (api *experimentsAPI) handleListAllExperimentRuns(w http.ResponseWriter, r
 		}
 	}
 
-	rows, err := api.db.QueryContext(
-		r.Context(),
-		`SELECT r.run_id,
-				r.experiment_id,
-				r.dataset_version_id,
-				COALESCE(s.status, r.status) AS status,
-				r.started_at,
-				COALESCE(r.ended_at, CASE WHEN s.status IN ('succeeded','failed','canceled') THEN s.observed_at END) AS ended_at,
-				r.git_repo,
-				r.git_commit,
-				r.git_ref,
-				r.params,
-				r.metrics,
-				r.artifacts_prefix
-		 FROM experiment_runs r
-		 LEFT JOIN LATERAL (
-			SELECT status, observed_at
-			FROM experiment_run_state_events
-			WHERE run_id = r.run_id
-			ORDER BY observed_at DESC
-			LIMIT 1
-		 ) s ON true
-		 WHERE r.project_id = $1
-		   AND ($2::bool IS false OR COALESCE(s.status, r.status) IN ('pending','running'))
-		   AND ($3 = '' OR COALESCE(s.status, r.status) = $3)
-		 ORDER BY r.started_at DESC
-		 LIMIT $4`,
-		projectID,
-		active,
-		statusFilter,
-		limit,
-	)
+	runs, err := api.runStore(api.db).ListRunSummaries(r.Context(), repo.RunSummaryFilter{
+		ProjectID:  projectID,
+		Status:     statusFilter,
+		ActiveOnly: active,
+		Limit:      limit,
+	})
 	if err != nil {
 		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
 		return
 	}
-	defer rows.Close()
-
-	out := make([]experimentRun, 0, limit)
-	for rows.Next() {
-		var (
-			runID            string
-			experimentID     string
-			datasetVersionID sql.NullString
-			status           string
-			startedAt        time.Time
-			endedAt          sql.NullTime
-			gitRepo          sql.NullString
-			gitCommit        sql.NullString
-			gitRef           sql.NullString
-			params           []byte
-			metrics          []byte
-			artifactsPrefix  sql.NullString
-		)
-		if err := rows.Scan(&runID, &experimentID, &datasetVersionID, &status, &startedAt, &endedAt, &gitRepo, &gitCommit, &gitRef, &params, &metrics, &artifactsPrefix); err != nil {
-			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
-			return
-		}
-
-		var endedAtPtr *time.Time
-		if endedAt.Valid && !endedAt.Time.IsZero() {
-			t := endedAt.Time.UTC()
-			endedAtPtr = &t
-		}
-
-		out = append(out, experimentRun{
-			RunID:            runID,
-			ExperimentID:     experimentID,
-			DatasetVersionID: strings.TrimSpace(datasetVersionID.String),
-			Status:           status,
-			StartedAt:        startedAt,
-			EndedAt:          endedAtPtr,
-			GitRepo:          strings.TrimSpace(gitRepo.String),
-			GitCommit:        strings.TrimSpace(gitCommit.String),
-			GitRef:           strings.TrimSpace(gitRef.String),
-			Params:           normalizeJSON(params),
-			Metrics:          normalizeJSON(metrics),
-			ArtifactsPrefix:  strings.TrimSpace(artifactsPrefix.String),
-		})
-	}
-	if err := rows.Err(); err != nil {
-		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
-		return
-	}
 
-	api.writeJSON(w, http.StatusOK, map[string]any{"runs": out})
+	api.writeJSON(w, http.StatusOK, map[string]any{"runs": experimentRunsFromSummaries(runs)})
 }
 
 func (api *experimentsAPI) handleGetExperimentRun(w http.ResponseWriter, r *http.Request) {
@@ -1087,45 +945,9 @@ func (api *experimentsAPI) handleGetExperimentRun(w http.ResponseWriter, r *http
 		return
 	}
 
-	var (
-		experimentID     string
-		datasetVersionID sql.NullString
-		status           string
-		startedAt        time.Time
-		endedAt          sql.NullTime
-		gitRepo          sql.NullString
-		gitCommit        sql.NullString
-		gitRef           sql.NullString
-		params           []byte
-		metrics          []byte
-		artifactsPrefix  sql.NullString
-	)
-	err := api.db.QueryRowContext(
-		r.Context(),
-		`SELECT r.experiment_id,
-				r.dataset_version_id,
-				COALESCE(s.status, r.status) AS status,
-				r.started_at,
-				COALESCE(r.ended_at, CASE WHEN s.status IN ('succeeded','failed','canceled') THEN s.observed_at END) AS ended_at,
-				r.git_repo,
-				r.git_commit,
-				r.git_ref,
-				r.params,
-				r.metrics,
-				r.artifacts_prefix
-		 FROM experiment_runs r
-		 LEFT JOIN LATERAL (
-			SELECT status, observed_at
-			FROM experiment_run_state_events
-			WHERE run_id = r.run_id
-			ORDER BY observed_at DESC
-			LIMIT 1
-		 ) s ON true
-		 WHERE r.run_id = $1`,
-		runID,
-	).Scan(&experimentID, &datasetVersionID, &status, &startedAt, &endedAt, &gitRepo, &gitCommit, &gitRef, &params, &metrics, &artifactsPrefix)
+	run, err := api.getExperimentRun(r.Context(), runID)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
+		if errors.Is(err, repo.ErrNotFound) {
 			api.writeError(w, r, http.StatusNotFound, "not_found")
 			return
 		}
@@ -1133,26 +955,41 @@ func (api *experimentsAPI) handleGetExperimentRun(w http.ResponseWriter, r *http
 		return
 	}
 
-	var endedAtPtr *time.Time
-	if endedAt.Valid && !endedAt.Time.IsZero() {
-		t := endedAt.Time.UTC()
-		endedAtPtr = &t
-	}
-
-	api.writeJSON(w, http.StatusOK, experimentRun{
-		RunID:            runID,
-		ExperimentID:     experimentID,
-		DatasetVersionID: strings.TrimSpace(datasetVersionID.String),
-		Status:           status,
-		StartedAt:        startedAt,
-		EndedAt:          endedAtPtr,
-		GitRepo:          strings.TrimSpace(gitRepo.String),
-		GitCommit:        strings.TrimSpace(gitCommit.String),
-		GitRef:           strings.TrimSpace(gitRef.String),
-		Params:           normalizeJSON(params),
-		Metrics:          normalizeJSON(metrics),
-		ArtifactsPrefix:  strings.TrimSpace(artifactsPrefix.String),
-	})
+	api.writeJSON(w, http.StatusOK, run)
+}
+
+func (api *experimentsAPI) getExperimentRun(ctx context.Context, runID string) (experimentRun, error) {
+	summary, err := api.runStore(api.db).GetRunSummary(ctx, runID)
+	if err != nil {
+		return experimentRun{}, err
+	}
+	return experimentRunFromSummary(summary), nil
+}
+
+func experimentRunFromSummary(summary repo.RunSummary) experimentRun {
+	return experimentRun{
+		RunID:             summary.RunID,
+		ExperimentID:      summary.ExperimentID,
+		DatasetVersionID:  summary.DatasetVersionID,
+		DatasetVersionIDs: summary.DatasetVersionIDs,
+		Status:            summary.Status,
+		StartedAt:         summary.StartedAt,
+		EndedAt:           summary.EndedAt,
+		GitRepo:           summary.GitRepo,
+		GitCommit:         summary.GitCommit,
+		GitRef:            summary.GitRef,
+		Params:            normalizeJSON(summary.Params),
+		Metrics:           normalizeJSON(summary.Metrics),
+		ArtifactsPrefix:   summary.ArtifactsPrefix,
+	}
+}
+
+func experimentRunsFromSummaries(summaries []repo.RunSummary) []experimentRun {
+	out := make([]experimentRun, 0, len(summaries))
+	for _, summary := range summaries {
+		out = append(out, experimentRunFromSummary(summary))
+	}
+	return out
 }
 
 type gateDecision struct {
@@ -1161,43 +998,92 @@ type gateDecision struct {
 	RuleID        string
 	EvaluationID  string
 	Status        string
+	Protection    domain.DataProtection
+	Lifecycle     domain.DatasetLifecycleState
 }
 
 func (api *experimentsAPI) requireQualityGatePass(w http.ResponseWriter, r *http.Request, identity auth.Identity, datasetVersionID string, experimentID string) (gateDecision, bool) {
 	ctx := r.Context()
 
-	var (
-		datasetID     string
-		qualityRuleID sql.NullString
-		contentSHA256 string
-	)
-	err := api.db.QueryRowContext(
-		ctx,
-		`SELECT dataset_id, quality_rule_id, content_sha256
-		 FROM dataset_versions
-		 WHERE version_id = $1`,
-		datasetVersionID,
-	).Scan(&datasetID, &qualityRuleID, &contentSHA256)
+	gate, err := api.runStore(api.db).GetRunDatasetGate(ctx, datasetVersionID)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
+		if errors.Is(err, repo.ErrNotFound) {
 			api.writeError(w, r, http.StatusNotFound, "not_found")
 			return gateDecision{}, false
 		}
 		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
 		return gateDecision{}, false
 	}
+	datasetID := gate.DatasetID
 
-	ruleID := strings.TrimSpace(qualityRuleID.String)
+	protection, err := api.loadDataProtection(ctx, datasetVersionID)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return gateDecision{}, false
+	}
+	lifecycle, err := api.loadDatasetLifecycle(ctx, datasetID)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return gateDecision{}, false
+	}
+	if protection.Recall != nil {
+		_ = auditlog.Enqueue(ctx, api.db, auditlog.Event{
+			OccurredAt:   time.Now().UTC(),
+			Actor:        identity.Subject,
+			Action:       "quality_gate.block",
+			ResourceType: "dataset_version",
+			ResourceID:   datasetVersionID,
+			RequestID:    r.Header.Get("X-Request-Id"),
+			IP:           httpapi.RequestIP(r.RemoteAddr),
+			UserAgent:    r.UserAgent(),
+			Payload: map[string]any{
+				"service":            "experiments",
+				"dataset_id":         datasetID,
+				"dataset_version_id": datasetVersionID,
+				"experiment_id":      experimentID,
+				"data_protection":    dataProtectionPayload(protection),
+				"reason":             "recalled",
+			},
+		})
+		api.writeError(w, r, http.StatusConflict, "dataset_version_recalled")
+		return gateDecision{}, false
+	}
+	// A reference version whose object changed or vanished can no longer
+	// deliver the registered content.
+	if gate.ReferenceState != "" && gate.ReferenceState != "verified" {
+		_ = auditlog.Enqueue(ctx, api.db, auditlog.Event{
+			OccurredAt:   time.Now().UTC(),
+			Actor:        identity.Subject,
+			Action:       "quality_gate.block",
+			ResourceType: "dataset_version",
+			ResourceID:   datasetVersionID,
+			RequestID:    r.Header.Get("X-Request-Id"),
+			IP:           httpapi.RequestIP(r.RemoteAddr),
+			UserAgent:    r.UserAgent(),
+			Payload: map[string]any{
+				"service":            "experiments",
+				"dataset_id":         datasetID,
+				"dataset_version_id": datasetVersionID,
+				"experiment_id":      experimentID,
+				"reference_state":    gate.ReferenceState,
+				"reason":             "reference_changed",
+			},
+		})
+		api.writeError(w, r, http.StatusConflict, "dataset_version_reference_changed")
+		return gateDecision{}, false
+	}
+
+	ruleID := gate.QualityRuleID
 	if ruleID == "" {
 		now := time.Now().UTC()
-		_, _ = auditlog.Insert(ctx, api.db, auditlog.Event{
+		_ = auditlog.Enqueue(ctx, api.db, auditlog.Event{
 			OccurredAt:   now,
 			Actor:        identity.Subject,
 			Action:       "quality_gate.block",
 			ResourceType: "dataset_version",
 			ResourceID:   datasetVersionID,
 			RequestID:    r.Header.Get("X-Request-Id"),
-			IP:           requestIP(r.RemoteAddr),
+			IP:           httpapi.RequestIP(r.RemoteAddr),
 			UserAgent:    r.UserAgent(),
 			Payload: map[string]any{
 				"service":            "experiments",
@@ -1211,58 +1097,41 @@ func (api *experimentsAPI) requireQualityGatePass(w http.ResponseWriter, r *http
 		return gateDecision{}, false
 	}
 
-	var (
-		evalID     string
-		evalStatus string
-	)
-	err = api.db.QueryRowContext(
-		ctx,
-		`SELECT evaluation_id, status
-		 FROM quality_evaluations
-		 WHERE dataset_version_id = $1 AND rule_id = $2
-		 ORDER BY evaluated_at DESC
-		 LIMIT 1`,
-		datasetVersionID,
-		ruleID,
-	).Scan(&evalID, &evalStatus)
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			now := time.Now().UTC()
-			_, _ = auditlog.Insert(ctx, api.db, auditlog.Event{
-				OccurredAt:   now,
-				Actor:        identity.Subject,
-				Action:       "quality_gate.block",
-				ResourceType: "dataset_version",
-				ResourceID:   datasetVersionID,
-				RequestID:    r.Header.Get("X-Request-Id"),
-				IP:           requestIP(r.RemoteAddr),
-				UserAgent:    r.UserAgent(),
-				Payload: map[string]any{
-					"service":            "experiments",
-					"dataset_id":         datasetID,
-					"dataset_version_id": datasetVersionID,
-					"rule_id":            ruleID,
-					"experiment_id":      experimentID,
-					"reason":             "not_evaluated",
-				},
-			})
-			api.writeError(w, r, http.StatusConflict, "quality_not_evaluated")
-			return gateDecision{}, false
-		}
-		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+	if gate.QualityEvaluation == nil {
+		now := time.Now().UTC()
+		_ = auditlog.Enqueue(ctx, api.db, auditlog.Event{
+			OccurredAt:   now,
+			Actor:        identity.Subject,
+			Action:       "quality_gate.block",
+			ResourceType: "dataset_version",
+			ResourceID:   datasetVersionID,
+			RequestID:    r.Header.Get("X-Request-Id"),
+			IP:           httpapi.RequestIP(r.RemoteAddr),
+			UserAgent:    r.UserAgent(),
+			Payload: map[string]any{
+				"service":            "experiments",
+				"dataset_id":         datasetID,
+				"dataset_version_id": datasetVersionID,
+				"rule_id":            ruleID,
+				"experiment_id":      experimentID,
+				"reason":             "not_evaluated",
+			},
+		})
+		api.writeError(w, r, http.StatusConflict, "quality_not_evaluated")
 		return gateDecision{}, false
 	}
+	evalID, evalStatus := gate.QualityEvaluation.EvaluationID, gate.QualityEvaluation.Status
 
 	if strings.ToLower(strings.TrimSpace(evalStatus)) != "pass" {
 		now := time.Now().UTC()
-		_, _ = auditlog.Insert(ctx, api.db, auditlog.Event{
+		_ = auditlog.Enqueue(ctx, api.db, auditlog.Event{
 			OccurredAt:   now,
 			Actor:        identity.Subject,
 			Action:       "quality_gate.block",
 			ResourceType: "dataset_version",
 			ResourceID:   datasetVersionID,
 			RequestID:    r.Header.Get("X-Request-Id"),
-			IP:           requestIP(r.RemoteAddr),
+			IP:           httpapi.RequestIP(r.RemoteAddr),
 			UserAgent:    r.UserAgent(),
 			Payload: map[string]any{
 				"service":            "experiments",
@@ -1279,29 +1148,49 @@ func (api *experimentsAPI) requireQualityGatePass(w http.ResponseWriter, r *http
 		return gateDecision{}, false
 	}
 
+	// Versions evaluated against a data contract are consumable only if the
+	// latest evaluation passed; versions without one predate the contract.
+	if contract := gate.ContractEvaluation; contract != nil {
+		if contract.Status != "pass" {
+			now := time.Now().UTC()
+			_ = auditlog.Enqueue(ctx, api.db, auditlog.Event{
+				OccurredAt:   now,
+				Actor:        identity.Subject,
+				Action:       "quality_gate.block",
+				ResourceType: "dataset_version",
+				ResourceID:   datasetVersionID,
+				RequestID:    r.Header.Get("X-Request-Id"),
+				IP:           httpapi.RequestIP(r.RemoteAddr),
+				UserAgent:    r.UserAgent(),
+				Payload: map[string]any{
+					"service":                     "experiments",
+					"dataset_id":                  datasetID,
+					"dataset_version_id":          datasetVersionID,
+					"rule_id":                     ruleID,
+					"evaluation_id":               evalID,
+					"data_contract_evaluation_id": contract.EvaluationID,
+					"experiment_id":               experimentID,
+					"reason":                      "data_contract_breached",
+				},
+			})
+			api.writeError(w, r, http.StatusConflict, "data_contract_breached")
+			return gateDecision{}, false
+		}
+	}
+
 	return gateDecision{
 		DatasetID:     datasetID,
-		ContentSHA256: strings.TrimSpace(contentSHA256),
+		ContentSHA256: gate.ContentSHA256,
 		RuleID:        ruleID,
 		EvaluationID:  evalID,
 		Status:        evalStatus,
+		Protection:    protection,
+		Lifecycle:     lifecycle.State,
 	}, true
 }
 
 func (api *experimentsAPI) experimentExists(ctx context.Context, experimentID string) (bool, error) {
-	var one int
-	err := api.db.QueryRowContext(
-		ctx,
-		`SELECT 1 FROM experiments WHERE experiment_id = $1`,
-		experimentID,
-	).Scan(&one)
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return false, nil
-		}
-		return false, err
-	}
-	return true, nil
+	return api.runStore(api.db).ExperimentExists(ctx, experimentID)
 }
 
 func formatTimePtr(t *time.Time) string {
@@ -1318,31 +1207,12 @@ func nullTimePtr(value *time.Time) sql.NullTime {
 	return sql.NullTime{Time: value.UTC(), Valid: true}
 }
 
-func decodeJSON(r *http.Request, dst any) error {
-	dec := json.NewDecoder(io.LimitReader(r.Body, 1<<20))
-	dec.DisallowUnknownFields()
-	if err := dec.Decode(dst); err != nil {
-		return err
-	}
-	if err := dec.Decode(&struct{}{}); err != io.EOF {
-		return errors.New("multiple JSON values")
-	}
-	return nil
-}
-
 func (api *experimentsAPI) writeJSON(w http.ResponseWriter, status int, body any) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	enc := json.NewEncoder(w)
-	enc.SetEscapeHTML(true)
-	_ = enc.Encode(body)
+	httpapi.WriteJSON(w, status, body)
 }
 
 func (api *experimentsAPI) writeError(w http.ResponseWriter, r *http.Request, status int, code string) {
-	api.writeJSON(w, status, map[string]any{
-		"error":      code,
-		"request_id": r.Header.Get("X-Request-Id"),
-	})
+	httpapi.WriteError(w, r, status, code)
 }
 
 func (api *experimentsAPI) writeRepoError(w http.ResponseWriter, r *http.Request, err error) {
@@ -1367,24 +1237,9 @@ func (api *experimentsAPI) writeRepoError(w http.ResponseWriter, r *http.Request
 	}
 }
 
-func requestIP(remoteAddr string) net.IP {
-	host, _, err := net.SplitHostPort(remoteAddr)
-	if err != nil {
-		return nil
-	}
-	return net.ParseIP(host)
-}
-
+// normalizeJSON also redacts secrets, unlike httpapi.NormalizeJSON.
 func normalizeJSON(raw []byte) json.RawMessage {
-	raw = bytesTrimSpace(raw)
-	if len(raw) == 0 || string(raw) == "null" {
-		return []byte("{}")
-	}
-	return redaction.RedactJSON(raw)
-}
-
-func bytesTrimSpace(in []byte) []byte {
-	return []byte(strings.TrimSpace(string(in)))
+	return redaction.RedactJSON(httpapi.NormalizeJSON(raw))
 }
 
 func nullString(value string) sql.NullString {
@@ -1410,25 +1265,3 @@ func isForeignKeyViolation(err error) bool {
 	}
 	return false
 }
-
-func parseIntQuery(r *http.Request, key string, def int) int {
-	v := strings.TrimSpace(r.URL.Query().Get(key))
-	if v == "" {
-		return def
-	}
-	parsed, err := strconv.Atoi(v)
-	if err != nil {
-		return def
-	}
-	return parsed
-}
-
-func clampInt(v int, min int, max int) int {
-	if v < min {
-		return min
-	}
-	if v > max {
-		return max
-	}
-	return v
-}
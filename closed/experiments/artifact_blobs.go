@@ -0,0 +1,203 @@
+package experiments
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+
+	platformpg "github.com/animus-labs/animus-go/closed/internal/platform/postgres"
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+	"github.com/animus-labs/animus-go/closed/internal/repo/postgres"
+	"github.com/minio/minio-go/v7"
+)
+
+// artifactBlobSHA256Meta is the user metadata key holding a blob's SHA-256,
+// checked before an existing object is reused.
+const artifactBlobSHA256Meta = "Sha256"
+
+const artifactBlobGCBatch = 100
+
+var sha256HexPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// errArtifactContentRequired reports that the server does not hold a
+// verified copy of a declared blob, so the client must upload the file.
+var errArtifactContentRequired = errors.New("artifact content required")
+
+// artifactBlobStore is the part of the object store the blob store uses.
+type artifactBlobStore interface {
+	StatObject(ctx context.Context, bucket, key string, opts minio.StatObjectOptions) (minio.ObjectInfo, error)
+	PutObject(ctx context.Context, bucket, key string, reader io.Reader, size int64, opts minio.PutObjectOptions) (minio.UploadInfo, error)
+	RemoveObject(ctx context.Context, bucket, key string, opts minio.RemoveObjectOptions) error
+}
+
+type artifactBlob struct {
+	SHA256    string
+	ObjectKey string
+	SizeBytes int64
+	// Deduplicated is true when an existing object was reused and nothing
+	// was written to the object store.
+	Deduplicated bool
+}
+
+func artifactBlobKey(sha256Hex string) string {
+	return fmt.Sprintf("blobs/sha256/%s/%s", sha256Hex[:2], sha256Hex)
+}
+
+// artifactBlobVerified reports whether info describes the blob sha256Hex of
+// size bytes as written by referenceArtifactBlob.
+func artifactBlobVerified(info minio.ObjectInfo, sha256Hex string, size int64) bool {
+	if info.Size != size {
+		return false
+	}
+	for key, value := range info.UserMetadata {
+		if strings.EqualFold(key, artifactBlobSHA256Meta) {
+			return strings.EqualFold(strings.TrimSpace(value), sha256Hex)
+		}
+	}
+	return false
+}
+
+// referenceArtifactBlob adds a reference to the blob sha256Hex inside tx,
+// creating it when needed. The blob row stays locked until tx ends, so the
+// garbage collector cannot remove the object meanwhile. An object that
+// already exists with the recorded size and hash is reused; otherwise
+// content is uploaded, and without content errArtifactContentRequired is
+// returned. size is ignored when content is nil.
+//
+// Digests are shared across projects, but a reference without content is
+// only accepted when projectID has already referenced the blob; otherwise
+// errArtifactContentRequired is returned even if another project holds it.
+// Runs outside any project always upload.
+//
+// An object written by a transaction that later rolls back has no row; the
+// next upload of the same content finds and reuses it.
+func referenceArtifactBlob(ctx context.Context, tx postgres.DB, store artifactBlobStore, bucket, projectID, sha256Hex string, content io.Reader, size int64, contentType string, now time.Time) (artifactBlob, error) {
+	blob := artifactBlob{SHA256: sha256Hex, ObjectKey: artifactBlobKey(sha256Hex), SizeBytes: size}
+	blobs := postgres.NewArtifactBlobStore(tx)
+	projectID = strings.TrimSpace(projectID)
+	if content == nil {
+		if projectID == "" {
+			return artifactBlob{}, errArtifactContentRequired
+		}
+		held, err := blobs.ProjectHolds(ctx, projectID, sha256Hex)
+		if err != nil {
+			return artifactBlob{}, err
+		}
+		if !held {
+			return artifactBlob{}, errArtifactContentRequired
+		}
+	} else if err := blobs.Ensure(ctx, sha256Hex, blob.ObjectKey, size, now); err != nil {
+		return artifactBlob{}, err
+	}
+	objectKey, sizeBytes, err := blobs.Lock(ctx, sha256Hex)
+	if errors.Is(err, repo.ErrNotFound) {
+		return artifactBlob{}, errArtifactContentRequired
+	}
+	if err != nil {
+		return artifactBlob{}, err
+	}
+	blob.ObjectKey, blob.SizeBytes = objectKey, sizeBytes
+
+	info, err := store.StatObject(ctx, bucket, blob.ObjectKey, minio.StatObjectOptions{})
+	switch {
+	case err == nil && artifactBlobVerified(info, sha256Hex, blob.SizeBytes):
+		blob.Deduplicated = true
+	case err != nil && minio.ToErrorResponse(err).Code != "NoSuchKey":
+		return artifactBlob{}, fmt.Errorf("stat blob: %w", err)
+	case content == nil:
+		return artifactBlob{}, errArtifactContentRequired
+	default:
+		if _, err := store.PutObject(ctx, bucket, blob.ObjectKey, content, blob.SizeBytes, minio.PutObjectOptions{
+			ContentType:  contentType,
+			UserMetadata: map[string]string{artifactBlobSHA256Meta: sha256Hex},
+		}); err != nil {
+			return artifactBlob{}, fmt.Errorf("put blob: %w", err)
+		}
+	}
+
+	if err := blobs.Reference(ctx, projectID, sha256Hex, now); err != nil {
+		return artifactBlob{}, err
+	}
+	return blob, nil
+}
+
+// releaseArtifactBlob drops one reference to sha256Hex. The blob becomes
+// eligible for collection once nothing references it for the grace period.
+func releaseArtifactBlob(ctx context.Context, db postgres.DB, sha256Hex string, now time.Time) error {
+	return postgres.NewArtifactBlobStore(db).Release(ctx, sha256Hex, now)
+}
+
+type artifactBlobGC struct {
+	db     *sql.DB
+	store  artifactBlobStore
+	bucket string
+	grace  time.Duration
+}
+
+func startArtifactBlobGC(ctx context.Context, logger *slog.Logger, db *sql.DB, store artifactBlobStore, bucket string, interval, grace time.Duration) {
+	if db == nil || store == nil {
+		return
+	}
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	gc := &artifactBlobGC{db: db, store: store, bucket: bucket, grace: grace}
+	syncer{
+		name:       "artifact_blob_gc",
+		logger:     logger,
+		leader:     platformpg.NewLeader(db, "artifact_blob_gc"),
+		interval:   interval,
+		maxBackoff: 4 * interval,
+		pass: func(ctx context.Context) error {
+			_, err := gc.collect(ctx, time.Now().UTC())
+			return err
+		},
+	}.start(ctx)
+}
+
+// collect removes up to artifactBlobGCBatch blobs unreferenced since before
+// now minus the grace period. Each blob is deleted in its own transaction
+// that holds the row lock while the object is removed, so a concurrent
+// upload either waits and recreates the blob or is skipped by the GC.
+func (gc *artifactBlobGC) collect(ctx context.Context, now time.Time) (int, error) {
+	cutoff := now.Add(-gc.grace)
+	removed := 0
+	for removed < artifactBlobGCBatch {
+		ok, err := gc.collectOne(ctx, cutoff)
+		if err != nil {
+			return removed, err
+		}
+		if !ok {
+			break
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+func (gc *artifactBlobGC) collectOne(ctx context.Context, cutoff time.Time) (bool, error) {
+	tx, err := gc.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	blobs := postgres.NewArtifactBlobStore(tx)
+	sha256Hex, objectKey, ok, err := blobs.LockCollectable(ctx, cutoff)
+	if err != nil || !ok {
+		return false, err
+	}
+	if err := gc.store.RemoveObject(ctx, gc.bucket, objectKey, minio.RemoveObjectOptions{}); err != nil {
+		return false, fmt.Errorf("remove blob %s: %w", sha256Hex, err)
+	}
+	if err := blobs.Delete(ctx, sha256Hex); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
@@ -0,0 +1,35 @@
+package experiments
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/minio/minio-go/v7"
+)
+
+func TestArtifactBlobKey(t *testing.T) {
+	sum := strings.Repeat("ab", 32)
+	if got := artifactBlobKey(sum); got != "blobs/sha256/ab/"+sum {
+		t.Fatalf("artifactBlobKey()=%q", got)
+	}
+	if !sha256HexPattern.MatchString(sum) || sha256HexPattern.MatchString(strings.ToUpper(sum)) || sha256HexPattern.MatchString(sum[:63]) {
+		t.Fatalf("sha256HexPattern mismatch")
+	}
+}
+
+func TestArtifactBlobVerified(t *testing.T) {
+	sum := strings.Repeat("0f", 32)
+	info := minio.ObjectInfo{Size: 42, UserMetadata: map[string]string{"Sha256": sum}}
+	if !artifactBlobVerified(info, sum, 42) {
+		t.Fatalf("expected blob to verify")
+	}
+	if artifactBlobVerified(info, sum, 41) {
+		t.Fatalf("expected size mismatch to fail")
+	}
+	if artifactBlobVerified(info, strings.Repeat("1f", 32), 42) {
+		t.Fatalf("expected hash mismatch to fail")
+	}
+	if artifactBlobVerified(minio.ObjectInfo{Size: 42}, sum, 42) {
+		t.Fatalf("expected object without recorded hash to fail")
+	}
+}
@@ -0,0 +1,585 @@
+package experiments
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	platformpg "github.com/animus-labs/animus-go/closed/internal/platform/postgres"
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+	"github.com/animus-labs/animus-go/closed/internal/repo/postgres"
+	"github.com/minio/minio-go/v7"
+)
+
+const (
+	retentionScopeProject    = "project"
+	retentionScopeExperiment = "experiment"
+
+	artifactRetentionActor = "system:artifact-retention"
+	// artifactRetentionBatch caps the artifacts deleted per experiment and
+	// pass, and the artifacts listed by a preview.
+	artifactRetentionBatch = 500
+
+	maxRetentionKeepLastRuns = 10000
+	maxRetentionKeepBestRuns = 1000
+)
+
+// Reasons a run keeps its artifacts.
+const (
+	retentionKeepActive     = "active"
+	retentionKeepReferenced = "referenced"
+	retentionKeepLast       = "keep_last"
+	retentionKeepBest       = "keep_best"
+)
+
+type artifactRetentionKeepBest struct {
+	Metric string `json:"metric"`
+	Order  string `json:"order"`
+	Runs   int    `json:"runs"`
+}
+
+type artifactRetentionPolicy struct {
+	ScopeType    string                     `json:"scope_type"`
+	ScopeID      string                     `json:"scope_id"`
+	ProjectID    string                     `json:"project_id"`
+	KeepLastRuns *int                       `json:"keep_last_runs,omitempty"`
+	KeepBest     *artifactRetentionKeepBest `json:"keep_best,omitempty"`
+	UpdatedAt    time.Time                  `json:"updated_at"`
+	UpdatedBy    string                     `json:"updated_by"`
+}
+
+type setArtifactRetentionRequest struct {
+	KeepLastRuns *int                       `json:"keep_last_runs"`
+	KeepBest     *artifactRetentionKeepBest `json:"keep_best"`
+}
+
+// normalizeArtifactRetention validates req; at least one keep rule is
+// required and keep_best.order defaults to desc.
+func normalizeArtifactRetention(req setArtifactRetentionRequest) (setArtifactRetentionRequest, bool) {
+	if req.KeepLastRuns == nil && req.KeepBest == nil {
+		return setArtifactRetentionRequest{}, false
+	}
+	if req.KeepLastRuns != nil && (*req.KeepLastRuns < 0 || *req.KeepLastRuns > maxRetentionKeepLastRuns) {
+		return setArtifactRetentionRequest{}, false
+	}
+	if req.KeepBest != nil {
+		best := *req.KeepBest
+		best.Metric = strings.TrimSpace(best.Metric)
+		best.Order = strings.ToLower(strings.TrimSpace(best.Order))
+		if best.Order == "" {
+			best.Order = leaderboardOrderDesc
+		}
+		if best.Metric == "" || len(best.Metric) > 200 || best.Runs <= 0 || best.Runs > maxRetentionKeepBestRuns {
+			return setArtifactRetentionRequest{}, false
+		}
+		if best.Order != leaderboardOrderAsc && best.Order != leaderboardOrderDesc {
+			return setArtifactRetentionRequest{}, false
+		}
+		req.KeepBest = &best
+	}
+	return req, true
+}
+
+type retentionRun struct {
+	RunID       string
+	StartedAt   time.Time
+	Active      bool
+	Referenced  bool
+	MetricValue *float64
+}
+
+type retentionDecision struct {
+	RunID   string   `json:"run_id"`
+	Reasons []string `json:"reasons"`
+}
+
+// planArtifactRetention splits runs into those that keep their artifacts,
+// with every rule that keeps them, and the expired rest. Active runs and
+// runs referenced by a model version or evidence bundle are always kept.
+func planArtifactRetention(policy artifactRetentionPolicy, runs []retentionRun) ([]retentionDecision, []string) {
+	byStart := append([]retentionRun(nil), runs...)
+	sort.SliceStable(byStart, func(i, j int) bool {
+		if !byStart[i].StartedAt.Equal(byStart[j].StartedAt) {
+			return byStart[i].StartedAt.After(byStart[j].StartedAt)
+		}
+		return byStart[i].RunID < byStart[j].RunID
+	})
+
+	reasons := map[string][]string{}
+	for i, run := range byStart {
+		if run.Active {
+			reasons[run.RunID] = append(reasons[run.RunID], retentionKeepActive)
+		}
+		if run.Referenced {
+			reasons[run.RunID] = append(reasons[run.RunID], retentionKeepReferenced)
+		}
+		if policy.KeepLastRuns != nil && i < *policy.KeepLastRuns {
+			reasons[run.RunID] = append(reasons[run.RunID], retentionKeepLast)
+		}
+	}
+	if best := policy.KeepBest; best != nil {
+		ranked := make([]retentionRun, 0, len(byStart))
+		for _, run := range byStart {
+			if run.MetricValue != nil {
+				ranked = append(ranked, run)
+			}
+		}
+		// Stable over byStart, so ties go to the newer run.
+		sort.SliceStable(ranked, func(i, j int) bool {
+			if best.Order == leaderboardOrderAsc {
+				return *ranked[i].MetricValue < *ranked[j].MetricValue
+			}
+			return *ranked[i].MetricValue > *ranked[j].MetricValue
+		})
+		for i := 0; i < len(ranked) && i < best.Runs; i++ {
+			reasons[ranked[i].RunID] = append(reasons[ranked[i].RunID], retentionKeepBest)
+		}
+	}
+
+	kept := []retentionDecision{}
+	expired := []string{}
+	for _, run := range byStart {
+		if len(reasons[run.RunID]) > 0 {
+			kept = append(kept, retentionDecision{RunID: run.RunID, Reasons: reasons[run.RunID]})
+			continue
+		}
+		expired = append(expired, run.RunID)
+	}
+	return kept, expired
+}
+
+type retentionArtifact struct {
+	ArtifactID string `json:"artifact_id"`
+	RunID      string `json:"run_id"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name,omitempty"`
+	ObjectKey  string `json:"object_key"`
+	SHA256     string `json:"sha256"`
+	SizeBytes  int64  `json:"size_bytes"`
+	blobSHA256 string
+}
+
+type artifactRetentionPreview struct {
+	ExperimentID  string                  `json:"experiment_id"`
+	Policy        artifactRetentionPolicy `json:"policy"`
+	KeptRuns      []retentionDecision     `json:"kept_runs"`
+	ExpiredRunIDs []string                `json:"expired_run_ids"`
+	Artifacts     []retentionArtifact     `json:"artifacts"`
+	TotalBytes    int64                   `json:"total_bytes"`
+}
+
+func artifactRetentionPolicyFromRecord(record postgres.ArtifactRetentionPolicyRecord) artifactRetentionPolicy {
+	policy := artifactRetentionPolicy{
+		ScopeType:    record.ScopeType,
+		ScopeID:      record.ScopeID,
+		ProjectID:    record.ProjectID,
+		KeepLastRuns: record.KeepLastRuns,
+		UpdatedAt:    record.UpdatedAt,
+		UpdatedBy:    record.UpdatedBy,
+	}
+	if record.KeepBestMetric != "" {
+		policy.KeepBest = &artifactRetentionKeepBest{Metric: record.KeepBestMetric, Order: record.KeepBestOrder, Runs: record.KeepBestRuns}
+	}
+	return policy
+}
+
+func loadRetentionRuns(ctx context.Context, store *postgres.ArtifactRetentionStore, experimentID string, policy artifactRetentionPolicy) ([]retentionRun, error) {
+	metric := ""
+	if policy.KeepBest != nil {
+		metric = policy.KeepBest.Metric
+	}
+	records, err := store.ListRuns(ctx, experimentID, metric)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]retentionRun, 0, len(records))
+	for _, record := range records {
+		out = append(out, retentionRun{
+			RunID:       record.RunID,
+			StartedAt:   record.StartedAt,
+			Active:      !isTerminalRunStatus(record.Status),
+			Referenced:  record.Referenced,
+			MetricValue: record.MetricValue,
+		})
+	}
+	return out, nil
+}
+
+func loadExpiredArtifacts(ctx context.Context, store *postgres.ArtifactRetentionStore, runIDs []string, limit int) ([]retentionArtifact, error) {
+	records, err := store.ListExpiredArtifacts(ctx, runIDs, limit)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]retentionArtifact, 0, len(records))
+	for _, record := range records {
+		out = append(out, retentionArtifact{
+			ArtifactID: record.ArtifactID,
+			RunID:      record.RunID,
+			Kind:       record.Kind,
+			Name:       record.Name,
+			ObjectKey:  record.ObjectKey,
+			SHA256:     record.SHA256,
+			SizeBytes:  record.SizeBytes,
+			blobSHA256: record.BlobSHA256,
+		})
+	}
+	return out, nil
+}
+
+// previewArtifactRetention computes what enforcing the experiment's
+// effective policy would delete. It returns repo.ErrNotFound when no policy
+// applies.
+func previewArtifactRetention(ctx context.Context, db postgres.DB, experimentID string, limit int) (artifactRetentionPreview, error) {
+	store := postgres.NewArtifactRetentionStore(db)
+	record, err := store.EffectivePolicy(ctx, experimentID)
+	if err != nil {
+		return artifactRetentionPreview{}, err
+	}
+	policy := artifactRetentionPolicyFromRecord(record)
+	runs, err := loadRetentionRuns(ctx, store, experimentID, policy)
+	if err != nil {
+		return artifactRetentionPreview{}, err
+	}
+	kept, expired := planArtifactRetention(policy, runs)
+	artifacts, err := loadExpiredArtifacts(ctx, store, expired, limit)
+	if err != nil {
+		return artifactRetentionPreview{}, err
+	}
+	out := artifactRetentionPreview{
+		ExperimentID:  experimentID,
+		Policy:        policy,
+		KeptRuns:      kept,
+		ExpiredRunIDs: expired,
+		Artifacts:     artifacts,
+	}
+	for _, artifact := range artifacts {
+		out.TotalBytes += artifact.SizeBytes
+	}
+	return out, nil
+}
+
+func (api *experimentsAPI) handleGetExperimentArtifactRetention(w http.ResponseWriter, r *http.Request) {
+	_, experimentID, ok := api.scopedExperiment(w, r)
+	if !ok {
+		return
+	}
+	api.writeArtifactRetentionPolicy(w, r, retentionScopeExperiment, experimentID)
+}
+
+func (api *experimentsAPI) handleSetExperimentArtifactRetention(w http.ResponseWriter, r *http.Request) {
+	identity, experimentID, ok := api.scopedExperiment(w, r)
+	if !ok {
+		return
+	}
+	projectID, _ := auth.ProjectIDFromContext(r.Context())
+	api.setArtifactRetentionPolicy(w, r, identity, retentionScopeExperiment, experimentID, strings.TrimSpace(projectID))
+}
+
+func (api *experimentsAPI) handleDeleteExperimentArtifactRetention(w http.ResponseWriter, r *http.Request) {
+	identity, experimentID, ok := api.scopedExperiment(w, r)
+	if !ok {
+		return
+	}
+	api.deleteArtifactRetentionPolicy(w, r, identity, retentionScopeExperiment, experimentID)
+}
+
+func (api *experimentsAPI) handlePreviewExperimentArtifactRetention(w http.ResponseWriter, r *http.Request) {
+	_, experimentID, ok := api.scopedExperiment(w, r)
+	if !ok {
+		return
+	}
+	preview, err := previewArtifactRetention(r.Context(), api.db, experimentID, httpapi.Limit(r, 100, artifactRetentionBatch))
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			api.writeError(w, r, http.StatusNotFound, "artifact_retention_not_configured")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	api.writeJSON(w, http.StatusOK, preview)
+}
+
+// scopedProject returns the caller and the project_id path value of
+// project-scoped requests.
+func (api *experimentsAPI) scopedProject(w http.ResponseWriter, r *http.Request) (auth.Identity, string, bool) {
+	identity, ok := auth.IdentityFromContext(r.Context())
+	if !ok || strings.TrimSpace(identity.Subject) == "" {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return auth.Identity{}, "", false
+	}
+	projectID := strings.TrimSpace(r.PathValue("project_id"))
+	if projectID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "project_id_required")
+		return auth.Identity{}, "", false
+	}
+	return identity, projectID, true
+}
+
+func (api *experimentsAPI) handleGetProjectArtifactRetention(w http.ResponseWriter, r *http.Request) {
+	_, projectID, ok := api.scopedProject(w, r)
+	if !ok {
+		return
+	}
+	api.writeArtifactRetentionPolicy(w, r, retentionScopeProject, projectID)
+}
+
+func (api *experimentsAPI) handleSetProjectArtifactRetention(w http.ResponseWriter, r *http.Request) {
+	identity, projectID, ok := api.scopedProject(w, r)
+	if !ok {
+		return
+	}
+	api.setArtifactRetentionPolicy(w, r, identity, retentionScopeProject, projectID, projectID)
+}
+
+func (api *experimentsAPI) handleDeleteProjectArtifactRetention(w http.ResponseWriter, r *http.Request) {
+	identity, projectID, ok := api.scopedProject(w, r)
+	if !ok {
+		return
+	}
+	api.deleteArtifactRetentionPolicy(w, r, identity, retentionScopeProject, projectID)
+}
+
+func (api *experimentsAPI) writeArtifactRetentionPolicy(w http.ResponseWriter, r *http.Request, scopeType, scopeID string) {
+	record, err := postgres.NewArtifactRetentionStore(api.db).GetPolicy(r.Context(), scopeType, scopeID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			api.writeError(w, r, http.StatusNotFound, "artifact_retention_not_configured")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	api.writeJSON(w, http.StatusOK, artifactRetentionPolicyFromRecord(record))
+}
+
+func (api *experimentsAPI) setArtifactRetentionPolicy(w http.ResponseWriter, r *http.Request, identity auth.Identity, scopeType, scopeID, projectID string) {
+	var req setArtifactRetentionRequest
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
+		return
+	}
+	req, ok := normalizeArtifactRetention(req)
+	if !ok {
+		api.writeError(w, r, http.StatusBadRequest, "invalid_retention_policy")
+		return
+	}
+	record := postgres.ArtifactRetentionPolicyRecord{
+		ScopeType:    scopeType,
+		ScopeID:      scopeID,
+		ProjectID:    projectID,
+		KeepLastRuns: req.KeepLastRuns,
+	}
+	if req.KeepBest != nil {
+		record.KeepBestMetric = req.KeepBest.Metric
+		record.KeepBestOrder = req.KeepBest.Order
+		record.KeepBestRuns = req.KeepBest.Runs
+	}
+
+	now := time.Now().UTC()
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	record.UpdatedAt, record.UpdatedBy = now, identity.Subject
+	if err := postgres.NewArtifactRetentionStore(tx).UpsertPolicy(r.Context(), record); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			api.writeError(w, r, http.StatusNotFound, "project_not_found")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	policy := artifactRetentionPolicy{
+		ScopeType:    scopeType,
+		ScopeID:      scopeID,
+		ProjectID:    projectID,
+		KeepLastRuns: req.KeepLastRuns,
+		KeepBest:     req.KeepBest,
+		UpdatedAt:    now,
+		UpdatedBy:    identity.Subject,
+	}
+	if err := auditlog.Enqueue(r.Context(), tx, auditlog.Event{
+		OccurredAt:   now,
+		Actor:        identity.Subject,
+		Action:       scopeType + ".artifact_retention_set",
+		ResourceType: scopeType,
+		ResourceID:   scopeID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload: map[string]any{
+			"service":        "experiments",
+			"project_id":     projectID,
+			"scope_type":     scopeType,
+			"scope_id":       scopeID,
+			"keep_last_runs": req.KeepLastRuns,
+			"keep_best":      req.KeepBest,
+		},
+	}); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "audit_failed")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	api.writeJSON(w, http.StatusOK, policy)
+}
+
+func (api *experimentsAPI) deleteArtifactRetentionPolicy(w http.ResponseWriter, r *http.Request, identity auth.Identity, scopeType, scopeID string) {
+	now := time.Now().UTC()
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	projectID, err := postgres.NewArtifactRetentionStore(tx).DeletePolicy(r.Context(), scopeType, scopeID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			api.writeError(w, r, http.StatusNotFound, "artifact_retention_not_configured")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if err := auditlog.Enqueue(r.Context(), tx, auditlog.Event{
+		OccurredAt:   now,
+		Actor:        identity.Subject,
+		Action:       scopeType + ".artifact_retention_deleted",
+		ResourceType: scopeType,
+		ResourceID:   scopeID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload: map[string]any{
+			"service":    "experiments",
+			"project_id": projectID,
+			"scope_type": scopeType,
+			"scope_id":   scopeID,
+		},
+	}); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "audit_failed")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func startArtifactRetention(ctx context.Context, logger *slog.Logger, api *experimentsAPI, interval time.Duration) {
+	if api == nil || api.db == nil {
+		return
+	}
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	syncer{
+		name:       "artifact_retention",
+		logger:     logger,
+		leader:     platformpg.NewLeader(api.db, "artifact_retention"),
+		interval:   interval,
+		maxBackoff: 4 * interval,
+		pass: func(ctx context.Context) error {
+			return api.enforceArtifactRetention(ctx, time.Now().UTC())
+		},
+	}.start(ctx)
+}
+
+// enforceArtifactRetention applies the effective policy of every experiment
+// that has one. A failing experiment does not stop the others.
+func (api *experimentsAPI) enforceArtifactRetention(ctx context.Context, now time.Time) error {
+	experimentIDs, err := postgres.NewArtifactRetentionStore(api.db).ListRetainedExperiments(ctx)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, experimentID := range experimentIDs {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if _, err := api.applyArtifactRetention(ctx, experimentID, now); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// applyArtifactRetention deletes up to artifactRetentionBatch expired
+// artifacts of the experiment in one transaction, auditing each deletion.
+// Content-addressed bodies lose a reference and are left to the blob GC;
+// objects of older artifacts are removed after the commit.
+func (api *experimentsAPI) applyArtifactRetention(ctx context.Context, experimentID string, now time.Time) (int, error) {
+	tx, err := api.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	preview, err := previewArtifactRetention(ctx, tx, experimentID, artifactRetentionBatch)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	store := postgres.NewArtifactRetentionStore(tx)
+	legacyKeys := []string{}
+	for _, artifact := range preview.Artifacts {
+		if err := store.DeleteArtifact(ctx, artifact.ArtifactID); err != nil {
+			return 0, err
+		}
+		if artifact.blobSHA256 != "" {
+			if err := releaseArtifactBlob(ctx, tx, artifact.blobSHA256, now); err != nil {
+				return 0, err
+			}
+		} else {
+			legacyKeys = append(legacyKeys, artifact.ObjectKey)
+		}
+		if err := auditlog.Enqueue(ctx, tx, auditlog.Event{
+			OccurredAt:   now,
+			Actor:        artifactRetentionActor,
+			Action:       "experiment_run_artifact.retention_deleted",
+			ResourceType: "experiment_run_artifact",
+			ResourceID:   artifact.ArtifactID,
+			Payload: map[string]any{
+				"service":       "experiments",
+				"project_id":    preview.Policy.ProjectID,
+				"experiment_id": experimentID,
+				"run_id":        artifact.RunID,
+				"artifact_id":   artifact.ArtifactID,
+				"kind":          artifact.Kind,
+				"sha256":        artifact.SHA256,
+				"size_bytes":    artifact.SizeBytes,
+				"policy_scope":  preview.Policy.ScopeType,
+			},
+		}); err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	for _, key := range legacyKeys {
+		if err := api.store.RemoveObject(ctx, api.storeCfg.BucketArtifacts, key, minio.RemoveObjectOptions{}); err != nil && api.logger != nil {
+			api.logger.Warn("artifact object removal failed", "object_key", key, "error", err)
+		}
+	}
+	return len(preview.Artifacts), nil
+}
@@ -0,0 +1,67 @@
+package experiments
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNormalizeArtifactRetention(t *testing.T) {
+	three := 3
+	req, ok := normalizeArtifactRetention(setArtifactRetentionRequest{
+		KeepBest: &artifactRetentionKeepBest{Metric: " loss ", Runs: 2},
+	})
+	if !ok || req.KeepBest.Metric != "loss" || req.KeepBest.Order != leaderboardOrderDesc {
+		t.Fatalf("req=%+v ok=%v", req.KeepBest, ok)
+	}
+	if _, ok := normalizeArtifactRetention(setArtifactRetentionRequest{KeepLastRuns: &three}); !ok {
+		t.Fatalf("expected keep_last_runs alone to be accepted")
+	}
+
+	negative := -1
+	for _, bad := range []setArtifactRetentionRequest{
+		{},
+		{KeepLastRuns: &negative},
+		{KeepBest: &artifactRetentionKeepBest{Metric: "", Runs: 1}},
+		{KeepBest: &artifactRetentionKeepBest{Metric: "loss", Runs: 0}},
+		{KeepBest: &artifactRetentionKeepBest{Metric: "loss", Order: "up", Runs: 1}},
+	} {
+		if _, ok := normalizeArtifactRetention(bad); ok {
+			t.Fatalf("expected %+v to be rejected", bad)
+		}
+	}
+}
+
+func TestPlanArtifactRetention(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	value := func(v float64) *float64 { return &v }
+	runs := []retentionRun{
+		{RunID: "r1", StartedAt: base, MetricValue: value(0.9)},
+		{RunID: "r2", StartedAt: base.Add(time.Hour), MetricValue: value(0.5)},
+		{RunID: "r3", StartedAt: base.Add(2 * time.Hour), Referenced: true},
+		{RunID: "r4", StartedAt: base.Add(3 * time.Hour), MetricValue: value(0.7)},
+		{RunID: "r5", StartedAt: base.Add(4 * time.Hour), Active: true},
+	}
+	one := 1
+	policy := artifactRetentionPolicy{
+		KeepLastRuns: &one,
+		KeepBest:     &artifactRetentionKeepBest{Metric: "acc", Order: leaderboardOrderDesc, Runs: 1},
+	}
+	kept, expired := planArtifactRetention(policy, runs)
+	wantKept := []retentionDecision{
+		{RunID: "r5", Reasons: []string{retentionKeepActive, retentionKeepLast}},
+		{RunID: "r3", Reasons: []string{retentionKeepReferenced}},
+		{RunID: "r1", Reasons: []string{retentionKeepBest}},
+	}
+	if !reflect.DeepEqual(kept, wantKept) || !reflect.DeepEqual(expired, []string{"r4", "r2"}) {
+		t.Fatalf("kept=%+v expired=%v", kept, expired)
+	}
+
+	policy.KeepLastRuns = nil
+	policy.KeepBest.Order = leaderboardOrderAsc
+	policy.KeepBest.Runs = 2
+	kept, expired = planArtifactRetention(policy, runs)
+	if len(kept) != 4 || !reflect.DeepEqual(expired, []string{"r1"}) {
+		t.Fatalf("asc: kept=%+v expired=%v", kept, expired)
+	}
+}
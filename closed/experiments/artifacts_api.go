@@ -1,4 +1,4 @@
-package main
+package experiments
 
 import (
 	"context"
@@ -17,6 +17,7 @@ import (
 
 	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
 	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
 	"github.com/animus-labs/animus-go/closed/internal/platform/lineageevent"
 	"github.com/animus-labs/animus-go/closed/internal/platform/redaction"
 	"github.com/google/uuid"
@@ -40,11 +41,14 @@ type experimentRunArtifact struct {
 
 type createExperimentRunArtifactResponse struct {
 	Artifact experimentRunArtifact `json:"artifact"`
+	// Deduplicated is true when the content was already stored and the
+	// upload reused it.
+	Deduplicated bool `json:"deduplicated"`
 }
 
 func isAllowedArtifactKind(kind string) bool {
 	switch strings.ToLower(strings.TrimSpace(kind)) {
-	case "model", "preview", "log", "file":
+	case "model", "preview", "log", "file", artifactKindCheckpoint:
 		return true
 	default:
 		return false
@@ -93,8 +97,7 @@ func (api *experimentsAPI) handleCreateExperimentRunArtifact(w http.ResponseWrit
 		return
 	}
 
-	prefix, err := api.getRunArtifactPrefix(r.Context(), runID)
-	if err != nil {
+	if _, err := api.getRunArtifactPrefix(r.Context(), runID); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			api.writeError(w, r, http.StatusNotFound, "not_found")
 			return
@@ -137,53 +140,80 @@ func (api *experimentsAPI) handleCreateExperimentRunArtifact(w http.ResponseWrit
 		return
 	}
 
+	// A client that declares sha256 may omit the file when the server
+	// already holds that content.
+	declaredSHA256 := strings.ToLower(strings.TrimSpace(r.FormValue("sha256")))
+	if declaredSHA256 != "" && !sha256HexPattern.MatchString(declaredSHA256) {
+		api.writeError(w, r, http.StatusBadRequest, "artifact_sha256_invalid")
+		return
+	}
+
+	var (
+		content     io.Reader
+		filename    = sanitizeFilename(r.FormValue("filename"))
+		contentType = strings.TrimSpace(r.FormValue("content_type"))
+		sha256Hex   = declaredSHA256
+		sizeBytes   int64
+	)
 	file, header, err := r.FormFile("file")
-	if err != nil {
+	switch {
+	case err == nil:
+		defer file.Close()
+		filename = sanitizeFilename(header.Filename)
+		contentType = strings.TrimSpace(header.Header.Get("Content-Type"))
+
+		hasher := sha256.New()
+		sizeBytes, err = io.Copy(hasher, file)
+		if err == nil {
+			_, err = file.Seek(0, io.SeekStart)
+		}
+		if err != nil {
+			api.writeError(w, r, http.StatusBadRequest, "invalid_multipart")
+			return
+		}
+		sha256Hex = hex.EncodeToString(hasher.Sum(nil))
+		if declaredSHA256 != "" && declaredSHA256 != sha256Hex {
+			api.writeError(w, r, http.StatusBadRequest, "artifact_sha256_mismatch")
+			return
+		}
+		content = file
+	case declaredSHA256 == "":
 		api.writeError(w, r, http.StatusBadRequest, "file_required")
 		return
 	}
-	defer file.Close()
-
-	filename := sanitizeFilename(header.Filename)
-	contentType := strings.TrimSpace(header.Header.Get("Content-Type"))
 	if contentType == "" {
 		contentType = "application/octet-stream"
 	}
 
 	artifactID := uuid.NewString()
-	objectKey := fmt.Sprintf("%s/artifacts/%s/%s/%s", prefix, strings.ToLower(kind), artifactID, filename)
+	now := time.Now().UTC()
 
-	hasher := sha256.New()
-	counter := &countingWriter{}
-	reader := io.TeeReader(file, io.MultiWriter(hasher, counter))
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
 
-	size := header.Size
-	if size <= 0 {
-		size = -1
+	projectID, err := api.runStore(tx).RunProjectID(r.Context(), runID)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
 	}
 
 	putCtx, cancel := context.WithTimeout(r.Context(), 10*time.Minute)
-	_, err = api.store.PutObject(
-		putCtx,
-		api.storeCfg.BucketArtifacts,
-		objectKey,
-		reader,
-		size,
-		minio.PutObjectOptions{ContentType: contentType},
-	)
+	blob, err := referenceArtifactBlob(putCtx, tx, api.store, api.storeCfg.BucketArtifacts, projectID, sha256Hex, content, sizeBytes, contentType, now)
 	cancel()
 	if err != nil {
+		if errors.Is(err, errArtifactContentRequired) {
+			api.writeError(w, r, http.StatusConflict, "artifact_content_required")
+			return
+		}
 		api.writeError(w, r, http.StatusBadGateway, "artifact_store_failed")
 		return
 	}
-
-	sha256Hex := hex.EncodeToString(hasher.Sum(nil))
-	sizeBytes := counter.n
-	if sizeBytes <= 0 && header.Size > 0 {
-		sizeBytes = header.Size
-	}
-
-	now := time.Now().UTC()
+	objectKey := blob.ObjectKey
+	sizeBytes = blob.SizeBytes
 
 	type integrityInput struct {
 		ArtifactID  string          `json:"artifact_id"`
@@ -214,19 +244,10 @@ func (api *experimentsAPI) handleCreateExperimentRunArtifact(w http.ResponseWrit
 		CreatedBy:   identity.Subject,
 	})
 	if err != nil {
-		_ = api.store.RemoveObject(r.Context(), api.storeCfg.BucketArtifacts, objectKey, minio.RemoveObjectOptions{})
 		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
 		return
 	}
 
-	tx, err := api.db.BeginTx(r.Context(), nil)
-	if err != nil {
-		_ = api.store.RemoveObject(r.Context(), api.storeCfg.BucketArtifacts, objectKey, minio.RemoveObjectOptions{})
-		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
-		return
-	}
-	defer func() { _ = tx.Rollback() }()
-
 	_, err = tx.ExecContext(
 		r.Context(),
 		`INSERT INTO experiment_run_artifacts (
@@ -242,8 +263,9 @@ func (api *experimentsAPI) handleCreateExperimentRunArtifact(w http.ResponseWrit
 			metadata,
 			created_at,
 			created_by,
-			integrity_sha256
-		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13)`,
+			integrity_sha256,
+			blob_sha256
+		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14)`,
 		artifactID,
 		runID,
 		strings.ToLower(strings.TrimSpace(kind)),
@@ -257,9 +279,9 @@ func (api *experimentsAPI) handleCreateExperimentRunArtifact(w http.ResponseWrit
 		now,
 		identity.Subject,
 		integrity,
+		sha256Hex,
 	)
 	if err != nil {
-		_ = api.store.RemoveObject(r.Context(), api.storeCfg.BucketArtifacts, objectKey, minio.RemoveObjectOptions{})
 		if isForeignKeyViolation(err) {
 			api.writeError(w, r, http.StatusNotFound, "not_found")
 			return
@@ -268,7 +290,7 @@ func (api *experimentsAPI) handleCreateExperimentRunArtifact(w http.ResponseWrit
 		return
 	}
 
-	_, err = lineageevent.Insert(r.Context(), tx, lineageevent.Event{
+	err = lineageevent.Enqueue(r.Context(), tx, lineageevent.Event{
 		OccurredAt:  now,
 		Actor:       identity.Subject,
 		RequestID:   r.Header.Get("X-Request-Id"),
@@ -289,19 +311,18 @@ func (api *experimentsAPI) handleCreateExperimentRunArtifact(w http.ResponseWrit
 		},
 	})
 	if err != nil {
-		_ = api.store.RemoveObject(r.Context(), api.storeCfg.BucketArtifacts, objectKey, minio.RemoveObjectOptions{})
 		api.writeError(w, r, http.StatusInternalServerError, "lineage_write_failed")
 		return
 	}
 
-	_, err = auditlog.Insert(r.Context(), tx, auditlog.Event{
+	err = auditlog.Enqueue(r.Context(), tx, auditlog.Event{
 		OccurredAt:   now,
 		Actor:        identity.Subject,
 		Action:       "experiment_run_artifact.create",
 		ResourceType: "experiment_run_artifact",
 		ResourceID:   artifactID,
 		RequestID:    r.Header.Get("X-Request-Id"),
-		IP:           requestIP(r.RemoteAddr),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
 		UserAgent:    r.UserAgent(),
 		Payload: map[string]any{
 			"service":      "experiments",
@@ -314,17 +335,16 @@ func (api *experimentsAPI) handleCreateExperimentRunArtifact(w http.ResponseWrit
 			"object_key":   objectKey,
 			"sha256":       sha256Hex,
 			"size_bytes":   sizeBytes,
+			"deduplicated": blob.Deduplicated,
 			"metadata":     metadata,
 		},
 	})
 	if err != nil {
-		_ = api.store.RemoveObject(r.Context(), api.storeCfg.BucketArtifacts, objectKey, minio.RemoveObjectOptions{})
 		api.writeError(w, r, http.StatusInternalServerError, "audit_failed")
 		return
 	}
 
 	if err := tx.Commit(); err != nil {
-		_ = api.store.RemoveObject(r.Context(), api.storeCfg.BucketArtifacts, objectKey, minio.RemoveObjectOptions{})
 		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
 		return
 	}
@@ -345,6 +365,7 @@ func (api *experimentsAPI) handleCreateExperimentRunArtifact(w http.ResponseWrit
 			CreatedAt:   now,
 			CreatedBy:   identity.Subject,
 		},
+		Deduplicated: blob.Deduplicated,
 	})
 }
 
@@ -355,7 +376,7 @@ func (api *experimentsAPI) handleListExperimentRunArtifacts(w http.ResponseWrite
 		return
 	}
 
-	limit := clampInt(parseIntQuery(r, "limit", 100), 1, 500)
+	limit := httpapi.Limit(r, 100, 500)
 	kind := strings.TrimSpace(r.URL.Query().Get("kind"))
 	kindFilter := strings.ToLower(strings.TrimSpace(kind))
 	if kindFilter != "" && !isAllowedArtifactKind(kindFilter) {
@@ -481,6 +502,10 @@ func (api *experimentsAPI) handleGetExperimentRunArtifact(w http.ResponseWriter,
 }
 
 func (api *experimentsAPI) handleDownloadExperimentRunArtifact(w http.ResponseWriter, r *http.Request) {
+	if identity, ok := auth.IdentityFromContext(r.Context()); ok && auth.AuditorOnly(identity.Roles) {
+		api.writeError(w, r, http.StatusForbidden, "auditor_read_only")
+		return
+	}
 	runID := strings.TrimSpace(r.PathValue("run_id"))
 	if runID == "" {
 		api.writeError(w, r, http.StatusBadRequest, "run_id_required")
@@ -544,12 +569,3 @@ func (api *experimentsAPI) handleDownloadExperimentRunArtifact(w http.ResponseWr
 	w.WriteHeader(http.StatusOK)
 	_, _ = io.Copy(w, obj)
 }
-
-type countingWriter struct {
-	n int64
-}
-
-func (w *countingWriter) Write(p []byte) (int, error) {
-	w.n += int64(len(p))
-	return len(p), nil
-}
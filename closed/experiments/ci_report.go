@@ -1,4 +1,4 @@
-package main
+package experiments
 
 import (
 	"context"
@@ -15,6 +15,7 @@ import (
 
 	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
 	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
 )
 
 func (api *experimentsAPI) handleCIReport(w http.ResponseWriter, r *http.Request) {
@@ -63,7 +64,19 @@ func (api *experimentsAPI) handleCIReport(w http.ResponseWriter, r *http.Request
 	var payload map[string]any
 	if err := json.Unmarshal(body, &payload); err != nil {
 		api.auditCIReportReject(r.Context(), identity, r, "", "invalid_json")
-		api.writeError(w, r, http.StatusBadRequest, "invalid_json")
+		httpapi.WriteDecodeError(w, r, err)
+		return
+	}
+
+	schema, _ := payload["schema"].(string)
+	switch strings.TrimSpace(schema) {
+	case "", ciReportSchemaV1:
+	case ciReportSchemaV2:
+		api.ingestCIReportV2(w, r, identity, body, tsInt, sig)
+		return
+	default:
+		api.auditCIReportReject(r.Context(), identity, r, "", "unsupported_schema")
+		api.writeError(w, r, http.StatusBadRequest, "unsupported_schema")
 		return
 	}
 
@@ -199,14 +212,14 @@ func (api *experimentsAPI) handleCIReport(w http.ResponseWriter, r *http.Request
 	).Scan(&insertedDigest)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			_, auditErr := auditlog.Insert(r.Context(), tx, auditlog.Event{
+			auditErr := auditlog.Enqueue(r.Context(), tx, auditlog.Event{
 				OccurredAt:   now,
 				Actor:        identity.Subject,
 				Action:       "ci_report.duplicate",
 				ResourceType: "model_image",
 				ResourceID:   imageDigest,
 				RequestID:    r.Header.Get("X-Request-Id"),
-				IP:           requestIP(r.RemoteAddr),
+				IP:           httpapi.RequestIP(r.RemoteAddr),
 				UserAgent:    r.UserAgent(),
 				Payload: map[string]any{
 					"service":        "experiments",
@@ -239,14 +252,14 @@ func (api *experimentsAPI) handleCIReport(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	_, err = auditlog.Insert(r.Context(), tx, auditlog.Event{
+	err = auditlog.Enqueue(r.Context(), tx, auditlog.Event{
 		OccurredAt:   now,
 		Actor:        identity.Subject,
 		Action:       "ci_report.create",
 		ResourceType: "model_image",
 		ResourceID:   insertedDigest,
 		RequestID:    r.Header.Get("X-Request-Id"),
-		IP:           requestIP(r.RemoteAddr),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
 		UserAgent:    r.UserAgent(),
 		Payload: map[string]any{
 			"service":        "experiments",
@@ -294,14 +307,14 @@ func (api *experimentsAPI) auditCIReportReject(ctx context.Context, identity aut
 	}
 
 	now := time.Now().UTC()
-	_, _ = auditlog.Insert(ctx, api.db, auditlog.Event{
+	_ = auditlog.Enqueue(ctx, api.db, auditlog.Event{
 		OccurredAt:   now,
 		Actor:        identity.Subject,
 		Action:       "ci_report.reject",
 		ResourceType: "ci_report",
 		ResourceID:   r.Header.Get("X-Request-Id"),
 		RequestID:    r.Header.Get("X-Request-Id"),
-		IP:           requestIP(r.RemoteAddr),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
 		UserAgent:    r.UserAgent(),
 		Payload:      payload,
 	})
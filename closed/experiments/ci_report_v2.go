@@ -0,0 +1,658 @@
+package experiments
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	"github.com/animus-labs/animus-go/closed/internal/platform/policy"
+	"github.com/google/uuid"
+)
+
+const (
+	ciReportSchemaV1 = "animus.ci.report.v1"
+	ciReportSchemaV2 = "animus.ci.report.v2"
+
+	ciCheckKindTest         = "test"
+	ciCheckKindCoverage     = "coverage"
+	ciCheckKindSecurityScan = "security_scan"
+	ciCheckKindLint         = "lint"
+	ciCheckKindBuild        = "build"
+	ciCheckKindOther        = "other"
+
+	ciCheckStatusPassed  = "passed"
+	ciCheckStatusFailed  = "failed"
+	ciCheckStatusSkipped = "skipped"
+	ciCheckStatusError   = "error"
+	// ciStatusMissing marks kinds without checks, and the whole context when
+	// no report was ingested, so policies can require a check to exist.
+	ciStatusMissing = "missing"
+
+	ciReportMaxChecks = 200
+)
+
+var ciCheckKinds = []string{
+	ciCheckKindTest,
+	ciCheckKindCoverage,
+	ciCheckKindSecurityScan,
+	ciCheckKindLint,
+	ciCheckKindBuild,
+	ciCheckKindOther,
+}
+
+// Check names become policy fields (ci.checks.<name>), which are matched
+// lower-cased.
+var ciCheckNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._:/-]{0,127}$`)
+
+// ciCheckSummaryFields lists the summary fields validated per kind; other
+// fields are kept as reported by the tool.
+var ciCheckSummaryFields = map[string]struct {
+	fields     []string
+	percentage bool
+}{
+	ciCheckKindTest:         {fields: []string{"total", "passed", "failed", "skipped", "errors"}},
+	ciCheckKindCoverage:     {fields: []string{"line_percent", "branch_percent", "threshold_percent"}, percentage: true},
+	ciCheckKindSecurityScan: {fields: []string{"critical", "high", "medium", "low", "unknown"}},
+}
+
+type ciReportV2 struct {
+	Schema      string      `json:"schema"`
+	Repo        string      `json:"repo"`
+	CommitSHA   string      `json:"commit_sha"`
+	ImageDigest string      `json:"image_digest,omitempty"`
+	PipelineID  string      `json:"pipeline_id"`
+	PipelineURL string      `json:"pipeline_url,omitempty"`
+	Provider    string      `json:"provider,omitempty"`
+	Checks      []ciCheckV2 `json:"checks"`
+}
+
+type ciCheckV2 struct {
+	Name    string         `json:"name"`
+	Kind    string         `json:"kind"`
+	Tool    string         `json:"tool"`
+	Status  string         `json:"status"`
+	Summary map[string]any `json:"summary,omitempty"`
+	URL     string         `json:"url,omitempty"`
+}
+
+type ciReport struct {
+	ReportID      string      `json:"report_id"`
+	Schema        string      `json:"schema"`
+	Repo          string      `json:"repo"`
+	CommitSHA     string      `json:"commit_sha"`
+	ImageDigest   string      `json:"image_digest,omitempty"`
+	PipelineID    string      `json:"pipeline_id"`
+	Provider      string      `json:"provider,omitempty"`
+	Status        string      `json:"status"`
+	PayloadSHA256 string      `json:"payload_sha256"`
+	ReceivedAt    time.Time   `json:"received_at"`
+	ReceivedBy    string      `json:"received_by"`
+	Checks        []ciCheckV2 `json:"checks,omitempty"`
+}
+
+// parseCIReportV2 decodes and normalizes a v2 report. The returned string is
+// the API error code of the first validation failure.
+func parseCIReportV2(body []byte) (ciReportV2, string) {
+	var report ciReportV2
+	if err := json.Unmarshal(body, &report); err != nil {
+		return ciReportV2{}, "invalid_json"
+	}
+	report.Schema = strings.TrimSpace(report.Schema)
+	report.Repo = strings.TrimSpace(report.Repo)
+	report.CommitSHA = strings.ToLower(strings.TrimSpace(report.CommitSHA))
+	report.ImageDigest = strings.ToLower(strings.TrimSpace(report.ImageDigest))
+	report.PipelineID = strings.TrimSpace(report.PipelineID)
+	report.PipelineURL = strings.TrimSpace(report.PipelineURL)
+	report.Provider = strings.TrimSpace(report.Provider)
+
+	switch {
+	case report.Schema != ciReportSchemaV2:
+		return ciReportV2{}, "unsupported_schema"
+	case report.Repo == "":
+		return ciReportV2{}, "repo_required"
+	case report.CommitSHA == "":
+		return ciReportV2{}, "commit_sha_required"
+	case report.PipelineID == "":
+		return ciReportV2{}, "pipeline_id_required"
+	case report.ImageDigest != "" && !isSHA256Digest(report.ImageDigest):
+		return ciReportV2{}, "image_digest_invalid"
+	case len(report.Checks) == 0:
+		return ciReportV2{}, "checks_required"
+	case len(report.Checks) > ciReportMaxChecks:
+		return ciReportV2{}, "too_many_checks"
+	}
+
+	seen := make(map[string]struct{}, len(report.Checks))
+	for i := range report.Checks {
+		check := &report.Checks[i]
+		check.Name = strings.ToLower(strings.TrimSpace(check.Name))
+		check.Kind = strings.ToLower(strings.TrimSpace(check.Kind))
+		check.Tool = strings.TrimSpace(check.Tool)
+		check.Status = strings.ToLower(strings.TrimSpace(check.Status))
+		check.URL = strings.TrimSpace(check.URL)
+		if !ciCheckNamePattern.MatchString(check.Name) {
+			return ciReportV2{}, "check_name_invalid"
+		}
+		if _, ok := seen[check.Name]; ok {
+			return ciReportV2{}, "check_name_duplicate"
+		}
+		seen[check.Name] = struct{}{}
+		if !isCICheckKind(check.Kind) {
+			return ciReportV2{}, "check_kind_invalid"
+		}
+		if check.Tool == "" {
+			return ciReportV2{}, "check_tool_required"
+		}
+		switch check.Status {
+		case ciCheckStatusPassed, ciCheckStatusFailed, ciCheckStatusSkipped, ciCheckStatusError:
+		default:
+			return ciReportV2{}, "check_status_invalid"
+		}
+		if !validCICheckSummary(check.Kind, check.Summary) {
+			return ciReportV2{}, "check_summary_invalid"
+		}
+	}
+	return report, ""
+}
+
+func isCICheckKind(kind string) bool {
+	for _, known := range ciCheckKinds {
+		if kind == known {
+			return true
+		}
+	}
+	return false
+}
+
+func validCICheckSummary(kind string, summary map[string]any) bool {
+	rules, ok := ciCheckSummaryFields[kind]
+	if !ok {
+		return true
+	}
+	for _, field := range rules.fields {
+		raw, ok := summary[field]
+		if !ok {
+			continue
+		}
+		value, ok := raw.(float64)
+		if !ok || value < 0 {
+			return false
+		}
+		if rules.percentage {
+			if value > 100 {
+				return false
+			}
+		} else if value != math.Trunc(value) {
+			return false
+		}
+	}
+	return true
+}
+
+// aggregateCIStatus folds check statuses: any failure or error fails, else
+// any pass passes, else everything was skipped.
+func aggregateCIStatus(statuses []string) string {
+	if len(statuses) == 0 {
+		return ciStatusMissing
+	}
+	passed := false
+	for _, status := range statuses {
+		switch status {
+		case ciCheckStatusFailed, ciCheckStatusError:
+			return ciCheckStatusFailed
+		case ciCheckStatusPassed:
+			passed = true
+		}
+	}
+	if passed {
+		return ciCheckStatusPassed
+	}
+	return ciCheckStatusSkipped
+}
+
+func ciReportStatus(checks []ciCheckV2) string {
+	statuses := make([]string, 0, len(checks))
+	for _, check := range checks {
+		statuses = append(statuses, check.Status)
+	}
+	return aggregateCIStatus(statuses)
+}
+
+// ciPolicyContext exposes per-check and per-kind outcomes of a report.
+func ciPolicyContext(reportID string, checks []ciCheckV2) policy.CIContext {
+	out := policy.CIContext{
+		ReportID: reportID,
+		Status:   ciReportStatus(checks),
+		Checks:   make(map[string]string, len(checks)),
+		Kinds:    make(map[string]string, len(ciCheckKinds)),
+	}
+	byKind := make(map[string][]string, len(ciCheckKinds))
+	for _, check := range checks {
+		out.Checks[check.Name] = check.Status
+		byKind[check.Kind] = append(byKind[check.Kind], check.Status)
+	}
+	for _, kind := range ciCheckKinds {
+		out.Kinds[kind] = aggregateCIStatus(byKind[kind])
+	}
+	return out
+}
+
+func (api *experimentsAPI) ingestCIReportV2(w http.ResponseWriter, r *http.Request, identity auth.Identity, body []byte, signatureTS int64, signature string) {
+	report, code := parseCIReportV2(body)
+	if code != "" {
+		api.auditCIReportReject(r.Context(), identity, r, report.ImageDigest, code)
+		api.writeError(w, r, http.StatusBadRequest, code)
+		return
+	}
+
+	payloadJSON, err := json.Marshal(report)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	payloadSHA256 := sha256HexBytes(payloadJSON)
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	reportID := uuid.NewString()
+	status := ciReportStatus(report.Checks)
+
+	integrity, err := integritySHA256(struct {
+		ReportID       string          `json:"report_id"`
+		Schema         string          `json:"schema"`
+		ReceivedAt     time.Time       `json:"received_at"`
+		ReceivedBy     string          `json:"received_by"`
+		SignatureTS    int64           `json:"signature_ts"`
+		Signature      string          `json:"signature"`
+		Payload        json.RawMessage `json:"payload"`
+		PayloadSHA256  string          `json:"payload_sha256"`
+		SignatureScope string          `json:"signature_scope"`
+	}{
+		ReportID:       reportID,
+		Schema:         ciReportSchemaV2,
+		ReceivedAt:     now,
+		ReceivedBy:     identity.Subject,
+		SignatureTS:    signatureTS,
+		Signature:      signature,
+		Payload:        payloadJSON,
+		PayloadSHA256:  payloadSHA256,
+		SignatureScope: "ci.report",
+	})
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var insertedID string
+	err = tx.QueryRowContext(
+		r.Context(),
+		`INSERT INTO ci_reports (
+			report_id,
+			schema_version,
+			repo,
+			commit_sha,
+			image_digest,
+			pipeline_id,
+			provider,
+			status,
+			received_at,
+			received_by,
+			signature_ts,
+			signature,
+			payload,
+			payload_sha256,
+			integrity_sha256
+		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15)
+		ON CONFLICT (repo, commit_sha, pipeline_id) DO NOTHING
+		RETURNING report_id`,
+		reportID,
+		ciReportSchemaV2,
+		report.Repo,
+		report.CommitSHA,
+		nullString(report.ImageDigest),
+		report.PipelineID,
+		nullString(report.Provider),
+		status,
+		now,
+		identity.Subject,
+		signatureTS,
+		signature,
+		payloadJSON,
+		payloadSHA256,
+		integrity,
+	).Scan(&insertedID)
+	if errors.Is(err, sql.ErrNoRows) {
+		api.handleDuplicateCIReport(w, r, tx, identity, report, payloadSHA256)
+		return
+	}
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	for _, check := range report.Checks {
+		summary, err := json.Marshal(check.Summary)
+		if err != nil || check.Summary == nil {
+			summary = []byte(`{}`)
+		}
+		if _, err := tx.ExecContext(
+			r.Context(),
+			`INSERT INTO ci_report_checks (report_id, name, kind, tool, status, summary, url)
+			 VALUES ($1,$2,$3,$4,$5,$6,$7)`,
+			insertedID,
+			check.Name,
+			check.Kind,
+			check.Tool,
+			check.Status,
+			summary,
+			nullString(check.URL),
+		); err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+	}
+
+	err = auditlog.Enqueue(r.Context(), tx, auditlog.Event{
+		OccurredAt:   now,
+		Actor:        identity.Subject,
+		Action:       "ci_report.create",
+		ResourceType: "ci_report",
+		ResourceID:   insertedID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload: map[string]any{
+			"service":        "experiments",
+			"schema":         ciReportSchemaV2,
+			"report_id":      insertedID,
+			"repo":           report.Repo,
+			"commit_sha":     report.CommitSHA,
+			"image_digest":   report.ImageDigest,
+			"pipeline_id":    report.PipelineID,
+			"provider":       report.Provider,
+			"status":         status,
+			"checks":         len(report.Checks),
+			"payload_sha256": payloadSHA256,
+			"signature_ts":   signatureTS,
+		},
+	})
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "audit_failed")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	w.Header().Set("Location", "/ci/reports/"+insertedID)
+	api.writeJSON(w, http.StatusCreated, map[string]any{
+		"status":         "created",
+		"schema":         ciReportSchemaV2,
+		"report_id":      insertedID,
+		"report_status":  status,
+		"repo":           report.Repo,
+		"commit_sha":     report.CommitSHA,
+		"image_digest":   report.ImageDigest,
+		"pipeline_id":    report.PipelineID,
+		"provider":       report.Provider,
+		"payload_sha256": payloadSHA256,
+		"received_at":    now,
+		"received_by":    identity.Subject,
+	})
+}
+
+// handleDuplicateCIReport answers a retry of the same pipeline report as a
+// no-op; a different payload for an already reported pipeline conflicts.
+func (api *experimentsAPI) handleDuplicateCIReport(w http.ResponseWriter, r *http.Request, tx *sql.Tx, identity auth.Identity, report ciReportV2, payloadSHA256 string) {
+	var (
+		existingID  string
+		existingSHA string
+		status      string
+	)
+	err := tx.QueryRowContext(
+		r.Context(),
+		`SELECT report_id, payload_sha256, status
+		 FROM ci_reports
+		 WHERE repo = $1 AND commit_sha = $2 AND pipeline_id = $3`,
+		report.Repo,
+		report.CommitSHA,
+		report.PipelineID,
+	).Scan(&existingID, &existingSHA, &status)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if existingSHA != payloadSHA256 {
+		api.writeError(w, r, http.StatusConflict, "ci_report_exists")
+		return
+	}
+
+	err = auditlog.Enqueue(r.Context(), tx, auditlog.Event{
+		OccurredAt:   time.Now().UTC(),
+		Actor:        identity.Subject,
+		Action:       "ci_report.duplicate",
+		ResourceType: "ci_report",
+		ResourceID:   existingID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload: map[string]any{
+			"service":        "experiments",
+			"schema":         ciReportSchemaV2,
+			"report_id":      existingID,
+			"payload_sha256": payloadSHA256,
+		},
+	})
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "audit_failed")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	api.writeJSON(w, http.StatusOK, map[string]any{
+		"status":         "duplicate",
+		"schema":         ciReportSchemaV2,
+		"report_id":      existingID,
+		"report_status":  status,
+		"image_digest":   report.ImageDigest,
+		"payload_sha256": payloadSHA256,
+	})
+}
+
+const ciReportColumns = `report_id, schema_version, repo, commit_sha, image_digest, pipeline_id, provider, status, payload_sha256, received_at, received_by`
+
+func scanCIReport(row rowScanner) (ciReport, error) {
+	var (
+		report      ciReport
+		imageDigest sql.NullString
+		provider    sql.NullString
+	)
+	if err := row.Scan(
+		&report.ReportID,
+		&report.Schema,
+		&report.Repo,
+		&report.CommitSHA,
+		&imageDigest,
+		&report.PipelineID,
+		&provider,
+		&report.Status,
+		&report.PayloadSHA256,
+		&report.ReceivedAt,
+		&report.ReceivedBy,
+	); err != nil {
+		return ciReport{}, err
+	}
+	report.ImageDigest = imageDigest.String
+	report.Provider = provider.String
+	report.ReceivedAt = report.ReceivedAt.UTC()
+	return report, nil
+}
+
+func (api *experimentsAPI) loadCIReportChecks(ctx context.Context, reportID string) ([]ciCheckV2, error) {
+	rows, err := api.db.QueryContext(
+		ctx,
+		`SELECT name, kind, tool, status, summary, url
+		 FROM ci_report_checks
+		 WHERE report_id = $1
+		 ORDER BY name`,
+		reportID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []ciCheckV2{}
+	for rows.Next() {
+		var (
+			check   ciCheckV2
+			summary []byte
+			url     sql.NullString
+		)
+		if err := rows.Scan(&check.Name, &check.Kind, &check.Tool, &check.Status, &summary, &url); err != nil {
+			return nil, err
+		}
+		if len(summary) > 0 {
+			if err := json.Unmarshal(summary, &check.Summary); err != nil {
+				return nil, err
+			}
+		}
+		check.URL = url.String
+		out = append(out, check)
+	}
+	return out, rows.Err()
+}
+
+func (api *experimentsAPI) handleListCIReports(w http.ResponseWriter, r *http.Request) {
+	limit := httpapi.Limit(r, 100, 500)
+	query := r.URL.Query()
+	repo := strings.TrimSpace(query.Get("repo"))
+	commitSHA := strings.ToLower(strings.TrimSpace(query.Get("commit_sha")))
+	imageDigest := strings.ToLower(strings.TrimSpace(query.Get("image_digest")))
+	if imageDigest != "" && !isSHA256Digest(imageDigest) {
+		api.writeError(w, r, http.StatusBadRequest, "image_digest_invalid")
+		return
+	}
+
+	rows, err := api.db.QueryContext(
+		r.Context(),
+		`SELECT `+ciReportColumns+`
+		 FROM ci_reports
+		 WHERE ($1 = '' OR repo = $1)
+		   AND ($2 = '' OR commit_sha = $2)
+		   AND ($3 = '' OR image_digest = $3)
+		 ORDER BY received_at DESC, report_id
+		 LIMIT $4`,
+		repo,
+		commitSHA,
+		imageDigest,
+		limit,
+	)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer rows.Close()
+
+	out := make([]ciReport, 0, limit)
+	for rows.Next() {
+		report, err := scanCIReport(rows)
+		if err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+		out = append(out, report)
+	}
+	if err := rows.Err(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	api.writeJSON(w, http.StatusOK, map[string]any{
+		"ci_reports": out,
+	})
+}
+
+func (api *experimentsAPI) handleGetCIReport(w http.ResponseWriter, r *http.Request) {
+	reportID := strings.TrimSpace(r.PathValue("report_id"))
+	if reportID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "report_id_required")
+		return
+	}
+
+	report, err := scanCIReport(api.db.QueryRowContext(
+		r.Context(),
+		`SELECT `+ciReportColumns+`
+		 FROM ci_reports
+		 WHERE report_id = $1`,
+		reportID,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	report.Checks, err = api.loadCIReportChecks(r.Context(), report.ReportID)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	api.writeJSON(w, http.StatusOK, report)
+}
+
+// loadCIPolicyContext returns the outcomes of the latest report for the
+// commit or image digest. An empty context means neither was known; a
+// missing status means nothing was reported for them.
+func (api *experimentsAPI) loadCIPolicyContext(ctx context.Context, commitSHA, imageDigest string) (policy.CIContext, error) {
+	commitSHA = strings.ToLower(strings.TrimSpace(commitSHA))
+	imageDigest = strings.ToLower(strings.TrimSpace(imageDigest))
+	if commitSHA == "" && imageDigest == "" {
+		return policy.CIContext{}, nil
+	}
+
+	var reportID string
+	err := api.db.QueryRowContext(
+		ctx,
+		`SELECT report_id
+		 FROM ci_reports
+		 WHERE ($1 <> '' AND commit_sha = $1)
+		    OR ($2 <> '' AND image_digest = $2)
+		 ORDER BY received_at DESC, report_id
+		 LIMIT 1`,
+		commitSHA,
+		imageDigest,
+	).Scan(&reportID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ciPolicyContext("", nil), nil
+	}
+	if err != nil {
+		return policy.CIContext{}, err
+	}
+	checks, err := api.loadCIReportChecks(ctx, reportID)
+	if err != nil {
+		return policy.CIContext{}, err
+	}
+	return ciPolicyContext(reportID, checks), nil
+}
@@ -0,0 +1,126 @@
+package experiments
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func ciReportV2Body(t *testing.T, mutate func(report map[string]any)) []byte {
+	t.Helper()
+	report := map[string]any{
+		"schema":       ciReportSchemaV2,
+		"repo":         "gitlab.example.com/acme/train",
+		"commit_sha":   "ABCDEF0123",
+		"image_digest": "sha256:" + "a1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90",
+		"pipeline_id":  "4242",
+		"checks": []any{
+			map[string]any{"name": "Unit-Tests", "kind": "test", "tool": "pytest", "status": "passed", "summary": map[string]any{"total": 120, "passed": 118, "skipped": 2}},
+			map[string]any{"name": "coverage", "kind": "coverage", "tool": "coverage.py", "status": "passed", "summary": map[string]any{"line_percent": 87.5}},
+			map[string]any{"name": "trivy", "kind": "security_scan", "tool": "trivy", "status": "failed", "summary": map[string]any{"critical": 1, "high": 3}},
+		},
+	}
+	if mutate != nil {
+		mutate(report)
+	}
+	body, err := json.Marshal(report)
+	if err != nil {
+		t.Fatalf("marshal report: %v", err)
+	}
+	return body
+}
+
+func setCICheckField(field string, value any) func(map[string]any) {
+	return func(report map[string]any) {
+		report["checks"].([]any)[0].(map[string]any)[field] = value
+	}
+}
+
+func TestParseCIReportV2(t *testing.T) {
+	report, code := parseCIReportV2(ciReportV2Body(t, nil))
+	if code != "" {
+		t.Fatalf("unexpected error %s", code)
+	}
+	if report.CommitSHA != "abcdef0123" || report.Checks[0].Name != "unit-tests" {
+		t.Fatalf("report not normalized: %+v", report)
+	}
+	if status := ciReportStatus(report.Checks); status != ciCheckStatusFailed {
+		t.Fatalf("expected failed report, got %s", status)
+	}
+}
+
+func TestParseCIReportV2Rejects(t *testing.T) {
+	cases := []struct {
+		name   string
+		mutate func(map[string]any)
+		code   string
+	}{
+		{name: "schema", mutate: func(r map[string]any) { r["schema"] = "animus.ci.report.v3" }, code: "unsupported_schema"},
+		{name: "commit", mutate: func(r map[string]any) { delete(r, "commit_sha") }, code: "commit_sha_required"},
+		{name: "digest", mutate: func(r map[string]any) { r["image_digest"] = "sha256:abc" }, code: "image_digest_invalid"},
+		{name: "no checks", mutate: func(r map[string]any) { r["checks"] = []any{} }, code: "checks_required"},
+		{name: "kind", mutate: setCICheckField("kind", "fuzz"), code: "check_kind_invalid"},
+		{name: "status", mutate: setCICheckField("status", "green"), code: "check_status_invalid"},
+		{name: "tool", mutate: setCICheckField("tool", " "), code: "check_tool_required"},
+		{name: "name", mutate: setCICheckField("name", "unit tests"), code: "check_name_invalid"},
+		{name: "duplicate", mutate: setCICheckField("name", "TRIVY"), code: "check_name_duplicate"},
+		{name: "fractional count", mutate: setCICheckField("summary", map[string]any{"failed": 1.5}), code: "check_summary_invalid"},
+		{name: "negative count", mutate: setCICheckField("summary", map[string]any{"total": -1}), code: "check_summary_invalid"},
+		{name: "percentage", mutate: func(r map[string]any) {
+			r["checks"].([]any)[1].(map[string]any)["summary"] = map[string]any{"line_percent": 101}
+		}, code: "check_summary_invalid"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, code := parseCIReportV2(ciReportV2Body(t, tc.mutate)); code != tc.code {
+				t.Fatalf("expected %s, got %q", tc.code, code)
+			}
+		})
+	}
+}
+
+func TestCIPolicyContext(t *testing.T) {
+	report, code := parseCIReportV2(ciReportV2Body(t, nil))
+	if code != "" {
+		t.Fatalf("unexpected error %s", code)
+	}
+	ci := ciPolicyContext("report-1", report.Checks)
+	if ci.ReportID != "report-1" || ci.Status != ciCheckStatusFailed {
+		t.Fatalf("unexpected context %+v", ci)
+	}
+	if ci.Checks["unit-tests"] != ciCheckStatusPassed || ci.Checks["trivy"] != ciCheckStatusFailed {
+		t.Fatalf("unexpected checks %v", ci.Checks)
+	}
+	want := map[string]string{
+		ciCheckKindTest:         ciCheckStatusPassed,
+		ciCheckKindCoverage:     ciCheckStatusPassed,
+		ciCheckKindSecurityScan: ciCheckStatusFailed,
+		ciCheckKindLint:         ciStatusMissing,
+	}
+	for kind, status := range want {
+		if ci.Kinds[kind] != status {
+			t.Fatalf("kind %s: expected %s, got %s", kind, status, ci.Kinds[kind])
+		}
+	}
+
+	missing := ciPolicyContext("", nil)
+	if missing.Status != ciStatusMissing || missing.Kinds[ciCheckKindSecurityScan] != ciStatusMissing {
+		t.Fatalf("unexpected missing context %+v", missing)
+	}
+}
+
+func TestAggregateCIStatus(t *testing.T) {
+	cases := []struct {
+		statuses []string
+		want     string
+	}{
+		{statuses: nil, want: ciStatusMissing},
+		{statuses: []string{ciCheckStatusSkipped}, want: ciCheckStatusSkipped},
+		{statuses: []string{ciCheckStatusSkipped, ciCheckStatusPassed}, want: ciCheckStatusPassed},
+		{statuses: []string{ciCheckStatusPassed, ciCheckStatusError}, want: ciCheckStatusFailed},
+	}
+	for _, tc := range cases {
+		if got := aggregateCIStatus(tc.statuses); got != tc.want {
+			t.Fatalf("%v: expected %s, got %s", tc.statuses, tc.want, got)
+		}
+	}
+}
@@ -1,4 +1,4 @@
-package main
+package experiments
 
 import (
 	"context"
@@ -17,6 +17,7 @@ import (
 
 	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
 	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
 	"github.com/google/uuid"
 )
 
@@ -73,7 +74,7 @@ func (api *experimentsAPI) handleCIWebhook(w http.ResponseWriter, r *http.Reques
 	var payload map[string]any
 	if err := json.Unmarshal(body, &payload); err != nil {
 		api.auditCIWebhookReject(r.Context(), identity, r, "", "invalid_json")
-		api.writeError(w, r, http.StatusBadRequest, "invalid_json")
+		httpapi.WriteDecodeError(w, r, err)
 		return
 	}
 
@@ -180,14 +181,14 @@ func (api *experimentsAPI) handleCIWebhook(w http.ResponseWriter, r *http.Reques
 				return
 			}
 
-			_, auditErr := auditlog.Insert(r.Context(), tx, auditlog.Event{
+			auditErr := auditlog.Enqueue(r.Context(), tx, auditlog.Event{
 				OccurredAt:   now,
 				Actor:        identity.Subject,
 				Action:       "ci_webhook.duplicate",
 				ResourceType: "experiment_run_context",
 				ResourceID:   existingID,
 				RequestID:    r.Header.Get("X-Request-Id"),
-				IP:           requestIP(r.RemoteAddr),
+				IP:           httpapi.RequestIP(r.RemoteAddr),
 				UserAgent:    r.UserAgent(),
 				Payload: map[string]any{
 					"service":        "experiments",
@@ -224,14 +225,14 @@ func (api *experimentsAPI) handleCIWebhook(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	_, err = auditlog.Insert(r.Context(), tx, auditlog.Event{
+	err = auditlog.Enqueue(r.Context(), tx, auditlog.Event{
 		OccurredAt:   now,
 		Actor:        identity.Subject,
 		Action:       "experiment_run_context.create",
 		ResourceType: "experiment_run_context",
 		ResourceID:   insertedID,
 		RequestID:    r.Header.Get("X-Request-Id"),
-		IP:           requestIP(r.RemoteAddr),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
 		UserAgent:    r.UserAgent(),
 		Payload: map[string]any{
 			"service":        "experiments",
@@ -313,14 +314,14 @@ func (api *experimentsAPI) auditCIWebhookReject(ctx context.Context, identity au
 	}
 
 	now := time.Now().UTC()
-	_, _ = auditlog.Insert(ctx, api.db, auditlog.Event{
+	_ = auditlog.Enqueue(ctx, api.db, auditlog.Event{
 		OccurredAt:   now,
 		Actor:        identity.Subject,
 		Action:       "ci_webhook.reject",
 		ResourceType: "ci_webhook",
 		ResourceID:   r.Header.Get("X-Request-Id"),
 		RequestID:    r.Header.Get("X-Request-Id"),
-		IP:           requestIP(r.RemoteAddr),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
 		UserAgent:    r.UserAgent(),
 		Payload:      payload,
 	})
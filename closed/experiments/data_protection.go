@@ -0,0 +1,51 @@
+package experiments
+
+import (
+	"context"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/domain"
+	"github.com/animus-labs/animus-go/closed/internal/platform/policy"
+	repopg "github.com/animus-labs/animus-go/closed/internal/repo/postgres"
+)
+
+func (api *experimentsAPI) loadDataProtection(ctx context.Context, datasetVersionID string) (domain.DataProtection, error) {
+	return repopg.NewDataProtectionStore(api.db).Get(ctx, datasetVersionID)
+}
+
+// applyDataProtection exposes a version's protection posture to policies as
+// dataset.encrypted, dataset.residency, dataset.classification and
+// dataset.recalled.
+func applyDataProtection(dataset *policy.DatasetContext, protection domain.DataProtection) {
+	encrypted := protection.Encrypted
+	recalled := protection.Recall != nil
+	dataset.Encrypted = &encrypted
+	dataset.EncryptionKeyID = protection.EncryptionKeyID
+	dataset.Residency = protection.Residency
+	dataset.Classification = protection.ClassificationLabels
+	dataset.Recalled = &recalled
+}
+
+// dataProtectionPayload is the posture recorded with quality gate decisions.
+func dataProtectionPayload(protection domain.DataProtection) map[string]any {
+	labels := protection.ClassificationLabels
+	if labels == nil {
+		labels = []string{}
+	}
+	out := map[string]any{
+		"encrypted":             protection.Encrypted,
+		"classification_labels": labels,
+		"recalled":              protection.Recall != nil,
+	}
+	if protection.EncryptionKeyID != "" {
+		out["encryption_key_id"] = protection.EncryptionKeyID
+	}
+	if protection.Residency != "" {
+		out["residency"] = protection.Residency
+	}
+	if protection.Recall != nil {
+		out["recall_reason"] = protection.Recall.Reason
+		out["recalled_at"] = protection.Recall.RecalledAt.Format(time.RFC3339Nano)
+	}
+	return out
+}
@@ -0,0 +1,35 @@
+package experiments
+
+import (
+	"testing"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/domain"
+	"github.com/animus-labs/animus-go/closed/internal/platform/policy"
+)
+
+func TestApplyDataProtection(t *testing.T) {
+	protection := domain.DataProtection{
+		Encrypted:            true,
+		EncryptionKeyID:      "kms-1",
+		Residency:            "eu",
+		ClassificationLabels: []string{"pii"},
+		Recall:               &domain.DatasetVersionRecall{Reason: "leak", RecalledAt: time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	var dataset policy.DatasetContext
+	applyDataProtection(&dataset, protection)
+	if dataset.Encrypted == nil || !*dataset.Encrypted || dataset.Recalled == nil || !*dataset.Recalled {
+		t.Fatalf("unexpected flags: %+v", dataset)
+	}
+	if dataset.Residency != "eu" || len(dataset.Classification) != 1 {
+		t.Fatalf("unexpected dataset context: %+v", dataset)
+	}
+
+	payload := dataProtectionPayload(protection)
+	if payload["recall_reason"] != "leak" || payload["residency"] != "eu" || payload["encryption_key_id"] != "kms-1" {
+		t.Fatalf("unexpected payload: %v", payload)
+	}
+	if labels, ok := dataProtectionPayload(domain.DataProtection{})["classification_labels"].([]string); !ok || labels == nil {
+		t.Fatalf("expected empty label list")
+	}
+}
@@ -1,4 +1,4 @@
-package main
+package experiments
 
 import (
 	"context"
@@ -13,7 +13,9 @@ import (
 	"github.com/animus-labs/animus-go/closed/internal/domain"
 	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
 	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
 	"github.com/animus-labs/animus-go/closed/internal/platform/rbac"
+	"github.com/animus-labs/animus-go/closed/internal/platform/validation"
 	"github.com/animus-labs/animus-go/closed/internal/repo"
 	"github.com/animus-labs/animus-go/closed/internal/repo/postgres"
 	"github.com/google/uuid"
@@ -150,7 +152,7 @@ func (api *experimentsAPI) devEnvDataplaneClient() (devEnvDataplaneClient, error
 	if strings.TrimSpace(api.dataplaneURL) == "" {
 		return nil, errors.New("dataplane url not configured")
 	}
-	return newDataplaneClient(api.dataplaneURL, api.runTokenSecret)
+	return newDataplaneClient(api.dataplaneURL, api.runTokenSecret, api.internalTransport)
 }
 
 func (api *experimentsAPI) buildDevEnvPolicySnapshot(ctx context.Context, projectID string, identity auth.Identity, envLock domain.EnvLock) (domain.PolicySnapshot, error) {
@@ -170,7 +172,7 @@ func (api *experimentsAPI) appendDevEnvAudit(ctx context.Context, event auditlog
 	if api.devEnvAuditOverride != nil {
 		return api.devEnvAuditOverride.Append(ctx, event)
 	}
-	_, err := auditlog.Insert(ctx, api.db, event)
+	err := auditlog.Enqueue(ctx, api.db, event)
 	return err
 }
 
@@ -192,8 +194,8 @@ func (api *experimentsAPI) handleCreateDevEnvironment(w http.ResponseWriter, r *
 	}
 
 	var req devEnvCreateRequest
-	if err := decodeJSON(r, &req); err != nil {
-		api.writeError(w, r, http.StatusBadRequest, "invalid_json")
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
 		return
 	}
 	idempotencyKey := strings.TrimSpace(r.Header.Get("Idempotency-Key"))
@@ -243,11 +245,11 @@ func (api *experimentsAPI) handleCreateDevEnvironment(w http.ResponseWriter, r *
 		api.writeError(w, r, http.StatusBadRequest, "ref_value_required")
 		return
 	}
-	if refType == domain.DevEnvRefTypeCommit && !validCommitSHA(refValue) {
+	if refType == domain.DevEnvRefTypeCommit && !validation.IsCommitSHA(refValue) {
 		api.writeError(w, r, http.StatusBadRequest, "invalid_ref_value")
 		return
 	}
-	if commitPin != "" && !validCommitSHA(commitPin) {
+	if commitPin != "" && !validation.IsCommitSHA(commitPin) {
 		api.writeError(w, r, http.StatusBadRequest, "invalid_commit_pin")
 		return
 	}
@@ -373,7 +375,7 @@ func (api *experimentsAPI) handleCreateDevEnvironment(w http.ResponseWriter, r *
 				ResourceType: "dev_environment",
 				ResourceID:   devEnv.ID,
 				RequestID:    r.Header.Get("X-Request-Id"),
-				IP:           requestIP(r.RemoteAddr),
+				IP:           httpapi.RequestIP(r.RemoteAddr),
 				UserAgent:    r.UserAgent(),
 				Payload: map[string]any{
 					"service":      "experiments",
@@ -426,14 +428,14 @@ func (api *experimentsAPI) handleCreateDevEnvironment(w http.ResponseWriter, r *
 				api.writeError(w, r, http.StatusInternalServerError, "internal_error")
 				return
 			}
-			if _, err := auditlog.Insert(r.Context(), tx, auditlog.Event{
+			if err := auditlog.Enqueue(r.Context(), tx, auditlog.Event{
 				OccurredAt:   now,
 				Actor:        identity.Subject,
 				Action:       auditDevEnvCreated,
 				ResourceType: "dev_environment",
 				ResourceID:   devEnv.ID,
 				RequestID:    r.Header.Get("X-Request-Id"),
-				IP:           requestIP(r.RemoteAddr),
+				IP:           httpapi.RequestIP(r.RemoteAddr),
 				UserAgent:    r.UserAgent(),
 				Payload: map[string]any{
 					"service":      "experiments",
@@ -494,7 +496,7 @@ func (api *experimentsAPI) handleCreateDevEnvironment(w http.ResponseWriter, r *
 			ResourceType: "dev_environment",
 			ResourceID:   record.Environment.ID,
 			RequestID:    r.Header.Get("X-Request-Id"),
-			IP:           requestIP(r.RemoteAddr),
+			IP:           httpapi.RequestIP(r.RemoteAddr),
 			UserAgent:    r.UserAgent(),
 			Payload: map[string]any{
 				"service":      "experiments",
@@ -518,7 +520,7 @@ func (api *experimentsAPI) handleCreateDevEnvironment(w http.ResponseWriter, r *
 		ResourceType: "dev_environment",
 		ResourceID:   record.Environment.ID,
 		RequestID:    r.Header.Get("X-Request-Id"),
-		IP:           requestIP(r.RemoteAddr),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
 		UserAgent:    r.UserAgent(),
 		Payload: map[string]any{
 			"service":    "experiments",
@@ -537,7 +539,7 @@ func (api *experimentsAPI) handleCreateDevEnvironment(w http.ResponseWriter, r *
 		ResourceType: "dev_environment",
 		ResourceID:   record.Environment.ID,
 		RequestID:    r.Header.Get("X-Request-Id"),
-		IP:           requestIP(r.RemoteAddr),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
 		UserAgent:    r.UserAgent(),
 		Payload: map[string]any{
 			"service":    "experiments",
@@ -567,7 +569,7 @@ func (api *experimentsAPI) handleListDevEnvironments(w http.ResponseWriter, r *h
 		return
 	}
 	state := strings.TrimSpace(r.URL.Query().Get("state"))
-	limit := clampInt(parseIntQuery(r, "limit", 100), 1, 500)
+	limit := httpapi.Limit(r, 100, 500)
 
 	store := api.devEnvStore()
 	if store == nil {
@@ -638,8 +640,8 @@ func (api *experimentsAPI) handleAccessDevEnvironment(w http.ResponseWriter, r *
 	}
 
 	var req devEnvAccessRequest
-	if err := decodeJSON(r, &req); err != nil && !errors.Is(err, io.EOF) {
-		api.writeError(w, r, http.StatusBadRequest, "invalid_json")
+	if err := httpapi.DecodeJSON(r, &req); err != nil && !errors.Is(err, io.EOF) {
+		httpapi.WriteDecodeError(w, r, err)
 		return
 	}
 
@@ -705,7 +707,7 @@ func (api *experimentsAPI) handleAccessDevEnvironment(w http.ResponseWriter, r *
 		ResourceType: "dev_environment",
 		ResourceID:   devEnvID,
 		RequestID:    r.Header.Get("X-Request-Id"),
-		IP:           requestIP(r.RemoteAddr),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
 		UserAgent:    r.UserAgent(),
 		Payload: map[string]any{
 			"service":    "experiments",
@@ -810,7 +812,7 @@ func (api *experimentsAPI) handleStopDevEnvironment(w http.ResponseWriter, r *ht
 		ResourceType: "dev_environment",
 		ResourceID:   devEnvID,
 		RequestID:    r.Header.Get("X-Request-Id"),
-		IP:           requestIP(r.RemoteAddr),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
 		UserAgent:    r.UserAgent(),
 		Payload: map[string]any{
 			"service":    "experiments",
@@ -1,4 +1,4 @@
-package main
+package experiments
 
 import (
 	"crypto/sha256"
@@ -14,6 +14,7 @@ import (
 	"github.com/animus-labs/animus-go/closed/internal/domain"
 	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
 	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
 	"github.com/animus-labs/animus-go/closed/internal/platform/rbac"
 	"github.com/animus-labs/animus-go/closed/internal/repo"
 )
@@ -121,7 +122,7 @@ func (api *experimentsAPI) handleDevEnvProxy(w http.ResponseWriter, r *http.Requ
 			ResourceType: "dev_environment",
 			ResourceID:   record.Environment.ID,
 			RequestID:    r.Header.Get("X-Request-Id"),
-			IP:           requestIP(r.RemoteAddr),
+			IP:           httpapi.RequestIP(r.RemoteAddr),
 			UserAgent:    r.UserAgent(),
 			Payload: map[string]any{
 				"service":    "experiments",
@@ -1,16 +1,19 @@
-package main
+package experiments
 
 import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"log/slog"
+	"net/http"
 	"strings"
 	"time"
 
 	"github.com/animus-labs/animus-go/closed/internal/dataplane"
 	"github.com/animus-labs/animus-go/closed/internal/domain"
 	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
+	platformpg "github.com/animus-labs/animus-go/closed/internal/platform/postgres"
 	"github.com/animus-labs/animus-go/closed/internal/repo"
 	"github.com/animus-labs/animus-go/closed/internal/repo/postgres"
 )
@@ -20,11 +23,12 @@ type devEnvReconciler struct {
 	db         *sql.DB
 	dpBaseURL  string
 	authSecret string
+	transport  http.RoundTripper
 	interval   time.Duration
 	batchLimit int
 }
 
-func startDevEnvReconciler(ctx context.Context, logger *slog.Logger, db *sql.DB, dpBaseURL, authSecret string, interval time.Duration) {
+func startDevEnvReconciler(ctx context.Context, logger *slog.Logger, db *sql.DB, dpBaseURL, authSecret string, transport http.RoundTripper, interval time.Duration) {
 	dpBaseURL = strings.TrimSpace(dpBaseURL)
 	authSecret = strings.TrimSpace(authSecret)
 	if dpBaseURL == "" || authSecret == "" || db == nil {
@@ -41,53 +45,49 @@ func startDevEnvReconciler(ctx context.Context, logger *slog.Logger, db *sql.DB,
 		db:         db,
 		dpBaseURL:  dpBaseURL,
 		authSecret: authSecret,
+		transport:  transport,
 		interval:   interval,
 		batchLimit: 200,
 	}
 
-	ticker := time.NewTicker(interval)
-	go func() {
-		defer ticker.Stop()
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case <-ticker.C:
-				reconciler.reconcileOnce(ctx)
-			}
-		}
-	}()
+	syncer{
+		name:       "devenv_reconciler",
+		logger:     logger,
+		leader:     platformpg.NewLeader(db, "devenv_reconciler"),
+		interval:   interval,
+		maxBackoff: 10 * interval,
+		pass:       reconciler.reconcileOnce,
+	}.start(ctx)
 }
 
-func (r *devEnvReconciler) reconcileOnce(ctx context.Context) {
+func (r *devEnvReconciler) reconcileOnce(ctx context.Context) error {
 	projectStore := postgres.NewProjectStore(r.db)
 	devEnvStore := postgres.NewDevEnvironmentStore(r.db)
 	if projectStore == nil || devEnvStore == nil {
-		return
+		return errors.New("stores unavailable")
 	}
-	client, err := newDataplaneClient(r.dpBaseURL, r.authSecret)
+	client, err := newDataplaneClient(r.dpBaseURL, r.authSecret, r.transport)
 	if err != nil {
-		return
+		return err
 	}
 	audit := auditlogAppender{db: r.db}
 
 	projects, err := projectStore.List(ctx, repo.ProjectFilter{Limit: r.batchLimit})
 	if err != nil {
-		if r.logger != nil {
-			r.logger.Warn("devenv reconcile projects failed", "error", err)
-		}
-		return
+		return fmt.Errorf("list projects: %w", err)
 	}
 
+	var passErr error
 	now := time.Now().UTC()
 	for _, project := range projects {
 		if ctx.Err() != nil {
-			return
+			return ctx.Err()
 		}
-		if err := expireDevEnvironments(ctx, devEnvStore, client, audit, project.ID, now, r.batchLimit); err != nil && r.logger != nil {
-			r.logger.Warn("devenv reconcile failed", "project_id", project.ID, "error", err)
+		if err := expireDevEnvironments(ctx, devEnvStore, client, audit, project.ID, now, r.batchLimit); err != nil {
+			passErr = errors.Join(passErr, fmt.Errorf("project %s: %w", project.ID, err))
 		}
 	}
+	return passErr
 }
 
 type auditlogAppender struct {
@@ -95,7 +95,7 @@ type auditlogAppender struct {
 }
 
 func (a auditlogAppender) Append(ctx context.Context, event auditlog.Event) error {
-	_, err := auditlog.Insert(ctx, a.db, event)
+	err := auditlog.Enqueue(ctx, a.db, event)
 	return err
 }
 
@@ -1,8 +1,7 @@
-package main
+package experiments
 
 import (
 	"strings"
-	"unicode"
 
 	"github.com/animus-labs/animus-go/closed/internal/domain"
 )
@@ -15,16 +14,3 @@ func validDevEnvRefType(refType string) bool {
 		return false
 	}
 }
-
-func validCommitSHA(raw string) bool {
-	raw = strings.TrimSpace(raw)
-	if len(raw) < 7 || len(raw) > 64 {
-		return false
-	}
-	for _, r := range raw {
-		if !unicode.IsDigit(r) && (r < 'a' || r > 'f') && (r < 'A' || r > 'F') {
-			return false
-		}
-	}
-	return true
-}
@@ -0,0 +1,422 @@
+package experiments
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/integrations/digests"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+	repopg "github.com/animus-labs/animus-go/closed/internal/repo/postgres"
+	"github.com/google/uuid"
+)
+
+const (
+	auditDigestCreated = "notification_digest.created"
+	auditDigestUpdated = "notification_digest.updated"
+	auditDigestDeleted = "notification_digest.deleted"
+	auditDigestSent    = "notification_digest.sent"
+	auditDigestFailed  = "notification_digest.failed"
+
+	digestScopeProject = "project"
+	digestScopeUser    = "user"
+)
+
+type notificationDigestRequest struct {
+	Name       string   `json:"name"`
+	Scope      string   `json:"scope,omitempty"`
+	Cadence    string   `json:"cadence"`
+	HourUTC    *int     `json:"hour_utc,omitempty"`
+	Weekday    string   `json:"weekday,omitempty"`
+	Channel    string   `json:"channel"`
+	Recipients []string `json:"recipients,omitempty"`
+	Enabled    *bool    `json:"enabled,omitempty"`
+}
+
+type notificationDigestUpdateRequest struct {
+	Name       *string   `json:"name,omitempty"`
+	Cadence    *string   `json:"cadence,omitempty"`
+	HourUTC    *int      `json:"hour_utc,omitempty"`
+	Weekday    *string   `json:"weekday,omitempty"`
+	Channel    *string   `json:"channel,omitempty"`
+	Recipients *[]string `json:"recipients,omitempty"`
+	Enabled    *bool     `json:"enabled,omitempty"`
+}
+
+type notificationDigestResponse struct {
+	ID         string     `json:"id"`
+	ProjectID  string     `json:"project_id"`
+	Name       string     `json:"name"`
+	Scope      string     `json:"scope"`
+	Subject    string     `json:"subject,omitempty"`
+	Cadence    string     `json:"cadence"`
+	HourUTC    int        `json:"hour_utc"`
+	Weekday    string     `json:"weekday,omitempty"`
+	Channel    string     `json:"channel"`
+	Recipients []string   `json:"recipients"`
+	Enabled    bool       `json:"enabled"`
+	NextRunAt  time.Time  `json:"next_run_at"`
+	LastSentAt *time.Time `json:"last_sent_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	CreatedBy  string     `json:"created_by"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+func notificationDigestResponseFromRecord(record digests.Digest) notificationDigestResponse {
+	out := notificationDigestResponse{
+		ID:         record.ID,
+		ProjectID:  record.ProjectID,
+		Name:       record.Name,
+		Scope:      digestScopeProject,
+		Subject:    record.Subject,
+		Cadence:    string(record.Cadence),
+		HourUTC:    record.HourUTC,
+		Channel:    string(record.Channel),
+		Recipients: record.Recipients,
+		Enabled:    record.Enabled,
+		NextRunAt:  record.NextRunAt,
+		LastSentAt: record.LastSentAt,
+		CreatedAt:  record.CreatedAt,
+		CreatedBy:  record.CreatedBy,
+		UpdatedAt:  record.UpdatedAt,
+	}
+	if record.Subject != "" {
+		out.Scope = digestScopeUser
+	}
+	if record.Cadence == digests.CadenceWeekly {
+		out.Weekday = strings.ToLower(record.Weekday.String())
+	}
+	return out
+}
+
+func parseWeekday(value string) (time.Weekday, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return time.Monday, true
+	}
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.ToLower(day.String()) == value {
+			return day, true
+		}
+	}
+	return 0, false
+}
+
+// validateDigestSchedule normalizes a create or update and returns the error
+// code for the first invalid field.
+func (api *experimentsAPI) validateDigestSchedule(record *digests.Digest) string {
+	if strings.TrimSpace(record.Name) == "" {
+		return "name_required"
+	}
+	if !record.Cadence.Valid() {
+		return "invalid_cadence"
+	}
+	if record.HourUTC < 0 || record.HourUTC > 23 {
+		return "invalid_hour_utc"
+	}
+	if !record.Channel.Valid() {
+		return "invalid_channel"
+	}
+	recipients, err := digests.NormalizeRecipients(record.Recipients)
+	if err != nil {
+		return "invalid_recipients"
+	}
+	record.Recipients = recipients
+	switch record.Channel {
+	case digests.ChannelEmail:
+		if len(recipients) == 0 {
+			return "recipients_required"
+		}
+		if api.digestMailer == nil {
+			return "email_channel_unavailable"
+		}
+	case digests.ChannelWebhook:
+		if len(recipients) > 0 {
+			return "recipients_not_allowed"
+		}
+		if !api.webhookConfig.Enabled() {
+			return "webhook_channel_unavailable"
+		}
+	}
+	return ""
+}
+
+func (api *experimentsAPI) handleCreateNotificationDigest(w http.ResponseWriter, r *http.Request) {
+	identity, ok := auth.IdentityFromContext(r.Context())
+	if !ok || strings.TrimSpace(identity.Subject) == "" {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	projectID, ok := auth.ProjectIDFromContext(r.Context())
+	if !ok || strings.TrimSpace(projectID) == "" {
+		api.writeError(w, r, http.StatusBadRequest, "project_id_required")
+		return
+	}
+
+	var req notificationDigestRequest
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
+		return
+	}
+
+	now := time.Now().UTC()
+	record := digests.Digest{
+		ID:         uuid.NewString(),
+		ProjectID:  projectID,
+		Name:       strings.TrimSpace(req.Name),
+		Cadence:    digests.Cadence(strings.ToLower(strings.TrimSpace(req.Cadence))),
+		HourUTC:    8,
+		Channel:    digests.Channel(strings.ToLower(strings.TrimSpace(req.Channel))),
+		Recipients: req.Recipients,
+		Enabled:    true,
+		CreatedAt:  now,
+		CreatedBy:  identity.Subject,
+		UpdatedAt:  now,
+	}
+	switch strings.ToLower(strings.TrimSpace(req.Scope)) {
+	case "", digestScopeProject:
+	case digestScopeUser:
+		// User digests summarize the caller's own activity only.
+		record.Subject = identity.Subject
+	default:
+		api.writeError(w, r, http.StatusBadRequest, "invalid_scope")
+		return
+	}
+	if req.HourUTC != nil {
+		record.HourUTC = *req.HourUTC
+	}
+	weekday, ok := parseWeekday(req.Weekday)
+	if !ok {
+		api.writeError(w, r, http.StatusBadRequest, "invalid_weekday")
+		return
+	}
+	record.Weekday = weekday
+	if req.Enabled != nil {
+		record.Enabled = *req.Enabled
+	}
+	if code := api.validateDigestSchedule(&record); code != "" {
+		api.writeError(w, r, http.StatusBadRequest, code)
+		return
+	}
+	record.NextRunAt = digests.NextRun(record.Cadence, record.HourUTC, record.Weekday, now)
+
+	store := repopg.NewNotificationDigestStore(api.db)
+	if store == nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	created, err := store.Create(r.Context(), record)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "digest_create_failed")
+		return
+	}
+
+	api.appendDigestAudit(r.Context(), r, auditDigestCreated, identity.Subject, created, nil)
+	api.writeJSON(w, http.StatusCreated, notificationDigestResponseFromRecord(created))
+}
+
+func (api *experimentsAPI) handleListNotificationDigests(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := auth.ProjectIDFromContext(r.Context())
+	if !ok || strings.TrimSpace(projectID) == "" {
+		api.writeError(w, r, http.StatusBadRequest, "project_id_required")
+		return
+	}
+	store := repopg.NewNotificationDigestStore(api.db)
+	if store == nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	limit := httpapi.Limit(r, 100, 500)
+	records, err := store.List(r.Context(), projectID, limit)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "digest_list_failed")
+		return
+	}
+	out := make([]notificationDigestResponse, 0, len(records))
+	for _, record := range records {
+		out = append(out, notificationDigestResponseFromRecord(record))
+	}
+	api.writeJSON(w, http.StatusOK, map[string]any{"digests": out})
+}
+
+// notificationDigest loads the digest named in the path, writing the error
+// response when it cannot.
+func (api *experimentsAPI) notificationDigest(w http.ResponseWriter, r *http.Request) (digests.Digest, bool) {
+	projectID, ok := auth.ProjectIDFromContext(r.Context())
+	if !ok || strings.TrimSpace(projectID) == "" {
+		api.writeError(w, r, http.StatusBadRequest, "project_id_required")
+		return digests.Digest{}, false
+	}
+	digestID := strings.TrimSpace(r.PathValue("digest_id"))
+	if digestID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "digest_id_required")
+		return digests.Digest{}, false
+	}
+	store := repopg.NewNotificationDigestStore(api.db)
+	if store == nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return digests.Digest{}, false
+	}
+	record, err := store.Get(r.Context(), projectID, digestID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return digests.Digest{}, false
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "digest_lookup_failed")
+		return digests.Digest{}, false
+	}
+	return record, true
+}
+
+func (api *experimentsAPI) handleGetNotificationDigest(w http.ResponseWriter, r *http.Request) {
+	record, ok := api.notificationDigest(w, r)
+	if !ok {
+		return
+	}
+	api.writeJSON(w, http.StatusOK, notificationDigestResponseFromRecord(record))
+}
+
+func (api *experimentsAPI) handleUpdateNotificationDigest(w http.ResponseWriter, r *http.Request) {
+	identity, ok := auth.IdentityFromContext(r.Context())
+	if !ok || strings.TrimSpace(identity.Subject) == "" {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	current, ok := api.notificationDigest(w, r)
+	if !ok {
+		return
+	}
+
+	var req notificationDigestUpdateRequest
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
+		return
+	}
+
+	updated := current
+	if req.Name != nil {
+		updated.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Cadence != nil {
+		updated.Cadence = digests.Cadence(strings.ToLower(strings.TrimSpace(*req.Cadence)))
+	}
+	if req.HourUTC != nil {
+		updated.HourUTC = *req.HourUTC
+	}
+	if req.Weekday != nil {
+		weekday, ok := parseWeekday(*req.Weekday)
+		if !ok {
+			api.writeError(w, r, http.StatusBadRequest, "invalid_weekday")
+			return
+		}
+		updated.Weekday = weekday
+	}
+	if req.Channel != nil {
+		updated.Channel = digests.Channel(strings.ToLower(strings.TrimSpace(*req.Channel)))
+	}
+	if req.Recipients != nil {
+		updated.Recipients = *req.Recipients
+	}
+	if req.Enabled != nil {
+		updated.Enabled = *req.Enabled
+	}
+	if code := api.validateDigestSchedule(&updated); code != "" {
+		api.writeError(w, r, http.StatusBadRequest, code)
+		return
+	}
+
+	now := time.Now().UTC()
+	// A changed schedule, or a digest enabled again, starts from now instead
+	// of catching up on the periods it missed.
+	if updated.Cadence != current.Cadence || updated.HourUTC != current.HourUTC || updated.Weekday != current.Weekday || (updated.Enabled && !current.Enabled) {
+		updated.NextRunAt = digests.NextRun(updated.Cadence, updated.HourUTC, updated.Weekday, now)
+	}
+	updated.UpdatedAt = now
+
+	record, err := repopg.NewNotificationDigestStore(api.db).Update(r.Context(), updated)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "digest_update_failed")
+		return
+	}
+
+	api.appendDigestAudit(r.Context(), r, auditDigestUpdated, identity.Subject, record, nil)
+	api.writeJSON(w, http.StatusOK, notificationDigestResponseFromRecord(record))
+}
+
+func (api *experimentsAPI) handleDeleteNotificationDigest(w http.ResponseWriter, r *http.Request) {
+	identity, ok := auth.IdentityFromContext(r.Context())
+	if !ok || strings.TrimSpace(identity.Subject) == "" {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	record, ok := api.notificationDigest(w, r)
+	if !ok {
+		return
+	}
+	if err := repopg.NewNotificationDigestStore(api.db).Delete(r.Context(), record.ProjectID, record.ID); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "digest_delete_failed")
+		return
+	}
+
+	api.appendDigestAudit(r.Context(), r, auditDigestDeleted, identity.Subject, record, nil)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handlePreviewNotificationDigest compiles the summary for the period ending
+// now without delivering it.
+func (api *experimentsAPI) handlePreviewNotificationDigest(w http.ResponseWriter, r *http.Request) {
+	record, ok := api.notificationDigest(w, r)
+	if !ok {
+		return
+	}
+	summary, err := api.compileDigest(r.Context(), record, time.Now().UTC())
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "digest_compile_failed")
+		return
+	}
+	api.writeJSON(w, http.StatusOK, summary)
+}
+
+// appendDigestAudit records digest changes and deliveries; r is nil for
+// deliveries made by the scheduler.
+func (api *experimentsAPI) appendDigestAudit(ctx context.Context, r *http.Request, action, actor string, record digests.Digest, extra map[string]any) {
+	payload := map[string]any{
+		"service":    "experiments",
+		"project_id": record.ProjectID,
+		"digest_id":  record.ID,
+		"cadence":    string(record.Cadence),
+		"channel":    string(record.Channel),
+		"enabled":    record.Enabled,
+	}
+	for key, value := range extra {
+		payload[key] = value
+	}
+	event := auditlog.Event{
+		OccurredAt:   time.Now().UTC(),
+		Actor:        actor,
+		Action:       action,
+		ResourceType: "notification_digest",
+		ResourceID:   record.ID,
+		Payload:      payload,
+	}
+	if r != nil {
+		event.RequestID = r.Header.Get("X-Request-Id")
+		event.IP = httpapi.RequestIP(r.RemoteAddr)
+		event.UserAgent = r.UserAgent()
+	}
+	_ = auditlog.Enqueue(ctx, api.db, event)
+}
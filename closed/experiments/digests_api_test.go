@@ -0,0 +1,63 @@
+package experiments
+
+import (
+	"testing"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/integrations/digests"
+	"github.com/animus-labs/animus-go/closed/internal/integrations/webhooks"
+)
+
+func TestParseWeekday(t *testing.T) {
+	if day, ok := parseWeekday(""); !ok || day != time.Monday {
+		t.Fatalf("default weekday = %s, %v", day, ok)
+	}
+	if day, ok := parseWeekday(" Friday "); !ok || day != time.Friday {
+		t.Fatalf("weekday = %s, %v", day, ok)
+	}
+	if _, ok := parseWeekday("fri"); ok {
+		t.Fatalf("expected abbreviation to be rejected")
+	}
+}
+
+func TestValidateDigestSchedule(t *testing.T) {
+	api := &experimentsAPI{webhookConfig: webhooks.Config{EnabledFlag: true}}
+	base := digests.Digest{Name: "weekly", Cadence: digests.CadenceWeekly, HourUTC: 9, Channel: digests.ChannelWebhook}
+
+	valid := base
+	if code := api.validateDigestSchedule(&valid); code != "" {
+		t.Fatalf("unexpected error code %q", code)
+	}
+
+	cases := map[string]func(*digests.Digest){
+		"invalid_cadence":           func(d *digests.Digest) { d.Cadence = "hourly" },
+		"invalid_hour_utc":          func(d *digests.Digest) { d.HourUTC = 24 },
+		"recipients_not_allowed":    func(d *digests.Digest) { d.Recipients = []string{"ops@example.com"} },
+		"recipients_required":       func(d *digests.Digest) { d.Channel = digests.ChannelEmail },
+		"email_channel_unavailable": func(d *digests.Digest) { d.Channel = digests.ChannelEmail; d.Recipients = []string{"ops@example.com"} },
+	}
+	for want, mutate := range cases {
+		record := base
+		mutate(&record)
+		if code := api.validateDigestSchedule(&record); code != want {
+			t.Fatalf("expected %q, got %q", want, code)
+		}
+	}
+
+	disabled := &experimentsAPI{}
+	record := base
+	if code := disabled.validateDigestSchedule(&record); code != "webhook_channel_unavailable" {
+		t.Fatalf("expected webhook_channel_unavailable, got %q", code)
+	}
+}
+
+func TestNotificationDigestResponseScope(t *testing.T) {
+	project := notificationDigestResponseFromRecord(digests.Digest{Cadence: digests.CadenceDaily, Weekday: time.Monday})
+	if project.Scope != digestScopeProject || project.Weekday != "" {
+		t.Fatalf("unexpected project digest response: %+v", project)
+	}
+	user := notificationDigestResponseFromRecord(digests.Digest{Subject: "alice", Cadence: digests.CadenceWeekly, Weekday: time.Friday})
+	if user.Scope != digestScopeUser || user.Weekday != "friday" {
+		t.Fatalf("unexpected user digest response: %+v", user)
+	}
+}
@@ -0,0 +1,205 @@
+package experiments
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/domain"
+	"github.com/animus-labs/animus-go/closed/internal/integrations/digests"
+	"github.com/animus-labs/animus-go/closed/internal/integrations/webhooks"
+	repopg "github.com/animus-labs/animus-go/closed/internal/repo/postgres"
+)
+
+const digestSchedulerActor = "system:notification-digests"
+
+func startDigestScheduler(ctx context.Context, logger *slog.Logger, api *experimentsAPI, interval time.Duration) {
+	if api == nil || api.db == nil {
+		return
+	}
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := api.runDueDigests(ctx, time.Now().UTC()); err != nil && logger != nil {
+					logger.Warn("notification digest run failed", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// runDueDigests claims every due digest before delivering it, so a digest is
+// sent at most once per period even with several replicas. Periods missed
+// while the service was down are skipped rather than sent in a burst.
+func (api *experimentsAPI) runDueDigests(ctx context.Context, now time.Time) error {
+	store := repopg.NewNotificationDigestStore(api.db)
+	due, err := store.ListDue(ctx, now, 50)
+	if err != nil {
+		return err
+	}
+	var lastErr error
+	for _, record := range due {
+		next := digests.NextRun(record.Cadence, record.HourUTC, record.Weekday, now)
+		claimed, err := store.Claim(ctx, record.ID, record.NextRunAt, next, now)
+		if err != nil {
+			lastErr = errors.Join(lastErr, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+		summary, err := api.compileDigest(ctx, record, record.NextRunAt)
+		if err == nil {
+			err = api.deliverDigest(ctx, record, summary, now)
+		}
+		if err != nil {
+			api.appendDigestAudit(ctx, nil, auditDigestFailed, digestSchedulerActor, record, map[string]any{
+				"period_end": record.NextRunAt.Format(time.RFC3339),
+				"error":      err.Error(),
+			})
+			lastErr = errors.Join(lastErr, err)
+			continue
+		}
+		api.appendDigestAudit(ctx, nil, auditDigestSent, digestSchedulerActor, record, map[string]any{
+			"period_end": record.NextRunAt.Format(time.RFC3339),
+		})
+	}
+	return lastErr
+}
+
+func (api *experimentsAPI) deliverDigest(ctx context.Context, record digests.Digest, summary digests.Summary, now time.Time) error {
+	switch record.Channel {
+	case digests.ChannelEmail:
+		subject, body := summary.Text(record.Name)
+		return api.digestMailer.Send(record.Recipients, subject, body, now)
+	case digests.ChannelWebhook:
+		raw, err := json.Marshal(summary)
+		if err != nil {
+			return err
+		}
+		payload, err := webhooks.NotificationDigestPayload(record.ProjectID, record.ID, summary.PeriodEnd, raw, now)
+		if err != nil {
+			return err
+		}
+		return api.enqueueWebhookPayload(ctx, digestSchedulerActor, "", payload)
+	default:
+		return errors.New("unsupported digest channel")
+	}
+}
+
+// compileDigest summarizes the cadence period ending at periodEnd. Pending
+// approvals and usage are reported as of now; they have no period.
+func (api *experimentsAPI) compileDigest(ctx context.Context, record digests.Digest, periodEnd time.Time) (digests.Summary, error) {
+	periodEnd = periodEnd.UTC()
+	summary := digests.Summary{
+		DigestID:    record.ID,
+		ProjectID:   record.ProjectID,
+		Subject:     record.Subject,
+		Cadence:     record.Cadence,
+		PeriodStart: periodEnd.Add(-record.Cadence.Period()),
+		PeriodEnd:   periodEnd,
+	}
+
+	rows, err := api.db.QueryContext(
+		ctx,
+		`SELECT s.status, COUNT(DISTINCT s.run_id)
+		 FROM experiment_run_state_events s
+		 JOIN experiment_runs r ON r.run_id = s.run_id
+		 WHERE r.project_id = $1
+		   AND s.status IN ('succeeded', 'failed', 'canceled')
+		   AND s.observed_at >= $2 AND s.observed_at < $3
+		   AND ($4 = '' OR EXISTS (
+				SELECT 1 FROM audit_events a
+				WHERE a.action = 'experiment_run.create'
+				  AND a.resource_type = 'experiment_run'
+				  AND a.resource_id = r.run_id
+				  AND a.actor = $4))
+		 GROUP BY s.status`,
+		record.ProjectID,
+		summary.PeriodStart,
+		summary.PeriodEnd,
+		record.Subject,
+	)
+	if err != nil {
+		return digests.Summary{}, err
+	}
+	for rows.Next() {
+		var (
+			status string
+			count  int
+		)
+		if err := rows.Scan(&status, &count); err != nil {
+			_ = rows.Close()
+			return digests.Summary{}, err
+		}
+		switch status {
+		case "succeeded":
+			summary.RunsCompleted.Succeeded = count
+		case "failed":
+			summary.RunsCompleted.Failed = count
+		case "canceled":
+			summary.RunsCompleted.Canceled = count
+		}
+	}
+	if err := rows.Err(); err != nil {
+		_ = rows.Close()
+		return digests.Summary{}, err
+	}
+	_ = rows.Close()
+
+	if err := api.db.QueryRowContext(
+		ctx,
+		`SELECT COUNT(*)
+		 FROM policy_approvals pa
+		 JOIN experiment_runs r ON r.run_id = pa.run_id
+		 WHERE r.project_id = $1 AND pa.status = $2 AND ($3 = '' OR pa.requested_by = $3)`,
+		record.ProjectID,
+		approvalStatusPending,
+		record.Subject,
+	).Scan(&summary.ApprovalsPending); err != nil {
+		return digests.Summary{}, err
+	}
+
+	if err := api.db.QueryRowContext(
+		ctx,
+		`SELECT COUNT(*)
+		 FROM audit_events a
+		 JOIN dataset_versions v ON v.version_id = a.resource_id
+		 WHERE a.action = 'quality_gate.block'
+		   AND a.resource_type = 'dataset_version'
+		   AND v.project_id = $1
+		   AND a.occurred_at >= $2 AND a.occurred_at < $3
+		   AND ($4 = '' OR a.actor = $4)`,
+		record.ProjectID,
+		summary.PeriodStart,
+		summary.PeriodEnd,
+		record.Subject,
+	).Scan(&summary.GatesFailed); err != nil {
+		return digests.Summary{}, err
+	}
+
+	if err := api.db.QueryRowContext(
+		ctx,
+		`SELECT
+			(SELECT COALESCE(SUM(size_bytes), 0) FROM dataset_versions WHERE project_id = $1),
+			(SELECT COALESCE(SUM(size_bytes), 0) FROM experiment_run_artifacts WHERE project_id = $1),
+			(SELECT COUNT(*) FROM dev_environments WHERE project_id = $1 AND state IN ($2, $3))`,
+		record.ProjectID,
+		domain.DevEnvStateProvisioning,
+		domain.DevEnvStateActive,
+	).Scan(&summary.Usage.DatasetStorageBytes, &summary.Usage.ArtifactStorageBytes, &summary.Usage.ActiveDevEnvironments); err != nil {
+		return digests.Summary{}, err
+	}
+	return summary, nil
+}
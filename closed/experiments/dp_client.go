@@ -1,4 +1,4 @@
-package main
+package experiments
 
 import (
 	"bytes"
@@ -7,6 +7,8 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -26,7 +28,10 @@ type dataplaneClient struct {
 	httpClient *http.Client
 }
 
-func newDataplaneClient(baseURL, secret string) (*dataplaneClient, error) {
+// newDataplaneClient builds a client for the Data Plane internal API. A nil
+// transport uses http.DefaultTransport; with internal mTLS it presents the
+// control plane's client certificate.
+func newDataplaneClient(baseURL, secret string, transport http.RoundTripper) (*dataplaneClient, error) {
 	baseURL = strings.TrimRight(strings.TrimSpace(baseURL), "/")
 	if baseURL == "" {
 		return nil, errors.New("dataplane base url is required")
@@ -38,7 +43,8 @@ func newDataplaneClient(baseURL, secret string) (*dataplaneClient, error) {
 		baseURL: baseURL,
 		secret:  secret,
 		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
+			Timeout:   10 * time.Second,
+			Transport: transport,
 		},
 	}, nil
 }
@@ -63,6 +69,31 @@ func (c *dataplaneClient) GetRunStatus(ctx context.Context, projectID, runID, re
 	return resp, status, err
 }
 
+// GetStepStatus reports the Job of one attempt of a pipeline step.
+func (c *dataplaneClient) GetStepStatus(ctx context.Context, projectID, runID, stepName string, attempt int, requestID string) (dataplane.RunExecutionStatus, int, error) {
+	if c == nil {
+		return dataplane.RunExecutionStatus{}, 0, errors.New("dataplane client not initialized")
+	}
+	query := url.Values{}
+	query.Set("project_id", strings.TrimSpace(projectID))
+	query.Set("step", stepName)
+	query.Set("attempt", strconv.Itoa(attempt))
+	path := fmt.Sprintf("/internal/dp/runs/%s/status?%s", strings.TrimSpace(runID), query.Encode())
+	var resp dataplane.RunExecutionStatus
+	status, err := c.getJSON(ctx, path, requestID, &resp)
+	return resp, status, err
+}
+
+func (c *dataplaneClient) CancelRun(ctx context.Context, req dataplane.RunCancelRequest, requestID string) (dataplane.RunCancelResponse, int, error) {
+	if c == nil {
+		return dataplane.RunCancelResponse{}, 0, errors.New("dataplane client not initialized")
+	}
+	path := fmt.Sprintf("/internal/dp/runs/%s:cancel", strings.TrimSpace(req.RunID))
+	var resp dataplane.RunCancelResponse
+	status, err := c.postJSON(ctx, path, req, requestID, &resp)
+	return resp, status, err
+}
+
 func (c *dataplaneClient) ProvisionDevEnv(ctx context.Context, req dataplane.DevEnvProvisionRequest, requestID string) (dataplane.DevEnvProvisionResponse, int, error) {
 	if c == nil {
 		return dataplane.DevEnvProvisionResponse{}, 0, errors.New("dataplane client not initialized")
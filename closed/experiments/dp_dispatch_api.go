@@ -1,7 +1,11 @@
-package main
+package experiments
 
 import (
+	"context"
+	"database/sql"
+	"encoding/json"
 	"errors"
+	"net"
 	"net/http"
 	"strings"
 	"time"
@@ -10,6 +14,8 @@ import (
 	"github.com/animus-labs/animus-go/closed/internal/domain"
 	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
 	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	"github.com/animus-labs/animus-go/closed/internal/platform/policy"
 	"github.com/animus-labs/animus-go/closed/internal/repo"
 	"github.com/animus-labs/animus-go/closed/internal/repo/postgres"
 	"github.com/google/uuid"
@@ -17,15 +23,46 @@ import (
 
 type runDispatchRequest struct {
 	IdempotencyKey string `json:"idempotencyKey,omitempty"`
+	// Priority orders the run in the queue when concurrency limits are set.
+	Priority string `json:"priority,omitempty"`
+	// MaxDurationSeconds bounds execution; active policies may lower it.
+	MaxDurationSeconds int64 `json:"maxDurationSeconds,omitempty"`
+	// AgentLabels routes the run to a remote agent carrying all of them
+	// instead of the data plane.
+	AgentLabels []string `json:"agentLabels,omitempty"`
 }
 
 type runDispatchResponse struct {
-	RunID      string `json:"runId"`
-	ProjectID  string `json:"projectId"`
-	DispatchID string `json:"dispatchId"`
-	Status     string `json:"status"`
-	DPBaseURL  string `json:"dpBaseUrl"`
-	Created    bool   `json:"created"`
+	RunID         string `json:"runId"`
+	ProjectID     string `json:"projectId"`
+	DispatchID    string `json:"dispatchId,omitempty"`
+	Status        string `json:"status"`
+	DPBaseURL     string `json:"dpBaseUrl,omitempty"`
+	Created       bool   `json:"created"`
+	Priority      string `json:"priority,omitempty"`
+	QueuePosition int    `json:"queuePosition,omitempty"`
+	// MaxDurationSeconds is the effective limit after policy caps.
+	MaxDurationSeconds int64 `json:"maxDurationSeconds,omitempty"`
+}
+
+// dispatchOrigin is who a dispatch is audited as: the caller for direct
+// dispatches, the run queue worker on behalf of the caller otherwise.
+type dispatchOrigin struct {
+	Actor       string
+	RequestedBy string
+	RequestID   string
+	IP          net.IP
+	UserAgent   string
+}
+
+func dispatchOriginFromRequest(r *http.Request, identity auth.Identity) dispatchOrigin {
+	return dispatchOrigin{
+		Actor:       identity.Subject,
+		RequestedBy: identity.Subject,
+		RequestID:   r.Header.Get("X-Request-Id"),
+		IP:          httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:   r.UserAgent(),
+	}
 }
 
 func (api *experimentsAPI) handleDispatchRun(w http.ResponseWriter, r *http.Request) {
@@ -45,18 +82,32 @@ func (api *experimentsAPI) handleDispatchRun(w http.ResponseWriter, r *http.Requ
 		api.writeError(w, r, http.StatusBadRequest, "run_id_required")
 		return
 	}
-	if strings.TrimSpace(api.dataplaneURL) == "" {
-		api.writeError(w, r, http.StatusInternalServerError, "dataplane_url_not_configured")
-		return
-	}
 	if strings.TrimSpace(api.runTokenSecret) == "" {
 		api.writeError(w, r, http.StatusInternalServerError, "internal_auth_not_configured")
 		return
 	}
 
 	var req runDispatchRequest
-	if err := decodeJSON(r, &req); err != nil {
-		api.writeError(w, r, http.StatusBadRequest, "invalid_json")
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
+		return
+	}
+	agentLabels, ok := normalizeAgentLabels(req.AgentLabels)
+	if !ok {
+		api.writeError(w, r, http.StatusBadRequest, "invalid_agent_labels")
+		return
+	}
+	if len(agentLabels) == 0 && strings.TrimSpace(api.dataplaneURL) == "" {
+		api.writeError(w, r, http.StatusInternalServerError, "dataplane_url_not_configured")
+		return
+	}
+	priority, ok := normalizeRunPriority(req.Priority)
+	if !ok {
+		api.writeError(w, r, http.StatusBadRequest, "invalid_priority")
+		return
+	}
+	if req.MaxDurationSeconds < 0 {
+		api.writeError(w, r, http.StatusBadRequest, "invalid_max_duration")
 		return
 	}
 
@@ -87,6 +138,10 @@ func (api *experimentsAPI) handleDispatchRun(w http.ResponseWriter, r *http.Requ
 		api.writeError(w, r, http.StatusConflict, "run_terminal")
 		return
 	}
+	if len(agentLabels) > 0 && isPipelineRun(runRecord) {
+		api.writeError(w, r, http.StatusBadRequest, "agent_pipeline_unsupported")
+		return
+	}
 
 	dpStore := postgres.NewDPEventStore(api.db)
 	if dpStore == nil {
@@ -96,34 +151,123 @@ func (api *experimentsAPI) handleDispatchRun(w http.ResponseWriter, r *http.Requ
 
 	if existing, err := dpStore.GetDispatchByRunID(r.Context(), projectID, runID); err == nil {
 		if shouldRetryDispatch(existing.Status) {
-			status, err := api.dispatchToDataplane(r, runRecord, existing.DispatchID, identity)
+			status, err := api.dispatchToDataplane(r.Context(), runRecord, existing, dispatchOriginFromRequest(r, identity))
 			if err != nil {
 				api.writeError(w, r, http.StatusInternalServerError, "internal_error")
 				return
 			}
 			api.writeJSON(w, http.StatusOK, runDispatchResponse{
-				RunID:      runID,
-				ProjectID:  projectID,
-				DispatchID: existing.DispatchID,
-				Status:     status,
-				DPBaseURL:  existing.DPBaseURL,
-				Created:    false,
+				RunID:              runID,
+				ProjectID:          projectID,
+				DispatchID:         existing.DispatchID,
+				Status:             status,
+				DPBaseURL:          existing.DPBaseURL,
+				Created:            false,
+				MaxDurationSeconds: existing.MaxDurationSeconds.Int64,
 			})
 			return
 		}
 		api.writeJSON(w, http.StatusOK, runDispatchResponse{
-			RunID:      runID,
-			ProjectID:  projectID,
-			DispatchID: existing.DispatchID,
-			Status:     existing.Status,
-			DPBaseURL:  existing.DPBaseURL,
-			Created:    false,
+			RunID:              runID,
+			ProjectID:          projectID,
+			DispatchID:         existing.DispatchID,
+			Status:             existing.Status,
+			DPBaseURL:          existing.DPBaseURL,
+			Created:            false,
+			MaxDurationSeconds: existing.MaxDurationSeconds.Int64,
+		})
+		return
+	}
+
+	maxDurationSeconds, err := api.runMaxDurationSeconds(r.Context(), req.MaxDurationSeconds)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if !api.requireRunDispatchAllowed(w, r, identity, runRecord) {
+		return
+	}
+	if len(agentLabels) > 0 {
+		if err := postgres.NewRunAgentStore(api.db).Assign(r.Context(), runRecord.ID, runRecord.ProjectID, agentLabels, identity.Subject, time.Now().UTC()); err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+	}
+
+	if api.runQueue.Enabled() {
+		api.enqueueRun(w, r, runRecord, idempotencyKey, priority, maxDurationSeconds, identity)
+		return
+	}
+
+	target, err := api.dispatchTarget(r.Context(), api.db, runID)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	dispatch, err := api.newRunDispatch(runRecord, target, idempotencyKey, identity.Subject, maxDurationSeconds, time.Now().UTC())
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	record, created, err := dpStore.CreateDispatch(r.Context(), dispatch)
+	if err != nil {
+		api.writeRepoError(w, r, err)
+		return
+	}
+	if !created {
+		api.writeJSON(w, http.StatusOK, runDispatchResponse{
+			RunID:              runID,
+			ProjectID:          projectID,
+			DispatchID:         record.DispatchID,
+			Status:             record.Status,
+			DPBaseURL:          record.DPBaseURL,
+			Created:            false,
+			MaxDurationSeconds: record.MaxDurationSeconds.Int64,
 		})
 		return
 	}
 
+	status, err := api.dispatchToDataplane(r.Context(), runRecord, record, dispatchOriginFromRequest(r, identity))
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	api.writeJSON(w, http.StatusOK, runDispatchResponse{
+		RunID:              runID,
+		ProjectID:          projectID,
+		DispatchID:         record.DispatchID,
+		Status:             status,
+		DPBaseURL:          record.DPBaseURL,
+		Created:            true,
+		MaxDurationSeconds: record.MaxDurationSeconds.Int64,
+	})
+}
+
+// runMaxDurationSeconds applies the max_run_duration of active policies to
+// the requested limit: the smallest bound wins and 0 means unbounded.
+func (api *experimentsAPI) runMaxDurationSeconds(ctx context.Context, requested int64) (int64, error) {
+	policies, err := api.loadActivePolicyVersions(ctx)
+	if err != nil {
+		return 0, err
+	}
+	limit := requested
+	for _, record := range policies {
+		var spec policy.Spec
+		if err := json.Unmarshal(record.SpecJSON, &spec); err != nil {
+			return 0, err
+		}
+		capSeconds := int64(spec.RunDurationLimit() / time.Second)
+		if capSeconds > 0 && (limit == 0 || capSeconds < limit) {
+			limit = capSeconds
+		}
+	}
+	return limit, nil
+}
+
+// newRunDispatch builds the requested dispatch record for runRecord, sent to
+// target: the data plane URL or agentDispatchTarget.
+func (api *experimentsAPI) newRunDispatch(runRecord repo.RunRecord, target, idempotencyKey, requestedBy string, maxDurationSeconds int64, now time.Time) (postgres.RunDispatchRecord, error) {
 	dispatchID := uuid.NewString()
-	now := time.Now().UTC()
 	integrity, err := integritySHA256(struct {
 		DispatchID  string    `json:"dispatch_id"`
 		RunID       string    `json:"run_id"`
@@ -132,80 +276,99 @@ func (api *experimentsAPI) handleDispatchRun(w http.ResponseWriter, r *http.Requ
 		SpecHash    string    `json:"spec_hash"`
 		Requested   time.Time `json:"requested_at"`
 		RequestedBy string    `json:"requested_by"`
+		MaxDuration int64     `json:"max_duration_seconds,omitempty"`
 	}{
 		DispatchID:  dispatchID,
-		RunID:       runID,
-		ProjectID:   projectID,
-		DPBaseURL:   api.dataplaneURL,
+		RunID:       runRecord.ID,
+		ProjectID:   runRecord.ProjectID,
+		DPBaseURL:   target,
 		SpecHash:    runRecord.SpecHash,
 		Requested:   now,
-		RequestedBy: identity.Subject,
+		RequestedBy: requestedBy,
+		MaxDuration: maxDurationSeconds,
 	})
 	if err != nil {
-		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
-		return
+		return postgres.RunDispatchRecord{}, err
 	}
-
-	record, created, err := dpStore.CreateDispatch(r.Context(), postgres.RunDispatchRecord{
+	return postgres.RunDispatchRecord{
 		DispatchID:     dispatchID,
-		RunID:          runID,
-		ProjectID:      projectID,
+		RunID:          runRecord.ID,
+		ProjectID:      runRecord.ProjectID,
 		IdempotencyKey: idempotencyKey,
-		DPBaseURL:      api.dataplaneURL,
+		DPBaseURL:      target,
 		Status:         dataplane.DispatchStatusRequested,
 		SpecHash:       runRecord.SpecHash,
 		RequestedAt:    now,
-		RequestedBy:    identity.Subject,
+		RequestedBy:    requestedBy,
 		UpdatedAt:      now,
 		IntegritySHA:   integrity,
-	})
-	if err != nil {
-		api.writeRepoError(w, r, err)
-		return
-	}
-	if !created {
-		api.writeJSON(w, http.StatusOK, runDispatchResponse{
-			RunID:      runID,
-			ProjectID:  projectID,
-			DispatchID: record.DispatchID,
-			Status:     record.Status,
-			DPBaseURL:  record.DPBaseURL,
-			Created:    false,
-		})
-		return
+		MaxDurationSeconds: sql.NullInt64{
+			Int64: maxDurationSeconds,
+			Valid: maxDurationSeconds > 0,
+		},
+	}, nil
+}
+
+// mintRunToken issues the token the job of runID reports metrics, artifacts
+// and events with through the gateway. It lives for ANIMUS_RUN_TOKEN_TTL or,
+// when longer, the run's maximum duration.
+func (api *experimentsAPI) mintRunToken(runID string, maxDurationSeconds int64, now time.Time) (string, error) {
+	ttl := api.runTokenTTL
+	if ttl <= 0 {
+		ttl = 12 * time.Hour
 	}
+	ttl = max(ttl, time.Duration(maxDurationSeconds)*time.Second)
+	return auth.GenerateRunToken(api.runTokenSecret, auth.RunTokenClaims{
+		RunID: runID,
+		Scopes: []string{
+			auth.RunTokenScopeMetricsWrite,
+			auth.RunTokenScopeArtifactsWrite,
+			auth.RunTokenScopeEventsWrite,
+		},
+		ExpiresAtUnix: now.Add(ttl).Unix(),
+	}, now)
+}
 
-	status, err := api.dispatchToDataplane(r, runRecord, record.DispatchID, identity)
+func (api *experimentsAPI) dispatchToDataplane(ctx context.Context, runRecord repo.RunRecord, dispatch postgres.RunDispatchRecord, origin dispatchOrigin) (string, error) {
+	if isAgentDispatch(dispatch) {
+		return api.dispatchToAgents(ctx, runRecord, dispatch, origin)
+	}
+	if isPipelineRun(runRecord) {
+		return api.advancePipeline(ctx, runRecord, dispatch, origin)
+	}
+	dispatchID := dispatch.DispatchID
+	client, err := newDataplaneClient(api.dataplaneURL, api.runTokenSecret, api.internalTransport)
 	if err != nil {
-		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
-		return
+		return dataplane.DispatchStatusError, err
 	}
-	api.writeJSON(w, http.StatusOK, runDispatchResponse{
-		RunID:      runID,
-		ProjectID:  projectID,
-		DispatchID: record.DispatchID,
-		Status:     status,
-		DPBaseURL:  api.dataplaneURL,
-		Created:    true,
-	})
-}
 
-func (api *experimentsAPI) dispatchToDataplane(r *http.Request, runRecord repo.RunRecord, dispatchID string, identity auth.Identity) (string, error) {
-	client, err := newDataplaneClient(api.dataplaneURL, api.runTokenSecret)
+	checkpoint, err := api.runCheckpointForExecution(ctx, api.db, runRecord.ID)
+	if err != nil {
+		return dataplane.DispatchStatusError, err
+	}
+	datasets, err := api.runDatasetsForExecution(ctx, api.db, runRecord.ID)
+	if err != nil {
+		return dataplane.DispatchStatusError, err
+	}
+	runToken, err := api.mintRunToken(runRecord.ID, dispatch.MaxDurationSeconds.Int64, time.Now().UTC())
 	if err != nil {
 		return dataplane.DispatchStatusError, err
 	}
 
 	status := dataplane.DispatchStatusRequested
 	lastError := ""
-	resp, statusCode, err := client.ExecuteRun(r.Context(), dataplane.RunExecutionRequest{
-		RunID:         runRecord.ID,
-		ProjectID:     runRecord.ProjectID,
-		DispatchID:    dispatchID,
-		EmittedAt:     time.Now().UTC(),
-		RequestedBy:   identity.Subject,
-		CorrelationID: r.Header.Get("X-Request-Id"),
-	}, r.Header.Get("X-Request-Id"))
+	resp, statusCode, err := client.ExecuteRun(ctx, dataplane.RunExecutionRequest{
+		RunID:              runRecord.ID,
+		ProjectID:          runRecord.ProjectID,
+		DispatchID:         dispatchID,
+		EmittedAt:          time.Now().UTC(),
+		RequestedBy:        origin.RequestedBy,
+		CorrelationID:      origin.RequestID,
+		MaxDurationSeconds: dispatch.MaxDurationSeconds.Int64,
+		Checkpoint:         checkpoint,
+		Datasets:           datasets,
+		RunToken:           runToken,
+	}, origin.RequestID)
 	if err == nil {
 		if resp.Accepted {
 			status = dataplane.DispatchStatusAccepted
@@ -220,7 +383,7 @@ func (api *experimentsAPI) dispatchToDataplane(r *http.Request, runRecord repo.R
 		lastError = err.Error()
 	}
 
-	tx, err := api.db.BeginTx(r.Context(), nil)
+	tx, err := api.db.BeginTx(ctx, nil)
 	if err != nil {
 		return status, err
 	}
@@ -229,29 +392,30 @@ func (api *experimentsAPI) dispatchToDataplane(r *http.Request, runRecord repo.R
 	if dpStore == nil {
 		return status, errors.New("dp store unavailable")
 	}
-	if err := dpStore.UpdateDispatchStatus(r.Context(), dispatchID, status, lastError, time.Now().UTC()); err != nil {
+	if err := dpStore.UpdateDispatchStatus(ctx, dispatchID, status, lastError, time.Now().UTC()); err != nil {
 		return status, err
 	}
 
-	if _, err := auditlog.Insert(r.Context(), tx, auditlog.Event{
+	if err := auditlog.Enqueue(ctx, tx, auditlog.Event{
 		OccurredAt:   time.Now().UTC(),
-		Actor:        identity.Subject,
+		Actor:        origin.Actor,
 		Action:       "run.dispatched",
 		ResourceType: "run",
 		ResourceID:   runRecord.ID,
-		RequestID:    r.Header.Get("X-Request-Id"),
-		IP:           requestIP(r.RemoteAddr),
-		UserAgent:    r.UserAgent(),
+		RequestID:    origin.RequestID,
+		IP:           origin.IP,
+		UserAgent:    origin.UserAgent,
 		Payload: map[string]any{
-			"service":       "experiments",
-			"project_id":    runRecord.ProjectID,
-			"run_id":        runRecord.ID,
-			"spec_hash":     runRecord.SpecHash,
-			"dispatch_id":   dispatchID,
-			"status":        status,
-			"dp_base_url":   api.dataplaneURL,
-			"requested_by":  identity.Subject,
-			"response_code": statusCode,
+			"service":              "experiments",
+			"project_id":           runRecord.ProjectID,
+			"run_id":               runRecord.ID,
+			"spec_hash":            runRecord.SpecHash,
+			"dispatch_id":          dispatchID,
+			"status":               status,
+			"dp_base_url":          api.dataplaneURL,
+			"requested_by":         origin.RequestedBy,
+			"response_code":        statusCode,
+			"max_duration_seconds": dispatch.MaxDurationSeconds.Int64,
 		},
 	}); err != nil {
 		return status, err
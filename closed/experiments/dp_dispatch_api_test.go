@@ -0,0 +1,40 @@
+package experiments
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+)
+
+func TestMintRunTokenIsScoped(t *testing.T) {
+	api := &experimentsAPI{runTokenSecret: "secret", runTokenTTL: time.Hour}
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	token, err := api.mintRunToken("run-1", 0, now)
+	if err != nil {
+		t.Fatalf("mintRunToken() err=%v", err)
+	}
+	claims, err := auth.VerifyRunToken("secret", token, now)
+	if err != nil {
+		t.Fatalf("VerifyRunToken() err=%v", err)
+	}
+	scopes := claims.EffectiveScopes()
+	for _, scope := range []string{auth.RunTokenScopeMetricsWrite, auth.RunTokenScopeArtifactsWrite, auth.RunTokenScopeEventsWrite} {
+		if !slices.Contains(scopes, scope) {
+			t.Fatalf("scopes=%v, missing %s", scopes, scope)
+		}
+	}
+	if claims.RunID != "run-1" || claims.ExpiresAtUnix != now.Add(time.Hour).Unix() {
+		t.Fatalf("claims=%+v", claims)
+	}
+
+	token, err = api.mintRunToken("run-1", int64((3 * time.Hour).Seconds()), now)
+	if err != nil {
+		t.Fatalf("mintRunToken() err=%v", err)
+	}
+	if claims, err := auth.VerifyRunToken("secret", token, now); err != nil || claims.ExpiresAtUnix != now.Add(3*time.Hour).Unix() {
+		t.Fatalf("long run: claims=%+v err=%v", claims, err)
+	}
+}
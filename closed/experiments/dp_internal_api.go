@@ -1,4 +1,4 @@
-package main
+package experiments
 
 import (
 	"context"
@@ -12,6 +12,7 @@ import (
 	"github.com/animus-labs/animus-go/closed/internal/dataplane"
 	"github.com/animus-labs/animus-go/closed/internal/domain"
 	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
 	"github.com/animus-labs/animus-go/closed/internal/repo"
 	"github.com/animus-labs/animus-go/closed/internal/repo/postgres"
 	"github.com/animus-labs/animus-go/closed/internal/service/runs"
@@ -40,8 +41,8 @@ func (api *experimentsAPI) handleDPHeartbeat(w http.ResponseWriter, r *http.Requ
 	}
 
 	var req dataplane.RunHeartbeat
-	if err := decodeJSON(r, &req); err != nil {
-		api.writeError(w, r, http.StatusBadRequest, "invalid_json")
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
 		return
 	}
 	if strings.TrimSpace(req.RunID) == "" {
@@ -139,6 +140,13 @@ func (api *experimentsAPI) handleDPHeartbeat(w http.ResponseWriter, r *http.Requ
 		}
 	}
 
+	if inserted && strings.TrimSpace(req.StepName) != "" {
+		if err := markPipelineStepRunning(r.Context(), tx, runID, strings.TrimSpace(req.StepName), req.Attempt, req.EmittedAt.UTC()); err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+	}
+
 	var applied bool
 	var stateErr error
 	if inserted {
@@ -146,7 +154,7 @@ func (api *experimentsAPI) handleDPHeartbeat(w http.ResponseWriter, r *http.Requ
 			Actor:     identity.Subject,
 			RequestID: r.Header.Get("X-Request-Id"),
 			UserAgent: r.UserAgent(),
-			IP:        requestIP(r.RemoteAddr),
+			IP:        httpapi.RequestIP(r.RemoteAddr),
 			Service:   "experiments",
 		}, projectID, runID, domain.RunStateRunning)
 	}
@@ -188,8 +196,8 @@ func (api *experimentsAPI) handleDPTerminal(w http.ResponseWriter, r *http.Reque
 	}
 
 	var req dataplane.RunTerminalState
-	if err := decodeJSON(r, &req); err != nil {
-		api.writeError(w, r, http.StatusBadRequest, "invalid_json")
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
 		return
 	}
 	if strings.TrimSpace(req.RunID) == "" {
@@ -293,6 +301,33 @@ func (api *experimentsAPI) handleDPTerminal(w http.ResponseWriter, r *http.Reque
 		}
 	}
 
+	// The terminal state of a step attempt ends the attempt; the pipeline
+	// decides whether the run goes on, retries the step or finishes.
+	if stepName := strings.TrimSpace(req.StepName); stepName != "" {
+		if inserted {
+			finishedAt := req.EmittedAt.UTC()
+			if req.FinishedAt != nil {
+				finishedAt = req.FinishedAt.UTC()
+			}
+			if _, err := finishPipelineStep(r.Context(), tx, runID, stepName, req.Attempt, nextState, req.Reason, req.ExitCode, finishedAt); err != nil {
+				api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+				return
+			}
+		}
+		if err := tx.Commit(); err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+		if inserted {
+			api.advancePipelineAfterStep(r, identity.Subject, runRecord)
+		}
+		api.writeJSON(w, http.StatusOK, dataplane.RunTerminalResponse{
+			Accepted:  !duplicate,
+			Duplicate: duplicate,
+		})
+		return
+	}
+
 	var applied bool
 	var stateErr error
 	if inserted {
@@ -300,7 +335,7 @@ func (api *experimentsAPI) handleDPTerminal(w http.ResponseWriter, r *http.Reque
 			Actor:     identity.Subject,
 			RequestID: r.Header.Get("X-Request-Id"),
 			UserAgent: r.UserAgent(),
-			IP:        requestIP(r.RemoteAddr),
+			IP:        httpapi.RequestIP(r.RemoteAddr),
 			Service:   "experiments",
 		}, projectID, runID, nextState)
 	}
@@ -352,8 +387,8 @@ func (api *experimentsAPI) handleDPArtifactCommitted(w http.ResponseWriter, r *h
 	}
 
 	var req dataplane.ArtifactCommitted
-	if err := decodeJSON(r, &req); err != nil {
-		api.writeError(w, r, http.StatusBadRequest, "invalid_json")
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
 		return
 	}
 	if strings.TrimSpace(req.RunID) == "" {
@@ -559,8 +594,8 @@ func (api *experimentsAPI) handleDPSecretAccessed(w http.ResponseWriter, r *http
 	}
 
 	var req dataplane.SecretAccessed
-	if err := decodeJSON(r, &req); err != nil {
-		api.writeError(w, r, http.StatusBadRequest, "invalid_json")
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
 		return
 	}
 	if strings.TrimSpace(req.RunID) == "" {
@@ -674,7 +709,7 @@ func (api *experimentsAPI) handleDPSecretAccessed(w http.ResponseWriter, r *http
 				ResourceType: "run",
 				ResourceID:   runID,
 				RequestID:    r.Header.Get("X-Request-Id"),
-				IP:           requestIP(r.RemoteAddr),
+				IP:           httpapi.RequestIP(r.RemoteAddr),
 				UserAgent:    r.UserAgent(),
 				Payload:      payload,
 			})
@@ -1,9 +1,10 @@
-package main
+package experiments
 
 import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"strings"
@@ -12,26 +13,32 @@ import (
 	"github.com/animus-labs/animus-go/closed/internal/dataplane"
 	"github.com/animus-labs/animus-go/closed/internal/domain"
 	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
+	platformpg "github.com/animus-labs/animus-go/closed/internal/platform/postgres"
 	"github.com/animus-labs/animus-go/closed/internal/repo"
 	"github.com/animus-labs/animus-go/closed/internal/repo/postgres"
 	"github.com/animus-labs/animus-go/closed/internal/service/runs"
 	"github.com/google/uuid"
 )
 
+// runTimeoutReason marks runs failed for exceeding their max duration.
+const runTimeoutReason = "timeout"
+
 type dpReconciler struct {
 	logger     *slog.Logger
 	db         *sql.DB
 	dpBaseURL  string
 	authSecret string
+	transport  http.RoundTripper
 	interval   time.Duration
 	staleAfter time.Duration
 	batchLimit int
 }
 
-func startDPReconciler(ctx context.Context, logger *slog.Logger, db *sql.DB, dpBaseURL, authSecret string, interval, staleAfter time.Duration) {
+func startDPReconciler(ctx context.Context, logger *slog.Logger, db *sql.DB, dpBaseURL, authSecret string, transport http.RoundTripper, interval, staleAfter time.Duration) {
 	dpBaseURL = strings.TrimSpace(dpBaseURL)
 	authSecret = strings.TrimSpace(authSecret)
-	if dpBaseURL == "" || authSecret == "" || db == nil {
+	// Without a data plane URL the reconciler still watches agent runs.
+	if authSecret == "" || db == nil {
 		if logger != nil {
 			logger.Warn("dp reconciler disabled", "dp_base_url", dpBaseURL != "", "auth", authSecret != "")
 		}
@@ -48,50 +55,60 @@ func startDPReconciler(ctx context.Context, logger *slog.Logger, db *sql.DB, dpB
 		db:         db,
 		dpBaseURL:  dpBaseURL,
 		authSecret: authSecret,
+		transport:  transport,
 		interval:   interval,
 		staleAfter: staleAfter,
 		batchLimit: 200,
 	}
 
-	ticker := time.NewTicker(interval)
-	go func() {
-		defer ticker.Stop()
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case <-ticker.C:
-				reconciler.reconcileOnce(ctx)
-			}
-		}
-	}()
+	syncer{
+		name:       "dp_reconciler",
+		logger:     logger,
+		leader:     platformpg.NewLeader(db, "dp_reconciler"),
+		interval:   interval,
+		maxBackoff: 10 * interval,
+		pass:       reconciler.reconcileOnce,
+	}.start(ctx)
 }
 
-func (r *dpReconciler) reconcileOnce(ctx context.Context) {
+// reconcileOnce returns the joined per-run errors so a failing pass backs off.
+func (r *dpReconciler) reconcileOnce(ctx context.Context) error {
 	dpStore := postgres.NewDPEventStore(r.db)
 	if dpStore == nil {
-		return
+		return errors.New("dp store unavailable")
 	}
 	statuses := []string{dataplane.DispatchStatusRequested, dataplane.DispatchStatusAccepted, dataplane.DispatchStatusRunning}
 	dispatches, err := dpStore.ListDispatchesByStatus(ctx, statuses, r.batchLimit)
 	if err != nil {
-		if r.logger != nil {
-			r.logger.Warn("dp reconcile list failed", "error", err)
-		}
-		return
+		return fmt.Errorf("list dispatches: %w", err)
 	}
 
+	var passErr error
+	now := time.Now().UTC()
 	for _, dispatch := range dispatches {
 		if ctx.Err() != nil {
-			return
+			return ctx.Err()
+		}
+		if deadline, ok := dispatchDeadline(dispatch); ok && !now.Before(deadline) {
+			if err := r.timeoutDispatch(ctx, dispatch, deadline); err != nil {
+				passErr = errors.Join(passErr, fmt.Errorf("timeout run %s: %w", dispatch.RunID, err))
+			}
+			continue
+		}
+		if pipeline, err := isPipelineDispatch(ctx, r.db, dispatch.DispatchID); err != nil || pipeline {
+			if err != nil {
+				passErr = errors.Join(passErr, fmt.Errorf("reconcile run %s: %w", dispatch.RunID, err))
+			}
+			continue
 		}
 		if !r.isHeartbeatStale(ctx, dpStore, dispatch.ProjectID, dispatch.RunID) {
 			continue
 		}
-		if err := r.reconcileDispatch(ctx, dispatch); err != nil && r.logger != nil {
-			r.logger.Warn("dp reconcile failed", "run_id", dispatch.RunID, "error", err)
+		if err := r.reconcileDispatch(ctx, dispatch); err != nil {
+			passErr = errors.Join(passErr, fmt.Errorf("reconcile run %s: %w", dispatch.RunID, err))
 		}
 	}
+	return passErr
 }
 
 func (r *dpReconciler) isHeartbeatStale(ctx context.Context, store *postgres.DPEventStore, projectID, runID string) bool {
@@ -104,11 +121,14 @@ func (r *dpReconciler) isHeartbeatStale(ctx context.Context, store *postgres.DPE
 }
 
 func (r *dpReconciler) reconcileDispatch(ctx context.Context, dispatch postgres.RunDispatchRecord) error {
+	if isAgentDispatch(dispatch) {
+		return r.reconcileAgentDispatch(ctx, dispatch)
+	}
 	dpURL := strings.TrimSpace(dispatch.DPBaseURL)
 	if dpURL == "" {
 		dpURL = r.dpBaseURL
 	}
-	client, err := newDataplaneClient(dpURL, r.authSecret)
+	client, err := newDataplaneClient(dpURL, r.authSecret, r.transport)
 	if err != nil {
 		return err
 	}
@@ -127,7 +147,81 @@ func (r *dpReconciler) reconcileDispatch(ctx context.Context, dispatch postgres.
 	return r.applyReconciledState(ctx, dispatch, nextState, status.Reason)
 }
 
+// reconcileAgentDispatch fails a run whose agent has not sent a heartbeat
+// for staleAfter since claiming it. Unclaimed runs keep waiting: an agent
+// that is offline is not a failure of the run.
+func (r *dpReconciler) reconcileAgentDispatch(ctx context.Context, dispatch postgres.RunDispatchRecord) error {
+	_, claimedAt, claimed, err := postgres.NewRunAgentStore(r.db).Assignment(ctx, dispatch.RunID)
+	if err != nil || !claimed {
+		return err
+	}
+	if time.Since(claimedAt) < r.staleAfter {
+		return nil
+	}
+	return r.applyReconciledState(ctx, dispatch, domain.RunStateFailed, agentHeartbeatLostReason)
+}
+
+// dispatchDeadline is when the dispatch exceeds its max duration, counted
+// from the dispatch request.
+func dispatchDeadline(dispatch postgres.RunDispatchRecord) (time.Time, bool) {
+	if !dispatch.MaxDurationSeconds.Valid || dispatch.MaxDurationSeconds.Int64 <= 0 {
+		return time.Time{}, false
+	}
+	return dispatch.RequestedAt.Add(time.Duration(dispatch.MaxDurationSeconds.Int64) * time.Second), true
+}
+
+// timeoutDispatch kills the run's Job and fails the run with reason timeout.
+// If the data plane cannot be reached the run stays active and is retried on
+// the next pass; the Job's activeDeadlineSeconds stops it meanwhile. Agent
+// runs are only failed: the agent enforces the deadline itself and stops the
+// container once its heartbeat is rejected.
+func (r *dpReconciler) timeoutDispatch(ctx context.Context, dispatch postgres.RunDispatchRecord, deadline time.Time) error {
+	if isAgentDispatch(dispatch) {
+		return r.applyRunState(ctx, dispatch, domain.RunStateFailed, runTimeoutReason, "run.timed_out", map[string]any{
+			"deadline":             deadline.UTC(),
+			"max_duration_seconds": dispatch.MaxDurationSeconds.Int64,
+			"job_canceled":         false,
+			"timed_out_at":         time.Now().UTC(),
+		})
+	}
+	dpURL := strings.TrimSpace(dispatch.DPBaseURL)
+	if dpURL == "" {
+		dpURL = r.dpBaseURL
+	}
+	client, err := newDataplaneClient(dpURL, r.authSecret, r.transport)
+	if err != nil {
+		return err
+	}
+	requestID := uuid.NewString()
+	resp, _, err := client.CancelRun(ctx, dataplane.RunCancelRequest{
+		RunID:         dispatch.RunID,
+		ProjectID:     dispatch.ProjectID,
+		DispatchID:    dispatch.DispatchID,
+		Reason:        runTimeoutReason,
+		EmittedAt:     time.Now().UTC(),
+		RequestedBy:   "system:reconciler",
+		CorrelationID: requestID,
+	}, requestID)
+	if err != nil {
+		return err
+	}
+	return r.applyRunState(ctx, dispatch, domain.RunStateFailed, runTimeoutReason, "run.timed_out", map[string]any{
+		"deadline":             deadline.UTC(),
+		"max_duration_seconds": dispatch.MaxDurationSeconds.Int64,
+		"job_canceled":         resp.Canceled,
+		"timed_out_at":         time.Now().UTC(),
+	})
+}
+
 func (r *dpReconciler) applyReconciledState(ctx context.Context, dispatch postgres.RunDispatchRecord, nextState domain.RunState, reason string) error {
+	return r.applyRunState(ctx, dispatch, nextState, reason, "run.reconciled", map[string]any{
+		"reconciled_at": time.Now().UTC(),
+	})
+}
+
+// applyRunState moves the run to nextState and audits it as action with
+// details merged into the payload.
+func (r *dpReconciler) applyRunState(ctx context.Context, dispatch postgres.RunDispatchRecord, nextState domain.RunState, reason, action string, details map[string]any) error {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
@@ -166,28 +260,31 @@ func (r *dpReconciler) applyReconciledState(ctx context.Context, dispatch postgr
 				return err
 			}
 		}
+		payload := map[string]any{
+			"service":     "experiments",
+			"project_id":  dispatch.ProjectID,
+			"run_id":      dispatch.RunID,
+			"spec_hash":   current.SpecHash,
+			"dispatch_id": dispatch.DispatchID,
+			"from":        string(prev),
+			"to":          string(nextState),
+			"reason":      strings.TrimSpace(reason),
+			"dp_base_url": dispatch.DPBaseURL,
+			"status":      dispatch.Status,
+		}
+		for key, value := range details {
+			payload[key] = value
+		}
 		recEvent := auditlog.Event{
 			OccurredAt:   time.Now().UTC(),
 			Actor:        "system:reconciler",
-			Action:       "run.reconciled",
+			Action:       action,
 			ResourceType: "run",
 			ResourceID:   dispatch.RunID,
 			RequestID:    uuid.NewString(),
-			Payload: map[string]any{
-				"service":       "experiments",
-				"project_id":    dispatch.ProjectID,
-				"run_id":        dispatch.RunID,
-				"spec_hash":     current.SpecHash,
-				"dispatch_id":   dispatch.DispatchID,
-				"from":          string(prev),
-				"to":            string(nextState),
-				"reason":        strings.TrimSpace(reason),
-				"dp_base_url":   dispatch.DPBaseURL,
-				"status":        dispatch.Status,
-				"reconciled_at": time.Now().UTC(),
-			},
+			Payload:      payload,
 		}
-		if _, err := auditlog.Insert(ctx, tx, recEvent); err != nil {
+		if err := auditlog.Enqueue(ctx, tx, recEvent); err != nil {
 			return err
 		}
 	}
@@ -1,9 +1,12 @@
-package main
+package experiments
 
 import (
+	"database/sql"
 	"testing"
+	"time"
 
 	"github.com/animus-labs/animus-go/closed/internal/domain"
+	"github.com/animus-labs/animus-go/closed/internal/repo/postgres"
 )
 
 func TestMapStatusToRunStateUnknown(t *testing.T) {
@@ -28,3 +31,17 @@ func TestMapStatusToRunStateAliases(t *testing.T) {
 		}
 	}
 }
+
+func TestDispatchDeadline(t *testing.T) {
+	requested := time.Date(2024, 1, 2, 3, 0, 0, 0, time.UTC)
+	if _, ok := dispatchDeadline(postgres.RunDispatchRecord{RequestedAt: requested}); ok {
+		t.Fatalf("expected no deadline without max duration")
+	}
+	deadline, ok := dispatchDeadline(postgres.RunDispatchRecord{
+		RequestedAt:        requested,
+		MaxDurationSeconds: sql.NullInt64{Int64: 5400, Valid: true},
+	})
+	if !ok || !deadline.Equal(requested.Add(90*time.Minute)) {
+		t.Fatalf("unexpected deadline %v %v", deadline, ok)
+	}
+}
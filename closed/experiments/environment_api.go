@@ -1,4 +1,4 @@
-package main
+package experiments
 
 import (
 	"errors"
@@ -10,6 +10,7 @@ import (
 	"github.com/animus-labs/animus-go/closed/internal/domain"
 	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
 	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
 	"github.com/animus-labs/animus-go/closed/internal/repo"
 	"github.com/animus-labs/animus-go/closed/internal/repo/postgres"
 	"github.com/google/uuid"
@@ -71,8 +72,8 @@ func (api *experimentsAPI) handleCreateEnvironmentDefinition(w http.ResponseWrit
 		return
 	}
 	var req environmentDefinitionRequest
-	if err := decodeJSON(r, &req); err != nil {
-		api.writeError(w, r, http.StatusBadRequest, "invalid_json")
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
 		return
 	}
 	idempotencyKey := strings.TrimSpace(r.Header.Get("Idempotency-Key"))
@@ -144,14 +145,14 @@ func (api *experimentsAPI) handleCreateEnvironmentDefinition(w http.ResponseWrit
 		return
 	}
 	if created {
-		if _, err := auditlog.Insert(r.Context(), tx, auditlog.Event{
+		if err := auditlog.Enqueue(r.Context(), tx, auditlog.Event{
 			OccurredAt:   now,
 			Actor:        identity.Subject,
 			Action:       "environment.defined",
 			ResourceType: "environment_definition",
 			ResourceID:   record.Definition.ID,
 			RequestID:    r.Header.Get("X-Request-Id"),
-			IP:           requestIP(r.RemoteAddr),
+			IP:           httpapi.RequestIP(r.RemoteAddr),
 			UserAgent:    r.UserAgent(),
 			Payload: map[string]any{
 				"service":       "experiments",
@@ -185,7 +186,7 @@ func (api *experimentsAPI) handleListEnvironmentDefinitions(w http.ResponseWrite
 	}
 	name := strings.TrimSpace(r.URL.Query().Get("name"))
 	status := strings.TrimSpace(r.URL.Query().Get("status"))
-	limit := clampInt(parseIntQuery(r, "limit", 100), 1, 500)
+	limit := httpapi.Limit(r, 100, 500)
 
 	store := postgres.NewEnvironmentStore(api.db)
 	if store == nil {
@@ -258,8 +259,8 @@ func (api *experimentsAPI) handleEnvironmentDefinitionVersionedChange(w http.Res
 	}
 
 	var req environmentDefinitionRequest
-	if err := decodeJSON(r, &req); err != nil {
-		api.writeError(w, r, http.StatusBadRequest, "invalid_json")
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
 		return
 	}
 	idempotencyKey := strings.TrimSpace(r.Header.Get("Idempotency-Key"))
@@ -389,14 +390,14 @@ func (api *experimentsAPI) handleEnvironmentDefinitionVersionedChange(w http.Res
 		return
 	}
 	if created {
-		if _, err := auditlog.Insert(r.Context(), tx, auditlog.Event{
+		if err := auditlog.Enqueue(r.Context(), tx, auditlog.Event{
 			OccurredAt:   now,
 			Actor:        identity.Subject,
 			Action:       action,
 			ResourceType: "environment_definition",
 			ResourceID:   record.Definition.ID,
 			RequestID:    r.Header.Get("X-Request-Id"),
-			IP:           requestIP(r.RemoteAddr),
+			IP:           httpapi.RequestIP(r.RemoteAddr),
 			UserAgent:    r.UserAgent(),
 			Payload: map[string]any{
 				"service":                  "experiments",
@@ -435,8 +436,8 @@ func (api *experimentsAPI) handleCreateEnvironmentLock(w http.ResponseWriter, r
 		return
 	}
 	var req environmentLockRequest
-	if err := decodeJSON(r, &req); err != nil {
-		api.writeError(w, r, http.StatusBadRequest, "invalid_json")
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
 		return
 	}
 	idempotencyKey := strings.TrimSpace(r.Header.Get("Idempotency-Key"))
@@ -515,14 +516,14 @@ func (api *experimentsAPI) handleCreateEnvironmentLock(w http.ResponseWriter, r
 		return
 	}
 	if created {
-		if _, err := auditlog.Insert(r.Context(), tx, auditlog.Event{
+		if err := auditlog.Enqueue(r.Context(), tx, auditlog.Event{
 			OccurredAt:   now,
 			Actor:        identity.Subject,
 			Action:       "environment.locked",
 			ResourceType: "environment_lock",
 			ResourceID:   record.Lock.LockID,
 			RequestID:    r.Header.Get("X-Request-Id"),
-			IP:           requestIP(r.RemoteAddr),
+			IP:           httpapi.RequestIP(r.RemoteAddr),
 			UserAgent:    r.UserAgent(),
 			Payload: map[string]any{
 				"service":                   "experiments",
@@ -554,7 +555,7 @@ func (api *experimentsAPI) handleListEnvironmentLocks(w http.ResponseWriter, r *
 		return
 	}
 	definitionID := strings.TrimSpace(r.URL.Query().Get("environment_definition_id"))
-	limit := clampInt(parseIntQuery(r, "limit", 100), 1, 500)
+	limit := httpapi.Limit(r, 100, 500)
 	store := postgres.NewEnvironmentStore(api.db)
 	if store == nil {
 		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
@@ -602,14 +603,14 @@ func (api *experimentsAPI) handleGetEnvironmentLock(w http.ResponseWriter, r *ht
 		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
 		return
 	}
-	if _, err := auditlog.Insert(r.Context(), api.db, auditlog.Event{
+	if err := auditlog.Enqueue(r.Context(), api.db, auditlog.Event{
 		OccurredAt:   time.Now().UTC(),
 		Actor:        identity.Subject,
 		Action:       "environment.lock.read",
 		ResourceType: "environment_lock",
 		ResourceID:   lockID,
 		RequestID:    r.Header.Get("X-Request-Id"),
-		IP:           requestIP(r.RemoteAddr),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
 		UserAgent:    r.UserAgent(),
 		Payload: map[string]any{
 			"service":    "experiments",
@@ -0,0 +1,572 @@
+package experiments
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	"github.com/google/uuid"
+	"github.com/minio/minio-go/v7"
+)
+
+const (
+	evaluationPreviewSchemaV1 = "animus.eval.preview.v1"
+
+	// defaultEvalPreviewSamples caps the previews of an evaluation that was
+	// created without its own preview_samples.
+	defaultEvalPreviewSamples = 16
+	evalPreviewSamplesMax     = 128
+
+	evaluationPreviewMaxBytes = 256 << 10
+	previewRedactedValue      = "[redacted]"
+)
+
+// previewRedactor masks parts of an evaluation preview document before it
+// is stored and returns the paths it masked. Redactors run in order.
+type previewRedactor interface {
+	RedactPreview(ctx context.Context, doc map[string]any) ([]string, error)
+}
+
+// pathPreviewRedactor masks the values at dotted paths of the preview
+// document, such as input.*.email. Segments are path.Match patterns and
+// arrays are walked transparently, as in audit payload redaction.
+type pathPreviewRedactor struct {
+	paths []string
+}
+
+// parsePreviewRedactPaths reads ANIMUS_EVAL_PREVIEW_REDACT_PATHS, a comma
+// separated list of dotted paths.
+func parsePreviewRedactPaths(raw string) (pathPreviewRedactor, error) {
+	var out pathPreviewRedactor
+	for _, item := range strings.Split(raw, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		for _, segment := range strings.Split(item, ".") {
+			if segment == "" {
+				return pathPreviewRedactor{}, fmt.Errorf("empty segment in %q", item)
+			}
+			if _, err := path.Match(segment, ""); err != nil {
+				return pathPreviewRedactor{}, fmt.Errorf("%q: %w", item, err)
+			}
+		}
+		out.paths = append(out.paths, item)
+	}
+	return out, nil
+}
+
+func (p pathPreviewRedactor) RedactPreview(_ context.Context, doc map[string]any) ([]string, error) {
+	masked := []string{}
+	for _, item := range p.paths {
+		if redactPreviewPath(doc, strings.Split(item, ".")) {
+			masked = append(masked, item)
+		}
+	}
+	return masked, nil
+}
+
+func redactPreviewPath(value any, segments []string) bool {
+	switch typed := value.(type) {
+	case map[string]any:
+		changed := false
+		for key, child := range typed {
+			if ok, _ := path.Match(segments[0], key); !ok {
+				continue
+			}
+			if len(segments) == 1 {
+				typed[key] = previewRedactedValue
+				changed = true
+				continue
+			}
+			if redactPreviewPath(child, segments[1:]) {
+				changed = true
+			}
+		}
+		return changed
+	case []any:
+		changed := false
+		for _, item := range typed {
+			if redactPreviewPath(item, segments) {
+				changed = true
+			}
+		}
+		return changed
+	default:
+		return false
+	}
+}
+
+type evaluationPreviewRequest struct {
+	SampleID string          `json:"sample_id"`
+	Input    json.RawMessage `json:"input"`
+	Output   json.RawMessage `json:"output"`
+	Expected json.RawMessage `json:"expected,omitempty"`
+	Metadata json.RawMessage `json:"metadata,omitempty"`
+}
+
+type evaluationPreview struct {
+	PreviewID     string         `json:"preview_id"`
+	EvaluationID  string         `json:"evaluation_id"`
+	RunID         string         `json:"run_id"`
+	SampleID      string         `json:"sample_id"`
+	Ordinal       int            `json:"ordinal"`
+	SHA256        string         `json:"sha256"`
+	SizeBytes     int64          `json:"size_bytes"`
+	RedactedPaths []string       `json:"redacted_paths"`
+	CreatedAt     time.Time      `json:"created_at"`
+	CreatedBy     string         `json:"created_by"`
+	Content       map[string]any `json:"content,omitempty"`
+}
+
+// buildPreviewDocument assembles the stored preview document. The returned
+// string is the error code for an invalid request.
+func buildPreviewDocument(evaluationID, runID string, req evaluationPreviewRequest) (map[string]any, string) {
+	if strings.TrimSpace(req.SampleID) == "" || len(req.SampleID) > evaluationSampleIDMax {
+		return nil, "sample_id_required"
+	}
+	if len(bytes.TrimSpace(req.Input)) == 0 {
+		return nil, "input_required"
+	}
+	if len(bytes.TrimSpace(req.Output)) == 0 {
+		return nil, "output_required"
+	}
+	doc := map[string]any{
+		"schema":        evaluationPreviewSchemaV1,
+		"evaluation_id": evaluationID,
+		"run_id":        runID,
+		"sample_id":     strings.TrimSpace(req.SampleID),
+	}
+	fields := map[string]json.RawMessage{
+		"input":    req.Input,
+		"output":   req.Output,
+		"expected": req.Expected,
+		"metadata": req.Metadata,
+	}
+	for name, raw := range fields {
+		if len(bytes.TrimSpace(raw)) == 0 {
+			continue
+		}
+		decoder := json.NewDecoder(bytes.NewReader(raw))
+		decoder.UseNumber()
+		var value any
+		if err := decoder.Decode(&value); err != nil {
+			return nil, "invalid_json"
+		}
+		doc[name] = value
+	}
+	return doc, ""
+}
+
+// redactPreview runs the configured redactors over doc and returns the
+// masked paths in redactor order.
+func (api *experimentsAPI) redactPreview(ctx context.Context, doc map[string]any) ([]string, error) {
+	masked := []string{}
+	for _, redactor := range api.previewRedactors {
+		paths, err := redactor.RedactPreview(ctx, doc)
+		if err != nil {
+			return nil, err
+		}
+		masked = append(masked, paths...)
+	}
+	return masked, nil
+}
+
+func evaluationPreviewKey(evaluationID, previewID string) string {
+	return fmt.Sprintf("evaluation-previews/%s/%s.json", evaluationID, previewID)
+}
+
+// evaluationFromPath returns the run_id and evaluation_id path values.
+func (api *experimentsAPI) evaluationFromPath(w http.ResponseWriter, r *http.Request) (string, string, bool) {
+	runID := strings.TrimSpace(r.PathValue("run_id"))
+	if runID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "run_id_required")
+		return "", "", false
+	}
+	evaluationID := strings.TrimSpace(r.PathValue("evaluation_id"))
+	if evaluationID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "evaluation_id_required")
+		return "", "", false
+	}
+	return runID, evaluationID, true
+}
+
+// handleCreateEvaluationPreview stores one preview of an evaluation, up to
+// the evaluation's preview_samples. The document is redacted before it is
+// written to the object store.
+func (api *experimentsAPI) handleCreateEvaluationPreview(w http.ResponseWriter, r *http.Request) {
+	identity, ok := auth.IdentityFromContext(r.Context())
+	if !ok || strings.TrimSpace(identity.Subject) == "" {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	runID, evaluationID, ok := api.evaluationFromPath(w, r)
+	if !ok {
+		return
+	}
+	var req evaluationPreviewRequest
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
+		return
+	}
+	doc, code := buildPreviewDocument(evaluationID, runID, req)
+	if code != "" {
+		api.writeError(w, r, http.StatusBadRequest, code)
+		return
+	}
+	redacted, err := api.redactPreview(r.Context(), doc)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	content, err := json.Marshal(doc)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if len(content) > evaluationPreviewMaxBytes {
+		api.writeError(w, r, http.StatusBadRequest, "preview_too_large")
+		return
+	}
+	sum := sha256.Sum256(content)
+	redactedJSON, err := json.Marshal(redacted)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	now := time.Now().UTC()
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// The evaluation row lock serializes ordinals and the preview cap.
+	var previewSamples int
+	err = tx.QueryRowContext(
+		r.Context(),
+		`SELECT preview_samples FROM experiment_run_evaluations WHERE evaluation_id = $1 AND run_id = $2 FOR UPDATE`,
+		evaluationID,
+		runID,
+	).Scan(&previewSamples)
+	if errors.Is(err, sql.ErrNoRows) {
+		api.writeError(w, r, http.StatusNotFound, "not_found")
+		return
+	}
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if previewSamples <= 0 {
+		previewSamples = api.evalPreviewSamples
+	}
+	var count, lastOrdinal int
+	if err := tx.QueryRowContext(
+		r.Context(),
+		`SELECT COUNT(*), COALESCE(MAX(ordinal), 0) FROM evaluation_preview_samples WHERE evaluation_id = $1`,
+		evaluationID,
+	).Scan(&count, &lastOrdinal); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if count >= previewSamples {
+		api.writeError(w, r, http.StatusConflict, "preview_limit_reached")
+		return
+	}
+
+	preview := evaluationPreview{
+		PreviewID:     uuid.NewString(),
+		EvaluationID:  evaluationID,
+		RunID:         runID,
+		SampleID:      strings.TrimSpace(req.SampleID),
+		Ordinal:       lastOrdinal + 1,
+		SHA256:        hex.EncodeToString(sum[:]),
+		SizeBytes:     int64(len(content)),
+		RedactedPaths: redacted,
+		CreatedAt:     now,
+		CreatedBy:     identity.Subject,
+	}
+	objectKey := evaluationPreviewKey(evaluationID, preview.PreviewID)
+	res, err := tx.ExecContext(
+		r.Context(),
+		`INSERT INTO evaluation_preview_samples (
+			preview_id, evaluation_id, run_id, sample_id, ordinal, object_key, sha256, size_bytes, redacted_paths, created_at, created_by
+		 ) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)
+		 ON CONFLICT (evaluation_id, sample_id) DO NOTHING`,
+		preview.PreviewID,
+		evaluationID,
+		runID,
+		preview.SampleID,
+		preview.Ordinal,
+		objectKey,
+		preview.SHA256,
+		preview.SizeBytes,
+		redactedJSON,
+		now,
+		identity.Subject,
+	)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		api.writeError(w, r, http.StatusConflict, "preview_sample_exists")
+		return
+	}
+	if api.store == nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if _, err := api.store.PutObject(r.Context(), api.storeCfg.BucketArtifacts, objectKey, bytes.NewReader(content), int64(len(content)), minio.PutObjectOptions{
+		ContentType: "application/json",
+	}); err != nil {
+		api.writeError(w, r, http.StatusBadGateway, "object_store_error")
+		return
+	}
+	if err := auditlog.Enqueue(r.Context(), tx, auditlog.Event{
+		OccurredAt:   now,
+		Actor:        identity.Subject,
+		Action:       "evaluation_preview.created",
+		ResourceType: "evaluation_preview",
+		ResourceID:   preview.PreviewID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload: map[string]any{
+			"service":        "experiments",
+			"run_id":         runID,
+			"evaluation_id":  evaluationID,
+			"sample_id":      preview.SampleID,
+			"ordinal":        preview.Ordinal,
+			"sha256":         preview.SHA256,
+			"redacted_paths": redacted,
+		},
+	}); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "audit_failed")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	api.writeJSON(w, http.StatusCreated, preview)
+}
+
+const evaluationPreviewColumns = `preview_id, evaluation_id, run_id, sample_id, ordinal, object_key, sha256, size_bytes, redacted_paths, created_at, created_by`
+
+func scanEvaluationPreview(row interface{ Scan(...any) error }) (evaluationPreview, string, error) {
+	var (
+		preview   evaluationPreview
+		objectKey string
+		redacted  []byte
+	)
+	if err := row.Scan(
+		&preview.PreviewID,
+		&preview.EvaluationID,
+		&preview.RunID,
+		&preview.SampleID,
+		&preview.Ordinal,
+		&objectKey,
+		&preview.SHA256,
+		&preview.SizeBytes,
+		&redacted,
+		&preview.CreatedAt,
+		&preview.CreatedBy,
+	); err != nil {
+		return evaluationPreview{}, "", err
+	}
+	if err := json.Unmarshal(redacted, &preview.RedactedPaths); err != nil {
+		return evaluationPreview{}, "", err
+	}
+	return preview, objectKey, nil
+}
+
+// loadPreviewContent reads a stored preview document and checks it against
+// the recorded hash.
+func (api *experimentsAPI) loadPreviewContent(ctx context.Context, objectKey, sha256Hex string) (map[string]any, error) {
+	if api.store == nil {
+		return nil, errors.New("object store unavailable")
+	}
+	obj, err := api.store.GetObject(ctx, api.storeCfg.BucketArtifacts, objectKey, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer obj.Close()
+	content, err := io.ReadAll(io.LimitReader(obj, evaluationPreviewMaxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(content)
+	if hex.EncodeToString(sum[:]) != sha256Hex {
+		return nil, fmt.Errorf("preview %s: content hash mismatch", objectKey)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	var doc map[string]any
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// handleListEvaluationPreviews pages through an evaluation's previews in
+// ordinal order with their content; next_after_ordinal continues.
+func (api *experimentsAPI) handleListEvaluationPreviews(w http.ResponseWriter, r *http.Request) {
+	if identity, ok := auth.IdentityFromContext(r.Context()); ok && auth.AuditorOnly(identity.Roles) {
+		api.writeError(w, r, http.StatusForbidden, "auditor_read_only")
+		return
+	}
+	runID, evaluationID, ok := api.evaluationFromPath(w, r)
+	if !ok {
+		return
+	}
+	afterOrdinal := 0
+	if raw := strings.TrimSpace(r.URL.Query().Get("after_ordinal")); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 0 {
+			api.writeError(w, r, http.StatusBadRequest, "invalid_after_ordinal")
+			return
+		}
+		afterOrdinal = parsed
+	}
+	if raw := r.URL.Query().Get("cursor"); raw != "" {
+		var pos evaluationPreviewCursor
+		if httpapi.DecodeCursor(raw, &pos) != nil || pos.AfterOrdinal < 0 {
+			api.writeError(w, r, http.StatusBadRequest, "invalid_cursor")
+			return
+		}
+		afterOrdinal = pos.AfterOrdinal
+	}
+	limit := httpapi.Limit(r, 20, 50)
+
+	var one int
+	err := api.db.QueryRowContext(
+		r.Context(),
+		`SELECT 1 FROM experiment_run_evaluations WHERE evaluation_id = $1 AND run_id = $2`,
+		evaluationID,
+		runID,
+	).Scan(&one)
+	if errors.Is(err, sql.ErrNoRows) {
+		api.writeError(w, r, http.StatusNotFound, "not_found")
+		return
+	}
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	rows, err := api.db.QueryContext(
+		r.Context(),
+		`SELECT `+evaluationPreviewColumns+`
+		 FROM evaluation_preview_samples
+		 WHERE evaluation_id = $1 AND ordinal > $2
+		 ORDER BY ordinal ASC
+		 LIMIT $3`,
+		evaluationID,
+		afterOrdinal,
+		limit,
+	)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer rows.Close()
+	type storedPreview struct {
+		preview   evaluationPreview
+		objectKey string
+	}
+	stored := []storedPreview{}
+	for rows.Next() {
+		preview, objectKey, err := scanEvaluationPreview(rows)
+		if err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+		stored = append(stored, storedPreview{preview: preview, objectKey: objectKey})
+	}
+	if err := rows.Err(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	previews := make([]evaluationPreview, 0, len(stored))
+	for _, item := range stored {
+		content, err := api.loadPreviewContent(r.Context(), item.objectKey, item.preview.SHA256)
+		if err != nil {
+			api.writeError(w, r, http.StatusBadGateway, "object_store_error")
+			return
+		}
+		item.preview.Content = content
+		previews = append(previews, item.preview)
+	}
+	resp := map[string]any{
+		"evaluation_id": evaluationID,
+		"previews":      previews,
+	}
+	if len(previews) == limit {
+		resp["next_after_ordinal"] = previews[len(previews)-1].Ordinal
+		resp["next_cursor"] = httpapi.EncodeCursor(evaluationPreviewCursor{AfterOrdinal: previews[len(previews)-1].Ordinal})
+	}
+	api.writeJSON(w, http.StatusOK, resp)
+}
+
+// evaluationPreviewCursor is the page position behind the cursor of the
+// evaluation previews list.
+type evaluationPreviewCursor struct {
+	AfterOrdinal int `json:"after_ordinal"`
+}
+
+func (api *experimentsAPI) handleGetEvaluationPreview(w http.ResponseWriter, r *http.Request) {
+	if identity, ok := auth.IdentityFromContext(r.Context()); ok && auth.AuditorOnly(identity.Roles) {
+		api.writeError(w, r, http.StatusForbidden, "auditor_read_only")
+		return
+	}
+	runID, evaluationID, ok := api.evaluationFromPath(w, r)
+	if !ok {
+		return
+	}
+	previewID := strings.TrimSpace(r.PathValue("preview_id"))
+	if previewID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "preview_id_required")
+		return
+	}
+	preview, objectKey, err := scanEvaluationPreview(api.db.QueryRowContext(
+		r.Context(),
+		`SELECT `+evaluationPreviewColumns+`
+		 FROM evaluation_preview_samples
+		 WHERE preview_id = $1 AND evaluation_id = $2 AND run_id = $3`,
+		previewID,
+		evaluationID,
+		runID,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		api.writeError(w, r, http.StatusNotFound, "not_found")
+		return
+	}
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	preview.Content, err = api.loadPreviewContent(r.Context(), objectKey, preview.SHA256)
+	if err != nil {
+		api.writeError(w, r, http.StatusBadGateway, "object_store_error")
+		return
+	}
+	api.writeJSON(w, http.StatusOK, preview)
+}
@@ -0,0 +1,64 @@
+package experiments
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestPathPreviewRedactor(t *testing.T) {
+	redactor, err := parsePreviewRedactPaths(" input.*.email , metadata.token,output.missing")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	doc, code := buildPreviewDocument("eval-1", "run-1", evaluationPreviewRequest{
+		SampleID: "s1",
+		Input:    json.RawMessage(`{"users":[{"email":"a@example.com","name":"a"}]}`),
+		Output:   json.RawMessage(`"ok"`),
+		Metadata: json.RawMessage(`{"token":"secret"}`),
+	})
+	if code != "" {
+		t.Fatalf("code=%s", code)
+	}
+	masked, err := redactor.RedactPreview(context.Background(), doc)
+	if err != nil {
+		t.Fatalf("redact: %v", err)
+	}
+	if !reflect.DeepEqual(masked, []string{"input.*.email", "metadata.token"}) {
+		t.Fatalf("masked=%v", masked)
+	}
+	user := doc["input"].(map[string]any)["users"].([]any)[0].(map[string]any)
+	if user["email"] != previewRedactedValue || user["name"] != "a" {
+		t.Fatalf("user=%v", user)
+	}
+
+	for _, bad := range []string{"input..email", "input.[", "."} {
+		if _, err := parsePreviewRedactPaths(bad); err == nil {
+			t.Fatalf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestBuildPreviewDocument(t *testing.T) {
+	valid := evaluationPreviewRequest{SampleID: "s1", Input: json.RawMessage(`1`), Output: json.RawMessage(`2`)}
+	doc, code := buildPreviewDocument("eval-1", "run-1", valid)
+	if code != "" || doc["schema"] != evaluationPreviewSchemaV1 || doc["input"] != json.Number("1") {
+		t.Fatalf("doc=%v code=%s", doc, code)
+	}
+	if _, ok := doc["expected"]; ok {
+		t.Fatalf("expected to be omitted, got %v", doc)
+	}
+	cases := map[string]func(*evaluationPreviewRequest){
+		"sample_id_required": func(req *evaluationPreviewRequest) { req.SampleID = " " },
+		"input_required":     func(req *evaluationPreviewRequest) { req.Input = nil },
+		"output_required":    func(req *evaluationPreviewRequest) { req.Output = json.RawMessage(" ") },
+	}
+	for want, mutate := range cases {
+		req := valid
+		mutate(&req)
+		if _, code := buildPreviewDocument("eval-1", "run-1", req); code != want {
+			t.Fatalf("code=%s want %s", code, want)
+		}
+	}
+}
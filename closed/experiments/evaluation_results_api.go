@@ -0,0 +1,814 @@
+package experiments
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	"github.com/animus-labs/animus-go/closed/internal/platform/lineageevent"
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+	"github.com/animus-labs/animus-go/closed/internal/repo/postgres"
+	"github.com/google/uuid"
+)
+
+const (
+	// evaluationResultSchemaV1 is the only accepted evaluation result schema.
+	evaluationResultSchemaV1 = "animus.eval.result.v1"
+
+	evaluationGoalMaximize = "maximize"
+	evaluationGoalMinimize = "minimize"
+
+	evaluationSuiteMetricsMax = 100
+	evaluationSamplesMax      = 1000
+	evaluationSampleIDMax     = 256
+)
+
+type evaluationMetric struct {
+	Name string `json:"name"`
+	Goal string `json:"goal"`
+}
+
+type createEvaluationSuiteRequest struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description,omitempty"`
+	Metrics     []evaluationMetric `json:"metrics"`
+}
+
+type evaluationSuite struct {
+	SuiteID     string             `json:"suite_id"`
+	ProjectID   string             `json:"project_id"`
+	Name        string             `json:"name"`
+	Description string             `json:"description,omitempty"`
+	Metrics     []evaluationMetric `json:"metrics"`
+	CreatedAt   time.Time          `json:"created_at"`
+	CreatedBy   string             `json:"created_by"`
+}
+
+type evaluationSample struct {
+	SampleID string             `json:"sample_id"`
+	Metrics  map[string]float64 `json:"metrics"`
+	Passed   *bool              `json:"passed,omitempty"`
+}
+
+type evaluationResultRequest struct {
+	Schema         string             `json:"schema"`
+	RunID          string             `json:"run_id"`
+	ModelVersionID string             `json:"model_version_id,omitempty"`
+	EvaluationID   string             `json:"evaluation_id,omitempty"`
+	Metrics        map[string]float64 `json:"metrics"`
+	Samples        []evaluationSample `json:"samples,omitempty"`
+}
+
+type evaluationSamplesRequest struct {
+	Schema  string             `json:"schema"`
+	Samples []evaluationSample `json:"samples"`
+}
+
+type evaluationResult struct {
+	ResultID        string             `json:"result_id"`
+	SuiteID         string             `json:"suite_id"`
+	ProjectID       string             `json:"project_id"`
+	Schema          string             `json:"schema"`
+	RunID           string             `json:"run_id"`
+	ModelVersionID  string             `json:"model_version_id,omitempty"`
+	EvaluationID    string             `json:"evaluation_id,omitempty"`
+	Metrics         map[string]float64 `json:"metrics"`
+	SampleCount     int                `json:"sample_count"`
+	CreatedAt       time.Time          `json:"created_at"`
+	CreatedBy       string             `json:"created_by"`
+	IntegritySHA256 string             `json:"integrity_sha256"`
+}
+
+type evaluationComparisonEntry struct {
+	ModelVersionID string             `json:"model_version_id"`
+	ResultID       string             `json:"result_id,omitempty"`
+	RunID          string             `json:"run_id,omitempty"`
+	CreatedAt      *time.Time         `json:"created_at,omitempty"`
+	Metrics        map[string]float64 `json:"metrics"`
+	// Deltas are the differences to the baseline for metrics both results
+	// have; Improved and Regressed name them according to the metric goal.
+	Deltas    map[string]float64 `json:"deltas,omitempty"`
+	Improved  []string           `json:"improved,omitempty"`
+	Regressed []string           `json:"regressed,omitempty"`
+}
+
+type evaluationComparison struct {
+	SuiteID                string                      `json:"suite_id"`
+	Metrics                []evaluationMetric          `json:"metrics"`
+	BaselineModelVersionID string                      `json:"baseline_model_version_id"`
+	ModelVersions          []evaluationComparisonEntry `json:"model_versions"`
+}
+
+var (
+	errEvaluationRunNotFound          = errors.New("evaluation run not found")
+	errEvaluationModelVersionNotFound = errors.New("evaluation model version not found")
+	errEvaluationNotFound             = errors.New("evaluation not found")
+)
+
+// normalizeEvaluationMetrics trims metric names and defaults goals to
+// maximize. Names must be unique and at least one metric is required.
+func normalizeEvaluationMetrics(metrics []evaluationMetric) ([]evaluationMetric, bool) {
+	if len(metrics) == 0 || len(metrics) > evaluationSuiteMetricsMax {
+		return nil, false
+	}
+	out := make([]evaluationMetric, 0, len(metrics))
+	seen := make(map[string]struct{}, len(metrics))
+	for _, metric := range metrics {
+		name := strings.TrimSpace(metric.Name)
+		goal := strings.ToLower(strings.TrimSpace(metric.Goal))
+		if goal == "" {
+			goal = evaluationGoalMaximize
+		}
+		if name == "" || (goal != evaluationGoalMaximize && goal != evaluationGoalMinimize) {
+			return nil, false
+		}
+		if _, dup := seen[name]; dup {
+			return nil, false
+		}
+		seen[name] = struct{}{}
+		out = append(out, evaluationMetric{Name: name, Goal: goal})
+	}
+	return out, true
+}
+
+// validateEvaluationSamples checks a batch of samples: at most
+// evaluationSamplesMax, each with a unique non-empty sample_id.
+func validateEvaluationSamples(samples []evaluationSample) bool {
+	if len(samples) > evaluationSamplesMax {
+		return false
+	}
+	seen := make(map[string]struct{}, len(samples))
+	for _, sample := range samples {
+		id := strings.TrimSpace(sample.SampleID)
+		if id == "" || len(id) > evaluationSampleIDMax || id != sample.SampleID {
+			return false
+		}
+		if _, dup := seen[id]; dup {
+			return false
+		}
+		seen[id] = struct{}{}
+	}
+	return true
+}
+
+// validateEvaluationResult returns the error code for a result that does
+// not follow animus.eval.result.v1 or lacks a metric of the suite.
+func validateEvaluationResult(suite evaluationSuite, req evaluationResultRequest) string {
+	if req.Schema != evaluationResultSchemaV1 {
+		return "evaluation_schema_unsupported"
+	}
+	if strings.TrimSpace(req.RunID) == "" {
+		return "run_id_required"
+	}
+	for _, metric := range suite.Metrics {
+		if _, ok := req.Metrics[metric.Name]; !ok {
+			return "invalid_metrics"
+		}
+	}
+	if !validateEvaluationSamples(req.Samples) {
+		return "evaluation_samples_invalid"
+	}
+	return ""
+}
+
+// compareEvaluationResults fills the deltas of every entry after the first
+// against the first, the baseline. Entries without a result are skipped.
+func compareEvaluationResults(metrics []evaluationMetric, entries []evaluationComparisonEntry) {
+	if len(entries) == 0 || entries[0].ResultID == "" {
+		return
+	}
+	baseline := entries[0].Metrics
+	for i := 1; i < len(entries); i++ {
+		entry := &entries[i]
+		if entry.ResultID == "" {
+			continue
+		}
+		entry.Deltas = map[string]float64{}
+		for _, metric := range metrics {
+			base, okBase := baseline[metric.Name]
+			value, ok := entry.Metrics[metric.Name]
+			if !okBase || !ok {
+				continue
+			}
+			delta := value - base
+			entry.Deltas[metric.Name] = delta
+			if metric.Goal == evaluationGoalMinimize {
+				delta = -delta
+			}
+			switch {
+			case delta > 0:
+				entry.Improved = append(entry.Improved, metric.Name)
+			case delta < 0:
+				entry.Regressed = append(entry.Regressed, metric.Name)
+			}
+		}
+	}
+}
+
+func evaluationSuiteFromRecord(record postgres.EvaluationSuiteRecord) (evaluationSuite, error) {
+	suite := evaluationSuite{
+		SuiteID:     record.SuiteID,
+		ProjectID:   record.ProjectID,
+		Name:        record.Name,
+		Description: record.Description,
+		CreatedAt:   record.CreatedAt,
+		CreatedBy:   record.CreatedBy,
+	}
+	if err := json.Unmarshal(record.Metrics, &suite.Metrics); err != nil {
+		return evaluationSuite{}, err
+	}
+	return suite, nil
+}
+
+func evaluationResultFromRecord(record postgres.EvaluationResultRecord) (evaluationResult, error) {
+	result := evaluationResult{
+		ResultID:        record.ResultID,
+		SuiteID:         record.SuiteID,
+		ProjectID:       record.ProjectID,
+		Schema:          record.SchemaVersion,
+		RunID:           record.RunID,
+		ModelVersionID:  record.ModelVersionID,
+		EvaluationID:    record.EvaluationID,
+		SampleCount:     record.SampleCount,
+		CreatedAt:       record.CreatedAt,
+		CreatedBy:       record.CreatedBy,
+		IntegritySHA256: record.IntegritySHA256,
+	}
+	if err := json.Unmarshal(record.Metrics, &result.Metrics); err != nil {
+		return evaluationResult{}, err
+	}
+	return result, nil
+}
+
+// checkEvaluationLinks verifies that the run and model version belong to
+// projectID and that the evaluation, when given, evaluated the run.
+func checkEvaluationLinks(ctx context.Context, db postgres.DB, projectID string, req evaluationResultRequest) error {
+	run, modelVersion, evaluation, err := postgres.NewEvaluationStore(db).LinksExist(ctx, projectID, req.RunID, req.ModelVersionID, req.EvaluationID)
+	switch {
+	case err != nil:
+		return err
+	case !run:
+		return errEvaluationRunNotFound
+	case !modelVersion:
+		return errEvaluationModelVersionNotFound
+	case !evaluation:
+		return errEvaluationNotFound
+	}
+	return nil
+}
+
+// insertEvaluationSamples stores samples of resultID and bumps its sample
+// count. Samples whose sample_id already exists are skipped; the number of
+// new samples is returned.
+func insertEvaluationSamples(ctx context.Context, tx *sql.Tx, resultID string, samples []evaluationSample, now time.Time) (int, error) {
+	records := make([]postgres.EvaluationSampleRecord, 0, len(samples))
+	for _, sample := range samples {
+		metrics := sample.Metrics
+		if metrics == nil {
+			metrics = map[string]float64{}
+		}
+		metricsJSON, err := json.Marshal(metrics)
+		if err != nil {
+			return 0, err
+		}
+		records = append(records, postgres.EvaluationSampleRecord{SampleID: sample.SampleID, Metrics: metricsJSON, Passed: sample.Passed})
+	}
+	return postgres.NewEvaluationStore(tx).AddSamples(ctx, resultID, records, now)
+}
+
+func (api *experimentsAPI) handleCreateEvaluationSuite(w http.ResponseWriter, r *http.Request) {
+	identity, projectID, ok := api.scopedProject(w, r)
+	if !ok {
+		return
+	}
+	var req createEvaluationSuiteRequest
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		api.writeError(w, r, http.StatusBadRequest, "name_required")
+		return
+	}
+	metrics, ok := normalizeEvaluationMetrics(req.Metrics)
+	if !ok {
+		api.writeError(w, r, http.StatusBadRequest, "invalid_metrics")
+		return
+	}
+	metricsJSON, err := json.Marshal(metrics)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	suite := evaluationSuite{
+		SuiteID:     uuid.NewString(),
+		ProjectID:   projectID,
+		Name:        name,
+		Description: strings.TrimSpace(req.Description),
+		Metrics:     metrics,
+		CreatedAt:   time.Now().UTC(),
+		CreatedBy:   identity.Subject,
+	}
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	err = postgres.NewEvaluationStore(tx).CreateSuite(r.Context(), postgres.EvaluationSuiteRecord{
+		SuiteID:     suite.SuiteID,
+		ProjectID:   suite.ProjectID,
+		Name:        suite.Name,
+		Description: suite.Description,
+		Metrics:     metricsJSON,
+		CreatedAt:   suite.CreatedAt,
+		CreatedBy:   suite.CreatedBy,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, repo.ErrConflict):
+			api.writeError(w, r, http.StatusConflict, "evaluation_suite_exists")
+		case errors.Is(err, repo.ErrNotFound):
+			api.writeError(w, r, http.StatusNotFound, "project_not_found")
+		default:
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		}
+		return
+	}
+	if err := auditlog.Enqueue(r.Context(), tx, auditlog.Event{
+		OccurredAt:   suite.CreatedAt,
+		Actor:        identity.Subject,
+		Action:       "evaluation_suite.created",
+		ResourceType: "evaluation_suite",
+		ResourceID:   suite.SuiteID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload: map[string]any{
+			"service":    "experiments",
+			"project_id": projectID,
+			"name":       suite.Name,
+			"metrics":    metrics,
+		},
+	}); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "audit_failed")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	api.writeJSON(w, http.StatusCreated, suite)
+}
+
+func (api *experimentsAPI) handleListEvaluationSuites(w http.ResponseWriter, r *http.Request) {
+	_, projectID, ok := api.scopedProject(w, r)
+	if !ok {
+		return
+	}
+	records, err := postgres.NewEvaluationStore(api.db).ListSuites(r.Context(), projectID, httpapi.Limit(r, 100, 500))
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	suites := make([]evaluationSuite, 0, len(records))
+	for _, record := range records {
+		suite, err := evaluationSuiteFromRecord(record)
+		if err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+		suites = append(suites, suite)
+	}
+	api.writeJSON(w, http.StatusOK, map[string]any{"suites": suites})
+}
+
+// evaluationSuiteFromPath loads the suite named by the suite_id path value
+// within the request's project.
+func (api *experimentsAPI) evaluationSuiteFromPath(w http.ResponseWriter, r *http.Request) (auth.Identity, evaluationSuite, bool) {
+	identity, projectID, ok := api.scopedProject(w, r)
+	if !ok {
+		return auth.Identity{}, evaluationSuite{}, false
+	}
+	suiteID := strings.TrimSpace(r.PathValue("suite_id"))
+	if suiteID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "suite_id_required")
+		return auth.Identity{}, evaluationSuite{}, false
+	}
+	record, err := postgres.NewEvaluationStore(api.db).GetSuite(r.Context(), projectID, suiteID)
+	if errors.Is(err, repo.ErrNotFound) {
+		api.writeError(w, r, http.StatusNotFound, "not_found")
+		return auth.Identity{}, evaluationSuite{}, false
+	}
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return auth.Identity{}, evaluationSuite{}, false
+	}
+	suite, err := evaluationSuiteFromRecord(record)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return auth.Identity{}, evaluationSuite{}, false
+	}
+	return identity, suite, true
+}
+
+func (api *experimentsAPI) handleGetEvaluationSuite(w http.ResponseWriter, r *http.Request) {
+	_, suite, ok := api.evaluationSuiteFromPath(w, r)
+	if !ok {
+		return
+	}
+	api.writeJSON(w, http.StatusOK, suite)
+}
+
+func (api *experimentsAPI) handleCreateEvaluationResult(w http.ResponseWriter, r *http.Request) {
+	identity, suite, ok := api.evaluationSuiteFromPath(w, r)
+	if !ok {
+		return
+	}
+	var req evaluationResultRequest
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
+		return
+	}
+	req.RunID = strings.TrimSpace(req.RunID)
+	req.ModelVersionID = strings.TrimSpace(req.ModelVersionID)
+	req.EvaluationID = strings.TrimSpace(req.EvaluationID)
+	if code := validateEvaluationResult(suite, req); code != "" {
+		api.writeError(w, r, http.StatusBadRequest, code)
+		return
+	}
+	metricsJSON, err := json.Marshal(req.Metrics)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	now := time.Now().UTC()
+	result := evaluationResult{
+		ResultID:       uuid.NewString(),
+		SuiteID:        suite.SuiteID,
+		ProjectID:      suite.ProjectID,
+		Schema:         req.Schema,
+		RunID:          req.RunID,
+		ModelVersionID: req.ModelVersionID,
+		EvaluationID:   req.EvaluationID,
+		Metrics:        req.Metrics,
+		CreatedAt:      now,
+		CreatedBy:      identity.Subject,
+	}
+	result.IntegritySHA256, err = integritySHA256(result)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := checkEvaluationLinks(r.Context(), tx, suite.ProjectID, req); err != nil {
+		switch {
+		case errors.Is(err, errEvaluationRunNotFound):
+			api.writeError(w, r, http.StatusNotFound, "run_not_found")
+		case errors.Is(err, errEvaluationModelVersionNotFound):
+			api.writeError(w, r, http.StatusNotFound, "model_version_not_found")
+		case errors.Is(err, errEvaluationNotFound):
+			api.writeError(w, r, http.StatusNotFound, "evaluation_not_found")
+		default:
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		}
+		return
+	}
+	err = postgres.NewEvaluationStore(tx).CreateResult(r.Context(), postgres.EvaluationResultRecord{
+		ResultID:        result.ResultID,
+		SuiteID:         result.SuiteID,
+		ProjectID:       result.ProjectID,
+		SchemaVersion:   result.Schema,
+		RunID:           result.RunID,
+		ModelVersionID:  result.ModelVersionID,
+		EvaluationID:    result.EvaluationID,
+		Metrics:         metricsJSON,
+		CreatedAt:       result.CreatedAt,
+		CreatedBy:       result.CreatedBy,
+		IntegritySHA256: result.IntegritySHA256,
+	})
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	result.SampleCount, err = insertEvaluationSamples(r.Context(), tx, result.ResultID, req.Samples, now)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	requestID := r.Header.Get("X-Request-Id")
+	if err := lineageevent.Enqueue(r.Context(), tx, lineageevent.Event{
+		OccurredAt:  now,
+		Actor:       identity.Subject,
+		RequestID:   requestID,
+		SubjectType: "experiment_run",
+		SubjectID:   result.RunID,
+		Predicate:   "produced",
+		ObjectType:  "evaluation_result",
+		ObjectID:    result.ResultID,
+		Metadata: map[string]any{
+			"project_id": result.ProjectID,
+			"suite_id":   result.SuiteID,
+		},
+	}); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if result.ModelVersionID != "" {
+		if err := lineageevent.Enqueue(r.Context(), tx, lineageevent.Event{
+			OccurredAt:  now,
+			Actor:       identity.Subject,
+			RequestID:   requestID,
+			SubjectType: "evaluation_result",
+			SubjectID:   result.ResultID,
+			Predicate:   "evaluates",
+			ObjectType:  "model_version",
+			ObjectID:    result.ModelVersionID,
+			Metadata: map[string]any{
+				"project_id": result.ProjectID,
+				"suite_id":   result.SuiteID,
+			},
+		}); err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+	}
+	if err := auditlog.Enqueue(r.Context(), tx, auditlog.Event{
+		OccurredAt:   now,
+		Actor:        identity.Subject,
+		Action:       "evaluation_result.created",
+		ResourceType: "evaluation_result",
+		ResourceID:   result.ResultID,
+		RequestID:    requestID,
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload: map[string]any{
+			"service":          "experiments",
+			"project_id":       result.ProjectID,
+			"suite_id":         result.SuiteID,
+			"run_id":           result.RunID,
+			"model_version_id": result.ModelVersionID,
+			"evaluation_id":    result.EvaluationID,
+			"metrics":          result.Metrics,
+			"sample_count":     result.SampleCount,
+			"integrity_sha256": result.IntegritySHA256,
+		},
+	}); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "audit_failed")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	api.writeJSON(w, http.StatusCreated, result)
+}
+
+func (api *experimentsAPI) handleListEvaluationResults(w http.ResponseWriter, r *http.Request) {
+	_, suite, ok := api.evaluationSuiteFromPath(w, r)
+	if !ok {
+		return
+	}
+	query := r.URL.Query()
+	records, err := postgres.NewEvaluationStore(api.db).ListResults(r.Context(), postgres.EvaluationResultFilter{
+		SuiteID:        suite.SuiteID,
+		ModelVersionID: query.Get("model_version_id"),
+		RunID:          query.Get("run_id"),
+		Limit:          httpapi.Limit(r, 50, 500),
+	})
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	results := make([]evaluationResult, 0, len(records))
+	for _, record := range records {
+		result, err := evaluationResultFromRecord(record)
+		if err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+		results = append(results, result)
+	}
+	api.writeJSON(w, http.StatusOK, map[string]any{"results": results})
+}
+
+// handleCompareEvaluationResults compares the latest result of each
+// model_version_id in the suite against the first one given.
+func (api *experimentsAPI) handleCompareEvaluationResults(w http.ResponseWriter, r *http.Request) {
+	_, suite, ok := api.evaluationSuiteFromPath(w, r)
+	if !ok {
+		return
+	}
+	versionIDs := []string{}
+	seen := map[string]struct{}{}
+	for _, raw := range r.URL.Query()["model_version_id"] {
+		id := strings.TrimSpace(raw)
+		if id == "" {
+			continue
+		}
+		if _, dup := seen[id]; dup {
+			continue
+		}
+		seen[id] = struct{}{}
+		versionIDs = append(versionIDs, id)
+	}
+	if len(versionIDs) < 2 || len(versionIDs) > 20 {
+		api.writeError(w, r, http.StatusBadRequest, "model_version_ids_required")
+		return
+	}
+
+	entries := make([]evaluationComparisonEntry, 0, len(versionIDs))
+	for _, versionID := range versionIDs {
+		entry := evaluationComparisonEntry{ModelVersionID: versionID, Metrics: map[string]float64{}}
+		record, err := postgres.NewEvaluationStore(api.db).LatestResult(r.Context(), suite.SuiteID, versionID)
+		var result evaluationResult
+		if err == nil {
+			result, err = evaluationResultFromRecord(record)
+		}
+		switch {
+		case errors.Is(err, repo.ErrNotFound):
+		case err != nil:
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		default:
+			createdAt := result.CreatedAt
+			entry.ResultID = result.ResultID
+			entry.RunID = result.RunID
+			entry.CreatedAt = &createdAt
+			entry.Metrics = result.Metrics
+		}
+		entries = append(entries, entry)
+	}
+	compareEvaluationResults(suite.Metrics, entries)
+	api.writeJSON(w, http.StatusOK, evaluationComparison{
+		SuiteID:                suite.SuiteID,
+		Metrics:                suite.Metrics,
+		BaselineModelVersionID: versionIDs[0],
+		ModelVersions:          entries,
+	})
+}
+
+// evaluationResultFromPath loads the result named by the result_id path
+// value within the request's project.
+func (api *experimentsAPI) evaluationResultFromPath(w http.ResponseWriter, r *http.Request) (auth.Identity, evaluationResult, bool) {
+	identity, projectID, ok := api.scopedProject(w, r)
+	if !ok {
+		return auth.Identity{}, evaluationResult{}, false
+	}
+	resultID := strings.TrimSpace(r.PathValue("result_id"))
+	if resultID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "result_id_required")
+		return auth.Identity{}, evaluationResult{}, false
+	}
+	record, err := postgres.NewEvaluationStore(api.db).GetResult(r.Context(), projectID, resultID)
+	if errors.Is(err, repo.ErrNotFound) {
+		api.writeError(w, r, http.StatusNotFound, "not_found")
+		return auth.Identity{}, evaluationResult{}, false
+	}
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return auth.Identity{}, evaluationResult{}, false
+	}
+	result, err := evaluationResultFromRecord(record)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return auth.Identity{}, evaluationResult{}, false
+	}
+	return identity, result, true
+}
+
+func (api *experimentsAPI) handleGetEvaluationResult(w http.ResponseWriter, r *http.Request) {
+	_, result, ok := api.evaluationResultFromPath(w, r)
+	if !ok {
+		return
+	}
+	api.writeJSON(w, http.StatusOK, result)
+}
+
+func (api *experimentsAPI) handleAddEvaluationSamples(w http.ResponseWriter, r *http.Request) {
+	identity, result, ok := api.evaluationResultFromPath(w, r)
+	if !ok {
+		return
+	}
+	var req evaluationSamplesRequest
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
+		return
+	}
+	if req.Schema != evaluationResultSchemaV1 {
+		api.writeError(w, r, http.StatusBadRequest, "evaluation_schema_unsupported")
+		return
+	}
+	if len(req.Samples) == 0 || !validateEvaluationSamples(req.Samples) {
+		api.writeError(w, r, http.StatusBadRequest, "evaluation_samples_invalid")
+		return
+	}
+
+	now := time.Now().UTC()
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	inserted, err := insertEvaluationSamples(r.Context(), tx, result.ResultID, req.Samples, now)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if err := auditlog.Enqueue(r.Context(), tx, auditlog.Event{
+		OccurredAt:   now,
+		Actor:        identity.Subject,
+		Action:       "evaluation_result.samples_added",
+		ResourceType: "evaluation_result",
+		ResourceID:   result.ResultID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload: map[string]any{
+			"service":    "experiments",
+			"project_id": result.ProjectID,
+			"suite_id":   result.SuiteID,
+			"submitted":  len(req.Samples),
+			"inserted":   inserted,
+		},
+	}); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "audit_failed")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	api.writeJSON(w, http.StatusOK, map[string]any{
+		"result_id":  result.ResultID,
+		"inserted":   inserted,
+		"duplicates": len(req.Samples) - inserted,
+	})
+}
+
+// evaluationSampleCursor is the page position behind the cursor of the
+// evaluation samples list.
+type evaluationSampleCursor struct {
+	AfterSampleID string `json:"after_sample_id"`
+}
+
+// handleListEvaluationSamples pages through a result's samples in
+// sample_id order; next_cursor (or next_after_sample_id) continues the
+// listing.
+func (api *experimentsAPI) handleListEvaluationSamples(w http.ResponseWriter, r *http.Request) {
+	_, result, ok := api.evaluationResultFromPath(w, r)
+	if !ok {
+		return
+	}
+	limit := httpapi.Limit(r, 100, 1000)
+	afterSampleID := strings.TrimSpace(r.URL.Query().Get("after_sample_id"))
+	if raw := r.URL.Query().Get("cursor"); raw != "" {
+		var pos evaluationSampleCursor
+		if httpapi.DecodeCursor(raw, &pos) != nil || pos.AfterSampleID == "" {
+			api.writeError(w, r, http.StatusBadRequest, "invalid_cursor")
+			return
+		}
+		afterSampleID = pos.AfterSampleID
+	}
+	records, err := postgres.NewEvaluationStore(api.db).ListSamples(r.Context(), result.ResultID, afterSampleID, limit)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	samples := make([]evaluationSample, 0, len(records))
+	for _, record := range records {
+		sample := evaluationSample{SampleID: record.SampleID, Passed: record.Passed}
+		if err := json.Unmarshal(record.Metrics, &sample.Metrics); err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+		samples = append(samples, sample)
+	}
+	resp := map[string]any{"result_id": result.ResultID, "samples": samples}
+	if len(samples) == limit {
+		resp["next_after_sample_id"] = samples[len(samples)-1].SampleID
+		resp["next_cursor"] = httpapi.EncodeCursor(evaluationSampleCursor{AfterSampleID: samples[len(samples)-1].SampleID})
+	}
+	api.writeJSON(w, http.StatusOK, resp)
+}
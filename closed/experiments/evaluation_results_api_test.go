@@ -0,0 +1,74 @@
+package experiments
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeEvaluationMetrics(t *testing.T) {
+	metrics, ok := normalizeEvaluationMetrics([]evaluationMetric{{Name: " accuracy "}, {Name: "loss", Goal: "Minimize"}})
+	want := []evaluationMetric{{Name: "accuracy", Goal: evaluationGoalMaximize}, {Name: "loss", Goal: evaluationGoalMinimize}}
+	if !ok || !reflect.DeepEqual(metrics, want) {
+		t.Fatalf("metrics=%+v ok=%v", metrics, ok)
+	}
+	for _, bad := range [][]evaluationMetric{
+		nil,
+		{{Name: ""}},
+		{{Name: "acc", Goal: "up"}},
+		{{Name: "acc"}, {Name: "acc", Goal: "minimize"}},
+	} {
+		if _, ok := normalizeEvaluationMetrics(bad); ok {
+			t.Fatalf("expected %+v to be rejected", bad)
+		}
+	}
+}
+
+func TestValidateEvaluationResult(t *testing.T) {
+	suite := evaluationSuite{Metrics: []evaluationMetric{{Name: "accuracy", Goal: evaluationGoalMaximize}}}
+	valid := evaluationResultRequest{
+		Schema:  evaluationResultSchemaV1,
+		RunID:   "run-1",
+		Metrics: map[string]float64{"accuracy": 0.9, "f1": 0.8},
+		Samples: []evaluationSample{{SampleID: "s1"}, {SampleID: "s2"}},
+	}
+	if code := validateEvaluationResult(suite, valid); code != "" {
+		t.Fatalf("code=%s", code)
+	}
+
+	cases := map[string]func(*evaluationResultRequest){
+		"evaluation_schema_unsupported": func(req *evaluationResultRequest) { req.Schema = "animus.eval.result.v2" },
+		"run_id_required":               func(req *evaluationResultRequest) { req.RunID = "" },
+		"invalid_metrics":               func(req *evaluationResultRequest) { req.Metrics = map[string]float64{"f1": 1} },
+		"evaluation_samples_invalid": func(req *evaluationResultRequest) {
+			req.Samples = []evaluationSample{{SampleID: "s1"}, {SampleID: "s1"}}
+		},
+	}
+	for want, mutate := range cases {
+		req := valid
+		mutate(&req)
+		if code := validateEvaluationResult(suite, req); code != want {
+			t.Fatalf("code=%s want %s", code, want)
+		}
+	}
+	if validateEvaluationSamples([]evaluationSample{{SampleID: " s1"}}) || validateEvaluationSamples([]evaluationSample{{SampleID: strings.Repeat("x", evaluationSampleIDMax+1)}}) {
+		t.Fatalf("expected malformed sample ids to be rejected")
+	}
+}
+
+func TestCompareEvaluationResults(t *testing.T) {
+	metrics := []evaluationMetric{{Name: "accuracy", Goal: evaluationGoalMaximize}, {Name: "loss", Goal: evaluationGoalMinimize}}
+	entries := []evaluationComparisonEntry{
+		{ModelVersionID: "mv-1", ResultID: "r1", Metrics: map[string]float64{"accuracy": 0.8, "loss": 0.5}},
+		{ModelVersionID: "mv-2", ResultID: "r2", Metrics: map[string]float64{"accuracy": 0.9, "loss": 0.75}},
+		{ModelVersionID: "mv-3", Metrics: map[string]float64{}},
+	}
+	compareEvaluationResults(metrics, entries)
+	got := entries[1]
+	if got.Deltas["loss"] != 0.25 || !reflect.DeepEqual(got.Improved, []string{"accuracy"}) || !reflect.DeepEqual(got.Regressed, []string{"loss"}) {
+		t.Fatalf("entry=%+v", got)
+	}
+	if entries[2].Deltas != nil {
+		t.Fatalf("expected no deltas without a result, got %+v", entries[2])
+	}
+}
@@ -1,4 +1,4 @@
-package main
+package experiments
 
 import (
 	"context"
@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/animus-labs/animus-go/closed/internal/integrations/eventbus"
 	"github.com/google/uuid"
 )
 
@@ -69,5 +70,29 @@ func (api *experimentsAPI) insertEvaluationStateEvent(ctx context.Context, tx *s
 		return false, err
 	}
 	affected, _ := res.RowsAffected()
-	return affected > 0, nil
+	if affected == 0 {
+		return false, nil
+	}
+	if api.bus.Enabled {
+		var (
+			runID     string
+			projectID sql.NullString
+		)
+		if err := tx.QueryRowContext(ctx,
+			`SELECT e.run_id, r.project_id
+			 FROM experiment_run_evaluations e
+			 JOIN experiment_runs r ON r.run_id = e.run_id
+			 WHERE e.evaluation_id = $1`,
+			evaluationID,
+		).Scan(&runID, &projectID); err != nil {
+			return false, err
+		}
+		if err := api.bus.Enqueue(ctx, tx, eventbus.EventEvaluationStateChanged, stateID, projectID.String, observedAt,
+			map[string]string{"evaluation_id": evaluationID, "run_id": runID},
+			map[string]any{"status": status},
+		); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
 }
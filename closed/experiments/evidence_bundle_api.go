@@ -1,4 +1,4 @@
-package main
+package experiments
 
 import (
 	"archive/zip"
@@ -20,7 +20,11 @@ import (
 
 	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
 	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/envelope"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
 	"github.com/animus-labs/animus-go/closed/internal/platform/lineageevent"
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+	repopg "github.com/animus-labs/animus-go/closed/internal/repo/postgres"
 	"github.com/google/uuid"
 	"github.com/minio/minio-go/v7"
 )
@@ -31,18 +35,31 @@ const (
 )
 
 type evidenceBundle struct {
-	BundleID        string    `json:"bundle_id"`
-	RunID           string    `json:"run_id"`
-	BundleObjectKey string    `json:"bundle_object_key"`
-	ReportObjectKey string    `json:"report_object_key"`
-	BundleSHA256    string    `json:"bundle_sha256"`
-	BundleSizeBytes int64     `json:"bundle_size_bytes"`
-	ReportSHA256    string    `json:"report_sha256"`
-	ReportSizeBytes int64     `json:"report_size_bytes"`
-	Signature       string    `json:"signature"`
-	SignatureAlg    string    `json:"signature_alg"`
-	CreatedAt       time.Time `json:"created_at"`
-	CreatedBy       string    `json:"created_by"`
+	BundleID        string            `json:"bundle_id"`
+	RunID           string            `json:"run_id"`
+	BundleObjectKey string            `json:"bundle_object_key"`
+	ReportObjectKey string            `json:"report_object_key"`
+	BundleSHA256    string            `json:"bundle_sha256"`
+	BundleSizeBytes int64             `json:"bundle_size_bytes"`
+	ReportSHA256    string            `json:"report_sha256"`
+	ReportSizeBytes int64             `json:"report_size_bytes"`
+	Signature       string            `json:"signature"`
+	SignatureAlg    string            `json:"signature_alg"`
+	SigningKeyID    string            `json:"signing_key_id,omitempty"`
+	CreatedAt       time.Time         `json:"created_at"`
+	CreatedBy       string            `json:"created_by"`
+	Encryption      *objectEncryption `json:"encryption,omitempty"`
+
+	bundleWrappedKey string
+	reportWrappedKey string
+	integritySHA256  string
+}
+
+// objectEncryption exposes which key protects the stored bundle and report;
+// the wrapped data keys stay in the database.
+type objectEncryption struct {
+	Algorithm string `json:"algorithm"`
+	KeyID     string `json:"key_id"`
 }
 
 type evidenceBundleListResponse struct {
@@ -107,6 +124,17 @@ type evidenceBundleManifest struct {
 	CreatedAt time.Time              `json:"created_at"`
 	CreatedBy string                 `json:"created_by"`
 	Files     []evidenceManifestFile `json:"files"`
+	// Signing is set for asymmetric signatures so the bundle names the key
+	// that verifies it.
+	Signing *evidenceManifestSigning `json:"signing,omitempty"`
+}
+
+type evidenceManifestSigning struct {
+	Algorithm         string `json:"algorithm"`
+	KeyID             string `json:"key_id"`
+	PublicKeyPEM      string `json:"public_key_pem"`
+	CertificateSHA256 string `json:"certificate_sha256,omitempty"`
+	KeysPath          string `json:"keys_path"`
 }
 
 type evidenceManifestFile struct {
@@ -133,8 +161,13 @@ type evidenceBundleIntegrityInput struct {
 	ReportSizeBytes int64     `json:"report_size_bytes"`
 	Signature       string    `json:"signature"`
 	SignatureAlg    string    `json:"signature_alg"`
+	SigningKeyID    string    `json:"signing_key_id,omitempty"`
 	CreatedAt       time.Time `json:"created_at"`
 	CreatedBy       string    `json:"created_by"`
+	EncryptionAlg   string    `json:"encryption_alg,omitempty"`
+	EncryptionKeyID string    `json:"encryption_key_id,omitempty"`
+	BundleWrapped   string    `json:"bundle_wrapped_key,omitempty"`
+	ReportWrapped   string    `json:"report_wrapped_key,omitempty"`
 }
 
 func (api *experimentsAPI) handleCreateEvidenceBundle(w http.ResponseWriter, r *http.Request) {
@@ -156,6 +189,10 @@ func (api *experimentsAPI) handleCreateEvidenceBundle(w http.ResponseWriter, r *
 			api.writeError(w, r, http.StatusBadGateway, "object_store_error")
 			return
 		}
+		if errors.Is(err, errEvidenceEncryptionFailed) {
+			api.writeError(w, r, http.StatusBadGateway, "encryption_key_unavailable")
+			return
+		}
 		if errors.Is(err, sql.ErrNoRows) || errors.Is(err, errEvidenceLedgerMissing) {
 			api.writeError(w, r, http.StatusNotFound, "not_found")
 			return
@@ -174,59 +211,16 @@ func (api *experimentsAPI) handleListEvidenceBundles(w http.ResponseWriter, r *h
 		api.writeError(w, r, http.StatusBadRequest, "run_id_required")
 		return
 	}
-	limit := clampInt(parseIntQuery(r, "limit", 100), 1, 500)
-
-	rows, err := api.db.QueryContext(
-		r.Context(),
-		`SELECT bundle_id,
-				bundle_object_key,
-				report_object_key,
-				bundle_sha256,
-				bundle_size_bytes,
-				report_sha256,
-				report_size_bytes,
-				signature,
-				signature_alg,
-				created_at,
-				created_by
-		 FROM experiment_run_evidence_bundles
-		 WHERE run_id = $1
-		 ORDER BY created_at DESC
-		 LIMIT $2`,
-		runID,
-		limit,
-	)
+	limit := httpapi.Limit(r, 100, 500)
+
+	bundles, err := api.evidenceStore(api.db).ListEvidenceBundles(r.Context(), runID, limit)
 	if err != nil {
 		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
 		return
 	}
-	defer rows.Close()
-
-	out := make([]evidenceBundle, 0, limit)
-	for rows.Next() {
-		var bundle evidenceBundle
-		if err := rows.Scan(
-			&bundle.BundleID,
-			&bundle.BundleObjectKey,
-			&bundle.ReportObjectKey,
-			&bundle.BundleSHA256,
-			&bundle.BundleSizeBytes,
-			&bundle.ReportSHA256,
-			&bundle.ReportSizeBytes,
-			&bundle.Signature,
-			&bundle.SignatureAlg,
-			&bundle.CreatedAt,
-			&bundle.CreatedBy,
-		); err != nil {
-			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
-			return
-		}
-		bundle.RunID = runID
-		out = append(out, bundle)
-	}
-	if err := rows.Err(); err != nil {
-		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
-		return
+	out := make([]evidenceBundle, 0, len(bundles))
+	for _, record := range bundles {
+		out = append(out, evidenceBundleFromRecord(record))
 	}
 
 	api.writeJSON(w, http.StatusOK, evidenceBundleListResponse{Bundles: out})
@@ -246,7 +240,7 @@ func (api *experimentsAPI) handleGetEvidenceBundle(w http.ResponseWriter, r *htt
 
 	bundle, err := api.getEvidenceBundle(r.Context(), runID, bundleID)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
+		if errors.Is(err, repo.ErrNotFound) {
 			api.writeError(w, r, http.StatusNotFound, "not_found")
 			return
 		}
@@ -271,7 +265,7 @@ func (api *experimentsAPI) handleDownloadEvidenceBundle(w http.ResponseWriter, r
 
 	bundle, err := api.getEvidenceBundle(r.Context(), runID, bundleID)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
+		if errors.Is(err, repo.ErrNotFound) {
 			api.writeError(w, r, http.StatusNotFound, "not_found")
 			return
 		}
@@ -279,6 +273,12 @@ func (api *experimentsAPI) handleDownloadEvidenceBundle(w http.ResponseWriter, r
 		return
 	}
 
+	dataKey, err := api.evidenceDataKey(r.Context(), bundle, bundle.bundleWrappedKey)
+	if err != nil {
+		api.writeError(w, r, http.StatusBadGateway, "encryption_key_unavailable")
+		return
+	}
+
 	obj, err := api.store.GetObject(r.Context(), api.storeCfg.BucketArtifacts, bundle.BundleObjectKey, minio.GetObjectOptions{})
 	if err != nil {
 		api.writeError(w, r, http.StatusBadGateway, "object_store_error")
@@ -290,6 +290,14 @@ func (api *experimentsAPI) handleDownloadEvidenceBundle(w http.ResponseWriter, r
 		api.writeError(w, r, http.StatusNotFound, "not_found")
 		return
 	}
+	var body io.Reader = obj
+	if dataKey != nil {
+		body, err = envelope.DecryptReader(dataKey, obj)
+		if err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+	}
 
 	filename := fmt.Sprintf("evidence-%s.zip", bundle.RunID)
 	w.Header().Set("Content-Type", "application/zip")
@@ -298,7 +306,7 @@ func (api *experimentsAPI) handleDownloadEvidenceBundle(w http.ResponseWriter, r
 		w.Header().Set("Content-Length", strconv.FormatInt(bundle.BundleSizeBytes, 10))
 	}
 	w.WriteHeader(http.StatusOK)
-	_, _ = io.Copy(w, obj)
+	_, _ = io.Copy(w, body)
 }
 
 func (api *experimentsAPI) handleDownloadEvidenceReport(w http.ResponseWriter, r *http.Request) {
@@ -313,9 +321,15 @@ func (api *experimentsAPI) handleDownloadEvidenceReport(w http.ResponseWriter, r
 		return
 	}
 
+	format := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("format")))
+	if format != "" && format != "pdf" && format != "json" {
+		api.writeError(w, r, http.StatusBadRequest, "invalid_format")
+		return
+	}
+
 	bundle, err := api.getEvidenceBundle(r.Context(), runID, bundleID)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
+		if errors.Is(err, repo.ErrNotFound) {
 			api.writeError(w, r, http.StatusNotFound, "not_found")
 			return
 		}
@@ -323,6 +337,17 @@ func (api *experimentsAPI) handleDownloadEvidenceReport(w http.ResponseWriter, r
 		return
 	}
 
+	if format == "json" {
+		api.handleEvidenceReportJSON(w, r, bundle)
+		return
+	}
+
+	dataKey, err := api.evidenceDataKey(r.Context(), bundle, bundle.reportWrappedKey)
+	if err != nil {
+		api.writeError(w, r, http.StatusBadGateway, "encryption_key_unavailable")
+		return
+	}
+
 	obj, err := api.store.GetObject(r.Context(), api.storeCfg.BucketArtifacts, bundle.ReportObjectKey, minio.GetObjectOptions{})
 	if err != nil {
 		api.writeError(w, r, http.StatusBadGateway, "object_store_error")
@@ -334,6 +359,14 @@ func (api *experimentsAPI) handleDownloadEvidenceReport(w http.ResponseWriter, r
 		api.writeError(w, r, http.StatusNotFound, "not_found")
 		return
 	}
+	var body io.Reader = obj
+	if dataKey != nil {
+		body, err = envelope.DecryptReader(dataKey, obj)
+		if err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+	}
 
 	filename := fmt.Sprintf("evidence-report-%s.pdf", bundle.RunID)
 	w.Header().Set("Content-Type", "application/pdf")
@@ -342,50 +375,54 @@ func (api *experimentsAPI) handleDownloadEvidenceReport(w http.ResponseWriter, r
 		w.Header().Set("Content-Length", strconv.FormatInt(bundle.ReportSizeBytes, 10))
 	}
 	w.WriteHeader(http.StatusOK)
-	_, _ = io.Copy(w, obj)
+	_, _ = io.Copy(w, body)
+}
+
+// evidenceDataKey unwraps the data key of one bundle object; it returns nil
+// for bundles stored in plaintext.
+func (api *experimentsAPI) evidenceDataKey(ctx context.Context, bundle evidenceBundle, wrappedKey string) ([]byte, error) {
+	if bundle.Encryption == nil {
+		return nil, nil
+	}
+	return api.encrypter.DataKey(ctx, bundle.Encryption.Algorithm, bundle.Encryption.KeyID, wrappedKey)
 }
 
 func (api *experimentsAPI) getEvidenceBundle(ctx context.Context, runID string, bundleID string) (evidenceBundle, error) {
-	var bundle evidenceBundle
-	err := api.db.QueryRowContext(
-		ctx,
-		`SELECT bundle_id,
-				bundle_object_key,
-				report_object_key,
-				bundle_sha256,
-				bundle_size_bytes,
-				report_sha256,
-				report_size_bytes,
-				signature,
-				signature_alg,
-				created_at,
-				created_by
-		 FROM experiment_run_evidence_bundles
-		 WHERE run_id = $1 AND bundle_id = $2`,
-		runID,
-		bundleID,
-	).Scan(
-		&bundle.BundleID,
-		&bundle.BundleObjectKey,
-		&bundle.ReportObjectKey,
-		&bundle.BundleSHA256,
-		&bundle.BundleSizeBytes,
-		&bundle.ReportSHA256,
-		&bundle.ReportSizeBytes,
-		&bundle.Signature,
-		&bundle.SignatureAlg,
-		&bundle.CreatedAt,
-		&bundle.CreatedBy,
-	)
+	record, err := api.evidenceStore(api.db).GetEvidenceBundle(ctx, runID, bundleID)
 	if err != nil {
 		return evidenceBundle{}, err
 	}
-	bundle.RunID = runID
-	return bundle, nil
+	return evidenceBundleFromRecord(record), nil
+}
+
+func evidenceBundleFromRecord(record repo.EvidenceBundleRecord) evidenceBundle {
+	bundle := evidenceBundle{
+		BundleID:         record.BundleID,
+		RunID:            record.RunID,
+		BundleObjectKey:  record.BundleObjectKey,
+		ReportObjectKey:  record.ReportObjectKey,
+		BundleSHA256:     record.BundleSHA256,
+		BundleSizeBytes:  record.BundleSizeBytes,
+		ReportSHA256:     record.ReportSHA256,
+		ReportSizeBytes:  record.ReportSizeBytes,
+		Signature:        record.Signature,
+		SignatureAlg:     record.SignatureAlg,
+		SigningKeyID:     record.SigningKeyID,
+		CreatedAt:        record.CreatedAt,
+		CreatedBy:        record.CreatedBy,
+		bundleWrappedKey: record.BundleWrappedKey,
+		reportWrappedKey: record.ReportWrappedKey,
+		integritySHA256:  record.IntegritySHA256,
+	}
+	if strings.TrimSpace(record.EncryptionAlg) != "" {
+		bundle.Encryption = &objectEncryption{Algorithm: record.EncryptionAlg, KeyID: record.EncryptionKeyID}
+	}
+	return bundle
 }
 
 var errEvidenceLedgerMissing = errors.New("execution ledger missing")
 var errEvidenceStoreFailed = errors.New("evidence object store failure")
+var errEvidenceEncryptionFailed = errors.New("evidence encryption key unavailable")
 
 func (api *experimentsAPI) createEvidenceBundle(ctx context.Context, runID string, identity auth.Identity, r *http.Request) (evidenceBundle, error) {
 	prefix, err := api.getRunArtifactPrefix(ctx, runID)
@@ -408,12 +445,17 @@ func (api *experimentsAPI) createEvidenceBundle(ctx context.Context, runID strin
 		return evidenceBundle{}, err
 	}
 
+	deployments, err := fetchEvidenceDeployments(ctx, api.db, runID)
+	if err != nil {
+		return evidenceBundle{}, err
+	}
+
 	auditEvents, err := fetchEvidenceAudit(ctx, api.db, evidenceAuditInput{
-		RunID:            runID,
-		ExecutionID:      ledgerEntry.ExecutionID,
-		DatasetVersionID: ledgerEntry.Dataset.VersionID,
-		DecisionIDs:      decisionIDs,
-		ApprovalIDs:      approvalIDs,
+		RunID:             runID,
+		ExecutionID:       ledgerEntry.ExecutionID,
+		DatasetVersionIDs: ledgerDatasetVersionIDs(ledgerEntry),
+		DecisionIDs:       decisionIDs,
+		ApprovalIDs:       approvalIDs,
 	})
 	if err != nil {
 		return evidenceBundle{}, err
@@ -435,33 +477,17 @@ func (api *experimentsAPI) createEvidenceBundle(ctx context.Context, runID strin
 	if err != nil {
 		return evidenceBundle{}, err
 	}
+	deploymentPayload, err := json.MarshalIndent(map[string]any{"deployments": deployments}, "", "  ")
+	if err != nil {
+		return evidenceBundle{}, err
+	}
 
 	bundleID := uuid.NewString()
-	createdAt := time.Now().UTC()
+	// Postgres keeps microseconds; truncating keeps the integrity hash
+	// reproducible from the stored row.
+	createdAt := time.Now().UTC().Truncate(time.Microsecond)
 
-	executionHash := ""
-	if len(ledgerRecord.Entries) > 0 {
-		executionHash = ledgerRecord.Entries[0].ExecutionHash
-	}
-
-	reportInput := evidenceReportInput{
-		RunID:            runID,
-		ExecutionID:      ledgerEntry.ExecutionID,
-		ExperimentID:     ledgerEntry.ExperimentID,
-		DatasetID:        ledgerEntry.Dataset.DatasetID,
-		DatasetVersionID: ledgerEntry.Dataset.VersionID,
-		DatasetSHA256:    ledgerEntry.Dataset.SHA256,
-		GitRepo:          ledgerEntry.Git.Repo,
-		GitCommit:        ledgerEntry.Git.Commit,
-		GitRef:           ledgerEntry.Git.Ref,
-		ImageRef:         ledgerEntry.Image.Ref,
-		ImageDigest:      ledgerEntry.Image.Digest,
-		ExecutionHash:    executionHash,
-		PolicyDecisions:  policySnapshot.Decisions,
-		PolicyApprovals:  policySnapshot.Approvals,
-		GeneratedAt:      createdAt,
-		GeneratedBy:      strings.TrimSpace(identity.Subject),
-	}
+	reportInput := buildEvidenceReportInput(runID, ledgerRecord, ledgerEntry, policySnapshot, createdAt, identity.Subject)
 	reportPDF, err := buildComplianceReportPDF(reportInput)
 	if err != nil {
 		return evidenceBundle{}, err
@@ -472,10 +498,30 @@ func (api *experimentsAPI) createEvidenceBundle(ctx context.Context, runID strin
 		{Name: "lineage.json", ContentType: "application/json", Data: lineagePayload},
 		{Name: "audit.json", ContentType: "application/json", Data: auditPayload},
 		{Name: "policies.json", ContentType: "application/json", Data: policyPayload},
+		{Name: "deployments.json", ContentType: "application/json", Data: deploymentPayload},
 		{Name: "report.pdf", ContentType: "application/pdf", Data: reportPDF},
 	}
 
-	manifestPayload, err := buildEvidenceManifest(bundleID, runID, createdAt, identity.Subject, files)
+	provenanceRun, err := fetchEvidenceProvenanceRun(ctx, api.db, runID)
+	if err != nil {
+		return evidenceBundle{}, err
+	}
+	provenanceFiles, err := evidenceProvenanceFiles(buildRunProvenance(ledgerEntry, ledgerRecord.Entries[0], provenanceRun), api.evidenceSigner)
+	if err != nil {
+		return evidenceBundle{}, err
+	}
+	files = append(files, provenanceFiles...)
+	configFiles, err := evidenceConfigManifestFiles(ctx, api.db, runID)
+	if err != nil {
+		return evidenceBundle{}, err
+	}
+	files = append(files, configFiles...)
+
+	var manifestSigning *evidenceManifestSigning
+	if api.evidenceSigner != nil {
+		manifestSigning = api.evidenceSigner.manifestSigning()
+	}
+	manifestPayload, err := buildEvidenceManifest(bundleID, runID, createdAt, identity.Subject, files, manifestSigning)
 	if err != nil {
 		return evidenceBundle{}, err
 	}
@@ -486,7 +532,7 @@ func (api *experimentsAPI) createEvidenceBundle(ctx context.Context, runID strin
 		return evidenceBundle{}, err
 	}
 
-	signature, err := computeEvidenceSignature(api.evidenceSigningSecret, bundleSHA)
+	signature, signatureAlg, signingKeyID, err := api.signEvidenceBundle(bundleSHA)
 	if err != nil {
 		return evidenceBundle{}, err
 	}
@@ -498,14 +544,44 @@ func (api *experimentsAPI) createEvidenceBundle(ctx context.Context, runID strin
 	bundleObjectKey := fmt.Sprintf("%s/bundle.zip", bundlePrefix)
 	reportObjectKey := fmt.Sprintf("%s/report.pdf", bundlePrefix)
 
+	// Hashes, sizes and the signature cover the plaintext; with encryption on,
+	// the bundle and the report are sealed under separate data keys.
+	storedBundle, storedReport := bundleData, reportPDF
+	bundleContentType, reportContentType := "application/zip", "application/pdf"
+	var encryption *objectEncryption
+	var encryptionAlg, encryptionKeyID, bundleWrappedKey, reportWrappedKey string
+	if api.encrypter.Enabled() {
+		bundleKey, err := api.encrypter.NewObjectKey(ctx)
+		if err != nil {
+			return evidenceBundle{}, fmt.Errorf("%w: %s", errEvidenceEncryptionFailed, err)
+		}
+		reportKey, err := api.encrypter.NewObjectKey(ctx)
+		if err != nil {
+			return evidenceBundle{}, fmt.Errorf("%w: %s", errEvidenceEncryptionFailed, err)
+		}
+		if reportKey.KeyID != bundleKey.KeyID {
+			return evidenceBundle{}, fmt.Errorf("%w: key rotated during bundle creation", errEvidenceEncryptionFailed)
+		}
+		if storedBundle, err = envelope.Seal(bundleKey.DataKey, bundleData); err != nil {
+			return evidenceBundle{}, err
+		}
+		if storedReport, err = envelope.Seal(reportKey.DataKey, reportPDF); err != nil {
+			return evidenceBundle{}, err
+		}
+		bundleContentType, reportContentType = "application/octet-stream", "application/octet-stream"
+		encryptionAlg, encryptionKeyID = bundleKey.Algorithm, bundleKey.KeyID
+		encryption = &objectEncryption{Algorithm: encryptionAlg, KeyID: encryptionKeyID}
+		bundleWrappedKey, reportWrappedKey = bundleKey.WrappedKey, reportKey.WrappedKey
+	}
+
 	putCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
 	_, err = api.store.PutObject(
 		putCtx,
 		api.storeCfg.BucketArtifacts,
 		bundleObjectKey,
-		bytes.NewReader(bundleData),
-		bundleSize,
-		minio.PutObjectOptions{ContentType: "application/zip"},
+		bytes.NewReader(storedBundle),
+		int64(len(storedBundle)),
+		minio.PutObjectOptions{ContentType: bundleContentType},
 	)
 	cancel()
 	if err != nil {
@@ -517,9 +593,9 @@ func (api *experimentsAPI) createEvidenceBundle(ctx context.Context, runID strin
 		putCtx,
 		api.storeCfg.BucketArtifacts,
 		reportObjectKey,
-		bytes.NewReader(reportPDF),
-		reportSize,
-		minio.PutObjectOptions{ContentType: "application/pdf"},
+		bytes.NewReader(storedReport),
+		int64(len(storedReport)),
+		minio.PutObjectOptions{ContentType: reportContentType},
 	)
 	cancel()
 	if err != nil {
@@ -537,9 +613,14 @@ func (api *experimentsAPI) createEvidenceBundle(ctx context.Context, runID strin
 		ReportSHA256:    reportSHA,
 		ReportSizeBytes: reportSize,
 		Signature:       signature,
-		SignatureAlg:    evidenceSignatureAlg,
+		SignatureAlg:    signatureAlg,
+		SigningKeyID:    signingKeyID,
 		CreatedAt:       createdAt,
 		CreatedBy:       strings.TrimSpace(identity.Subject),
+		EncryptionAlg:   encryptionAlg,
+		EncryptionKeyID: encryptionKeyID,
+		BundleWrapped:   bundleWrappedKey,
+		ReportWrapped:   reportWrappedKey,
 	})
 	if err != nil {
 		_ = api.store.RemoveObject(ctx, api.storeCfg.BucketArtifacts, bundleObjectKey, minio.RemoveObjectOptions{})
@@ -555,44 +636,33 @@ func (api *experimentsAPI) createEvidenceBundle(ctx context.Context, runID strin
 	}
 	defer func() { _ = tx.Rollback() }()
 
-	_, err = tx.ExecContext(
-		ctx,
-		`INSERT INTO experiment_run_evidence_bundles (
-			bundle_id,
-			run_id,
-			bundle_object_key,
-			report_object_key,
-			bundle_sha256,
-			bundle_size_bytes,
-			report_sha256,
-			report_size_bytes,
-			signature,
-			signature_alg,
-			created_at,
-			created_by,
-			integrity_sha256
-		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13)`,
-		bundleID,
-		runID,
-		bundleObjectKey,
-		reportObjectKey,
-		bundleSHA,
-		bundleSize,
-		reportSHA,
-		reportSize,
-		signature,
-		evidenceSignatureAlg,
-		createdAt,
-		strings.TrimSpace(identity.Subject),
-		integrity,
-	)
+	err = api.evidenceStore(tx).CreateEvidenceBundle(ctx, repo.EvidenceBundleRecord{
+		BundleID:         bundleID,
+		RunID:            runID,
+		BundleObjectKey:  bundleObjectKey,
+		ReportObjectKey:  reportObjectKey,
+		BundleSHA256:     bundleSHA,
+		BundleSizeBytes:  bundleSize,
+		ReportSHA256:     reportSHA,
+		ReportSizeBytes:  reportSize,
+		Signature:        signature,
+		SignatureAlg:     signatureAlg,
+		SigningKeyID:     signingKeyID,
+		CreatedAt:        createdAt,
+		CreatedBy:        strings.TrimSpace(identity.Subject),
+		EncryptionAlg:    encryptionAlg,
+		EncryptionKeyID:  encryptionKeyID,
+		BundleWrappedKey: bundleWrappedKey,
+		ReportWrappedKey: reportWrappedKey,
+		IntegritySHA256:  integrity,
+	})
 	if err != nil {
 		_ = api.store.RemoveObject(ctx, api.storeCfg.BucketArtifacts, bundleObjectKey, minio.RemoveObjectOptions{})
 		_ = api.store.RemoveObject(ctx, api.storeCfg.BucketArtifacts, reportObjectKey, minio.RemoveObjectOptions{})
 		return evidenceBundle{}, err
 	}
 
-	_, err = lineageevent.Insert(ctx, tx, lineageevent.Event{
+	err = lineageevent.Enqueue(ctx, tx, lineageevent.Event{
 		OccurredAt:  createdAt,
 		Actor:       identity.Subject,
 		RequestID:   r.Header.Get("X-Request-Id"),
@@ -604,7 +674,7 @@ func (api *experimentsAPI) createEvidenceBundle(ctx context.Context, runID strin
 		Metadata: map[string]any{
 			"bundle_sha256": bundleSHA,
 			"bundle_size":   bundleSize,
-			"signature_alg": evidenceSignatureAlg,
+			"signature_alg": signatureAlg,
 		},
 	})
 	if err != nil {
@@ -613,14 +683,14 @@ func (api *experimentsAPI) createEvidenceBundle(ctx context.Context, runID strin
 		return evidenceBundle{}, err
 	}
 
-	_, err = auditlog.Insert(ctx, tx, auditlog.Event{
+	err = auditlog.Enqueue(ctx, tx, auditlog.Event{
 		OccurredAt:   createdAt,
 		Actor:        identity.Subject,
 		Action:       "evidence_bundle.create",
 		ResourceType: "evidence_bundle",
 		ResourceID:   bundleID,
 		RequestID:    r.Header.Get("X-Request-Id"),
-		IP:           requestIP(r.RemoteAddr),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
 		UserAgent:    r.UserAgent(),
 		Payload: map[string]any{
 			"service":           "experiments",
@@ -631,7 +701,7 @@ func (api *experimentsAPI) createEvidenceBundle(ctx context.Context, runID strin
 			"bundle_size_bytes": bundleSize,
 			"report_sha256":     reportSHA,
 			"report_size_bytes": reportSize,
-			"signature_alg":     evidenceSignatureAlg,
+			"signature_alg":     signatureAlg,
 		},
 	})
 	if err != nil {
@@ -656,12 +726,44 @@ func (api *experimentsAPI) createEvidenceBundle(ctx context.Context, runID strin
 		ReportSHA256:    reportSHA,
 		ReportSizeBytes: reportSize,
 		Signature:       signature,
-		SignatureAlg:    evidenceSignatureAlg,
+		SignatureAlg:    signatureAlg,
+		SigningKeyID:    signingKeyID,
 		CreatedAt:       createdAt,
 		CreatedBy:       strings.TrimSpace(identity.Subject),
+		Encryption:      encryption,
 	}, nil
 }
 
+// buildEvidenceReportInput derives the compliance report from the same ledger
+// and policy snapshot that go into the bundle, so the report can be rebuilt
+// from bundle contents alone.
+func buildEvidenceReportInput(runID string, ledgerRecord executionLedgerExportResponse, ledgerEntry executionLedgerEntry, policies evidencePolicySnapshot, generatedAt time.Time, generatedBy string) evidenceReportInput {
+	executionHash := ""
+	if len(ledgerRecord.Entries) > 0 {
+		executionHash = ledgerRecord.Entries[0].ExecutionHash
+	}
+	return evidenceReportInput{
+		RunID:            runID,
+		ExecutionID:      ledgerEntry.ExecutionID,
+		ExperimentID:     ledgerEntry.ExperimentID,
+		DatasetID:        ledgerEntry.Dataset.DatasetID,
+		DatasetVersionID: ledgerEntry.Dataset.VersionID,
+		DatasetSHA256:    ledgerEntry.Dataset.SHA256,
+		Datasets:         ledgerEntry.Datasets,
+		GitRepo:          ledgerEntry.Git.Repo,
+		GitCommit:        ledgerEntry.Git.Commit,
+		GitRef:           ledgerEntry.Git.Ref,
+		ImageRef:         ledgerEntry.Image.Ref,
+		ImageDigest:      ledgerEntry.Image.Digest,
+		ExecutionHash:    executionHash,
+		PolicyDecisions:  policies.Decisions,
+		PolicyApprovals:  policies.Approvals,
+		Energy:           ledgerEntry.Energy,
+		GeneratedAt:      generatedAt,
+		GeneratedBy:      strings.TrimSpace(generatedBy),
+	}
+}
+
 func (api *experimentsAPI) fetchEvidenceLedger(ctx context.Context, runID string) (executionLedgerExportResponse, executionLedgerEntry, error) {
 	var (
 		record    executionLedgerRecord
@@ -767,15 +869,26 @@ func fetchEvidenceLineage(ctx context.Context, db *sql.DB, runID string) ([]evid
 }
 
 type evidenceAuditInput struct {
-	RunID            string
-	ExecutionID      string
-	DatasetVersionID string
-	DecisionIDs      []string
-	ApprovalIDs      []string
+	RunID             string
+	ExecutionID       string
+	DatasetVersionIDs []string
+	DecisionIDs       []string
+	ApprovalIDs       []string
+}
+
+// ledgerDatasetVersionIDs returns every input dataset version of the entry;
+// entries written before multi-dataset runs only carry the primary dataset.
+func ledgerDatasetVersionIDs(entry executionLedgerEntry) []string {
+	ids := []string{entry.Dataset.VersionID}
+	for _, dataset := range entry.Datasets {
+		ids = append(ids, dataset.VersionID)
+	}
+	return uniqueNonEmpty(ids)
 }
 
 func fetchEvidenceAudit(ctx context.Context, db *sql.DB, input evidenceAuditInput) ([]evidenceAuditEvent, error) {
-	ids := []string{input.RunID, input.ExecutionID, input.DatasetVersionID}
+	ids := []string{input.RunID, input.ExecutionID}
+	ids = append(ids, input.DatasetVersionIDs...)
 	ids = append(ids, input.DecisionIDs...)
 	ids = append(ids, input.ApprovalIDs...)
 	ids = uniqueNonEmpty(ids)
@@ -883,6 +996,7 @@ func fetchEvidenceDecisions(ctx context.Context, db *sql.DB, runID string) ([]po
 				d.decision,
 				d.rule_id,
 				d.reason,
+				d.bundle_revision,
 				d.created_at,
 				d.created_by
 		 FROM policy_decisions d
@@ -911,6 +1025,7 @@ func fetchEvidenceDecisions(ctx context.Context, db *sql.DB, runID string) ([]po
 			decision   string
 			ruleID     sql.NullString
 			reason     sql.NullString
+			bundleRev  sql.NullString
 			createdAt  time.Time
 			createdBy  string
 		)
@@ -926,6 +1041,7 @@ func fetchEvidenceDecisions(ctx context.Context, db *sql.DB, runID string) ([]po
 			&decision,
 			&ruleID,
 			&reason,
+			&bundleRev,
 			&createdAt,
 			&createdBy,
 		); err != nil {
@@ -943,6 +1059,7 @@ func fetchEvidenceDecisions(ctx context.Context, db *sql.DB, runID string) ([]po
 				Decision:        decision,
 				RuleID:          strings.TrimSpace(ruleID.String),
 				Reason:          strings.TrimSpace(reason.String),
+				BundleRevision:  strings.TrimSpace(bundleRev.String),
 				CreatedAt:       createdAt,
 				CreatedBy:       createdBy,
 			},
@@ -973,7 +1090,11 @@ func fetchEvidenceApprovals(ctx context.Context, db *sql.DB, runID string) ([]po
 				d.policy_version_id,
 				d.decision,
 				d.rule_id,
-				d.context
+				d.context,
+				a.workflow,
+				a.current_stage,
+				a.escalate_at,
+				a.assigned_to
 		 FROM policy_approvals a
 		 JOIN policy_decisions d ON d.decision_id = a.decision_id
 		 JOIN policies p ON p.policy_id = d.policy_id
@@ -1005,6 +1126,10 @@ func fetchEvidenceApprovals(ctx context.Context, db *sql.DB, runID string) ([]po
 			decision    string
 			ruleID      sql.NullString
 			contextRaw  []byte
+			workflow    []byte
+			stage       int
+			escalateAt  sql.NullTime
+			assignedTo  sql.NullString
 		)
 		if err := rows.Scan(
 			&approvalID,
@@ -1022,6 +1147,10 @@ func fetchEvidenceApprovals(ctx context.Context, db *sql.DB, runID string) ([]po
 			&decision,
 			&ruleID,
 			&contextRaw,
+			&workflow,
+			&stage,
+			&escalateAt,
+			&assignedTo,
 		); err != nil {
 			return nil, nil, err
 		}
@@ -1033,7 +1162,7 @@ func fetchEvidenceApprovals(ctx context.Context, db *sql.DB, runID string) ([]po
 		}
 
 		out = append(out, policyApprovalDetail{
-			policyApprovalSummary: policyApprovalSummary{
+			policyApprovalSummary: newPolicyApprovalSummary(workflow, stage, timePtrFromNull(escalateAt), policyApprovalSummary{
 				ApprovalID:      approvalID,
 				DecisionID:      decisionID,
 				RunID:           strings.TrimSpace(runIDVal.String),
@@ -1048,7 +1177,8 @@ func fetchEvidenceApprovals(ctx context.Context, db *sql.DB, runID string) ([]po
 				PolicyVersionID: versionID,
 				Decision:        decision,
 				RuleID:          strings.TrimSpace(ruleID.String),
-			},
+				AssignedTo:      strings.TrimSpace(assignedTo.String),
+			}),
 			DecisionContext: normalizeJSON(contextRaw),
 		})
 		ids = append(ids, approvalID)
@@ -1056,6 +1186,14 @@ func fetchEvidenceApprovals(ctx context.Context, db *sql.DB, runID string) ([]po
 	if err := rows.Err(); err != nil {
 		return nil, nil, err
 	}
+	rows.Close()
+	for i := range out {
+		votes, err := fetchPolicyApprovalVotes(ctx, repopg.NewPolicyStore(db), out[i].ApprovalID)
+		if err != nil {
+			return nil, nil, err
+		}
+		out[i].Votes = votes
+	}
 	return out, uniqueNonEmpty(ids), nil
 }
 
@@ -1138,7 +1276,7 @@ func fetchEvidencePolicyVersions(ctx context.Context, db *sql.DB, decisions []po
 	return out, nil
 }
 
-func buildEvidenceManifest(bundleID string, runID string, createdAt time.Time, createdBy string, files []evidenceBundleFile) ([]byte, error) {
+func buildEvidenceManifest(bundleID string, runID string, createdAt time.Time, createdBy string, files []evidenceBundleFile, signing *evidenceManifestSigning) ([]byte, error) {
 	manifestFiles := make([]evidenceManifestFile, 0, len(files))
 	for _, file := range files {
 		manifestFiles = append(manifestFiles, evidenceManifestFile{
@@ -1159,6 +1297,7 @@ func buildEvidenceManifest(bundleID string, runID string, createdAt time.Time, c
 		CreatedAt: createdAt,
 		CreatedBy: strings.TrimSpace(createdBy),
 		Files:     manifestFiles,
+		Signing:   signing,
 	}
 	return json.MarshalIndent(manifest, "", "  ")
 }
@@ -1193,6 +1332,17 @@ func buildEvidenceZip(createdAt time.Time, files []evidenceBundleFile) ([]byte,
 	return bundleData, bundleSHA, int64(len(bundleData)), nil
 }
 
+// signEvidenceBundle signs with the configured Ed25519 key, or with the shared
+// HMAC secret when no key is configured.
+func (api *experimentsAPI) signEvidenceBundle(bundleSHA string) (string, string, string, error) {
+	if api.evidenceSigner != nil {
+		signature, err := api.evidenceSigner.Sign(bundleSHA)
+		return signature, evidenceSignatureAlgEd25519, api.evidenceSigner.KeyID(), err
+	}
+	signature, err := computeEvidenceSignature(api.evidenceSigningSecret, bundleSHA)
+	return signature, evidenceSignatureAlg, "", err
+}
+
 func computeEvidenceSignature(secret string, bundleSHA string) (string, error) {
 	if strings.TrimSpace(secret) == "" {
 		return "", errors.New("evidence signing secret required")
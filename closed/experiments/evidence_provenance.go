@@ -0,0 +1,338 @@
+package experiments
+
+import (
+	"context"
+	"crypto/ed25519"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+)
+
+// Provenance follows the in-toto Statement v1 layout with a SLSA provenance
+// v1 predicate, so standard supply-chain verifiers can consume it.
+const (
+	inTotoStatementTypeV1       = "https://in-toto.io/Statement/v1"
+	slsaProvenancePredicateV1   = "https://slsa.dev/provenance/v1"
+	dssePayloadTypeInToto       = "application/vnd.in-toto+json"
+	evidenceProvenanceBuildType = "https://animus.dev/buildtypes/experiment-run/v1"
+	evidenceProvenanceBuilderID = "https://animus.dev/builders/experiments"
+
+	evidenceProvenanceName = "provenance.intoto.json"
+	evidenceProvenanceDSSE = "provenance.dsse.json"
+)
+
+type inTotoStatement struct {
+	Type          string           `json:"_type"`
+	Subject       []inTotoSubject  `json:"subject"`
+	PredicateType string           `json:"predicateType"`
+	Predicate     slsaProvenanceV1 `json:"predicate"`
+}
+
+type inTotoSubject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+type slsaProvenanceV1 struct {
+	BuildDefinition slsaBuildDefinition `json:"buildDefinition"`
+	RunDetails      slsaRunDetails      `json:"runDetails"`
+}
+
+type slsaBuildDefinition struct {
+	BuildType            string                   `json:"buildType"`
+	ExternalParameters   map[string]any           `json:"externalParameters"`
+	InternalParameters   map[string]any           `json:"internalParameters,omitempty"`
+	ResolvedDependencies []slsaResourceDescriptor `json:"resolvedDependencies"`
+}
+
+type slsaResourceDescriptor struct {
+	Name   string            `json:"name,omitempty"`
+	URI    string            `json:"uri,omitempty"`
+	Digest map[string]string `json:"digest"`
+}
+
+type slsaRunDetails struct {
+	Builder    slsaBuilder              `json:"builder"`
+	Metadata   slsaBuildMetadata        `json:"metadata"`
+	Byproducts []slsaResourceDescriptor `json:"byproducts,omitempty"`
+}
+
+type slsaBuilder struct {
+	ID string `json:"id"`
+}
+
+type slsaBuildMetadata struct {
+	InvocationID string     `json:"invocationId"`
+	StartedOn    *time.Time `json:"startedOn,omitempty"`
+	FinishedOn   *time.Time `json:"finishedOn,omitempty"`
+}
+
+type dsseEnvelope struct {
+	PayloadType string          `json:"payloadType"`
+	Payload     string          `json:"payload"`
+	Signatures  []dsseSignature `json:"signatures"`
+}
+
+type dsseSignature struct {
+	KeyID string `json:"keyid"`
+	Sig   string `json:"sig"`
+}
+
+type evidenceProvenanceRun struct {
+	Subjects  []inTotoSubject
+	StartedAt time.Time
+	EndedAt   sql.NullTime
+}
+
+var errEvidenceProvenanceMissing = errors.New("evidence provenance missing")
+
+// fetchEvidenceProvenanceRun loads the run's artifact digests, which become
+// the attestation subjects, and its timing.
+func fetchEvidenceProvenanceRun(ctx context.Context, db *sql.DB, runID string) (evidenceProvenanceRun, error) {
+	var run evidenceProvenanceRun
+	err := db.QueryRowContext(
+		ctx,
+		`SELECT started_at, ended_at FROM experiment_runs WHERE run_id = $1`,
+		runID,
+	).Scan(&run.StartedAt, &run.EndedAt)
+	if err != nil {
+		return evidenceProvenanceRun{}, err
+	}
+
+	rows, err := db.QueryContext(
+		ctx,
+		`SELECT artifact_id, name, filename, sha256
+		 FROM experiment_run_artifacts
+		 WHERE run_id = $1
+		 ORDER BY created_at ASC, artifact_id ASC`,
+		runID,
+	)
+	if err != nil {
+		return evidenceProvenanceRun{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			artifactID string
+			name       sql.NullString
+			filename   sql.NullString
+			sha        string
+		)
+		if err := rows.Scan(&artifactID, &name, &filename, &sha); err != nil {
+			return evidenceProvenanceRun{}, err
+		}
+		subject := artifactID
+		if v := strings.TrimSpace(filename.String); v != "" {
+			subject = artifactID + "/" + v
+		} else if v := strings.TrimSpace(name.String); v != "" {
+			subject = artifactID + "/" + v
+		}
+		run.Subjects = append(run.Subjects, inTotoSubject{Name: subject, Digest: map[string]string{"sha256": sha}})
+	}
+	if err := rows.Err(); err != nil {
+		return evidenceProvenanceRun{}, err
+	}
+	return run, nil
+}
+
+// buildRunProvenance describes the run as a SLSA build: artifacts are the
+// subjects, dataset versions, the git commit and the image digest are the
+// resolved dependencies.
+func buildRunProvenance(entry executionLedgerEntry, record executionLedgerRecord, run evidenceProvenanceRun) inTotoStatement {
+	external := map[string]any{
+		"experiment_id": entry.ExperimentID,
+		"run_id":        entry.RunID,
+	}
+	if len(entry.Params) > 0 {
+		external["params"] = entry.Params
+	}
+	if len(entry.Resources) > 0 {
+		external["resources"] = entry.Resources
+	}
+	if entry.Image.Ref != "" {
+		external["image"] = entry.Image.Ref
+	}
+	internal := map[string]any{"executor": entry.Executor.Kind}
+	if entry.Executor.K8sNamespace != "" {
+		internal["k8s_namespace"] = entry.Executor.K8sNamespace
+	}
+
+	datasets := entry.Datasets
+	if len(datasets) == 0 && entry.Dataset.VersionID != "" {
+		datasets = []executionLedgerDataset{entry.Dataset}
+	}
+	deps := make([]slsaResourceDescriptor, 0, len(datasets)+2)
+	for _, dataset := range datasets {
+		deps = append(deps, slsaResourceDescriptor{
+			Name:   "dataset:" + dataset.DatasetID,
+			URI:    fmt.Sprintf("animus://datasets/%s/versions/%s", dataset.DatasetID, dataset.VersionID),
+			Digest: map[string]string{"sha256": dataset.SHA256},
+		})
+	}
+	if entry.Git.Commit != "" {
+		uri := entry.Git.Repo
+		if uri != "" && !strings.HasPrefix(uri, "git+") {
+			uri = "git+" + uri
+		}
+		if uri != "" && entry.Git.Ref != "" {
+			uri += "@" + entry.Git.Ref
+		}
+		deps = append(deps, slsaResourceDescriptor{
+			Name:   "source",
+			URI:    uri,
+			Digest: map[string]string{"gitCommit": entry.Git.Commit},
+		})
+	}
+	if algorithm, digest, ok := strings.Cut(entry.Image.Digest, ":"); ok && digest != "" {
+		deps = append(deps, slsaResourceDescriptor{
+			Name:   "image",
+			URI:    entry.Image.Ref,
+			Digest: map[string]string{algorithm: digest},
+		})
+	}
+
+	metadata := slsaBuildMetadata{InvocationID: entry.ExecutionID}
+	if !run.StartedAt.IsZero() {
+		started := run.StartedAt.UTC()
+		metadata.StartedOn = &started
+	}
+	if run.EndedAt.Valid {
+		finished := run.EndedAt.Time.UTC()
+		metadata.FinishedOn = &finished
+	}
+
+	return inTotoStatement{
+		Type:          inTotoStatementTypeV1,
+		Subject:       run.Subjects,
+		PredicateType: slsaProvenancePredicateV1,
+		Predicate: slsaProvenanceV1{
+			BuildDefinition: slsaBuildDefinition{
+				BuildType:            evidenceProvenanceBuildType,
+				ExternalParameters:   external,
+				InternalParameters:   internal,
+				ResolvedDependencies: deps,
+			},
+			RunDetails: slsaRunDetails{
+				Builder:  slsaBuilder{ID: evidenceProvenanceBuilderID},
+				Metadata: metadata,
+				Byproducts: []slsaResourceDescriptor{{
+					Name:   "execution_ledger",
+					URI:    "animus://execution-ledger/" + record.LedgerID,
+					Digest: map[string]string{"sha256": record.EntrySHA256},
+				}},
+			},
+		},
+	}
+}
+
+// evidenceProvenanceFiles renders the statement and, with an Ed25519 signer,
+// a DSSE envelope over it. Runs without artifacts get no provenance: a
+// statement must name at least one subject.
+func evidenceProvenanceFiles(statement inTotoStatement, signer *evidenceSigner) ([]evidenceBundleFile, error) {
+	if len(statement.Subject) == 0 {
+		return nil, nil
+	}
+	payload, err := json.MarshalIndent(statement, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	files := []evidenceBundleFile{{Name: evidenceProvenanceName, ContentType: dssePayloadTypeInToto, Data: payload}}
+	if signer == nil {
+		return files, nil
+	}
+	envelope, err := json.MarshalIndent(signer.signDSSE(dssePayloadTypeInToto, payload), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(files, evidenceBundleFile{Name: evidenceProvenanceDSSE, ContentType: "application/json", Data: envelope}), nil
+}
+
+func (s *evidenceSigner) signDSSE(payloadType string, payload []byte) dsseEnvelope {
+	sig := ed25519.Sign(s.private, dssePAE(payloadType, payload))
+	return dsseEnvelope{
+		PayloadType: payloadType,
+		Payload:     base64.StdEncoding.EncodeToString(payload),
+		Signatures:  []dsseSignature{{KeyID: s.key.KeyID, Sig: base64.StdEncoding.EncodeToString(sig)}},
+	}
+}
+
+// dssePAE is the DSSE v1 pre-authentication encoding.
+func dssePAE(payloadType string, payload []byte) []byte {
+	return fmt.Appendf(nil, "DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload)
+}
+
+func (api *experimentsAPI) handleDownloadEvidenceProvenance(w http.ResponseWriter, r *http.Request) {
+	runID := strings.TrimSpace(r.PathValue("run_id"))
+	bundleID := strings.TrimSpace(r.PathValue("bundle_id"))
+	if runID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "run_id_required")
+		return
+	}
+	if bundleID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "bundle_id_required")
+		return
+	}
+	format := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("format")))
+	if format != "" && format != "statement" && format != "dsse" {
+		api.writeError(w, r, http.StatusBadRequest, "invalid_format")
+		return
+	}
+
+	bundle, err := api.getEvidenceBundle(r.Context(), runID, bundleID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	archive, err := api.readEvidenceObject(r.Context(), bundle, bundle.BundleObjectKey, bundle.bundleWrappedKey)
+	if err != nil {
+		api.writeEvidenceReadError(w, r, err)
+		return
+	}
+	if archive.Problem != "" {
+		api.writeError(w, r, http.StatusNotFound, "not_found")
+		return
+	}
+	files, err := readEvidenceZip(archive.Data)
+	if err != nil {
+		api.writeError(w, r, http.StatusUnprocessableEntity, "bundle_unreadable")
+		return
+	}
+
+	name, contentType, err := selectEvidenceProvenance(files, format)
+	if err != nil {
+		api.writeError(w, r, http.StatusNotFound, "provenance_unavailable")
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("provenance-%s-%s", bundle.RunID, name)))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(files[name])
+}
+
+// selectEvidenceProvenance prefers the signed envelope unless the plain
+// statement is requested.
+func selectEvidenceProvenance(files map[string][]byte, format string) (string, string, error) {
+	_, hasStatement := files[evidenceProvenanceName]
+	_, hasEnvelope := files[evidenceProvenanceDSSE]
+	switch {
+	case format == "statement" && hasStatement:
+		return evidenceProvenanceName, dssePayloadTypeInToto, nil
+	case format != "statement" && hasEnvelope:
+		return evidenceProvenanceDSSE, "application/json", nil
+	case format == "" && hasStatement:
+		return evidenceProvenanceName, dssePayloadTypeInToto, nil
+	}
+	return "", "", errEvidenceProvenanceMissing
+}
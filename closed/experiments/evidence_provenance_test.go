@@ -0,0 +1,131 @@
+package experiments
+
+import (
+	"crypto/ed25519"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func testProvenanceEntry() (executionLedgerEntry, executionLedgerRecord) {
+	entry := executionLedgerEntry{
+		RunID:        "run-1",
+		ExecutionID:  "exec-1",
+		ExperimentID: "exp-1",
+		Datasets: []executionLedgerDataset{
+			{DatasetID: "ds-1", VersionID: "dv-1", SHA256: "aa"},
+			{DatasetID: "ds-2", VersionID: "dv-2", SHA256: "bb"},
+		},
+		Git:      executionLedgerGit{Repo: "https://git.example.com/ml/train.git", Commit: "0123abcd", Ref: "main"},
+		Image:    executionLedgerImage{Ref: "registry.example.com/train:1", Digest: "sha256:cc"},
+		Executor: executionLedgerExecutor{Kind: "k8s", K8sNamespace: "runs"},
+		Params:   json.RawMessage(`{"lr":0.1}`),
+	}
+	return entry, executionLedgerRecord{LedgerID: "ledger-1", EntrySHA256: "dd"}
+}
+
+func TestBuildRunProvenance(t *testing.T) {
+	entry, record := testProvenanceEntry()
+	started := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	statement := buildRunProvenance(entry, record, evidenceProvenanceRun{
+		Subjects:  []inTotoSubject{{Name: "art-1/model.pt", Digest: map[string]string{"sha256": "ee"}}},
+		StartedAt: started,
+		EndedAt:   sql.NullTime{Time: started.Add(time.Hour), Valid: true},
+	})
+
+	if statement.Type != inTotoStatementTypeV1 || statement.PredicateType != slsaProvenancePredicateV1 {
+		t.Fatalf("unexpected statement header %q %q", statement.Type, statement.PredicateType)
+	}
+	deps := statement.Predicate.BuildDefinition.ResolvedDependencies
+	if len(deps) != 4 {
+		t.Fatalf("expected 4 resolved dependencies, got %+v", deps)
+	}
+	if deps[0].Digest["sha256"] != "aa" || deps[1].URI != "animus://datasets/ds-2/versions/dv-2" {
+		t.Fatalf("unexpected dataset dependencies %+v", deps[:2])
+	}
+	if deps[2].URI != "git+https://git.example.com/ml/train.git@main" || deps[2].Digest["gitCommit"] != "0123abcd" {
+		t.Fatalf("unexpected source dependency %+v", deps[2])
+	}
+	if deps[3].Digest["sha256"] != "cc" || deps[3].URI != "registry.example.com/train:1" {
+		t.Fatalf("unexpected image dependency %+v", deps[3])
+	}
+	metadata := statement.Predicate.RunDetails.Metadata
+	if metadata.InvocationID != "exec-1" || metadata.StartedOn == nil || metadata.FinishedOn == nil {
+		t.Fatalf("unexpected metadata %+v", metadata)
+	}
+}
+
+func TestEvidenceProvenanceFiles(t *testing.T) {
+	entry, record := testProvenanceEntry()
+	empty := buildRunProvenance(entry, record, evidenceProvenanceRun{})
+	if files, err := evidenceProvenanceFiles(empty, nil); err != nil || files != nil {
+		t.Fatalf("expected no provenance without subjects, got %d files (%v)", len(files), err)
+	}
+
+	statement := buildRunProvenance(entry, record, evidenceProvenanceRun{
+		Subjects: []inTotoSubject{{Name: "art-1", Digest: map[string]string{"sha256": "ee"}}},
+	})
+	files, err := evidenceProvenanceFiles(statement, nil)
+	if err != nil || len(files) != 1 || files[0].Name != evidenceProvenanceName {
+		t.Fatalf("expected statement only, got %+v (%v)", files, err)
+	}
+
+	_, keyPEM := newTestEd25519Key(t)
+	signer, err := newEvidenceSigner(keyPEM, nil)
+	if err != nil {
+		t.Fatalf("new signer: %v", err)
+	}
+	files, err = evidenceProvenanceFiles(statement, signer)
+	if err != nil || len(files) != 2 || files[1].Name != evidenceProvenanceDSSE {
+		t.Fatalf("expected statement and envelope, got %+v (%v)", files, err)
+	}
+
+	var envelope dsseEnvelope
+	if err := json.Unmarshal(files[1].Data, &envelope); err != nil {
+		t.Fatalf("decode envelope: %v", err)
+	}
+	payload, err := base64.StdEncoding.DecodeString(envelope.Payload)
+	if err != nil || string(payload) != string(files[0].Data) {
+		t.Fatalf("envelope payload does not match the statement")
+	}
+	sig, err := base64.StdEncoding.DecodeString(envelope.Signatures[0].Sig)
+	if err != nil {
+		t.Fatalf("decode signature: %v", err)
+	}
+	public := signer.private.Public().(ed25519.PublicKey)
+	if envelope.Signatures[0].KeyID != signer.KeyID() || !ed25519.Verify(public, dssePAE(envelope.PayloadType, payload), sig) {
+		t.Fatalf("envelope signature does not verify")
+	}
+}
+
+func TestDSSEPAE(t *testing.T) {
+	got := string(dssePAE("http://example.com/HelloWorld", []byte("hello world")))
+	want := "DSSEv1 29 http://example.com/HelloWorld 11 hello world"
+	if got != want {
+		t.Fatalf("pae=%q want %q", got, want)
+	}
+}
+
+func TestSelectEvidenceProvenance(t *testing.T) {
+	both := map[string][]byte{evidenceProvenanceName: nil, evidenceProvenanceDSSE: nil}
+	statementOnly := map[string][]byte{evidenceProvenanceName: nil}
+	cases := []struct {
+		files  map[string][]byte
+		format string
+		want   string
+	}{
+		{files: both, format: "", want: evidenceProvenanceDSSE},
+		{files: both, format: "statement", want: evidenceProvenanceName},
+		{files: statementOnly, format: "", want: evidenceProvenanceName},
+		{files: statementOnly, format: "dsse", want: ""},
+		{files: map[string][]byte{}, format: "", want: ""},
+	}
+	for _, tc := range cases {
+		name, _, err := selectEvidenceProvenance(tc.files, tc.format)
+		if name != tc.want || (tc.want == "") != (err != nil) {
+			t.Fatalf("format %q: got %q (%v), want %q", tc.format, name, err, tc.want)
+		}
+	}
+}
@@ -1,4 +1,4 @@
-package main
+package experiments
 
 import (
 	"bytes"
@@ -8,22 +8,24 @@ import (
 )
 
 type evidenceReportInput struct {
-	RunID            string
-	ExecutionID      string
-	ExperimentID     string
-	DatasetID        string
-	DatasetVersionID string
-	DatasetSHA256    string
-	GitRepo          string
-	GitCommit        string
-	GitRef           string
-	ImageRef         string
-	ImageDigest      string
-	ExecutionHash    string
-	PolicyDecisions  []policyDecisionDetail
-	PolicyApprovals  []policyApprovalDetail
-	GeneratedAt      time.Time
-	GeneratedBy      string
+	RunID            string                   `json:"run_id"`
+	ExecutionID      string                   `json:"execution_id"`
+	ExperimentID     string                   `json:"experiment_id"`
+	DatasetID        string                   `json:"dataset_id"`
+	DatasetVersionID string                   `json:"dataset_version_id"`
+	DatasetSHA256    string                   `json:"dataset_sha256"`
+	Datasets         []executionLedgerDataset `json:"datasets"`
+	GitRepo          string                   `json:"git_repo"`
+	GitCommit        string                   `json:"git_commit"`
+	GitRef           string                   `json:"git_ref"`
+	ImageRef         string                   `json:"image_ref"`
+	ImageDigest      string                   `json:"image_digest"`
+	ExecutionHash    string                   `json:"execution_hash"`
+	PolicyDecisions  []policyDecisionDetail   `json:"policy_decisions"`
+	PolicyApprovals  []policyApprovalDetail   `json:"policy_approvals"`
+	Energy           *executionLedgerEnergy   `json:"energy,omitempty"`
+	GeneratedAt      time.Time                `json:"generated_at"`
+	GeneratedBy      string                   `json:"generated_by"`
 }
 
 func buildComplianceReportPDF(input evidenceReportInput) ([]byte, error) {
@@ -46,6 +48,14 @@ func buildComplianceReportPDF(input evidenceReportInput) ([]byte, error) {
 	addLine("Dataset ID", input.DatasetID)
 	addLine("Dataset Version ID", input.DatasetVersionID)
 	addLine("Dataset SHA256", input.DatasetSHA256)
+	if len(input.Datasets) > 1 {
+		lines = append(lines, fmt.Sprintf("Input datasets: %d", len(input.Datasets)))
+		for _, dataset := range input.Datasets {
+			for _, line := range wrapText(fmt.Sprintf("- %s version=%s sha256=%s", safeValue(dataset.DatasetID), safeValue(dataset.VersionID), safeValue(dataset.SHA256)), 90) {
+				lines = append(lines, line)
+			}
+		}
+	}
 	addLine("Git Repo", input.GitRepo)
 	addLine("Git Commit", input.GitCommit)
 	addLine("Git Ref", input.GitRef)
@@ -53,6 +63,16 @@ func buildComplianceReportPDF(input evidenceReportInput) ([]byte, error) {
 	addLine("Image Digest", input.ImageDigest)
 	addLine("Execution Hash", input.ExecutionHash)
 
+	if energy := input.Energy; energy != nil {
+		lines = append(lines, "")
+		lines = append(lines, "Energy footprint (estimated)")
+		addLine("Duration", (time.Duration(energy.DurationSeconds) * time.Second).String())
+		addLine("Hardware", fmt.Sprintf("%g GPU x %g W, %g CPU cores x %g W", energy.GPUs, energy.GPUTDPWatts, energy.CPUCores, energy.CPUTDPWatts))
+		addLine("Energy", fmt.Sprintf("%.3f kWh", energy.EnergyKWh))
+		addLine("Grid factor", fmt.Sprintf("%g kgCO2e/kWh (region %s)", energy.GridFactor, safeValue(energy.Region)))
+		addLine("CO2e", fmt.Sprintf("%.3f kg", energy.CO2eKg))
+	}
+
 	lines = append(lines, "")
 	lines = append(lines, fmt.Sprintf("Policy decisions: %d", len(input.PolicyDecisions)))
 	appendPolicyDecisionLines(&lines, input.PolicyDecisions, 5)
@@ -0,0 +1,309 @@
+package experiments
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/x509"
+	"database/sql"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/env"
+)
+
+const (
+	evidenceSigningModeHMAC    = "hmac"
+	evidenceSigningModeEd25519 = "ed25519"
+
+	evidenceSignatureAlgEd25519 = "ed25519"
+)
+
+type evidenceSigningConfig struct {
+	Mode string
+	// KeyFile holds a PKCS#8 PEM Ed25519 private key.
+	KeyFile string
+	// CertFile optionally holds the PEM certificate chain, leaf first.
+	CertFile string
+}
+
+func evidenceSigningConfigFromEnv() (evidenceSigningConfig, error) {
+	cfg := evidenceSigningConfig{
+		Mode:     strings.ToLower(strings.TrimSpace(env.String("ANIMUS_EVIDENCE_SIGNING_MODE", evidenceSigningModeHMAC))),
+		KeyFile:  strings.TrimSpace(env.String("ANIMUS_EVIDENCE_SIGNING_KEY_FILE", "")),
+		CertFile: strings.TrimSpace(env.String("ANIMUS_EVIDENCE_SIGNING_CERT_FILE", "")),
+	}
+	if err := cfg.Validate(); err != nil {
+		return evidenceSigningConfig{}, err
+	}
+	return cfg, nil
+}
+
+func (c evidenceSigningConfig) Validate() error {
+	switch c.Mode {
+	case evidenceSigningModeHMAC:
+		return nil
+	case evidenceSigningModeEd25519:
+		if c.KeyFile == "" {
+			return errors.New("ANIMUS_EVIDENCE_SIGNING_KEY_FILE is required when evidence signing mode is ed25519")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported evidence signing mode %q", c.Mode)
+	}
+}
+
+// evidenceSigningKey is the public half of an asymmetric evidence signing key,
+// as published for external verifiers.
+type evidenceSigningKey struct {
+	KeyID               string    `json:"key_id"`
+	Algorithm           string    `json:"algorithm"`
+	PublicKeyPEM        string    `json:"public_key_pem"`
+	CertificateChainPEM string    `json:"certificate_chain_pem,omitempty"`
+	CertificateSHA256   string    `json:"certificate_sha256,omitempty"`
+	Active              bool      `json:"active"`
+	CreatedAt           time.Time `json:"created_at"`
+}
+
+type evidenceSigningKeyListResponse struct {
+	// Mode is the signing mode of new bundles; HMAC keys are never published.
+	Mode        string               `json:"mode"`
+	ActiveKeyID string               `json:"active_key_id,omitempty"`
+	Keys        []evidenceSigningKey `json:"keys"`
+}
+
+// evidenceSigner signs bundle hashes with an Ed25519 key. A nil signer means
+// bundles are HMAC-signed with the shared secret.
+type evidenceSigner struct {
+	key     evidenceSigningKey
+	private ed25519.PrivateKey
+}
+
+func loadEvidenceSigner(cfg evidenceSigningConfig) (*evidenceSigner, error) {
+	if cfg.Mode != evidenceSigningModeEd25519 {
+		return nil, nil
+	}
+	keyPEM, err := os.ReadFile(cfg.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("read evidence signing key: %w", err)
+	}
+	var certPEM []byte
+	if cfg.CertFile != "" {
+		if certPEM, err = os.ReadFile(cfg.CertFile); err != nil {
+			return nil, fmt.Errorf("read evidence signing certificate: %w", err)
+		}
+	}
+	return newEvidenceSigner(keyPEM, certPEM)
+}
+
+func newEvidenceSigner(keyPEM []byte, certPEM []byte) (*evidenceSigner, error) {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return nil, errors.New("evidence signing key must be a PKCS#8 PEM private key")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse evidence signing key: %w", err)
+	}
+	private, ok := parsed.(ed25519.PrivateKey)
+	if !ok {
+		return nil, errors.New("evidence signing key is not an Ed25519 key")
+	}
+	public := private.Public().(ed25519.PublicKey)
+	spki, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		return nil, fmt.Errorf("marshal evidence signing public key: %w", err)
+	}
+
+	key := evidenceSigningKey{
+		KeyID:        sha256HexBytes(spki),
+		Algorithm:    evidenceSignatureAlgEd25519,
+		PublicKeyPEM: string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: spki})),
+		Active:       true,
+	}
+	if len(certPEM) > 0 {
+		leaf, chain, err := parseEvidenceCertificateChain(certPEM)
+		if err != nil {
+			return nil, err
+		}
+		leafKey, ok := leaf.PublicKey.(ed25519.PublicKey)
+		if !ok || !leafKey.Equal(public) {
+			return nil, errors.New("evidence signing certificate does not match the signing key")
+		}
+		key.CertificateChainPEM = chain
+		key.CertificateSHA256 = sha256HexBytes(leaf.Raw)
+	}
+	return &evidenceSigner{key: key, private: private}, nil
+}
+
+// parseEvidenceCertificateChain returns the leaf certificate and the chain
+// re-encoded without any non-certificate PEM blocks.
+func parseEvidenceCertificateChain(certPEM []byte) (*x509.Certificate, string, error) {
+	var (
+		leaf  *x509.Certificate
+		chain strings.Builder
+	)
+	for rest := certPEM; ; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, "", fmt.Errorf("parse evidence signing certificate: %w", err)
+		}
+		if leaf == nil {
+			leaf = cert
+		}
+		chain.Write(pem.EncodeToMemory(block))
+	}
+	if leaf == nil {
+		return nil, "", errors.New("evidence signing certificate file has no certificates")
+	}
+	return leaf, chain.String(), nil
+}
+
+// Sign signs the hex bundle hash, the same message the HMAC mode covers.
+func (s *evidenceSigner) Sign(bundleSHA string) (string, error) {
+	if strings.TrimSpace(bundleSHA) == "" {
+		return "", errors.New("bundle sha required")
+	}
+	sig := ed25519.Sign(s.private, []byte(strings.TrimSpace(bundleSHA)))
+	return base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// KeyID identifies the key as the hex SHA-256 of its DER SubjectPublicKeyInfo.
+func (s *evidenceSigner) KeyID() string {
+	return s.key.KeyID
+}
+
+func (s *evidenceSigner) manifestSigning() *evidenceManifestSigning {
+	return &evidenceManifestSigning{
+		Algorithm:         s.key.Algorithm,
+		KeyID:             s.key.KeyID,
+		PublicKeyPEM:      s.key.PublicKeyPEM,
+		CertificateSHA256: s.key.CertificateSHA256,
+		KeysPath:          "/evidence-signing-keys",
+	}
+}
+
+func verifyEvidenceEd25519(publicKeyPEM string, bundleSHA string, signature string) (bool, error) {
+	block, _ := pem.Decode([]byte(publicKeyPEM))
+	if block == nil {
+		return false, errors.New("signing key is not PEM encoded")
+	}
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return false, fmt.Errorf("parse signing key: %w", err)
+	}
+	public, ok := parsed.(ed25519.PublicKey)
+	if !ok {
+		return false, errors.New("signing key is not an Ed25519 key")
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(strings.TrimSpace(signature))
+	if err != nil {
+		return false, nil
+	}
+	return ed25519.Verify(public, []byte(strings.TrimSpace(bundleSHA)), sig), nil
+}
+
+// registerEvidenceSigningKey publishes the configured key so bundles signed
+// with it stay verifiable after the key is rotated out.
+func registerEvidenceSigningKey(ctx context.Context, db *sql.DB, signer *evidenceSigner) error {
+	if signer == nil {
+		return nil
+	}
+	_, err := db.ExecContext(
+		ctx,
+		`INSERT INTO evidence_signing_keys (
+			key_id,
+			algorithm,
+			public_key_pem,
+			certificate_chain_pem,
+			certificate_sha256
+		) VALUES ($1,$2,$3,$4,$5)
+		ON CONFLICT (key_id) DO UPDATE SET
+			certificate_chain_pem = COALESCE(EXCLUDED.certificate_chain_pem, evidence_signing_keys.certificate_chain_pem),
+			certificate_sha256 = COALESCE(EXCLUDED.certificate_sha256, evidence_signing_keys.certificate_sha256),
+			last_registered_at = now()`,
+		signer.key.KeyID,
+		signer.key.Algorithm,
+		signer.key.PublicKeyPEM,
+		nullString(signer.key.CertificateChainPEM),
+		nullString(signer.key.CertificateSHA256),
+	)
+	if err != nil {
+		return fmt.Errorf("register evidence signing key: %w", err)
+	}
+	return nil
+}
+
+func scanEvidenceSigningKey(row rowScanner) (evidenceSigningKey, error) {
+	var (
+		key       evidenceSigningKey
+		chain     sql.NullString
+		certSHA   sql.NullString
+		createdAt time.Time
+	)
+	if err := row.Scan(&key.KeyID, &key.Algorithm, &key.PublicKeyPEM, &chain, &certSHA, &createdAt); err != nil {
+		return evidenceSigningKey{}, err
+	}
+	key.CertificateChainPEM = chain.String
+	key.CertificateSHA256 = certSHA.String
+	key.CreatedAt = createdAt.UTC()
+	return key, nil
+}
+
+func getEvidenceSigningKey(ctx context.Context, db *sql.DB, keyID string) (evidenceSigningKey, error) {
+	return scanEvidenceSigningKey(db.QueryRowContext(
+		ctx,
+		`SELECT key_id, algorithm, public_key_pem, certificate_chain_pem, certificate_sha256, created_at
+		 FROM evidence_signing_keys
+		 WHERE key_id = $1`,
+		keyID,
+	))
+}
+
+func (api *experimentsAPI) handleListEvidenceSigningKeys(w http.ResponseWriter, r *http.Request) {
+	rows, err := api.db.QueryContext(
+		r.Context(),
+		`SELECT key_id, algorithm, public_key_pem, certificate_chain_pem, certificate_sha256, created_at
+		 FROM evidence_signing_keys
+		 ORDER BY created_at DESC, key_id`,
+	)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer rows.Close()
+
+	resp := evidenceSigningKeyListResponse{Mode: evidenceSigningModeHMAC, Keys: []evidenceSigningKey{}}
+	if api.evidenceSigner != nil {
+		resp.Mode = evidenceSigningModeEd25519
+		resp.ActiveKeyID = api.evidenceSigner.KeyID()
+	}
+	for rows.Next() {
+		key, err := scanEvidenceSigningKey(rows)
+		if err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+		key.Active = key.KeyID == resp.ActiveKeyID
+		resp.Keys = append(resp.Keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	api.writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,151 @@
+package experiments
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestEd25519Key(t *testing.T) (ed25519.PrivateKey, []byte) {
+	t.Helper()
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	return private, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func newTestCertificatePEM(t *testing.T, private ed25519.PrivateKey) []byte {
+	t.Helper()
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "evidence-signer"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, private.Public(), private)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestEvidenceSigningConfigValidate(t *testing.T) {
+	cases := []struct {
+		cfg     evidenceSigningConfig
+		wantErr bool
+	}{
+		{cfg: evidenceSigningConfig{Mode: evidenceSigningModeHMAC}},
+		{cfg: evidenceSigningConfig{Mode: evidenceSigningModeEd25519, KeyFile: "/keys/evidence.pem"}},
+		{cfg: evidenceSigningConfig{Mode: evidenceSigningModeEd25519}, wantErr: true},
+		{cfg: evidenceSigningConfig{Mode: "rsa"}, wantErr: true},
+	}
+	for _, tc := range cases {
+		if err := tc.cfg.Validate(); (err != nil) != tc.wantErr {
+			t.Fatalf("%+v: unexpected error %v", tc.cfg, err)
+		}
+	}
+}
+
+func TestNewEvidenceSignerWithCertificate(t *testing.T) {
+	private, keyPEM := newTestEd25519Key(t)
+	certPEM := newTestCertificatePEM(t, private)
+
+	signer, err := newEvidenceSigner(keyPEM, certPEM)
+	if err != nil {
+		t.Fatalf("new signer: %v", err)
+	}
+	if len(signer.KeyID()) != 64 || signer.key.CertificateSHA256 == "" || signer.key.CertificateChainPEM != string(certPEM) {
+		t.Fatalf("unexpected key %+v", signer.key)
+	}
+
+	other, _ := newTestEd25519Key(t)
+	if _, err := newEvidenceSigner(keyPEM, newTestCertificatePEM(t, other)); err == nil {
+		t.Fatalf("expected mismatched certificate to be rejected")
+	}
+	if _, err := newEvidenceSigner([]byte("not a key"), nil); err == nil {
+		t.Fatalf("expected invalid key to be rejected")
+	}
+}
+
+func TestEvidenceSignerRoundTrip(t *testing.T) {
+	_, keyPEM := newTestEd25519Key(t)
+	signer, err := newEvidenceSigner(keyPEM, nil)
+	if err != nil {
+		t.Fatalf("new signer: %v", err)
+	}
+	sha := strings.Repeat("ab", 32)
+	signature, err := signer.Sign(sha)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	ok, err := verifyEvidenceEd25519(signer.key.PublicKeyPEM, sha, signature)
+	if err != nil || !ok {
+		t.Fatalf("expected signature to verify: ok=%v err=%v", ok, err)
+	}
+	ok, _ = verifyEvidenceEd25519(signer.key.PublicKeyPEM, strings.Repeat("cd", 32), signature)
+	if ok {
+		t.Fatalf("signature verified for a different hash")
+	}
+}
+
+func TestVerifyEvidenceBundleEd25519(t *testing.T) {
+	_, keyPEM := newTestEd25519Key(t)
+	signer, err := newEvidenceSigner(keyPEM, nil)
+	if err != nil {
+		t.Fatalf("new signer: %v", err)
+	}
+	bundle, archive, report := buildSignedTestEvidenceBundle(t, signer, nil)
+	if bundle.SignatureAlg != evidenceSignatureAlgEd25519 || bundle.SigningKeyID != signer.KeyID() {
+		t.Fatalf("unexpected signing fields %q %q", bundle.SignatureAlg, bundle.SigningKeyID)
+	}
+
+	files, err := readEvidenceZip(archive)
+	if err != nil {
+		t.Fatalf("read zip: %v", err)
+	}
+	if !strings.Contains(string(files[evidenceManifestName]), signer.KeyID()) {
+		t.Fatalf("manifest does not name the signing key")
+	}
+
+	key := signer.key
+	verify := func(key *evidenceSigningKey) evidenceVerificationReport {
+		result, err := verifyEvidenceBundle(evidenceVerifyInput{
+			Bundle:     bundle,
+			SigningKey: key,
+			Archive:    evidenceStoredObject{Data: archive},
+			Report:     evidenceStoredObject{Data: report},
+		})
+		if err != nil {
+			t.Fatalf("verify: %v", err)
+		}
+		return result
+	}
+	if result := verify(&key); !result.Valid {
+		t.Fatalf("expected valid bundle, failed=%v", result.FailedChecks)
+	}
+	if result := verify(nil); !slices.Equal(result.FailedChecks, []string{evidenceCheckSignature}) {
+		t.Fatalf("expected signature failure for unregistered key, got %v", result.FailedChecks)
+	}
+
+	_, otherPEM := newTestEd25519Key(t)
+	other, err := newEvidenceSigner(otherPEM, nil)
+	if err != nil {
+		t.Fatalf("new signer: %v", err)
+	}
+	if result := verify(&other.key); !slices.Equal(result.FailedChecks, []string{evidenceCheckSignature}) {
+		t.Fatalf("expected signature failure for another key, got %v", result.FailedChecks)
+	}
+}
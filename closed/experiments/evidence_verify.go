@@ -0,0 +1,560 @@
+package experiments
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/envelope"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+	"github.com/minio/minio-go/v7"
+)
+
+const (
+	evidenceVerificationSchemaV1 = "animus.evidence_verification.v1"
+	evidenceReportSchemaV1       = "animus.evidence_report.v1"
+
+	evidenceManifestName = "manifest.json"
+)
+
+// Verification check names, in the order they are reported.
+const (
+	evidenceCheckIntegrity   = "integrity_sha256"
+	evidenceCheckBundleSHA   = "bundle_sha256"
+	evidenceCheckBundleSize  = "bundle_size_bytes"
+	evidenceCheckSignature   = "signature"
+	evidenceCheckManifest    = "manifest"
+	evidenceCheckFiles       = "files"
+	evidenceCheckReportSHA   = "report_sha256"
+	evidenceCheckReportInZip = "report_in_bundle"
+)
+
+var errEvidenceVerifyUnavailable = errors.New("evidence verification unavailable")
+
+type evidenceVerificationCheck struct {
+	Name     string `json:"name"`
+	OK       bool   `json:"ok"`
+	Expected string `json:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty"`
+	Detail   string `json:"detail,omitempty"`
+}
+
+type evidenceVerificationFile struct {
+	Name            string `json:"name"`
+	OK              bool   `json:"ok"`
+	ExpectedSHA256  string `json:"expected_sha256"`
+	ActualSHA256    string `json:"actual_sha256,omitempty"`
+	ExpectedSize    int64  `json:"expected_size_bytes"`
+	ActualSizeBytes int64  `json:"actual_size_bytes,omitempty"`
+	Detail          string `json:"detail,omitempty"`
+}
+
+type evidenceVerificationReport struct {
+	Schema       string                      `json:"schema"`
+	BundleID     string                      `json:"bundle_id"`
+	RunID        string                      `json:"run_id"`
+	Valid        bool                        `json:"valid"`
+	FailedChecks []string                    `json:"failed_checks"`
+	Checks       []evidenceVerificationCheck `json:"checks"`
+	Files        []evidenceVerificationFile  `json:"files"`
+	VerifiedAt   time.Time                   `json:"verified_at"`
+	VerifiedBy   string                      `json:"verified_by"`
+}
+
+// evidenceStoredObject is one object re-read from the store. Problem is set
+// instead of Data when the object is missing or cannot be decrypted; both
+// count against the bundle rather than failing the request.
+type evidenceStoredObject struct {
+	Data    []byte
+	Problem string
+}
+
+type evidenceVerifyInput struct {
+	Bundle evidenceBundle
+	Secret string
+	// SigningKey is the published key named by the bundle's signing_key_id;
+	// nil for HMAC bundles or when the key is not registered.
+	SigningKey *evidenceSigningKey
+	Archive    evidenceStoredObject
+	Report     evidenceStoredObject
+}
+
+type evidenceReportJSONResponse struct {
+	Schema       string              `json:"schema"`
+	BundleID     string              `json:"bundle_id"`
+	RunID        string              `json:"run_id"`
+	BundleSHA256 string              `json:"bundle_sha256"`
+	ReportSHA256 string              `json:"report_sha256"`
+	Report       evidenceReportInput `json:"report"`
+}
+
+func (api *experimentsAPI) handleEvidenceBundleAction(w http.ResponseWriter, r *http.Request) {
+	// ServeMux wildcards span whole segments, so {bundle_id}:verify arrives
+	// as one path value.
+	bundleID, ok := strings.CutSuffix(strings.TrimSpace(r.PathValue("bundle_id")), ":verify")
+	if !ok {
+		api.writeError(w, r, http.StatusNotFound, "not_found")
+		return
+	}
+	api.verifyEvidenceBundleRequest(w, r, bundleID)
+}
+
+func (api *experimentsAPI) verifyEvidenceBundleRequest(w http.ResponseWriter, r *http.Request, bundleID string) {
+	identity, ok := auth.IdentityFromContext(r.Context())
+	if !ok || strings.TrimSpace(identity.Subject) == "" {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	runID := strings.TrimSpace(r.PathValue("run_id"))
+	bundleID = strings.TrimSpace(bundleID)
+	if runID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "run_id_required")
+		return
+	}
+	if bundleID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "bundle_id_required")
+		return
+	}
+
+	bundle, err := api.getEvidenceBundle(r.Context(), runID, bundleID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	archive, err := api.readEvidenceObject(r.Context(), bundle, bundle.BundleObjectKey, bundle.bundleWrappedKey)
+	if err != nil {
+		api.writeEvidenceReadError(w, r, err)
+		return
+	}
+	report, err := api.readEvidenceObject(r.Context(), bundle, bundle.ReportObjectKey, bundle.reportWrappedKey)
+	if err != nil {
+		api.writeEvidenceReadError(w, r, err)
+		return
+	}
+	var signingKey *evidenceSigningKey
+	if bundle.SigningKeyID != "" {
+		key, err := getEvidenceSigningKey(r.Context(), api.db, bundle.SigningKeyID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+		if err == nil {
+			signingKey = &key
+		}
+	}
+
+	result, err := verifyEvidenceBundle(evidenceVerifyInput{
+		Bundle:     bundle,
+		Secret:     api.evidenceSigningSecret,
+		SigningKey: signingKey,
+		Archive:    archive,
+		Report:     report,
+	})
+	if err != nil {
+		api.logger.Error("evidence bundle verify failed", "error", err, "run_id", runID, "bundle_id", bundleID)
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	result.VerifiedAt = time.Now().UTC()
+	result.VerifiedBy = strings.TrimSpace(identity.Subject)
+
+	// Verification writes nothing else, so the event goes to the outbox on
+	// its own.
+	err = auditlog.Enqueue(r.Context(), api.db, auditlog.Event{
+		OccurredAt:   result.VerifiedAt,
+		Actor:        identity.Subject,
+		Action:       "evidence_bundle.verify",
+		ResourceType: "evidence_bundle",
+		ResourceID:   bundleID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload: map[string]any{
+			"service":       "experiments",
+			"run_id":        runID,
+			"valid":         result.Valid,
+			"failed_checks": result.FailedChecks,
+		},
+	})
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	api.writeJSON(w, http.StatusOK, result)
+}
+
+// handleEvidenceReportJSON rebuilds the compliance report from the ledger and
+// policy snapshot inside the bundle, so verifiers get the PDF's content
+// without parsing it.
+func (api *experimentsAPI) handleEvidenceReportJSON(w http.ResponseWriter, r *http.Request, bundle evidenceBundle) {
+	archive, err := api.readEvidenceObject(r.Context(), bundle, bundle.BundleObjectKey, bundle.bundleWrappedKey)
+	if err != nil {
+		api.writeEvidenceReadError(w, r, err)
+		return
+	}
+	if archive.Problem != "" {
+		api.writeError(w, r, http.StatusNotFound, "not_found")
+		return
+	}
+	files, err := readEvidenceZip(archive.Data)
+	if err != nil {
+		api.writeError(w, r, http.StatusUnprocessableEntity, "bundle_unreadable")
+		return
+	}
+	report, err := evidenceReportFromBundle(files)
+	if err != nil {
+		api.writeError(w, r, http.StatusUnprocessableEntity, "bundle_unreadable")
+		return
+	}
+
+	api.writeJSON(w, http.StatusOK, evidenceReportJSONResponse{
+		Schema:       evidenceReportSchemaV1,
+		BundleID:     bundle.BundleID,
+		RunID:        bundle.RunID,
+		BundleSHA256: bundle.BundleSHA256,
+		ReportSHA256: bundle.ReportSHA256,
+		Report:       report,
+	})
+}
+
+func (api *experimentsAPI) writeEvidenceReadError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, errEvidenceEncryptionFailed) {
+		api.writeError(w, r, http.StatusBadGateway, "encryption_key_unavailable")
+		return
+	}
+	api.writeError(w, r, http.StatusBadGateway, "object_store_error")
+}
+
+// readEvidenceObject downloads and decrypts one bundle object. A missing
+// object or a ciphertext that fails authentication is reported as a Problem;
+// store and key service outages are errors.
+func (api *experimentsAPI) readEvidenceObject(ctx context.Context, bundle evidenceBundle, objectKey string, wrappedKey string) (evidenceStoredObject, error) {
+	dataKey, err := api.evidenceDataKey(ctx, bundle, wrappedKey)
+	if err != nil {
+		return evidenceStoredObject{}, fmt.Errorf("%w: %s", errEvidenceEncryptionFailed, err)
+	}
+
+	obj, err := api.store.GetObject(ctx, api.storeCfg.BucketArtifacts, objectKey, minio.GetObjectOptions{})
+	if err != nil {
+		return evidenceStoredObject{}, fmt.Errorf("%w: %s", errEvidenceStoreFailed, err)
+	}
+	defer obj.Close()
+
+	data, err := io.ReadAll(obj)
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return evidenceStoredObject{Problem: "object missing from store"}, nil
+		}
+		return evidenceStoredObject{}, fmt.Errorf("%w: %s", errEvidenceStoreFailed, err)
+	}
+	if dataKey != nil {
+		plaintext, err := envelope.Open(dataKey, data)
+		if err != nil {
+			return evidenceStoredObject{Problem: "object failed decryption"}, nil
+		}
+		data = plaintext
+	}
+	return evidenceStoredObject{Data: data}, nil
+}
+
+// verifyEvidenceBundle recomputes every hash and the signature of a bundle
+// against its database row. The result is valid only if every check passes.
+func verifyEvidenceBundle(input evidenceVerifyInput) (evidenceVerificationReport, error) {
+	bundle := input.Bundle
+	if bundle.SignatureAlg == evidenceSignatureAlg && strings.TrimSpace(input.Secret) == "" {
+		return evidenceVerificationReport{}, fmt.Errorf("%w: signing secret not configured", errEvidenceVerifyUnavailable)
+	}
+
+	result := evidenceVerificationReport{
+		Schema:       evidenceVerificationSchemaV1,
+		BundleID:     bundle.BundleID,
+		RunID:        bundle.RunID,
+		FailedChecks: []string{},
+		Files:        []evidenceVerificationFile{},
+	}
+	add := func(check evidenceVerificationCheck) {
+		result.Checks = append(result.Checks, check)
+		if !check.OK {
+			result.FailedChecks = append(result.FailedChecks, check.Name)
+		}
+	}
+
+	integrity, err := integritySHA256(evidenceIntegrityInputFromBundle(bundle))
+	if err != nil {
+		return evidenceVerificationReport{}, err
+	}
+	add(hashCheck(evidenceCheckIntegrity, bundle.integritySHA256, integrity, ""))
+
+	archiveSHA := ""
+	if input.Archive.Problem == "" {
+		archiveSHA = sha256HexBytes(input.Archive.Data)
+		add(hashCheck(evidenceCheckBundleSHA, bundle.BundleSHA256, archiveSHA, ""))
+		add(hashCheck(evidenceCheckBundleSize, strconv.FormatInt(bundle.BundleSizeBytes, 10), strconv.Itoa(len(input.Archive.Data)), ""))
+	} else {
+		add(evidenceVerificationCheck{Name: evidenceCheckBundleSHA, Expected: bundle.BundleSHA256, Detail: input.Archive.Problem})
+		add(evidenceVerificationCheck{Name: evidenceCheckBundleSize, Expected: strconv.FormatInt(bundle.BundleSizeBytes, 10), Detail: input.Archive.Problem})
+	}
+
+	add(signatureCheck(bundle, input.Secret, input.SigningKey, archiveSHA))
+
+	var files map[string][]byte
+	if input.Archive.Problem == "" {
+		files, err = readEvidenceZip(input.Archive.Data)
+		if err != nil {
+			files = nil
+			add(evidenceVerificationCheck{Name: evidenceCheckManifest, Detail: "bundle is not a readable zip archive"})
+		}
+	} else {
+		add(evidenceVerificationCheck{Name: evidenceCheckManifest, Detail: input.Archive.Problem})
+	}
+	var manifestFiles map[string]evidenceManifestFile
+	if files != nil {
+		var check evidenceVerificationCheck
+		manifestFiles, check = manifestCheck(bundle, files)
+		add(check)
+	}
+	if manifestFiles != nil {
+		fileResults, check := filesCheck(manifestFiles, files)
+		result.Files = fileResults
+		add(check)
+	} else {
+		add(evidenceVerificationCheck{Name: evidenceCheckFiles, Detail: "manifest unavailable"})
+	}
+
+	if input.Report.Problem == "" {
+		add(hashCheck(evidenceCheckReportSHA, bundle.ReportSHA256, sha256HexBytes(input.Report.Data), ""))
+	} else {
+		add(evidenceVerificationCheck{Name: evidenceCheckReportSHA, Expected: bundle.ReportSHA256, Detail: input.Report.Problem})
+	}
+	if entry, ok := manifestFiles["report.pdf"]; ok {
+		add(hashCheck(evidenceCheckReportInZip, bundle.ReportSHA256, entry.SHA256, "manifest entry for report.pdf"))
+	} else {
+		add(evidenceVerificationCheck{Name: evidenceCheckReportInZip, Expected: bundle.ReportSHA256, Detail: "report.pdf not listed in manifest"})
+	}
+
+	result.Valid = len(result.FailedChecks) == 0
+	return result, nil
+}
+
+func evidenceIntegrityInputFromBundle(bundle evidenceBundle) evidenceBundleIntegrityInput {
+	input := evidenceBundleIntegrityInput{
+		BundleID:        bundle.BundleID,
+		RunID:           bundle.RunID,
+		BundleObjectKey: bundle.BundleObjectKey,
+		ReportObjectKey: bundle.ReportObjectKey,
+		BundleSHA256:    bundle.BundleSHA256,
+		BundleSizeBytes: bundle.BundleSizeBytes,
+		ReportSHA256:    bundle.ReportSHA256,
+		ReportSizeBytes: bundle.ReportSizeBytes,
+		Signature:       bundle.Signature,
+		SignatureAlg:    bundle.SignatureAlg,
+		SigningKeyID:    bundle.SigningKeyID,
+		CreatedAt:       bundle.CreatedAt.UTC(),
+		CreatedBy:       bundle.CreatedBy,
+		BundleWrapped:   bundle.bundleWrappedKey,
+		ReportWrapped:   bundle.reportWrappedKey,
+	}
+	if bundle.Encryption != nil {
+		input.EncryptionAlg = bundle.Encryption.Algorithm
+		input.EncryptionKeyID = bundle.Encryption.KeyID
+	}
+	return input
+}
+
+func hashCheck(name string, expected string, actual string, detail string) evidenceVerificationCheck {
+	return evidenceVerificationCheck{
+		Name:     name,
+		OK:       expected != "" && expected == actual,
+		Expected: expected,
+		Actual:   actual,
+		Detail:   detail,
+	}
+}
+
+// signatureCheck re-checks the signature over the recomputed archive hash,
+// falling back to the stored hash when the archive could not be read.
+func signatureCheck(bundle evidenceBundle, secret string, key *evidenceSigningKey, archiveSHA string) evidenceVerificationCheck {
+	check := evidenceVerificationCheck{Name: evidenceCheckSignature}
+	signed := archiveSHA
+	if signed == "" {
+		signed = bundle.BundleSHA256
+	}
+	switch bundle.SignatureAlg {
+	case evidenceSignatureAlg:
+		expected, err := computeEvidenceSignature(secret, signed)
+		if err != nil {
+			check.Detail = err.Error()
+			return check
+		}
+		check.OK = hmac.Equal([]byte(expected), []byte(bundle.Signature))
+		if !check.OK {
+			check.Detail = "HMAC does not match bundle contents"
+		}
+	case evidenceSignatureAlgEd25519:
+		check.Expected = bundle.SigningKeyID
+		if key == nil || key.Algorithm != evidenceSignatureAlgEd25519 {
+			check.Detail = fmt.Sprintf("signing key %q is not registered", bundle.SigningKeyID)
+			return check
+		}
+		ok, err := verifyEvidenceEd25519(key.PublicKeyPEM, signed, bundle.Signature)
+		if err != nil {
+			check.Detail = err.Error()
+			return check
+		}
+		check.OK = ok
+		if !check.OK {
+			check.Detail = "Ed25519 signature does not match bundle contents"
+		}
+	default:
+		check.Detail = fmt.Sprintf("unsupported signature_alg %q", bundle.SignatureAlg)
+	}
+	return check
+}
+
+func manifestCheck(bundle evidenceBundle, files map[string][]byte) (map[string]evidenceManifestFile, evidenceVerificationCheck) {
+	check := evidenceVerificationCheck{Name: evidenceCheckManifest}
+	raw, ok := files[evidenceManifestName]
+	if !ok {
+		check.Detail = "manifest.json missing from bundle"
+		return nil, check
+	}
+	var manifest evidenceBundleManifest
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		check.Detail = "manifest.json is not valid JSON"
+		return nil, check
+	}
+
+	var problems []string
+	if manifest.Schema != evidenceBundleSchemaV1 {
+		problems = append(problems, fmt.Sprintf("schema %q", manifest.Schema))
+	}
+	if manifest.BundleID != bundle.BundleID {
+		problems = append(problems, fmt.Sprintf("bundle_id %q", manifest.BundleID))
+	}
+	if manifest.RunID != bundle.RunID {
+		problems = append(problems, fmt.Sprintf("run_id %q", manifest.RunID))
+	}
+	switch {
+	case manifest.Signing == nil && bundle.SigningKeyID != "":
+		problems = append(problems, "missing signing block")
+	case manifest.Signing != nil && (manifest.Signing.KeyID != bundle.SigningKeyID || manifest.Signing.Algorithm != bundle.SignatureAlg):
+		problems = append(problems, fmt.Sprintf("signing key %q", manifest.Signing.KeyID))
+	}
+	listed := make(map[string]evidenceManifestFile, len(manifest.Files))
+	for _, file := range manifest.Files {
+		listed[file.Name] = file
+	}
+	for name := range files {
+		if _, ok := listed[name]; !ok && name != evidenceManifestName {
+			problems = append(problems, fmt.Sprintf("unlisted file %q", name))
+		}
+	}
+	if len(problems) > 0 {
+		check.Detail = "unexpected " + strings.Join(problems, ", ")
+		return listed, check
+	}
+	check.OK = true
+	return listed, check
+}
+
+func filesCheck(listed map[string]evidenceManifestFile, files map[string][]byte) ([]evidenceVerificationFile, evidenceVerificationCheck) {
+	names := make([]string, 0, len(listed))
+	for name := range listed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	check := evidenceVerificationCheck{Name: evidenceCheckFiles, OK: true}
+	out := make([]evidenceVerificationFile, 0, len(names))
+	mismatched := 0
+	for _, name := range names {
+		entry := listed[name]
+		file := evidenceVerificationFile{
+			Name:           name,
+			ExpectedSHA256: entry.SHA256,
+			ExpectedSize:   entry.SizeBytes,
+		}
+		data, ok := files[name]
+		if !ok {
+			file.Detail = "missing from bundle"
+		} else {
+			file.ActualSHA256 = sha256HexBytes(data)
+			file.ActualSizeBytes = int64(len(data))
+			file.OK = file.ActualSHA256 == entry.SHA256 && file.ActualSizeBytes == entry.SizeBytes
+		}
+		if !file.OK {
+			mismatched++
+		}
+		out = append(out, file)
+	}
+	if mismatched > 0 {
+		check.OK = false
+		check.Detail = fmt.Sprintf("%d of %d files do not match the manifest", mismatched, len(names))
+	}
+	return out, check
+}
+
+func readEvidenceZip(data []byte) (map[string][]byte, error) {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string][]byte, len(reader.File))
+	for _, file := range reader.File {
+		rc, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		content, err := io.ReadAll(rc)
+		_ = rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		files[file.Name] = content
+	}
+	return files, nil
+}
+
+func evidenceReportFromBundle(files map[string][]byte) (evidenceReportInput, error) {
+	var manifest evidenceBundleManifest
+	if err := json.Unmarshal(files[evidenceManifestName], &manifest); err != nil {
+		return evidenceReportInput{}, fmt.Errorf("manifest: %w", err)
+	}
+	var ledger executionLedgerExportResponse
+	if err := json.Unmarshal(files["ledger.json"], &ledger); err != nil {
+		return evidenceReportInput{}, fmt.Errorf("ledger: %w", err)
+	}
+	if len(ledger.Entries) == 0 {
+		return evidenceReportInput{}, errors.New("ledger: no entries")
+	}
+	var entry executionLedgerEntry
+	if err := json.Unmarshal(ledger.Entries[0].Entry, &entry); err != nil {
+		return evidenceReportInput{}, fmt.Errorf("ledger entry: %w", err)
+	}
+	var policies evidencePolicySnapshot
+	if err := json.Unmarshal(files["policies.json"], &policies); err != nil {
+		return evidenceReportInput{}, fmt.Errorf("policies: %w", err)
+	}
+	return buildEvidenceReportInput(manifest.RunID, ledger, entry, policies, manifest.CreatedAt, manifest.CreatedBy), nil
+}
@@ -0,0 +1,216 @@
+package experiments
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+)
+
+const testEvidenceSecret = "evidence-secret"
+
+func buildTestEvidenceBundle(t *testing.T, tamper func(files []evidenceBundleFile)) (evidenceBundle, []byte, []byte) {
+	t.Helper()
+	return buildSignedTestEvidenceBundle(t, nil, tamper)
+}
+
+func buildSignedTestEvidenceBundle(t *testing.T, signer *evidenceSigner, tamper func(files []evidenceBundleFile)) (evidenceBundle, []byte, []byte) {
+	t.Helper()
+
+	createdAt := time.Date(2026, 3, 4, 5, 6, 7, 123456000, time.UTC)
+	entry, err := json.Marshal(executionLedgerEntry{ExecutionID: "exec-1", ExperimentID: "exp-1"})
+	if err != nil {
+		t.Fatalf("marshal entry: %v", err)
+	}
+	ledger, err := json.Marshal(executionLedgerExportResponse{
+		Entries: []executionLedgerRecord{{RunID: "run-1", ExecutionHash: "hash-1", Entry: entry}},
+	})
+	if err != nil {
+		t.Fatalf("marshal ledger: %v", err)
+	}
+	report := []byte("%PDF-1.4 report")
+	files := []evidenceBundleFile{
+		{Name: "ledger.json", ContentType: "application/json", Data: ledger},
+		{Name: "policies.json", ContentType: "application/json", Data: []byte(`{"decisions":[],"approvals":[],"versions":[]}`)},
+		{Name: "report.pdf", ContentType: "application/pdf", Data: report},
+	}
+	var signing *evidenceManifestSigning
+	if signer != nil {
+		signing = signer.manifestSigning()
+	}
+	manifest, err := buildEvidenceManifest("bundle-1", "run-1", createdAt, "alice", files, signing)
+	if err != nil {
+		t.Fatalf("manifest: %v", err)
+	}
+	files = append(files, evidenceBundleFile{Name: evidenceManifestName, ContentType: "application/json", Data: manifest})
+	if tamper != nil {
+		tamper(files)
+	}
+	archive, archiveSHA, archiveSize, err := buildEvidenceZip(createdAt, files)
+	if err != nil {
+		t.Fatalf("zip: %v", err)
+	}
+	api := &experimentsAPI{evidenceSigningSecret: testEvidenceSecret, evidenceSigner: signer}
+	signature, signatureAlg, signingKeyID, err := api.signEvidenceBundle(archiveSHA)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+
+	bundle := evidenceBundle{
+		BundleID:        "bundle-1",
+		RunID:           "run-1",
+		BundleObjectKey: "runs/run-1/evidence/bundle-1/bundle.zip",
+		ReportObjectKey: "runs/run-1/evidence/bundle-1/report.pdf",
+		BundleSHA256:    archiveSHA,
+		BundleSizeBytes: archiveSize,
+		ReportSHA256:    sha256HexBytes(report),
+		ReportSizeBytes: int64(len(report)),
+		Signature:       signature,
+		SignatureAlg:    signatureAlg,
+		SigningKeyID:    signingKeyID,
+		CreatedAt:       createdAt,
+		CreatedBy:       "alice",
+	}
+	bundle.integritySHA256, err = integritySHA256(evidenceIntegrityInputFromBundle(bundle))
+	if err != nil {
+		t.Fatalf("integrity: %v", err)
+	}
+	return bundle, archive, report
+}
+
+func verifyTestBundle(t *testing.T, bundle evidenceBundle, archive, report []byte) evidenceVerificationReport {
+	t.Helper()
+	result, err := verifyEvidenceBundle(evidenceVerifyInput{
+		Bundle:  bundle,
+		Secret:  testEvidenceSecret,
+		Archive: evidenceStoredObject{Data: archive},
+		Report:  evidenceStoredObject{Data: report},
+	})
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	return result
+}
+
+func TestVerifyEvidenceBundleValid(t *testing.T) {
+	bundle, archive, report := buildTestEvidenceBundle(t, nil)
+	result := verifyTestBundle(t, bundle, archive, report)
+	if !result.Valid || len(result.FailedChecks) != 0 {
+		t.Fatalf("expected valid bundle, failed=%v checks=%+v", result.FailedChecks, result.Checks)
+	}
+	if len(result.Checks) != 8 || len(result.Files) != 3 {
+		t.Fatalf("unexpected report shape: %d checks, %d files", len(result.Checks), len(result.Files))
+	}
+}
+
+func TestVerifyEvidenceBundleDetectsTampering(t *testing.T) {
+	cases := []struct {
+		name   string
+		mutate func(bundle *evidenceBundle, archive, report *[]byte)
+		failed []string
+	}{
+		{
+			name: "archive bytes",
+			mutate: func(_ *evidenceBundle, archive, _ *[]byte) {
+				*archive = append(*archive, 0)
+			},
+			failed: []string{evidenceCheckBundleSHA, evidenceCheckBundleSize, evidenceCheckSignature},
+		},
+		{
+			name: "stored signature",
+			mutate: func(bundle *evidenceBundle, _, _ *[]byte) {
+				bundle.Signature = "forged"
+			},
+			failed: []string{evidenceCheckIntegrity, evidenceCheckSignature},
+		},
+		{
+			name: "report object",
+			mutate: func(_ *evidenceBundle, _, report *[]byte) {
+				*report = []byte("%PDF-1.4 edited")
+			},
+			failed: []string{evidenceCheckReportSHA},
+		},
+		{
+			name: "row created_by",
+			mutate: func(bundle *evidenceBundle, _, _ *[]byte) {
+				bundle.CreatedBy = "mallory"
+			},
+			failed: []string{evidenceCheckIntegrity},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			bundle, archive, report := buildTestEvidenceBundle(t, nil)
+			tc.mutate(&bundle, &archive, &report)
+			result := verifyTestBundle(t, bundle, archive, report)
+			if result.Valid || !slices.Equal(result.FailedChecks, tc.failed) {
+				t.Fatalf("expected failed %v, got %v", tc.failed, result.FailedChecks)
+			}
+		})
+	}
+}
+
+func TestVerifyEvidenceBundleDetectsRepackedFile(t *testing.T) {
+	// A repacked archive re-signed with the right secret still fails if a
+	// file no longer matches the manifest.
+	bundle, archive, report := buildTestEvidenceBundle(t, func(files []evidenceBundleFile) {
+		files[1].Data = []byte(`{"decisions":[],"approvals":[],"versions":[],"x":1}`)
+	})
+	result := verifyTestBundle(t, bundle, archive, report)
+	if result.Valid || !slices.Equal(result.FailedChecks, []string{evidenceCheckFiles}) {
+		t.Fatalf("expected files failure, got %v", result.FailedChecks)
+	}
+	for _, file := range result.Files {
+		if file.OK == (file.Name == "policies.json") {
+			t.Fatalf("unexpected file result %+v", file)
+		}
+	}
+}
+
+func TestVerifyEvidenceBundleMissingObject(t *testing.T) {
+	bundle, _, report := buildTestEvidenceBundle(t, nil)
+	result, err := verifyEvidenceBundle(evidenceVerifyInput{
+		Bundle:  bundle,
+		Secret:  testEvidenceSecret,
+		Archive: evidenceStoredObject{Problem: "object missing from store"},
+		Report:  evidenceStoredObject{Data: report},
+	})
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	want := []string{evidenceCheckBundleSHA, evidenceCheckBundleSize, evidenceCheckManifest, evidenceCheckFiles, evidenceCheckReportInZip}
+	if result.Valid || !slices.Equal(result.FailedChecks, want) {
+		t.Fatalf("expected %v, got %v", want, result.FailedChecks)
+	}
+}
+
+func TestVerifyEvidenceBundleRequiresSecret(t *testing.T) {
+	bundle, archive, report := buildTestEvidenceBundle(t, nil)
+	_, err := verifyEvidenceBundle(evidenceVerifyInput{
+		Bundle:  bundle,
+		Archive: evidenceStoredObject{Data: archive},
+		Report:  evidenceStoredObject{Data: report},
+	})
+	if err == nil {
+		t.Fatalf("expected error without signing secret")
+	}
+}
+
+func TestEvidenceReportFromBundle(t *testing.T) {
+	_, archive, _ := buildTestEvidenceBundle(t, nil)
+	files, err := readEvidenceZip(archive)
+	if err != nil {
+		t.Fatalf("read zip: %v", err)
+	}
+	report, err := evidenceReportFromBundle(files)
+	if err != nil {
+		t.Fatalf("report: %v", err)
+	}
+	if report.RunID != "run-1" || report.ExecutionID != "exec-1" || report.ExperimentID != "exp-1" ||
+		report.ExecutionHash != "hash-1" || report.GeneratedBy != "alice" {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	if !report.GeneratedAt.Equal(time.Date(2026, 3, 4, 5, 6, 7, 123456000, time.UTC)) {
+		t.Fatalf("unexpected generated_at %s", report.GeneratedAt)
+	}
+}
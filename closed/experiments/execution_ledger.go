@@ -1,4 +1,4 @@
-package main
+package experiments
 
 import (
 	"context"
@@ -15,20 +15,22 @@ const executionLedgerSchemaV1 = "animus.execution_ledger.v1"
 const executionReplaySchemaV1 = "animus.execution_replay.v1"
 
 type executionLedgerEntry struct {
-	Schema       string                  `json:"schema"`
-	LedgerID     string                  `json:"ledger_id"`
-	RunID        string                  `json:"run_id"`
-	ExecutionID  string                  `json:"execution_id"`
-	ExperimentID string                  `json:"experiment_id"`
-	Dataset      executionLedgerDataset  `json:"dataset"`
-	Git          executionLedgerGit      `json:"git"`
-	Image        executionLedgerImage    `json:"image"`
-	Executor     executionLedgerExecutor `json:"executor"`
-	Params       json.RawMessage         `json:"params"`
-	Resources    json.RawMessage         `json:"resources"`
-	Policy       executionLedgerPolicy   `json:"policy"`
-	CreatedAt    time.Time               `json:"created_at"`
-	CreatedBy    string                  `json:"created_by"`
+	Schema       string                   `json:"schema"`
+	LedgerID     string                   `json:"ledger_id"`
+	RunID        string                   `json:"run_id"`
+	ExecutionID  string                   `json:"execution_id"`
+	ExperimentID string                   `json:"experiment_id"`
+	Dataset      executionLedgerDataset   `json:"dataset"`
+	Datasets     []executionLedgerDataset `json:"datasets"`
+	Git          executionLedgerGit       `json:"git"`
+	Image        executionLedgerImage     `json:"image"`
+	Executor     executionLedgerExecutor  `json:"executor"`
+	Params       json.RawMessage          `json:"params"`
+	Resources    json.RawMessage          `json:"resources"`
+	Policy       executionLedgerPolicy    `json:"policy"`
+	Energy       *executionLedgerEnergy   `json:"energy,omitempty"`
+	CreatedAt    time.Time                `json:"created_at"`
+	CreatedBy    string                   `json:"created_by"`
 }
 
 type executionLedgerDataset struct {
@@ -86,20 +88,21 @@ type executionLedgerApproval struct {
 }
 
 type executionReplayBundle struct {
-	Schema           string          `json:"schema"`
-	RunID            string          `json:"run_id"`
-	ExperimentID     string          `json:"experiment_id"`
-	DatasetID        string          `json:"dataset_id"`
-	DatasetVersionID string          `json:"dataset_version_id"`
-	DatasetSHA256    string          `json:"dataset_sha256"`
-	GitRepo          string          `json:"git_repo,omitempty"`
-	GitCommit        string          `json:"git_commit,omitempty"`
-	GitRef           string          `json:"git_ref,omitempty"`
-	ImageRef         string          `json:"image_ref"`
-	ImageDigest      string          `json:"image_digest"`
-	Executor         string          `json:"executor"`
-	Resources        json.RawMessage `json:"resources"`
-	Params           json.RawMessage `json:"params"`
+	Schema           string                   `json:"schema"`
+	RunID            string                   `json:"run_id"`
+	ExperimentID     string                   `json:"experiment_id"`
+	DatasetID        string                   `json:"dataset_id"`
+	DatasetVersionID string                   `json:"dataset_version_id"`
+	DatasetSHA256    string                   `json:"dataset_sha256"`
+	Datasets         []executionLedgerDataset `json:"datasets"`
+	GitRepo          string                   `json:"git_repo,omitempty"`
+	GitCommit        string                   `json:"git_commit,omitempty"`
+	GitRef           string                   `json:"git_ref,omitempty"`
+	ImageRef         string                   `json:"image_ref"`
+	ImageDigest      string                   `json:"image_digest"`
+	Executor         string                   `json:"executor"`
+	Resources        json.RawMessage          `json:"resources"`
+	Params           json.RawMessage          `json:"params"`
 }
 
 func (api *experimentsAPI) insertExecutionLedgerEntry(ctx context.Context, tx *sql.Tx, runID string, executionID string) (string, bool, error) {
@@ -131,6 +134,8 @@ func (api *experimentsAPI) insertExecutionLedgerEntry(ctx context.Context, tx *s
 		createdBy        string
 		datasetID        sql.NullString
 		datasetSHA       sql.NullString
+		runStartedAt     time.Time
+		runEndedAt       sql.NullTime
 	)
 
 	err := tx.QueryRowContext(
@@ -152,7 +157,9 @@ func (api *experimentsAPI) insertExecutionLedgerEntry(ctx context.Context, tx *s
 				e.created_at,
 				e.created_by,
 				v.dataset_id,
-				v.content_sha256
+				v.content_sha256,
+				r.started_at,
+				r.ended_at
 		 FROM experiment_runs r
 		 JOIN experiment_run_executions e ON e.run_id = r.run_id
 		 LEFT JOIN dataset_versions v ON v.version_id = r.dataset_version_id
@@ -178,6 +185,8 @@ func (api *experimentsAPI) insertExecutionLedgerEntry(ctx context.Context, tx *s
 		&createdBy,
 		&datasetID,
 		&datasetSHA,
+		&runStartedAt,
+		&runEndedAt,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -208,6 +217,23 @@ func (api *experimentsAPI) insertExecutionLedgerEntry(ctx context.Context, tx *s
 	if imageDigest == "" {
 		return "", false, errors.New("image digest missing")
 	}
+	primaryDataset := executionLedgerDataset{
+		DatasetID: datasetIDValue,
+		VersionID: datasetVersionID,
+		SHA256:    datasetSHAValue,
+	}
+	datasets, err := fetchRunDatasets(ctx, tx, runID)
+	if err != nil {
+		return "", false, err
+	}
+	if len(datasets) == 0 {
+		datasets = []executionLedgerDataset{primaryDataset}
+	}
+	for _, dataset := range datasets {
+		if dataset.VersionID == "" || dataset.SHA256 == "" {
+			return "", false, errors.New("dataset metadata missing")
+		}
+	}
 
 	decisions, err := fetchExecutionLedgerDecisions(ctx, tx, runID)
 	if err != nil {
@@ -220,6 +246,10 @@ func (api *experimentsAPI) insertExecutionLedgerEntry(ctx context.Context, tx *s
 
 	paramsJSON := normalizeJSON(paramsRaw)
 	resourcesJSON := normalizeJSON(resourcesRaw)
+	var runEnded *time.Time
+	if runEndedAt.Valid {
+		runEnded = &runEndedAt.Time
+	}
 
 	ledgerID := uuid.NewString()
 	entry := executionLedgerEntry{
@@ -228,11 +258,8 @@ func (api *experimentsAPI) insertExecutionLedgerEntry(ctx context.Context, tx *s
 		RunID:        runID,
 		ExecutionID:  executionID,
 		ExperimentID: experimentID,
-		Dataset: executionLedgerDataset{
-			DatasetID: datasetIDValue,
-			VersionID: datasetVersionID,
-			SHA256:    datasetSHAValue,
-		},
+		Dataset:      primaryDataset,
+		Datasets:     datasets,
 		Git: executionLedgerGit{
 			Repo:   gitRepo,
 			Commit: gitCommit,
@@ -255,6 +282,7 @@ func (api *experimentsAPI) insertExecutionLedgerEntry(ctx context.Context, tx *s
 			Decisions: decisions,
 			Approvals: approvals,
 		},
+		Energy:    api.energy.estimate(resourcesJSON, runStartedAt, runEnded),
 		CreatedAt: createdAt.UTC(),
 		CreatedBy: createdBy,
 	}
@@ -271,6 +299,7 @@ func (api *experimentsAPI) insertExecutionLedgerEntry(ctx context.Context, tx *s
 		DatasetID:        datasetIDValue,
 		DatasetVersionID: datasetVersionID,
 		DatasetSHA256:    datasetSHAValue,
+		Datasets:         datasets,
 		GitRepo:          gitRepo,
 		GitCommit:        gitCommit,
 		GitRef:           gitRef,
@@ -1,4 +1,4 @@
-package main
+package experiments
 
 import (
 	"database/sql"
@@ -8,6 +8,8 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
 )
 
 type executionLedgerRecord struct {
@@ -29,7 +31,7 @@ type executionLedgerExportResponse struct {
 
 func (api *experimentsAPI) handleListExecutionLedger(w http.ResponseWriter, r *http.Request) {
 	runID := strings.TrimSpace(r.URL.Query().Get("run_id"))
-	limit := clampInt(parseIntQuery(r, "limit", 100), 1, 500)
+	limit := httpapi.Limit(r, 100, 500)
 
 	fromTime, fromOk, err := parseTimeQuery(r, "from")
 	if err != nil {
@@ -0,0 +1,546 @@
+package experiments
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/buildinfo"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+	"github.com/animus-labs/animus-go/closed/internal/repo/postgres"
+	"github.com/minio/minio-go/v7"
+)
+
+const experimentArchiveSchema = "animus.experiment-export/v1"
+
+// Files of an experiment archive. JSONL files hold one record per line;
+// artifact bodies, when included, live under experimentArchiveBlobPrefix
+// named by their SHA-256.
+const (
+	experimentArchiveManifestName   = "manifest.json"
+	experimentArchiveExperimentName = "experiment.json"
+	experimentArchiveRunsName       = "runs.jsonl"
+	experimentArchiveMetricsName    = "metrics.jsonl"
+	experimentArchiveEventsName     = "events.jsonl"
+	experimentArchiveDecisionsName  = "policy_decisions.jsonl"
+	experimentArchiveConfigsName    = "config_manifests.jsonl"
+	experimentArchiveArtifactsName  = "artifacts.jsonl"
+	experimentArchiveBlobPrefix     = "artifacts/sha256/"
+	experimentArchiveContentTypeZip = "application/zip"
+)
+
+type experimentExportRequest struct {
+	IncludeArtifacts bool `json:"include_artifacts"`
+}
+
+type experimentArchiveFile struct {
+	Name      string `json:"name"`
+	SHA256    string `json:"sha256"`
+	SizeBytes int64  `json:"size_bytes"`
+	Records   int    `json:"records,omitempty"`
+}
+
+// experimentArchiveManifest is written last and lists every other file with
+// its digest, so a truncated or edited archive is rejected on import.
+type experimentArchiveManifest struct {
+	Schema           string                  `json:"schema"`
+	ExperimentID     string                  `json:"experiment_id"`
+	ProjectID        string                  `json:"project_id"`
+	ExportedAt       time.Time               `json:"exported_at"`
+	ExportedBy       string                  `json:"exported_by"`
+	SourceVersion    string                  `json:"source_version"`
+	IncludeArtifacts bool                    `json:"include_artifacts"`
+	Files            []experimentArchiveFile `json:"files"`
+}
+
+type experimentArchiveExperiment struct {
+	ExperimentID    string          `json:"experiment_id"`
+	ProjectID       string          `json:"project_id"`
+	Name            string          `json:"name"`
+	Description     string          `json:"description,omitempty"`
+	Metadata        json.RawMessage `json:"metadata"`
+	CreatedAt       time.Time       `json:"created_at"`
+	CreatedBy       string          `json:"created_by"`
+	IntegritySHA256 string          `json:"integrity_sha256"`
+}
+
+type experimentArchiveRun struct {
+	RunID             string          `json:"run_id"`
+	Status            string          `json:"status"`
+	StartedAt         time.Time       `json:"started_at"`
+	EndedAt           *time.Time      `json:"ended_at,omitempty"`
+	GitRepo           string          `json:"git_repo,omitempty"`
+	GitCommit         string          `json:"git_commit,omitempty"`
+	GitRef            string          `json:"git_ref,omitempty"`
+	Params            json.RawMessage `json:"params"`
+	Metrics           json.RawMessage `json:"metrics"`
+	DatasetVersionIDs []string        `json:"dataset_version_ids"`
+	IntegritySHA256   string          `json:"integrity_sha256"`
+}
+
+type experimentArchiveMetric struct {
+	RunID      string          `json:"run_id"`
+	RecordedAt time.Time       `json:"recorded_at"`
+	RecordedBy string          `json:"recorded_by"`
+	Step       int64           `json:"step"`
+	Name       string          `json:"name"`
+	Value      float64         `json:"value"`
+	Metadata   json.RawMessage `json:"metadata"`
+}
+
+type experimentArchiveEvent struct {
+	RunID      string          `json:"run_id"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Actor      string          `json:"actor"`
+	Level      string          `json:"level"`
+	Message    string          `json:"message"`
+	Metadata   json.RawMessage `json:"metadata"`
+}
+
+type experimentArchiveDecision struct {
+	DecisionID      string          `json:"decision_id"`
+	RunID           string          `json:"run_id"`
+	PolicyID        string          `json:"policy_id"`
+	PolicyVersionID string          `json:"policy_version_id"`
+	PolicySHA256    string          `json:"policy_sha256"`
+	Context         json.RawMessage `json:"context"`
+	ContextSHA256   string          `json:"context_sha256"`
+	Decision        string          `json:"decision"`
+	RuleID          string          `json:"rule_id,omitempty"`
+	Reason          string          `json:"reason,omitempty"`
+	BundleRevision  string          `json:"bundle_revision,omitempty"`
+	CreatedAt       time.Time       `json:"created_at"`
+	CreatedBy       string          `json:"created_by"`
+	IntegritySHA256 string          `json:"integrity_sha256"`
+}
+
+// experimentArchiveArtifact references an artifact by content hash.
+// Included reports whether its body is in the archive; without it, an import
+// can only restore the artifact when the target already holds that blob.
+type experimentArchiveArtifact struct {
+	ArtifactID  string          `json:"artifact_id"`
+	RunID       string          `json:"run_id"`
+	Kind        string          `json:"kind"`
+	Name        string          `json:"name,omitempty"`
+	Filename    string          `json:"filename,omitempty"`
+	ContentType string          `json:"content_type,omitempty"`
+	SHA256      string          `json:"sha256"`
+	SizeBytes   int64           `json:"size_bytes"`
+	Metadata    json.RawMessage `json:"metadata"`
+	CreatedAt   time.Time       `json:"created_at"`
+	CreatedBy   string          `json:"created_by"`
+	Included    bool            `json:"included"`
+
+	objectKey string
+}
+
+// experimentArchiveWriter writes archive entries and records their digests
+// for the manifest.
+type experimentArchiveWriter struct {
+	zw       *zip.Writer
+	modified time.Time
+	files    []experimentArchiveFile
+}
+
+func newExperimentArchiveWriter(w io.Writer, modified time.Time) *experimentArchiveWriter {
+	return &experimentArchiveWriter{zw: zip.NewWriter(w), modified: modified}
+}
+
+// writeFile adds name with the content fill writes; fill returns the number
+// of records written, zero for non-record files.
+func (a *experimentArchiveWriter) writeFile(name string, method uint16, fill func(io.Writer) (int, error)) error {
+	entry, err := a.zw.CreateHeader(&zip.FileHeader{Name: name, Method: method, Modified: a.modified})
+	if err != nil {
+		return err
+	}
+	hasher := sha256.New()
+	counter := &countingWriter{}
+	records, err := fill(io.MultiWriter(entry, hasher, counter))
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	a.files = append(a.files, experimentArchiveFile{
+		Name:      name,
+		SHA256:    hex.EncodeToString(hasher.Sum(nil)),
+		SizeBytes: counter.n,
+		Records:   records,
+	})
+	return nil
+}
+
+// finish writes the manifest listing the files written so far and closes
+// the archive.
+func (a *experimentArchiveWriter) finish(manifest experimentArchiveManifest) error {
+	manifest.Files = a.files
+	payload, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	entry, err := a.zw.CreateHeader(&zip.FileHeader{Name: experimentArchiveManifestName, Method: zip.Deflate, Modified: a.modified})
+	if err != nil {
+		return err
+	}
+	if _, err := entry.Write(payload); err != nil {
+		return err
+	}
+	return a.zw.Close()
+}
+
+type countingWriter struct{ n int64 }
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	c.n += int64(len(p))
+	return len(p), nil
+}
+
+func (api *experimentsAPI) handleExperimentAction(w http.ResponseWriter, r *http.Request) {
+	// ServeMux wildcards span whole segments, so {experiment_id}:export
+	// arrives as one path value.
+	experimentID, ok := strings.CutSuffix(strings.TrimSpace(r.PathValue("experiment_id")), ":export")
+	if !ok {
+		api.writeError(w, r, http.StatusNotFound, "not_found")
+		return
+	}
+	api.exportExperiment(w, r, experimentID)
+}
+
+func (api *experimentsAPI) exportExperiment(w http.ResponseWriter, r *http.Request, experimentID string) {
+	identity, ok := auth.IdentityFromContext(r.Context())
+	if !ok || strings.TrimSpace(identity.Subject) == "" {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	projectID, ok := auth.ProjectIDFromContext(r.Context())
+	if !ok || strings.TrimSpace(projectID) == "" {
+		api.writeError(w, r, http.StatusBadRequest, "project_id_required")
+		return
+	}
+	if experimentID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "experiment_id_required")
+		return
+	}
+	var req experimentExportRequest
+	if err := httpapi.DecodeJSON(r, &req); err != nil && !errors.Is(err, io.EOF) {
+		httpapi.WriteDecodeError(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+	// The export is audited together with the reads it is built from.
+	tx, err := api.db.BeginTx(ctx, nil)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+	exp, err := loadExperimentArchiveExperiment(ctx, api.runStore(tx), projectID, experimentID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	artifacts, err := loadExperimentArchiveArtifacts(ctx, postgres.NewExperimentArchiveStore(tx), experimentID)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	now := time.Now().UTC()
+	if err := auditlog.Enqueue(ctx, tx, auditlog.Event{
+		OccurredAt:   now,
+		Actor:        identity.Subject,
+		Action:       "experiment.export",
+		ResourceType: "experiment",
+		ResourceID:   experimentID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload: map[string]any{
+			"service":           "experiments",
+			"project_id":        projectID,
+			"experiment_id":     experimentID,
+			"include_artifacts": req.IncludeArtifacts,
+			"artifacts":         len(artifacts),
+		},
+	}); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "audit_failed")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	// The archive streams straight into the response, so failures past this
+	// point can only truncate it; the manifest is missing from a truncated
+	// archive and the import rejects it.
+	w.Header().Set("Content-Type", experimentArchiveContentTypeZip)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "experiment-"+experimentID+".zip"))
+	w.WriteHeader(http.StatusOK)
+
+	archive := newExperimentArchiveWriter(w, now)
+	if err := api.writeExperimentArchive(ctx, archive, exp, artifacts, req.IncludeArtifacts); err != nil {
+		if api.logger != nil {
+			api.logger.Warn("experiment export failed", "experiment_id", experimentID, "error", err)
+		}
+		return
+	}
+	if err := archive.finish(experimentArchiveManifest{
+		Schema:           experimentArchiveSchema,
+		ExperimentID:     experimentID,
+		ProjectID:        projectID,
+		ExportedAt:       now,
+		ExportedBy:       identity.Subject,
+		SourceVersion:    buildinfo.Get().Version,
+		IncludeArtifacts: req.IncludeArtifacts,
+	}); err != nil && api.logger != nil {
+		api.logger.Warn("experiment export failed", "experiment_id", experimentID, "error", err)
+	}
+}
+
+func (api *experimentsAPI) writeExperimentArchive(ctx context.Context, archive *experimentArchiveWriter, exp experimentArchiveExperiment, artifacts []experimentArchiveArtifact, includeArtifacts bool) error {
+	if err := archive.writeFile(experimentArchiveExperimentName, zip.Deflate, func(w io.Writer) (int, error) {
+		return 0, json.NewEncoder(w).Encode(exp)
+	}); err != nil {
+		return err
+	}
+	jsonl := []struct {
+		name  string
+		write func(context.Context, *postgres.ExperimentArchiveStore, string, *json.Encoder) (int, error)
+	}{
+		{experimentArchiveRunsName, writeExperimentArchiveRuns},
+		{experimentArchiveMetricsName, writeExperimentArchiveMetrics},
+		{experimentArchiveEventsName, writeExperimentArchiveEvents},
+		{experimentArchiveDecisionsName, writeExperimentArchiveDecisions},
+		{experimentArchiveConfigsName, writeExperimentArchiveConfigs},
+	}
+	archives := postgres.NewExperimentArchiveStore(api.db)
+	for _, file := range jsonl {
+		if err := archive.writeFile(file.name, zip.Deflate, func(w io.Writer) (int, error) {
+			return file.write(ctx, archives, exp.ExperimentID, json.NewEncoder(w))
+		}); err != nil {
+			return err
+		}
+	}
+
+	if includeArtifacts {
+		written := make(map[string]bool)
+		for i := range artifacts {
+			artifact := &artifacts[i]
+			if written[artifact.SHA256] {
+				artifact.Included = true
+				continue
+			}
+			included, err := api.writeExperimentArchiveBlob(ctx, archive, *artifact)
+			if err != nil {
+				return err
+			}
+			artifact.Included = included
+			written[artifact.SHA256] = included
+		}
+	}
+	return archive.writeFile(experimentArchiveArtifactsName, zip.Deflate, func(w io.Writer) (int, error) {
+		enc := json.NewEncoder(w)
+		for _, artifact := range artifacts {
+			if err := enc.Encode(artifact); err != nil {
+				return 0, err
+			}
+		}
+		return len(artifacts), nil
+	})
+}
+
+// writeExperimentArchiveBlob copies one artifact body into the archive. A
+// body the store no longer holds, e.g. after retention, is left out rather
+// than failing the export.
+func (api *experimentsAPI) writeExperimentArchiveBlob(ctx context.Context, archive *experimentArchiveWriter, artifact experimentArchiveArtifact) (bool, error) {
+	obj, err := api.store.GetObject(ctx, api.storeCfg.BucketArtifacts, artifact.objectKey, minio.GetObjectOptions{})
+	if err != nil {
+		return false, err
+	}
+	defer obj.Close()
+	if _, err := obj.Stat(); err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return false, nil
+		}
+		return false, err
+	}
+	err = archive.writeFile(experimentArchiveBlobPrefix+artifact.SHA256, zip.Store, func(w io.Writer) (int, error) {
+		_, err := io.Copy(w, obj)
+		return 0, err
+	})
+	return err == nil, err
+}
+
+func loadExperimentArchiveExperiment(ctx context.Context, runs repo.RunStore, projectID, experimentID string) (experimentArchiveExperiment, error) {
+	record, err := runs.GetExperiment(ctx, projectID, experimentID)
+	if err != nil {
+		return experimentArchiveExperiment{}, err
+	}
+	return experimentArchiveExperiment{
+		ExperimentID:    record.ExperimentID,
+		ProjectID:       record.ProjectID,
+		Name:            record.Name,
+		Description:     record.Description,
+		Metadata:        record.Metadata,
+		CreatedAt:       record.CreatedAt.UTC(),
+		CreatedBy:       record.CreatedBy,
+		IntegritySHA256: record.IntegritySHA256,
+	}, nil
+}
+
+func loadExperimentArchiveArtifacts(ctx context.Context, archives *postgres.ExperimentArchiveStore, experimentID string) ([]experimentArchiveArtifact, error) {
+	records, err := archives.ListArtifacts(ctx, experimentID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]experimentArchiveArtifact, 0, len(records))
+	for _, record := range records {
+		out = append(out, experimentArchiveArtifact{
+			ArtifactID:  record.ArtifactID,
+			RunID:       record.RunID,
+			Kind:        record.Kind,
+			Name:        record.Name,
+			Filename:    record.Filename,
+			ContentType: record.ContentType,
+			SHA256:      record.SHA256,
+			SizeBytes:   record.SizeBytes,
+			Metadata:    record.Metadata,
+			CreatedAt:   record.CreatedAt,
+			CreatedBy:   record.CreatedBy,
+			objectKey:   record.ObjectKey,
+		})
+	}
+	return out, nil
+}
+
+func writeExperimentArchiveRuns(ctx context.Context, archives *postgres.ExperimentArchiveStore, experimentID string, enc *json.Encoder) (int, error) {
+	datasets, err := archives.RunDatasetVersions(ctx, experimentID)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	err = archives.EachRun(ctx, experimentID, func(record postgres.ExperimentArchiveRunRecord) error {
+		run := experimentArchiveRun{
+			RunID:             record.RunID,
+			Status:            record.Status,
+			StartedAt:         record.StartedAt,
+			EndedAt:           record.EndedAt,
+			GitRepo:           record.GitRepo,
+			GitCommit:         record.GitCommit,
+			GitRef:            record.GitRef,
+			Params:            record.Params,
+			Metrics:           record.Metrics,
+			DatasetVersionIDs: runDatasetVersionIDs(record.DatasetVersionID, datasets[record.RunID]),
+			IntegritySHA256:   record.IntegritySHA256,
+		}
+		if err := enc.Encode(run); err != nil {
+			return err
+		}
+		count++
+		return nil
+	})
+	return count, err
+}
+
+func writeExperimentArchiveMetrics(ctx context.Context, archives *postgres.ExperimentArchiveStore, experimentID string, enc *json.Encoder) (int, error) {
+	count := 0
+	err := archives.EachMetric(ctx, experimentID, func(record postgres.ExperimentArchiveMetricRecord) error {
+		if err := enc.Encode(experimentArchiveMetric{
+			RunID:      record.RunID,
+			RecordedAt: record.RecordedAt,
+			RecordedBy: record.RecordedBy,
+			Step:       record.Step,
+			Name:       record.Name,
+			Value:      record.Value,
+			Metadata:   record.Metadata,
+		}); err != nil {
+			return err
+		}
+		count++
+		return nil
+	})
+	return count, err
+}
+
+func writeExperimentArchiveEvents(ctx context.Context, archives *postgres.ExperimentArchiveStore, experimentID string, enc *json.Encoder) (int, error) {
+	count := 0
+	err := archives.EachEvent(ctx, experimentID, func(record repo.RunEventRecord) error {
+		if err := enc.Encode(experimentArchiveEvent{
+			RunID:      record.RunID,
+			OccurredAt: record.OccurredAt,
+			Actor:      record.Actor,
+			Level:      record.Level,
+			Message:    record.Message,
+			Metadata:   record.Metadata,
+		}); err != nil {
+			return err
+		}
+		count++
+		return nil
+	})
+	return count, err
+}
+
+func writeExperimentArchiveDecisions(ctx context.Context, archives *postgres.ExperimentArchiveStore, experimentID string, enc *json.Encoder) (int, error) {
+	count := 0
+	err := archives.EachDecision(ctx, experimentID, func(record postgres.ExperimentArchiveDecisionRecord) error {
+		if err := enc.Encode(experimentArchiveDecision{
+			DecisionID:      record.DecisionID,
+			RunID:           record.RunID,
+			PolicyID:        record.PolicyID,
+			PolicyVersionID: record.PolicyVersionID,
+			PolicySHA256:    record.PolicySHA256,
+			Context:         record.Context,
+			ContextSHA256:   record.ContextSHA256,
+			Decision:        record.Decision,
+			RuleID:          record.RuleID,
+			Reason:          record.Reason,
+			BundleRevision:  record.BundleRevision,
+			CreatedAt:       record.CreatedAt,
+			CreatedBy:       record.CreatedBy,
+			IntegritySHA256: record.IntegritySHA256,
+		}); err != nil {
+			return err
+		}
+		count++
+		return nil
+	})
+	return count, err
+}
+
+// writeExperimentArchiveConfigs exports the signed run config manifests
+// with their exact bytes, so the signatures stay checkable.
+func writeExperimentArchiveConfigs(ctx context.Context, archives *postgres.ExperimentArchiveStore, experimentID string, enc *json.Encoder) (int, error) {
+	count := 0
+	err := archives.EachConfig(ctx, experimentID, func(record postgres.ExperimentArchiveConfigRecord) error {
+		if err := enc.Encode(runConfigManifestRecord{
+			RunID:        record.RunID,
+			Manifest:     json.RawMessage(record.Manifest),
+			SHA256:       record.SHA256,
+			Signature:    record.Signature,
+			SignatureAlg: record.SignatureAlg,
+			SigningKeyID: record.SigningKeyID,
+			CreatedAt:    record.CreatedAt,
+		}); err != nil {
+			return err
+		}
+		count++
+		return nil
+	})
+	return count, err
+}
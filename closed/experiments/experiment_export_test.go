@@ -0,0 +1,79 @@
+package experiments
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+func buildTestExperimentArchive(t *testing.T, runsSHA256 string, extra func(*zip.Writer)) *zip.Reader {
+	t.Helper()
+	var buf bytes.Buffer
+	archive := newExperimentArchiveWriter(&buf, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	if err := archive.writeFile(experimentArchiveExperimentName, zip.Deflate, func(w io.Writer) (int, error) {
+		return 1, json.NewEncoder(w).Encode(experimentArchiveExperiment{ExperimentID: "exp-1", Name: "baseline"})
+	}); err != nil {
+		t.Fatalf("write experiment: %v", err)
+	}
+	if err := archive.writeFile(experimentArchiveRunsName, zip.Deflate, func(w io.Writer) (int, error) {
+		_, err := io.WriteString(w, `{"run_id":"run-1"}`+"\n")
+		return 1, err
+	}); err != nil {
+		t.Fatalf("write runs: %v", err)
+	}
+	if extra != nil {
+		extra(archive.zw)
+	}
+	// A listed digest that differs from the content stands in for tampering.
+	if runsSHA256 != "" {
+		archive.files[1].SHA256 = runsSHA256
+	}
+	if err := archive.finish(experimentArchiveManifest{Schema: experimentArchiveSchema, ExperimentID: "exp-1"}); err != nil {
+		t.Fatalf("finish: %v", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("zip reader: %v", err)
+	}
+	return zr
+}
+
+func TestOpenExperimentArchiveRoundTrip(t *testing.T) {
+	archive, err := openExperimentArchive(buildTestExperimentArchive(t, "", nil))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if archive.Manifest.ExperimentID != "exp-1" || len(archive.Manifest.Files) != 2 {
+		t.Fatalf("manifest = %+v", archive.Manifest)
+	}
+	var runIDs []string
+	if err := eachExperimentArchiveRecord(archive, experimentArchiveRunsName, func(run experimentArchiveRun) error {
+		runIDs = append(runIDs, run.RunID)
+		return nil
+	}); err != nil {
+		t.Fatalf("runs: %v", err)
+	}
+	if len(runIDs) != 1 || runIDs[0] != "run-1" {
+		t.Fatalf("run ids = %v", runIDs)
+	}
+}
+
+func TestOpenExperimentArchiveRejectsMismatch(t *testing.T) {
+	_, err := openExperimentArchive(buildTestExperimentArchive(t, sha256HexBytes([]byte("other")), nil))
+	var mismatch experimentArchiveChecksumError
+	if !errors.As(err, &mismatch) || mismatch.Name != experimentArchiveRunsName {
+		t.Fatalf("err = %v, want checksum mismatch on runs", err)
+	}
+
+	_, err = openExperimentArchive(buildTestExperimentArchive(t, "", func(zw *zip.Writer) {
+		w, _ := zw.Create("notes.txt")
+		_, _ = io.WriteString(w, "unlisted")
+	}))
+	if !errors.Is(err, errExperimentArchiveInvalid) {
+		t.Fatalf("err = %v, want invalid archive", err)
+	}
+}
@@ -0,0 +1,850 @@
+package experiments
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	"github.com/animus-labs/animus-go/closed/internal/platform/lineageevent"
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+	"github.com/animus-labs/animus-go/closed/internal/repo/postgres"
+	"github.com/google/uuid"
+	"github.com/minio/minio-go/v7"
+)
+
+const (
+	// maxExperimentImportBytes bounds the uploaded archive.
+	maxExperimentImportBytes = 4 << 30
+	// maxExperimentArchiveBytes bounds the content of an archive once
+	// uncompressed, so a small upload cannot inflate without limit.
+	maxExperimentArchiveBytes = 16 << 30
+	// maxExperimentArchiveJSONBytes bounds the entries read whole: the
+	// manifest and experiment.json.
+	maxExperimentArchiveJSONBytes = 16 << 20
+)
+
+var errExperimentArchiveInvalid = errors.New("invalid experiment archive")
+
+// experimentArchiveChecksumError names the archive file whose content does
+// not match the manifest.
+type experimentArchiveChecksumError struct{ Name string }
+
+func (e experimentArchiveChecksumError) Error() string {
+	return "experiment archive checksum mismatch: " + e.Name
+}
+
+// experimentArchive is an archive whose files all matched its manifest.
+type experimentArchive struct {
+	Manifest       experimentArchiveManifest
+	ManifestSHA256 string
+	files          map[string]*zip.File
+}
+
+// openExperimentArchive reads the manifest and checks every listed file
+// against it. Files the manifest does not list are rejected, so nothing
+// unverified is imported.
+func openExperimentArchive(zr *zip.Reader) (experimentArchive, error) {
+	entries := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		if _, dup := entries[f.Name]; dup {
+			return experimentArchive{}, fmt.Errorf("%w: duplicate entry %s", errExperimentArchiveInvalid, f.Name)
+		}
+		entries[f.Name] = f
+	}
+	var total uint64
+	for _, f := range zr.File {
+		total += f.UncompressedSize64
+		if total > maxExperimentArchiveBytes || total < f.UncompressedSize64 {
+			return experimentArchive{}, fmt.Errorf("%w: content exceeds %d bytes", errExperimentArchiveInvalid, int64(maxExperimentArchiveBytes))
+		}
+	}
+	manifestFile, ok := entries[experimentArchiveManifestName]
+	if !ok {
+		return experimentArchive{}, fmt.Errorf("%w: %s missing", errExperimentArchiveInvalid, experimentArchiveManifestName)
+	}
+	payload, err := readExperimentArchiveEntry(manifestFile)
+	if err != nil {
+		return experimentArchive{}, fmt.Errorf("%w: %v", errExperimentArchiveInvalid, err)
+	}
+	var manifest experimentArchiveManifest
+	if err := json.Unmarshal(payload, &manifest); err != nil {
+		return experimentArchive{}, fmt.Errorf("%w: manifest: %v", errExperimentArchiveInvalid, err)
+	}
+	if manifest.Schema != experimentArchiveSchema {
+		return experimentArchive{}, fmt.Errorf("%w: unsupported schema %q", errExperimentArchiveInvalid, manifest.Schema)
+	}
+
+	archive := experimentArchive{
+		Manifest:       manifest,
+		ManifestSHA256: sha256HexBytes(payload),
+		files:          make(map[string]*zip.File, len(manifest.Files)),
+	}
+	for _, listed := range manifest.Files {
+		f, ok := entries[listed.Name]
+		if !ok {
+			return experimentArchive{}, experimentArchiveChecksumError{Name: listed.Name}
+		}
+		if _, dup := archive.files[listed.Name]; dup {
+			return experimentArchive{}, fmt.Errorf("%w: %s listed twice", errExperimentArchiveInvalid, listed.Name)
+		}
+		if listed.SizeBytes < 0 || f.UncompressedSize64 != uint64(listed.SizeBytes) {
+			return experimentArchive{}, experimentArchiveChecksumError{Name: listed.Name}
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return experimentArchive{}, fmt.Errorf("%w: %v", errExperimentArchiveInvalid, err)
+		}
+		// One byte past the declared size is enough to tell a longer entry.
+		hasher := sha256.New()
+		size, err := io.Copy(hasher, io.LimitReader(rc, listed.SizeBytes+1))
+		_ = rc.Close()
+		if err != nil {
+			return experimentArchive{}, fmt.Errorf("%w: %s: %v", errExperimentArchiveInvalid, listed.Name, err)
+		}
+		if size != listed.SizeBytes || hex.EncodeToString(hasher.Sum(nil)) != strings.ToLower(listed.SHA256) {
+			return experimentArchive{}, experimentArchiveChecksumError{Name: listed.Name}
+		}
+		// Artifact bodies are named by their digest, which imports rely on.
+		if blobSHA, ok := strings.CutPrefix(listed.Name, experimentArchiveBlobPrefix); ok && blobSHA != strings.ToLower(listed.SHA256) {
+			return experimentArchive{}, experimentArchiveChecksumError{Name: listed.Name}
+		}
+		archive.files[listed.Name] = f
+	}
+	for name := range entries {
+		if _, ok := archive.files[name]; !ok && name != experimentArchiveManifestName {
+			return experimentArchive{}, fmt.Errorf("%w: %s not in manifest", errExperimentArchiveInvalid, name)
+		}
+	}
+	for _, required := range []string{experimentArchiveExperimentName, experimentArchiveRunsName} {
+		if _, ok := archive.files[required]; !ok {
+			return experimentArchive{}, fmt.Errorf("%w: %s missing", errExperimentArchiveInvalid, required)
+		}
+	}
+	return archive, nil
+}
+
+// readExperimentArchiveEntry reads a JSON entry whole, up to its declared
+// size and maxExperimentArchiveJSONBytes.
+func readExperimentArchiveEntry(f *zip.File) ([]byte, error) {
+	if f.UncompressedSize64 > maxExperimentArchiveJSONBytes {
+		return nil, fmt.Errorf("%s exceeds %d bytes", f.Name, maxExperimentArchiveJSONBytes)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	payload, err := io.ReadAll(io.LimitReader(rc, int64(f.UncompressedSize64)+1))
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(payload)) > f.UncompressedSize64 {
+		return nil, fmt.Errorf("%s is longer than declared", f.Name)
+	}
+	return payload, nil
+}
+
+// eachExperimentArchiveRecord decodes the JSONL file name record by record;
+// a file the archive does not hold has no records.
+func eachExperimentArchiveRecord[T any](archive experimentArchive, name string, fn func(T) error) error {
+	f, ok := archive.files[name]
+	if !ok {
+		return nil
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	dec := json.NewDecoder(rc)
+	for {
+		var record T
+		if err := dec.Decode(&record); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("%w: %s: %v", errExperimentArchiveInvalid, name, err)
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+}
+
+type experimentImportCounts struct {
+	Runs            int `json:"runs"`
+	MetricSamples   int `json:"metric_samples"`
+	Events          int `json:"events"`
+	Artifacts       int `json:"artifacts"`
+	PolicyDecisions int `json:"policy_decisions"`
+	ConfigManifests int `json:"config_manifests"`
+}
+
+// experimentImport records where an imported experiment came from. Policy
+// decisions and run config manifests refer to the source installation's
+// policies and signing keys, so they are counted and kept in the stored
+// archive but not recreated.
+type experimentImport struct {
+	ImportID                    string                 `json:"import_id"`
+	ProjectID                   string                 `json:"project_id"`
+	ExperimentID                string                 `json:"experiment_id"`
+	Name                        string                 `json:"name"`
+	SourceExperimentID          string                 `json:"source_experiment_id"`
+	SourceProjectID             string                 `json:"source_project_id"`
+	SourceVersion               string                 `json:"source_version,omitempty"`
+	ExportedAt                  time.Time              `json:"exported_at"`
+	ManifestSHA256              string                 `json:"manifest_sha256"`
+	ArchiveObjectKey            string                 `json:"archive_object_key"`
+	RunIDs                      map[string]string      `json:"run_ids"`
+	Imported                    experimentImportCounts `json:"imported"`
+	MissingArtifactIDs          []string               `json:"missing_artifact_ids"`
+	UnresolvedDatasetVersionIDs []string               `json:"unresolved_dataset_version_ids"`
+	CreatedAt                   time.Time              `json:"created_at"`
+	CreatedBy                   string                 `json:"created_by"`
+}
+
+func experimentImportArchiveKey(importID string) string {
+	return "imports/experiments/" + importID + ".zip"
+}
+
+func (api *experimentsAPI) handleImportExperiment(w http.ResponseWriter, r *http.Request) {
+	identity, ok := auth.IdentityFromContext(r.Context())
+	if !ok || strings.TrimSpace(identity.Subject) == "" {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	projectID, ok := auth.ProjectIDFromContext(r.Context())
+	if !ok || strings.TrimSpace(projectID) == "" {
+		api.writeError(w, r, http.StatusBadRequest, "project_id_required")
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxExperimentImportBytes)
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			api.writeError(w, r, http.StatusRequestEntityTooLarge, "upload_too_large")
+			return
+		}
+		api.writeError(w, r, http.StatusBadRequest, "invalid_multipart")
+		return
+	}
+	if r.MultipartForm != nil {
+		defer func() { _ = r.MultipartForm.RemoveAll() }()
+	}
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		api.writeError(w, r, http.StatusBadRequest, "file_required")
+		return
+	}
+	defer file.Close()
+
+	zr, err := zip.NewReader(file, header.Size)
+	if err != nil {
+		api.writeError(w, r, http.StatusUnprocessableEntity, "invalid_experiment_archive")
+		return
+	}
+	archive, err := openExperimentArchive(zr)
+	if err != nil {
+		api.writeExperimentArchiveError(w, r, err)
+		return
+	}
+	var source experimentArchiveExperiment
+	payload, err := readExperimentArchiveEntry(archive.files[experimentArchiveExperimentName])
+	if err == nil {
+		err = json.Unmarshal(payload, &source)
+	}
+	if err != nil || strings.TrimSpace(source.ExperimentID) == "" {
+		api.writeError(w, r, http.StatusUnprocessableEntity, "invalid_experiment_archive")
+		return
+	}
+	name := strings.TrimSpace(r.FormValue("name"))
+	if name == "" {
+		name = strings.TrimSpace(source.Name)
+	}
+	if name == "" {
+		api.writeError(w, r, http.StatusBadRequest, "name_required")
+		return
+	}
+
+	now := time.Now().UTC()
+	record := experimentImport{
+		ImportID:           uuid.NewString(),
+		ProjectID:          projectID,
+		ExperimentID:       uuid.NewString(),
+		Name:               name,
+		SourceExperimentID: source.ExperimentID,
+		SourceProjectID:    archive.Manifest.ProjectID,
+		SourceVersion:      archive.Manifest.SourceVersion,
+		ExportedAt:         archive.Manifest.ExportedAt,
+		ManifestSHA256:     archive.ManifestSHA256,
+		RunIDs:             map[string]string{},
+		MissingArtifactIDs: []string{},
+		CreatedAt:          now,
+		CreatedBy:          identity.Subject,
+	}
+	record.ArchiveObjectKey = experimentImportArchiveKey(record.ImportID)
+
+	// The archive is kept as received: it is the only copy of the policy
+	// decisions and config manifests, and what the import is checked against.
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	putCtx, cancel := context.WithTimeout(r.Context(), 10*time.Minute)
+	_, err = api.store.PutObject(putCtx, api.storeCfg.BucketArtifacts, record.ArchiveObjectKey, file, header.Size, minio.PutObjectOptions{
+		ContentType: experimentArchiveContentTypeZip,
+	})
+	cancel()
+	if err != nil {
+		api.writeError(w, r, http.StatusBadGateway, "object_store_error")
+		return
+	}
+
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := api.insertImportedExperiment(r.Context(), tx, record, source); err != nil {
+		if errors.Is(err, repo.ErrConflict) {
+			api.writeError(w, r, http.StatusConflict, "experiment_name_exists")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if err := api.importExperimentArchive(r, tx, archive, &record); err != nil {
+		if errors.Is(err, errExperimentArchiveInvalid) {
+			api.writeExperimentArchiveError(w, r, err)
+			return
+		}
+		if errors.Is(err, errArtifactStore) {
+			api.writeError(w, r, http.StatusBadGateway, "artifact_store_failed")
+			return
+		}
+		api.writeExperimentRunInsertError(w, r, err)
+		return
+	}
+	if err := insertExperimentImport(r.Context(), tx, record); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	if err := lineageevent.Enqueue(r.Context(), tx, lineageevent.Event{
+		OccurredAt:  now,
+		Actor:       identity.Subject,
+		RequestID:   r.Header.Get("X-Request-Id"),
+		SubjectType: "experiment",
+		SubjectID:   record.ExperimentID,
+		Predicate:   "imported_from",
+		ObjectType:  "experiment_import",
+		ObjectID:    record.ImportID,
+		Metadata: map[string]any{
+			"source_experiment_id": record.SourceExperimentID,
+			"source_project_id":    record.SourceProjectID,
+			"manifest_sha256":      record.ManifestSHA256,
+		},
+	}); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "lineage_write_failed")
+		return
+	}
+	if err := auditlog.Enqueue(r.Context(), tx, auditlog.Event{
+		OccurredAt:   now,
+		Actor:        identity.Subject,
+		Action:       "experiment.import",
+		ResourceType: "experiment",
+		ResourceID:   record.ExperimentID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload: map[string]any{
+			"service":                        "experiments",
+			"import_id":                      record.ImportID,
+			"experiment_id":                  record.ExperimentID,
+			"name":                           record.Name,
+			"source_experiment_id":           record.SourceExperimentID,
+			"source_project_id":              record.SourceProjectID,
+			"manifest_sha256":                record.ManifestSHA256,
+			"imported":                       record.Imported,
+			"missing_artifact_ids":           record.MissingArtifactIDs,
+			"unresolved_dataset_version_ids": record.UnresolvedDatasetVersionIDs,
+		},
+	}); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "audit_failed")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	w.Header().Set("Location", "/experiments/"+record.ExperimentID)
+	api.writeJSON(w, http.StatusCreated, record)
+}
+
+func (api *experimentsAPI) writeExperimentArchiveError(w http.ResponseWriter, r *http.Request, err error) {
+	var checksum experimentArchiveChecksumError
+	if errors.As(err, &checksum) {
+		api.writeError(w, r, http.StatusUnprocessableEntity, "experiment_archive_checksum_mismatch")
+		return
+	}
+	api.writeError(w, r, http.StatusUnprocessableEntity, "invalid_experiment_archive")
+}
+
+var errArtifactStore = errors.New("artifact store failure")
+
+// importExperimentArchive recreates the runs of the archive under new IDs
+// with their metrics, events and artifacts, filling in record.
+func (api *experimentsAPI) importExperimentArchive(r *http.Request, tx *sql.Tx, archive experimentArchive, record *experimentImport) error {
+	ctx := r.Context()
+	unresolved := make(map[string]bool)
+	err := eachExperimentArchiveRecord(archive, experimentArchiveRunsName, func(source experimentArchiveRun) error {
+		if strings.TrimSpace(source.RunID) == "" || record.RunIDs[source.RunID] != "" {
+			return fmt.Errorf("%w: run %q", errExperimentArchiveInvalid, source.RunID)
+		}
+		run, datasets, err := importedExperimentRun(ctx, tx, *record, source, unresolved)
+		if err != nil {
+			return err
+		}
+		// Imported runs never passed this installation's gates, so their
+		// datasets are written here rather than through insertExperimentRun,
+		// which would record quality_gate.allow decisions.
+		if err := insertExperimentRun(r, tx, record.CreatedBy, run); err != nil {
+			return err
+		}
+		if err := insertRunDatasets(ctx, tx, run.RunID, datasets); err != nil {
+			return err
+		}
+		record.RunIDs[source.RunID] = run.RunID
+		record.Imported.Runs++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	record.UnresolvedDatasetVersionIDs = sortedKeys(unresolved)
+
+	runID := func(sourceRunID string) (string, error) {
+		if id, ok := record.RunIDs[sourceRunID]; ok {
+			return id, nil
+		}
+		return "", fmt.Errorf("%w: unknown run %q", errExperimentArchiveInvalid, sourceRunID)
+	}
+
+	metrics := api.metricStore(tx)
+	err = eachExperimentArchiveRecord(archive, experimentArchiveMetricsName, func(source experimentArchiveMetric) error {
+		id, err := runID(source.RunID)
+		if err != nil {
+			return err
+		}
+		sample, err := importedMetricSample(id, source)
+		if err != nil {
+			return err
+		}
+		created, err := metrics.InsertSample(ctx, sample)
+		if created {
+			record.Imported.MetricSamples++
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	err = eachExperimentArchiveRecord(archive, experimentArchiveEventsName, func(source experimentArchiveEvent) error {
+		id, err := runID(source.RunID)
+		if err != nil {
+			return err
+		}
+		if err := insertImportedRunEvent(ctx, api.runStore(tx), id, source); err != nil {
+			return err
+		}
+		record.Imported.Events++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	err = eachExperimentArchiveRecord(archive, experimentArchiveArtifactsName, func(source experimentArchiveArtifact) error {
+		id, err := runID(source.RunID)
+		if err != nil {
+			return err
+		}
+		imported, err := api.importArtifact(r, tx, archive, record.ProjectID, record.CreatedBy, id, source)
+		if err != nil {
+			return err
+		}
+		if imported {
+			record.Imported.Artifacts++
+		} else {
+			record.MissingArtifactIDs = append(record.MissingArtifactIDs, source.ArtifactID)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, file := range archive.Manifest.Files {
+		switch file.Name {
+		case experimentArchiveDecisionsName:
+			record.Imported.PolicyDecisions = file.Records
+		case experimentArchiveConfigsName:
+			record.Imported.ConfigManifests = file.Records
+		}
+	}
+	return nil
+}
+
+func (api *experimentsAPI) insertImportedExperiment(ctx context.Context, tx *sql.Tx, record experimentImport, source experimentArchiveExperiment) error {
+	metadata := source.Metadata
+	if len(metadata) == 0 {
+		metadata = json.RawMessage(`{}`)
+	}
+	experiment, err := newExperimentRecord(record.ExperimentID, record.ProjectID, record.Name, source.Description, metadata, record.CreatedAt, record.CreatedBy)
+	if err != nil {
+		return err
+	}
+	return api.runStore(tx).CreateExperiment(ctx, experiment)
+}
+
+// importedExperimentRun maps a source run onto the target and returns it with
+// its datasets. Dataset versions are kept when the project holds them; the
+// others are added to unresolved.
+func importedExperimentRun(ctx context.Context, tx *sql.Tx, record experimentImport, source experimentArchiveRun, unresolved map[string]bool) (experimentRunInsert, []runDataset, error) {
+	run := experimentRunInsert{
+		RunID:        uuid.NewString(),
+		ExperimentID: record.ExperimentID,
+		ProjectID:    record.ProjectID,
+		Status:       strings.TrimSpace(source.Status),
+		StartedAt:    source.StartedAt.UTC(),
+		EndedAt:      source.EndedAt,
+		GitRepo:      source.GitRepo,
+		GitCommit:    source.GitCommit,
+		GitRef:       source.GitRef,
+		ParamsJSON:   source.Params,
+		MetricsJSON:  source.Metrics,
+		CreatedAt:    record.CreatedAt,
+	}
+	if run.Status == "" {
+		return experimentRunInsert{}, nil, fmt.Errorf("%w: run %s has no status", errExperimentArchiveInvalid, source.RunID)
+	}
+	if len(run.ParamsJSON) == 0 {
+		run.ParamsJSON = json.RawMessage(`{}`)
+	}
+	if len(run.MetricsJSON) == 0 {
+		run.MetricsJSON = json.RawMessage(`{}`)
+	}
+	if err := json.Unmarshal(run.ParamsJSON, &run.Params); err != nil {
+		return experimentRunInsert{}, nil, fmt.Errorf("%w: run %s params: %v", errExperimentArchiveInvalid, source.RunID, err)
+	}
+	if err := json.Unmarshal(run.MetricsJSON, &run.Metrics); err != nil {
+		return experimentRunInsert{}, nil, fmt.Errorf("%w: run %s metrics: %v", errExperimentArchiveInvalid, source.RunID, err)
+	}
+
+	var datasets []runDataset
+	for _, versionID := range source.DatasetVersionIDs {
+		datasetID, contentSHA256, err := postgres.NewDatasetStore(tx).DatasetVersionContent(ctx, record.ProjectID, versionID)
+		if errors.Is(err, repo.ErrNotFound) {
+			unresolved[versionID] = true
+			continue
+		}
+		if err != nil {
+			return experimentRunInsert{}, nil, err
+		}
+		datasets = append(datasets, runDataset{VersionID: versionID, Gate: gateDecision{DatasetID: datasetID, ContentSHA256: contentSHA256}})
+		run.DatasetVersionIDs = append(run.DatasetVersionIDs, versionID)
+	}
+	if len(run.DatasetVersionIDs) > 0 {
+		run.DatasetVersionID = run.DatasetVersionIDs[0]
+	}
+	integrity, err := experimentRunIntegrity(run)
+	if err != nil {
+		return experimentRunInsert{}, nil, err
+	}
+	run.IntegritySHA256 = integrity
+	return run, datasets, nil
+}
+
+func importedMetricSample(runID string, source experimentArchiveMetric) (repo.MetricSampleRecord, error) {
+	metadata := source.Metadata
+	if len(metadata) == 0 {
+		metadata = json.RawMessage(`{}`)
+	}
+	sample := repo.MetricSampleRecord{
+		SampleID:   uuid.NewString(),
+		RunID:      runID,
+		RecordedAt: source.RecordedAt.UTC(),
+		RecordedBy: source.RecordedBy,
+		Step:       source.Step,
+		Name:       source.Name,
+		Value:      source.Value,
+		Metadata:   metadata,
+	}
+	type integrityInput struct {
+		SampleID   string          `json:"sample_id"`
+		RunID      string          `json:"run_id"`
+		RecordedAt time.Time       `json:"recorded_at"`
+		RecordedBy string          `json:"recorded_by"`
+		Step       int64           `json:"step"`
+		Name       string          `json:"name"`
+		Value      float64         `json:"value"`
+		Metadata   json.RawMessage `json:"metadata"`
+	}
+	integrity, err := integritySHA256(integrityInput{
+		SampleID:   sample.SampleID,
+		RunID:      sample.RunID,
+		RecordedAt: sample.RecordedAt,
+		RecordedBy: sample.RecordedBy,
+		Step:       sample.Step,
+		Name:       sample.Name,
+		Value:      sample.Value,
+		Metadata:   metadata,
+	})
+	sample.IntegritySHA256 = integrity
+	return sample, err
+}
+
+func insertImportedRunEvent(ctx context.Context, runs repo.RunStore, runID string, source experimentArchiveEvent) error {
+	metadata := source.Metadata
+	if len(metadata) == 0 {
+		metadata = json.RawMessage(`{}`)
+	}
+	type integrityInput struct {
+		RunID      string          `json:"run_id"`
+		OccurredAt time.Time       `json:"occurred_at"`
+		Actor      string          `json:"actor"`
+		Level      string          `json:"level"`
+		Message    string          `json:"message"`
+		Metadata   json.RawMessage `json:"metadata"`
+	}
+	occurredAt := source.OccurredAt.UTC()
+	integrity, err := integritySHA256(integrityInput{
+		RunID:      runID,
+		OccurredAt: occurredAt,
+		Actor:      source.Actor,
+		Level:      source.Level,
+		Message:    source.Message,
+		Metadata:   metadata,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = runs.InsertRunEvent(ctx, repo.RunEventRecord{
+		RunID:           runID,
+		OccurredAt:      occurredAt,
+		Actor:           source.Actor,
+		Level:           source.Level,
+		Message:         source.Message,
+		Metadata:        metadata,
+		IntegritySHA256: integrity,
+	})
+	return err
+}
+
+// importArtifact recreates one artifact from its body in the archive or, when
+// the body was not exported, from a blob the importing project already holds. It
+// reports false when neither is available.
+func (api *experimentsAPI) importArtifact(r *http.Request, tx *sql.Tx, archive experimentArchive, projectID, actor, runID string, source experimentArchiveArtifact) (bool, error) {
+	ctx := r.Context()
+	sha256Hex := strings.ToLower(strings.TrimSpace(source.SHA256))
+	if !sha256HexPattern.MatchString(sha256Hex) || !isAllowedArtifactKind(source.Kind) {
+		return false, fmt.Errorf("%w: artifact %s", errExperimentArchiveInvalid, source.ArtifactID)
+	}
+	contentType := strings.TrimSpace(source.ContentType)
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	var (
+		content io.Reader
+		size    int64
+	)
+	if f, ok := archive.files[experimentArchiveBlobPrefix+sha256Hex]; ok {
+		// openExperimentArchive matched the body against the digest in its
+		// name.
+		rc, err := f.Open()
+		if err != nil {
+			return false, err
+		}
+		defer rc.Close()
+		content = rc
+		size = int64(f.UncompressedSize64)
+	}
+	putCtx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+	blob, err := referenceArtifactBlob(putCtx, tx, api.store, api.storeCfg.BucketArtifacts, projectID, sha256Hex, content, size, contentType, time.Now().UTC())
+	cancel()
+	if errors.Is(err, errArtifactContentRequired) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("%w: %v", errArtifactStore, err)
+	}
+
+	metadata, err := importedArtifactMetadata(source)
+	if err != nil {
+		return false, err
+	}
+	artifactID := uuid.NewString()
+	kind := strings.ToLower(strings.TrimSpace(source.Kind))
+	createdAt := source.CreatedAt.UTC()
+	type integrityInput struct {
+		ArtifactID  string          `json:"artifact_id"`
+		RunID       string          `json:"run_id"`
+		Kind        string          `json:"kind"`
+		Name        string          `json:"name,omitempty"`
+		Filename    string          `json:"filename,omitempty"`
+		ContentType string          `json:"content_type,omitempty"`
+		ObjectKey   string          `json:"object_key"`
+		SHA256      string          `json:"sha256"`
+		SizeBytes   int64           `json:"size_bytes"`
+		Metadata    json.RawMessage `json:"metadata"`
+		CreatedAt   time.Time       `json:"created_at"`
+		CreatedBy   string          `json:"created_by"`
+	}
+	integrity, err := integritySHA256(integrityInput{
+		ArtifactID:  artifactID,
+		RunID:       runID,
+		Kind:        kind,
+		Name:        source.Name,
+		Filename:    source.Filename,
+		ContentType: contentType,
+		ObjectKey:   blob.ObjectKey,
+		SHA256:      sha256Hex,
+		SizeBytes:   blob.SizeBytes,
+		Metadata:    metadata,
+		CreatedAt:   createdAt,
+		CreatedBy:   actor,
+	})
+	if err != nil {
+		return false, err
+	}
+	err = postgres.NewExperimentArchiveStore(tx).CreateArtifact(ctx, postgres.ExperimentArchiveArtifactRecord{
+		ArtifactID:      artifactID,
+		RunID:           runID,
+		Kind:            kind,
+		Name:            source.Name,
+		Filename:        source.Filename,
+		ContentType:     contentType,
+		ObjectKey:       blob.ObjectKey,
+		SHA256:          sha256Hex,
+		SizeBytes:       blob.SizeBytes,
+		Metadata:        metadata,
+		CreatedAt:       createdAt,
+		CreatedBy:       actor,
+		IntegritySHA256: integrity,
+		BlobSHA256:      sha256Hex,
+	})
+	if err != nil {
+		return false, err
+	}
+	err = lineageevent.Enqueue(ctx, tx, lineageevent.Event{
+		OccurredAt:  time.Now().UTC(),
+		Actor:       actor,
+		RequestID:   r.Header.Get("X-Request-Id"),
+		SubjectType: "experiment_run",
+		SubjectID:   runID,
+		Predicate:   "produced",
+		ObjectType:  "artifact",
+		ObjectID:    artifactID,
+		Metadata: map[string]any{
+			"kind":               kind,
+			"name":               source.Name,
+			"filename":           source.Filename,
+			"sha256":             sha256Hex,
+			"size_bytes":         blob.SizeBytes,
+			"source_artifact_id": source.ArtifactID,
+		},
+	})
+	if err != nil {
+		return false, fmt.Errorf("%w: %v", errExperimentRunLineage, err)
+	}
+	return true, nil
+}
+
+// importedArtifactMetadata is the source artifact's metadata with its original
+// author under source_created_by; the importer becomes created_by.
+func importedArtifactMetadata(source experimentArchiveArtifact) (json.RawMessage, error) {
+	metadata := map[string]any{}
+	if len(source.Metadata) > 0 {
+		if err := json.Unmarshal(source.Metadata, &metadata); err != nil || metadata == nil {
+			return nil, fmt.Errorf("%w: artifact %s metadata", errExperimentArchiveInvalid, source.ArtifactID)
+		}
+	}
+	if createdBy := strings.TrimSpace(source.CreatedBy); createdBy != "" {
+		metadata["source_created_by"] = createdBy
+	}
+	return json.Marshal(metadata)
+}
+
+func insertExperimentImport(ctx context.Context, tx *sql.Tx, record experimentImport) error {
+	summary, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	integrity, err := integritySHA256(record)
+	if err != nil {
+		return err
+	}
+	return postgres.NewExperimentArchiveStore(tx).CreateImport(ctx, postgres.ExperimentImportRecord{
+		ImportID:           record.ImportID,
+		ProjectID:          record.ProjectID,
+		ExperimentID:       record.ExperimentID,
+		SourceExperimentID: record.SourceExperimentID,
+		SourceProjectID:    record.SourceProjectID,
+		ManifestSHA256:     record.ManifestSHA256,
+		ArchiveObjectKey:   record.ArchiveObjectKey,
+		Summary:            summary,
+		CreatedAt:          record.CreatedAt,
+		CreatedBy:          record.CreatedBy,
+		IntegritySHA256:    integrity,
+	})
+}
+
+func (api *experimentsAPI) handleGetExperimentImport(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := auth.ProjectIDFromContext(r.Context())
+	if !ok || strings.TrimSpace(projectID) == "" {
+		api.writeError(w, r, http.StatusBadRequest, "project_id_required")
+		return
+	}
+	experimentID := strings.TrimSpace(r.PathValue("experiment_id"))
+	if experimentID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "experiment_id_required")
+		return
+	}
+	summary, err := postgres.NewExperimentArchiveStore(api.db).ImportSummary(r.Context(), projectID, experimentID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	api.writeJSON(w, http.StatusOK, json.RawMessage(summary))
+}
+
+func sortedKeys(set map[string]bool) []string {
+	out := make([]string, 0, len(set))
+	for key := range set {
+		out = append(out, key)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,249 @@
+package experiments
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"hash/crc32"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+)
+
+func TestExperimentArchiveRoundTrip(t *testing.T) {
+	blob := []byte("model weights")
+	blobSHA := sha256HexBytes(blob)
+	var buf bytes.Buffer
+	writer := newExperimentArchiveWriter(&buf, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	writeRecords := func(name string, records ...any) {
+		t.Helper()
+		if err := writer.writeFile(name, zip.Deflate, func(w io.Writer) (int, error) {
+			enc := json.NewEncoder(w)
+			for _, record := range records {
+				if err := enc.Encode(record); err != nil {
+					return 0, err
+				}
+			}
+			return len(records), nil
+		}); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	writeRecords(experimentArchiveExperimentName, experimentArchiveExperiment{ExperimentID: "exp-1", Name: "baseline"})
+	writeRecords(experimentArchiveRunsName, experimentArchiveRun{RunID: "run-1"}, experimentArchiveRun{RunID: "run-2"})
+	writeRecords(experimentArchiveMetricsName, experimentArchiveMetric{RunID: "run-1", Name: "loss", Step: 3, Value: 0.25})
+	writeRecords(experimentArchiveArtifactsName, experimentArchiveArtifact{ArtifactID: "a-1", RunID: "run-2", Kind: "model", SHA256: blobSHA, SizeBytes: int64(len(blob)), Included: true})
+	if err := writer.writeFile(experimentArchiveBlobPrefix+blobSHA, zip.Store, func(w io.Writer) (int, error) {
+		_, err := w.Write(blob)
+		return 0, err
+	}); err != nil {
+		t.Fatalf("write blob: %v", err)
+	}
+	if err := writer.finish(experimentArchiveManifest{Schema: experimentArchiveSchema, ExperimentID: "exp-1", IncludeArtifacts: true}); err != nil {
+		t.Fatalf("finish: %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("zip reader: %v", err)
+	}
+	archive, err := openExperimentArchive(zr)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	payload, err := readExperimentArchiveEntry(archive.files[experimentArchiveExperimentName])
+	if err != nil || !strings.Contains(string(payload), `"name":"baseline"`) {
+		t.Fatalf("experiment = %s, %v", payload, err)
+	}
+	var metrics []experimentArchiveMetric
+	if err := eachExperimentArchiveRecord(archive, experimentArchiveMetricsName, func(m experimentArchiveMetric) error {
+		metrics = append(metrics, m)
+		return nil
+	}); err != nil {
+		t.Fatalf("metrics: %v", err)
+	}
+	if len(metrics) != 1 || metrics[0].Name != "loss" || metrics[0].Value != 0.25 {
+		t.Fatalf("metrics = %+v", metrics)
+	}
+	counts := map[string]int{}
+	for _, f := range archive.Manifest.Files {
+		counts[f.Name] = f.Records
+	}
+	if counts[experimentArchiveRunsName] != 2 || counts[experimentArchiveArtifactsName] != 1 {
+		t.Fatalf("manifest files = %+v", archive.Manifest.Files)
+	}
+	if _, ok := archive.files[experimentArchiveBlobPrefix+blobSHA]; !ok {
+		t.Fatal("artifact body missing from the archive")
+	}
+}
+
+// rawArchiveEntry adds name stored as is, declaring size as its uncompressed
+// length whatever data holds.
+func rawArchiveEntry(t *testing.T, zw *zip.Writer, name string, data []byte, size uint64) {
+	t.Helper()
+	w, err := zw.CreateRaw(&zip.FileHeader{
+		Name:               name,
+		Method:             zip.Store,
+		CRC32:              crc32.ChecksumIEEE(data),
+		CompressedSize64:   uint64(len(data)),
+		UncompressedSize64: size,
+	})
+	if err != nil {
+		t.Fatalf("create %s: %v", name, err)
+	}
+	if _, err := w.Write(data); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+}
+
+func TestOpenExperimentArchiveRejectsMalformed(t *testing.T) {
+	runs := []byte(`{"run_id":"run-1"}` + "\n")
+	cases := []struct {
+		name  string
+		extra func(*zip.Writer)
+		// listed, when set, is added to the manifest for an entry written
+		// by extra.
+		listed *experimentArchiveFile
+	}{
+		{
+			name: "entry longer than declared",
+			extra: func(zw *zip.Writer) {
+				rawArchiveEntry(t, zw, experimentArchiveEventsName, runs, 4)
+			},
+			listed: &experimentArchiveFile{Name: experimentArchiveEventsName, SHA256: sha256HexBytes(runs[:4]), SizeBytes: 4},
+		},
+		{
+			name: "size differs from the manifest",
+			extra: func(zw *zip.Writer) {
+				rawArchiveEntry(t, zw, experimentArchiveEventsName, runs, uint64(len(runs)))
+			},
+			listed: &experimentArchiveFile{Name: experimentArchiveEventsName, SHA256: sha256HexBytes(runs), SizeBytes: 4},
+		},
+		{
+			name: "declared content past the archive limit",
+			extra: func(zw *zip.Writer) {
+				rawArchiveEntry(t, zw, experimentArchiveEventsName, runs, maxExperimentArchiveBytes)
+			},
+		},
+	}
+	for _, tc := range cases {
+		zr := buildTestExperimentArchiveWith(t, tc.extra, tc.listed)
+		_, err := openExperimentArchive(zr)
+		var mismatch experimentArchiveChecksumError
+		if !errors.Is(err, errExperimentArchiveInvalid) && !errors.As(err, &mismatch) {
+			t.Fatalf("%s: err = %v, want a rejected archive", tc.name, err)
+		}
+	}
+
+	// A manifest too large to read whole.
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	rawArchiveEntry(t, zw, experimentArchiveManifestName, []byte("{}"), maxExperimentArchiveJSONBytes+1)
+	if err := zw.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("zip reader: %v", err)
+	}
+	if _, err := openExperimentArchive(zr); !errors.Is(err, errExperimentArchiveInvalid) {
+		t.Fatalf("oversized manifest: err = %v", err)
+	}
+}
+
+// buildTestExperimentArchiveWith is buildTestExperimentArchive with one more
+// manifest entry for a file extra writes outside the archive writer.
+func buildTestExperimentArchiveWith(t *testing.T, extra func(*zip.Writer), listed *experimentArchiveFile) *zip.Reader {
+	t.Helper()
+	var buf bytes.Buffer
+	archive := newExperimentArchiveWriter(&buf, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	for _, name := range []string{experimentArchiveExperimentName, experimentArchiveRunsName} {
+		if err := archive.writeFile(name, zip.Deflate, func(w io.Writer) (int, error) {
+			_, err := io.WriteString(w, `{"experiment_id":"exp-1","run_id":"run-1"}`+"\n")
+			return 1, err
+		}); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	extra(archive.zw)
+	if listed != nil {
+		archive.files = append(archive.files, *listed)
+	}
+	if err := archive.finish(experimentArchiveManifest{Schema: experimentArchiveSchema, ExperimentID: "exp-1"}); err != nil {
+		t.Fatalf("finish: %v", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("zip reader: %v", err)
+	}
+	return zr
+}
+
+func TestImportExperimentRejectsMalformedArchive(t *testing.T) {
+	api := &experimentsAPI{}
+	upload := func(field string, content []byte) *httptest.ResponseRecorder {
+		t.Helper()
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		part, err := mw.CreateFormFile(field, "experiment.zip")
+		if err != nil {
+			t.Fatalf("form file: %v", err)
+		}
+		_, _ = part.Write(content)
+		if err := mw.Close(); err != nil {
+			t.Fatalf("close multipart: %v", err)
+		}
+		req := httptest.NewRequest(http.MethodPost, "/experiments:import", &body)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		ctx := auth.ContextWithProjectID(auth.ContextWithIdentity(req.Context(), auth.Identity{Subject: "alice", Roles: []string{auth.RoleEditor}}), "proj-1")
+		rec := httptest.NewRecorder()
+		api.handleImportExperiment(rec, req.WithContext(ctx))
+		return rec
+	}
+
+	var noManifest bytes.Buffer
+	zw := zip.NewWriter(&noManifest)
+	rawArchiveEntry(t, zw, experimentArchiveRunsName, []byte("{}\n"), 3)
+	if err := zw.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	cases := []struct {
+		name    string
+		field   string
+		content []byte
+		status  int
+		code    string
+	}{
+		{name: "file required", field: "archive", content: []byte("x"), status: http.StatusBadRequest, code: "file_required"},
+		{name: "not a zip", field: "file", content: []byte("not a zip"), status: http.StatusUnprocessableEntity, code: "invalid_experiment_archive"},
+		{name: "manifest missing", field: "file", content: noManifest.Bytes(), status: http.StatusUnprocessableEntity, code: "invalid_experiment_archive"},
+	}
+	for _, tc := range cases {
+		rec := upload(tc.field, tc.content)
+		if rec.Code != tc.status || !strings.Contains(rec.Body.String(), tc.code) {
+			t.Fatalf("%s: status=%d body=%s", tc.name, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestImportedArtifactMetadataKeepsSourceAuthor(t *testing.T) {
+	got, err := importedArtifactMetadata(experimentArchiveArtifact{ArtifactID: "a-1", CreatedBy: "bob", Metadata: json.RawMessage(`{"epoch":3}`)})
+	if err != nil {
+		t.Fatalf("importedArtifactMetadata() err=%v", err)
+	}
+	var metadata map[string]any
+	if err := json.Unmarshal(got, &metadata); err != nil || metadata["source_created_by"] != "bob" || metadata["epoch"] != float64(3) {
+		t.Fatalf("metadata=%s err=%v", got, err)
+	}
+
+	if _, err := importedArtifactMetadata(experimentArchiveArtifact{ArtifactID: "a-2", Metadata: json.RawMessage(`[1]`)}); !errors.Is(err, errExperimentArchiveInvalid) {
+		t.Fatalf("non-object metadata: err=%v", err)
+	}
+}
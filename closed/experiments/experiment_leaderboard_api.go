@@ -0,0 +1,209 @@
+package experiments
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+)
+
+const (
+	leaderboardOrderAsc  = "asc"
+	leaderboardOrderDesc = "desc"
+
+	leaderboardSourceSample = "sample"
+	leaderboardSourceFinal  = "final"
+)
+
+type leaderboardQuery struct {
+	Metric string
+	Order  string
+	Status string
+	Limit  int
+}
+
+type leaderboardEntry struct {
+	Rank              int             `json:"rank"`
+	RunID             string          `json:"run_id"`
+	Status            string          `json:"status"`
+	Value             float64         `json:"value"`
+	Source            string          `json:"source"`
+	Step              *int64          `json:"step,omitempty"`
+	StartedAt         time.Time       `json:"started_at"`
+	Params            json.RawMessage `json:"params"`
+	DatasetVersionIDs []string        `json:"dataset_version_ids"`
+	GitCommit         string          `json:"git_commit,omitempty"`
+	ImageRef          string          `json:"image_ref,omitempty"`
+	ImageDigest       string          `json:"image_digest,omitempty"`
+}
+
+type leaderboardResponse struct {
+	ExperimentID string             `json:"experiment_id"`
+	Metric       string             `json:"metric"`
+	Order        string             `json:"order"`
+	Runs         []leaderboardEntry `json:"runs"`
+}
+
+// parseLeaderboardQuery reads metric, order, status and limit; the returned
+// string is the error code for an invalid query.
+func parseLeaderboardQuery(r *http.Request) (leaderboardQuery, string) {
+	values := r.URL.Query()
+	q := leaderboardQuery{
+		Metric: strings.TrimSpace(values.Get("metric")),
+		Order:  strings.ToLower(strings.TrimSpace(values.Get("order"))),
+		Status: strings.ToLower(strings.TrimSpace(values.Get("status"))),
+		Limit:  httpapi.Limit(r, 50, 500),
+	}
+	if q.Metric == "" {
+		return leaderboardQuery{}, "metric_required"
+	}
+	switch q.Order {
+	case "":
+		q.Order = leaderboardOrderDesc
+	case leaderboardOrderAsc, leaderboardOrderDesc:
+	default:
+		return leaderboardQuery{}, "invalid_order"
+	}
+	if q.Status != "" {
+		if _, ok := allowedRunStatuses[q.Status]; !ok {
+			return leaderboardQuery{}, "invalid_status"
+		}
+	}
+	return q, ""
+}
+
+// handleGetExperimentLeaderboard ranks the experiment's runs by the final
+// value of one metric: the sample with the highest step, or the numeric value
+// recorded in the run's metrics when the run has no samples. Runs without the
+// metric are left out.
+func (api *experimentsAPI) handleGetExperimentLeaderboard(w http.ResponseWriter, r *http.Request) {
+	experimentID := strings.TrimSpace(r.PathValue("experiment_id"))
+	if experimentID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "experiment_id_required")
+		return
+	}
+	q, code := parseLeaderboardQuery(r)
+	if code != "" {
+		api.writeError(w, r, http.StatusBadRequest, code)
+		return
+	}
+
+	exists, err := api.experimentExists(r.Context(), experimentID)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if !exists {
+		api.writeError(w, r, http.StatusNotFound, "not_found")
+		return
+	}
+
+	direction := "DESC"
+	if q.Order == leaderboardOrderAsc {
+		direction = "ASC"
+	}
+	rows, err := api.db.QueryContext(
+		r.Context(),
+		`SELECT run_id, status, value, step, started_at, params, dataset_version_ids, git_commit, image_ref, image_digest
+		 FROM (
+			SELECT r.run_id,
+					COALESCE(s.status, r.status) AS status,
+					COALESCE(
+						m.value,
+						CASE WHEN jsonb_typeof(r.metrics -> $2) = 'number' THEN (r.metrics ->> $2)::double precision END
+					) AS value,
+					m.step,
+					r.started_at,
+					r.params,
+					(SELECT COALESCE(json_agg(d.dataset_version_id ORDER BY d.position), '[]'::json)
+					   FROM experiment_run_datasets d
+					  WHERE d.run_id = r.run_id) AS dataset_version_ids,
+					r.git_commit,
+					e.image_ref,
+					e.image_digest
+			 FROM experiment_runs r
+			 LEFT JOIN LATERAL (
+				SELECT status
+				FROM experiment_run_state_events
+				WHERE run_id = r.run_id
+				ORDER BY observed_at DESC
+				LIMIT 1
+			 ) s ON true
+			 LEFT JOIN LATERAL (
+				SELECT value, step
+				FROM experiment_run_metric_samples
+				WHERE run_id = r.run_id AND name = $2
+				ORDER BY step DESC
+				LIMIT 1
+			 ) m ON true
+			 LEFT JOIN experiment_run_executions e ON e.run_id = r.run_id
+			 WHERE r.experiment_id = $1
+		 ) ranked
+		 WHERE value IS NOT NULL
+		   AND ($3 = '' OR status = $3)
+		 ORDER BY value `+direction+`, started_at DESC, run_id
+		 LIMIT $4`,
+		experimentID,
+		q.Metric,
+		q.Status,
+		q.Limit,
+	)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer rows.Close()
+
+	out := leaderboardResponse{
+		ExperimentID: experimentID,
+		Metric:       q.Metric,
+		Order:        q.Order,
+		Runs:         make([]leaderboardEntry, 0, q.Limit),
+	}
+	for rows.Next() {
+		var (
+			entry       leaderboardEntry
+			step        sql.NullInt64
+			params      []byte
+			datasetIDs  []byte
+			gitCommit   sql.NullString
+			imageRef    sql.NullString
+			imageDigest sql.NullString
+		)
+		if err := rows.Scan(&entry.RunID, &entry.Status, &entry.Value, &step, &entry.StartedAt, &params, &datasetIDs, &gitCommit, &imageRef, &imageDigest); err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+		entry.Rank = len(out.Runs) + 1
+		entry.Source = leaderboardSourceFinal
+		if step.Valid {
+			s := step.Int64
+			entry.Step = &s
+			entry.Source = leaderboardSourceSample
+		}
+		entry.StartedAt = entry.StartedAt.UTC()
+		entry.Params = normalizeJSON(params)
+		entry.DatasetVersionIDs = decodeRunDatasetVersionIDs(datasetIDs)
+		if entry.DatasetVersionIDs == nil {
+			entry.DatasetVersionIDs = []string{}
+		}
+		entry.GitCommit = strings.TrimSpace(gitCommit.String)
+		entry.ImageRef = strings.TrimSpace(imageRef.String)
+		entry.ImageDigest = strings.TrimSpace(imageDigest.String)
+		if entry.ImageDigest == "" {
+			if parsed, ok := parseImageDigestFromRef(entry.ImageRef); ok {
+				entry.ImageDigest = parsed
+			}
+		}
+		out.Runs = append(out.Runs, entry)
+	}
+	if err := rows.Err(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	api.writeJSON(w, http.StatusOK, out)
+}
@@ -0,0 +1,27 @@
+package experiments
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseLeaderboardQuery(t *testing.T) {
+	q, code := parseLeaderboardQuery(httptest.NewRequest("GET", "/experiments/e1/leaderboard?metric=accuracy", nil))
+	if code != "" || q.Metric != "accuracy" || q.Order != leaderboardOrderDesc || q.Limit != 50 {
+		t.Fatalf("unexpected defaults: %+v %q", q, code)
+	}
+	q, code = parseLeaderboardQuery(httptest.NewRequest("GET", "/experiments/e1/leaderboard?metric=loss&order=ASC&status=succeeded&limit=10", nil))
+	if code != "" || q.Order != leaderboardOrderAsc || q.Status != "succeeded" || q.Limit != 10 {
+		t.Fatalf("unexpected query: %+v %q", q, code)
+	}
+
+	for target, want := range map[string]string{
+		"/experiments/e1/leaderboard":                           "metric_required",
+		"/experiments/e1/leaderboard?metric=acc&order=best":     "invalid_order",
+		"/experiments/e1/leaderboard?metric=acc&status=unknown": "invalid_status",
+	} {
+		if _, code := parseLeaderboardQuery(httptest.NewRequest("GET", target, nil)); code != want {
+			t.Fatalf("%s: expected %s, got %q", target, want, code)
+		}
+	}
+}
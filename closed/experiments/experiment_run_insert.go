@@ -0,0 +1,325 @@
+package experiments
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	"github.com/animus-labs/animus-go/closed/internal/platform/lineageevent"
+)
+
+var (
+	errExperimentRunLineage = errors.New("experiment run lineage write failed")
+	errExperimentRunAudit   = errors.New("experiment run audit write failed")
+)
+
+// experimentRunInsert is a validated experiment run whose datasets already
+// passed the quality gate and dataset policies.
+type experimentRunInsert struct {
+	RunID             string
+	ExperimentID      string
+	ProjectID         string
+	DatasetVersionID  string
+	DatasetVersionIDs []string
+	Datasets          []runDataset
+	Status            string
+	StartedAt         time.Time
+	EndedAt           *time.Time
+	GitRepo           string
+	GitCommit         string
+	GitRef            string
+	Params            map[string]any
+	ParamsJSON        json.RawMessage
+	Metrics           map[string]any
+	MetricsJSON       json.RawMessage
+	ArtifactsPrefix   string
+	CreatedAt         time.Time
+	IntegritySHA256   string
+	ConfigManifest    *runConfigManifestRecord
+}
+
+func experimentRunIntegrity(run experimentRunInsert) (string, error) {
+	type integrityInput struct {
+		RunID             string          `json:"run_id"`
+		ExperimentID      string          `json:"experiment_id"`
+		ProjectID         string          `json:"project_id"`
+		DatasetVersionID  string          `json:"dataset_version_id,omitempty"`
+		DatasetVersionIDs []string        `json:"dataset_version_ids,omitempty"`
+		Status            string          `json:"status"`
+		StartedAt         time.Time       `json:"started_at"`
+		EndedAt           *time.Time      `json:"ended_at,omitempty"`
+		GitRepo           string          `json:"git_repo,omitempty"`
+		GitCommit         string          `json:"git_commit,omitempty"`
+		GitRef            string          `json:"git_ref,omitempty"`
+		Params            json.RawMessage `json:"params"`
+		Metrics           json.RawMessage `json:"metrics"`
+		ArtifactsPrefix   string          `json:"artifacts_prefix,omitempty"`
+	}
+	input := integrityInput{
+		RunID:            run.RunID,
+		ExperimentID:     run.ExperimentID,
+		ProjectID:        run.ProjectID,
+		DatasetVersionID: run.DatasetVersionID,
+		Status:           run.Status,
+		StartedAt:        run.StartedAt,
+		EndedAt:          run.EndedAt,
+		GitRepo:          run.GitRepo,
+		GitCommit:        run.GitCommit,
+		GitRef:           run.GitRef,
+		Params:           run.ParamsJSON,
+		Metrics:          run.MetricsJSON,
+		ArtifactsPrefix:  run.ArtifactsPrefix,
+	}
+	if len(run.DatasetVersionIDs) > 1 {
+		// Only multi-dataset runs carry the list, so single-dataset hashes are
+		// unchanged.
+		input.DatasetVersionIDs = run.DatasetVersionIDs
+	}
+	return integritySHA256(input)
+}
+
+// insertExperimentRun writes the run row, its datasets, lineage and audit
+// events in tx.
+func insertExperimentRun(r *http.Request, tx *sql.Tx, actor string, run experimentRunInsert) error {
+	ctx := r.Context()
+	_, err := tx.ExecContext(
+		ctx,
+		`INSERT INTO experiment_runs (
+			run_id,
+			experiment_id,
+			project_id,
+			dataset_version_id,
+			status,
+			started_at,
+			ended_at,
+			git_repo,
+			git_commit,
+			git_ref,
+			params,
+			metrics,
+			artifacts_prefix,
+			integrity_sha256
+		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14)`,
+		run.RunID,
+		run.ExperimentID,
+		run.ProjectID,
+		nullString(run.DatasetVersionID),
+		run.Status,
+		run.StartedAt,
+		nullTimePtr(run.EndedAt),
+		nullString(run.GitRepo),
+		nullString(run.GitCommit),
+		nullString(run.GitRef),
+		run.ParamsJSON,
+		run.MetricsJSON,
+		nullString(run.ArtifactsPrefix),
+		run.IntegritySHA256,
+	)
+	if err != nil {
+		return err
+	}
+	if err := insertRunDatasets(ctx, tx, run.RunID, run.Datasets); err != nil {
+		return err
+	}
+	if run.ConfigManifest != nil {
+		if err := insertRunConfigManifest(ctx, tx, run.ConfigManifest); err != nil {
+			return err
+		}
+	}
+
+	err = lineageevent.Enqueue(ctx, tx, lineageevent.Event{
+		OccurredAt:  run.CreatedAt,
+		Actor:       actor,
+		RequestID:   r.Header.Get("X-Request-Id"),
+		SubjectType: "experiment",
+		SubjectID:   run.ExperimentID,
+		Predicate:   "has_run",
+		ObjectType:  "experiment_run",
+		ObjectID:    run.RunID,
+		Metadata: map[string]any{
+			"status":              run.Status,
+			"dataset_version_id":  run.DatasetVersionID,
+			"dataset_version_ids": run.DatasetVersionIDs,
+			"git_commit":          run.GitCommit,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %v", errExperimentRunLineage, err)
+	}
+
+	for i, dataset := range run.Datasets {
+		err = lineageevent.Enqueue(ctx, tx, lineageevent.Event{
+			OccurredAt:  run.CreatedAt,
+			Actor:       actor,
+			RequestID:   r.Header.Get("X-Request-Id"),
+			SubjectType: "dataset_version",
+			SubjectID:   dataset.VersionID,
+			Predicate:   "used_by",
+			ObjectType:  "experiment_run",
+			ObjectID:    run.RunID,
+			Metadata: map[string]any{
+				"dataset_id":    dataset.Gate.DatasetID,
+				"experiment_id": run.ExperimentID,
+				"rule_id":       dataset.Gate.RuleID,
+				"evaluation_id": dataset.Gate.EvaluationID,
+				"status":        dataset.Gate.Status,
+				"position":      i,
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("%w: %v", errExperimentRunLineage, err)
+		}
+	}
+
+	if run.GitCommit != "" {
+		err = lineageevent.Enqueue(ctx, tx, lineageevent.Event{
+			OccurredAt:  run.CreatedAt,
+			Actor:       actor,
+			RequestID:   r.Header.Get("X-Request-Id"),
+			SubjectType: "experiment_run",
+			SubjectID:   run.RunID,
+			Predicate:   "built_from",
+			ObjectType:  "git_commit",
+			ObjectID:    run.GitCommit,
+			Metadata: map[string]any{
+				"git_repo": run.GitRepo,
+				"git_ref":  run.GitRef,
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("%w: %v", errExperimentRunLineage, err)
+		}
+	}
+
+	for _, dataset := range run.Datasets {
+		err = auditlog.Enqueue(ctx, tx, auditlog.Event{
+			OccurredAt:   run.CreatedAt,
+			Actor:        actor,
+			Action:       "quality_gate.allow",
+			ResourceType: "dataset_version",
+			ResourceID:   dataset.VersionID,
+			RequestID:    r.Header.Get("X-Request-Id"),
+			IP:           httpapi.RequestIP(r.RemoteAddr),
+			UserAgent:    r.UserAgent(),
+			Payload: map[string]any{
+				"service":            "experiments",
+				"dataset_id":         dataset.Gate.DatasetID,
+				"dataset_version_id": dataset.VersionID,
+				"rule_id":            dataset.Gate.RuleID,
+				"evaluation_id":      dataset.Gate.EvaluationID,
+				"status":             dataset.Gate.Status,
+				"experiment_id":      run.ExperimentID,
+				"run_id":             run.RunID,
+				"data_protection":    dataProtectionPayload(dataset.Gate.Protection),
+				"lifecycle_state":    string(dataset.Gate.Lifecycle),
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("%w: %v", errExperimentRunAudit, err)
+		}
+	}
+
+	err = auditlog.Enqueue(ctx, tx, auditlog.Event{
+		OccurredAt:   run.CreatedAt,
+		Actor:        actor,
+		Action:       "experiment_run.create",
+		ResourceType: "experiment_run",
+		ResourceID:   run.RunID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload: map[string]any{
+			"service":             "experiments",
+			"run_id":              run.RunID,
+			"experiment_id":       run.ExperimentID,
+			"dataset_version_id":  run.DatasetVersionID,
+			"dataset_version_ids": run.DatasetVersionIDs,
+			"status":              run.Status,
+			"started_at":          run.StartedAt.Format(time.RFC3339Nano),
+			"ended_at":            formatTimePtr(run.EndedAt),
+			"git_repo":            run.GitRepo,
+			"git_commit":          run.GitCommit,
+			"git_ref":             run.GitRef,
+			"params":              run.Params,
+			"metrics":             run.Metrics,
+			"artifacts_prefix":    run.ArtifactsPrefix,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %v", errExperimentRunAudit, err)
+	}
+	return nil
+}
+
+func (api *experimentsAPI) writeExperimentRunInsertError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case isForeignKeyViolation(err):
+		api.writeError(w, r, http.StatusNotFound, "not_found")
+	case errors.Is(err, errExperimentRunLineage):
+		api.writeError(w, r, http.StatusInternalServerError, "lineage_write_failed")
+	case errors.Is(err, errExperimentRunAudit):
+		api.writeError(w, r, http.StatusInternalServerError, "audit_failed")
+	default:
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+	}
+}
+
+// createExperimentRunInProcess calls the run creation handler in-process as
+// identity, so quality gates, dataset policies, fencing and the run audit
+// apply exactly as for an API call. requestID becomes the request ID of the
+// gate decisions.
+func (api *experimentsAPI) createExperimentRunInProcess(ctx context.Context, identity auth.Identity, experimentID, requestID, userAgent string, in createExperimentRunRequest) (*inProcessResponse, error) {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return nil, err
+	}
+	ctx = auth.ContextWithIdentity(ctx, identity)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "/experiments/"+experimentID+"/runs", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.SetPathValue("experiment_id", experimentID)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Request-Id", requestID)
+	req.Header.Set("User-Agent", userAgent)
+
+	rec := &inProcessResponse{header: make(http.Header)}
+	api.handleCreateExperimentRun(rec, req)
+	return rec, nil
+}
+
+// inProcessResponse collects the response of an in-process handler call.
+type inProcessResponse struct {
+	header http.Header
+	status int
+	buf    bytes.Buffer
+}
+
+func (s *inProcessResponse) Header() http.Header { return s.header }
+
+func (s *inProcessResponse) WriteHeader(status int) {
+	if s.status == 0 {
+		s.status = status
+	}
+}
+
+func (s *inProcessResponse) Write(p []byte) (int, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	return s.buf.Write(p)
+}
+
+// created reports whether the call created or, under dedupe fencing, reused
+// a run.
+func (s *inProcessResponse) created() bool {
+	return s.status == http.StatusCreated || s.status == http.StatusOK
+}
@@ -0,0 +1,649 @@
+package experiments
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	"github.com/animus-labs/animus-go/closed/internal/platform/lineageevent"
+	"github.com/google/uuid"
+)
+
+const maxSweepRuns = 100
+
+const (
+	sweepGoalMaximize = "maximize"
+	sweepGoalMinimize = "minimize"
+
+	sweepStatusPending   = "pending"
+	sweepStatusRunning   = "running"
+	sweepStatusSucceeded = "succeeded"
+	sweepStatusFailed    = "failed"
+	sweepStatusCompleted = "completed"
+)
+
+var (
+	errSweepParamsRequired = errors.New("grid or param_sets is required")
+	errSweepParamsConflict = errors.New("grid and param_sets are mutually exclusive")
+	errSweepGridEmpty      = errors.New("grid parameter has no values")
+	errSweepTooLarge       = errors.New("sweep exceeds the run limit")
+)
+
+type sweepObjective struct {
+	Metric string `json:"metric"`
+	Goal   string `json:"goal"`
+}
+
+type createSweepRequest struct {
+	DatasetVersionID  string           `json:"dataset_version_id,omitempty"`
+	DatasetVersionIDs []string         `json:"dataset_version_ids,omitempty"`
+	Status            string           `json:"status,omitempty"`
+	GitRepo           string           `json:"git_repo,omitempty"`
+	GitCommit         string           `json:"git_commit,omitempty"`
+	GitRef            string           `json:"git_ref,omitempty"`
+	BaseParams        map[string]any   `json:"base_params,omitempty"`
+	Grid              map[string][]any `json:"grid,omitempty"`
+	ParamSets         []map[string]any `json:"param_sets,omitempty"`
+	Objective         *sweepObjective  `json:"objective,omitempty"`
+}
+
+type sweepRun struct {
+	Position       int             `json:"position"`
+	RunID          string          `json:"run_id"`
+	Params         json.RawMessage `json:"params"`
+	Reused         bool            `json:"reused"`
+	Status         string          `json:"status,omitempty"`
+	ObjectiveValue *float64        `json:"objective_value,omitempty"`
+}
+
+type sweepBest struct {
+	RunID  string  `json:"run_id"`
+	Metric string  `json:"metric"`
+	Value  float64 `json:"value"`
+	Status string  `json:"status"`
+}
+
+type sweep struct {
+	SweepID      string          `json:"sweep_id"`
+	ExperimentID string          `json:"experiment_id"`
+	ProjectID    string          `json:"project_id"`
+	Objective    *sweepObjective `json:"objective,omitempty"`
+	RunCount     int             `json:"run_count"`
+	Status       string          `json:"status,omitempty"`
+	StatusCounts map[string]int  `json:"status_counts,omitempty"`
+	Best         *sweepBest      `json:"best,omitempty"`
+	Runs         []sweepRun      `json:"runs"`
+	CreatedAt    time.Time       `json:"created_at"`
+	CreatedBy    string          `json:"created_by"`
+}
+
+// handleCreateSweep registers one experiment run per parameter set in a
+// single transaction. Dataset gates and policies are evaluated once for the
+// sweep; fencing applies to every run, and an exclusive fence conflict
+// rejects the whole sweep.
+func (api *experimentsAPI) handleCreateSweep(w http.ResponseWriter, r *http.Request) {
+	identity, ok := auth.IdentityFromContext(r.Context())
+	if !ok || strings.TrimSpace(identity.Subject) == "" {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	experimentID := strings.TrimSpace(r.PathValue("experiment_id"))
+	if experimentID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "experiment_id_required")
+		return
+	}
+	projectID, err := projectIDForExperiment(r.Context(), api.db, experimentID)
+	if err != nil || strings.TrimSpace(projectID) == "" {
+		api.writeError(w, r, http.StatusNotFound, "not_found")
+		return
+	}
+	if !api.requireActiveProject(w, r, projectID) {
+		return
+	}
+	exists, err := api.experimentExists(r.Context(), experimentID)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if !exists {
+		api.writeError(w, r, http.StatusNotFound, "not_found")
+		return
+	}
+
+	var req createSweepRequest
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
+		return
+	}
+
+	status := strings.ToLower(strings.TrimSpace(req.Status))
+	if status == "" {
+		status = "pending"
+	}
+	if _, ok := allowedRunStatuses[status]; !ok {
+		api.writeError(w, r, http.StatusBadRequest, "invalid_status")
+		return
+	}
+
+	objective, ok := normalizeSweepObjective(req.Objective)
+	if !ok {
+		api.writeError(w, r, http.StatusBadRequest, "invalid_objective")
+		return
+	}
+
+	paramSets, err := expandSweepParams(req.BaseParams, req.Grid, req.ParamSets)
+	if err != nil {
+		switch {
+		case errors.Is(err, errSweepTooLarge):
+			api.writeError(w, r, http.StatusBadRequest, "sweep_too_large")
+		case errors.Is(err, errSweepParamsConflict):
+			api.writeError(w, r, http.StatusBadRequest, "sweep_params_conflict")
+		case errors.Is(err, errSweepGridEmpty):
+			api.writeError(w, r, http.StatusBadRequest, "invalid_grid")
+		default:
+			api.writeError(w, r, http.StatusBadRequest, "sweep_params_required")
+		}
+		return
+	}
+
+	datasetVersionIDs := runDatasetVersionIDs(req.DatasetVersionID, req.DatasetVersionIDs)
+	if len(datasetVersionIDs) > maxRunDatasetVersions {
+		api.writeError(w, r, http.StatusBadRequest, "too_many_dataset_versions")
+		return
+	}
+	datasets := make([]runDataset, 0, len(datasetVersionIDs))
+	for _, versionID := range datasetVersionIDs {
+		gate, ok := api.requireQualityGatePass(w, r, identity, versionID, experimentID)
+		if !ok {
+			return
+		}
+		policies, ok := api.requireDatasetPolicyAllow(w, r, identity, projectID, versionID, experimentID, gate)
+		if !ok {
+			return
+		}
+		datasets = append(datasets, runDataset{VersionID: versionID, Gate: gate, Policies: policies})
+	}
+	datasetVersionID := ""
+	if len(datasetVersionIDs) > 0 {
+		datasetVersionID = datasetVersionIDs[0]
+	}
+
+	now := time.Now().UTC()
+	sweepID := uuid.NewString()
+	gitRepo := strings.TrimSpace(req.GitRepo)
+	gitCommit := strings.TrimSpace(req.GitCommit)
+	gitRef := strings.TrimSpace(req.GitRef)
+
+	specJSON, err := json.Marshal(map[string]any{
+		"base_params":         req.BaseParams,
+		"grid":                req.Grid,
+		"param_sets":          req.ParamSets,
+		"dataset_version_ids": datasetVersionIDs,
+		"status":              status,
+		"git_repo":            gitRepo,
+		"git_commit":          gitCommit,
+		"git_ref":             gitRef,
+	})
+	if err != nil {
+		api.writeError(w, r, http.StatusBadRequest, "invalid_params")
+		return
+	}
+
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	out := sweep{
+		SweepID:      sweepID,
+		ExperimentID: experimentID,
+		ProjectID:    projectID,
+		Objective:    objective,
+		RunCount:     len(paramSets),
+		Runs:         make([]sweepRun, 0, len(paramSets)),
+		CreatedAt:    now,
+		CreatedBy:    identity.Subject,
+	}
+	integrity, err := integritySHA256(struct {
+		SweepID      string          `json:"sweep_id"`
+		ExperimentID string          `json:"experiment_id"`
+		ProjectID    string          `json:"project_id"`
+		Objective    *sweepObjective `json:"objective,omitempty"`
+		Spec         json.RawMessage `json:"spec"`
+		CreatedAt    time.Time       `json:"created_at"`
+		CreatedBy    string          `json:"created_by"`
+	}{sweepID, experimentID, projectID, objective, specJSON, now, identity.Subject})
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	var objectiveMetric, objectiveGoal string
+	if objective != nil {
+		objectiveMetric, objectiveGoal = objective.Metric, objective.Goal
+	}
+	_, err = tx.ExecContext(
+		r.Context(),
+		`INSERT INTO experiment_sweeps (sweep_id, experiment_id, project_id, objective_metric, objective_goal, spec, run_count, created_at, created_by, integrity_sha256)
+		 VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)`,
+		sweepID,
+		experimentID,
+		projectID,
+		nullString(objectiveMetric),
+		nullString(objectiveGoal),
+		specJSON,
+		len(paramSets),
+		now,
+		identity.Subject,
+		integrity,
+	)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	for position, params := range paramSets {
+		paramsJSON, err := json.Marshal(params)
+		if err != nil {
+			api.writeError(w, r, http.StatusBadRequest, "invalid_params")
+			return
+		}
+
+		fenceMode, fencedRunID, err := fenceExperimentRun(r.Context(), tx, experimentID, datasetVersionIDs, status, paramsJSON, gitCommit)
+		if err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+		member := sweepRun{Position: position, Params: paramsJSON}
+		switch {
+		case fencedRunID != "" && fenceMode == runFencingDedupe:
+			member.RunID = fencedRunID
+			member.Reused = true
+		case fencedRunID != "":
+			_ = tx.Rollback()
+			api.writeFencedRun(w, r, identity, experimentID, fenceMode, fencedRunID)
+			return
+		default:
+			run := experimentRunInsert{
+				RunID:             uuid.NewString(),
+				ExperimentID:      experimentID,
+				ProjectID:         projectID,
+				DatasetVersionID:  datasetVersionID,
+				DatasetVersionIDs: datasetVersionIDs,
+				Datasets:          datasets,
+				Status:            status,
+				StartedAt:         now,
+				GitRepo:           gitRepo,
+				GitCommit:         gitCommit,
+				GitRef:            gitRef,
+				Params:            params,
+				ParamsJSON:        paramsJSON,
+				Metrics:           map[string]any{},
+				MetricsJSON:       json.RawMessage(`{}`),
+				CreatedAt:         now,
+			}
+			run.IntegritySHA256, err = experimentRunIntegrity(run)
+			if err != nil {
+				api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+				return
+			}
+			if err := api.prepareRunConfigManifest(r.Context(), tx, &run); err != nil {
+				api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+				return
+			}
+			if err := insertExperimentRun(r, tx, identity.Subject, run); err != nil {
+				api.writeExperimentRunInsertError(w, r, err)
+				return
+			}
+			execution, err := api.evaluateExecutionPolicy(r.Context(), runExecutionPolicyInput(identity, run))
+			if err != nil {
+				api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+				return
+			}
+			if _, err := api.recordExecutionPolicy(r.Context(), tx, run.RunID, identity, execution); err != nil {
+				api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+				return
+			}
+			member.RunID = run.RunID
+		}
+
+		_, err = tx.ExecContext(
+			r.Context(),
+			`INSERT INTO experiment_sweep_runs (sweep_id, position, run_id, params, reused) VALUES ($1,$2,$3,$4,$5)`,
+			sweepID,
+			position,
+			member.RunID,
+			paramsJSON,
+			member.Reused,
+		)
+		if err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+		err = lineageevent.Enqueue(r.Context(), tx, lineageevent.Event{
+			OccurredAt:  now,
+			Actor:       identity.Subject,
+			RequestID:   r.Header.Get("X-Request-Id"),
+			SubjectType: "experiment_sweep",
+			SubjectID:   sweepID,
+			Predicate:   "contains",
+			ObjectType:  "experiment_run",
+			ObjectID:    member.RunID,
+			Metadata: map[string]any{
+				"experiment_id": experimentID,
+				"position":      position,
+				"reused":        member.Reused,
+			},
+		})
+		if err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "lineage_write_failed")
+			return
+		}
+		out.Runs = append(out.Runs, member)
+	}
+
+	runIDs := make([]string, 0, len(out.Runs))
+	reused := 0
+	for _, member := range out.Runs {
+		runIDs = append(runIDs, member.RunID)
+		if member.Reused {
+			reused++
+		}
+	}
+	err = auditlog.Enqueue(r.Context(), tx, auditlog.Event{
+		OccurredAt:   now,
+		Actor:        identity.Subject,
+		Action:       "experiment_sweep.create",
+		ResourceType: "experiment_sweep",
+		ResourceID:   sweepID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload: map[string]any{
+			"service":             "experiments",
+			"sweep_id":            sweepID,
+			"experiment_id":       experimentID,
+			"run_ids":             runIDs,
+			"run_count":           len(runIDs),
+			"reused_runs":         reused,
+			"dataset_version_ids": datasetVersionIDs,
+			"objective":           objective,
+		},
+	})
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "audit_failed")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	w.Header().Set("Location", "/sweeps/"+sweepID)
+	api.writeJSON(w, http.StatusCreated, out)
+}
+
+func (api *experimentsAPI) handleGetSweep(w http.ResponseWriter, r *http.Request) {
+	sweepID := strings.TrimSpace(r.PathValue("sweep_id"))
+	if sweepID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "sweep_id_required")
+		return
+	}
+
+	out, err := api.getSweep(r.Context(), sweepID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	api.writeJSON(w, http.StatusOK, out)
+}
+
+func (api *experimentsAPI) getSweep(ctx context.Context, sweepID string) (sweep, error) {
+	var (
+		out             sweep
+		objectiveMetric sql.NullString
+		objectiveGoal   sql.NullString
+	)
+	err := api.db.QueryRowContext(
+		ctx,
+		`SELECT sweep_id, experiment_id, project_id, objective_metric, objective_goal, run_count, created_at, created_by
+		 FROM experiment_sweeps
+		 WHERE sweep_id = $1`,
+		sweepID,
+	).Scan(&out.SweepID, &out.ExperimentID, &out.ProjectID, &objectiveMetric, &objectiveGoal, &out.RunCount, &out.CreatedAt, &out.CreatedBy)
+	if err != nil {
+		return sweep{}, err
+	}
+	out.CreatedAt = out.CreatedAt.UTC()
+	if metric := strings.TrimSpace(objectiveMetric.String); metric != "" {
+		out.Objective = &sweepObjective{Metric: metric, Goal: strings.TrimSpace(objectiveGoal.String)}
+	}
+
+	metricName := ""
+	if out.Objective != nil {
+		metricName = out.Objective.Metric
+	}
+	rows, err := api.db.QueryContext(
+		ctx,
+		`SELECT sr.position,
+				sr.run_id,
+				sr.params,
+				sr.reused,
+				COALESCE(s.status, r.status) AS status,
+				m.value,
+				r.metrics
+		 FROM experiment_sweep_runs sr
+		 JOIN experiment_runs r ON r.run_id = sr.run_id
+		 LEFT JOIN LATERAL (
+			SELECT status
+			FROM experiment_run_state_events
+			WHERE run_id = r.run_id
+			ORDER BY observed_at DESC
+			LIMIT 1
+		 ) s ON true
+		 LEFT JOIN LATERAL (
+			SELECT value
+			FROM experiment_run_metric_samples
+			WHERE run_id = r.run_id AND name = $2
+			ORDER BY step DESC
+			LIMIT 1
+		 ) m ON true
+		 WHERE sr.sweep_id = $1
+		 ORDER BY sr.position`,
+		sweepID,
+		metricName,
+	)
+	if err != nil {
+		return sweep{}, err
+	}
+	defer rows.Close()
+
+	out.Runs = []sweepRun{}
+	for rows.Next() {
+		var (
+			member  sweepRun
+			params  []byte
+			sample  sql.NullFloat64
+			metrics []byte
+		)
+		if err := rows.Scan(&member.Position, &member.RunID, &params, &member.Reused, &member.Status, &sample, &metrics); err != nil {
+			return sweep{}, err
+		}
+		member.Params = normalizeJSON(params)
+		if metricName != "" {
+			if sample.Valid {
+				value := sample.Float64
+				member.ObjectiveValue = &value
+			} else {
+				member.ObjectiveValue = finalMetricValue(metrics, metricName)
+			}
+		}
+		out.Runs = append(out.Runs, member)
+	}
+	if err := rows.Err(); err != nil {
+		return sweep{}, err
+	}
+
+	out.Status, out.StatusCounts = sweepAggregateStatus(out.Runs)
+	if out.Objective != nil {
+		out.Best = pickSweepBest(out.Runs, *out.Objective)
+	}
+	return out, nil
+}
+
+func normalizeSweepObjective(in *sweepObjective) (*sweepObjective, bool) {
+	if in == nil {
+		return nil, true
+	}
+	metric := strings.TrimSpace(in.Metric)
+	if metric == "" {
+		return nil, false
+	}
+	goal := strings.ToLower(strings.TrimSpace(in.Goal))
+	switch goal {
+	case "":
+		goal = sweepGoalMaximize
+	case sweepGoalMaximize, sweepGoalMinimize:
+	default:
+		return nil, false
+	}
+	return &sweepObjective{Metric: metric, Goal: goal}, true
+}
+
+// expandSweepParams returns one parameter set per run: the cartesian product
+// of the grid, or the explicit param sets, each layered over base.
+func expandSweepParams(base map[string]any, grid map[string][]any, sets []map[string]any) ([]map[string]any, error) {
+	switch {
+	case len(grid) > 0 && len(sets) > 0:
+		return nil, errSweepParamsConflict
+	case len(grid) == 0 && len(sets) == 0:
+		return nil, errSweepParamsRequired
+	}
+
+	if len(sets) > 0 {
+		if len(sets) > maxSweepRuns {
+			return nil, errSweepTooLarge
+		}
+		out := make([]map[string]any, 0, len(sets))
+		for _, set := range sets {
+			out = append(out, mergeSweepParams(base, set))
+		}
+		return out, nil
+	}
+
+	keys := make([]string, 0, len(grid))
+	total := 1
+	for key, values := range grid {
+		if strings.TrimSpace(key) == "" || len(values) == 0 {
+			return nil, errSweepGridEmpty
+		}
+		total *= len(values)
+		if total > maxSweepRuns {
+			return nil, errSweepTooLarge
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	out := []map[string]any{{}}
+	for _, key := range keys {
+		next := make([]map[string]any, 0, len(out)*len(grid[key]))
+		for _, partial := range out {
+			for _, value := range grid[key] {
+				combo := mergeSweepParams(partial, map[string]any{key: value})
+				next = append(next, combo)
+			}
+		}
+		out = next
+	}
+	for i, combo := range out {
+		out[i] = mergeSweepParams(base, combo)
+	}
+	return out, nil
+}
+
+func mergeSweepParams(base, overrides map[string]any) map[string]any {
+	out := make(map[string]any, len(base)+len(overrides))
+	for key, value := range base {
+		out[key] = value
+	}
+	for key, value := range overrides {
+		out[key] = value
+	}
+	return out
+}
+
+// sweepAggregateStatus summarizes member runs: pending or running while any
+// run is not finished, then succeeded or failed when all runs agree, and
+// completed for a mix of outcomes.
+func sweepAggregateStatus(runs []sweepRun) (string, map[string]int) {
+	counts := make(map[string]int)
+	for _, run := range runs {
+		counts[run.Status]++
+	}
+	switch {
+	case len(runs) == 0:
+		return sweepStatusPending, counts
+	case counts["running"] > 0:
+		return sweepStatusRunning, counts
+	case counts["pending"] > 0:
+		return sweepStatusPending, counts
+	case counts["succeeded"] == len(runs):
+		return sweepStatusSucceeded, counts
+	case counts["succeeded"] == 0:
+		return sweepStatusFailed, counts
+	default:
+		return sweepStatusCompleted, counts
+	}
+}
+
+func pickSweepBest(runs []sweepRun, objective sweepObjective) *sweepBest {
+	var best *sweepBest
+	for _, run := range runs {
+		if run.ObjectiveValue == nil {
+			continue
+		}
+		value := *run.ObjectiveValue
+		better := best == nil
+		if best != nil {
+			if objective.Goal == sweepGoalMinimize {
+				better = value < best.Value
+			} else {
+				better = value > best.Value
+			}
+		}
+		if better {
+			best = &sweepBest{RunID: run.RunID, Metric: objective.Metric, Value: value, Status: run.Status}
+		}
+	}
+	return best
+}
+
+// finalMetricValue reads a numeric metric recorded on the run itself, for
+// runs that report final metrics without samples.
+func finalMetricValue(raw []byte, name string) *float64 {
+	var metrics map[string]any
+	if err := json.Unmarshal(raw, &metrics); err != nil {
+		return nil
+	}
+	value, ok := metrics[name].(float64)
+	if !ok {
+		return nil
+	}
+	return &value
+}
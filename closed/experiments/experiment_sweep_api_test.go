@@ -0,0 +1,105 @@
+package experiments
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestExpandSweepParamsGrid(t *testing.T) {
+	sets, err := expandSweepParams(
+		map[string]any{"epochs": 10.0, "lr": 0.5},
+		map[string][]any{"lr": {0.1, 0.01}, "batch": {32.0, 64.0}},
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("expand: %v", err)
+	}
+	want := []map[string]any{
+		{"epochs": 10.0, "batch": 32.0, "lr": 0.1},
+		{"epochs": 10.0, "batch": 32.0, "lr": 0.01},
+		{"epochs": 10.0, "batch": 64.0, "lr": 0.1},
+		{"epochs": 10.0, "batch": 64.0, "lr": 0.01},
+	}
+	if !reflect.DeepEqual(sets, want) {
+		t.Fatalf("unexpected sets: %v", sets)
+	}
+}
+
+func TestExpandSweepParamsValidation(t *testing.T) {
+	cases := []struct {
+		name string
+		grid map[string][]any
+		sets []map[string]any
+		want error
+	}{
+		{name: "empty", want: errSweepParamsRequired},
+		{name: "both", grid: map[string][]any{"lr": {0.1}}, sets: []map[string]any{{"lr": 0.1}}, want: errSweepParamsConflict},
+		{name: "no values", grid: map[string][]any{"lr": {}}, want: errSweepGridEmpty},
+		{name: "too large", grid: map[string][]any{"a": make([]any, 11), "b": make([]any, 10)}, want: errSweepTooLarge},
+		{name: "too many sets", sets: make([]map[string]any, maxSweepRuns+1), want: errSweepTooLarge},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := expandSweepParams(nil, tc.grid, tc.sets); !errors.Is(err, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestSweepAggregateStatus(t *testing.T) {
+	runs := func(statuses ...string) []sweepRun {
+		out := make([]sweepRun, 0, len(statuses))
+		for _, status := range statuses {
+			out = append(out, sweepRun{Status: status})
+		}
+		return out
+	}
+	cases := map[string][]sweepRun{
+		sweepStatusRunning:   runs("succeeded", "running", "pending"),
+		sweepStatusPending:   runs("succeeded", "pending"),
+		sweepStatusSucceeded: runs("succeeded", "succeeded"),
+		sweepStatusFailed:    runs("failed", "canceled"),
+		sweepStatusCompleted: runs("succeeded", "failed"),
+	}
+	for want, members := range cases {
+		if got, _ := sweepAggregateStatus(members); got != want {
+			t.Fatalf("expected %s, got %s", want, got)
+		}
+	}
+}
+
+func TestPickSweepBest(t *testing.T) {
+	value := func(v float64) *float64 { return &v }
+	runs := []sweepRun{
+		{RunID: "a", Status: "succeeded", ObjectiveValue: value(0.7)},
+		{RunID: "b", Status: "succeeded", ObjectiveValue: value(0.9)},
+		{RunID: "c", Status: "running"},
+		{RunID: "d", Status: "failed", ObjectiveValue: value(0.2)},
+	}
+	best := pickSweepBest(runs, sweepObjective{Metric: "acc", Goal: sweepGoalMaximize})
+	if best == nil || best.RunID != "b" || best.Value != 0.9 {
+		t.Fatalf("unexpected maximize best: %+v", best)
+	}
+	best = pickSweepBest(runs, sweepObjective{Metric: "loss", Goal: sweepGoalMinimize})
+	if best == nil || best.RunID != "d" {
+		t.Fatalf("unexpected minimize best: %+v", best)
+	}
+	if best := pickSweepBest(runs[2:3], sweepObjective{Metric: "acc", Goal: sweepGoalMaximize}); best != nil {
+		t.Fatalf("expected no best, got %+v", best)
+	}
+}
+
+func TestNormalizeSweepObjective(t *testing.T) {
+	got, ok := normalizeSweepObjective(&sweepObjective{Metric: " acc "})
+	if !ok || got.Metric != "acc" || got.Goal != sweepGoalMaximize {
+		t.Fatalf("unexpected objective: %+v %v", got, ok)
+	}
+	if _, ok := normalizeSweepObjective(&sweepObjective{Metric: "acc", Goal: "highest"}); ok {
+		t.Fatalf("expected invalid goal")
+	}
+	if _, ok := normalizeSweepObjective(&sweepObjective{Goal: sweepGoalMinimize}); ok {
+		t.Fatalf("expected metric required")
+	}
+}
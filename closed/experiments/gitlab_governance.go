@@ -1,4 +1,4 @@
-package main
+package experiments
 
 import (
 	"context"
@@ -15,6 +15,7 @@ import (
 
 	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
 	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
 	"github.com/google/uuid"
 )
 
@@ -144,7 +145,7 @@ func (api *experimentsAPI) handleGitlabWebhook(w http.ResponseWriter, r *http.Re
 	var payload map[string]any
 	if err := json.Unmarshal(body, &payload); err != nil {
 		api.auditGitlabWebhookReject(r.Context(), identity, r, "", "invalid_json")
-		api.writeError(w, r, http.StatusBadRequest, "invalid_json")
+		httpapi.WriteDecodeError(w, r, err)
 		return
 	}
 
@@ -281,14 +282,14 @@ func (api *experimentsAPI) handleGitlabWebhook(w http.ResponseWriter, r *http.Re
 				return
 			}
 
-			_, auditErr := auditlog.Insert(r.Context(), tx, auditlog.Event{
+			auditErr := auditlog.Enqueue(r.Context(), tx, auditlog.Event{
 				OccurredAt:   now,
 				Actor:        identity.Subject,
 				Action:       "gitlab_webhook.duplicate",
 				ResourceType: "gitlab_governance",
 				ResourceID:   existingRecord.GovernanceID,
 				RequestID:    r.Header.Get("X-Request-Id"),
-				IP:           requestIP(r.RemoteAddr),
+				IP:           httpapi.RequestIP(r.RemoteAddr),
 				UserAgent:    r.UserAgent(),
 				Payload: map[string]any{
 					"service":        "experiments",
@@ -318,14 +319,14 @@ func (api *experimentsAPI) handleGitlabWebhook(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	_, err = auditlog.Insert(r.Context(), tx, auditlog.Event{
+	err = auditlog.Enqueue(r.Context(), tx, auditlog.Event{
 		OccurredAt:   now,
 		Actor:        identity.Subject,
 		Action:       "gitlab_governance.create",
 		ResourceType: "gitlab_governance",
 		ResourceID:   insertedID,
 		RequestID:    r.Header.Get("X-Request-Id"),
-		IP:           requestIP(r.RemoteAddr),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
 		UserAgent:    r.UserAgent(),
 		Payload: map[string]any{
 			"service":        "experiments",
@@ -387,14 +388,14 @@ func (api *experimentsAPI) auditGitlabWebhookReject(ctx context.Context, identit
 	}
 
 	now := time.Now().UTC()
-	_, _ = auditlog.Insert(ctx, api.db, auditlog.Event{
+	_ = auditlog.Enqueue(ctx, api.db, auditlog.Event{
 		OccurredAt:   now,
 		Actor:        identity.Subject,
 		Action:       "gitlab_webhook.reject",
 		ResourceType: "gitlab_webhook",
 		ResourceID:   r.Header.Get("X-Request-Id"),
 		RequestID:    r.Header.Get("X-Request-Id"),
-		IP:           requestIP(r.RemoteAddr),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
 		UserAgent:    r.UserAgent(),
 		Payload:      payload,
 	})
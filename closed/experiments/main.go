@@ -1,4 +1,4 @@
-package main
+package experiments
 
 import (
 	"context"
@@ -11,20 +11,33 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/animus-labs/animus-go/closed/internal/integrations/digests"
+	"github.com/animus-labs/animus-go/closed/internal/integrations/eventbus"
+	"github.com/animus-labs/animus-go/closed/internal/integrations/notify"
 	"github.com/animus-labs/animus-go/closed/internal/integrations/registryverify"
+	"github.com/animus-labs/animus-go/closed/internal/integrations/vulnscan"
 	"github.com/animus-labs/animus-go/closed/internal/integrations/webhooks"
 	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
 	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
 	"github.com/animus-labs/animus-go/closed/internal/platform/env"
+	"github.com/animus-labs/animus-go/closed/internal/platform/envelope"
 	"github.com/animus-labs/animus-go/closed/internal/platform/httpserver"
+	"github.com/animus-labs/animus-go/closed/internal/platform/inproc"
+	"github.com/animus-labs/animus-go/closed/internal/platform/migrate"
 	"github.com/animus-labs/animus-go/closed/internal/platform/objectstore"
+	"github.com/animus-labs/animus-go/closed/internal/platform/outbox"
+	"github.com/animus-labs/animus-go/closed/internal/platform/outbox/servicerelay"
+	"github.com/animus-labs/animus-go/closed/internal/platform/policy"
 	"github.com/animus-labs/animus-go/closed/internal/platform/postgres"
 	"github.com/animus-labs/animus-go/closed/internal/platform/rbac"
 	"github.com/animus-labs/animus-go/closed/internal/platform/secrets"
 	repopg "github.com/animus-labs/animus-go/closed/internal/repo/postgres"
+	"github.com/animus-labs/animus-go/closed/migrations"
+	"github.com/animus-labs/animus-go/core/contracts/openapi"
 )
 
-func main() {
+// Main runs experiments as a standalone service.
+func Main() {
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
 
 	ctx := context.Background()
@@ -38,38 +51,88 @@ func main() {
 		os.Exit(2)
 	}
 
-	rbacAllowDirect, err := env.Bool("AUTH_RBAC_ALLOW_DIRECT_ROLES", true)
+	dbCfg, err := postgres.ConfigFromEnv("experiments")
 	if err != nil {
-		logger.Error("invalid env", "error", err)
+		logger.Error("invalid database config", "error", err)
 		os.Exit(2)
 	}
-	profile := strings.ToLower(strings.TrimSpace(env.String("ANIMUS_PROFILE", "dev")))
-	if profile == "" {
-		profile = "dev"
+	db, err := postgres.Open(ctx, dbCfg)
+	if err != nil {
+		logger.Error("database unavailable", "error", err)
+		os.Exit(1)
 	}
-	if profile != "dev" && profile != "production" {
-		logger.Error("invalid profile", "profile", profile)
+	defer func() { _ = db.Close() }()
+
+	migrator, err := migrate.New(db, migrations.Files, logger)
+	if err != nil {
+		logger.Error("invalid migrations", "error", err)
 		os.Exit(2)
 	}
+	if done, err := migrate.Startup(ctx, migrator, os.Args[1:], os.Stdout); err != nil {
+		logger.Error("migrations failed", "error", err)
+		os.Exit(1)
+	} else if done {
+		return
+	}
 
-	dbCfg, err := postgres.ConfigFromEnv()
+	replicaCfg, err := postgres.ReplicaConfigFromEnv("experiments")
 	if err != nil {
-		logger.Error("invalid database config", "error", err)
+		logger.Error("invalid read replica config", "error", err)
 		os.Exit(2)
 	}
-	db, err := postgres.Open(ctx, dbCfg)
+	reads, err := postgres.OpenReplica(db, dbCfg, replicaCfg, logger)
 	if err != nil {
-		logger.Error("database unavailable", "error", err)
+		logger.Error("read replica init failed", "error", err)
+		os.Exit(2)
+	}
+	defer func() { _ = reads.Close() }()
+	httpserver.RegisterMetricsProvider(postgres.PrometheusMetrics("experiments", reads.Pools()))
+
+	internalMTLSCfg, err := auth.InternalMTLSConfigFromEnv()
+	if err != nil {
+		logger.Error("invalid internal mtls config", "error", err)
+		os.Exit(2)
+	}
+	internalServerTLS, err := internalMTLSCfg.ServerTLSConfig()
+	if err != nil {
+		logger.Error("internal mtls init failed", "error", err)
+		os.Exit(2)
+	}
+	handler := NewHandler(ctx, logger, &inproc.Resources{DB: db, Reads: reads, Migrator: migrator})
+
+	cfg := httpserver.Config{
+		Service:         "experiments",
+		Addr:            addr,
+		ShutdownTimeout: shutdownTimeout,
+		TLSConfig:       internalServerTLS,
+	}
+
+	if err := httpserver.Run(ctx, logger, cfg, httpserver.Wrap(logger, "experiments", handler)); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Error("server failed", "error", err)
 		os.Exit(1)
 	}
-	defer func() { _ = db.Close() }()
+}
 
-	storeCfg, err := objectstore.ConfigFromEnv()
+// NewHandler builds the experiments API on res and starts the reconcilers,
+// dispatchers and schedulers, which stop with ctx. Invalid configuration exits
+// the process, as it does in Main.
+func NewHandler(ctx context.Context, logger *slog.Logger, res *inproc.Resources) http.Handler {
+	db, reads := res.DB, res.Reads
+	rbacAllowDirect, err := env.Bool("AUTH_RBAC_ALLOW_DIRECT_ROLES", true)
 	if err != nil {
-		logger.Error("invalid object store config", "error", err)
+		logger.Error("invalid env", "error", err)
+		os.Exit(2)
+	}
+	profile := strings.ToLower(strings.TrimSpace(env.String("ANIMUS_PROFILE", "dev")))
+	if profile == "" {
+		profile = "dev"
+	}
+	if profile != "dev" && profile != "production" {
+		logger.Error("invalid profile", "profile", profile)
 		os.Exit(2)
 	}
-	storeClient, err := objectstore.NewMinIOClient(storeCfg)
+
+	storeClient, storeCfg, err := res.ObjectStore()
 	if err != nil {
 		logger.Error("object store client init failed", "error", err)
 		os.Exit(2)
@@ -82,12 +145,24 @@ func main() {
 	}
 	cancel()
 
-	internalAuthSecret := env.String("ANIMUS_INTERNAL_AUTH_SECRET", "")
-	headersAuth, err := auth.NewGatewayHeadersAuthenticator(internalAuthSecret)
+	headersAuth, err := auth.NewGatewayHeadersAuthenticator(env.String("ANIMUS_INTERNAL_AUTH_SECRET", ""))
 	if err != nil {
 		logger.Error("invalid internal auth config", "error", err)
 		os.Exit(2)
 	}
+	// Outgoing internal calls are signed with the newest secret only.
+	internalAuthSecret := headersAuth.Secret
+	internalMTLSCfg, err := auth.InternalMTLSConfigFromEnv()
+	if err != nil {
+		logger.Error("invalid internal mtls config", "error", err)
+		os.Exit(2)
+	}
+	headersAuth.Peers = internalMTLSCfg.PeerVerifier()
+	internalTransport, err := internalMTLSCfg.Transport()
+	if err != nil {
+		logger.Error("internal mtls init failed", "error", err)
+		os.Exit(2)
+	}
 
 	ciWebhookSecret := env.String("ANIMUS_CI_WEBHOOK_SECRET", "")
 	if ciWebhookSecret == "" {
@@ -103,14 +178,81 @@ func main() {
 		logger.Error("missing evidence signing secret", "env", "ANIMUS_EVIDENCE_SIGNING_SECRET")
 		os.Exit(2)
 	}
+	evidenceSigningCfg, err := evidenceSigningConfigFromEnv()
+	if err != nil {
+		logger.Error("invalid evidence signing config", "error", err)
+		os.Exit(2)
+	}
+	evidenceSigner, err := loadEvidenceSigner(evidenceSigningCfg)
+	if err != nil {
+		logger.Error("evidence signing key init failed", "error", err)
+		os.Exit(2)
+	}
+	startupCtx, cancel = context.WithTimeout(ctx, 5*time.Second)
+	if err := registerEvidenceSigningKey(startupCtx, db, evidenceSigner); err != nil {
+		cancel()
+		logger.Error("evidence signing key registration failed", "error", err)
+		os.Exit(1)
+	}
+	cancel()
 
 	gitlabWebhookSecret := strings.TrimSpace(env.String("ANIMUS_GITLAB_WEBHOOK_SECRET", ""))
 
+	outboxCfg, err := outbox.ConfigFromEnv()
+	if err != nil {
+		logger.Error("invalid outbox config", "error", err)
+		os.Exit(2)
+	}
+	busCfg, err := eventbus.ConfigFromEnv()
+	if err != nil {
+		logger.Error("invalid event bus config", "error", err)
+		os.Exit(2)
+	}
+	busPublisher, err := busCfg.NewPublisher()
+	if err != nil {
+		logger.Error("event bus init failed", "error", err)
+		os.Exit(2)
+	}
 	webhookCfg, err := webhooks.ConfigFromEnv()
 	if err != nil {
 		logger.Error("invalid webhook config", "error", err)
 		os.Exit(2)
 	}
+	digestSMTPCfg, err := digests.SMTPConfigFromEnv()
+	if err != nil {
+		logger.Error("invalid digest smtp config", "error", err)
+		os.Exit(2)
+	}
+	digestInterval, err := env.Duration("ANIMUS_DIGEST_POLL_INTERVAL", 5*time.Minute)
+	if err != nil {
+		logger.Error("invalid digest poll interval", "error", err)
+		os.Exit(2)
+	}
+	runSchedulerInterval, err := env.Duration("ANIMUS_RUN_SCHEDULER_INTERVAL", 30*time.Second)
+	if err != nil {
+		logger.Error("invalid run scheduler interval", "error", err)
+		os.Exit(2)
+	}
+	pipelineOrchestratorInterval, err := env.Duration("ANIMUS_PIPELINE_ORCHESTRATOR_INTERVAL", 15*time.Second)
+	if err != nil {
+		logger.Error("invalid pipeline orchestrator interval", "error", err)
+		os.Exit(2)
+	}
+	approvalEscalationInterval, err := env.Duration("ANIMUS_POLICY_APPROVAL_ESCALATION_INTERVAL", time.Minute)
+	if err != nil {
+		logger.Error("invalid policy approval escalation interval", "error", err)
+		os.Exit(2)
+	}
+	approvalNotifyCfg, err := notify.ConfigFromEnv()
+	if err != nil {
+		logger.Error("invalid approval notification config", "error", err)
+		os.Exit(2)
+	}
+	runQueueCfg, err := runQueueConfigFromEnv()
+	if err != nil {
+		logger.Error("invalid run queue config", "error", err)
+		os.Exit(2)
+	}
 
 	secretsCfg, err := secrets.ConfigFromEnv()
 	if err != nil {
@@ -160,17 +302,39 @@ func main() {
 		os.Exit(2)
 	}
 
+	metricAnomalyCfg, err := metricAnomalyConfigFromEnv()
+	if err != nil {
+		logger.Error("invalid metric anomaly config", "error", err)
+		os.Exit(2)
+	}
+	energyCfg, err := energyConfigFromEnv()
+	if err != nil {
+		logger.Error("invalid energy config", "error", err)
+		os.Exit(2)
+	}
+
+	rbacPermissionsTTL, err := env.Duration("AUTH_RBAC_PERMISSIONS_CACHE_TTL", 30*time.Second)
+	if err != nil {
+		logger.Error("invalid rbac permissions cache ttl", "error", err)
+		os.Exit(2)
+	}
+
 	roleBindings := repopg.NewRoleBindingStore(db)
 	auditAppender := repopg.NewAuditAppender(db, nil)
+	permissionCache := rbac.NewPermissionCache(repopg.NewRBACPermissionStore(db), "experiments", rbacPermissionsTTL)
 	authorizer := rbac.Authorizer{
 		Store:           roleBindings,
 		Audit:           auditAppender,
 		AllowDirect:     rbacAllowDirect,
 		RequiredRoleFor: experimentsRequiredRole,
+		Permissions:     permissionCache,
+		AuditorScope:    experimentsAuditorScope,
 	}
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/healthz", httpserver.Healthz("experiments"))
+	mux.HandleFunc("/version", httpserver.BuildInfo("experiments"))
+	mux.Handle("GET "+openapi.ServicePath, openapi.Handler(openapi.Experiments))
 	mux.HandleFunc(
 		"/readyz",
 		httpserver.ReadyzWithChecks(
@@ -208,6 +372,46 @@ func main() {
 		logger.Error("invalid dp heartbeat stale after", "error", err)
 		os.Exit(2)
 	}
+	artifactBlobGCInterval, err := env.Duration("ANIMUS_ARTIFACT_BLOB_GC_INTERVAL", time.Hour)
+	if err != nil {
+		logger.Error("invalid artifact blob gc interval", "error", err)
+		os.Exit(2)
+	}
+	artifactBlobGCGrace, err := env.Duration("ANIMUS_ARTIFACT_BLOB_GC_GRACE", 24*time.Hour)
+	if err != nil || artifactBlobGCGrace < 0 {
+		logger.Error("invalid artifact blob gc grace", "error", err)
+		os.Exit(2)
+	}
+	artifactRetentionInterval, err := env.Duration("ANIMUS_ARTIFACT_RETENTION_INTERVAL", time.Hour)
+	if err != nil {
+		logger.Error("invalid artifact retention interval", "error", err)
+		os.Exit(2)
+	}
+	metricImportInterval, err := env.Duration("ANIMUS_METRIC_IMPORT_INTERVAL", 10*time.Second)
+	if err != nil {
+		logger.Error("invalid metric import interval", "error", err)
+		os.Exit(2)
+	}
+	checkpointURLTTL, err := env.Duration("ANIMUS_CHECKPOINT_URL_TTL", defaultCheckpointURLTTL)
+	if err != nil || checkpointURLTTL <= 0 || checkpointURLTTL > 7*24*time.Hour {
+		logger.Error("invalid checkpoint url ttl", "error", err)
+		os.Exit(2)
+	}
+	datasetURLTTL, err := env.Duration("ANIMUS_DATASET_URL_TTL", defaultDatasetURLTTL)
+	if err != nil || datasetURLTTL <= 0 || datasetURLTTL > 7*24*time.Hour {
+		logger.Error("invalid dataset url ttl", "error", err)
+		os.Exit(2)
+	}
+	evalPreviewSamples, err := env.Int("ANIMUS_EVAL_PREVIEW_SAMPLES", defaultEvalPreviewSamples)
+	if err != nil || evalPreviewSamples < 1 || evalPreviewSamples > evalPreviewSamplesMax {
+		logger.Error("invalid eval preview samples", "error", err)
+		os.Exit(2)
+	}
+	previewRedactPaths, err := parsePreviewRedactPaths(os.Getenv("ANIMUS_EVAL_PREVIEW_REDACT_PATHS"))
+	if err != nil {
+		logger.Error("invalid eval preview redact paths", "error", err)
+		os.Exit(2)
+	}
 	registryCfg, err := registryverify.ConfigFromEnv()
 	if err != nil {
 		logger.Error("invalid registry config", "error", err)
@@ -217,11 +421,37 @@ func main() {
 		Default: registryCfg.DefaultPolicy(),
 		Store:   repopg.NewRegistryPolicyStore(db),
 	}
+	runImageVerifyCfg, err := runImageVerifyConfigFromEnv()
+	if err != nil {
+		logger.Error("invalid run image verify config", "error", err)
+		os.Exit(2)
+	}
+	vulnScanCfg, err := vulnscan.ConfigFromEnv()
+	if err != nil {
+		logger.Error("invalid vulnerability scanner config", "error", err)
+		os.Exit(2)
+	}
 	registryProviders := map[string]registryverify.Provider{
 		registryverify.ProviderNoop:       registryverify.NoopProvider{},
 		registryverify.ProviderCosignStub: registryverify.CosignStubProvider{},
 	}
 
+	encryptionCfg, err := envelope.ConfigFromEnv()
+	if err != nil {
+		logger.Error("invalid encryption config", "error", err)
+		os.Exit(2)
+	}
+	encrypter, err := envelope.New(encryptionCfg)
+	if err != nil {
+		logger.Error("encryption init failed", "error", err)
+		os.Exit(2)
+	}
+	opaCfg, err := policy.OPAConfigFromEnv()
+	if err != nil {
+		logger.Error("invalid policy opa config", "error", err)
+		os.Exit(2)
+	}
+
 	api := newExperimentsAPI(
 		logger,
 		db,
@@ -245,10 +475,37 @@ func main() {
 		devEnvServiceDomain,
 		devEnvCodeServerPort,
 	)
+	api.reads = reads
+	api.permissionCache = permissionCache
+	api.metricAnomalies = newMetricAnomalyDetector(metricAnomalyCfg)
+	api.internalTransport = internalTransport
+	api.evidenceSigner = evidenceSigner
+	api.encrypter = encrypter
+	api.policyEvaluator = policy.Evaluator{OPA: policy.NewOPAClient(opaCfg)}
+	api.digestMailer = digests.NewMailer(digestSMTPCfg)
+	approvalSenders, err := approvalNotifyCfg.Senders(api.digestMailer)
+	if err != nil {
+		logger.Error("invalid approval notification config", "error", err)
+		os.Exit(2)
+	}
+	api.approvalNotifier = notify.New(approvalSenders...)
+	api.approvalPendingAfter = approvalNotifyCfg.PendingAfter
+	api.runQueue = runQueueCfg
+	api.runImageVerify = runImageVerifyCfg
+	api.checkpointURLTTL = checkpointURLTTL
+	api.datasetURLTTL = datasetURLTTL
+	api.evalPreviewSamples = evalPreviewSamples
+	api.previewRedactors = []previewRedactor{previewRedactPaths}
+	api.energy = energyCfg
+	api.runConfigEnv = runConfigEnvironment(os.LookupEnv)
+	if scanner := vulnscan.NewHTTPScanner(vulnScanCfg); scanner != nil {
+		api.vulnScanner = scanner
+	}
+	api.bus = servicerelay.Bus{Enabled: busPublisher != nil}
 	api.register(mux)
 
-	startDPReconciler(ctx, logger, db, dataplaneURL, internalAuthSecret, dpReconcileInterval, dpHeartbeatStaleAfter)
-	startDevEnvReconciler(ctx, logger, db, dataplaneURL, internalAuthSecret, devEnvReconcileInterval)
+	startDPReconciler(ctx, logger, db, dataplaneURL, internalAuthSecret, internalTransport, dpReconcileInterval, dpHeartbeatStaleAfter)
+	startDevEnvReconciler(ctx, logger, db, dataplaneURL, internalAuthSecret, internalTransport, devEnvReconcileInterval)
 	webhookWorker := webhooks.NewWorker(
 		repopg.NewWebhookSubscriptionStore(db),
 		repopg.NewWebhookDeliveryStore(db),
@@ -259,8 +516,18 @@ func main() {
 		webhookCfg,
 	)
 	startWebhookDispatcher(ctx, logger, webhookWorker)
+	startDigestScheduler(ctx, logger, api, digestInterval)
+	startRunScheduler(ctx, logger, api, runSchedulerInterval)
+	startPipelineOrchestrator(ctx, logger, api, pipelineOrchestratorInterval, dpHeartbeatStaleAfter)
+	startApprovalEscalations(ctx, logger, api, approvalEscalationInterval)
+	startApprovalNotifications(ctx, logger, api, approvalNotifyCfg.PollInterval)
+	startRunQueue(ctx, logger, api)
+	servicerelay.Start(ctx, logger, db, outboxCfg, busPublisher, busCfg.TopicPrefix)
+	startArtifactBlobGC(ctx, logger, db, storeClient, storeCfg.BucketArtifacts, artifactBlobGCInterval, artifactBlobGCGrace)
+	startArtifactRetention(ctx, logger, api, artifactRetentionInterval)
+	startMetricImports(ctx, logger, api, metricImportInterval)
 
-	handler := auth.Middleware{
+	return auth.Middleware{
 		Logger:         logger,
 		Authenticator:  headersAuth,
 		Authorize:      authorizer.Authorize,
@@ -270,17 +537,6 @@ func main() {
 			defer cancel()
 			return auditlog.InsertAuthDeny(auditCtx, db, "experiments", event)
 		},
-		SkipPrefixes: []string{"/healthz", "/readyz"},
+		SkipPrefixes: []string{"/healthz", "/readyz", "/version", openapi.ServicePath},
 	}.Wrap(mux)
-
-	cfg := httpserver.Config{
-		Service:         "experiments",
-		Addr:            addr,
-		ShutdownTimeout: shutdownTimeout,
-	}
-
-	if err := httpserver.Run(ctx, logger, cfg, httpserver.Wrap(logger, "experiments", handler)); err != nil && !errors.Is(err, http.ErrServerClosed) {
-		logger.Error("server failed", "error", err)
-		os.Exit(1)
-	}
 }
@@ -0,0 +1,428 @@
+package experiments
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/env"
+)
+
+const (
+	metricAnomalyActor = "system:metric-analyzer"
+
+	metricAnomalyNonFinite  = "non_finite"
+	metricAnomalyDivergence = "divergence"
+	metricAnomalyPlateau    = "plateau"
+)
+
+type metricAnomalyConfig struct {
+	Enabled bool
+	// LossMetrics lists metric names checked for divergence and plateaus.
+	LossMetrics []string
+	// DivergenceRatio flags a loss that rose this many times its best magnitude above the best value.
+	DivergenceRatio float64
+	// PlateauSteps flags a loss that has not improved for this many steps; 0 disables the check.
+	PlateauSteps int64
+	// PlateauMinDelta is the relative improvement required to reset the plateau window.
+	PlateauMinDelta float64
+	// FailNonFiniteSteps fails the run once a metric stays NaN/inf for this many steps; 0 disables it.
+	FailNonFiniteSteps int64
+	FailOnDivergence   bool
+	// IdleTTL drops analyzer state for runs that stopped reporting metrics.
+	IdleTTL time.Duration
+}
+
+func metricAnomalyConfigFromEnv() (metricAnomalyConfig, error) {
+	enabled, err := env.Bool("ANIMUS_METRIC_ANOMALY_ENABLED", false)
+	if err != nil {
+		return metricAnomalyConfig{}, err
+	}
+	divergenceRatio, err := env.Float("ANIMUS_METRIC_ANOMALY_DIVERGENCE_RATIO", 10)
+	if err != nil {
+		return metricAnomalyConfig{}, err
+	}
+	plateauSteps, err := env.Int("ANIMUS_METRIC_ANOMALY_PLATEAU_STEPS", 5000)
+	if err != nil {
+		return metricAnomalyConfig{}, err
+	}
+	plateauMinDelta, err := env.Float("ANIMUS_METRIC_ANOMALY_PLATEAU_MIN_DELTA", 0.001)
+	if err != nil {
+		return metricAnomalyConfig{}, err
+	}
+	failNonFiniteSteps, err := env.Int("ANIMUS_METRIC_ANOMALY_FAIL_NONFINITE_STEPS", 0)
+	if err != nil {
+		return metricAnomalyConfig{}, err
+	}
+	failOnDivergence, err := env.Bool("ANIMUS_METRIC_ANOMALY_FAIL_ON_DIVERGENCE", false)
+	if err != nil {
+		return metricAnomalyConfig{}, err
+	}
+	idleTTL, err := env.Duration("ANIMUS_METRIC_ANOMALY_IDLE_TTL", 6*time.Hour)
+	if err != nil {
+		return metricAnomalyConfig{}, err
+	}
+
+	cfg := metricAnomalyConfig{
+		Enabled:            enabled,
+		LossMetrics:        parseMetricNames(env.String("ANIMUS_METRIC_ANOMALY_LOSS_METRICS", "loss,train_loss,val_loss,eval_loss")),
+		DivergenceRatio:    divergenceRatio,
+		PlateauSteps:       int64(plateauSteps),
+		PlateauMinDelta:    plateauMinDelta,
+		FailNonFiniteSteps: int64(failNonFiniteSteps),
+		FailOnDivergence:   failOnDivergence,
+		IdleTTL:            idleTTL,
+	}
+	if err := cfg.Validate(); err != nil {
+		return metricAnomalyConfig{}, err
+	}
+	return cfg, nil
+}
+
+func (c metricAnomalyConfig) Validate() error {
+	if c.DivergenceRatio <= 0 || math.IsNaN(c.DivergenceRatio) || math.IsInf(c.DivergenceRatio, 0) {
+		return fmt.Errorf("metric anomaly divergence ratio must be positive")
+	}
+	if c.PlateauSteps < 0 {
+		return fmt.Errorf("metric anomaly plateau steps must be >= 0")
+	}
+	if c.PlateauMinDelta < 0 || math.IsNaN(c.PlateauMinDelta) || math.IsInf(c.PlateauMinDelta, 0) {
+		return fmt.Errorf("metric anomaly plateau min delta must be >= 0")
+	}
+	if c.FailNonFiniteSteps < 0 {
+		return fmt.Errorf("metric anomaly fail non-finite steps must be >= 0")
+	}
+	if c.IdleTTL <= 0 {
+		return fmt.Errorf("metric anomaly idle ttl must be positive")
+	}
+	return nil
+}
+
+func parseMetricNames(raw string) []string {
+	seen := map[string]struct{}{}
+	out := make([]string, 0)
+	for _, part := range strings.Split(raw, ",") {
+		name := strings.TrimSpace(part)
+		if name == "" {
+			continue
+		}
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// parseNonFiniteMetric accepts the string spellings training frameworks use for
+// values JSON cannot encode as numbers.
+func parseNonFiniteMetric(raw string) (float64, bool) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "nan", "-nan", "+nan":
+		return math.NaN(), true
+	case "inf", "+inf", "infinity", "+infinity":
+		return math.Inf(1), true
+	case "-inf", "-infinity":
+		return math.Inf(-1), true
+	default:
+		return 0, false
+	}
+}
+
+type metricAnomaly struct {
+	Kind      string   `json:"kind"`
+	Metric    string   `json:"metric"`
+	Step      int64    `json:"step"`
+	Value     string   `json:"value"`
+	Reference *float64 `json:"reference,omitempty"`
+	SinceStep int64    `json:"since_step"`
+	Fail      bool     `json:"fail"`
+}
+
+func (a metricAnomaly) message() string {
+	switch a.Kind {
+	case metricAnomalyNonFinite:
+		if a.Fail {
+			return fmt.Sprintf("metric %s non-finite since step %d; failing run", a.Metric, a.SinceStep)
+		}
+		return fmt.Sprintf("metric %s is non-finite (%s) at step %d", a.Metric, a.Value, a.Step)
+	case metricAnomalyDivergence:
+		if a.Fail {
+			return fmt.Sprintf("metric %s diverged at step %d; failing run", a.Metric, a.Step)
+		}
+		return fmt.Sprintf("metric %s diverged at step %d", a.Metric, a.Step)
+	case metricAnomalyPlateau:
+		return fmt.Sprintf("metric %s has not improved since step %d", a.Metric, a.SinceStep)
+	default:
+		return fmt.Sprintf("metric %s anomaly at step %d", a.Metric, a.Step)
+	}
+}
+
+func (a metricAnomaly) metadata() map[string]any {
+	meta := map[string]any{
+		"analyzer":   "metric_anomaly",
+		"kind":       a.Kind,
+		"metric":     a.Metric,
+		"step":       a.Step,
+		"value":      a.Value,
+		"since_step": a.SinceStep,
+		"fail":       a.Fail,
+	}
+	if a.Reference != nil {
+		meta["reference"] = *a.Reference
+	}
+	return meta
+}
+
+type metricSeriesState struct {
+	nonFiniteSince    int64
+	nonFinite         bool
+	nonFiniteReported bool
+
+	hasBest            bool
+	best               float64
+	bestStep           int64
+	divergenceReported bool
+	plateauReported    bool
+}
+
+type runMetricState struct {
+	lastSeen time.Time
+	failed   bool
+	series   map[string]*metricSeriesState
+}
+
+// metricAnomalyDetector keeps per-run metric history in memory so ingested
+// samples can be checked without re-reading the series from Postgres.
+type metricAnomalyDetector struct {
+	cfg  metricAnomalyConfig
+	loss map[string]struct{}
+	now  func() time.Time
+
+	mu        sync.Mutex
+	runs      map[string]*runMetricState
+	lastPrune time.Time
+}
+
+func newMetricAnomalyDetector(cfg metricAnomalyConfig) *metricAnomalyDetector {
+	if !cfg.Enabled {
+		return nil
+	}
+	loss := make(map[string]struct{}, len(cfg.LossMetrics))
+	for _, name := range cfg.LossMetrics {
+		loss[name] = struct{}{}
+	}
+	return &metricAnomalyDetector{
+		cfg:  cfg,
+		loss: loss,
+		now:  time.Now,
+		runs: map[string]*runMetricState{},
+	}
+}
+
+func (d *metricAnomalyDetector) Enabled() bool {
+	return d != nil
+}
+
+// Observe records one ingested step and returns anomalies that newly appeared.
+// Each condition is reported once until the series recovers.
+func (d *metricAnomalyDetector) Observe(runID string, step int64, metrics map[string]float64) []metricAnomaly {
+	if d == nil || len(metrics) == 0 {
+		return nil
+	}
+	now := d.now().UTC()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.pruneLocked(now)
+
+	run, ok := d.runs[runID]
+	if !ok {
+		run = &runMetricState{series: map[string]*metricSeriesState{}}
+		d.runs[runID] = run
+	}
+	run.lastSeen = now
+	if run.failed {
+		return nil
+	}
+
+	names := make([]string, 0, len(metrics))
+	for name := range metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	out := make([]metricAnomaly, 0)
+	for _, name := range names {
+		series, ok := run.series[name]
+		if !ok {
+			series = &metricSeriesState{}
+			run.series[name] = series
+		}
+		_, isLoss := d.loss[name]
+		if anomaly, ok := d.observeSeries(series, name, isLoss, step, metrics[name]); ok {
+			out = append(out, anomaly)
+			if anomaly.Fail {
+				run.failed = true
+				break
+			}
+		}
+	}
+	return out
+}
+
+func (d *metricAnomalyDetector) observeSeries(series *metricSeriesState, name string, isLoss bool, step int64, value float64) (metricAnomaly, bool) {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		if !series.nonFinite {
+			series.nonFinite = true
+			series.nonFiniteSince = step
+		}
+		anomaly := metricAnomaly{
+			Kind:      metricAnomalyNonFinite,
+			Metric:    name,
+			Step:      step,
+			Value:     formatMetricValue(value),
+			SinceStep: series.nonFiniteSince,
+		}
+		if d.cfg.FailNonFiniteSteps > 0 && step-series.nonFiniteSince+1 >= d.cfg.FailNonFiniteSteps {
+			anomaly.Fail = true
+			return anomaly, true
+		}
+		if series.nonFiniteReported {
+			return metricAnomaly{}, false
+		}
+		series.nonFiniteReported = true
+		return anomaly, true
+	}
+	series.nonFinite = false
+	series.nonFiniteReported = false
+
+	if !isLoss {
+		return metricAnomaly{}, false
+	}
+	if !series.hasBest {
+		series.hasBest = true
+		series.best = value
+		series.bestStep = step
+		return metricAnomaly{}, false
+	}
+
+	scale := math.Max(math.Abs(series.best), 1e-12)
+	if value < series.best-d.cfg.PlateauMinDelta*scale {
+		series.best = value
+		series.bestStep = step
+		series.divergenceReported = false
+		series.plateauReported = false
+		return metricAnomaly{}, false
+	}
+	if value < series.best {
+		series.best = value
+	}
+
+	if value-series.best > d.cfg.DivergenceRatio*scale {
+		if series.divergenceReported {
+			return metricAnomaly{}, false
+		}
+		series.divergenceReported = true
+		best := series.best
+		return metricAnomaly{
+			Kind:      metricAnomalyDivergence,
+			Metric:    name,
+			Step:      step,
+			Value:     formatMetricValue(value),
+			Reference: &best,
+			SinceStep: series.bestStep,
+			Fail:      d.cfg.FailOnDivergence,
+		}, true
+	}
+	series.divergenceReported = false
+
+	if d.cfg.PlateauSteps > 0 && !series.plateauReported && step-series.bestStep >= d.cfg.PlateauSteps {
+		series.plateauReported = true
+		best := series.best
+		return metricAnomaly{
+			Kind:      metricAnomalyPlateau,
+			Metric:    name,
+			Step:      step,
+			Value:     formatMetricValue(value),
+			Reference: &best,
+			SinceStep: series.bestStep,
+		}, true
+	}
+	return metricAnomaly{}, false
+}
+
+func (d *metricAnomalyDetector) pruneLocked(now time.Time) {
+	if now.Sub(d.lastPrune) < time.Minute {
+		return
+	}
+	d.lastPrune = now
+	for runID, run := range d.runs {
+		if now.Sub(run.lastSeen) > d.cfg.IdleTTL {
+			delete(d.runs, runID)
+		}
+	}
+}
+
+func formatMetricValue(value float64) string {
+	switch {
+	case math.IsNaN(value):
+		return "NaN"
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	default:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+}
+
+// recordMetricAnomalies writes one run event per anomaly and, for fail-level
+// anomalies, moves a still-active run to failed. It reports whether the run was failed.
+func (api *experimentsAPI) recordMetricAnomalies(ctx context.Context, tx *sql.Tx, runID string, anomalies []metricAnomaly) (bool, error) {
+	var failing *metricAnomaly
+	for i := range anomalies {
+		anomaly := anomalies[i]
+		level := "warn"
+		if anomaly.Fail {
+			level = "error"
+			failing = &anomalies[i]
+		}
+		if err := api.insertRunEvent(ctx, tx, runID, metricAnomalyActor, level, anomaly.message(), anomaly.metadata()); err != nil {
+			return false, err
+		}
+	}
+	if failing == nil {
+		return false, nil
+	}
+
+	var status string
+	err := tx.QueryRowContext(ctx,
+		`SELECT COALESCE(
+			(SELECT status FROM experiment_run_state_events WHERE run_id = $1 ORDER BY observed_at DESC LIMIT 1),
+			r.status
+		 )
+		 FROM experiment_runs r
+		 WHERE r.run_id = $1`,
+		runID,
+	).Scan(&status)
+	if err != nil {
+		return false, err
+	}
+	switch strings.ToLower(strings.TrimSpace(status)) {
+	case "succeeded", "failed", "canceled":
+		return false, nil
+	}
+
+	details := failing.metadata()
+	details["reason"] = "metric_anomaly"
+	return api.insertRunStateEvent(ctx, tx, runID, "failed", time.Now().UTC(), details)
+}
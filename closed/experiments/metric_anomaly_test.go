@@ -0,0 +1,109 @@
+package experiments
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func testMetricAnomalyConfig() metricAnomalyConfig {
+	return metricAnomalyConfig{
+		Enabled:            true,
+		LossMetrics:        []string{"loss"},
+		DivergenceRatio:    10,
+		PlateauSteps:       100,
+		PlateauMinDelta:    0.01,
+		FailNonFiniteSteps: 100,
+		IdleTTL:            time.Hour,
+	}
+}
+
+func TestMetricAnomalyNonFiniteFailsAfterConsecutiveSteps(t *testing.T) {
+	detector := newMetricAnomalyDetector(testMetricAnomalyConfig())
+
+	got := detector.Observe("run-1", 10, map[string]float64{"loss": math.NaN(), "accuracy": 0.5})
+	if len(got) != 1 || got[0].Kind != metricAnomalyNonFinite || got[0].Fail {
+		t.Fatalf("expected a single non-fatal non_finite anomaly, got %+v", got)
+	}
+	if got := detector.Observe("run-1", 50, map[string]float64{"loss": math.Inf(1)}); len(got) != 0 {
+		t.Fatalf("expected repeated non-finite value to be reported once, got %+v", got)
+	}
+	got = detector.Observe("run-1", 109, map[string]float64{"loss": math.NaN()})
+	if len(got) != 1 || !got[0].Fail || got[0].SinceStep != 10 {
+		t.Fatalf("expected fail after 100 non-finite steps, got %+v", got)
+	}
+	if got := detector.Observe("run-1", 110, map[string]float64{"loss": math.NaN()}); len(got) != 0 {
+		t.Fatalf("expected failed run to be ignored, got %+v", got)
+	}
+}
+
+func TestMetricAnomalyNonFiniteResetsOnFiniteValue(t *testing.T) {
+	detector := newMetricAnomalyDetector(testMetricAnomalyConfig())
+
+	detector.Observe("run-1", 0, map[string]float64{"loss": math.NaN()})
+	detector.Observe("run-1", 50, map[string]float64{"loss": 1.0})
+	got := detector.Observe("run-1", 120, map[string]float64{"loss": math.NaN()})
+	if len(got) != 1 || got[0].Fail || got[0].SinceStep != 120 {
+		t.Fatalf("expected fresh non-fatal anomaly after recovery, got %+v", got)
+	}
+}
+
+func TestMetricAnomalyDivergenceAndPlateau(t *testing.T) {
+	cfg := testMetricAnomalyConfig()
+	cfg.FailOnDivergence = true
+	detector := newMetricAnomalyDetector(cfg)
+
+	detector.Observe("run-1", 0, map[string]float64{"loss": 2.0})
+	detector.Observe("run-1", 10, map[string]float64{"loss": 1.0})
+	got := detector.Observe("run-1", 20, map[string]float64{"loss": 50.0})
+	if len(got) != 1 || got[0].Kind != metricAnomalyDivergence || !got[0].Fail {
+		t.Fatalf("expected fatal divergence, got %+v", got)
+	}
+
+	detector = newMetricAnomalyDetector(testMetricAnomalyConfig())
+	detector.Observe("run-2", 0, map[string]float64{"loss": 1.0})
+	if got := detector.Observe("run-2", 99, map[string]float64{"loss": 0.999}); len(got) != 0 {
+		t.Fatalf("unexpected anomaly before plateau window: %+v", got)
+	}
+	got = detector.Observe("run-2", 100, map[string]float64{"loss": 0.998})
+	if len(got) != 1 || got[0].Kind != metricAnomalyPlateau || got[0].SinceStep != 0 {
+		t.Fatalf("expected plateau anomaly, got %+v", got)
+	}
+	if got := detector.Observe("run-2", 150, map[string]float64{"loss": 0.998}); len(got) != 0 {
+		t.Fatalf("expected plateau to be reported once, got %+v", got)
+	}
+}
+
+func TestMetricAnomalyIgnoresNonLossTrends(t *testing.T) {
+	detector := newMetricAnomalyDetector(testMetricAnomalyConfig())
+
+	detector.Observe("run-1", 0, map[string]float64{"lr": 0.001})
+	if got := detector.Observe("run-1", 500, map[string]float64{"lr": 1.0}); len(got) != 0 {
+		t.Fatalf("expected non-loss metrics to skip trend checks, got %+v", got)
+	}
+}
+
+func TestIngestMetricsRejectsNonFiniteWhenAnalyzerDisabled(t *testing.T) {
+	api := &experimentsAPI{}
+	req := adminRequest(http.MethodPost, "/experiment-runs/run-1/metrics", `{"step":1,"metrics":{"loss":"NaN"}}`)
+	req.SetPathValue("run_id", "run-1")
+	resp := httptest.NewRecorder()
+
+	api.handleIngestExperimentRunMetrics(resp, req)
+	if resp.Code != http.StatusBadRequest {
+		t.Fatalf("status=%d want 400 body=%s", resp.Code, resp.Body.String())
+	}
+}
+
+func TestMetricAnomalyConfigValidate(t *testing.T) {
+	cfg := testMetricAnomalyConfig()
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected valid config, got %v", err)
+	}
+	cfg.DivergenceRatio = 0
+	if err := cfg.Validate(); err == nil {
+		t.Fatalf("expected zero divergence ratio to be rejected")
+	}
+}
@@ -0,0 +1,515 @@
+package experiments
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+	"sort"
+	"strings"
+)
+
+const (
+	metricImportFormatTensorBoard = "tensorboard"
+	metricImportFormatWandb       = "wandb"
+
+	// maxTFRecordBytes bounds one event; scalar events are a few hundred
+	// bytes, and larger records (graphs, images) are skipped unread.
+	maxTFRecordBytes = 64 << 20
+	// maxWandbLineBytes bounds one history row.
+	maxWandbLineBytes = 16 << 20
+)
+
+var errMetricImportCorrupt = errors.New("metric import file is corrupt")
+
+// metricImportPoint is one scalar read from an import file.
+type metricImportPoint struct {
+	Name  string
+	Step  int64
+	Value float64
+	// WallTime is the writer's clock in seconds since the epoch, 0 when the
+	// format has none.
+	WallTime float64
+}
+
+// metricImportReader yields the scalars of an import file in file order and
+// io.EOF after the last one.
+type metricImportReader interface {
+	Next() (metricImportPoint, error)
+}
+
+// detectMetricImportFormat guesses the format from the upload's filename:
+// TensorBoard names event files events.out.tfevents.*, W&B history is JSON
+// lines.
+func detectMetricImportFormat(filename string) string {
+	name := strings.ToLower(filename)
+	switch {
+	case strings.Contains(name, "tfevents"):
+		return metricImportFormatTensorBoard
+	case strings.HasSuffix(name, ".jsonl"), strings.HasSuffix(name, ".json"):
+		return metricImportFormatWandb
+	default:
+		return ""
+	}
+}
+
+func newMetricImportReader(format string, r io.Reader) (metricImportReader, error) {
+	switch format {
+	case metricImportFormatTensorBoard:
+		return newTensorBoardReader(r), nil
+	case metricImportFormatWandb:
+		return newWandbHistoryReader(r), nil
+	default:
+		return nil, fmt.Errorf("unknown metric import format %q", format)
+	}
+}
+
+// tensorBoardReader reads scalars from a TensorBoard event file: a TFRecord
+// stream of tensorflow.Event protos. Both TF1 simple_value summaries and TF2
+// scalar tensors are read; other summaries are skipped.
+type tensorBoardReader struct {
+	r       *bufio.Reader
+	pending []metricImportPoint
+	// scalarTags remembers tags whose first event carried the scalars
+	// plugin metadata; later events of a tag omit it.
+	scalarTags map[string]bool
+}
+
+func newTensorBoardReader(r io.Reader) *tensorBoardReader {
+	return &tensorBoardReader{r: bufio.NewReaderSize(r, 64<<10), scalarTags: map[string]bool{}}
+}
+
+var crc32c = crc32.MakeTable(crc32.Castagnoli)
+
+func maskedCRC32C(data []byte) uint32 {
+	crc := crc32.Checksum(data, crc32c)
+	return ((crc >> 15) | (crc << 17)) + 0xa282ead8
+}
+
+func (t *tensorBoardReader) Next() (metricImportPoint, error) {
+	for len(t.pending) == 0 {
+		record, err := t.readRecord()
+		if err != nil {
+			return metricImportPoint{}, err
+		}
+		if record == nil {
+			continue
+		}
+		points, err := t.decodeEvent(record)
+		if err != nil {
+			return metricImportPoint{}, err
+		}
+		t.pending = points
+	}
+	point := t.pending[0]
+	t.pending = t.pending[1:]
+	return point, nil
+}
+
+// readRecord returns the next record's payload, nil for a record too large
+// to hold a scalar, or io.EOF. A record cut short at the end of the file is
+// treated as the end, as TensorBoard does for files still being written.
+func (t *tensorBoardReader) readRecord() ([]byte, error) {
+	var header [12]byte
+	if _, err := io.ReadFull(t.r, header[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, io.EOF
+		}
+		return nil, err
+	}
+	if binary.LittleEndian.Uint32(header[8:]) != maskedCRC32C(header[:8]) {
+		return nil, fmt.Errorf("%w: record length checksum mismatch", errMetricImportCorrupt)
+	}
+	length := binary.LittleEndian.Uint64(header[:8])
+	if length > maxMetricImportBytes {
+		// No record is longer than the upload that holds it.
+		return nil, fmt.Errorf("%w: record length %d exceeds the import limit", errMetricImportCorrupt, length)
+	}
+	if length > maxTFRecordBytes {
+		if _, err := io.CopyN(io.Discard, t.r, int64(length)+4); err != nil {
+			return nil, io.EOF
+		}
+		return nil, nil
+	}
+	data := make([]byte, length+4)
+	if _, err := io.ReadFull(t.r, data); err != nil {
+		return nil, io.EOF
+	}
+	if binary.LittleEndian.Uint32(data[length:]) != maskedCRC32C(data[:length]) {
+		return nil, fmt.Errorf("%w: record data checksum mismatch", errMetricImportCorrupt)
+	}
+	return data[:length], nil
+}
+
+func (t *tensorBoardReader) decodeEvent(event []byte) ([]metricImportPoint, error) {
+	var (
+		wallTime  float64
+		step      int64
+		summaries [][]byte
+	)
+	err := eachProtoField(event, func(num int, v uint64, data []byte) error {
+		switch num {
+		case 1:
+			wallTime = math.Float64frombits(v)
+		case 2:
+			step = int64(v)
+		case 5:
+			summaries = append(summaries, data)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	var points []metricImportPoint
+	for _, summary := range summaries {
+		err := eachProtoField(summary, func(num int, _ uint64, data []byte) error {
+			if num != 1 {
+				return nil
+			}
+			point, ok, err := t.decodeSummaryValue(data)
+			if err != nil || !ok {
+				return err
+			}
+			point.Step = step
+			point.WallTime = wallTime
+			points = append(points, point)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return points, nil
+}
+
+func (t *tensorBoardReader) decodeSummaryValue(value []byte) (metricImportPoint, bool, error) {
+	var (
+		point       metricImportPoint
+		simple      *float64
+		tensor      []byte
+		metadata    []byte
+		hasMetadata bool
+	)
+	err := eachProtoField(value, func(num int, v uint64, data []byte) error {
+		switch num {
+		case 1:
+			point.Name = string(data)
+		case 2:
+			f := float64(math.Float32frombits(uint32(v)))
+			simple = &f
+		case 8:
+			tensor = data
+		case 9:
+			metadata, hasMetadata = data, true
+		}
+		return nil
+	})
+	if err != nil || point.Name == "" {
+		return point, false, err
+	}
+	if simple != nil {
+		point.Value = *simple
+		return point, true, nil
+	}
+	if hasMetadata {
+		scalar, err := isScalarSummaryMetadata(metadata)
+		if err != nil {
+			return point, false, err
+		}
+		t.scalarTags[point.Name] = scalar
+	}
+	if tensor == nil || !t.scalarTags[point.Name] {
+		return point, false, nil
+	}
+	v, ok, err := decodeScalarTensor(tensor)
+	if err != nil || !ok {
+		return point, false, err
+	}
+	point.Value = v
+	return point, true, nil
+}
+
+// isScalarSummaryMetadata reports whether SummaryMetadata names the scalars
+// plugin or the scalar data class.
+func isScalarSummaryMetadata(metadata []byte) (bool, error) {
+	scalar := false
+	err := eachProtoField(metadata, func(num int, v uint64, data []byte) error {
+		switch num {
+		case 1:
+			return eachProtoField(data, func(num int, _ uint64, name []byte) error {
+				if num == 1 && string(name) == "scalars" {
+					scalar = true
+				}
+				return nil
+			})
+		case 4:
+			if v == 1 {
+				scalar = true
+			}
+		}
+		return nil
+	})
+	return scalar, err
+}
+
+// TensorProto dtypes a scalar summary is written with.
+const (
+	tfDTFloat  = 1
+	tfDTDouble = 2
+	tfDTInt32  = 3
+	tfDTInt64  = 9
+)
+
+// decodeScalarTensor reads the single value of a TensorProto, from
+// tensor_content or the typed value field.
+func decodeScalarTensor(tensor []byte) (float64, bool, error) {
+	var (
+		dtype   uint64
+		content []byte
+		values  []float64
+	)
+	err := eachProtoField(tensor, func(num int, v uint64, data []byte) error {
+		switch num {
+		case 1:
+			dtype = v
+		case 4:
+			content = data
+		case 5, 6, 7, 10:
+			decoded, err := decodeTensorValues(num, v, data)
+			values = append(values, decoded...)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, false, err
+	}
+	if len(content) > 0 {
+		switch {
+		case dtype == tfDTFloat && len(content) == 4:
+			return float64(math.Float32frombits(binary.LittleEndian.Uint32(content))), true, nil
+		case dtype == tfDTDouble && len(content) == 8:
+			return math.Float64frombits(binary.LittleEndian.Uint64(content)), true, nil
+		case dtype == tfDTInt32 && len(content) == 4:
+			return float64(int32(binary.LittleEndian.Uint32(content))), true, nil
+		case dtype == tfDTInt64 && len(content) == 8:
+			return float64(int64(binary.LittleEndian.Uint64(content))), true, nil
+		}
+		return 0, false, nil
+	}
+	if len(values) != 1 {
+		return 0, false, nil
+	}
+	return values[0], true, nil
+}
+
+// decodeTensorValues decodes float_val (5), double_val (6), int_val (7) or
+// int64_val (10), packed or not.
+func decodeTensorValues(num int, v uint64, data []byte) ([]float64, error) {
+	if data == nil {
+		switch num {
+		case 5:
+			return []float64{float64(math.Float32frombits(uint32(v)))}, nil
+		case 6:
+			return []float64{math.Float64frombits(v)}, nil
+		case 7:
+			return []float64{float64(int32(v))}, nil
+		default:
+			return []float64{float64(int64(v))}, nil
+		}
+	}
+	var out []float64
+	for len(data) > 0 {
+		switch num {
+		case 5:
+			if len(data) < 4 {
+				return nil, fmt.Errorf("%w: short packed float", errMetricImportCorrupt)
+			}
+			out = append(out, float64(math.Float32frombits(binary.LittleEndian.Uint32(data))))
+			data = data[4:]
+		case 6:
+			if len(data) < 8 {
+				return nil, fmt.Errorf("%w: short packed double", errMetricImportCorrupt)
+			}
+			out = append(out, math.Float64frombits(binary.LittleEndian.Uint64(data)))
+			data = data[8:]
+		default:
+			n, size := binary.Uvarint(data)
+			if size <= 0 {
+				return nil, fmt.Errorf("%w: bad packed varint", errMetricImportCorrupt)
+			}
+			if num == 7 {
+				out = append(out, float64(int32(n)))
+			} else {
+				out = append(out, float64(int64(n)))
+			}
+			data = data[size:]
+		}
+	}
+	return out, nil
+}
+
+// eachProtoField walks the fields of a protobuf message. Varint and fixed
+// fields arrive in v, length-delimited ones in data.
+func eachProtoField(msg []byte, fn func(num int, v uint64, data []byte) error) error {
+	for len(msg) > 0 {
+		key, n := binary.Uvarint(msg)
+		if n <= 0 {
+			return fmt.Errorf("%w: bad field key", errMetricImportCorrupt)
+		}
+		msg = msg[n:]
+		num := int(key >> 3)
+		var (
+			v    uint64
+			data []byte
+		)
+		switch key & 7 {
+		case 0:
+			v, n = binary.Uvarint(msg)
+			if n <= 0 {
+				return fmt.Errorf("%w: bad varint", errMetricImportCorrupt)
+			}
+			msg = msg[n:]
+		case 1:
+			if len(msg) < 8 {
+				return fmt.Errorf("%w: short fixed64", errMetricImportCorrupt)
+			}
+			v = binary.LittleEndian.Uint64(msg)
+			msg = msg[8:]
+		case 2:
+			length, n := binary.Uvarint(msg)
+			if n <= 0 || length > uint64(len(msg)-n) {
+				return fmt.Errorf("%w: bad length", errMetricImportCorrupt)
+			}
+			data = msg[n : n+int(length)]
+			if data == nil {
+				data = []byte{}
+			}
+			msg = msg[n+int(length):]
+		case 5:
+			if len(msg) < 4 {
+				return fmt.Errorf("%w: short fixed32", errMetricImportCorrupt)
+			}
+			v = uint64(binary.LittleEndian.Uint32(msg))
+			msg = msg[4:]
+		default:
+			return fmt.Errorf("%w: unsupported wire type %d", errMetricImportCorrupt, key&7)
+		}
+		if err := fn(num, v, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// wandbHistoryReader reads a W&B run history export: JSON lines as in
+// wandb-history.jsonl or run.history().to_json(orient="records", lines=True).
+// The step is _step, or the row number when absent; nested values are
+// flattened with "/", as W&B displays them. Keys starting with "_" and media
+// objects are skipped.
+type wandbHistoryReader struct {
+	r       *bufio.Reader
+	row     int64
+	pending []metricImportPoint
+}
+
+func newWandbHistoryReader(r io.Reader) *wandbHistoryReader {
+	return &wandbHistoryReader{r: bufio.NewReaderSize(r, 64<<10)}
+}
+
+func (h *wandbHistoryReader) Next() (metricImportPoint, error) {
+	for len(h.pending) == 0 {
+		line, err := h.readLine()
+		if err != nil {
+			return metricImportPoint{}, err
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		h.pending, err = decodeWandbHistoryRow(line, h.row)
+		if err != nil {
+			return metricImportPoint{}, fmt.Errorf("%w: row %d: %v", errMetricImportCorrupt, h.row+1, err)
+		}
+		h.row++
+	}
+	point := h.pending[0]
+	h.pending = h.pending[1:]
+	return point, nil
+}
+
+func (h *wandbHistoryReader) readLine() (string, error) {
+	var sb strings.Builder
+	for {
+		chunk, err := h.r.ReadSlice('\n')
+		sb.Write(chunk)
+		if sb.Len() > maxWandbLineBytes {
+			return "", fmt.Errorf("%w: row %d exceeds %d bytes", errMetricImportCorrupt, h.row+1, maxWandbLineBytes)
+		}
+		switch {
+		case err == nil:
+			return sb.String(), nil
+		case errors.Is(err, bufio.ErrBufferFull):
+			continue
+		case errors.Is(err, io.EOF) && sb.Len() > 0:
+			return sb.String(), nil
+		default:
+			return "", err
+		}
+	}
+}
+
+func decodeWandbHistoryRow(line string, row int64) ([]metricImportPoint, error) {
+	var fields map[string]any
+	dec := json.NewDecoder(strings.NewReader(line))
+	dec.UseNumber()
+	if err := dec.Decode(&fields); err != nil {
+		return nil, err
+	}
+	step := row
+	if raw, ok := fields["_step"].(json.Number); ok {
+		n, err := raw.Int64()
+		if err != nil {
+			return nil, fmt.Errorf("_step: %v", err)
+		}
+		step = n
+	}
+	var wallTime float64
+	if raw, ok := fields["_timestamp"].(json.Number); ok {
+		wallTime, _ = raw.Float64()
+	}
+	var points []metricImportPoint
+	flattenWandbValues("", fields, func(name string, value float64) {
+		points = append(points, metricImportPoint{Name: name, Step: step, Value: value, WallTime: wallTime})
+	})
+	sort.Slice(points, func(i, j int) bool { return points[i].Name < points[j].Name })
+	return points, nil
+}
+
+func flattenWandbValues(prefix string, fields map[string]any, fn func(string, float64)) {
+	if _, media := fields["_type"]; media {
+		return
+	}
+	for key, value := range fields {
+		if strings.HasPrefix(key, "_") {
+			continue
+		}
+		name := key
+		if prefix != "" {
+			name = prefix + "/" + key
+		}
+		switch v := value.(type) {
+		case json.Number:
+			if f, err := v.Float64(); err == nil {
+				fn(name, f)
+			}
+		case map[string]any:
+			flattenWandbValues(name, v, fn)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package experiments
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"strings"
+	"testing"
+)
+
+func pbKey(num, wire int) []byte {
+	return binary.AppendUvarint(nil, uint64(num<<3|wire))
+}
+
+func pbVarint(num int, v uint64) []byte {
+	return binary.AppendUvarint(pbKey(num, 0), v)
+}
+
+func pbFixed32(num int, v uint32) []byte {
+	return binary.LittleEndian.AppendUint32(pbKey(num, 5), v)
+}
+
+func pbFixed64(num int, v uint64) []byte {
+	return binary.LittleEndian.AppendUint64(pbKey(num, 1), v)
+}
+
+func pbBytes(num int, data []byte) []byte {
+	return append(binary.AppendUvarint(pbKey(num, 2), uint64(len(data))), data...)
+}
+
+func pbConcat(parts ...[]byte) []byte {
+	return bytes.Join(parts, nil)
+}
+
+func tfEvent(step int64, values ...[]byte) []byte {
+	var summary []byte
+	for _, value := range values {
+		summary = append(summary, pbBytes(1, value)...)
+	}
+	return pbConcat(pbFixed64(1, math.Float64bits(1700000000.5)), pbVarint(2, uint64(step)), pbBytes(5, summary))
+}
+
+func tfRecord(data []byte) []byte {
+	header := binary.LittleEndian.AppendUint64(nil, uint64(len(data)))
+	out := binary.LittleEndian.AppendUint32(header, maskedCRC32C(header))
+	out = append(out, data...)
+	return binary.LittleEndian.AppendUint32(out, maskedCRC32C(data))
+}
+
+func readAllMetricImportPoints(t *testing.T, reader metricImportReader) []metricImportPoint {
+	t.Helper()
+	var out []metricImportPoint
+	for {
+		point, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return out
+		}
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		out = append(out, point)
+	}
+}
+
+func TestTensorBoardReaderScalars(t *testing.T) {
+	scalarMeta := pbBytes(9, pbBytes(1, pbBytes(1, []byte("scalars"))))
+	floatTensor := func(v float32) []byte {
+		return pbBytes(8, pbConcat(pbVarint(1, tfDTFloat), pbBytes(4, binary.LittleEndian.AppendUint32(nil, math.Float32bits(v)))))
+	}
+	var file []byte
+	file = append(file, tfRecord(pbBytes(3, []byte("brain.Event:2")))...)
+	// TF1 simple_value.
+	file = append(file, tfRecord(tfEvent(1, pbConcat(pbBytes(1, []byte("loss")), pbFixed32(2, math.Float32bits(0.5)))))...)
+	// TF2 scalar tensor: metadata only on the first event of the tag.
+	file = append(file, tfRecord(tfEvent(1, pbConcat(pbBytes(1, []byte("acc")), scalarMeta, floatTensor(0.25))))...)
+	file = append(file, tfRecord(tfEvent(2, pbConcat(pbBytes(1, []byte("acc")), floatTensor(0.75))))...)
+	// A non-scalar tensor summary is skipped.
+	imageMeta := pbBytes(9, pbBytes(1, pbBytes(1, []byte("images"))))
+	file = append(file, tfRecord(tfEvent(2, pbConcat(pbBytes(1, []byte("sample")), imageMeta, floatTensor(1))))...)
+	// A record cut off mid-write ends the file.
+	file = append(file, tfRecord(tfEvent(3, pbConcat(pbBytes(1, []byte("loss")), pbFixed32(2, math.Float32bits(0.1)))))[:20]...)
+
+	points := readAllMetricImportPoints(t, newTensorBoardReader(bytes.NewReader(file)))
+	want := []metricImportPoint{
+		{Name: "loss", Step: 1, Value: 0.5},
+		{Name: "acc", Step: 1, Value: 0.25},
+		{Name: "acc", Step: 2, Value: 0.75},
+	}
+	if len(points) != len(want) {
+		t.Fatalf("points = %+v", points)
+	}
+	for i, p := range points {
+		if p.Name != want[i].Name || p.Step != want[i].Step || p.Value != want[i].Value || p.WallTime != 1700000000.5 {
+			t.Fatalf("point %d = %+v, want %+v", i, p, want[i])
+		}
+	}
+}
+
+func TestTensorBoardReaderRejectsCorruptRecord(t *testing.T) {
+	record := tfRecord(tfEvent(1, pbConcat(pbBytes(1, []byte("loss")), pbFixed32(2, math.Float32bits(0.5)))))
+	record[14] ^= 0xff
+	if _, err := newTensorBoardReader(bytes.NewReader(record)).Next(); !errors.Is(err, errMetricImportCorrupt) {
+		t.Fatalf("err = %v, want corrupt", err)
+	}
+}
+
+// A length past any upload, up to values that overflow int64, is corrupt
+// rather than a record to skip.
+func TestTensorBoardReaderRejectsCorruptLength(t *testing.T) {
+	for _, length := range []uint64{maxMetricImportBytes + 1, 1 << 63, math.MaxUint64} {
+		header := binary.LittleEndian.AppendUint64(nil, length)
+		record := binary.LittleEndian.AppendUint32(header, maskedCRC32C(header))
+		if _, err := newTensorBoardReader(bytes.NewReader(record)).Next(); !errors.Is(err, errMetricImportCorrupt) {
+			t.Fatalf("length %d: err = %v, want corrupt", length, err)
+		}
+	}
+}
+
+func TestWandbHistoryReader(t *testing.T) {
+	history := strings.Join([]string{
+		`{"_step": 0, "_timestamp": 1700000000.25, "loss": 1.5, "train": {"acc": 0.5}, "note": "x"}`,
+		``,
+		`{"_step": 5, "loss": 0.5, "examples": {"_type": "images/separated", "count": 4}}`,
+		`{"loss": 0.25}`,
+	}, "\n")
+	points := readAllMetricImportPoints(t, newWandbHistoryReader(strings.NewReader(history)))
+	want := []metricImportPoint{
+		{Name: "loss", Step: 0, Value: 1.5, WallTime: 1700000000.25},
+		{Name: "train/acc", Step: 0, Value: 0.5, WallTime: 1700000000.25},
+		{Name: "loss", Step: 5, Value: 0.5},
+		// Without _step the row number is the step.
+		{Name: "loss", Step: 2, Value: 0.25},
+	}
+	if len(points) != len(want) {
+		t.Fatalf("points = %+v", points)
+	}
+	for i := range want {
+		if points[i] != want[i] {
+			t.Fatalf("point %d = %+v, want %+v", i, points[i], want[i])
+		}
+	}
+
+	if _, err := newWandbHistoryReader(strings.NewReader("{not json}\n")).Next(); !errors.Is(err, errMetricImportCorrupt) {
+		t.Fatalf("err = %v, want corrupt", err)
+	}
+}
+
+func TestDetectMetricImportFormat(t *testing.T) {
+	cases := map[string]string{
+		"events.out.tfevents.1700000000.host.1.0": metricImportFormatTensorBoard,
+		"wandb-history.jsonl":                     metricImportFormatWandb,
+		"metrics.csv":                             "",
+	}
+	for name, want := range cases {
+		if got := detectMetricImportFormat(name); got != want {
+			t.Errorf("detectMetricImportFormat(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,515 @@
+package experiments
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	platformpg "github.com/animus-labs/animus-go/closed/internal/platform/postgres"
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+	"github.com/google/uuid"
+	"github.com/minio/minio-go/v7"
+)
+
+const (
+	maxMetricImportBytes = 4 << 30
+	// metricImportBatch is how many scalars are written, and progress
+	// recorded, per transaction.
+	metricImportBatch = 500
+	// metricImportStale is how long a running import may go without
+	// progress before another pass starts it over.
+	metricImportStale = 10 * time.Minute
+)
+
+// experimentRunMetricImport is an upload of scalar series for a run. The
+// worker reads the stored file in batches; the counters say how far it got.
+type experimentRunMetricImport struct {
+	ImportID       string     `json:"import_id"`
+	RunID          string     `json:"run_id"`
+	Format         string     `json:"format"`
+	Filename       string     `json:"filename,omitempty"`
+	SHA256         string     `json:"sha256"`
+	SizeBytes      int64      `json:"size_bytes"`
+	Status         string     `json:"status"`
+	BytesProcessed int64      `json:"bytes_processed"`
+	Progress       float64    `json:"progress"`
+	SamplesRead    int64      `json:"samples_read"`
+	Inserted       int64      `json:"samples_inserted"`
+	Duplicates     int64      `json:"samples_duplicate"`
+	Invalid        int64      `json:"samples_invalid"`
+	Error          string     `json:"error,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	CreatedBy      string     `json:"created_by"`
+	StartedAt      *time.Time `json:"started_at,omitempty"`
+	FinishedAt     *time.Time `json:"finished_at,omitempty"`
+	objectKey      string
+}
+
+func metricImportObjectKey(importID string) string {
+	return "metric-imports/" + importID
+}
+
+func metricImportProgress(processed, size int64) float64 {
+	if size <= 0 {
+		return 1
+	}
+	return math.Min(1, float64(processed)/float64(size))
+}
+
+func (api *experimentsAPI) handleCreateExperimentRunMetricImport(w http.ResponseWriter, r *http.Request) {
+	identity, ok := auth.IdentityFromContext(r.Context())
+	if !ok || strings.TrimSpace(identity.Subject) == "" {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	runID := strings.TrimSpace(r.PathValue("run_id"))
+	if runID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "run_id_required")
+		return
+	}
+	if exists, err := api.runStore(api.db).RunExists(r.Context(), runID); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	} else if !exists {
+		api.writeError(w, r, http.StatusNotFound, "not_found")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxMetricImportBytes)
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			api.writeError(w, r, http.StatusRequestEntityTooLarge, "upload_too_large")
+			return
+		}
+		api.writeError(w, r, http.StatusBadRequest, "invalid_multipart")
+		return
+	}
+	if r.MultipartForm != nil {
+		defer func() { _ = r.MultipartForm.RemoveAll() }()
+	}
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		api.writeError(w, r, http.StatusBadRequest, "file_required")
+		return
+	}
+	defer file.Close()
+
+	filename := sanitizeFilename(header.Filename)
+	format := strings.ToLower(strings.TrimSpace(r.FormValue("format")))
+	if format == "" {
+		format = detectMetricImportFormat(filename)
+	}
+	if format != metricImportFormatTensorBoard && format != metricImportFormatWandb {
+		api.writeError(w, r, http.StatusBadRequest, "metric_import_format_invalid")
+		return
+	}
+
+	hasher := sha256.New()
+	_, err = io.Copy(hasher, file)
+	if err == nil {
+		_, err = file.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		api.writeError(w, r, http.StatusBadRequest, "invalid_multipart")
+		return
+	}
+
+	now := time.Now().UTC()
+	record := experimentRunMetricImport{
+		ImportID:  uuid.NewString(),
+		RunID:     runID,
+		Format:    format,
+		Filename:  filename,
+		SHA256:    hex.EncodeToString(hasher.Sum(nil)),
+		SizeBytes: header.Size,
+		Status:    "queued",
+		CreatedAt: now,
+		CreatedBy: identity.Subject,
+	}
+	record.objectKey = metricImportObjectKey(record.ImportID)
+
+	putCtx, cancel := context.WithTimeout(r.Context(), 10*time.Minute)
+	_, err = api.store.PutObject(putCtx, api.storeCfg.BucketArtifacts, record.objectKey, file, header.Size, minio.PutObjectOptions{
+		ContentType: "application/octet-stream",
+	})
+	cancel()
+	if err != nil {
+		api.writeError(w, r, http.StatusBadGateway, "object_store_error")
+		return
+	}
+
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	_, err = tx.ExecContext(
+		r.Context(),
+		`INSERT INTO experiment_run_metric_imports (
+			import_id, run_id, format, filename, object_key, sha256, size_bytes, status, created_at, created_by, updated_at
+		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$9)`,
+		record.ImportID,
+		record.RunID,
+		record.Format,
+		nullString(record.Filename),
+		record.objectKey,
+		record.SHA256,
+		record.SizeBytes,
+		record.Status,
+		record.CreatedAt,
+		record.CreatedBy,
+	)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	err = auditlog.Enqueue(r.Context(), tx, auditlog.Event{
+		OccurredAt:   now,
+		Actor:        identity.Subject,
+		Action:       "experiment_run_metric_import.create",
+		ResourceType: "experiment_run_metric_import",
+		ResourceID:   record.ImportID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload: map[string]any{
+			"service":    "experiments",
+			"import_id":  record.ImportID,
+			"run_id":     runID,
+			"format":     format,
+			"filename":   filename,
+			"sha256":     record.SHA256,
+			"size_bytes": record.SizeBytes,
+		},
+	})
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "audit_failed")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/experiment-runs/%s/metric-imports/%s", runID, record.ImportID))
+	api.writeJSON(w, http.StatusAccepted, record)
+}
+
+const metricImportColumns = `import_id, run_id, format, filename, object_key, sha256, size_bytes, status,
+	bytes_processed, samples_read, samples_inserted, samples_duplicate, samples_invalid, error,
+	created_at, created_by, started_at, finished_at`
+
+func scanMetricImport(row interface{ Scan(...any) error }) (experimentRunMetricImport, error) {
+	var (
+		out        experimentRunMetricImport
+		filename   sql.NullString
+		errMessage sql.NullString
+		startedAt  sql.NullTime
+		finishedAt sql.NullTime
+	)
+	if err := row.Scan(
+		&out.ImportID, &out.RunID, &out.Format, &filename, &out.objectKey, &out.SHA256, &out.SizeBytes, &out.Status,
+		&out.BytesProcessed, &out.SamplesRead, &out.Inserted, &out.Duplicates, &out.Invalid, &errMessage,
+		&out.CreatedAt, &out.CreatedBy, &startedAt, &finishedAt,
+	); err != nil {
+		return experimentRunMetricImport{}, err
+	}
+	out.Filename = filename.String
+	out.Error = errMessage.String
+	if startedAt.Valid {
+		t := startedAt.Time.UTC()
+		out.StartedAt = &t
+	}
+	if finishedAt.Valid {
+		t := finishedAt.Time.UTC()
+		out.FinishedAt = &t
+	}
+	out.Progress = metricImportProgress(out.BytesProcessed, out.SizeBytes)
+	if out.Status == "succeeded" {
+		out.Progress = 1
+	}
+	return out, nil
+}
+
+func (api *experimentsAPI) handleListExperimentRunMetricImports(w http.ResponseWriter, r *http.Request) {
+	runID := strings.TrimSpace(r.PathValue("run_id"))
+	if runID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "run_id_required")
+		return
+	}
+	limit := httpapi.Limit(r, 50, 200)
+	rows, err := api.db.QueryContext(r.Context(),
+		`SELECT `+metricImportColumns+`
+		 FROM experiment_run_metric_imports
+		 WHERE run_id = $1
+		 ORDER BY created_at DESC
+		 LIMIT $2`,
+		runID, limit,
+	)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer rows.Close()
+	out := make([]experimentRunMetricImport, 0, limit)
+	for rows.Next() {
+		record, err := scanMetricImport(rows)
+		if err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+		out = append(out, record)
+	}
+	if err := rows.Err(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	api.writeJSON(w, http.StatusOK, map[string]any{"run_id": runID, "imports": out})
+}
+
+func (api *experimentsAPI) handleGetExperimentRunMetricImport(w http.ResponseWriter, r *http.Request) {
+	runID := strings.TrimSpace(r.PathValue("run_id"))
+	importID := strings.TrimSpace(r.PathValue("import_id"))
+	record, err := scanMetricImport(api.db.QueryRowContext(r.Context(),
+		`SELECT `+metricImportColumns+`
+		 FROM experiment_run_metric_imports
+		 WHERE run_id = $1 AND import_id = $2`,
+		runID, importID,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	api.writeJSON(w, http.StatusOK, record)
+}
+
+func startMetricImports(ctx context.Context, logger *slog.Logger, api *experimentsAPI, interval time.Duration) {
+	if api == nil || api.db == nil || api.store == nil {
+		return
+	}
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	syncer{
+		name:       "metric_imports",
+		logger:     logger,
+		leader:     platformpg.NewLeader(api.db, "metric_imports"),
+		interval:   interval,
+		maxBackoff: 30 * interval,
+		pass: func(ctx context.Context) error {
+			return api.runMetricImports(ctx, logger)
+		},
+	}.start(ctx)
+}
+
+// runMetricImports works through queued imports, oldest first. An import
+// left running by a replica that stopped is started over; samples it already
+// wrote are counted as duplicates the second time.
+func (api *experimentsAPI) runMetricImports(ctx context.Context, logger *slog.Logger) error {
+	for {
+		record, err := api.claimMetricImport(ctx, time.Now().UTC())
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := api.processMetricImport(ctx, record); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if logger != nil {
+				logger.Warn("metric import failed", "import_id", record.ImportID, "run_id", record.RunID, "error", err)
+			}
+			if ferr := api.finishMetricImport(ctx, record, "failed", err.Error()); ferr != nil {
+				return ferr
+			}
+			continue
+		}
+		if err := api.finishMetricImport(ctx, record, "succeeded", ""); err != nil {
+			return err
+		}
+	}
+}
+
+func (api *experimentsAPI) claimMetricImport(ctx context.Context, now time.Time) (experimentRunMetricImport, error) {
+	return scanMetricImport(api.db.QueryRowContext(ctx,
+		`UPDATE experiment_run_metric_imports
+		 SET status = 'running', started_at = $1, updated_at = $1,
+			bytes_processed = 0, samples_read = 0, samples_inserted = 0, samples_duplicate = 0, samples_invalid = 0
+		 WHERE import_id = (
+			SELECT import_id FROM experiment_run_metric_imports
+			WHERE status = 'queued' OR (status = 'running' AND updated_at < $2)
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		 )
+		 RETURNING `+metricImportColumns,
+		now, now.Add(-metricImportStale),
+	))
+}
+
+func (api *experimentsAPI) finishMetricImport(ctx context.Context, record experimentRunMetricImport, status, message string) error {
+	now := time.Now().UTC()
+	_, err := api.db.ExecContext(ctx,
+		`UPDATE experiment_run_metric_imports
+		 SET status = $1, error = $2, finished_at = $3, updated_at = $3
+		 WHERE import_id = $4`,
+		status, nullString(message), now, record.ImportID,
+	)
+	return err
+}
+
+// countingReader counts the bytes read through it, for progress.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func (api *experimentsAPI) processMetricImport(ctx context.Context, record experimentRunMetricImport) error {
+	obj, err := api.store.GetObject(ctx, api.storeCfg.BucketArtifacts, record.objectKey, minio.GetObjectOptions{})
+	if err != nil {
+		return fmt.Errorf("open upload: %w", err)
+	}
+	defer obj.Close()
+	counter := &countingReader{r: obj}
+	reader, err := newMetricImportReader(record.Format, counter)
+	if err != nil {
+		return err
+	}
+
+	batch := make([]metricImportPoint, 0, metricImportBatch)
+	done := false
+	for !done {
+		batch = batch[:0]
+		for len(batch) < metricImportBatch {
+			point, err := reader.Next()
+			if errors.Is(err, io.EOF) {
+				done = true
+				break
+			}
+			if err != nil {
+				return err
+			}
+			batch = append(batch, point)
+		}
+		if err := api.writeMetricImportBatch(ctx, &record, batch, counter.n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeMetricImportBatch stores one batch and the progress after it in one
+// transaction. Samples are unique per run, name and step, so a step the run
+// already has is a duplicate and keeps its value.
+func (api *experimentsAPI) writeMetricImportBatch(ctx context.Context, record *experimentRunMetricImport, batch []metricImportPoint, bytesProcessed int64) error {
+	tx, err := api.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	now := time.Now().UTC()
+	metricStore := api.metricStore(tx)
+	for _, point := range batch {
+		record.SamplesRead++
+		name := strings.TrimSpace(point.Name)
+		if name == "" || point.Step < 0 || math.IsNaN(point.Value) || math.IsInf(point.Value, 0) {
+			record.Invalid++
+			continue
+		}
+		metadata := map[string]any{"source": record.Format, "import_id": record.ImportID}
+		if point.WallTime > 0 {
+			metadata["wall_time"] = point.WallTime
+		}
+		metadataJSON, err := json.Marshal(metadata)
+		if err != nil {
+			return err
+		}
+		sampleID := uuid.NewString()
+		type integrityInput struct {
+			SampleID   string          `json:"sample_id"`
+			RunID      string          `json:"run_id"`
+			RecordedAt time.Time       `json:"recorded_at"`
+			RecordedBy string          `json:"recorded_by"`
+			Step       int64           `json:"step"`
+			Name       string          `json:"name"`
+			Value      float64         `json:"value"`
+			Metadata   json.RawMessage `json:"metadata"`
+		}
+		integrity, err := integritySHA256(integrityInput{
+			SampleID:   sampleID,
+			RunID:      record.RunID,
+			RecordedAt: now,
+			RecordedBy: record.CreatedBy,
+			Step:       point.Step,
+			Name:       name,
+			Value:      point.Value,
+			Metadata:   metadataJSON,
+		})
+		if err != nil {
+			return err
+		}
+		created, err := metricStore.InsertSample(ctx, repo.MetricSampleRecord{
+			SampleID:        sampleID,
+			RunID:           record.RunID,
+			RecordedAt:      now,
+			RecordedBy:      record.CreatedBy,
+			Step:            point.Step,
+			Name:            name,
+			Value:           point.Value,
+			Metadata:        metadataJSON,
+			IntegritySHA256: integrity,
+		})
+		if err != nil {
+			return err
+		}
+		if created {
+			record.Inserted++
+		} else {
+			record.Duplicates++
+		}
+	}
+	record.BytesProcessed = bytesProcessed
+	_, err = tx.ExecContext(ctx,
+		`UPDATE experiment_run_metric_imports
+		 SET bytes_processed = $1, samples_read = $2, samples_inserted = $3, samples_duplicate = $4, samples_invalid = $5, updated_at = $6
+		 WHERE import_id = $7`,
+		record.BytesProcessed, record.SamplesRead, record.Inserted, record.Duplicates, record.Invalid, now, record.ImportID,
+	)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
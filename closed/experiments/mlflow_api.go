@@ -0,0 +1,1247 @@
+package experiments
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	"github.com/animus-labs/animus-go/closed/internal/platform/lineageevent"
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+	"github.com/animus-labs/animus-go/closed/internal/repo/postgres"
+	"github.com/google/uuid"
+)
+
+// The MLflow shim serves the subset of the MLflow tracking REST API that the
+// stock client uses to report a run: experiments are looked up or created by
+// name, runs map onto experiment runs, metrics onto metric samples, and
+// log_artifact uploads onto run artifacts. Setting
+//
+//	MLFLOW_TRACKING_URI=https://<gateway>/api/experiments/projects/<project_id>/mlflow
+//	MLFLOW_TRACKING_TOKEN=<service account token>
+//
+// is enough for unmodified training code. Errors use the MLflow envelope so
+// the client can tell a missing experiment from a failure.
+const (
+	mlflowRunNameTag = "mlflow.runName"
+	mlflowUserTag    = "mlflow.user"
+
+	// The batch limits match the MLflow server's.
+	maxMLflowBatchMetrics = 1000
+	maxMLflowBatchParams  = 100
+	maxMLflowBatchTags    = 100
+	maxMLflowKeyBytes     = 250
+	maxMLflowValueBytes   = 6000
+
+	maxMLflowArtifactBytes = 5 << 30
+)
+
+// MLflow error codes understood by the client.
+const (
+	mlflowInvalidParameterValue  = "INVALID_PARAMETER_VALUE"
+	mlflowResourceDoesNotExist   = "RESOURCE_DOES_NOT_EXIST"
+	mlflowResourceAlreadyExists  = "RESOURCE_ALREADY_EXISTS"
+	mlflowInvalidState           = "INVALID_STATE"
+	mlflowInternalError          = "INTERNAL_ERROR"
+	mlflowTemporarilyUnavailable = "TEMPORARILY_UNAVAILABLE"
+)
+
+var (
+	errMLflowRunNotFound = errors.New("mlflow run not found")
+	errMLflowParamChange = errors.New("mlflow param value changed")
+)
+
+// mlflowInt64 accepts both encodings protobuf JSON allows for int64 fields:
+// a number or a decimal string.
+type mlflowInt64 int64
+
+func (v *mlflowInt64) UnmarshalJSON(data []byte) error {
+	raw := strings.TrimSpace(string(data))
+	if raw == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(raw); err == nil {
+		raw = unquoted
+	}
+	n, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid int64 %q", raw)
+	}
+	*v = mlflowInt64(n)
+	return nil
+}
+
+type mlflowKeyValue struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type mlflowMetric struct {
+	Key       string      `json:"key"`
+	Value     float64     `json:"value"`
+	Timestamp mlflowInt64 `json:"timestamp"`
+	Step      mlflowInt64 `json:"step"`
+}
+
+type mlflowExperiment struct {
+	ExperimentID     string           `json:"experiment_id"`
+	Name             string           `json:"name"`
+	ArtifactLocation string           `json:"artifact_location"`
+	LifecycleStage   string           `json:"lifecycle_stage"`
+	CreationTime     int64            `json:"creation_time"`
+	LastUpdateTime   int64            `json:"last_update_time"`
+	Tags             []mlflowKeyValue `json:"tags,omitempty"`
+}
+
+type mlflowRunInfo struct {
+	RunID          string `json:"run_id"`
+	RunUUID        string `json:"run_uuid"`
+	RunName        string `json:"run_name,omitempty"`
+	ExperimentID   string `json:"experiment_id"`
+	UserID         string `json:"user_id"`
+	Status         string `json:"status"`
+	StartTime      int64  `json:"start_time"`
+	EndTime        int64  `json:"end_time,omitempty"`
+	ArtifactURI    string `json:"artifact_uri"`
+	LifecycleStage string `json:"lifecycle_stage"`
+}
+
+type mlflowRunData struct {
+	Metrics []mlflowMetric   `json:"metrics"`
+	Params  []mlflowKeyValue `json:"params"`
+	Tags    []mlflowKeyValue `json:"tags"`
+}
+
+type mlflowRun struct {
+	Info mlflowRunInfo `json:"info"`
+	Data mlflowRunData `json:"data"`
+}
+
+func (api *experimentsAPI) registerMLflow(mux *http.ServeMux) {
+	mux.HandleFunc("GET /projects/{project_id}/mlflow/api/2.0/mlflow/experiments/get-by-name", api.handleMLflowGetExperimentByName)
+	mux.HandleFunc("GET /projects/{project_id}/mlflow/api/2.0/mlflow/experiments/get", api.handleMLflowGetExperiment)
+	mux.HandleFunc("POST /projects/{project_id}/mlflow/api/2.0/mlflow/experiments/create", api.handleMLflowCreateExperiment)
+	mux.HandleFunc("POST /projects/{project_id}/mlflow/api/2.0/mlflow/runs/create", api.handleMLflowCreateRun)
+	mux.HandleFunc("POST /projects/{project_id}/mlflow/api/2.0/mlflow/runs/update", api.handleMLflowUpdateRun)
+	mux.HandleFunc("GET /projects/{project_id}/mlflow/api/2.0/mlflow/runs/get", api.handleMLflowGetRun)
+	mux.HandleFunc("POST /projects/{project_id}/mlflow/api/2.0/mlflow/runs/log-metric", api.handleMLflowLogMetric)
+	mux.HandleFunc("POST /projects/{project_id}/mlflow/api/2.0/mlflow/runs/log-parameter", api.handleMLflowLogParam)
+	mux.HandleFunc("POST /projects/{project_id}/mlflow/api/2.0/mlflow/runs/set-tag", api.handleMLflowSetTag)
+	mux.HandleFunc("POST /projects/{project_id}/mlflow/api/2.0/mlflow/runs/log-batch", api.handleMLflowLogBatch)
+	mux.HandleFunc("PUT /projects/{project_id}/mlflow/api/2.0/mlflow-artifacts/artifacts/{artifact_path...}", api.handleMLflowPutArtifact)
+}
+
+// mlflowProjectID returns the project named by an MLflow shim path. The
+// client cannot send X-Project-Id, so the tracking URI carries it.
+func mlflowProjectID(urlPath string) string {
+	rest, ok := strings.CutPrefix(urlPath, "/projects/")
+	if !ok {
+		return ""
+	}
+	projectID, rest, ok := strings.Cut(rest, "/")
+	if !ok || !strings.HasPrefix(rest, "mlflow/") {
+		return ""
+	}
+	return strings.TrimSpace(projectID)
+}
+
+// mlflowRunStatus maps an MLflow RunStatus onto the run status recorded for it.
+func mlflowRunStatus(status string) (string, bool) {
+	switch strings.ToUpper(strings.TrimSpace(status)) {
+	case "RUNNING":
+		return "running", true
+	case "SCHEDULED":
+		return "pending", true
+	case "FINISHED":
+		return "succeeded", true
+	case "FAILED":
+		return "failed", true
+	case "KILLED":
+		return "canceled", true
+	default:
+		return "", false
+	}
+}
+
+func mlflowStatusOf(status string) string {
+	switch strings.ToLower(strings.TrimSpace(status)) {
+	case "pending":
+		return "SCHEDULED"
+	case "succeeded":
+		return "FINISHED"
+	case "failed":
+		return "FAILED"
+	case "canceled":
+		return "KILLED"
+	default:
+		return "RUNNING"
+	}
+}
+
+func mlflowArtifactURI(experimentID, runID string) string {
+	return "mlflow-artifacts:/" + experimentID + "/" + runID + "/artifacts"
+}
+
+// parseMLflowArtifactPath splits the path under mlflow-artifacts/artifacts
+// into the run named by mlflowArtifactURI and the file within it.
+func parseMLflowArtifactPath(artifactPath string) (experimentID, runID, name string, ok bool) {
+	parts := strings.SplitN(strings.Trim(artifactPath, "/"), "/", 4)
+	if len(parts) != 4 || parts[0] == "" || parts[1] == "" || parts[2] != "artifacts" {
+		return "", "", "", false
+	}
+	name = path.Clean(parts[3])
+	if name == "." || name == ".." || strings.HasPrefix(name, "../") || strings.HasPrefix(name, "/") {
+		return "", "", "", false
+	}
+	return parts[0], parts[1], name, true
+}
+
+func validMLflowKeyValue(kv mlflowKeyValue) bool {
+	key := strings.TrimSpace(kv.Key)
+	return key != "" && len(key) <= maxMLflowKeyBytes && len(kv.Value) <= maxMLflowValueBytes
+}
+
+func mlflowMillis(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixMilli()
+}
+
+// decodeMLflowRequest is lenient about unknown fields: MLflow clients send
+// deprecated aliases (run_uuid) alongside the current ones.
+func decodeMLflowRequest(r *http.Request, dst any) error {
+	return json.NewDecoder(io.LimitReader(r.Body, httpapi.MaxBodyBytes)).Decode(dst)
+}
+
+func writeMLflowError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error_code": code, "message": message})
+}
+
+func writeMLflowInternalError(w http.ResponseWriter) {
+	writeMLflowError(w, http.StatusInternalServerError, mlflowInternalError, "internal error")
+}
+
+// mlflowCaller returns the identity and project of a shim request, or writes
+// the MLflow error and returns false.
+func (api *experimentsAPI) mlflowCaller(w http.ResponseWriter, r *http.Request) (auth.Identity, string, bool) {
+	identity, ok := auth.IdentityFromContext(r.Context())
+	if !ok || strings.TrimSpace(identity.Subject) == "" {
+		writeMLflowInternalError(w)
+		return auth.Identity{}, "", false
+	}
+	projectID, ok := auth.ProjectIDFromContext(r.Context())
+	if !ok || strings.TrimSpace(projectID) == "" {
+		writeMLflowError(w, http.StatusBadRequest, mlflowInvalidParameterValue, "project_id is required")
+		return auth.Identity{}, "", false
+	}
+	return identity, projectID, true
+}
+
+func (api *experimentsAPI) mlflowRequireActiveProject(w http.ResponseWriter, r *http.Request, projectID string) bool {
+	project, err := postgres.NewProjectStore(api.db).Get(r.Context(), projectID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			writeMLflowError(w, http.StatusNotFound, mlflowResourceDoesNotExist, "project not found")
+			return false
+		}
+		writeMLflowInternalError(w)
+		return false
+	}
+	if project.Archived() {
+		writeMLflowError(w, http.StatusBadRequest, mlflowInvalidState, "project is archived")
+		return false
+	}
+	return true
+}
+
+func (api *experimentsAPI) loadMLflowExperiment(ctx context.Context, projectID, column, value string) (mlflowExperiment, error) {
+	var (
+		out       mlflowExperiment
+		createdAt time.Time
+		metadata  []byte
+	)
+	err := api.db.QueryRowContext(ctx,
+		`SELECT experiment_id, name, created_at, metadata
+		 FROM experiments
+		 WHERE project_id = $1 AND `+column+` = $2`,
+		projectID, value,
+	).Scan(&out.ExperimentID, &out.Name, &createdAt, &metadata)
+	if err != nil {
+		return mlflowExperiment{}, err
+	}
+	out.ArtifactLocation = "mlflow-artifacts:/" + out.ExperimentID
+	out.LifecycleStage = "active"
+	out.CreationTime = mlflowMillis(createdAt)
+	out.LastUpdateTime = out.CreationTime
+	var meta struct {
+		MLflowTags []mlflowKeyValue `json:"mlflow_tags"`
+	}
+	if json.Unmarshal(metadata, &meta) == nil {
+		out.Tags = meta.MLflowTags
+	}
+	return out, nil
+}
+
+func (api *experimentsAPI) writeMLflowExperiment(w http.ResponseWriter, r *http.Request, projectID, column, value string) {
+	if strings.TrimSpace(value) == "" {
+		writeMLflowError(w, http.StatusBadRequest, mlflowInvalidParameterValue, "missing value for required parameter")
+		return
+	}
+	experiment, err := api.loadMLflowExperiment(r.Context(), projectID, column, strings.TrimSpace(value))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeMLflowError(w, http.StatusNotFound, mlflowResourceDoesNotExist, fmt.Sprintf("Could not find experiment with %s=%s", column, value))
+			return
+		}
+		writeMLflowInternalError(w)
+		return
+	}
+	api.writeJSON(w, http.StatusOK, map[string]any{"experiment": experiment})
+}
+
+func (api *experimentsAPI) handleMLflowGetExperimentByName(w http.ResponseWriter, r *http.Request) {
+	_, projectID, ok := api.mlflowCaller(w, r)
+	if !ok {
+		return
+	}
+	api.writeMLflowExperiment(w, r, projectID, "name", r.URL.Query().Get("experiment_name"))
+}
+
+func (api *experimentsAPI) handleMLflowGetExperiment(w http.ResponseWriter, r *http.Request) {
+	_, projectID, ok := api.mlflowCaller(w, r)
+	if !ok {
+		return
+	}
+	api.writeMLflowExperiment(w, r, projectID, "experiment_id", r.URL.Query().Get("experiment_id"))
+}
+
+func (api *experimentsAPI) handleMLflowCreateExperiment(w http.ResponseWriter, r *http.Request) {
+	identity, projectID, ok := api.mlflowCaller(w, r)
+	if !ok {
+		return
+	}
+	var req struct {
+		Name string           `json:"name"`
+		Tags []mlflowKeyValue `json:"tags"`
+	}
+	if err := decodeMLflowRequest(r, &req); err != nil {
+		writeMLflowError(w, http.StatusBadRequest, mlflowInvalidParameterValue, "invalid request body")
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		writeMLflowError(w, http.StatusBadRequest, mlflowInvalidParameterValue, "experiment name is required")
+		return
+	}
+	for _, tag := range req.Tags {
+		if !validMLflowKeyValue(tag) {
+			writeMLflowError(w, http.StatusBadRequest, mlflowInvalidParameterValue, "invalid experiment tag")
+			return
+		}
+	}
+	if !api.mlflowRequireActiveProject(w, r, projectID) {
+		return
+	}
+
+	metadata := map[string]any{"source": "mlflow"}
+	if len(req.Tags) > 0 {
+		metadata["mlflow_tags"] = req.Tags
+	}
+	metadataJSON, err := json.Marshal(metadata)
+	if err != nil {
+		writeMLflowInternalError(w)
+		return
+	}
+
+	record, err := newExperimentRecord(uuid.NewString(), projectID, name, "", metadataJSON, time.Now().UTC(), identity.Subject)
+	if err != nil {
+		writeMLflowInternalError(w)
+		return
+	}
+
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		writeMLflowInternalError(w)
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := api.createExperiment(r, tx, record, metadata); err != nil {
+		if errors.Is(err, repo.ErrConflict) {
+			writeMLflowError(w, http.StatusBadRequest, mlflowResourceAlreadyExists, fmt.Sprintf("Experiment '%s' already exists.", name))
+			return
+		}
+		writeMLflowInternalError(w)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		writeMLflowInternalError(w)
+		return
+	}
+	api.writeJSON(w, http.StatusOK, map[string]string{"experiment_id": record.ExperimentID})
+}
+
+func (api *experimentsAPI) handleMLflowCreateRun(w http.ResponseWriter, r *http.Request) {
+	identity, projectID, ok := api.mlflowCaller(w, r)
+	if !ok {
+		return
+	}
+	var req struct {
+		ExperimentID string           `json:"experiment_id"`
+		UserID       string           `json:"user_id"`
+		RunName      string           `json:"run_name"`
+		StartTime    mlflowInt64      `json:"start_time"`
+		Tags         []mlflowKeyValue `json:"tags"`
+	}
+	if err := decodeMLflowRequest(r, &req); err != nil {
+		writeMLflowError(w, http.StatusBadRequest, mlflowInvalidParameterValue, "invalid request body")
+		return
+	}
+	experimentID := strings.TrimSpace(req.ExperimentID)
+	if experimentID == "" {
+		writeMLflowError(w, http.StatusBadRequest, mlflowInvalidParameterValue, "experiment_id is required")
+		return
+	}
+	if len(req.Tags) > maxMLflowBatchTags {
+		writeMLflowError(w, http.StatusBadRequest, mlflowInvalidParameterValue, "too many tags")
+		return
+	}
+	tags := make([]mlflowKeyValue, 0, len(req.Tags)+2)
+	runName := strings.TrimSpace(req.RunName)
+	userID := strings.TrimSpace(req.UserID)
+	for _, tag := range req.Tags {
+		if !validMLflowKeyValue(tag) {
+			writeMLflowError(w, http.StatusBadRequest, mlflowInvalidParameterValue, "invalid run tag")
+			return
+		}
+		switch tag.Key {
+		case mlflowRunNameTag:
+			if runName == "" {
+				runName = tag.Value
+			}
+			continue
+		case mlflowUserTag:
+			if userID == "" {
+				userID = tag.Value
+			}
+			continue
+		}
+		tags = append(tags, tag)
+	}
+	if userID == "" {
+		userID = identity.Subject
+	}
+	if runName != "" {
+		tags = append(tags, mlflowKeyValue{Key: mlflowRunNameTag, Value: runName})
+	}
+	tags = append(tags, mlflowKeyValue{Key: mlflowUserTag, Value: userID})
+
+	if !api.mlflowRequireActiveProject(w, r, projectID) {
+		return
+	}
+	if _, err := api.loadMLflowExperiment(r.Context(), projectID, "experiment_id", experimentID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// The client falls back to experiment 0 when none was set; there
+			// is no project default, so name the fix.
+			writeMLflowError(w, http.StatusNotFound, mlflowResourceDoesNotExist, fmt.Sprintf("No experiment with id=%s; call mlflow.set_experiment first", experimentID))
+			return
+		}
+		writeMLflowInternalError(w)
+		return
+	}
+
+	now := time.Now().UTC()
+	startedAt := now
+	if req.StartTime > 0 {
+		startedAt = time.UnixMilli(int64(req.StartTime)).UTC()
+	}
+	run := experimentRunInsert{
+		RunID:        uuid.NewString(),
+		ExperimentID: experimentID,
+		ProjectID:    projectID,
+		Status:       "running",
+		StartedAt:    startedAt,
+		Params:       map[string]any{},
+		ParamsJSON:   json.RawMessage(`{}`),
+		Metrics:      map[string]any{},
+		MetricsJSON:  json.RawMessage(`{}`),
+		CreatedAt:    now,
+	}
+	integrity, err := experimentRunIntegrity(run)
+	if err != nil {
+		writeMLflowInternalError(w)
+		return
+	}
+	run.IntegritySHA256 = integrity
+
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		writeMLflowInternalError(w)
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := api.prepareRunConfigManifest(r.Context(), tx, &run); err != nil {
+		writeMLflowInternalError(w)
+		return
+	}
+	if err := insertExperimentRun(r, tx, identity.Subject, run); err != nil {
+		writeMLflowInternalError(w)
+		return
+	}
+	for _, tag := range tags {
+		if err := setMLflowRunTag(r.Context(), tx, run.RunID, identity.Subject, tag, now); err != nil {
+			writeMLflowInternalError(w)
+			return
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		writeMLflowInternalError(w)
+		return
+	}
+
+	api.writeJSON(w, http.StatusOK, map[string]any{"run": mlflowRun{
+		Info: mlflowRunInfo{
+			RunID:          run.RunID,
+			RunUUID:        run.RunID,
+			RunName:        runName,
+			ExperimentID:   experimentID,
+			UserID:         userID,
+			Status:         mlflowStatusOf(run.Status),
+			StartTime:      mlflowMillis(startedAt),
+			ArtifactURI:    mlflowArtifactURI(experimentID, run.RunID),
+			LifecycleStage: "active",
+		},
+		Data: mlflowRunData{Metrics: []mlflowMetric{}, Params: []mlflowKeyValue{}, Tags: tags},
+	}})
+}
+
+// mlflowRequestRunID accepts run_uuid, which older clients send instead of
+// run_id.
+func mlflowRequestRunID(runID, runUUID string) string {
+	if id := strings.TrimSpace(runID); id != "" {
+		return id
+	}
+	return strings.TrimSpace(runUUID)
+}
+
+func (api *experimentsAPI) handleMLflowUpdateRun(w http.ResponseWriter, r *http.Request) {
+	identity, projectID, ok := api.mlflowCaller(w, r)
+	if !ok {
+		return
+	}
+	var req struct {
+		RunID   string      `json:"run_id"`
+		RunUUID string      `json:"run_uuid"`
+		Status  string      `json:"status"`
+		EndTime mlflowInt64 `json:"end_time"`
+		RunName string      `json:"run_name"`
+	}
+	if err := decodeMLflowRequest(r, &req); err != nil {
+		writeMLflowError(w, http.StatusBadRequest, mlflowInvalidParameterValue, "invalid request body")
+		return
+	}
+	runID := mlflowRequestRunID(req.RunID, req.RunUUID)
+	status := ""
+	if strings.TrimSpace(req.Status) != "" {
+		var ok bool
+		if status, ok = mlflowRunStatus(req.Status); !ok {
+			writeMLflowError(w, http.StatusBadRequest, mlflowInvalidParameterValue, "invalid run status")
+			return
+		}
+	}
+
+	now := time.Now().UTC()
+	observedAt := now
+	if req.EndTime > 0 {
+		observedAt = time.UnixMilli(int64(req.EndTime)).UTC()
+	}
+
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		writeMLflowInternalError(w)
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := mlflowRunExperimentID(r.Context(), tx, projectID, runID); err != nil {
+		api.writeMLflowRunError(w, err)
+		return
+	}
+	// Status changes are state events, as for runs reported by the data
+	// plane; the run row keeps its integrity hash.
+	if status != "" {
+		if _, err := api.insertRunStateEvent(r.Context(), tx, runID, status, observedAt, map[string]any{"source": "mlflow", "actor": identity.Subject}); err != nil {
+			writeMLflowInternalError(w)
+			return
+		}
+	}
+	if name := strings.TrimSpace(req.RunName); name != "" {
+		if err := setMLflowRunTag(r.Context(), tx, runID, identity.Subject, mlflowKeyValue{Key: mlflowRunNameTag, Value: name}, now); err != nil {
+			writeMLflowInternalError(w)
+			return
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		writeMLflowInternalError(w)
+		return
+	}
+
+	run, err := api.loadMLflowRun(r.Context(), projectID, runID)
+	if err != nil {
+		api.writeMLflowRunError(w, err)
+		return
+	}
+	api.writeJSON(w, http.StatusOK, map[string]any{"run_info": run.Info})
+}
+
+func (api *experimentsAPI) handleMLflowGetRun(w http.ResponseWriter, r *http.Request) {
+	_, projectID, ok := api.mlflowCaller(w, r)
+	if !ok {
+		return
+	}
+	runID := mlflowRequestRunID(r.URL.Query().Get("run_id"), r.URL.Query().Get("run_uuid"))
+	run, err := api.loadMLflowRun(r.Context(), projectID, runID)
+	if err != nil {
+		api.writeMLflowRunError(w, err)
+		return
+	}
+	api.writeJSON(w, http.StatusOK, map[string]any{"run": run})
+}
+
+func (api *experimentsAPI) writeMLflowRunError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, errMLflowRunNotFound):
+		writeMLflowError(w, http.StatusNotFound, mlflowResourceDoesNotExist, "Run not found")
+	case errors.Is(err, errMLflowParamChange):
+		writeMLflowError(w, http.StatusBadRequest, mlflowInvalidParameterValue, err.Error())
+	default:
+		writeMLflowInternalError(w)
+	}
+}
+
+func mlflowRunExperimentID(ctx context.Context, q postgres.DB, projectID, runID string) (string, error) {
+	if runID == "" {
+		return "", errMLflowRunNotFound
+	}
+	var experimentID string
+	err := q.QueryRowContext(ctx,
+		`SELECT experiment_id FROM experiment_runs WHERE run_id = $1 AND project_id = $2`,
+		runID, projectID,
+	).Scan(&experimentID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", errMLflowRunNotFound
+	}
+	return experimentID, err
+}
+
+func (api *experimentsAPI) loadMLflowRun(ctx context.Context, projectID, runID string) (mlflowRun, error) {
+	if runID == "" {
+		return mlflowRun{}, errMLflowRunNotFound
+	}
+	var (
+		out       mlflowRun
+		startedAt time.Time
+		endedAt   sql.NullTime
+		status    string
+		params    []byte
+	)
+	// The latest state event wins over the status the run was created with.
+	err := api.db.QueryRowContext(ctx,
+		`SELECT r.experiment_id, r.started_at,
+			COALESCE(r.ended_at, (
+				SELECT observed_at FROM experiment_run_state_events
+				WHERE run_id = r.run_id AND status IN ('succeeded', 'failed', 'canceled')
+				ORDER BY observed_at DESC LIMIT 1
+			)),
+			COALESCE((
+				SELECT status FROM experiment_run_state_events
+				WHERE run_id = r.run_id
+				ORDER BY observed_at DESC LIMIT 1
+			), r.status),
+			r.params
+		 FROM experiment_runs r
+		 WHERE r.run_id = $1 AND r.project_id = $2`,
+		runID, projectID,
+	).Scan(&out.Info.ExperimentID, &startedAt, &endedAt, &status, &params)
+	if errors.Is(err, sql.ErrNoRows) {
+		return mlflowRun{}, errMLflowRunNotFound
+	}
+	if err != nil {
+		return mlflowRun{}, err
+	}
+	out.Info.RunID = runID
+	out.Info.RunUUID = runID
+	out.Info.Status = mlflowStatusOf(status)
+	out.Info.StartTime = mlflowMillis(startedAt)
+	if endedAt.Valid {
+		out.Info.EndTime = mlflowMillis(endedAt.Time)
+	}
+	out.Info.ArtifactURI = mlflowArtifactURI(out.Info.ExperimentID, runID)
+	out.Info.LifecycleStage = "active"
+
+	out.Data.Metrics, err = mlflowLatestMetrics(ctx, api.db, runID)
+	if err != nil {
+		return mlflowRun{}, err
+	}
+	out.Data.Params, err = mlflowRunParams(ctx, api.db, runID, params)
+	if err != nil {
+		return mlflowRun{}, err
+	}
+	out.Data.Tags, err = mlflowRunTags(ctx, api.db, runID)
+	if err != nil {
+		return mlflowRun{}, err
+	}
+	for _, tag := range out.Data.Tags {
+		switch tag.Key {
+		case mlflowRunNameTag:
+			out.Info.RunName = tag.Value
+		case mlflowUserTag:
+			out.Info.UserID = tag.Value
+		}
+	}
+	return out, nil
+}
+
+func mlflowLatestMetrics(ctx context.Context, db *sql.DB, runID string) ([]mlflowMetric, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT DISTINCT ON (name) name, value, step, recorded_at, metadata
+		 FROM experiment_run_metric_samples
+		 WHERE run_id = $1
+		 ORDER BY name, step DESC`,
+		runID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []mlflowMetric{}
+	for rows.Next() {
+		var (
+			metric     mlflowMetric
+			step       int64
+			recordedAt time.Time
+			metadata   []byte
+		)
+		if err := rows.Scan(&metric.Key, &metric.Value, &step, &recordedAt, &metadata); err != nil {
+			return nil, err
+		}
+		metric.Step = mlflowInt64(step)
+		metric.Timestamp = mlflowInt64(mlflowMillis(recordedAt))
+		var meta struct {
+			Timestamp int64 `json:"timestamp"`
+		}
+		if json.Unmarshal(metadata, &meta) == nil && meta.Timestamp > 0 {
+			metric.Timestamp = mlflowInt64(meta.Timestamp)
+		}
+		out = append(out, metric)
+	}
+	return out, rows.Err()
+}
+
+// mlflowRunParams lists the params the run was created with followed by the
+// ones logged since.
+func mlflowRunParams(ctx context.Context, db *sql.DB, runID string, created []byte) ([]mlflowKeyValue, error) {
+	out := []mlflowKeyValue{}
+	var params map[string]any
+	if err := json.Unmarshal(created, &params); err == nil {
+		for _, key := range sortedMapKeys(params) {
+			value, ok := params[key].(string)
+			if !ok {
+				raw, _ := json.Marshal(params[key])
+				value = string(raw)
+			}
+			out = append(out, mlflowKeyValue{Key: key, Value: value})
+		}
+	}
+	rows, err := db.QueryContext(ctx,
+		`SELECT key, value FROM experiment_run_logged_params WHERE run_id = $1 ORDER BY key`,
+		runID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var kv mlflowKeyValue
+		if err := rows.Scan(&kv.Key, &kv.Value); err != nil {
+			return nil, err
+		}
+		out = append(out, kv)
+	}
+	return out, rows.Err()
+}
+
+func mlflowRunTags(ctx context.Context, db *sql.DB, runID string) ([]mlflowKeyValue, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT key, value FROM experiment_run_key_tags WHERE run_id = $1 ORDER BY key`,
+		runID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []mlflowKeyValue{}
+	for rows.Next() {
+		var kv mlflowKeyValue
+		if err := rows.Scan(&kv.Key, &kv.Value); err != nil {
+			return nil, err
+		}
+		out = append(out, kv)
+	}
+	return out, rows.Err()
+}
+
+func sortedMapKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func setMLflowRunTag(ctx context.Context, tx *sql.Tx, runID, actor string, tag mlflowKeyValue, now time.Time) error {
+	_, err := tx.ExecContext(ctx,
+		`INSERT INTO experiment_run_key_tags (run_id, key, value, updated_at, updated_by)
+		 VALUES ($1,$2,$3,$4,$5)
+		 ON CONFLICT (run_id, key) DO UPDATE SET value = EXCLUDED.value, updated_at = EXCLUDED.updated_at, updated_by = EXCLUDED.updated_by`,
+		runID, strings.TrimSpace(tag.Key), tag.Value, now, actor,
+	)
+	return err
+}
+
+// logMLflowParam records a param once; as in MLflow, logging the same key
+// again is accepted only with the same value.
+func logMLflowParam(ctx context.Context, tx *sql.Tx, runID, actor string, param mlflowKeyValue, now time.Time) error {
+	key := strings.TrimSpace(param.Key)
+	type integrityInput struct {
+		RunID      string    `json:"run_id"`
+		Key        string    `json:"key"`
+		Value      string    `json:"value"`
+		RecordedAt time.Time `json:"recorded_at"`
+		RecordedBy string    `json:"recorded_by"`
+	}
+	integrity, err := integritySHA256(integrityInput{RunID: runID, Key: key, Value: param.Value, RecordedAt: now, RecordedBy: actor})
+	if err != nil {
+		return err
+	}
+	res, err := tx.ExecContext(ctx,
+		`INSERT INTO experiment_run_logged_params (run_id, key, value, recorded_at, recorded_by, integrity_sha256)
+		 VALUES ($1,$2,$3,$4,$5,$6)
+		 ON CONFLICT (run_id, key) DO NOTHING`,
+		runID, key, param.Value, now, actor, integrity,
+	)
+	if err != nil {
+		return err
+	}
+	if affected, _ := res.RowsAffected(); affected > 0 {
+		return nil
+	}
+	var existing string
+	if err := tx.QueryRowContext(ctx,
+		`SELECT value FROM experiment_run_logged_params WHERE run_id = $1 AND key = $2`,
+		runID, key,
+	).Scan(&existing); err != nil {
+		return err
+	}
+	if existing != param.Value {
+		return fmt.Errorf("%w: param %q was already logged with value %q", errMLflowParamChange, key, existing)
+	}
+	return nil
+}
+
+func (api *experimentsAPI) handleMLflowLogMetric(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		RunID   string `json:"run_id"`
+		RunUUID string `json:"run_uuid"`
+		mlflowMetric
+	}
+	if err := decodeMLflowRequest(r, &req); err != nil {
+		writeMLflowError(w, http.StatusBadRequest, mlflowInvalidParameterValue, "invalid request body")
+		return
+	}
+	api.logMLflowBatch(w, r, mlflowRequestRunID(req.RunID, req.RunUUID), []mlflowMetric{req.mlflowMetric}, nil, nil)
+}
+
+func (api *experimentsAPI) handleMLflowLogParam(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		RunID   string `json:"run_id"`
+		RunUUID string `json:"run_uuid"`
+		mlflowKeyValue
+	}
+	if err := decodeMLflowRequest(r, &req); err != nil {
+		writeMLflowError(w, http.StatusBadRequest, mlflowInvalidParameterValue, "invalid request body")
+		return
+	}
+	api.logMLflowBatch(w, r, mlflowRequestRunID(req.RunID, req.RunUUID), nil, []mlflowKeyValue{req.mlflowKeyValue}, nil)
+}
+
+func (api *experimentsAPI) handleMLflowSetTag(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		RunID   string `json:"run_id"`
+		RunUUID string `json:"run_uuid"`
+		mlflowKeyValue
+	}
+	if err := decodeMLflowRequest(r, &req); err != nil {
+		writeMLflowError(w, http.StatusBadRequest, mlflowInvalidParameterValue, "invalid request body")
+		return
+	}
+	api.logMLflowBatch(w, r, mlflowRequestRunID(req.RunID, req.RunUUID), nil, nil, []mlflowKeyValue{req.mlflowKeyValue})
+}
+
+func (api *experimentsAPI) handleMLflowLogBatch(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		RunID   string           `json:"run_id"`
+		Metrics []mlflowMetric   `json:"metrics"`
+		Params  []mlflowKeyValue `json:"params"`
+		Tags    []mlflowKeyValue `json:"tags"`
+	}
+	if err := decodeMLflowRequest(r, &req); err != nil {
+		writeMLflowError(w, http.StatusBadRequest, mlflowInvalidParameterValue, "invalid request body")
+		return
+	}
+	api.logMLflowBatch(w, r, strings.TrimSpace(req.RunID), req.Metrics, req.Params, req.Tags)
+}
+
+// logMLflowBatch backs every log endpoint. Metrics become metric samples
+// (one per name and step; a repeated step keeps the first value) and go
+// through the anomaly detector like natively ingested ones.
+func (api *experimentsAPI) logMLflowBatch(w http.ResponseWriter, r *http.Request, runID string, metrics []mlflowMetric, params, tags []mlflowKeyValue) {
+	identity, projectID, ok := api.mlflowCaller(w, r)
+	if !ok {
+		return
+	}
+	if len(metrics) > maxMLflowBatchMetrics || len(params) > maxMLflowBatchParams || len(tags) > maxMLflowBatchTags {
+		writeMLflowError(w, http.StatusBadRequest, mlflowInvalidParameterValue, "batch exceeds the per-request limits")
+		return
+	}
+	for _, metric := range metrics {
+		key := strings.TrimSpace(metric.Key)
+		if key == "" || len(key) > maxMLflowKeyBytes || metric.Step < 0 {
+			writeMLflowError(w, http.StatusBadRequest, mlflowInvalidParameterValue, "invalid metric")
+			return
+		}
+	}
+	for _, kv := range append(append([]mlflowKeyValue{}, params...), tags...) {
+		if !validMLflowKeyValue(kv) {
+			writeMLflowError(w, http.StatusBadRequest, mlflowInvalidParameterValue, "invalid param or tag")
+			return
+		}
+	}
+
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		writeMLflowInternalError(w)
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := mlflowRunExperimentID(r.Context(), tx, projectID, runID); err != nil {
+		api.writeMLflowRunError(w, err)
+		return
+	}
+
+	now := time.Now().UTC()
+	for _, param := range params {
+		if err := logMLflowParam(r.Context(), tx, runID, identity.Subject, param, now); err != nil {
+			api.writeMLflowRunError(w, err)
+			return
+		}
+	}
+	for _, tag := range tags {
+		if err := setMLflowRunTag(r.Context(), tx, runID, identity.Subject, tag, now); err != nil {
+			writeMLflowInternalError(w)
+			return
+		}
+	}
+
+	byStep := map[int64]map[string]float64{}
+	metricStore := api.metricStore(tx)
+	for _, metric := range metrics {
+		name := strings.TrimSpace(metric.Key)
+		step := int64(metric.Step)
+		metadataJSON, err := json.Marshal(map[string]any{"source": "mlflow", "timestamp": int64(metric.Timestamp)})
+		if err != nil {
+			writeMLflowInternalError(w)
+			return
+		}
+		sampleID := uuid.NewString()
+		type integrityInput struct {
+			SampleID   string          `json:"sample_id"`
+			RunID      string          `json:"run_id"`
+			RecordedAt time.Time       `json:"recorded_at"`
+			RecordedBy string          `json:"recorded_by"`
+			Step       int64           `json:"step"`
+			Name       string          `json:"name"`
+			Value      float64         `json:"value"`
+			Metadata   json.RawMessage `json:"metadata"`
+			RequestID  string          `json:"request_id,omitempty"`
+			UserAgent  string          `json:"user_agent,omitempty"`
+			RemoteAddr string          `json:"remote_addr,omitempty"`
+		}
+		integrity, err := integritySHA256(integrityInput{
+			SampleID:   sampleID,
+			RunID:      runID,
+			RecordedAt: now,
+			RecordedBy: identity.Subject,
+			Step:       step,
+			Name:       name,
+			Value:      metric.Value,
+			Metadata:   metadataJSON,
+			RequestID:  r.Header.Get("X-Request-Id"),
+			UserAgent:  r.UserAgent(),
+			RemoteAddr: r.RemoteAddr,
+		})
+		if err != nil {
+			writeMLflowInternalError(w)
+			return
+		}
+		if _, err := metricStore.InsertSample(r.Context(), repo.MetricSampleRecord{
+			SampleID:        sampleID,
+			RunID:           runID,
+			RecordedAt:      now,
+			RecordedBy:      identity.Subject,
+			Step:            step,
+			Name:            name,
+			Value:           metric.Value,
+			Metadata:        metadataJSON,
+			IntegritySHA256: integrity,
+		}); err != nil {
+			writeMLflowInternalError(w)
+			return
+		}
+		if byStep[step] == nil {
+			byStep[step] = map[string]float64{}
+		}
+		byStep[step][name] = metric.Value
+	}
+
+	steps := make([]int64, 0, len(byStep))
+	for step := range byStep {
+		steps = append(steps, step)
+	}
+	sort.Slice(steps, func(i, j int) bool { return steps[i] < steps[j] })
+	for _, step := range steps {
+		anomalies := api.metricAnomalies.Observe(runID, step, byStep[step])
+		if _, err := api.recordMetricAnomalies(r.Context(), tx, runID, anomalies); err != nil {
+			writeMLflowInternalError(w)
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		writeMLflowInternalError(w)
+		return
+	}
+	api.writeJSON(w, http.StatusOK, map[string]any{})
+}
+
+// handleMLflowPutArtifact receives log_artifact uploads, which the client
+// sends to the artifact_uri returned with the run.
+func (api *experimentsAPI) handleMLflowPutArtifact(w http.ResponseWriter, r *http.Request) {
+	identity, projectID, ok := api.mlflowCaller(w, r)
+	if !ok {
+		return
+	}
+	experimentID, runID, name, ok := parseMLflowArtifactPath(r.PathValue("artifact_path"))
+	if !ok {
+		writeMLflowError(w, http.StatusBadRequest, mlflowInvalidParameterValue, "artifact path must be <experiment_id>/<run_id>/artifacts/<path>")
+		return
+	}
+	runExperimentID, err := mlflowRunExperimentID(r.Context(), api.db, projectID, runID)
+	if err == nil && runExperimentID != experimentID {
+		err = errMLflowRunNotFound
+	}
+	if err != nil {
+		api.writeMLflowRunError(w, err)
+		return
+	}
+
+	// The blob is content-addressed, so the upload is spooled to learn its
+	// digest before it is stored.
+	spool, err := os.CreateTemp("", "mlflow-artifact-*")
+	if err != nil {
+		writeMLflowInternalError(w)
+		return
+	}
+	defer func() {
+		_ = spool.Close()
+		_ = os.Remove(spool.Name())
+	}()
+	hasher := sha256.New()
+	sizeBytes, err := io.Copy(io.MultiWriter(spool, hasher), http.MaxBytesReader(w, r.Body, maxMLflowArtifactBytes))
+	if err == nil {
+		_, err = spool.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeMLflowError(w, http.StatusRequestEntityTooLarge, mlflowInvalidParameterValue, "artifact is too large")
+			return
+		}
+		writeMLflowError(w, http.StatusBadRequest, mlflowInvalidParameterValue, "could not read artifact")
+		return
+	}
+	sha256Hex := hex.EncodeToString(hasher.Sum(nil))
+	contentType := strings.TrimSpace(r.Header.Get("Content-Type"))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	filename := sanitizeFilename(name)
+	metadata := map[string]any{"source": "mlflow", "path": name}
+	metadataJSON, err := json.Marshal(metadata)
+	if err != nil {
+		writeMLflowInternalError(w)
+		return
+	}
+
+	artifactID := uuid.NewString()
+	now := time.Now().UTC()
+
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		writeMLflowInternalError(w)
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	putCtx, cancel := context.WithTimeout(r.Context(), 10*time.Minute)
+	blob, err := referenceArtifactBlob(putCtx, tx, api.store, api.storeCfg.BucketArtifacts, projectID, sha256Hex, spool, sizeBytes, contentType, now)
+	cancel()
+	if err != nil {
+		writeMLflowError(w, http.StatusServiceUnavailable, mlflowTemporarilyUnavailable, "artifact store failed")
+		return
+	}
+
+	type integrityInput struct {
+		ArtifactID  string          `json:"artifact_id"`
+		RunID       string          `json:"run_id"`
+		Kind        string          `json:"kind"`
+		Name        string          `json:"name,omitempty"`
+		Filename    string          `json:"filename,omitempty"`
+		ContentType string          `json:"content_type,omitempty"`
+		ObjectKey   string          `json:"object_key"`
+		SHA256      string          `json:"sha256"`
+		SizeBytes   int64           `json:"size_bytes"`
+		Metadata    json.RawMessage `json:"metadata"`
+		CreatedAt   time.Time       `json:"created_at"`
+		CreatedBy   string          `json:"created_by"`
+	}
+	integrity, err := integritySHA256(integrityInput{
+		ArtifactID:  artifactID,
+		RunID:       runID,
+		Kind:        "file",
+		Name:        name,
+		Filename:    filename,
+		ContentType: contentType,
+		ObjectKey:   blob.ObjectKey,
+		SHA256:      sha256Hex,
+		SizeBytes:   blob.SizeBytes,
+		Metadata:    metadataJSON,
+		CreatedAt:   now,
+		CreatedBy:   identity.Subject,
+	})
+	if err != nil {
+		writeMLflowInternalError(w)
+		return
+	}
+	_, err = tx.ExecContext(
+		r.Context(),
+		`INSERT INTO experiment_run_artifacts (
+			artifact_id, run_id, kind, name, filename, content_type, object_key, sha256, size_bytes, metadata, created_at, created_by, integrity_sha256, blob_sha256
+		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14)`,
+		artifactID,
+		runID,
+		"file",
+		name,
+		filename,
+		contentType,
+		blob.ObjectKey,
+		sha256Hex,
+		blob.SizeBytes,
+		metadataJSON,
+		now,
+		identity.Subject,
+		integrity,
+		sha256Hex,
+	)
+	if err != nil {
+		writeMLflowInternalError(w)
+		return
+	}
+	err = lineageevent.Enqueue(r.Context(), tx, lineageevent.Event{
+		OccurredAt:  now,
+		Actor:       identity.Subject,
+		RequestID:   r.Header.Get("X-Request-Id"),
+		SubjectType: "experiment_run",
+		SubjectID:   runID,
+		Predicate:   "produced",
+		ObjectType:  "artifact",
+		ObjectID:    artifactID,
+		Metadata: map[string]any{
+			"kind":         "file",
+			"name":         name,
+			"filename":     filename,
+			"content_type": contentType,
+			"object_key":   blob.ObjectKey,
+			"sha256":       sha256Hex,
+			"size_bytes":   blob.SizeBytes,
+			"metadata":     metadata,
+		},
+	})
+	if err != nil {
+		writeMLflowInternalError(w)
+		return
+	}
+	err = auditlog.Enqueue(r.Context(), tx, auditlog.Event{
+		OccurredAt:   now,
+		Actor:        identity.Subject,
+		Action:       "experiment_run_artifact.create",
+		ResourceType: "experiment_run_artifact",
+		ResourceID:   artifactID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload: map[string]any{
+			"service":      "experiments",
+			"artifact_id":  artifactID,
+			"run_id":       runID,
+			"kind":         "file",
+			"name":         name,
+			"filename":     filename,
+			"content_type": contentType,
+			"object_key":   blob.ObjectKey,
+			"sha256":       sha256Hex,
+			"size_bytes":   blob.SizeBytes,
+			"deduplicated": blob.Deduplicated,
+			"metadata":     metadata,
+		},
+	})
+	if err != nil {
+		writeMLflowInternalError(w)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		writeMLflowInternalError(w)
+		return
+	}
+	api.writeJSON(w, http.StatusOK, map[string]any{})
+}
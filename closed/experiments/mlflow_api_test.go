@@ -0,0 +1,63 @@
+package experiments
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMLflowProjectID(t *testing.T) {
+	cases := map[string]string{
+		"/projects/p-1/mlflow/api/2.0/mlflow/runs/create": "p-1",
+		"/projects/p-1/runs":                              "",
+		"/projects/p-1/mlflowx/api":                       "",
+		"/experiments/e-1":                                "",
+	}
+	for path, want := range cases {
+		if got := mlflowProjectID(path); got != want {
+			t.Errorf("mlflowProjectID(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestParseMLflowArtifactPath(t *testing.T) {
+	experimentID, runID, name, ok := parseMLflowArtifactPath("exp-1/run-1/artifacts/model/model.pkl")
+	if !ok || experimentID != "exp-1" || runID != "run-1" || name != "model/model.pkl" {
+		t.Fatalf("got %q %q %q %v", experimentID, runID, name, ok)
+	}
+	for _, bad := range []string{"exp-1/run-1/artifacts", "exp-1/run-1/files/a.txt", "exp-1/run-1/artifacts/../../x", "/run-1/artifacts/a"} {
+		if _, _, _, ok := parseMLflowArtifactPath(bad); ok {
+			t.Errorf("parseMLflowArtifactPath(%q) accepted", bad)
+		}
+	}
+}
+
+func TestMLflowRunStatusRoundTrip(t *testing.T) {
+	for _, status := range []string{"RUNNING", "SCHEDULED", "FINISHED", "FAILED", "KILLED"} {
+		mapped, ok := mlflowRunStatus(status)
+		if !ok {
+			t.Fatalf("mlflowRunStatus(%q) rejected", status)
+		}
+		if _, ok := allowedRunStatuses[mapped]; !ok {
+			t.Fatalf("%s maps to unknown run status %q", status, mapped)
+		}
+		if back := mlflowStatusOf(mapped); back != status {
+			t.Fatalf("%s round-trips to %s", status, back)
+		}
+	}
+	if _, ok := mlflowRunStatus("DELETED"); ok {
+		t.Fatal("unknown status accepted")
+	}
+}
+
+func TestMLflowInt64AcceptsStrings(t *testing.T) {
+	var metric mlflowMetric
+	if err := json.Unmarshal([]byte(`{"key":"loss","value":0.5,"timestamp":"1700000000000","step":3}`), &metric); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if metric.Timestamp != 1700000000000 || metric.Step != 3 {
+		t.Fatalf("metric = %+v", metric)
+	}
+	if err := json.Unmarshal([]byte(`{"step":"x"}`), &metric); err == nil {
+		t.Fatal("non-numeric step accepted")
+	}
+}
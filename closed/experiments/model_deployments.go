@@ -0,0 +1,505 @@
+package experiments
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/domain"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	"github.com/animus-labs/animus-go/closed/internal/platform/lineageevent"
+	"github.com/animus-labs/animus-go/closed/internal/platform/policy"
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+	"github.com/google/uuid"
+)
+
+const (
+	auditModelDeploymentRegistered = "model.deployment.registered"
+	auditModelDeploymentBlocked    = "model.deployment.blocked"
+)
+
+const (
+	deploymentStatusActive     = "active"
+	deploymentStatusSuperseded = "superseded"
+)
+
+const (
+	maxDeploymentEndpointLen = 2048
+	maxDeploymentReplicas    = 10000
+	maxReplicaInfoBytes      = 16 << 10
+)
+
+var deploymentEnvironmentPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{0,62}$`)
+
+type modelDeploymentRequest struct {
+	Environment string          `json:"environment"`
+	EndpointURL string          `json:"endpoint_url"`
+	Replicas    *int            `json:"replicas"`
+	ReplicaInfo json.RawMessage `json:"replica_info,omitempty"`
+	DeployedAt  *time.Time      `json:"deployed_at,omitempty"`
+	DeployedBy  string          `json:"deployed_by,omitempty"`
+}
+
+type modelDeployment struct {
+	DeploymentID    string          `json:"deployment_id"`
+	ProjectID       string          `json:"project_id"`
+	ModelID         string          `json:"model_id"`
+	ModelVersionID  string          `json:"model_version_id"`
+	RunID           string          `json:"run_id"`
+	Environment     string          `json:"environment"`
+	EndpointURL     string          `json:"endpoint_url"`
+	Replicas        int             `json:"replicas"`
+	ReplicaInfo     json.RawMessage `json:"replica_info"`
+	Status          string          `json:"status"`
+	DeployedAt      time.Time       `json:"deployed_at"`
+	DeployedBy      string          `json:"deployed_by"`
+	SupersededAt    *time.Time      `json:"superseded_at,omitempty"`
+	SupersededBy    string          `json:"superseded_by,omitempty"`
+	CreatedAt       time.Time       `json:"created_at"`
+	CreatedBy       string          `json:"created_by"`
+	IntegritySHA256 string          `json:"integrity_sha256"`
+}
+
+// validateDeploymentRequest normalizes req in place and returns the error
+// code for an invalid request.
+func validateDeploymentRequest(req *modelDeploymentRequest, now time.Time) string {
+	req.Environment = strings.ToLower(strings.TrimSpace(req.Environment))
+	if !deploymentEnvironmentPattern.MatchString(req.Environment) {
+		return "environment_invalid"
+	}
+	req.EndpointURL = strings.TrimSpace(req.EndpointURL)
+	endpoint, err := url.Parse(req.EndpointURL)
+	if err != nil || len(req.EndpointURL) > maxDeploymentEndpointLen || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" || endpoint.User != nil {
+		return "endpoint_url_invalid"
+	}
+	if req.Replicas == nil || *req.Replicas < 0 || *req.Replicas > maxDeploymentReplicas {
+		return "replicas_invalid"
+	}
+	info := bytes.TrimSpace(req.ReplicaInfo)
+	if len(info) == 0 || bytes.Equal(info, []byte("null")) {
+		info = []byte("{}")
+	}
+	var object map[string]any
+	if len(info) > maxReplicaInfoBytes || json.Unmarshal(info, &object) != nil || object == nil {
+		return "replica_info_invalid"
+	}
+	req.ReplicaInfo = info
+	if req.DeployedAt != nil && req.DeployedAt.After(now) {
+		return "deployed_at_invalid"
+	}
+	req.DeployedBy = strings.TrimSpace(req.DeployedBy)
+	return ""
+}
+
+// requireDeploymentPolicyAllow evaluates the project's active policies for a
+// deployment. Only explicit deny rules block, as for dataset policies on run
+// registration; a blocked registration is audited.
+func (api *experimentsAPI) requireDeploymentPolicyAllow(w http.ResponseWriter, r *http.Request, identity auth.Identity, version domain.ModelVersion, req modelDeploymentRequest) bool {
+	ctx := r.Context()
+	policies, err := api.loadScopedPolicyVersions(ctx, policyScope{ProjectID: version.ProjectID})
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return false
+	}
+	if len(policies) == 0 {
+		return true
+	}
+	replicas := *req.Replicas
+	policyContext := policy.Context{
+		Actor:      policy.ActorContext{Subject: identity.Subject, Email: identity.Email, Roles: identity.Roles},
+		Experiment: policy.ExperimentContext{RunID: version.RunID},
+		Deployment: policy.DeploymentContext{
+			Environment:    req.Environment,
+			EndpointURL:    req.EndpointURL,
+			Replicas:       &replicas,
+			ModelID:        version.ModelID,
+			ModelVersionID: version.ID,
+			ModelStatus:    string(version.Status),
+		},
+	}
+	denial, err := api.firstDatasetPolicyMatch(ctx, policies, policyContext, policy.EffectDeny)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return false
+	}
+	if denial == nil {
+		return true
+	}
+	_ = auditlog.Enqueue(ctx, api.db, auditlog.Event{
+		OccurredAt:   time.Now().UTC(),
+		Actor:        identity.Subject,
+		Action:       auditModelDeploymentBlocked,
+		ResourceType: "model_version",
+		ResourceID:   version.ID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload: map[string]any{
+			"service":           "experiments",
+			"project_id":        version.ProjectID,
+			"model_id":          version.ModelID,
+			"environment":       req.Environment,
+			"endpoint_url":      req.EndpointURL,
+			"policy_id":         denial.PolicyID,
+			"policy_version_id": denial.PolicyVersionID,
+			"rule_id":           denial.Decision.RuleID,
+			"reason":            "policy_denied",
+		},
+	})
+	api.writeError(w, r, http.StatusForbidden, "policy_denied")
+	return false
+}
+
+// handleCreateModelDeployment records that a model version now serves an
+// endpoint. The previous active deployment of the endpoint is superseded.
+func (api *experimentsAPI) handleCreateModelDeployment(w http.ResponseWriter, r *http.Request) {
+	identity, projectID, ok := api.scopedProject(w, r)
+	if !ok {
+		return
+	}
+	versionID := strings.TrimSpace(r.PathValue("model_version_id"))
+	if versionID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "model_version_id_required")
+		return
+	}
+	var req modelDeploymentRequest
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
+		return
+	}
+	now := time.Now().UTC()
+	if code := validateDeploymentRequest(&req, now); code != "" {
+		api.writeError(w, r, http.StatusBadRequest, code)
+		return
+	}
+
+	versionStore := api.modelVersionStore()
+	if versionStore == nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	version, err := versionStore.Get(r.Context(), projectID, versionID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if version.Status == domain.ModelStatusDeprecated {
+		api.writeError(w, r, http.StatusConflict, "model_version_deprecated")
+		return
+	}
+	if !api.requireDeploymentPolicyAllow(w, r, identity, version, req) {
+		return
+	}
+
+	deployment := modelDeployment{
+		DeploymentID:   uuid.NewString(),
+		ProjectID:      projectID,
+		ModelID:        version.ModelID,
+		ModelVersionID: version.ID,
+		RunID:          version.RunID,
+		Environment:    req.Environment,
+		EndpointURL:    req.EndpointURL,
+		Replicas:       *req.Replicas,
+		ReplicaInfo:    req.ReplicaInfo,
+		Status:         deploymentStatusActive,
+		DeployedAt:     now,
+		DeployedBy:     identity.Subject,
+		CreatedAt:      now,
+		CreatedBy:      identity.Subject,
+	}
+	if req.DeployedAt != nil {
+		deployment.DeployedAt = req.DeployedAt.UTC().Truncate(time.Microsecond)
+	}
+	if req.DeployedBy != "" {
+		deployment.DeployedBy = req.DeployedBy
+	}
+	integrity, err := integritySHA256(deployment)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "integrity_failed")
+		return
+	}
+	deployment.IntegritySHA256 = integrity
+
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Retire the endpoint's active deployment before inserting its successor;
+	// superseded_by is checked at commit.
+	var superseded []string
+	rows, err := tx.QueryContext(
+		r.Context(),
+		`UPDATE model_deployments
+		 SET status = $5, superseded_at = $6, superseded_by = $7
+		 WHERE project_id = $1 AND environment = $2 AND endpoint_url = $3 AND status = $4
+		 RETURNING deployment_id`,
+		projectID,
+		deployment.Environment,
+		deployment.EndpointURL,
+		deploymentStatusActive,
+		deploymentStatusSuperseded,
+		now,
+		deployment.DeploymentID,
+	)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			_ = rows.Close()
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+		superseded = append(superseded, id)
+	}
+	if err := rows.Close(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if _, err := tx.ExecContext(
+		r.Context(),
+		`INSERT INTO model_deployments (
+			deployment_id, project_id, model_id, model_version_id, run_id, environment, endpoint_url,
+			replicas, replica_info, status, deployed_at, deployed_by, created_at, created_by, integrity_sha256
+		 ) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15)`,
+		deployment.DeploymentID,
+		projectID,
+		deployment.ModelID,
+		deployment.ModelVersionID,
+		deployment.RunID,
+		deployment.Environment,
+		deployment.EndpointURL,
+		deployment.Replicas,
+		[]byte(deployment.ReplicaInfo),
+		deploymentStatusActive,
+		deployment.DeployedAt,
+		deployment.DeployedBy,
+		now,
+		identity.Subject,
+		deployment.IntegritySHA256,
+	); err != nil {
+		if isUniqueViolation(err) {
+			api.writeError(w, r, http.StatusConflict, "deployment_conflict")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	for _, subject := range []struct{ typ, id string }{
+		{"model_version", deployment.ModelVersionID},
+		{"experiment_run", deployment.RunID},
+	} {
+		if err := lineageevent.Enqueue(r.Context(), tx, lineageevent.Event{
+			OccurredAt:  now,
+			Actor:       identity.Subject,
+			RequestID:   r.Header.Get("X-Request-Id"),
+			SubjectType: subject.typ,
+			SubjectID:   subject.id,
+			Predicate:   "deployed_as",
+			ObjectType:  "model_deployment",
+			ObjectID:    deployment.DeploymentID,
+			Metadata: map[string]any{
+				"environment":  deployment.Environment,
+				"endpoint_url": deployment.EndpointURL,
+			},
+		}); err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+	}
+	if err := auditlog.Enqueue(r.Context(), tx, auditlog.Event{
+		OccurredAt:   now,
+		Actor:        identity.Subject,
+		Action:       auditModelDeploymentRegistered,
+		ResourceType: "model_deployment",
+		ResourceID:   deployment.DeploymentID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload: map[string]any{
+			"service":          "experiments",
+			"project_id":       projectID,
+			"model_id":         deployment.ModelID,
+			"model_version_id": deployment.ModelVersionID,
+			"run_id":           deployment.RunID,
+			"environment":      deployment.Environment,
+			"endpoint_url":     deployment.EndpointURL,
+			"replicas":         deployment.Replicas,
+			"deployed_by":      deployment.DeployedBy,
+			"superseded":       superseded,
+		},
+	}); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "audit_failed")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	api.writeJSON(w, http.StatusCreated, deployment)
+}
+
+const modelDeploymentColumns = `deployment_id, project_id, model_id, model_version_id, run_id, environment, endpoint_url,
+	replicas, replica_info, status, deployed_at, deployed_by, superseded_at, superseded_by, created_at, created_by, integrity_sha256`
+
+func scanModelDeployment(row interface{ Scan(...any) error }) (modelDeployment, error) {
+	var (
+		out          modelDeployment
+		replicaInfo  []byte
+		supersededAt sql.NullTime
+		supersededBy sql.NullString
+	)
+	if err := row.Scan(
+		&out.DeploymentID,
+		&out.ProjectID,
+		&out.ModelID,
+		&out.ModelVersionID,
+		&out.RunID,
+		&out.Environment,
+		&out.EndpointURL,
+		&out.Replicas,
+		&replicaInfo,
+		&out.Status,
+		&out.DeployedAt,
+		&out.DeployedBy,
+		&supersededAt,
+		&supersededBy,
+		&out.CreatedAt,
+		&out.CreatedBy,
+		&out.IntegritySHA256,
+	); err != nil {
+		return modelDeployment{}, err
+	}
+	out.ReplicaInfo = normalizeJSON(replicaInfo)
+	out.DeployedAt = out.DeployedAt.UTC()
+	out.CreatedAt = out.CreatedAt.UTC()
+	if supersededAt.Valid {
+		at := supersededAt.Time.UTC()
+		out.SupersededAt = &at
+	}
+	out.SupersededBy = supersededBy.String
+	return out, nil
+}
+
+func (api *experimentsAPI) handleListModelDeployments(w http.ResponseWriter, r *http.Request) {
+	_, projectID, ok := api.scopedProject(w, r)
+	if !ok {
+		return
+	}
+	query := r.URL.Query()
+	status := strings.TrimSpace(query.Get("status"))
+	if status != "" && status != deploymentStatusActive && status != deploymentStatusSuperseded {
+		api.writeError(w, r, http.StatusBadRequest, "invalid_status")
+		return
+	}
+	limit := httpapi.Limit(r, 100, 500)
+	rows, err := api.db.QueryContext(
+		r.Context(),
+		`SELECT `+modelDeploymentColumns+`
+		 FROM model_deployments
+		 WHERE project_id = $1
+		   AND ($2 = '' OR environment = $2)
+		   AND ($3 = '' OR model_id = $3)
+		   AND ($4 = '' OR model_version_id = $4)
+		   AND ($5 = '' OR status = $5)
+		 ORDER BY deployed_at DESC, deployment_id DESC
+		 LIMIT $6`,
+		projectID,
+		strings.ToLower(strings.TrimSpace(query.Get("environment"))),
+		strings.TrimSpace(query.Get("model_id")),
+		strings.TrimSpace(query.Get("model_version_id")),
+		status,
+		limit,
+	)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer rows.Close()
+	deployments := []modelDeployment{}
+	for rows.Next() {
+		deployment, err := scanModelDeployment(rows)
+		if err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+		deployments = append(deployments, deployment)
+	}
+	if err := rows.Err(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	api.writeJSON(w, http.StatusOK, map[string]any{"deployments": deployments})
+}
+
+func (api *experimentsAPI) handleGetModelDeployment(w http.ResponseWriter, r *http.Request) {
+	_, projectID, ok := api.scopedProject(w, r)
+	if !ok {
+		return
+	}
+	deploymentID := strings.TrimSpace(r.PathValue("deployment_id"))
+	if deploymentID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "deployment_id_required")
+		return
+	}
+	deployment, err := scanModelDeployment(api.db.QueryRowContext(
+		r.Context(),
+		`SELECT `+modelDeploymentColumns+` FROM model_deployments WHERE project_id = $1 AND deployment_id = $2`,
+		projectID,
+		deploymentID,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	api.writeJSON(w, http.StatusOK, deployment)
+}
+
+// fetchEvidenceDeployments returns the deployments of model versions built
+// from runID, oldest first.
+func fetchEvidenceDeployments(ctx context.Context, db *sql.DB, runID string) ([]modelDeployment, error) {
+	rows, err := db.QueryContext(
+		ctx,
+		`SELECT `+modelDeploymentColumns+`
+		 FROM model_deployments
+		 WHERE run_id = $1
+		 ORDER BY deployed_at ASC, deployment_id ASC`,
+		runID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := make([]modelDeployment, 0)
+	for rows.Next() {
+		deployment, err := scanModelDeployment(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, deployment)
+	}
+	return out, rows.Err()
+}
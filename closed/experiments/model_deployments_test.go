@@ -0,0 +1,61 @@
+package experiments
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateDeploymentRequest(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	replicas := func(n int) *int { return &n }
+	future := now.Add(time.Hour)
+
+	cases := []struct {
+		name string
+		req  modelDeploymentRequest
+		want string
+	}{
+		{name: "ok", req: modelDeploymentRequest{Environment: " Prod ", EndpointURL: "https://serve.example.com/v1/predict", Replicas: replicas(3)}},
+		{name: "zero replicas", req: modelDeploymentRequest{Environment: "staging", EndpointURL: "http://10.0.0.1:8080", Replicas: replicas(0)}},
+		{name: "empty environment", req: modelDeploymentRequest{EndpointURL: "https://a.example", Replicas: replicas(1)}, want: "environment_invalid"},
+		{name: "bad environment", req: modelDeploymentRequest{Environment: "prod/eu", EndpointURL: "https://a.example", Replicas: replicas(1)}, want: "environment_invalid"},
+		{name: "relative url", req: modelDeploymentRequest{Environment: "prod", EndpointURL: "/predict", Replicas: replicas(1)}, want: "endpoint_url_invalid"},
+		{name: "grpc url", req: modelDeploymentRequest{Environment: "prod", EndpointURL: "grpc://a.example", Replicas: replicas(1)}, want: "endpoint_url_invalid"},
+		{name: "credentials", req: modelDeploymentRequest{Environment: "prod", EndpointURL: "https://u:p@a.example", Replicas: replicas(1)}, want: "endpoint_url_invalid"},
+		{name: "long url", req: modelDeploymentRequest{Environment: "prod", EndpointURL: "https://a.example/" + strings.Repeat("x", maxDeploymentEndpointLen), Replicas: replicas(1)}, want: "endpoint_url_invalid"},
+		{name: "missing replicas", req: modelDeploymentRequest{Environment: "prod", EndpointURL: "https://a.example"}, want: "replicas_invalid"},
+		{name: "negative replicas", req: modelDeploymentRequest{Environment: "prod", EndpointURL: "https://a.example", Replicas: replicas(-1)}, want: "replicas_invalid"},
+		{name: "replica info array", req: modelDeploymentRequest{Environment: "prod", EndpointURL: "https://a.example", Replicas: replicas(1), ReplicaInfo: json.RawMessage(`[1]`)}, want: "replica_info_invalid"},
+		{name: "future deployed_at", req: modelDeploymentRequest{Environment: "prod", EndpointURL: "https://a.example", Replicas: replicas(1), DeployedAt: &future}, want: "deployed_at_invalid"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := tc.req
+			if got := validateDeploymentRequest(&req, now); got != tc.want {
+				t.Fatalf("validateDeploymentRequest() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestValidateDeploymentRequestNormalizes(t *testing.T) {
+	replicas := 2
+	req := modelDeploymentRequest{
+		Environment: " Prod ",
+		EndpointURL: " https://serve.example.com ",
+		Replicas:    &replicas,
+		ReplicaInfo: json.RawMessage(`null`),
+		DeployedBy:  " ci-bot ",
+	}
+	if code := validateDeploymentRequest(&req, time.Now()); code != "" {
+		t.Fatalf("unexpected code %q", code)
+	}
+	if req.Environment != "prod" || req.EndpointURL != "https://serve.example.com" || req.DeployedBy != "ci-bot" {
+		t.Fatalf("not normalized: %+v", req)
+	}
+	if string(req.ReplicaInfo) != "{}" {
+		t.Fatalf("replica_info = %s, want {}", req.ReplicaInfo)
+	}
+}
@@ -0,0 +1,398 @@
+package experiments
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/integrations/registryverify"
+	"github.com/animus-labs/animus-go/closed/internal/integrations/vulnscan"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	"github.com/animus-labs/animus-go/closed/internal/platform/policy"
+	"github.com/google/uuid"
+)
+
+const (
+	vulnScanSourcePush = "push"
+	vulnScanSourcePull = "pull"
+
+	// Raw scanner reports are far larger than regular request bodies.
+	vulnScanMaxBodyBytes = 32 << 20
+)
+
+type vulnerabilityScan struct {
+	ScanID         string             `json:"scan_id"`
+	ImageDigest    string             `json:"image_digest"`
+	Scanner        string             `json:"scanner"`
+	ScannerVersion string             `json:"scanner_version,omitempty"`
+	Source         string             `json:"source"`
+	Counts         vulnscan.Counts    `json:"counts"`
+	Findings       []vulnscan.Finding `json:"findings,omitempty"`
+	ReportSHA256   string             `json:"report_sha256"`
+	ScannedAt      time.Time          `json:"scanned_at"`
+	CreatedAt      time.Time          `json:"created_at"`
+	CreatedBy      string             `json:"created_by"`
+}
+
+type pushVulnerabilityScanRequest struct {
+	// Format is the scanner output format: trivy or grype.
+	Format    string          `json:"format"`
+	Report    json.RawMessage `json:"report"`
+	ScannedAt *time.Time      `json:"scanned_at,omitempty"`
+}
+
+type pullVulnerabilityScanRequest struct {
+	// ImageRef is the registry repository of the digest, e.g.
+	// ghcr.io/acme/train; the scanner pulls ImageRef@digest.
+	ImageRef string `json:"image_ref"`
+}
+
+func vulnerabilityPolicyCounts(counts vulnscan.Counts) *policy.VulnerabilityCounts {
+	return &policy.VulnerabilityCounts{
+		Critical: counts.Critical,
+		High:     counts.High,
+		Medium:   counts.Medium,
+		Low:      counts.Low,
+		Unknown:  counts.Unknown,
+	}
+}
+
+func imageDigestFromPath(r *http.Request) (string, string) {
+	imageDigest := strings.ToLower(strings.TrimSpace(r.PathValue("image_digest")))
+	if imageDigest == "" {
+		return "", "image_digest_required"
+	}
+	if !isSHA256Digest(imageDigest) {
+		return "", "image_digest_invalid"
+	}
+	return imageDigest, ""
+}
+
+func (api *experimentsAPI) handlePushVulnerabilityScan(w http.ResponseWriter, r *http.Request) {
+	identity, ok := auth.IdentityFromContext(r.Context())
+	if !ok || strings.TrimSpace(identity.Subject) == "" {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	imageDigest, code := imageDigestFromPath(r)
+	if code != "" {
+		api.writeError(w, r, http.StatusBadRequest, code)
+		return
+	}
+
+	var req pushVulnerabilityScanRequest
+	dec := json.NewDecoder(io.LimitReader(r.Body, vulnScanMaxBodyBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
+		return
+	}
+	format, err := vulnscan.NormalizeFormat(req.Format)
+	if err != nil {
+		api.writeError(w, r, http.StatusBadRequest, "invalid_format")
+		return
+	}
+	if len(req.Report) == 0 {
+		api.writeError(w, r, http.StatusBadRequest, "report_required")
+		return
+	}
+	report, err := vulnscan.Parse(format, req.Report)
+	if err != nil {
+		api.writeError(w, r, http.StatusBadRequest, "invalid_report")
+		return
+	}
+	scannedAt := time.Now().UTC()
+	if req.ScannedAt != nil {
+		scannedAt = req.ScannedAt.UTC()
+	}
+
+	scan, err := api.insertVulnerabilityScan(r, identity, imageDigest, vulnScanSourcePush, report, sha256HexBytes(req.Report), scannedAt)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	api.writeJSON(w, http.StatusCreated, scan)
+}
+
+func (api *experimentsAPI) handlePullVulnerabilityScan(w http.ResponseWriter, r *http.Request) {
+	identity, ok := auth.IdentityFromContext(r.Context())
+	if !ok || strings.TrimSpace(identity.Subject) == "" {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	imageDigest, code := imageDigestFromPath(r)
+	if code != "" {
+		api.writeError(w, r, http.StatusBadRequest, code)
+		return
+	}
+	if api.vulnScanner == nil {
+		api.writeError(w, r, http.StatusServiceUnavailable, "scanner_not_configured")
+		return
+	}
+
+	var req pullVulnerabilityScanRequest
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
+		return
+	}
+	imageRef := strings.TrimSpace(req.ImageRef)
+	if imageRef == "" {
+		api.writeError(w, r, http.StatusBadRequest, "image_ref_required")
+		return
+	}
+	if base, digest, found := strings.Cut(imageRef, "@"); found {
+		if strings.ToLower(digest) != imageDigest {
+			api.writeError(w, r, http.StatusBadRequest, "image_digest_mismatch")
+			return
+		}
+		imageRef = base
+	}
+	digestRef, err := registryverify.BuildDigestRef(imageRef, imageDigest)
+	if err != nil {
+		api.writeError(w, r, http.StatusBadRequest, "image_ref_invalid")
+		return
+	}
+
+	report, err := api.vulnScanner.Scan(r.Context(), digestRef)
+	if err != nil {
+		api.logger.Warn("vulnerability scan failed", "image", digestRef, "error", err)
+		api.writeError(w, r, http.StatusBadGateway, "scan_failed")
+		return
+	}
+	reportJSON, err := json.Marshal(report)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	scan, err := api.insertVulnerabilityScan(r, identity, imageDigest, vulnScanSourcePull, report, sha256HexBytes(reportJSON), time.Now().UTC())
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	api.writeJSON(w, http.StatusCreated, scan)
+}
+
+func (api *experimentsAPI) insertVulnerabilityScan(r *http.Request, identity auth.Identity, imageDigest, source string, report vulnscan.Report, reportSHA256 string, scannedAt time.Time) (vulnerabilityScan, error) {
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	scan := vulnerabilityScan{
+		ScanID:         uuid.NewString(),
+		ImageDigest:    imageDigest,
+		Scanner:        report.Scanner,
+		ScannerVersion: report.ScannerVersion,
+		Source:         source,
+		Counts:         report.Counts,
+		Findings:       report.Findings,
+		ReportSHA256:   reportSHA256,
+		ScannedAt:      scannedAt.Truncate(time.Microsecond),
+		CreatedAt:      now,
+		CreatedBy:      identity.Subject,
+	}
+	findingsJSON, err := json.Marshal(scan.Findings)
+	if err != nil {
+		return vulnerabilityScan{}, err
+	}
+	integrity, err := integritySHA256(scan)
+	if err != nil {
+		return vulnerabilityScan{}, err
+	}
+
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		return vulnerabilityScan{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	_, err = tx.ExecContext(
+		r.Context(),
+		`INSERT INTO model_image_vulnerability_scans (
+			scan_id,
+			image_digest,
+			scanner,
+			scanner_version,
+			source,
+			critical_count,
+			high_count,
+			medium_count,
+			low_count,
+			unknown_count,
+			findings,
+			report_sha256,
+			scanned_at,
+			created_at,
+			created_by,
+			integrity_sha256
+		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16)`,
+		scan.ScanID,
+		scan.ImageDigest,
+		scan.Scanner,
+		nullString(scan.ScannerVersion),
+		scan.Source,
+		scan.Counts.Critical,
+		scan.Counts.High,
+		scan.Counts.Medium,
+		scan.Counts.Low,
+		scan.Counts.Unknown,
+		findingsJSON,
+		scan.ReportSHA256,
+		scan.ScannedAt,
+		scan.CreatedAt,
+		scan.CreatedBy,
+		integrity,
+	)
+	if err != nil {
+		return vulnerabilityScan{}, err
+	}
+
+	err = auditlog.Enqueue(r.Context(), tx, auditlog.Event{
+		OccurredAt:   now,
+		Actor:        identity.Subject,
+		Action:       "model_image.vulnerability_scan",
+		ResourceType: "model_image",
+		ResourceID:   imageDigest,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload: map[string]any{
+			"service":       "experiments",
+			"scan_id":       scan.ScanID,
+			"image_digest":  imageDigest,
+			"scanner":       scan.Scanner,
+			"source":        source,
+			"counts":        scan.Counts,
+			"report_sha256": reportSHA256,
+		},
+	})
+	if err != nil {
+		return vulnerabilityScan{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return vulnerabilityScan{}, err
+	}
+	return scan, nil
+}
+
+const vulnerabilityScanColumns = `scan_id, image_digest, scanner, scanner_version, source,
+	critical_count, high_count, medium_count, low_count, unknown_count,
+	findings, report_sha256, scanned_at, created_at, created_by`
+
+func scanVulnerabilityScan(row rowScanner) (vulnerabilityScan, error) {
+	var (
+		scan     vulnerabilityScan
+		version  sql.NullString
+		findings []byte
+	)
+	if err := row.Scan(
+		&scan.ScanID,
+		&scan.ImageDigest,
+		&scan.Scanner,
+		&version,
+		&scan.Source,
+		&scan.Counts.Critical,
+		&scan.Counts.High,
+		&scan.Counts.Medium,
+		&scan.Counts.Low,
+		&scan.Counts.Unknown,
+		&findings,
+		&scan.ReportSHA256,
+		&scan.ScannedAt,
+		&scan.CreatedAt,
+		&scan.CreatedBy,
+	); err != nil {
+		return vulnerabilityScan{}, err
+	}
+	scan.ScannerVersion = version.String
+	scan.ScannedAt = scan.ScannedAt.UTC()
+	scan.CreatedAt = scan.CreatedAt.UTC()
+	if len(findings) > 0 {
+		if err := json.Unmarshal(findings, &scan.Findings); err != nil {
+			return vulnerabilityScan{}, err
+		}
+	}
+	return scan, nil
+}
+
+// latestVulnerabilityScan returns sql.ErrNoRows when the digest was never
+// scanned.
+func (api *experimentsAPI) latestVulnerabilityScan(ctx context.Context, imageDigest string) (vulnerabilityScan, error) {
+	return scanVulnerabilityScan(api.db.QueryRowContext(
+		ctx,
+		`SELECT `+vulnerabilityScanColumns+`
+		 FROM model_image_vulnerability_scans
+		 WHERE image_digest = $1
+		 ORDER BY scanned_at DESC, created_at DESC
+		 LIMIT 1`,
+		imageDigest,
+	))
+}
+
+func (api *experimentsAPI) handleListVulnerabilityScans(w http.ResponseWriter, r *http.Request) {
+	imageDigest, code := imageDigestFromPath(r)
+	if code != "" {
+		api.writeError(w, r, http.StatusBadRequest, code)
+		return
+	}
+	limit := httpapi.Limit(r, 20, 200)
+
+	rows, err := api.db.QueryContext(
+		r.Context(),
+		`SELECT `+vulnerabilityScanColumns+`
+		 FROM model_image_vulnerability_scans
+		 WHERE image_digest = $1
+		 ORDER BY scanned_at DESC, created_at DESC
+		 LIMIT $2`,
+		imageDigest,
+		limit,
+	)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer rows.Close()
+
+	out := make([]vulnerabilityScan, 0, limit)
+	for rows.Next() {
+		scan, err := scanVulnerabilityScan(rows)
+		if err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+		out = append(out, scan)
+	}
+	if err := rows.Err(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	api.writeJSON(w, http.StatusOK, map[string]any{
+		"vulnerability_scans": out,
+	})
+}
+
+// imagePolicyVulnerabilities fills the scan fields of the policy image from
+// the latest scan of its digest.
+func (api *experimentsAPI) imagePolicyVulnerabilities(ctx context.Context, image *policy.ImageContext) error {
+	digest := strings.ToLower(strings.TrimSpace(image.Digest))
+	if !isSHA256Digest(digest) {
+		return nil
+	}
+	scanned := false
+	image.Scanned = &scanned
+	scan, err := api.latestVulnerabilityScan(ctx, digest)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	scanned = true
+	image.Vulnerabilities = vulnerabilityPolicyCounts(scan.Counts)
+	return nil
+}
@@ -0,0 +1,73 @@
+package experiments
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/policy"
+)
+
+const testScanDigest = "sha256:a1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90"
+
+func serveVulnerabilityScan(t *testing.T, api *experimentsAPI, path, body string) (int, string) {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /model-images/{image_digest}/vulnerability-scans", api.handlePushVulnerabilityScan)
+	mux.HandleFunc("POST /model-images/{image_digest}/vulnerability-scans/pull", api.handlePullVulnerabilityScan)
+
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(body))
+	req = req.WithContext(auth.ContextWithIdentity(req.Context(), auth.Identity{Subject: "user-1"}))
+	resp := httptest.NewRecorder()
+	mux.ServeHTTP(resp, req)
+
+	var out struct {
+		Error string `json:"error"`
+	}
+	_ = json.NewDecoder(resp.Body).Decode(&out)
+	return resp.Code, out.Error
+}
+
+func TestPushVulnerabilityScanRejects(t *testing.T) {
+	cases := []struct {
+		name   string
+		digest string
+		body   string
+		code   string
+	}{
+		{name: "digest", digest: "sha256:abc", body: `{"format":"trivy","report":{}}`, code: "image_digest_invalid"},
+		{name: "json", digest: testScanDigest, body: `{"format":"trivy","extra":1}`, code: "invalid_json"},
+		{name: "format", digest: testScanDigest, body: `{"format":"clair","report":{}}`, code: "invalid_format"},
+		{name: "report missing", digest: testScanDigest, body: `{"format":"grype"}`, code: "report_required"},
+		{name: "report invalid", digest: testScanDigest, body: `{"format":"trivy","report":[1,2]}`, code: "invalid_report"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			status, code := serveVulnerabilityScan(t, &experimentsAPI{}, "/model-images/"+tc.digest+"/vulnerability-scans", tc.body)
+			if status != http.StatusBadRequest || code != tc.code {
+				t.Fatalf("expected 400 %s, got %d %q", tc.code, status, code)
+			}
+		})
+	}
+}
+
+func TestPullVulnerabilityScanWithoutScanner(t *testing.T) {
+	status, code := serveVulnerabilityScan(t, &experimentsAPI{}, "/model-images/"+testScanDigest+"/vulnerability-scans/pull", `{"image_ref":"ghcr.io/acme/train"}`)
+	if status != http.StatusServiceUnavailable || code != "scanner_not_configured" {
+		t.Fatalf("expected 503 scanner_not_configured, got %d %q", status, code)
+	}
+}
+
+func TestImagePolicyVulnerabilitiesSkipsUnpinnedImages(t *testing.T) {
+	image := policy.ImageContext{Ref: "ghcr.io/acme/train:latest"}
+	if err := (&experimentsAPI{}).imagePolicyVulnerabilities(context.Background(), &image); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if image.Scanned != nil || image.Vulnerabilities != nil {
+		t.Fatalf("expected no scan fields for unpinned image, got %+v", image)
+	}
+}
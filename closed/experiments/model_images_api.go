@@ -1,4 +1,4 @@
-package main
+package experiments
 
 import (
 	"database/sql"
@@ -7,6 +7,9 @@ import (
 	"net/http"
 	"strings"
 	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/integrations/vulnscan"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
 )
 
 type modelImage struct {
@@ -18,10 +21,20 @@ type modelImage struct {
 	ReceivedAt  time.Time       `json:"received_at"`
 	ReceivedBy  string          `json:"received_by"`
 	Payload     json.RawMessage `json:"payload,omitempty"`
+	// Vulnerabilities summarizes the latest vulnerability scan of the digest.
+	Vulnerabilities *modelImageVulnerabilities `json:"vulnerabilities,omitempty"`
+}
+
+type modelImageVulnerabilities struct {
+	ScanID         string          `json:"scan_id"`
+	Scanner        string          `json:"scanner"`
+	ScannerVersion string          `json:"scanner_version,omitempty"`
+	Counts         vulnscan.Counts `json:"counts"`
+	ScannedAt      time.Time       `json:"scanned_at"`
 }
 
 func (api *experimentsAPI) handleListModelImages(w http.ResponseWriter, r *http.Request) {
-	limit := clampInt(parseIntQuery(r, "limit", 100), 1, 500)
+	limit := httpapi.Limit(r, 100, 500)
 	repo := strings.TrimSpace(r.URL.Query().Get("repo"))
 	commitSHA := strings.TrimSpace(r.URL.Query().Get("commit_sha"))
 
@@ -126,7 +139,7 @@ func (api *experimentsAPI) handleGetModelImage(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	api.writeJSON(w, http.StatusOK, modelImage{
+	image := modelImage{
 		ImageDigest: imageDigest,
 		Repo:        repoValue,
 		CommitSHA:   commitValue,
@@ -135,5 +148,21 @@ func (api *experimentsAPI) handleGetModelImage(w http.ResponseWriter, r *http.Re
 		ReceivedAt:  receivedAt,
 		ReceivedBy:  receivedBy,
 		Payload:     normalizeJSON(payload),
-	})
+	}
+	scan, err := api.latestVulnerabilityScan(r.Context(), imageDigest)
+	switch {
+	case err == nil:
+		image.Vulnerabilities = &modelImageVulnerabilities{
+			ScanID:         scan.ScanID,
+			Scanner:        scan.Scanner,
+			ScannerVersion: scan.ScannerVersion,
+			Counts:         scan.Counts,
+			ScannedAt:      scan.ScannedAt,
+		}
+	case !errors.Is(err, sql.ErrNoRows):
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	api.writeJSON(w, http.StatusOK, image)
 }
@@ -1,4 +1,4 @@
-package main
+package experiments
 
 import (
 	"context"
@@ -13,6 +13,7 @@ import (
 	"github.com/animus-labs/animus-go/closed/internal/domain"
 	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
 	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
 	"github.com/animus-labs/animus-go/closed/internal/platform/lineageevent"
 	"github.com/animus-labs/animus-go/closed/internal/repo"
 	"github.com/animus-labs/animus-go/closed/internal/repo/postgres"
@@ -212,7 +213,7 @@ func (api *experimentsAPI) appendModelAudit(ctx context.Context, q auditlog.Quer
 	if q == nil {
 		return errors.New("audit appender required")
 	}
-	_, err := auditlog.Insert(ctx, q, event)
+	err := auditlog.Enqueue(ctx, q, event)
 	return err
 }
 
@@ -226,7 +227,7 @@ func (api *experimentsAPI) appendModelLineage(ctx context.Context, q lineageeven
 	if q == nil {
 		return errors.New("lineage appender required")
 	}
-	_, err := lineageevent.Insert(ctx, q, event)
+	err := lineageevent.Enqueue(ctx, q, event)
 	return err
 }
 
@@ -243,8 +244,8 @@ func (api *experimentsAPI) handleCreateModel(w http.ResponseWriter, r *http.Requ
 	}
 
 	var req modelCreateRequest
-	if err := decodeJSON(r, &req); err != nil {
-		api.writeError(w, r, http.StatusBadRequest, "invalid_json")
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
 		return
 	}
 	idempotencyKey := strings.TrimSpace(r.Header.Get("Idempotency-Key"))
@@ -308,7 +309,7 @@ func (api *experimentsAPI) handleCreateModel(w http.ResponseWriter, r *http.Requ
 				ResourceType: "model",
 				ResourceID:   record.ID,
 				RequestID:    r.Header.Get("X-Request-Id"),
-				IP:           requestIP(r.RemoteAddr),
+				IP:           httpapi.RequestIP(r.RemoteAddr),
 				UserAgent:    r.UserAgent(),
 				Payload: map[string]any{
 					"service":    "experiments",
@@ -350,7 +351,7 @@ func (api *experimentsAPI) handleCreateModel(w http.ResponseWriter, r *http.Requ
 			ResourceType: "model",
 			ResourceID:   record.ID,
 			RequestID:    r.Header.Get("X-Request-Id"),
-			IP:           requestIP(r.RemoteAddr),
+			IP:           httpapi.RequestIP(r.RemoteAddr),
 			UserAgent:    r.UserAgent(),
 			Payload: map[string]any{
 				"service":    "experiments",
@@ -378,7 +379,7 @@ func (api *experimentsAPI) handleListModels(w http.ResponseWriter, r *http.Reque
 		return
 	}
 	name := strings.TrimSpace(r.URL.Query().Get("name"))
-	limit := clampInt(parseIntQuery(r, "limit", 100), 1, 500)
+	limit := httpapi.Limit(r, 100, 500)
 
 	store := api.modelStore()
 	if store == nil {
@@ -432,7 +433,7 @@ func (api *experimentsAPI) handleListModelVersions(w http.ResponseWriter, r *htt
 		api.writeError(w, r, http.StatusBadRequest, "model_id_required")
 		return
 	}
-	limit := clampInt(parseIntQuery(r, "limit", 100), 1, 500)
+	limit := httpapi.Limit(r, 100, 500)
 
 	store := api.modelVersionStore()
 	if store == nil {
@@ -464,8 +465,8 @@ func (api *experimentsAPI) handleCreateModelVersion(w http.ResponseWriter, r *ht
 		return
 	}
 	var req modelVersionCreateRequest
-	if err := decodeJSON(r, &req); err != nil {
-		api.writeError(w, r, http.StatusBadRequest, "invalid_json")
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
 		return
 	}
 	idempotencyKey := strings.TrimSpace(r.Header.Get("Idempotency-Key"))
@@ -650,7 +651,7 @@ func (api *experimentsAPI) handleCreateModelVersion(w http.ResponseWriter, r *ht
 				ResourceType: "model_version",
 				ResourceID:   record.ID,
 				RequestID:    r.Header.Get("X-Request-Id"),
-				IP:           requestIP(r.RemoteAddr),
+				IP:           httpapi.RequestIP(r.RemoteAddr),
 				UserAgent:    r.UserAgent(),
 				Payload: map[string]any{
 					"service":       "experiments",
@@ -749,7 +750,7 @@ func (api *experimentsAPI) handleCreateModelVersion(w http.ResponseWriter, r *ht
 			ResourceType: "model_version",
 			ResourceID:   record.ID,
 			RequestID:    r.Header.Get("X-Request-Id"),
-			IP:           requestIP(r.RemoteAddr),
+			IP:           httpapi.RequestIP(r.RemoteAddr),
 			UserAgent:    r.UserAgent(),
 			Payload: map[string]any{
 				"service":       "experiments",
@@ -915,7 +916,7 @@ func (api *experimentsAPI) handleModelVersionTransition(w http.ResponseWriter, r
 			ResourceType: "model_version",
 			ResourceID:   versionID,
 			RequestID:    r.Header.Get("X-Request-Id"),
-			IP:           requestIP(r.RemoteAddr),
+			IP:           httpapi.RequestIP(r.RemoteAddr),
 			UserAgent:    r.UserAgent(),
 			Payload: map[string]any{
 				"service":     "experiments",
@@ -970,7 +971,7 @@ func (api *experimentsAPI) handleModelVersionTransition(w http.ResponseWriter, r
 		ResourceType: "model_version",
 		ResourceID:   versionID,
 		RequestID:    r.Header.Get("X-Request-Id"),
-		IP:           requestIP(r.RemoteAddr),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
 		UserAgent:    r.UserAgent(),
 		Payload: map[string]any{
 			"service":     "experiments",
@@ -1012,8 +1013,8 @@ func (api *experimentsAPI) handleExportModelVersion(w http.ResponseWriter, r *ht
 		return
 	}
 	var req modelExportRequest
-	if err := decodeJSON(r, &req); err != nil && !errors.Is(err, io.EOF) {
-		api.writeError(w, r, http.StatusBadRequest, "invalid_json")
+	if err := httpapi.DecodeJSON(r, &req); err != nil && !errors.Is(err, io.EOF) {
+		httpapi.WriteDecodeError(w, r, err)
 		return
 	}
 	idempotencyKey := strings.TrimSpace(r.Header.Get("Idempotency-Key"))
@@ -1089,7 +1090,7 @@ func (api *experimentsAPI) handleExportModelVersion(w http.ResponseWriter, r *ht
 				ResourceType: "model_export",
 				ResourceID:   record.ExportID,
 				RequestID:    r.Header.Get("X-Request-Id"),
-				IP:           requestIP(r.RemoteAddr),
+				IP:           httpapi.RequestIP(r.RemoteAddr),
 				UserAgent:    r.UserAgent(),
 				Payload: map[string]any{
 					"service":          "experiments",
@@ -1131,7 +1132,7 @@ func (api *experimentsAPI) handleExportModelVersion(w http.ResponseWriter, r *ht
 			ResourceType: "model_export",
 			ResourceID:   record.ExportID,
 			RequestID:    r.Header.Get("X-Request-Id"),
-			IP:           requestIP(r.RemoteAddr),
+			IP:           httpapi.RequestIP(r.RemoteAddr),
 			UserAgent:    r.UserAgent(),
 			Payload: map[string]any{
 				"service":          "experiments",
@@ -1,18 +1,18 @@
-package main
+package experiments
 
 import (
-	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
 	"net/http"
-	"strconv"
 	"strings"
 	"time"
 
 	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
 	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
 	"github.com/animus-labs/animus-go/closed/internal/platform/policy"
+	"github.com/animus-labs/animus-go/closed/internal/repo"
 	"github.com/google/uuid"
 )
 
@@ -89,6 +89,7 @@ type policyDecisionSummary struct {
 	Decision        string    `json:"decision"`
 	RuleID          string    `json:"rule_id,omitempty"`
 	Reason          string    `json:"reason,omitempty"`
+	BundleRevision  string    `json:"bundle_revision,omitempty"`
 	CreatedAt       time.Time `json:"created_at"`
 	CreatedBy       string    `json:"created_by"`
 }
@@ -117,11 +118,21 @@ type policyApprovalSummary struct {
 	PolicyVersionID string     `json:"policy_version_id"`
 	Decision        string     `json:"decision"`
 	RuleID          string     `json:"rule_id,omitempty"`
+	// Workflow is the stage list snapshotted at request time; CurrentStage
+	// indexes it.
+	Workflow     policy.ApprovalWorkflow `json:"workflow"`
+	CurrentStage int                     `json:"current_stage"`
+	StageName    string                  `json:"stage_name"`
+	EscalateAt   *time.Time              `json:"escalate_at,omitempty"`
+	// AssignedTo reserves the next vote in the current stage for one
+	// reviewer (or their active delegate).
+	AssignedTo string `json:"assigned_to,omitempty"`
 }
 
 type policyApprovalDetail struct {
 	policyApprovalSummary
-	DecisionContext json.RawMessage `json:"decision_context,omitempty"`
+	DecisionContext json.RawMessage      `json:"decision_context,omitempty"`
+	Votes           []policyApprovalVote `json:"votes"`
 }
 
 type policyApprovalListResponse struct {
@@ -133,93 +144,16 @@ type policyApprovalActionRequest struct {
 }
 
 func (api *experimentsAPI) handleListPolicies(w http.ResponseWriter, r *http.Request) {
-	limit := clampInt(parseIntQuery(r, "limit", 100), 1, 500)
-
-	rows, err := api.db.QueryContext(
-		r.Context(),
-		`SELECT p.policy_id,
-				p.name,
-				p.description,
-				p.created_at,
-				p.created_by,
-				v.policy_version_id,
-				v.version,
-				v.status,
-				v.spec_sha256,
-				v.created_at,
-				v.created_by
-		 FROM policies p
-		 LEFT JOIN LATERAL (
-			SELECT policy_version_id, version, status, spec_sha256, created_at, created_by
-			FROM policy_versions
-			WHERE policy_id = p.policy_id
-			ORDER BY version DESC
-			LIMIT 1
-		 ) v ON true
-		 ORDER BY p.created_at DESC
-		 LIMIT $1`,
-		limit,
-	)
+	limit := httpapi.Limit(r, 100, 500)
+
+	policies, err := api.policyStore(api.db).ListPolicies(r.Context(), limit)
 	if err != nil {
 		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
 		return
 	}
-	defer rows.Close()
-
-	out := make([]policySummary, 0, limit)
-	for rows.Next() {
-		var (
-			policyID         string
-			name             string
-			desc             sql.NullString
-			createdAt        time.Time
-			createdBy        string
-			versionID        sql.NullString
-			versionNum       sql.NullInt64
-			versionStatus    sql.NullString
-			specSHA          sql.NullString
-			versionCreatedAt sql.NullTime
-			versionCreatedBy sql.NullString
-		)
-		if err := rows.Scan(
-			&policyID,
-			&name,
-			&desc,
-			&createdAt,
-			&createdBy,
-			&versionID,
-			&versionNum,
-			&versionStatus,
-			&specSHA,
-			&versionCreatedAt,
-			&versionCreatedBy,
-		); err != nil {
-			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
-			return
-		}
-
-		summary := policySummary{
-			PolicyID:    policyID,
-			Name:        name,
-			Description: strings.TrimSpace(desc.String),
-			CreatedAt:   createdAt,
-			CreatedBy:   createdBy,
-		}
-		if versionID.Valid && versionNum.Valid && versionStatus.Valid && specSHA.Valid && versionCreatedAt.Valid && versionCreatedBy.Valid {
-			summary.LatestVersion = policyVersionSummary{
-				PolicyVersionID: versionID.String,
-				Version:         int(versionNum.Int64),
-				Status:          strings.TrimSpace(versionStatus.String),
-				SpecSHA256:      strings.TrimSpace(specSHA.String),
-				CreatedAt:       versionCreatedAt.Time.UTC(),
-				CreatedBy:       strings.TrimSpace(versionCreatedBy.String),
-			}
-		}
-		out = append(out, summary)
-	}
-	if err := rows.Err(); err != nil {
-		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
-		return
+	out := make([]policySummary, 0, len(policies))
+	for _, record := range policies {
+		out = append(out, policySummaryFromRecord(record))
 	}
 
 	api.writeJSON(w, http.StatusOK, policyListResponse{Policies: out})
@@ -232,54 +166,9 @@ func (api *experimentsAPI) handleGetPolicy(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	var (
-		name             string
-		desc             sql.NullString
-		createdAt        time.Time
-		createdBy        string
-		versionID        sql.NullString
-		versionNum       sql.NullInt64
-		versionStatus    sql.NullString
-		specSHA          sql.NullString
-		versionCreatedAt sql.NullTime
-		versionCreatedBy sql.NullString
-	)
-	err := api.db.QueryRowContext(
-		r.Context(),
-		`SELECT p.name,
-				p.description,
-				p.created_at,
-				p.created_by,
-				v.policy_version_id,
-				v.version,
-				v.status,
-				v.spec_sha256,
-				v.created_at,
-				v.created_by
-		 FROM policies p
-		 LEFT JOIN LATERAL (
-			SELECT policy_version_id, version, status, spec_sha256, created_at, created_by
-			FROM policy_versions
-			WHERE policy_id = p.policy_id
-			ORDER BY version DESC
-			LIMIT 1
-		 ) v ON true
-		 WHERE p.policy_id = $1`,
-		policyID,
-	).Scan(
-		&name,
-		&desc,
-		&createdAt,
-		&createdBy,
-		&versionID,
-		&versionNum,
-		&versionStatus,
-		&specSHA,
-		&versionCreatedAt,
-		&versionCreatedBy,
-	)
+	record, err := api.policyStore(api.db).GetPolicy(r.Context(), policyID)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
+		if errors.Is(err, repo.ErrNotFound) {
 			api.writeError(w, r, http.StatusNotFound, "not_found")
 			return
 		}
@@ -287,25 +176,28 @@ func (api *experimentsAPI) handleGetPolicy(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	api.writeJSON(w, http.StatusOK, policySummaryFromRecord(record))
+}
+
+func policySummaryFromRecord(record repo.PolicyRecord) policySummary {
 	out := policySummary{
-		PolicyID:    policyID,
-		Name:        name,
-		Description: strings.TrimSpace(desc.String),
-		CreatedAt:   createdAt,
-		CreatedBy:   createdBy,
+		PolicyID:    record.PolicyID,
+		Name:        record.Name,
+		Description: record.Description,
+		CreatedAt:   record.CreatedAt,
+		CreatedBy:   record.CreatedBy,
 	}
-	if versionID.Valid && versionNum.Valid && versionStatus.Valid && specSHA.Valid && versionCreatedAt.Valid && versionCreatedBy.Valid {
+	if latest := record.Latest; latest != nil {
 		out.LatestVersion = policyVersionSummary{
-			PolicyVersionID: versionID.String,
-			Version:         int(versionNum.Int64),
-			Status:          strings.TrimSpace(versionStatus.String),
-			SpecSHA256:      strings.TrimSpace(specSHA.String),
-			CreatedAt:       versionCreatedAt.Time.UTC(),
-			CreatedBy:       strings.TrimSpace(versionCreatedBy.String),
+			PolicyVersionID: latest.PolicyVersionID,
+			Version:         latest.Version,
+			Status:          latest.Status,
+			SpecSHA256:      latest.SpecSHA256,
+			CreatedAt:       latest.CreatedAt,
+			CreatedBy:       latest.CreatedBy,
 		}
 	}
-
-	api.writeJSON(w, http.StatusOK, out)
+	return out
 }
 
 func (api *experimentsAPI) handleCreatePolicy(w http.ResponseWriter, r *http.Request) {
@@ -316,8 +208,8 @@ func (api *experimentsAPI) handleCreatePolicy(w http.ResponseWriter, r *http.Req
 	}
 
 	var req createPolicyRequest
-	if err := decodeJSON(r, &req); err != nil {
-		api.writeError(w, r, http.StatusBadRequest, "invalid_json")
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
 		return
 	}
 
@@ -343,6 +235,10 @@ func (api *experimentsAPI) handleCreatePolicy(w http.ResponseWriter, r *http.Req
 		api.writeError(w, r, http.StatusBadRequest, "invalid_spec")
 		return
 	}
+	if spec.IsOPA() && api.policyEvaluator.OPA == nil {
+		api.writeError(w, r, http.StatusBadRequest, "opa_not_configured")
+		return
+	}
 	specJSON, err := json.Marshal(spec)
 	if err != nil {
 		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
@@ -407,25 +303,17 @@ func (api *experimentsAPI) handleCreatePolicy(w http.ResponseWriter, r *http.Req
 	}
 	defer func() { _ = tx.Rollback() }()
 
-	_, err = tx.ExecContext(
-		r.Context(),
-		`INSERT INTO policies (
-			policy_id,
-			name,
-			description,
-			created_at,
-			created_by,
-			integrity_sha256
-		) VALUES ($1,$2,$3,$4,$5,$6)`,
-		policyID,
-		name,
-		nullString(description),
-		now,
-		identity.Subject,
-		policyIntegrity,
-	)
+	policies := api.policyStore(tx)
+	err = policies.CreatePolicy(r.Context(), repo.PolicyRecord{
+		PolicyID:        policyID,
+		Name:            name,
+		Description:     description,
+		CreatedAt:       now,
+		CreatedBy:       identity.Subject,
+		IntegritySHA256: policyIntegrity,
+	})
 	if err != nil {
-		if isUniqueViolation(err) {
+		if errors.Is(err, repo.ErrConflict) {
 			api.writeError(w, r, http.StatusConflict, "policy_name_exists")
 			return
 		}
@@ -433,44 +321,31 @@ func (api *experimentsAPI) handleCreatePolicy(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	_, err = tx.ExecContext(
-		r.Context(),
-		`INSERT INTO policy_versions (
-			policy_version_id,
-			policy_id,
-			version,
-			status,
-			spec_yaml,
-			spec_json,
-			spec_sha256,
-			created_at,
-			created_by,
-			integrity_sha256
-		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)`,
-		versionID,
-		policyID,
-		1,
-		status,
-		specRaw,
-		specJSON,
-		specSHA,
-		now,
-		identity.Subject,
-		versionIntegrity,
-	)
+	err = policies.CreatePolicyVersion(r.Context(), repo.PolicyVersionRecord{
+		PolicyVersionID: versionID,
+		PolicyID:        policyID,
+		Version:         1,
+		Status:          status,
+		SpecYAML:        specRaw,
+		SpecJSON:        specJSON,
+		SpecSHA256:      specSHA,
+		CreatedAt:       now,
+		CreatedBy:       identity.Subject,
+		IntegritySHA256: versionIntegrity,
+	})
 	if err != nil {
 		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
 		return
 	}
 
-	_, err = auditlog.Insert(r.Context(), tx, auditlog.Event{
+	err = auditlog.Enqueue(r.Context(), tx, auditlog.Event{
 		OccurredAt:   now,
 		Actor:        identity.Subject,
 		Action:       "policy.create",
 		ResourceType: "policy",
 		ResourceID:   policyID,
 		RequestID:    r.Header.Get("X-Request-Id"),
-		IP:           requestIP(r.RemoteAddr),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
 		UserAgent:    r.UserAgent(),
 		Payload: map[string]any{
 			"service":    "experiments",
@@ -485,14 +360,14 @@ func (api *experimentsAPI) handleCreatePolicy(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	_, err = auditlog.Insert(r.Context(), tx, auditlog.Event{
+	err = auditlog.Enqueue(r.Context(), tx, auditlog.Event{
 		OccurredAt:   now,
 		Actor:        identity.Subject,
 		Action:       "policy.version.create",
 		ResourceType: "policy_version",
 		ResourceID:   versionID,
 		RequestID:    r.Header.Get("X-Request-Id"),
-		IP:           requestIP(r.RemoteAddr),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
 		UserAgent:    r.UserAgent(),
 		Payload: map[string]any{
 			"service":     "experiments",
@@ -531,71 +406,37 @@ func (api *experimentsAPI) handleListPolicyVersions(w http.ResponseWriter, r *ht
 		api.writeError(w, r, http.StatusBadRequest, "policy_id_required")
 		return
 	}
-	limit := clampInt(parseIntQuery(r, "limit", 100), 1, 500)
+	limit := httpapi.Limit(r, 100, 500)
 
-	if ok, err := api.policyExists(r.Context(), policyID); err != nil {
+	policies := api.policyStore(api.db)
+	if _, err := policies.GetPolicy(r.Context(), policyID); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return
+		}
 		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
 		return
-	} else if !ok {
-		api.writeError(w, r, http.StatusNotFound, "not_found")
-		return
-	}
-
-	rows, err := api.db.QueryContext(
-		r.Context(),
-		`SELECT policy_version_id,
-				version,
-				status,
-				spec_yaml,
-				spec_json,
-				spec_sha256,
-				created_at,
-				created_by
-		 FROM policy_versions
-		 WHERE policy_id = $1
-		 ORDER BY version DESC
-		 LIMIT $2`,
-		policyID,
-		limit,
-	)
+	}
+
+	versions, err := policies.ListPolicyVersions(r.Context(), policyID, limit)
 	if err != nil {
 		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
 		return
 	}
-	defer rows.Close()
-
-	out := make([]policyVersion, 0, limit)
-	for rows.Next() {
-		var (
-			versionID string
-			version   int
-			status    string
-			specYAML  string
-			specJSON  []byte
-			specSHA   string
-			createdAt time.Time
-			createdBy string
-		)
-		if err := rows.Scan(&versionID, &version, &status, &specYAML, &specJSON, &specSHA, &createdAt, &createdBy); err != nil {
-			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
-			return
-		}
+	out := make([]policyVersion, 0, len(versions))
+	for _, version := range versions {
 		out = append(out, policyVersion{
-			PolicyVersionID: versionID,
+			PolicyVersionID: version.PolicyVersionID,
 			PolicyID:        policyID,
-			Version:         version,
-			Status:          strings.TrimSpace(status),
-			SpecYAML:        specYAML,
-			Spec:            normalizeJSON(specJSON),
-			SpecSHA256:      strings.TrimSpace(specSHA),
-			CreatedAt:       createdAt,
-			CreatedBy:       createdBy,
+			Version:         version.Version,
+			Status:          version.Status,
+			SpecYAML:        version.SpecYAML,
+			Spec:            normalizeJSON(version.SpecJSON),
+			SpecSHA256:      version.SpecSHA256,
+			CreatedAt:       version.CreatedAt,
+			CreatedBy:       version.CreatedBy,
 		})
 	}
-	if err := rows.Err(); err != nil {
-		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
-		return
-	}
 
 	api.writeJSON(w, http.StatusOK, policyVersionListResponse{
 		PolicyID: policyID,
@@ -616,8 +457,8 @@ func (api *experimentsAPI) handleCreatePolicyVersion(w http.ResponseWriter, r *h
 	}
 
 	var req createPolicyVersionRequest
-	if err := decodeJSON(r, &req); err != nil {
-		api.writeError(w, r, http.StatusBadRequest, "invalid_json")
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
 		return
 	}
 	specRaw := strings.TrimSpace(req.Spec)
@@ -636,6 +477,10 @@ func (api *experimentsAPI) handleCreatePolicyVersion(w http.ResponseWriter, r *h
 		api.writeError(w, r, http.StatusBadRequest, "invalid_spec")
 		return
 	}
+	if spec.IsOPA() && api.policyEvaluator.OPA == nil {
+		api.writeError(w, r, http.StatusBadRequest, "opa_not_configured")
+		return
+	}
 	specJSON, err := json.Marshal(spec)
 	if err != nil {
 		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
@@ -650,14 +495,10 @@ func (api *experimentsAPI) handleCreatePolicyVersion(w http.ResponseWriter, r *h
 	}
 	defer func() { _ = tx.Rollback() }()
 
-	var existingName string
-	err = tx.QueryRowContext(
-		r.Context(),
-		`SELECT name FROM policies WHERE policy_id = $1`,
-		policyID,
-	).Scan(&existingName)
+	policies := api.policyStore(tx)
+	existing, err := policies.GetPolicy(r.Context(), policyID)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
+		if errors.Is(err, repo.ErrNotFound) {
 			api.writeError(w, r, http.StatusNotFound, "not_found")
 			return
 		}
@@ -665,17 +506,10 @@ func (api *experimentsAPI) handleCreatePolicyVersion(w http.ResponseWriter, r *h
 		return
 	}
 
-	var maxVersion int
-	err = tx.QueryRowContext(
-		r.Context(),
-		`SELECT COALESCE(MAX(version), 0) FROM policy_versions WHERE policy_id = $1`,
-		policyID,
-	).Scan(&maxVersion)
-	if err != nil {
-		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
-		return
+	version := 1
+	if existing.Latest != nil {
+		version = existing.Latest.Version + 1
 	}
-	version := maxVersion + 1
 	now := time.Now().UTC()
 	versionID := uuid.NewString()
 
@@ -706,49 +540,36 @@ func (api *experimentsAPI) handleCreatePolicyVersion(w http.ResponseWriter, r *h
 		return
 	}
 
-	_, err = tx.ExecContext(
-		r.Context(),
-		`INSERT INTO policy_versions (
-			policy_version_id,
-			policy_id,
-			version,
-			status,
-			spec_yaml,
-			spec_json,
-			spec_sha256,
-			created_at,
-			created_by,
-			integrity_sha256
-		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)`,
-		versionID,
-		policyID,
-		version,
-		status,
-		specRaw,
-		specJSON,
-		specSHA,
-		now,
-		identity.Subject,
-		versionIntegrity,
-	)
+	err = policies.CreatePolicyVersion(r.Context(), repo.PolicyVersionRecord{
+		PolicyVersionID: versionID,
+		PolicyID:        policyID,
+		Version:         version,
+		Status:          status,
+		SpecYAML:        specRaw,
+		SpecJSON:        specJSON,
+		SpecSHA256:      specSHA,
+		CreatedAt:       now,
+		CreatedBy:       identity.Subject,
+		IntegritySHA256: versionIntegrity,
+	})
 	if err != nil {
 		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
 		return
 	}
 
-	_, err = auditlog.Insert(r.Context(), tx, auditlog.Event{
+	err = auditlog.Enqueue(r.Context(), tx, auditlog.Event{
 		OccurredAt:   now,
 		Actor:        identity.Subject,
 		Action:       "policy.version.create",
 		ResourceType: "policy_version",
 		ResourceID:   versionID,
 		RequestID:    r.Header.Get("X-Request-Id"),
-		IP:           requestIP(r.RemoteAddr),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
 		UserAgent:    r.UserAgent(),
 		Payload: map[string]any{
 			"service":     "experiments",
 			"policy_id":   policyID,
-			"name":        existingName,
+			"name":        existing.Name,
 			"version":     version,
 			"status":      status,
 			"spec_sha256": specSHA,
@@ -778,90 +599,20 @@ func (api *experimentsAPI) handleCreatePolicyVersion(w http.ResponseWriter, r *h
 }
 
 func (api *experimentsAPI) handleListPolicyDecisions(w http.ResponseWriter, r *http.Request) {
-	limit := clampInt(parseIntQuery(r, "limit", 100), 1, 500)
+	limit := httpapi.Limit(r, 100, 500)
 	runID := strings.TrimSpace(r.URL.Query().Get("run_id"))
 
-	query := `SELECT d.decision_id,
-			d.run_id,
-			d.policy_id,
-			p.name,
-			d.policy_version_id,
-			d.policy_sha256,
-			d.context_sha256,
-			d.decision,
-			d.rule_id,
-			d.reason,
-			d.created_at,
-			d.created_by
-		FROM policy_decisions d
-		JOIN policies p ON p.policy_id = d.policy_id`
-	args := []any{}
-	if runID != "" {
-		args = append(args, runID)
-		query += " WHERE d.run_id = $" + strconv.Itoa(len(args))
-	}
-	args = append(args, limit)
-	query += " ORDER BY d.created_at DESC LIMIT $" + strconv.Itoa(len(args))
-
-	rows, err := api.db.QueryContext(r.Context(), query, args...)
+	decisions, err := api.policyStore(api.db).ListPolicyDecisions(r.Context(), repo.PolicyDecisionFilter{
+		RunID: runID,
+		Limit: limit,
+	})
 	if err != nil {
 		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
 		return
 	}
-	defer rows.Close()
-
-	out := make([]policyDecisionSummary, 0, limit)
-	for rows.Next() {
-		var (
-			decisionID string
-			runIDVal   sql.NullString
-			policyID   string
-			policyName string
-			versionID  string
-			policySHA  string
-			contextSHA string
-			decision   string
-			ruleID     sql.NullString
-			reason     sql.NullString
-			createdAt  time.Time
-			createdBy  string
-		)
-		if err := rows.Scan(
-			&decisionID,
-			&runIDVal,
-			&policyID,
-			&policyName,
-			&versionID,
-			&policySHA,
-			&contextSHA,
-			&decision,
-			&ruleID,
-			&reason,
-			&createdAt,
-			&createdBy,
-		); err != nil {
-			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
-			return
-		}
-
-		out = append(out, policyDecisionSummary{
-			DecisionID:      decisionID,
-			RunID:           strings.TrimSpace(runIDVal.String),
-			PolicyID:        policyID,
-			PolicyName:      policyName,
-			PolicyVersionID: versionID,
-			PolicySHA256:    policySHA,
-			ContextSHA256:   contextSHA,
-			Decision:        decision,
-			RuleID:          strings.TrimSpace(ruleID.String),
-			Reason:          strings.TrimSpace(reason.String),
-			CreatedAt:       createdAt,
-			CreatedBy:       createdBy,
-		})
-	}
-	if err := rows.Err(); err != nil {
-		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
-		return
+	out := make([]policyDecisionSummary, 0, len(decisions))
+	for _, record := range decisions {
+		out = append(out, policyDecisionSummaryFromRecord(record))
 	}
 
 	api.writeJSON(w, http.StatusOK, policyDecisionListResponse{Decisions: out})
@@ -874,54 +625,9 @@ func (api *experimentsAPI) handleGetPolicyDecision(w http.ResponseWriter, r *htt
 		return
 	}
 
-	var (
-		runIDVal   sql.NullString
-		policyID   string
-		policyName string
-		versionID  string
-		policySHA  string
-		contextSHA string
-		contextRaw []byte
-		decision   string
-		ruleID     sql.NullString
-		reason     sql.NullString
-		createdAt  time.Time
-		createdBy  string
-	)
-	err := api.db.QueryRowContext(
-		r.Context(),
-		`SELECT d.run_id,
-				d.policy_id,
-				p.name,
-				d.policy_version_id,
-				d.policy_sha256,
-				d.context_sha256,
-				d.context,
-				d.decision,
-				d.rule_id,
-				d.reason,
-				d.created_at,
-				d.created_by
-		 FROM policy_decisions d
-		 JOIN policies p ON p.policy_id = d.policy_id
-		 WHERE d.decision_id = $1`,
-		decisionID,
-	).Scan(
-		&runIDVal,
-		&policyID,
-		&policyName,
-		&versionID,
-		&policySHA,
-		&contextSHA,
-		&contextRaw,
-		&decision,
-		&ruleID,
-		&reason,
-		&createdAt,
-		&createdBy,
-	)
+	record, err := api.policyStore(api.db).GetPolicyDecision(r.Context(), decisionID)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
+		if errors.Is(err, repo.ErrNotFound) {
 			api.writeError(w, r, http.StatusNotFound, "not_found")
 			return
 		}
@@ -930,26 +636,31 @@ func (api *experimentsAPI) handleGetPolicyDecision(w http.ResponseWriter, r *htt
 	}
 
 	api.writeJSON(w, http.StatusOK, policyDecisionDetail{
-		policyDecisionSummary: policyDecisionSummary{
-			DecisionID:      decisionID,
-			RunID:           strings.TrimSpace(runIDVal.String),
-			PolicyID:        policyID,
-			PolicyName:      policyName,
-			PolicyVersionID: versionID,
-			PolicySHA256:    policySHA,
-			ContextSHA256:   contextSHA,
-			Decision:        decision,
-			RuleID:          strings.TrimSpace(ruleID.String),
-			Reason:          strings.TrimSpace(reason.String),
-			CreatedAt:       createdAt,
-			CreatedBy:       createdBy,
-		},
-		Context: normalizeJSON(contextRaw),
+		policyDecisionSummary: policyDecisionSummaryFromRecord(record),
+		Context:               normalizeJSON(record.Context),
 	})
 }
 
+func policyDecisionSummaryFromRecord(record repo.PolicyDecisionRecord) policyDecisionSummary {
+	return policyDecisionSummary{
+		DecisionID:      record.DecisionID,
+		RunID:           record.RunID,
+		PolicyID:        record.PolicyID,
+		PolicyName:      record.PolicyName,
+		PolicyVersionID: record.PolicyVersionID,
+		PolicySHA256:    record.PolicySHA256,
+		ContextSHA256:   record.ContextSHA256,
+		Decision:        record.Decision,
+		RuleID:          record.RuleID,
+		Reason:          record.Reason,
+		BundleRevision:  record.BundleRevision,
+		CreatedAt:       record.CreatedAt,
+		CreatedBy:       record.CreatedBy,
+	}
+}
+
 func (api *experimentsAPI) handleListPolicyApprovals(w http.ResponseWriter, r *http.Request) {
-	limit := clampInt(parseIntQuery(r, "limit", 100), 1, 500)
+	limit := httpapi.Limit(r, 100, 500)
 	statusFilter := strings.TrimSpace(r.URL.Query().Get("status"))
 	runID := strings.TrimSpace(r.URL.Query().Get("run_id"))
 	if statusFilter != "" {
@@ -959,110 +670,18 @@ func (api *experimentsAPI) handleListPolicyApprovals(w http.ResponseWriter, r *h
 		}
 	}
 
-	query := `SELECT a.approval_id,
-			a.decision_id,
-			a.run_id,
-			a.status,
-			a.requested_at,
-			a.requested_by,
-			a.decided_at,
-			a.decided_by,
-			a.reason,
-			d.policy_id,
-			p.name,
-			d.policy_version_id,
-			d.decision,
-			d.rule_id
-		FROM policy_approvals a
-		JOIN policy_decisions d ON d.decision_id = a.decision_id
-		JOIN policies p ON p.policy_id = d.policy_id`
-	args := []any{}
-	clauses := []string{}
-	if statusFilter != "" {
-		args = append(args, strings.ToLower(statusFilter))
-		clauses = append(clauses, "a.status = $"+strconv.Itoa(len(args)))
-	}
-	if runID != "" {
-		args = append(args, runID)
-		clauses = append(clauses, "a.run_id = $"+strconv.Itoa(len(args)))
-	}
-	if len(clauses) > 0 {
-		query += " WHERE " + strings.Join(clauses, " AND ")
-	}
-	args = append(args, limit)
-	query += " ORDER BY a.requested_at DESC LIMIT $" + strconv.Itoa(len(args))
-
-	rows, err := api.db.QueryContext(r.Context(), query, args...)
+	records, err := api.policyStore(api.db).ListPolicyApprovals(r.Context(), repo.PolicyApprovalFilter{
+		Status: statusFilter,
+		RunID:  runID,
+		Limit:  limit,
+	})
 	if err != nil {
 		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
 		return
 	}
-	defer rows.Close()
-
-	out := make([]policyApprovalSummary, 0, limit)
-	for rows.Next() {
-		var (
-			approvalID  string
-			decisionID  string
-			runIDVal    sql.NullString
-			status      string
-			requestedAt time.Time
-			requestedBy string
-			decidedAt   sql.NullTime
-			decidedBy   sql.NullString
-			reason      sql.NullString
-			policyID    string
-			policyName  string
-			versionID   string
-			decision    string
-			ruleID      sql.NullString
-		)
-		if err := rows.Scan(
-			&approvalID,
-			&decisionID,
-			&runIDVal,
-			&status,
-			&requestedAt,
-			&requestedBy,
-			&decidedAt,
-			&decidedBy,
-			&reason,
-			&policyID,
-			&policyName,
-			&versionID,
-			&decision,
-			&ruleID,
-		); err != nil {
-			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
-			return
-		}
-
-		var decidedAtPtr *time.Time
-		if decidedAt.Valid && !decidedAt.Time.IsZero() {
-			t := decidedAt.Time.UTC()
-			decidedAtPtr = &t
-		}
-
-		out = append(out, policyApprovalSummary{
-			ApprovalID:      approvalID,
-			DecisionID:      decisionID,
-			RunID:           strings.TrimSpace(runIDVal.String),
-			Status:          strings.TrimSpace(status),
-			RequestedAt:     requestedAt,
-			RequestedBy:     requestedBy,
-			DecidedAt:       decidedAtPtr,
-			DecidedBy:       strings.TrimSpace(decidedBy.String),
-			Reason:          strings.TrimSpace(reason.String),
-			PolicyID:        policyID,
-			PolicyName:      policyName,
-			PolicyVersionID: versionID,
-			Decision:        decision,
-			RuleID:          strings.TrimSpace(ruleID.String),
-		})
-	}
-	if err := rows.Err(); err != nil {
-		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
-		return
+	out := make([]policyApprovalSummary, 0, len(records))
+	for _, record := range records {
+		out = append(out, policyApprovalSummaryFromRecord(record))
 	}
 
 	api.writeJSON(w, http.StatusOK, policyApprovalListResponse{Approvals: out})
@@ -1075,92 +694,46 @@ func (api *experimentsAPI) handleGetPolicyApproval(w http.ResponseWriter, r *htt
 		return
 	}
 
-	var (
-		decisionID  string
-		runIDVal    sql.NullString
-		status      string
-		requestedAt time.Time
-		requestedBy string
-		decidedAt   sql.NullTime
-		decidedBy   sql.NullString
-		reason      sql.NullString
-		policyID    string
-		policyName  string
-		versionID   string
-		decision    string
-		ruleID      sql.NullString
-		contextRaw  []byte
-	)
-	err := api.db.QueryRowContext(
-		r.Context(),
-		`SELECT a.decision_id,
-				a.run_id,
-				a.status,
-				a.requested_at,
-				a.requested_by,
-				a.decided_at,
-				a.decided_by,
-				a.reason,
-				d.policy_id,
-				p.name,
-				d.policy_version_id,
-				d.decision,
-				d.rule_id,
-				d.context
-		 FROM policy_approvals a
-		 JOIN policy_decisions d ON d.decision_id = a.decision_id
-		 JOIN policies p ON p.policy_id = d.policy_id
-		 WHERE a.approval_id = $1`,
-		approvalID,
-	).Scan(
-		&decisionID,
-		&runIDVal,
-		&status,
-		&requestedAt,
-		&requestedBy,
-		&decidedAt,
-		&decidedBy,
-		&reason,
-		&policyID,
-		&policyName,
-		&versionID,
-		&decision,
-		&ruleID,
-		&contextRaw,
-	)
+	store := api.policyStore(api.db)
+	record, err := store.GetPolicyApproval(r.Context(), approvalID)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
+		if errors.Is(err, repo.ErrNotFound) {
 			api.writeError(w, r, http.StatusNotFound, "not_found")
 			return
 		}
 		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
 		return
 	}
-
-	var decidedAtPtr *time.Time
-	if decidedAt.Valid && !decidedAt.Time.IsZero() {
-		t := decidedAt.Time.UTC()
-		decidedAtPtr = &t
+	votes, err := fetchPolicyApprovalVotes(r.Context(), store, approvalID)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
 	}
 
 	api.writeJSON(w, http.StatusOK, policyApprovalDetail{
-		policyApprovalSummary: policyApprovalSummary{
-			ApprovalID:      approvalID,
-			DecisionID:      decisionID,
-			RunID:           strings.TrimSpace(runIDVal.String),
-			Status:          strings.TrimSpace(status),
-			RequestedAt:     requestedAt,
-			RequestedBy:     requestedBy,
-			DecidedAt:       decidedAtPtr,
-			DecidedBy:       strings.TrimSpace(decidedBy.String),
-			Reason:          strings.TrimSpace(reason.String),
-			PolicyID:        policyID,
-			PolicyName:      policyName,
-			PolicyVersionID: versionID,
-			Decision:        decision,
-			RuleID:          strings.TrimSpace(ruleID.String),
-		},
-		DecisionContext: normalizeJSON(contextRaw),
+		policyApprovalSummary: policyApprovalSummaryFromRecord(record),
+		DecisionContext:       normalizeJSON(record.Context),
+		Votes:                 votes,
+	})
+}
+
+func policyApprovalSummaryFromRecord(record repo.PolicyApprovalRecord) policyApprovalSummary {
+	return newPolicyApprovalSummary(record.Workflow, record.CurrentStage, record.EscalateAt, policyApprovalSummary{
+		ApprovalID:      record.ApprovalID,
+		DecisionID:      record.DecisionID,
+		RunID:           record.RunID,
+		Status:          record.Status,
+		RequestedAt:     record.RequestedAt,
+		RequestedBy:     record.RequestedBy,
+		DecidedAt:       record.DecidedAt,
+		DecidedBy:       record.DecidedBy,
+		Reason:          record.Reason,
+		PolicyID:        record.PolicyID,
+		PolicyName:      record.PolicyName,
+		PolicyVersionID: record.PolicyVersionID,
+		Decision:        record.Decision,
+		RuleID:          record.RuleID,
+		AssignedTo:      record.AssignedTo,
 	})
 }
 
@@ -1170,10 +743,6 @@ func (api *experimentsAPI) handleApprovePolicyApproval(w http.ResponseWriter, r
 		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
 		return
 	}
-	if !auth.HasAtLeast(identity.Roles, auth.RoleAdmin) {
-		api.writeError(w, r, http.StatusForbidden, "approval_requires_admin")
-		return
-	}
 
 	approvalID := strings.TrimSpace(r.PathValue("approval_id"))
 	if approvalID == "" {
@@ -1182,8 +751,8 @@ func (api *experimentsAPI) handleApprovePolicyApproval(w http.ResponseWriter, r
 	}
 
 	var req policyApprovalActionRequest
-	if err := decodeJSON(r, &req); err != nil {
-		api.writeError(w, r, http.StatusBadRequest, "invalid_json")
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
 		return
 	}
 	reason := strings.TrimSpace(req.Reason)
@@ -1195,112 +764,102 @@ func (api *experimentsAPI) handleApprovePolicyApproval(w http.ResponseWriter, r
 	}
 	defer func() { _ = tx.Rollback() }()
 
-	var (
-		decisionID  string
-		runID       string
-		status      string
-		requestedAt time.Time
-		requestedBy string
-	)
-	err = tx.QueryRowContext(
-		r.Context(),
-		`SELECT decision_id, run_id, status, requested_at, requested_by
-		 FROM policy_approvals
-		 WHERE approval_id = $1
-		 FOR UPDATE`,
-		approvalID,
-	).Scan(&decisionID, &runID, &status, &requestedAt, &requestedBy)
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			api.writeError(w, r, http.StatusNotFound, "not_found")
-			return
-		}
-		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
-		return
-	}
-	if strings.TrimSpace(status) != approvalStatusPending {
-		api.writeError(w, r, http.StatusConflict, "approval_not_pending")
-		return
-	}
-	if requestedBy == identity.Subject {
-		api.writeError(w, r, http.StatusForbidden, "approval_requires_second_reviewer")
-		return
-	}
-	runID = strings.TrimSpace(runID)
-	if runID == "" {
-		api.writeError(w, r, http.StatusBadRequest, "run_id_required")
+	state, ok := api.lockPendingApproval(w, r, tx, approvalID, identity)
+	if !ok {
 		return
 	}
-	runID = strings.TrimSpace(runID)
+	runID := state.RunID
 	if runID == "" {
 		api.writeError(w, r, http.StatusBadRequest, "run_id_required")
 		return
 	}
 
 	decidedAt := time.Now().UTC()
-	type approvalIntegrityInput struct {
-		ApprovalID  string     `json:"approval_id"`
-		DecisionID  string     `json:"decision_id"`
-		RunID       string     `json:"run_id"`
-		Status      string     `json:"status"`
-		RequestedAt time.Time  `json:"requested_at"`
-		RequestedBy string     `json:"requested_by"`
-		DecidedAt   *time.Time `json:"decided_at,omitempty"`
-		DecidedBy   string     `json:"decided_by,omitempty"`
-		Reason      string     `json:"reason,omitempty"`
-	}
-	approvalIntegrity, err := integritySHA256(approvalIntegrityInput{
-		ApprovalID:  approvalID,
-		DecisionID:  decisionID,
-		RunID:       runID,
-		Status:      approvalStatusApproved,
-		RequestedAt: requestedAt,
-		RequestedBy: requestedBy,
-		DecidedAt:   &decidedAt,
-		DecidedBy:   identity.Subject,
-		Reason:      reason,
-	})
+	if err := recordApprovalVote(r.Context(), tx, state, identity.Subject, approvalVoteApprove, reason, decidedAt); err != nil {
+		api.writeApprovalVoteError(w, r, err)
+		return
+	}
+	stage := state.stage()
+	approvals, err := countStageApprovals(r.Context(), tx, approvalID, state.CurrentStage)
 	if err != nil {
 		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
 		return
 	}
+	if approvals < stage.Approvers() || !state.lastStage() {
+		action := "policy.approval.vote"
+		stageApprovals := approvals
+		if approvals >= stage.Approvers() {
+			action = "policy.approval.stage_completed"
+			stageApprovals = 0
+			if err := advanceApprovalStage(r.Context(), tx, &state, decidedAt); err != nil {
+				api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+				return
+			}
+		}
+		err = auditlog.Enqueue(r.Context(), tx, auditlog.Event{
+			OccurredAt:   decidedAt,
+			Actor:        identity.Subject,
+			Action:       action,
+			ResourceType: "policy_approval",
+			ResourceID:   approvalID,
+			RequestID:    r.Header.Get("X-Request-Id"),
+			IP:           httpapi.RequestIP(r.RemoteAddr),
+			UserAgent:    r.UserAgent(),
+			Payload: map[string]any{
+				"service":      "experiments",
+				"approval_id":  approvalID,
+				"decision_id":  state.DecisionID,
+				"run_id":       runID,
+				"stage":        stage.Name,
+				"approvals":    approvals,
+				"required":     stage.Approvers(),
+				"on_behalf_of": state.OnBehalfOf,
+				"reason":       reason,
+			},
+		})
+		if err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "audit_failed")
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+		api.writeJSON(w, http.StatusOK, map[string]any{
+			"approval_id":     approvalID,
+			"approval_status": approvalStatusPending,
+			"run_id":          runID,
+			"run_status":      "pending",
+			"stage":           state.stage().Name,
+			"stage_index":     state.CurrentStage,
+			"stage_approvals": stageApprovals,
+			"stage_required":  state.stage().Approvers(),
+		})
+		return
+	}
 
-	_, err = tx.ExecContext(
-		r.Context(),
-		`UPDATE policy_approvals
-		 SET status = $1,
-			 decided_at = $2,
-			 decided_by = $3,
-			 reason = $4,
-			 integrity_sha256 = $5
-		 WHERE approval_id = $6`,
-		approvalStatusApproved,
-		decidedAt,
-		identity.Subject,
-		nullString(reason),
-		approvalIntegrity,
-		approvalID,
-	)
-	if err != nil {
+	if err := finishPolicyApproval(r.Context(), tx, state, approvalStatusApproved, identity.Subject, reason, decidedAt); err != nil {
 		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
 		return
 	}
 
-	_, err = auditlog.Insert(r.Context(), tx, auditlog.Event{
+	err = auditlog.Enqueue(r.Context(), tx, auditlog.Event{
 		OccurredAt:   decidedAt,
 		Actor:        identity.Subject,
 		Action:       "policy.approval.approved",
 		ResourceType: "policy_approval",
 		ResourceID:   approvalID,
 		RequestID:    r.Header.Get("X-Request-Id"),
-		IP:           requestIP(r.RemoteAddr),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
 		UserAgent:    r.UserAgent(),
 		Payload: map[string]any{
-			"service":     "experiments",
-			"approval_id": approvalID,
-			"decision_id": decisionID,
-			"run_id":      runID,
-			"reason":      reason,
+			"service":      "experiments",
+			"approval_id":  approvalID,
+			"decision_id":  state.DecisionID,
+			"run_id":       runID,
+			"stage":        stage.Name,
+			"on_behalf_of": state.OnBehalfOf,
+			"reason":       reason,
 		},
 	})
 	if err != nil {
@@ -1308,15 +867,8 @@ func (api *experimentsAPI) handleApprovePolicyApproval(w http.ResponseWriter, r
 		return
 	}
 
-	var deniedCount int
-	err = tx.QueryRowContext(
-		r.Context(),
-		`SELECT COUNT(1)
-		 FROM policy_approvals
-		 WHERE run_id = $1 AND status = $2`,
-		runID,
-		approvalStatusDenied,
-	).Scan(&deniedCount)
+	store := api.policyStore(tx)
+	deniedCount, err := store.CountRunApprovals(r.Context(), runID, approvalStatusDenied)
 	if err != nil {
 		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
 		return
@@ -1330,15 +882,7 @@ func (api *experimentsAPI) handleApprovePolicyApproval(w http.ResponseWriter, r
 		return
 	}
 
-	var pendingCount int
-	err = tx.QueryRowContext(
-		r.Context(),
-		`SELECT COUNT(1)
-		 FROM policy_approvals
-		 WHERE run_id = $1 AND status = $2`,
-		runID,
-		approvalStatusPending,
-	).Scan(&pendingCount)
+	pendingCount, err := store.CountRunApprovals(r.Context(), runID, approvalStatusPending)
 	if err != nil {
 		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
 		return
@@ -1367,14 +911,14 @@ func (api *experimentsAPI) handleApprovePolicyApproval(w http.ResponseWriter, r
 		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
 		return
 	}
-	_, err = auditlog.Insert(r.Context(), tx, auditlog.Event{
+	err = auditlog.Enqueue(r.Context(), tx, auditlog.Event{
 		OccurredAt:   now,
 		Actor:        identity.Subject,
 		Action:       "experiment_run.approved",
 		ResourceType: "experiment_run",
 		ResourceID:   runID,
 		RequestID:    r.Header.Get("X-Request-Id"),
-		IP:           requestIP(r.RemoteAddr),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
 		UserAgent:    r.UserAgent(),
 		Payload: map[string]any{
 			"service":           "experiments",
@@ -1407,10 +951,6 @@ func (api *experimentsAPI) handleDenyPolicyApproval(w http.ResponseWriter, r *ht
 		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
 		return
 	}
-	if !auth.HasAtLeast(identity.Roles, auth.RoleAdmin) {
-		api.writeError(w, r, http.StatusForbidden, "approval_requires_admin")
-		return
-	}
 
 	approvalID := strings.TrimSpace(r.PathValue("approval_id"))
 	if approvalID == "" {
@@ -1419,8 +959,8 @@ func (api *experimentsAPI) handleDenyPolicyApproval(w http.ResponseWriter, r *ht
 	}
 
 	var req policyApprovalActionRequest
-	if err := decodeJSON(r, &req); err != nil {
-		api.writeError(w, r, http.StatusBadRequest, "invalid_json")
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
 		return
 	}
 	reason := strings.TrimSpace(req.Reason)
@@ -1432,122 +972,39 @@ func (api *experimentsAPI) handleDenyPolicyApproval(w http.ResponseWriter, r *ht
 	}
 	defer func() { _ = tx.Rollback() }()
 
-	var (
-		decisionID  string
-		runID       string
-		status      string
-		requestedAt time.Time
-		requestedBy string
-	)
-	err = tx.QueryRowContext(
-		r.Context(),
-		`SELECT decision_id, run_id, status, requested_at, requested_by
-		 FROM policy_approvals
-		 WHERE approval_id = $1
-		 FOR UPDATE`,
-		approvalID,
-	).Scan(&decisionID, &runID, &status, &requestedAt, &requestedBy)
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			api.writeError(w, r, http.StatusNotFound, "not_found")
-			return
-		}
-		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
-		return
-	}
-	if strings.TrimSpace(status) != approvalStatusPending {
-		api.writeError(w, r, http.StatusConflict, "approval_not_pending")
-		return
-	}
-	if requestedBy == identity.Subject {
-		api.writeError(w, r, http.StatusForbidden, "approval_requires_second_reviewer")
+	state, ok := api.lockPendingApproval(w, r, tx, approvalID, identity)
+	if !ok {
 		return
 	}
+	runID := state.RunID
 
 	decidedAt := time.Now().UTC()
-	type approvalIntegrityInput struct {
-		ApprovalID  string     `json:"approval_id"`
-		DecisionID  string     `json:"decision_id"`
-		RunID       string     `json:"run_id"`
-		Status      string     `json:"status"`
-		RequestedAt time.Time  `json:"requested_at"`
-		RequestedBy string     `json:"requested_by"`
-		DecidedAt   *time.Time `json:"decided_at,omitempty"`
-		DecidedBy   string     `json:"decided_by,omitempty"`
-		Reason      string     `json:"reason,omitempty"`
-	}
-	approvalIntegrity, err := integritySHA256(approvalIntegrityInput{
-		ApprovalID:  approvalID,
-		DecisionID:  decisionID,
-		RunID:       runID,
-		Status:      approvalStatusDenied,
-		RequestedAt: requestedAt,
-		RequestedBy: requestedBy,
-		DecidedAt:   &decidedAt,
-		DecidedBy:   identity.Subject,
-		Reason:      reason,
-	})
-	if err != nil {
-		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
-		return
-	}
-
-	_, err = tx.ExecContext(
-		r.Context(),
-		`UPDATE policy_approvals
-		 SET status = $1,
-			 decided_at = $2,
-			 decided_by = $3,
-			 reason = $4,
-			 integrity_sha256 = $5
-		 WHERE approval_id = $6`,
-		approvalStatusDenied,
-		decidedAt,
-		identity.Subject,
-		nullString(reason),
-		approvalIntegrity,
-		approvalID,
-	)
-	if err != nil {
-		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
-		return
-	}
-
-	_, err = api.insertRunStateEvent(r.Context(), tx, runID, "canceled", decidedAt, map[string]any{
-		"reason":      "policy_denied",
-		"approval_id": approvalID,
-		"decision_id": decisionID,
-		"approved_by": identity.Subject,
-		"deny_reason": reason,
-	})
-	if err != nil {
-		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+	if err := recordApprovalVote(r.Context(), tx, state, identity.Subject, approvalVoteDeny, reason, decidedAt); err != nil {
+		api.writeApprovalVoteError(w, r, err)
 		return
 	}
-	if err := api.insertRunEvent(r.Context(), tx, runID, identity.Subject, "info", "approval denied", map[string]any{
-		"approval_id": approvalID,
-		"decision_id": decisionID,
-		"reason":      reason,
-	}); err != nil {
+	if err := api.denyPolicyApproval(r.Context(), tx, state, identity.Subject, reason, decidedAt); err != nil {
 		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
 		return
 	}
 
-	_, err = auditlog.Insert(r.Context(), tx, auditlog.Event{
+	err = auditlog.Enqueue(r.Context(), tx, auditlog.Event{
 		OccurredAt:   decidedAt,
 		Actor:        identity.Subject,
 		Action:       "policy.approval.denied",
 		ResourceType: "policy_approval",
 		ResourceID:   approvalID,
 		RequestID:    r.Header.Get("X-Request-Id"),
-		IP:           requestIP(r.RemoteAddr),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
 		UserAgent:    r.UserAgent(),
 		Payload: map[string]any{
-			"service":     "experiments",
-			"approval_id": approvalID,
-			"decision_id": decisionID,
-			"run_id":      runID,
-			"reason":      reason,
+			"service":      "experiments",
+			"approval_id":  approvalID,
+			"decision_id":  state.DecisionID,
+			"run_id":       runID,
+			"stage":        state.stage().Name,
+			"on_behalf_of": state.OnBehalfOf,
+			"reason":       reason,
 		},
 	})
 	if err != nil {
@@ -1568,20 +1025,78 @@ func (api *experimentsAPI) handleDenyPolicyApproval(w http.ResponseWriter, r *ht
 	})
 }
 
-func (api *experimentsAPI) policyExists(ctx context.Context, policyID string) (bool, error) {
-	var one int
-	err := api.db.QueryRowContext(
-		ctx,
-		`SELECT 1 FROM policies WHERE policy_id = $1`,
-		policyID,
-	).Scan(&one)
+// lockPendingApproval locks the approval and checks that identity may vote in
+// its current stage, writing the error response otherwise. When the approval
+// is assigned, only the assignee or their active delegate may vote.
+func (api *experimentsAPI) lockPendingApproval(w http.ResponseWriter, r *http.Request, tx *sql.Tx, approvalID string, identity auth.Identity) (policyApprovalState, bool) {
+	state, err := lockPolicyApproval(r.Context(), tx, approvalID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return false, nil
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return policyApprovalState{}, false
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return policyApprovalState{}, false
+	}
+	if state.Status != approvalStatusPending {
+		api.writeError(w, r, http.StatusConflict, "approval_not_pending")
+		return policyApprovalState{}, false
+	}
+	if code := approvalVoterError(state, identity); code != "" {
+		if code == "approval_requires_second_reviewer" {
+			api.auditSelfApprovalAttempt(r, state, identity)
 		}
-		return false, err
+		api.writeError(w, r, http.StatusForbidden, code)
+		return policyApprovalState{}, false
+	}
+	delegate := ""
+	if state.AssignedTo != "" && state.AssignedTo != identity.Subject {
+		delegate, err = activeApprovalDelegate(r.Context(), tx, state.AssignedTo, time.Now().UTC())
+		if err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return policyApprovalState{}, false
+		}
+	}
+	onBehalfOf, ok := approvalActingFor(state, identity.Subject, delegate)
+	if !ok {
+		api.writeError(w, r, http.StatusForbidden, "approval_assigned_elsewhere")
+		return policyApprovalState{}, false
+	}
+	state.OnBehalfOf = onBehalfOf
+	return state, true
+}
+
+// auditSelfApprovalAttempt records a requester voting on their own approval.
+// It is written outside the vote transaction, which is rolled back, so the
+// audit service's self_approval_attempt rule sees it.
+func (api *experimentsAPI) auditSelfApprovalAttempt(r *http.Request, state policyApprovalState, identity auth.Identity) {
+	projectID, _ := auth.ProjectIDFromContext(r.Context())
+	_ = auditlog.Enqueue(r.Context(), api.db, auditlog.Event{
+		OccurredAt:   time.Now().UTC(),
+		Actor:        identity.Subject,
+		Action:       "policy.approval.self_approval_denied",
+		ResourceType: "policy_approval",
+		ResourceID:   state.ApprovalID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload: map[string]any{
+			"service":      "experiments",
+			"project_id":   strings.TrimSpace(projectID),
+			"approval_id":  state.ApprovalID,
+			"decision_id":  state.DecisionID,
+			"run_id":       state.RunID,
+			"requested_by": state.RequestedBy,
+		},
+	})
+}
+
+func (api *experimentsAPI) writeApprovalVoteError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, errApprovalAlreadyVoted) {
+		api.writeError(w, r, http.StatusConflict, "approval_already_voted")
+		return
 	}
-	return true, nil
+	api.writeError(w, r, http.StatusInternalServerError, "internal_error")
 }
 
 func normalizePolicyStatus(value string) (string, bool) {
@@ -0,0 +1,572 @@
+package experiments
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	"github.com/google/uuid"
+)
+
+const maxApprovalDelegationWindow = 90 * 24 * time.Hour
+
+type policyApprovalReassignRequest struct {
+	Assignee string `json:"assignee"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+type policyApprovalDelegationRequest struct {
+	// Delegator defaults to the caller; only admins delegate for others.
+	Delegator string     `json:"delegator,omitempty"`
+	Delegate  string     `json:"delegate"`
+	StartsAt  *time.Time `json:"starts_at,omitempty"`
+	EndsAt    *time.Time `json:"ends_at"`
+	Reason    string     `json:"reason,omitempty"`
+}
+
+// policyApprovalDelegation lets Delegate vote on approvals assigned to
+// Delegator while the window is open. Delegation is one hop: a delegate's
+// own delegation does not carry the assignment further.
+type policyApprovalDelegation struct {
+	DelegationID string     `json:"delegation_id"`
+	Delegator    string     `json:"delegator"`
+	Delegate     string     `json:"delegate"`
+	StartsAt     time.Time  `json:"starts_at"`
+	EndsAt       time.Time  `json:"ends_at"`
+	Reason       string     `json:"reason,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	CreatedBy    string     `json:"created_by"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+	RevokedBy    string     `json:"revoked_by,omitempty"`
+	Active       bool       `json:"active"`
+}
+
+type policyApprovalDelegationListResponse struct {
+	Delegations []policyApprovalDelegation `json:"delegations"`
+}
+
+func (d policyApprovalDelegation) activeAt(now time.Time) bool {
+	return d.RevokedAt == nil && !now.Before(d.StartsAt) && now.Before(d.EndsAt)
+}
+
+// delegation validates the request for identity and returns the delegation
+// to store, or the error code.
+func (req policyApprovalDelegationRequest) delegation(identity auth.Identity, now time.Time) (policyApprovalDelegation, string) {
+	out := policyApprovalDelegation{
+		Delegator: strings.TrimSpace(req.Delegator),
+		Delegate:  strings.TrimSpace(req.Delegate),
+		StartsAt:  now,
+		Reason:    strings.TrimSpace(req.Reason),
+		CreatedAt: now,
+		CreatedBy: identity.Subject,
+	}
+	if out.Delegator == "" {
+		out.Delegator = identity.Subject
+	}
+	if out.Delegate == "" {
+		return policyApprovalDelegation{}, "delegate_required"
+	}
+	if out.Delegate == out.Delegator {
+		return policyApprovalDelegation{}, "delegate_is_delegator"
+	}
+	if req.StartsAt != nil && !req.StartsAt.IsZero() {
+		out.StartsAt = req.StartsAt.UTC()
+	}
+	if req.EndsAt == nil || req.EndsAt.IsZero() {
+		return policyApprovalDelegation{}, "ends_at_required"
+	}
+	out.EndsAt = req.EndsAt.UTC()
+	if !out.EndsAt.After(out.StartsAt) || !out.EndsAt.After(now) {
+		return policyApprovalDelegation{}, "invalid_delegation_window"
+	}
+	if out.EndsAt.Sub(out.StartsAt) > maxApprovalDelegationWindow {
+		return policyApprovalDelegation{}, "delegation_window_too_long"
+	}
+	return out, ""
+}
+
+func approvalDelegationIntegrity(d policyApprovalDelegation) (string, error) {
+	type integrityInput struct {
+		DelegationID string     `json:"delegation_id"`
+		Delegator    string     `json:"delegator"`
+		Delegate     string     `json:"delegate"`
+		StartsAt     time.Time  `json:"starts_at"`
+		EndsAt       time.Time  `json:"ends_at"`
+		Reason       string     `json:"reason,omitempty"`
+		CreatedAt    time.Time  `json:"created_at"`
+		CreatedBy    string     `json:"created_by"`
+		RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+		RevokedBy    string     `json:"revoked_by,omitempty"`
+	}
+	return integritySHA256(integrityInput{
+		DelegationID: d.DelegationID,
+		Delegator:    d.Delegator,
+		Delegate:     d.Delegate,
+		StartsAt:     d.StartsAt,
+		EndsAt:       d.EndsAt,
+		Reason:       d.Reason,
+		CreatedAt:    d.CreatedAt,
+		CreatedBy:    d.CreatedBy,
+		RevokedAt:    d.RevokedAt,
+		RevokedBy:    d.RevokedBy,
+	})
+}
+
+// activeApprovalDelegate returns who votes for delegator at now, or "".
+func activeApprovalDelegate(ctx context.Context, tx *sql.Tx, delegator string, now time.Time) (string, error) {
+	if strings.TrimSpace(delegator) == "" {
+		return "", nil
+	}
+	var delegate string
+	err := tx.QueryRowContext(
+		ctx,
+		`SELECT delegate
+		 FROM policy_approval_delegations
+		 WHERE delegator = $1 AND revoked_at IS NULL AND starts_at <= $2 AND ends_at > $2
+		 ORDER BY starts_at DESC
+		 LIMIT 1`,
+		delegator,
+		now,
+	).Scan(&delegate)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return strings.TrimSpace(delegate), err
+}
+
+// handleReassignPolicyApproval assigns the next vote in the current stage to
+// one reviewer. Assignment never grants a vote: the assignee still needs the
+// stage roles (or admin), and the requester can never be assigned.
+func (api *experimentsAPI) handleReassignPolicyApproval(w http.ResponseWriter, r *http.Request) {
+	identity, ok := auth.IdentityFromContext(r.Context())
+	if !ok || strings.TrimSpace(identity.Subject) == "" {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	approvalID := strings.TrimSpace(r.PathValue("approval_id"))
+	if approvalID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "approval_id_required")
+		return
+	}
+
+	var req policyApprovalReassignRequest
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
+		return
+	}
+	assignee := strings.TrimSpace(req.Assignee)
+	reason := strings.TrimSpace(req.Reason)
+	if assignee == "" {
+		api.writeError(w, r, http.StatusBadRequest, "assignee_required")
+		return
+	}
+
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	state, err := lockPolicyApproval(r.Context(), tx, approvalID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if state.Status != approvalStatusPending {
+		api.writeError(w, r, http.StatusConflict, "approval_not_pending")
+		return
+	}
+	if assignee == state.RequestedBy {
+		api.writeError(w, r, http.StatusBadRequest, "assignee_is_requester")
+		return
+	}
+	voted, err := approvalVotedBy(r.Context(), tx, approvalID, assignee)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if voted {
+		api.writeError(w, r, http.StatusConflict, "assignee_already_voted")
+		return
+	}
+
+	now := time.Now().UTC()
+	delegate, err := activeApprovalDelegate(r.Context(), tx, assignee, now)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if _, err := tx.ExecContext(r.Context(), `UPDATE policy_approvals SET assigned_to = $1 WHERE approval_id = $2`, assignee, approvalID); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	err = auditlog.Enqueue(r.Context(), tx, auditlog.Event{
+		OccurredAt:   now,
+		Actor:        identity.Subject,
+		Action:       "policy.approval.reassigned",
+		ResourceType: "policy_approval",
+		ResourceID:   approvalID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload: map[string]any{
+			"service":           "experiments",
+			"approval_id":       approvalID,
+			"decision_id":       state.DecisionID,
+			"run_id":            state.RunID,
+			"stage":             state.stage().Name,
+			"previous_assignee": state.AssignedTo,
+			"assignee":          assignee,
+			"active_delegate":   delegate,
+			"reason":            reason,
+		},
+	})
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "audit_failed")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	out := map[string]any{
+		"approval_id":     approvalID,
+		"approval_status": approvalStatusPending,
+		"assigned_to":     assignee,
+		"stage":           state.stage().Name,
+		"stage_index":     state.CurrentStage,
+	}
+	if state.AssignedTo != "" {
+		out["previous_assignee"] = state.AssignedTo
+	}
+	if delegate != "" {
+		out["active_delegate"] = delegate
+	}
+	api.writeJSON(w, http.StatusOK, out)
+}
+
+func (api *experimentsAPI) handleCreatePolicyApprovalDelegation(w http.ResponseWriter, r *http.Request) {
+	identity, ok := auth.IdentityFromContext(r.Context())
+	if !ok || strings.TrimSpace(identity.Subject) == "" {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	var req policyApprovalDelegationRequest
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
+		return
+	}
+	now := time.Now().UTC()
+	delegation, code := req.delegation(identity, now)
+	if code != "" {
+		api.writeError(w, r, http.StatusBadRequest, code)
+		return
+	}
+	if delegation.Delegator != identity.Subject && !auth.HasAtLeast(identity.Roles, auth.RoleAdmin) {
+		api.writeError(w, r, http.StatusForbidden, "approval_delegation_forbidden")
+		return
+	}
+	delegation.DelegationID = uuid.NewString()
+	integrity, err := approvalDelegationIntegrity(delegation)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var one int
+	err = tx.QueryRowContext(
+		r.Context(),
+		`SELECT 1
+		 FROM policy_approval_delegations
+		 WHERE delegator = $1 AND revoked_at IS NULL AND starts_at < $3 AND ends_at > $2
+		 LIMIT 1`,
+		delegation.Delegator,
+		delegation.StartsAt,
+		delegation.EndsAt,
+	).Scan(&one)
+	if err == nil {
+		api.writeError(w, r, http.StatusConflict, "approval_delegation_overlaps")
+		return
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	_, err = tx.ExecContext(
+		r.Context(),
+		`INSERT INTO policy_approval_delegations (
+			delegation_id,
+			delegator,
+			delegate,
+			starts_at,
+			ends_at,
+			reason,
+			created_at,
+			created_by,
+			integrity_sha256
+		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)`,
+		delegation.DelegationID,
+		delegation.Delegator,
+		delegation.Delegate,
+		delegation.StartsAt,
+		delegation.EndsAt,
+		nullString(delegation.Reason),
+		delegation.CreatedAt,
+		delegation.CreatedBy,
+		integrity,
+	)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	err = auditlog.Enqueue(r.Context(), tx, auditlog.Event{
+		OccurredAt:   now,
+		Actor:        identity.Subject,
+		Action:       "policy.approval_delegation.created",
+		ResourceType: "policy_approval_delegation",
+		ResourceID:   delegation.DelegationID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload: map[string]any{
+			"service":       "experiments",
+			"delegation_id": delegation.DelegationID,
+			"delegator":     delegation.Delegator,
+			"delegate":      delegation.Delegate,
+			"starts_at":     delegation.StartsAt,
+			"ends_at":       delegation.EndsAt,
+			"reason":        delegation.Reason,
+		},
+	})
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "audit_failed")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	delegation.Active = delegation.activeAt(now)
+	api.writeJSON(w, http.StatusCreated, delegation)
+}
+
+func (api *experimentsAPI) handleListPolicyApprovalDelegations(w http.ResponseWriter, r *http.Request) {
+	limit := httpapi.Limit(r, 100, 500)
+	delegator := strings.TrimSpace(r.URL.Query().Get("delegator"))
+	delegate := strings.TrimSpace(r.URL.Query().Get("delegate"))
+	activeOnly := false
+	if raw := strings.TrimSpace(r.URL.Query().Get("active")); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			api.writeError(w, r, http.StatusBadRequest, "invalid_active")
+			return
+		}
+		activeOnly = parsed
+	}
+	now := time.Now().UTC()
+
+	query := `SELECT delegation_id, delegator, delegate, starts_at, ends_at, reason, created_at, created_by, revoked_at, revoked_by
+		FROM policy_approval_delegations`
+	args := []any{}
+	clauses := []string{}
+	if delegator != "" {
+		args = append(args, delegator)
+		clauses = append(clauses, "delegator = $"+strconv.Itoa(len(args)))
+	}
+	if delegate != "" {
+		args = append(args, delegate)
+		clauses = append(clauses, "delegate = $"+strconv.Itoa(len(args)))
+	}
+	if activeOnly {
+		args = append(args, now)
+		n := strconv.Itoa(len(args))
+		clauses = append(clauses, "revoked_at IS NULL AND starts_at <= $"+n+" AND ends_at > $"+n)
+	}
+	if len(clauses) > 0 {
+		query += " WHERE " + strings.Join(clauses, " AND ")
+	}
+	args = append(args, limit)
+	query += " ORDER BY starts_at DESC LIMIT $" + strconv.Itoa(len(args))
+
+	rows, err := api.db.QueryContext(r.Context(), query, args...)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer rows.Close()
+
+	out := make([]policyApprovalDelegation, 0, limit)
+	for rows.Next() {
+		delegation, err := scanPolicyApprovalDelegation(rows)
+		if err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+		delegation.Active = delegation.activeAt(now)
+		out = append(out, delegation)
+	}
+	if err := rows.Err(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	api.writeJSON(w, http.StatusOK, policyApprovalDelegationListResponse{Delegations: out})
+}
+
+// handleRevokePolicyApprovalDelegation ends a delegation early. The row is
+// kept so votes cast under it stay explainable.
+func (api *experimentsAPI) handleRevokePolicyApprovalDelegation(w http.ResponseWriter, r *http.Request) {
+	identity, ok := auth.IdentityFromContext(r.Context())
+	if !ok || strings.TrimSpace(identity.Subject) == "" {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	delegationID := strings.TrimSpace(r.PathValue("delegation_id"))
+	if delegationID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "delegation_id_required")
+		return
+	}
+
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	delegation, err := scanPolicyApprovalDelegation(tx.QueryRowContext(
+		r.Context(),
+		`SELECT delegation_id, delegator, delegate, starts_at, ends_at, reason, created_at, created_by, revoked_at, revoked_by
+		 FROM policy_approval_delegations
+		 WHERE delegation_id = $1
+		 FOR UPDATE`,
+		delegationID,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if delegation.Delegator != identity.Subject && !auth.HasAtLeast(identity.Roles, auth.RoleAdmin) {
+		api.writeError(w, r, http.StatusForbidden, "approval_delegation_forbidden")
+		return
+	}
+	if delegation.RevokedAt != nil {
+		api.writeError(w, r, http.StatusConflict, "approval_delegation_revoked")
+		return
+	}
+
+	now := time.Now().UTC()
+	delegation.RevokedAt = &now
+	delegation.RevokedBy = identity.Subject
+	integrity, err := approvalDelegationIntegrity(delegation)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	_, err = tx.ExecContext(
+		r.Context(),
+		`UPDATE policy_approval_delegations
+		 SET revoked_at = $1,
+			 revoked_by = $2,
+			 integrity_sha256 = $3
+		 WHERE delegation_id = $4`,
+		now,
+		identity.Subject,
+		integrity,
+		delegationID,
+	)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	err = auditlog.Enqueue(r.Context(), tx, auditlog.Event{
+		OccurredAt:   now,
+		Actor:        identity.Subject,
+		Action:       "policy.approval_delegation.revoked",
+		ResourceType: "policy_approval_delegation",
+		ResourceID:   delegationID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload: map[string]any{
+			"service":       "experiments",
+			"delegation_id": delegationID,
+			"delegator":     delegation.Delegator,
+			"delegate":      delegation.Delegate,
+		},
+	})
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "audit_failed")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	delegation.Active = false
+	api.writeJSON(w, http.StatusOK, delegation)
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanPolicyApprovalDelegation(row rowScanner) (policyApprovalDelegation, error) {
+	var (
+		out       policyApprovalDelegation
+		reason    sql.NullString
+		revokedAt sql.NullTime
+		revokedBy sql.NullString
+	)
+	if err := row.Scan(
+		&out.DelegationID,
+		&out.Delegator,
+		&out.Delegate,
+		&out.StartsAt,
+		&out.EndsAt,
+		&reason,
+		&out.CreatedAt,
+		&out.CreatedBy,
+		&revokedAt,
+		&revokedBy,
+	); err != nil {
+		return policyApprovalDelegation{}, err
+	}
+	out.Reason = strings.TrimSpace(reason.String)
+	if revokedAt.Valid {
+		t := revokedAt.Time.UTC()
+		out.RevokedAt = &t
+	}
+	out.RevokedBy = strings.TrimSpace(revokedBy.String)
+	return out, nil
+}
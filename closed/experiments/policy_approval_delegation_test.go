@@ -0,0 +1,55 @@
+package experiments
+
+import (
+	"testing"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+)
+
+func TestPolicyApprovalDelegationRequest(t *testing.T) {
+	now := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	identity := auth.Identity{Subject: "alice"}
+	at := func(d time.Duration) *time.Time {
+		t := now.Add(d)
+		return &t
+	}
+
+	delegation, code := policyApprovalDelegationRequest{Delegate: " bob ", EndsAt: at(7 * 24 * time.Hour)}.delegation(identity, now)
+	if code != "" {
+		t.Fatalf("unexpected code %q", code)
+	}
+	if delegation.Delegator != "alice" || delegation.Delegate != "bob" || !delegation.StartsAt.Equal(now) || delegation.CreatedBy != "alice" {
+		t.Fatalf("unexpected delegation %+v", delegation)
+	}
+	if !delegation.activeAt(now) || delegation.activeAt(now.Add(7*24*time.Hour)) {
+		t.Fatalf("window must be [starts_at, ends_at)")
+	}
+	if delegation.activeAt(now.Add(-time.Second)) {
+		t.Fatalf("delegation must not be active before starts_at")
+	}
+	revokedAt := now
+	delegation.RevokedAt = &revokedAt
+	if delegation.activeAt(now.Add(time.Hour)) {
+		t.Fatalf("revoked delegation must not be active")
+	}
+
+	cases := []struct {
+		req  policyApprovalDelegationRequest
+		want string
+	}{
+		{policyApprovalDelegationRequest{EndsAt: at(time.Hour)}, "delegate_required"},
+		{policyApprovalDelegationRequest{Delegate: "alice", EndsAt: at(time.Hour)}, "delegate_is_delegator"},
+		{policyApprovalDelegationRequest{Delegator: "bob", Delegate: "bob", EndsAt: at(time.Hour)}, "delegate_is_delegator"},
+		{policyApprovalDelegationRequest{Delegate: "bob"}, "ends_at_required"},
+		{policyApprovalDelegationRequest{Delegate: "bob", StartsAt: at(2 * time.Hour), EndsAt: at(time.Hour)}, "invalid_delegation_window"},
+		{policyApprovalDelegationRequest{Delegate: "bob", StartsAt: at(-2 * time.Hour), EndsAt: at(-time.Hour)}, "invalid_delegation_window"},
+		{policyApprovalDelegationRequest{Delegate: "bob", EndsAt: at(maxApprovalDelegationWindow + time.Hour)}, "delegation_window_too_long"},
+		{policyApprovalDelegationRequest{Delegator: "carol", Delegate: "bob", StartsAt: at(24 * time.Hour), EndsAt: at(48 * time.Hour)}, ""},
+	}
+	for _, tc := range cases {
+		if _, got := tc.req.delegation(identity, now); got != tc.want {
+			t.Fatalf("%+v: got %q, want %q", tc.req, got, tc.want)
+		}
+	}
+}
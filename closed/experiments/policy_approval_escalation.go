@@ -0,0 +1,156 @@
+package experiments
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/integrations/webhooks"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
+	"github.com/animus-labs/animus-go/closed/internal/platform/policy"
+)
+
+const approvalEscalationActor = "system:policy-approval-escalation"
+
+func startApprovalEscalations(ctx context.Context, logger *slog.Logger, api *experimentsAPI, interval time.Duration) {
+	if api == nil || api.db == nil {
+		return
+	}
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := api.runApprovalEscalations(ctx, time.Now().UTC()); err != nil && logger != nil {
+					logger.Warn("policy approval escalation failed", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// runApprovalEscalations handles approvals whose current stage is overdue.
+// Each approval is locked with SKIP LOCKED, so replicas never escalate the
+// same stage twice; notify clears escalate_at, so a stage notifies once.
+func (api *experimentsAPI) runApprovalEscalations(ctx context.Context, now time.Time) error {
+	rows, err := api.db.QueryContext(
+		ctx,
+		`SELECT approval_id
+		 FROM policy_approvals
+		 WHERE status = $1 AND escalate_at IS NOT NULL AND escalate_at <= $2
+		 ORDER BY escalate_at
+		 LIMIT 50`,
+		approvalStatusPending,
+		now,
+	)
+	if err != nil {
+		return err
+	}
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	var lastErr error
+	for _, id := range ids {
+		if err := api.escalateApproval(ctx, id, now); err != nil {
+			lastErr = errors.Join(lastErr, err)
+		}
+	}
+	return lastErr
+}
+
+func (api *experimentsAPI) escalateApproval(ctx context.Context, approvalID string, now time.Time) error {
+	tx, err := api.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var due bool
+	err = tx.QueryRowContext(
+		ctx,
+		`SELECT status = $2 AND escalate_at IS NOT NULL AND escalate_at <= $3
+		 FROM policy_approvals
+		 WHERE approval_id = $1
+		 FOR UPDATE SKIP LOCKED`,
+		approvalID,
+		approvalStatusPending,
+		now,
+	).Scan(&due)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !due) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	state, err := lockPolicyApproval(ctx, tx, approvalID)
+	if err != nil {
+		return err
+	}
+
+	stage := state.stage()
+	action := stage.EscalationAction()
+	payload := map[string]any{
+		"service":     "experiments",
+		"approval_id": approvalID,
+		"decision_id": state.DecisionID,
+		"run_id":      state.RunID,
+		"stage":       stage.Name,
+		"action":      action,
+	}
+	switch action {
+	case policy.EscalationDeny:
+		if err := api.denyPolicyApproval(ctx, tx, state, approvalEscalationActor, "approval_timeout", now); err != nil {
+			return err
+		}
+	default:
+		if _, err := tx.ExecContext(ctx, `UPDATE policy_approvals SET escalate_at = NULL WHERE approval_id = $1`, approvalID); err != nil {
+			return err
+		}
+	}
+	if err := auditlog.Enqueue(ctx, tx, auditlog.Event{
+		OccurredAt:   now,
+		Actor:        approvalEscalationActor,
+		Action:       "policy.approval.escalated",
+		ResourceType: "policy_approval",
+		ResourceID:   approvalID,
+		Payload:      payload,
+	}); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	if action != policy.EscalationNotify || state.RunID == "" {
+		return nil
+	}
+	projectID, err := projectIDForRun(ctx, api.db, state.RunID)
+	if err != nil {
+		return err
+	}
+	webhookPayload, err := webhooks.PolicyApprovalEscalatedPayload(projectID, approvalID, state.RunID, stage.Name, now)
+	if err != nil {
+		return err
+	}
+	return api.enqueueWebhookPayload(ctx, approvalEscalationActor, "", webhookPayload)
+}
@@ -0,0 +1,162 @@
+package experiments
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/domain"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/migrate"
+	"github.com/animus-labs/animus-go/closed/internal/repo/postgres"
+	"github.com/animus-labs/animus-go/closed/migrations"
+	"github.com/google/uuid"
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+// TestRunApprovalFlow drives a require_approval policy end to end against a
+// scratch database named by ANIMUS_EXPERIMENTS_TEST_DATABASE_URL (migrated
+// on first use): creating the run opens a staged approval, the run's model
+// export stays blocked until both stages have voted, then it is allowed.
+//
+//	ANIMUS_EXPERIMENTS_TEST_DATABASE_URL=postgres://... go test ./closed/experiments -run RunApprovalFlow
+func TestRunApprovalFlow(t *testing.T) {
+	dsn := os.Getenv("ANIMUS_EXPERIMENTS_TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("ANIMUS_EXPERIMENTS_TEST_DATABASE_URL not set")
+	}
+	ctx := context.Background()
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer db.Close()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	migrator, err := migrate.New(db, migrations.Files, logger)
+	if err != nil {
+		t.Fatalf("migrations: %v", err)
+	}
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	projectID := "approval-flow-" + uuid.NewString()
+	if err := postgres.NewProjectStore(db).Create(ctx, domain.Project{
+		ID:              projectID,
+		Name:            projectID,
+		CreatedAt:       time.Now().UTC(),
+		CreatedBy:       "alice",
+		IntegritySHA256: sha256HexBytes([]byte(projectID)),
+	}); err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+
+	api := &experimentsAPI{logger: logger, db: db, reads: db}
+	mux := http.NewServeMux()
+	api.register(mux)
+	alice := auth.Identity{Subject: "alice", Roles: []string{auth.RoleEditor}}
+	bob := auth.Identity{Subject: "bob", Roles: []string{auth.RoleEditor, "security"}}
+	carol := auth.Identity{Subject: "carol", Roles: []string{auth.RoleAdmin}}
+	call := func(identity auth.Identity, method, path string, body any, out any) int {
+		t.Helper()
+		raw, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("encode body: %v", err)
+		}
+		req := httptest.NewRequest(method, path, bytes.NewReader(raw))
+		req.Header.Set("Content-Type", "application/json")
+		ctx := auth.ContextWithProjectID(auth.ContextWithIdentity(req.Context(), identity), projectID)
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req.WithContext(ctx))
+		if out != nil && rec.Code < 300 {
+			if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+				t.Fatalf("%s %s: decode %s: %v", method, path, rec.Body.String(), err)
+			}
+		}
+		if rec.Code >= 300 {
+			t.Logf("%s %s: %d %s", method, path, rec.Code, rec.Body.String())
+		}
+		return rec.Code
+	}
+
+	var exp experiment
+	if code := call(alice, http.MethodPost, "/experiments", createExperimentRequest{Name: "approval flow"}, &exp); code != http.StatusCreated {
+		t.Fatalf("create experiment: %d", code)
+	}
+	spec := `schema: animus.policy.v1
+default_effect: allow
+rules:
+  - id: gpu-review
+    effect: require_approval
+    when:
+      all:
+        - field: experiment.id
+          op: eq
+          value: ` + exp.ExperimentID + `
+    approval:
+      stages:
+        - name: security
+          groups: [security]
+        - name: data-owner
+`
+	if code := call(carol, http.MethodPost, "/policies", createPolicyRequest{Name: "approval-flow-" + exp.ExperimentID, Spec: spec}, nil); code != http.StatusCreated {
+		t.Fatalf("create policy: %d", code)
+	}
+
+	var run experimentRun
+	if code := call(alice, http.MethodPost, "/experiments/"+exp.ExperimentID+"/runs", createExperimentRunRequest{Status: "pending"}, &run); code != http.StatusCreated {
+		t.Fatalf("create run: %d", code)
+	}
+	var approvals policyApprovalListResponse
+	if code := call(carol, http.MethodGet, "/policy-approvals?run_id="+run.RunID, nil, &approvals); code != http.StatusOK {
+		t.Fatalf("list approvals: %d", code)
+	}
+	if len(approvals.Approvals) != 1 || approvals.Approvals[0].Status != approvalStatusPending {
+		t.Fatalf("expected one pending approval, got %+v", approvals.Approvals)
+	}
+	approvalID := approvals.Approvals[0].ApprovalID
+
+	export := func() string {
+		t.Helper()
+		decision, err := api.checkModelExportPolicy(ctx, projectID, run.RunID)
+		if err != nil {
+			t.Fatalf("check export policy: %v", err)
+		}
+		return decision.Code
+	}
+	if code := export(); code != modelExportPolicyApprovalRequired {
+		t.Fatalf("before votes: export code %q", code)
+	}
+
+	vote := policyApprovalActionRequest{Reason: "reviewed"}
+	if code := call(alice, http.MethodPost, "/policy-approvals/"+approvalID+"/approve", vote, nil); code != http.StatusForbidden {
+		t.Fatalf("requester vote: %d", code)
+	}
+	if code := call(carol, http.MethodPost, "/policy-approvals/"+approvalID+"/approve", vote, nil); code != http.StatusForbidden {
+		t.Fatalf("admin outside the security stage: %d", code)
+	}
+	if code := call(bob, http.MethodPost, "/policy-approvals/"+approvalID+"/approve", vote, nil); code != http.StatusOK {
+		t.Fatalf("security vote: %d", code)
+	}
+	if code := export(); code != modelExportPolicyApprovalRequired {
+		t.Fatalf("after first stage: export code %q", code)
+	}
+	var final map[string]any
+	if code := call(carol, http.MethodPost, "/policy-approvals/"+approvalID+"/approve", vote, &final); code != http.StatusOK {
+		t.Fatalf("data-owner vote: %d", code)
+	}
+	if final["approval_status"] != approvalStatusApproved || final["dispatch_required"] != true {
+		t.Fatalf("final vote response %+v", final)
+	}
+	if code := export(); code != "" {
+		t.Fatalf("after approval: export code %q", code)
+	}
+}
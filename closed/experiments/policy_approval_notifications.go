@@ -0,0 +1,229 @@
+package experiments
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/integrations/notify"
+)
+
+func startApprovalNotifications(ctx context.Context, logger *slog.Logger, api *experimentsAPI, interval time.Duration) {
+	if api == nil || api.db == nil || api.approvalNotifier == nil {
+		return
+	}
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := api.runApprovalNotifications(ctx, time.Now().UTC()); err != nil && logger != nil {
+					logger.Warn("policy approval notification failed", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// approvalNotice is what a notification says about one approval.
+type approvalNotice struct {
+	ApprovalID  string
+	RunID       string
+	ProjectID   string
+	PolicyName  string
+	Status      string
+	StageName   string
+	AssignedTo  string
+	RequestedAt time.Time
+	RequestedBy string
+	DecidedBy   string
+	Reason      string
+}
+
+// approvalNotificationClaim stamps up to 50 approvals due for event and
+// returns their ids.
+type approvalNotificationClaim struct {
+	event notify.Event
+	query string
+	args  []any
+}
+
+// runApprovalNotifications announces new, overdue and decided approvals.
+// Each approval is claimed by stamping its column before sending, so replicas
+// never notify twice; like digests, a failed send is not retried.
+func (api *experimentsAPI) runApprovalNotifications(ctx context.Context, now time.Time) error {
+	if api.approvalNotifier == nil {
+		return nil
+	}
+	claims := []approvalNotificationClaim{
+		{
+			event: notify.EventApprovalRequested,
+			query: `UPDATE policy_approvals SET requested_notified_at = $1
+				 WHERE approval_id IN (
+					SELECT approval_id FROM policy_approvals
+					WHERE status = $2 AND requested_notified_at IS NULL
+					ORDER BY requested_at
+					LIMIT 50
+					FOR UPDATE SKIP LOCKED)
+				 RETURNING approval_id`,
+			args: []any{now, approvalStatusPending},
+		},
+		{
+			event: notify.EventApprovalDecided,
+			query: `UPDATE policy_approvals SET decided_notified_at = $1
+				 WHERE approval_id IN (
+					SELECT approval_id FROM policy_approvals
+					WHERE status <> $2 AND decided_notified_at IS NULL
+					ORDER BY decided_at
+					LIMIT 50
+					FOR UPDATE SKIP LOCKED)
+				 RETURNING approval_id`,
+			args: []any{now, approvalStatusPending},
+		},
+	}
+	if api.approvalPendingAfter > 0 {
+		claims = append(claims, approvalNotificationClaim{
+			event: notify.EventApprovalPending,
+			query: `UPDATE policy_approvals SET reminded_at = $1
+				 WHERE approval_id IN (
+					SELECT approval_id FROM policy_approvals
+					WHERE status = $2 AND reminded_at IS NULL AND requested_at <= $3
+					ORDER BY requested_at
+					LIMIT 50
+					FOR UPDATE SKIP LOCKED)
+				 RETURNING approval_id`,
+			args: []any{now, approvalStatusPending, now.Add(-api.approvalPendingAfter)},
+		})
+	}
+
+	var lastErr error
+	for _, claim := range claims {
+		ids, err := claimApprovalNotifications(ctx, api.db, claim.query, claim.args...)
+		if err != nil {
+			lastErr = errors.Join(lastErr, err)
+			continue
+		}
+		for _, id := range ids {
+			notice, err := loadApprovalNotice(ctx, api.db, id)
+			if err == nil {
+				err = api.approvalNotifier.Send(ctx, approvalNotification(claim.event, notice, now))
+			}
+			if err != nil {
+				lastErr = errors.Join(lastErr, fmt.Errorf("approval %s: %w", id, err))
+			}
+		}
+	}
+	return lastErr
+}
+
+func claimApprovalNotifications(ctx context.Context, db *sql.DB, query string, args ...any) ([]string, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func loadApprovalNotice(ctx context.Context, db *sql.DB, approvalID string) (approvalNotice, error) {
+	var (
+		notice     approvalNotice
+		runID      sql.NullString
+		projectID  sql.NullString
+		decidedBy  sql.NullString
+		reason     sql.NullString
+		assignedTo sql.NullString
+		workflow   []byte
+		stage      int
+	)
+	err := db.QueryRowContext(
+		ctx,
+		`SELECT a.run_id, r.project_id, p.name, a.status, a.requested_at, a.requested_by,
+				a.decided_by, a.reason, a.assigned_to, a.workflow, a.current_stage
+		 FROM policy_approvals a
+		 JOIN policy_decisions d ON d.decision_id = a.decision_id
+		 JOIN policies p ON p.policy_id = d.policy_id
+		 LEFT JOIN experiment_runs r ON r.run_id = a.run_id
+		 WHERE a.approval_id = $1`,
+		approvalID,
+	).Scan(&runID, &projectID, &notice.PolicyName, &notice.Status, &notice.RequestedAt, &notice.RequestedBy,
+		&decidedBy, &reason, &assignedTo, &workflow, &stage)
+	if err != nil {
+		return approvalNotice{}, err
+	}
+	notice.ApprovalID = approvalID
+	notice.RunID = strings.TrimSpace(runID.String)
+	notice.ProjectID = strings.TrimSpace(projectID.String)
+	notice.Status = strings.TrimSpace(notice.Status)
+	notice.DecidedBy = strings.TrimSpace(decidedBy.String)
+	notice.Reason = strings.TrimSpace(reason.String)
+	notice.AssignedTo = strings.TrimSpace(assignedTo.String)
+	notice.StageName = policyApprovalState{Workflow: decodeApprovalWorkflow(workflow), CurrentStage: stage}.stage().Name
+	return notice, nil
+}
+
+// approvalNotification renders the notice. The assignee is emailed directly
+// when their subject is an email address.
+func approvalNotification(event notify.Event, n approvalNotice, now time.Time) notify.Message {
+	var subject string
+	switch event {
+	case notify.EventApprovalRequested:
+		subject = fmt.Sprintf("Approval requested: %s", n.PolicyName)
+	case notify.EventApprovalPending:
+		subject = fmt.Sprintf("Approval pending for %s: %s", now.Sub(n.RequestedAt).Truncate(time.Minute), n.PolicyName)
+	default:
+		subject = fmt.Sprintf("Approval %s: %s", n.Status, n.PolicyName)
+	}
+
+	lines := []string{"Approval: " + n.ApprovalID}
+	add := func(label, value string) {
+		if value != "" {
+			lines = append(lines, label+": "+value)
+		}
+	}
+	add("Project", n.ProjectID)
+	add("Run", n.RunID)
+	add("Policy", n.PolicyName)
+	add("Requested by", n.RequestedBy)
+	lines = append(lines, "Requested at: "+n.RequestedAt.UTC().Format(time.RFC3339))
+	if event == notify.EventApprovalDecided {
+		add("Decided by", n.DecidedBy)
+		add("Reason", n.Reason)
+	} else {
+		add("Stage", n.StageName)
+		add("Assigned to", n.AssignedTo)
+	}
+
+	msg := notify.Message{
+		Event:   event,
+		Subject: subject,
+		Text:    strings.Join(lines, "\n"),
+		SentAt:  now,
+	}
+	if event != notify.EventApprovalDecided && n.AssignedTo != "" {
+		if addr, err := mail.ParseAddress(n.AssignedTo); err == nil && addr.Address == n.AssignedTo {
+			msg.To = []string{n.AssignedTo}
+		}
+	}
+	return msg
+}
@@ -0,0 +1,54 @@
+package experiments
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/integrations/notify"
+)
+
+func TestApprovalNotification(t *testing.T) {
+	requestedAt := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	notice := approvalNotice{
+		ApprovalID:  "appr-1",
+		RunID:       "run-1",
+		ProjectID:   "proj-1",
+		PolicyName:  "pii-export",
+		Status:      approvalStatusPending,
+		StageName:   "security",
+		AssignedTo:  "carol@example.com",
+		RequestedAt: requestedAt,
+		RequestedBy: "alice",
+	}
+
+	requested := approvalNotification(notify.EventApprovalRequested, notice, requestedAt)
+	if requested.Subject != "Approval requested: pii-export" {
+		t.Fatalf("unexpected subject %q", requested.Subject)
+	}
+	for _, want := range []string{"Approval: appr-1", "Run: run-1", "Stage: security", "Assigned to: carol@example.com"} {
+		if !strings.Contains(requested.Text, want) {
+			t.Fatalf("text missing %q:\n%s", want, requested.Text)
+		}
+	}
+	if len(requested.To) != 1 || requested.To[0] != "carol@example.com" {
+		t.Fatalf("expected assignee to be emailed, got %v", requested.To)
+	}
+
+	pending := approvalNotification(notify.EventApprovalPending, notice, requestedAt.Add(26*time.Hour+30*time.Second))
+	if pending.Subject != "Approval pending for 26h0m0s: pii-export" {
+		t.Fatalf("unexpected subject %q", pending.Subject)
+	}
+
+	notice.Status = approvalStatusDenied
+	notice.DecidedBy = "bob"
+	notice.Reason = "no DPA"
+	notice.AssignedTo = "dave"
+	decided := approvalNotification(notify.EventApprovalDecided, notice, requestedAt.Add(time.Hour))
+	if decided.Subject != "Approval denied: pii-export" || !strings.Contains(decided.Text, "Decided by: bob") || strings.Contains(decided.Text, "Stage:") {
+		t.Fatalf("unexpected decided notice %+v", decided)
+	}
+	if len(decided.To) != 0 {
+		t.Fatalf("decided notices go to configured channels only, got %v", decided.To)
+	}
+}
@@ -0,0 +1,363 @@
+package experiments
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/policy"
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+	"github.com/google/uuid"
+)
+
+const (
+	approvalVoteApprove = "approve"
+	approvalVoteDeny    = "deny"
+)
+
+var errApprovalAlreadyVoted = errors.New("approval already voted")
+
+// policyApprovalState is a pending approval locked for update, with the
+// workflow snapshotted when the approval was requested.
+type policyApprovalState struct {
+	ApprovalID   string
+	DecisionID   string
+	RunID        string
+	Status       string
+	RequestedAt  time.Time
+	RequestedBy  string
+	Workflow     policy.ApprovalWorkflow
+	CurrentStage int
+	AssignedTo   string
+	// OnBehalfOf is set when the voter acts as the assignee's delegate.
+	OnBehalfOf string
+}
+
+func (s policyApprovalState) stage() policy.ApprovalStage {
+	if s.CurrentStage < 0 || s.CurrentStage >= len(s.Workflow.Stages) {
+		return policy.ApprovalStage{}
+	}
+	return s.Workflow.Stages[s.CurrentStage]
+}
+
+func (s policyApprovalState) lastStage() bool {
+	return s.CurrentStage >= len(s.Workflow.Stages)-1
+}
+
+type policyApprovalVote struct {
+	VoteID     string    `json:"vote_id"`
+	Stage      int       `json:"stage"`
+	StageName  string    `json:"stage_name"`
+	Voter      string    `json:"voter"`
+	OnBehalfOf string    `json:"on_behalf_of,omitempty"`
+	Vote       string    `json:"vote"`
+	Reason     string    `json:"reason,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// newPolicyApprovalSummary fills the workflow fields of summary.
+func newPolicyApprovalSummary(workflow []byte, stage int, escalateAt *time.Time, summary policyApprovalSummary) policyApprovalSummary {
+	state := policyApprovalState{Workflow: decodeApprovalWorkflow(workflow), CurrentStage: stage}
+	summary.Workflow = state.Workflow
+	summary.CurrentStage = stage
+	summary.StageName = state.stage().Name
+	if escalateAt != nil && summary.Status == approvalStatusPending {
+		t := escalateAt.UTC()
+		summary.EscalateAt = &t
+	}
+	return summary
+}
+
+// decodeApprovalWorkflow reads a stored workflow snapshot. Approvals created
+// before workflows existed have none and get the single admin review.
+func decodeApprovalWorkflow(raw []byte) policy.ApprovalWorkflow {
+	if len(raw) == 0 || string(raw) == "null" {
+		return policy.DefaultApprovalWorkflow()
+	}
+	var workflow policy.ApprovalWorkflow
+	if err := json.Unmarshal(raw, &workflow); err != nil || workflow.Validate() != nil {
+		return policy.DefaultApprovalWorkflow()
+	}
+	return workflow
+}
+
+// approvalVoterError returns the error code refusing identity a vote in the
+// current stage, or "" when it may vote.
+func approvalVoterError(state policyApprovalState, identity auth.Identity) string {
+	if state.RequestedBy == identity.Subject {
+		return "approval_requires_second_reviewer"
+	}
+	stage := state.stage()
+	if stage.Restricted() {
+		if !stage.Allows(identity.Roles) {
+			return "approval_stage_role_required"
+		}
+		return ""
+	}
+	if !auth.HasAtLeast(identity.Roles, auth.RoleAdmin) {
+		return "approval_requires_admin"
+	}
+	return ""
+}
+
+// approvalActingFor decides who subject votes for on an assigned approval:
+// the assignee votes for themselves, and the assignee's active delegate
+// (assigneeDelegate) votes on their behalf. Anyone else is refused with
+// false. Unassigned approvals are open to every eligible voter.
+func approvalActingFor(state policyApprovalState, subject, assigneeDelegate string) (string, bool) {
+	if state.AssignedTo == "" || state.AssignedTo == subject {
+		return "", true
+	}
+	if assigneeDelegate != "" && assigneeDelegate == subject {
+		return state.AssignedTo, true
+	}
+	return "", false
+}
+
+func lockPolicyApproval(ctx context.Context, tx *sql.Tx, approvalID string) (policyApprovalState, error) {
+	var (
+		state      policyApprovalState
+		runID      sql.NullString
+		workflow   []byte
+		assignedTo sql.NullString
+	)
+	err := tx.QueryRowContext(
+		ctx,
+		`SELECT decision_id, run_id, status, requested_at, requested_by, workflow, current_stage, assigned_to
+		 FROM policy_approvals
+		 WHERE approval_id = $1
+		 FOR UPDATE`,
+		approvalID,
+	).Scan(&state.DecisionID, &runID, &state.Status, &state.RequestedAt, &state.RequestedBy, &workflow, &state.CurrentStage, &assignedTo)
+	if err != nil {
+		return policyApprovalState{}, err
+	}
+	state.ApprovalID = approvalID
+	state.RunID = strings.TrimSpace(runID.String)
+	state.Status = strings.TrimSpace(state.Status)
+	state.Workflow = decodeApprovalWorkflow(workflow)
+	state.AssignedTo = strings.TrimSpace(assignedTo.String)
+	return state, nil
+}
+
+// recordApprovalVote stores a vote in the current stage and releases the
+// stage assignment. A subject votes at most once per approval, whether
+// directly or through a delegate, so one person cannot complete two stages.
+func recordApprovalVote(ctx context.Context, tx *sql.Tx, state policyApprovalState, voter, vote, reason string, now time.Time) error {
+	principal := voter
+	if state.OnBehalfOf != "" {
+		principal = state.OnBehalfOf
+	}
+	voted, err := approvalVotedBy(ctx, tx, state.ApprovalID, voter, principal)
+	if err != nil {
+		return err
+	}
+	if voted {
+		return errApprovalAlreadyVoted
+	}
+
+	voteID := uuid.NewString()
+	stageName := strings.TrimSpace(state.stage().Name)
+	type integrityInput struct {
+		VoteID     string    `json:"vote_id"`
+		ApprovalID string    `json:"approval_id"`
+		Stage      int       `json:"stage"`
+		StageName  string    `json:"stage_name"`
+		Voter      string    `json:"voter"`
+		OnBehalfOf string    `json:"on_behalf_of,omitempty"`
+		Vote       string    `json:"vote"`
+		Reason     string    `json:"reason,omitempty"`
+		CreatedAt  time.Time `json:"created_at"`
+	}
+	integrity, err := integritySHA256(integrityInput{
+		VoteID:     voteID,
+		ApprovalID: state.ApprovalID,
+		Stage:      state.CurrentStage,
+		StageName:  stageName,
+		Voter:      voter,
+		OnBehalfOf: state.OnBehalfOf,
+		Vote:       vote,
+		Reason:     reason,
+		CreatedAt:  now,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(
+		ctx,
+		`INSERT INTO policy_approval_votes (
+			vote_id,
+			approval_id,
+			stage,
+			stage_name,
+			voter,
+			on_behalf_of,
+			vote,
+			reason,
+			created_at,
+			integrity_sha256
+		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)`,
+		voteID,
+		state.ApprovalID,
+		state.CurrentStage,
+		stageName,
+		voter,
+		nullString(state.OnBehalfOf),
+		vote,
+		nullString(reason),
+		now,
+		integrity,
+	)
+	if err != nil || state.AssignedTo == "" {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `UPDATE policy_approvals SET assigned_to = NULL WHERE approval_id = $1`, state.ApprovalID)
+	return err
+}
+
+// approvalVotedBy reports whether any of subjects voted on the approval,
+// themselves or through a delegate.
+func approvalVotedBy(ctx context.Context, tx *sql.Tx, approvalID string, subjects ...string) (bool, error) {
+	for _, subject := range uniqueNonEmpty(subjects) {
+		var one int
+		err := tx.QueryRowContext(
+			ctx,
+			`SELECT 1
+			 FROM policy_approval_votes
+			 WHERE approval_id = $1 AND (voter = $2 OR on_behalf_of = $2)
+			 LIMIT 1`,
+			approvalID,
+			subject,
+		).Scan(&one)
+		if err == nil {
+			return true, nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+func countStageApprovals(ctx context.Context, tx *sql.Tx, approvalID string, stage int) (int, error) {
+	var count int
+	err := tx.QueryRowContext(
+		ctx,
+		`SELECT COUNT(1)
+		 FROM policy_approval_votes
+		 WHERE approval_id = $1 AND stage = $2 AND vote = $3`,
+		approvalID,
+		stage,
+		approvalVoteApprove,
+	).Scan(&count)
+	return count, err
+}
+
+// advanceApprovalStage opens the next stage and restarts its escalation clock.
+func advanceApprovalStage(ctx context.Context, tx *sql.Tx, state *policyApprovalState, now time.Time) error {
+	state.CurrentStage++
+	var escalateAt sql.NullTime
+	if at, ok := state.stage().EscalatesAt(now); ok {
+		escalateAt = sql.NullTime{Time: at, Valid: true}
+	}
+	_, err := tx.ExecContext(
+		ctx,
+		`UPDATE policy_approvals
+		 SET current_stage = $1,
+			 stage_started_at = $2,
+			 escalate_at = $3
+		 WHERE approval_id = $4`,
+		state.CurrentStage,
+		now,
+		escalateAt,
+		state.ApprovalID,
+	)
+	return err
+}
+
+// finishPolicyApproval moves the approval to its final status.
+func finishPolicyApproval(ctx context.Context, tx *sql.Tx, state policyApprovalState, status, decidedBy, reason string, decidedAt time.Time) error {
+	type approvalIntegrityInput struct {
+		ApprovalID  string     `json:"approval_id"`
+		DecisionID  string     `json:"decision_id"`
+		RunID       string     `json:"run_id"`
+		Status      string     `json:"status"`
+		RequestedAt time.Time  `json:"requested_at"`
+		RequestedBy string     `json:"requested_by"`
+		DecidedAt   *time.Time `json:"decided_at,omitempty"`
+		DecidedBy   string     `json:"decided_by,omitempty"`
+		Reason      string     `json:"reason,omitempty"`
+	}
+	integrity, err := integritySHA256(approvalIntegrityInput{
+		ApprovalID:  state.ApprovalID,
+		DecisionID:  state.DecisionID,
+		RunID:       state.RunID,
+		Status:      status,
+		RequestedAt: state.RequestedAt,
+		RequestedBy: state.RequestedBy,
+		DecidedAt:   &decidedAt,
+		DecidedBy:   decidedBy,
+		Reason:      reason,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(
+		ctx,
+		`UPDATE policy_approvals
+		 SET status = $1,
+			 decided_at = $2,
+			 decided_by = $3,
+			 reason = $4,
+			 integrity_sha256 = $5,
+			 escalate_at = NULL,
+			 assigned_to = NULL
+		 WHERE approval_id = $6`,
+		status,
+		decidedAt,
+		decidedBy,
+		nullString(reason),
+		integrity,
+		state.ApprovalID,
+	)
+	return err
+}
+
+// denyPolicyApproval denies the approval and cancels its run.
+func (api *experimentsAPI) denyPolicyApproval(ctx context.Context, tx *sql.Tx, state policyApprovalState, decidedBy, reason string, decidedAt time.Time) error {
+	if err := finishPolicyApproval(ctx, tx, state, approvalStatusDenied, decidedBy, reason, decidedAt); err != nil {
+		return err
+	}
+	if _, err := api.insertRunStateEvent(ctx, tx, state.RunID, "canceled", decidedAt, map[string]any{
+		"reason":         "policy_denied",
+		"approval_id":    state.ApprovalID,
+		"decision_id":    state.DecisionID,
+		"approval_stage": state.stage().Name,
+		"approved_by":    decidedBy,
+		"deny_reason":    reason,
+	}); err != nil {
+		return err
+	}
+	return api.insertRunEvent(ctx, tx, state.RunID, decidedBy, "info", "approval denied", map[string]any{
+		"approval_id":    state.ApprovalID,
+		"decision_id":    state.DecisionID,
+		"approval_stage": state.stage().Name,
+		"reason":         reason,
+	})
+}
+
+func fetchPolicyApprovalVotes(ctx context.Context, store repo.PolicyStore, approvalID string) ([]policyApprovalVote, error) {
+	records, err := store.ListPolicyApprovalVotes(ctx, approvalID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]policyApprovalVote, 0, len(records))
+	for _, record := range records {
+		out = append(out, policyApprovalVote(record))
+	}
+	return out, nil
+}
@@ -0,0 +1,91 @@
+package experiments
+
+import (
+	"testing"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/policy"
+)
+
+func TestApprovalVoterError(t *testing.T) {
+	state := policyApprovalState{
+		RequestedBy: "alice",
+		Workflow: policy.ApprovalWorkflow{Stages: []policy.ApprovalStage{
+			{Name: "security", Groups: []string{"security"}},
+			{Name: "review"},
+		}},
+	}
+
+	cases := []struct {
+		stage    int
+		identity auth.Identity
+		want     string
+	}{
+		{0, auth.Identity{Subject: "alice", Roles: []string{"security"}}, "approval_requires_second_reviewer"},
+		{0, auth.Identity{Subject: "bob", Roles: []string{auth.RoleAdmin}}, "approval_stage_role_required"},
+		{0, auth.Identity{Subject: "bob", Roles: []string{auth.RoleEditor, "security"}}, ""},
+		{1, auth.Identity{Subject: "carol", Roles: []string{auth.RoleEditor}}, "approval_requires_admin"},
+		{1, auth.Identity{Subject: "carol", Roles: []string{auth.RoleAdmin}}, ""},
+	}
+	for _, tc := range cases {
+		state.CurrentStage = tc.stage
+		if got := approvalVoterError(state, tc.identity); got != tc.want {
+			t.Fatalf("stage %d %+v: got %q, want %q", tc.stage, tc.identity, got, tc.want)
+		}
+	}
+}
+
+func TestApprovalActingFor(t *testing.T) {
+	state := policyApprovalState{RequestedBy: "alice"}
+	if onBehalfOf, ok := approvalActingFor(state, "bob", ""); !ok || onBehalfOf != "" {
+		t.Fatalf("unassigned approval: got %q %v", onBehalfOf, ok)
+	}
+
+	state.AssignedTo = "carol"
+	cases := []struct {
+		subject  string
+		delegate string
+		want     string
+		ok       bool
+	}{
+		{"carol", "", "", true},
+		{"carol", "dave", "", true},
+		{"dave", "dave", "carol", true},
+		{"dave", "", "", false},
+		{"bob", "dave", "", false},
+	}
+	for _, tc := range cases {
+		got, ok := approvalActingFor(state, tc.subject, tc.delegate)
+		if got != tc.want || ok != tc.ok {
+			t.Fatalf("subject %s delegate %q: got %q %v, want %q %v", tc.subject, tc.delegate, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestDecodeApprovalWorkflow(t *testing.T) {
+	for _, raw := range []string{"", "null", `{"stages":[]}`, "not json"} {
+		workflow := decodeApprovalWorkflow([]byte(raw))
+		if len(workflow.Stages) != 1 || workflow.Stages[0].Name != "review" {
+			t.Fatalf("decode %q: expected default workflow, got %+v", raw, workflow)
+		}
+	}
+	workflow := decodeApprovalWorkflow([]byte(`{"stages":[{"name":"security"},{"name":"data-owner","min_approvers":2}]}`))
+	if len(workflow.Stages) != 2 || workflow.Stages[1].Approvers() != 2 {
+		t.Fatalf("unexpected workflow %+v", workflow)
+	}
+}
+
+func TestNewPolicyApprovalSummary(t *testing.T) {
+	escalateAt := time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC)
+	raw := []byte(`{"stages":[{"name":"security"},{"name":"data-owner"}]}`)
+
+	pending := newPolicyApprovalSummary(raw, 1, &escalateAt, policyApprovalSummary{Status: approvalStatusPending})
+	if pending.StageName != "data-owner" || pending.CurrentStage != 1 || pending.EscalateAt == nil {
+		t.Fatalf("unexpected pending summary %+v", pending)
+	}
+	decided := newPolicyApprovalSummary(raw, 1, &escalateAt, policyApprovalSummary{Status: approvalStatusApproved})
+	if decided.EscalateAt != nil {
+		t.Fatalf("decided approvals must not report escalate_at")
+	}
+}
@@ -0,0 +1,514 @@
+package experiments
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/domain"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+	"github.com/animus-labs/animus-go/closed/internal/repo/postgres"
+	"github.com/google/uuid"
+)
+
+const (
+	policyScopeProject      = "project"
+	policyScopeExperiment   = "experiment"
+	policyScopeDatasetClass = "dataset_class"
+
+	maxPolicyScopeIDLen = 256
+)
+
+// policyBinding attaches a policy to a project, an experiment or a dataset
+// classification label. A policy without bindings governs every run; once
+// bound it governs only runs in a bound scope. PolicyVersionID pins the
+// version evaluated for the scope instead of the latest one.
+type policyBinding struct {
+	BindingID       string    `json:"binding_id"`
+	PolicyID        string    `json:"policy_id"`
+	PolicyVersionID string    `json:"policy_version_id,omitempty"`
+	ScopeType       string    `json:"scope_type"`
+	ScopeID         string    `json:"scope_id"`
+	CreatedAt       time.Time `json:"created_at"`
+	CreatedBy       string    `json:"created_by"`
+}
+
+type policyBindingRequest struct {
+	ScopeType       string `json:"scope_type"`
+	ScopeID         string `json:"scope_id"`
+	PolicyVersionID string `json:"policy_version_id,omitempty"`
+}
+
+type policyBindingListResponse struct {
+	Bindings []policyBinding `json:"bindings"`
+}
+
+// policyScope is what a run is evaluated in: its project, its experiment
+// (legacy experiment runs and sweeps) and the classification labels of the
+// dataset version it trains on.
+type policyScope struct {
+	ProjectID      string
+	ExperimentID   string
+	DatasetClasses []string
+}
+
+// rank orders matching bindings by specificity: an experiment binding wins
+// over a project binding, which wins over a dataset class binding.
+func (s policyScope) rank(binding policyBinding) int {
+	switch binding.ScopeType {
+	case policyScopeExperiment:
+		if binding.ScopeID == strings.TrimSpace(s.ExperimentID) && binding.ScopeID != "" {
+			return 3
+		}
+	case policyScopeProject:
+		if binding.ScopeID == strings.TrimSpace(s.ProjectID) && binding.ScopeID != "" {
+			return 2
+		}
+	case policyScopeDatasetClass:
+		for _, label := range s.DatasetClasses {
+			if strings.ToLower(strings.TrimSpace(label)) == binding.ScopeID {
+				return 1
+			}
+		}
+	}
+	return 0
+}
+
+// normalize validates the request and returns the binding to store, or the
+// error code.
+func (req policyBindingRequest) normalize() (policyBinding, string) {
+	out := policyBinding{
+		ScopeType:       strings.ToLower(strings.TrimSpace(req.ScopeType)),
+		ScopeID:         strings.TrimSpace(req.ScopeID),
+		PolicyVersionID: strings.TrimSpace(req.PolicyVersionID),
+	}
+	switch out.ScopeType {
+	case policyScopeProject, policyScopeExperiment:
+	case policyScopeDatasetClass:
+		// Classification labels are stored lowercased by the registry.
+		out.ScopeID = strings.ToLower(out.ScopeID)
+	default:
+		return policyBinding{}, "scope_type_invalid"
+	}
+	if out.ScopeID == "" {
+		return policyBinding{}, "scope_id_required"
+	}
+	if len(out.ScopeID) > maxPolicyScopeIDLen {
+		return policyBinding{}, "scope_id_too_long"
+	}
+	return out, ""
+}
+
+// selectScopedPolicies picks the policy versions governing scope. Unbound
+// policies apply as they are; bound ones apply only through a matching
+// binding, the most specific match deciding between the latest version and
+// a pinned one. pinned holds the pinned versions by policy_version_id.
+func selectScopedPolicies(active []policyVersionRecord, bindings []policyBinding, pinned map[string]policyVersionRecord, scope policyScope) []policyVersionRecord {
+	byPolicy := map[string][]policyBinding{}
+	for _, binding := range bindings {
+		byPolicy[binding.PolicyID] = append(byPolicy[binding.PolicyID], binding)
+	}
+
+	out := make([]policyVersionRecord, 0, len(active))
+	for _, record := range active {
+		bound := byPolicy[record.PolicyID]
+		if len(bound) == 0 {
+			out = append(out, record)
+			continue
+		}
+		best := 0
+		for _, binding := range bound {
+			if rank := scope.rank(binding); rank > best {
+				best = rank
+			}
+		}
+		if best == 0 {
+			continue
+		}
+		// Among equally specific matches an unpinned binding keeps the latest
+		// version; otherwise the highest pinned version applies.
+		var choice *policyVersionRecord
+		for _, binding := range bound {
+			if scope.rank(binding) != best {
+				continue
+			}
+			if binding.PolicyVersionID == "" {
+				choice = &record
+				break
+			}
+			version, ok := pinned[binding.PolicyVersionID]
+			if !ok {
+				continue
+			}
+			if choice == nil || version.Version > choice.Version {
+				choice = &version
+			}
+		}
+		if choice != nil {
+			out = append(out, *choice)
+		}
+	}
+	return out
+}
+
+// loadScopedPolicyVersions returns the policies governing a run in scope:
+// active policies selected through their bindings, narrowed to the project's
+// default policy set when one is configured.
+func (api *experimentsAPI) loadScopedPolicyVersions(ctx context.Context, scope policyScope) ([]policyVersionRecord, error) {
+	settings, err := api.projectSettings(ctx, scope.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	return api.scopedPolicyVersions(ctx, scope, settings)
+}
+
+func (api *experimentsAPI) scopedPolicyVersions(ctx context.Context, scope policyScope, settings domain.ProjectSettings) ([]policyVersionRecord, error) {
+	active, err := api.loadActivePolicyVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(active) == 0 {
+		return active, nil
+	}
+	bindings, err := api.listPolicyBindings(ctx, "", "", "", 0)
+	if err != nil {
+		return nil, err
+	}
+	pinned, err := api.loadPinnedPolicyVersions(ctx, bindings)
+	if err != nil {
+		return nil, err
+	}
+	selected := selectScopedPolicies(active, bindings, pinned, scope)
+	return filterProjectPolicies(selected, settings.DefaultPolicyIDs), nil
+}
+
+func (api *experimentsAPI) loadPinnedPolicyVersions(ctx context.Context, bindings []policyBinding) (map[string]policyVersionRecord, error) {
+	out := map[string]policyVersionRecord{}
+	for _, binding := range bindings {
+		if binding.PolicyVersionID == "" {
+			continue
+		}
+		if _, ok := out[binding.PolicyVersionID]; ok {
+			continue
+		}
+		var record policyVersionRecord
+		err := api.db.QueryRowContext(
+			ctx,
+			`SELECT p.policy_id, p.name, v.policy_version_id, v.version, v.status, v.spec_json, v.spec_sha256
+			 FROM policy_versions v
+			 JOIN policies p ON p.policy_id = v.policy_id
+			 WHERE v.policy_version_id = $1`,
+			binding.PolicyVersionID,
+		).Scan(&record.PolicyID, &record.PolicyName, &record.PolicyVersionID, &record.Version, &record.Status, &record.SpecJSON, &record.SpecSHA256)
+		if err != nil {
+			return nil, err
+		}
+		out[binding.PolicyVersionID] = record
+	}
+	return out, nil
+}
+
+func (api *experimentsAPI) listPolicyBindings(ctx context.Context, policyID, scopeType, scopeID string, limit int) ([]policyBinding, error) {
+	query := `SELECT binding_id, policy_id, policy_version_id, scope_type, scope_id, created_at, created_by
+		FROM policy_bindings`
+	args := []any{}
+	clauses := []string{}
+	if policyID != "" {
+		args = append(args, policyID)
+		clauses = append(clauses, "policy_id = $"+strconv.Itoa(len(args)))
+	}
+	if scopeType != "" {
+		args = append(args, scopeType)
+		clauses = append(clauses, "scope_type = $"+strconv.Itoa(len(args)))
+	}
+	if scopeID != "" {
+		args = append(args, scopeID)
+		clauses = append(clauses, "scope_id = $"+strconv.Itoa(len(args)))
+	}
+	if len(clauses) > 0 {
+		query += " WHERE " + strings.Join(clauses, " AND ")
+	}
+	query += " ORDER BY created_at, binding_id"
+	if limit > 0 {
+		args = append(args, limit)
+		query += " LIMIT $" + strconv.Itoa(len(args))
+	}
+
+	rows, err := api.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []policyBinding{}
+	for rows.Next() {
+		var (
+			binding   policyBinding
+			versionID sql.NullString
+		)
+		if err := rows.Scan(&binding.BindingID, &binding.PolicyID, &versionID, &binding.ScopeType, &binding.ScopeID, &binding.CreatedAt, &binding.CreatedBy); err != nil {
+			return nil, err
+		}
+		binding.PolicyVersionID = versionID.String
+		binding.CreatedAt = binding.CreatedAt.UTC()
+		out = append(out, binding)
+	}
+	return out, rows.Err()
+}
+
+// checkPolicyBindingTarget returns the status and error code for a binding
+// whose policy, pinned version or scope does not exist.
+func (api *experimentsAPI) checkPolicyBindingTarget(ctx context.Context, binding policyBinding) (int, string, error) {
+	var policyExists bool
+	if err := api.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM policies WHERE policy_id = $1)`, binding.PolicyID).Scan(&policyExists); err != nil {
+		return 0, "", err
+	}
+	if !policyExists {
+		return http.StatusNotFound, "not_found", nil
+	}
+	if binding.PolicyVersionID != "" {
+		var versionPolicyID, status string
+		err := api.db.QueryRowContext(ctx, `SELECT policy_id, status FROM policy_versions WHERE policy_version_id = $1`, binding.PolicyVersionID).Scan(&versionPolicyID, &status)
+		if errors.Is(err, sql.ErrNoRows) || (err == nil && versionPolicyID != binding.PolicyID) {
+			return http.StatusNotFound, "policy_version_not_found", nil
+		}
+		if err != nil {
+			return 0, "", err
+		}
+		if status != policyStatusActive {
+			return http.StatusConflict, "policy_version_not_active", nil
+		}
+	}
+	switch binding.ScopeType {
+	case policyScopeProject:
+		if _, err := postgres.NewProjectStore(api.db).Get(ctx, binding.ScopeID); err != nil {
+			if errors.Is(err, repo.ErrNotFound) {
+				return http.StatusNotFound, "project_not_found", nil
+			}
+			return 0, "", err
+		}
+	case policyScopeExperiment:
+		exists, err := api.experimentExists(ctx, binding.ScopeID)
+		if err != nil {
+			return 0, "", err
+		}
+		if !exists {
+			return http.StatusNotFound, "experiment_not_found", nil
+		}
+	}
+	return 0, "", nil
+}
+
+func (api *experimentsAPI) handleCreatePolicyBinding(w http.ResponseWriter, r *http.Request) {
+	identity, ok := auth.IdentityFromContext(r.Context())
+	if !ok || strings.TrimSpace(identity.Subject) == "" {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	policyID := strings.TrimSpace(r.PathValue("policy_id"))
+	if policyID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "policy_id_required")
+		return
+	}
+
+	var req policyBindingRequest
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
+		return
+	}
+	binding, code := req.normalize()
+	if code != "" {
+		api.writeError(w, r, http.StatusBadRequest, code)
+		return
+	}
+	binding.PolicyID = policyID
+	status, code, err := api.checkPolicyBindingTarget(r.Context(), binding)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if code != "" {
+		api.writeError(w, r, status, code)
+		return
+	}
+
+	now := time.Now().UTC()
+	binding.BindingID = uuid.NewString()
+	binding.CreatedAt = now
+	binding.CreatedBy = identity.Subject
+	integrity, err := integritySHA256(binding)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	_, err = tx.ExecContext(
+		r.Context(),
+		`INSERT INTO policy_bindings (
+			binding_id,
+			policy_id,
+			policy_version_id,
+			scope_type,
+			scope_id,
+			created_at,
+			created_by,
+			integrity_sha256
+		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8)`,
+		binding.BindingID,
+		binding.PolicyID,
+		nullString(binding.PolicyVersionID),
+		binding.ScopeType,
+		binding.ScopeID,
+		binding.CreatedAt,
+		binding.CreatedBy,
+		integrity,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			api.writeError(w, r, http.StatusConflict, "policy_binding_exists")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if err := insertPolicyBindingAudit(r, tx, identity, now, "policy.binding.created", binding); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "audit_failed")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	w.Header().Set("Location", "/policies/"+policyID+"/bindings/"+binding.BindingID)
+	api.writeJSON(w, http.StatusCreated, binding)
+}
+
+func (api *experimentsAPI) handleListPolicyBindings(w http.ResponseWriter, r *http.Request) {
+	policyID := strings.TrimSpace(r.PathValue("policy_id"))
+	if policyID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "policy_id_required")
+		return
+	}
+	bindings, err := api.listPolicyBindings(r.Context(), policyID, "", "", httpapi.Limit(r, 100, 500))
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	api.writeJSON(w, http.StatusOK, policyBindingListResponse{Bindings: bindings})
+}
+
+// handleSearchPolicyBindings lists bindings across policies, filtered by
+// scope_type and scope_id: what is attached to a project or experiment.
+func (api *experimentsAPI) handleSearchPolicyBindings(w http.ResponseWriter, r *http.Request) {
+	scopeType := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("scope_type")))
+	scopeID := strings.TrimSpace(r.URL.Query().Get("scope_id"))
+	switch scopeType {
+	case "", policyScopeProject, policyScopeExperiment:
+	case policyScopeDatasetClass:
+		scopeID = strings.ToLower(scopeID)
+	default:
+		api.writeError(w, r, http.StatusBadRequest, "scope_type_invalid")
+		return
+	}
+	bindings, err := api.listPolicyBindings(r.Context(), "", scopeType, scopeID, httpapi.Limit(r, 100, 500))
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	sort.SliceStable(bindings, func(i, j int) bool { return bindings[i].PolicyID < bindings[j].PolicyID })
+	api.writeJSON(w, http.StatusOK, policyBindingListResponse{Bindings: bindings})
+}
+
+func (api *experimentsAPI) handleDeletePolicyBinding(w http.ResponseWriter, r *http.Request) {
+	identity, ok := auth.IdentityFromContext(r.Context())
+	if !ok || strings.TrimSpace(identity.Subject) == "" {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	policyID := strings.TrimSpace(r.PathValue("policy_id"))
+	bindingID := strings.TrimSpace(r.PathValue("binding_id"))
+	if policyID == "" || bindingID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "binding_id_required")
+		return
+	}
+
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var (
+		binding   policyBinding
+		versionID sql.NullString
+	)
+	err = tx.QueryRowContext(
+		r.Context(),
+		`DELETE FROM policy_bindings
+		 WHERE binding_id = $1 AND policy_id = $2
+		 RETURNING binding_id, policy_id, policy_version_id, scope_type, scope_id, created_at, created_by`,
+		bindingID,
+		policyID,
+	).Scan(&binding.BindingID, &binding.PolicyID, &versionID, &binding.ScopeType, &binding.ScopeID, &binding.CreatedAt, &binding.CreatedBy)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	binding.PolicyVersionID = versionID.String
+	if err := insertPolicyBindingAudit(r, tx, identity, time.Now().UTC(), "policy.binding.deleted", binding); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "audit_failed")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	api.writeJSON(w, http.StatusOK, map[string]any{"status": "ok"})
+}
+
+func insertPolicyBindingAudit(r *http.Request, tx *sql.Tx, identity auth.Identity, now time.Time, action string, binding policyBinding) error {
+	payload := map[string]any{
+		"service":           "experiments",
+		"binding_id":        binding.BindingID,
+		"policy_id":         binding.PolicyID,
+		"policy_version_id": binding.PolicyVersionID,
+		"scope_type":        binding.ScopeType,
+		"scope_id":          binding.ScopeID,
+	}
+	if binding.ScopeType == policyScopeProject {
+		payload["project_id"] = binding.ScopeID
+	}
+	err := auditlog.Enqueue(r.Context(), tx, auditlog.Event{
+		OccurredAt:   now,
+		Actor:        identity.Subject,
+		Action:       action,
+		ResourceType: "policy_binding",
+		ResourceID:   binding.BindingID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload:      payload,
+	})
+	return err
+}
@@ -0,0 +1,102 @@
+package experiments
+
+import "testing"
+
+func scopedPolicyIDs(records []policyVersionRecord) map[string]string {
+	out := map[string]string{}
+	for _, record := range records {
+		out[record.PolicyID] = record.PolicyVersionID
+	}
+	return out
+}
+
+func TestSelectScopedPolicies(t *testing.T) {
+	active := []policyVersionRecord{
+		{PolicyID: "pol-global", PolicyVersionID: "pv-global-2", Version: 2},
+		{PolicyID: "pol-proj", PolicyVersionID: "pv-proj-3", Version: 3},
+		{PolicyID: "pol-pii", PolicyVersionID: "pv-pii-1", Version: 1},
+	}
+	pinned := map[string]policyVersionRecord{
+		"pv-proj-1": {PolicyID: "pol-proj", PolicyVersionID: "pv-proj-1", Version: 1},
+		"pv-proj-2": {PolicyID: "pol-proj", PolicyVersionID: "pv-proj-2", Version: 2},
+	}
+	bindings := []policyBinding{
+		{PolicyID: "pol-proj", ScopeType: policyScopeProject, ScopeID: "proj-1", PolicyVersionID: "pv-proj-1"},
+		{PolicyID: "pol-proj", ScopeType: policyScopeExperiment, ScopeID: "exp-1"},
+		{PolicyID: "pol-proj", ScopeType: policyScopeProject, ScopeID: "proj-2", PolicyVersionID: "pv-proj-1"},
+		{PolicyID: "pol-proj", ScopeType: policyScopeProject, ScopeID: "proj-2", PolicyVersionID: "pv-proj-2"},
+		{PolicyID: "pol-pii", ScopeType: policyScopeDatasetClass, ScopeID: "pii"},
+	}
+
+	cases := []struct {
+		name  string
+		scope policyScope
+		want  map[string]string
+	}{
+		{
+			name:  "unbound scope",
+			scope: policyScope{ProjectID: "proj-9"},
+			want:  map[string]string{"pol-global": "pv-global-2"},
+		},
+		{
+			name:  "project pin",
+			scope: policyScope{ProjectID: "proj-1"},
+			want:  map[string]string{"pol-global": "pv-global-2", "pol-proj": "pv-proj-1"},
+		},
+		{
+			name:  "experiment over project",
+			scope: policyScope{ProjectID: "proj-1", ExperimentID: "exp-1"},
+			want:  map[string]string{"pol-global": "pv-global-2", "pol-proj": "pv-proj-3"},
+		},
+		{
+			name:  "highest pin",
+			scope: policyScope{ProjectID: "proj-2"},
+			want:  map[string]string{"pol-global": "pv-global-2", "pol-proj": "pv-proj-2"},
+		},
+		{
+			name:  "dataset class",
+			scope: policyScope{ProjectID: "proj-9", DatasetClasses: []string{" PII "}},
+			want:  map[string]string{"pol-global": "pv-global-2", "pol-pii": "pv-pii-1"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := scopedPolicyIDs(selectScopedPolicies(active, bindings, pinned, tc.scope))
+			if len(got) != len(tc.want) {
+				t.Fatalf("got %v want %v", got, tc.want)
+			}
+			for policyID, versionID := range tc.want {
+				if got[policyID] != versionID {
+					t.Fatalf("policy %s: got %q want %q", policyID, got[policyID], versionID)
+				}
+			}
+		})
+	}
+}
+
+func TestSelectScopedPoliciesSkipsMissingPin(t *testing.T) {
+	active := []policyVersionRecord{{PolicyID: "pol-1", PolicyVersionID: "pv-2", Version: 2}}
+	bindings := []policyBinding{{PolicyID: "pol-1", ScopeType: policyScopeProject, ScopeID: "proj-1", PolicyVersionID: "pv-gone"}}
+	got := selectScopedPolicies(active, bindings, nil, policyScope{ProjectID: "proj-1"})
+	if len(got) != 0 {
+		t.Fatalf("expected no policies, got %+v", got)
+	}
+}
+
+func TestPolicyBindingRequestNormalize(t *testing.T) {
+	binding, code := policyBindingRequest{ScopeType: " Dataset_Class ", ScopeID: " PII "}.normalize()
+	if code != "" {
+		t.Fatalf("unexpected error %s", code)
+	}
+	if binding.ScopeType != policyScopeDatasetClass || binding.ScopeID != "pii" {
+		t.Fatalf("unexpected binding %+v", binding)
+	}
+	for want, req := range map[string]policyBindingRequest{
+		"scope_type_invalid": {ScopeType: "tenant", ScopeID: "t-1"},
+		"scope_id_required":  {ScopeType: policyScopeProject, ScopeID: "  "},
+	} {
+		if _, code := req.normalize(); code != want {
+			t.Fatalf("expected %s, got %q", want, code)
+		}
+	}
+}
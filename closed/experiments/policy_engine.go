@@ -1,4 +1,4 @@
-package main
+package experiments
 
 import (
 	"context"
@@ -8,6 +8,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/animus-labs/animus-go/closed/internal/domain"
+	"github.com/animus-labs/animus-go/closed/internal/integrations/eventbus"
 	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
 	"github.com/animus-labs/animus-go/closed/internal/platform/policy"
 	"github.com/google/uuid"
@@ -33,12 +35,16 @@ type policyEvaluation struct {
 }
 
 type executionPolicyInput struct {
+	ProjectID         string
 	RunID             string
 	Actor             policy.ActorContext
 	ExperimentID      string
 	DatasetID         string
 	DatasetVersionID  string
 	DatasetSHA256     string
+	DatasetAgeDays    *float64
+	DatasetStale      *bool
+	DatasetProtection *domain.DataProtection
 	GitRepo           string
 	GitCommit         string
 	GitRef            string
@@ -55,8 +61,14 @@ type executionPolicyResult struct {
 	Evaluations   []policyEvaluation
 }
 
+// evaluateExecutionPolicy evaluates the policies bound to the run's project,
+// experiment and dataset classes (see selectScopedPolicies).
 func (api *experimentsAPI) evaluateExecutionPolicy(ctx context.Context, input executionPolicyInput) (executionPolicyResult, error) {
-	policies, err := api.loadActivePolicyVersions(ctx)
+	scope := policyScope{ProjectID: input.ProjectID, ExperimentID: input.ExperimentID}
+	if input.DatasetProtection != nil {
+		scope.DatasetClasses = input.DatasetProtection.ClassificationLabels
+	}
+	policies, err := api.loadScopedPolicyVersions(ctx, scope)
 	if err != nil {
 		return executionPolicyResult{}, err
 	}
@@ -71,6 +83,8 @@ func (api *experimentsAPI) evaluateExecutionPolicy(ctx context.Context, input ex
 			DatasetID: strings.TrimSpace(input.DatasetID),
 			VersionID: strings.TrimSpace(input.DatasetVersionID),
 			SHA256:    strings.TrimSpace(input.DatasetSHA256),
+			AgeDays:   input.DatasetAgeDays,
+			Stale:     input.DatasetStale,
 		},
 		Experiment: policy.ExperimentContext{
 			ExperimentID: strings.TrimSpace(input.ExperimentID),
@@ -99,6 +113,10 @@ func (api *experimentsAPI) evaluateExecutionPolicy(ctx context.Context, input ex
 		context.Meta["gitlab"] = gitlabMeta
 	}
 
+	if input.DatasetProtection != nil {
+		applyDataProtection(&context.Dataset, *input.DatasetProtection)
+	}
+
 	contextJSON, err := json.Marshal(context)
 	if err != nil {
 		return executionPolicyResult{}, err
@@ -111,7 +129,7 @@ func (api *experimentsAPI) evaluateExecutionPolicy(ctx context.Context, input ex
 		if err := json.Unmarshal(record.SpecJSON, &spec); err != nil {
 			return executionPolicyResult{}, err
 		}
-		decision, err := policy.Evaluate(spec, context)
+		decision, err := api.policyEvaluator.Evaluate(ctx, spec, context)
 		if err != nil {
 			return executionPolicyResult{}, err
 		}
@@ -133,6 +151,46 @@ func (api *experimentsAPI) evaluateExecutionPolicy(ctx context.Context, input ex
 	}, nil
 }
 
+// runExecutionPolicyInput is the policy input of a run being created; the
+// first dataset is the one policies see, as in experiment_runs.
+func runExecutionPolicyInput(identity auth.Identity, run experimentRunInsert) executionPolicyInput {
+	input := executionPolicyInput{
+		ProjectID: run.ProjectID,
+		RunID:     run.RunID,
+		Actor: policy.ActorContext{
+			Subject: identity.Subject,
+			Email:   identity.Email,
+			Roles:   identity.Roles,
+		},
+		ExperimentID:     run.ExperimentID,
+		DatasetVersionID: run.DatasetVersionID,
+		GitRepo:          run.GitRepo,
+		GitCommit:        run.GitCommit,
+		GitRef:           run.GitRef,
+	}
+	if len(run.Datasets) > 0 {
+		gate := run.Datasets[0].Gate
+		input.DatasetID = gate.DatasetID
+		input.DatasetSHA256 = gate.ContentSHA256
+		input.DatasetProtection = &gate.Protection
+	}
+	return input
+}
+
+// recordExecutionPolicy stores the decisions evaluated for a new run in its
+// creating tx. When they require approval it also opens the pending
+// approvals that hold the run back; their ids are returned.
+func (api *experimentsAPI) recordExecutionPolicy(ctx context.Context, tx *sql.Tx, runID string, actor auth.Identity, result executionPolicyResult) ([]string, error) {
+	decisions, err := api.insertPolicyDecisions(ctx, tx, runID, actor, result.ContextJSON, result.ContextSHA256, result.Evaluations)
+	if err != nil {
+		return nil, err
+	}
+	if aggregatePolicyDecision(result.Evaluations) != policy.EffectRequireApproval {
+		return nil, nil
+	}
+	return api.insertPolicyApprovals(ctx, tx, runID, actor, decisions)
+}
+
 func (api *experimentsAPI) loadActivePolicyVersions(ctx context.Context) ([]policyVersionRecord, error) {
 	rows, err := api.db.QueryContext(
 		ctx,
@@ -204,6 +262,7 @@ type insertedPolicyDecision struct {
 	PolicyVersionID string
 	Effect          string
 	RuleID          string
+	Approval        *policy.ApprovalWorkflow
 }
 
 func (api *experimentsAPI) insertPolicyDecisions(ctx context.Context, tx *sql.Tx, runID string, actor auth.Identity, contextJSON []byte, contextSHA256 string, evaluations []policyEvaluation) ([]insertedPolicyDecision, error) {
@@ -221,6 +280,20 @@ func (api *experimentsAPI) insertPolicyDecisions(ctx context.Context, tx *sql.Tx
 		runIDValue = sql.NullString{String: runID, Valid: true}
 	}
 
+	var projectID string
+	if api.bus.Enabled {
+		projectID, _ = auth.ProjectIDFromContext(ctx)
+		if runID != "" {
+			var runProject sql.NullString
+			if err := tx.QueryRowContext(ctx, `SELECT project_id FROM experiment_runs WHERE run_id = $1`, runID).Scan(&runProject); err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return nil, err
+			}
+			if runProject.Valid {
+				projectID = runProject.String
+			}
+		}
+	}
+
 	out := make([]insertedPolicyDecision, 0, len(evaluations))
 	for _, evaluation := range evaluations {
 		decisionID := uuid.NewString()
@@ -230,6 +303,7 @@ func (api *experimentsAPI) insertPolicyDecisions(ctx context.Context, tx *sql.Tx
 		}
 		ruleID := strings.TrimSpace(evaluation.Decision.RuleID)
 		reason := strings.TrimSpace(evaluation.Decision.Reason)
+		bundleRevision := strings.TrimSpace(evaluation.Decision.BundleRevision)
 		if contextJSON == nil {
 			contextJSON = []byte("{}")
 		}
@@ -246,6 +320,7 @@ func (api *experimentsAPI) insertPolicyDecisions(ctx context.Context, tx *sql.Tx
 			Decision        string          `json:"decision"`
 			RuleID          string          `json:"rule_id,omitempty"`
 			Reason          string          `json:"reason,omitempty"`
+			BundleRevision  string          `json:"bundle_revision,omitempty"`
 			CreatedAt       time.Time       `json:"created_at"`
 			CreatedBy       string          `json:"created_by"`
 		}
@@ -260,6 +335,7 @@ func (api *experimentsAPI) insertPolicyDecisions(ctx context.Context, tx *sql.Tx
 			Decision:        decision,
 			RuleID:          ruleID,
 			Reason:          reason,
+			BundleRevision:  bundleRevision,
 			CreatedAt:       now,
 			CreatedBy:       actor.Subject,
 		})
@@ -282,8 +358,9 @@ func (api *experimentsAPI) insertPolicyDecisions(ctx context.Context, tx *sql.Tx
 				reason,
 				created_at,
 				created_by,
-				integrity_sha256
-			) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13)`,
+				integrity_sha256,
+				bundle_revision
+			) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14)`,
 			decisionID,
 			runIDValue,
 			evaluation.PolicyID,
@@ -297,10 +374,22 @@ func (api *experimentsAPI) insertPolicyDecisions(ctx context.Context, tx *sql.Tx
 			now,
 			actor.Subject,
 			integrity,
+			nullString(bundleRevision),
 		)
 		if err != nil {
 			return nil, err
 		}
+		if err := api.bus.Enqueue(ctx, tx, eventbus.EventPolicyDecisionRecorded, decisionID, projectID, now,
+			map[string]string{"policy_decision_id": decisionID, "policy_id": evaluation.PolicyID, "run_id": runID},
+			map[string]any{
+				"decision":          decision,
+				"rule_id":           ruleID,
+				"policy_name":       evaluation.PolicyName,
+				"policy_version_id": evaluation.PolicyVersionID,
+			},
+		); err != nil {
+			return nil, err
+		}
 
 		out = append(out, insertedPolicyDecision{
 			DecisionID:      decisionID,
@@ -309,6 +398,7 @@ func (api *experimentsAPI) insertPolicyDecisions(ctx context.Context, tx *sql.Tx
 			PolicyVersionID: evaluation.PolicyVersionID,
 			Effect:          decision,
 			RuleID:          ruleID,
+			Approval:        evaluation.Decision.Approval,
 		})
 	}
 
@@ -331,6 +421,18 @@ func (api *experimentsAPI) insertPolicyApprovals(ctx context.Context, tx *sql.Tx
 			continue
 		}
 		approvalID := uuid.NewString()
+		workflow := policy.DefaultApprovalWorkflow()
+		if decision.Approval != nil {
+			workflow = *decision.Approval
+		}
+		workflowJSON, err := json.Marshal(workflow)
+		if err != nil {
+			return nil, err
+		}
+		var escalateAt sql.NullTime
+		if at, ok := workflow.Stages[0].EscalatesAt(now); ok {
+			escalateAt = sql.NullTime{Time: at, Valid: true}
+		}
 
 		type integrityInput struct {
 			ApprovalID  string    `json:"approval_id"`
@@ -361,8 +463,12 @@ func (api *experimentsAPI) insertPolicyApprovals(ctx context.Context, tx *sql.Tx
 				status,
 				requested_at,
 				requested_by,
-				integrity_sha256
-			) VALUES ($1,$2,$3,$4,$5,$6,$7)`,
+				integrity_sha256,
+				workflow,
+				current_stage,
+				stage_started_at,
+				escalate_at
+			) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,0,$5,$9)`,
 			approvalID,
 			decision.DecisionID,
 			runID,
@@ -370,6 +476,8 @@ func (api *experimentsAPI) insertPolicyApprovals(ctx context.Context, tx *sql.Tx
 			now,
 			actor.Subject,
 			integrity,
+			workflowJSON,
+			escalateAt,
 		)
 		if err != nil {
 			return nil, err
@@ -1,4 +1,4 @@
-package main
+package experiments
 
 import (
 	"errors"
@@ -8,6 +8,7 @@ import (
 
 	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
 	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
 	"github.com/animus-labs/animus-go/closed/internal/repo"
 	"github.com/animus-labs/animus-go/closed/internal/repo/postgres"
 )
@@ -29,11 +30,18 @@ func (api *experimentsAPI) handleGetRunPolicySnapshot(w http.ResponseWriter, r *
 		return
 	}
 
-	store := postgres.NewRunBindingsStore(api.db)
-	if store == nil {
+	if api.db == nil {
 		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
 		return
 	}
+	// The read is audited in the transaction it is made in.
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+	store := postgres.NewRunBindingsStore(tx)
 	snapshot, err := store.GetPolicySnapshot(r.Context(), projectID, runID)
 	if err != nil {
 		if errors.Is(err, repo.ErrNotFound) {
@@ -44,14 +52,14 @@ func (api *experimentsAPI) handleGetRunPolicySnapshot(w http.ResponseWriter, r *
 		return
 	}
 
-	if _, err := auditlog.Insert(r.Context(), api.db, auditlog.Event{
+	if err := auditlog.Enqueue(r.Context(), tx, auditlog.Event{
 		OccurredAt:   time.Now().UTC(),
 		Actor:        identity.Subject,
 		Action:       "policy.snapshot.read",
 		ResourceType: "policy_snapshot",
 		ResourceID:   snapshot.SnapshotSHA256,
 		RequestID:    r.Header.Get("X-Request-Id"),
-		IP:           requestIP(r.RemoteAddr),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
 		UserAgent:    r.UserAgent(),
 		Payload: map[string]any{
 			"service":         "experiments",
@@ -63,6 +71,10 @@ func (api *experimentsAPI) handleGetRunPolicySnapshot(w http.ResponseWriter, r *
 		api.writeError(w, r, http.StatusInternalServerError, "audit_failed")
 		return
 	}
+	if err := tx.Commit(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
 
 	api.writeJSON(w, http.StatusOK, snapshot)
 }
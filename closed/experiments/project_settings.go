@@ -0,0 +1,61 @@
+package experiments
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/animus-labs/animus-go/closed/internal/domain"
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+	"github.com/animus-labs/animus-go/closed/internal/repo/postgres"
+)
+
+// requireActiveProject refuses new runs in projects archived through the
+// dataset registry with 409 project_archived.
+func (api *experimentsAPI) requireActiveProject(w http.ResponseWriter, r *http.Request, projectID string) bool {
+	project, err := postgres.NewProjectStore(api.db).Get(r.Context(), projectID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			api.writeError(w, r, http.StatusNotFound, "project_not_found")
+			return false
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return false
+	}
+	if project.Archived() {
+		api.writeError(w, r, http.StatusConflict, "project_archived")
+		return false
+	}
+	return true
+}
+
+func (api *experimentsAPI) projectSettings(ctx context.Context, projectID string) (domain.ProjectSettings, error) {
+	return postgres.NewProjectSettingsStore(api.db).Get(ctx, projectID)
+}
+
+func filterProjectPolicies(policies []policyVersionRecord, policyIDs []string) []policyVersionRecord {
+	if len(policyIDs) == 0 {
+		return policies
+	}
+	allowed := make(map[string]bool, len(policyIDs))
+	for _, id := range policyIDs {
+		allowed[strings.TrimSpace(id)] = true
+	}
+	out := make([]policyVersionRecord, 0, len(policies))
+	for _, record := range policies {
+		if allowed[strings.TrimSpace(record.PolicyID)] {
+			out = append(out, record)
+		}
+	}
+	return out
+}
+
+// projectSnapshotRetention records the project artifact retention in run
+// policy snapshots.
+func projectSnapshotRetention(settings domain.ProjectSettings) domain.PolicySnapshotRetention {
+	if settings.ArtifactRetentionDays <= 0 {
+		return domain.PolicySnapshotRetention{Mode: "not_configured"}
+	}
+	return domain.PolicySnapshotRetention{Mode: "project_default", ArtifactRetentionDays: settings.ArtifactRetentionDays}
+}
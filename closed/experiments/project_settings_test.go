@@ -0,0 +1,29 @@
+package experiments
+
+import (
+	"testing"
+
+	"github.com/animus-labs/animus-go/closed/internal/domain"
+)
+
+func TestFilterProjectPolicies(t *testing.T) {
+	policies := []policyVersionRecord{{PolicyID: "pol-a"}, {PolicyID: "pol-b"}, {PolicyID: "pol-c"}}
+
+	if got := filterProjectPolicies(policies, nil); len(got) != 3 {
+		t.Fatalf("expected all policies without a default set, got %d", len(got))
+	}
+	got := filterProjectPolicies(policies, []string{"pol-c", "pol-a", "pol-missing"})
+	if len(got) != 2 || got[0].PolicyID != "pol-a" || got[1].PolicyID != "pol-c" {
+		t.Fatalf("unexpected policies: %+v", got)
+	}
+}
+
+func TestProjectSnapshotRetention(t *testing.T) {
+	if got := projectSnapshotRetention(domain.ProjectSettings{}); got.Mode != "not_configured" {
+		t.Fatalf("expected not_configured, got %+v", got)
+	}
+	got := projectSnapshotRetention(domain.ProjectSettings{ArtifactRetentionDays: 30})
+	if got.Mode != "project_default" || got.ArtifactRetentionDays != 30 {
+		t.Fatalf("unexpected retention: %+v", got)
+	}
+}
@@ -1,4 +1,4 @@
-package main
+package experiments
 
 import (
 	"context"
@@ -13,18 +13,76 @@ import (
 func experimentsRequiredRole(r *http.Request) string {
 	path := strings.TrimSpace(r.URL.Path)
 	switch {
-	case strings.HasPrefix(path, "/policies"), strings.HasPrefix(path, "/policy-decisions"), strings.HasPrefix(path, "/policy-approvals"):
+	case r.Method == http.MethodPost && strings.HasPrefix(path, "/policy-approvals/") && (strings.HasSuffix(path, "/approve") || strings.HasSuffix(path, "/deny")):
+		// Approval stages may name non-admin reviewers (data owners); the
+		// handler checks stage roles, or admin for stages without them.
+		return auth.RoleEditor
+	case strings.HasPrefix(path, "/policies"), strings.HasPrefix(path, "/policy-bindings"), strings.HasPrefix(path, "/policy-decisions"), strings.HasPrefix(path, "/policy-approvals"):
 		return auth.RoleAdmin
-	case strings.Contains(path, "/role-bindings"):
+	case strings.Contains(path, "/role-bindings"), strings.Contains(path, "/members"), strings.HasPrefix(path, "/rbac/"), strings.HasPrefix(path, "/admin/"):
 		return auth.RoleAdmin
 	case strings.Contains(path, "/webhooks"):
 		return auth.RoleAdmin
 	case strings.Contains(path, "/model-versions/") && (strings.HasSuffix(path, ":approve") || strings.HasSuffix(path, ":deprecate") || strings.HasSuffix(path, ":export")):
 		return auth.RoleAdmin
+	case strings.HasPrefix(path, "/experiments/") && strings.HasSuffix(path, ":export"):
+		return auth.RoleAdmin
 	}
 	return rbac.RequiredRoleFromRequest(r)
 }
 
+// experimentsAuditorRoutes are the read-only routes open to the auditor role:
+// experiment and run metadata, tags and searches, evidence bundles, the
+// execution ledger, and policies. Artifacts, dev environments, webhooks, and
+// RBAC stay closed.
+var experimentsAuditorRoutes = rbac.AuditorRoutes(
+	"/experiments",
+	"/experiments/*",
+	"/experiments/*/runs",
+	"/experiments/*/leaderboard",
+	"/experiments/*/import",
+	"/sweeps/*",
+	"/experiments/*/schedules",
+	"/schedules/*",
+	"/schedules/*/firings",
+	"/projects/*/run-templates",
+	"/run-templates/*",
+	"/tags",
+	"/tags/**",
+	"/search",
+	"/saved-searches",
+	"/saved-searches/**",
+	"/experiment-runs",
+	"/experiment-runs/*",
+	"/experiment-runs/*/metrics",
+	"/experiment-runs/*/metric-imports",
+	"/experiment-runs/*/metric-imports/*",
+	"/experiment-runs/*/events",
+	"/experiment-runs/*/execution",
+	"/experiment-runs/*/config-manifest",
+	"/experiment-runs/*/column-lineage",
+	"/experiment-runs/*/evidence-bundles/**",
+	"/evidence-signing-keys",
+	"/execution-ledger/**",
+	"/projects/*/runs/*",
+	"/projects/*/runs/*/policy-snapshot",
+	"/projects/*/runs/*/reproducibility-bundle",
+	"/projects/*/model-versions/*/provenance",
+	"/policies/**",
+	"/policy-bindings",
+	"/policy-decisions/**",
+	"/policy-approvals/**",
+	"/policy-approval-delegations",
+)
+
+func experimentsAuditorScope(r *http.Request) bool {
+	// Action routes such as {run_id}:plan compute rather than read.
+	if strings.Contains(r.URL.Path, ":") {
+		return false
+	}
+	return experimentsAuditorRoutes(r)
+}
+
 func experimentsProjectResolver(db *sql.DB) auth.ProjectResolver {
 	return func(r *http.Request, identity auth.Identity) (string, error) {
 		path := strings.TrimSpace(r.URL.Path)
@@ -32,11 +90,15 @@ func experimentsProjectResolver(db *sql.DB) auth.ProjectResolver {
 			return "", auth.ErrProjectRequired
 		}
 
-		if strings.HasPrefix(path, "/policies") || strings.HasPrefix(path, "/policy-decisions") || strings.HasPrefix(path, "/policy-approvals") ||
-			strings.HasPrefix(path, "/model-images") || strings.HasPrefix(path, "/ci/") || strings.HasPrefix(path, "/gitlab/") {
+		if strings.HasPrefix(path, "/policies") || strings.HasPrefix(path, "/policy-bindings") || strings.HasPrefix(path, "/policy-decisions") || strings.HasPrefix(path, "/policy-approvals") ||
+			strings.HasPrefix(path, "/policy-approval-delegations") || strings.HasPrefix(path, "/model-images") || strings.HasPrefix(path, "/ci/") || strings.HasPrefix(path, "/gitlab/") ||
+			strings.HasPrefix(path, "/rbac/") || strings.HasPrefix(path, "/admin/") || path == "/evidence-signing-keys" {
 			return "", nil
 		}
 
+		if projectID := mlflowProjectID(path); projectID != "" {
+			return projectID, nil
+		}
 		if projectID := auth.ProjectIDFromRequest(r); projectID != "" {
 			return projectID, nil
 		}
@@ -45,6 +107,8 @@ func experimentsProjectResolver(db *sql.DB) auth.ProjectResolver {
 			return projectIDForRun(r.Context(), db, runID)
 		}
 		if experimentID := strings.TrimSpace(r.PathValue("experiment_id")); experimentID != "" {
+			// {experiment_id}:export arrives as one path value.
+			experimentID, _, _ = strings.Cut(experimentID, ":")
 			return projectIDForExperiment(r.Context(), db, experimentID)
 		}
 		if runID := strings.TrimSpace(r.URL.Query().Get("run_id")); runID != "" {
@@ -53,6 +117,15 @@ func experimentsProjectResolver(db *sql.DB) auth.ProjectResolver {
 		if sessionID := strings.TrimSpace(r.PathValue("session_id")); sessionID != "" {
 			return projectIDForDevEnvSession(r.Context(), db, sessionID)
 		}
+		if sweepID := strings.TrimSpace(r.PathValue("sweep_id")); sweepID != "" {
+			return projectIDForSweep(r.Context(), db, sweepID)
+		}
+		if scheduleID := strings.TrimSpace(r.PathValue("schedule_id")); scheduleID != "" {
+			return projectIDForRunSchedule(r.Context(), db, scheduleID)
+		}
+		if templateID := strings.TrimSpace(r.PathValue("template_id")); templateID != "" {
+			return projectIDForRunTemplate(r.Context(), db, templateID)
+		}
 
 		return "", auth.ErrProjectRequired
 	}
@@ -93,3 +166,39 @@ func projectIDForDevEnvSession(ctx context.Context, db *sql.DB, sessionID string
 	}
 	return strings.TrimSpace(projectID.String), nil
 }
+
+func projectIDForSweep(ctx context.Context, db *sql.DB, sweepID string) (string, error) {
+	if db == nil {
+		return "", auth.ErrProjectRequired
+	}
+	row := db.QueryRowContext(ctx, `SELECT project_id FROM experiment_sweeps WHERE sweep_id = $1`, strings.TrimSpace(sweepID))
+	var projectID sql.NullString
+	if err := row.Scan(&projectID); err != nil {
+		return "", auth.ErrProjectRequired
+	}
+	return strings.TrimSpace(projectID.String), nil
+}
+
+func projectIDForRunSchedule(ctx context.Context, db *sql.DB, scheduleID string) (string, error) {
+	if db == nil {
+		return "", auth.ErrProjectRequired
+	}
+	row := db.QueryRowContext(ctx, `SELECT project_id FROM experiment_run_schedules WHERE schedule_id = $1`, strings.TrimSpace(scheduleID))
+	var projectID sql.NullString
+	if err := row.Scan(&projectID); err != nil {
+		return "", auth.ErrProjectRequired
+	}
+	return strings.TrimSpace(projectID.String), nil
+}
+
+func projectIDForRunTemplate(ctx context.Context, db *sql.DB, templateID string) (string, error) {
+	if db == nil {
+		return "", auth.ErrProjectRequired
+	}
+	row := db.QueryRowContext(ctx, `SELECT project_id FROM run_templates WHERE template_id = $1`, strings.TrimSpace(templateID))
+	var projectID sql.NullString
+	if err := row.Scan(&projectID); err != nil {
+		return "", auth.ErrProjectRequired
+	}
+	return strings.TrimSpace(projectID.String), nil
+}
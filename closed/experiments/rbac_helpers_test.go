@@ -1,4 +1,4 @@
-package main
+package experiments
 
 import (
 	"net/http"
@@ -20,6 +20,7 @@ func TestExperimentsRequiredRoleModelTransitions(t *testing.T) {
 		"/projects/proj-1/model-versions/ver-1:approve",
 		"/projects/proj-1/model-versions/ver-1:deprecate",
 		"/projects/proj-1/model-versions/ver-1:export",
+		"/experiments/exp-1:export",
 	}
 	for _, path := range paths {
 		req := httptest.NewRequest(http.MethodPost, path, nil)
@@ -34,4 +35,83 @@ func TestExperimentsRequiredRoleRoleBindings(t *testing.T) {
 	if got := experimentsRequiredRole(req); got != auth.RoleAdmin {
 		t.Fatalf("expected admin role, got %s", got)
 	}
+	for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodDelete} {
+		req := httptest.NewRequest(method, "/projects/proj-1/members", nil)
+		if got := experimentsRequiredRole(req); got != auth.RoleAdmin {
+			t.Fatalf("%s members: expected admin role, got %s", method, got)
+		}
+	}
+}
+
+func TestExperimentsRequiredRolePolicyApprovals(t *testing.T) {
+	for method, path := range map[string]string{
+		http.MethodPost: "/policy-approvals/appr-1/approve",
+		http.MethodGet:  "/policy-approvals/appr-1",
+	} {
+		want := auth.RoleAdmin
+		if method == http.MethodPost {
+			want = auth.RoleEditor
+		}
+		req := httptest.NewRequest(method, path, nil)
+		if got := experimentsRequiredRole(req); got != want {
+			t.Fatalf("%s %s expected %s role, got %s", method, path, want, got)
+		}
+	}
+	req := httptest.NewRequest(http.MethodPost, "/policy-approvals/appr-1/deny", nil)
+	if got := experimentsRequiredRole(req); got != auth.RoleEditor {
+		t.Fatalf("expected editor role for deny, got %s", got)
+	}
+	req = httptest.NewRequest(http.MethodPost, "/policy-approvals/appr-1/reassign", nil)
+	if got := experimentsRequiredRole(req); got != auth.RoleAdmin {
+		t.Fatalf("expected admin role for reassign, got %s", got)
+	}
+	req = httptest.NewRequest(http.MethodPost, "/policy-approval-delegations", nil)
+	if got := experimentsRequiredRole(req); got != auth.RoleEditor {
+		t.Fatalf("expected editor role for delegations, got %s", got)
+	}
+}
+
+func TestExperimentsRequiredRolePolicyBindings(t *testing.T) {
+	for method, path := range map[string]string{
+		http.MethodPost:   "/policies/pol-1/bindings",
+		http.MethodGet:    "/policy-bindings",
+		http.MethodDelete: "/policies/pol-1/bindings/bind-1",
+	} {
+		req := httptest.NewRequest(method, path, nil)
+		if got := experimentsRequiredRole(req); got != auth.RoleAdmin {
+			t.Fatalf("%s %s expected admin role, got %s", method, path, got)
+		}
+	}
+}
+
+func TestExperimentsRequiredRoleAdminBootstrap(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/admin/bootstrap", nil)
+	if got := experimentsRequiredRole(req); got != auth.RoleAdmin {
+		t.Fatalf("expected admin role, got %s", got)
+	}
+}
+
+func TestExperimentsAuditorScope(t *testing.T) {
+	for path, want := range map[string]bool{
+		"/experiment-runs/run-1":                             true,
+		"/experiment-runs/run-1/evidence-bundles/b-1/report": true,
+		"/execution-ledger/run-1":                            true,
+		"/evidence-signing-keys":                             true,
+		"/policy-decisions/d-1":                              true,
+		"/policy-approval-delegations":                       true,
+		"/policies/pol-1/bindings":                           true,
+		"/policy-bindings":                                   true,
+		"/projects/proj-1/runs/run-1/policy-snapshot":        true,
+		"/experiment-runs/run-1/artifacts/a-1/download":      false,
+		"/projects/proj-1/runs/run-1:plan":                   false,
+		"/projects/proj-1/role-bindings":                     false,
+		"/projects/proj-1/webhooks/deliveries":               false,
+		"/projects/proj-1/dev-environments":                  false,
+		"/rbac/roles":                                        false,
+	} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if got := experimentsAuditorScope(req); got != want {
+			t.Fatalf("path %s: auditor scope=%v want %v", path, got, want)
+		}
+	}
 }
@@ -0,0 +1,496 @@
+package experiments
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/domain"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	"github.com/animus-labs/animus-go/closed/internal/platform/rbac"
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+	repopg "github.com/animus-labs/animus-go/closed/internal/repo/postgres"
+)
+
+var rbacRouteServices = map[string]struct{}{
+	"experiments":      {},
+	"dataset-registry": {},
+	"lineage":          {},
+	"audit":            {},
+}
+
+type rbacRole struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Permissions []string  `json:"permissions"`
+	CreatedAt   time.Time `json:"created_at"`
+	CreatedBy   string    `json:"created_by"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	UpdatedBy   string    `json:"updated_by"`
+}
+
+type rbacRoleRequest struct {
+	Name        string   `json:"name,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Permissions []string `json:"permissions"`
+}
+
+type rbacRoleListResponse struct {
+	Roles []rbacRole `json:"roles"`
+}
+
+type rbacRoutePermission struct {
+	RouteID     string    `json:"route_id"`
+	Service     string    `json:"service"`
+	Method      string    `json:"method"`
+	PathPattern string    `json:"path_pattern"`
+	Permission  string    `json:"permission"`
+	CreatedAt   time.Time `json:"created_at"`
+	CreatedBy   string    `json:"created_by"`
+}
+
+type rbacRoutePermissionRequest struct {
+	Service     string `json:"service"`
+	Method      string `json:"method"`
+	PathPattern string `json:"path_pattern"`
+	Permission  string `json:"permission"`
+}
+
+type rbacRoutePermissionListResponse struct {
+	Routes []rbacRoutePermission `json:"routes"`
+}
+
+type rbacPermissionStore interface {
+	CreateRole(ctx context.Context, record repo.RBACRoleRecord) (repo.RBACRoleRecord, error)
+	UpdateRole(ctx context.Context, record repo.RBACRoleRecord) (repo.RBACRoleRecord, error)
+	GetRole(ctx context.Context, name string) (repo.RBACRoleRecord, error)
+	ListRoles(ctx context.Context) ([]repo.RBACRoleRecord, error)
+	DeleteRole(ctx context.Context, name string) error
+	CreateRoutePermission(ctx context.Context, record repo.RBACRoutePermissionRecord) (repo.RBACRoutePermissionRecord, error)
+	ListRoutePermissions(ctx context.Context, service string) ([]repo.RBACRoutePermissionRecord, error)
+	DeleteRoutePermission(ctx context.Context, routeID string) error
+}
+
+func (api *experimentsAPI) rbacPermissionStore() rbacPermissionStore {
+	if api == nil {
+		return nil
+	}
+	if api.rbacPermissionStoreOverride != nil {
+		return api.rbacPermissionStoreOverride
+	}
+	if api.db == nil {
+		return nil
+	}
+	return repopg.NewRBACPermissionStore(api.db)
+}
+
+func (api *experimentsAPI) customRoleExists(ctx context.Context, role string) bool {
+	store := api.rbacPermissionStore()
+	if store == nil {
+		return false
+	}
+	_, err := store.GetRole(ctx, role)
+	return err == nil
+}
+
+func (api *experimentsAPI) handleListRBACRoles(w http.ResponseWriter, r *http.Request) {
+	store := api.rbacPermissionStore()
+	if store == nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	records, err := store.ListRoles(r.Context())
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	out := make([]rbacRole, 0, len(records))
+	for _, record := range records {
+		out = append(out, rbacRoleFromRecord(record))
+	}
+	api.writeJSON(w, http.StatusOK, rbacRoleListResponse{Roles: out})
+}
+
+func (api *experimentsAPI) handleGetRBACRole(w http.ResponseWriter, r *http.Request) {
+	name := strings.ToLower(strings.TrimSpace(r.PathValue("role_name")))
+	if name == "" {
+		api.writeError(w, r, http.StatusBadRequest, "role_name_required")
+		return
+	}
+	store := api.rbacPermissionStore()
+	if store == nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	record, err := store.GetRole(r.Context(), name)
+	if err != nil {
+		api.writeRepoError(w, r, err)
+		return
+	}
+	api.writeJSON(w, http.StatusOK, rbacRoleFromRecord(record))
+}
+
+func (api *experimentsAPI) handleCreateRBACRole(w http.ResponseWriter, r *http.Request) {
+	identity, ok := auth.IdentityFromContext(r.Context())
+	if !ok || strings.TrimSpace(identity.Subject) == "" {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	var req rbacRoleRequest
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
+		return
+	}
+	name := strings.ToLower(strings.TrimSpace(req.Name))
+	if name == "" {
+		api.writeError(w, r, http.StatusBadRequest, "role_name_required")
+		return
+	}
+	if !rbac.ValidRoleName(name) {
+		api.writeError(w, r, http.StatusBadRequest, "role_name_invalid")
+		return
+	}
+	permissions, ok := normalizeRBACPermissions(req.Permissions)
+	if !ok {
+		api.writeError(w, r, http.StatusBadRequest, "permission_invalid")
+		return
+	}
+
+	store := api.rbacPermissionStore()
+	if store == nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	now := time.Now().UTC()
+	record := repo.RBACRoleRecord{
+		Name:        name,
+		Description: strings.TrimSpace(req.Description),
+		Permissions: permissions,
+		CreatedAt:   now,
+		CreatedBy:   identity.Subject,
+		UpdatedAt:   now,
+		UpdatedBy:   identity.Subject,
+	}
+	integrity, err := integritySHA256(rbacRoleFromRecord(record))
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	record.IntegritySHA = integrity
+
+	created, err := store.CreateRole(r.Context(), record)
+	if err != nil {
+		if isUniqueViolation(err) {
+			api.writeError(w, r, http.StatusConflict, "role_exists")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "role_create_failed")
+		return
+	}
+	api.invalidatePermissionCache()
+	api.appendRBACAuditEvent(r, "rbac.role_created", "rbac_role", created.Name, identity.Subject, map[string]any{
+		"role_name":   created.Name,
+		"permissions": created.Permissions,
+	})
+
+	w.Header().Set("Location", "/rbac/roles/"+created.Name)
+	api.writeJSON(w, http.StatusCreated, rbacRoleFromRecord(created))
+}
+
+func (api *experimentsAPI) handleUpdateRBACRole(w http.ResponseWriter, r *http.Request) {
+	identity, ok := auth.IdentityFromContext(r.Context())
+	if !ok || strings.TrimSpace(identity.Subject) == "" {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	name := strings.ToLower(strings.TrimSpace(r.PathValue("role_name")))
+	if name == "" {
+		api.writeError(w, r, http.StatusBadRequest, "role_name_required")
+		return
+	}
+	var req rbacRoleRequest
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
+		return
+	}
+	if strings.TrimSpace(req.Name) != "" && strings.ToLower(strings.TrimSpace(req.Name)) != name {
+		api.writeError(w, r, http.StatusBadRequest, "role_name_immutable")
+		return
+	}
+	permissions, ok := normalizeRBACPermissions(req.Permissions)
+	if !ok {
+		api.writeError(w, r, http.StatusBadRequest, "permission_invalid")
+		return
+	}
+
+	store := api.rbacPermissionStore()
+	if store == nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	existing, err := store.GetRole(r.Context(), name)
+	if err != nil {
+		api.writeRepoError(w, r, err)
+		return
+	}
+
+	record := existing
+	record.Description = strings.TrimSpace(req.Description)
+	record.Permissions = permissions
+	record.UpdatedAt = time.Now().UTC()
+	record.UpdatedBy = identity.Subject
+	integrity, err := integritySHA256(rbacRoleFromRecord(record))
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	record.IntegritySHA = integrity
+
+	updated, err := store.UpdateRole(r.Context(), record)
+	if err != nil {
+		api.writeRepoError(w, r, err)
+		return
+	}
+	api.invalidatePermissionCache()
+	api.appendRBACAuditEvent(r, "rbac.role_updated", "rbac_role", updated.Name, identity.Subject, map[string]any{
+		"role_name":            updated.Name,
+		"permissions":          updated.Permissions,
+		"previous_permissions": existing.Permissions,
+	})
+	api.writeJSON(w, http.StatusOK, rbacRoleFromRecord(updated))
+}
+
+func (api *experimentsAPI) handleDeleteRBACRole(w http.ResponseWriter, r *http.Request) {
+	identity, ok := auth.IdentityFromContext(r.Context())
+	if !ok || strings.TrimSpace(identity.Subject) == "" {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	name := strings.ToLower(strings.TrimSpace(r.PathValue("role_name")))
+	if name == "" {
+		api.writeError(w, r, http.StatusBadRequest, "role_name_required")
+		return
+	}
+	store := api.rbacPermissionStore()
+	if store == nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if err := store.DeleteRole(r.Context(), name); err != nil {
+		api.writeRepoError(w, r, err)
+		return
+	}
+	api.invalidatePermissionCache()
+	api.appendRBACAuditEvent(r, "rbac.role_deleted", "rbac_role", name, identity.Subject, map[string]any{
+		"role_name": name,
+	})
+	api.writeJSON(w, http.StatusOK, map[string]any{"status": "ok"})
+}
+
+func (api *experimentsAPI) handleListRBACRoutePermissions(w http.ResponseWriter, r *http.Request) {
+	service := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("service")))
+	if service != "" {
+		if _, ok := rbacRouteServices[service]; !ok {
+			api.writeError(w, r, http.StatusBadRequest, "service_invalid")
+			return
+		}
+	}
+	store := api.rbacPermissionStore()
+	if store == nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	records, err := store.ListRoutePermissions(r.Context(), service)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	out := make([]rbacRoutePermission, 0, len(records))
+	for _, record := range records {
+		out = append(out, rbacRoutePermissionFromRecord(record))
+	}
+	api.writeJSON(w, http.StatusOK, rbacRoutePermissionListResponse{Routes: out})
+}
+
+func (api *experimentsAPI) handleCreateRBACRoutePermission(w http.ResponseWriter, r *http.Request) {
+	identity, ok := auth.IdentityFromContext(r.Context())
+	if !ok || strings.TrimSpace(identity.Subject) == "" {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	var req rbacRoutePermissionRequest
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
+		return
+	}
+	service := strings.ToLower(strings.TrimSpace(req.Service))
+	method := strings.ToUpper(strings.TrimSpace(req.Method))
+	pattern := strings.TrimSpace(req.PathPattern)
+	permission := strings.ToLower(strings.TrimSpace(req.Permission))
+	if service == "" || method == "" || pattern == "" || permission == "" {
+		api.writeError(w, r, http.StatusBadRequest, "route_fields_required")
+		return
+	}
+	if _, ok := rbacRouteServices[service]; !ok {
+		api.writeError(w, r, http.StatusBadRequest, "service_invalid")
+		return
+	}
+	switch method {
+	case "*", http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		// ok
+	default:
+		api.writeError(w, r, http.StatusBadRequest, "method_invalid")
+		return
+	}
+	if !rbac.ValidRoutePattern(pattern) {
+		api.writeError(w, r, http.StatusBadRequest, "path_pattern_invalid")
+		return
+	}
+	if permission == rbac.PermissionWildcard || strings.HasSuffix(permission, ":*") || !rbac.ValidPermission(permission) {
+		api.writeError(w, r, http.StatusBadRequest, "permission_invalid")
+		return
+	}
+
+	store := api.rbacPermissionStore()
+	if store == nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	record := repo.RBACRoutePermissionRecord{
+		Service:     service,
+		Method:      method,
+		PathPattern: pattern,
+		Permission:  permission,
+		CreatedAt:   time.Now().UTC(),
+		CreatedBy:   identity.Subject,
+	}
+	integrity, err := integritySHA256(rbacRoutePermissionFromRecord(record))
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	record.IntegritySHA = integrity
+
+	created, err := store.CreateRoutePermission(r.Context(), record)
+	if err != nil {
+		if isUniqueViolation(err) {
+			api.writeError(w, r, http.StatusConflict, "route_permission_exists")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "route_permission_create_failed")
+		return
+	}
+	api.invalidatePermissionCache()
+	api.appendRBACAuditEvent(r, "rbac.route_permission_created", "rbac_route_permission", created.RouteID, identity.Subject, map[string]any{
+		"route_id":     created.RouteID,
+		"service":      created.Service,
+		"method":       created.Method,
+		"path_pattern": created.PathPattern,
+		"permission":   created.Permission,
+	})
+	api.writeJSON(w, http.StatusCreated, rbacRoutePermissionFromRecord(created))
+}
+
+func (api *experimentsAPI) handleDeleteRBACRoutePermission(w http.ResponseWriter, r *http.Request) {
+	identity, ok := auth.IdentityFromContext(r.Context())
+	if !ok || strings.TrimSpace(identity.Subject) == "" {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	routeID := strings.TrimSpace(r.PathValue("route_id"))
+	if routeID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "route_id_required")
+		return
+	}
+	store := api.rbacPermissionStore()
+	if store == nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if err := store.DeleteRoutePermission(r.Context(), routeID); err != nil {
+		api.writeRepoError(w, r, err)
+		return
+	}
+	api.invalidatePermissionCache()
+	api.appendRBACAuditEvent(r, "rbac.route_permission_deleted", "rbac_route_permission", routeID, identity.Subject, map[string]any{
+		"route_id": routeID,
+	})
+	api.writeJSON(w, http.StatusOK, map[string]any{"status": "ok"})
+}
+
+func (api *experimentsAPI) invalidatePermissionCache() {
+	if api == nil || api.permissionCache == nil {
+		return
+	}
+	api.permissionCache.Invalidate()
+}
+
+func (api *experimentsAPI) appendRBACAuditEvent(r *http.Request, action, resourceType, resourceID, actor string, payload map[string]any) {
+	appender := api.roleBindingAuditAppender()
+	if appender == nil {
+		return
+	}
+	_, _ = appender.Append(r.Context(), domain.AuditEvent{
+		OccurredAt:   time.Now().UTC(),
+		Actor:        strings.TrimSpace(actor),
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload:      payload,
+	})
+}
+
+func normalizeRBACPermissions(input []string) ([]string, bool) {
+	out := make([]string, 0, len(input))
+	seen := make(map[string]struct{}, len(input))
+	for _, permission := range input {
+		permission = strings.ToLower(strings.TrimSpace(permission))
+		if permission == "" {
+			continue
+		}
+		if !rbac.ValidPermission(permission) {
+			return nil, false
+		}
+		if _, ok := seen[permission]; ok {
+			continue
+		}
+		seen[permission] = struct{}{}
+		out = append(out, permission)
+	}
+	sort.Strings(out)
+	return out, true
+}
+
+func rbacRoleFromRecord(record repo.RBACRoleRecord) rbacRole {
+	permissions := record.Permissions
+	if permissions == nil {
+		permissions = []string{}
+	}
+	return rbacRole{
+		Name:        record.Name,
+		Description: record.Description,
+		Permissions: permissions,
+		CreatedAt:   record.CreatedAt,
+		CreatedBy:   record.CreatedBy,
+		UpdatedAt:   record.UpdatedAt,
+		UpdatedBy:   record.UpdatedBy,
+	}
+}
+
+func rbacRoutePermissionFromRecord(record repo.RBACRoutePermissionRecord) rbacRoutePermission {
+	return rbacRoutePermission{
+		RouteID:     record.RouteID,
+		Service:     record.Service,
+		Method:      record.Method,
+		PathPattern: record.PathPattern,
+		Permission:  record.Permission,
+		CreatedAt:   record.CreatedAt,
+		CreatedBy:   record.CreatedBy,
+	}
+}
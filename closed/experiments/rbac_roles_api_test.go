@@ -0,0 +1,158 @@
+package experiments
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+)
+
+type stubRBACPermissionStore struct {
+	roles  map[string]repo.RBACRoleRecord
+	routes map[string]repo.RBACRoutePermissionRecord
+}
+
+func (s *stubRBACPermissionStore) CreateRole(ctx context.Context, record repo.RBACRoleRecord) (repo.RBACRoleRecord, error) {
+	if s.roles == nil {
+		s.roles = map[string]repo.RBACRoleRecord{}
+	}
+	s.roles[record.Name] = record
+	return record, nil
+}
+
+func (s *stubRBACPermissionStore) UpdateRole(ctx context.Context, record repo.RBACRoleRecord) (repo.RBACRoleRecord, error) {
+	if _, ok := s.roles[record.Name]; !ok {
+		return repo.RBACRoleRecord{}, repo.ErrNotFound
+	}
+	s.roles[record.Name] = record
+	return record, nil
+}
+
+func (s *stubRBACPermissionStore) GetRole(ctx context.Context, name string) (repo.RBACRoleRecord, error) {
+	record, ok := s.roles[name]
+	if !ok {
+		return repo.RBACRoleRecord{}, repo.ErrNotFound
+	}
+	return record, nil
+}
+
+func (s *stubRBACPermissionStore) ListRoles(ctx context.Context) ([]repo.RBACRoleRecord, error) {
+	out := make([]repo.RBACRoleRecord, 0, len(s.roles))
+	for _, record := range s.roles {
+		out = append(out, record)
+	}
+	return out, nil
+}
+
+func (s *stubRBACPermissionStore) DeleteRole(ctx context.Context, name string) error {
+	if _, ok := s.roles[name]; !ok {
+		return repo.ErrNotFound
+	}
+	delete(s.roles, name)
+	return nil
+}
+
+func (s *stubRBACPermissionStore) CreateRoutePermission(ctx context.Context, record repo.RBACRoutePermissionRecord) (repo.RBACRoutePermissionRecord, error) {
+	if s.routes == nil {
+		s.routes = map[string]repo.RBACRoutePermissionRecord{}
+	}
+	if record.RouteID == "" {
+		record.RouteID = "route-1"
+	}
+	s.routes[record.RouteID] = record
+	return record, nil
+}
+
+func (s *stubRBACPermissionStore) ListRoutePermissions(ctx context.Context, service string) ([]repo.RBACRoutePermissionRecord, error) {
+	out := make([]repo.RBACRoutePermissionRecord, 0, len(s.routes))
+	for _, record := range s.routes {
+		if service != "" && record.Service != service {
+			continue
+		}
+		out = append(out, record)
+	}
+	return out, nil
+}
+
+func (s *stubRBACPermissionStore) DeleteRoutePermission(ctx context.Context, routeID string) error {
+	delete(s.routes, routeID)
+	return nil
+}
+
+func adminRequest(method, target, body string) *http.Request {
+	req := httptest.NewRequest(method, target, bytes.NewBufferString(body))
+	return req.WithContext(auth.ContextWithIdentity(req.Context(), auth.Identity{Subject: "admin-1"}))
+}
+
+func TestCreateRBACRoleValidatesAndAudits(t *testing.T) {
+	store := &stubRBACPermissionStore{}
+	audit := &stubAuditAppender{}
+	api := &experimentsAPI{rbacPermissionStoreOverride: store, roleBindingAuditOverride: audit}
+
+	resp := httptest.NewRecorder()
+	api.handleCreateRBACRole(resp, adminRequest(http.MethodPost, "/rbac/roles", `{"name":"admin","permissions":[]}`))
+	if resp.Code != http.StatusBadRequest {
+		t.Fatalf("expected builtin role name to be rejected, got %d", resp.Code)
+	}
+
+	resp = httptest.NewRecorder()
+	api.handleCreateRBACRole(resp, adminRequest(http.MethodPost, "/rbac/roles", `{"name":"data-steward","permissions":["not a permission"]}`))
+	if resp.Code != http.StatusBadRequest {
+		t.Fatalf("expected invalid permission to be rejected, got %d", resp.Code)
+	}
+
+	resp = httptest.NewRecorder()
+	api.handleCreateRBACRole(resp, adminRequest(http.MethodPost, "/rbac/roles", `{"name":"data-steward","permissions":["quality_rules:create","quality_rules:create"]}`))
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("status=%d want 201 body=%s", resp.Code, resp.Body.String())
+	}
+	role := store.roles["data-steward"]
+	if len(role.Permissions) != 1 || role.IntegritySHA == "" {
+		t.Fatalf("unexpected stored role: %+v", role)
+	}
+	if len(audit.events) != 1 || audit.events[0].Action != "rbac.role_created" {
+		t.Fatalf("unexpected audit events: %+v", audit.events)
+	}
+}
+
+func TestCreateRBACRoutePermissionRejectsWildcardPermission(t *testing.T) {
+	store := &stubRBACPermissionStore{}
+	api := &experimentsAPI{rbacPermissionStoreOverride: store, roleBindingAuditOverride: &stubAuditAppender{}}
+
+	resp := httptest.NewRecorder()
+	api.handleCreateRBACRoutePermission(resp, adminRequest(http.MethodPost, "/rbac/route-permissions", `{"service":"experiments","method":"POST","path_pattern":"/policies/**","permission":"policies:*"}`))
+	if resp.Code != http.StatusBadRequest {
+		t.Fatalf("expected wildcard route permission to be rejected, got %d", resp.Code)
+	}
+
+	resp = httptest.NewRecorder()
+	api.handleCreateRBACRoutePermission(resp, adminRequest(http.MethodPost, "/rbac/route-permissions", `{"service":"experiments","method":"post","path_pattern":"/policy-approvals/*/approve","permission":"policies:approve"}`))
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("status=%d want 201 body=%s", resp.Code, resp.Body.String())
+	}
+	if store.routes["route-1"].Method != http.MethodPost {
+		t.Fatalf("expected normalized method, got %+v", store.routes["route-1"])
+	}
+}
+
+func TestRoleBindingAcceptsCustomRole(t *testing.T) {
+	api := &experimentsAPI{
+		roleBindingStoreOverride:    &stubRoleBindingStore{},
+		roleBindingAuditOverride:    &stubAuditAppender{},
+		rbacPermissionStoreOverride: &stubRBACPermissionStore{roles: map[string]repo.RBACRoleRecord{"data-steward": {Name: "data-steward"}}},
+	}
+
+	for role, want := range map[string]int{"data-steward": http.StatusOK, "unknown-role": http.StatusBadRequest} {
+		req := adminRequest(http.MethodPost, "/projects/proj-1/role-bindings", `{"subject_type":"group","subject":"stewards","role":"`+role+`"}`)
+		req = req.WithContext(auth.ContextWithProjectID(req.Context(), "proj-1"))
+		resp := httptest.NewRecorder()
+		api.handleUpsertRoleBinding(resp, req)
+		if resp.Code != want {
+			t.Fatalf("role %s: status=%d want %d", role, resp.Code, want)
+		}
+	}
+}
@@ -1,4 +1,4 @@
-package main
+package experiments
 
 import (
 	"context"
@@ -29,14 +29,14 @@ type imageVerificationStore interface {
 	GetLatestByImage(ctx context.Context, projectID, imageDigestRef string) (registryverify.Record, error)
 }
 
-func (api *experimentsAPI) imageVerificationStore() imageVerificationStore {
+func (api *experimentsAPI) imageVerificationStore(db postgres.DB) imageVerificationStore {
 	if api == nil {
 		return nil
 	}
 	if api.registryStoreOverride != nil {
 		return api.registryStoreOverride
 	}
-	return postgres.NewImageVerificationStore(api.db)
+	return postgres.NewImageVerificationStore(db)
 }
 
 func (api *experimentsAPI) registryProvider(name string) registryverify.Provider {
@@ -59,7 +59,7 @@ func (api *experimentsAPI) verifyRegistryImages(ctx context.Context, identity au
 		return false, "", err
 	}
 	policy = policy.Normalize()
-	store := api.imageVerificationStore()
+	store := api.imageVerificationStore(api.db)
 	if store == nil {
 		return false, "", errors.New("image verification store unavailable")
 	}
@@ -229,7 +229,7 @@ func (api *experimentsAPI) appendRegistryAudit(ctx context.Context, event auditl
 	if api == nil || api.db == nil {
 		return
 	}
-	_, _ = auditlog.Insert(ctx, api.db, event)
+	_ = auditlog.Enqueue(ctx, api.db, event)
 }
 
 func isUnsignedFailure(reason string) bool {
@@ -1,4 +1,4 @@
-package main
+package experiments
 
 import (
 	"encoding/json"
@@ -35,7 +35,7 @@ func (api *experimentsAPI) handleListRegistryVerifications(w http.ResponseWriter
 		api.writeError(w, r, http.StatusBadRequest, "project_id_required")
 		return
 	}
-	store := api.imageVerificationStore()
+	store := api.imageVerificationStore(api.db)
 	if store == nil {
 		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
 		return
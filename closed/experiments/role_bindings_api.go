@@ -1,4 +1,4 @@
-package main
+package experiments
 
 import (
 	"context"
@@ -8,6 +8,7 @@ import (
 
 	"github.com/animus-labs/animus-go/closed/internal/domain"
 	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
 	"github.com/animus-labs/animus-go/closed/internal/repo"
 	repopg "github.com/animus-labs/animus-go/closed/internal/repo/postgres"
 )
@@ -83,8 +84,8 @@ func (api *experimentsAPI) handleUpsertRoleBinding(w http.ResponseWriter, r *htt
 	}
 
 	var req roleBindingRequest
-	if err := decodeJSON(r, &req); err != nil {
-		api.writeError(w, r, http.StatusBadRequest, "invalid_json")
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
 		return
 	}
 
@@ -107,11 +108,13 @@ func (api *experimentsAPI) handleUpsertRoleBinding(w http.ResponseWriter, r *htt
 		return
 	}
 	switch role {
-	case auth.RoleViewer, auth.RoleEditor, auth.RoleAdmin:
+	case auth.RoleViewer, auth.RoleEditor, auth.RoleAdmin, auth.RoleAuditor:
 		// ok
 	default:
-		api.writeError(w, r, http.StatusBadRequest, "role_invalid")
-		return
+		if !api.customRoleExists(r.Context(), role) {
+			api.writeError(w, r, http.StatusBadRequest, "role_invalid")
+			return
+		}
 	}
 
 	store := api.roleBindingStore()
@@ -1,4 +1,4 @@
-package main
+package experiments
 
 import (
 	"bytes"
@@ -135,3 +135,40 @@ func TestRoleBindingDeleteEmitsAudit(t *testing.T) {
 		t.Fatalf("unexpected audit action: %s", audit.events[0].Action)
 	}
 }
+
+func TestProjectMembersRoutes(t *testing.T) {
+	store := &stubRoleBindingStore{}
+	api := &experimentsAPI{
+		roleBindingStoreOverride: store,
+		roleBindingAuditOverride: &stubAuditAppender{},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /projects/{project_id}/members", api.handleListRoleBindings)
+	mux.HandleFunc("POST /projects/{project_id}/members", api.handleUpsertRoleBinding)
+	mux.HandleFunc("DELETE /projects/{project_id}/members/{binding_id}", api.handleDeleteRoleBinding)
+
+	serve := func(method, path, body string) int {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req = req.WithContext(auth.ContextWithIdentity(req.Context(), auth.Identity{Subject: "admin-1"}))
+		req = req.WithContext(auth.ContextWithProjectID(req.Context(), "proj-1"))
+		resp := httptest.NewRecorder()
+		mux.ServeHTTP(resp, req)
+		return resp.Code
+	}
+
+	if code := serve(http.MethodPost, "/projects/proj-1/members", `{"subject_type":"email","subject":"ann@example.com","role":"editor"}`); code != http.StatusOK {
+		t.Fatalf("add member status=%d", code)
+	}
+	if code := serve(http.MethodGet, "/projects/proj-1/members", ""); code != http.StatusOK {
+		t.Fatalf("list members status=%d", code)
+	}
+	if code := serve(http.MethodDelete, "/projects/proj-1/members/binding-1", ""); code != http.StatusOK {
+		t.Fatalf("remove member status=%d", code)
+	}
+	if len(store.records) != 0 {
+		t.Fatalf("expected member removed, got %+v", store.records)
+	}
+	if code := serve(http.MethodDelete, "/projects/proj-1/members/binding-1", ""); code != http.StatusNotFound {
+		t.Fatalf("expected 404 for removed member, got %d", code)
+	}
+}
@@ -0,0 +1,240 @@
+package experiments
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/dataplane"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+	"github.com/animus-labs/animus-go/closed/internal/repo/postgres"
+)
+
+// agentDispatchTarget is the dp_base_url of dispatches that wait for a remote
+// agent to claim them instead of being pushed to the data plane.
+const agentDispatchTarget = "agent:"
+
+const (
+	agentClaimMaxWait      = 30 * time.Second
+	agentClaimPollInterval = time.Second
+	maxAgentLabels         = 16
+	// agentHeartbeatLostReason fails claimed runs whose agent went silent.
+	agentHeartbeatLostReason = "agent_heartbeat_lost"
+)
+
+var (
+	agentLabelPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._=/-]{0,62}$`)
+	agentIDPattern    = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,127}$`)
+)
+
+// normalizeAgentLabels lower-cases, dedupes and sorts labels. It reports
+// false when a label is malformed or there are too many.
+func normalizeAgentLabels(labels []string) ([]string, bool) {
+	seen := map[string]struct{}{}
+	out := make([]string, 0, len(labels))
+	for _, label := range labels {
+		label = strings.ToLower(strings.TrimSpace(label))
+		if !agentLabelPattern.MatchString(label) {
+			return nil, false
+		}
+		if _, ok := seen[label]; ok {
+			continue
+		}
+		seen[label] = struct{}{}
+		out = append(out, label)
+	}
+	if len(out) > maxAgentLabels {
+		return nil, false
+	}
+	sort.Strings(out)
+	return out, true
+}
+
+func isAgentDispatch(dispatch postgres.RunDispatchRecord) bool {
+	return strings.TrimSpace(dispatch.DPBaseURL) == agentDispatchTarget
+}
+
+// dispatchTarget is the dp_base_url for a new dispatch of runID: the agent
+// target when the run was assigned to agents, the data plane otherwise.
+func (api *experimentsAPI) dispatchTarget(ctx context.Context, db postgres.DB, runID string) (string, error) {
+	assigned, _, _, err := postgres.NewRunAgentStore(db).Assignment(ctx, runID)
+	switch {
+	case err != nil:
+		return "", err
+	case assigned:
+		return agentDispatchTarget, nil
+	default:
+		return api.dataplaneURL, nil
+	}
+}
+
+// dispatchToAgents leaves the dispatch requested for an agent to claim and
+// audits it like a data plane dispatch. The dispatch row is committed by the
+// caller and nothing else is written here, so the event goes to the outbox
+// on its own.
+func (api *experimentsAPI) dispatchToAgents(ctx context.Context, runRecord repo.RunRecord, dispatch postgres.RunDispatchRecord, origin dispatchOrigin) (string, error) {
+	status := dataplane.DispatchStatusRequested
+	if err := auditlog.Enqueue(ctx, api.db, auditlog.Event{
+		OccurredAt:   time.Now().UTC(),
+		Actor:        origin.Actor,
+		Action:       "run.dispatched",
+		ResourceType: "run",
+		ResourceID:   runRecord.ID,
+		RequestID:    origin.RequestID,
+		IP:           origin.IP,
+		UserAgent:    origin.UserAgent,
+		Payload: map[string]any{
+			"service":              "experiments",
+			"project_id":           runRecord.ProjectID,
+			"run_id":               runRecord.ID,
+			"spec_hash":            runRecord.SpecHash,
+			"dispatch_id":          dispatch.DispatchID,
+			"status":               status,
+			"dp_base_url":          agentDispatchTarget,
+			"requested_by":         origin.RequestedBy,
+			"max_duration_seconds": dispatch.MaxDurationSeconds.Int64,
+		},
+	}); err != nil {
+		return status, err
+	}
+	return status, nil
+}
+
+// handleAgentClaim hands the oldest run waiting for an agent with the
+// caller's labels to the agent, holding the request open for up to
+// waitSeconds while there is none.
+func (api *experimentsAPI) handleAgentClaim(w http.ResponseWriter, r *http.Request) {
+	identity, ok := auth.IdentityFromContext(r.Context())
+	if !ok || strings.TrimSpace(identity.Subject) == "" {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	agentID := strings.TrimSpace(r.PathValue("agent_id"))
+	if !agentIDPattern.MatchString(agentID) {
+		api.writeError(w, r, http.StatusBadRequest, "invalid_agent_id")
+		return
+	}
+
+	var req dataplane.AgentClaimRequest
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
+		return
+	}
+	labels, ok := normalizeAgentLabels(req.Labels)
+	if !ok {
+		api.writeError(w, r, http.StatusBadRequest, "invalid_agent_labels")
+		return
+	}
+	wait := time.Duration(req.WaitSeconds) * time.Second
+	if wait < 0 {
+		wait = 0
+	}
+	if wait > agentClaimMaxWait {
+		wait = agentClaimMaxWait
+	}
+
+	deadline := time.Now().Add(wait)
+	for {
+		claimed, ok, err := api.claimAgentRun(r.Context(), agentID, labels, identity, r)
+		if err != nil {
+			if r.Context().Err() != nil {
+				return
+			}
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+		if ok {
+			api.writeJSON(w, http.StatusOK, claimed)
+			return
+		}
+		if !time.Now().Before(deadline) {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		select {
+		case <-r.Context().Done():
+			return
+		case <-time.After(agentClaimPollInterval):
+		}
+	}
+}
+
+// claimAgentRun assigns one waiting run to agentID and accepts its dispatch
+// in the same transaction, so concurrent agents never claim a run twice.
+func (api *experimentsAPI) claimAgentRun(ctx context.Context, agentID string, labels []string, identity auth.Identity, r *http.Request) (dataplane.RunExecutionRequest, bool, error) {
+	tx, err := api.db.BeginTx(ctx, nil)
+	if err != nil {
+		return dataplane.RunExecutionRequest{}, false, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	now := time.Now().UTC()
+	claim, ok, err := postgres.NewRunAgentStore(tx).Claim(ctx, agentID, labels, agentDispatchTarget, dataplane.DispatchStatusRequested, now)
+	if err != nil || !ok {
+		return dataplane.RunExecutionRequest{}, false, err
+	}
+	dispatchID, runID, projectID := claim.DispatchID, claim.RunID, claim.ProjectID
+	dpStore := postgres.NewDPEventStore(tx)
+	if dpStore == nil {
+		return dataplane.RunExecutionRequest{}, false, errors.New("dp store unavailable")
+	}
+	if err := dpStore.UpdateDispatchStatus(ctx, dispatchID, dataplane.DispatchStatusAccepted, "", now); err != nil {
+		return dataplane.RunExecutionRequest{}, false, err
+	}
+	checkpoint, err := api.runCheckpointForExecution(ctx, tx, runID)
+	if err != nil {
+		return dataplane.RunExecutionRequest{}, false, err
+	}
+	datasets, err := api.runDatasetsForExecution(ctx, tx, runID)
+	if err != nil {
+		return dataplane.RunExecutionRequest{}, false, err
+	}
+	runToken, err := api.mintRunToken(runID, claim.MaxDurationSeconds, now)
+	if err != nil {
+		return dataplane.RunExecutionRequest{}, false, err
+	}
+	requestID := r.Header.Get("X-Request-Id")
+	if err := auditlog.Enqueue(ctx, tx, auditlog.Event{
+		OccurredAt:   now,
+		Actor:        identity.Subject,
+		Action:       "run.agent_claimed",
+		ResourceType: "run",
+		ResourceID:   runID,
+		RequestID:    requestID,
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload: map[string]any{
+			"service":      "experiments",
+			"project_id":   projectID,
+			"run_id":       runID,
+			"dispatch_id":  dispatchID,
+			"agent_id":     agentID,
+			"agent_labels": labels,
+		},
+	}); err != nil {
+		return dataplane.RunExecutionRequest{}, false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return dataplane.RunExecutionRequest{}, false, err
+	}
+
+	return dataplane.RunExecutionRequest{
+		RunID:              runID,
+		ProjectID:          projectID,
+		DispatchID:         dispatchID,
+		EmittedAt:          now,
+		RequestedBy:        claim.RequestedBy,
+		CorrelationID:      requestID,
+		MaxDurationSeconds: claim.MaxDurationSeconds,
+		Checkpoint:         checkpoint,
+		Datasets:           datasets,
+		RunToken:           runToken,
+	}, true, nil
+}
@@ -0,0 +1,65 @@
+package experiments
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/repo/postgres"
+)
+
+func TestNormalizeAgentLabels(t *testing.T) {
+	labels, ok := normalizeAgentLabels([]string{" GPU ", "zone=eu-1", "gpu", "pool/a100"})
+	if !ok || !reflect.DeepEqual(labels, []string{"gpu", "pool/a100", "zone=eu-1"}) {
+		t.Fatalf("labels=%v ok=%v", labels, ok)
+	}
+	if labels, ok := normalizeAgentLabels(nil); !ok || len(labels) != 0 {
+		t.Fatalf("nil labels=%v ok=%v", labels, ok)
+	}
+	for _, bad := range [][]string{{""}, {"-gpu"}, {"gpu pool"}, {strings.Repeat("a", 64)}} {
+		if _, ok := normalizeAgentLabels(bad); ok {
+			t.Fatalf("expected %q to be rejected", bad)
+		}
+	}
+	many := make([]string, maxAgentLabels+1)
+	for i := range many {
+		many[i] = "l" + strings.Repeat("x", i)
+	}
+	if _, ok := normalizeAgentLabels(many); ok {
+		t.Fatalf("expected too many labels to be rejected")
+	}
+}
+
+func TestIsAgentDispatch(t *testing.T) {
+	if !isAgentDispatch(postgres.RunDispatchRecord{DPBaseURL: agentDispatchTarget}) {
+		t.Fatalf("expected agent dispatch")
+	}
+	if isAgentDispatch(postgres.RunDispatchRecord{DPBaseURL: "http://dataplane:8086"}) {
+		t.Fatalf("expected data plane dispatch")
+	}
+}
+
+func TestAgentClaimValidation(t *testing.T) {
+	api := &experimentsAPI{}
+	for _, tc := range []struct {
+		agentID string
+		body    string
+		want    string
+	}{
+		{agentID: "../x", body: `{}`, want: "invalid_agent_id"},
+		{agentID: "agent-1", body: `{"labels":["GPU POOL"]}`, want: "invalid_agent_labels"},
+		{agentID: "agent-1", body: `{"labels":"gpu"}`, want: "invalid_json"},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/internal/cp/agents/"+tc.agentID+"/claim", strings.NewReader(tc.body))
+		req.SetPathValue("agent_id", tc.agentID)
+		req = req.WithContext(auth.ContextWithIdentity(req.Context(), auth.Identity{Subject: "system:dataplane", Roles: []string{auth.RoleAdmin}}))
+		rec := httptest.NewRecorder()
+		api.handleAgentClaim(rec, req)
+		if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), tc.want) {
+			t.Fatalf("%s %s: status=%d body=%s", tc.agentID, tc.body, rec.Code, rec.Body.String())
+		}
+	}
+}
@@ -1,4 +1,4 @@
-package main
+package experiments
 
 import (
 	"context"
@@ -21,12 +21,17 @@ func (api *experimentsAPI) buildPolicySnapshot(ctx context.Context, projectID st
 	if projectID == "" {
 		return domain.PolicySnapshot{}, errors.New("project id is required")
 	}
-	policies, err := api.loadActivePolicyVersions(ctx)
+	settings, err := api.projectSettings(ctx, projectID)
+	if err != nil {
+		return domain.PolicySnapshot{}, err
+	}
+	policies, err := api.scopedPolicyVersions(ctx, policyScope{ProjectID: projectID}, settings)
 	if err != nil {
 		return domain.PolicySnapshot{}, err
 	}
 	now := time.Now().UTC()
 	snapshot := assemblePolicySnapshot(projectID, identity, envLock, policies, now)
+	snapshot.Retention = projectSnapshotRetention(settings)
 
 	hash, err := hashPolicySnapshot(snapshot)
 	if err != nil {
@@ -0,0 +1,214 @@
+package experiments
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/dataplane"
+	"github.com/animus-labs/animus-go/closed/internal/domain"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+	"github.com/animus-labs/animus-go/closed/internal/repo/postgres"
+	"github.com/animus-labs/animus-go/closed/internal/service/runs"
+)
+
+const maxRunCancelReasonLength = 512
+
+type runCancelRequest struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+type runCancelResponse struct {
+	RunID     string `json:"runId"`
+	ProjectID string `json:"projectId"`
+	Status    string `json:"status"`
+	// JobCanceled reports whether the data plane stopped a running Job; it
+	// is false for runs that were never dispatched or were still queued.
+	JobCanceled bool `json:"jobCanceled"`
+}
+
+// handleCancelRun cancels a run that has not finished. A dispatched run's
+// Job is stopped on the data plane first; if that fails the run is left
+// untouched so the call can be retried. Queued runs are dropped from the
+// queue on its next drain.
+func (api *experimentsAPI) handleCancelRun(w http.ResponseWriter, r *http.Request) {
+	identity, ok := auth.IdentityFromContext(r.Context())
+	if !ok || strings.TrimSpace(identity.Subject) == "" {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	projectID := strings.TrimSpace(r.PathValue("project_id"))
+	runID := strings.TrimSpace(r.PathValue("run_id"))
+	if projectID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "project_id_required")
+		return
+	}
+	if runID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "run_id_required")
+		return
+	}
+
+	var req runCancelRequest
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
+		return
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if len(reason) > maxRunCancelReasonLength {
+		api.writeError(w, r, http.StatusBadRequest, "reason_too_long")
+		return
+	}
+	if reason == "" {
+		reason = "canceled"
+	}
+
+	runStore := postgres.NewRunSpecStore(api.db)
+	dpStore := postgres.NewDPEventStore(api.db)
+	if runStore == nil || dpStore == nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	runRecord, err := runStore.GetRun(r.Context(), projectID, runID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if domain.IsTerminalRunState(domain.NormalizeRunState(runRecord.Status)) {
+		api.writeError(w, r, http.StatusConflict, "run_terminal")
+		return
+	}
+
+	requestID := r.Header.Get("X-Request-Id")
+	jobCanceled := false
+	dispatch, err := dpStore.GetDispatchByRunID(r.Context(), projectID, runID)
+	switch {
+	case err == nil && isAgentDispatch(dispatch):
+		// Agents learn of the cancellation from the rejected heartbeat and
+		// stop the container themselves.
+	case err == nil && !isTerminalDispatchStatus(dispatch.Status):
+		dpURL := strings.TrimSpace(dispatch.DPBaseURL)
+		if dpURL == "" {
+			dpURL = api.dataplaneURL
+		}
+		client, err := newDataplaneClient(dpURL, api.runTokenSecret, api.internalTransport)
+		if err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "dataplane_url_not_configured")
+			return
+		}
+		resp, _, err := client.CancelRun(r.Context(), dataplane.RunCancelRequest{
+			RunID:         runID,
+			ProjectID:     projectID,
+			DispatchID:    dispatch.DispatchID,
+			Reason:        reason,
+			EmittedAt:     time.Now().UTC(),
+			RequestedBy:   identity.Subject,
+			CorrelationID: requestID,
+		}, requestID)
+		if err != nil {
+			api.writeError(w, r, http.StatusBadGateway, "dataplane_cancel_failed")
+			return
+		}
+		jobCanceled = resp.Canceled
+	case err != nil && !errors.Is(err, repo.ErrNotFound) && !errors.Is(err, sql.ErrNoRows):
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	applied, err := api.cancelRunState(r.Context(), r, identity, runRecord, reason, jobCanceled)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if !applied {
+		api.writeError(w, r, http.StatusConflict, "run_terminal")
+		return
+	}
+	api.writeJSON(w, http.StatusOK, runCancelResponse{
+		RunID:       runID,
+		ProjectID:   projectID,
+		Status:      string(domain.RunStateCanceled),
+		JobCanceled: jobCanceled,
+	})
+}
+
+// cancelRunState moves the run to canceled and audits it as run.canceled.
+// It reports false when the run reached a terminal state concurrently.
+func (api *experimentsAPI) cancelRunState(ctx context.Context, r *http.Request, identity auth.Identity, runRecord repo.RunRecord, reason string, jobCanceled bool) (bool, error) {
+	tx, err := api.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	runStore := postgres.NewRunSpecStore(tx)
+	dpStore := postgres.NewDPEventStore(tx)
+	if runStore == nil || dpStore == nil {
+		return false, errors.New("stores unavailable")
+	}
+	prev, applied, err := runStore.UpdateDerivedStatus(ctx, runRecord.ProjectID, runRecord.ID, domain.RunStateCanceled)
+	if errors.Is(err, repo.ErrInvalidTransition) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if !applied {
+		return false, nil
+	}
+
+	requestID := r.Header.Get("X-Request-Id")
+	appender := runs.NewAuditAppender(tx)
+	if appender == nil {
+		return false, errors.New("audit appender unavailable")
+	}
+	auditInfo := runs.AuditInfo{
+		Actor:     identity.Subject,
+		RequestID: requestID,
+		Service:   "experiments",
+	}
+	if event, ok, err := runs.BuildRunTransitionEvent(auditInfo, runRecord.ProjectID, runRecord.ID, runRecord.SpecHash, prev, domain.RunStateCanceled); err == nil && ok {
+		if err := appender.Append(ctx, *event); err != nil {
+			return false, err
+		}
+	}
+	if err := auditlog.Enqueue(ctx, tx, auditlog.Event{
+		OccurredAt:   time.Now().UTC(),
+		Actor:        identity.Subject,
+		Action:       "run.canceled",
+		ResourceType: "run",
+		ResourceID:   runRecord.ID,
+		RequestID:    requestID,
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload: map[string]any{
+			"service":      "experiments",
+			"project_id":   runRecord.ProjectID,
+			"run_id":       runRecord.ID,
+			"spec_hash":    runRecord.SpecHash,
+			"from":         string(prev),
+			"to":           string(domain.RunStateCanceled),
+			"reason":       reason,
+			"job_canceled": jobCanceled,
+		},
+	}); err != nil {
+		return false, err
+	}
+	if err := updateDispatchStatus(ctx, dpStore, runRecord.ProjectID, runRecord.ID, dataplane.DispatchStatusCanceled, reason); err != nil {
+		return false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
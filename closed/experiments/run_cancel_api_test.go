@@ -0,0 +1,28 @@
+package experiments
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+)
+
+func TestCancelRunValidation(t *testing.T) {
+	api := &experimentsAPI{}
+	for body, want := range map[string]string{
+		`{"why":"x"}`: "invalid_json",
+		`{"reason":"` + strings.Repeat("r", maxRunCancelReasonLength+1) + `"}`: "reason_too_long",
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/projects/proj-1/runs/run-1/cancel", strings.NewReader(body))
+		req.SetPathValue("project_id", "proj-1")
+		req.SetPathValue("run_id", "run-1")
+		req = req.WithContext(auth.ContextWithIdentity(req.Context(), auth.Identity{Subject: "user-1", Roles: []string{auth.RoleEditor}}))
+		rec := httptest.NewRecorder()
+		api.handleCancelRun(rec, req)
+		if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), want) {
+			t.Fatalf("body %s: status=%d body=%s", body, rec.Code, rec.Body.String())
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package experiments
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/dataplane"
+	"github.com/animus-labs/animus-go/closed/internal/domain"
+	"github.com/animus-labs/animus-go/closed/internal/platform/lineageevent"
+	"github.com/animus-labs/animus-go/closed/internal/repo/postgres"
+)
+
+const artifactKindCheckpoint = "checkpoint"
+
+// defaultCheckpointURLTTL is how long a presigned checkpoint URL stays valid
+// when ANIMUS_CHECKPOINT_URL_TTL is unset. It must outlast queueing on the
+// executor, not the run itself: the checkpoint is read at start-up.
+const defaultCheckpointURLTTL = 6 * time.Hour
+
+var (
+	errCheckpointRefInvalid  = errors.New("checkpoint reference invalid")
+	errCheckpointNotFound    = errors.New("checkpoint not found")
+	errCheckpointKindInvalid = errors.New("checkpoint artifact kind invalid")
+)
+
+// resolveRunCheckpoint checks that ref names a checkpoint artifact of an
+// experiment run in projectID and pins its content hash.
+func resolveRunCheckpoint(ctx context.Context, db postgres.DB, projectID string, ref runCheckpointRef) (domain.CheckpointRef, error) {
+	out := domain.CheckpointRef{
+		RunID:      strings.TrimSpace(ref.RunID),
+		ArtifactID: strings.TrimSpace(ref.ArtifactID),
+	}
+	if out.RunID == "" || out.ArtifactID == "" {
+		return domain.CheckpointRef{}, errCheckpointRefInvalid
+	}
+	var kind string
+	err := db.QueryRowContext(
+		ctx,
+		`SELECT kind, sha256
+		 FROM experiment_run_artifacts
+		 WHERE artifact_id = $1 AND run_id = $2 AND project_id = $3`,
+		out.ArtifactID,
+		out.RunID,
+		projectID,
+	).Scan(&kind, &out.SHA256)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.CheckpointRef{}, errCheckpointNotFound
+	}
+	if err != nil {
+		return domain.CheckpointRef{}, err
+	}
+	if !strings.EqualFold(strings.TrimSpace(kind), artifactKindCheckpoint) {
+		return domain.CheckpointRef{}, errCheckpointKindInvalid
+	}
+	return out, nil
+}
+
+// recordRunCheckpoint pins the checkpoint of a new run and appends the
+// resumed_from lineage edge from the run to the checkpoint artifact.
+func recordRunCheckpoint(ctx context.Context, tx *sql.Tx, projectID, runID, actor, requestID string, ref domain.CheckpointRef, now time.Time) error {
+	if _, err := tx.ExecContext(
+		ctx,
+		`INSERT INTO run_checkpoints (run_id, project_id, source_run_id, artifact_id, sha256, created_at, created_by)
+		 VALUES ($1,$2,$3,$4,$5,$6,$7)
+		 ON CONFLICT (run_id) DO NOTHING`,
+		runID,
+		projectID,
+		ref.RunID,
+		ref.ArtifactID,
+		ref.SHA256,
+		now,
+		actor,
+	); err != nil {
+		return err
+	}
+	err := lineageevent.Enqueue(ctx, tx, lineageevent.Event{
+		OccurredAt:  now,
+		Actor:       actor,
+		RequestID:   requestID,
+		SubjectType: "run",
+		SubjectID:   runID,
+		Predicate:   "resumed_from",
+		ObjectType:  "experiment_run_artifact",
+		ObjectID:    ref.ArtifactID,
+		Metadata: map[string]any{
+			"project_id":    projectID,
+			"source_run_id": ref.RunID,
+			"sha256":        ref.SHA256,
+		},
+	})
+	return err
+}
+
+// runCheckpointForExecution returns the checkpoint runID resumes from with a
+// fresh presigned download URL, or nil when the run starts from scratch.
+func (api *experimentsAPI) runCheckpointForExecution(ctx context.Context, db postgres.DB, runID string) (*dataplane.RunCheckpoint, error) {
+	var (
+		checkpoint dataplane.RunCheckpoint
+		objectKey  string
+	)
+	err := db.QueryRowContext(
+		ctx,
+		`SELECT c.source_run_id, c.artifact_id, c.sha256, a.size_bytes, a.object_key
+		 FROM run_checkpoints c
+		 JOIN experiment_run_artifacts a ON a.artifact_id = c.artifact_id
+		 WHERE c.run_id = $1`,
+		runID,
+	).Scan(&checkpoint.RunID, &checkpoint.ArtifactID, &checkpoint.SHA256, &checkpoint.SizeBytes, &objectKey)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if api.store == nil {
+		return nil, errors.New("object store unavailable")
+	}
+	ttl := api.checkpointURLTTL
+	if ttl <= 0 {
+		ttl = defaultCheckpointURLTTL
+	}
+	url, err := api.store.PresignedGetObject(ctx, api.storeCfg.BucketArtifacts, objectKey, ttl, nil)
+	if err != nil {
+		return nil, err
+	}
+	checkpoint.URL = url.String()
+	checkpoint.ExpiresAt = time.Now().UTC().Add(ttl)
+	return &checkpoint, nil
+}
@@ -0,0 +1,40 @@
+package experiments
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/animus-labs/animus-go/closed/internal/domain"
+)
+
+func TestRunSpecResumeFrom(t *testing.T) {
+	spec := domain.RunSpec{RunSpecVersion: runSpecVersion, ProjectID: "proj-1"}
+	fresh, err := hashRunSpec(spec)
+	if err != nil {
+		t.Fatalf("hashRunSpec() err=%v", err)
+	}
+	raw, err := marshalRunSpec(spec, []byte(`{}`))
+	if err != nil || strings.Contains(string(raw), "resumeFrom") {
+		t.Fatalf("fresh spec=%s err=%v", raw, err)
+	}
+
+	spec.ResumeFrom = &domain.CheckpointRef{RunID: "run-0", ArtifactID: "art-1", SHA256: strings.Repeat("a", 64)}
+	resumed, err := hashRunSpec(spec)
+	if err != nil || resumed == fresh {
+		t.Fatalf("resumed hash=%s fresh=%s err=%v", resumed, fresh, err)
+	}
+	raw, err = marshalRunSpec(spec, []byte(`{}`))
+	if err != nil || !strings.Contains(string(raw), `"resumeFrom":{"runId":"run-0","artifactId":"art-1"`) {
+		t.Fatalf("resumed spec=%s err=%v", raw, err)
+	}
+}
+
+func TestResolveRunCheckpointRequiresRef(t *testing.T) {
+	for _, ref := range []runCheckpointRef{{}, {RunID: "run-0"}, {ArtifactID: " "}} {
+		if _, err := resolveRunCheckpoint(context.Background(), nil, "proj-1", ref); !errors.Is(err, errCheckpointRefInvalid) {
+			t.Fatalf("ref=%+v err=%v", ref, err)
+		}
+	}
+}
@@ -0,0 +1,433 @@
+package experiments
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	"github.com/animus-labs/animus-go/closed/internal/platform/lineageevent"
+)
+
+const (
+	maxColumnLineageColumns     = 256
+	maxColumnLineageOutputs     = 256
+	maxColumnLineageDerivedFrom = 64
+	maxColumnNameLen            = 256
+)
+
+// columnLineage is the column mapping a run declares: the columns it read
+// from each consumed dataset version, and the columns or features it wrote,
+// each derived from some of those input columns. An output with a
+// dataset_version_id is a column of that version; otherwise it is a feature
+// of the run.
+type columnLineage struct {
+	Inputs  []columnLineageInput  `json:"inputs"`
+	Outputs []columnLineageOutput `json:"outputs"`
+}
+
+type columnLineageInput struct {
+	DatasetVersionID string   `json:"dataset_version_id"`
+	Columns          []string `json:"columns"`
+}
+
+type columnLineageOutput struct {
+	Name             string      `json:"name"`
+	DatasetVersionID string      `json:"dataset_version_id,omitempty"`
+	DerivedFrom      []columnRef `json:"derived_from"`
+}
+
+type columnRef struct {
+	DatasetVersionID string `json:"dataset_version_id"`
+	Column           string `json:"column"`
+}
+
+type runColumnLineage struct {
+	RunID      string        `json:"run_id"`
+	Mapping    columnLineage `json:"mapping"`
+	DeclaredAt time.Time     `json:"declared_at"`
+	DeclaredBy string        `json:"declared_by"`
+}
+
+func validColumnName(name string) bool {
+	if name == "" || len(name) > maxColumnNameLen || strings.TrimSpace(name) != name {
+		return false
+	}
+	for _, r := range name {
+		if unicode.IsControl(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeColumnLineage validates a declared mapping against the dataset
+// versions the run consumed, and returns the error code for the first
+// problem. Column names are kept as declared; duplicates are dropped.
+func normalizeColumnLineage(in columnLineage, runVersions map[string]struct{}) (columnLineage, string) {
+	if len(in.Inputs) == 0 || len(in.Inputs) > maxRunDatasetVersions || len(in.Outputs) > maxColumnLineageOutputs {
+		return columnLineage{}, "column_lineage_invalid"
+	}
+	out := columnLineage{Inputs: make([]columnLineageInput, 0, len(in.Inputs)), Outputs: make([]columnLineageOutput, 0, len(in.Outputs))}
+	declared := make(map[columnRef]struct{})
+	seenInputs := make(map[string]struct{}, len(in.Inputs))
+	for _, input := range in.Inputs {
+		versionID := strings.TrimSpace(input.DatasetVersionID)
+		if _, ok := runVersions[versionID]; !ok {
+			return columnLineage{}, "column_lineage_dataset_not_used"
+		}
+		if _, ok := seenInputs[versionID]; ok || len(input.Columns) == 0 || len(input.Columns) > maxColumnLineageColumns {
+			return columnLineage{}, "column_lineage_invalid"
+		}
+		seenInputs[versionID] = struct{}{}
+		columns := make([]string, 0, len(input.Columns))
+		for _, column := range input.Columns {
+			if !validColumnName(column) {
+				return columnLineage{}, "column_lineage_invalid"
+			}
+			ref := columnRef{DatasetVersionID: versionID, Column: column}
+			if _, ok := declared[ref]; ok {
+				continue
+			}
+			declared[ref] = struct{}{}
+			columns = append(columns, column)
+		}
+		out.Inputs = append(out.Inputs, columnLineageInput{DatasetVersionID: versionID, Columns: columns})
+	}
+
+	seenOutputs := make(map[columnRef]struct{}, len(in.Outputs))
+	for _, output := range in.Outputs {
+		key := columnRef{DatasetVersionID: strings.TrimSpace(output.DatasetVersionID), Column: output.Name}
+		if !validColumnName(output.Name) || len(output.DerivedFrom) == 0 || len(output.DerivedFrom) > maxColumnLineageDerivedFrom {
+			return columnLineage{}, "column_lineage_invalid"
+		}
+		if _, ok := seenOutputs[key]; ok {
+			return columnLineage{}, "column_lineage_invalid"
+		}
+		seenOutputs[key] = struct{}{}
+		derived := make([]columnRef, 0, len(output.DerivedFrom))
+		seenRefs := make(map[columnRef]struct{}, len(output.DerivedFrom))
+		for _, ref := range output.DerivedFrom {
+			ref.DatasetVersionID = strings.TrimSpace(ref.DatasetVersionID)
+			if _, ok := declared[ref]; !ok {
+				return columnLineage{}, "column_lineage_input_undeclared"
+			}
+			if _, ok := seenRefs[ref]; ok {
+				continue
+			}
+			seenRefs[ref] = struct{}{}
+			derived = append(derived, ref)
+		}
+		out.Outputs = append(out.Outputs, columnLineageOutput{Name: output.Name, DatasetVersionID: key.DatasetVersionID, DerivedFrom: derived})
+	}
+	return out, ""
+}
+
+// outputNode is the lineage node of output produced by runID.
+func (o columnLineageOutput) outputNode(runID string) (string, string) {
+	if o.DatasetVersionID != "" {
+		return lineageevent.NodeDatasetColumn, lineageevent.ColumnNodeID(o.DatasetVersionID, o.Name)
+	}
+	return lineageevent.NodeRunFeature, lineageevent.ColumnNodeID(runID, o.Name)
+}
+
+// handleDeclareRunColumnLineage records the column mapping of a run once.
+// Column nodes are shared between runs: a dataset version gets one
+// has_column edge per column, however many runs read it.
+func (api *experimentsAPI) handleDeclareRunColumnLineage(w http.ResponseWriter, r *http.Request) {
+	identity, ok := auth.IdentityFromContext(r.Context())
+	if !ok || strings.TrimSpace(identity.Subject) == "" {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	runID := strings.TrimSpace(r.PathValue("run_id"))
+	if runID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "run_id_required")
+		return
+	}
+	if exists, err := api.runStore(api.db).RunExists(r.Context(), runID); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	} else if !exists {
+		api.writeError(w, r, http.StatusNotFound, "not_found")
+		return
+	}
+	var req columnLineage
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
+		return
+	}
+
+	runVersions, datasetOf, err := runConsumedDatasetVersions(r.Context(), api.db, runID)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	mapping, code := normalizeColumnLineage(req, runVersions)
+	if code != "" {
+		api.writeError(w, r, http.StatusBadRequest, code)
+		return
+	}
+	for _, output := range mapping.Outputs {
+		if output.DatasetVersionID == "" {
+			continue
+		}
+		if _, ok := datasetOf[output.DatasetVersionID]; ok {
+			continue
+		}
+		var datasetID string
+		err := api.db.QueryRowContext(
+			r.Context(),
+			`SELECT dataset_id FROM dataset_versions WHERE version_id = $1`,
+			output.DatasetVersionID,
+		).Scan(&datasetID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				api.writeError(w, r, http.StatusNotFound, "dataset_version_not_found")
+				return
+			}
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+		datasetOf[output.DatasetVersionID] = datasetID
+	}
+
+	now := time.Now().UTC()
+	record := runColumnLineage{RunID: runID, Mapping: mapping, DeclaredAt: now, DeclaredBy: identity.Subject}
+	mappingJSON, err := json.Marshal(mapping)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	integrity, err := integritySHA256(record)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(
+		r.Context(),
+		`INSERT INTO run_column_lineage (run_id, mapping, declared_at, declared_by, integrity_sha256)
+		 VALUES ($1,$2,$3,$4,$5)`,
+		runID,
+		mappingJSON,
+		now,
+		identity.Subject,
+		integrity,
+	); err != nil {
+		if isUniqueViolation(err) {
+			api.writeError(w, r, http.StatusConflict, "column_lineage_already_declared")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	edges := columnLineageWriter{ctx: r.Context(), tx: tx, runID: runID, actor: identity.Subject, requestID: r.Header.Get("X-Request-Id"), now: now}
+	for _, input := range mapping.Inputs {
+		for _, column := range input.Columns {
+			nodeID := lineageevent.ColumnNodeID(input.DatasetVersionID, column)
+			if err := edges.datasetColumn(nodeID, datasetOf[input.DatasetVersionID], input.DatasetVersionID, column); err != nil {
+				api.writeError(w, r, http.StatusInternalServerError, "lineage_write_failed")
+				return
+			}
+			if err := edges.edge(lineageevent.NodeDatasetColumn, nodeID, "used_by", "experiment_run", runID, map[string]any{
+				"dataset_id":         datasetOf[input.DatasetVersionID],
+				"dataset_version_id": input.DatasetVersionID,
+				"column":             column,
+			}); err != nil {
+				api.writeError(w, r, http.StatusInternalServerError, "lineage_write_failed")
+				return
+			}
+		}
+	}
+	for _, output := range mapping.Outputs {
+		nodeType, nodeID := output.outputNode(runID)
+		var err error
+		if nodeType == lineageevent.NodeDatasetColumn {
+			err = edges.datasetColumn(nodeID, datasetOf[output.DatasetVersionID], output.DatasetVersionID, output.Name)
+		} else {
+			err = edges.runFeature(nodeID, output.Name)
+		}
+		if err == nil {
+			err = edges.edge("experiment_run", runID, "produced", nodeType, nodeID, map[string]any{
+				"column":             output.Name,
+				"dataset_version_id": output.DatasetVersionID,
+			})
+		}
+		for _, ref := range output.DerivedFrom {
+			if err != nil {
+				break
+			}
+			err = edges.edge(lineageevent.NodeDatasetColumn, lineageevent.ColumnNodeID(ref.DatasetVersionID, ref.Column), "derived_into", nodeType, nodeID, map[string]any{
+				"run_id": runID,
+			})
+		}
+		if err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "lineage_write_failed")
+			return
+		}
+	}
+
+	if err := auditlog.Enqueue(r.Context(), tx, auditlog.Event{
+		OccurredAt:   now,
+		Actor:        identity.Subject,
+		Action:       "experiment_run.column_lineage",
+		ResourceType: "experiment_run",
+		ResourceID:   runID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload: map[string]any{
+			"service":          "experiments",
+			"inputs":           len(mapping.Inputs),
+			"outputs":          len(mapping.Outputs),
+			"integrity_sha256": integrity,
+		},
+	}); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	api.writeJSON(w, http.StatusCreated, record)
+}
+
+func (api *experimentsAPI) handleGetRunColumnLineage(w http.ResponseWriter, r *http.Request) {
+	runID := strings.TrimSpace(r.PathValue("run_id"))
+	if runID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "run_id_required")
+		return
+	}
+	var (
+		record      = runColumnLineage{RunID: runID}
+		mappingJSON []byte
+	)
+	err := api.db.QueryRowContext(
+		r.Context(),
+		`SELECT mapping, declared_at, declared_by FROM run_column_lineage WHERE run_id = $1`,
+		runID,
+	).Scan(&mappingJSON, &record.DeclaredAt, &record.DeclaredBy)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if err := json.Unmarshal(mappingJSON, &record.Mapping); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	record.DeclaredAt = record.DeclaredAt.UTC()
+	api.writeJSON(w, http.StatusOK, record)
+}
+
+// runConsumedDatasetVersions returns the dataset versions a run consumed,
+// with the dataset of each.
+func runConsumedDatasetVersions(ctx context.Context, db *sql.DB, runID string) (map[string]struct{}, map[string]string, error) {
+	rows, err := db.QueryContext(
+		ctx,
+		`SELECT dataset_version_id, dataset_id FROM experiment_run_datasets WHERE run_id = $1`,
+		runID,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	versions := make(map[string]struct{})
+	datasets := make(map[string]string)
+	for rows.Next() {
+		var versionID, datasetID string
+		if err := rows.Scan(&versionID, &datasetID); err != nil {
+			return nil, nil, err
+		}
+		versionID = strings.TrimSpace(versionID)
+		versions[versionID] = struct{}{}
+		datasets[versionID] = strings.TrimSpace(datasetID)
+	}
+	return versions, datasets, rows.Err()
+}
+
+// columnLineageWriter writes column nodes and their edges in one transaction.
+type columnLineageWriter struct {
+	ctx       context.Context
+	tx        *sql.Tx
+	runID     string
+	actor     string
+	requestID string
+	now       time.Time
+}
+
+func (w columnLineageWriter) edge(subjectType, subjectID, predicate, objectType, objectID string, metadata map[string]any) error {
+	err := lineageevent.Enqueue(w.ctx, w.tx, lineageevent.Event{
+		OccurredAt:  w.now,
+		Actor:       w.actor,
+		RequestID:   w.requestID,
+		SubjectType: subjectType,
+		SubjectID:   subjectID,
+		Predicate:   predicate,
+		ObjectType:  objectType,
+		ObjectID:    objectID,
+		Metadata:    metadata,
+	})
+	return err
+}
+
+// datasetColumn registers a column of a dataset version; the first run to
+// declare it also links it to the version.
+func (w columnLineageWriter) datasetColumn(nodeID, datasetID, versionID, column string) error {
+	res, err := w.tx.ExecContext(
+		w.ctx,
+		`INSERT INTO lineage_columns (node_type, node_id, column_name, dataset_id, dataset_version_id, created_at)
+		 VALUES ($1,$2,$3,$4,$5,$6)
+		 ON CONFLICT (node_type, node_id) DO NOTHING`,
+		lineageevent.NodeDatasetColumn,
+		nodeID,
+		column,
+		nullString(datasetID),
+		versionID,
+		w.now,
+	)
+	if err != nil {
+		return err
+	}
+	if inserted, _ := res.RowsAffected(); inserted == 0 {
+		return nil
+	}
+	return w.edge("dataset_version", versionID, "has_column", lineageevent.NodeDatasetColumn, nodeID, map[string]any{
+		"dataset_id": datasetID,
+		"column":     column,
+	})
+}
+
+func (w columnLineageWriter) runFeature(nodeID, name string) error {
+	_, err := w.tx.ExecContext(
+		w.ctx,
+		`INSERT INTO lineage_columns (node_type, node_id, column_name, run_id, created_at)
+		 VALUES ($1,$2,$3,$4,$5)`,
+		lineageevent.NodeRunFeature,
+		nodeID,
+		name,
+		w.runID,
+		w.now,
+	)
+	return err
+}
@@ -0,0 +1,50 @@
+package experiments
+
+import (
+	"testing"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/lineageevent"
+)
+
+func TestNormalizeColumnLineage(t *testing.T) {
+	runVersions := map[string]struct{}{"dv-1": {}, "dv-2": {}}
+	in := columnLineage{
+		Inputs: []columnLineageInput{
+			{DatasetVersionID: " dv-1 ", Columns: []string{"ssn", "age", "ssn"}},
+		},
+		Outputs: []columnLineageOutput{
+			{Name: "risk_score", DerivedFrom: []columnRef{{DatasetVersionID: "dv-1", Column: "ssn"}, {DatasetVersionID: "dv-1", Column: "ssn"}}},
+			{Name: "age_bucket", DatasetVersionID: "dv-9", DerivedFrom: []columnRef{{DatasetVersionID: "dv-1", Column: "age"}}},
+		},
+	}
+	got, code := normalizeColumnLineage(in, runVersions)
+	if code != "" {
+		t.Fatalf("code = %q", code)
+	}
+	if len(got.Inputs[0].Columns) != 2 || got.Inputs[0].DatasetVersionID != "dv-1" || len(got.Outputs[0].DerivedFrom) != 1 {
+		t.Fatalf("mapping = %+v", got)
+	}
+	if typ, id := got.Outputs[0].outputNode("run-1"); typ != lineageevent.NodeRunFeature || id != "run-1#risk_score" {
+		t.Fatalf("feature node = %s %s", typ, id)
+	}
+	if typ, id := got.Outputs[1].outputNode("run-1"); typ != lineageevent.NodeDatasetColumn || id != "dv-9#age_bucket" {
+		t.Fatalf("column node = %s %s", typ, id)
+	}
+
+	cases := map[string]columnLineage{
+		"column_lineage_dataset_not_used": {Inputs: []columnLineageInput{{DatasetVersionID: "dv-3", Columns: []string{"a"}}}},
+		"column_lineage_input_undeclared": {
+			Inputs:  []columnLineageInput{{DatasetVersionID: "dv-1", Columns: []string{"a"}}},
+			Outputs: []columnLineageOutput{{Name: "f", DerivedFrom: []columnRef{{DatasetVersionID: "dv-2", Column: "a"}}}},
+		},
+		"column_lineage_invalid": {Inputs: []columnLineageInput{{DatasetVersionID: "dv-1", Columns: []string{" a"}}}},
+	}
+	for want, mapping := range cases {
+		if _, code := normalizeColumnLineage(mapping, runVersions); code != want {
+			t.Errorf("code = %q, want %q", code, want)
+		}
+	}
+	if _, code := normalizeColumnLineage(columnLineage{}, runVersions); code != "column_lineage_invalid" {
+		t.Errorf("empty mapping code = %q", code)
+	}
+}
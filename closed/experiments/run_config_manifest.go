@@ -0,0 +1,285 @@
+package experiments
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/buildinfo"
+	"github.com/animus-labs/animus-go/closed/internal/repo/postgres"
+)
+
+const runConfigManifestSchema = "animus.run-config/v1"
+
+const (
+	evidenceConfigManifestName      = "config_manifest.json"
+	evidenceConfigManifestSignature = "config_manifest.sig.json"
+)
+
+// runConfigEnvKeys are the settings recorded in run config manifests: the
+// toggles that change how runs are gated, executed, dispatched and
+// accounted. Secrets are never listed.
+var runConfigEnvKeys = []string{
+	"ANIMUS_CARBON_DEFAULT_GRID_FACTOR",
+	"ANIMUS_CARBON_DEFAULT_REGION",
+	"ANIMUS_CARBON_GRID_FACTORS",
+	"ANIMUS_CHECKPOINT_URL_TTL",
+	"ANIMUS_DATAPLANE_URL",
+	"ANIMUS_DATASET_URL_TTL",
+	"ANIMUS_DP_HEARTBEAT_STALE_AFTER",
+	"ANIMUS_ENERGY_CPU_TDP_WATTS",
+	"ANIMUS_ENERGY_GPU_TDP_WATTS",
+	"ANIMUS_EVIDENCE_SIGNING_MODE",
+	"ANIMUS_METRIC_ANOMALY_ENABLED",
+	"ANIMUS_METRIC_ANOMALY_FAIL_NONFINITE_STEPS",
+	"ANIMUS_METRIC_ANOMALY_FAIL_ON_DIVERGENCE",
+	"ANIMUS_PIPELINE_ORCHESTRATOR_INTERVAL",
+	"ANIMUS_PROFILE",
+	"ANIMUS_RUN_IMAGE_VERIFY_MODE",
+	"ANIMUS_RUN_IMAGE_VERIFY_REQUIRE_SBOM",
+	"ANIMUS_RUN_QUEUE_MAX_CONCURRENT",
+	"ANIMUS_RUN_QUEUE_MAX_CONCURRENT_PER_PROJECT",
+	"ANIMUS_RUN_TOKEN_TTL",
+	"ANIMUS_TRAINING_EXECUTOR",
+}
+
+// runConfigEnvironment returns the set runConfigEnvKeys; unset keys are left
+// out, so the manifest shows which defaults applied.
+func runConfigEnvironment(lookup func(string) (string, bool)) map[string]string {
+	out := make(map[string]string)
+	for _, key := range runConfigEnvKeys {
+		if value, ok := lookup(key); ok {
+			out[key] = strings.TrimSpace(value)
+		}
+	}
+	return out
+}
+
+type runConfigPolicy struct {
+	PolicyID        string `json:"policy_id"`
+	PolicyName      string `json:"policy_name"`
+	PolicyVersionID string `json:"policy_version_id"`
+	Version         int    `json:"version"`
+	SpecSHA256      string `json:"spec_sha256"`
+}
+
+type runConfigDataset struct {
+	DatasetVersionID    string            `json:"dataset_version_id"`
+	DatasetID           string            `json:"dataset_id"`
+	QualityRuleID       string            `json:"quality_rule_id,omitempty"`
+	QualityRuleSHA256   string            `json:"quality_rule_sha256,omitempty"`
+	QualityEvaluationID string            `json:"quality_evaluation_id,omitempty"`
+	QualityGateStatus   string            `json:"quality_gate_status,omitempty"`
+	LifecycleState      string            `json:"lifecycle_state,omitempty"`
+	Policies            []runConfigPolicy `json:"policies"`
+}
+
+// runConfigManifest is the configuration a run was created under: the
+// policy versions evaluated for each dataset, the quality rule behind each
+// gate, the service build and schema version, and the run-relevant settings.
+type runConfigManifest struct {
+	Schema        string             `json:"schema"`
+	RunID         string             `json:"run_id"`
+	ProjectID     string             `json:"project_id"`
+	ExperimentID  string             `json:"experiment_id"`
+	CapturedAt    time.Time          `json:"captured_at"`
+	Service       string             `json:"service"`
+	Build         buildinfo.Info     `json:"build"`
+	SchemaVersion int64              `json:"schema_version"`
+	Datasets      []runConfigDataset `json:"datasets"`
+	Environment   map[string]string  `json:"environment"`
+}
+
+// runConfigManifestRecord is a signed manifest. The signature covers the
+// SHA-256 of Manifest byte for byte, which is why the manifest is stored and
+// exported as the exact bytes that were hashed. Those bytes are compact JSON,
+// which encoding/json embeds in responses unchanged.
+type runConfigManifestRecord struct {
+	RunID        string          `json:"run_id"`
+	Manifest     json.RawMessage `json:"manifest"`
+	SHA256       string          `json:"manifest_sha256"`
+	Signature    string          `json:"signature"`
+	SignatureAlg string          `json:"signature_alg"`
+	SigningKeyID string          `json:"signing_key_id,omitempty"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+// buildRunConfigManifest assembles the manifest of run from its gated
+// datasets; q reads the quality rules and the schema version, usually in
+// the transaction inserting the run.
+func (api *experimentsAPI) buildRunConfigManifest(ctx context.Context, q postgres.DB, run experimentRunInsert) (runConfigManifest, error) {
+	manifest := runConfigManifest{
+		Schema:       runConfigManifestSchema,
+		RunID:        run.RunID,
+		ProjectID:    run.ProjectID,
+		ExperimentID: run.ExperimentID,
+		CapturedAt:   run.CreatedAt,
+		Service:      "experiments",
+		Build:        buildinfo.Get(),
+		Datasets:     make([]runConfigDataset, 0, len(run.Datasets)),
+		Environment:  api.runConfigEnv,
+	}
+	if manifest.Environment == nil {
+		manifest.Environment = map[string]string{}
+	}
+	if err := q.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&manifest.SchemaVersion); err != nil {
+		return runConfigManifest{}, err
+	}
+	for _, dataset := range run.Datasets {
+		entry := runConfigDataset{
+			DatasetVersionID:    dataset.VersionID,
+			DatasetID:           dataset.Gate.DatasetID,
+			QualityRuleID:       dataset.Gate.RuleID,
+			QualityEvaluationID: dataset.Gate.EvaluationID,
+			QualityGateStatus:   dataset.Gate.Status,
+			LifecycleState:      string(dataset.Gate.Lifecycle),
+			Policies:            runConfigPolicies(dataset.Policies),
+		}
+		if entry.QualityRuleID != "" {
+			err := q.QueryRowContext(ctx, `SELECT integrity_sha256 FROM quality_rules WHERE rule_id = $1`, entry.QualityRuleID).Scan(&entry.QualityRuleSHA256)
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return runConfigManifest{}, err
+			}
+		}
+		manifest.Datasets = append(manifest.Datasets, entry)
+	}
+	return manifest, nil
+}
+
+func runConfigPolicies(records []policyVersionRecord) []runConfigPolicy {
+	out := make([]runConfigPolicy, 0, len(records))
+	for _, record := range records {
+		out = append(out, runConfigPolicy{
+			PolicyID:        record.PolicyID,
+			PolicyName:      record.PolicyName,
+			PolicyVersionID: record.PolicyVersionID,
+			Version:         record.Version,
+			SpecSHA256:      record.SpecSHA256,
+		})
+	}
+	slices.SortFunc(out, func(a, b runConfigPolicy) int { return strings.Compare(a.PolicyVersionID, b.PolicyVersionID) })
+	return out
+}
+
+// signRunConfigManifest signs the manifest like an evidence bundle: with the
+// Ed25519 evidence key when configured, else with the HMAC secret.
+func (api *experimentsAPI) signRunConfigManifest(manifest runConfigManifest) (*runConfigManifestRecord, error) {
+	payload, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, err
+	}
+	sha := sha256HexBytes(payload)
+	signature, alg, keyID, err := api.signEvidenceBundle(sha)
+	if err != nil {
+		return nil, err
+	}
+	return &runConfigManifestRecord{
+		RunID:        manifest.RunID,
+		Manifest:     payload,
+		SHA256:       sha,
+		Signature:    signature,
+		SignatureAlg: alg,
+		SigningKeyID: keyID,
+		CreatedAt:    manifest.CapturedAt,
+	}, nil
+}
+
+// prepareRunConfigManifest builds and signs the manifest of run, to be
+// written by insertExperimentRun.
+func (api *experimentsAPI) prepareRunConfigManifest(ctx context.Context, q postgres.DB, run *experimentRunInsert) error {
+	manifest, err := api.buildRunConfigManifest(ctx, q, *run)
+	if err != nil {
+		return err
+	}
+	run.ConfigManifest, err = api.signRunConfigManifest(manifest)
+	return err
+}
+
+func insertRunConfigManifest(ctx context.Context, tx *sql.Tx, record *runConfigManifestRecord) error {
+	_, err := tx.ExecContext(
+		ctx,
+		`INSERT INTO experiment_run_config_manifests (run_id, manifest, manifest_sha256, signature, signature_alg, signing_key_id, created_at)
+		 VALUES ($1,$2,$3,$4,$5,$6,$7)`,
+		record.RunID,
+		string(record.Manifest),
+		record.SHA256,
+		record.Signature,
+		record.SignatureAlg,
+		nullString(record.SigningKeyID),
+		record.CreatedAt,
+	)
+	return err
+}
+
+// getRunConfigManifest returns the run's manifest; sql.ErrNoRows for runs
+// created before manifests were recorded.
+func getRunConfigManifest(ctx context.Context, db *sql.DB, runID string) (runConfigManifestRecord, error) {
+	var (
+		out          runConfigManifestRecord
+		manifest     string
+		signingKeyID sql.NullString
+	)
+	err := db.QueryRowContext(
+		ctx,
+		`SELECT run_id, manifest, manifest_sha256, signature, signature_alg, signing_key_id, created_at
+		 FROM experiment_run_config_manifests
+		 WHERE run_id = $1`,
+		runID,
+	).Scan(&out.RunID, &manifest, &out.SHA256, &out.Signature, &out.SignatureAlg, &signingKeyID, &out.CreatedAt)
+	if err != nil {
+		return runConfigManifestRecord{}, err
+	}
+	out.Manifest = json.RawMessage(manifest)
+	out.SigningKeyID = signingKeyID.String
+	out.CreatedAt = out.CreatedAt.UTC()
+	return out, nil
+}
+
+func (api *experimentsAPI) handleGetRunConfigManifest(w http.ResponseWriter, r *http.Request) {
+	runID := strings.TrimSpace(r.PathValue("run_id"))
+	if runID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "run_id_required")
+		return
+	}
+	record, err := getRunConfigManifest(r.Context(), api.db, runID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	api.writeJSON(w, http.StatusOK, record)
+}
+
+// evidenceConfigManifestFiles exports the manifest bytes unchanged, so the
+// signature in the companion file can be checked against them, or nothing
+// for runs without a manifest.
+func evidenceConfigManifestFiles(ctx context.Context, db *sql.DB, runID string) ([]evidenceBundleFile, error) {
+	record, err := getRunConfigManifest(ctx, db, runID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	signature, err := json.MarshalIndent(map[string]any{
+		"manifest_sha256": record.SHA256,
+		"signature":       record.Signature,
+		"signature_alg":   record.SignatureAlg,
+		"signing_key_id":  record.SigningKeyID,
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return []evidenceBundleFile{
+		{Name: evidenceConfigManifestName, ContentType: "application/json", Data: record.Manifest},
+		{Name: evidenceConfigManifestSignature, ContentType: "application/json", Data: signature},
+	}, nil
+}
@@ -0,0 +1,78 @@
+package experiments
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRunConfigEnvironment(t *testing.T) {
+	env := map[string]string{
+		"ANIMUS_TRAINING_EXECUTOR":       "dataplane",
+		"ANIMUS_RUN_IMAGE_VERIFY_MODE":   " enforce ",
+		"ANIMUS_EVIDENCE_SIGNING_SECRET": "s3cr3t",
+	}
+	got := runConfigEnvironment(func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	})
+	want := map[string]string{
+		"ANIMUS_TRAINING_EXECUTOR":     "dataplane",
+		"ANIMUS_RUN_IMAGE_VERIFY_MODE": "enforce",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("environment = %v, want %v", got, want)
+	}
+}
+
+func TestSignRunConfigManifest(t *testing.T) {
+	api := &experimentsAPI{evidenceSigningSecret: "secret"}
+	manifest := runConfigManifest{
+		Schema:     runConfigManifestSchema,
+		RunID:      "run-1",
+		CapturedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Datasets: []runConfigDataset{{
+			DatasetVersionID: "dv-1",
+			Policies: runConfigPolicies([]policyVersionRecord{
+				{PolicyID: "p-2", PolicyVersionID: "pv-b", Version: 3},
+				{PolicyID: "p-1", PolicyVersionID: "pv-a", Version: 1},
+			}),
+		}},
+		Environment: map[string]string{},
+	}
+
+	record, err := api.signRunConfigManifest(manifest)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	if record.SHA256 != sha256HexBytes(record.Manifest) {
+		t.Fatalf("sha256 does not cover the manifest bytes")
+	}
+	want, err := computeEvidenceSignature("secret", record.SHA256)
+	if err != nil {
+		t.Fatalf("signature: %v", err)
+	}
+	if record.Signature != want || record.SignatureAlg != evidenceSignatureAlg || record.RunID != "run-1" {
+		t.Fatalf("record = %+v", record)
+	}
+
+	response, err := json.Marshal(record)
+	if err != nil {
+		t.Fatalf("marshal record: %v", err)
+	}
+	var served struct {
+		Manifest json.RawMessage `json:"manifest"`
+	}
+	if err := json.Unmarshal(response, &served); err != nil || sha256HexBytes(served.Manifest) != record.SHA256 {
+		t.Fatalf("served manifest does not match the signed bytes: %v", err)
+	}
+
+	var decoded runConfigManifest
+	if err := json.Unmarshal(record.Manifest, &decoded); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if policies := decoded.Datasets[0].Policies; policies[0].PolicyVersionID != "pv-a" || policies[1].PolicyVersionID != "pv-b" {
+		t.Fatalf("policies not ordered: %+v", policies)
+	}
+}
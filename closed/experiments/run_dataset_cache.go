@@ -0,0 +1,236 @@
+package experiments
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/dataplane"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+	"github.com/animus-labs/animus-go/closed/internal/repo/postgres"
+)
+
+// defaultDatasetURLTTL is how long presigned dataset URLs stay valid when
+// ANIMUS_DATASET_URL_TTL is unset. Like checkpoints, datasets are read at
+// start-up, so the TTL only has to outlast queueing.
+const defaultDatasetURLTTL = 6 * time.Hour
+
+// datasetCacheKey is the node-local cache key of dataset content: versions
+// with the same bytes share one cache entry.
+func datasetCacheKey(sha256Hex string) string {
+	return "sha256/" + strings.ToLower(strings.TrimSpace(sha256Hex))
+}
+
+// runDatasetsForExecution returns the dataset versions bound to runID with
+// fresh presigned download URLs and their cache keys. Encrypted versions
+// are left out: the run fetches those through the dataset registry.
+func (api *experimentsAPI) runDatasetsForExecution(ctx context.Context, db postgres.DB, runID string) ([]dataplane.RunDataset, error) {
+	rows, err := db.QueryContext(
+		ctx,
+		`SELECT b.key, v.version_id, v.content_sha256, COALESCE(v.size_bytes, 0), v.object_key, COALESCE(ref.store_version_id, '')
+		 FROM runs r
+		 CROSS JOIN LATERAL jsonb_each_text(COALESCE(r.run_spec -> 'datasetBindings', '{}'::jsonb)) b
+		 JOIN dataset_versions v ON v.version_id = b.value AND v.project_id = r.project_id
+		 LEFT JOIN dataset_version_references ref ON ref.version_id = v.version_id
+		 WHERE r.run_id = $1 AND COALESCE(v.encryption_alg, '') = ''
+		 ORDER BY b.key`,
+		runID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type datasetObject struct {
+		dataset   dataplane.RunDataset
+		objectKey string
+		// storeVersionID pins reference versions in versioned buckets.
+		storeVersionID string
+	}
+	objects := []datasetObject{}
+	for rows.Next() {
+		var item datasetObject
+		if err := rows.Scan(&item.dataset.Binding, &item.dataset.VersionID, &item.dataset.SHA256, &item.dataset.SizeBytes, &item.objectKey, &item.storeVersionID); err != nil {
+			return nil, err
+		}
+		objects = append(objects, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(objects) == 0 {
+		return nil, nil
+	}
+	if api.store == nil {
+		return nil, errors.New("object store unavailable")
+	}
+	ttl := api.datasetURLTTL
+	if ttl <= 0 {
+		ttl = defaultDatasetURLTTL
+	}
+	expiresAt := time.Now().UTC().Add(ttl)
+	out := make([]dataplane.RunDataset, 0, len(objects))
+	for _, item := range objects {
+		// Reference versions are read in place from the bucket they point into.
+		bucket, key := api.storeCfg.DatasetObject(item.objectKey)
+		var params url.Values
+		if item.storeVersionID != "" {
+			params = url.Values{"versionId": {item.storeVersionID}}
+		}
+		location, err := api.store.PresignedGetObject(ctx, bucket, key, ttl, params)
+		if err != nil {
+			return nil, err
+		}
+		dataset := item.dataset
+		dataset.URL = location.String()
+		dataset.ExpiresAt = expiresAt
+		dataset.CacheKey = datasetCacheKey(dataset.SHA256)
+		out = append(out, dataset)
+	}
+	return out, nil
+}
+
+func validDatasetCacheEntries(entries []dataplane.DatasetCacheEntry) bool {
+	if len(entries) == 0 {
+		return false
+	}
+	for _, entry := range entries {
+		if strings.TrimSpace(entry.VersionID) == "" || !sha256HexPattern.MatchString(strings.ToLower(entry.SHA256)) || entry.SizeBytes < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// handleDPDatasetCache records an executor's dataset cache hits for a run
+// in the DP event ledger.
+func (api *experimentsAPI) handleDPDatasetCache(w http.ResponseWriter, r *http.Request) {
+	identity, ok := auth.IdentityFromContext(r.Context())
+	if !ok || strings.TrimSpace(identity.Subject) == "" {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	runID := strings.TrimSpace(r.PathValue("run_id"))
+	if runID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "run_id_required")
+		return
+	}
+
+	var req dataplane.DatasetCacheReport
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
+		return
+	}
+	if strings.TrimSpace(req.RunID) == "" {
+		api.writeError(w, r, http.StatusBadRequest, "run_id_required")
+		return
+	}
+	if strings.TrimSpace(req.EventID) == "" {
+		api.writeError(w, r, http.StatusBadRequest, "event_id_required")
+		return
+	}
+	if strings.TrimSpace(req.ProjectID) == "" {
+		api.writeError(w, r, http.StatusBadRequest, "project_id_required")
+		return
+	}
+	if !strings.EqualFold(req.RunID, runID) {
+		api.writeError(w, r, http.StatusBadRequest, "run_id_mismatch")
+		return
+	}
+	if req.EmittedAt.IsZero() {
+		api.writeError(w, r, http.StatusBadRequest, "emitted_at_required")
+		return
+	}
+	if !validDatasetCacheEntries(req.Entries) {
+		api.writeError(w, r, http.StatusBadRequest, "dataset_cache_entries_invalid")
+		return
+	}
+
+	projectID := strings.TrimSpace(req.ProjectID)
+	runStore := postgres.NewRunSpecStore(api.db)
+	if runStore == nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if _, err := runStore.GetRun(r.Context(), projectID, runID); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	payloadJSON, err := json.Marshal(req)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	integrity, err := integritySHA256(dpEventIntegrityInput{
+		EventID:   strings.TrimSpace(req.EventID),
+		RunID:     runID,
+		ProjectID: projectID,
+		EventType: dataplane.EventTypeDatasetCache,
+		EmittedAt: req.EmittedAt.UTC(),
+		Payload:   payloadJSON,
+	})
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	dpStore := postgres.NewDPEventStore(tx)
+	if dpStore == nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	inserted, err := dpStore.InsertEvent(r.Context(), postgres.RunDPEventRecord{
+		EventID:      req.EventID,
+		RunID:        runID,
+		ProjectID:    projectID,
+		EventType:    dataplane.EventTypeDatasetCache,
+		Payload:      payloadJSON,
+		EmittedAt:    req.EmittedAt.UTC(),
+		ReceivedAt:   time.Now().UTC(),
+		IntegritySHA: integrity,
+	})
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	duplicate := !inserted
+	if duplicate {
+		if err := ensureDPEventMatches(r.Context(), dpStore, req.EventID, runID, projectID, dataplane.EventTypeDatasetCache); err != nil {
+			if errors.Is(err, errDPEventMismatch) {
+				api.writeError(w, r, http.StatusConflict, "event_conflict")
+				return
+			}
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	api.writeJSON(w, http.StatusOK, dataplane.DatasetCacheReportResponse{
+		Accepted:  !duplicate,
+		Duplicate: duplicate,
+	})
+}
@@ -0,0 +1,32 @@
+package experiments
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/animus-labs/animus-go/closed/internal/dataplane"
+)
+
+func TestDatasetCacheKey(t *testing.T) {
+	sha := strings.Repeat("A", 64)
+	if got := datasetCacheKey(" " + sha + " "); got != "sha256/"+strings.Repeat("a", 64) {
+		t.Fatalf("datasetCacheKey()=%q", got)
+	}
+}
+
+func TestValidDatasetCacheEntries(t *testing.T) {
+	sha := strings.Repeat("a", 64)
+	if !validDatasetCacheEntries([]dataplane.DatasetCacheEntry{{VersionID: "dv-1", SHA256: sha, Hit: true, SizeBytes: 10}}) {
+		t.Fatalf("expected entry to be accepted")
+	}
+	for _, bad := range [][]dataplane.DatasetCacheEntry{
+		nil,
+		{{SHA256: sha}},
+		{{VersionID: "dv-1", SHA256: "abc"}},
+		{{VersionID: "dv-1", SHA256: sha, SizeBytes: -1}},
+	} {
+		if validDatasetCacheEntries(bad) {
+			t.Fatalf("expected %+v to be rejected", bad)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package experiments
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/domain"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	"github.com/animus-labs/animus-go/closed/internal/platform/policy"
+	repopg "github.com/animus-labs/animus-go/closed/internal/repo/postgres"
+)
+
+// datasetAge is what the registry knows about how old a dataset version is.
+type datasetAge struct {
+	VersionCreatedAt time.Time
+	// ReferenceAt is the latest version of the dataset, or the dataset itself
+	// when it has none; freshness is measured from it.
+	ReferenceAt time.Time
+	MaxAgeDays  int
+}
+
+func (a datasetAge) policyFields(now time.Time) (*float64, *bool) {
+	ageDays := now.Sub(a.VersionCreatedAt).Hours() / 24
+	if ageDays < 0 {
+		ageDays = 0
+	}
+	if a.MaxAgeDays <= 0 {
+		return &ageDays, nil
+	}
+	stale := now.After(a.ReferenceAt.Add(time.Duration(a.MaxAgeDays) * 24 * time.Hour))
+	return &ageDays, &stale
+}
+
+func (api *experimentsAPI) loadDatasetAge(ctx context.Context, datasetVersionID string) (datasetAge, error) {
+	var (
+		out         datasetAge
+		maxAgeDays  sql.NullInt64
+		latestAt    sql.NullTime
+		datasetTime time.Time
+	)
+	err := api.db.QueryRowContext(
+		ctx,
+		`SELECT v.created_at, d.created_at, e.max_age_days, lv.created_at
+		 FROM dataset_versions v
+		 JOIN datasets d ON d.dataset_id = v.dataset_id
+		 LEFT JOIN dataset_freshness_expectations e ON e.dataset_id = v.dataset_id
+		 LEFT JOIN LATERAL (
+			SELECT created_at
+			FROM dataset_versions
+			WHERE dataset_id = v.dataset_id
+			ORDER BY created_at DESC, ordinal DESC
+			LIMIT 1
+		 ) lv ON true
+		 WHERE v.version_id = $1`,
+		datasetVersionID,
+	).Scan(&out.VersionCreatedAt, &datasetTime, &maxAgeDays, &latestAt)
+	if err != nil {
+		return datasetAge{}, err
+	}
+	out.VersionCreatedAt = out.VersionCreatedAt.UTC()
+	out.ReferenceAt = datasetTime.UTC()
+	if latestAt.Valid {
+		out.ReferenceAt = latestAt.Time.UTC()
+	}
+	if maxAgeDays.Valid {
+		out.MaxAgeDays = int(maxAgeDays.Int64)
+	}
+	return out, nil
+}
+
+// loadDatasetDriftScore returns the score of the latest drift report of a
+// dataset version, or nil when none was computed.
+func (api *experimentsAPI) loadDatasetDriftScore(ctx context.Context, datasetVersionID string) (*float64, error) {
+	var score float64
+	err := api.db.QueryRowContext(
+		ctx,
+		`SELECT score
+		 FROM dataset_drift_reports
+		 WHERE version_id = $1
+		 ORDER BY created_at DESC
+		 LIMIT 1`,
+		datasetVersionID,
+	).Scan(&score)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &score, nil
+}
+
+func (api *experimentsAPI) loadDatasetLifecycle(ctx context.Context, datasetID string) (domain.DatasetLifecycle, error) {
+	return repopg.NewDatasetLifecycleStore(api.db).Get(ctx, datasetID)
+}
+
+// firstDatasetPolicyMatch returns the first active policy with a rule of the
+// given effect matching the context. Default effects are ignored: run
+// registration only acts on explicit rules.
+func (api *experimentsAPI) firstDatasetPolicyMatch(ctx context.Context, policies []policyVersionRecord, input policy.Context, effect string) (*policyEvaluation, error) {
+	for _, record := range policies {
+		var spec policy.Spec
+		if err := json.Unmarshal(record.SpecJSON, &spec); err != nil {
+			return nil, err
+		}
+		decision, err := api.policyEvaluator.Evaluate(ctx, spec, input)
+		if err != nil {
+			return nil, err
+		}
+		if decision.Reason != "rule_match" || decision.Effect != effect {
+			continue
+		}
+		return &policyEvaluation{
+			PolicyID:        record.PolicyID,
+			PolicyName:      record.PolicyName,
+			PolicyVersionID: record.PolicyVersionID,
+			PolicyVersion:   record.Version,
+			PolicySHA256:    record.SpecSHA256,
+			Decision:        decision,
+		}, nil
+	}
+	return nil, nil
+}
+
+// requireDatasetPolicyAllow evaluates active policies against the dataset a
+// run trains on. The context carries only dataset and experiment fields, so
+// rules over actor, git or image never match here. Versions of archived
+// datasets additionally need a matching allow rule. It returns the policy
+// versions evaluated, for the run's config manifest.
+func (api *experimentsAPI) requireDatasetPolicyAllow(w http.ResponseWriter, r *http.Request, identity auth.Identity, projectID string, datasetVersionID string, experimentID string, gate gateDecision) ([]policyVersionRecord, bool) {
+	ctx := r.Context()
+	policies, err := api.loadScopedPolicyVersions(ctx, policyScope{
+		ProjectID:      projectID,
+		ExperimentID:   experimentID,
+		DatasetClasses: gate.Protection.ClassificationLabels,
+	})
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return nil, false
+	}
+	archived := gate.Lifecycle == domain.DatasetLifecycleArchived
+	if len(policies) == 0 && !archived {
+		return policies, true
+	}
+	age, err := api.loadDatasetAge(ctx, datasetVersionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return nil, false
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return nil, false
+	}
+
+	driftScore, err := api.loadDatasetDriftScore(ctx, datasetVersionID)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return nil, false
+	}
+
+	now := time.Now().UTC()
+	ageDays, stale := age.policyFields(now)
+	dataset := policy.DatasetContext{
+		DatasetID:      strings.TrimSpace(gate.DatasetID),
+		VersionID:      strings.TrimSpace(datasetVersionID),
+		SHA256:         strings.TrimSpace(gate.ContentSHA256),
+		AgeDays:        ageDays,
+		Stale:          stale,
+		DriftScore:     driftScore,
+		LifecycleState: string(gate.Lifecycle),
+	}
+	applyDataProtection(&dataset, gate.Protection)
+	policyContext := policy.Context{
+		Dataset:    dataset,
+		Experiment: policy.ExperimentContext{ExperimentID: strings.TrimSpace(experimentID)},
+	}
+	denial, err := api.firstDatasetPolicyMatch(ctx, policies, policyContext, policy.EffectDeny)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return nil, false
+	}
+	if denial == nil && archived {
+		allowed, err := api.firstDatasetPolicyMatch(ctx, policies, policyContext, policy.EffectAllow)
+		if err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return nil, false
+		}
+		if allowed == nil {
+			_ = auditlog.Enqueue(ctx, api.db, auditlog.Event{
+				OccurredAt:   now,
+				Actor:        identity.Subject,
+				Action:       "quality_gate.block",
+				ResourceType: "dataset_version",
+				ResourceID:   datasetVersionID,
+				RequestID:    r.Header.Get("X-Request-Id"),
+				IP:           httpapi.RequestIP(r.RemoteAddr),
+				UserAgent:    r.UserAgent(),
+				Payload: map[string]any{
+					"service":            "experiments",
+					"dataset_id":         gate.DatasetID,
+					"dataset_version_id": datasetVersionID,
+					"experiment_id":      experimentID,
+					"lifecycle_state":    string(gate.Lifecycle),
+					"reason":             "archived",
+				},
+			})
+			api.writeError(w, r, http.StatusConflict, "dataset_archived")
+			return nil, false
+		}
+	}
+	if denial == nil {
+		return policies, true
+	}
+
+	payload := map[string]any{
+		"service":            "experiments",
+		"dataset_id":         gate.DatasetID,
+		"dataset_version_id": datasetVersionID,
+		"experiment_id":      experimentID,
+		"policy_id":          denial.PolicyID,
+		"policy_version_id":  denial.PolicyVersionID,
+		"rule_id":            denial.Decision.RuleID,
+		"dataset_age_days":   *ageDays,
+		"data_protection":    dataProtectionPayload(gate.Protection),
+		"reason":             "policy_denied",
+	}
+	if stale != nil {
+		payload["dataset_stale"] = *stale
+	}
+	if driftScore != nil {
+		payload["dataset_drift_score"] = *driftScore
+	}
+	_ = auditlog.Enqueue(ctx, api.db, auditlog.Event{
+		OccurredAt:   now,
+		Actor:        identity.Subject,
+		Action:       "quality_gate.block",
+		ResourceType: "dataset_version",
+		ResourceID:   datasetVersionID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload:      payload,
+	})
+	api.writeError(w, r, http.StatusConflict, "policy_denied")
+	return nil, false
+}
@@ -0,0 +1,116 @@
+package experiments
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/policy"
+)
+
+func TestDatasetAgePolicyFields(t *testing.T) {
+	now := time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)
+	age := datasetAge{
+		VersionCreatedAt: now.Add(-10 * 24 * time.Hour),
+		ReferenceAt:      now.Add(-2 * 24 * time.Hour),
+	}
+	ageDays, stale := age.policyFields(now)
+	if ageDays == nil || *ageDays != 10 {
+		t.Fatalf("unexpected age: %v", ageDays)
+	}
+	if stale != nil {
+		t.Fatalf("expected no staleness without an expectation")
+	}
+
+	age.MaxAgeDays = 1
+	if _, stale = age.policyFields(now); stale == nil || !*stale {
+		t.Fatalf("expected stale dataset")
+	}
+	age.MaxAgeDays = 7
+	if _, stale = age.policyFields(now); stale == nil || *stale {
+		t.Fatalf("expected fresh dataset")
+	}
+}
+
+func TestFirstDatasetPolicyMatch(t *testing.T) {
+	specJSON := func(spec policy.Spec) []byte {
+		out, err := json.Marshal(spec)
+		if err != nil {
+			t.Fatalf("marshal spec: %v", err)
+		}
+		return out
+	}
+	defaultDeny := policyVersionRecord{
+		PolicyID: "p-default",
+		SpecJSON: specJSON(policy.Spec{
+			Schema: policy.SpecSchemaV1,
+			Rules: []policy.Rule{{
+				ID:     "allow-prod",
+				Effect: policy.EffectAllow,
+				When:   policy.ConditionGroup{All: []policy.Condition{{Field: "git.ref", Op: "eq", Value: "main"}}},
+			}},
+		}),
+	}
+	maxAge := policyVersionRecord{
+		PolicyID: "p-age",
+		SpecJSON: specJSON(policy.Spec{
+			Schema:        policy.SpecSchemaV1,
+			DefaultEffect: policy.EffectAllow,
+			Rules: []policy.Rule{{
+				ID:     "deny-old",
+				Effect: policy.EffectDeny,
+				When:   policy.ConditionGroup{All: []policy.Condition{{Field: "dataset.age_days", Op: "gt", Value: "30"}}},
+			}},
+		}),
+	}
+	policies := []policyVersionRecord{defaultDeny, maxAge}
+	api := &experimentsAPI{}
+
+	young := 5.0
+	denial, err := api.firstDatasetPolicyMatch(context.Background(), policies, policy.Context{Dataset: policy.DatasetContext{AgeDays: &young}}, policy.EffectDeny)
+	if err != nil {
+		t.Fatalf("evaluate: %v", err)
+	}
+	if denial != nil {
+		t.Fatalf("default deny must not block run registration, got %+v", denial)
+	}
+
+	old := 45.0
+	denial, err = api.firstDatasetPolicyMatch(context.Background(), policies, policy.Context{Dataset: policy.DatasetContext{AgeDays: &old}}, policy.EffectDeny)
+	if err != nil {
+		t.Fatalf("evaluate: %v", err)
+	}
+	if denial == nil || denial.PolicyID != "p-age" || denial.Decision.RuleID != "deny-old" {
+		t.Fatalf("expected deny-old, got %+v", denial)
+	}
+}
+
+func TestArchivedDatasetNeedsAllowRule(t *testing.T) {
+	specJSON, err := json.Marshal(policy.Spec{
+		Schema: policy.SpecSchemaV1,
+		Rules: []policy.Rule{{
+			ID:     "allow-archived-backfill",
+			Effect: policy.EffectAllow,
+			When: policy.ConditionGroup{All: []policy.Condition{
+				{Field: "dataset.lifecycle_state", Op: "eq", Value: "archived"},
+				{Field: "experiment.id", Op: "eq", Value: "exp-backfill"},
+			}},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("marshal spec: %v", err)
+	}
+	policies := []policyVersionRecord{{PolicyID: "p-archive", SpecJSON: specJSON}}
+	archived := policy.DatasetContext{LifecycleState: "archived"}
+	api := &experimentsAPI{}
+
+	allowed, err := api.firstDatasetPolicyMatch(context.Background(), policies, policy.Context{Dataset: archived, Experiment: policy.ExperimentContext{ExperimentID: "exp-backfill"}}, policy.EffectAllow)
+	if err != nil || allowed == nil || allowed.Decision.RuleID != "allow-archived-backfill" {
+		t.Fatalf("expected allow rule match, got %+v (%v)", allowed, err)
+	}
+	allowed, err = api.firstDatasetPolicyMatch(context.Background(), policies, policy.Context{Dataset: archived, Experiment: policy.ExperimentContext{ExperimentID: "exp-other"}}, policy.EffectAllow)
+	if err != nil || allowed != nil {
+		t.Fatalf("expected no allow match, got %+v (%v)", allowed, err)
+	}
+}
@@ -0,0 +1,99 @@
+package experiments
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+)
+
+// maxRunDatasetVersions bounds how many dataset versions a single run may
+// consume; every version is gated and written to lineage inside one request.
+const maxRunDatasetVersions = 32
+
+type runDataset struct {
+	VersionID string
+	Gate      gateDecision
+	Policies  []policyVersionRecord
+}
+
+// runDatasetVersionIDs merges the legacy single dataset_version_id with the
+// dataset_version_ids list. Order is preserved and the legacy value, when set,
+// comes first so it remains the run's primary dataset.
+func runDatasetVersionIDs(primary string, extra []string) []string {
+	ids := uniqueNonEmpty(append([]string{primary}, extra...))
+	if len(ids) == 0 {
+		return nil
+	}
+	return ids
+}
+
+func insertRunDatasets(ctx context.Context, tx *sql.Tx, runID string, datasets []runDataset) error {
+	for i, dataset := range datasets {
+		_, err := tx.ExecContext(
+			ctx,
+			`INSERT INTO experiment_run_datasets (
+				run_id,
+				dataset_version_id,
+				position,
+				dataset_id,
+				content_sha256,
+				quality_rule_id,
+				quality_evaluation_id
+			) VALUES ($1,$2,$3,$4,$5,$6,$7)`,
+			runID,
+			dataset.VersionID,
+			i,
+			dataset.Gate.DatasetID,
+			dataset.Gate.ContentSHA256,
+			nullString(dataset.Gate.RuleID),
+			nullString(dataset.Gate.EvaluationID),
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func fetchRunDatasets(ctx context.Context, tx *sql.Tx, runID string) ([]executionLedgerDataset, error) {
+	rows, err := tx.QueryContext(
+		ctx,
+		`SELECT dataset_id, dataset_version_id, content_sha256
+		 FROM experiment_run_datasets
+		 WHERE run_id = $1
+		 ORDER BY position ASC`,
+		runID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []executionLedgerDataset{}
+	for rows.Next() {
+		var dataset executionLedgerDataset
+		if err := rows.Scan(&dataset.DatasetID, &dataset.VersionID, &dataset.SHA256); err != nil {
+			return nil, err
+		}
+		dataset.DatasetID = strings.TrimSpace(dataset.DatasetID)
+		dataset.VersionID = strings.TrimSpace(dataset.VersionID)
+		dataset.SHA256 = strings.TrimSpace(dataset.SHA256)
+		out = append(out, dataset)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func decodeRunDatasetVersionIDs(raw []byte) []string {
+	if len(raw) == 0 {
+		return nil
+	}
+	var ids []string
+	if err := json.Unmarshal(raw, &ids); err != nil || len(ids) == 0 {
+		return nil
+	}
+	return ids
+}
@@ -0,0 +1,52 @@
+package experiments
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRunDatasetVersionIDs(t *testing.T) {
+	cases := []struct {
+		name    string
+		primary string
+		extra   []string
+		want    []string
+	}{
+		{name: "none", want: nil},
+		{name: "legacy only", primary: "dv-1", want: []string{"dv-1"}},
+		{name: "list only", extra: []string{"dv-2", "dv-1"}, want: []string{"dv-2", "dv-1"}},
+		{name: "legacy first", primary: "dv-3", extra: []string{"dv-1", "dv-2"}, want: []string{"dv-3", "dv-1", "dv-2"}},
+		{name: "dedupe and trim", primary: " dv-1 ", extra: []string{"dv-2", "dv-1", "", "dv-2 "}, want: []string{"dv-1", "dv-2"}},
+	}
+	for _, tc := range cases {
+		if got := runDatasetVersionIDs(tc.primary, tc.extra); !reflect.DeepEqual(got, tc.want) {
+			t.Fatalf("%s: got %v want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestDecodeRunDatasetVersionIDs(t *testing.T) {
+	if got := decodeRunDatasetVersionIDs([]byte(`["dv-1","dv-2"]`)); !reflect.DeepEqual(got, []string{"dv-1", "dv-2"}) {
+		t.Fatalf("unexpected ids: %v", got)
+	}
+	if got := decodeRunDatasetVersionIDs([]byte(`[]`)); got != nil {
+		t.Fatalf("expected nil for empty list, got %v", got)
+	}
+}
+
+func TestLedgerDatasetVersionIDsIncludesLegacyPrimary(t *testing.T) {
+	entry := executionLedgerEntry{
+		Dataset: executionLedgerDataset{VersionID: "dv-1"},
+		Datasets: []executionLedgerDataset{
+			{VersionID: "dv-1"},
+			{VersionID: "dv-2"},
+		},
+	}
+	if got := ledgerDatasetVersionIDs(entry); !reflect.DeepEqual(got, []string{"dv-1", "dv-2"}) {
+		t.Fatalf("unexpected ids: %v", got)
+	}
+	legacy := executionLedgerEntry{Dataset: executionLedgerDataset{VersionID: "dv-9"}}
+	if got := ledgerDatasetVersionIDs(legacy); !reflect.DeepEqual(got, []string{"dv-9"}) {
+		t.Fatalf("unexpected legacy ids: %v", got)
+	}
+}
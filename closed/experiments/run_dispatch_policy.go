@@ -0,0 +1,114 @@
+package experiments
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	"github.com/animus-labs/animus-go/closed/internal/platform/policy"
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+)
+
+const auditRunDispatchBlocked = "run.dispatch_blocked"
+
+// requireRunDispatchAllowed gates dispatch on what the run executes: its
+// images are verified when configured, then active policies are evaluated
+// against the actor, git commit, image (including its latest vulnerability
+// scan) and latest CI report. Like dataset
+// policies, only explicit deny rules act here.
+func (api *experimentsAPI) requireRunDispatchAllowed(w http.ResponseWriter, r *http.Request, identity auth.Identity, runRecord repo.RunRecord) bool {
+	ctx := r.Context()
+	images, err := runSpecImages(runRecord)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return false
+	}
+
+	var image policy.ImageContext
+	if len(images) > 0 {
+		image.Ref = strings.TrimSpace(images[0].Ref)
+		image.Digest = strings.ToLower(strings.TrimSpace(images[0].Digest))
+		if api.runImageVerify.Enabled() {
+			verification, ok := api.checkRunImages(w, r, identity, runRecord, images)
+			if !ok {
+				return false
+			}
+			image = verification.policyImage(image)
+		}
+	}
+
+	policies, err := api.loadScopedPolicyVersions(ctx, policyScope{ProjectID: runRecord.ProjectID})
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return false
+	}
+	if len(policies) == 0 {
+		return true
+	}
+	var spec runSpecPayload
+	if err := json.Unmarshal(runRecord.RunSpec, &spec); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return false
+	}
+	if err := api.imagePolicyVulnerabilities(ctx, &image); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return false
+	}
+	ci, err := api.loadCIPolicyContext(ctx, spec.CodeRef.CommitSHA, image.Digest)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return false
+	}
+	policyContext := policy.Context{
+		Actor: policy.ActorContext{
+			Subject: strings.TrimSpace(identity.Subject),
+			Email:   strings.TrimSpace(identity.Email),
+			Roles:   identity.Roles,
+		},
+		Experiment: policy.ExperimentContext{RunID: runRecord.ID},
+		Git: policy.GitContext{
+			Repo:   strings.TrimSpace(spec.CodeRef.RepoURL),
+			Commit: strings.TrimSpace(spec.CodeRef.CommitSHA),
+		},
+		Image: image,
+		CI:    ci,
+	}
+	denial, err := api.firstDatasetPolicyMatch(ctx, policies, policyContext, policy.EffectDeny)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return false
+	}
+	if denial != nil {
+		api.auditRunDispatchBlocked(r, identity, runRecord, map[string]any{
+			"reason":            "policy_denied",
+			"policy_id":         denial.PolicyID,
+			"policy_version_id": denial.PolicyVersionID,
+			"rule_id":           denial.Decision.RuleID,
+			"ci_report_id":      ci.ReportID,
+		})
+		api.writeError(w, r, http.StatusConflict, "policy_denied")
+		return false
+	}
+	return true
+}
+
+func (api *experimentsAPI) auditRunDispatchBlocked(r *http.Request, identity auth.Identity, runRecord repo.RunRecord, payload map[string]any) {
+	payload["service"] = "experiments"
+	payload["project_id"] = runRecord.ProjectID
+	payload["run_id"] = runRecord.ID
+	_ = auditlog.Enqueue(r.Context(), api.db, auditlog.Event{
+		OccurredAt:   time.Now().UTC(),
+		Actor:        identity.Subject,
+		Action:       auditRunDispatchBlocked,
+		ResourceType: "run",
+		ResourceID:   runRecord.ID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload:      payload,
+	})
+}
@@ -1,4 +1,4 @@
-package main
+package experiments
 
 import (
 	"encoding/json"
@@ -14,6 +14,7 @@ import (
 	"github.com/animus-labs/animus-go/closed/internal/execution/state"
 	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
 	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
 	"github.com/animus-labs/animus-go/closed/internal/repo"
 	"github.com/animus-labs/animus-go/closed/internal/repo/postgres"
 	"github.com/animus-labs/animus-go/closed/internal/service/runs"
@@ -25,6 +26,7 @@ type dryRunResponse struct {
 	State          string                      `json:"state"`
 	AttemptsByStep map[string]int              `json:"attemptsByStep,omitempty"`
 	Existing       bool                        `json:"existing"`
+	ReportID       string                      `json:"reportId,omitempty"`
 	Steps          []executor.DryRunStepResult `json:"steps"`
 }
 
@@ -116,7 +118,7 @@ func (api *experimentsAPI) handleDryRun(w http.ResponseWriter, r *http.Request)
 		Actor:     identity.Subject,
 		RequestID: r.Header.Get("X-Request-Id"),
 		UserAgent: r.UserAgent(),
-		IP:        requestIP(r.RemoteAddr),
+		IP:        httpapi.RequestIP(r.RemoteAddr),
 		Service:   "experiments",
 	}
 	auditAppender := runs.NewAuditAppender(tx)
@@ -137,6 +139,11 @@ func (api *experimentsAPI) handleDryRun(w http.ResponseWriter, r *http.Request)
 			return
 		}
 		attempts := deriveRunStateFromRecords(&execPlan, true, records).AttemptsMap
+		reportID, err := existingDryRunReportID(r, tx, projectID, runID)
+		if err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
 		if err := tx.Commit(); err != nil {
 			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
 			return
@@ -147,6 +154,7 @@ func (api *experimentsAPI) handleDryRun(w http.ResponseWriter, r *http.Request)
 			State:          string(derivedState),
 			AttemptsByStep: attempts,
 			Existing:       true,
+			ReportID:       reportID,
 			Steps:          buildDryRunSummary(execPlan, records),
 		})
 		return
@@ -159,6 +167,11 @@ func (api *experimentsAPI) handleDryRun(w http.ResponseWriter, r *http.Request)
 			return
 		}
 		attempts := deriveRunStateFromRecords(&execPlan, true, records).AttemptsMap
+		reportID, err := existingDryRunReportID(r, tx, projectID, runID)
+		if err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
 		if err := tx.Commit(); err != nil {
 			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
 			return
@@ -169,6 +182,7 @@ func (api *experimentsAPI) handleDryRun(w http.ResponseWriter, r *http.Request)
 			State:          string(derivedState),
 			AttemptsByStep: attempts,
 			Existing:       true,
+			ReportID:       reportID,
 			Steps:          buildDryRunSummary(execPlan, records),
 		})
 		return
@@ -212,6 +226,12 @@ func (api *experimentsAPI) handleDryRun(w http.ResponseWriter, r *http.Request)
 	}
 	attempts := deriveRunStateFromRecords(&execPlan, true, records).AttemptsMap
 
+	reportID, err := api.recordDryRunReport(r, tx, identity.Subject, runRecord, execPlan, records, dryRunStatusFromState(derivedFinal))
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "dry_run_report_failed")
+		return
+	}
+
 	if err := tx.Commit(); err != nil {
 		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
 		return
@@ -223,6 +243,7 @@ func (api *experimentsAPI) handleDryRun(w http.ResponseWriter, r *http.Request)
 		State:          string(derivedFinal),
 		AttemptsByStep: attempts,
 		Existing:       result.Existing,
+		ReportID:       reportID,
 		Steps:          buildDryRunSummary(execPlan, records),
 	})
 }
@@ -291,14 +312,14 @@ func (api *experimentsAPI) appendDryRunStepAuditEvents(r *http.Request, q auditl
 	}
 	now := time.Now().UTC()
 	for _, attempt := range attempts {
-		_, err := auditlog.Insert(r.Context(), q, auditlog.Event{
+		err := auditlog.Enqueue(r.Context(), q, auditlog.Event{
 			OccurredAt:   now,
 			Actor:        actor,
 			Action:       "dry_run.step.started",
 			ResourceType: "step_execution",
 			ResourceID:   runID,
 			RequestID:    r.Header.Get("X-Request-Id"),
-			IP:           requestIP(r.RemoteAddr),
+			IP:           httpapi.RequestIP(r.RemoteAddr),
 			UserAgent:    r.UserAgent(),
 			Payload: map[string]any{
 				"service":    "experiments",
@@ -321,14 +342,14 @@ func (api *experimentsAPI) appendDryRunStepAuditEvents(r *http.Request, q auditl
 		case dryrun.StatusSkipped:
 			action = "dry_run.step.skipped"
 		}
-		_, err = auditlog.Insert(r.Context(), q, auditlog.Event{
+		err = auditlog.Enqueue(r.Context(), q, auditlog.Event{
 			OccurredAt:   now,
 			Actor:        actor,
 			Action:       action,
 			ResourceType: "step_execution",
 			ResourceID:   runID,
 			RequestID:    r.Header.Get("X-Request-Id"),
-			IP:           requestIP(r.RemoteAddr),
+			IP:           httpapi.RequestIP(r.RemoteAddr),
 			UserAgent:    r.UserAgent(),
 			Payload: map[string]any{
 				"service":    "experiments",
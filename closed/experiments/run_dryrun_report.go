@@ -0,0 +1,691 @@
+package experiments
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/domain"
+	"github.com/animus-labs/animus-go/closed/internal/execution/executor/dryrun"
+	"github.com/animus-labs/animus-go/closed/internal/execution/state"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+	"github.com/animus-labs/animus-go/closed/internal/repo/postgres"
+	"github.com/google/uuid"
+)
+
+const dryRunReportSchema = "animus.dry_run_report.v1"
+
+const (
+	dryRunImageSourceStep        = "step"
+	dryRunImageSourceEnvironment = "environment"
+
+	datasetGatePass         = "pass"
+	datasetGateFail         = "fail"
+	datasetGateNotEvaluated = "not_evaluated"
+	datasetGateNoRule       = "no_rule"
+	datasetGateRecalled     = "recalled"
+	datasetGateMissing      = "missing"
+	// datasetGateReferenceChanged marks a reference version whose object
+	// changed or vanished since registration.
+	datasetGateReferenceChanged = "reference_changed"
+
+	policyCheckCurrent  = "current"
+	policyCheckChanged  = "changed"
+	policyCheckInactive = "inactive"
+	policyCheckAdded    = "added"
+)
+
+// dryRunReport is the persisted outcome of a dry run: what was validated and
+// which inputs a real execution of the same run would use.
+type dryRunReport struct {
+	Schema       string                    `json:"schema"`
+	ProjectID    string                    `json:"projectId"`
+	RunID        string                    `json:"runId"`
+	SpecHash     string                    `json:"specHash"`
+	PipelineName string                    `json:"pipelineName,omitempty"`
+	Status       string                    `json:"status"`
+	GeneratedAt  time.Time                 `json:"generatedAt"`
+	Steps        []dryRunReportStep        `json:"steps"`
+	Images       []dryRunReportImage       `json:"images"`
+	DatasetGates []dryRunReportDatasetGate `json:"datasetGates"`
+	PolicyChecks []dryRunReportPolicyCheck `json:"policyChecks"`
+}
+
+type dryRunReportStep struct {
+	Name        string   `json:"name"`
+	Image       string   `json:"image"`
+	DependsOn   []string `json:"dependsOn"`
+	MaxAttempts int      `json:"maxAttempts"`
+	Attempts    int      `json:"attempts"`
+	Status      string   `json:"status"`
+}
+
+type dryRunReportImage struct {
+	Source   string `json:"source"`
+	Name     string `json:"name"`
+	Ref      string `json:"ref"`
+	Digest   string `json:"digest,omitempty"`
+	Resolved bool   `json:"resolved"`
+}
+
+type dryRunReportDatasetGate struct {
+	Binding          string `json:"binding"`
+	DatasetVersionID string `json:"datasetVersionId"`
+	DatasetID        string `json:"datasetId,omitempty"`
+	Status           string `json:"status"`
+	RuleID           string `json:"ruleId,omitempty"`
+	EvaluationID     string `json:"evaluationId,omitempty"`
+}
+
+type dryRunReportPolicyCheck struct {
+	PolicyID        string `json:"policyId"`
+	PolicyName      string `json:"policyName,omitempty"`
+	PolicyVersionID string `json:"policyVersionId,omitempty"`
+	PolicySHA256    string `json:"policySha256,omitempty"`
+	ActiveVersionID string `json:"activeVersionId,omitempty"`
+	ActiveSHA256    string `json:"activeSha256,omitempty"`
+	Status          string `json:"status"`
+}
+
+type dryRunReportRecord struct {
+	ReportID     string
+	ProjectID    string
+	RunID        string
+	Status       string
+	Report       dryRunReport
+	ReportSHA256 string
+	CreatedAt    time.Time
+	CreatedBy    string
+}
+
+type dryRunReportDiff struct {
+	Changed         bool                 `json:"changed"`
+	SpecHashChanged bool                 `json:"specHashChanged"`
+	StepsAdded      []string             `json:"stepsAdded"`
+	StepsRemoved    []string             `json:"stepsRemoved"`
+	StepsChanged    []dryRunStepChange   `json:"stepsChanged"`
+	Images          []dryRunReportChange `json:"images"`
+	DatasetBindings []dryRunReportChange `json:"datasetBindings"`
+	Policies        []dryRunReportChange `json:"policies"`
+	DatasetGates    []dryRunReportChange `json:"datasetGates"`
+}
+
+type dryRunStepChange struct {
+	Name   string   `json:"name"`
+	Fields []string `json:"fields"`
+}
+
+// dryRunReportChange is one keyed value that differs between the baseline
+// and the current report; an empty side means the key is absent there.
+type dryRunReportChange struct {
+	Key    string `json:"key"`
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+}
+
+type dryRunReportBaseline struct {
+	RunID     string    `json:"runId"`
+	ReportID  string    `json:"reportId"`
+	SpecHash  string    `json:"specHash"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type dryRunReportResponse struct {
+	ReportID     string                `json:"reportId"`
+	ReportSHA256 string                `json:"reportSha256"`
+	CreatedAt    time.Time             `json:"createdAt"`
+	CreatedBy    string                `json:"createdBy"`
+	Report       dryRunReport          `json:"report"`
+	Baseline     *dryRunReportBaseline `json:"baseline,omitempty"`
+	Diff         *dryRunReportDiff     `json:"diff,omitempty"`
+}
+
+func (api *experimentsAPI) handleGetDryRunReport(w http.ResponseWriter, r *http.Request) {
+	projectID := strings.TrimSpace(r.PathValue("project_id"))
+	runID := strings.TrimSpace(r.PathValue("run_id"))
+	if projectID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "project_id_required")
+		return
+	}
+	if runID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "run_id_required")
+		return
+	}
+
+	current, err := getDryRunReport(r.Context(), api.db, projectID, runID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			api.writeError(w, r, http.StatusNotFound, "dry_run_report_not_found")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	resp := dryRunReportResponse{
+		ReportID:     current.ReportID,
+		ReportSHA256: current.ReportSHA256,
+		CreatedAt:    current.CreatedAt,
+		CreatedBy:    current.CreatedBy,
+		Report:       current.Report,
+	}
+	baseline, found, err := findDryRunReportBaseline(r.Context(), api.db, current)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if found {
+		diff := diffDryRunReports(baseline.Report, current.Report)
+		resp.Baseline = &dryRunReportBaseline{
+			RunID:     baseline.RunID,
+			ReportID:  baseline.ReportID,
+			SpecHash:  baseline.Report.SpecHash,
+			CreatedAt: baseline.CreatedAt,
+		}
+		resp.Diff = &diff
+	}
+	api.writeJSON(w, http.StatusOK, resp)
+}
+
+// recordDryRunReport builds the report for a finished dry run and stores it
+// with its audit event inside the dry-run transaction. A run keeps the report
+// of its first dry run.
+func (api *experimentsAPI) recordDryRunReport(r *http.Request, tx *sql.Tx, actor string, runRecord repo.RunRecord, execPlan domain.ExecutionPlan, records []repo.StepExecutionRecord, status string) (string, error) {
+	report, err := api.buildDryRunReport(r.Context(), tx, runRecord, execPlan, records, status)
+	if err != nil {
+		return "", err
+	}
+	reportSHA, err := integritySHA256(report)
+	if err != nil {
+		return "", err
+	}
+	blob, err := json.Marshal(report)
+	if err != nil {
+		return "", err
+	}
+
+	reportID := uuid.NewString()
+	var pipelineName sql.NullString
+	if report.PipelineName != "" {
+		pipelineName = sql.NullString{String: report.PipelineName, Valid: true}
+	}
+	res, err := tx.ExecContext(
+		r.Context(),
+		`INSERT INTO run_dry_run_reports (report_id, project_id, run_id, spec_hash, pipeline_name, status, report, report_sha256, created_at, created_by)
+		 VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)
+		 ON CONFLICT (project_id, run_id) DO NOTHING`,
+		reportID,
+		runRecord.ProjectID,
+		runRecord.ID,
+		runRecord.SpecHash,
+		pipelineName,
+		status,
+		blob,
+		reportSHA,
+		report.GeneratedAt,
+		actor,
+	)
+	if err != nil {
+		return "", err
+	}
+	if affected, err := res.RowsAffected(); err == nil && affected == 0 {
+		existing, err := getDryRunReport(r.Context(), tx, runRecord.ProjectID, runRecord.ID)
+		if err != nil {
+			return "", err
+		}
+		return existing.ReportID, nil
+	}
+
+	err = auditlog.Enqueue(r.Context(), tx, auditlog.Event{
+		OccurredAt:   report.GeneratedAt,
+		Actor:        actor,
+		Action:       "dry_run.report.created",
+		ResourceType: "dry_run_report",
+		ResourceID:   reportID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload: map[string]any{
+			"service":       "experiments",
+			"project_id":    runRecord.ProjectID,
+			"run_id":        runRecord.ID,
+			"spec_hash":     runRecord.SpecHash,
+			"status":        status,
+			"report_sha256": reportSHA,
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	return reportID, nil
+}
+
+func (api *experimentsAPI) buildDryRunReport(ctx context.Context, db postgres.DB, runRecord repo.RunRecord, execPlan domain.ExecutionPlan, records []repo.StepExecutionRecord, status string) (dryRunReport, error) {
+	pipelineSpec, err := decodePipelineSpec(runRecord.PipelineSpec)
+	if err != nil {
+		return dryRunReport{}, err
+	}
+	var runSpec runSpecPayload
+	if len(runRecord.RunSpec) > 0 {
+		if err := json.Unmarshal(runRecord.RunSpec, &runSpec); err != nil {
+			return dryRunReport{}, err
+		}
+	}
+
+	report := dryRunReport{
+		Schema:      dryRunReportSchema,
+		ProjectID:   runRecord.ProjectID,
+		RunID:       runRecord.ID,
+		SpecHash:    runRecord.SpecHash,
+		Status:      status,
+		GeneratedAt: time.Now().UTC(),
+		Steps:       buildDryRunReportSteps(execPlan, pipelineSpec, records),
+		Images:      resolveDryRunImages(pipelineSpec, runSpec.EnvLock.Images),
+	}
+	if pipelineSpec.Metadata != nil {
+		report.PipelineName = strings.TrimSpace(pipelineSpec.Metadata.Name)
+	}
+
+	report.DatasetGates, err = api.checkDatasetGates(ctx, db, runSpec.DatasetBindings)
+	if err != nil {
+		return dryRunReport{}, err
+	}
+	active, err := api.loadScopedPolicyVersions(ctx, policyScope{ProjectID: runRecord.ProjectID})
+	if err != nil {
+		return dryRunReport{}, err
+	}
+	report.PolicyChecks = checkSnapshotPolicies(runSpec.PolicySnapshot.Policies, active)
+	return report, nil
+}
+
+func buildDryRunReportSteps(execPlan domain.ExecutionPlan, pipelineSpec domain.PipelineSpec, records []repo.StepExecutionRecord) []dryRunReportStep {
+	images := make(map[string]string, len(pipelineSpec.Spec.Steps))
+	for _, step := range pipelineSpec.Spec.Steps {
+		images[strings.TrimSpace(step.Name)] = strings.TrimSpace(step.Image)
+	}
+	byStep := groupStepExecutions(records)
+	deps := planDependencies(execPlan)
+
+	out := make([]dryRunReportStep, 0, len(execPlan.Steps))
+	for _, step := range execPlan.Steps {
+		name := strings.TrimSpace(step.Name)
+		if name == "" {
+			continue
+		}
+		attempts, outcome := state.DeriveStepOutcome(byStep[name])
+		dependsOn := append([]string{}, deps[name]...)
+		sort.Strings(dependsOn)
+		out = append(out, dryRunReportStep{
+			Name:        name,
+			Image:       images[name],
+			DependsOn:   dependsOn,
+			MaxAttempts: step.RetryPolicy.MaxAttempts,
+			Attempts:    attempts,
+			Status:      stepStatusFromOutcome(outcome, attempts),
+		})
+	}
+	return out
+}
+
+// resolveDryRunImages reports the digest each step image and environment
+// image would run with. Step images without a pinned digest resolve through
+// the environment lock by ref or name.
+func resolveDryRunImages(pipelineSpec domain.PipelineSpec, lockImages []domain.EnvironmentImage) []dryRunReportImage {
+	lockDigests := make(map[string]string, len(lockImages)*2)
+	for _, image := range lockImages {
+		digest := strings.ToLower(strings.TrimSpace(image.Digest))
+		if !isSHA256Digest(digest) {
+			continue
+		}
+		if ref := strings.TrimSpace(image.Ref); ref != "" {
+			lockDigests[ref] = digest
+		}
+		if name := strings.TrimSpace(image.Name); name != "" {
+			lockDigests[name] = digest
+		}
+	}
+
+	out := make([]dryRunReportImage, 0, len(pipelineSpec.Spec.Steps)+len(lockImages))
+	for _, step := range pipelineSpec.Spec.Steps {
+		ref := strings.TrimSpace(step.Image)
+		digest, ok := parseImageDigestFromRef(ref)
+		if !ok {
+			digest = lockDigests[ref]
+		}
+		out = append(out, dryRunReportImage{
+			Source:   dryRunImageSourceStep,
+			Name:     strings.TrimSpace(step.Name),
+			Ref:      ref,
+			Digest:   digest,
+			Resolved: digest != "",
+		})
+	}
+	for _, image := range lockImages {
+		digest := strings.ToLower(strings.TrimSpace(image.Digest))
+		if !isSHA256Digest(digest) {
+			digest = ""
+		}
+		out = append(out, dryRunReportImage{
+			Source:   dryRunImageSourceEnvironment,
+			Name:     strings.TrimSpace(image.Name),
+			Ref:      strings.TrimSpace(image.Ref),
+			Digest:   digest,
+			Resolved: digest != "",
+		})
+	}
+	return out
+}
+
+// checkDatasetGates reads the quality gate state of every bound dataset
+// version without recording a gate decision; the gate itself is enforced
+// when runs are registered.
+func (api *experimentsAPI) checkDatasetGates(ctx context.Context, db postgres.DB, bindings map[string]string) ([]dryRunReportDatasetGate, error) {
+	names := make([]string, 0, len(bindings))
+	for name := range bindings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	out := make([]dryRunReportDatasetGate, 0, len(names))
+	for _, name := range names {
+		gate := dryRunReportDatasetGate{
+			Binding:          name,
+			DatasetVersionID: strings.TrimSpace(bindings[name]),
+		}
+		var (
+			ruleID     sql.NullString
+			evalID     sql.NullString
+			evalStatus sql.NullString
+			refState   sql.NullString
+		)
+		err := db.QueryRowContext(
+			ctx,
+			`SELECT v.dataset_id, v.quality_rule_id, e.evaluation_id, e.status, s.state
+			 FROM dataset_versions v
+			 LEFT JOIN dataset_version_reference_status s ON s.version_id = v.version_id
+			 LEFT JOIN LATERAL (
+				SELECT evaluation_id, status
+				FROM quality_evaluations
+				WHERE dataset_version_id = v.version_id AND rule_id = v.quality_rule_id
+				ORDER BY evaluated_at DESC
+				LIMIT 1
+			 ) e ON true
+			 WHERE v.version_id = $1`,
+			gate.DatasetVersionID,
+		).Scan(&gate.DatasetID, &ruleID, &evalID, &evalStatus, &refState)
+		if errors.Is(err, sql.ErrNoRows) {
+			gate.Status = datasetGateMissing
+			out = append(out, gate)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		gate.RuleID = strings.TrimSpace(ruleID.String)
+		gate.EvaluationID = strings.TrimSpace(evalID.String)
+
+		protection, err := api.loadDataProtection(ctx, gate.DatasetVersionID)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case protection.Recall != nil:
+			gate.Status = datasetGateRecalled
+		case refState.Valid && refState.String != "verified":
+			gate.Status = datasetGateReferenceChanged
+		case gate.RuleID == "":
+			gate.Status = datasetGateNoRule
+		case gate.EvaluationID == "":
+			gate.Status = datasetGateNotEvaluated
+		case strings.ToLower(strings.TrimSpace(evalStatus.String)) == "pass":
+			gate.Status = datasetGatePass
+		default:
+			gate.Status = datasetGateFail
+		}
+		out = append(out, gate)
+	}
+	return out, nil
+}
+
+// checkSnapshotPolicies compares the policies captured when the run was
+// created with the policies active now.
+func checkSnapshotPolicies(snapshot []domain.PolicySnapshotPolicy, active []policyVersionRecord) []dryRunReportPolicyCheck {
+	activeByID := make(map[string]policyVersionRecord, len(active))
+	for _, record := range active {
+		activeByID[record.PolicyID] = record
+	}
+
+	out := make([]dryRunReportPolicyCheck, 0, len(snapshot)+len(active))
+	seen := make(map[string]struct{}, len(snapshot))
+	for _, captured := range snapshot {
+		check := dryRunReportPolicyCheck{
+			PolicyID:        captured.PolicyID,
+			PolicyName:      captured.PolicyName,
+			PolicyVersionID: captured.PolicyVersionID,
+			PolicySHA256:    captured.PolicySHA256,
+			Status:          policyCheckInactive,
+		}
+		seen[captured.PolicyID] = struct{}{}
+		if current, ok := activeByID[captured.PolicyID]; ok {
+			check.ActiveVersionID = current.PolicyVersionID
+			check.ActiveSHA256 = current.SpecSHA256
+			check.Status = policyCheckChanged
+			if current.PolicyVersionID == captured.PolicyVersionID {
+				check.Status = policyCheckCurrent
+			}
+		}
+		out = append(out, check)
+	}
+	for _, current := range active {
+		if _, ok := seen[current.PolicyID]; ok {
+			continue
+		}
+		out = append(out, dryRunReportPolicyCheck{
+			PolicyID:        current.PolicyID,
+			PolicyName:      current.PolicyName,
+			ActiveVersionID: current.PolicyVersionID,
+			ActiveSHA256:    current.SpecSHA256,
+			Status:          policyCheckAdded,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].PolicyID < out[j].PolicyID })
+	return out
+}
+
+// diffDryRunReports lists what changed from the baseline report to the
+// current one. Attempt counts and outcomes are not compared: they describe
+// the dry run itself, not what a real execution would use.
+func diffDryRunReports(baseline, current dryRunReport) dryRunReportDiff {
+	diff := dryRunReportDiff{
+		SpecHashChanged: baseline.SpecHash != current.SpecHash,
+		StepsAdded:      []string{},
+		StepsRemoved:    []string{},
+		StepsChanged:    []dryRunStepChange{},
+	}
+
+	before := make(map[string]dryRunReportStep, len(baseline.Steps))
+	for _, step := range baseline.Steps {
+		before[step.Name] = step
+	}
+	after := make(map[string]dryRunReportStep, len(current.Steps))
+	for _, step := range current.Steps {
+		after[step.Name] = step
+		prev, ok := before[step.Name]
+		if !ok {
+			diff.StepsAdded = append(diff.StepsAdded, step.Name)
+			continue
+		}
+		var fields []string
+		if prev.Image != step.Image {
+			fields = append(fields, "image")
+		}
+		if strings.Join(prev.DependsOn, ",") != strings.Join(step.DependsOn, ",") {
+			fields = append(fields, "dependsOn")
+		}
+		if prev.MaxAttempts != step.MaxAttempts {
+			fields = append(fields, "maxAttempts")
+		}
+		if len(fields) > 0 {
+			diff.StepsChanged = append(diff.StepsChanged, dryRunStepChange{Name: step.Name, Fields: fields})
+		}
+	}
+	for _, step := range baseline.Steps {
+		if _, ok := after[step.Name]; !ok {
+			diff.StepsRemoved = append(diff.StepsRemoved, step.Name)
+		}
+	}
+	sort.Strings(diff.StepsAdded)
+	sort.Strings(diff.StepsRemoved)
+	sort.Slice(diff.StepsChanged, func(i, j int) bool { return diff.StepsChanged[i].Name < diff.StepsChanged[j].Name })
+
+	imageKey := func(image dryRunReportImage) string { return image.Source + ":" + image.Name }
+	imageValue := func(image dryRunReportImage) string {
+		if image.Digest != "" {
+			return image.Digest
+		}
+		return image.Ref
+	}
+	diff.Images = diffKeyed(keyedValues(baseline.Images, imageKey, imageValue), keyedValues(current.Images, imageKey, imageValue))
+
+	gateKey := func(gate dryRunReportDatasetGate) string { return gate.Binding }
+	diff.DatasetBindings = diffKeyed(
+		keyedValues(baseline.DatasetGates, gateKey, func(gate dryRunReportDatasetGate) string { return gate.DatasetVersionID }),
+		keyedValues(current.DatasetGates, gateKey, func(gate dryRunReportDatasetGate) string { return gate.DatasetVersionID }),
+	)
+	diff.DatasetGates = diffKeyed(
+		keyedValues(baseline.DatasetGates, gateKey, func(gate dryRunReportDatasetGate) string { return gate.Status }),
+		keyedValues(current.DatasetGates, gateKey, func(gate dryRunReportDatasetGate) string { return gate.Status }),
+	)
+
+	policyKey := func(check dryRunReportPolicyCheck) string { return check.PolicyID }
+	policyValue := func(check dryRunReportPolicyCheck) string {
+		if check.PolicyVersionID != "" {
+			return check.PolicyVersionID
+		}
+		return check.ActiveVersionID
+	}
+	diff.Policies = diffKeyed(keyedValues(baseline.PolicyChecks, policyKey, policyValue), keyedValues(current.PolicyChecks, policyKey, policyValue))
+
+	diff.Changed = diff.SpecHashChanged ||
+		len(diff.StepsAdded) > 0 ||
+		len(diff.StepsRemoved) > 0 ||
+		len(diff.StepsChanged) > 0 ||
+		len(diff.Images) > 0 ||
+		len(diff.DatasetBindings) > 0 ||
+		len(diff.DatasetGates) > 0 ||
+		len(diff.Policies) > 0
+	return diff
+}
+
+func keyedValues[T any](items []T, key func(T) string, value func(T) string) map[string]string {
+	out := make(map[string]string, len(items))
+	for _, item := range items {
+		out[key(item)] = value(item)
+	}
+	return out
+}
+
+func diffKeyed(before, after map[string]string) []dryRunReportChange {
+	out := []dryRunReportChange{}
+	for key, value := range after {
+		if prev, ok := before[key]; !ok || prev != value {
+			out = append(out, dryRunReportChange{Key: key, Before: before[key], After: value})
+		}
+	}
+	for key, value := range before {
+		if _, ok := after[key]; !ok {
+			out = append(out, dryRunReportChange{Key: key, Before: value})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out
+}
+
+const dryRunReportColumns = `report_id, project_id, run_id, status, report, report_sha256, created_at, created_by`
+
+func scanDryRunReport(row interface{ Scan(dest ...any) error }) (dryRunReportRecord, error) {
+	var (
+		record dryRunReportRecord
+		blob   []byte
+	)
+	if err := row.Scan(
+		&record.ReportID,
+		&record.ProjectID,
+		&record.RunID,
+		&record.Status,
+		&blob,
+		&record.ReportSHA256,
+		&record.CreatedAt,
+		&record.CreatedBy,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return dryRunReportRecord{}, repo.ErrNotFound
+		}
+		return dryRunReportRecord{}, err
+	}
+	if err := json.Unmarshal(blob, &record.Report); err != nil {
+		return dryRunReportRecord{}, err
+	}
+	record.CreatedAt = record.CreatedAt.UTC()
+	return record, nil
+}
+
+func getDryRunReport(ctx context.Context, db postgres.DB, projectID, runID string) (dryRunReportRecord, error) {
+	return scanDryRunReport(db.QueryRowContext(
+		ctx,
+		`SELECT `+dryRunReportColumns+`
+		 FROM run_dry_run_reports
+		 WHERE project_id = $1 AND run_id = $2`,
+		projectID,
+		runID,
+	))
+}
+
+// findDryRunReportBaseline returns the latest succeeded dry-run report of
+// another run in the project created before the given one, preferring runs
+// of the same pipeline.
+func findDryRunReportBaseline(ctx context.Context, db postgres.DB, current dryRunReportRecord) (dryRunReportRecord, bool, error) {
+	record, err := scanDryRunReport(db.QueryRowContext(
+		ctx,
+		`SELECT `+dryRunReportColumns+`
+		 FROM run_dry_run_reports
+		 WHERE project_id = $1
+		   AND run_id <> $2
+		   AND status = $3
+		   AND created_at < $4
+		 ORDER BY (pipeline_name IS NOT DISTINCT FROM NULLIF($5, '')) DESC, created_at DESC
+		 LIMIT 1`,
+		current.ProjectID,
+		current.RunID,
+		dryrun.StatusSucceeded,
+		current.CreatedAt,
+		current.Report.PipelineName,
+	))
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return dryRunReportRecord{}, false, nil
+		}
+		return dryRunReportRecord{}, false, err
+	}
+	return record, true, nil
+}
+
+// existingDryRunReportID returns the report of an earlier dry run, or an
+// empty ID for runs dry-run before reports were recorded.
+func existingDryRunReportID(r *http.Request, db postgres.DB, projectID, runID string) (string, error) {
+	record, err := getDryRunReport(r.Context(), db, projectID, runID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return "", nil
+		}
+		return "", err
+	}
+	return record.ReportID, nil
+}
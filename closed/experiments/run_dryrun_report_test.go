@@ -0,0 +1,126 @@
+package experiments
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/animus-labs/animus-go/closed/internal/domain"
+)
+
+func TestResolveDryRunImagesUsesEnvironmentLock(t *testing.T) {
+	pinned := "sha256:" + strings.Repeat("a", 64)
+	locked := "sha256:" + strings.Repeat("b", 64)
+	spec := domain.PipelineSpec{Spec: domain.PipelineSpecBody{Steps: []domain.PipelineStep{
+		{Name: "train", Image: "registry/train@" + pinned},
+		{Name: "eval", Image: "registry/eval:1.0"},
+		{Name: "report", Image: "registry/report:latest"},
+	}}}
+	lock := []domain.EnvironmentImage{{Name: "eval", Ref: "registry/eval:1.0", Digest: locked}}
+
+	images := resolveDryRunImages(spec, lock)
+	if len(images) != 4 {
+		t.Fatalf("expected 4 images, got %d", len(images))
+	}
+	if images[0].Digest != pinned || !images[0].Resolved {
+		t.Fatalf("expected pinned digest, got %+v", images[0])
+	}
+	if images[1].Digest != locked || !images[1].Resolved {
+		t.Fatalf("expected lock digest, got %+v", images[1])
+	}
+	if images[2].Resolved || images[2].Digest != "" {
+		t.Fatalf("expected unresolved image, got %+v", images[2])
+	}
+	if images[3].Source != dryRunImageSourceEnvironment || images[3].Digest != locked {
+		t.Fatalf("expected environment image, got %+v", images[3])
+	}
+}
+
+func TestCheckSnapshotPolicies(t *testing.T) {
+	snapshot := []domain.PolicySnapshotPolicy{
+		{PolicyID: "p1", PolicyVersionID: "v1"},
+		{PolicyID: "p2", PolicyVersionID: "v1"},
+		{PolicyID: "p3", PolicyVersionID: "v1"},
+	}
+	active := []policyVersionRecord{
+		{PolicyID: "p1", PolicyVersionID: "v1"},
+		{PolicyID: "p2", PolicyVersionID: "v2"},
+		{PolicyID: "p4", PolicyVersionID: "v1"},
+	}
+
+	checks := checkSnapshotPolicies(snapshot, active)
+	got := make(map[string]string, len(checks))
+	for _, check := range checks {
+		got[check.PolicyID] = check.Status
+	}
+	want := map[string]string{
+		"p1": policyCheckCurrent,
+		"p2": policyCheckChanged,
+		"p3": policyCheckInactive,
+		"p4": policyCheckAdded,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected checks: %v", got)
+	}
+}
+
+func TestDiffDryRunReports(t *testing.T) {
+	baseline := dryRunReport{
+		SpecHash: "hash-1",
+		Steps: []dryRunReportStep{
+			{Name: "prep", Image: "img/prep:1", MaxAttempts: 1},
+			{Name: "train", Image: "img/train:1", DependsOn: []string{"prep"}, MaxAttempts: 1},
+			{Name: "old", Image: "img/old:1", MaxAttempts: 1},
+		},
+		Images: []dryRunReportImage{
+			{Source: dryRunImageSourceStep, Name: "train", Ref: "img/train:1", Digest: "sha256:1"},
+		},
+		DatasetGates: []dryRunReportDatasetGate{
+			{Binding: "train", DatasetVersionID: "dv-1", Status: datasetGatePass},
+		},
+		PolicyChecks: []dryRunReportPolicyCheck{{PolicyID: "p1", PolicyVersionID: "v1"}},
+	}
+	current := dryRunReport{
+		SpecHash: "hash-2",
+		Steps: []dryRunReportStep{
+			{Name: "prep", Image: "img/prep:1", MaxAttempts: 1, Attempts: 3},
+			{Name: "train", Image: "img/train:2", DependsOn: []string{"prep"}, MaxAttempts: 2},
+			{Name: "eval", Image: "img/eval:1", MaxAttempts: 1},
+		},
+		Images: []dryRunReportImage{
+			{Source: dryRunImageSourceStep, Name: "train", Ref: "img/train:2", Digest: "sha256:2"},
+		},
+		DatasetGates: []dryRunReportDatasetGate{
+			{Binding: "train", DatasetVersionID: "dv-2", Status: datasetGatePass},
+		},
+		PolicyChecks: []dryRunReportPolicyCheck{{PolicyID: "p1", PolicyVersionID: "v1"}},
+	}
+
+	diff := diffDryRunReports(baseline, current)
+	if !diff.Changed || !diff.SpecHashChanged {
+		t.Fatalf("expected changed diff, got %+v", diff)
+	}
+	if !reflect.DeepEqual(diff.StepsAdded, []string{"eval"}) || !reflect.DeepEqual(diff.StepsRemoved, []string{"old"}) {
+		t.Fatalf("unexpected step set changes: %+v", diff)
+	}
+	wantChanged := []dryRunStepChange{{Name: "train", Fields: []string{"image", "maxAttempts"}}}
+	if !reflect.DeepEqual(diff.StepsChanged, wantChanged) {
+		t.Fatalf("unexpected step changes: %+v", diff.StepsChanged)
+	}
+	wantImages := []dryRunReportChange{{Key: "step:train", Before: "sha256:1", After: "sha256:2"}}
+	if !reflect.DeepEqual(diff.Images, wantImages) {
+		t.Fatalf("unexpected image changes: %+v", diff.Images)
+	}
+	wantBindings := []dryRunReportChange{{Key: "train", Before: "dv-1", After: "dv-2"}}
+	if !reflect.DeepEqual(diff.DatasetBindings, wantBindings) {
+		t.Fatalf("unexpected binding changes: %+v", diff.DatasetBindings)
+	}
+	if len(diff.DatasetGates) != 0 || len(diff.Policies) != 0 {
+		t.Fatalf("unexpected gate/policy changes: %+v", diff)
+	}
+
+	same := diffDryRunReports(current, current)
+	if same.Changed {
+		t.Fatalf("expected no changes, got %+v", same)
+	}
+}
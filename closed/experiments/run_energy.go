@@ -0,0 +1,355 @@
+package experiments
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/env"
+)
+
+const energyMethodTDPv1 = "tdp_duration_v1"
+
+const (
+	energyGroupExperiment = "experiment"
+	energyGroupRegion     = "region"
+	energyGroupDay        = "day"
+)
+
+type energyConfig struct {
+	// GPUWatts is the thermal design power assumed for one GPU.
+	GPUWatts float64
+	// CPUWatts is the thermal design power assumed for one CPU core.
+	CPUWatts float64
+	// DefaultRegion applies to runs whose resources name no region.
+	DefaultRegion string
+	// GridFactors maps a region to its grid intensity in kgCO2e/kWh.
+	GridFactors map[string]float64
+	// DefaultGridFactor applies to regions missing from GridFactors.
+	DefaultGridFactor float64
+}
+
+func energyConfigFromEnv() (energyConfig, error) {
+	gpuWatts, err := env.Float("ANIMUS_ENERGY_GPU_TDP_WATTS", 300)
+	if err != nil {
+		return energyConfig{}, err
+	}
+	cpuWatts, err := env.Float("ANIMUS_ENERGY_CPU_TDP_WATTS", 10)
+	if err != nil {
+		return energyConfig{}, err
+	}
+	defaultFactor, err := env.Float("ANIMUS_CARBON_DEFAULT_GRID_FACTOR", 0.475)
+	if err != nil {
+		return energyConfig{}, err
+	}
+	factors, err := parseGridFactors(env.String("ANIMUS_CARBON_GRID_FACTORS", ""))
+	if err != nil {
+		return energyConfig{}, err
+	}
+	cfg := energyConfig{
+		GPUWatts:          gpuWatts,
+		CPUWatts:          cpuWatts,
+		DefaultRegion:     strings.ToLower(strings.TrimSpace(env.String("ANIMUS_CARBON_DEFAULT_REGION", ""))),
+		GridFactors:       factors,
+		DefaultGridFactor: defaultFactor,
+	}
+	if err := cfg.Validate(); err != nil {
+		return energyConfig{}, err
+	}
+	return cfg, nil
+}
+
+func (c energyConfig) Validate() error {
+	for name, value := range map[string]float64{
+		"gpu tdp watts":       c.GPUWatts,
+		"cpu tdp watts":       c.CPUWatts,
+		"default grid factor": c.DefaultGridFactor,
+	} {
+		if value < 0 || math.IsNaN(value) || math.IsInf(value, 0) {
+			return fmt.Errorf("energy %s must be >= 0", name)
+		}
+	}
+	return nil
+}
+
+// parseGridFactors reads "region=kgCO2e/kWh" pairs separated by commas.
+func parseGridFactors(raw string) (map[string]float64, error) {
+	out := map[string]float64{}
+	for _, item := range strings.Split(raw, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		region, value, ok := strings.Cut(item, "=")
+		region = strings.ToLower(strings.TrimSpace(region))
+		if !ok || region == "" {
+			return nil, fmt.Errorf("grid factor %q must be region=value", item)
+		}
+		factor, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || factor < 0 || math.IsNaN(factor) || math.IsInf(factor, 0) {
+			return nil, fmt.Errorf("grid factor %q must be a non-negative number", item)
+		}
+		out[region] = factor
+	}
+	return out, nil
+}
+
+// executionLedgerEnergy is the energy and carbon estimate of a run, recorded
+// in its ledger entry once the run has ended:
+// duration × (GPUs × GPU TDP + CPU cores × CPU TDP), times the grid factor
+// of the run's region.
+type executionLedgerEnergy struct {
+	Method          string  `json:"method"`
+	DurationSeconds float64 `json:"duration_seconds"`
+	GPUs            float64 `json:"gpus"`
+	CPUCores        float64 `json:"cpu_cores"`
+	GPUTDPWatts     float64 `json:"gpu_tdp_watts"`
+	CPUTDPWatts     float64 `json:"cpu_tdp_watts"`
+	EnergyKWh       float64 `json:"energy_kwh"`
+	Region          string  `json:"region,omitempty"`
+	GridFactor      float64 `json:"grid_factor_kg_per_kwh"`
+	CO2eKg          float64 `json:"co2e_kg"`
+}
+
+// energyResources reads the executor resource hints: "gpus" (or "gpu"), a
+// Kubernetes "cpu" quantity and an optional "region". A run without a CPU
+// hint is counted as one core.
+func energyResources(raw json.RawMessage) (gpus float64, cpuCores float64, region string) {
+	var resources map[string]any
+	_ = json.Unmarshal(raw, &resources)
+	cpuCores = 1
+	for _, key := range []string{"gpus", "gpu"} {
+		if value, ok := resourceNumber(resources[key]); ok {
+			gpus = value
+			break
+		}
+	}
+	if value, ok := resourceNumber(resources["cpu"]); ok && value > 0 {
+		cpuCores = value
+	}
+	if value, ok := resources["region"].(string); ok {
+		region = strings.ToLower(strings.TrimSpace(value))
+	}
+	return gpus, cpuCores, region
+}
+
+func resourceNumber(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, v >= 0
+	case string:
+		v = strings.TrimSpace(v)
+		if milli, ok := strings.CutSuffix(v, "m"); ok {
+			n, err := strconv.ParseFloat(milli, 64)
+			return n / 1000, err == nil && n >= 0
+		}
+		n, err := strconv.ParseFloat(v, 64)
+		return n, err == nil && n >= 0
+	default:
+		return 0, false
+	}
+}
+
+// estimate returns nil while the run has not ended.
+func (c energyConfig) estimate(resources json.RawMessage, startedAt time.Time, endedAt *time.Time) *executionLedgerEnergy {
+	if endedAt == nil || endedAt.Before(startedAt) {
+		return nil
+	}
+	gpus, cpuCores, region := energyResources(resources)
+	if region == "" {
+		region = c.DefaultRegion
+	}
+	factor, ok := c.GridFactors[region]
+	if !ok {
+		factor = c.DefaultGridFactor
+	}
+	duration := endedAt.Sub(startedAt).Seconds()
+	kwh := duration / 3600 * (gpus*c.GPUWatts + cpuCores*c.CPUWatts) / 1000
+	return &executionLedgerEnergy{
+		Method:          energyMethodTDPv1,
+		DurationSeconds: duration,
+		GPUs:            gpus,
+		CPUCores:        cpuCores,
+		GPUTDPWatts:     c.GPUWatts,
+		CPUTDPWatts:     c.CPUWatts,
+		EnergyKWh:       roundEnergy(kwh),
+		Region:          region,
+		GridFactor:      factor,
+		CO2eKg:          roundEnergy(kwh * factor),
+	}
+}
+
+func roundEnergy(v float64) float64 {
+	return math.Round(v*1e6) / 1e6
+}
+
+type energyUsageTotals struct {
+	Runs            int     `json:"runs"`
+	LedgerRuns      int     `json:"ledger_runs"`
+	DurationSeconds float64 `json:"duration_seconds"`
+	EnergyKWh       float64 `json:"energy_kwh"`
+	CO2eKg          float64 `json:"co2e_kg"`
+}
+
+func (t *energyUsageTotals) add(energy executionLedgerEnergy, fromLedger bool) {
+	t.Runs++
+	if fromLedger {
+		t.LedgerRuns++
+	}
+	t.DurationSeconds += energy.DurationSeconds
+	t.EnergyKWh = roundEnergy(t.EnergyKWh + energy.EnergyKWh)
+	t.CO2eKg = roundEnergy(t.CO2eKg + energy.CO2eKg)
+}
+
+type energyUsageGroup struct {
+	Key string `json:"key"`
+	energyUsageTotals
+}
+
+type energyUsageResponse struct {
+	ProjectID string             `json:"project_id"`
+	From      *time.Time         `json:"from,omitempty"`
+	To        *time.Time         `json:"to,omitempty"`
+	GroupBy   string             `json:"group_by"`
+	Totals    energyUsageTotals  `json:"totals"`
+	Groups    []energyUsageGroup `json:"groups"`
+}
+
+type energyUsageRun struct {
+	ExperimentID string
+	EndedAt      time.Time
+	Energy       executionLedgerEnergy
+	FromLedger   bool
+}
+
+// aggregateEnergyUsage sums run estimates overall and per group; groups are
+// ordered by emissions, largest first.
+func aggregateEnergyUsage(runs []energyUsageRun, groupBy string) (energyUsageTotals, []energyUsageGroup) {
+	var totals energyUsageTotals
+	byKey := map[string]*energyUsageGroup{}
+	for _, run := range runs {
+		totals.add(run.Energy, run.FromLedger)
+		var key string
+		switch groupBy {
+		case energyGroupRegion:
+			key = run.Energy.Region
+		case energyGroupDay:
+			key = run.EndedAt.UTC().Format(time.DateOnly)
+		default:
+			key = run.ExperimentID
+		}
+		group, ok := byKey[key]
+		if !ok {
+			group = &energyUsageGroup{Key: key}
+			byKey[key] = group
+		}
+		group.add(run.Energy, run.FromLedger)
+	}
+	groups := make([]energyUsageGroup, 0, len(byKey))
+	for _, group := range byKey {
+		groups = append(groups, *group)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].CO2eKg != groups[j].CO2eKg {
+			return groups[i].CO2eKg > groups[j].CO2eKg
+		}
+		return groups[i].Key < groups[j].Key
+	})
+	return totals, groups
+}
+
+// handleGetEnergyUsage aggregates the energy and carbon footprint of the
+// project's finished runs by end time. Runs whose ledger entry carries an
+// estimate use it; the rest are estimated with the current configuration.
+func (api *experimentsAPI) handleGetEnergyUsage(w http.ResponseWriter, r *http.Request) {
+	_, projectID, ok := api.scopedProject(w, r)
+	if !ok {
+		return
+	}
+	fromTime, fromOk, err := parseTimeQuery(r, "from")
+	if err != nil {
+		api.writeError(w, r, http.StatusBadRequest, "invalid_from")
+		return
+	}
+	toTime, toOk, err := parseTimeQuery(r, "to")
+	if err != nil {
+		api.writeError(w, r, http.StatusBadRequest, "invalid_to")
+		return
+	}
+	groupBy := strings.TrimSpace(r.URL.Query().Get("group_by"))
+	switch groupBy {
+	case "":
+		groupBy = energyGroupExperiment
+	case energyGroupExperiment, energyGroupRegion, energyGroupDay:
+	default:
+		api.writeError(w, r, http.StatusBadRequest, "invalid_group_by")
+		return
+	}
+
+	resp := energyUsageResponse{ProjectID: projectID, GroupBy: groupBy}
+	var from, to any
+	if fromOk {
+		from = fromTime
+		resp.From = &fromTime
+	}
+	if toOk {
+		to = toTime
+		resp.To = &toTime
+	}
+	rows, err := api.db.QueryContext(
+		r.Context(),
+		`SELECT r.experiment_id, r.started_at, r.ended_at, COALESCE(e.resources, '{}'::jsonb), l.entry->'energy'
+		 FROM experiment_runs r
+		 LEFT JOIN experiment_run_executions e ON e.run_id = r.run_id
+		 LEFT JOIN execution_ledger_entries l ON l.run_id = r.run_id
+		 WHERE r.project_id = $1
+		   AND r.ended_at IS NOT NULL
+		   AND ($2::timestamptz IS NULL OR r.ended_at >= $2)
+		   AND ($3::timestamptz IS NULL OR r.ended_at < $3)
+		   AND ($4 = '' OR r.experiment_id = $4)`,
+		projectID,
+		from,
+		to,
+		strings.TrimSpace(r.URL.Query().Get("experiment_id")),
+	)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer rows.Close()
+
+	runs := []energyUsageRun{}
+	for rows.Next() {
+		var (
+			run        energyUsageRun
+			startedAt  time.Time
+			resources  []byte
+			ledgerJSON []byte
+		)
+		if err := rows.Scan(&run.ExperimentID, &startedAt, &run.EndedAt, &resources, &ledgerJSON); err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+		if len(ledgerJSON) > 0 && json.Unmarshal(ledgerJSON, &run.Energy) == nil && run.Energy.Method != "" {
+			run.FromLedger = true
+		} else {
+			estimate := api.energy.estimate(resources, startedAt, &run.EndedAt)
+			if estimate == nil {
+				continue
+			}
+			run.Energy = *estimate
+		}
+		runs = append(runs, run)
+	}
+	if err := rows.Err(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	resp.Totals, resp.Groups = aggregateEnergyUsage(runs, groupBy)
+	api.writeJSON(w, http.StatusOK, resp)
+}
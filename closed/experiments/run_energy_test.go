@@ -0,0 +1,89 @@
+package experiments
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestParseGridFactors(t *testing.T) {
+	factors, err := parseGridFactors(" EU-North-1=0.03, us-east-1 = 0.38 ,")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if factors["eu-north-1"] != 0.03 || factors["us-east-1"] != 0.38 || len(factors) != 2 {
+		t.Fatalf("factors = %v", factors)
+	}
+	for _, raw := range []string{"eu-north-1", "=0.1", "eu=abc", "eu=-1"} {
+		if _, err := parseGridFactors(raw); err == nil {
+			t.Fatalf("expected error for %q", raw)
+		}
+	}
+}
+
+func TestEnergyEstimate(t *testing.T) {
+	cfg := energyConfig{
+		GPUWatts:          300,
+		CPUWatts:          10,
+		DefaultRegion:     "eu-west-1",
+		GridFactors:       map[string]float64{"eu-north-1": 0.03, "eu-west-1": 0.3},
+		DefaultGridFactor: 0.5,
+	}
+	start := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	end := start.Add(2 * time.Hour)
+
+	if got := cfg.estimate(json.RawMessage(`{"gpus":1}`), start, nil); got != nil {
+		t.Fatalf("running run estimated: %+v", got)
+	}
+
+	// 2h × (2 × 300 W + 4 × 10 W) = 1.28 kWh.
+	got := cfg.estimate(json.RawMessage(`{"gpus":2,"cpu":"4000m","region":"EU-North-1"}`), start, &end)
+	if got == nil {
+		t.Fatal("expected estimate")
+	}
+	if got.EnergyKWh != 1.28 || got.Region != "eu-north-1" || got.GridFactor != 0.03 || got.CO2eKg != 0.0384 {
+		t.Fatalf("estimate = %+v", got)
+	}
+	if got.DurationSeconds != 7200 || got.Method != energyMethodTDPv1 {
+		t.Fatalf("estimate = %+v", got)
+	}
+
+	// No hints: one CPU core in the default region.
+	got = cfg.estimate(json.RawMessage(`{}`), start, &end)
+	if got.GPUs != 0 || got.CPUCores != 1 || got.EnergyKWh != 0.02 || got.Region != "eu-west-1" || got.CO2eKg != 0.006 {
+		t.Fatalf("estimate = %+v", got)
+	}
+
+	cfg.DefaultRegion = ""
+	got = cfg.estimate(json.RawMessage(`{"gpu":1,"region":"ap-south-1"}`), start, &end)
+	if got.GridFactor != 0.5 || got.GPUs != 1 {
+		t.Fatalf("estimate = %+v", got)
+	}
+}
+
+func TestAggregateEnergyUsage(t *testing.T) {
+	day := time.Date(2026, 5, 1, 23, 0, 0, 0, time.UTC)
+	runs := []energyUsageRun{
+		{ExperimentID: "a", EndedAt: day, Energy: executionLedgerEnergy{DurationSeconds: 60, EnergyKWh: 1, Region: "eu", CO2eKg: 0.3}, FromLedger: true},
+		{ExperimentID: "b", EndedAt: day.Add(2 * time.Hour), Energy: executionLedgerEnergy{DurationSeconds: 30, EnergyKWh: 2, Region: "us", CO2eKg: 0.8}},
+		{ExperimentID: "a", EndedAt: day.Add(time.Hour), Energy: executionLedgerEnergy{DurationSeconds: 10, EnergyKWh: 0.5, Region: "us", CO2eKg: 0.2}},
+	}
+
+	totals, groups := aggregateEnergyUsage(runs, energyGroupExperiment)
+	if totals.Runs != 3 || totals.LedgerRuns != 1 || totals.EnergyKWh != 3.5 || totals.CO2eKg != 1.3 || totals.DurationSeconds != 100 {
+		t.Fatalf("totals = %+v", totals)
+	}
+	if len(groups) != 2 || groups[0].Key != "b" || groups[1].Key != "a" || groups[1].Runs != 2 || groups[1].CO2eKg != 0.5 {
+		t.Fatalf("groups = %+v", groups)
+	}
+
+	_, groups = aggregateEnergyUsage(runs, energyGroupRegion)
+	if len(groups) != 2 || groups[0].Key != "us" || groups[0].CO2eKg != 1 {
+		t.Fatalf("region groups = %+v", groups)
+	}
+
+	_, groups = aggregateEnergyUsage(runs, energyGroupDay)
+	if len(groups) != 2 || groups[0].Key != "2026-05-02" || groups[1].Key != "2026-05-01" {
+		t.Fatalf("day groups = %+v", groups)
+	}
+}
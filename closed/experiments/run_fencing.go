@@ -0,0 +1,425 @@
+package experiments
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+)
+
+// Run fencing modes. exclusive rejects a new run while another active run of
+// the experiment uses one of its dataset versions; dedupe returns the existing
+// run when one with the same datasets, params and commit has not failed.
+const (
+	runFencingExclusive = "exclusive"
+	runFencingDedupe    = "dedupe"
+)
+
+type runFencing struct {
+	ExperimentID string    `json:"experiment_id"`
+	Mode         string    `json:"mode"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	UpdatedBy    string    `json:"updated_by"`
+}
+
+type setRunFencingRequest struct {
+	Mode string `json:"mode"`
+}
+
+func normalizeRunFencingMode(mode string) (string, bool) {
+	mode = strings.ToLower(strings.TrimSpace(mode))
+	switch mode {
+	case runFencingExclusive, runFencingDedupe:
+		return mode, true
+	default:
+		return "", false
+	}
+}
+
+func isTerminalRunStatus(status string) bool {
+	switch status {
+	case "succeeded", "failed", "canceled":
+		return true
+	default:
+		return false
+	}
+}
+
+type fenceCandidate struct {
+	RunID             string
+	DatasetVersionIDs []string
+	Params            []byte
+	GitCommit         string
+}
+
+// exclusiveConflict returns the first candidate sharing a dataset version with
+// the new run.
+func exclusiveConflict(candidates []fenceCandidate, datasetVersionIDs []string) string {
+	for _, candidate := range candidates {
+		for _, versionID := range candidate.DatasetVersionIDs {
+			if slices.Contains(datasetVersionIDs, versionID) {
+				return candidate.RunID
+			}
+		}
+	}
+	return ""
+}
+
+// dedupeMatch returns the first candidate with the same ordered dataset
+// versions, params and git commit. Params are compared as JSON values since
+// jsonb does not keep key order.
+func dedupeMatch(candidates []fenceCandidate, datasetVersionIDs []string, paramsJSON []byte, gitCommit string) (string, error) {
+	var params any
+	if err := json.Unmarshal(paramsJSON, &params); err != nil {
+		return "", err
+	}
+	for _, candidate := range candidates {
+		if candidate.GitCommit != gitCommit || !slices.Equal(candidate.DatasetVersionIDs, datasetVersionIDs) {
+			continue
+		}
+		var existing any
+		if err := json.Unmarshal(candidate.Params, &existing); err != nil {
+			return "", err
+		}
+		if reflect.DeepEqual(existing, params) {
+			return candidate.RunID, nil
+		}
+	}
+	return "", nil
+}
+
+// fenceExperimentRun applies the experiment's fencing mode inside the run
+// creation transaction. Registrations of a fenced experiment are serialized by
+// an advisory lock held until the transaction ends, so concurrent CI retries
+// see each other's runs. It returns the run that blocks or absorbs the
+// request, or "" when the new run may be created.
+func fenceExperimentRun(ctx context.Context, tx *sql.Tx, experimentID string, datasetVersionIDs []string, status string, paramsJSON []byte, gitCommit string) (string, string, error) {
+	var mode string
+	err := tx.QueryRowContext(ctx, `SELECT mode FROM experiment_run_fencing WHERE experiment_id = $1`, experimentID).Scan(&mode)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", "", nil
+		}
+		return "", "", err
+	}
+	// A run registered as already finished does not start a training.
+	if mode == runFencingExclusive && (len(datasetVersionIDs) == 0 || isTerminalRunStatus(status)) {
+		return mode, "", nil
+	}
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, "experiment_run_fencing:"+experimentID); err != nil {
+		return "", "", err
+	}
+
+	candidates, err := loadFenceCandidates(ctx, tx, experimentID, mode, paramsJSON, gitCommit)
+	if err != nil {
+		return "", "", err
+	}
+	if mode == runFencingExclusive {
+		return mode, exclusiveConflict(candidates, datasetVersionIDs), nil
+	}
+	runID, err := dedupeMatch(candidates, datasetVersionIDs, paramsJSON, gitCommit)
+	return mode, runID, err
+}
+
+func loadFenceCandidates(ctx context.Context, tx *sql.Tx, experimentID string, mode string, paramsJSON []byte, gitCommit string) ([]fenceCandidate, error) {
+	excluded := `'succeeded','failed','canceled'`
+	args := []any{experimentID}
+	filter := ""
+	if mode == runFencingDedupe {
+		excluded = `'failed','canceled'`
+		filter = ` AND r.params = $2::jsonb AND COALESCE(r.git_commit, '') = $3`
+		args = append(args, string(paramsJSON), gitCommit)
+	}
+	rows, err := tx.QueryContext(
+		ctx,
+		fmt.Sprintf(`SELECT r.run_id,
+				r.dataset_version_id,
+				(SELECT COALESCE(json_agg(d.dataset_version_id ORDER BY d.position), '[]'::json)
+				   FROM experiment_run_datasets d
+				  WHERE d.run_id = r.run_id) AS dataset_version_ids,
+				r.params,
+				r.git_commit
+		 FROM experiment_runs r
+		 LEFT JOIN LATERAL (
+			SELECT status
+			FROM experiment_run_state_events
+			WHERE run_id = r.run_id
+			ORDER BY observed_at DESC
+			LIMIT 1
+		 ) s ON true
+		 WHERE r.experiment_id = $1
+		   AND COALESCE(s.status, r.status) NOT IN (%s)%s
+		 ORDER BY r.started_at DESC
+		 LIMIT 100`, excluded, filter),
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []fenceCandidate{}
+	for rows.Next() {
+		var (
+			candidate         fenceCandidate
+			datasetVersionID  sql.NullString
+			datasetVersionIDs []byte
+			gitCommit         sql.NullString
+		)
+		if err := rows.Scan(&candidate.RunID, &datasetVersionID, &datasetVersionIDs, &candidate.Params, &gitCommit); err != nil {
+			return nil, err
+		}
+		// Runs registered before experiment_run_datasets only carry the
+		// primary dataset version.
+		candidate.DatasetVersionIDs = decodeRunDatasetVersionIDs(datasetVersionIDs)
+		if len(candidate.DatasetVersionIDs) == 0 {
+			candidate.DatasetVersionIDs = runDatasetVersionIDs(datasetVersionID.String, nil)
+		}
+		candidate.GitCommit = strings.TrimSpace(gitCommit.String)
+		out = append(out, candidate)
+	}
+	return out, rows.Err()
+}
+
+// writeFencedRun answers a run registration stopped by fencing: dedupe
+// returns the existing run with 200, exclusive rejects with 409.
+func (api *experimentsAPI) writeFencedRun(w http.ResponseWriter, r *http.Request, identity auth.Identity, experimentID string, mode string, runID string) {
+	action := "experiment_run.fenced"
+	if mode == runFencingDedupe {
+		action = "experiment_run.deduplicated"
+	}
+	_ = auditlog.Enqueue(r.Context(), api.db, auditlog.Event{
+		OccurredAt:   time.Now().UTC(),
+		Actor:        identity.Subject,
+		Action:       action,
+		ResourceType: "experiment_run",
+		ResourceID:   runID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload: map[string]any{
+			"service":       "experiments",
+			"experiment_id": experimentID,
+			"run_id":        runID,
+			"mode":          mode,
+		},
+	})
+
+	if mode != runFencingDedupe {
+		api.writeJSON(w, http.StatusConflict, map[string]any{
+			"error":      "run_in_progress",
+			"request_id": r.Header.Get("X-Request-Id"),
+			"run_id":     runID,
+		})
+		return
+	}
+	run, err := api.getExperimentRun(r.Context(), runID)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	w.Header().Set("Location", "/experiment-runs/"+runID)
+	api.writeJSON(w, http.StatusOK, run)
+}
+
+// scopedExperiment resolves the experiment_id path value within the
+// caller's project.
+func (api *experimentsAPI) scopedExperiment(w http.ResponseWriter, r *http.Request) (auth.Identity, string, bool) {
+	identity, ok := auth.IdentityFromContext(r.Context())
+	if !ok || strings.TrimSpace(identity.Subject) == "" {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return auth.Identity{}, "", false
+	}
+	projectID, ok := auth.ProjectIDFromContext(r.Context())
+	if !ok || strings.TrimSpace(projectID) == "" {
+		api.writeError(w, r, http.StatusBadRequest, "project_id_required")
+		return auth.Identity{}, "", false
+	}
+	experimentID := strings.TrimSpace(r.PathValue("experiment_id"))
+	if experimentID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "experiment_id_required")
+		return auth.Identity{}, "", false
+	}
+	var one int
+	err := api.db.QueryRowContext(
+		r.Context(),
+		`SELECT 1 FROM experiments WHERE experiment_id = $1 AND project_id = $2`,
+		experimentID,
+		projectID,
+	).Scan(&one)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return auth.Identity{}, "", false
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return auth.Identity{}, "", false
+	}
+	return identity, experimentID, true
+}
+
+func (api *experimentsAPI) getRunFencing(ctx context.Context, experimentID string) (runFencing, error) {
+	out := runFencing{ExperimentID: experimentID}
+	err := api.db.QueryRowContext(
+		ctx,
+		`SELECT mode, updated_at, updated_by FROM experiment_run_fencing WHERE experiment_id = $1`,
+		experimentID,
+	).Scan(&out.Mode, &out.UpdatedAt, &out.UpdatedBy)
+	if err != nil {
+		return runFencing{}, err
+	}
+	out.UpdatedAt = out.UpdatedAt.UTC()
+	return out, nil
+}
+
+func (api *experimentsAPI) handleGetRunFencing(w http.ResponseWriter, r *http.Request) {
+	_, experimentID, ok := api.scopedExperiment(w, r)
+	if !ok {
+		return
+	}
+	fencing, err := api.getRunFencing(r.Context(), experimentID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			api.writeError(w, r, http.StatusNotFound, "run_fencing_not_configured")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	api.writeJSON(w, http.StatusOK, fencing)
+}
+
+func (api *experimentsAPI) handleSetRunFencing(w http.ResponseWriter, r *http.Request) {
+	identity, experimentID, ok := api.scopedExperiment(w, r)
+	if !ok {
+		return
+	}
+	var req setRunFencingRequest
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
+		return
+	}
+	mode, ok := normalizeRunFencingMode(req.Mode)
+	if !ok {
+		api.writeError(w, r, http.StatusBadRequest, "invalid_mode")
+		return
+	}
+
+	now := time.Now().UTC()
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	_, err = tx.ExecContext(
+		r.Context(),
+		`INSERT INTO experiment_run_fencing (experiment_id, mode, updated_at, updated_by)
+		 VALUES ($1,$2,$3,$4)
+		 ON CONFLICT (experiment_id) DO UPDATE
+		 SET mode = EXCLUDED.mode,
+			 updated_at = EXCLUDED.updated_at,
+			 updated_by = EXCLUDED.updated_by`,
+		experimentID,
+		mode,
+		now,
+		identity.Subject,
+	)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if err := auditlog.Enqueue(r.Context(), tx, auditlog.Event{
+		OccurredAt:   now,
+		Actor:        identity.Subject,
+		Action:       "experiment.run_fencing_set",
+		ResourceType: "experiment",
+		ResourceID:   experimentID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload: map[string]any{
+			"service":       "experiments",
+			"experiment_id": experimentID,
+			"mode":          mode,
+		},
+	}); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "audit_failed")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	api.writeJSON(w, http.StatusOK, runFencing{
+		ExperimentID: experimentID,
+		Mode:         mode,
+		UpdatedAt:    now,
+		UpdatedBy:    identity.Subject,
+	})
+}
+
+func (api *experimentsAPI) handleDeleteRunFencing(w http.ResponseWriter, r *http.Request) {
+	identity, experimentID, ok := api.scopedExperiment(w, r)
+	if !ok {
+		return
+	}
+
+	now := time.Now().UTC()
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var mode string
+	err = tx.QueryRowContext(
+		r.Context(),
+		`DELETE FROM experiment_run_fencing WHERE experiment_id = $1 RETURNING mode`,
+		experimentID,
+	).Scan(&mode)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			api.writeError(w, r, http.StatusNotFound, "run_fencing_not_configured")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if err := auditlog.Enqueue(r.Context(), tx, auditlog.Event{
+		OccurredAt:   now,
+		Actor:        identity.Subject,
+		Action:       "experiment.run_fencing_deleted",
+		ResourceType: "experiment",
+		ResourceID:   experimentID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload: map[string]any{
+			"service":       "experiments",
+			"experiment_id": experimentID,
+			"mode":          mode,
+		},
+	}); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "audit_failed")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,56 @@
+package experiments
+
+import "testing"
+
+func TestNormalizeRunFencingMode(t *testing.T) {
+	for input, want := range map[string]string{" Exclusive ": runFencingExclusive, "dedupe": runFencingDedupe} {
+		got, ok := normalizeRunFencingMode(input)
+		if !ok || got != want {
+			t.Fatalf("normalizeRunFencingMode(%q) = %q, %v", input, got, ok)
+		}
+	}
+	for _, input := range []string{"", "none", "queue"} {
+		if _, ok := normalizeRunFencingMode(input); ok {
+			t.Fatalf("expected %q to be rejected", input)
+		}
+	}
+}
+
+func TestExclusiveConflict(t *testing.T) {
+	candidates := []fenceCandidate{
+		{RunID: "run-1", DatasetVersionIDs: []string{"dv-a"}},
+		{RunID: "run-2", DatasetVersionIDs: []string{"dv-b", "dv-c"}},
+	}
+	if got := exclusiveConflict(candidates, []string{"dv-c"}); got != "run-2" {
+		t.Fatalf("expected run-2, got %q", got)
+	}
+	if got := exclusiveConflict(candidates, []string{"dv-d"}); got != "" {
+		t.Fatalf("expected no conflict, got %q", got)
+	}
+}
+
+func TestDedupeMatch(t *testing.T) {
+	candidates := []fenceCandidate{
+		{RunID: "run-1", DatasetVersionIDs: []string{"dv-a", "dv-b"}, Params: []byte(`{"lr": 0.1, "epochs": 3}`), GitCommit: "abc"},
+	}
+	params := []byte(`{"epochs":3,"lr":0.1}`)
+
+	got, err := dedupeMatch(candidates, []string{"dv-a", "dv-b"}, params, "abc")
+	if err != nil || got != "run-1" {
+		t.Fatalf("expected run-1, got %q (%v)", got, err)
+	}
+	for name, tc := range map[string]struct {
+		datasets []string
+		params   string
+		commit   string
+	}{
+		"dataset order": {[]string{"dv-b", "dv-a"}, `{"epochs":3,"lr":0.1}`, "abc"},
+		"params":        {[]string{"dv-a", "dv-b"}, `{"epochs":4,"lr":0.1}`, "abc"},
+		"commit":        {[]string{"dv-a", "dv-b"}, `{"epochs":3,"lr":0.1}`, "def"},
+	} {
+		got, err := dedupeMatch(candidates, tc.datasets, []byte(tc.params), tc.commit)
+		if err != nil || got != "" {
+			t.Fatalf("%s: expected no match, got %q (%v)", name, got, err)
+		}
+	}
+}
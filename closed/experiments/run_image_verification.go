@@ -0,0 +1,269 @@
+package experiments
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/domain"
+	"github.com/animus-labs/animus-go/closed/internal/integrations/registryverify"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/env"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	"github.com/animus-labs/animus-go/closed/internal/platform/policy"
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+)
+
+const (
+	runImageVerifyModeOff     = "off"
+	runImageVerifyModeAudit   = "audit"
+	runImageVerifyModeEnforce = "enforce"
+
+	auditRunImageVerification = "run.image_verification"
+
+	runImageFailureSBOMMissing = "sbom_missing"
+)
+
+// runImageVerifyConfig controls the supply-chain check of run images before
+// dispatch. Audit mode records results and exposes them to policies; enforce
+// mode also blocks runs whose images fail verification.
+type runImageVerifyConfig struct {
+	Mode        string
+	RequireSBOM bool
+}
+
+func runImageVerifyConfigFromEnv() (runImageVerifyConfig, error) {
+	requireSBOM, err := env.Bool("ANIMUS_RUN_IMAGE_VERIFY_REQUIRE_SBOM", false)
+	if err != nil {
+		return runImageVerifyConfig{}, err
+	}
+	cfg := runImageVerifyConfig{
+		Mode:        strings.ToLower(strings.TrimSpace(env.String("ANIMUS_RUN_IMAGE_VERIFY_MODE", runImageVerifyModeOff))),
+		RequireSBOM: requireSBOM,
+	}
+	if err := cfg.Validate(); err != nil {
+		return runImageVerifyConfig{}, err
+	}
+	return cfg, nil
+}
+
+func (c runImageVerifyConfig) Validate() error {
+	switch c.Mode {
+	case runImageVerifyModeOff, runImageVerifyModeAudit, runImageVerifyModeEnforce:
+		return nil
+	default:
+		return fmt.Errorf("unsupported run image verify mode %q", c.Mode)
+	}
+}
+
+// Enabled treats the zero value as off so tests and callers that never load
+// the config keep dispatching without registry calls.
+func (c runImageVerifyConfig) Enabled() bool {
+	return c.Mode == runImageVerifyModeAudit || c.Mode == runImageVerifyModeEnforce
+}
+
+type runImageCheck struct {
+	Name              string `json:"name,omitempty"`
+	ImageDigestRef    string `json:"image_digest_ref"`
+	Provider          string `json:"provider,omitempty"`
+	Signed            bool   `json:"signed"`
+	Verified          bool   `json:"verified"`
+	SBOM              bool   `json:"sbom"`
+	SBOMPredicateType string `json:"sbom_predicate_type,omitempty"`
+	SBOMDigest        string `json:"sbom_digest,omitempty"`
+	FailureReason     string `json:"failure_reason,omitempty"`
+}
+
+type runImageVerification struct {
+	Images []runImageCheck
+	// FailureReason is the reason of the first failed image; empty when every
+	// image passed.
+	FailureReason string
+
+	// records are the image_verifications rows of the checked images,
+	// written with the audit event by checkRunImages.
+	records []registryverify.Record
+}
+
+func (v runImageVerification) Failed() bool {
+	return v.FailureReason != ""
+}
+
+// policyImage folds the checks into the single image of the policy context:
+// flags are true only when every image has them.
+func (v runImageVerification) policyImage(image policy.ImageContext) policy.ImageContext {
+	if len(v.Images) == 0 {
+		return image
+	}
+	signed, verified, sbom := true, true, true
+	predicateType := v.Images[0].SBOMPredicateType
+	for _, image := range v.Images {
+		signed = signed && image.Signed
+		verified = verified && image.Verified
+		sbom = sbom && image.SBOM
+		if image.SBOMPredicateType != predicateType {
+			predicateType = ""
+		}
+	}
+	image.Signed = &signed
+	image.Verified = &verified
+	image.SBOM = &sbom
+	image.SBOMPredicateType = predicateType
+	return image
+}
+
+// runSpecImages returns the images of the run's environment lock; a run
+// without a stored spec has none.
+func runSpecImages(runRecord repo.RunRecord) ([]domain.EnvironmentImage, error) {
+	if len(runRecord.RunSpec) == 0 {
+		return nil, nil
+	}
+	var spec runSpecPayload
+	if err := json.Unmarshal(runRecord.RunSpec, &spec); err != nil {
+		return nil, err
+	}
+	return spec.EnvLock.Images, nil
+}
+
+// verifyRunImages checks the signature and SBOM attestation of every image in
+// the run's environment lock with the project's registry provider. It makes
+// no writes: the results carry the image_verifications rows to upsert, like
+// environment lock checks, once the registry calls are done.
+func (api *experimentsAPI) verifyRunImages(ctx context.Context, projectID string, images []domain.EnvironmentImage) (runImageVerification, error) {
+	regPolicy, err := api.registryPolicyResolver.Resolve(ctx, projectID)
+	if err != nil {
+		return runImageVerification{}, err
+	}
+	regPolicy = regPolicy.Normalize()
+	provider := api.registryProvider(regPolicy.Provider)
+
+	out := runImageVerification{Images: make([]runImageCheck, 0, len(images))}
+	for _, image := range images {
+		check := runImageCheck{Name: strings.TrimSpace(image.Name)}
+		digestRef, err := registryverify.BuildDigestRef(image.Ref, image.Digest)
+		if err != nil {
+			check.ImageDigestRef = strings.TrimSpace(image.Ref)
+			check.FailureReason = "invalid_digest_ref"
+			out.add(check)
+			continue
+		}
+		check.ImageDigestRef = digestRef
+
+		record, _, _, err := api.verifyRegistryImage(ctx, regPolicy, projectID, digestRef)
+		if err != nil {
+			return runImageVerification{}, err
+		}
+		check.Provider = record.Provider
+		check.Signed = record.Signed
+		check.Verified = record.Verified
+		if !record.Verified {
+			check.FailureReason = record.FailureReason
+		}
+
+		attestation := api.fetchRunImageSBOM(ctx, provider, digestRef)
+		check.SBOM = attestation.Found
+		check.SBOMPredicateType = attestation.PredicateType
+		check.SBOMDigest = attestation.Digest
+		if check.FailureReason == "" && !check.SBOM && api.runImageVerify.RequireSBOM {
+			check.FailureReason = runImageFailureSBOMMissing
+		}
+
+		details := map[string]any{}
+		_ = json.Unmarshal(record.Details, &details)
+		details["sbom"] = check.SBOM
+		if check.SBOM {
+			details["sbom_type"] = check.SBOMPredicateType
+			details["sbom_digest"] = check.SBOMDigest
+		}
+		record.Details = registryverify.SanitizeDetails(details)
+		out.records = append(out.records, record)
+		out.add(check)
+	}
+	return out, nil
+}
+
+func (api *experimentsAPI) fetchRunImageSBOM(ctx context.Context, provider registryverify.Provider, imageDigestRef string) registryverify.SBOMAttestation {
+	if api.registryVerifyTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, api.registryVerifyTimeout)
+		defer cancel()
+	}
+	attestation, err := registryverify.FetchSBOMAttestation(ctx, provider, imageDigestRef)
+	if err != nil {
+		return registryverify.SBOMAttestation{ImageDigestRef: imageDigestRef, FailureReason: "provider_error"}
+	}
+	return attestation
+}
+
+func (v *runImageVerification) add(check runImageCheck) {
+	v.Images = append(v.Images, check)
+	if v.FailureReason == "" {
+		v.FailureReason = check.FailureReason
+	}
+}
+
+// checkRunImages verifies run images and audits the result. In enforce mode
+// a failed verification answers the request and blocks dispatch.
+func (api *experimentsAPI) checkRunImages(w http.ResponseWriter, r *http.Request, identity auth.Identity, runRecord repo.RunRecord, images []domain.EnvironmentImage) (runImageVerification, bool) {
+	ctx := r.Context()
+	verification, err := api.verifyRunImages(ctx, runRecord.ProjectID, images)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return runImageVerification{}, false
+	}
+
+	tx, err := api.db.BeginTx(ctx, nil)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return runImageVerification{}, false
+	}
+	defer func() { _ = tx.Rollback() }()
+	store := api.imageVerificationStore(tx)
+	for _, record := range verification.records {
+		if _, err := store.Upsert(ctx, record); err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return runImageVerification{}, false
+		}
+	}
+	err = auditlog.Enqueue(ctx, tx, auditlog.Event{
+		OccurredAt:   time.Now().UTC(),
+		Actor:        identity.Subject,
+		Action:       auditRunImageVerification,
+		ResourceType: "run",
+		ResourceID:   runRecord.ID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload: map[string]any{
+			"service":        "experiments",
+			"project_id":     runRecord.ProjectID,
+			"run_id":         runRecord.ID,
+			"mode":           api.runImageVerify.Mode,
+			"require_sbom":   api.runImageVerify.RequireSBOM,
+			"images":         verification.Images,
+			"failure_reason": verification.FailureReason,
+		},
+	})
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return runImageVerification{}, false
+	}
+
+	if verification.Failed() && api.runImageVerify.Mode == runImageVerifyModeEnforce {
+		code := "image_verification_failed"
+		if verification.FailureReason == runImageFailureSBOMMissing {
+			code = "image_sbom_required"
+		}
+		api.auditRunDispatchBlocked(r, identity, runRecord, map[string]any{"reason": verification.FailureReason})
+		api.writeError(w, r, http.StatusUnprocessableEntity, code)
+		return runImageVerification{}, false
+	}
+	return verification, true
+}
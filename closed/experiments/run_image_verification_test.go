@@ -0,0 +1,149 @@
+package experiments
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/domain"
+	"github.com/animus-labs/animus-go/closed/internal/integrations/registryverify"
+	"github.com/animus-labs/animus-go/closed/internal/platform/policy"
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+)
+
+type stubSBOMRegistryProvider struct {
+	stubRegistryProvider
+	attestation registryverify.SBOMAttestation
+}
+
+func (s stubSBOMRegistryProvider) FetchSBOMAttestation(ctx context.Context, imageDigestRef string) (registryverify.SBOMAttestation, error) {
+	return s.attestation, nil
+}
+
+func newRunImageTestAPI(provider registryverify.Provider, store *stubImageVerificationStore, cfg runImageVerifyConfig) *experimentsAPI {
+	return &experimentsAPI{
+		registryPolicyResolver: registryverify.PolicyResolver{Default: registryverify.Policy{Mode: registryverify.ModeVerifyOnly, Provider: "stub"}},
+		registryProviders:      map[string]registryverify.Provider{"stub": provider},
+		registryStoreOverride:  store,
+		runImageVerify:         cfg,
+	}
+}
+
+var runTestImages = []domain.EnvironmentImage{
+	{Name: "trainer", Ref: "ghcr.io/acme/trainer:1.0", Digest: "sha256:aaaaaaaa"},
+	{Name: "sidecar", Ref: "ghcr.io/acme/sidecar:1.0", Digest: "sha256:bbbbbbbb"},
+}
+
+func TestVerifyRunImagesSignedWithSBOM(t *testing.T) {
+	store := &stubImageVerificationStore{}
+	provider := stubSBOMRegistryProvider{
+		stubRegistryProvider: stubRegistryProvider{result: registryverify.VerificationResult{Verified: true, Signed: true, Provider: "stub", VerifiedAt: time.Now().UTC()}},
+		attestation:          registryverify.SBOMAttestation{Found: true, PredicateType: registryverify.PredicateSPDX, Digest: "sha256:cccc"},
+	}
+	api := newRunImageTestAPI(provider, store, runImageVerifyConfig{Mode: runImageVerifyModeEnforce, RequireSBOM: true})
+
+	result, err := api.verifyRunImages(context.Background(), "proj-1", runTestImages)
+	if err != nil {
+		t.Fatalf("verify run images: %v", err)
+	}
+	if result.Failed() || len(result.Images) != 2 {
+		t.Fatalf("expected two passing images, got %+v", result)
+	}
+	if len(result.records) != 2 || len(store.records) != 0 {
+		t.Fatalf("expected 2 records left to write, got %d (%d written)", len(result.records), len(store.records))
+	}
+	var details map[string]any
+	if err := json.Unmarshal(result.records[0].Details, &details); err != nil {
+		t.Fatalf("decode details: %v", err)
+	}
+	if details["sbom"] != true || details["sbom_digest"] != "sha256:cccc" {
+		t.Fatalf("unexpected record details %v", details)
+	}
+
+	image := result.policyImage(policy.ImageContext{Ref: "ghcr.io/acme/trainer:1.0", Digest: "sha256:aaaaaaaa"})
+	if image.Ref != "ghcr.io/acme/trainer:1.0" || image.Digest != "sha256:aaaaaaaa" {
+		t.Fatalf("unexpected policy image %+v", image)
+	}
+	if !*image.Signed || !*image.Verified || !*image.SBOM || image.SBOMPredicateType != registryverify.PredicateSPDX {
+		t.Fatalf("unexpected policy image flags %+v", image)
+	}
+}
+
+func TestVerifyRunImagesRequiresSBOM(t *testing.T) {
+	store := &stubImageVerificationStore{}
+	provider := stubRegistryProvider{result: registryverify.VerificationResult{Verified: true, Signed: true, Provider: "stub", VerifiedAt: time.Now().UTC()}}
+
+	api := newRunImageTestAPI(provider, store, runImageVerifyConfig{Mode: runImageVerifyModeEnforce, RequireSBOM: true})
+	result, err := api.verifyRunImages(context.Background(), "proj-1", runTestImages)
+	if err != nil {
+		t.Fatalf("verify run images: %v", err)
+	}
+	if result.FailureReason != runImageFailureSBOMMissing {
+		t.Fatalf("expected sbom_missing, got %q", result.FailureReason)
+	}
+
+	api = newRunImageTestAPI(provider, store, runImageVerifyConfig{Mode: runImageVerifyModeEnforce})
+	result, err = api.verifyRunImages(context.Background(), "proj-1", runTestImages)
+	if err != nil {
+		t.Fatalf("verify run images: %v", err)
+	}
+	if result.Failed() || *result.policyImage(policy.ImageContext{}).SBOM {
+		t.Fatalf("expected pass without sbom requirement, got %+v", result)
+	}
+}
+
+func TestVerifyRunImagesUnsignedAndInvalidDigest(t *testing.T) {
+	store := &stubImageVerificationStore{}
+	provider := stubRegistryProvider{result: registryverify.VerificationResult{Verified: false, Signed: false, Provider: "stub", VerifiedAt: time.Now().UTC(), FailureReason: "unsigned"}}
+	api := newRunImageTestAPI(provider, store, runImageVerifyConfig{Mode: runImageVerifyModeAudit})
+
+	images := []domain.EnvironmentImage{
+		{Name: "trainer", Ref: "ghcr.io/acme/trainer:1.0"},
+		runTestImages[1],
+	}
+	result, err := api.verifyRunImages(context.Background(), "proj-1", images)
+	if err != nil {
+		t.Fatalf("verify run images: %v", err)
+	}
+	if result.FailureReason != "invalid_digest_ref" || result.Images[1].FailureReason != "unsigned" {
+		t.Fatalf("unexpected failures %+v", result)
+	}
+	if len(result.records) != 1 {
+		t.Fatalf("expected only the pinned image recorded, got %d", len(result.records))
+	}
+	if image := result.policyImage(policy.ImageContext{}); *image.Signed || *image.Verified {
+		t.Fatalf("expected unsigned policy image, got %+v", image)
+	}
+}
+
+func TestRunSpecImages(t *testing.T) {
+	raw, err := json.Marshal(runSpecPayload{EnvLock: runSpecEnvLockPayload{Images: runTestImages}})
+	if err != nil {
+		t.Fatalf("marshal spec: %v", err)
+	}
+	images, err := runSpecImages(repo.RunRecord{RunSpec: raw})
+	if err != nil {
+		t.Fatalf("run spec images: %v", err)
+	}
+	if len(images) != 2 || images[0].Digest != "sha256:aaaaaaaa" {
+		t.Fatalf("unexpected images %+v", images)
+	}
+	if images, err := runSpecImages(repo.RunRecord{}); err != nil || images != nil {
+		t.Fatalf("expected no images without a spec, got %+v %v", images, err)
+	}
+}
+
+func TestRunImageVerifyConfigValidate(t *testing.T) {
+	for _, mode := range []string{runImageVerifyModeOff, runImageVerifyModeAudit, runImageVerifyModeEnforce} {
+		if err := (runImageVerifyConfig{Mode: mode}).Validate(); err != nil {
+			t.Fatalf("mode %s: %v", mode, err)
+		}
+	}
+	if err := (runImageVerifyConfig{Mode: "strict"}).Validate(); err == nil {
+		t.Fatalf("expected error for unknown mode")
+	}
+	if (runImageVerifyConfig{}).Enabled() {
+		t.Fatalf("zero config must be disabled")
+	}
+}
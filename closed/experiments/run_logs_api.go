@@ -0,0 +1,412 @@
+package experiments
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/dataplane"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+	"github.com/animus-labs/animus-go/closed/internal/repo/postgres"
+	"github.com/minio/minio-go/v7"
+)
+
+// runLogInlineMaxBytes is the largest chunk kept in Postgres; bigger chunks
+// spill to the artifacts bucket and run_logs keeps the object key.
+const runLogInlineMaxBytes = 64 << 10
+
+type runLogEntry struct {
+	LogID       int64     `json:"log_id"`
+	RunID       string    `json:"run_id"`
+	PodName     string    `json:"pod_name"`
+	Container   string    `json:"container"`
+	Content     string    `json:"content"`
+	SizeBytes   int64     `json:"size_bytes"`
+	Spilled     bool      `json:"spilled"`
+	FirstLineAt time.Time `json:"first_line_at"`
+	LastLineAt  time.Time `json:"last_line_at"`
+	ReceivedAt  time.Time `json:"received_at"`
+}
+
+// runLogCursor is the page position behind the cursor of the run logs list.
+type runLogCursor struct {
+	AfterLogID int64 `json:"after_log_id"`
+}
+
+type runLogIntegrityInput struct {
+	EventID       string    `json:"event_id"`
+	RunID         string    `json:"run_id"`
+	ProjectID     string    `json:"project_id"`
+	PodName       string    `json:"pod_name"`
+	Container     string    `json:"container"`
+	ContentSHA256 string    `json:"content_sha256"`
+	FirstLineAt   time.Time `json:"first_line_at"`
+	LastLineAt    time.Time `json:"last_line_at"`
+	EmittedAt     time.Time `json:"emitted_at"`
+}
+
+func runLogObjectKey(runID, eventID string) string {
+	return fmt.Sprintf("run-logs/%s/%s.log", runID, eventID)
+}
+
+func (api *experimentsAPI) handleDPRunLogs(w http.ResponseWriter, r *http.Request) {
+	identity, ok := auth.IdentityFromContext(r.Context())
+	if !ok || strings.TrimSpace(identity.Subject) == "" {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	runID := strings.TrimSpace(r.PathValue("run_id"))
+	if runID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "run_id_required")
+		return
+	}
+
+	var req dataplane.RunLogChunk
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
+		return
+	}
+	eventID := strings.TrimSpace(req.EventID)
+	projectID := strings.TrimSpace(req.ProjectID)
+	switch {
+	case strings.TrimSpace(req.RunID) == "":
+		api.writeError(w, r, http.StatusBadRequest, "run_id_required")
+		return
+	case eventID == "":
+		api.writeError(w, r, http.StatusBadRequest, "event_id_required")
+		return
+	case projectID == "":
+		api.writeError(w, r, http.StatusBadRequest, "project_id_required")
+		return
+	case !strings.EqualFold(req.RunID, runID):
+		api.writeError(w, r, http.StatusBadRequest, "run_id_mismatch")
+		return
+	case req.EmittedAt.IsZero():
+		api.writeError(w, r, http.StatusBadRequest, "emitted_at_required")
+		return
+	case strings.TrimSpace(req.PodName) == "" || strings.TrimSpace(req.Container) == "":
+		api.writeError(w, r, http.StatusBadRequest, "log_source_required")
+		return
+	case req.Content == "":
+		api.writeError(w, r, http.StatusBadRequest, "content_required")
+		return
+	}
+
+	runStore := postgres.NewRunSpecStore(api.db)
+	if runStore == nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if _, err := runStore.GetRun(r.Context(), projectID, runID); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	// Check for a replay before spilling so a conflicting event cannot
+	// overwrite the stored object.
+	var existingRunID string
+	err := api.db.QueryRowContext(r.Context(), `SELECT run_id FROM run_logs WHERE event_id = $1`, eventID).Scan(&existingRunID)
+	switch {
+	case err == nil:
+		if existingRunID != runID {
+			api.writeError(w, r, http.StatusConflict, "event_conflict")
+			return
+		}
+		api.writeJSON(w, http.StatusOK, dataplane.RunLogChunkResponse{Accepted: true, Duplicate: true})
+		return
+	case !errors.Is(err, sql.ErrNoRows):
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	sum := sha256.Sum256([]byte(req.Content))
+	integrity, err := integritySHA256(runLogIntegrityInput{
+		EventID:       eventID,
+		RunID:         runID,
+		ProjectID:     projectID,
+		PodName:       strings.TrimSpace(req.PodName),
+		Container:     strings.TrimSpace(req.Container),
+		ContentSHA256: hex.EncodeToString(sum[:]),
+		FirstLineAt:   req.FirstLineAt.UTC(),
+		LastLineAt:    req.LastLineAt.UTC(),
+		EmittedAt:     req.EmittedAt.UTC(),
+	})
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	var content, objectKey sql.NullString
+	if len(req.Content) > runLogInlineMaxBytes && api.store != nil {
+		objectKey = sql.NullString{String: runLogObjectKey(runID, eventID), Valid: true}
+		putCtx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+		_, err := api.store.PutObject(
+			putCtx,
+			api.storeCfg.BucketArtifacts,
+			objectKey.String,
+			strings.NewReader(req.Content),
+			int64(len(req.Content)),
+			minio.PutObjectOptions{ContentType: "text/plain; charset=utf-8"},
+		)
+		cancel()
+		if err != nil {
+			api.writeError(w, r, http.StatusBadGateway, "object_store_error")
+			return
+		}
+	} else {
+		content = sql.NullString{String: req.Content, Valid: true}
+	}
+
+	firstLineAt, lastLineAt := req.FirstLineAt.UTC(), req.LastLineAt.UTC()
+	if firstLineAt.IsZero() {
+		firstLineAt = req.EmittedAt.UTC()
+	}
+	if lastLineAt.IsZero() {
+		lastLineAt = firstLineAt
+	}
+	res, err := api.db.ExecContext(
+		r.Context(),
+		`INSERT INTO run_logs (
+			event_id, run_id, project_id, pod_name, container, content, object_key,
+			size_bytes, first_line_at, last_line_at, emitted_at, received_at, integrity_sha256
+		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13)
+		ON CONFLICT (event_id) DO NOTHING`,
+		eventID,
+		runID,
+		projectID,
+		strings.TrimSpace(req.PodName),
+		strings.TrimSpace(req.Container),
+		content,
+		objectKey,
+		int64(len(req.Content)),
+		firstLineAt,
+		lastLineAt,
+		req.EmittedAt.UTC(),
+		time.Now().UTC(),
+		integrity,
+	)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	affected, _ := res.RowsAffected()
+	api.writeJSON(w, http.StatusOK, dataplane.RunLogChunkResponse{Accepted: true, Duplicate: affected == 0})
+}
+
+func (api *experimentsAPI) handleGetExperimentRunLogs(w http.ResponseWriter, r *http.Request) {
+	runID := strings.TrimSpace(r.PathValue("run_id"))
+	if runID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "run_id_required")
+		return
+	}
+
+	var exists bool
+	if err := api.db.QueryRowContext(
+		r.Context(),
+		`SELECT EXISTS (SELECT 1 FROM runs WHERE run_id = $1) OR EXISTS (SELECT 1 FROM experiment_runs WHERE run_id = $1)`,
+		runID,
+	).Scan(&exists); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if !exists {
+		api.writeError(w, r, http.StatusNotFound, "not_found")
+		return
+	}
+
+	afterRaw := strings.TrimSpace(r.URL.Query().Get("after_log_id"))
+	if afterRaw == "" {
+		afterRaw = strings.TrimSpace(r.Header.Get("Last-Event-ID"))
+	}
+	var afterLogID int64
+	if afterRaw != "" {
+		parsed, err := strconv.ParseInt(afterRaw, 10, 64)
+		if err != nil || parsed < 0 {
+			api.writeError(w, r, http.StatusBadRequest, "invalid_after_log_id")
+			return
+		}
+		afterLogID = parsed
+	}
+	if raw := r.URL.Query().Get("cursor"); raw != "" {
+		var pos runLogCursor
+		if httpapi.DecodeCursor(raw, &pos) != nil || pos.AfterLogID < 0 {
+			api.writeError(w, r, http.StatusBadRequest, "invalid_cursor")
+			return
+		}
+		afterLogID = pos.AfterLogID
+	}
+	limit := httpapi.Limit(r, 200, 1000)
+
+	if !strings.EqualFold(strings.TrimSpace(r.URL.Query().Get("follow")), "true") {
+		logs, err := api.listRunLogs(r.Context(), runID, afterLogID, limit)
+		if err != nil {
+			api.writeRunLogError(w, r, err)
+			return
+		}
+		resp := map[string]any{
+			"run_id": runID,
+			"logs":   logs,
+		}
+		if len(logs) > 0 {
+			resp["next_after_log_id"] = logs[len(logs)-1].LogID
+			resp["next_cursor"] = httpapi.EncodeCursor(runLogCursor{AfterLogID: logs[len(logs)-1].LogID})
+		}
+		api.writeJSON(w, http.StatusOK, resp)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		api.writeError(w, r, http.StatusInternalServerError, "streaming_not_supported")
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	_ = writeSSE(w, "ready", "", map[string]any{
+		"run_id":       runID,
+		"after_log_id": afterLogID,
+		"server_ts":    time.Now().UTC().Unix(),
+		"request_id":   r.Header.Get("X-Request-Id"),
+	})
+
+	poll := time.NewTicker(1 * time.Second)
+	heartbeat := time.NewTicker(15 * time.Second)
+	defer poll.Stop()
+	defer heartbeat.Stop()
+
+	for {
+		logs, err := api.listRunLogs(r.Context(), runID, afterLogID, limit)
+		if err != nil {
+			if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+				return
+			}
+			_ = writeSSE(w, "error", "", map[string]any{"error": err.Error()})
+			return
+		}
+		for _, entry := range logs {
+			if err := writeSSE(w, "log", strconv.FormatInt(entry.LogID, 10), entry); err != nil {
+				return
+			}
+			afterLogID = entry.LogID
+		}
+		if len(logs) == limit {
+			continue
+		}
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			_, _ = fmt.Fprintf(w, ": ping\n\n")
+			flusher.Flush()
+		case <-poll.C:
+		}
+	}
+}
+
+var errRunLogObjectStore = errors.New("run log object store error")
+
+func (api *experimentsAPI) writeRunLogError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, errRunLogObjectStore) {
+		api.writeError(w, r, http.StatusBadGateway, "object_store_error")
+		return
+	}
+	api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+}
+
+// listRunLogs returns chunks after afterLogID in order, reading spilled
+// chunks back from the object store.
+func (api *experimentsAPI) listRunLogs(ctx context.Context, runID string, afterLogID int64, limit int) ([]runLogEntry, error) {
+	rows, err := api.db.QueryContext(
+		ctx,
+		`SELECT log_id, pod_name, container, content, object_key, size_bytes, first_line_at, last_line_at, received_at
+		 FROM run_logs
+		 WHERE run_id = $1 AND log_id > $2
+		 ORDER BY log_id ASC
+		 LIMIT $3`,
+		runID,
+		afterLogID,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type pendingSpill struct {
+		index     int
+		objectKey string
+	}
+	var (
+		out    []runLogEntry
+		spills []pendingSpill
+	)
+	for rows.Next() {
+		var (
+			entry     runLogEntry
+			content   sql.NullString
+			objectKey sql.NullString
+		)
+		if err := rows.Scan(&entry.LogID, &entry.PodName, &entry.Container, &content, &objectKey, &entry.SizeBytes, &entry.FirstLineAt, &entry.LastLineAt, &entry.ReceivedAt); err != nil {
+			return nil, err
+		}
+		entry.RunID = runID
+		entry.Content = content.String
+		entry.FirstLineAt = entry.FirstLineAt.UTC()
+		entry.LastLineAt = entry.LastLineAt.UTC()
+		entry.ReceivedAt = entry.ReceivedAt.UTC()
+		if !content.Valid && objectKey.Valid {
+			entry.Spilled = true
+			spills = append(spills, pendingSpill{index: len(out), objectKey: objectKey.String})
+		}
+		out = append(out, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, spill := range spills {
+		content, err := api.readRunLogObject(ctx, spill.objectKey)
+		if err != nil {
+			return nil, err
+		}
+		out[spill.index].Content = content
+	}
+	return out, nil
+}
+
+func (api *experimentsAPI) readRunLogObject(ctx context.Context, objectKey string) (string, error) {
+	if api.store == nil {
+		return "", fmt.Errorf("%w: object store not configured", errRunLogObjectStore)
+	}
+	obj, err := api.store.GetObject(ctx, api.storeCfg.BucketArtifacts, objectKey, minio.GetObjectOptions{})
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", errRunLogObjectStore, err)
+	}
+	defer obj.Close()
+	blob, err := io.ReadAll(io.LimitReader(obj, httpapi.MaxBodyBytes))
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", errRunLogObjectStore, err)
+	}
+	return string(blob), nil
+}
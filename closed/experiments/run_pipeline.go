@@ -0,0 +1,640 @@
+package experiments
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/dataplane"
+	"github.com/animus-labs/animus-go/closed/internal/domain"
+	"github.com/animus-labs/animus-go/closed/internal/execution/dag"
+	"github.com/animus-labs/animus-go/closed/internal/execution/plan"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+	"github.com/animus-labs/animus-go/closed/internal/repo/postgres"
+	"github.com/animus-labs/animus-go/closed/internal/service/runs"
+	"github.com/google/uuid"
+)
+
+// pipelineStateNotDispatched is the pipeline state of a run that has not
+// been dispatched yet.
+const pipelineStateNotDispatched = "not_dispatched"
+
+// reasonInputArtifactMissing fails a step attempt whose upstream step did not
+// commit an artifact the step declares as input.
+const reasonInputArtifactMissing = "input_artifact_missing"
+
+var errStepInputMissing = errors.New("step input artifact missing")
+
+// pipelineStepInputRef is the artifact a step attempt received for one
+// input, kept on the attempt as lineage of what it consumed.
+type pipelineStepInputRef struct {
+	Name       string `json:"name"`
+	FromStep   string `json:"fromStep"`
+	Artifact   string `json:"artifact"`
+	ArtifactID string `json:"artifactId"`
+	SHA256     string `json:"sha256"`
+}
+
+type pipelineStepAttempt struct {
+	AttemptID    string                 `json:"attemptId"`
+	StepName     string                 `json:"-"`
+	Attempt      int                    `json:"attempt"`
+	Status       string                 `json:"status"`
+	NotBefore    time.Time              `json:"notBefore"`
+	Inputs       []pipelineStepInputRef `json:"inputs"`
+	JobName      string                 `json:"jobName,omitempty"`
+	DispatchedAt *time.Time             `json:"dispatchedAt,omitempty"`
+	HeartbeatAt  *time.Time             `json:"heartbeatAt,omitempty"`
+	FinishedAt   *time.Time             `json:"finishedAt,omitempty"`
+	Reason       string                 `json:"reason,omitempty"`
+	ExitCode     *int                   `json:"exitCode,omitempty"`
+}
+
+type pipelineStepStatus struct {
+	Name        string                `json:"name"`
+	DependsOn   []string              `json:"dependsOn"`
+	Status      string                `json:"status"`
+	Attempts    int                   `json:"attempts"`
+	MaxAttempts int                   `json:"maxAttempts"`
+	History     []pipelineStepAttempt `json:"history"`
+}
+
+type runPipelineResponse struct {
+	RunID      string               `json:"runId"`
+	ProjectID  string               `json:"projectId"`
+	RunState   string               `json:"runState"`
+	State      string               `json:"state"`
+	DispatchID string               `json:"dispatchId,omitempty"`
+	Steps      []pipelineStepStatus `json:"steps"`
+}
+
+// isPipelineRun reports whether the run's pipeline has several steps, which
+// the control plane orchestrates one step attempt at a time.
+func isPipelineRun(runRecord repo.RunRecord) bool {
+	spec, err := decodePipelineSpec(runRecord.PipelineSpec)
+	return err == nil && len(spec.Spec.Steps) > 1
+}
+
+func (api *experimentsAPI) handleGetRunPipeline(w http.ResponseWriter, r *http.Request) {
+	projectID, runID, ok := api.runStepPath(w, r)
+	if !ok {
+		return
+	}
+	runStore := postgres.NewRunSpecStore(api.db)
+	planStore := postgres.NewPlanStore(api.db)
+	dpStore := postgres.NewDPEventStore(api.db)
+	if runStore == nil || planStore == nil || dpStore == nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	runRecord, err := runStore.GetRun(r.Context(), projectID, runID)
+	if err != nil {
+		api.writeRepoError(w, r, err)
+		return
+	}
+	if !isPipelineRun(runRecord) {
+		api.writeError(w, r, http.StatusConflict, "pipeline_run_required")
+		return
+	}
+	execPlan, _, err := loadExecutionPlan(r.Context(), planStore, projectID, runID)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if execPlan == nil {
+		// Not planned yet: show the plan dispatch will persist.
+		spec, err := decodePipelineSpec(runRecord.PipelineSpec)
+		if err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "invalid_pipeline_spec")
+			return
+		}
+		built, err := plan.BuildPlan(spec, runID, projectID)
+		if err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "invalid_pipeline_spec")
+			return
+		}
+		execPlan = &built
+	}
+	attempts, err := listPipelineAttempts(r.Context(), api.db, runID)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	resp := runPipelineResponse{
+		RunID:     runID,
+		ProjectID: projectID,
+		RunState:  runRecord.Status,
+	}
+	dispatch, err := dpStore.GetDispatchByRunID(r.Context(), projectID, runID)
+	switch {
+	case err == nil:
+		resp.DispatchID = dispatch.DispatchID
+	case errors.Is(err, repo.ErrNotFound), errors.Is(err, sql.ErrNoRows):
+	default:
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	eval := dag.Evaluate(*execPlan, dagAttempts(attempts))
+	resp.State = string(eval.State)
+	runState := domain.NormalizeRunState(runRecord.Status)
+	switch {
+	case resp.DispatchID == "":
+		resp.State = pipelineStateNotDispatched
+	case eval.State == domain.RunStateRunning && domain.IsTerminalRunState(runState):
+		// Canceled or timed out while steps were still in flight.
+		resp.State = string(runState)
+	}
+	history := make(map[string][]pipelineStepAttempt, len(execPlan.Steps))
+	for _, attempt := range attempts {
+		history[attempt.StepName] = append(history[attempt.StepName], attempt)
+	}
+	resp.Steps = make([]pipelineStepStatus, 0, len(eval.Steps))
+	for _, step := range eval.Steps {
+		item := pipelineStepStatus{
+			Name:        step.Name,
+			DependsOn:   step.DependsOn,
+			Status:      step.Status,
+			Attempts:    step.Attempts,
+			MaxAttempts: step.MaxAttempts,
+			History:     history[step.Name],
+		}
+		if item.DependsOn == nil {
+			item.DependsOn = []string{}
+		}
+		if item.History == nil {
+			item.History = []pipelineStepAttempt{}
+		}
+		resp.Steps = append(resp.Steps, item)
+	}
+	api.writeJSON(w, http.StatusOK, resp)
+}
+
+func listPipelineAttempts(ctx context.Context, db postgres.DB, runID string) ([]pipelineStepAttempt, error) {
+	records, err := postgres.NewRunStepAttemptStore(db).List(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]pipelineStepAttempt, 0, len(records))
+	for _, record := range records {
+		attempt := pipelineStepAttempt{
+			AttemptID:    record.AttemptID,
+			StepName:     record.StepName,
+			Attempt:      record.Attempt,
+			Status:       record.Status,
+			NotBefore:    record.NotBefore,
+			JobName:      record.JobName,
+			DispatchedAt: record.DispatchedAt,
+			HeartbeatAt:  record.HeartbeatAt,
+			FinishedAt:   record.FinishedAt,
+			Reason:       record.Reason,
+			ExitCode:     record.ExitCode,
+		}
+		if err := json.Unmarshal(record.Inputs, &attempt.Inputs); err != nil {
+			return nil, err
+		}
+		if attempt.Inputs == nil {
+			attempt.Inputs = []pipelineStepInputRef{}
+		}
+		out = append(out, attempt)
+	}
+	return out, nil
+}
+
+func timePtrFromNull(value sql.NullTime) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+	t := value.Time.UTC()
+	return &t
+}
+
+func dagAttempts(attempts []pipelineStepAttempt) []dag.Attempt {
+	out := make([]dag.Attempt, 0, len(attempts))
+	for _, attempt := range attempts {
+		item := dag.Attempt{StepName: attempt.StepName, Attempt: attempt.Attempt, Status: attempt.Status}
+		if attempt.FinishedAt != nil {
+			item.FinishedAt = *attempt.FinishedAt
+		}
+		out = append(out, item)
+	}
+	return out
+}
+
+// pipelineFailureReason names the step that failed the pipeline for the
+// dispatch's last error.
+func pipelineFailureReason(attempts []pipelineStepAttempt, steps []dag.StepStatus) string {
+	for _, step := range steps {
+		if step.Status != dag.StepFailed {
+			continue
+		}
+		for i := len(attempts) - 1; i >= 0; i-- {
+			if attempts[i].StepName == step.Name {
+				return fmt.Sprintf("step %s failed: %s", step.Name, attempts[i].Reason)
+			}
+		}
+	}
+	return ""
+}
+
+// dueStepAttempt is a step attempt claimed for dispatch.
+type dueStepAttempt struct {
+	AttemptID string
+	StepName  string
+	Attempt   int
+}
+
+// advancePipeline moves a multi-step run forward: it records the attempts
+// dag.Evaluate asks for, dispatches those that are due and finishes the run
+// once no step is left to run. The dispatch row lock serializes concurrent
+// advances of one run, such as a terminal event racing the orchestrator,
+// so every attempt is created and dispatched once.
+func (api *experimentsAPI) advancePipeline(ctx context.Context, runRecord repo.RunRecord, dispatch postgres.RunDispatchRecord, origin dispatchOrigin) (string, error) {
+	now := time.Now().UTC()
+	tx, err := api.db.BeginTx(ctx, nil)
+	if err != nil {
+		return dispatch.Status, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	runStore := postgres.NewRunSpecStore(tx)
+	dpStore := postgres.NewDPEventStore(tx)
+	attemptStore := postgres.NewRunStepAttemptStore(tx)
+	if runStore == nil || dpStore == nil || attemptStore == nil {
+		return dispatch.Status, errors.New("stores unavailable")
+	}
+	status, err := dpStore.LockDispatch(ctx, dispatch.DispatchID)
+	if err != nil {
+		return dispatch.Status, err
+	}
+	if isTerminalDispatchStatus(status) {
+		return status, nil
+	}
+	current, err := runStore.GetRun(ctx, runRecord.ProjectID, runRecord.ID)
+	if err != nil {
+		return status, err
+	}
+	currentState := domain.NormalizeRunState(current.Status)
+	if domain.IsTerminalRunState(currentState) {
+		return status, nil
+	}
+	execPlan, err := api.pipelinePlan(ctx, tx, current, origin)
+	if err != nil {
+		return status, err
+	}
+	attempts, err := listPipelineAttempts(ctx, tx, current.ID)
+	if err != nil {
+		return status, err
+	}
+
+	eval := dag.Evaluate(execPlan, dagAttempts(attempts))
+	for _, start := range eval.Start {
+		notBefore := start.NotBefore
+		if notBefore.Before(now) {
+			notBefore = now
+		}
+		if err := attemptStore.CreatePending(ctx, uuid.NewString(), current.ProjectID, current.ID, dispatch.DispatchID, start.StepName, start.Attempt, dag.AttemptPending, notBefore, now); err != nil {
+			return status, err
+		}
+	}
+
+	if eval.State == domain.RunStateSucceeded || eval.State == domain.RunStateFailed {
+		auditInfo := runs.AuditInfo{
+			Actor:     origin.Actor,
+			RequestID: origin.RequestID,
+			UserAgent: origin.UserAgent,
+			IP:        origin.IP,
+			Service:   "experiments",
+		}
+		// A pipeline can fail before any step reported a heartbeat.
+		if currentState != domain.RunStateRunning {
+			if _, err := updateRunStateWithAudit(ctx, tx, runStore, current.SpecHash, auditInfo, current.ProjectID, current.ID, domain.RunStateRunning); err != nil && !errors.Is(err, repo.ErrInvalidTransition) {
+				return status, err
+			}
+		}
+		applied, err := updateRunStateWithAudit(ctx, tx, runStore, current.SpecHash, auditInfo, current.ProjectID, current.ID, eval.State)
+		if err != nil && !errors.Is(err, repo.ErrInvalidTransition) {
+			return status, err
+		}
+		status = dispatchStatusFromRunState(eval.State)
+		if err := updateDispatchStatus(ctx, dpStore, current.ProjectID, current.ID, status, pipelineFailureReason(attempts, eval.Steps)); err != nil {
+			return status, err
+		}
+		if err := tx.Commit(); err != nil {
+			return status, err
+		}
+		if applied {
+			if err := api.enqueueWebhookRunFinished(ctx, origin.Actor, origin.RequestID, current.ProjectID, current.ID, now); err != nil && api.logger != nil {
+				api.logger.Warn("webhook enqueue failed", "project_id", current.ProjectID, "run_id", current.ID, "error", err)
+			}
+		}
+		return status, nil
+	}
+
+	claimed, err := attemptStore.ClaimDue(ctx, current.ID, dag.AttemptDispatched, now)
+	if err != nil {
+		return status, err
+	}
+	due := make([]dueStepAttempt, 0, len(claimed))
+	for _, attempt := range claimed {
+		due = append(due, dueStepAttempt{AttemptID: attempt.AttemptID, StepName: attempt.StepName, Attempt: attempt.Attempt})
+	}
+	if err := tx.Commit(); err != nil {
+		return status, err
+	}
+	if len(due) == 0 {
+		return status, nil
+	}
+
+	spec, err := decodePipelineSpec(current.PipelineSpec)
+	if err != nil {
+		return status, err
+	}
+	accepted := false
+	var sendErr error
+	for _, attempt := range due {
+		ok, err := api.dispatchPipelineStep(ctx, current, dispatch, spec, attempt, origin)
+		accepted = accepted || ok
+		sendErr = errors.Join(sendErr, err)
+	}
+	if accepted && status != dataplane.DispatchStatusAccepted && status != dataplane.DispatchStatusRunning {
+		dpStore := postgres.NewDPEventStore(api.db)
+		if err := dpStore.UpdateDispatchStatus(ctx, dispatch.DispatchID, dataplane.DispatchStatusAccepted, "", time.Now().UTC()); err != nil {
+			return status, errors.Join(sendErr, err)
+		}
+		status = dataplane.DispatchStatusAccepted
+	}
+	return status, sendErr
+}
+
+// pipelinePlan loads the run's execution plan, persisting it first when the
+// run was dispatched without being planned.
+func (api *experimentsAPI) pipelinePlan(ctx context.Context, tx *sql.Tx, runRecord repo.RunRecord, origin dispatchOrigin) (domain.ExecutionPlan, error) {
+	planStore := postgres.NewPlanStore(tx)
+	if planStore == nil {
+		return domain.ExecutionPlan{}, errors.New("plan store unavailable")
+	}
+	existing, found, err := loadExecutionPlan(ctx, planStore, runRecord.ProjectID, runRecord.ID)
+	if err != nil {
+		return domain.ExecutionPlan{}, err
+	}
+	if found {
+		return *existing, nil
+	}
+	spec, err := decodePipelineSpec(runRecord.PipelineSpec)
+	if err != nil {
+		return domain.ExecutionPlan{}, err
+	}
+	execPlan, err := plan.BuildPlan(spec, runRecord.ID, runRecord.ProjectID)
+	if err != nil {
+		return domain.ExecutionPlan{}, err
+	}
+	planJSON, err := plan.MarshalExecutionPlan(execPlan)
+	if err != nil {
+		return domain.ExecutionPlan{}, err
+	}
+	planRecord, err := planStore.UpsertPlan(ctx, runRecord.ProjectID, runRecord.ID, planJSON)
+	if err != nil {
+		return domain.ExecutionPlan{}, err
+	}
+	err = auditlog.Enqueue(ctx, tx, auditlog.Event{
+		OccurredAt:   time.Now().UTC(),
+		Actor:        origin.Actor,
+		Action:       "execution.planned",
+		ResourceType: "execution_plan",
+		ResourceID:   planRecord.ID,
+		RequestID:    origin.RequestID,
+		IP:           origin.IP,
+		UserAgent:    origin.UserAgent,
+		Payload: map[string]any{
+			"service":    "experiments",
+			"project_id": runRecord.ProjectID,
+			"run_id":     runRecord.ID,
+			"spec_hash":  runRecord.SpecHash,
+			"trigger":    "dispatch",
+		},
+	})
+	if err != nil {
+		return domain.ExecutionPlan{}, err
+	}
+	return execPlan, nil
+}
+
+// dispatchPipelineStep sends one claimed attempt to the data plane and
+// records the outcome: a transport failure returns the attempt to pending
+// for the orchestrator to resend, a rejection or a missing input artifact
+// fails the attempt, which then counts against the step's retries.
+func (api *experimentsAPI) dispatchPipelineStep(ctx context.Context, runRecord repo.RunRecord, dispatch postgres.RunDispatchRecord, spec domain.PipelineSpec, attempt dueStepAttempt, origin dispatchOrigin) (bool, error) {
+	var step domain.PipelineStep
+	for _, candidate := range spec.Spec.Steps {
+		if candidate.Name == attempt.StepName {
+			step = candidate
+			break
+		}
+	}
+
+	outcome := stepDispatchOutcome{Status: dag.AttemptDispatched}
+	inputs, refs, err := api.pipelineStepInputs(ctx, runRecord.ID, step)
+	switch {
+	case errors.Is(err, errStepInputMissing):
+		outcome = stepDispatchOutcome{Status: dag.AttemptFailed, Reason: reasonInputArtifactMissing + ": " + err.Error()}
+		return false, api.recordStepDispatch(ctx, runRecord, dispatch, attempt, outcome, origin)
+	case err != nil:
+		outcome = stepDispatchOutcome{Status: dag.AttemptPending, Reason: err.Error()}
+		return false, errors.Join(err, api.recordStepDispatch(ctx, runRecord, dispatch, attempt, outcome, origin))
+	}
+	outcome.Inputs = refs
+
+	client, err := newDataplaneClient(api.dataplaneURL, api.runTokenSecret, api.internalTransport)
+	if err != nil {
+		outcome = stepDispatchOutcome{Status: dag.AttemptPending, Reason: err.Error()}
+		return false, errors.Join(err, api.recordStepDispatch(ctx, runRecord, dispatch, attempt, outcome, origin))
+	}
+	checkpoint, err := api.runCheckpointForExecution(ctx, api.db, runRecord.ID)
+	if err != nil {
+		outcome = stepDispatchOutcome{Status: dag.AttemptPending, Reason: err.Error()}
+		return false, errors.Join(err, api.recordStepDispatch(ctx, runRecord, dispatch, attempt, outcome, origin))
+	}
+	datasets, err := api.runDatasetsForExecution(ctx, api.db, runRecord.ID)
+	if err != nil {
+		outcome = stepDispatchOutcome{Status: dag.AttemptPending, Reason: err.Error()}
+		return false, errors.Join(err, api.recordStepDispatch(ctx, runRecord, dispatch, attempt, outcome, origin))
+	}
+	runToken, err := api.mintRunToken(runRecord.ID, dispatch.MaxDurationSeconds.Int64, time.Now().UTC())
+	if err != nil {
+		outcome = stepDispatchOutcome{Status: dag.AttemptPending, Reason: err.Error()}
+		return false, errors.Join(err, api.recordStepDispatch(ctx, runRecord, dispatch, attempt, outcome, origin))
+	}
+
+	resp, statusCode, err := client.ExecuteRun(ctx, dataplane.RunExecutionRequest{
+		RunID:              runRecord.ID,
+		ProjectID:          runRecord.ProjectID,
+		DispatchID:         dispatch.DispatchID,
+		EmittedAt:          time.Now().UTC(),
+		RequestedBy:        origin.RequestedBy,
+		CorrelationID:      origin.RequestID,
+		MaxDurationSeconds: dispatch.MaxDurationSeconds.Int64,
+		Checkpoint:         checkpoint,
+		Datasets:           datasets,
+		RunToken:           runToken,
+		Step: &dataplane.RunStep{
+			Name:    attempt.StepName,
+			Attempt: attempt.Attempt,
+			Inputs:  inputs,
+		},
+	}, origin.RequestID)
+	outcome.ResponseCode = statusCode
+	switch {
+	case err == nil && resp.Accepted:
+		outcome.JobName = resp.JobName
+	case err == nil:
+		outcome.Status, outcome.Reason = dag.AttemptFailed, "dispatch_rejected: "+resp.Message
+	case statusCode >= 400 && statusCode < 500:
+		outcome.Status, outcome.Reason = dag.AttemptFailed, "dispatch_rejected: "+err.Error()
+	default:
+		outcome.Status, outcome.Reason = dag.AttemptPending, err.Error()
+	}
+	if recordErr := api.recordStepDispatch(ctx, runRecord, dispatch, attempt, outcome, origin); recordErr != nil {
+		return false, recordErr
+	}
+	if outcome.Status == dag.AttemptPending {
+		return false, err
+	}
+	return outcome.Status == dag.AttemptDispatched, nil
+}
+
+type stepDispatchOutcome struct {
+	Status       string
+	JobName      string
+	Reason       string
+	ResponseCode int
+	Inputs       []pipelineStepInputRef
+}
+
+func (api *experimentsAPI) recordStepDispatch(ctx context.Context, runRecord repo.RunRecord, dispatch postgres.RunDispatchRecord, attempt dueStepAttempt, outcome stepDispatchOutcome, origin dispatchOrigin) error {
+	inputs := outcome.Inputs
+	if inputs == nil {
+		inputs = []pipelineStepInputRef{}
+	}
+	inputsJSON, err := json.Marshal(inputs)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+
+	tx, err := api.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := postgres.NewRunStepAttemptStore(tx).RecordDispatch(ctx, attempt.AttemptID, outcome.Status, outcome.JobName, outcome.Reason, inputsJSON, now); err != nil {
+		return err
+	}
+
+	artifactIDs := make([]string, 0, len(inputs))
+	for _, input := range inputs {
+		artifactIDs = append(artifactIDs, input.ArtifactID)
+	}
+	payload := map[string]any{
+		"service":       "experiments",
+		"project_id":    runRecord.ProjectID,
+		"run_id":        runRecord.ID,
+		"spec_hash":     runRecord.SpecHash,
+		"dispatch_id":   dispatch.DispatchID,
+		"step_name":     attempt.StepName,
+		"attempt":       attempt.Attempt,
+		"status":        outcome.Status,
+		"input_ids":     artifactIDs,
+		"response_code": outcome.ResponseCode,
+		"requested_by":  origin.RequestedBy,
+	}
+	if outcome.Reason != "" {
+		payload["reason"] = outcome.Reason
+	}
+	if err := auditlog.Enqueue(ctx, tx, auditlog.Event{
+		OccurredAt:   now,
+		Actor:        origin.Actor,
+		Action:       "run.step_dispatched",
+		ResourceType: "run",
+		ResourceID:   runRecord.ID,
+		RequestID:    origin.RequestID,
+		IP:           origin.IP,
+		UserAgent:    origin.UserAgent,
+		Payload:      payload,
+	}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// pipelineStepInputs resolves each artifact input of step to the newest
+// artifact of the run named Artifact that the upstream step committed, i.e.
+// uploaded with metadata.step set to its name (ANIMUS_STEP_NAME).
+func (api *experimentsAPI) pipelineStepInputs(ctx context.Context, runID string, step domain.PipelineStep) ([]dataplane.RunStepInput, []pipelineStepInputRef, error) {
+	if len(step.Inputs.Artifacts) == 0 {
+		return nil, nil, nil
+	}
+	if api.store == nil {
+		return nil, nil, errors.New("object store unavailable")
+	}
+	ttl := api.checkpointURLTTL
+	if ttl <= 0 {
+		ttl = defaultCheckpointURLTTL
+	}
+	inputs := make([]dataplane.RunStepInput, 0, len(step.Inputs.Artifacts))
+	refs := make([]pipelineStepInputRef, 0, len(step.Inputs.Artifacts))
+	for _, declared := range step.Inputs.Artifacts {
+		input := dataplane.RunStepInput{
+			Name:     declared.Name,
+			FromStep: declared.FromStep,
+			Artifact: declared.Artifact,
+		}
+		output, err := postgres.NewRunStepAttemptStore(api.db).Output(ctx, runID, declared.Artifact, declared.FromStep)
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, nil, fmt.Errorf("%w: %s from step %s", errStepInputMissing, declared.Artifact, declared.FromStep)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		input.ArtifactID, input.SHA256, input.SizeBytes = output.ArtifactID, output.SHA256, output.SizeBytes
+		url, err := api.store.PresignedGetObject(ctx, api.storeCfg.BucketArtifacts, output.ObjectKey, ttl, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		input.URL = url.String()
+		input.ExpiresAt = time.Now().UTC().Add(ttl)
+		inputs = append(inputs, input)
+		refs = append(refs, pipelineStepInputRef{
+			Name:       input.Name,
+			FromStep:   input.FromStep,
+			Artifact:   input.Artifact,
+			ArtifactID: input.ArtifactID,
+			SHA256:     input.SHA256,
+		})
+	}
+	return inputs, refs, nil
+}
+
+// markPipelineStepRunning records a heartbeat of a step attempt.
+func markPipelineStepRunning(ctx context.Context, db postgres.DB, runID, stepName string, attempt int, at time.Time) error {
+	return postgres.NewRunStepAttemptStore(db).MarkRunning(ctx, runID, stepName, attempt, at)
+}
+
+// finishPipelineStep records the terminal state of a step attempt; a
+// canceled attempt counts as failed. It reports whether the attempt was
+// still in flight.
+func finishPipelineStep(ctx context.Context, db postgres.DB, runID, stepName string, attempt int, state domain.RunState, reason string, exitCode *int, finishedAt time.Time) (bool, error) {
+	status := dag.AttemptFailed
+	if state == domain.RunStateSucceeded {
+		status = dag.AttemptSucceeded
+	}
+	return postgres.NewRunStepAttemptStore(db).Finish(ctx, runID, stepName, attempt, status, strings.TrimSpace(reason), exitCode, finishedAt)
+}
@@ -0,0 +1,162 @@
+package experiments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/domain"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+	"github.com/animus-labs/animus-go/closed/internal/repo/postgres"
+	"github.com/google/uuid"
+)
+
+const (
+	pipelineOrchestratorActor     = "system:pipeline-orchestrator"
+	pipelineOrchestratorUserAgent = "animus-pipeline-orchestrator"
+)
+
+// startPipelineOrchestrator advances multi-step runs that no event moves
+// forward: retries whose backoff elapsed, attempts the data plane could not
+// be reached for, and attempts whose heartbeats stopped for staleAfter.
+func startPipelineOrchestrator(ctx context.Context, logger *slog.Logger, api *experimentsAPI, interval, staleAfter time.Duration) {
+	if api == nil || api.db == nil || strings.TrimSpace(api.runTokenSecret) == "" || strings.TrimSpace(api.dataplaneURL) == "" {
+		return
+	}
+	if interval <= 0 {
+		interval = 15 * time.Second
+	}
+	if staleAfter <= 0 {
+		staleAfter = 2 * time.Minute
+	}
+
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := api.orchestratePipelines(ctx, time.Now().UTC(), staleAfter); err != nil && logger != nil {
+					logger.Warn("pipeline orchestration failed", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+func (api *experimentsAPI) orchestratePipelines(ctx context.Context, now time.Time, staleAfter time.Duration) error {
+	active, err := postgres.NewRunStepAttemptStore(api.db).ListActiveRuns(ctx, 100)
+	if err != nil {
+		return err
+	}
+
+	var passErr error
+	for _, run := range active {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := api.orchestratePipeline(ctx, run.ProjectID, run.RunID, now.Add(-staleAfter)); err != nil {
+			passErr = errors.Join(passErr, fmt.Errorf("pipeline run %s: %w", run.RunID, err))
+		}
+	}
+	return passErr
+}
+
+func (api *experimentsAPI) orchestratePipeline(ctx context.Context, projectID, runID string, staleBefore time.Time) error {
+	runRecord, err := postgres.NewRunSpecStore(api.db).GetRun(ctx, projectID, runID)
+	if err != nil {
+		return err
+	}
+	dispatch, err := postgres.NewDPEventStore(api.db).GetDispatchByRunID(ctx, projectID, runID)
+	if err != nil {
+		return err
+	}
+	if err := api.reconcileStalePipelineSteps(ctx, dispatch, staleBefore); err != nil {
+		return err
+	}
+	_, err = api.advancePipeline(ctx, runRecord, dispatch, dispatchOrigin{
+		Actor:       pipelineOrchestratorActor,
+		RequestedBy: dispatch.RequestedBy,
+		RequestID:   uuid.NewString(),
+		UserAgent:   pipelineOrchestratorUserAgent,
+	})
+	return err
+}
+
+// reconcileStalePipelineSteps asks the data plane about in-flight attempts
+// without a recent heartbeat: an attempt whose Job is gone fails with
+// dp_not_found, a finished Job whose terminal event was lost is recorded.
+func (api *experimentsAPI) reconcileStalePipelineSteps(ctx context.Context, dispatch postgres.RunDispatchRecord, staleBefore time.Time) error {
+	stale, err := postgres.NewRunStepAttemptStore(api.db).ListStale(ctx, dispatch.DispatchID, staleBefore)
+	if err != nil {
+		return err
+	}
+	if len(stale) == 0 {
+		return nil
+	}
+
+	client, err := newDataplaneClient(api.dataplaneURL, api.runTokenSecret, api.internalTransport)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	for _, attempt := range stale {
+		status, code, err := client.GetStepStatus(ctx, dispatch.ProjectID, dispatch.RunID, attempt.StepName, attempt.Attempt, "")
+		if err != nil {
+			if code != http.StatusNotFound {
+				return err
+			}
+			if _, err := finishPipelineStep(ctx, api.db, dispatch.RunID, attempt.StepName, attempt.Attempt, domain.RunStateFailed, "dp_not_found", nil, now); err != nil {
+				return err
+			}
+			continue
+		}
+		switch state := mapStatusToRunState(status.State); state {
+		case domain.RunStateSucceeded, domain.RunStateFailed:
+			finishedAt := now
+			if status.FinishedAt != nil {
+				finishedAt = status.FinishedAt.UTC()
+			}
+			if _, err := finishPipelineStep(ctx, api.db, dispatch.RunID, attempt.StepName, attempt.Attempt, state, status.Reason, nil, finishedAt); err != nil {
+				return err
+			}
+		case domain.RunStateRunning:
+			if err := markPipelineStepRunning(ctx, api.db, dispatch.RunID, attempt.StepName, attempt.Attempt, now); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// advancePipelineAfterStep advances the run right after a step attempt
+// ended. Failures are only logged: the orchestrator retries on its next pass.
+func (api *experimentsAPI) advancePipelineAfterStep(r *http.Request, actor string, runRecord repo.RunRecord) {
+	dispatch, err := postgres.NewDPEventStore(api.db).GetDispatchByRunID(r.Context(), runRecord.ProjectID, runRecord.ID)
+	if err == nil {
+		_, err = api.advancePipeline(r.Context(), runRecord, dispatch, dispatchOrigin{
+			Actor:       actor,
+			RequestedBy: dispatch.RequestedBy,
+			RequestID:   r.Header.Get("X-Request-Id"),
+			IP:          httpapi.RequestIP(r.RemoteAddr),
+			UserAgent:   r.UserAgent(),
+		})
+	}
+	if err != nil && api.logger != nil {
+		api.logger.Warn("pipeline advance failed", "project_id", runRecord.ProjectID, "run_id", runRecord.ID, "error", err)
+	}
+}
+
+// isPipelineDispatch reports whether the dispatch runs a multi-step run,
+// whose attempts the pipeline orchestrator reconciles instead of the DP
+// reconciler.
+func isPipelineDispatch(ctx context.Context, db postgres.DB, dispatchID string) (bool, error) {
+	return postgres.NewRunStepAttemptStore(db).DispatchHasAttempts(ctx, dispatchID)
+}
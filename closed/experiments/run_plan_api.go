@@ -1,4 +1,4 @@
-package main
+package experiments
 
 import (
 	"errors"
@@ -9,6 +9,7 @@ import (
 	"github.com/animus-labs/animus-go/closed/internal/execution/plan"
 	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
 	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
 	"github.com/animus-labs/animus-go/closed/internal/repo"
 	"github.com/animus-labs/animus-go/closed/internal/repo/postgres"
 	"github.com/animus-labs/animus-go/closed/internal/service/runs"
@@ -98,14 +99,14 @@ func (api *experimentsAPI) handlePlanRun(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	_, err = auditlog.Insert(r.Context(), tx, auditlog.Event{
+	err = auditlog.Enqueue(r.Context(), tx, auditlog.Event{
 		OccurredAt:   time.Now().UTC(),
 		Actor:        identity.Subject,
 		Action:       "execution.planned",
 		ResourceType: "execution_plan",
 		ResourceID:   planRecord.ID,
 		RequestID:    r.Header.Get("X-Request-Id"),
-		IP:           requestIP(r.RemoteAddr),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
 		UserAgent:    r.UserAgent(),
 		Payload: map[string]any{
 			"service":    "experiments",
@@ -123,7 +124,7 @@ func (api *experimentsAPI) handlePlanRun(w http.ResponseWriter, r *http.Request)
 		Actor:     identity.Subject,
 		RequestID: r.Header.Get("X-Request-Id"),
 		UserAgent: r.UserAgent(),
-		IP:        requestIP(r.RemoteAddr),
+		IP:        httpapi.RequestIP(r.RemoteAddr),
 		Service:   "experiments",
 	}
 	auditAppender := runs.NewAuditAppender(tx)
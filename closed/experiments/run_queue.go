@@ -0,0 +1,586 @@
+package experiments
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/dataplane"
+	"github.com/animus-labs/animus-go/closed/internal/domain"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/env"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+	"github.com/animus-labs/animus-go/closed/internal/repo/postgres"
+)
+
+const (
+	runQueueActor = "system:run-queue"
+
+	runPriorityHigh   = "high"
+	runPriorityNormal = "normal"
+	runPriorityLow    = "low"
+
+	runQueueStatusQueued    = "queued"
+	runQueueStatusSubmitted = "submitted"
+	runQueueStatusCanceled  = "canceled"
+
+	// runQueueScanLimit bounds one drain pass; runs behind a capped project
+	// further down wait for the next pass.
+	runQueueScanLimit = 500
+)
+
+// activeDispatchStatuses are the dispatch states that hold a concurrency slot.
+var activeDispatchStatuses = []string{
+	dataplane.DispatchStatusRequested,
+	dataplane.DispatchStatusAccepted,
+	dataplane.DispatchStatusRunning,
+}
+
+type runQueueConfig struct {
+	// MaxConcurrent caps in-flight runs across all projects; 0 is unlimited.
+	MaxConcurrent int
+	// MaxConcurrentPerProject caps in-flight runs of one project; 0 is unlimited.
+	MaxConcurrentPerProject int
+	PollInterval            time.Duration
+}
+
+func runQueueConfigFromEnv() (runQueueConfig, error) {
+	maxConcurrent, err := env.Int("ANIMUS_RUN_QUEUE_MAX_CONCURRENT", 0)
+	if err != nil {
+		return runQueueConfig{}, err
+	}
+	maxPerProject, err := env.Int("ANIMUS_RUN_QUEUE_MAX_CONCURRENT_PER_PROJECT", 0)
+	if err != nil {
+		return runQueueConfig{}, err
+	}
+	pollInterval, err := env.Duration("ANIMUS_RUN_QUEUE_POLL_INTERVAL", 5*time.Second)
+	if err != nil {
+		return runQueueConfig{}, err
+	}
+	cfg := runQueueConfig{
+		MaxConcurrent:           maxConcurrent,
+		MaxConcurrentPerProject: maxPerProject,
+		PollInterval:            pollInterval,
+	}
+	if err := cfg.Validate(); err != nil {
+		return runQueueConfig{}, err
+	}
+	return cfg, nil
+}
+
+func (c runQueueConfig) Validate() error {
+	if c.MaxConcurrent < 0 {
+		return fmt.Errorf("run queue max concurrent must be >= 0")
+	}
+	if c.MaxConcurrentPerProject < 0 {
+		return fmt.Errorf("run queue max concurrent per project must be >= 0")
+	}
+	if c.PollInterval <= 0 {
+		return fmt.Errorf("run queue poll interval must be positive")
+	}
+	return nil
+}
+
+// Enabled reports whether any limit is set. Without limits runs are
+// dispatched immediately, as before the queue existed.
+func (c runQueueConfig) Enabled() bool {
+	return c.MaxConcurrent > 0 || c.MaxConcurrentPerProject > 0
+}
+
+// normalizeRunPriority maps a priority class to its rank; empty is normal.
+func normalizeRunPriority(value string) (string, bool) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", runPriorityNormal:
+		return runPriorityNormal, true
+	case runPriorityHigh:
+		return runPriorityHigh, true
+	case runPriorityLow:
+		return runPriorityLow, true
+	default:
+		return "", false
+	}
+}
+
+func runPriorityRank(priority string) int {
+	switch priority {
+	case runPriorityHigh:
+		return 2
+	case runPriorityLow:
+		return 0
+	default:
+		return 1
+	}
+}
+
+type queuedRun struct {
+	RunID          string
+	ProjectID      string
+	Priority       string
+	IdempotencyKey string
+	EnqueuedAt     time.Time
+	EnqueuedBy     string
+	RequestID      string
+	// MaxDurationSeconds is carried into the dispatch; 0 is unbounded.
+	MaxDurationSeconds int64
+}
+
+// pickQueuedRuns selects the runs to submit now. queue is in priority order
+// (rank, then age); a project at its limit is skipped without blocking the
+// projects behind it.
+func pickQueuedRuns(queue []queuedRun, cfg runQueueConfig, globalActive int, projectActive map[string]int) []queuedRun {
+	active := make(map[string]int, len(projectActive))
+	for project, count := range projectActive {
+		active[project] = count
+	}
+	var picked []queuedRun
+	for _, entry := range queue {
+		if cfg.MaxConcurrent > 0 && globalActive >= cfg.MaxConcurrent {
+			break
+		}
+		if cfg.MaxConcurrentPerProject > 0 && active[entry.ProjectID] >= cfg.MaxConcurrentPerProject {
+			continue
+		}
+		picked = append(picked, entry)
+		active[entry.ProjectID]++
+		globalActive++
+	}
+	return picked
+}
+
+// enqueueRun queues a run that has no dispatch yet and answers 202. Repeated
+// requests return the existing entry and keep its original priority.
+func (api *experimentsAPI) enqueueRun(w http.ResponseWriter, r *http.Request, runRecord repo.RunRecord, idempotencyKey, priority string, maxDurationSeconds int64, identity auth.Identity) {
+	now := time.Now().UTC()
+	requestID := r.Header.Get("X-Request-Id")
+	integrity, err := integritySHA256(struct {
+		RunID          string    `json:"run_id"`
+		ProjectID      string    `json:"project_id"`
+		Priority       string    `json:"priority"`
+		IdempotencyKey string    `json:"idempotency_key"`
+		EnqueuedAt     time.Time `json:"enqueued_at"`
+		EnqueuedBy     string    `json:"enqueued_by"`
+		MaxDuration    int64     `json:"max_duration_seconds,omitempty"`
+	}{
+		RunID:          runRecord.ID,
+		ProjectID:      runRecord.ProjectID,
+		Priority:       priority,
+		IdempotencyKey: idempotencyKey,
+		EnqueuedAt:     now,
+		EnqueuedBy:     identity.Subject,
+		MaxDuration:    maxDurationSeconds,
+	})
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.ExecContext(
+		r.Context(),
+		`INSERT INTO run_queue (
+			run_id,
+			project_id,
+			priority,
+			priority_rank,
+			idempotency_key,
+			status,
+			enqueued_at,
+			enqueued_by,
+			request_id,
+			integrity_sha256,
+			max_duration_seconds
+		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)
+		ON CONFLICT (run_id) DO NOTHING`,
+		runRecord.ID,
+		runRecord.ProjectID,
+		priority,
+		runPriorityRank(priority),
+		idempotencyKey,
+		runQueueStatusQueued,
+		now,
+		identity.Subject,
+		nullString(requestID),
+		integrity,
+		sql.NullInt64{Int64: maxDurationSeconds, Valid: maxDurationSeconds > 0},
+	)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	created, _ := res.RowsAffected()
+
+	var (
+		status      string
+		rank        int
+		enqueuedAt  time.Time
+		maxDuration sql.NullInt64
+	)
+	err = tx.QueryRowContext(
+		r.Context(),
+		`SELECT status, priority, priority_rank, enqueued_at, max_duration_seconds FROM run_queue WHERE run_id = $1`,
+		runRecord.ID,
+	).Scan(&status, &priority, &rank, &enqueuedAt, &maxDuration)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	position := 0
+	if status == runQueueStatusQueued {
+		err = tx.QueryRowContext(
+			r.Context(),
+			`SELECT COUNT(*) + 1
+			 FROM run_queue
+			 WHERE status = $1 AND (priority_rank > $2 OR (priority_rank = $2 AND enqueued_at < $3))`,
+			runQueueStatusQueued,
+			rank,
+			enqueuedAt,
+		).Scan(&position)
+		if err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+	}
+
+	if created > 0 {
+		err = auditlog.Enqueue(r.Context(), tx, auditlog.Event{
+			OccurredAt:   now,
+			Actor:        identity.Subject,
+			Action:       "run.queued",
+			ResourceType: "run",
+			ResourceID:   runRecord.ID,
+			RequestID:    requestID,
+			IP:           httpapi.RequestIP(r.RemoteAddr),
+			UserAgent:    r.UserAgent(),
+			Payload: map[string]any{
+				"service":              "experiments",
+				"project_id":           runRecord.ProjectID,
+				"run_id":               runRecord.ID,
+				"spec_hash":            runRecord.SpecHash,
+				"priority":             priority,
+				"queue_position":       position,
+				"max_duration_seconds": maxDuration.Int64,
+			},
+		})
+		if err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "audit_failed")
+			return
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	api.writeJSON(w, http.StatusAccepted, runDispatchResponse{
+		RunID:              runRecord.ID,
+		ProjectID:          runRecord.ProjectID,
+		Status:             status,
+		Created:            created > 0,
+		Priority:           priority,
+		QueuePosition:      position,
+		MaxDurationSeconds: maxDuration.Int64,
+	})
+}
+
+func startRunQueue(ctx context.Context, logger *slog.Logger, api *experimentsAPI) {
+	if api == nil || api.db == nil || !api.runQueue.Enabled() {
+		return
+	}
+	interval := api.runQueue.PollInterval
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := api.drainRunQueue(ctx, time.Now().UTC()); err != nil && logger != nil {
+					logger.Warn("run queue drain failed", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+type runQueueSubmission struct {
+	entry    queuedRun
+	run      repo.RunRecord
+	dispatch postgres.RunDispatchRecord
+}
+
+// drainRunQueue moves queued runs into free capacity. Replicas serialize on
+// an advisory lock so slots are counted once; the dispatch rows are created
+// in the same transaction and hold their slots while the data plane is
+// called after commit.
+func (api *experimentsAPI) drainRunQueue(ctx context.Context, now time.Time) error {
+	tx, err := api.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, "run_queue"); err != nil {
+		return err
+	}
+	globalActive, projectActive, err := countActiveDispatches(ctx, tx)
+	if err != nil {
+		return err
+	}
+	queue, err := listQueuedRuns(ctx, tx, runQueueScanLimit)
+	if err != nil {
+		return err
+	}
+	picked := pickQueuedRuns(queue, api.runQueue, globalActive, projectActive)
+	if len(picked) == 0 {
+		return nil
+	}
+
+	runStore := postgres.NewRunSpecStore(tx)
+	dpStore := postgres.NewDPEventStore(tx)
+	if runStore == nil || dpStore == nil {
+		return errors.New("stores unavailable")
+	}
+	submissions := make([]runQueueSubmission, 0, len(picked))
+	for _, entry := range picked {
+		runRecord, err := runStore.GetRun(ctx, entry.ProjectID, entry.RunID)
+		if err != nil && !errors.Is(err, repo.ErrNotFound) {
+			return err
+		}
+		if err != nil || domain.IsTerminalRunState(domain.NormalizeRunState(runRecord.Status)) {
+			if err := finishQueuedRun(ctx, tx, entry, runQueueStatusCanceled, "", now); err != nil {
+				return err
+			}
+			continue
+		}
+		target, err := api.dispatchTarget(ctx, tx, runRecord.ID)
+		if err != nil {
+			return err
+		}
+		dispatch, err := api.newRunDispatch(runRecord, target, entry.IdempotencyKey, entry.EnqueuedBy, entry.MaxDurationSeconds, now)
+		if err != nil {
+			return err
+		}
+		record, created, err := dpStore.CreateDispatch(ctx, dispatch)
+		if err != nil {
+			return err
+		}
+		if err := finishQueuedRun(ctx, tx, entry, runQueueStatusSubmitted, record.DispatchID, now); err != nil {
+			return err
+		}
+		if created {
+			submissions = append(submissions, runQueueSubmission{entry: entry, run: runRecord, dispatch: record})
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	var lastErr error
+	for _, submission := range submissions {
+		origin := dispatchOrigin{
+			Actor:       runQueueActor,
+			RequestedBy: submission.entry.EnqueuedBy,
+			RequestID:   submission.entry.RequestID,
+		}
+		if _, err := api.dispatchToDataplane(ctx, submission.run, submission.dispatch, origin); err != nil {
+			lastErr = errors.Join(lastErr, fmt.Errorf("run %s: %w", submission.run.ID, err))
+		}
+	}
+	return lastErr
+}
+
+func countActiveDispatches(ctx context.Context, tx *sql.Tx) (int, map[string]int, error) {
+	rows, err := tx.QueryContext(
+		ctx,
+		`SELECT project_id, COUNT(*)
+		 FROM run_dispatches
+		 WHERE status IN ($1, $2, $3)
+		 GROUP BY project_id`,
+		activeDispatchStatuses[0],
+		activeDispatchStatuses[1],
+		activeDispatchStatuses[2],
+	)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer rows.Close()
+	total := 0
+	byProject := map[string]int{}
+	for rows.Next() {
+		var (
+			projectID string
+			count     int
+		)
+		if err := rows.Scan(&projectID, &count); err != nil {
+			return 0, nil, err
+		}
+		byProject[projectID] = count
+		total += count
+	}
+	return total, byProject, rows.Err()
+}
+
+func listQueuedRuns(ctx context.Context, tx *sql.Tx, limit int) ([]queuedRun, error) {
+	rows, err := tx.QueryContext(
+		ctx,
+		`SELECT run_id, project_id, priority, idempotency_key, enqueued_at, enqueued_by, request_id, max_duration_seconds
+		 FROM run_queue
+		 WHERE status = $1
+		 ORDER BY priority_rank DESC, enqueued_at
+		 LIMIT $2`,
+		runQueueStatusQueued,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []queuedRun{}
+	for rows.Next() {
+		var (
+			entry       queuedRun
+			requestID   sql.NullString
+			maxDuration sql.NullInt64
+		)
+		if err := rows.Scan(&entry.RunID, &entry.ProjectID, &entry.Priority, &entry.IdempotencyKey, &entry.EnqueuedAt, &entry.EnqueuedBy, &requestID, &maxDuration); err != nil {
+			return nil, err
+		}
+		entry.RequestID = strings.TrimSpace(requestID.String)
+		entry.MaxDurationSeconds = maxDuration.Int64
+		out = append(out, entry)
+	}
+	return out, rows.Err()
+}
+
+func finishQueuedRun(ctx context.Context, tx *sql.Tx, entry queuedRun, status, dispatchID string, now time.Time) error {
+	if _, err := tx.ExecContext(
+		ctx,
+		`UPDATE run_queue SET status = $1, dequeued_at = $2, dispatch_id = $3 WHERE run_id = $4`,
+		status,
+		now,
+		nullString(dispatchID),
+		entry.RunID,
+	); err != nil {
+		return err
+	}
+	err := auditlog.Enqueue(ctx, tx, auditlog.Event{
+		OccurredAt:   now,
+		Actor:        runQueueActor,
+		Action:       "run.dequeued",
+		ResourceType: "run",
+		ResourceID:   entry.RunID,
+		RequestID:    entry.RequestID,
+		Payload: map[string]any{
+			"service":     "experiments",
+			"project_id":  entry.ProjectID,
+			"run_id":      entry.RunID,
+			"priority":    entry.Priority,
+			"status":      status,
+			"dispatch_id": dispatchID,
+			"waited_ms":   now.Sub(entry.EnqueuedAt).Milliseconds(),
+		},
+	})
+	return err
+}
+
+type runQueueEntry struct {
+	RunID      string    `json:"runId"`
+	Priority   string    `json:"priority"`
+	Position   int       `json:"position"`
+	EnqueuedAt time.Time `json:"enqueuedAt"`
+	EnqueuedBy string    `json:"enqueuedBy"`
+}
+
+type runQueueResponse struct {
+	ProjectID               string          `json:"projectId"`
+	MaxConcurrent           int             `json:"maxConcurrent"`
+	MaxConcurrentPerProject int             `json:"maxConcurrentPerProject"`
+	ActiveGlobal            int             `json:"activeGlobal"`
+	ActiveProject           int             `json:"activeProject"`
+	Queued                  []runQueueEntry `json:"queued"`
+}
+
+// handleListRunQueue shows a project's queued runs with their position in the
+// global queue.
+func (api *experimentsAPI) handleListRunQueue(w http.ResponseWriter, r *http.Request) {
+	projectID := strings.TrimSpace(r.PathValue("project_id"))
+	if projectID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "project_id_required")
+		return
+	}
+	limit := httpapi.Limit(r, 100, 500)
+
+	tx, err := api.db.BeginTx(r.Context(), &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	globalActive, projectActive, err := countActiveDispatches(r.Context(), tx)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	rows, err := tx.QueryContext(
+		r.Context(),
+		`SELECT run_id, priority, position, enqueued_at, enqueued_by
+		 FROM (
+			SELECT run_id, project_id, priority, enqueued_at, enqueued_by,
+				ROW_NUMBER() OVER (ORDER BY priority_rank DESC, enqueued_at) AS position
+			FROM run_queue
+			WHERE status = $1
+		 ) ranked
+		 WHERE project_id = $2
+		 ORDER BY position
+		 LIMIT $3`,
+		runQueueStatusQueued,
+		projectID,
+		limit,
+	)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer rows.Close()
+
+	queued := make([]runQueueEntry, 0, limit)
+	for rows.Next() {
+		var entry runQueueEntry
+		if err := rows.Scan(&entry.RunID, &entry.Priority, &entry.Position, &entry.EnqueuedAt, &entry.EnqueuedBy); err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+		queued = append(queued, entry)
+	}
+	if err := rows.Err(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	api.writeJSON(w, http.StatusOK, runQueueResponse{
+		ProjectID:               projectID,
+		MaxConcurrent:           api.runQueue.MaxConcurrent,
+		MaxConcurrentPerProject: api.runQueue.MaxConcurrentPerProject,
+		ActiveGlobal:            globalActive,
+		ActiveProject:           projectActive[projectID],
+		Queued:                  queued,
+	})
+}
@@ -0,0 +1,89 @@
+package experiments
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNormalizeRunPriority(t *testing.T) {
+	cases := map[string]string{
+		"":        runPriorityNormal,
+		"normal":  runPriorityNormal,
+		" HIGH ":  runPriorityHigh,
+		"low":     runPriorityLow,
+		"urgent":  "",
+		"highest": "",
+	}
+	for in, want := range cases {
+		got, ok := normalizeRunPriority(in)
+		if ok != (want != "") || got != want {
+			t.Fatalf("normalizeRunPriority(%q) = %q, %v; want %q", in, got, ok, want)
+		}
+	}
+	if runPriorityRank(runPriorityHigh) <= runPriorityRank(runPriorityNormal) || runPriorityRank(runPriorityNormal) <= runPriorityRank(runPriorityLow) {
+		t.Fatalf("priority ranks out of order")
+	}
+}
+
+func TestRunQueueConfig(t *testing.T) {
+	if (runQueueConfig{PollInterval: time.Second}).Enabled() {
+		t.Fatalf("queue without limits must be disabled")
+	}
+	if !(runQueueConfig{MaxConcurrentPerProject: 1, PollInterval: time.Second}).Enabled() {
+		t.Fatalf("per-project limit must enable the queue")
+	}
+	if err := (runQueueConfig{MaxConcurrent: -1, PollInterval: time.Second}).Validate(); err == nil {
+		t.Fatalf("expected negative limit to be rejected")
+	}
+	if err := (runQueueConfig{MaxConcurrent: 1}).Validate(); err == nil {
+		t.Fatalf("expected zero poll interval to be rejected")
+	}
+
+	t.Setenv("ANIMUS_RUN_QUEUE_MAX_CONCURRENT", "8")
+	t.Setenv("ANIMUS_RUN_QUEUE_MAX_CONCURRENT_PER_PROJECT", "2")
+	cfg, err := runQueueConfigFromEnv()
+	if err != nil {
+		t.Fatalf("runQueueConfigFromEnv: %v", err)
+	}
+	if cfg.MaxConcurrent != 8 || cfg.MaxConcurrentPerProject != 2 || cfg.PollInterval != 5*time.Second {
+		t.Fatalf("unexpected config %+v", cfg)
+	}
+}
+
+func TestPickQueuedRuns(t *testing.T) {
+	queue := []queuedRun{
+		{RunID: "a1", ProjectID: "a"},
+		{RunID: "a2", ProjectID: "a"},
+		{RunID: "b1", ProjectID: "b"},
+		{RunID: "c1", ProjectID: "c"},
+		{RunID: "b2", ProjectID: "b"},
+	}
+	ids := func(runs []queuedRun) string {
+		out := make([]string, 0, len(runs))
+		for _, run := range runs {
+			out = append(out, run.RunID)
+		}
+		return strings.Join(out, ",")
+	}
+
+	cfg := runQueueConfig{MaxConcurrent: 3, MaxConcurrentPerProject: 1}
+	if got := ids(pickQueuedRuns(queue, cfg, 0, nil)); got != "a1,b1,c1" {
+		t.Fatalf("capped project must not block others, got %s", got)
+	}
+
+	projectActive := map[string]int{"a": 1}
+	if got := ids(pickQueuedRuns(queue, cfg, 1, projectActive)); got != "b1,c1" {
+		t.Fatalf("active runs must count against limits, got %s", got)
+	}
+	if projectActive["a"] != 1 || len(projectActive) != 1 {
+		t.Fatalf("pickQueuedRuns must not mutate the active counts")
+	}
+
+	if got := ids(pickQueuedRuns(queue, runQueueConfig{MaxConcurrent: 2}, 0, nil)); got != "a1,a2" {
+		t.Fatalf("global limit must follow queue order, got %s", got)
+	}
+	if got := ids(pickQueuedRuns(queue, cfg, 3, nil)); got != "" {
+		t.Fatalf("expected nothing when global capacity is used, got %s", got)
+	}
+}
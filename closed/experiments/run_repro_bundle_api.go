@@ -1,4 +1,4 @@
-package main
+package experiments
 
 import (
 	"encoding/json"
@@ -9,6 +9,7 @@ import (
 
 	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
 	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
 	"github.com/animus-labs/animus-go/closed/internal/repo"
 	"github.com/animus-labs/animus-go/closed/internal/repo/postgres"
 )
@@ -72,7 +73,7 @@ func (api *experimentsAPI) handleGetRunReproducibilityBundle(w http.ResponseWrit
 	}
 
 	now := time.Now().UTC()
-	if _, err := auditlog.Insert(
+	if err := auditlog.Enqueue(
 		r.Context(),
 		api.db,
 		reproBundleAuditEvent(now, identity, r, projectID, runID, record.SpecHash, policySHA),
@@ -101,7 +102,7 @@ func reproBundleAuditEvent(now time.Time, identity auth.Identity, r *http.Reques
 		ResourceType: "run",
 		ResourceID:   runID,
 		RequestID:    r.Header.Get("X-Request-Id"),
-		IP:           requestIP(r.RemoteAddr),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
 		UserAgent:    r.UserAgent(),
 		Payload: map[string]any{
 			"service":                "experiments",
@@ -0,0 +1,575 @@
+package experiments
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/cron"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	"github.com/animus-labs/animus-go/closed/internal/platform/validation"
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+	"github.com/animus-labs/animus-go/closed/internal/repo/postgres"
+	"github.com/google/uuid"
+)
+
+const (
+	runScheduleStateActive = "active"
+	runScheduleStatePaused = "paused"
+
+	runScheduleSelectorLatest        = "latest"
+	runScheduleSelectorLatestPassing = "latest_passing"
+	runScheduleSelectorPinned        = "pinned"
+
+	runScheduleFiringCreated = "created"
+	runScheduleFiringBlocked = "blocked"
+	runScheduleFiringFailed  = "failed"
+
+	maxRunScheduleNameLen = 200
+)
+
+// runScheduleTemplate describes the run a schedule creates. The dataset is
+// resolved at every firing: latest takes the newest version of dataset_id,
+// latest_passing the newest one whose quality gate currently passes, and
+// pinned always uses dataset_version_id. A template without a dataset
+// creates runs without one.
+type runScheduleTemplate struct {
+	DatasetID        string         `json:"dataset_id,omitempty"`
+	DatasetSelector  string         `json:"dataset_selector,omitempty"`
+	DatasetVersionID string         `json:"dataset_version_id,omitempty"`
+	ImageRef         string         `json:"image_ref,omitempty"`
+	GitRepo          string         `json:"git_repo,omitempty"`
+	GitCommit        string         `json:"git_commit,omitempty"`
+	GitRef           string         `json:"git_ref,omitempty"`
+	Params           map[string]any `json:"params,omitempty"`
+	Resources        map[string]any `json:"resources,omitempty"`
+}
+
+type createRunScheduleRequest struct {
+	Name     string              `json:"name"`
+	Cron     string              `json:"cron"`
+	Template runScheduleTemplate `json:"template"`
+}
+
+type runSchedule struct {
+	ScheduleID   string              `json:"schedule_id"`
+	ProjectID    string              `json:"project_id"`
+	ExperimentID string              `json:"experiment_id"`
+	Name         string              `json:"name"`
+	Cron         string              `json:"cron"`
+	Template     runScheduleTemplate `json:"template"`
+	State        string              `json:"state"`
+	NextRunAt    *time.Time          `json:"next_run_at,omitempty"`
+	LastRunAt    *time.Time          `json:"last_run_at,omitempty"`
+	LastRunID    string              `json:"last_run_id,omitempty"`
+	LastStatus   string              `json:"last_status,omitempty"`
+	LastError    string              `json:"last_error,omitempty"`
+	CreatedAt    time.Time           `json:"created_at"`
+	CreatedBy    string              `json:"created_by"`
+	UpdatedAt    time.Time           `json:"updated_at"`
+	UpdatedBy    string              `json:"updated_by"`
+}
+
+type runScheduleFiring struct {
+	FiringID         string    `json:"firing_id"`
+	ScheduleID       string    `json:"schedule_id"`
+	ScheduledFor     time.Time `json:"scheduled_for"`
+	FiredAt          time.Time `json:"fired_at"`
+	Status           string    `json:"status"`
+	RunID            string    `json:"run_id,omitempty"`
+	DatasetVersionID string    `json:"dataset_version_id,omitempty"`
+	ErrorCode        string    `json:"error_code,omitempty"`
+}
+
+// validateRunScheduleRequest normalizes req in place and returns the parsed
+// cron expression.
+func validateRunScheduleRequest(req *createRunScheduleRequest, now time.Time) (cron.Schedule, *validation.Errors) {
+	errs := &validation.Errors{}
+	req.Name = strings.TrimSpace(req.Name)
+	if errs.Required("/name", req.Name) && len(req.Name) > maxRunScheduleNameLen {
+		errs.Add("/name", validation.CodeOutOfRange, "must be at most "+strconv.Itoa(maxRunScheduleNameLen)+" characters")
+	}
+
+	req.Cron = strings.Join(strings.Fields(req.Cron), " ")
+	var schedule cron.Schedule
+	if errs.Required("/cron", req.Cron) {
+		parsed, err := cron.Parse(req.Cron)
+		switch {
+		case err != nil:
+			errs.Add("/cron", validation.CodeInvalidCron, err.Error())
+		case parsed.Next(now).IsZero():
+			errs.Add("/cron", validation.CodeInvalidCron, "never fires")
+		default:
+			schedule = parsed
+		}
+	}
+
+	validateRunScheduleTemplate(errs, &req.Template)
+	return schedule, errs
+}
+
+// validateRunScheduleTemplate normalizes the run template t in place,
+// recording violations under /template. An unset selector defaults to pinned
+// when a version is given and to latest_passing when only a dataset is.
+func validateRunScheduleTemplate(errs *validation.Errors, t *runScheduleTemplate) {
+	t.DatasetID = strings.TrimSpace(t.DatasetID)
+	t.DatasetVersionID = strings.TrimSpace(t.DatasetVersionID)
+	t.DatasetSelector = strings.ToLower(strings.TrimSpace(t.DatasetSelector))
+	if t.DatasetSelector == "" {
+		switch {
+		case t.DatasetVersionID != "":
+			t.DatasetSelector = runScheduleSelectorPinned
+		case t.DatasetID != "":
+			t.DatasetSelector = runScheduleSelectorLatestPassing
+		}
+	}
+	switch t.DatasetSelector {
+	case "":
+	case runScheduleSelectorPinned:
+		if errs.Required("/template/dataset_version_id", t.DatasetVersionID) {
+			errs.UUID("/template/dataset_version_id", t.DatasetVersionID)
+		}
+		t.DatasetID = ""
+	case runScheduleSelectorLatest, runScheduleSelectorLatestPassing:
+		if errs.Required("/template/dataset_id", t.DatasetID) {
+			errs.UUID("/template/dataset_id", t.DatasetID)
+		}
+		if t.DatasetVersionID != "" {
+			errs.Add("/template/dataset_version_id", validation.CodeOutOfRange, "is only allowed with the pinned selector")
+		}
+	default:
+		errs.Add("/template/dataset_selector", validation.CodeInvalidEnum, "must be latest, latest_passing or pinned")
+	}
+
+	t.ImageRef = strings.TrimSpace(t.ImageRef)
+	if t.ImageRef != "" {
+		errs.ImageRef("/template/image_ref", t.ImageRef)
+	}
+	t.GitRepo = strings.TrimSpace(t.GitRepo)
+	t.GitCommit = strings.TrimSpace(t.GitCommit)
+	t.GitRef = strings.TrimSpace(t.GitRef)
+	errs.RepoURL("/template/git_repo", t.GitRepo)
+	errs.CommitSHA("/template/git_commit", t.GitCommit)
+	validateRunParamsAndResources(errs, "/template", t.Params, t.Resources)
+}
+
+func (api *experimentsAPI) handleCreateRunSchedule(w http.ResponseWriter, r *http.Request) {
+	identity, ok := auth.IdentityFromContext(r.Context())
+	if !ok || strings.TrimSpace(identity.Subject) == "" {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	experimentID := strings.TrimSpace(r.PathValue("experiment_id"))
+	if experimentID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "experiment_id_required")
+		return
+	}
+	projectID, err := projectIDForExperiment(r.Context(), api.db, experimentID)
+	if err != nil || strings.TrimSpace(projectID) == "" {
+		api.writeError(w, r, http.StatusNotFound, "not_found")
+		return
+	}
+	if !api.requireActiveProject(w, r, projectID) {
+		return
+	}
+
+	var req createRunScheduleRequest
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
+		return
+	}
+	now := time.Now().UTC()
+	schedule, errs := validateRunScheduleRequest(&req, now)
+	if errs.Write(w, r) {
+		return
+	}
+
+	// Gates and policies run at every firing; here the dataset only has to
+	// belong to the project.
+	if ok, err := api.runTemplateDatasetInProject(r.Context(), projectID, req.Template); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	} else if !ok {
+		api.writeError(w, r, http.StatusNotFound, "not_found")
+		return
+	}
+
+	templateJSON, err := json.Marshal(req.Template)
+	if err != nil {
+		api.writeError(w, r, http.StatusBadRequest, "invalid_params")
+		return
+	}
+	next := schedule.Next(now)
+	out := runSchedule{
+		ScheduleID:   uuid.NewString(),
+		ProjectID:    projectID,
+		ExperimentID: experimentID,
+		Name:         req.Name,
+		Cron:         req.Cron,
+		Template:     req.Template,
+		State:        runScheduleStateActive,
+		NextRunAt:    &next,
+		CreatedAt:    now,
+		CreatedBy:    identity.Subject,
+		UpdatedAt:    now,
+		UpdatedBy:    identity.Subject,
+	}
+	integrity, err := integritySHA256(struct {
+		ScheduleID   string          `json:"schedule_id"`
+		ProjectID    string          `json:"project_id"`
+		ExperimentID string          `json:"experiment_id"`
+		Name         string          `json:"name"`
+		Cron         string          `json:"cron"`
+		Template     json.RawMessage `json:"template"`
+		CreatedAt    time.Time       `json:"created_at"`
+		CreatedBy    string          `json:"created_by"`
+	}{out.ScheduleID, projectID, experimentID, out.Name, out.Cron, templateJSON, now, identity.Subject})
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	err = postgres.NewRunScheduleStore(tx).Create(r.Context(), postgres.RunScheduleRecord{
+		ScheduleID:      out.ScheduleID,
+		ProjectID:       projectID,
+		ExperimentID:    experimentID,
+		Name:            out.Name,
+		Cron:            out.Cron,
+		Template:        templateJSON,
+		State:           out.State,
+		NextRunAt:       &next,
+		CreatedAt:       now,
+		CreatedBy:       identity.Subject,
+		IntegritySHA256: integrity,
+	})
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	err = auditlog.Enqueue(r.Context(), tx, auditlog.Event{
+		OccurredAt:   now,
+		Actor:        identity.Subject,
+		Action:       "experiment_run_schedule.create",
+		ResourceType: "experiment_run_schedule",
+		ResourceID:   out.ScheduleID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload: map[string]any{
+			"service":       "experiments",
+			"schedule_id":   out.ScheduleID,
+			"experiment_id": experimentID,
+			"cron":          out.Cron,
+			"template":      json.RawMessage(templateJSON),
+			"next_run_at":   next.Format(time.RFC3339),
+		},
+	})
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "audit_failed")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	w.Header().Set("Location", "/schedules/"+out.ScheduleID)
+	api.writeJSON(w, http.StatusCreated, out)
+}
+
+// runTemplateDatasetInProject reports whether the dataset or pinned version
+// of t belongs to projectID; a template without a dataset always does.
+func (api *experimentsAPI) runTemplateDatasetInProject(ctx context.Context, projectID string, t runScheduleTemplate) (bool, error) {
+	if t.DatasetSelector == "" {
+		return true, nil
+	}
+	id, version := t.DatasetID, false
+	if t.DatasetSelector == runScheduleSelectorPinned {
+		id, version = t.DatasetVersionID, true
+	}
+	datasetProjectID, err := postgres.NewDatasetStore(api.db).DatasetProjectID(ctx, id, version)
+	if errors.Is(err, repo.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return datasetProjectID == projectID, nil
+}
+
+// runScheduleFromRecord decodes the stored template.
+func runScheduleFromRecord(record postgres.RunScheduleRecord) (runSchedule, error) {
+	out := runSchedule{
+		ScheduleID:   record.ScheduleID,
+		ProjectID:    record.ProjectID,
+		ExperimentID: record.ExperimentID,
+		Name:         record.Name,
+		Cron:         record.Cron,
+		State:        record.State,
+		NextRunAt:    record.NextRunAt,
+		LastRunAt:    record.LastRunAt,
+		LastRunID:    record.LastRunID,
+		LastStatus:   record.LastStatus,
+		LastError:    record.LastError,
+		CreatedAt:    record.CreatedAt,
+		CreatedBy:    record.CreatedBy,
+		UpdatedAt:    record.UpdatedAt,
+		UpdatedBy:    record.UpdatedBy,
+	}
+	if err := json.Unmarshal(record.Template, &out.Template); err != nil {
+		return runSchedule{}, err
+	}
+	return out, nil
+}
+
+func (api *experimentsAPI) handleListRunSchedules(w http.ResponseWriter, r *http.Request) {
+	experimentID := strings.TrimSpace(r.PathValue("experiment_id"))
+	if experimentID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "experiment_id_required")
+		return
+	}
+	state := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("state")))
+	switch state {
+	case "", runScheduleStateActive, runScheduleStatePaused:
+	default:
+		api.writeError(w, r, http.StatusBadRequest, "invalid_state")
+		return
+	}
+	limit := httpapi.Limit(r, 100, 500)
+
+	records, err := postgres.NewRunScheduleStore(api.db).List(r.Context(), postgres.RunScheduleFilter{
+		ExperimentID: experimentID,
+		State:        state,
+		Limit:        limit,
+	})
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	out := make([]runSchedule, 0, len(records))
+	for _, record := range records {
+		schedule, err := runScheduleFromRecord(record)
+		if err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+		out = append(out, schedule)
+	}
+	api.writeJSON(w, http.StatusOK, map[string]any{
+		"experiment_id": experimentID,
+		"schedules":     out,
+	})
+}
+
+func (api *experimentsAPI) handleGetRunSchedule(w http.ResponseWriter, r *http.Request) {
+	scheduleID := strings.TrimSpace(r.PathValue("schedule_id"))
+	if scheduleID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "schedule_id_required")
+		return
+	}
+	record, err := postgres.NewRunScheduleStore(api.db).Get(r.Context(), scheduleID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	out, err := runScheduleFromRecord(record)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	api.writeJSON(w, http.StatusOK, out)
+}
+
+func (api *experimentsAPI) handlePauseRunSchedule(w http.ResponseWriter, r *http.Request) {
+	api.setRunScheduleState(w, r, runScheduleStatePaused)
+}
+
+func (api *experimentsAPI) handleResumeRunSchedule(w http.ResponseWriter, r *http.Request) {
+	api.setRunScheduleState(w, r, runScheduleStateActive)
+}
+
+// setRunScheduleState pauses or resumes a schedule. Resuming recomputes the
+// next firing from now, so periods missed while paused are skipped; setting
+// the current state is a no-op.
+func (api *experimentsAPI) setRunScheduleState(w http.ResponseWriter, r *http.Request, state string) {
+	identity, ok := auth.IdentityFromContext(r.Context())
+	if !ok || strings.TrimSpace(identity.Subject) == "" {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	scheduleID := strings.TrimSpace(r.PathValue("schedule_id"))
+	if scheduleID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "schedule_id_required")
+		return
+	}
+
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	schedules := postgres.NewRunScheduleStore(tx)
+	record, err := schedules.GetForUpdate(r.Context(), scheduleID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	current, err := runScheduleFromRecord(record)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if current.State == state {
+		api.writeJSON(w, http.StatusOK, current)
+		return
+	}
+
+	now := time.Now().UTC()
+	var next *time.Time
+	action := "experiment_run_schedule.pause"
+	if state == runScheduleStateActive {
+		if !api.requireActiveProject(w, r, current.ProjectID) {
+			return
+		}
+		schedule, err := cron.Parse(current.Cron)
+		if err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+		t := schedule.Next(now)
+		next = &t
+		action = "experiment_run_schedule.resume"
+	}
+
+	if err := schedules.SetState(r.Context(), scheduleID, state, next, now, identity.Subject); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	payload := map[string]any{
+		"service":       "experiments",
+		"schedule_id":   scheduleID,
+		"experiment_id": current.ExperimentID,
+		"state":         state,
+	}
+	if next != nil {
+		payload["next_run_at"] = next.Format(time.RFC3339)
+	}
+	err = auditlog.Enqueue(r.Context(), tx, auditlog.Event{
+		OccurredAt:   now,
+		Actor:        identity.Subject,
+		Action:       action,
+		ResourceType: "experiment_run_schedule",
+		ResourceID:   scheduleID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload:      payload,
+	})
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "audit_failed")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	current.State = state
+	current.NextRunAt = next
+	current.UpdatedAt = now
+	current.UpdatedBy = identity.Subject
+	api.writeJSON(w, http.StatusOK, current)
+}
+
+func (api *experimentsAPI) handleListRunScheduleFirings(w http.ResponseWriter, r *http.Request) {
+	scheduleID := strings.TrimSpace(r.PathValue("schedule_id"))
+	if scheduleID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "schedule_id_required")
+		return
+	}
+	schedules := postgres.NewRunScheduleStore(api.db)
+	if _, err := schedules.Get(r.Context(), scheduleID); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	limit := httpapi.Limit(r, 100, 500)
+
+	records, err := schedules.ListFirings(r.Context(), scheduleID, limit)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	out := make([]runScheduleFiring, 0, len(records))
+	for _, record := range records {
+		out = append(out, runScheduleFiring{
+			FiringID:         record.FiringID,
+			ScheduleID:       record.ScheduleID,
+			ScheduledFor:     record.ScheduledFor,
+			FiredAt:          record.FiredAt,
+			Status:           record.Status,
+			RunID:            record.RunID,
+			DatasetVersionID: record.DatasetVersionID,
+			ErrorCode:        record.ErrorCode,
+		})
+	}
+	api.writeJSON(w, http.StatusOK, map[string]any{
+		"schedule_id": scheduleID,
+		"firings":     out,
+	})
+}
+
+// resolveScheduleDatasetVersion picks the dataset version for one firing; an
+// empty result with a nil error means no version qualifies.
+func (api *experimentsAPI) resolveScheduleDatasetVersion(ctx context.Context, t runScheduleTemplate) (string, error) {
+	passingOnly := false
+	switch t.DatasetSelector {
+	case "":
+		return "", nil
+	case runScheduleSelectorPinned:
+		return t.DatasetVersionID, nil
+	case runScheduleSelectorLatest:
+	case runScheduleSelectorLatestPassing:
+		passingOnly = true
+	default:
+		return "", errors.New("unsupported dataset selector")
+	}
+	versionID, err := postgres.NewDatasetStore(api.db).LatestDatasetVersionID(ctx, t.DatasetID, passingOnly)
+	if errors.Is(err, repo.ErrNotFound) {
+		return "", nil
+	}
+	return versionID, err
+}
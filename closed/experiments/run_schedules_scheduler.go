@@ -0,0 +1,243 @@
+package experiments
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/cron"
+	"github.com/animus-labs/animus-go/closed/internal/platform/lineageevent"
+	"github.com/animus-labs/animus-go/closed/internal/repo/postgres"
+	"github.com/google/uuid"
+)
+
+const (
+	runSchedulerActor     = "system:run-scheduler"
+	runSchedulerUserAgent = "animus-run-scheduler"
+)
+
+func startRunScheduler(ctx context.Context, logger *slog.Logger, api *experimentsAPI, interval time.Duration) {
+	if api == nil || api.db == nil {
+		return
+	}
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := api.runDueSchedules(ctx, time.Now().UTC()); err != nil && logger != nil {
+					logger.Warn("scheduled run firing failed", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+type dueRunSchedule struct {
+	ScheduleID   string
+	ExperimentID string
+	Cron         string
+	Template     runScheduleTemplate
+	NextRunAt    time.Time
+	CreatedBy    string
+}
+
+// runDueSchedules claims every due schedule before firing it, so a period
+// creates at most one run even with several replicas. Like digests, periods
+// missed while the service was down are skipped rather than fired in a burst.
+func (api *experimentsAPI) runDueSchedules(ctx context.Context, now time.Time) error {
+	records, err := postgres.NewRunScheduleStore(api.db).ListDue(ctx, now, 50)
+	if err != nil {
+		return err
+	}
+	due := make([]dueRunSchedule, 0, len(records))
+	for _, schedule := range records {
+		record := dueRunSchedule{
+			ScheduleID:   schedule.ScheduleID,
+			ExperimentID: schedule.ExperimentID,
+			Cron:         schedule.Cron,
+			CreatedBy:    schedule.CreatedBy,
+		}
+		if schedule.NextRunAt != nil {
+			record.NextRunAt = *schedule.NextRunAt
+		}
+		if err := json.Unmarshal(schedule.Template, &record.Template); err != nil {
+			return err
+		}
+		due = append(due, record)
+	}
+
+	var lastErr error
+	for _, record := range due {
+		claimed, err := api.claimRunSchedule(ctx, record, now)
+		if err != nil {
+			lastErr = errors.Join(lastErr, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+		if err := api.fireRunSchedule(ctx, record, now); err != nil {
+			lastErr = errors.Join(lastErr, err)
+		}
+	}
+	return lastErr
+}
+
+// claimRunSchedule advances next_run_at past now if no other replica has yet.
+// An expression that stopped firing pauses the schedule.
+func (api *experimentsAPI) claimRunSchedule(ctx context.Context, record dueRunSchedule, now time.Time) (bool, error) {
+	state := runScheduleStateActive
+	var next *time.Time
+	if schedule, err := cron.Parse(record.Cron); err == nil {
+		if t := schedule.Next(now); !t.IsZero() {
+			next = &t
+		}
+	}
+	if next == nil {
+		state = runScheduleStatePaused
+	}
+	return postgres.NewRunScheduleStore(api.db).Claim(ctx, record.ScheduleID, record.NextRunAt, next, state, now)
+}
+
+// fireRunSchedule creates the run for one claimed period by calling the run
+// creation handler in-process as the schedule's creator, so quality gates,
+// dataset policies, fencing and the run audit apply exactly as for an API
+// call. The firing ID doubles as the request ID of those gate decisions.
+func (api *experimentsAPI) fireRunSchedule(ctx context.Context, record dueRunSchedule, now time.Time) error {
+	firing := runScheduleFiring{
+		FiringID:     uuid.NewString(),
+		ScheduleID:   record.ScheduleID,
+		ScheduledFor: record.NextRunAt.UTC(),
+		FiredAt:      now,
+	}
+
+	versionID, err := api.resolveScheduleDatasetVersion(ctx, record.Template)
+	switch {
+	case err != nil:
+		firing.Status, firing.ErrorCode = runScheduleFiringFailed, "internal_error"
+	case versionID == "" && record.Template.DatasetSelector != "":
+		firing.Status, firing.ErrorCode = runScheduleFiringBlocked, "no_eligible_dataset_version"
+	default:
+		firing.DatasetVersionID = versionID
+		firing.Status, firing.RunID, firing.ErrorCode = api.createScheduledRun(ctx, record, firing.FiringID, versionID)
+	}
+
+	tx, err := api.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	err = postgres.NewRunScheduleStore(tx).RecordFiring(ctx, postgres.RunScheduleFiringRecord{
+		FiringID:         firing.FiringID,
+		ScheduleID:       firing.ScheduleID,
+		ScheduledFor:     firing.ScheduledFor,
+		FiredAt:          firing.FiredAt,
+		Status:           firing.Status,
+		RunID:            firing.RunID,
+		DatasetVersionID: firing.DatasetVersionID,
+		ErrorCode:        firing.ErrorCode,
+	})
+	if err != nil {
+		return err
+	}
+
+	if firing.RunID != "" {
+		err = lineageevent.Enqueue(ctx, tx, lineageevent.Event{
+			OccurredAt:  now,
+			Actor:       runSchedulerActor,
+			RequestID:   firing.FiringID,
+			SubjectType: "experiment_run_schedule",
+			SubjectID:   firing.ScheduleID,
+			Predicate:   "triggered",
+			ObjectType:  "experiment_run",
+			ObjectID:    firing.RunID,
+			Metadata: map[string]any{
+				"experiment_id":      record.ExperimentID,
+				"scheduled_for":      firing.ScheduledFor.Format(time.RFC3339),
+				"dataset_version_id": firing.DatasetVersionID,
+				"image_ref":          record.Template.ImageRef,
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	payload := map[string]any{
+		"service":       "experiments",
+		"schedule_id":   firing.ScheduleID,
+		"experiment_id": record.ExperimentID,
+		"firing_id":     firing.FiringID,
+		"scheduled_for": firing.ScheduledFor.Format(time.RFC3339),
+		"status":        firing.Status,
+	}
+	if firing.RunID != "" {
+		payload["run_id"] = firing.RunID
+	}
+	if firing.DatasetVersionID != "" {
+		payload["dataset_version_id"] = firing.DatasetVersionID
+	}
+	if firing.ErrorCode != "" {
+		payload["error"] = firing.ErrorCode
+	}
+	err = auditlog.Enqueue(ctx, tx, auditlog.Event{
+		OccurredAt:   now,
+		Actor:        runSchedulerActor,
+		Action:       "experiment_run_schedule.fire",
+		ResourceType: "experiment_run_schedule",
+		ResourceID:   firing.ScheduleID,
+		RequestID:    firing.FiringID,
+		UserAgent:    runSchedulerUserAgent,
+		Payload:      payload,
+	})
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// createScheduledRun returns the firing status with the created (or, under
+// dedupe fencing, reused) run ID, or the error code that refused the run.
+func (api *experimentsAPI) createScheduledRun(ctx context.Context, record dueRunSchedule, requestID string, datasetVersionID string) (string, string, string) {
+	rec, err := api.createExperimentRunInProcess(ctx, auth.Identity{Subject: record.CreatedBy}, record.ExperimentID, requestID, runSchedulerUserAgent, createExperimentRunRequest{
+		DatasetVersionID: datasetVersionID,
+		Status:           "pending",
+		GitRepo:          record.Template.GitRepo,
+		GitCommit:        record.Template.GitCommit,
+		GitRef:           record.Template.GitRef,
+		Params:           record.Template.Params,
+	})
+	if err != nil {
+		return runScheduleFiringFailed, "", "internal_error"
+	}
+
+	var payload struct {
+		RunID string `json:"run_id"`
+		Error string `json:"error"`
+	}
+	_ = json.Unmarshal(rec.buf.Bytes(), &payload)
+	switch {
+	case rec.created() && payload.RunID != "":
+		return runScheduleFiringCreated, payload.RunID, ""
+	case payload.Error == "":
+		payload.Error = "internal_error"
+	}
+	if rec.status >= http.StatusInternalServerError {
+		return runScheduleFiringFailed, "", payload.Error
+	}
+	return runScheduleFiringBlocked, "", payload.Error
+}
@@ -0,0 +1,75 @@
+package experiments
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidateRunScheduleRequest(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	const (
+		datasetID = "6f1c2a3e-8d4b-4b8e-9f0a-1c2d3e4f5a6b"
+		versionID = "0a9b8c7d-6e5f-4a3b-8c1d-2e3f4a5b6c7d"
+	)
+
+	cases := []struct {
+		name   string
+		req    createRunScheduleRequest
+		fields []string
+	}{
+		{name: "no dataset", req: createRunScheduleRequest{Name: "nightly", Cron: "@daily"}},
+		{name: "latest passing", req: createRunScheduleRequest{Name: "n", Cron: "0 3 * * 1-5", Template: runScheduleTemplate{DatasetID: datasetID, ImageRef: "ghcr.io/acme/train:1.2"}}},
+		{name: "missing name and cron", req: createRunScheduleRequest{}, fields: []string{"name", "cron"}},
+		{name: "bad cron", req: createRunScheduleRequest{Name: "n", Cron: "61 * * * *"}, fields: []string{"cron"}},
+		{name: "never fires", req: createRunScheduleRequest{Name: "n", Cron: "0 0 30 2 *"}, fields: []string{"cron"}},
+		{name: "unknown selector", req: createRunScheduleRequest{Name: "n", Cron: "@hourly", Template: runScheduleTemplate{DatasetID: datasetID, DatasetSelector: "oldest"}}, fields: []string{"template.dataset_selector"}},
+		{name: "latest without dataset", req: createRunScheduleRequest{Name: "n", Cron: "@hourly", Template: runScheduleTemplate{DatasetSelector: "latest"}}, fields: []string{"template.dataset_id"}},
+		{name: "latest with version", req: createRunScheduleRequest{Name: "n", Cron: "@hourly", Template: runScheduleTemplate{DatasetSelector: "latest", DatasetID: datasetID, DatasetVersionID: versionID}}, fields: []string{"template.dataset_version_id"}},
+		{name: "bad template fields", req: createRunScheduleRequest{Name: "n", Cron: "@hourly", Template: runScheduleTemplate{
+			ImageRef:  "Not An Image",
+			GitCommit: "xyz",
+			Params:    map[string]any{"lr": []any{1}},
+			Resources: map[string]any{"gpus": -1},
+		}}, fields: []string{"template.image_ref", "template.git_commit", "template.params.lr", "template.resources.gpus"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := tc.req
+			_, errs := validateRunScheduleRequest(&req, now)
+			got := errs.Fields()
+			if len(got) != len(tc.fields) {
+				t.Fatalf("fields = %+v, want %v", got, tc.fields)
+			}
+			for i, field := range tc.fields {
+				if got[i].Field != field {
+					t.Fatalf("fields = %+v, want %v", got, tc.fields)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateRunScheduleRequestNormalizes(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	req := createRunScheduleRequest{
+		Name: " nightly ",
+		Cron: " 30  2 * * * ",
+		Template: runScheduleTemplate{
+			DatasetID:        "6f1c2a3e-8d4b-4b8e-9f0a-1c2d3e4f5a6b",
+			DatasetVersionID: " 0a9b8c7d-6e5f-4a3b-8c1d-2e3f4a5b6c7d ",
+		},
+	}
+	schedule, errs := validateRunScheduleRequest(&req, now)
+	if !errs.Empty() {
+		t.Fatalf("unexpected errors %+v", errs.Fields())
+	}
+	if req.Name != "nightly" || req.Cron != "30 2 * * *" {
+		t.Fatalf("not normalized: %+v", req)
+	}
+	if req.Template.DatasetSelector != runScheduleSelectorPinned || req.Template.DatasetID != "" || req.Template.DatasetVersionID != "0a9b8c7d-6e5f-4a3b-8c1d-2e3f4a5b6c7d" {
+		t.Fatalf("template = %+v", req.Template)
+	}
+	if next := schedule.Next(now); !next.Equal(time.Date(2026, 3, 2, 2, 30, 0, 0, time.UTC)) {
+		t.Fatalf("next = %v", next)
+	}
+}
@@ -1,4 +1,4 @@
-package main
+package experiments
 
 import (
 	"bytes"
@@ -16,6 +16,7 @@ import (
 	"github.com/animus-labs/animus-go/closed/internal/execution/specvalidator"
 	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
 	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
 	"github.com/animus-labs/animus-go/closed/internal/repo"
 	"github.com/animus-labs/animus-go/closed/internal/repo/postgres"
 )
@@ -38,6 +39,12 @@ type createRunRequest struct {
 	CodeRef         runSpecCodeRef    `json:"codeRef"`
 	EnvLock         runSpecEnvLockRef `json:"envLock"`
 	Parameters      map[string]any    `json:"parameters"`
+	ResumeFrom      *runCheckpointRef `json:"resumeFrom,omitempty"`
+}
+
+type runCheckpointRef struct {
+	RunID      string `json:"runId"`
+	ArtifactID string `json:"artifactId"`
 }
 
 type runSpecCodeRef struct {
@@ -98,8 +105,8 @@ func (api *experimentsAPI) handleCreateRun(w http.ResponseWriter, r *http.Reques
 	}
 
 	var req createRunRequest
-	if err := decodeJSON(r, &req); err != nil {
-		api.writeError(w, r, http.StatusBadRequest, "invalid_json")
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
 		return
 	}
 
@@ -117,6 +124,9 @@ func (api *experimentsAPI) handleCreateRun(w http.ResponseWriter, r *http.Reques
 		api.writeError(w, r, http.StatusBadRequest, "environment_lock_required")
 		return
 	}
+	if !api.requireActiveProject(w, r, projectID) {
+		return
+	}
 	envStore := postgres.NewEnvironmentStore(api.db)
 	if envStore == nil {
 		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
@@ -159,6 +169,24 @@ func (api *experimentsAPI) handleCreateRun(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	if req.ResumeFrom != nil {
+		checkpoint, err := resolveRunCheckpoint(r.Context(), api.db, projectID, *req.ResumeFrom)
+		if err != nil {
+			switch {
+			case errors.Is(err, errCheckpointRefInvalid):
+				api.writeError(w, r, http.StatusBadRequest, "checkpoint_ref_invalid")
+			case errors.Is(err, errCheckpointNotFound):
+				api.writeError(w, r, http.StatusNotFound, "checkpoint_not_found")
+			case errors.Is(err, errCheckpointKindInvalid):
+				api.writeError(w, r, http.StatusBadRequest, "checkpoint_kind_invalid")
+			default:
+				api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			}
+			return
+		}
+		runSpec.ResumeFrom = &checkpoint
+	}
+
 	if err := api.ensureDatasetBindingsExist(r.Context(), projectID, runSpec.DatasetBindings); err != nil {
 		if errors.Is(err, errDatasetVersionMissing) {
 			api.writeError(w, r, http.StatusNotFound, "dataset_version_not_found")
@@ -212,36 +240,44 @@ func (api *experimentsAPI) handleCreateRun(w http.ResponseWriter, r *http.Reques
 
 	if created {
 		now := time.Now().UTC()
-		_, err = auditlog.Insert(r.Context(), tx, auditlog.Event{
+		createdPayload := map[string]any{
+			"service":         "experiments",
+			"project_id":      projectID,
+			"run_id":          record.ID,
+			"spec_hash":       specHash,
+			"idempotency_key": idempotencyKey,
+		}
+		if runSpec.ResumeFrom != nil {
+			createdPayload["resumed_from"] = runSpec.ResumeFrom
+			if err := recordRunCheckpoint(r.Context(), tx, projectID, record.ID, identity.Subject, r.Header.Get("X-Request-Id"), *runSpec.ResumeFrom, now); err != nil {
+				api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+				return
+			}
+		}
+		err = auditlog.Enqueue(r.Context(), tx, auditlog.Event{
 			OccurredAt:   now,
 			Actor:        identity.Subject,
 			Action:       "run.created",
 			ResourceType: "run",
 			ResourceID:   record.ID,
 			RequestID:    r.Header.Get("X-Request-Id"),
-			IP:           requestIP(r.RemoteAddr),
+			IP:           httpapi.RequestIP(r.RemoteAddr),
 			UserAgent:    r.UserAgent(),
-			Payload: map[string]any{
-				"service":         "experiments",
-				"project_id":      projectID,
-				"run_id":          record.ID,
-				"spec_hash":       specHash,
-				"idempotency_key": idempotencyKey,
-			},
+			Payload:      createdPayload,
 		})
 		if err != nil {
 			api.writeError(w, r, http.StatusInternalServerError, "audit_failed")
 			return
 		}
 
-		_, err = auditlog.Insert(r.Context(), tx, auditlog.Event{
+		err = auditlog.Enqueue(r.Context(), tx, auditlog.Event{
 			OccurredAt:   now,
 			Actor:        identity.Subject,
 			Action:       "run.validated",
 			ResourceType: "run",
 			ResourceID:   record.ID,
 			RequestID:    r.Header.Get("X-Request-Id"),
-			IP:           requestIP(r.RemoteAddr),
+			IP:           httpapi.RequestIP(r.RemoteAddr),
 			UserAgent:    r.UserAgent(),
 			Payload: map[string]any{
 				"service":    "experiments",
@@ -255,14 +291,14 @@ func (api *experimentsAPI) handleCreateRun(w http.ResponseWriter, r *http.Reques
 			return
 		}
 
-		_, err = auditlog.Insert(r.Context(), tx, auditlog.Event{
+		err = auditlog.Enqueue(r.Context(), tx, auditlog.Event{
 			OccurredAt:   now,
 			Actor:        identity.Subject,
 			Action:       "policy.snapshot.materialized",
 			ResourceType: "policy_snapshot",
 			ResourceID:   runSpec.PolicySnapshot.SnapshotSHA256,
 			RequestID:    r.Header.Get("X-Request-Id"),
-			IP:           requestIP(r.RemoteAddr),
+			IP:           httpapi.RequestIP(r.RemoteAddr),
 			UserAgent:    r.UserAgent(),
 			Payload: map[string]any{
 				"service":                "experiments",
@@ -432,6 +468,7 @@ func marshalRunSpec(spec domain.RunSpec, pipelineSpecJSON []byte) ([]byte, error
 		},
 		Parameters:     spec.Parameters,
 		PolicySnapshot: spec.PolicySnapshot,
+		ResumeFrom:     spec.ResumeFrom,
 		CreatedAt:      spec.CreatedAt,
 		CreatedBy:      strings.TrimSpace(spec.CreatedBy),
 	}
@@ -447,6 +484,7 @@ type runSpecPayload struct {
 	EnvLock         runSpecEnvLockPayload `json:"envLock"`
 	Parameters      map[string]any        `json:"parameters"`
 	PolicySnapshot  domain.PolicySnapshot `json:"policySnapshot"`
+	ResumeFrom      *domain.CheckpointRef `json:"resumeFrom,omitempty"`
 	CreatedAt       time.Time             `json:"createdAt"`
 	CreatedBy       string                `json:"createdBy,omitempty"`
 }
@@ -479,6 +517,7 @@ func hashRunSpec(spec domain.RunSpec) (string, error) {
 		},
 		Parameters:        canonicalParameters(spec.Parameters),
 		PolicySnapshotSHA: spec.PolicySnapshot.SnapshotSHA256,
+		ResumeFrom:        spec.ResumeFrom,
 	}
 	blob, err := json.Marshal(canonical)
 	if err != nil {
@@ -497,6 +536,7 @@ type canonicalRunSpec struct {
 	EnvLock           canonicalEnvLock      `json:"envLock"`
 	Parameters        json.RawMessage       `json:"parameters"`
 	PolicySnapshotSHA string                `json:"policySnapshotSha256"`
+	ResumeFrom        *domain.CheckpointRef `json:"resumeFrom,omitempty"`
 }
 
 type canonicalEnvLock struct {
@@ -0,0 +1,527 @@
+package experiments
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/domain"
+	executor "github.com/animus-labs/animus-go/closed/internal/execution/executor"
+	"github.com/animus-labs/animus-go/closed/internal/execution/executor/dryrun"
+	"github.com/animus-labs/animus-go/closed/internal/execution/state"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	"github.com/animus-labs/animus-go/closed/internal/platform/lineageevent"
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+	"github.com/animus-labs/animus-go/closed/internal/repo/postgres"
+	"github.com/animus-labs/animus-go/closed/internal/service/runs"
+)
+
+const (
+	stepStatusPending   = "pending"
+	stepStatusRunning   = "running"
+	stepStatusSucceeded = "succeeded"
+	stepStatusFailed    = "failed"
+	stepStatusSkipped   = "skipped"
+)
+
+type runStepSummary struct {
+	Name        string                 `json:"name"`
+	DependsOn   []string               `json:"dependsOn"`
+	MaxAttempts int                    `json:"maxAttempts"`
+	Status      string                 `json:"status"`
+	Attempts    int                    `json:"attempts"`
+	Rerunnable  bool                   `json:"rerunnable"`
+	LastAttempt *stepExecutionResponse `json:"lastAttempt,omitempty"`
+}
+
+type runStepsResponse struct {
+	RunID string           `json:"runId"`
+	State string           `json:"state"`
+	Steps []runStepSummary `json:"steps"`
+}
+
+type stepDatasetInput struct {
+	Name       string `json:"name"`
+	DatasetRef string `json:"datasetRef"`
+}
+
+type stepArtifactInput struct {
+	Name     string `json:"name"`
+	FromStep string `json:"fromStep"`
+	Artifact string `json:"artifact"`
+}
+
+type stepArtifactOutput struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	MediaType   string `json:"mediaType,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+type runStepDetail struct {
+	runStepSummary
+	Executions []stepExecutionResponse `json:"executions"`
+	Inputs     struct {
+		Datasets  []stepDatasetInput  `json:"datasets"`
+		Artifacts []stepArtifactInput `json:"artifacts"`
+	} `json:"inputs"`
+	Outputs []stepArtifactOutput `json:"outputs"`
+}
+
+type runStepDetailResponse struct {
+	RunID string        `json:"runId"`
+	State string        `json:"state"`
+	Step  runStepDetail `json:"step"`
+}
+
+// runStepView is what the step endpoints need about a planned run.
+type runStepView struct {
+	run     repo.RunRecord
+	plan    domain.ExecutionPlan
+	spec    domain.PipelineSpec
+	records []repo.StepExecutionRecord
+}
+
+func (api *experimentsAPI) handleListRunSteps(w http.ResponseWriter, r *http.Request) {
+	projectID, runID, ok := api.runStepPath(w, r)
+	if !ok {
+		return
+	}
+	view, ok := api.loadRunStepView(w, r, api.db, projectID, runID)
+	if !ok {
+		return
+	}
+
+	derived := deriveRunStateFromRecords(&view.plan, true, view.records)
+	api.writeJSON(w, http.StatusOK, runStepsResponse{
+		RunID: runID,
+		State: string(derived.State),
+		Steps: buildRunStepSummaries(view.plan, view.records),
+	})
+}
+
+func (api *experimentsAPI) handleGetRunStep(w http.ResponseWriter, r *http.Request) {
+	projectID, runID, ok := api.runStepPath(w, r)
+	if !ok {
+		return
+	}
+	stepName := strings.TrimSpace(r.PathValue("step_name"))
+	if stepName == "" {
+		api.writeError(w, r, http.StatusBadRequest, "step_name_required")
+		return
+	}
+	view, ok := api.loadRunStepView(w, r, api.db, projectID, runID)
+	if !ok {
+		return
+	}
+	detail, found := buildRunStepDetail(view, stepName)
+	if !found {
+		api.writeError(w, r, http.StatusNotFound, "step_not_found")
+		return
+	}
+
+	derived := deriveRunStateFromRecords(&view.plan, true, view.records)
+	api.writeJSON(w, http.StatusOK, runStepDetailResponse{
+		RunID: runID,
+		State: string(derived.State),
+		Step:  detail,
+	})
+}
+
+// handleRerunRunStep runs a fresh retry cycle for one failed or skipped step
+// through the dry-run executor, reopening the run's dry run until the state
+// is derived again.
+func (api *experimentsAPI) handleRerunRunStep(w http.ResponseWriter, r *http.Request) {
+	identity, ok := auth.IdentityFromContext(r.Context())
+	if !ok || strings.TrimSpace(identity.Subject) == "" {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	projectID, runID, ok := api.runStepPath(w, r)
+	if !ok {
+		return
+	}
+	stepName := strings.TrimSpace(r.PathValue("step_name"))
+	if stepName == "" {
+		api.writeError(w, r, http.StatusBadRequest, "step_name_required")
+		return
+	}
+
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	runStore := postgres.NewRunSpecStore(tx)
+	planStore := postgres.NewPlanStore(tx)
+	stepStore := postgres.NewStepExecutionStore(tx)
+	if runStore == nil || planStore == nil || stepStore == nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	stateSvc := runs.New(runStore, planStore, stepStore)
+	if stateSvc == nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	view, ok := api.loadRunStepView(w, r, tx, projectID, runID)
+	if !ok {
+		return
+	}
+	previous, found := latestStepExecution(view.records, stepName)
+	if !found {
+		if _, inPlan := buildRunStepDetail(view, stepName); inPlan {
+			api.writeError(w, r, http.StatusConflict, "step_not_rerunnable")
+			return
+		}
+		api.writeError(w, r, http.StatusNotFound, "step_not_found")
+		return
+	}
+
+	auditInfo := runs.AuditInfo{
+		Actor:     identity.Subject,
+		RequestID: r.Header.Get("X-Request-Id"),
+		UserAgent: r.UserAgent(),
+		IP:        httpapi.RequestIP(r.RemoteAddr),
+		Service:   "experiments",
+	}
+	auditAppender := runs.NewAuditAppender(tx)
+	if auditAppender == nil {
+		api.writeError(w, r, http.StatusInternalServerError, "audit_failed")
+		return
+	}
+
+	exec := dryrun.New(stepStore)
+	input := executor.DryRunInput{
+		ProjectID: projectID,
+		RunID:     runID,
+		SpecHash:  view.run.SpecHash,
+		Plan:      view.plan,
+	}
+	if _, err := stateSvc.MarkDryRunRunningWithAudit(r.Context(), auditAppender, auditInfo, projectID, runID, view.run.SpecHash); err != nil {
+		api.writeRepoError(w, r, err)
+		return
+	}
+	result, err := exec.RerunStep(r.Context(), input, stepName)
+	if err != nil {
+		switch {
+		case errors.Is(err, dryrun.ErrStepNotFound):
+			api.writeError(w, r, http.StatusNotFound, "step_not_found")
+		case errors.Is(err, dryrun.ErrStepNotRerunnable):
+			api.writeError(w, r, http.StatusConflict, "step_not_rerunnable")
+		case errors.Is(err, dryrun.ErrStepDependenciesIncomplete):
+			api.writeError(w, r, http.StatusConflict, "step_dependencies_incomplete")
+		default:
+			api.writeError(w, r, http.StatusInternalServerError, "rerun_failed")
+		}
+		return
+	}
+
+	records, err := stepStore.ListByRun(r.Context(), projectID, runID)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if err := api.appendStepRerunEvents(r, tx, identity.Subject, projectID, runID, view.run.SpecHash, previous, records, result.Attempts); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "audit_failed")
+		return
+	}
+
+	_, _, derived, err := stateSvc.DeriveAndPersistWithAudit(r.Context(), auditAppender, auditInfo, projectID, runID, view.run.SpecHash)
+	if err != nil {
+		api.writeRepoError(w, r, err)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	view.records = records
+	detail, _ := buildRunStepDetail(view, stepName)
+	api.writeJSON(w, http.StatusOK, runStepDetailResponse{
+		RunID: runID,
+		State: string(derived),
+		Step:  detail,
+	})
+}
+
+func (api *experimentsAPI) runStepPath(w http.ResponseWriter, r *http.Request) (string, string, bool) {
+	projectID := strings.TrimSpace(r.PathValue("project_id"))
+	runID := strings.TrimSpace(r.PathValue("run_id"))
+	if projectID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "project_id_required")
+		return "", "", false
+	}
+	if runID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "run_id_required")
+		return "", "", false
+	}
+	return projectID, runID, true
+}
+
+func (api *experimentsAPI) loadRunStepView(w http.ResponseWriter, r *http.Request, db postgres.DB, projectID, runID string) (runStepView, bool) {
+	runStore := postgres.NewRunSpecStore(db)
+	planStore := postgres.NewPlanStore(db)
+	stepStore := postgres.NewStepExecutionStore(db)
+	if runStore == nil || planStore == nil || stepStore == nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return runStepView{}, false
+	}
+	runRecord, err := runStore.GetRun(r.Context(), projectID, runID)
+	if err != nil {
+		api.writeRepoError(w, r, err)
+		return runStepView{}, false
+	}
+	planSpec, planExists, err := loadExecutionPlan(r.Context(), planStore, projectID, runID)
+	if err != nil {
+		api.writeRepoError(w, r, err)
+		return runStepView{}, false
+	}
+	if !planExists {
+		api.writeError(w, r, http.StatusNotFound, "plan_not_found")
+		return runStepView{}, false
+	}
+	pipelineSpec, err := decodePipelineSpec(runRecord.PipelineSpec)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "invalid_pipeline_spec")
+		return runStepView{}, false
+	}
+	records, err := stepStore.ListByRun(r.Context(), projectID, runID)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return runStepView{}, false
+	}
+	return runStepView{run: runRecord, plan: *planSpec, spec: pipelineSpec, records: records}, true
+}
+
+// appendStepRerunEvents audits the re-run and every new attempt, and links
+// the first new attempt to the attempt it retries in lineage.
+func (api *experimentsAPI) appendStepRerunEvents(r *http.Request, tx auditlog.QueryRower, actor, projectID, runID, specHash string, previous repo.StepExecutionRecord, records []repo.StepExecutionRecord, attempts []executor.DryRunAttempt) error {
+	now := time.Now().UTC()
+	var first repo.StepExecutionRecord
+	for _, record := range records {
+		if record.StepName == previous.StepName && record.Attempt == previous.Attempt+1 {
+			first = record
+			break
+		}
+	}
+	if first.ID == "" {
+		return errors.New("rerun attempt not recorded")
+	}
+
+	err := auditlog.Enqueue(r.Context(), tx, auditlog.Event{
+		OccurredAt:   now,
+		Actor:        actor,
+		Action:       "step.rerun_requested",
+		ResourceType: "step_execution",
+		ResourceID:   first.ID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload: map[string]any{
+			"service":                    "experiments",
+			"project_id":                 projectID,
+			"run_id":                     runID,
+			"step_name":                  previous.StepName,
+			"previous_attempt":           previous.Attempt,
+			"previous_status":            previous.Status,
+			"previous_step_execution_id": previous.ID,
+			"spec_hash":                  specHash,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if err := api.appendDryRunStepAuditEvents(r, tx, actor, projectID, runID, specHash, attempts); err != nil {
+		return err
+	}
+
+	err = lineageevent.Enqueue(r.Context(), tx, lineageevent.Event{
+		OccurredAt:  now,
+		Actor:       actor,
+		RequestID:   r.Header.Get("X-Request-Id"),
+		SubjectType: "step_execution",
+		SubjectID:   first.ID,
+		Predicate:   "retry_of",
+		ObjectType:  "step_execution",
+		ObjectID:    previous.ID,
+		Metadata: map[string]any{
+			"project_id": projectID,
+			"run_id":     runID,
+			"step_name":  previous.StepName,
+			"attempt":    first.Attempt,
+			"spec_hash":  specHash,
+		},
+	})
+	return err
+}
+
+func buildRunStepSummaries(plan domain.ExecutionPlan, records []repo.StepExecutionRecord) []runStepSummary {
+	byStep := groupStepExecutions(records)
+	deps := planDependencies(plan)
+	outcomes := make(map[string]domain.StepOutcome, len(plan.Steps))
+	for name, stepRecords := range byStep {
+		_, outcome := state.DeriveStepOutcome(stepRecords)
+		outcomes[name] = outcome
+	}
+
+	out := make([]runStepSummary, 0, len(plan.Steps))
+	for _, step := range plan.Steps {
+		name := strings.TrimSpace(step.Name)
+		if name == "" {
+			continue
+		}
+		out = append(out, summarizeRunStep(step, deps[name], byStep[name], outcomes))
+	}
+	return out
+}
+
+func summarizeRunStep(step domain.ExecutionPlanStep, dependsOn []string, stepRecords []repo.StepExecutionRecord, outcomes map[string]domain.StepOutcome) runStepSummary {
+	attempts, outcome := state.DeriveStepOutcome(stepRecords)
+	summary := runStepSummary{
+		Name:        strings.TrimSpace(step.Name),
+		DependsOn:   dependsOn,
+		MaxAttempts: step.RetryPolicy.MaxAttempts,
+		Status:      stepStatusFromOutcome(outcome, attempts),
+		Attempts:    attempts,
+	}
+	if summary.DependsOn == nil {
+		summary.DependsOn = []string{}
+	}
+	if last, ok := latestStepExecution(stepRecords, summary.Name); ok {
+		resp := stepExecutionResponseFromRecord(last)
+		summary.LastAttempt = &resp
+	}
+	if summary.Status == stepStatusFailed || summary.Status == stepStatusSkipped {
+		summary.Rerunnable = true
+		for _, dep := range dependsOn {
+			if outcomes[dep] != domain.StepOutcomeSucceeded {
+				summary.Rerunnable = false
+				break
+			}
+		}
+	}
+	return summary
+}
+
+func buildRunStepDetail(view runStepView, stepName string) (runStepDetail, bool) {
+	var planStep *domain.ExecutionPlanStep
+	for i := range view.plan.Steps {
+		if strings.TrimSpace(view.plan.Steps[i].Name) == stepName {
+			planStep = &view.plan.Steps[i]
+			break
+		}
+	}
+	if planStep == nil {
+		return runStepDetail{}, false
+	}
+
+	byStep := groupStepExecutions(view.records)
+	outcomes := make(map[string]domain.StepOutcome, len(byStep))
+	for name, stepRecords := range byStep {
+		_, outcome := state.DeriveStepOutcome(stepRecords)
+		outcomes[name] = outcome
+	}
+	detail := runStepDetail{
+		runStepSummary: summarizeRunStep(*planStep, planDependencies(view.plan)[stepName], byStep[stepName], outcomes),
+		Executions:     make([]stepExecutionResponse, 0, len(byStep[stepName])),
+		Outputs:        []stepArtifactOutput{},
+	}
+	for _, record := range byStep[stepName] {
+		detail.Executions = append(detail.Executions, stepExecutionResponseFromRecord(record))
+	}
+	detail.Inputs.Datasets = []stepDatasetInput{}
+	detail.Inputs.Artifacts = []stepArtifactInput{}
+	for _, specStep := range view.spec.Spec.Steps {
+		if strings.TrimSpace(specStep.Name) != stepName {
+			continue
+		}
+		for _, input := range specStep.Inputs.Datasets {
+			detail.Inputs.Datasets = append(detail.Inputs.Datasets, stepDatasetInput{Name: input.Name, DatasetRef: input.DatasetRef})
+		}
+		for _, input := range specStep.Inputs.Artifacts {
+			detail.Inputs.Artifacts = append(detail.Inputs.Artifacts, stepArtifactInput{Name: input.Name, FromStep: input.FromStep, Artifact: input.Artifact})
+		}
+		for _, output := range specStep.Outputs.Artifacts {
+			detail.Outputs = append(detail.Outputs, stepArtifactOutput{Name: output.Name, Type: output.Type, MediaType: output.MediaType, Description: output.Description})
+		}
+		break
+	}
+	return detail, true
+}
+
+func groupStepExecutions(records []repo.StepExecutionRecord) map[string][]repo.StepExecutionRecord {
+	byStep := make(map[string][]repo.StepExecutionRecord)
+	for _, record := range records {
+		name := strings.TrimSpace(record.StepName)
+		if name == "" {
+			continue
+		}
+		byStep[name] = append(byStep[name], record)
+	}
+	return byStep
+}
+
+func planDependencies(plan domain.ExecutionPlan) map[string][]string {
+	deps := make(map[string][]string)
+	for _, edge := range plan.Edges {
+		from, to := strings.TrimSpace(edge.From), strings.TrimSpace(edge.To)
+		if from == "" || to == "" {
+			continue
+		}
+		deps[to] = append(deps[to], from)
+	}
+	return deps
+}
+
+func latestStepExecution(records []repo.StepExecutionRecord, stepName string) (repo.StepExecutionRecord, bool) {
+	var (
+		latest repo.StepExecutionRecord
+		found  bool
+	)
+	for _, record := range records {
+		if strings.TrimSpace(record.StepName) != stepName {
+			continue
+		}
+		if !found || record.Attempt > latest.Attempt {
+			latest, found = record, true
+		}
+	}
+	return latest, found
+}
+
+func stepStatusFromOutcome(outcome domain.StepOutcome, attempts int) string {
+	switch outcome {
+	case domain.StepOutcomeSucceeded:
+		return stepStatusSucceeded
+	case domain.StepOutcomeFailed:
+		return stepStatusFailed
+	case domain.StepOutcomeSkipped:
+		return stepStatusSkipped
+	}
+	if attempts > 0 {
+		return stepStatusRunning
+	}
+	return stepStatusPending
+}
+
+func stepExecutionResponseFromRecord(record repo.StepExecutionRecord) stepExecutionResponse {
+	return stepExecutionResponse{
+		StepName:     record.StepName,
+		Attempt:      record.Attempt,
+		Status:       record.Status,
+		StartedAt:    record.StartedAt,
+		FinishedAt:   record.FinishedAt,
+		ErrorCode:    strings.TrimSpace(record.ErrorCode),
+		ErrorMessage: strings.TrimSpace(record.ErrorMessage),
+		Result:       json.RawMessage(record.Result),
+	}
+}
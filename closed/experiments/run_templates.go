@@ -0,0 +1,590 @@
+package experiments
+
+import (
+	"encoding/json"
+	"errors"
+	"maps"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	"github.com/animus-labs/animus-go/closed/internal/platform/lineageevent"
+	"github.com/animus-labs/animus-go/closed/internal/platform/validation"
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+	"github.com/animus-labs/animus-go/closed/internal/repo/postgres"
+	"github.com/google/uuid"
+)
+
+const (
+	runTemplateParamString  = "string"
+	runTemplateParamNumber  = "number"
+	runTemplateParamInteger = "integer"
+	runTemplateParamBoolean = "boolean"
+
+	maxRunTemplateNameLen        = 200
+	maxRunTemplateDescriptionLen = 2000
+	maxRunTemplateParams         = 100
+)
+
+// runTemplateParam declares one parameter callers supply when instantiating
+// a template. Minimum and Maximum apply to number and integer parameters.
+type runTemplateParam struct {
+	Type        string   `json:"type"`
+	Description string   `json:"description,omitempty"`
+	Required    bool     `json:"required,omitempty"`
+	Default     any      `json:"default,omitempty"`
+	Enum        []any    `json:"enum,omitempty"`
+	Minimum     *float64 `json:"minimum,omitempty"`
+	Maximum     *float64 `json:"maximum,omitempty"`
+}
+
+type createRunTemplateRequest struct {
+	Name         string                      `json:"name"`
+	Description  string                      `json:"description,omitempty"`
+	Template     runScheduleTemplate         `json:"template"`
+	ParamsSchema map[string]runTemplateParam `json:"params_schema,omitempty"`
+}
+
+// runTemplate is one immutable version of a named template. Template.Params
+// are fixed for every run; callers only supply the ParamsSchema parameters.
+type runTemplate struct {
+	TemplateID   string                      `json:"template_id"`
+	ProjectID    string                      `json:"project_id"`
+	Name         string                      `json:"name"`
+	Version      int                         `json:"version"`
+	Description  string                      `json:"description,omitempty"`
+	Template     runScheduleTemplate         `json:"template"`
+	ParamsSchema map[string]runTemplateParam `json:"params_schema"`
+	CreatedAt    time.Time                   `json:"created_at"`
+	CreatedBy    string                      `json:"created_by"`
+}
+
+type instantiateRunTemplateRequest struct {
+	ExperimentID string         `json:"experiment_id"`
+	Params       map[string]any `json:"params,omitempty"`
+}
+
+type runTemplateInstantiation struct {
+	TemplateID       string          `json:"template_id"`
+	Name             string          `json:"name"`
+	Version          int             `json:"version"`
+	ExperimentID     string          `json:"experiment_id"`
+	DatasetVersionID string          `json:"dataset_version_id,omitempty"`
+	ImageRef         string          `json:"image_ref,omitempty"`
+	Resources        map[string]any  `json:"resources,omitempty"`
+	Params           map[string]any  `json:"params"`
+	Run              json.RawMessage `json:"run"`
+}
+
+// validateRunTemplateRequest normalizes req in place. A schema parameter may
+// not also be fixed by the template params, and every default must satisfy
+// its own declaration.
+func validateRunTemplateRequest(req *createRunTemplateRequest) *validation.Errors {
+	errs := &validation.Errors{}
+	req.Name = strings.TrimSpace(req.Name)
+	if errs.Required("/name", req.Name) && len(req.Name) > maxRunTemplateNameLen {
+		errs.Add("/name", validation.CodeOutOfRange, "must be at most "+strconv.Itoa(maxRunTemplateNameLen)+" characters")
+	}
+	req.Description = strings.TrimSpace(req.Description)
+	if len(req.Description) > maxRunTemplateDescriptionLen {
+		errs.Add("/description", validation.CodeOutOfRange, "must be at most "+strconv.Itoa(maxRunTemplateDescriptionLen)+" characters")
+	}
+	validateRunScheduleTemplate(errs, &req.Template)
+
+	if len(req.ParamsSchema) > maxRunTemplateParams {
+		errs.Add("/params_schema", validation.CodeTooMany, "must declare at most "+strconv.Itoa(maxRunTemplateParams)+" parameters")
+	}
+	for _, name := range slices.Sorted(maps.Keys(req.ParamsSchema)) {
+		pointer := validation.Pointer("params_schema", name)
+		if strings.TrimSpace(name) == "" {
+			errs.Add(pointer, validation.CodeEmptyKey, "parameter names must not be empty")
+			continue
+		}
+		if _, fixed := req.Template.Params[name]; fixed {
+			errs.Add(pointer, validation.CodeDuplicate, "is already fixed by template params")
+		}
+		spec := req.ParamsSchema[name]
+		spec.Type = strings.ToLower(strings.TrimSpace(spec.Type))
+		spec.Description = strings.TrimSpace(spec.Description)
+		req.ParamsSchema[name] = spec
+		switch spec.Type {
+		case runTemplateParamString, runTemplateParamBoolean:
+			if spec.Minimum != nil || spec.Maximum != nil {
+				errs.Add(pointer, validation.CodeOutOfRange, "minimum and maximum only apply to number and integer parameters")
+			}
+		case runTemplateParamNumber, runTemplateParamInteger:
+			if spec.Minimum != nil && spec.Maximum != nil && *spec.Minimum > *spec.Maximum {
+				errs.Add(pointer+"/minimum", validation.CodeOutOfRange, "must not exceed maximum")
+			}
+		default:
+			errs.Add(pointer+"/type", validation.CodeInvalidEnum, "must be string, number, integer or boolean")
+			continue
+		}
+		for i, value := range spec.Enum {
+			checkRunTemplateParamType(errs, pointer+"/enum/"+strconv.Itoa(i), spec.Type, value)
+		}
+		if spec.Default != nil {
+			checkRunTemplateParam(errs, pointer+"/default", spec, spec.Default)
+		}
+	}
+	return errs
+}
+
+// checkRunTemplateParamType records a violation when value, decoded from
+// JSON, does not have the declared type.
+func checkRunTemplateParamType(errs *validation.Errors, pointer, typ string, value any) bool {
+	ok := false
+	switch typ {
+	case runTemplateParamString:
+		_, ok = value.(string)
+	case runTemplateParamNumber:
+		_, ok = value.(float64)
+	case runTemplateParamInteger:
+		n, isNumber := value.(float64)
+		ok = isNumber && n == math.Trunc(n)
+	case runTemplateParamBoolean:
+		_, ok = value.(bool)
+	}
+	if !ok {
+		errs.Add(pointer, validation.CodeInvalidType, "must be of type "+typ)
+	}
+	return ok
+}
+
+// checkRunTemplateParam records a violation when value does not satisfy the
+// declaration spec.
+func checkRunTemplateParam(errs *validation.Errors, pointer string, spec runTemplateParam, value any) bool {
+	if !checkRunTemplateParamType(errs, pointer, spec.Type, value) {
+		return false
+	}
+	if len(spec.Enum) > 0 && !slices.Contains(spec.Enum, value) {
+		errs.Add(pointer, validation.CodeInvalidEnum, "must be one of the declared enum values")
+		return false
+	}
+	if n, ok := value.(float64); ok {
+		if spec.Minimum != nil && n < *spec.Minimum {
+			errs.Add(pointer, validation.CodeOutOfRange, "must be at least "+strconv.FormatFloat(*spec.Minimum, 'g', -1, 64))
+			return false
+		}
+		if spec.Maximum != nil && n > *spec.Maximum {
+			errs.Add(pointer, validation.CodeOutOfRange, "must be at most "+strconv.FormatFloat(*spec.Maximum, 'g', -1, 64))
+			return false
+		}
+	}
+	return true
+}
+
+// resolveRunTemplateParams merges the fixed template params with the
+// caller's params, filling unset parameters from their defaults. Parameters
+// the schema does not declare are rejected, including attempts to override
+// a fixed one.
+func resolveRunTemplateParams(fixed map[string]any, schema map[string]runTemplateParam, provided map[string]any) (map[string]any, *validation.Errors) {
+	errs := &validation.Errors{}
+	out := make(map[string]any, len(fixed)+len(schema))
+	maps.Copy(out, fixed)
+	for _, name := range slices.Sorted(maps.Keys(provided)) {
+		if _, declared := schema[name]; !declared {
+			errs.Add(validation.Pointer("params", name), validation.CodeUnknownField, "is not declared in the template params_schema")
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(schema)) {
+		spec := schema[name]
+		pointer := validation.Pointer("params", name)
+		value, ok := provided[name]
+		switch {
+		case ok && value != nil:
+			if checkRunTemplateParam(errs, pointer, spec, value) {
+				out[name] = value
+			}
+		case spec.Default != nil:
+			out[name] = spec.Default
+		case spec.Required:
+			errs.Add(pointer, validation.CodeRequired, "is required")
+		}
+	}
+	return out, errs
+}
+
+func (api *experimentsAPI) handleCreateRunTemplate(w http.ResponseWriter, r *http.Request) {
+	identity, projectID, ok := api.scopedProject(w, r)
+	if !ok {
+		return
+	}
+	if !api.requireActiveProject(w, r, projectID) {
+		return
+	}
+
+	var req createRunTemplateRequest
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
+		return
+	}
+	if errs := validateRunTemplateRequest(&req); errs.Write(w, r) {
+		return
+	}
+	if ok, err := api.runTemplateDatasetInProject(r.Context(), projectID, req.Template); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	} else if !ok {
+		api.writeError(w, r, http.StatusNotFound, "not_found")
+		return
+	}
+	if req.ParamsSchema == nil {
+		req.ParamsSchema = map[string]runTemplateParam{}
+	}
+	templateJSON, err := json.Marshal(req.Template)
+	if err != nil {
+		api.writeError(w, r, http.StatusBadRequest, "invalid_params")
+		return
+	}
+	schemaJSON, err := json.Marshal(req.ParamsSchema)
+	if err != nil {
+		api.writeError(w, r, http.StatusBadRequest, "invalid_params")
+		return
+	}
+
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	templates := postgres.NewRunTemplateStore(tx)
+	version, err := templates.NextVersion(r.Context(), projectID, req.Name)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	now := time.Now().UTC()
+	out := runTemplate{
+		TemplateID:   uuid.NewString(),
+		ProjectID:    projectID,
+		Name:         req.Name,
+		Version:      version,
+		Description:  req.Description,
+		Template:     req.Template,
+		ParamsSchema: req.ParamsSchema,
+		CreatedAt:    now,
+		CreatedBy:    identity.Subject,
+	}
+	integrity, err := integritySHA256(struct {
+		TemplateID   string          `json:"template_id"`
+		ProjectID    string          `json:"project_id"`
+		Name         string          `json:"name"`
+		Version      int             `json:"version"`
+		Description  string          `json:"description"`
+		Template     json.RawMessage `json:"template"`
+		ParamsSchema json.RawMessage `json:"params_schema"`
+		CreatedAt    time.Time       `json:"created_at"`
+		CreatedBy    string          `json:"created_by"`
+	}{out.TemplateID, projectID, out.Name, out.Version, out.Description, templateJSON, schemaJSON, now, identity.Subject})
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	err = templates.Create(r.Context(), postgres.RunTemplateRecord{
+		TemplateID:      out.TemplateID,
+		ProjectID:       projectID,
+		Name:            out.Name,
+		Version:         out.Version,
+		Description:     out.Description,
+		Template:        templateJSON,
+		ParamsSchema:    schemaJSON,
+		CreatedAt:       now,
+		CreatedBy:       identity.Subject,
+		IntegritySHA256: integrity,
+	})
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	err = auditlog.Enqueue(r.Context(), tx, auditlog.Event{
+		OccurredAt:   now,
+		Actor:        identity.Subject,
+		Action:       "run_template.create",
+		ResourceType: "run_template",
+		ResourceID:   out.TemplateID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload: map[string]any{
+			"service":       "experiments",
+			"template_id":   out.TemplateID,
+			"name":          out.Name,
+			"version":       out.Version,
+			"template":      json.RawMessage(templateJSON),
+			"params_schema": json.RawMessage(schemaJSON),
+		},
+	})
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "audit_failed")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	w.Header().Set("Location", "/run-templates/"+out.TemplateID)
+	api.writeJSON(w, http.StatusCreated, out)
+}
+
+// runTemplateFromRecord decodes the stored template and parameter schema.
+func runTemplateFromRecord(record postgres.RunTemplateRecord) (runTemplate, error) {
+	out := runTemplate{
+		TemplateID:  record.TemplateID,
+		ProjectID:   record.ProjectID,
+		Name:        record.Name,
+		Version:     record.Version,
+		Description: record.Description,
+		CreatedAt:   record.CreatedAt,
+		CreatedBy:   record.CreatedBy,
+	}
+	if err := json.Unmarshal(record.Template, &out.Template); err != nil {
+		return runTemplate{}, err
+	}
+	if err := json.Unmarshal(record.ParamsSchema, &out.ParamsSchema); err != nil {
+		return runTemplate{}, err
+	}
+	if out.ParamsSchema == nil {
+		out.ParamsSchema = map[string]runTemplateParam{}
+	}
+	return out, nil
+}
+
+func (api *experimentsAPI) handleListRunTemplates(w http.ResponseWriter, r *http.Request) {
+	_, projectID, ok := api.scopedProject(w, r)
+	if !ok {
+		return
+	}
+	query := r.URL.Query()
+	latestOnly := false
+	if raw := strings.TrimSpace(query.Get("latest")); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			api.writeError(w, r, http.StatusBadRequest, "invalid_latest")
+			return
+		}
+		latestOnly = parsed
+	}
+	limit := httpapi.Limit(r, 100, 500)
+
+	records, err := postgres.NewRunTemplateStore(api.db).List(r.Context(), postgres.RunTemplateFilter{
+		ProjectID:  projectID,
+		Name:       query.Get("name"),
+		LatestOnly: latestOnly,
+		Limit:      limit,
+	})
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	out := make([]runTemplate, 0, len(records))
+	for _, record := range records {
+		template, err := runTemplateFromRecord(record)
+		if err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+		out = append(out, template)
+	}
+	api.writeJSON(w, http.StatusOK, map[string]any{
+		"project_id": projectID,
+		"templates":  out,
+	})
+}
+
+func (api *experimentsAPI) handleGetRunTemplate(w http.ResponseWriter, r *http.Request) {
+	template, ok := api.loadRunTemplate(w, r)
+	if !ok {
+		return
+	}
+	api.writeJSON(w, http.StatusOK, template)
+}
+
+func (api *experimentsAPI) loadRunTemplate(w http.ResponseWriter, r *http.Request) (runTemplate, bool) {
+	templateID := strings.TrimSpace(r.PathValue("template_id"))
+	if templateID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "template_id_required")
+		return runTemplate{}, false
+	}
+	record, err := postgres.NewRunTemplateStore(api.db).Get(r.Context(), templateID)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return runTemplate{}, false
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return runTemplate{}, false
+	}
+	template, err := runTemplateFromRecord(record)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return runTemplate{}, false
+	}
+	return template, true
+}
+
+// handleInstantiateRunTemplate validates the caller's params against the
+// template schema, resolves the template's dataset selector and creates the
+// run through the regular run creation handler, so quality gates, dataset
+// policies and fencing apply as for POST /experiments/{experiment_id}/runs.
+// A refused run is answered with that handler's response.
+func (api *experimentsAPI) handleInstantiateRunTemplate(w http.ResponseWriter, r *http.Request) {
+	identity, ok := auth.IdentityFromContext(r.Context())
+	if !ok || strings.TrimSpace(identity.Subject) == "" {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	template, ok := api.loadRunTemplate(w, r)
+	if !ok {
+		return
+	}
+
+	var req instantiateRunTemplateRequest
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
+		return
+	}
+	req.ExperimentID = strings.TrimSpace(req.ExperimentID)
+	params, errs := resolveRunTemplateParams(template.Template.Params, template.ParamsSchema, req.Params)
+	if errs.Required("/experiment_id", req.ExperimentID) {
+		errs.UUID("/experiment_id", req.ExperimentID)
+	}
+	if errs.Write(w, r) {
+		return
+	}
+	if !api.requireActiveProject(w, r, template.ProjectID) {
+		return
+	}
+	if projectID, err := projectIDForExperiment(r.Context(), api.db, req.ExperimentID); err != nil || projectID != template.ProjectID {
+		api.writeError(w, r, http.StatusNotFound, "not_found")
+		return
+	}
+
+	versionID, err := api.resolveScheduleDatasetVersion(r.Context(), template.Template)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if versionID == "" && template.Template.DatasetSelector != "" {
+		api.writeError(w, r, http.StatusConflict, "no_eligible_dataset_version")
+		return
+	}
+
+	requestID := r.Header.Get("X-Request-Id")
+	rec, err := api.createExperimentRunInProcess(r.Context(), identity, req.ExperimentID, requestID, r.UserAgent(), createExperimentRunRequest{
+		DatasetVersionID: versionID,
+		Status:           "pending",
+		GitRepo:          template.Template.GitRepo,
+		GitCommit:        template.Template.GitCommit,
+		GitRef:           template.Template.GitRef,
+		Params:           params,
+	})
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if !rec.created() {
+		w.Header().Set("Content-Type", rec.header.Get("Content-Type"))
+		w.WriteHeader(rec.status)
+		_, _ = w.Write(rec.buf.Bytes())
+		return
+	}
+	var created struct {
+		RunID string `json:"run_id"`
+	}
+	if err := json.Unmarshal(rec.buf.Bytes(), &created); err != nil || created.RunID == "" {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	now := time.Now().UTC()
+	err = lineageevent.Enqueue(r.Context(), tx, lineageevent.Event{
+		OccurredAt:  now,
+		Actor:       identity.Subject,
+		RequestID:   requestID,
+		SubjectType: "run_template",
+		SubjectID:   template.TemplateID,
+		Predicate:   "instantiated_as",
+		ObjectType:  "experiment_run",
+		ObjectID:    created.RunID,
+		Metadata: map[string]any{
+			"name":               template.Name,
+			"version":            template.Version,
+			"experiment_id":      req.ExperimentID,
+			"dataset_version_id": versionID,
+			"image_ref":          template.Template.ImageRef,
+		},
+	})
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	payload := map[string]any{
+		"service":       "experiments",
+		"template_id":   template.TemplateID,
+		"name":          template.Name,
+		"version":       template.Version,
+		"experiment_id": req.ExperimentID,
+		"run_id":        created.RunID,
+		"params":        params,
+	}
+	if versionID != "" {
+		payload["dataset_version_id"] = versionID
+	}
+	err = auditlog.Enqueue(r.Context(), tx, auditlog.Event{
+		OccurredAt:   now,
+		Actor:        identity.Subject,
+		Action:       "run_template.instantiate",
+		ResourceType: "run_template",
+		ResourceID:   template.TemplateID,
+		RequestID:    requestID,
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload:      payload,
+	})
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "audit_failed")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	w.Header().Set("Location", "/experiment-runs/"+created.RunID)
+	api.writeJSON(w, http.StatusCreated, runTemplateInstantiation{
+		TemplateID:       template.TemplateID,
+		Name:             template.Name,
+		Version:          template.Version,
+		ExperimentID:     req.ExperimentID,
+		DatasetVersionID: versionID,
+		ImageRef:         template.Template.ImageRef,
+		Resources:        template.Template.Resources,
+		Params:           params,
+		Run:              json.RawMessage(rec.buf.Bytes()),
+	})
+}
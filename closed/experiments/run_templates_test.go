@@ -0,0 +1,88 @@
+package experiments
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidateRunTemplateRequest(t *testing.T) {
+	one, ten := 1.0, 10.0
+
+	cases := []struct {
+		name   string
+		req    createRunTemplateRequest
+		fields []string
+	}{
+		{name: "minimal", req: createRunTemplateRequest{Name: "train-resnet"}},
+		{name: "schema", req: createRunTemplateRequest{
+			Name:     "train-resnet",
+			Template: runScheduleTemplate{ImageRef: "ghcr.io/acme/train:1.2", Params: map[string]any{"arch": "resnet50"}},
+			ParamsSchema: map[string]runTemplateParam{
+				"epochs": {Type: "Integer", Default: 5.0, Minimum: &one, Maximum: &ten},
+				"opt":    {Type: "string", Enum: []any{"sgd", "adam"}, Required: true},
+			},
+		}},
+		{name: "missing name", req: createRunTemplateRequest{}, fields: []string{"name"}},
+		{name: "bad declarations", req: createRunTemplateRequest{
+			Name:     "t",
+			Template: runScheduleTemplate{Params: map[string]any{"arch": "resnet50"}},
+			ParamsSchema: map[string]runTemplateParam{
+				"arch":   {Type: "string"},
+				"epochs": {Type: "integer", Default: 2.5},
+				"flag":   {Type: "boolean", Minimum: &one},
+				"lr":     {Type: "float"},
+				"opt":    {Type: "string", Enum: []any{"sgd", 1.0}, Default: "rmsprop"},
+				"steps":  {Type: "number", Minimum: &ten, Maximum: &one},
+			},
+		}, fields: []string{"params_schema.arch", "params_schema.epochs.default", "params_schema.flag", "params_schema.lr.type", "params_schema.opt.enum.1", "params_schema.opt.default", "params_schema.steps.minimum"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := tc.req
+			got := validateRunTemplateRequest(&req).Fields()
+			if len(got) != len(tc.fields) {
+				t.Fatalf("fields = %+v, want %v", got, tc.fields)
+			}
+			for i, field := range tc.fields {
+				if got[i].Field != field {
+					t.Fatalf("fields = %+v, want %v", got, tc.fields)
+				}
+			}
+		})
+	}
+}
+
+func TestResolveRunTemplateParams(t *testing.T) {
+	one := 1.0
+	fixed := map[string]any{"arch": "resnet50"}
+	schema := map[string]runTemplateParam{
+		"epochs": {Type: runTemplateParamInteger, Default: 5.0, Minimum: &one},
+		"opt":    {Type: runTemplateParamString, Enum: []any{"sgd", "adam"}, Required: true},
+		"seed":   {Type: runTemplateParamInteger},
+	}
+
+	params, errs := resolveRunTemplateParams(fixed, schema, map[string]any{"opt": "adam"})
+	if !errs.Empty() {
+		t.Fatalf("unexpected errors %+v", errs.Fields())
+	}
+	if want := map[string]any{"arch": "resnet50", "epochs": 5.0, "opt": "adam"}; !reflect.DeepEqual(params, want) {
+		t.Fatalf("params = %v, want %v", params, want)
+	}
+
+	_, errs = resolveRunTemplateParams(fixed, schema, map[string]any{"arch": "vit", "epochs": 0.0, "seed": "7"})
+	want := []struct{ field, code string }{
+		{"params.arch", "unknown_field"},
+		{"params.epochs", "out_of_range"},
+		{"params.opt", "required"},
+		{"params.seed", "invalid_type"},
+	}
+	got := errs.Fields()
+	if len(got) != len(want) {
+		t.Fatalf("fields = %+v", got)
+	}
+	for i, w := range want {
+		if got[i].Field != w.field || got[i].Code != w.code {
+			t.Fatalf("field %d = %+v, want %+v", i, got[i], w)
+		}
+	}
+}
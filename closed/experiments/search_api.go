@@ -0,0 +1,661 @@
+package experiments
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/domain"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	"github.com/animus-labs/animus-go/closed/internal/repo/postgres"
+	"github.com/google/uuid"
+)
+
+const maxSearchTags = 20
+
+// searchQuery is the filter set accepted by GET /search and stored by saved
+// searches. Tags must all be attached; name matches a case-insensitive
+// substring (the experiment name for runs); the date range applies to
+// created_at, or started_at for runs.
+type searchQuery struct {
+	ResourceType  string     `json:"resource_type"`
+	Tags          []string   `json:"tags,omitempty"`
+	Name          string     `json:"name,omitempty"`
+	Status        string     `json:"status,omitempty"`
+	CreatedAfter  *time.Time `json:"created_after,omitempty"`
+	CreatedBefore *time.Time `json:"created_before,omitempty"`
+	Metric        string     `json:"metric,omitempty"`
+	MetricMin     *float64   `json:"metric_min,omitempty"`
+	MetricMax     *float64   `json:"metric_max,omitempty"`
+}
+
+type searchResult struct {
+	ResourceType string    `json:"resource_type"`
+	ResourceID   string    `json:"resource_id"`
+	Name         string    `json:"name"`
+	Status       string    `json:"status,omitempty"`
+	ExperimentID string    `json:"experiment_id,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	MetricValue  *float64  `json:"metric_value,omitempty"`
+	Tags         []string  `json:"tags"`
+}
+
+type searchResponse struct {
+	Query   searchQuery    `json:"query"`
+	Results []searchResult `json:"results"`
+}
+
+type savedSearch struct {
+	SearchID  string      `json:"search_id"`
+	Name      string      `json:"name"`
+	Query     searchQuery `json:"query"`
+	CreatedAt time.Time   `json:"created_at"`
+	CreatedBy string      `json:"created_by"`
+}
+
+type savedSearchRequest struct {
+	Name  string      `json:"name"`
+	Query searchQuery `json:"query"`
+}
+
+// parseSearchParams reads a searchQuery from the URL; tags may repeat or be
+// comma separated. The returned string is the error code for a bad value.
+func parseSearchParams(r *http.Request) (searchQuery, string) {
+	values := r.URL.Query()
+	q := searchQuery{
+		ResourceType: values.Get("resource_type"),
+		Name:         values.Get("name"),
+		Status:       values.Get("status"),
+		Metric:       values.Get("metric"),
+	}
+	for _, raw := range values["tag"] {
+		for _, name := range strings.Split(raw, ",") {
+			if strings.TrimSpace(name) != "" {
+				q.Tags = append(q.Tags, name)
+			}
+		}
+	}
+	for _, bound := range []struct {
+		key    string
+		target **time.Time
+	}{{"created_after", &q.CreatedAfter}, {"created_before", &q.CreatedBefore}} {
+		parsed, ok, err := parseTimeQuery(r, bound.key)
+		if err != nil {
+			return searchQuery{}, "invalid_" + bound.key
+		}
+		if ok {
+			*bound.target = &parsed
+		}
+	}
+	for _, bound := range []struct {
+		key    string
+		target **float64
+	}{{"metric_min", &q.MetricMin}, {"metric_max", &q.MetricMax}} {
+		raw := strings.TrimSpace(values.Get(bound.key))
+		if raw == "" {
+			continue
+		}
+		parsed, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return searchQuery{}, "invalid_" + bound.key
+		}
+		*bound.target = &parsed
+	}
+	return q, ""
+}
+
+// normalizeSearchQuery validates q for its resource type and returns the
+// error code for the first invalid filter.
+func normalizeSearchQuery(q searchQuery) (searchQuery, string) {
+	resourceType, ok := normalizeTaggedType(q.ResourceType)
+	if !ok {
+		if strings.TrimSpace(q.ResourceType) == "" {
+			return searchQuery{}, "resource_type_required"
+		}
+		return searchQuery{}, "invalid_resource_type"
+	}
+	q.ResourceType = resourceType
+
+	tags := make([]string, 0, len(q.Tags))
+	seen := make(map[string]struct{}, len(q.Tags))
+	for _, raw := range q.Tags {
+		name, ok := normalizeTagName(raw)
+		if !ok {
+			return searchQuery{}, "invalid_tag_name"
+		}
+		if _, dup := seen[name]; dup {
+			continue
+		}
+		seen[name] = struct{}{}
+		tags = append(tags, name)
+	}
+	if len(tags) > maxSearchTags {
+		return searchQuery{}, "too_many_tags"
+	}
+	q.Tags = nil
+	if len(tags) > 0 {
+		q.Tags = tags
+	}
+
+	q.Name = strings.TrimSpace(q.Name)
+	q.Status = strings.ToLower(strings.TrimSpace(q.Status))
+	if q.Status != "" {
+		switch q.ResourceType {
+		case taggedRun:
+			if _, ok := allowedRunStatuses[q.Status]; !ok {
+				return searchQuery{}, "invalid_status"
+			}
+		case taggedModel:
+			if !domain.ModelStatus(q.Status).Valid() {
+				return searchQuery{}, "invalid_status"
+			}
+		default:
+			return searchQuery{}, "unsupported_filter"
+		}
+	}
+
+	if q.CreatedAfter != nil {
+		after := q.CreatedAfter.UTC()
+		q.CreatedAfter = &after
+	}
+	if q.CreatedBefore != nil {
+		before := q.CreatedBefore.UTC()
+		q.CreatedBefore = &before
+	}
+	if q.CreatedAfter != nil && q.CreatedBefore != nil && !q.CreatedAfter.Before(*q.CreatedBefore) {
+		return searchQuery{}, "invalid_date_range"
+	}
+
+	q.Metric = strings.TrimSpace(q.Metric)
+	if q.Metric == "" && (q.MetricMin != nil || q.MetricMax != nil) {
+		return searchQuery{}, "metric_required"
+	}
+	if q.Metric != "" && q.ResourceType != taggedRun {
+		return searchQuery{}, "unsupported_filter"
+	}
+	if q.MetricMin != nil && q.MetricMax != nil && *q.MetricMin > *q.MetricMax {
+		return searchQuery{}, "invalid_metric_range"
+	}
+	return q, ""
+}
+
+// buildSearchSQL renders q as one query over the resource's table. Columns
+// are id, name, status, experiment_id, date and metric value.
+func buildSearchSQL(projectID string, q searchQuery, limit int) (string, []any, error) {
+	args := []any{projectID}
+	arg := func(value any) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	var (
+		selectSQL string
+		idColumn  string
+		nameExpr  string
+		dateExpr  string
+		status    string
+	)
+	clauses := make([]string, 0, 8)
+	switch q.ResourceType {
+	case taggedExperiment:
+		selectSQL = `SELECT x.experiment_id, x.name, NULL::text, NULL::text, x.created_at, NULL::double precision
+		 FROM experiments x`
+		idColumn, nameExpr, dateExpr = "x.experiment_id", "x.name", "x.created_at"
+		clauses = append(clauses, "x.project_id = $1")
+	case taggedDataset:
+		selectSQL = `SELECT d.dataset_id, d.name, NULL::text, NULL::text, d.created_at, NULL::double precision
+		 FROM datasets d`
+		idColumn, nameExpr, dateExpr = "d.dataset_id", "d.name", "d.created_at"
+		clauses = append(clauses, "d.project_id = $1")
+	case taggedModel:
+		selectSQL = `SELECT m.model_id, m.name, m.status, NULL::text, m.created_at, NULL::double precision
+		 FROM models m`
+		idColumn, nameExpr, dateExpr, status = "m.model_id", "m.name", "m.created_at", "m.status"
+		clauses = append(clauses, "m.project_id = $1")
+	case taggedRun:
+		valueExpr := "NULL::double precision"
+		metricJoin := ""
+		if q.Metric != "" {
+			metric := arg(q.Metric)
+			valueExpr = `COALESCE(mv.value, CASE WHEN jsonb_typeof(r.metrics -> ` + metric + `) = 'number' THEN (r.metrics ->> ` + metric + `)::double precision END)`
+			metricJoin = `
+		 LEFT JOIN LATERAL (
+			SELECT value
+			FROM experiment_run_metric_samples
+			WHERE run_id = r.run_id AND name = ` + metric + `
+			ORDER BY step DESC
+			LIMIT 1
+		 ) mv ON true`
+		}
+		selectSQL = `SELECT r.run_id, x.name, COALESCE(s.status, r.status), r.experiment_id, r.started_at, ` + valueExpr + `
+		 FROM experiment_runs r
+		 JOIN experiments x ON x.experiment_id = r.experiment_id
+		 LEFT JOIN LATERAL (
+			SELECT status
+			FROM experiment_run_state_events
+			WHERE run_id = r.run_id
+			ORDER BY observed_at DESC
+			LIMIT 1
+		 ) s ON true` + metricJoin
+		idColumn, nameExpr, dateExpr, status = "r.run_id", "x.name", "r.started_at", "COALESCE(s.status, r.status)"
+		clauses = append(clauses, "r.project_id = $1")
+		if q.Metric != "" {
+			clauses = append(clauses, valueExpr+" IS NOT NULL")
+			if q.MetricMin != nil {
+				clauses = append(clauses, valueExpr+" >= "+arg(*q.MetricMin))
+			}
+			if q.MetricMax != nil {
+				clauses = append(clauses, valueExpr+" <= "+arg(*q.MetricMax))
+			}
+		}
+	default:
+		return "", nil, fmt.Errorf("unsupported resource type %q", q.ResourceType)
+	}
+
+	if len(q.Tags) > 0 {
+		tagsJSON, err := json.Marshal(q.Tags)
+		if err != nil {
+			return "", nil, err
+		}
+		clauses = append(clauses, `(SELECT count(*)
+			 FROM resource_tags rt
+			 JOIN tags t ON t.tag_id = rt.tag_id
+			 WHERE rt.project_id = $1
+			   AND rt.resource_type = `+arg(q.ResourceType)+`
+			   AND rt.resource_id = `+idColumn+`
+			   AND t.name IN (SELECT jsonb_array_elements_text(`+arg(string(tagsJSON))+`::jsonb))) = `+arg(len(q.Tags)))
+	}
+	if q.Name != "" {
+		clauses = append(clauses, "strpos(lower("+nameExpr+"), lower("+arg(q.Name)+")) > 0")
+	}
+	if q.Status != "" {
+		clauses = append(clauses, status+" = "+arg(q.Status))
+	}
+	if q.CreatedAfter != nil {
+		clauses = append(clauses, dateExpr+" >= "+arg(*q.CreatedAfter))
+	}
+	if q.CreatedBefore != nil {
+		clauses = append(clauses, dateExpr+" < "+arg(*q.CreatedBefore))
+	}
+
+	query := selectSQL + "\n\t\t WHERE " + strings.Join(clauses, "\n\t\t   AND ") +
+		"\n\t\t ORDER BY " + dateExpr + " DESC, " + idColumn +
+		"\n\t\t LIMIT " + arg(limit)
+	return query, args, nil
+}
+
+func (api *experimentsAPI) handleSearch(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := auth.ProjectIDFromContext(r.Context())
+	if !ok || strings.TrimSpace(projectID) == "" {
+		api.writeError(w, r, http.StatusBadRequest, "project_id_required")
+		return
+	}
+	q, code := parseSearchParams(r)
+	if code == "" {
+		q, code = normalizeSearchQuery(q)
+	}
+	if code != "" {
+		api.writeError(w, r, http.StatusBadRequest, code)
+		return
+	}
+	api.writeSearchResults(w, r, projectID, q)
+}
+
+func (api *experimentsAPI) writeSearchResults(w http.ResponseWriter, r *http.Request, projectID string, q searchQuery) {
+	results, err := runSearch(r.Context(), api.db, projectID, q, httpapi.Limit(r, 100, 500))
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	api.writeJSON(w, http.StatusOK, searchResponse{Query: q, Results: results})
+}
+
+func runSearch(ctx context.Context, db postgres.DB, projectID string, q searchQuery, limit int) ([]searchResult, error) {
+	query, args, err := buildSearchSQL(projectID, q, limit)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := make([]searchResult, 0)
+	for rows.Next() {
+		var (
+			item         searchResult
+			status       sql.NullString
+			experimentID sql.NullString
+			value        sql.NullFloat64
+		)
+		if err := rows.Scan(&item.ResourceID, &item.Name, &status, &experimentID, &item.CreatedAt, &value); err != nil {
+			return nil, err
+		}
+		item.ResourceType = q.ResourceType
+		item.Status = status.String
+		item.ExperimentID = experimentID.String
+		item.CreatedAt = item.CreatedAt.UTC()
+		if value.Valid {
+			v := value.Float64
+			item.MetricValue = &v
+		}
+		item.Tags = []string{}
+		results = append(results, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := attachResultTags(ctx, db, projectID, q.ResourceType, results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// attachResultTags loads every result's tag names with a single query.
+func attachResultTags(ctx context.Context, db postgres.DB, projectID, resourceType string, results []searchResult) error {
+	if len(results) == 0 {
+		return nil
+	}
+	index := make(map[string]int, len(results))
+	ids := make([]string, 0, len(results))
+	for i, item := range results {
+		index[item.ResourceID] = i
+		ids = append(ids, item.ResourceID)
+	}
+	idsJSON, err := json.Marshal(ids)
+	if err != nil {
+		return err
+	}
+	rows, err := db.QueryContext(
+		ctx,
+		`SELECT rt.resource_id, t.name
+		 FROM resource_tags rt
+		 JOIN tags t ON t.tag_id = rt.tag_id
+		 WHERE rt.project_id = $1
+		   AND rt.resource_type = $2
+		   AND rt.resource_id IN (SELECT jsonb_array_elements_text($3::jsonb))
+		 ORDER BY t.name`,
+		projectID,
+		resourceType,
+		string(idsJSON),
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var resourceID, name string
+		if err := rows.Scan(&resourceID, &name); err != nil {
+			return err
+		}
+		if i, ok := index[resourceID]; ok {
+			results[i].Tags = append(results[i].Tags, name)
+		}
+	}
+	return rows.Err()
+}
+
+func (api *experimentsAPI) handleCreateSavedSearch(w http.ResponseWriter, r *http.Request) {
+	identity, ok := auth.IdentityFromContext(r.Context())
+	if !ok || strings.TrimSpace(identity.Subject) == "" {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	projectID, ok := auth.ProjectIDFromContext(r.Context())
+	if !ok || strings.TrimSpace(projectID) == "" {
+		api.writeError(w, r, http.StatusBadRequest, "project_id_required")
+		return
+	}
+
+	var req savedSearchRequest
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		api.writeError(w, r, http.StatusBadRequest, "name_required")
+		return
+	}
+	q, code := normalizeSearchQuery(req.Query)
+	if code != "" {
+		api.writeError(w, r, http.StatusBadRequest, code)
+		return
+	}
+	queryJSON, err := json.Marshal(q)
+	if err != nil {
+		api.writeError(w, r, http.StatusBadRequest, "invalid_query")
+		return
+	}
+
+	created := savedSearch{
+		SearchID:  uuid.NewString(),
+		Name:      name,
+		Query:     q,
+		CreatedAt: time.Now().UTC(),
+		CreatedBy: identity.Subject,
+	}
+
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	_, err = tx.ExecContext(
+		r.Context(),
+		`INSERT INTO saved_searches (search_id, project_id, name, resource_type, query, created_at, created_by)
+		 VALUES ($1,$2,$3,$4,$5,$6,$7)`,
+		created.SearchID,
+		projectID,
+		created.Name,
+		q.ResourceType,
+		queryJSON,
+		created.CreatedAt,
+		created.CreatedBy,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			api.writeError(w, r, http.StatusConflict, "saved_search_name_exists")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	err = auditlog.Enqueue(r.Context(), tx, auditlog.Event{
+		OccurredAt:   created.CreatedAt,
+		Actor:        identity.Subject,
+		Action:       "saved_search.created",
+		ResourceType: "saved_search",
+		ResourceID:   created.SearchID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload: map[string]any{
+			"service":    "experiments",
+			"project_id": projectID,
+			"search_id":  created.SearchID,
+			"name":       created.Name,
+			"query":      q,
+		},
+	})
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "audit_failed")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	w.Header().Set("Location", "/saved-searches/"+created.SearchID)
+	api.writeJSON(w, http.StatusCreated, created)
+}
+
+func (api *experimentsAPI) handleListSavedSearches(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := auth.ProjectIDFromContext(r.Context())
+	if !ok || strings.TrimSpace(projectID) == "" {
+		api.writeError(w, r, http.StatusBadRequest, "project_id_required")
+		return
+	}
+	limit := httpapi.Limit(r, 100, 500)
+
+	rows, err := api.db.QueryContext(
+		r.Context(),
+		`SELECT search_id, name, query, created_at, created_by
+		 FROM saved_searches
+		 WHERE project_id = $1
+		 ORDER BY name
+		 LIMIT $2`,
+		projectID,
+		limit,
+	)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer rows.Close()
+
+	out := make([]savedSearch, 0)
+	for rows.Next() {
+		item, err := scanSavedSearch(rows)
+		if err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+		out = append(out, item)
+	}
+	if err := rows.Err(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	api.writeJSON(w, http.StatusOK, map[string]any{"saved_searches": out})
+}
+
+// savedSearchFromPath loads the saved search named in the path, writing the
+// error response when it cannot.
+func (api *experimentsAPI) savedSearchFromPath(w http.ResponseWriter, r *http.Request) (string, savedSearch, bool) {
+	projectID, ok := auth.ProjectIDFromContext(r.Context())
+	if !ok || strings.TrimSpace(projectID) == "" {
+		api.writeError(w, r, http.StatusBadRequest, "project_id_required")
+		return "", savedSearch{}, false
+	}
+	searchID := strings.TrimSpace(r.PathValue("search_id"))
+	if searchID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "search_id_required")
+		return "", savedSearch{}, false
+	}
+	item, err := scanSavedSearch(api.db.QueryRowContext(
+		r.Context(),
+		`SELECT search_id, name, query, created_at, created_by
+		 FROM saved_searches
+		 WHERE project_id = $1 AND search_id = $2`,
+		projectID,
+		searchID,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return "", savedSearch{}, false
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return "", savedSearch{}, false
+	}
+	return projectID, item, true
+}
+
+func (api *experimentsAPI) handleGetSavedSearch(w http.ResponseWriter, r *http.Request) {
+	_, item, ok := api.savedSearchFromPath(w, r)
+	if !ok {
+		return
+	}
+	api.writeJSON(w, http.StatusOK, item)
+}
+
+// handleRunSavedSearch re-runs the stored query; limit comes from the
+// request, not the saved search.
+func (api *experimentsAPI) handleRunSavedSearch(w http.ResponseWriter, r *http.Request) {
+	projectID, item, ok := api.savedSearchFromPath(w, r)
+	if !ok {
+		return
+	}
+	api.writeSearchResults(w, r, projectID, item.Query)
+}
+
+func (api *experimentsAPI) handleDeleteSavedSearch(w http.ResponseWriter, r *http.Request) {
+	identity, ok := auth.IdentityFromContext(r.Context())
+	if !ok || strings.TrimSpace(identity.Subject) == "" {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	projectID, item, ok := api.savedSearchFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	result, err := tx.ExecContext(r.Context(), `DELETE FROM saved_searches WHERE project_id = $1 AND search_id = $2`, projectID, item.SearchID)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		api.writeError(w, r, http.StatusNotFound, "not_found")
+		return
+	}
+	err = auditlog.Enqueue(r.Context(), tx, auditlog.Event{
+		OccurredAt:   time.Now().UTC(),
+		Actor:        identity.Subject,
+		Action:       "saved_search.deleted",
+		ResourceType: "saved_search",
+		ResourceID:   item.SearchID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload: map[string]any{
+			"service":    "experiments",
+			"project_id": projectID,
+			"search_id":  item.SearchID,
+			"name":       item.Name,
+		},
+	})
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "audit_failed")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func scanSavedSearch(row rowScanner) (savedSearch, error) {
+	var (
+		item      savedSearch
+		queryJSON []byte
+	)
+	if err := row.Scan(&item.SearchID, &item.Name, &queryJSON, &item.CreatedAt, &item.CreatedBy); err != nil {
+		return savedSearch{}, err
+	}
+	if err := json.Unmarshal(queryJSON, &item.Query); err != nil {
+		return savedSearch{}, err
+	}
+	return item, nil
+}
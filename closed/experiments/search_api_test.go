@@ -0,0 +1,105 @@
+package experiments
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseSearchParams(t *testing.T) {
+	req := httptest.NewRequest("GET", "/search?resource_type=experiment_run&tag=a,b&tag=c&name=resnet&status=Succeeded&metric=acc&metric_min=0.9&created_after=2026-01-01T00:00:00Z", nil)
+	q, code := parseSearchParams(req)
+	if code != "" {
+		t.Fatalf("unexpected code %q", code)
+	}
+	q, code = normalizeSearchQuery(q)
+	if code != "" {
+		t.Fatalf("unexpected code %q", code)
+	}
+	if !reflect.DeepEqual(q.Tags, []string{"a", "b", "c"}) || q.Status != "succeeded" || q.MetricMin == nil || *q.MetricMin != 0.9 || q.CreatedAfter == nil {
+		t.Fatalf("unexpected query: %+v", q)
+	}
+
+	req = httptest.NewRequest("GET", "/search?resource_type=experiment&metric_max=abc", nil)
+	if _, code := parseSearchParams(req); code != "invalid_metric_max" {
+		t.Fatalf("expected invalid_metric_max, got %q", code)
+	}
+}
+
+func TestNormalizeSearchQueryValidation(t *testing.T) {
+	value := func(v float64) *float64 { return &v }
+	cases := []struct {
+		name string
+		q    searchQuery
+		want string
+	}{
+		{name: "missing type", q: searchQuery{}, want: "resource_type_required"},
+		{name: "bad type", q: searchQuery{ResourceType: "artifact"}, want: "invalid_resource_type"},
+		{name: "bad tag", q: searchQuery{ResourceType: "experiment", Tags: []string{"a b"}}, want: "invalid_tag_name"},
+		{name: "status on experiment", q: searchQuery{ResourceType: "experiment", Status: "running"}, want: "unsupported_filter"},
+		{name: "bad run status", q: searchQuery{ResourceType: "experiment_run", Status: "done"}, want: "invalid_status"},
+		{name: "bad model status", q: searchQuery{ResourceType: "model", Status: "running"}, want: "invalid_status"},
+		{name: "metric on dataset", q: searchQuery{ResourceType: "dataset", Metric: "acc"}, want: "unsupported_filter"},
+		{name: "threshold without metric", q: searchQuery{ResourceType: "experiment_run", MetricMin: value(1)}, want: "metric_required"},
+		{name: "inverted metric range", q: searchQuery{ResourceType: "experiment_run", Metric: "acc", MetricMin: value(2), MetricMax: value(1)}, want: "invalid_metric_range"},
+		{name: "ok", q: searchQuery{ResourceType: "model", Status: "approved", Tags: []string{"Prod", "prod"}}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, code := normalizeSearchQuery(tc.q)
+			if code != tc.want {
+				t.Fatalf("expected %q, got %q", tc.want, code)
+			}
+			if code == "" && !reflect.DeepEqual(got.Tags, []string{"prod"}) {
+				t.Fatalf("expected deduplicated tags, got %v", got.Tags)
+			}
+		})
+	}
+}
+
+func TestBuildSearchSQLRuns(t *testing.T) {
+	value := func(v float64) *float64 { return &v }
+	q := searchQuery{
+		ResourceType: taggedRun,
+		Tags:         []string{"baseline", "gpu"},
+		Name:         "resnet",
+		Status:       "succeeded",
+		Metric:       "accuracy",
+		MetricMin:    value(0.9),
+	}
+	query, args, err := buildSearchSQL("project-1", q, 25)
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	for _, fragment := range []string{
+		"FROM experiment_runs r",
+		"experiment_run_metric_samples",
+		"rt.resource_id = r.run_id",
+		"strpos(lower(x.name), lower($7)) > 0",
+		"COALESCE(s.status, r.status) = $8",
+		"ORDER BY r.started_at DESC, r.run_id",
+		"LIMIT $9",
+	} {
+		if !strings.Contains(query, fragment) {
+			t.Fatalf("query missing %q:\n%s", fragment, query)
+		}
+	}
+	want := []any{"project-1", "accuracy", 0.9, taggedRun, `["baseline","gpu"]`, 2, "resnet", "succeeded", 25}
+	if !reflect.DeepEqual(args, want) {
+		t.Fatalf("unexpected args: %#v", args)
+	}
+}
+
+func TestBuildSearchSQLExperiments(t *testing.T) {
+	query, args, err := buildSearchSQL("project-1", searchQuery{ResourceType: taggedExperiment}, 10)
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if strings.Contains(query, "resource_tags") || !strings.Contains(query, "FROM experiments x") {
+		t.Fatalf("unexpected query:\n%s", query)
+	}
+	if !reflect.DeepEqual(args, []any{"project-1", 10}) {
+		t.Fatalf("unexpected args: %#v", args)
+	}
+}
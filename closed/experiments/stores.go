@@ -0,0 +1,37 @@
+package experiments
+
+import (
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+	repopg "github.com/animus-labs/animus-go/closed/internal/repo/postgres"
+)
+
+// The store accessors take the handle to run on: api.db, or the transaction
+// a handler writes its audit and lineage events in.
+
+func (api *experimentsAPI) runStore(db repopg.DB) repo.RunStore {
+	if api.runStoreOverride != nil {
+		return api.runStoreOverride
+	}
+	return repopg.NewRunStore(db).WithReads(api.reads)
+}
+
+func (api *experimentsAPI) metricStore(db repopg.DB) repo.MetricStore {
+	if api.metricStoreOverride != nil {
+		return api.metricStoreOverride
+	}
+	return repopg.NewMetricStore(db)
+}
+
+func (api *experimentsAPI) policyStore(db repopg.DB) repo.PolicyStore {
+	if api.policyStoreOverride != nil {
+		return api.policyStoreOverride
+	}
+	return repopg.NewPolicyStore(db)
+}
+
+func (api *experimentsAPI) evidenceStore(db repopg.DB) repo.EvidenceStore {
+	if api.evidenceStoreOverride != nil {
+		return api.evidenceStoreOverride
+	}
+	return repopg.NewEvidenceStore(db)
+}
@@ -0,0 +1,224 @@
+package experiments
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+)
+
+type stubPolicyStore struct {
+	repo.PolicyStore
+	policies       map[string]repo.PolicyRecord
+	decisions      []repo.PolicyDecisionRecord
+	decisionFilter repo.PolicyDecisionFilter
+	approvals      []repo.PolicyApprovalRecord
+	approvalFilter repo.PolicyApprovalFilter
+}
+
+func (s *stubPolicyStore) GetPolicy(_ context.Context, policyID string) (repo.PolicyRecord, error) {
+	record, ok := s.policies[policyID]
+	if !ok {
+		return repo.PolicyRecord{}, repo.ErrNotFound
+	}
+	return record, nil
+}
+
+func (s *stubPolicyStore) ListPolicyDecisions(_ context.Context, filter repo.PolicyDecisionFilter) ([]repo.PolicyDecisionRecord, error) {
+	s.decisionFilter = filter
+	return s.decisions, nil
+}
+
+func (s *stubPolicyStore) ListPolicyApprovals(_ context.Context, filter repo.PolicyApprovalFilter) ([]repo.PolicyApprovalRecord, error) {
+	s.approvalFilter = filter
+	return s.approvals, nil
+}
+
+type stubRunStore struct {
+	repo.RunStore
+	runs        map[string]repo.RunSummary
+	events      []repo.RunEventRecord
+	eventFilter repo.RunEventFilter
+}
+
+func (s *stubRunStore) RunExists(_ context.Context, runID string) (bool, error) {
+	_, ok := s.runs[runID]
+	return ok, nil
+}
+
+func (s *stubRunStore) ListRunEvents(_ context.Context, filter repo.RunEventFilter) ([]repo.RunEventRecord, error) {
+	s.eventFilter = filter
+	return s.events, nil
+}
+
+func (s *stubRunStore) GetRunSummary(_ context.Context, runID string) (repo.RunSummary, error) {
+	run, ok := s.runs[runID]
+	if !ok {
+		return repo.RunSummary{}, repo.ErrNotFound
+	}
+	return run, nil
+}
+
+func TestGetPolicyUsesStore(t *testing.T) {
+	createdAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	api := &experimentsAPI{policyStoreOverride: &stubPolicyStore{policies: map[string]repo.PolicyRecord{
+		"pol-1": {
+			PolicyID:  "pol-1",
+			Name:      "gpu-quota",
+			CreatedAt: createdAt,
+			CreatedBy: "user-1",
+			Latest:    &repo.PolicyVersionRecord{PolicyVersionID: "ver-2", Version: 2, Status: policyStatusActive, SpecSHA256: "abc", CreatedAt: createdAt},
+		},
+	}}}
+
+	req := httptest.NewRequest(http.MethodGet, "/policies/pol-1", nil)
+	req.SetPathValue("policy_id", "pol-1")
+	resp := httptest.NewRecorder()
+	api.handleGetPolicy(resp, req)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("status=%d body=%s", resp.Code, resp.Body.String())
+	}
+	var got policySummary
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if got.Name != "gpu-quota" || got.LatestVersion.Version != 2 || got.LatestVersion.PolicyVersionID != "ver-2" {
+		t.Fatalf("unexpected policy: %+v", got)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/policies/missing", nil)
+	req.SetPathValue("policy_id", "missing")
+	resp = httptest.NewRecorder()
+	api.handleGetPolicy(resp, req)
+	if resp.Code != http.StatusNotFound {
+		t.Fatalf("status=%d want 404", resp.Code)
+	}
+}
+
+func TestListPolicyDecisionsPassesRunFilter(t *testing.T) {
+	store := &stubPolicyStore{decisions: []repo.PolicyDecisionRecord{{
+		DecisionID: "dec-1",
+		RunID:      "run-1",
+		PolicyID:   "pol-1",
+		Decision:   "allow",
+		Context:    []byte(`{"secret":"x"}`),
+	}}}
+	api := &experimentsAPI{policyStoreOverride: store}
+
+	req := httptest.NewRequest(http.MethodGet, "/policy-decisions?run_id=run-1&limit=5", nil)
+	resp := httptest.NewRecorder()
+	api.handleListPolicyDecisions(resp, req)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("status=%d body=%s", resp.Code, resp.Body.String())
+	}
+	if store.decisionFilter.RunID != "run-1" || store.decisionFilter.Limit != 5 {
+		t.Fatalf("filter=%+v", store.decisionFilter)
+	}
+	var got policyDecisionListResponse
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(got.Decisions) != 1 || got.Decisions[0].DecisionID != "dec-1" {
+		t.Fatalf("decisions=%+v", got.Decisions)
+	}
+}
+
+func TestGetExperimentRunMapsNotFound(t *testing.T) {
+	api := &experimentsAPI{runStoreOverride: &stubRunStore{runs: map[string]repo.RunSummary{
+		"run-1": {RunID: "run-1", ExperimentID: "exp-1", Status: "running", Params: []byte(`{"lr":0.1}`)},
+	}}}
+
+	req := httptest.NewRequest(http.MethodGet, "/experiment-runs/run-1", nil)
+	req.SetPathValue("run_id", "run-1")
+	resp := httptest.NewRecorder()
+	api.handleGetExperimentRun(resp, req)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("status=%d body=%s", resp.Code, resp.Body.String())
+	}
+	var got experimentRun
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if got.ExperimentID != "exp-1" || got.Status != "running" || string(got.Params) != `{"lr":0.1}` {
+		t.Fatalf("unexpected run: %+v", got)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/experiment-runs/run-2", nil)
+	req.SetPathValue("run_id", "run-2")
+	resp = httptest.NewRecorder()
+	api.handleGetExperimentRun(resp, req)
+	if resp.Code != http.StatusNotFound {
+		t.Fatalf("status=%d want 404", resp.Code)
+	}
+}
+
+func TestListPolicyApprovalsUsesStore(t *testing.T) {
+	escalateAt := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	store := &stubPolicyStore{approvals: []repo.PolicyApprovalRecord{{
+		ApprovalID:   "appr-1",
+		RunID:        "run-1",
+		Status:       approvalStatusPending,
+		Workflow:     []byte(`{"stages":[{"name":"security"},{"name":"data-owner"}]}`),
+		CurrentStage: 1,
+		EscalateAt:   &escalateAt,
+	}}}
+	api := &experimentsAPI{policyStoreOverride: store}
+
+	req := httptest.NewRequest(http.MethodGet, "/policy-approvals?status=pending&run_id=run-1&limit=5", nil)
+	resp := httptest.NewRecorder()
+	api.handleListPolicyApprovals(resp, req)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("status=%d body=%s", resp.Code, resp.Body.String())
+	}
+	if store.approvalFilter != (repo.PolicyApprovalFilter{Status: "pending", RunID: "run-1", Limit: 5}) {
+		t.Fatalf("filter=%+v", store.approvalFilter)
+	}
+	var got policyApprovalListResponse
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(got.Approvals) != 1 || got.Approvals[0].StageName != "data-owner" || got.Approvals[0].EscalateAt == nil {
+		t.Fatalf("approvals=%+v", got.Approvals)
+	}
+}
+
+func TestListExperimentRunEventsUsesStore(t *testing.T) {
+	store := &stubRunStore{
+		runs:   map[string]repo.RunSummary{"run-1": {RunID: "run-1"}},
+		events: []repo.RunEventRecord{{EventID: 7, RunID: "run-1", Level: "info", Message: "started"}},
+	}
+	api := &experimentsAPI{runStoreOverride: store}
+
+	req := httptest.NewRequest(http.MethodGet, "/experiment-runs/run-1/events?before_event_id=9", nil)
+	req.SetPathValue("run_id", "run-1")
+	resp := httptest.NewRecorder()
+	api.handleListExperimentRunEvents(resp, req)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("status=%d body=%s", resp.Code, resp.Body.String())
+	}
+	if store.eventFilter.RunID != "run-1" || store.eventFilter.BeforeEventID != 9 {
+		t.Fatalf("filter=%+v", store.eventFilter)
+	}
+	var got struct {
+		Events            []experimentRunEvent `json:"events"`
+		NextBeforeEventID int64                `json:"next_before_event_id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(got.Events) != 1 || got.Events[0].Message != "started" || got.NextBeforeEventID != 7 {
+		t.Fatalf("events=%+v", got)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/experiment-runs/run-2/events", nil)
+	req.SetPathValue("run_id", "run-2")
+	resp = httptest.NewRecorder()
+	api.handleListExperimentRunEvents(resp, req)
+	if resp.Code != http.StatusNotFound {
+		t.Fatalf("status=%d want 404", resp.Code)
+	}
+}
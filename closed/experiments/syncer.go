@@ -0,0 +1,189 @@
+package experiments
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpserver"
+	platformpg "github.com/animus-labs/animus-go/closed/internal/platform/postgres"
+)
+
+// syncer runs pass periodically on the one replica that holds its leader
+// lock, so runs and dev environments are observed by a single writer.
+// Failed passes back off exponentially up to maxBackoff; every delay is
+// jittered so replicas do not poll in lockstep.
+type syncer struct {
+	name       string
+	logger     *slog.Logger
+	leader     *platformpg.Leader
+	interval   time.Duration
+	maxBackoff time.Duration
+	pass       func(ctx context.Context) error
+}
+
+func (s syncer) start(ctx context.Context) {
+	stats := registerSyncerStats(s.name)
+	backoff := syncBackoff{base: s.interval, max: s.maxBackoff, jitter: rand.Float64}
+
+	go func() {
+		defer s.leader.Release(context.Background())
+		timer := time.NewTimer(backoff.next(nil))
+		defer timer.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-timer.C:
+			}
+			timer.Reset(backoff.next(s.runOnce(ctx, stats)))
+		}
+	}()
+}
+
+func (s syncer) runOnce(ctx context.Context, stats *syncerStats) error {
+	leading, err := s.leader.Acquire(ctx)
+	stats.setLeader(leading)
+	if err != nil {
+		if s.logger != nil {
+			s.logger.Warn("syncer leader election failed", "syncer", s.name, "error", err)
+		}
+		return err
+	}
+	if !leading {
+		return nil
+	}
+	started := time.Now()
+	err = s.pass(ctx)
+	stats.observe(started, time.Since(started), err)
+	if err != nil && s.logger != nil {
+		s.logger.Warn("syncer pass failed", "syncer", s.name, "error", err)
+	}
+	return err
+}
+
+type syncBackoff struct {
+	base     time.Duration
+	max      time.Duration
+	failures int
+	jitter   func() float64
+}
+
+// next returns the delay before the following pass: base after a success,
+// doubling per consecutive failure up to max, scaled by a factor in
+// [0.8, 1.2).
+func (b *syncBackoff) next(err error) time.Duration {
+	if err == nil {
+		b.failures = 0
+	} else if b.failures < 16 {
+		b.failures++
+	}
+	delay := b.base
+	for i := 0; i < b.failures && delay < b.max; i++ {
+		delay *= 2
+	}
+	if b.max > 0 && delay > b.max {
+		delay = b.max
+	}
+	factor := 1.0
+	if b.jitter != nil {
+		factor = 0.8 + 0.4*b.jitter()
+	}
+	return time.Duration(float64(delay) * factor)
+}
+
+type syncerStats struct {
+	mu          sync.Mutex
+	leader      bool
+	lastSuccess time.Time
+	lastPass    time.Duration
+	successes   uint64
+	failures    uint64
+}
+
+func (s *syncerStats) setLeader(leader bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.leader = leader
+}
+
+func (s *syncerStats) observe(started time.Time, took time.Duration, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastPass = took
+	if err != nil {
+		s.failures++
+		return
+	}
+	s.successes++
+	s.lastSuccess = started
+}
+
+var (
+	syncerStatsMu   sync.Mutex
+	syncerStatsByID = map[string]*syncerStats{}
+	syncerStatsOnce sync.Once
+)
+
+func registerSyncerStats(name string) *syncerStats {
+	syncerStatsOnce.Do(func() {
+		httpserver.RegisterMetricsProvider(func(w io.Writer) { writeSyncerMetrics(w, time.Now()) })
+	})
+	syncerStatsMu.Lock()
+	defer syncerStatsMu.Unlock()
+	stats, ok := syncerStatsByID[name]
+	if !ok {
+		stats = &syncerStats{}
+		syncerStatsByID[name] = stats
+	}
+	return stats
+}
+
+// writeSyncerMetrics exports per-syncer leadership and lag. Lag is the time
+// since the last successful pass and is reported by the leader only, so the
+// fleet-wide value is max(animus_syncer_lag_seconds).
+func writeSyncerMetrics(w io.Writer, now time.Time) {
+	syncerStatsMu.Lock()
+	names := make([]string, 0, len(syncerStatsByID))
+	for name := range syncerStatsByID {
+		names = append(names, name)
+	}
+	syncerStatsMu.Unlock()
+	sort.Strings(names)
+
+	fmt.Fprint(w, "# HELP animus_syncer_leader Whether this replica leads the syncer.\n")
+	fmt.Fprint(w, "# TYPE animus_syncer_leader gauge\n")
+	fmt.Fprint(w, "# HELP animus_syncer_lag_seconds Seconds since the syncer's last successful pass on the leader.\n")
+	fmt.Fprint(w, "# TYPE animus_syncer_lag_seconds gauge\n")
+	fmt.Fprint(w, "# HELP animus_syncer_pass_duration_seconds Duration of the last syncer pass.\n")
+	fmt.Fprint(w, "# TYPE animus_syncer_pass_duration_seconds gauge\n")
+	fmt.Fprint(w, "# HELP animus_syncer_passes_total Syncer passes by result.\n")
+	fmt.Fprint(w, "# TYPE animus_syncer_passes_total counter\n")
+	for _, name := range names {
+		syncerStatsMu.Lock()
+		stats := syncerStatsByID[name]
+		syncerStatsMu.Unlock()
+
+		stats.mu.Lock()
+		leader, lastSuccess, lastPass := stats.leader, stats.lastSuccess, stats.lastPass
+		successes, failures := stats.successes, stats.failures
+		stats.mu.Unlock()
+
+		leaderValue := 0
+		if leader {
+			leaderValue = 1
+		}
+		fmt.Fprintf(w, "animus_syncer_leader{syncer=\"%s\"} %d\n", name, leaderValue)
+		if leader && !lastSuccess.IsZero() {
+			fmt.Fprintf(w, "animus_syncer_lag_seconds{syncer=\"%s\"} %.3f\n", name, now.Sub(lastSuccess).Seconds())
+		}
+		fmt.Fprintf(w, "animus_syncer_pass_duration_seconds{syncer=\"%s\"} %.3f\n", name, lastPass.Seconds())
+		fmt.Fprintf(w, "animus_syncer_passes_total{syncer=\"%s\",result=\"success\"} %d\n", name, successes)
+		fmt.Fprintf(w, "animus_syncer_passes_total{syncer=\"%s\",result=\"failure\"} %d\n", name, failures)
+	}
+}
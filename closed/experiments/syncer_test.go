@@ -0,0 +1,61 @@
+package experiments
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSyncBackoff(t *testing.T) {
+	fail := errors.New("boom")
+	b := syncBackoff{base: 30 * time.Second, max: 5 * time.Minute}
+	want := []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 5 * time.Minute, 5 * time.Minute}
+	for i, expected := range want {
+		if got := b.next(fail); got != expected {
+			t.Fatalf("failure %d: expected %s, got %s", i+1, expected, got)
+		}
+	}
+	if got := b.next(nil); got != 30*time.Second {
+		t.Fatalf("success must reset to base, got %s", got)
+	}
+
+	b.jitter = func() float64 { return 0 }
+	if got := b.next(nil); got != 24*time.Second {
+		t.Fatalf("expected lower jitter bound, got %s", got)
+	}
+	b.jitter = func() float64 { return 0.999 }
+	if got := b.next(nil); got < 35*time.Second || got >= 36*time.Second {
+		t.Fatalf("expected upper jitter bound, got %s", got)
+	}
+}
+
+func TestWriteSyncerMetrics(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	leader := registerSyncerStats("test_leader")
+	leader.setLeader(true)
+	leader.observe(now.Add(-90*time.Second), 2*time.Second, nil)
+	leader.observe(now.Add(-30*time.Second), time.Second, errors.New("boom"))
+	follower := registerSyncerStats("test_follower")
+	follower.setLeader(false)
+	follower.observe(now.Add(-time.Hour), time.Second, nil)
+
+	var buf bytes.Buffer
+	writeSyncerMetrics(&buf, now)
+	out := buf.String()
+	for _, line := range []string{
+		`animus_syncer_leader{syncer="test_leader"} 1`,
+		`animus_syncer_lag_seconds{syncer="test_leader"} 90.000`,
+		`animus_syncer_passes_total{syncer="test_leader",result="success"} 1`,
+		`animus_syncer_passes_total{syncer="test_leader",result="failure"} 1`,
+		`animus_syncer_leader{syncer="test_follower"} 0`,
+	} {
+		if !strings.Contains(out, line+"\n") {
+			t.Fatalf("missing %q in:\n%s", line, out)
+		}
+	}
+	if strings.Contains(out, `animus_syncer_lag_seconds{syncer="test_follower"}`) {
+		t.Fatalf("followers must not report lag")
+	}
+}
@@ -0,0 +1,666 @@
+package experiments
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	"github.com/animus-labs/animus-go/closed/internal/repo/postgres"
+	"github.com/google/uuid"
+)
+
+const (
+	auditTagCreated  = "tag.created"
+	auditTagUpdated  = "tag.updated"
+	auditTagDeleted  = "tag.deleted"
+	auditTagAttached = "tag.attached"
+	auditTagDetached = "tag.detached"
+
+	taggedExperiment = "experiment"
+	taggedRun        = "experiment_run"
+	taggedDataset    = "dataset"
+	taggedModel      = "model"
+)
+
+// taggableResource names the table and key column that hold a taggable
+// resource; every one of them carries project_id.
+type taggableResource struct {
+	Table    string
+	IDColumn string
+}
+
+var taggableResources = map[string]taggableResource{
+	taggedExperiment: {Table: "experiments", IDColumn: "experiment_id"},
+	taggedRun:        {Table: "experiment_runs", IDColumn: "run_id"},
+	taggedDataset:    {Table: "datasets", IDColumn: "dataset_id"},
+	taggedModel:      {Table: "models", IDColumn: "model_id"},
+}
+
+var tagNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._:/-]{0,62}$`)
+
+type tag struct {
+	TagID         string    `json:"tag_id"`
+	Name          string    `json:"name"`
+	Description   string    `json:"description,omitempty"`
+	ResourceCount int       `json:"resource_count"`
+	CreatedAt     time.Time `json:"created_at"`
+	CreatedBy     string    `json:"created_by"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type tagRequest struct {
+	Name        *string `json:"name,omitempty"`
+	Description *string `json:"description,omitempty"`
+}
+
+type tagResource struct {
+	ResourceType string    `json:"resource_type"`
+	ResourceID   string    `json:"resource_id"`
+	CreatedAt    time.Time `json:"created_at"`
+	CreatedBy    string    `json:"created_by"`
+}
+
+type tagAttachRequest struct {
+	ResourceType string `json:"resource_type"`
+	ResourceID   string `json:"resource_id"`
+}
+
+// normalizeTagName lowercases the name so tags compare case-insensitively.
+func normalizeTagName(raw string) (string, bool) {
+	name := strings.ToLower(strings.TrimSpace(raw))
+	return name, tagNamePattern.MatchString(name)
+}
+
+func normalizeTaggedType(raw string) (string, bool) {
+	resourceType := strings.ToLower(strings.TrimSpace(raw))
+	_, ok := taggableResources[resourceType]
+	return resourceType, ok
+}
+
+func (api *experimentsAPI) handleListTags(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := auth.ProjectIDFromContext(r.Context())
+	if !ok || strings.TrimSpace(projectID) == "" {
+		api.writeError(w, r, http.StatusBadRequest, "project_id_required")
+		return
+	}
+	limit := httpapi.Limit(r, 200, 1000)
+
+	rows, err := api.db.QueryContext(
+		r.Context(),
+		`SELECT t.tag_id, t.name, t.description, t.created_at, t.created_by, t.updated_at,
+				(SELECT count(*) FROM resource_tags rt WHERE rt.tag_id = t.tag_id)
+		 FROM tags t
+		 WHERE t.project_id = $1
+		 ORDER BY t.name
+		 LIMIT $2`,
+		projectID,
+		limit,
+	)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer rows.Close()
+
+	out := make([]tag, 0)
+	for rows.Next() {
+		item, err := scanTag(rows)
+		if err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+		out = append(out, item)
+	}
+	if err := rows.Err(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	api.writeJSON(w, http.StatusOK, map[string]any{"tags": out})
+}
+
+func (api *experimentsAPI) handleCreateTag(w http.ResponseWriter, r *http.Request) {
+	identity, ok := auth.IdentityFromContext(r.Context())
+	if !ok || strings.TrimSpace(identity.Subject) == "" {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	projectID, ok := auth.ProjectIDFromContext(r.Context())
+	if !ok || strings.TrimSpace(projectID) == "" {
+		api.writeError(w, r, http.StatusBadRequest, "project_id_required")
+		return
+	}
+
+	var req tagRequest
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
+		return
+	}
+	if req.Name == nil || strings.TrimSpace(*req.Name) == "" {
+		api.writeError(w, r, http.StatusBadRequest, "name_required")
+		return
+	}
+	name, ok := normalizeTagName(*req.Name)
+	if !ok {
+		api.writeError(w, r, http.StatusBadRequest, "invalid_tag_name")
+		return
+	}
+	description := ""
+	if req.Description != nil {
+		description = strings.TrimSpace(*req.Description)
+	}
+
+	now := time.Now().UTC()
+	created := tag{
+		TagID:       uuid.NewString(),
+		Name:        name,
+		Description: description,
+		CreatedAt:   now,
+		CreatedBy:   identity.Subject,
+		UpdatedAt:   now,
+	}
+
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	_, err = tx.ExecContext(
+		r.Context(),
+		`INSERT INTO tags (tag_id, project_id, name, description, created_at, created_by, updated_at)
+		 VALUES ($1,$2,$3,$4,$5,$6,$7)`,
+		created.TagID,
+		projectID,
+		created.Name,
+		nullString(created.Description),
+		created.CreatedAt,
+		created.CreatedBy,
+		created.UpdatedAt,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			api.writeError(w, r, http.StatusConflict, "tag_name_exists")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if err := insertTagAudit(r, tx, identity.Subject, auditTagCreated, projectID, created, nil); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "audit_failed")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	w.Header().Set("Location", "/tags/"+created.TagID)
+	api.writeJSON(w, http.StatusCreated, created)
+}
+
+func (api *experimentsAPI) handleGetTag(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := auth.ProjectIDFromContext(r.Context())
+	if !ok || strings.TrimSpace(projectID) == "" {
+		api.writeError(w, r, http.StatusBadRequest, "project_id_required")
+		return
+	}
+	tagID := strings.TrimSpace(r.PathValue("tag_id"))
+	if tagID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "tag_id_required")
+		return
+	}
+	item, err := getTag(r.Context(), api.db, projectID, tagID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	api.writeJSON(w, http.StatusOK, item)
+}
+
+func (api *experimentsAPI) handleUpdateTag(w http.ResponseWriter, r *http.Request) {
+	identity, ok := auth.IdentityFromContext(r.Context())
+	if !ok || strings.TrimSpace(identity.Subject) == "" {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	projectID, ok := auth.ProjectIDFromContext(r.Context())
+	if !ok || strings.TrimSpace(projectID) == "" {
+		api.writeError(w, r, http.StatusBadRequest, "project_id_required")
+		return
+	}
+	tagID := strings.TrimSpace(r.PathValue("tag_id"))
+	if tagID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "tag_id_required")
+		return
+	}
+
+	var req tagRequest
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
+		return
+	}
+
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	current, err := getTag(r.Context(), tx, projectID, tagID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	updated := current
+	if req.Name != nil {
+		name, ok := normalizeTagName(*req.Name)
+		if !ok {
+			api.writeError(w, r, http.StatusBadRequest, "invalid_tag_name")
+			return
+		}
+		updated.Name = name
+	}
+	if req.Description != nil {
+		updated.Description = strings.TrimSpace(*req.Description)
+	}
+	updated.UpdatedAt = time.Now().UTC()
+
+	_, err = tx.ExecContext(
+		r.Context(),
+		`UPDATE tags SET name = $3, description = $4, updated_at = $5
+		 WHERE project_id = $1 AND tag_id = $2`,
+		projectID,
+		tagID,
+		updated.Name,
+		nullString(updated.Description),
+		updated.UpdatedAt,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			api.writeError(w, r, http.StatusConflict, "tag_name_exists")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	extra := map[string]any{}
+	if updated.Name != current.Name {
+		extra["previous_name"] = current.Name
+	}
+	if err := insertTagAudit(r, tx, identity.Subject, auditTagUpdated, projectID, updated, extra); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "audit_failed")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	api.writeJSON(w, http.StatusOK, updated)
+}
+
+// handleDeleteTag removes the tag; its attachments go with it.
+func (api *experimentsAPI) handleDeleteTag(w http.ResponseWriter, r *http.Request) {
+	identity, ok := auth.IdentityFromContext(r.Context())
+	if !ok || strings.TrimSpace(identity.Subject) == "" {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	projectID, ok := auth.ProjectIDFromContext(r.Context())
+	if !ok || strings.TrimSpace(projectID) == "" {
+		api.writeError(w, r, http.StatusBadRequest, "project_id_required")
+		return
+	}
+	tagID := strings.TrimSpace(r.PathValue("tag_id"))
+	if tagID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "tag_id_required")
+		return
+	}
+
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	current, err := getTag(r.Context(), tx, projectID, tagID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if _, err := tx.ExecContext(r.Context(), `DELETE FROM tags WHERE project_id = $1 AND tag_id = $2`, projectID, tagID); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	extra := map[string]any{"resource_count": current.ResourceCount}
+	if err := insertTagAudit(r, tx, identity.Subject, auditTagDeleted, projectID, current, extra); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "audit_failed")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (api *experimentsAPI) handleListTagResources(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := auth.ProjectIDFromContext(r.Context())
+	if !ok || strings.TrimSpace(projectID) == "" {
+		api.writeError(w, r, http.StatusBadRequest, "project_id_required")
+		return
+	}
+	tagID := strings.TrimSpace(r.PathValue("tag_id"))
+	if tagID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "tag_id_required")
+		return
+	}
+	resourceType := ""
+	if raw := r.URL.Query().Get("resource_type"); strings.TrimSpace(raw) != "" {
+		normalized, ok := normalizeTaggedType(raw)
+		if !ok {
+			api.writeError(w, r, http.StatusBadRequest, "invalid_resource_type")
+			return
+		}
+		resourceType = normalized
+	}
+	limit := httpapi.Limit(r, 100, 500)
+
+	if _, err := getTag(r.Context(), api.db, projectID, tagID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	rows, err := api.db.QueryContext(
+		r.Context(),
+		`SELECT resource_type, resource_id, created_at, created_by
+		 FROM resource_tags
+		 WHERE project_id = $1 AND tag_id = $2 AND ($3 = '' OR resource_type = $3)
+		 ORDER BY created_at DESC, resource_type, resource_id
+		 LIMIT $4`,
+		projectID,
+		tagID,
+		resourceType,
+		limit,
+	)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer rows.Close()
+
+	out := make([]tagResource, 0)
+	for rows.Next() {
+		var item tagResource
+		if err := rows.Scan(&item.ResourceType, &item.ResourceID, &item.CreatedAt, &item.CreatedBy); err != nil {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+		out = append(out, item)
+	}
+	if err := rows.Err(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	api.writeJSON(w, http.StatusOK, map[string]any{"tag_id": tagID, "resources": out})
+}
+
+// handleAttachTag is idempotent: attaching a tag that is already attached
+// returns 200 and writes no audit event.
+func (api *experimentsAPI) handleAttachTag(w http.ResponseWriter, r *http.Request) {
+	identity, ok := auth.IdentityFromContext(r.Context())
+	if !ok || strings.TrimSpace(identity.Subject) == "" {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	projectID, ok := auth.ProjectIDFromContext(r.Context())
+	if !ok || strings.TrimSpace(projectID) == "" {
+		api.writeError(w, r, http.StatusBadRequest, "project_id_required")
+		return
+	}
+	tagID := strings.TrimSpace(r.PathValue("tag_id"))
+	if tagID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "tag_id_required")
+		return
+	}
+
+	var req tagAttachRequest
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
+		return
+	}
+	resourceType, ok := normalizeTaggedType(req.ResourceType)
+	if !ok {
+		api.writeError(w, r, http.StatusBadRequest, "invalid_resource_type")
+		return
+	}
+	resourceID := strings.TrimSpace(req.ResourceID)
+	if resourceID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "resource_id_required")
+		return
+	}
+
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	current, err := getTag(r.Context(), tx, projectID, tagID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	exists, err := taggedResourceExists(r.Context(), tx, projectID, resourceType, resourceID)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if !exists {
+		api.writeError(w, r, http.StatusNotFound, "resource_not_found")
+		return
+	}
+
+	attached := tagResource{
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		CreatedAt:    time.Now().UTC(),
+		CreatedBy:    identity.Subject,
+	}
+	result, err := tx.ExecContext(
+		r.Context(),
+		`INSERT INTO resource_tags (tag_id, project_id, resource_type, resource_id, created_at, created_by)
+		 VALUES ($1,$2,$3,$4,$5,$6)
+		 ON CONFLICT (tag_id, resource_type, resource_id) DO NOTHING`,
+		tagID,
+		projectID,
+		attached.ResourceType,
+		attached.ResourceID,
+		attached.CreatedAt,
+		attached.CreatedBy,
+	)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		api.writeJSON(w, http.StatusOK, attached)
+		return
+	}
+	extra := map[string]any{"resource_type": resourceType, "resource_id": resourceID}
+	if err := insertTagAudit(r, tx, identity.Subject, auditTagAttached, projectID, current, extra); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "audit_failed")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	api.writeJSON(w, http.StatusCreated, attached)
+}
+
+func (api *experimentsAPI) handleDetachTag(w http.ResponseWriter, r *http.Request) {
+	identity, ok := auth.IdentityFromContext(r.Context())
+	if !ok || strings.TrimSpace(identity.Subject) == "" {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	projectID, ok := auth.ProjectIDFromContext(r.Context())
+	if !ok || strings.TrimSpace(projectID) == "" {
+		api.writeError(w, r, http.StatusBadRequest, "project_id_required")
+		return
+	}
+	tagID := strings.TrimSpace(r.PathValue("tag_id"))
+	if tagID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "tag_id_required")
+		return
+	}
+	resourceType, ok := normalizeTaggedType(r.PathValue("resource_type"))
+	if !ok {
+		api.writeError(w, r, http.StatusBadRequest, "invalid_resource_type")
+		return
+	}
+	resourceID := strings.TrimSpace(r.PathValue("resource_id"))
+	if resourceID == "" {
+		api.writeError(w, r, http.StatusBadRequest, "resource_id_required")
+		return
+	}
+
+	tx, err := api.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	current, err := getTag(r.Context(), tx, projectID, tagID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			api.writeError(w, r, http.StatusNotFound, "not_found")
+			return
+		}
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	result, err := tx.ExecContext(
+		r.Context(),
+		`DELETE FROM resource_tags
+		 WHERE project_id = $1 AND tag_id = $2 AND resource_type = $3 AND resource_id = $4`,
+		projectID,
+		tagID,
+		resourceType,
+		resourceID,
+	)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		api.writeError(w, r, http.StatusNotFound, "not_found")
+		return
+	}
+	extra := map[string]any{"resource_type": resourceType, "resource_id": resourceID}
+	if err := insertTagAudit(r, tx, identity.Subject, auditTagDetached, projectID, current, extra); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "audit_failed")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func scanTag(row rowScanner) (tag, error) {
+	var (
+		item        tag
+		description sql.NullString
+	)
+	if err := row.Scan(&item.TagID, &item.Name, &description, &item.CreatedAt, &item.CreatedBy, &item.UpdatedAt, &item.ResourceCount); err != nil {
+		return tag{}, err
+	}
+	item.Description = description.String
+	return item, nil
+}
+
+func getTag(ctx context.Context, db postgres.DB, projectID, tagID string) (tag, error) {
+	return scanTag(db.QueryRowContext(
+		ctx,
+		`SELECT t.tag_id, t.name, t.description, t.created_at, t.created_by, t.updated_at,
+				(SELECT count(*) FROM resource_tags rt WHERE rt.tag_id = t.tag_id)
+		 FROM tags t
+		 WHERE t.project_id = $1 AND t.tag_id = $2`,
+		projectID,
+		tagID,
+	))
+}
+
+func taggedResourceExists(ctx context.Context, db postgres.DB, projectID, resourceType, resourceID string) (bool, error) {
+	resource, ok := taggableResources[resourceType]
+	if !ok {
+		return false, nil
+	}
+	var exists bool
+	err := db.QueryRowContext(
+		ctx,
+		`SELECT EXISTS (SELECT 1 FROM `+resource.Table+` WHERE `+resource.IDColumn+` = $1 AND project_id = $2)`,
+		resourceID,
+		projectID,
+	).Scan(&exists)
+	return exists, err
+}
+
+func insertTagAudit(r *http.Request, tx *sql.Tx, actor, action, projectID string, item tag, extra map[string]any) error {
+	payload := map[string]any{
+		"service":    "experiments",
+		"project_id": projectID,
+		"tag_id":     item.TagID,
+		"name":       item.Name,
+	}
+	for key, value := range extra {
+		payload[key] = value
+	}
+	err := auditlog.Enqueue(r.Context(), tx, auditlog.Event{
+		OccurredAt:   time.Now().UTC(),
+		Actor:        actor,
+		Action:       action,
+		ResourceType: "tag",
+		ResourceID:   item.TagID,
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload:      payload,
+	})
+	return err
+}
@@ -0,0 +1,32 @@
+package experiments
+
+import "testing"
+
+func TestNormalizeTagName(t *testing.T) {
+	cases := map[string]struct {
+		want string
+		ok   bool
+	}{
+		" Baseline ":  {want: "baseline", ok: true},
+		"team:vision": {want: "team:vision", ok: true},
+		"v1.2/rc-1":   {want: "v1.2/rc-1", ok: true},
+		"":            {ok: false},
+		"-leading":    {ok: false},
+		"has space":   {ok: false},
+	}
+	for raw, tc := range cases {
+		got, ok := normalizeTagName(raw)
+		if ok != tc.ok || (ok && got != tc.want) {
+			t.Fatalf("normalizeTagName(%q) = %q, %v", raw, got, ok)
+		}
+	}
+}
+
+func TestNormalizeTaggedType(t *testing.T) {
+	if got, ok := normalizeTaggedType(" Experiment_Run "); !ok || got != taggedRun {
+		t.Fatalf("unexpected type: %q %v", got, ok)
+	}
+	if _, ok := normalizeTaggedType("artifact"); ok {
+		t.Fatalf("expected artifact to be rejected")
+	}
+}
@@ -1,36 +1,112 @@
-package main
+package experiments
 
 import (
 	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"maps"
 	"net/http"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/animus-labs/animus-go/closed/internal/integrations/eventbus"
 	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
 	"github.com/animus-labs/animus-go/closed/internal/platform/redaction"
+	"github.com/animus-labs/animus-go/closed/internal/platform/validation"
+	"github.com/animus-labs/animus-go/closed/internal/repo"
 	"github.com/google/uuid"
 )
 
 type executeExperimentRunRequest struct {
-	ExperimentID     string         `json:"experiment_id"`
-	DatasetVersionID string         `json:"dataset_version_id"`
-	ImageRef         string         `json:"image_ref"`
-	GitRepo          string         `json:"git_repo,omitempty"`
-	GitCommit        string         `json:"git_commit,omitempty"`
-	GitRef           string         `json:"git_ref,omitempty"`
-	Params           map[string]any `json:"params,omitempty"`
-	Resources        map[string]any `json:"resources,omitempty"`
+	ExperimentID      string         `json:"experiment_id"`
+	DatasetVersionID  string         `json:"dataset_version_id"`
+	DatasetVersionIDs []string       `json:"dataset_version_ids,omitempty"`
+	ImageRef          string         `json:"image_ref"`
+	GitRepo           string         `json:"git_repo,omitempty"`
+	GitCommit         string         `json:"git_commit,omitempty"`
+	GitRef            string         `json:"git_ref,omitempty"`
+	Params            map[string]any `json:"params,omitempty"`
+	Resources         map[string]any `json:"resources,omitempty"`
 }
 
+// handleExecuteExperimentRun validates the request so clients get every
+// field error at once, then refuses it: execution is Data Plane-only.
 func (api *experimentsAPI) handleExecuteExperimentRun(w http.ResponseWriter, r *http.Request) {
+	var req executeExperimentRunRequest
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
+		return
+	}
+	if errs := validateExecuteExperimentRun(req); errs.Write(w, r) {
+		return
+	}
 	api.writeError(w, r, http.StatusNotImplemented, "training_executor_disabled")
 }
 
+// executeResourceHints are the resources keys the executors read; other keys
+// are passed through unchecked.
+var executeResourceHints = map[string]func(*validation.Errors, string, any) bool{
+	"cpu":    (*validation.Errors).Quantity,
+	"memory": (*validation.Errors).Quantity,
+	"gpus":   (*validation.Errors).Count,
+}
+
+func validateExecuteExperimentRun(req executeExperimentRunRequest) *validation.Errors {
+	errs := &validation.Errors{}
+	if errs.Required("/experiment_id", req.ExperimentID) {
+		errs.UUID("/experiment_id", req.ExperimentID)
+	}
+	if errs.Required("/dataset_version_id", req.DatasetVersionID) {
+		errs.UUID("/dataset_version_id", req.DatasetVersionID)
+	}
+	if len(req.DatasetVersionIDs) > maxRunDatasetVersions {
+		errs.Add("/dataset_version_ids", validation.CodeTooMany, "must have at most "+strconv.Itoa(maxRunDatasetVersions)+" items")
+	}
+	seen := map[string]bool{strings.TrimSpace(req.DatasetVersionID): true}
+	for i, id := range req.DatasetVersionIDs {
+		pointer := validation.Pointer("dataset_version_ids", strconv.Itoa(i))
+		id = strings.TrimSpace(id)
+		if !errs.Required(pointer, id) || !errs.UUID(pointer, id) {
+			continue
+		}
+		if seen[id] {
+			errs.Add(pointer, validation.CodeDuplicate, "repeats an earlier dataset version")
+		}
+		seen[id] = true
+	}
+	if errs.Required("/image_ref", req.ImageRef) {
+		errs.ImageRef("/image_ref", req.ImageRef)
+	}
+	errs.RepoURL("/git_repo", req.GitRepo)
+	errs.CommitSHA("/git_commit", req.GitCommit)
+	validateRunParamsAndResources(errs, "", req.Params, req.Resources)
+	return errs
+}
+
+// validateRunParamsAndResources checks the params and resources of an
+// execute request; prefix is the pointer of the object holding them, empty
+// for the request root.
+func validateRunParamsAndResources(errs *validation.Errors, prefix string, params, resources map[string]any) {
+	for _, key := range slices.Sorted(maps.Keys(params)) {
+		pointer := prefix + validation.Pointer("params", key)
+		if strings.TrimSpace(key) == "" {
+			errs.Add(pointer, validation.CodeEmptyKey, "parameter names must not be empty")
+			continue
+		}
+		errs.Scalar(pointer, params[key])
+	}
+	for _, key := range slices.Sorted(maps.Keys(resources)) {
+		if check, ok := executeResourceHints[key]; ok {
+			check(errs, prefix+validation.Pointer("resources", key), resources[key])
+		}
+	}
+}
+
 type ingestExperimentRunMetricsRequest struct {
 	Step    int64          `json:"step"`
 	Metrics map[string]any `json:"metrics"`
@@ -51,8 +127,8 @@ func (api *experimentsAPI) handleIngestExperimentRunMetrics(w http.ResponseWrite
 	}
 
 	var req ingestExperimentRunMetricsRequest
-	if err := decodeJSON(r, &req); err != nil {
-		api.writeError(w, r, http.StatusBadRequest, "invalid_json")
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
 		return
 	}
 	if req.Step < 0 {
@@ -65,6 +141,7 @@ func (api *experimentsAPI) handleIngestExperimentRunMetrics(w http.ResponseWrite
 	}
 
 	metrics := make(map[string]float64, len(req.Metrics))
+	observed := make(map[string]float64, len(req.Metrics))
 	for k, v := range req.Metrics {
 		name := strings.TrimSpace(k)
 		if name == "" {
@@ -78,10 +155,20 @@ func (api *experimentsAPI) handleIngestExperimentRunMetrics(w http.ResponseWrite
 			metrics[name] = float64(n)
 		case int64:
 			metrics[name] = float64(n)
+		case string:
+			// Non-finite values are only accepted for the analyzer; they are never stored.
+			value, ok := parseNonFiniteMetric(n)
+			if !ok || !api.metricAnomalies.Enabled() {
+				api.writeError(w, r, http.StatusBadRequest, "invalid_metric_value")
+				return
+			}
+			observed[name] = value
+			continue
 		default:
 			api.writeError(w, r, http.StatusBadRequest, "invalid_metric_value")
 			return
 		}
+		observed[name] = metrics[name]
 	}
 
 	metadataMap := req.Meta
@@ -101,17 +188,18 @@ func (api *experimentsAPI) handleIngestExperimentRunMetrics(w http.ResponseWrite
 	}
 	defer func() { _ = tx.Rollback() }()
 
-	var one int
-	if err := tx.QueryRowContext(r.Context(), `SELECT 1 FROM experiment_runs WHERE run_id = $1`, runID).Scan(&one); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			api.writeError(w, r, http.StatusNotFound, "not_found")
-			return
-		}
+	if exists, err := api.runStore(tx).RunExists(r.Context(), runID); err != nil {
 		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
 		return
+	} else if !exists {
+		api.writeError(w, r, http.StatusNotFound, "not_found")
+		return
 	}
 
+	anomalies := api.metricAnomalies.Observe(runID, req.Step, observed)
+
 	now := time.Now().UTC()
+	metricStore := api.metricStore(tx)
 	inserted := 0
 	names := make([]string, 0, len(metrics))
 	for name := range metrics {
@@ -155,40 +243,32 @@ func (api *experimentsAPI) handleIngestExperimentRunMetrics(w http.ResponseWrite
 			return
 		}
 
-		res, err := tx.ExecContext(
-			r.Context(),
-			`INSERT INTO experiment_run_metric_samples (
-				sample_id,
-				run_id,
-				recorded_at,
-				recorded_by,
-				step,
-				name,
-				value,
-				metadata,
-				integrity_sha256
-			) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
-			ON CONFLICT (run_id, name, step) DO NOTHING`,
-			sampleID,
-			runID,
-			now,
-			identity.Subject,
-			req.Step,
-			name,
-			value,
-			metadataJSON,
-			integrity,
-		)
+		created, err := metricStore.InsertSample(r.Context(), repo.MetricSampleRecord{
+			SampleID:        sampleID,
+			RunID:           runID,
+			RecordedAt:      now,
+			RecordedBy:      identity.Subject,
+			Step:            req.Step,
+			Name:            name,
+			Value:           value,
+			Metadata:        metadataJSON,
+			IntegritySHA256: integrity,
+		})
 		if err != nil {
 			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
 			return
 		}
-		affected, _ := res.RowsAffected()
-		if affected > 0 {
+		if created {
 			inserted++
 		}
 	}
 
+	runFailed, err := api.recordMetricAnomalies(r.Context(), tx, runID, anomalies)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
 	if err := tx.Commit(); err != nil {
 		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
 		return
@@ -198,13 +278,18 @@ func (api *experimentsAPI) handleIngestExperimentRunMetrics(w http.ResponseWrite
 	if inserted == 0 {
 		status = http.StatusOK
 	}
-	api.writeJSON(w, status, map[string]any{
+	resp := map[string]any{
 		"run_id":     runID,
 		"step":       req.Step,
 		"inserted":   inserted,
-		"received":   len(metrics),
+		"received":   len(observed),
 		"request_id": r.Header.Get("X-Request-Id"),
-	})
+	}
+	if len(anomalies) > 0 {
+		resp["anomalies"] = anomalies
+		resp["run_failed"] = runFailed
+	}
+	api.writeJSON(w, status, resp)
 }
 
 type experimentRunMetricSample struct {
@@ -225,75 +310,38 @@ func (api *experimentsAPI) handleListExperimentRunMetrics(w http.ResponseWriter,
 		return
 	}
 
-	var one int
-	if err := api.db.QueryRowContext(r.Context(), `SELECT 1 FROM experiment_runs WHERE run_id = $1`, runID).Scan(&one); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			api.writeError(w, r, http.StatusNotFound, "not_found")
-			return
-		}
+	if exists, err := api.runStore(api.db).RunExists(r.Context(), runID); err != nil {
 		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
 		return
+	} else if !exists {
+		api.writeError(w, r, http.StatusNotFound, "not_found")
+		return
 	}
 
-	limit := clampInt(parseIntQuery(r, "limit", 200), 1, 1000)
+	limit := httpapi.Limit(r, 200, 1000)
 	nameFilter := strings.TrimSpace(r.URL.Query().Get("name"))
 
-	var (
-		rows *sql.Rows
-		err  error
-	)
-	if nameFilter != "" {
-		rows, err = api.db.QueryContext(
-			r.Context(),
-			`SELECT sample_id, recorded_at, recorded_by, step, name, value, metadata
-			 FROM experiment_run_metric_samples
-			 WHERE run_id = $1 AND name = $2
-			 ORDER BY step DESC
-			 LIMIT $3`,
-			runID,
-			nameFilter,
-			limit,
-		)
-	} else {
-		rows, err = api.db.QueryContext(
-			r.Context(),
-			`SELECT DISTINCT ON (name) sample_id, recorded_at, recorded_by, step, name, value, metadata
-			 FROM experiment_run_metric_samples
-			 WHERE run_id = $1
-			 ORDER BY name, step DESC
-			 LIMIT $2`,
-			runID,
-			limit,
-		)
-	}
+	samples, err := api.metricStore(api.db).ListSamples(r.Context(), repo.MetricSampleFilter{
+		RunID: runID,
+		Name:  nameFilter,
+		Limit: limit,
+	})
 	if err != nil {
 		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
 		return
 	}
-	defer rows.Close()
-
-	out := make([]experimentRunMetricSample, 0, limit)
-	for rows.Next() {
-		var (
-			sample   experimentRunMetricSample
-			metadata []byte
-		)
-		if err := rows.Scan(&sample.SampleID, &sample.RecordedAt, &sample.RecordedBy, &sample.Step, &sample.Name, &sample.Value, &metadata); err != nil {
-			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
-			return
-		}
-		sample.RunID = runID
-		sample.Metadata = normalizeJSON(metadata)
-		out = append(out, sample)
-	}
-	if err := rows.Err(); err != nil {
-		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
-		return
-	}
-	if nameFilter != "" {
-		for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
-			out[i], out[j] = out[j], out[i]
-		}
+	out := make([]experimentRunMetricSample, 0, len(samples))
+	for _, sample := range samples {
+		out = append(out, experimentRunMetricSample{
+			SampleID:   sample.SampleID,
+			RunID:      runID,
+			RecordedAt: sample.RecordedAt,
+			RecordedBy: sample.RecordedBy,
+			Step:       sample.Step,
+			Name:       sample.Name,
+			Value:      sample.Value,
+			Metadata:   normalizeJSON(sample.Metadata),
+		})
 	}
 
 	api.writeJSON(w, http.StatusOK, map[string]any{
@@ -334,8 +382,8 @@ func (api *experimentsAPI) handleCreateExperimentRunEvent(w http.ResponseWriter,
 	}
 
 	var req createExperimentRunEventRequest
-	if err := decodeJSON(r, &req); err != nil {
-		api.writeError(w, r, http.StatusBadRequest, "invalid_json")
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
 		return
 	}
 
@@ -399,29 +447,17 @@ func (api *experimentsAPI) handleCreateExperimentRunEvent(w http.ResponseWriter,
 		return
 	}
 
-	var eventID int64
-	err = api.db.QueryRowContext(
-		r.Context(),
-		`INSERT INTO experiment_run_events (
-			run_id,
-			occurred_at,
-			actor,
-			level,
-			message,
-			metadata,
-			integrity_sha256
-		) VALUES ($1,$2,$3,$4,$5,$6,$7)
-		RETURNING event_id`,
-		runID,
-		occurredAt,
-		identity.Subject,
-		level,
-		message,
-		metaJSON,
-		integrity,
-	).Scan(&eventID)
+	eventID, err := api.runStore(api.db).InsertRunEvent(r.Context(), repo.RunEventRecord{
+		RunID:           runID,
+		OccurredAt:      occurredAt,
+		Actor:           identity.Subject,
+		Level:           level,
+		Message:         message,
+		Metadata:        metaJSON,
+		IntegritySHA256: integrity,
+	})
 	if err != nil {
-		if isForeignKeyViolation(err) {
+		if errors.Is(err, repo.ErrNotFound) {
 			api.writeError(w, r, http.StatusNotFound, "not_found")
 			return
 		}
@@ -448,17 +484,18 @@ func (api *experimentsAPI) handleListExperimentRunEvents(w http.ResponseWriter,
 		return
 	}
 
-	var one int
-	if err := api.db.QueryRowContext(r.Context(), `SELECT 1 FROM experiment_runs WHERE run_id = $1`, runID).Scan(&one); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			api.writeError(w, r, http.StatusNotFound, "not_found")
-			return
-		}
+	store := api.runStore(api.db)
+	exists, err := store.RunExists(r.Context(), runID)
+	if err != nil {
 		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
 		return
 	}
+	if !exists {
+		api.writeError(w, r, http.StatusNotFound, "not_found")
+		return
+	}
 
-	limit := clampInt(parseIntQuery(r, "limit", 200), 1, 1000)
+	limit := httpapi.Limit(r, 200, 1000)
 	beforeRaw := strings.TrimSpace(r.URL.Query().Get("before_event_id"))
 	var beforeID int64
 	if beforeRaw != "" {
@@ -469,42 +506,31 @@ func (api *experimentsAPI) handleListExperimentRunEvents(w http.ResponseWriter,
 		}
 		beforeID = parsed
 	}
-
-	args := []any{runID}
-	query := `SELECT event_id, occurred_at, actor, level, message, metadata
-		FROM experiment_run_events
-		WHERE run_id = $1`
-	if beforeID > 0 {
-		args = append(args, beforeID)
-		query += " AND event_id < $" + strconv.Itoa(len(args))
+	if raw := r.URL.Query().Get("cursor"); raw != "" {
+		var pos runEventCursor
+		if httpapi.DecodeCursor(raw, &pos) != nil || pos.BeforeEventID <= 0 {
+			api.writeError(w, r, http.StatusBadRequest, "invalid_cursor")
+			return
+		}
+		beforeID = pos.BeforeEventID
 	}
-	args = append(args, limit)
-	query += " ORDER BY event_id DESC LIMIT $" + strconv.Itoa(len(args))
 
-	rows, err := api.db.QueryContext(r.Context(), query, args...)
+	records, err := store.ListRunEvents(r.Context(), repo.RunEventFilter{RunID: runID, BeforeEventID: beforeID, Limit: limit})
 	if err != nil {
 		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
 		return
 	}
-	defer rows.Close()
-
-	out := make([]experimentRunEvent, 0, limit)
-	for rows.Next() {
-		var (
-			ev          experimentRunEvent
-			metadataRaw []byte
-		)
-		if err := rows.Scan(&ev.EventID, &ev.OccurredAt, &ev.Actor, &ev.Level, &ev.Message, &metadataRaw); err != nil {
-			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
-			return
-		}
-		ev.RunID = runID
-		ev.Metadata = normalizeJSON(metadataRaw)
-		out = append(out, ev)
-	}
-	if err := rows.Err(); err != nil {
-		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
-		return
+	out := make([]experimentRunEvent, 0, len(records))
+	for _, record := range records {
+		out = append(out, experimentRunEvent{
+			EventID:    record.EventID,
+			RunID:      runID,
+			OccurredAt: record.OccurredAt,
+			Actor:      record.Actor,
+			Level:      record.Level,
+			Message:    record.Message,
+			Metadata:   normalizeJSON(record.Metadata),
+		})
 	}
 
 	resp := map[string]any{
@@ -513,10 +539,17 @@ func (api *experimentsAPI) handleListExperimentRunEvents(w http.ResponseWriter,
 	}
 	if len(out) > 0 {
 		resp["next_before_event_id"] = out[len(out)-1].EventID
+		resp["next_cursor"] = httpapi.EncodeCursor(runEventCursor{BeforeEventID: out[len(out)-1].EventID})
 	}
 	api.writeJSON(w, http.StatusOK, resp)
 }
 
+// runEventCursor is the page position behind the cursor of the run events
+// list.
+type runEventCursor struct {
+	BeforeEventID int64 `json:"before_event_id"`
+}
+
 func (api *experimentsAPI) insertRunStateEvent(ctx context.Context, tx *sql.Tx, runID string, status string, observedAt time.Time, details map[string]any) (bool, error) {
 	if tx == nil {
 		return false, errors.New("tx is required")
@@ -557,22 +590,31 @@ func (api *experimentsAPI) insertRunStateEvent(ctx context.Context, tx *sql.Tx,
 		return false, err
 	}
 
-	res, err := tx.ExecContext(ctx,
-		`INSERT INTO experiment_run_state_events (state_id, run_id, status, observed_at, details, integrity_sha256)
-		 VALUES ($1,$2,$3,$4,$5,$6)
-		 ON CONFLICT (run_id, status) DO NOTHING`,
-		stateID,
-		runID,
-		status,
-		observedAt,
-		detailsJSON,
-		integrity,
-	)
-	if err != nil {
+	store := api.runStore(tx)
+	inserted, err := store.InsertRunStateEvent(ctx, repo.RunStateEventRecord{
+		StateID:         stateID,
+		RunID:           runID,
+		Status:          status,
+		ObservedAt:      observedAt,
+		Details:         detailsJSON,
+		IntegritySHA256: integrity,
+	})
+	if err != nil || !inserted {
 		return false, err
 	}
-	affected, _ := res.RowsAffected()
-	return affected > 0, nil
+	if api.bus.Enabled {
+		projectID, err := store.RunProjectID(ctx, runID)
+		if err != nil {
+			return false, err
+		}
+		if err := api.bus.Enqueue(ctx, tx, eventbus.EventRunStateChanged, stateID, projectID, observedAt,
+			map[string]string{"run_id": runID},
+			map[string]any{"status": status},
+		); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
 }
 
 func (api *experimentsAPI) insertRunEvent(ctx context.Context, tx *sql.Tx, runID string, actor string, level string, message string, metadata map[string]any) error {
@@ -616,23 +658,14 @@ func (api *experimentsAPI) insertRunEvent(ctx context.Context, tx *sql.Tx, runID
 		return err
 	}
 
-	_, err = tx.ExecContext(ctx,
-		`INSERT INTO experiment_run_events (
-			run_id,
-			occurred_at,
-			actor,
-			level,
-			message,
-			metadata,
-			integrity_sha256
-		) VALUES ($1,$2,$3,$4,$5,$6,$7)`,
-		runID,
-		occurredAt,
-		actor,
-		level,
-		message,
-		metadataJSON,
-		integrity,
-	)
+	_, err = api.runStore(tx).InsertRunEvent(ctx, repo.RunEventRecord{
+		RunID:           runID,
+		OccurredAt:      occurredAt,
+		Actor:           actor,
+		Level:           level,
+		Message:         message,
+		Metadata:        metadataJSON,
+		IntegritySHA256: integrity,
+	})
 	return err
 }
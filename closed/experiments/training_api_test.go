@@ -0,0 +1,74 @@
+package experiments
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+)
+
+func TestExecuteExperimentRunReportsAllFieldErrors(t *testing.T) {
+	api := &experimentsAPI{}
+	body := `{
+		"experiment_id": "exp-1",
+		"dataset_version_id": "",
+		"dataset_version_ids": ["4b1f0c52-8a0e-4d7e-9a53-0d6b3c2c9d11", "4b1f0c52-8a0e-4d7e-9a53-0d6b3c2c9d11"],
+		"image_ref": "ghcr.io/Acme/train:latest",
+		"git_commit": "main",
+		"params": {"lr": 0.1, "layers": [1, 2]},
+		"resources": {"cpu": "two", "gpus": -1, "node_pool": "a100"}
+	}`
+	req := httptest.NewRequest(http.MethodPost, "/experiments/runs:execute", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	api.handleExecuteExperimentRun(rec, req)
+
+	var resp struct {
+		Error  string               `json:"error"`
+		Errors []httpapi.FieldError `json:"errors"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if rec.Code != http.StatusBadRequest || resp.Error != "validation_failed" {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body)
+	}
+	got := make([]string, 0, len(resp.Errors))
+	for _, fe := range resp.Errors {
+		got = append(got, fe.Pointer+" "+fe.Code)
+	}
+	want := []string{
+		"/experiment_id invalid_uuid",
+		"/dataset_version_id required",
+		"/dataset_version_ids/1 duplicate",
+		"/image_ref invalid_image_ref",
+		"/git_commit invalid_commit_sha",
+		"/params/layers invalid_type",
+		"/resources/cpu invalid_quantity",
+		"/resources/gpus out_of_range",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("errors:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestExecuteExperimentRunValidRequestIsRefused(t *testing.T) {
+	api := &experimentsAPI{}
+	body := `{
+		"experiment_id": "0d9c7f1e-2f54-4a0b-8f55-5a6f3f1d2c10",
+		"dataset_version_id": "4b1f0c52-8a0e-4d7e-9a53-0d6b3c2c9d11",
+		"image_ref": "` + validImageRef + `",
+		"git_repo": "https://github.com/acme/repo",
+		"git_commit": "deadbeef",
+		"params": {"lr": 0.1, "optimizer": "adam"},
+		"resources": {"cpu": "500m", "memory": "4Gi", "gpus": "1"}
+	}`
+	req := httptest.NewRequest(http.MethodPost, "/experiments/runs:execute", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	api.handleExecuteExperimentRun(rec, req)
+	if rec.Code != http.StatusNotImplemented || !strings.Contains(rec.Body.String(), "training_executor_disabled") {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body)
+	}
+}
@@ -1,4 +1,4 @@
-package main
+package experiments
 
 import (
 	"context"
@@ -10,6 +10,7 @@ import (
 	"github.com/animus-labs/animus-go/closed/internal/domain"
 	"github.com/animus-labs/animus-go/closed/internal/integrations/webhooks"
 	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
 	"github.com/animus-labs/animus-go/closed/internal/platform/redaction"
 	"github.com/animus-labs/animus-go/closed/internal/repo"
 	repopg "github.com/animus-labs/animus-go/closed/internal/repo/postgres"
@@ -99,8 +100,8 @@ func (api *experimentsAPI) handleCreateWebhookSubscription(w http.ResponseWriter
 	}
 
 	var req webhookSubscriptionRequest
-	if err := decodeJSON(r, &req); err != nil {
-		api.writeError(w, r, http.StatusBadRequest, "invalid_json")
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
 		return
 	}
 
@@ -170,7 +171,7 @@ func (api *experimentsAPI) handleListWebhookSubscriptions(w http.ResponseWriter,
 		return
 	}
 
-	limit := clampInt(parseIntQuery(r, "limit", 100), 1, 500)
+	limit := httpapi.Limit(r, 100, 500)
 	store := repopg.NewWebhookSubscriptionStore(api.db)
 	if store == nil {
 		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
@@ -208,8 +209,8 @@ func (api *experimentsAPI) handleUpdateWebhookSubscription(w http.ResponseWriter
 	}
 
 	var req webhookSubscriptionUpdateRequest
-	if err := decodeJSON(r, &req); err != nil {
-		api.writeError(w, r, http.StatusBadRequest, "invalid_json")
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
 		return
 	}
 
@@ -305,7 +306,7 @@ func (api *experimentsAPI) handleListWebhookDeliveries(w http.ResponseWriter, r
 		status = webhooks.DeliveryStatus(raw)
 	}
 
-	limit := clampInt(parseIntQuery(r, "limit", 200), 1, 500)
+	limit := httpapi.Limit(r, 200, 500)
 	store := repopg.NewWebhookDeliveryStore(api.db)
 	if store == nil {
 		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
@@ -352,7 +353,7 @@ func (api *experimentsAPI) handleListWebhookDeliveryAttempts(w http.ResponseWrit
 		return
 	}
 
-	limit := clampInt(parseIntQuery(r, "limit", 200), 1, 500)
+	limit := httpapi.Limit(r, 200, 500)
 	attemptStore := repopg.NewWebhookDeliveryAttemptStore(api.db)
 	if attemptStore == nil {
 		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
@@ -389,8 +390,8 @@ func (api *experimentsAPI) handleReplayWebhookDelivery(w http.ResponseWriter, r
 	}
 
 	var req webhookReplayRequest
-	if err := decodeJSON(r, &req); err != nil {
-		api.writeError(w, r, http.StatusBadRequest, "invalid_json")
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
 		return
 	}
 	replayToken := strings.TrimSpace(req.ReplayToken)
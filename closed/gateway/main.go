@@ -1,7 +1,8 @@
-package main
+package gateway
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,6 +12,7 @@ import (
 	"net/url"
 	"os"
 	"os/signal"
+	"slices"
 	"strconv"
 	"strings"
 	"syscall"
@@ -19,13 +21,21 @@ import (
 	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
 	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
 	"github.com/animus-labs/animus-go/closed/internal/platform/env"
+	"github.com/animus-labs/animus-go/closed/internal/platform/envelope"
 	"github.com/animus-labs/animus-go/closed/internal/platform/httpserver"
+	"github.com/animus-labs/animus-go/closed/internal/platform/inproc"
+	"github.com/animus-labs/animus-go/closed/internal/platform/migrate"
 	"github.com/animus-labs/animus-go/closed/internal/platform/postgres"
 	"github.com/animus-labs/animus-go/closed/internal/platform/rbac"
 	repopg "github.com/animus-labs/animus-go/closed/internal/repo/postgres"
+	"github.com/animus-labs/animus-go/closed/migrations"
+	"github.com/animus-labs/animus-go/core/contracts/errorcatalog"
+	"github.com/animus-labs/animus-go/core/contracts/openapi"
 )
 
-func main() {
+// Main runs the gateway as a standalone service proxying to the other
+// services over HTTP.
+func Main() {
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
 
 	ctx := context.Background()
@@ -39,7 +49,7 @@ func main() {
 		os.Exit(2)
 	}
 
-	dbCfg, err := postgres.ConfigFromEnv()
+	dbCfg, err := postgres.ConfigFromEnv("gateway")
 	if err != nil {
 		logger.Error("invalid database config", "error", err)
 		os.Exit(2)
@@ -51,6 +61,37 @@ func main() {
 	}
 	defer func() { _ = db.Close() }()
 
+	migrator, err := migrate.New(db, migrations.Files, logger)
+	if err != nil {
+		logger.Error("invalid migrations", "error", err)
+		os.Exit(2)
+	}
+	if done, err := migrate.Startup(ctx, migrator, os.Args[1:], os.Stdout); err != nil {
+		logger.Error("migrations failed", "error", err)
+		os.Exit(1)
+	} else if done {
+		return
+	}
+	httpserver.RegisterMetricsProvider(postgres.PrometheusMetrics("gateway", map[string]*sql.DB{"primary": db}))
+	handler := NewHandler(ctx, logger, &inproc.Resources{DB: db, Migrator: migrator})
+
+	cfg := httpserver.Config{
+		Service:         "gateway",
+		Addr:            addr,
+		ShutdownTimeout: shutdownTimeout,
+	}
+
+	if err := httpserver.Run(ctx, logger, cfg, httpserver.Wrap(logger, "gateway", handler)); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Error("server failed", "error", err)
+		os.Exit(1)
+	}
+}
+
+// NewHandler builds the gateway on res. Upstreams mounted in res.Services are
+// served in-process; the others are proxied to their *_BASE_URL. Invalid
+// configuration exits the process, as it does in Main.
+func NewHandler(ctx context.Context, logger *slog.Logger, res *inproc.Resources) http.Handler {
+	db, migrator := res.DB, res.Migrator
 	auditAppender := repopg.NewAuditAppender(db, nil)
 
 	authCfg, err := auth.ConfigFromEnv()
@@ -59,15 +100,17 @@ func main() {
 		os.Exit(2)
 	}
 
-	internalAuthSecret := env.String("ANIMUS_INTERNAL_AUTH_SECRET", "")
-	if strings.TrimSpace(internalAuthSecret) == "" {
+	internalAuthSecrets := auth.ParseInternalAuthSecrets(env.String("ANIMUS_INTERNAL_AUTH_SECRET", ""))
+	if len(internalAuthSecrets) == 0 {
 		logger.Error("missing internal auth secret", "env", "ANIMUS_INTERNAL_AUTH_SECRET")
 		os.Exit(2)
 	}
+	internalAuthSecret := internalAuthSecrets.Current()
 
 	var authenticator auth.Authenticator
 	var oidcService *auth.OIDCService
 	var samlService *auth.SAMLService
+	var passwordService *auth.PasswordService
 	var sessionManager *auth.SessionManager
 	switch authCfg.Mode {
 	case auth.ModeDev:
@@ -79,6 +122,19 @@ func main() {
 			Audit:         auditAppender,
 			MaxConcurrent: authCfg.SessionMaxConcurrent,
 		}
+		if authCfg.SessionRefresh {
+			encryptionCfg, err := envelope.ConfigFromEnv()
+			if err != nil {
+				logger.Error("invalid encryption config", "error", err)
+				os.Exit(2)
+			}
+			encrypter, err := envelope.New(encryptionCfg)
+			if err != nil {
+				logger.Error("encryption init failed", "error", err)
+				os.Exit(2)
+			}
+			sessionManager.RefreshTokens = encrypter
+		}
 		svc, err := auth.NewOIDCService(ctx, authCfg, sessionManager)
 		if err != nil {
 			logger.Error("oidc init failed", "error", err)
@@ -100,6 +156,25 @@ func main() {
 		}
 		samlService = svc
 		authenticator = svc
+	case auth.ModeLDAP, auth.ModeStatic:
+		sessionStore := repopg.NewSessionStore(db)
+		sessionManager = &auth.SessionManager{
+			Store:         sessionStore,
+			Audit:         auditAppender,
+			MaxConcurrent: authCfg.SessionMaxConcurrent,
+		}
+		verifier, err := auth.NewPasswordVerifier(authCfg)
+		if err != nil {
+			logger.Error("password verifier init failed", "mode", authCfg.Mode, "error", err)
+			os.Exit(1)
+		}
+		svc, err := auth.NewPasswordService(authCfg, verifier, sessionManager)
+		if err != nil {
+			logger.Error("password login init failed", "error", err)
+			os.Exit(1)
+		}
+		passwordService = svc
+		authenticator = svc
 	case auth.ModeDisabled:
 		authenticator = nil
 	default:
@@ -107,10 +182,40 @@ func main() {
 		os.Exit(2)
 	}
 
+	rbacAllowDirect, err := env.Bool("AUTH_RBAC_ALLOW_DIRECT_ROLES", true)
+	if err != nil {
+		logger.Error("invalid env", "error", err)
+		os.Exit(2)
+	}
+
+	serviceAccountCfg, err := auth.ServiceAccountConfigFromEnv()
+	if err != nil {
+		logger.Error("invalid service account config", "error", err)
+		os.Exit(2)
+	}
+	var (
+		serviceAccounts     *auth.ServiceAccountManager
+		runTokenRevocations *auth.RunTokenRevocationManager
+	)
 	if authenticator != nil {
+		runTokenRevocations = &auth.RunTokenRevocationManager{
+			Store: repopg.NewRunTokenRevocationStore(db),
+			Audit: auditAppender,
+		}
 		authenticator = auth.RunTokenAuthenticator{
-			Secret: internalAuthSecret,
-			Next:   authenticator,
+			Secret:          internalAuthSecret,
+			PreviousSecrets: internalAuthSecrets.Previous(),
+			Revocations:     runTokenRevocations.Store,
+			Next:            authenticator,
+		}
+		serviceAccounts = &auth.ServiceAccountManager{
+			Store:  repopg.NewServiceAccountStore(db),
+			Audit:  auditAppender,
+			Config: serviceAccountCfg,
+		}
+		authenticator = auth.ServiceAccountAuthenticator{
+			Manager: serviceAccounts,
+			Next:    authenticator,
 		}
 	}
 
@@ -148,12 +253,16 @@ func main() {
 				}
 				return auth.ErrForbidden
 			case http.MethodPost:
+				scope := ""
 				switch path {
 				case "/api/experiments/experiment-runs/" + runID + "/metrics":
-					return nil
+					scope = auth.RunTokenScopeMetricsWrite
 				case artifactBase:
-					return nil
+					scope = auth.RunTokenScopeArtifactsWrite
 				case "/api/experiments/experiment-runs/" + runID + "/events":
+					scope = auth.RunTokenScopeEventsWrite
+				}
+				if scope != "" && slices.Contains(identity.Scopes, scope) {
 					return nil
 				}
 				return auth.ErrForbidden
@@ -162,7 +271,7 @@ func main() {
 			}
 		}
 
-		return nil
+		return authorizeProjectScope(r, identity)
 	}
 	auditFn := func(ctx context.Context, event auth.DenyEvent) error {
 		auditCtx, cancel := context.WithTimeout(ctx, 750*time.Millisecond)
@@ -193,7 +302,7 @@ func main() {
 		return auth.Middleware{
 			Logger:        logger,
 			Authenticator: authenticator,
-			Authorize:     adminAuthorizer.Authorize,
+			Authorize:     authorizeGlobalAdmin(adminAuthorizer),
 			Audit:         auditFn,
 		}.Wrap(handler)
 	}
@@ -210,6 +319,9 @@ func main() {
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/healthz", httpserver.Healthz("gateway"))
+	mux.HandleFunc("/version", httpserver.BuildInfo("gateway"))
+	mux.Handle("GET "+openapi.ServicePath, openapi.AggregateHandler(openapi.GatewayMounts))
+	mux.Handle("GET "+errorcatalog.ServicePath, errorcatalog.Handler())
 	mux.HandleFunc(
 		"/readyz",
 		httpserver.ReadyzWithChecks(
@@ -226,8 +338,23 @@ func main() {
 	)
 	httpserver.RegisterMetrics(mux, "gateway")
 
+	if serviceAccounts != nil {
+		serviceAccountsHandler := adminProtected(auth.ServiceAccountsHandler(serviceAccounts))
+		mux.Handle("/auth/service-accounts", serviceAccountsHandler)
+		mux.Handle("/auth/service-accounts/", serviceAccountsHandler)
+	}
+	if runTokenRevocations != nil {
+		mux.Handle("/auth/run-tokens/revocations", adminProtected(auth.RunTokenRevocationsHandler(runTokenRevocations)))
+	}
+
 	if oidcService != nil {
 		mux.HandleFunc("/auth/logout", oidcService.LogoutHandler())
+		if authCfg.CookieSessions() {
+			mux.HandleFunc("/auth/csrf", oidcService.CSRFHandler())
+		}
+		if authCfg.SessionRefresh {
+			mux.HandleFunc("/auth/refresh", oidcService.RefreshHandler())
+		}
 		if sessionManager != nil {
 			mux.Handle("/auth/force-logout", adminProtected(auth.ForceLogoutHandler(sessionManager)))
 		}
@@ -270,6 +397,25 @@ func main() {
 		}
 	} else if samlService != nil {
 		mux.HandleFunc("/auth/logout", samlService.LogoutHandler())
+		mux.HandleFunc("/auth/csrf", auth.CSRFHandler(authCfg, sessionManager))
+		if sessionManager != nil {
+			mux.Handle("/auth/force-logout", adminProtected(auth.ForceLogoutHandler(sessionManager)))
+		}
+		mux.Handle("/auth/session", sessionProtected(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			identity, _ := auth.IdentityFromContext(r.Context())
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"subject": identity.Subject,
+				"email":   identity.Email,
+				"roles":   identity.Roles,
+			})
+		})))
+		mux.Handle("/api/auth/me", sessionProtected(authMeHandler()))
+	} else if passwordService != nil {
+		mux.HandleFunc("/auth/login", passwordService.LoginHandler())
+		mux.HandleFunc("/auth/logout", passwordService.LogoutHandler())
+		mux.HandleFunc("/auth/csrf", auth.CSRFHandler(authCfg, sessionManager))
 		if sessionManager != nil {
 			mux.Handle("/auth/force-logout", adminProtected(auth.ForceLogoutHandler(sessionManager)))
 		}
@@ -315,37 +461,95 @@ func main() {
 		mux.Handle("/api/auth/me", sessionProtected(authMeHandler()))
 	}
 
-	datasetRegistryProxy, err := newReverseProxy(logger, internalAuthSecret, env.String("DATASET_REGISTRY_BASE_URL", "http://localhost:8081"))
+	internalMTLSCfg, err := auth.InternalMTLSConfigFromEnv()
+	if err != nil {
+		logger.Error("invalid internal mtls config", "error", err)
+		os.Exit(2)
+	}
+	internalTransport, err := internalMTLSCfg.Transport()
+	if err != nil {
+		logger.Error("internal mtls init failed", "error", err)
+		os.Exit(2)
+	}
+
+	// Mounted services are served in-process; the rest, and status probes of
+	// the rest, go over the network.
+	statusTransport := internalTransport
+	if res.Services != nil {
+		res.Services.Fallback = internalTransport
+		statusTransport = res.Services
+	}
+	upstream := func(service, key, fallback string) (string, http.RoundTripper) {
+		if res.Services.Serves(service) {
+			return inproc.URL(service), res.Services
+		}
+		return env.String(key, fallback), internalTransport
+	}
+	datasetRegistryURL, datasetRegistryTransport := upstream("dataset-registry", "DATASET_REGISTRY_BASE_URL", "http://localhost:8081")
+	qualityURL, qualityTransport := upstream("quality", "QUALITY_BASE_URL", "http://localhost:8082")
+	experimentsURL, experimentsTransport := upstream("experiments", "EXPERIMENTS_BASE_URL", "http://localhost:8083")
+	lineageURL, lineageTransport := upstream("lineage", "LINEAGE_BASE_URL", "http://localhost:8084")
+	auditURL, auditTransport := upstream("audit", "AUDIT_BASE_URL", "http://localhost:8085")
+
+	datasetRegistryProxy, err := newReverseProxy(logger, internalAuthSecret, datasetRegistryTransport, datasetRegistryURL)
 	if err != nil {
 		logger.Error("proxy init failed", "service", "dataset-registry", "error", err)
 		os.Exit(2)
 	}
-	qualityProxy, err := newReverseProxy(logger, internalAuthSecret, env.String("QUALITY_BASE_URL", "http://localhost:8082"))
+	qualityProxy, err := newReverseProxy(logger, internalAuthSecret, qualityTransport, qualityURL)
 	if err != nil {
 		logger.Error("proxy init failed", "service", "quality", "error", err)
 		os.Exit(2)
 	}
-	experimentsProxy, err := newReverseProxy(logger, internalAuthSecret, env.String("EXPERIMENTS_BASE_URL", "http://localhost:8083"))
+	experimentsProxy, err := newReverseProxy(logger, internalAuthSecret, experimentsTransport, experimentsURL)
 	if err != nil {
 		logger.Error("proxy init failed", "service", "experiments", "error", err)
 		os.Exit(2)
 	}
-	lineageProxy, err := newReverseProxy(logger, internalAuthSecret, env.String("LINEAGE_BASE_URL", "http://localhost:8084"))
+	lineageProxy, err := newReverseProxy(logger, internalAuthSecret, lineageTransport, lineageURL)
 	if err != nil {
 		logger.Error("proxy init failed", "service", "lineage", "error", err)
 		os.Exit(2)
 	}
-	auditProxy, err := newReverseProxy(logger, internalAuthSecret, env.String("AUDIT_BASE_URL", "http://localhost:8085"))
+	auditProxy, err := newReverseProxy(logger, internalAuthSecret, auditTransport, auditURL)
 	if err != nil {
 		logger.Error("proxy init failed", "service", "audit", "error", err)
 		os.Exit(2)
 	}
 
-	mux.Handle("/api/dataset-registry/", protected(http.StripPrefix("/api/dataset-registry", datasetRegistryProxy)))
-	mux.Handle("/api/quality/", protected(http.StripPrefix("/api/quality", qualityProxy)))
-	mux.Handle("/api/experiments/", protected(http.StripPrefix("/api/experiments", experimentsProxy)))
-	mux.Handle("/api/lineage/", protected(http.StripPrefix("/api/lineage", lineageProxy)))
-	mux.Handle("/api/audit/", protected(http.StripPrefix("/api/audit", auditProxy)))
+	schemaGuardCfg, err := schemaGuardConfigFromEnv()
+	if err != nil {
+		logger.Error("invalid schema guard config", "error", err)
+		os.Exit(2)
+	}
+	schemaGuard, err := newSchemaGuard(logger, schemaGuardCfg, []string{"dataset-registry", "quality", "experiments", "lineage", "audit"})
+	if err != nil {
+		logger.Error("schema guard init failed", "error", err)
+		os.Exit(2)
+	}
+
+	mux.Handle("/api/dataset-registry/", protected(http.StripPrefix("/api/dataset-registry", schemaGuard.Wrap("dataset-registry", datasetRegistryProxy))))
+	mux.Handle("/api/quality/", protected(http.StripPrefix("/api/quality", schemaGuard.Wrap("quality", qualityProxy))))
+	mux.Handle("/api/experiments/", protected(http.StripPrefix("/api/experiments", schemaGuard.Wrap("experiments", experimentsProxy))))
+	mux.Handle("/api/lineage/", protected(http.StripPrefix("/api/lineage", schemaGuard.Wrap("lineage", lineageProxy))))
+	mux.Handle("/api/audit/", protected(http.StripPrefix("/api/audit", schemaGuard.Wrap("audit", auditProxy))))
+	mux.Handle("GET /api/overview/runs/{run_id}", protected(runOverviewHandler(map[string]http.Handler{
+		"experiments": experimentsProxy,
+		"lineage":     lineageProxy,
+		"audit":       auditProxy,
+	})))
+	mux.Handle("GET /api/status", sessionProtected(platformStatusHandler(&http.Client{Transport: statusTransport}, db.PingContext, []statusUpstream{
+		{Name: "dataset-registry", BaseURL: datasetRegistryURL},
+		{Name: "quality", BaseURL: qualityURL},
+		{Name: "experiments", BaseURL: experimentsURL},
+		{Name: "lineage", BaseURL: lineageURL},
+		{Name: "audit", BaseURL: auditURL},
+	})))
+	mux.Handle("GET /admin/migrations", adminProtected(migrationsStatusHandler(migrator.Status)))
+	mux.Handle("/api/search", protected(searchHandler(db, repopg.NewRoleBindingStore(db), rbacAllowDirect)))
+	// One-time dataset share links are redeemed by external reviewers without
+	// a session; the registry checks the token.
+	mux.Handle("/share/dataset-shares/", http.StripPrefix("/share", datasetRegistryProxy))
 
 	consoleUpstreamRaw := strings.TrimSpace(env.String("ANIMUS_CONSOLE_UPSTREAM_URL", ""))
 	if consoleUpstreamRaw == "" {
@@ -373,19 +577,14 @@ func main() {
 		httpserver.Healthz("gateway")(w, r)
 	})
 
-	cfg := httpserver.Config{
-		Service:         "gateway",
-		Addr:            addr,
-		ShutdownTimeout: shutdownTimeout,
-	}
-
-	if err := httpserver.Run(ctx, logger, cfg, httpserver.Wrap(logger, "gateway", mux)); err != nil && !errors.Is(err, http.ErrServerClosed) {
-		logger.Error("server failed", "error", err)
-		os.Exit(1)
+	var handler http.Handler = mux
+	if oidcService != nil {
+		handler = oidcService.RenewSessions(handler)
 	}
+	return auth.RequireCSRF(authCfg, handler)
 }
 
-func newReverseProxy(logger *slog.Logger, internalAuthSecret string, target string) (http.Handler, error) {
+func newReverseProxy(logger *slog.Logger, internalAuthSecret string, transport http.RoundTripper, target string) (http.Handler, error) {
 	upstream, err := url.Parse(target)
 	if err != nil {
 		return nil, err
@@ -393,8 +592,14 @@ func newReverseProxy(logger *slog.Logger, internalAuthSecret string, target stri
 	if upstream.Scheme == "" || upstream.Host == "" {
 		return nil, fmt.Errorf("invalid upstream url: %q", target)
 	}
+	if transport != nil && upstream.Scheme != "https" && upstream.Scheme != inproc.Scheme {
+		return nil, fmt.Errorf("internal mtls requires an https upstream url: %q", target)
+	}
 
 	proxy := httputil.NewSingleHostReverseProxy(upstream)
+	if transport != nil {
+		proxy.Transport = transport
+	}
 	director := proxy.Director
 	proxy.Director = func(r *http.Request) {
 		director(r)
@@ -412,6 +617,9 @@ func newReverseProxy(logger *slog.Logger, internalAuthSecret string, target stri
 			if roles != "" {
 				r.Header.Set(auth.HeaderRoles, roles)
 			}
+			if identity.ProjectID != "" {
+				r.Header.Set("X-Project-Id", identity.ProjectID)
+			}
 			ts := strconv.FormatInt(time.Now().UTC().Unix(), 10)
 			sig, err := auth.ComputeInternalAuthSignature(
 				internalAuthSecret,
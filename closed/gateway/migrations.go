@@ -0,0 +1,26 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	"github.com/animus-labs/animus-go/closed/internal/platform/migrate"
+)
+
+// migrationsStatusHandler serves GET /admin/migrations: the schema versions
+// applied to the shared database against the migrations built into this
+// gateway, so drift between environments shows up without psql.
+func migrationsStatusHandler(status func(context.Context) (migrate.Status, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		st, err := status(r.Context())
+		if err != nil {
+			httpapi.WriteError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		_ = json.NewEncoder(w).Encode(st)
+	})
+}
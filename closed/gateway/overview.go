@@ -0,0 +1,203 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+)
+
+const (
+	runOverviewTimeout     = 5 * time.Second
+	maxOverviewSectionSize = 4 << 20
+)
+
+// overviewSection is one upstream read composed into an overview. Path is the
+// upstream path with %s for the escaped run id; a section that is not
+// Required degrades to a failure marker instead of failing the overview.
+type overviewSection struct {
+	Name     string
+	Service  string
+	Path     string
+	Required bool
+}
+
+// runOverviewSections are the reads the console makes to render a run page.
+var runOverviewSections = []overviewSection{
+	{Name: "run", Service: "experiments", Path: "/experiment-runs/%s", Required: true},
+	{Name: "metrics", Service: "experiments", Path: "/experiment-runs/%s/metrics"},
+	{Name: "artifacts", Service: "experiments", Path: "/experiment-runs/%s/artifacts"},
+	{Name: "events", Service: "experiments", Path: "/experiment-runs/%s/events"},
+	{Name: "policy_decisions", Service: "experiments", Path: "/policy-decisions?run_id=%s"},
+	{Name: "lineage", Service: "lineage", Path: "/runs/%s"},
+	{Name: "audit", Service: "audit", Path: "/events?resource_id=%s&limit=50"},
+}
+
+type overviewFailure struct {
+	Section string `json:"section"`
+	Service string `json:"service"`
+	Status  int    `json:"status,omitempty"`
+	Error   string `json:"error"`
+}
+
+type sectionResult struct {
+	status int
+	body   json.RawMessage
+	err    string
+}
+
+// runOverviewHandler serves GET /api/overview/runs/{run_id}. Sections are
+// fetched concurrently through the same upstream handlers the /api/<service>/
+// proxies use, so identity headers, internal signatures and upstream RBAC
+// apply to every read exactly as if the console had made it.
+func runOverviewHandler(upstreams map[string]http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		runID := strings.TrimSpace(r.PathValue("run_id"))
+		if runID == "" {
+			httpapi.WriteError(w, r, http.StatusBadRequest, "run_id_required")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), runOverviewTimeout)
+		defer cancel()
+
+		results := make([]sectionResult, len(runOverviewSections))
+		var wg sync.WaitGroup
+		for i, section := range runOverviewSections {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				results[i] = fetchOverviewSection(ctx, r, upstreams[section.Service], fmt.Sprintf(section.Path, url.PathEscape(runID)))
+			}()
+		}
+		wg.Wait()
+
+		out := map[string]any{"run_id": runID}
+		failures := []overviewFailure{}
+		for i, section := range runOverviewSections {
+			result := results[i]
+			if result.err == "" {
+				out[section.Name] = result.body
+				continue
+			}
+			if section.Required {
+				status := result.status
+				if status == 0 {
+					status = http.StatusBadGateway
+				}
+				httpapi.WriteError(w, r, status, result.err)
+				return
+			}
+			out[section.Name] = nil
+			failures = append(failures, overviewFailure{
+				Section: section.Name,
+				Service: section.Service,
+				Status:  result.status,
+				Error:   result.err,
+			})
+		}
+		out["partial"] = len(failures) > 0
+		out["failures"] = failures
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(out)
+	})
+}
+
+// fetchOverviewSection runs one GET through upstream and returns its JSON
+// body, or the upstream status and error code when it did not succeed.
+func fetchOverviewSection(ctx context.Context, parent *http.Request, upstream http.Handler, target string) sectionResult {
+	if upstream == nil {
+		return sectionResult{err: "service_unavailable"}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return sectionResult{err: "invalid_request"}
+	}
+	req.RemoteAddr = parent.RemoteAddr
+	req.Header.Set("Accept", "application/json")
+	for _, name := range []string{"X-Request-Id", "X-Project-Id"} {
+		if value := parent.Header.Get(name); value != "" {
+			req.Header.Set(name, value)
+		}
+	}
+
+	rec := &bufferedResponse{header: make(http.Header), limit: maxOverviewSectionSize}
+	serveBuffered(upstream, rec, req)
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	switch {
+	case rec.overflow:
+		return sectionResult{status: rec.status, err: "response_too_large"}
+	case (rec.status < 200 || rec.status > 299) && errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return sectionResult{status: http.StatusGatewayTimeout, err: "upstream_timeout"}
+	case rec.status < 200 || rec.status > 299:
+		var payload struct {
+			Error string `json:"error"`
+		}
+		_ = json.Unmarshal(rec.buf.Bytes(), &payload)
+		if payload.Error == "" {
+			payload.Error = strings.ReplaceAll(strings.ToLower(http.StatusText(rec.status)), " ", "_")
+		}
+		return sectionResult{status: rec.status, err: payload.Error}
+	case !json.Valid(rec.buf.Bytes()):
+		return sectionResult{status: rec.status, err: "invalid_upstream_response"}
+	}
+	return sectionResult{status: rec.status, body: json.RawMessage(bytes.TrimSpace(rec.buf.Bytes()))}
+}
+
+// serveBuffered runs upstream into rec. httputil.ReverseProxy aborts with
+// http.ErrAbortHandler when copying the body fails, which a refused oversized
+// write does; outside the server's own goroutine that panic must be caught.
+func serveBuffered(upstream http.Handler, rec *bufferedResponse, req *http.Request) {
+	defer func() {
+		if v := recover(); v != nil {
+			if v != http.ErrAbortHandler {
+				panic(v)
+			}
+			if !rec.overflow {
+				rec.status = http.StatusBadGateway
+			}
+		}
+	}()
+	upstream.ServeHTTP(rec, req)
+}
+
+// bufferedResponse collects an upstream response in memory, refusing writes
+// past limit so a large listing cannot balloon the composed document.
+type bufferedResponse struct {
+	header   http.Header
+	status   int
+	buf      bytes.Buffer
+	limit    int
+	overflow bool
+}
+
+func (b *bufferedResponse) Header() http.Header { return b.header }
+
+func (b *bufferedResponse) WriteHeader(status int) {
+	if b.status == 0 {
+		b.status = status
+	}
+}
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	if b.status == 0 {
+		b.status = http.StatusOK
+	}
+	if b.buf.Len()+len(p) > b.limit {
+		b.overflow = true
+		return 0, errors.New("overview section too large")
+	}
+	return b.buf.Write(p)
+}
@@ -0,0 +1,129 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+)
+
+func serveOverview(t *testing.T, upstreams map[string]http.Handler, runID string) *httptest.ResponseRecorder {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.Handle("GET /api/overview/runs/{run_id}", runOverviewHandler(upstreams))
+	req := httptest.NewRequest(http.MethodGet, "/api/overview/runs/"+runID, nil)
+	req.Header.Set("X-Project-Id", "proj-1")
+	req = req.WithContext(auth.ContextWithIdentity(req.Context(), auth.Identity{Subject: "user-1"}))
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRunOverviewComposesSectionsWithFailureMarkers(t *testing.T) {
+	experiments := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if identity, ok := auth.IdentityFromContext(r.Context()); !ok || identity.Subject != "user-1" || r.Header.Get("X-Project-Id") != "proj-1" {
+			t.Errorf("identity or project not forwarded to %s", r.URL)
+		}
+		switch r.URL.Path {
+		case "/experiment-runs/run-1":
+			_, _ = w.Write([]byte(`{"run_id":"run-1","status":"succeeded"}`))
+		case "/policy-decisions":
+			if r.URL.Query().Get("run_id") != "run-1" {
+				t.Errorf("policy decisions query %q", r.URL.RawQuery)
+			}
+			_, _ = w.Write([]byte(`{"decisions":[]}`))
+		default:
+			_, _ = w.Write([]byte(`{"items":[]}`))
+		}
+	})
+	audit := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("resource_id") != "run-1" {
+			t.Errorf("audit query %q", r.URL.RawQuery)
+		}
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"error":"forbidden"}`))
+	})
+
+	rec := serveOverview(t, map[string]http.Handler{"experiments": experiments, "audit": audit}, "run-1")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body)
+	}
+	var out struct {
+		Run      map[string]any    `json:"run"`
+		Metrics  json.RawMessage   `json:"metrics"`
+		Lineage  json.RawMessage   `json:"lineage"`
+		Audit    json.RawMessage   `json:"audit"`
+		Partial  bool              `json:"partial"`
+		Failures []overviewFailure `json:"failures"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &out); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if out.Run["status"] != "succeeded" || string(out.Metrics) != `{"items":[]}` {
+		t.Fatalf("run=%v metrics=%s", out.Run, out.Metrics)
+	}
+	if !out.Partial || string(out.Lineage) != "null" || string(out.Audit) != "null" || len(out.Failures) != 2 {
+		t.Fatalf("partial=%v failures=%+v", out.Partial, out.Failures)
+	}
+	if out.Failures[0] != (overviewFailure{Section: "lineage", Service: "lineage", Error: "service_unavailable"}) ||
+		out.Failures[1] != (overviewFailure{Section: "audit", Service: "audit", Status: http.StatusForbidden, Error: "forbidden"}) {
+		t.Fatalf("failures=%+v", out.Failures)
+	}
+}
+
+func TestRunOverviewFailsWhenRunIsMissing(t *testing.T) {
+	experiments := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/experiment-runs/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"not_found"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	})
+	rec := serveOverview(t, map[string]http.Handler{"experiments": experiments}, "missing")
+	if rec.Code != http.StatusNotFound || !strings.Contains(rec.Body.String(), `"not_found"`) {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body)
+	}
+}
+
+// A reverse proxy aborts with a panic when the buffered copy is refused; the
+// section must degrade instead of taking the gateway down.
+func TestRunOverviewCapsSectionThroughProxy(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/experiment-runs/run-1/events" {
+			_, _ = w.Write([]byte(`"` + strings.Repeat("x", maxOverviewSectionSize) + `"`))
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer upstream.Close()
+	target, _ := url.Parse(upstream.URL)
+	proxy := httputil.NewSingleHostReverseProxy(target)
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /api/overview/runs/{run_id}", runOverviewHandler(map[string]http.Handler{"experiments": proxy}))
+	req := httptest.NewRequest(http.MethodGet, "/api/overview/runs/run-1", nil)
+	req = req.WithContext(context.WithValue(req.Context(), http.ServerContextKey, &http.Server{}))
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	var out struct {
+		Failures []overviewFailure `json:"failures"`
+	}
+	if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &out) != nil {
+		t.Fatalf("status=%d", rec.Code)
+	}
+	found := false
+	for _, failure := range out.Failures {
+		found = found || (failure.Section == "events" && failure.Error == "response_too_large")
+	}
+	if !found {
+		t.Fatalf("failures=%+v", out.Failures)
+	}
+}
@@ -0,0 +1,579 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/env"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	schemaGuardModeOff     = "off"
+	schemaGuardModeReport  = "report"
+	schemaGuardModeEnforce = "enforce"
+
+	schemaGuardMaxViolations = 20
+	schemaGuardMaxDepth      = 64
+)
+
+type schemaGuardConfig struct {
+	Mode         string
+	SpecDir      string
+	MaxBodyBytes int64
+}
+
+func schemaGuardConfigFromEnv() (schemaGuardConfig, error) {
+	cfg := schemaGuardConfig{
+		Mode:    strings.ToLower(strings.TrimSpace(env.String("GATEWAY_SCHEMA_GUARD_MODE", schemaGuardModeOff))),
+		SpecDir: strings.TrimSpace(env.String("GATEWAY_SCHEMA_GUARD_SPEC_DIR", "core/contracts/openapi")),
+	}
+	maxBody, err := env.Int("GATEWAY_SCHEMA_GUARD_MAX_BODY_BYTES", 10<<20)
+	if err != nil {
+		return schemaGuardConfig{}, err
+	}
+	cfg.MaxBodyBytes = int64(maxBody)
+
+	switch cfg.Mode {
+	case schemaGuardModeOff, schemaGuardModeReport, schemaGuardModeEnforce:
+	default:
+		return schemaGuardConfig{}, fmt.Errorf("GATEWAY_SCHEMA_GUARD_MODE must be one of off, report, enforce")
+	}
+	if cfg.Mode != schemaGuardModeOff {
+		if cfg.SpecDir == "" {
+			return schemaGuardConfig{}, errors.New("GATEWAY_SCHEMA_GUARD_SPEC_DIR is required when the schema guard is enabled")
+		}
+		if cfg.MaxBodyBytes <= 0 {
+			return schemaGuardConfig{}, errors.New("GATEWAY_SCHEMA_GUARD_MAX_BODY_BYTES must be positive")
+		}
+	}
+	return cfg, nil
+}
+
+// schemaGuard validates JSON request bodies of proxied write routes against the
+// request schemas published in core/contracts/openapi. Only the keywords used by
+// those contracts are checked; anything else is accepted as-is.
+type schemaGuard struct {
+	logger       *slog.Logger
+	enforce      bool
+	maxBodyBytes int64
+	specs        map[string]*schemaGuardSpec
+	patterns     sync.Map
+}
+
+type schemaGuardSpec struct {
+	routes  []schemaGuardRoute
+	schemas map[string]any
+}
+
+type schemaGuardRoute struct {
+	method   string
+	template string
+	pattern  *regexp.Regexp
+	params   int
+	required bool
+	schema   map[string]any
+}
+
+type schemaViolation struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// newSchemaGuard loads <service>.yaml from the spec directory for every service.
+// It returns nil when the guard is off.
+func newSchemaGuard(logger *slog.Logger, cfg schemaGuardConfig, services []string) (*schemaGuard, error) {
+	if cfg.Mode == schemaGuardModeOff {
+		return nil, nil
+	}
+	guard := &schemaGuard{
+		logger:       logger,
+		enforce:      cfg.Mode == schemaGuardModeEnforce,
+		maxBodyBytes: cfg.MaxBodyBytes,
+		specs:        make(map[string]*schemaGuardSpec, len(services)),
+	}
+	for _, service := range services {
+		path := filepath.Join(cfg.SpecDir, service+".yaml")
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", path, err)
+		}
+		spec, err := parseSchemaGuardSpec(data)
+		if err != nil {
+			return nil, fmt.Errorf("load %s: %w", path, err)
+		}
+		guard.specs[service] = spec
+	}
+	return guard, nil
+}
+
+func parseSchemaGuardSpec(data []byte) (*schemaGuardSpec, error) {
+	var doc struct {
+		Paths      map[string]map[string]any `yaml:"paths"`
+		Components struct {
+			Schemas map[string]any `yaml:"schemas"`
+		} `yaml:"components"`
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	spec := &schemaGuardSpec{schemas: doc.Components.Schemas}
+	if spec.schemas == nil {
+		spec.schemas = map[string]any{}
+	}
+	for template, item := range doc.Paths {
+		pattern, params, err := compilePathTemplate(template)
+		if err != nil {
+			return nil, fmt.Errorf("path %s: %w", template, err)
+		}
+		for _, method := range []string{http.MethodPost, http.MethodPut, http.MethodPatch} {
+			op, ok := item[strings.ToLower(method)].(map[string]any)
+			if !ok {
+				continue
+			}
+			route := schemaGuardRoute{method: method, template: template, pattern: pattern, params: params}
+			if body, ok := op["requestBody"].(map[string]any); ok {
+				route.required, _ = body["required"].(bool)
+				if content, ok := body["content"].(map[string]any); ok {
+					if media, ok := content["application/json"].(map[string]any); ok {
+						route.schema, _ = media["schema"].(map[string]any)
+					}
+				}
+			}
+			if route.schema != nil {
+				if err := checkSchemaRefs(route.schema, spec.schemas); err != nil {
+					return nil, fmt.Errorf("%s %s: %w", method, template, err)
+				}
+			}
+			spec.routes = append(spec.routes, route)
+		}
+	}
+	// Literal routes such as /datasets/search must win over /datasets/{id}.
+	sort.Slice(spec.routes, func(i, j int) bool {
+		a, b := spec.routes[i], spec.routes[j]
+		if a.params != b.params {
+			return a.params < b.params
+		}
+		if len(a.template) != len(b.template) {
+			return len(a.template) > len(b.template)
+		}
+		return a.template < b.template
+	})
+	return spec, nil
+}
+
+var pathTemplateParam = regexp.MustCompile(`\{[^/{}]+\}`)
+
+func compilePathTemplate(template string) (*regexp.Regexp, int, error) {
+	var (
+		b      strings.Builder
+		last   int
+		params int
+	)
+	b.WriteString("^")
+	for _, loc := range pathTemplateParam.FindAllStringIndex(template, -1) {
+		b.WriteString(regexp.QuoteMeta(template[last:loc[0]]))
+		b.WriteString(`[^/]+?`)
+		last = loc[1]
+		params++
+	}
+	b.WriteString(regexp.QuoteMeta(template[last:]))
+	b.WriteString("$")
+	pattern, err := regexp.Compile(b.String())
+	return pattern, params, err
+}
+
+func checkSchemaRefs(node any, schemas map[string]any) error {
+	switch typed := node.(type) {
+	case map[string]any:
+		if ref, ok := typed["$ref"].(string); ok {
+			if _, ok := lookupSchemaRef(ref, schemas); !ok {
+				return fmt.Errorf("unresolved $ref %q", ref)
+			}
+		}
+		for _, child := range typed {
+			if err := checkSchemaRefs(child, schemas); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, child := range typed {
+			if err := checkSchemaRefs(child, schemas); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func lookupSchemaRef(ref string, schemas map[string]any) (map[string]any, bool) {
+	name, ok := strings.CutPrefix(ref, "#/components/schemas/")
+	if !ok {
+		return nil, false
+	}
+	schema, ok := schemas[name].(map[string]any)
+	return schema, ok
+}
+
+// Wrap validates requests for service before handing them to next. Paths are
+// matched after the /api/<service> prefix has been stripped.
+func (g *schemaGuard) Wrap(service string, next http.Handler) http.Handler {
+	if g == nil || g.specs[service] == nil {
+		return next
+	}
+	spec := g.specs[service]
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route, ok := spec.match(r.Method, r.URL.Path)
+		if !ok || route.schema == nil || !isJSONRequest(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, g.maxBodyBytes+1))
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte("{\"error\":\"invalid_body\"}\n"))
+			return
+		}
+		if int64(len(body)) > g.maxBodyBytes {
+			if g.enforce {
+				g.reject(w, r, service, route, http.StatusRequestEntityTooLarge, "request_too_large", nil)
+				return
+			}
+			r.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(body), r.Body), Closer: r.Body}
+			next.ServeHTTP(w, r)
+			return
+		}
+		_ = r.Body.Close()
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		r.ContentLength = int64(len(body))
+
+		if violations, code := spec.validateBody(g, route, body); code != "" {
+			if g.reject(w, r, service, route, http.StatusBadRequest, code, violations) {
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// reject reports a bad request and, in enforce mode, answers it. It returns
+// whether the response has been written.
+func (g *schemaGuard) reject(w http.ResponseWriter, r *http.Request, service string, route schemaGuardRoute, status int, code string, violations []schemaViolation) bool {
+	requestID := r.Header.Get("X-Request-Id")
+	g.logger.Warn("schema guard rejected request",
+		"request_id", requestID,
+		"service", service,
+		"method", r.Method,
+		"route", route.template,
+		"error", code,
+		"violations", violations,
+		"enforced", g.enforce,
+	)
+	if !g.enforce {
+		return false
+	}
+	payload := map[string]any{"error": code}
+	if requestID != "" {
+		payload["request_id"] = requestID
+	}
+	if len(violations) > 0 {
+		payload["violations"] = violations
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(payload)
+	return true
+}
+
+func (s *schemaGuardSpec) match(method, path string) (schemaGuardRoute, bool) {
+	for _, route := range s.routes {
+		if route.method == method && route.pattern.MatchString(path) {
+			return route, true
+		}
+	}
+	return schemaGuardRoute{}, false
+}
+
+func (s *schemaGuardSpec) validateBody(g *schemaGuard, route schemaGuardRoute, body []byte) ([]schemaViolation, string) {
+	if len(bytes.TrimSpace(body)) == 0 {
+		if route.required {
+			return []schemaViolation{{Path: "/", Message: "request body is required"}}, "schema_validation_failed"
+		}
+		return nil, ""
+	}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return nil, "invalid_json"
+	}
+	if dec.More() {
+		return nil, "invalid_json"
+	}
+	v := schemaValidator{guard: g, schemas: s.schemas}
+	v.validate(route.schema, value, "", 0)
+	if len(v.violations) > 0 {
+		return v.violations, "schema_validation_failed"
+	}
+	return nil, ""
+}
+
+func isJSONRequest(r *http.Request) bool {
+	raw := strings.TrimSpace(r.Header.Get("Content-Type"))
+	if raw == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(raw)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+type schemaValidator struct {
+	guard      *schemaGuard
+	schemas    map[string]any
+	violations []schemaViolation
+}
+
+func (v *schemaValidator) fail(path, format string, args ...any) {
+	if len(v.violations) >= schemaGuardMaxViolations {
+		return
+	}
+	if path == "" {
+		path = "/"
+	}
+	v.violations = append(v.violations, schemaViolation{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *schemaValidator) validate(schema map[string]any, value any, path string, depth int) {
+	if depth > schemaGuardMaxDepth || len(v.violations) >= schemaGuardMaxViolations {
+		return
+	}
+	if ref, ok := schema["$ref"].(string); ok {
+		if resolved, ok := lookupSchemaRef(ref, v.schemas); ok {
+			v.validate(resolved, value, path, depth+1)
+		}
+		return
+	}
+	if all, ok := schema["allOf"].([]any); ok {
+		for _, item := range all {
+			if sub, ok := item.(map[string]any); ok {
+				v.validate(sub, value, path, depth+1)
+			}
+		}
+	}
+	if value == nil {
+		if nullable, _ := schema["nullable"].(bool); nullable {
+			return
+		}
+		if typ, ok := schema["type"].(string); ok {
+			v.fail(path, "expected %s, got null", typ)
+		}
+		return
+	}
+	if enum, ok := schema["enum"].([]any); ok && !enumContains(enum, value) {
+		v.fail(path, "value is not one of the allowed values")
+	}
+
+	typ, _ := schema["type"].(string)
+	switch typ {
+	case "object":
+		obj, ok := value.(map[string]any)
+		if !ok {
+			v.fail(path, "expected object, got %s", jsonTypeName(value))
+			return
+		}
+		v.validateObject(schema, obj, path, depth)
+	case "array":
+		arr, ok := value.([]any)
+		if !ok {
+			v.fail(path, "expected array, got %s", jsonTypeName(value))
+			return
+		}
+		if min, ok := schemaNumber(schema["minItems"]); ok && float64(len(arr)) < min {
+			v.fail(path, "expected at least %v items", min)
+		}
+		if max, ok := schemaNumber(schema["maxItems"]); ok && float64(len(arr)) > max {
+			v.fail(path, "expected at most %v items", max)
+		}
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range arr {
+				v.validate(items, item, fmt.Sprintf("%s/%d", path, i), depth+1)
+			}
+		}
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			v.fail(path, "expected string, got %s", jsonTypeName(value))
+			return
+		}
+		v.validateString(schema, str, path)
+	case "integer", "number":
+		num, ok := value.(json.Number)
+		if !ok {
+			v.fail(path, "expected %s, got %s", typ, jsonTypeName(value))
+			return
+		}
+		f, err := num.Float64()
+		if err != nil {
+			v.fail(path, "invalid number")
+			return
+		}
+		if typ == "integer" && f != math.Trunc(f) {
+			v.fail(path, "expected integer, got number")
+			return
+		}
+		if min, ok := schemaNumber(schema["minimum"]); ok && f < min {
+			v.fail(path, "must be >= %v", min)
+		}
+		if max, ok := schemaNumber(schema["maximum"]); ok && f > max {
+			v.fail(path, "must be <= %v", max)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			v.fail(path, "expected boolean, got %s", jsonTypeName(value))
+		}
+	}
+}
+
+func (v *schemaValidator) validateObject(schema map[string]any, obj map[string]any, path string, depth int) {
+	if required, ok := schema["required"].([]any); ok {
+		for _, item := range required {
+			name, _ := item.(string)
+			if _, present := obj[name]; name != "" && !present {
+				v.fail(path+"/"+name, "is required")
+			}
+		}
+	}
+	properties, _ := schema["properties"].(map[string]any)
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		child := path + "/" + key
+		if prop, ok := properties[key].(map[string]any); ok {
+			v.validate(prop, obj[key], child, depth+1)
+			continue
+		}
+		switch extra := schema["additionalProperties"].(type) {
+		case bool:
+			if !extra {
+				v.fail(child, "unknown field")
+			}
+		case map[string]any:
+			v.validate(extra, obj[key], child, depth+1)
+		}
+	}
+}
+
+func (v *schemaValidator) validateString(schema map[string]any, str, path string) {
+	if min, ok := schemaNumber(schema["minLength"]); ok && float64(utf8.RuneCountInString(str)) < min {
+		v.fail(path, "must be at least %v characters", min)
+	}
+	if max, ok := schemaNumber(schema["maxLength"]); ok && float64(utf8.RuneCountInString(str)) > max {
+		v.fail(path, "must be at most %v characters", max)
+	}
+	if raw, ok := schema["pattern"].(string); ok {
+		if pattern := v.guard.pattern(raw); pattern != nil && !pattern.MatchString(str) {
+			v.fail(path, "does not match pattern %s", raw)
+		}
+	}
+	if format, _ := schema["format"].(string); format == "date-time" {
+		if _, err := time.Parse(time.RFC3339, str); err != nil {
+			v.fail(path, "expected RFC 3339 date-time")
+		}
+	}
+}
+
+// pattern caches compiled schema patterns; invalid patterns are skipped.
+func (g *schemaGuard) pattern(raw string) *regexp.Regexp {
+	if cached, ok := g.patterns.Load(raw); ok {
+		pattern, _ := cached.(*regexp.Regexp)
+		return pattern
+	}
+	pattern, err := regexp.Compile(raw)
+	if err != nil {
+		pattern = nil
+	}
+	g.patterns.Store(raw, pattern)
+	return pattern
+}
+
+func enumContains(enum []any, value any) bool {
+	for _, allowed := range enum {
+		switch typed := value.(type) {
+		case json.Number:
+			want, ok := schemaNumber(allowed)
+			got, err := typed.Float64()
+			if ok && err == nil && want == got {
+				return true
+			}
+		default:
+			if allowed == value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func schemaNumber(value any) (float64, bool) {
+	switch typed := value.(type) {
+	case int:
+		return float64(typed), true
+	case int64:
+		return float64(typed), true
+	case uint64:
+		return float64(typed), true
+	case float64:
+		return typed, true
+	default:
+		return 0, false
+	}
+}
+
+func jsonTypeName(value any) string {
+	switch value.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case json.Number:
+		return "number"
+	case bool:
+		return "boolean"
+	case nil:
+		return "null"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
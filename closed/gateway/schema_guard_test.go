@@ -0,0 +1,118 @@
+package gateway
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newTestSchemaGuard(t *testing.T, mode string) *schemaGuard {
+	t.Helper()
+	guard, err := newSchemaGuard(slog.New(slog.NewTextHandler(io.Discard, nil)), schemaGuardConfig{
+		Mode:         mode,
+		SpecDir:      "../../core/contracts/openapi",
+		MaxBodyBytes: 1 << 10,
+	}, []string{"dataset-registry", "quality", "experiments", "lineage", "audit"})
+	if err != nil {
+		t.Fatalf("load schema guard: %v", err)
+	}
+	return guard
+}
+
+func TestSchemaGuardEnforce(t *testing.T) {
+	guard := newTestSchemaGuard(t, schemaGuardModeEnforce)
+
+	var forwarded string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		forwarded = string(body)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	handler := guard.Wrap("experiments", next)
+
+	cases := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		want   int
+		errors string
+	}{
+		{name: "valid metrics", method: http.MethodPost, target: "/experiment-runs/run-1/metrics", body: `{"step":1,"metrics":{"loss":0.5}}`, want: http.StatusNoContent},
+		{name: "missing step", method: http.MethodPost, target: "/experiment-runs/run-1/metrics", body: `{"metrics":{"loss":0.5}}`, want: http.StatusBadRequest, errors: `"path":"/step"`},
+		{name: "negative step", method: http.MethodPost, target: "/experiment-runs/run-1/metrics", body: `{"step":-1,"metrics":{}}`, want: http.StatusBadRequest, errors: "must be >= 0"},
+		{name: "unknown field", method: http.MethodPost, target: "/experiment-runs/run-1/metrics", body: `{"step":1,"metrics":{},"extra":true}`, want: http.StatusBadRequest, errors: `"path":"/extra"`},
+		{name: "malformed json", method: http.MethodPost, target: "/experiment-runs/run-1/metrics", body: `{"step":`, want: http.StatusBadRequest, errors: "invalid_json"},
+		{name: "too large", method: http.MethodPost, target: "/experiment-runs/run-1/metrics", body: `{"step":1,"metrics":{"x":"` + strings.Repeat("a", 2048) + `"}}`, want: http.StatusRequestEntityTooLarge},
+		{name: "reads pass through", method: http.MethodGet, target: "/experiment-runs/run-1/metrics", want: http.StatusNoContent},
+		{name: "unknown route passes through", method: http.MethodPost, target: "/not-in-contract", body: `{"anything":1}`, want: http.StatusNoContent},
+	}
+	for _, tc := range cases {
+		forwarded = ""
+		req := httptest.NewRequest(tc.method, tc.target, strings.NewReader(tc.body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != tc.want {
+			t.Fatalf("%s: status=%d want %d body=%s", tc.name, rec.Code, tc.want, rec.Body.String())
+		}
+		if tc.errors != "" && !strings.Contains(rec.Body.String(), tc.errors) {
+			t.Fatalf("%s: expected %q in %s", tc.name, tc.errors, rec.Body.String())
+		}
+		if tc.want == http.StatusNoContent && forwarded != tc.body {
+			t.Fatalf("%s: body not forwarded intact: %q", tc.name, forwarded)
+		}
+	}
+}
+
+func TestSchemaGuardReportForwardsInvalidBodies(t *testing.T) {
+	guard := newTestSchemaGuard(t, schemaGuardModeReport)
+
+	called := false
+	handler := guard.Wrap("experiments", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	req := httptest.NewRequest(http.MethodPost, "/experiment-runs/run-1/metrics", strings.NewReader(`{"metrics":1}`))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if !called || rec.Code != http.StatusNoContent {
+		t.Fatalf("expected report mode to forward, status=%d", rec.Code)
+	}
+}
+
+func TestSchemaGuardPrefersLiteralRoutes(t *testing.T) {
+	spec, err := parseSchemaGuardSpec([]byte(`
+paths:
+  /items/{id}:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema: {type: object, required: [name]}
+  /items/search:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema: {type: object, required: [query]}
+  /items/{id}:archive:
+    post: {}
+`))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	for path, want := range map[string]string{
+		"/items/search":      "/items/search",
+		"/items/abc":         "/items/{id}",
+		"/items/abc:archive": "/items/{id}:archive",
+	} {
+		route, ok := spec.match(http.MethodPost, path)
+		if !ok || route.template != want {
+			t.Fatalf("%s: matched %q want %q", path, route.template, want)
+		}
+	}
+}
@@ -0,0 +1,378 @@
+package gateway
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	"github.com/animus-labs/animus-go/closed/internal/platform/rbac"
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+)
+
+const (
+	searchKindExperiment  = "experiment"
+	searchKindRun         = "experiment_run"
+	searchKindDataset     = "dataset"
+	searchKindQualityRule = "quality_rule"
+	searchKindPolicy      = "policy"
+	searchKindAuditEvent  = "audit_event"
+
+	maxSearchTextLen = 200
+)
+
+// searchKind is one searchable entity. Vector must repeat the expression of
+// its GIN index in migration 000056 verbatim. Text feeds the snippet;
+// Link is the gateway path of the hit with %s for the id. AdminVector,
+// when set, replaces Vector for admins only (see searchKindsFor).
+type searchKind struct {
+	Name        string
+	Role        string
+	From        string
+	ID          string
+	Title       string
+	Text        string
+	Vector      string
+	AdminVector string
+	Date        string
+	Link        string
+	Scope       string
+	Extra       string
+	Auditor     bool
+}
+
+var searchKinds = []searchKind{
+	{
+		Name:   searchKindExperiment,
+		Role:   auth.RoleViewer,
+		From:   "experiments x",
+		ID:     "x.experiment_id",
+		Title:  "x.name",
+		Text:   "x.name || ' ' || coalesce(x.description, '')",
+		Vector: "to_tsvector('simple', x.name || ' ' || coalesce(x.description, ''))",
+		Date:   "x.created_at",
+		Link:   "/api/experiments/experiments/%s",
+		Scope:  "x.project_id = q.project_id",
+	},
+	{
+		Name:   searchKindRun,
+		Role:   auth.RoleViewer,
+		From:   "experiment_runs r JOIN experiments rx ON rx.experiment_id = r.experiment_id",
+		ID:     "r.run_id",
+		Title:  "rx.name || ' / ' || r.run_id",
+		Text:   "coalesce(r.git_repo, '') || ' ' || coalesce(r.git_ref, '') || ' ' || coalesce(r.git_commit, '') || ' ' || r.params::text",
+		Vector: "(to_tsvector('simple', r.run_id || ' ' || coalesce(r.git_repo, '') || ' ' || coalesce(r.git_ref, '') || ' ' || coalesce(r.git_commit, '')) || jsonb_to_tsvector('simple', r.params, '[\"string\"]'))",
+		Date:   "r.started_at",
+		Link:   "/api/experiments/experiment-runs/%s",
+		Scope:  "r.project_id = q.project_id",
+		// A run also matches through the names of the datasets it used.
+		Extra: `EXISTS (
+				SELECT 1
+				FROM experiment_run_datasets rd
+				JOIN datasets rdd ON rdd.dataset_id = rd.dataset_id
+				WHERE rd.run_id = r.run_id
+				  AND to_tsvector('simple', rdd.name || ' ' || coalesce(rdd.description, '')) @@ q.query
+			)`,
+	},
+	{
+		Name:   searchKindDataset,
+		Role:   auth.RoleViewer,
+		From:   "datasets d",
+		ID:     "d.dataset_id",
+		Title:  "d.name",
+		Text:   "d.name || ' ' || coalesce(d.description, '')",
+		Vector: "to_tsvector('simple', d.name || ' ' || coalesce(d.description, ''))",
+		Date:   "d.created_at",
+		Link:   "/api/dataset-registry/datasets/%s",
+		Scope:  "d.project_id = q.project_id",
+	},
+	{
+		Name:   searchKindQualityRule,
+		Role:   auth.RoleViewer,
+		From:   "quality_rules qr",
+		ID:     "qr.rule_id",
+		Title:  "qr.name",
+		Text:   "qr.name || ' ' || coalesce(qr.description, '')",
+		Vector: "to_tsvector('simple', qr.name || ' ' || coalesce(qr.description, ''))",
+		Date:   "qr.created_at",
+		Link:   "/api/quality/rules/%s",
+	},
+	{
+		Name:   searchKindPolicy,
+		Role:   auth.RoleAdmin,
+		From:   "policies p",
+		ID:     "p.policy_id",
+		Title:  "p.name",
+		Text:   "p.name || ' ' || coalesce(p.description, '')",
+		Vector: "to_tsvector('simple', p.name || ' ' || coalesce(p.description, ''))",
+		Date:   "p.created_at",
+		Link:   "/api/experiments/policies/%s",
+	},
+	{
+		Name:    searchKindAuditEvent,
+		Role:    auth.RoleAdmin,
+		Auditor: true,
+		From:    "audit_events a",
+		ID:      "a.event_id::text",
+		Title:   "a.action || ' ' || a.resource_type || '/' || a.resource_id",
+		// The audit service masks payload fields for readers below admin,
+		// so the payload never feeds snippets and only admins match on it.
+		Text:        "a.action || ' ' || a.resource_type || ' ' || a.resource_id",
+		Vector:      "to_tsvector('simple', a.action || ' ' || a.resource_type || ' ' || a.resource_id)",
+		AdminVector: "(to_tsvector('simple', a.action || ' ' || a.resource_type || ' ' || a.resource_id) || jsonb_to_tsvector('simple', a.payload, '[\"string\"]'))",
+		Date:        "a.occurred_at",
+		Link:        "/api/audit/events/%s",
+		// Audit events carry no project column; only events whose payload
+		// names the project are searchable from it.
+		Scope: "a.payload ->> 'project_id' = q.project_id",
+	},
+}
+
+type searchHit struct {
+	Type      string    `json:"type"`
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	Snippet   string    `json:"snippet"`
+	Rank      float64   `json:"rank"`
+	Timestamp time.Time `json:"timestamp"`
+	Link      string    `json:"link"`
+}
+
+type searchResponse struct {
+	Query     string      `json:"query"`
+	ProjectID string      `json:"project_id"`
+	Types     []string    `json:"types"`
+	Hits      []searchHit `json:"hits"`
+}
+
+// searchHandler serves keyword search over project entities. Project roles
+// are resolved here because the gateway fronts no single upstream service:
+// viewers see experiments, runs, datasets and quality rules, admins also
+// policies, and admins or auditors audit events.
+func searchHandler(db *sql.DB, bindings rbac.BindingStore, allowDirect bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			httpapi.WriteError(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
+			return
+		}
+		identity, ok := auth.IdentityFromContext(r.Context())
+		if !ok || strings.TrimSpace(identity.Subject) == "" {
+			httpapi.WriteError(w, r, http.StatusUnauthorized, "unauthenticated")
+			return
+		}
+		projectID := auth.ProjectIDFromRequest(r)
+		if projectID == "" {
+			httpapi.WriteError(w, r, http.StatusBadRequest, "project_id_required")
+			return
+		}
+		text := strings.TrimSpace(r.URL.Query().Get("q"))
+		if text == "" {
+			httpapi.WriteError(w, r, http.StatusBadRequest, "q_required")
+			return
+		}
+		if len(text) > maxSearchTextLen {
+			httpapi.WriteError(w, r, http.StatusBadRequest, "q_too_long")
+			return
+		}
+		limit, err := parseSearchLimit(r.URL.Query().Get("limit"))
+		if err != nil {
+			httpapi.WriteError(w, r, http.StatusBadRequest, "invalid_limit")
+			return
+		}
+
+		role, roleBindings, err := rbac.ResolveRole(r.Context(), bindings, projectID, identity, allowDirect)
+		if err != nil {
+			httpapi.WriteError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+		auditor := hasAuditorBinding(identity, roleBindings, allowDirect)
+		if !rbac.HasAtLeast(role, auth.RoleViewer) && !auditor {
+			httpapi.WriteError(w, r, http.StatusForbidden, "forbidden")
+			return
+		}
+		kinds, code := selectSearchKinds(r.URL.Query().Get("types"), role, auditor)
+		switch code {
+		case "":
+		case "type_forbidden":
+			httpapi.WriteError(w, r, http.StatusForbidden, code)
+			return
+		default:
+			httpapi.WriteError(w, r, http.StatusBadRequest, code)
+			return
+		}
+
+		kinds = searchKindsFor(kinds, identity)
+
+		out := searchResponse{Query: text, ProjectID: projectID, Types: make([]string, 0, len(kinds)), Hits: []searchHit{}}
+		for _, kind := range kinds {
+			out.Types = append(out.Types, kind.Name)
+		}
+		if len(kinds) > 0 {
+			hits, err := runFullTextSearch(r, db, kinds, text, projectID, limit)
+			if err != nil {
+				httpapi.WriteError(w, r, http.StatusInternalServerError, "internal_error")
+				return
+			}
+			out.Hits = hits
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(out)
+	})
+}
+
+func parseSearchLimit(raw string) (int, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return 20, nil
+	}
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit <= 0 {
+		return 0, fmt.Errorf("invalid limit %q", raw)
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	return limit, nil
+}
+
+// selectSearchKinds returns the requested kinds the caller may read. An
+// empty request means every permitted kind; naming a forbidden kind is an
+// error rather than a silent omission.
+func selectSearchKinds(raw, role string, auditor bool) ([]searchKind, string) {
+	allowed := func(kind searchKind) bool {
+		return rbac.HasAtLeast(role, kind.Role) || (auditor && (kind.Auditor || kind.Role == auth.RoleViewer))
+	}
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		out := make([]searchKind, 0, len(searchKinds))
+		for _, kind := range searchKinds {
+			if allowed(kind) {
+				out = append(out, kind)
+			}
+		}
+		return out, ""
+	}
+	requested := make(map[string]struct{})
+	for _, name := range strings.Split(raw, ",") {
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+			requested[name] = struct{}{}
+		}
+	}
+	out := make([]searchKind, 0, len(requested))
+	for _, kind := range searchKinds {
+		if _, ok := requested[kind.Name]; !ok {
+			continue
+		}
+		if !allowed(kind) {
+			return nil, "type_forbidden"
+		}
+		out = append(out, kind)
+		delete(requested, kind.Name)
+	}
+	if len(requested) > 0 {
+		return nil, "invalid_types"
+	}
+	return out, ""
+}
+
+// searchKindsFor applies AdminVector for callers holding the admin role
+// itself, the same test the audit service uses before returning payloads
+// unredacted; project admins through a binding still match without them.
+func searchKindsFor(kinds []searchKind, identity auth.Identity) []searchKind {
+	if !auth.HasAtLeast(identity.Roles, auth.RoleAdmin) {
+		return kinds
+	}
+	out := make([]searchKind, len(kinds))
+	for i, kind := range kinds {
+		if kind.AdminVector != "" {
+			kind.Vector = kind.AdminVector
+		}
+		out[i] = kind
+	}
+	return out
+}
+
+func hasAuditorBinding(identity auth.Identity, bindings []repo.RoleBindingRecord, allowDirect bool) bool {
+	for _, binding := range bindings {
+		if strings.EqualFold(strings.TrimSpace(binding.Role), auth.RoleAuditor) {
+			return true
+		}
+	}
+	if !allowDirect {
+		return false
+	}
+	for _, role := range identity.Roles {
+		if strings.EqualFold(strings.TrimSpace(role), auth.RoleAuditor) {
+			return true
+		}
+	}
+	return false
+}
+
+// buildFullTextSearchSQL unions one ranked branch per kind. $1 is the query
+// text, $2 the project, $3 the limit; q carries the first two so every
+// parameter is referenced whichever kinds are selected.
+func buildFullTextSearchSQL(kinds []searchKind) string {
+	branches := make([]string, 0, len(kinds))
+	for _, kind := range kinds {
+		match := kind.Vector + " @@ q.query"
+		if kind.Extra != "" {
+			match = "(" + match + " OR " + kind.Extra + ")"
+		}
+		where := match
+		if kind.Scope != "" {
+			where = kind.Scope + " AND " + match
+		}
+		branches = append(branches, `(SELECT '`+kind.Name+`' AS type,
+				`+kind.ID+` AS id,
+				`+kind.Title+` AS title,
+				ts_headline('simple', left(`+kind.Text+`, 4000), q.query, 'MaxWords=24, MinWords=8, MaxFragments=1, StartSel=**, StopSel=**') AS snippet,
+				ts_rank(`+kind.Vector+`, q.query) AS rank,
+				`+kind.Date+` AS ts
+			 FROM `+kind.From+`, q
+			 WHERE `+where+`
+			 ORDER BY rank DESC, ts DESC
+			 LIMIT $3)`)
+	}
+	return `WITH q AS (SELECT websearch_to_tsquery('simple', $1) AS query, $2::text AS project_id)
+		 SELECT type, id, title, snippet, rank, ts
+		 FROM (` + strings.Join(branches, "\n\t\t UNION ALL ") + `) hits
+		 ORDER BY rank DESC, ts DESC, type, id
+		 LIMIT $3`
+}
+
+func runFullTextSearch(r *http.Request, db *sql.DB, kinds []searchKind, text, projectID string, limit int) ([]searchHit, error) {
+	links := make(map[string]string, len(kinds))
+	for _, kind := range kinds {
+		links[kind.Name] = kind.Link
+	}
+	rows, err := db.QueryContext(r.Context(), buildFullTextSearchSQL(kinds), text, projectID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hits := make([]searchHit, 0, limit)
+	for rows.Next() {
+		var (
+			hit     searchHit
+			snippet sql.NullString
+		)
+		if err := rows.Scan(&hit.Type, &hit.ID, &hit.Title, &snippet, &hit.Rank, &hit.Timestamp); err != nil {
+			return nil, err
+		}
+		hit.Snippet = snippet.String
+		hit.Timestamp = hit.Timestamp.UTC()
+		hit.Link = fmt.Sprintf(links[hit.Type], url.PathEscape(hit.ID))
+		hits = append(hits, hit)
+	}
+	return hits, rows.Err()
+}
@@ -0,0 +1,231 @@
+package gateway
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+)
+
+type staticBindings []repo.RoleBindingRecord
+
+func (s staticBindings) ListBySubjects(context.Context, string, []repo.RoleBindingSubject) ([]repo.RoleBindingRecord, error) {
+	return s, nil
+}
+
+func kindNames(kinds []searchKind) []string {
+	out := make([]string, 0, len(kinds))
+	for _, kind := range kinds {
+		out = append(out, kind.Name)
+	}
+	return out
+}
+
+func TestSelectSearchKinds(t *testing.T) {
+	viewer, code := selectSearchKinds("", auth.RoleViewer, false)
+	if code != "" || strings.Join(kindNames(viewer), ",") != "experiment,experiment_run,dataset,quality_rule" {
+		t.Fatalf("unexpected viewer kinds: %v %q", kindNames(viewer), code)
+	}
+	admin, _ := selectSearchKinds("", auth.RoleAdmin, false)
+	if len(admin) != len(searchKinds) {
+		t.Fatalf("expected every kind for admin, got %v", kindNames(admin))
+	}
+	auditor, _ := selectSearchKinds("audit_event, dataset", "", true)
+	if strings.Join(kindNames(auditor), ",") != "dataset,audit_event" {
+		t.Fatalf("unexpected auditor kinds: %v", kindNames(auditor))
+	}
+	if _, code := selectSearchKinds("policy", auth.RoleEditor, false); code != "type_forbidden" {
+		t.Fatalf("expected type_forbidden, got %q", code)
+	}
+	if _, code := selectSearchKinds("experiment,model", auth.RoleViewer, false); code != "invalid_types" {
+		t.Fatalf("expected invalid_types, got %q", code)
+	}
+}
+
+// The planner only uses the GIN indexes when the query repeats their
+// expressions, so each kind's vectors must appear in the migrations once the
+// table alias is dropped.
+func TestSearchVectorsMatchIndexes(t *testing.T) {
+	var migration string
+	for _, name := range []string{"000056_full_text_search.up.sql", "000090_audit_search_redacted.up.sql"} {
+		raw, err := os.ReadFile("../migrations/" + name)
+		if err != nil {
+			t.Fatalf("read migration: %v", err)
+		}
+		migration += string(raw)
+	}
+	space := regexp.MustCompile(`\s+`)
+	migration = space.ReplaceAllString(migration, " ")
+	for _, kind := range searchKinds {
+		alias := strings.Fields(kind.From)[1] + "."
+		for _, vector := range []string{kind.Vector, kind.AdminVector} {
+			vector = strings.ReplaceAll(vector, alias, "")
+			// The payload-free audit vector is a prefix of the full one,
+			// so match the whole indexed expression.
+			if vector != "" && !strings.Contains(migration, "GIN ("+vector+")") {
+				t.Fatalf("%s vector has no matching index: %s", kind.Name, vector)
+			}
+		}
+	}
+}
+
+// Audit payloads are masked for readers below admin, so neither the
+// snippet nor, for those readers, the match may read them.
+func TestAuditSearchKeepsPayloadFromNonAdmins(t *testing.T) {
+	kinds, _ := selectSearchKinds(searchKindAuditEvent, "", true)
+	auditor := buildFullTextSearchSQL(searchKindsFor(kinds, auth.Identity{Subject: "auditor-1", Roles: []string{auth.RoleAuditor}}))
+	projectAdmin := buildFullTextSearchSQL(searchKindsFor(kinds, auth.Identity{Subject: "owner-1", Roles: []string{auth.RoleEditor}}))
+	for name, query := range map[string]string{"auditor": auditor, "project admin": projectAdmin} {
+		if strings.Count(query, "payload") != 1 || !strings.Contains(query, "a.payload ->> 'project_id' = q.project_id") {
+			t.Fatalf("%s query reads the payload beyond the project scope:\n%s", name, query)
+		}
+	}
+
+	admin := buildFullTextSearchSQL(searchKindsFor(kinds, auth.Identity{Subject: "admin-1", Roles: []string{auth.RoleAdmin}}))
+	if !strings.Contains(admin, "jsonb_to_tsvector('simple', a.payload") {
+		t.Fatalf("admin query should match payload strings:\n%s", admin)
+	}
+	if strings.Contains(admin, "left(a.payload") {
+		t.Fatalf("admin snippet should not quote the payload:\n%s", admin)
+	}
+}
+
+func TestBuildFullTextSearchSQL(t *testing.T) {
+	kinds, _ := selectSearchKinds("quality_rule", auth.RoleViewer, false)
+	query := buildFullTextSearchSQL(kinds)
+	for _, fragment := range []string{"websearch_to_tsquery('simple', $1)", "$2::text AS project_id", "FROM quality_rules qr, q", "LIMIT $3"} {
+		if !strings.Contains(query, fragment) {
+			t.Fatalf("query missing %q:\n%s", fragment, query)
+		}
+	}
+	if strings.Contains(query, "UNION ALL") {
+		t.Fatalf("single kind should not union:\n%s", query)
+	}
+}
+
+func TestSearchHandlerValidation(t *testing.T) {
+	handler := searchHandler(nil, staticBindings{{Role: auth.RoleViewer}}, false)
+	cases := []struct {
+		name   string
+		target string
+		header string
+		status int
+		code   string
+	}{
+		{name: "project required", target: "/api/search?q=fraud", status: http.StatusBadRequest, code: "project_id_required"},
+		{name: "query required", target: "/api/search", header: "proj-1", status: http.StatusBadRequest, code: "q_required"},
+		{name: "bad limit", target: "/api/search?q=fraud&limit=x", header: "proj-1", status: http.StatusBadRequest, code: "invalid_limit"},
+		{name: "forbidden type", target: "/api/search?q=fraud&types=audit_event", header: "proj-1", status: http.StatusForbidden, code: "type_forbidden"},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodGet, tc.target, nil)
+		req = req.WithContext(auth.ContextWithIdentity(req.Context(), auth.Identity{Subject: "user-1"}))
+		if tc.header != "" {
+			req.Header.Set("X-Project-Id", tc.header)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != tc.status || !strings.Contains(rec.Body.String(), tc.code) {
+			t.Fatalf("%s: status=%d body=%s", tc.name, rec.Code, rec.Body.String())
+		}
+	}
+
+	denied := searchHandler(nil, staticBindings{}, false)
+	req := httptest.NewRequest(http.MethodGet, "/api/search?q=fraud", nil)
+	req = req.WithContext(auth.ContextWithIdentity(req.Context(), auth.Identity{Subject: "user-1"}))
+	req.Header.Set("X-Project-Id", "proj-1")
+	rec := httptest.NewRecorder()
+	denied.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 without a binding, got %d", rec.Code)
+	}
+}
+
+// captureDriver records the statements a search runs and answers each with
+// no rows, so handler tests can check the SQL without a database.
+type captureDriver struct {
+	mu      sync.Mutex
+	queries []string
+}
+
+func (d *captureDriver) Open(string) (driver.Conn, error) { return captureConn{d}, nil }
+
+func (d *captureDriver) last() string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.queries) == 0 {
+		return ""
+	}
+	return d.queries[len(d.queries)-1]
+}
+
+type captureConn struct{ d *captureDriver }
+
+func (c captureConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepare not supported")
+}
+func (c captureConn) Close() error              { return nil }
+func (c captureConn) Begin() (driver.Tx, error) { return nil, errors.New("tx not supported") }
+
+func (c captureConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	c.d.mu.Lock()
+	c.d.queries = append(c.d.queries, query)
+	c.d.mu.Unlock()
+	return emptyRows{}, nil
+}
+
+type emptyRows struct{}
+
+func (emptyRows) Columns() []string {
+	return []string{"type", "id", "title", "snippet", "rank", "ts"}
+}
+func (emptyRows) Close() error              { return nil }
+func (emptyRows) Next([]driver.Value) error { return io.EOF }
+
+var searchCapture = &captureDriver{}
+
+func init() { sql.Register("gateway-search-capture", searchCapture) }
+
+// An auditor searching audit events gets a query whose match and snippet
+// leave the payload alone; only the admin role ranks over it.
+func TestSearchHandlerRedactsAuditPayload(t *testing.T) {
+	db, err := sql.Open("gateway-search-capture", "")
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer db.Close()
+	handler := searchHandler(db, staticBindings{}, true)
+
+	search := func(identity auth.Identity) string {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/api/search?q=secret&types=audit_event", nil)
+		req = req.WithContext(auth.ContextWithIdentity(req.Context(), identity))
+		req.Header.Set("X-Project-Id", "proj-1")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: status=%d body=%s", identity.Subject, rec.Code, rec.Body.String())
+		}
+		return searchCapture.last()
+	}
+
+	auditor := search(auth.Identity{Subject: "auditor-1", Roles: []string{auth.RoleAuditor}})
+	if strings.Count(auditor, "payload") != 1 || !strings.Contains(auditor, "a.payload ->> 'project_id' = q.project_id") {
+		t.Fatalf("auditor query reads the payload beyond the project scope:\n%s", auditor)
+	}
+	admin := search(auth.Identity{Subject: "admin-1", Roles: []string{auth.RoleAdmin}})
+	if !strings.Contains(admin, "jsonb_to_tsvector('simple', a.payload") || strings.Contains(admin, "left(a.payload") {
+		t.Fatalf("admin query should match but not quote the payload:\n%s", admin)
+	}
+}
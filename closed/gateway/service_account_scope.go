@@ -0,0 +1,57 @@
+package gateway
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/rbac"
+)
+
+// authorizeProjectScope rejects requests from project-pinned identities that
+// name a different project in the path, header, or query string.
+func authorizeProjectScope(r *http.Request, identity auth.Identity) error {
+	pinned := strings.TrimSpace(identity.ProjectID)
+	if pinned == "" {
+		return nil
+	}
+	for _, candidate := range requestProjectIDs(r) {
+		if candidate != pinned {
+			return auth.ErrForbidden
+		}
+	}
+	return nil
+}
+
+// requestProjectIDs returns every project id a request names. Upstream services
+// mount project routes as /projects/{project_id}/..., which the gateway sees as
+// /api/<service>/projects/{project_id}/....
+func requestProjectIDs(r *http.Request) []string {
+	out := make([]string, 0, 3)
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(segments) >= 4 && segments[0] == "api" && segments[2] == "projects" {
+		if id := strings.TrimSpace(segments[3]); id != "" {
+			out = append(out, id)
+		}
+	}
+	for _, value := range []string{
+		r.Header.Get("X-Project-Id"),
+		r.URL.Query().Get("project_id"),
+	} {
+		if value = strings.TrimSpace(value); value != "" {
+			out = append(out, value)
+		}
+	}
+	return out
+}
+
+// authorizeGlobalAdmin keeps project-pinned identities away from gateway-wide
+// admin endpoints, so a project token cannot mint unscoped tokens or end other sessions.
+func authorizeGlobalAdmin(authorizer rbac.Authorizer) auth.AuthorizeFunc {
+	return func(r *http.Request, identity auth.Identity) error {
+		if strings.TrimSpace(identity.ProjectID) != "" {
+			return auth.ErrForbidden
+		}
+		return authorizer.Authorize(r, identity)
+	}
+}
@@ -0,0 +1,38 @@
+package gateway
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+)
+
+func TestAuthorizeProjectScope(t *testing.T) {
+	pinned := auth.Identity{Subject: "sa:ci", Roles: []string{"editor"}, ProjectID: "proj-1"}
+
+	cases := []struct {
+		name     string
+		target   string
+		header   string
+		identity auth.Identity
+		want     error
+	}{
+		{name: "same project path", target: "/api/experiments/projects/proj-1/runs", identity: pinned},
+		{name: "other project path", target: "/api/experiments/projects/proj-2/runs", identity: pinned, want: auth.ErrForbidden},
+		{name: "other project header", target: "/api/experiments/experiment-runs/run-1", header: "proj-2", identity: pinned, want: auth.ErrForbidden},
+		{name: "other project query", target: "/api/dataset-registry/datasets?project_id=proj-2", identity: pinned, want: auth.ErrForbidden},
+		{name: "no project named", target: "/api/experiments/experiment-runs/run-1", identity: pinned},
+		{name: "unpinned identity", target: "/api/experiments/projects/proj-2/runs", identity: auth.Identity{Subject: "user-1"}},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodGet, tc.target, nil)
+		if tc.header != "" {
+			req.Header.Set("X-Project-Id", tc.header)
+		}
+		if err := authorizeProjectScope(req, tc.identity); !errors.Is(err, tc.want) {
+			t.Fatalf("%s: err=%v want %v", tc.name, err, tc.want)
+		}
+	}
+}
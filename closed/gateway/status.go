@@ -0,0 +1,153 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/buildinfo"
+)
+
+const (
+	statusProbeTimeout = 2 * time.Second
+	maxStatusBodyBytes = 64 << 10
+
+	platformStatusOK       = "ok"
+	platformStatusDegraded = "degraded"
+
+	serviceStatusReady       = "ready"
+	serviceStatusNotReady    = "not_ready"
+	serviceStatusUnreachable = "unreachable"
+)
+
+// statusUpstream is a service whose /readyz the gateway reports on.
+type statusUpstream struct {
+	Name    string
+	BaseURL string
+}
+
+type serviceStatus struct {
+	Name       string          `json:"name"`
+	Status     string          `json:"status"`
+	Version    string          `json:"version,omitempty"`
+	LatencyMs  int64           `json:"latency_ms"`
+	HTTPStatus int             `json:"http_status,omitempty"`
+	Checks     json.RawMessage `json:"checks,omitempty"`
+	Error      string          `json:"error,omitempty"`
+}
+
+type platformStatus struct {
+	Status    string          `json:"status"`
+	CheckedAt time.Time       `json:"checked_at"`
+	Services  []serviceStatus `json:"services"`
+}
+
+// platformStatusHandler serves GET /api/status: the gateway's own readiness
+// followed by every upstream's /readyz, probed concurrently. The document is
+// 200 when everything is ready and 503 otherwise, so it works both as a
+// monitor target and as a page to read.
+func platformStatusHandler(client *http.Client, ping func(context.Context) error, upstreams []statusUpstream) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		services := make([]serviceStatus, len(upstreams)+1)
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			services[0] = gatewayStatus(r.Context(), ping)
+		}()
+		for i, upstream := range upstreams {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				services[i+1] = probeUpstream(r.Context(), client, upstream)
+			}()
+		}
+		wg.Wait()
+
+		out := platformStatus{Status: platformStatusOK, CheckedAt: time.Now().UTC(), Services: services}
+		code := http.StatusOK
+		for _, service := range services {
+			if service.Status != serviceStatusReady {
+				out.Status = platformStatusDegraded
+				code = http.StatusServiceUnavailable
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(code)
+		_ = json.NewEncoder(w).Encode(out)
+	})
+}
+
+func gatewayStatus(ctx context.Context, ping func(context.Context) error) serviceStatus {
+	ctx, cancel := context.WithTimeout(ctx, statusProbeTimeout)
+	defer cancel()
+	start := time.Now()
+	err := ping(ctx)
+	out := serviceStatus{
+		Name:      "gateway",
+		Status:    serviceStatusReady,
+		Version:   buildinfo.Get().Version,
+		LatencyMs: time.Since(start).Milliseconds(),
+	}
+	check := map[string]any{"name": "postgres", "status": "ok", "duration_ms": out.LatencyMs}
+	if err != nil {
+		out.Status = serviceStatusNotReady
+		check["status"] = "fail"
+		check["error"] = err.Error()
+	}
+	out.Checks, _ = json.Marshal([]any{check})
+	return out
+}
+
+// probeUpstream calls upstream's /readyz and reads the service, version and
+// checks fields every service's readiness handler returns.
+func probeUpstream(ctx context.Context, client *http.Client, upstream statusUpstream) serviceStatus {
+	out := serviceStatus{Name: upstream.Name}
+	ctx, cancel := context.WithTimeout(ctx, statusProbeTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(upstream.BaseURL, "/")+"/readyz", nil)
+	if err != nil {
+		out.Status = serviceStatusUnreachable
+		out.Error = "invalid_upstream_url"
+		return out
+	}
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		out.LatencyMs = time.Since(start).Milliseconds()
+		out.Status = serviceStatusUnreachable
+		out.Error = "unreachable"
+		if ctx.Err() != nil {
+			out.Error = "timeout"
+		}
+		return out
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxStatusBodyBytes))
+	out.LatencyMs = time.Since(start).Milliseconds()
+	out.HTTPStatus = resp.StatusCode
+
+	var payload struct {
+		Status  string          `json:"status"`
+		Version string          `json:"version"`
+		Checks  json.RawMessage `json:"checks"`
+	}
+	_ = json.Unmarshal(body, &payload)
+	out.Version = payload.Version
+	out.Checks = payload.Checks
+	if resp.StatusCode == http.StatusOK && payload.Status == serviceStatusReady {
+		out.Status = serviceStatusReady
+		return out
+	}
+	out.Status = serviceStatusNotReady
+	if payload.Status == "" {
+		out.Error = "unexpected_response"
+	}
+	return out
+}
@@ -0,0 +1,87 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/buildinfo"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpserver"
+)
+
+func TestPlatformStatusReportsEveryService(t *testing.T) {
+	ready := httptest.NewServer(httpserver.ReadyzWithChecks("experiments"))
+	defer ready.Close()
+	notReady := httptest.NewServer(httpserver.ReadyzWithChecks("lineage", httpserver.ReadinessCheck{
+		Name:  "postgres",
+		Check: func(context.Context) error { return errors.New("connection refused") },
+	}))
+	defer notReady.Close()
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	handler := platformStatusHandler(http.DefaultClient, func(context.Context) error { return nil }, []statusUpstream{
+		{Name: "experiments", BaseURL: ready.URL},
+		{Name: "lineage", BaseURL: notReady.URL + "/"},
+		{Name: "audit", BaseURL: down.URL},
+	})
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/status", nil))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status=%d, want 503", rec.Code)
+	}
+	var out platformStatus
+	if err := json.Unmarshal(rec.Body.Bytes(), &out); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if out.Status != platformStatusDegraded || len(out.Services) != 4 {
+		t.Fatalf("unexpected document %+v", out)
+	}
+	want := []struct{ name, status string }{
+		{"gateway", serviceStatusReady},
+		{"experiments", serviceStatusReady},
+		{"lineage", serviceStatusNotReady},
+		{"audit", serviceStatusUnreachable},
+	}
+	for i, w := range want {
+		if got := out.Services[i]; got.Name != w.name || got.Status != w.status {
+			t.Fatalf("service %d = %s/%s, want %s/%s", i, got.Name, got.Status, w.name, w.status)
+		}
+	}
+	if out.Services[1].Version != buildinfo.Get().Version || out.Services[2].HTTPStatus != http.StatusServiceUnavailable || len(out.Services[2].Checks) == 0 {
+		t.Fatalf("unexpected upstream details %+v", out.Services[1:3])
+	}
+}
+
+func TestPlatformStatusOKWhenEverythingIsReady(t *testing.T) {
+	ready := httptest.NewServer(httpserver.Readyz("audit"))
+	defer ready.Close()
+
+	handler := platformStatusHandler(http.DefaultClient, func(context.Context) error { return nil }, []statusUpstream{{Name: "audit", BaseURL: ready.URL}})
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/status", nil))
+
+	var out platformStatus
+	if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &out) != nil || out.Status != platformStatusOK {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body)
+	}
+	if out.CheckedAt.IsZero() || time.Since(out.CheckedAt) > time.Minute {
+		t.Fatalf("checked_at=%v", out.CheckedAt)
+	}
+}
+
+func TestPlatformStatusGatewayDatabaseDown(t *testing.T) {
+	handler := platformStatusHandler(http.DefaultClient, func(context.Context) error { return errors.New("db down") }, nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/status", nil))
+
+	var out platformStatus
+	if rec.Code != http.StatusServiceUnavailable || json.Unmarshal(rec.Body.Bytes(), &out) != nil || out.Services[0].Status != serviceStatusNotReady {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body)
+	}
+}
@@ -11,6 +11,7 @@ const (
 	EventTypeTerminal          = "terminal"
 	EventTypeArtifactCommitted = "artifact_committed"
 	EventTypeSecretAccessed    = "secret_accessed"
+	EventTypeDatasetCache      = "dataset_cache"
 )
 
 const (
@@ -31,6 +32,72 @@ type RunExecutionRequest struct {
 	EmittedAt     time.Time `json:"emittedAt"`
 	RequestedBy   string    `json:"requestedBy,omitempty"`
 	CorrelationID string    `json:"correlationId,omitempty"`
+	// MaxDurationSeconds becomes the Job's activeDeadlineSeconds; 0 is unbounded.
+	MaxDurationSeconds int64 `json:"maxDurationSeconds,omitempty"`
+	// Checkpoint is set when the run spec resumes from a checkpoint.
+	Checkpoint *RunCheckpoint `json:"checkpoint,omitempty"`
+	// Datasets are the run's dataset bindings with download URLs.
+	Datasets []RunDataset `json:"datasets,omitempty"`
+	// Step selects one attempt of one step of a multi-step pipeline; without
+	// it the pipeline must have exactly one step.
+	Step *RunStep `json:"step,omitempty"`
+	// RunToken is the scoped run token the job reports metrics, artifacts
+	// and events with; the job gets it as ANIMUS_RUN_TOKEN.
+	RunToken string `json:"runToken,omitempty"`
+}
+
+// RunStep is one attempt of a pipeline step, with the artifacts of upstream
+// steps it declares as inputs.
+type RunStep struct {
+	Name    string         `json:"name"`
+	Attempt int            `json:"attempt"`
+	Inputs  []RunStepInput `json:"inputs,omitempty"`
+}
+
+// RunStepInput is the artifact an upstream step committed under Artifact,
+// bound to the step input Name, with a presigned download URL.
+type RunStepInput struct {
+	Name       string    `json:"name"`
+	FromStep   string    `json:"fromStep"`
+	Artifact   string    `json:"artifact"`
+	ArtifactID string    `json:"artifactId"`
+	SHA256     string    `json:"sha256"`
+	SizeBytes  int64     `json:"sizeBytes,omitempty"`
+	URL        string    `json:"url"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+}
+
+// RunDataset is a dataset version bound to a run. Executors with a
+// node-local cache keep its content under CacheKey, so runs on the same
+// node download each dataset version once.
+type RunDataset struct {
+	Binding   string    `json:"binding"`
+	VersionID string    `json:"versionId"`
+	SHA256    string    `json:"sha256"`
+	SizeBytes int64     `json:"sizeBytes,omitempty"`
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	// CacheKey is "sha256/<content sha256>".
+	CacheKey string `json:"cacheKey"`
+}
+
+// RunCheckpoint is the checkpoint a run resumes from, with a presigned URL
+// the run downloads it from until ExpiresAt.
+type RunCheckpoint struct {
+	RunID      string    `json:"runId"`
+	ArtifactID string    `json:"artifactId"`
+	SHA256     string    `json:"sha256"`
+	SizeBytes  int64     `json:"sizeBytes"`
+	URL        string    `json:"url"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+}
+
+// AgentClaimRequest is sent by a run agent polling for work. The control
+// plane answers with the RunExecutionRequest of the oldest run waiting for
+// an agent with these labels, or 204 once WaitSeconds pass without one.
+type AgentClaimRequest struct {
+	Labels      []string `json:"labels"`
+	WaitSeconds int      `json:"waitSeconds,omitempty"`
 }
 
 type RunExecutionResponse struct {
@@ -52,6 +119,29 @@ type RunExecutionStatus struct {
 	StartedAt  *time.Time `json:"startedAt,omitempty"`
 	FinishedAt *time.Time `json:"finishedAt,omitempty"`
 	Reason     string     `json:"reason,omitempty"`
+	StepName   string     `json:"stepName,omitempty"`
+	Attempt    int        `json:"attempt,omitempty"`
+}
+
+// RunCancelRequest asks the data plane to kill a run's Job, e.g. once the
+// control plane has failed it for exceeding its deadline.
+type RunCancelRequest struct {
+	RunID         string    `json:"runId"`
+	ProjectID     string    `json:"projectId"`
+	DispatchID    string    `json:"dispatchId"`
+	Reason        string    `json:"reason,omitempty"`
+	EmittedAt     time.Time `json:"emittedAt"`
+	RequestedBy   string    `json:"requestedBy,omitempty"`
+	CorrelationID string    `json:"correlationId,omitempty"`
+}
+
+type RunCancelResponse struct {
+	RunID     string `json:"runId"`
+	ProjectID string `json:"projectId"`
+	Canceled  bool   `json:"canceled"`
+	JobName   string `json:"jobName,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Message   string `json:"message,omitempty"`
 }
 
 type DevEnvProvisionRequest struct {
@@ -124,6 +214,9 @@ type RunHeartbeat struct {
 	EmittedAt     time.Time      `json:"emittedAt"`
 	CorrelationID string         `json:"correlationId,omitempty"`
 	Details       map[string]any `json:"details,omitempty"`
+	// StepName and Attempt identify the step attempt of a multi-step run.
+	StepName string `json:"stepName,omitempty"`
+	Attempt  int    `json:"attempt,omitempty"`
 }
 
 type RunHeartbeatResponse struct {
@@ -142,6 +235,10 @@ type RunTerminalState struct {
 	ExitCode      *int           `json:"exitCode,omitempty"`
 	CorrelationID string         `json:"correlationId,omitempty"`
 	Details       map[string]any `json:"details,omitempty"`
+	// StepName and Attempt identify the step attempt of a multi-step run;
+	// its terminal state ends the attempt, not the run.
+	StepName string `json:"stepName,omitempty"`
+	Attempt  int    `json:"attempt,omitempty"`
 }
 
 type RunTerminalResponse struct {
@@ -179,3 +276,47 @@ type SecretAccessedResponse struct {
 	Accepted  bool `json:"accepted"`
 	Duplicate bool `json:"duplicate"`
 }
+
+// DatasetCacheReport records which datasets of a run were already in the
+// executor's node-local cache when the run started.
+type DatasetCacheReport struct {
+	EventID       string              `json:"eventId"`
+	RunID         string              `json:"runId"`
+	ProjectID     string              `json:"projectId"`
+	EmittedAt     time.Time           `json:"emittedAt"`
+	CorrelationID string              `json:"correlationId,omitempty"`
+	Node          string              `json:"node,omitempty"`
+	Entries       []DatasetCacheEntry `json:"entries"`
+}
+
+type DatasetCacheEntry struct {
+	VersionID string `json:"versionId"`
+	SHA256    string `json:"sha256"`
+	Hit       bool   `json:"hit"`
+	SizeBytes int64  `json:"sizeBytes,omitempty"`
+}
+
+type DatasetCacheReportResponse struct {
+	Accepted  bool `json:"accepted"`
+	Duplicate bool `json:"duplicate"`
+}
+
+// RunLogChunk carries consecutive log lines of one run container. Lines are
+// stripped of their kubelet timestamps; FirstLineAt and LastLineAt bound them.
+type RunLogChunk struct {
+	EventID       string    `json:"eventId"`
+	RunID         string    `json:"runId"`
+	ProjectID     string    `json:"projectId"`
+	PodName       string    `json:"podName"`
+	Container     string    `json:"container"`
+	Content       string    `json:"content"`
+	FirstLineAt   time.Time `json:"firstLineAt"`
+	LastLineAt    time.Time `json:"lastLineAt"`
+	EmittedAt     time.Time `json:"emittedAt"`
+	CorrelationID string    `json:"correlationId,omitempty"`
+}
+
+type RunLogChunkResponse struct {
+	Accepted  bool `json:"accepted"`
+	Duplicate bool `json:"duplicate"`
+}
@@ -32,6 +32,69 @@ type DatasetVersion struct {
 	CreatedAt       time.Time
 	CreatedBy       string
 	IntegritySHA256 string
+	// Encryption is empty for objects stored in plaintext.
+	Encryption ObjectEncryption
+}
+
+// ObjectEncryption records how a stored object was envelope-encrypted: the
+// data key wrapped by the key encryption key KeyID.
+type ObjectEncryption struct {
+	Algorithm  string
+	KeyID      string
+	WrappedKey string
+}
+
+func (e ObjectEncryption) Enabled() bool {
+	return strings.TrimSpace(e.KeyID) != ""
+}
+
+// DataProtection is the data protection posture of a dataset version: how its
+// object is stored, where the dataset may reside, how it is classified, and
+// whether the version was recalled.
+type DataProtection struct {
+	Encrypted            bool
+	EncryptionKeyID      string
+	Residency            string
+	ClassificationLabels []string
+	Recall               *DatasetVersionRecall
+}
+
+// DatasetVersionRecall withdraws a dataset version from further use.
+type DatasetVersionRecall struct {
+	VersionID  string
+	DatasetID  string
+	Reason     string
+	RecalledAt time.Time
+	RecalledBy string
+}
+
+// DatasetLifecycleState is the lifecycle state of a dataset. Datasets without
+// a recorded state are active.
+type DatasetLifecycleState string
+
+const (
+	DatasetLifecycleActive     DatasetLifecycleState = "active"
+	DatasetLifecycleDeprecated DatasetLifecycleState = "deprecated"
+	DatasetLifecycleArchived   DatasetLifecycleState = "archived"
+)
+
+func (s DatasetLifecycleState) Valid() bool {
+	switch s {
+	case DatasetLifecycleActive, DatasetLifecycleDeprecated, DatasetLifecycleArchived:
+		return true
+	default:
+		return false
+	}
+}
+
+// DatasetLifecycle is the current lifecycle state of a dataset and the last
+// transition into it; UpdatedAt is nil for datasets never transitioned.
+type DatasetLifecycle struct {
+	DatasetID string
+	State     DatasetLifecycleState
+	Reason    string
+	UpdatedAt *time.Time
+	UpdatedBy string
 }
 
 func (d Dataset) Validate() error {
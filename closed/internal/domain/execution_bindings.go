@@ -98,6 +98,8 @@ type PolicySnapshotRetention struct {
 	PolicyVersionID string `json:"policyVersionId,omitempty"`
 	PolicySHA256    string `json:"policySha256,omitempty"`
 	LegalHold       bool   `json:"legalHold,omitempty"`
+	// ArtifactRetentionDays is inherited from the project settings.
+	ArtifactRetentionDays int `json:"artifactRetentionDays,omitempty"`
 }
 
 type PolicySnapshotNetwork struct {
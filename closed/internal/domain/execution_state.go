@@ -126,11 +126,14 @@ var allowedRunTransitions = map[RunState][]RunState{
 		RunStateRunning,
 		RunStateCanceled,
 	},
+	// A finished dry run reopens when an individual step is re-run.
 	RunStateDryRunSucceeded: {
+		RunStateDryRunRunning,
 		RunStateRunning,
 		RunStateCanceled,
 	},
 	RunStateDryRunFailed: {
+		RunStateDryRunRunning,
 		RunStateRunning,
 		RunStateCanceled,
 	},
@@ -17,6 +17,8 @@ func TestCanTransitionRunState(t *testing.T) {
 		{"canceled->running", RunStateCanceled, RunStateRunning, false},
 		{"dryrun_succeeded->running", RunStateDryRunSucceeded, RunStateRunning, true},
 		{"failed->running", RunStateFailed, RunStateRunning, false},
+		{"dryrun_failed->dryrun_running", RunStateDryRunFailed, RunStateDryRunRunning, true},
+		{"failed->dryrun_running", RunStateFailed, RunStateDryRunRunning, false},
 	}
 	for _, tc := range cases {
 		if got := CanTransitionRunState(tc.from, tc.to); got != tc.allowed {
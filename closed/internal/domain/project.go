@@ -14,9 +14,19 @@ type Project struct {
 	Metadata        Metadata
 	CreatedAt       time.Time
 	CreatedBy       string
+	UpdatedAt       *time.Time
+	UpdatedBy       string
+	ArchivedAt      *time.Time
+	ArchivedBy      string
 	IntegritySHA256 string
 }
 
+// Archived reports whether the project was deleted. Archived projects keep
+// their history but accept no new datasets, uploads, artifacts or runs.
+func (p Project) Archived() bool {
+	return p.ArchivedAt != nil
+}
+
 func (p Project) Validate() error {
 	if strings.TrimSpace(p.ID) == "" {
 		return errors.New("project id is required")
@@ -29,3 +39,14 @@ func (p Project) Validate() error {
 	}
 	return nil
 }
+
+// ProjectSettings are project-wide defaults inherited by uploads, artifacts
+// and runs. Projects without stored settings get the zero value.
+type ProjectSettings struct {
+	ProjectID             string
+	DefaultQualityRuleID  string
+	DefaultPolicyIDs      []string
+	ArtifactRetentionDays int
+	UpdatedAt             *time.Time
+	UpdatedBy             string
+}
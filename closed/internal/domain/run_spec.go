@@ -12,6 +12,15 @@ type RunSpec struct {
 	EnvLock         EnvLock
 	Parameters      Metadata
 	PolicySnapshot  PolicySnapshot
-	CreatedAt       time.Time
-	CreatedBy       string
+	// ResumeFrom is the checkpoint the run resumes from; nil starts fresh.
+	ResumeFrom *CheckpointRef
+	CreatedAt  time.Time
+	CreatedBy  string
+}
+
+// CheckpointRef names a checkpoint artifact of an earlier experiment run.
+type CheckpointRef struct {
+	RunID      string `json:"runId"`
+	ArtifactID string `json:"artifactId"`
+	SHA256     string `json:"sha256"`
 }
@@ -0,0 +1,187 @@
+package dag
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/domain"
+)
+
+// Attempt statuses of real step executions.
+const (
+	AttemptPending    = "pending"
+	AttemptDispatched = "dispatched"
+	AttemptRunning    = "running"
+	AttemptSucceeded  = "succeeded"
+	AttemptFailed     = "failed"
+)
+
+// Step statuses derived by Evaluate.
+const (
+	StepWaiting   = "waiting"
+	StepPending   = "pending"
+	StepRunning   = "running"
+	StepRetrying  = "retrying"
+	StepSucceeded = "succeeded"
+	StepFailed    = "failed"
+	StepSkipped   = "skipped"
+)
+
+// Attempt is one recorded execution attempt of a step.
+type Attempt struct {
+	StepName   string
+	Attempt    int
+	Status     string
+	FinishedAt time.Time
+}
+
+// Start is an attempt the orchestrator should create, to be dispatched no
+// earlier than NotBefore.
+type Start struct {
+	StepName  string
+	Attempt   int
+	NotBefore time.Time
+}
+
+// StepStatus is the derived status of one plan step.
+type StepStatus struct {
+	Name        string
+	Status      string
+	Attempts    int
+	MaxAttempts int
+	DependsOn   []string
+}
+
+// Evaluation is the derived pipeline state and the attempts to start next.
+type Evaluation struct {
+	Steps []StepStatus
+	Start []Start
+	// State is running, succeeded or failed.
+	State domain.RunState
+}
+
+// Evaluate derives the status of every step of plan from the recorded
+// attempts. A step starts once all its dependencies succeeded; a failed
+// attempt is retried after the step's backoff until MaxAttempts is reached.
+// Evaluation is fail-fast: once a step has failed for good no further step
+// or retry starts, in-flight attempts finish and unstarted steps are skipped.
+// Plan steps are expected in topological order, as BuildPlan returns them.
+func Evaluate(plan domain.ExecutionPlan, attempts []Attempt) Evaluation {
+	latest := make(map[string]Attempt, len(attempts))
+	for _, attempt := range attempts {
+		name := strings.TrimSpace(attempt.StepName)
+		if current, ok := latest[name]; !ok || attempt.Attempt > current.Attempt {
+			latest[name] = attempt
+		}
+	}
+	deps := make(map[string][]string)
+	for _, edge := range plan.Edges {
+		deps[edge.To] = append(deps[edge.To], edge.From)
+	}
+
+	halted := false
+	for _, step := range plan.Steps {
+		if attempt, ok := latest[step.Name]; ok && attempt.Status == AttemptFailed && attempt.Attempt >= maxAttempts(step) {
+			halted = true
+			break
+		}
+	}
+
+	out := Evaluation{Steps: make([]StepStatus, 0, len(plan.Steps))}
+	statuses := make(map[string]string, len(plan.Steps))
+	for _, step := range plan.Steps {
+		status := StepStatus{
+			Name:        step.Name,
+			MaxAttempts: maxAttempts(step),
+			DependsOn:   deps[step.Name],
+		}
+		attempt, ok := latest[step.Name]
+		if ok {
+			status.Attempts = attempt.Attempt
+		}
+		switch {
+		case ok && attempt.Status == AttemptPending && attempt.Attempt > 1:
+			status.Status = StepRetrying
+		case ok && attempt.Status == AttemptPending:
+			status.Status = StepPending
+		case ok && (attempt.Status == AttemptDispatched || attempt.Status == AttemptRunning):
+			status.Status = StepRunning
+		case ok && attempt.Status == AttemptSucceeded:
+			status.Status = StepSucceeded
+		case ok && attempt.Status == AttemptFailed && attempt.Attempt < status.MaxAttempts && !halted:
+			status.Status = StepRetrying
+			out.Start = append(out.Start, Start{
+				StepName:  step.Name,
+				Attempt:   attempt.Attempt + 1,
+				NotBefore: attempt.FinishedAt.Add(RetryDelay(step.RetryPolicy, attempt.Attempt)),
+			})
+		case ok:
+			status.Status = StepFailed
+		default:
+			status.Status = unstartedStatus(status.DependsOn, statuses, halted)
+			if status.Status == StepPending {
+				out.Start = append(out.Start, Start{StepName: step.Name, Attempt: 1})
+			}
+		}
+		statuses[step.Name] = status.Status
+		out.Steps = append(out.Steps, status)
+	}
+
+	out.State = domain.RunStateSucceeded
+	for _, status := range out.Steps {
+		switch status.Status {
+		case StepWaiting, StepPending, StepRunning, StepRetrying:
+			out.State = domain.RunStateRunning
+		case StepFailed, StepSkipped:
+			if out.State != domain.RunStateRunning {
+				out.State = domain.RunStateFailed
+			}
+		}
+	}
+	return out
+}
+
+func unstartedStatus(dependsOn []string, statuses map[string]string, halted bool) string {
+	if halted {
+		return StepSkipped
+	}
+	ready := true
+	for _, dep := range dependsOn {
+		switch statuses[dep] {
+		case StepSucceeded:
+		case StepFailed, StepSkipped:
+			return StepSkipped
+		default:
+			ready = false
+		}
+	}
+	if ready {
+		return StepPending
+	}
+	return StepWaiting
+}
+
+func maxAttempts(step domain.ExecutionPlanStep) int {
+	if step.RetryPolicy.MaxAttempts < 1 {
+		return 1
+	}
+	return step.RetryPolicy.MaxAttempts
+}
+
+// RetryDelay is the backoff before the attempt following failedAttempt:
+// InitialSeconds, multiplied per failed attempt for exponential backoff,
+// capped at MaxSeconds when set.
+func RetryDelay(policy domain.PipelineRetryPolicy, failedAttempt int) time.Duration {
+	if failedAttempt < 1 {
+		return 0
+	}
+	seconds := float64(max(policy.Backoff.InitialSeconds, 0))
+	if strings.EqualFold(policy.Backoff.Type, "exponential") && policy.Backoff.Multiplier > 0 {
+		seconds *= math.Pow(policy.Backoff.Multiplier, float64(failedAttempt-1))
+	}
+	if policy.Backoff.MaxSeconds > 0 && seconds > float64(policy.Backoff.MaxSeconds) {
+		seconds = float64(policy.Backoff.MaxSeconds)
+	}
+	return time.Duration(seconds) * time.Second
+}
@@ -0,0 +1,149 @@
+package dag
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/domain"
+)
+
+// diamondPlan is prepare -> (train, eval-data) -> report; train retries
+// twice with a fixed 30s backoff.
+func diamondPlan() domain.ExecutionPlan {
+	return domain.ExecutionPlan{
+		Steps: []domain.ExecutionPlanStep{
+			{Name: "prepare", RetryPolicy: domain.PipelineRetryPolicy{MaxAttempts: 1}},
+			{Name: "eval-data", RetryPolicy: domain.PipelineRetryPolicy{MaxAttempts: 1}},
+			{Name: "train", RetryPolicy: domain.PipelineRetryPolicy{MaxAttempts: 3, Backoff: domain.PipelineBackoff{Type: "fixed", InitialSeconds: 30}}},
+			{Name: "report", RetryPolicy: domain.PipelineRetryPolicy{MaxAttempts: 1}},
+		},
+		Edges: []domain.ExecutionPlanEdge{
+			{From: "prepare", To: "eval-data"},
+			{From: "prepare", To: "train"},
+			{From: "eval-data", To: "report"},
+			{From: "train", To: "report"},
+		},
+	}
+}
+
+func statusesOf(eval Evaluation) map[string]string {
+	out := make(map[string]string, len(eval.Steps))
+	for _, step := range eval.Steps {
+		out[step.Name] = step.Status
+	}
+	return out
+}
+
+func TestEvaluate(t *testing.T) {
+	finished := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name     string
+		attempts []Attempt
+		statuses map[string]string
+		start    []Start
+		state    domain.RunState
+	}{
+		{
+			name:     "fresh run starts roots",
+			statuses: map[string]string{"prepare": StepPending, "eval-data": StepWaiting, "train": StepWaiting, "report": StepWaiting},
+			start:    []Start{{StepName: "prepare", Attempt: 1}},
+			state:    domain.RunStateRunning,
+		},
+		{
+			name:     "fan out after root",
+			attempts: []Attempt{{StepName: "prepare", Attempt: 1, Status: AttemptSucceeded, FinishedAt: finished}},
+			statuses: map[string]string{"prepare": StepSucceeded, "eval-data": StepPending, "train": StepPending, "report": StepWaiting},
+			start:    []Start{{StepName: "eval-data", Attempt: 1}, {StepName: "train", Attempt: 1}},
+			state:    domain.RunStateRunning,
+		},
+		{
+			name: "retry after backoff",
+			attempts: []Attempt{
+				{StepName: "prepare", Attempt: 1, Status: AttemptSucceeded, FinishedAt: finished},
+				{StepName: "eval-data", Attempt: 1, Status: AttemptRunning},
+				{StepName: "train", Attempt: 1, Status: AttemptFailed, FinishedAt: finished},
+			},
+			statuses: map[string]string{"prepare": StepSucceeded, "eval-data": StepRunning, "train": StepRetrying, "report": StepWaiting},
+			start:    []Start{{StepName: "train", Attempt: 2, NotBefore: finished.Add(30 * time.Second)}},
+			state:    domain.RunStateRunning,
+		},
+		{
+			name: "scheduled retry is not started twice",
+			attempts: []Attempt{
+				{StepName: "prepare", Attempt: 1, Status: AttemptSucceeded, FinishedAt: finished},
+				{StepName: "eval-data", Attempt: 1, Status: AttemptSucceeded, FinishedAt: finished},
+				{StepName: "train", Attempt: 1, Status: AttemptFailed, FinishedAt: finished},
+				{StepName: "train", Attempt: 2, Status: AttemptPending},
+			},
+			statuses: map[string]string{"prepare": StepSucceeded, "eval-data": StepSucceeded, "train": StepRetrying, "report": StepWaiting},
+			state:    domain.RunStateRunning,
+		},
+		{
+			name: "all succeeded",
+			attempts: []Attempt{
+				{StepName: "prepare", Attempt: 1, Status: AttemptSucceeded},
+				{StepName: "eval-data", Attempt: 1, Status: AttemptSucceeded},
+				{StepName: "train", Attempt: 1, Status: AttemptFailed},
+				{StepName: "train", Attempt: 2, Status: AttemptSucceeded},
+				{StepName: "report", Attempt: 1, Status: AttemptSucceeded},
+			},
+			statuses: map[string]string{"prepare": StepSucceeded, "eval-data": StepSucceeded, "train": StepSucceeded, "report": StepSucceeded},
+			state:    domain.RunStateSucceeded,
+		},
+		{
+			name: "final failure waits for in-flight steps",
+			attempts: []Attempt{
+				{StepName: "prepare", Attempt: 1, Status: AttemptSucceeded},
+				{StepName: "eval-data", Attempt: 1, Status: AttemptFailed},
+				{StepName: "train", Attempt: 1, Status: AttemptRunning},
+			},
+			statuses: map[string]string{"prepare": StepSucceeded, "eval-data": StepFailed, "train": StepRunning, "report": StepSkipped},
+			state:    domain.RunStateRunning,
+		},
+		{
+			name: "final failure stops retries",
+			attempts: []Attempt{
+				{StepName: "prepare", Attempt: 1, Status: AttemptSucceeded},
+				{StepName: "eval-data", Attempt: 1, Status: AttemptFailed},
+				{StepName: "train", Attempt: 1, Status: AttemptFailed},
+			},
+			statuses: map[string]string{"prepare": StepSucceeded, "eval-data": StepFailed, "train": StepFailed, "report": StepSkipped},
+			state:    domain.RunStateFailed,
+		},
+		{
+			name:     "failed root skips the rest",
+			attempts: []Attempt{{StepName: "prepare", Attempt: 1, Status: AttemptFailed}},
+			statuses: map[string]string{"prepare": StepFailed, "eval-data": StepSkipped, "train": StepSkipped, "report": StepSkipped},
+			state:    domain.RunStateFailed,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			eval := Evaluate(diamondPlan(), tc.attempts)
+			if got := statusesOf(eval); !reflect.DeepEqual(got, tc.statuses) {
+				t.Fatalf("statuses = %v, want %v", got, tc.statuses)
+			}
+			if !reflect.DeepEqual(eval.Start, tc.start) {
+				t.Fatalf("start = %+v, want %+v", eval.Start, tc.start)
+			}
+			if eval.State != tc.state {
+				t.Fatalf("state = %s, want %s", eval.State, tc.state)
+			}
+		})
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	exponential := domain.PipelineRetryPolicy{Backoff: domain.PipelineBackoff{Type: "exponential", InitialSeconds: 10, MaxSeconds: 60, Multiplier: 2}}
+	for attempt, want := range map[int]time.Duration{0: 0, 1: 10 * time.Second, 2: 20 * time.Second, 3: 40 * time.Second, 4: 60 * time.Second} {
+		if got := RetryDelay(exponential, attempt); got != want {
+			t.Fatalf("exponential attempt %d = %s, want %s", attempt, got, want)
+		}
+	}
+	fixed := domain.PipelineRetryPolicy{Backoff: domain.PipelineBackoff{Type: "fixed", InitialSeconds: 90, MaxSeconds: 60}}
+	if got := RetryDelay(fixed, 3); got != 60*time.Second {
+		t.Fatalf("fixed = %s", got)
+	}
+}
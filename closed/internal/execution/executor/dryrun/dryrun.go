@@ -5,6 +5,7 @@ import (
 	"crypto/sha256"
 	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
 	"strings"
@@ -22,6 +23,12 @@ const (
 	StatusSkipped   = "Skipped"
 )
 
+var (
+	ErrStepNotFound               = errors.New("step not found in plan")
+	ErrStepNotRerunnable          = errors.New("step did not fail")
+	ErrStepDependenciesIncomplete = errors.New("step dependencies have not succeeded")
+)
+
 type outcomeDecider func(specHash, runID, stepName string, attempt int) float64
 
 type Executor struct {
@@ -118,60 +125,136 @@ func (e *Executor) DryRun(ctx context.Context, input executor.DryRunInput) (exec
 			return executor.DryRunResult{}, fmt.Errorf("attempts exceed maxAttempts for step %q", stepName)
 		}
 
-		for attempt := startAttempt; attempt <= maxAttempts; attempt++ {
-			score := e.decide(specHash, runID, stepName, attempt)
-			success := score < 0.8
-			status := StatusFailed
-			errorCode := ""
-			errorMessage := ""
-			resultPayload := map[string]any{
-				"dry_run": true,
-				"attempt": attempt,
-				"score":   score,
-			}
+		stepInserted, final, err := e.attemptStep(ctx, projectID, runID, specHash, step, startAttempt, maxAttempts, baseTime, &seq)
+		inserted = append(inserted, stepInserted...)
+		if err != nil {
+			return executor.DryRunResult{}, err
+		}
+		if final != "" {
+			finalStatuses[stepName] = final
+		}
+	}
 
-			if success {
-				status = StatusSucceeded
-			} else if attempt < maxAttempts {
-				status = StatusRetried
-				errorCode = "dry_run_retry"
-				errorMessage = "simulated failure; retrying"
-				backoffSeconds := computeBackoffSeconds(step.RetryPolicy, attempt)
-				resultPayload["backoff_seconds"] = backoffSeconds
-			} else {
-				status = StatusFailed
-				errorCode = "dry_run_failed"
-				errorMessage = "simulated failure; retries exhausted"
-			}
+	allRecords := append(records, inserted...)
+	result := buildResult(input.Plan, allRecords, false)
+	result.Attempts = buildAttemptResults(inserted)
+	return result, nil
+}
 
-			record := newAttemptRecord(projectID, runID, stepName, attempt, status, specHash, baseTime, seq, resultPayload, errorCode, errorMessage)
-			seq++
-			insertedRecord, created, err := e.repo.InsertAttempt(ctx, record)
-			if err != nil {
-				return executor.DryRunResult{}, err
-			}
-			if created {
-				inserted = append(inserted, insertedRecord)
-			}
+// RerunStep simulates a fresh retry cycle for one step whose latest outcome
+// is Failed or Skipped. Attempt numbers continue after the last recorded
+// attempt and the step gets its full maxAttempts budget again. Every
+// dependency must have succeeded.
+func (e *Executor) RerunStep(ctx context.Context, input executor.DryRunInput, stepName string) (executor.DryRunResult, error) {
+	projectID := strings.TrimSpace(input.ProjectID)
+	runID := strings.TrimSpace(input.RunID)
+	specHash := strings.TrimSpace(input.SpecHash)
+	stepName = strings.TrimSpace(stepName)
+	if projectID == "" {
+		return executor.DryRunResult{}, fmt.Errorf("project id is required")
+	}
+	if runID == "" {
+		return executor.DryRunResult{}, fmt.Errorf("run id is required")
+	}
+	if specHash == "" {
+		return executor.DryRunResult{}, fmt.Errorf("spec hash is required")
+	}
+	if e == nil || e.repo == nil {
+		return executor.DryRunResult{}, fmt.Errorf("step execution repository is required")
+	}
 
-			st.Attempts = attempt
-			if status == StatusSucceeded {
-				finalStatuses[stepName] = StatusSucceeded
-				break
-			}
-			if status == StatusFailed {
-				finalStatuses[stepName] = StatusFailed
-				break
-			}
+	var step domain.ExecutionPlanStep
+	found := false
+	for _, candidate := range input.Plan.Steps {
+		if strings.TrimSpace(candidate.Name) == stepName {
+			step, found = candidate, true
+			break
 		}
 	}
+	if !found {
+		return executor.DryRunResult{}, ErrStepNotFound
+	}
+	maxAttempts := step.RetryPolicy.MaxAttempts
+	if maxAttempts < 1 {
+		return executor.DryRunResult{}, fmt.Errorf("invalid maxAttempts for step %q", stepName)
+	}
 
-	allRecords := append(records, inserted...)
-	result := buildResult(input.Plan, allRecords, false)
+	records, err := e.repo.ListByRun(ctx, projectID, runID)
+	if err != nil {
+		return executor.DryRunResult{}, err
+	}
+	state := buildStepState(records)
+	st := state[stepName]
+	if st.TerminalStatus != StatusFailed && st.TerminalStatus != StatusSkipped {
+		return executor.DryRunResult{}, ErrStepNotRerunnable
+	}
+	statuses := make(map[string]string, len(state))
+	for name, depState := range state {
+		statuses[name] = depState.TerminalStatus
+	}
+	if depsFailed(stepName, dependencies(input.Plan.Edges), statuses) {
+		return executor.DryRunResult{}, ErrStepDependenciesIncomplete
+	}
+
+	seq := 0
+	inserted, _, err := e.attemptStep(ctx, projectID, runID, specHash, step, st.Attempts+1, st.Attempts+maxAttempts, e.now().UTC(), &seq)
+	if err != nil {
+		return executor.DryRunResult{}, err
+	}
+	result := buildResult(input.Plan, append(records, inserted...), false)
 	result.Attempts = buildAttemptResults(inserted)
 	return result, nil
 }
 
+// attemptStep records attempts first..last until one succeeds; a failure
+// before last is Retried, a failure at last is Failed. It returns the
+// inserted records and the terminal status reached, if any.
+func (e *Executor) attemptStep(ctx context.Context, projectID, runID, specHash string, step domain.ExecutionPlanStep, first, last int, baseTime time.Time, seq *int) ([]repo.StepExecutionRecord, string, error) {
+	stepName := strings.TrimSpace(step.Name)
+	inserted := make([]repo.StepExecutionRecord, 0)
+	for attempt := first; attempt <= last; attempt++ {
+		score := e.decide(specHash, runID, stepName, attempt)
+		success := score < 0.8
+		status := StatusFailed
+		errorCode := ""
+		errorMessage := ""
+		resultPayload := map[string]any{
+			"dry_run": true,
+			"attempt": attempt,
+			"score":   score,
+		}
+
+		if success {
+			status = StatusSucceeded
+		} else if attempt < last {
+			status = StatusRetried
+			errorCode = "dry_run_retry"
+			errorMessage = "simulated failure; retrying"
+			backoffSeconds := computeBackoffSeconds(step.RetryPolicy, attempt-first+1)
+			resultPayload["backoff_seconds"] = backoffSeconds
+		} else {
+			status = StatusFailed
+			errorCode = "dry_run_failed"
+			errorMessage = "simulated failure; retries exhausted"
+		}
+
+		record := newAttemptRecord(projectID, runID, stepName, attempt, status, specHash, baseTime, *seq, resultPayload, errorCode, errorMessage)
+		*seq++
+		insertedRecord, created, err := e.repo.InsertAttempt(ctx, record)
+		if err != nil {
+			return inserted, "", err
+		}
+		if created {
+			inserted = append(inserted, insertedRecord)
+		}
+
+		if status == StatusSucceeded || status == StatusFailed {
+			return inserted, status, nil
+		}
+	}
+	return inserted, "", nil
+}
+
 type stepState struct {
 	Attempts        int
 	TerminalStatus  string
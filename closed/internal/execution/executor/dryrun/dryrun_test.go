@@ -267,3 +267,88 @@ func itoa(value int) string {
 	}
 	return sign + string(buf)
 }
+
+func TestRerunStepContinuesAttempts(t *testing.T) {
+	plan := samplePlan(1, 2)
+	input := executor.DryRunInput{
+		ProjectID: "proj-1",
+		RunID:     "run-1",
+		SpecHash:  "spec-hash",
+		Plan:      plan,
+	}
+	repo := newMemoryRepo()
+	exec := New(repo)
+	exec.now = func() time.Time { return time.Date(2026, 2, 2, 8, 0, 0, 0, time.UTC) }
+	exec.decide = func(specHash, runID, stepName string, attempt int) float64 {
+		if attempt < 4 {
+			return 0.99
+		}
+		return 0.01
+	}
+
+	first, err := exec.DryRun(context.Background(), input)
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if first.Status != StatusFailed {
+		t.Fatalf("expected failed dry run, got %s", first.Status)
+	}
+
+	result, err := exec.RerunStep(context.Background(), input, "a")
+	if err != nil {
+		t.Fatalf("rerun: %v", err)
+	}
+	if result.Status != StatusSucceeded {
+		t.Fatalf("expected rerun to succeed, got %s", result.Status)
+	}
+	if len(result.Attempts) != 2 || result.Attempts[0].Attempt != 3 || result.Attempts[0].Status != StatusRetried || result.Attempts[1].Attempt != 4 {
+		t.Fatalf("unexpected rerun attempts: %+v", result.Attempts)
+	}
+
+	if _, err := exec.RerunStep(context.Background(), input, "a"); err != ErrStepNotRerunnable {
+		t.Fatalf("expected ErrStepNotRerunnable for succeeded step, got %v", err)
+	}
+	if _, err := exec.RerunStep(context.Background(), input, "missing"); err != ErrStepNotFound {
+		t.Fatalf("expected ErrStepNotFound, got %v", err)
+	}
+}
+
+func TestRerunStepRequiresSucceededDependencies(t *testing.T) {
+	plan := samplePlan(2, 1)
+	plan.Edges = []domain.ExecutionPlanEdge{{From: "a", To: "b"}}
+	input := executor.DryRunInput{
+		ProjectID: "proj-1",
+		RunID:     "run-1",
+		SpecHash:  "spec-hash",
+		Plan:      plan,
+	}
+	repo := newMemoryRepo()
+	exec := New(repo)
+	exec.now = func() time.Time { return time.Date(2026, 2, 2, 9, 0, 0, 0, time.UTC) }
+	failFirst := true
+	exec.decide = func(specHash, runID, stepName string, attempt int) float64 {
+		if stepName == "a" && failFirst {
+			return 0.99
+		}
+		return 0.01
+	}
+
+	if _, err := exec.DryRun(context.Background(), input); err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if _, err := exec.RerunStep(context.Background(), input, "b"); err != ErrStepDependenciesIncomplete {
+		t.Fatalf("expected ErrStepDependenciesIncomplete, got %v", err)
+	}
+
+	failFirst = false
+	if _, err := exec.RerunStep(context.Background(), input, "a"); err != nil {
+		t.Fatalf("rerun a: %v", err)
+	}
+	result, err := exec.RerunStep(context.Background(), input, "b")
+	if err != nil {
+		t.Fatalf("rerun b: %v", err)
+	}
+	if result.Status != StatusSucceeded {
+		t.Fatalf("expected run to succeed after reruns, got %s", result.Status)
+	}
+}
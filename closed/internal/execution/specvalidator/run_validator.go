@@ -2,11 +2,10 @@ package specvalidator
 
 import (
 	"fmt"
-	"net/url"
 	"strings"
-	"unicode"
 
 	"github.com/animus-labs/animus-go/closed/internal/domain"
+	"github.com/animus-labs/animus-go/closed/internal/platform/validation"
 )
 
 // ValidateRunSpec performs strict validation of a RunSpec.
@@ -22,13 +21,13 @@ func ValidateRunSpec(spec domain.RunSpec) error {
 	repoURL := strings.TrimSpace(spec.CodeRef.RepoURL)
 	if repoURL == "" {
 		issues.Add("codeRef.repoUrl is required")
-	} else if !validRepoURL(repoURL) {
+	} else if !validation.IsRepoURL(repoURL) {
 		issues.Add("codeRef.repoUrl is invalid")
 	}
 	commit := strings.TrimSpace(spec.CodeRef.CommitSHA)
 	if commit == "" {
 		issues.Add("codeRef.commitSha is required")
-	} else if !validCommitSHA(commit) {
+	} else if !validation.IsCommitSHA(commit) {
 		issues.Add("codeRef.commitSha must be hex (7..64)")
 	}
 	if strings.TrimSpace(spec.EnvLock.EnvHash) == "" {
@@ -158,43 +157,6 @@ func ValidateRunSpec(spec domain.RunSpec) error {
 	return issues.OrNil()
 }
 
-func validRepoURL(raw string) bool {
-	raw = strings.TrimSpace(raw)
-	if raw == "" {
-		return false
-	}
-	if strings.HasPrefix(raw, "git@") {
-		parts := strings.SplitN(raw, ":", 2)
-		if len(parts) != 2 {
-			return false
-		}
-		host := strings.TrimSpace(strings.TrimPrefix(parts[0], "git@"))
-		path := strings.TrimSpace(parts[1])
-		return host != "" && path != ""
-	}
-	parsed, err := url.Parse(raw)
-	if err != nil {
-		return false
-	}
-	if parsed.Scheme == "" || parsed.Host == "" {
-		return false
-	}
-	return true
-}
-
-func validCommitSHA(raw string) bool {
-	raw = strings.TrimSpace(raw)
-	if len(raw) < 7 || len(raw) > 64 {
-		return false
-	}
-	for _, r := range raw {
-		if !unicode.IsDigit(r) && (r < 'a' || r > 'f') && (r < 'A' || r > 'F') {
-			return false
-		}
-	}
-	return true
-}
-
 func pipelineDatasetRefs(spec domain.PipelineSpec) map[string]struct{} {
 	refs := make(map[string]struct{})
 	for _, step := range spec.Spec.Steps {
@@ -0,0 +1,85 @@
+package digests
+
+import (
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNextRun(t *testing.T) {
+	// 2026-03-04 is a Wednesday.
+	after := time.Date(2026, 3, 4, 10, 30, 0, 0, time.UTC)
+	cases := []struct {
+		name    string
+		cadence Cadence
+		hour    int
+		weekday time.Weekday
+		want    time.Time
+	}{
+		{"daily later today", CadenceDaily, 12, time.Monday, time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)},
+		{"daily tomorrow", CadenceDaily, 10, time.Monday, time.Date(2026, 3, 5, 10, 0, 0, 0, time.UTC)},
+		{"weekly next monday", CadenceWeekly, 9, time.Monday, time.Date(2026, 3, 9, 9, 0, 0, 0, time.UTC)},
+		{"weekly later today", CadenceWeekly, 18, time.Wednesday, time.Date(2026, 3, 4, 18, 0, 0, 0, time.UTC)},
+		{"weekly next week", CadenceWeekly, 8, time.Wednesday, time.Date(2026, 3, 11, 8, 0, 0, 0, time.UTC)},
+	}
+	for _, tc := range cases {
+		if got := NextRun(tc.cadence, tc.hour, tc.weekday, after); !got.Equal(tc.want) {
+			t.Fatalf("%s: NextRun = %s, want %s", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestNormalizeRecipients(t *testing.T) {
+	got, err := NormalizeRecipients([]string{" Ops@Example.com", "", "lead@example.com", "ops@example.com"})
+	if err != nil {
+		t.Fatalf("NormalizeRecipients: %v", err)
+	}
+	if strings.Join(got, ",") != "lead@example.com,ops@example.com" {
+		t.Fatalf("unexpected recipients: %v", got)
+	}
+	for _, bad := range []string{"not-an-address", "Ops <ops@example.com>"} {
+		if _, err := NormalizeRecipients([]string{bad}); err == nil {
+			t.Fatalf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestMailerSend(t *testing.T) {
+	var (
+		gotAddr string
+		gotFrom string
+		gotTo   []string
+		gotMsg  string
+	)
+	m := NewMailer(SMTPConfig{Addr: "smtp.example.com:587", From: "Animus <animus@example.com>"})
+	m.send = func(addr string, _ smtp.Auth, from string, to []string, msg []byte) error {
+		gotAddr, gotFrom, gotTo, gotMsg = addr, from, to, string(msg)
+		return nil
+	}
+	summary := Summary{
+		ProjectID:        "proj-1",
+		Cadence:          CadenceDaily,
+		RunsCompleted:    RunCounts{Succeeded: 3, Failed: 1},
+		ApprovalsPending: 2,
+		GatesFailed:      5,
+	}
+	subject, body := summary.Text("ml-team")
+	if err := m.Send([]string{"lead@example.com"}, subject, body, time.Now()); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if gotAddr != "smtp.example.com:587" || gotFrom != "animus@example.com" || len(gotTo) != 1 {
+		t.Fatalf("unexpected envelope: %s %s %v", gotAddr, gotFrom, gotTo)
+	}
+	for _, want := range []string{"Subject: [Animus] daily digest: ml-team", "Runs completed: 4 (succeeded 3, failed 1, canceled 0)", "Gates failed: 5\r\n"} {
+		if !strings.Contains(gotMsg, want) {
+			t.Fatalf("message missing %q:\n%s", want, gotMsg)
+		}
+	}
+}
+
+func TestMailerDisabled(t *testing.T) {
+	if NewMailer(SMTPConfig{}) != nil {
+		t.Fatalf("expected nil mailer without smtp address")
+	}
+}
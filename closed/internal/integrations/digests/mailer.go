@@ -0,0 +1,92 @@
+package digests
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/env"
+)
+
+// SMTPConfig enables the email channel. Without an address only webhook
+// digests can be scheduled.
+type SMTPConfig struct {
+	Addr     string
+	From     string
+	Username string
+	Password string
+}
+
+func SMTPConfigFromEnv() (SMTPConfig, error) {
+	cfg := SMTPConfig{
+		Addr:     strings.TrimSpace(env.String("ANIMUS_DIGEST_SMTP_ADDR", "")),
+		From:     strings.TrimSpace(env.String("ANIMUS_DIGEST_SMTP_FROM", "")),
+		Username: strings.TrimSpace(env.String("ANIMUS_DIGEST_SMTP_USERNAME", "")),
+		Password: env.String("ANIMUS_DIGEST_SMTP_PASSWORD", ""),
+	}
+	if !cfg.Enabled() {
+		return cfg, nil
+	}
+	if _, _, err := net.SplitHostPort(cfg.Addr); err != nil {
+		return SMTPConfig{}, fmt.Errorf("ANIMUS_DIGEST_SMTP_ADDR must be host:port: %w", err)
+	}
+	if _, err := mail.ParseAddress(cfg.From); err != nil {
+		return SMTPConfig{}, errors.New("ANIMUS_DIGEST_SMTP_FROM must be an email address")
+	}
+	return cfg, nil
+}
+
+func (c SMTPConfig) Enabled() bool {
+	return c.Addr != ""
+}
+
+type sendFunc func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+
+type Mailer struct {
+	cfg  SMTPConfig
+	send sendFunc
+}
+
+// NewMailer returns nil when SMTP is not configured.
+func NewMailer(cfg SMTPConfig) *Mailer {
+	if !cfg.Enabled() {
+		return nil
+	}
+	return &Mailer{cfg: cfg, send: smtp.SendMail}
+}
+
+func (m *Mailer) Send(to []string, subject, body string, now time.Time) error {
+	if m == nil {
+		return errors.New("smtp not configured")
+	}
+	if len(to) == 0 {
+		return errors.New("recipients are required")
+	}
+	var auth smtp.Auth
+	if m.cfg.Username != "" {
+		host, _, _ := net.SplitHostPort(m.cfg.Addr)
+		auth = smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, host)
+	}
+	from, err := mail.ParseAddress(m.cfg.From)
+	if err != nil {
+		return err
+	}
+	return m.send(m.cfg.Addr, auth, from.Address, to, buildMessage(m.cfg.From, to, subject, body, now))
+}
+
+func buildMessage(from string, to []string, subject, body string, now time.Time) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", now.UTC().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	return []byte(b.String())
+}
@@ -0,0 +1,167 @@
+package digests
+
+import (
+	"fmt"
+	"net/mail"
+	"sort"
+	"strings"
+	"time"
+)
+
+type Cadence string
+
+const (
+	CadenceDaily  Cadence = "daily"
+	CadenceWeekly Cadence = "weekly"
+)
+
+type Channel string
+
+const (
+	ChannelWebhook Channel = "webhook"
+	ChannelEmail   Channel = "email"
+)
+
+const maxRecipients = 20
+
+// Digest is a scheduled summary of project activity. A digest with a Subject
+// only counts activity that subject initiated; otherwise it covers the whole
+// project.
+type Digest struct {
+	ID         string
+	ProjectID  string
+	Name       string
+	Subject    string
+	Cadence    Cadence
+	HourUTC    int
+	Weekday    time.Weekday
+	Channel    Channel
+	Recipients []string
+	Enabled    bool
+	NextRunAt  time.Time
+	LastSentAt *time.Time
+	CreatedAt  time.Time
+	CreatedBy  string
+	UpdatedAt  time.Time
+}
+
+type RunCounts struct {
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+	Canceled  int `json:"canceled"`
+}
+
+func (c RunCounts) Total() int {
+	return c.Succeeded + c.Failed + c.Canceled
+}
+
+// Usage reports absolute consumption; the platform has no project quotas to
+// compare it against.
+type Usage struct {
+	DatasetStorageBytes   int64 `json:"dataset_storage_bytes"`
+	ArtifactStorageBytes  int64 `json:"artifact_storage_bytes"`
+	ActiveDevEnvironments int   `json:"active_dev_environments"`
+}
+
+type Summary struct {
+	DigestID         string    `json:"digest_id"`
+	ProjectID        string    `json:"project_id"`
+	Subject          string    `json:"subject,omitempty"`
+	Cadence          Cadence   `json:"cadence"`
+	PeriodStart      time.Time `json:"period_start"`
+	PeriodEnd        time.Time `json:"period_end"`
+	RunsCompleted    RunCounts `json:"runs_completed"`
+	ApprovalsPending int       `json:"approvals_pending"`
+	GatesFailed      int       `json:"gates_failed"`
+	Usage            Usage     `json:"usage"`
+}
+
+func (c Cadence) Valid() bool {
+	switch c {
+	case CadenceDaily, CadenceWeekly:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c Cadence) Period() time.Duration {
+	if c == CadenceWeekly {
+		return 7 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+func (c Channel) Valid() bool {
+	switch c {
+	case ChannelWebhook, ChannelEmail:
+		return true
+	default:
+		return false
+	}
+}
+
+// NextRun returns the first scheduled time strictly after the given instant.
+// Weekly digests fire on the weekday, daily digests ignore it.
+func NextRun(cadence Cadence, hourUTC int, weekday time.Weekday, after time.Time) time.Time {
+	after = after.UTC()
+	next := time.Date(after.Year(), after.Month(), after.Day(), hourUTC, 0, 0, 0, time.UTC)
+	if cadence == CadenceWeekly {
+		next = next.AddDate(0, 0, (int(weekday)-int(next.Weekday())+7)%7)
+		if !next.After(after) {
+			next = next.AddDate(0, 0, 7)
+		}
+		return next
+	}
+	if !next.After(after) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// NormalizeRecipients validates email addresses and returns them lowercased,
+// deduplicated and sorted.
+func NormalizeRecipients(input []string) ([]string, error) {
+	seen := map[string]struct{}{}
+	out := make([]string, 0, len(input))
+	for _, value := range input {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		addr, err := mail.ParseAddress(value)
+		if err != nil || addr.Name != "" {
+			return nil, fmt.Errorf("invalid recipient: %s", value)
+		}
+		address := strings.ToLower(addr.Address)
+		if _, ok := seen[address]; ok {
+			continue
+		}
+		seen[address] = struct{}{}
+		out = append(out, address)
+	}
+	if len(out) > maxRecipients {
+		return nil, fmt.Errorf("at most %d recipients are allowed", maxRecipients)
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// Text renders the summary as a plain-text email.
+func (s Summary) Text(name string) (string, string) {
+	subject := fmt.Sprintf("[Animus] %s digest: %s", s.Cadence, name)
+	var b strings.Builder
+	fmt.Fprintf(&b, "Project: %s\n", s.ProjectID)
+	if s.Subject != "" {
+		fmt.Fprintf(&b, "Activity of: %s\n", s.Subject)
+	}
+	fmt.Fprintf(&b, "Period: %s - %s (UTC)\n\n", s.PeriodStart.UTC().Format(time.RFC3339), s.PeriodEnd.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&b, "Runs completed: %d (succeeded %d, failed %d, canceled %d)\n",
+		s.RunsCompleted.Total(), s.RunsCompleted.Succeeded, s.RunsCompleted.Failed, s.RunsCompleted.Canceled)
+	fmt.Fprintf(&b, "Approvals pending: %d\n", s.ApprovalsPending)
+	fmt.Fprintf(&b, "Gates failed: %d\n\n", s.GatesFailed)
+	fmt.Fprintf(&b, "Dataset storage: %d bytes\n", s.Usage.DatasetStorageBytes)
+	fmt.Fprintf(&b, "Artifact storage: %d bytes\n", s.Usage.ArtifactStorageBytes)
+	fmt.Fprintf(&b, "Active dev environments: %d\n", s.Usage.ActiveDevEnvironments)
+	return subject, b.String()
+}
@@ -0,0 +1,106 @@
+package eventbus
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/env"
+)
+
+const (
+	BackendNone  = ""
+	BackendNATS  = "nats"
+	BackendKafka = "kafka"
+
+	FormatJSON = "json"
+	FormatAvro = "avro"
+)
+
+type Config struct {
+	// Backend is empty when publishing is disabled.
+	Backend     string
+	TopicPrefix string
+	// Format is json, or avro for Kafka through a REST Proxy backed by a
+	// schema registry.
+	Format  string
+	Timeout time.Duration
+
+	// NATSURL is nats://[user:pass@]host:port, or tls:// for TLS.
+	NATSURL   string
+	NATSToken string
+
+	// KafkaRESTURL is the base URL of a Kafka REST Proxy (v2 API).
+	KafkaRESTURL      string
+	KafkaRESTUsername string
+	KafkaRESTPassword string
+}
+
+func ConfigFromEnv() (Config, error) {
+	timeout, err := env.Duration("ANIMUS_EVENTBUS_TIMEOUT", 5*time.Second)
+	if err != nil {
+		return Config{}, err
+	}
+	cfg := Config{
+		Backend:           strings.ToLower(strings.TrimSpace(env.String("ANIMUS_EVENTBUS_BACKEND", ""))),
+		TopicPrefix:       strings.TrimSpace(env.String("ANIMUS_EVENTBUS_TOPIC_PREFIX", "animus")),
+		Format:            strings.ToLower(strings.TrimSpace(env.String("ANIMUS_EVENTBUS_FORMAT", FormatJSON))),
+		Timeout:           timeout,
+		NATSURL:           strings.TrimSpace(env.String("ANIMUS_EVENTBUS_NATS_URL", "")),
+		NATSToken:         strings.TrimSpace(env.String("ANIMUS_EVENTBUS_NATS_TOKEN", "")),
+		KafkaRESTURL:      strings.TrimRight(strings.TrimSpace(env.String("ANIMUS_EVENTBUS_KAFKA_REST_URL", "")), "/"),
+		KafkaRESTUsername: strings.TrimSpace(env.String("ANIMUS_EVENTBUS_KAFKA_REST_USERNAME", "")),
+		KafkaRESTPassword: env.String("ANIMUS_EVENTBUS_KAFKA_REST_PASSWORD", ""),
+	}
+	return cfg, cfg.Validate()
+}
+
+func (c Config) Enabled() bool {
+	return c.Backend != BackendNone
+}
+
+func (c Config) Validate() error {
+	if c.Format != FormatJSON && c.Format != FormatAvro {
+		return fmt.Errorf("ANIMUS_EVENTBUS_FORMAT must be %s or %s", FormatJSON, FormatAvro)
+	}
+	if c.Timeout <= 0 {
+		return errors.New("ANIMUS_EVENTBUS_TIMEOUT must be positive")
+	}
+	switch c.Backend {
+	case BackendNone:
+		return nil
+	case BackendNATS:
+		if c.Format != FormatJSON {
+			return errors.New("ANIMUS_EVENTBUS_FORMAT=avro requires the kafka backend")
+		}
+		u, err := url.Parse(c.NATSURL)
+		if err != nil || u.Host == "" || (u.Scheme != "nats" && u.Scheme != "tls") {
+			return errors.New("ANIMUS_EVENTBUS_NATS_URL must be a nats:// or tls:// URL")
+		}
+	case BackendKafka:
+		u, err := url.Parse(c.KafkaRESTURL)
+		if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+			return errors.New("ANIMUS_EVENTBUS_KAFKA_REST_URL must be an http(s) URL")
+		}
+	default:
+		return fmt.Errorf("ANIMUS_EVENTBUS_BACKEND must be %s or %s", BackendNATS, BackendKafka)
+	}
+	return nil
+}
+
+// NewPublisher returns the configured backend, or nil when publishing is
+// disabled.
+func (c Config) NewPublisher() (Publisher, error) {
+	switch c.Backend {
+	case BackendNone:
+		return nil, nil
+	case BackendNATS:
+		return NewNATSPublisher(c.NATSURL, c.NATSToken, c.Timeout)
+	case BackendKafka:
+		return NewKafkaRESTPublisher(c.KafkaRESTURL, c.KafkaRESTUsername, c.KafkaRESTPassword, c.Format, c.Timeout), nil
+	default:
+		return nil, fmt.Errorf("unsupported event bus backend %q", c.Backend)
+	}
+}
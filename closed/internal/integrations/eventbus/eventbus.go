@@ -0,0 +1,144 @@
+// Package eventbus publishes run, dataset, evaluation and policy events to a
+// message bus so downstream platforms need not poll the HTTP APIs. Events are
+// enqueued in the transactional outbox with the change they describe and the
+// outbox relay publishes them; delivery is at least once, keyed by EventID.
+package eventbus
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/outbox"
+)
+
+// SchemaVersion is bumped on incompatible envelope changes; consumers should
+// ignore events with a version they do not know.
+const SchemaVersion = 1
+
+type EventType string
+
+const (
+	EventRunStateChanged        EventType = "run.state_changed"
+	EventEvaluationStateChanged EventType = "evaluation.state_changed"
+	EventDatasetVersionCreated  EventType = "dataset_version.created"
+	EventDataContractEvaluated  EventType = "data_contract.evaluated"
+	EventPolicyDecisionRecorded EventType = "policy.decision_recorded"
+	EventExperimentCreated      EventType = "experiment.created"
+)
+
+func (t EventType) Valid() bool {
+	switch t {
+	case EventRunStateChanged, EventEvaluationStateChanged, EventDatasetVersionCreated, EventDataContractEvaluated, EventPolicyDecisionRecorded, EventExperimentCreated:
+		return true
+	default:
+		return false
+	}
+}
+
+// Event is the versioned envelope published on the bus. Subject holds the
+// identifiers of the affected resources (run_id, dataset_version_id, ...);
+// Data holds the event-specific fields.
+type Event struct {
+	SchemaVersion int               `json:"schema_version"`
+	EventID       string            `json:"event_id"`
+	EventType     EventType         `json:"event_type"`
+	OccurredAt    time.Time         `json:"occurred_at"`
+	ProjectID     string            `json:"project_id,omitempty"`
+	Subject       map[string]string `json:"subject"`
+	Data          json.RawMessage   `json:"data"`
+}
+
+// NewEvent builds an event whose id is derived from eventType and sourceID,
+// the id of the row that records the change, so re-enqueueing the same
+// change yields the same event.
+func NewEvent(eventType EventType, sourceID string, projectID string, occurredAt time.Time, subject map[string]string, data any) (Event, error) {
+	if !eventType.Valid() {
+		return Event{}, fmt.Errorf("invalid event type %q", eventType)
+	}
+	sourceID = strings.TrimSpace(sourceID)
+	if sourceID == "" {
+		return Event{}, errors.New("source id is required")
+	}
+	if occurredAt.IsZero() {
+		occurredAt = time.Now().UTC()
+	}
+	if data == nil {
+		data = map[string]any{}
+	}
+	dataJSON, err := json.Marshal(data)
+	if err != nil {
+		return Event{}, fmt.Errorf("marshal event data: %w", err)
+	}
+	cleaned := make(map[string]string, len(subject))
+	for key, value := range subject {
+		if value = strings.TrimSpace(value); value != "" {
+			cleaned[key] = value
+		}
+	}
+	sum := sha256.Sum256([]byte(string(eventType) + "|" + sourceID))
+	return Event{
+		SchemaVersion: SchemaVersion,
+		EventID:       "ev-" + hex.EncodeToString(sum[:]),
+		EventType:     eventType,
+		OccurredAt:    occurredAt.UTC(),
+		ProjectID:     strings.TrimSpace(projectID),
+		Subject:       cleaned,
+		Data:          dataJSON,
+	}, nil
+}
+
+// Key is the partition key: events about one resource stay ordered.
+func (e Event) Key() string {
+	for _, field := range []string{"run_id", "dataset_version_id", "evaluation_id", "policy_decision_id", "experiment_id"} {
+		if value := e.Subject[field]; value != "" {
+			return value
+		}
+	}
+	return e.EventID
+}
+
+// Publisher sends one event to topic. Implementations must be safe for use
+// by one goroutine at a time; the outbox relay publishes sequentially.
+type Publisher interface {
+	Publish(ctx context.Context, topic string, event Event) error
+	Close() error
+}
+
+// Enqueue stores event in the outbox through q, normally the transaction of
+// the change it describes.
+func Enqueue(ctx context.Context, q outbox.QueryRower, event Event) error {
+	if !event.EventType.Valid() {
+		return fmt.Errorf("invalid event type %q", event.EventType)
+	}
+	if _, err := outbox.Append(ctx, q, outbox.KindBus, event.EventID, event); err != nil {
+		return fmt.Errorf("enqueue bus event: %w", err)
+	}
+	return nil
+}
+
+// Deliver returns the outbox.DeliverFunc for outbox.KindBus. Topics are
+// "<topicPrefix>.<event type>", e.g. animus.run.state_changed.
+func Deliver(publisher Publisher, topicPrefix string) outbox.DeliverFunc {
+	topicPrefix = strings.Trim(strings.TrimSpace(topicPrefix), ".")
+	return func(ctx context.Context, _ *sql.Tx, record json.RawMessage) (int64, error) {
+		var event Event
+		if err := json.Unmarshal(record, &event); err != nil {
+			return 0, fmt.Errorf("decode bus outbox record: %w", err)
+		}
+		topic := string(event.EventType)
+		if topicPrefix != "" {
+			topic = topicPrefix + "." + topic
+		}
+		if err := publisher.Publish(ctx, topic, event); err != nil {
+			return 0, fmt.Errorf("publish %s: %w", topic, err)
+		}
+		return 0, nil
+	}
+}
@@ -0,0 +1,105 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type recordingPublisher struct {
+	topics []string
+	events []Event
+	err    error
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, topic string, event Event) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.topics = append(p.topics, topic)
+	p.events = append(p.events, event)
+	return nil
+}
+
+func (p *recordingPublisher) Close() error { return nil }
+
+func TestNewEventDeterministicID(t *testing.T) {
+	at := time.Unix(1700000000, 0)
+	a, err := NewEvent(EventRunStateChanged, "state-1", "p-1", at, map[string]string{"run_id": "run-1", "empty": " "}, map[string]any{"status": "running"})
+	if err != nil {
+		t.Fatalf("NewEvent() err=%v", err)
+	}
+	b, err := NewEvent(EventRunStateChanged, "state-1", "p-1", at.Add(time.Minute), nil, nil)
+	if err != nil {
+		t.Fatalf("NewEvent() err=%v", err)
+	}
+	if a.EventID != b.EventID || !strings.HasPrefix(a.EventID, "ev-") {
+		t.Fatalf("event ids differ: %q vs %q", a.EventID, b.EventID)
+	}
+	c, _ := NewEvent(EventPolicyDecisionRecorded, "state-1", "p-1", at, nil, nil)
+	if c.EventID == a.EventID {
+		t.Fatalf("event id must depend on the type")
+	}
+	if a.SchemaVersion != SchemaVersion || a.Key() != "run-1" || string(a.Data) != `{"status":"running"}` {
+		t.Fatalf("unexpected event %+v", a)
+	}
+	if _, ok := a.Subject["empty"]; ok {
+		t.Fatalf("blank subject fields must be dropped")
+	}
+	if _, err := NewEvent("run.unknown", "x", "", at, nil, nil); err == nil {
+		t.Fatalf("expected error for unknown type")
+	}
+	if _, err := NewEvent(EventRunStateChanged, " ", "", at, nil, nil); err == nil {
+		t.Fatalf("expected error without source id")
+	}
+}
+
+func TestDeliverPublishesToPrefixedTopic(t *testing.T) {
+	event, err := NewEvent(EventDatasetVersionCreated, "dv-1", "p-1", time.Now(), map[string]string{"dataset_version_id": "dv-1"}, nil)
+	if err != nil {
+		t.Fatalf("NewEvent() err=%v", err)
+	}
+	record, _ := json.Marshal(event)
+	pub := &recordingPublisher{}
+	id, err := Deliver(pub, "animus.")(context.Background(), nil, record)
+	if err != nil || id != 0 {
+		t.Fatalf("Deliver() id=%d err=%v", id, err)
+	}
+	if len(pub.topics) != 1 || pub.topics[0] != "animus.dataset_version.created" {
+		t.Fatalf("unexpected topics %v", pub.topics)
+	}
+	if pub.events[0].EventID != event.EventID {
+		t.Fatalf("event changed in transit: %+v", pub.events[0])
+	}
+
+	pub.err = errors.New("down")
+	if _, err := Deliver(pub, "")(context.Background(), nil, record); err == nil {
+		t.Fatalf("expected publish error")
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	cases := []struct {
+		name string
+		cfg  Config
+		ok   bool
+	}{
+		{"disabled", Config{Format: FormatJSON, Timeout: time.Second}, true},
+		{"nats", Config{Backend: BackendNATS, Format: FormatJSON, Timeout: time.Second, NATSURL: "nats://user:pw@nats:4222"}, true},
+		{"nats avro", Config{Backend: BackendNATS, Format: FormatAvro, Timeout: time.Second, NATSURL: "nats://nats:4222"}, false},
+		{"nats bad url", Config{Backend: BackendNATS, Format: FormatJSON, Timeout: time.Second, NATSURL: "http://nats"}, false},
+		{"kafka avro", Config{Backend: BackendKafka, Format: FormatAvro, Timeout: time.Second, KafkaRESTURL: "https://rest-proxy:8082"}, true},
+		{"kafka missing url", Config{Backend: BackendKafka, Format: FormatJSON, Timeout: time.Second}, false},
+		{"unknown backend", Config{Backend: "rabbitmq", Format: FormatJSON, Timeout: time.Second}, false},
+		{"bad format", Config{Format: "xml", Timeout: time.Second}, false},
+	}
+	for _, tc := range cases {
+		err := tc.cfg.Validate()
+		if (err == nil) != tc.ok {
+			t.Fatalf("%s: Validate() err=%v", tc.name, err)
+		}
+	}
+}
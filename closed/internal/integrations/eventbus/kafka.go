@@ -0,0 +1,135 @@
+package eventbus
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// AvroSchema is the value schema of events published with FormatAvro. Data
+// stays a JSON document so event-specific fields need no schema change.
+const AvroSchema = `{"type":"record","name":"Event","namespace":"io.animus.events","fields":[` +
+	`{"name":"schema_version","type":"int"},` +
+	`{"name":"event_id","type":"string"},` +
+	`{"name":"event_type","type":"string"},` +
+	`{"name":"occurred_at","type":{"type":"long","logicalType":"timestamp-millis"}},` +
+	`{"name":"project_id","type":"string"},` +
+	`{"name":"subject","type":{"type":"map","values":"string"}},` +
+	`{"name":"data","type":"string"}]}`
+
+// KafkaRESTPublisher produces to Kafka through a REST Proxy (v2 API), keyed
+// by Event.Key. With FormatAvro the proxy registers AvroSchema in its schema
+// registry and encodes the records.
+type KafkaRESTPublisher struct {
+	baseURL  string
+	username string
+	password string
+	format   string
+	http     *http.Client
+}
+
+func NewKafkaRESTPublisher(baseURL, username, password, format string, timeout time.Duration) *KafkaRESTPublisher {
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	if format != FormatAvro {
+		format = FormatJSON
+	}
+	return &KafkaRESTPublisher{
+		baseURL:  strings.TrimRight(strings.TrimSpace(baseURL), "/"),
+		username: username,
+		password: password,
+		format:   format,
+		http:     &http.Client{Timeout: timeout},
+	}
+}
+
+type kafkaRecord struct {
+	Key   string `json:"key"`
+	Value any    `json:"value"`
+}
+
+type kafkaProduceRequest struct {
+	KeySchema   string        `json:"key_schema,omitempty"`
+	ValueSchema string        `json:"value_schema,omitempty"`
+	Records     []kafkaRecord `json:"records"`
+}
+
+type kafkaProduceResponse struct {
+	Offsets []struct {
+		ErrorCode *int   `json:"error_code"`
+		Error     string `json:"error"`
+	} `json:"offsets"`
+}
+
+func (p *KafkaRESTPublisher) Publish(ctx context.Context, topic string, event Event) error {
+	body := kafkaProduceRequest{Records: []kafkaRecord{{Key: event.Key(), Value: event}}}
+	contentType := "application/vnd.kafka.json.v2+json"
+	if p.format == FormatAvro {
+		contentType = "application/vnd.kafka.avro.v2+json"
+		body.KeySchema = `"string"`
+		body.ValueSchema = AvroSchema
+		body.Records[0].Value = avroValue(event)
+	}
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/topics/"+url.PathEscape(topic), bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+	if p.username != "" {
+		req.SetBasicAuth(p.username, p.password)
+	}
+	resp, err := p.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	var produced kafkaProduceResponse
+	if err := json.Unmarshal(respBody, &produced); err != nil {
+		return fmt.Errorf("decode produce response: %w", err)
+	}
+	for _, offset := range produced.Offsets {
+		if offset.ErrorCode != nil || offset.Error != "" {
+			return fmt.Errorf("produce failed: %s", offset.Error)
+		}
+	}
+	return nil
+}
+
+func (p *KafkaRESTPublisher) Close() error { return nil }
+
+// avroValue is event in the Avro JSON encoding of AvroSchema.
+func avroValue(event Event) map[string]any {
+	subject := event.Subject
+	if subject == nil {
+		subject = map[string]string{}
+	}
+	data := string(event.Data)
+	if data == "" {
+		data = "{}"
+	}
+	return map[string]any{
+		"schema_version": event.SchemaVersion,
+		"event_id":       event.EventID,
+		"event_type":     string(event.EventType),
+		"occurred_at":    event.OccurredAt.UnixMilli(),
+		"project_id":     event.ProjectID,
+		"subject":        subject,
+		"data":           data,
+	}
+}
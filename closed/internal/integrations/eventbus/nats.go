@@ -0,0 +1,215 @@
+package eventbus
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NATSPublisher speaks the NATS core text protocol. Every publish is followed
+// by a PING and waits for the PONG, so a returned nil means the server has
+// processed the message; core NATS keeps no copy for absent subscribers, use
+// a JetStream stream on the subjects for durability.
+type NATSPublisher struct {
+	addr     string
+	host     string
+	useTLS   bool
+	user     string
+	password string
+	token    string
+	timeout  time.Duration
+
+	mu     sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func NewNATSPublisher(rawURL, token string, timeout time.Duration) (*NATSPublisher, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || u.Host == "" {
+		return nil, errors.New("invalid nats url")
+	}
+	if u.Scheme != "nats" && u.Scheme != "tls" {
+		return nil, fmt.Errorf("unsupported nats url scheme %q", u.Scheme)
+	}
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), "4222")
+	}
+	p := &NATSPublisher{
+		addr:    addr,
+		host:    u.Hostname(),
+		useTLS:  u.Scheme == "tls",
+		token:   strings.TrimSpace(token),
+		timeout: timeout,
+	}
+	if u.User != nil {
+		p.user = u.User.Username()
+		p.password, _ = u.User.Password()
+	}
+	return p, nil
+}
+
+func (p *NATSPublisher) Publish(ctx context.Context, topic string, event Event) error {
+	if strings.ContainsAny(topic, " \t\r\n") || topic == "" {
+		return fmt.Errorf("invalid nats subject %q", topic)
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.conn == nil {
+		if err := p.connect(ctx); err != nil {
+			return err
+		}
+	}
+	if err := p.publish(ctx, topic, payload); err != nil {
+		p.closeLocked()
+		return err
+	}
+	return nil
+}
+
+func (p *NATSPublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.closeLocked()
+}
+
+func (p *NATSPublisher) closeLocked() error {
+	if p.conn == nil {
+		return nil
+	}
+	err := p.conn.Close()
+	p.conn = nil
+	p.reader = nil
+	return err
+}
+
+func (p *NATSPublisher) publish(ctx context.Context, topic string, payload []byte) error {
+	p.setDeadline(ctx)
+	frame := make([]byte, 0, len(payload)+len(topic)+32)
+	frame = fmt.Appendf(frame, "PUB %s %d\r\n", topic, len(payload))
+	frame = append(frame, payload...)
+	frame = append(frame, "\r\nPING\r\n"...)
+	if _, err := p.conn.Write(frame); err != nil {
+		return fmt.Errorf("nats write: %w", err)
+	}
+	return p.awaitPong()
+}
+
+func (p *NATSPublisher) connect(ctx context.Context) error {
+	dialer := net.Dialer{Timeout: p.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", p.addr)
+	if err != nil {
+		return fmt.Errorf("nats dial: %w", err)
+	}
+	p.conn = conn
+	p.reader = bufio.NewReader(conn)
+	p.setDeadline(ctx)
+
+	line, err := p.readLine()
+	if err != nil {
+		p.closeLocked()
+		return err
+	}
+	if !strings.HasPrefix(line, "INFO ") {
+		p.closeLocked()
+		return fmt.Errorf("nats: unexpected greeting %q", line)
+	}
+	var info struct {
+		TLSRequired bool `json:"tls_required"`
+	}
+	_ = json.Unmarshal([]byte(strings.TrimPrefix(line, "INFO ")), &info)
+	if p.useTLS || info.TLSRequired {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: p.host, MinVersion: tls.VersionTLS12})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			p.closeLocked()
+			return fmt.Errorf("nats tls: %w", err)
+		}
+		p.conn = tlsConn
+		p.reader = bufio.NewReader(tlsConn)
+	}
+
+	connect := map[string]any{
+		"verbose":      false,
+		"pedantic":     false,
+		"tls_required": p.useTLS || info.TLSRequired,
+		"name":         "animus",
+		"lang":         "go",
+		"version":      "1",
+		"protocol":     1,
+	}
+	if p.user != "" {
+		connect["user"] = p.user
+		connect["pass"] = p.password
+	}
+	if p.token != "" {
+		connect["auth_token"] = p.token
+	}
+	blob, err := json.Marshal(connect)
+	if err != nil {
+		p.closeLocked()
+		return err
+	}
+	if _, err := fmt.Fprintf(p.conn, "CONNECT %s\r\nPING\r\n", blob); err != nil {
+		p.closeLocked()
+		return fmt.Errorf("nats write: %w", err)
+	}
+	if err := p.awaitPong(); err != nil {
+		p.closeLocked()
+		return err
+	}
+	return nil
+}
+
+// awaitPong reads until the PONG answering our PING, answering server PINGs
+// and surfacing -ERR.
+func (p *NATSPublisher) awaitPong() error {
+	for {
+		line, err := p.readLine()
+		if err != nil {
+			return err
+		}
+		switch {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			if _, err := p.conn.Write([]byte("PONG\r\n")); err != nil {
+				return fmt.Errorf("nats write: %w", err)
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("nats: %s", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+	}
+}
+
+func (p *NATSPublisher) readLine() (string, error) {
+	line, err := p.reader.ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("nats read: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func (p *NATSPublisher) setDeadline(ctx context.Context) {
+	deadline := time.Now().Add(p.timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	_ = p.conn.SetDeadline(deadline)
+}
@@ -0,0 +1,158 @@
+package eventbus
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeNATS accepts one connection, checks CONNECT and returns every
+// published message on msgs.
+func fakeNATS(t *testing.T, msgs chan<- string) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		_, _ = io.WriteString(conn, "INFO {\"server_id\":\"test\"}\r\n")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			switch {
+			case strings.HasPrefix(line, "CONNECT "):
+				if !strings.Contains(line, `"user":"svc"`) {
+					_, _ = io.WriteString(conn, "-ERR 'Authorization Violation'\r\n")
+					return
+				}
+			case line == "PING":
+				_, _ = io.WriteString(conn, "PONG\r\n")
+			case strings.HasPrefix(line, "PUB "):
+				parts := strings.Fields(line)
+				size, _ := strconv.Atoi(parts[len(parts)-1])
+				buf := make([]byte, size+2)
+				if _, err := io.ReadFull(r, buf); err != nil {
+					return
+				}
+				msgs <- parts[1] + " " + string(buf[:size])
+			}
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func TestNATSPublisher(t *testing.T) {
+	msgs := make(chan string, 1)
+	addr := fakeNATS(t, msgs)
+	pub, err := NewNATSPublisher("nats://svc:pw@"+addr, "", time.Second)
+	if err != nil {
+		t.Fatalf("NewNATSPublisher() err=%v", err)
+	}
+	defer pub.Close()
+
+	event, _ := NewEvent(EventRunStateChanged, "state-1", "p-1", time.Now(), map[string]string{"run_id": "run-1"}, nil)
+	if err := pub.Publish(context.Background(), "animus.run.state_changed", event); err != nil {
+		t.Fatalf("Publish() err=%v", err)
+	}
+	got := <-msgs
+	subject, payload, _ := strings.Cut(got, " ")
+	if subject != "animus.run.state_changed" {
+		t.Fatalf("subject=%q", subject)
+	}
+	var decoded Event
+	if err := json.Unmarshal([]byte(payload), &decoded); err != nil || decoded.EventID != event.EventID {
+		t.Fatalf("payload %s err=%v", payload, err)
+	}
+	if err := pub.Publish(context.Background(), "bad subject", event); err == nil {
+		t.Fatalf("expected invalid subject error")
+	}
+}
+
+func TestNATSPublisherAuthError(t *testing.T) {
+	addr := fakeNATS(t, make(chan string, 1))
+	pub, err := NewNATSPublisher("nats://"+addr, "", time.Second)
+	if err != nil {
+		t.Fatalf("NewNATSPublisher() err=%v", err)
+	}
+	event, _ := NewEvent(EventRunStateChanged, "state-1", "p-1", time.Now(), nil, nil)
+	err = pub.Publish(context.Background(), "animus.run.state_changed", event)
+	if err == nil || !strings.Contains(err.Error(), "Authorization Violation") {
+		t.Fatalf("expected authorization error, got %v", err)
+	}
+}
+
+func TestKafkaRESTPublisher(t *testing.T) {
+	var (
+		gotPath        string
+		gotContentType string
+		gotBody        kafkaProduceRequest
+		gotUser        string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotContentType = r.Header.Get("Content-Type")
+		gotUser, _, _ = r.BasicAuth()
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		_, _ = io.WriteString(w, `{"offsets":[{"partition":0,"offset":7,"error_code":null,"error":null}]}`)
+	}))
+	defer srv.Close()
+
+	event, _ := NewEvent(EventPolicyDecisionRecorded, "d-1", "p-1", time.Unix(1700000000, 0), map[string]string{"run_id": "run-1", "policy_decision_id": "d-1"}, nil)
+
+	pub := NewKafkaRESTPublisher(srv.URL+"/", "svc", "pw", FormatJSON, time.Second)
+	if err := pub.Publish(context.Background(), "animus.policy.decision_recorded", event); err != nil {
+		t.Fatalf("Publish() err=%v", err)
+	}
+	if gotPath != "/topics/animus.policy.decision_recorded" || gotContentType != "application/vnd.kafka.json.v2+json" || gotUser != "svc" {
+		t.Fatalf("unexpected request path=%q content-type=%q user=%q", gotPath, gotContentType, gotUser)
+	}
+	if len(gotBody.Records) != 1 || gotBody.Records[0].Key != "run-1" || gotBody.ValueSchema != "" {
+		t.Fatalf("unexpected body %+v", gotBody)
+	}
+
+	avro := NewKafkaRESTPublisher(srv.URL, "", "", FormatAvro, time.Second)
+	if err := avro.Publish(context.Background(), "animus.policy.decision_recorded", event); err != nil {
+		t.Fatalf("Publish(avro) err=%v", err)
+	}
+	if gotContentType != "application/vnd.kafka.avro.v2+json" || gotBody.ValueSchema != AvroSchema || gotBody.KeySchema != `"string"` {
+		t.Fatalf("unexpected avro request content-type=%q body=%+v", gotContentType, gotBody)
+	}
+	value, _ := gotBody.Records[0].Value.(map[string]any)
+	if value["occurred_at"] != float64(1700000000000) || value["data"] != "{}" {
+		t.Fatalf("unexpected avro value %+v", value)
+	}
+	if !json.Valid([]byte(AvroSchema)) {
+		t.Fatalf("avro schema is not valid JSON")
+	}
+}
+
+func TestKafkaRESTPublisherRecordError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"offsets":[{"partition":null,"offset":null,"error_code":40403,"error":"Schema not found"}]}`)
+	}))
+	defer srv.Close()
+
+	event, _ := NewEvent(EventRunStateChanged, "s-1", "p-1", time.Now(), nil, nil)
+	err := NewKafkaRESTPublisher(srv.URL, "", "", FormatJSON, time.Second).Publish(context.Background(), "t", event)
+	if err == nil || !strings.Contains(err.Error(), "Schema not found") {
+		t.Fatalf("expected record error, got %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+package notify
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/integrations/digests"
+	"github.com/animus-labs/animus-go/closed/internal/platform/env"
+)
+
+// Config selects the approval notification channels. Email goes through the
+// shared SMTP settings (ANIMUS_DIGEST_SMTP_*).
+type Config struct {
+	EmailTo         []string
+	SlackWebhookURL string
+	// PendingAfter sends one reminder for approvals still pending this long
+	// after the request; zero disables reminders.
+	PendingAfter time.Duration
+	PollInterval time.Duration
+	HTTPTimeout  time.Duration
+}
+
+func ConfigFromEnv() (Config, error) {
+	pendingAfter, err := env.Duration("ANIMUS_APPROVAL_NOTIFY_PENDING_AFTER", 24*time.Hour)
+	if err != nil {
+		return Config{}, err
+	}
+	pollInterval, err := env.Duration("ANIMUS_APPROVAL_NOTIFY_INTERVAL", time.Minute)
+	if err != nil {
+		return Config{}, err
+	}
+	httpTimeout, err := env.Duration("ANIMUS_APPROVAL_NOTIFY_HTTP_TIMEOUT", 5*time.Second)
+	if err != nil {
+		return Config{}, err
+	}
+	var emailTo []string
+	for _, part := range strings.Split(env.String("ANIMUS_APPROVAL_NOTIFY_EMAIL_TO", ""), ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			emailTo = append(emailTo, part)
+		}
+	}
+	if len(emailTo) > 0 {
+		emailTo, err = digests.NormalizeRecipients(emailTo)
+		if err != nil {
+			return Config{}, errors.New("ANIMUS_APPROVAL_NOTIFY_EMAIL_TO: " + err.Error())
+		}
+	}
+	cfg := Config{
+		EmailTo:         emailTo,
+		SlackWebhookURL: strings.TrimSpace(env.String("ANIMUS_APPROVAL_NOTIFY_SLACK_WEBHOOK_URL", "")),
+		PendingAfter:    pendingAfter,
+		PollInterval:    pollInterval,
+		HTTPTimeout:     httpTimeout,
+	}
+	return cfg, cfg.Validate()
+}
+
+func (c Config) Validate() error {
+	if c.SlackWebhookURL != "" && !strings.HasPrefix(c.SlackWebhookURL, "https://") {
+		return errors.New("ANIMUS_APPROVAL_NOTIFY_SLACK_WEBHOOK_URL must be an https URL")
+	}
+	if c.PendingAfter < 0 {
+		return errors.New("ANIMUS_APPROVAL_NOTIFY_PENDING_AFTER must not be negative")
+	}
+	if c.PollInterval <= 0 {
+		return errors.New("ANIMUS_APPROVAL_NOTIFY_INTERVAL must be positive")
+	}
+	if c.HTTPTimeout <= 0 {
+		return errors.New("ANIMUS_APPROVAL_NOTIFY_HTTP_TIMEOUT must be positive")
+	}
+	return nil
+}
+
+// Senders builds the configured channels. Email recipients require mailer;
+// it is an error to list them without SMTP.
+func (c Config) Senders(mailer *digests.Mailer) ([]Sender, error) {
+	var out []Sender
+	if len(c.EmailTo) > 0 {
+		if mailer == nil {
+			return nil, errors.New("ANIMUS_APPROVAL_NOTIFY_EMAIL_TO requires ANIMUS_DIGEST_SMTP_ADDR")
+		}
+		out = append(out, NewEmailSender(mailer, c.EmailTo))
+	}
+	if c.SlackWebhookURL != "" {
+		out = append(out, NewSlackSender(c.SlackWebhookURL, c.HTTPTimeout))
+	}
+	return out, nil
+}
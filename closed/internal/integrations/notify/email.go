@@ -0,0 +1,39 @@
+package notify
+
+import (
+	"context"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/integrations/digests"
+)
+
+// Mailer is the SMTP transport; *digests.Mailer implements it.
+type Mailer interface {
+	Send(to []string, subject, body string, now time.Time) error
+}
+
+var _ Mailer = (*digests.Mailer)(nil)
+
+// EmailSender mails the configured recipients plus Message.To.
+type EmailSender struct {
+	mailer Mailer
+	to     []string
+}
+
+func NewEmailSender(mailer Mailer, to []string) *EmailSender {
+	return &EmailSender{mailer: mailer, to: to}
+}
+
+func (s *EmailSender) Name() string { return "email" }
+
+func (s *EmailSender) Send(_ context.Context, msg Message) error {
+	to, err := digests.NormalizeRecipients(append(append([]string{}, s.to...), msg.To...))
+	if err != nil {
+		return err
+	}
+	sentAt := msg.SentAt
+	if sentAt.IsZero() {
+		sentAt = time.Now().UTC()
+	}
+	return s.mailer.Send(to, msg.Subject, msg.Text, sentAt)
+}
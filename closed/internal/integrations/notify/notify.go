@@ -0,0 +1,68 @@
+// Package notify pushes short human-readable notices to chat and email so
+// reviewers learn about approvals and audit alerts without polling the API.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type Event string
+
+const (
+	EventApprovalRequested Event = "approval.requested"
+	EventApprovalDecided   Event = "approval.decided"
+	EventApprovalPending   Event = "approval.pending"
+	EventAuditAlert        Event = "audit.alert"
+)
+
+// Message is one notice. To adds recipients for senders that address people
+// (email); channel senders ignore it.
+type Message struct {
+	Event   Event
+	Subject string
+	Text    string
+	To      []string
+	SentAt  time.Time
+}
+
+// Sender delivers a message over one channel.
+type Sender interface {
+	Name() string
+	Send(ctx context.Context, msg Message) error
+}
+
+// Notifier fans a message out to every configured sender.
+type Notifier struct {
+	senders []Sender
+}
+
+// New returns nil when no sender is configured, so callers can skip work.
+func New(senders ...Sender) *Notifier {
+	out := make([]Sender, 0, len(senders))
+	for _, sender := range senders {
+		if sender != nil {
+			out = append(out, sender)
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return &Notifier{senders: out}
+}
+
+// Send tries every sender; one failing channel does not block the others.
+func (n *Notifier) Send(ctx context.Context, msg Message) error {
+	if n == nil {
+		return nil
+	}
+	var errs error
+	for _, sender := range n.senders {
+		if err := sender.Send(ctx, msg); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("%s: %w", sender.Name(), err))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,114 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeSender struct {
+	name string
+	err  error
+	got  []Message
+}
+
+func (s *fakeSender) Name() string { return s.name }
+
+func (s *fakeSender) Send(_ context.Context, msg Message) error {
+	s.got = append(s.got, msg)
+	return s.err
+}
+
+type fakeMailer struct {
+	to      []string
+	subject string
+	body    string
+}
+
+func (m *fakeMailer) Send(to []string, subject, body string, _ time.Time) error {
+	m.to, m.subject, m.body = to, subject, body
+	return nil
+}
+
+func TestNotifierFansOut(t *testing.T) {
+	if New() != nil || New(nil) != nil {
+		t.Fatalf("expected nil notifier without senders")
+	}
+	var nilNotifier *Notifier
+	if err := nilNotifier.Send(context.Background(), Message{}); err != nil {
+		t.Fatalf("nil notifier: %v", err)
+	}
+
+	failing := &fakeSender{name: "slack", err: errors.New("boom")}
+	ok := &fakeSender{name: "email"}
+	err := New(failing, ok).Send(context.Background(), Message{Subject: "s"})
+	if err == nil || !strings.Contains(err.Error(), "slack: boom") {
+		t.Fatalf("expected slack error, got %v", err)
+	}
+	if len(failing.got) != 1 || len(ok.got) != 1 {
+		t.Fatalf("every sender must be tried")
+	}
+}
+
+func TestEmailSenderMergesRecipients(t *testing.T) {
+	mailer := &fakeMailer{}
+	sender := NewEmailSender(mailer, []string{"reviewers@example.com"})
+	err := sender.Send(context.Background(), Message{Subject: "Approval requested", Text: "body", To: []string{"Carol@Example.com", "reviewers@example.com"}})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if strings.Join(mailer.to, ",") != "carol@example.com,reviewers@example.com" {
+		t.Fatalf("unexpected recipients %v", mailer.to)
+	}
+	if mailer.subject != "Approval requested" || mailer.body != "body" {
+		t.Fatalf("unexpected mail %q %q", mailer.subject, mailer.body)
+	}
+}
+
+func TestSlackSender(t *testing.T) {
+	var got map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode: %v", err)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	sender := NewSlackSender(srv.URL, time.Second)
+	if err := sender.Send(context.Background(), Message{Subject: "Approval <pending>", Text: "run r-1 & more"}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if got["text"] != "*Approval &lt;pending&gt;*\nrun r-1 &amp; more" {
+		t.Fatalf("unexpected text %q", got["text"])
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid_token", http.StatusForbidden)
+	}))
+	defer failing.Close()
+	if err := NewSlackSender(failing.URL, time.Second).Send(context.Background(), Message{Subject: "s"}); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Fatalf("expected status error, got %v", err)
+	}
+}
+
+func TestConfigSenders(t *testing.T) {
+	cfg := Config{EmailTo: []string{"ops@example.com"}, PollInterval: time.Minute, HTTPTimeout: time.Second}
+	if _, err := cfg.Senders(nil); err == nil {
+		t.Fatalf("expected error for email recipients without smtp")
+	}
+	cfg = Config{SlackWebhookURL: "http://hooks.example.com/x", PollInterval: time.Minute, HTTPTimeout: time.Second}
+	if err := cfg.Validate(); err == nil {
+		t.Fatalf("expected non-https slack url to be rejected")
+	}
+	cfg.SlackWebhookURL = "https://hooks.slack.com/services/T/B/X"
+	senders, err := cfg.Senders(nil)
+	if err != nil || len(senders) != 1 || senders[0].Name() != "slack" {
+		t.Fatalf("unexpected senders %v %v", senders, err)
+	}
+}
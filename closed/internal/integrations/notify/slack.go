@@ -0,0 +1,60 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// SlackSender posts to a Slack incoming webhook. The channel is fixed by the
+// webhook, so Message.To is ignored.
+type SlackSender struct {
+	url  string
+	http *http.Client
+}
+
+func NewSlackSender(url string, timeout time.Duration) *SlackSender {
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	return &SlackSender{url: strings.TrimSpace(url), http: &http.Client{Timeout: timeout}}
+}
+
+func (s *SlackSender) Name() string { return "slack" }
+
+func (s *SlackSender) Send(ctx context.Context, msg Message) error {
+	payload, err := json.Marshal(map[string]string{"text": slackText(msg)})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// slackText bolds the subject; Slack's mrkdwn needs &, < and > escaped.
+func slackText(msg Message) string {
+	escape := strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	text := "*" + escape.Replace(msg.Subject) + "*"
+	if body := strings.TrimSpace(msg.Text); body != "" {
+		text += "\n" + escape.Replace(body)
+	}
+	return text
+}
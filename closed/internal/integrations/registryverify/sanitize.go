@@ -10,6 +10,9 @@ func SanitizeDetails(details map[string]any) json.RawMessage {
 		"reason":      {},
 		"error":       {},
 		"status_code": {},
+		"sbom":        {},
+		"sbom_type":   {},
+		"sbom_digest": {},
 	}
 	out := map[string]any{}
 	for key, value := range details {
@@ -0,0 +1,34 @@
+package registryverify
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFetchSBOMAttestationUnsupportedProvider(t *testing.T) {
+	att, err := FetchSBOMAttestation(context.Background(), NoopProvider{}, "ghcr.io/acme/runtime@sha256:deadbeef")
+	if err != nil {
+		t.Fatalf("fetch sbom: %v", err)
+	}
+	if att.Found || att.FailureReason != "sbom_unsupported" || att.Provider != ProviderNoop {
+		t.Fatalf("unexpected attestation %+v", att)
+	}
+}
+
+func TestFetchSBOMAttestationCosignStub(t *testing.T) {
+	att, err := FetchSBOMAttestation(context.Background(), CosignStubProvider{}, "ghcr.io/acme/runtime@sha256:deadbeef")
+	if err != nil {
+		t.Fatalf("fetch sbom: %v", err)
+	}
+	if !att.Found || att.PredicateType != PredicateSPDX || att.Digest == "" {
+		t.Fatalf("unexpected attestation %+v", att)
+	}
+
+	att, err = FetchSBOMAttestation(context.Background(), CosignStubProvider{}, "ghcr.io/acme/runtime:latest")
+	if err != nil {
+		t.Fatalf("fetch sbom: %v", err)
+	}
+	if att.Found || att.FailureReason != "invalid_digest_ref" {
+		t.Fatalf("expected invalid digest ref, got %+v", att)
+	}
+}
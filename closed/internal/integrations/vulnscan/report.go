@@ -0,0 +1,231 @@
+package vulnscan
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	FormatTrivy = "trivy"
+	FormatGrype = "grype"
+
+	SeverityCritical = "critical"
+	SeverityHigh     = "high"
+	SeverityMedium   = "medium"
+	SeverityLow      = "low"
+	SeverityUnknown  = "unknown"
+
+	// MaxFindings bounds the critical and high findings kept per report;
+	// counts always cover every finding.
+	MaxFindings = 500
+)
+
+// Counts is the number of distinct vulnerabilities per severity.
+type Counts struct {
+	Critical int `json:"critical"`
+	High     int `json:"high"`
+	Medium   int `json:"medium"`
+	Low      int `json:"low"`
+	Unknown  int `json:"unknown"`
+}
+
+func (c Counts) Total() int {
+	return c.Critical + c.High + c.Medium + c.Low + c.Unknown
+}
+
+type Finding struct {
+	ID               string `json:"id"`
+	Severity         string `json:"severity"`
+	Package          string `json:"package,omitempty"`
+	InstalledVersion string `json:"installed_version,omitempty"`
+	FixedVersion     string `json:"fixed_version,omitempty"`
+}
+
+// Report is a scanner result reduced to severity counts and the critical and
+// high findings.
+type Report struct {
+	Scanner        string    `json:"scanner"`
+	ScannerVersion string    `json:"scanner_version,omitempty"`
+	Counts         Counts    `json:"counts"`
+	Findings       []Finding `json:"findings"`
+}
+
+func NormalizeFormat(format string) (string, error) {
+	switch value := strings.ToLower(strings.TrimSpace(format)); value {
+	case FormatTrivy, FormatGrype:
+		return value, nil
+	default:
+		return "", fmt.Errorf("unsupported scan format %q", format)
+	}
+}
+
+// Parse reads a Trivy (`trivy image -f json`) or Grype (`grype -o json`)
+// report.
+func Parse(format string, data []byte) (Report, error) {
+	normalized, err := NormalizeFormat(format)
+	if err != nil {
+		return Report{}, err
+	}
+	if normalized == FormatGrype {
+		return ParseGrype(data)
+	}
+	return ParseTrivy(data)
+}
+
+type trivyReport struct {
+	Results []struct {
+		Vulnerabilities []struct {
+			VulnerabilityID  string `json:"VulnerabilityID"`
+			PkgName          string `json:"PkgName"`
+			InstalledVersion string `json:"InstalledVersion"`
+			FixedVersion     string `json:"FixedVersion"`
+			Severity         string `json:"Severity"`
+		} `json:"Vulnerabilities"`
+	} `json:"Results"`
+	Trivy struct {
+		Version string `json:"Version"`
+	} `json:"Trivy"`
+}
+
+func ParseTrivy(data []byte) (Report, error) {
+	var raw trivyReport
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return Report{}, fmt.Errorf("decode trivy report: %w", err)
+	}
+	if raw.Results == nil {
+		return Report{}, errors.New("trivy report has no Results")
+	}
+	builder := newReportBuilder(FormatTrivy, raw.Trivy.Version)
+	for _, result := range raw.Results {
+		for _, vuln := range result.Vulnerabilities {
+			builder.add(Finding{
+				ID:               vuln.VulnerabilityID,
+				Severity:         vuln.Severity,
+				Package:          vuln.PkgName,
+				InstalledVersion: vuln.InstalledVersion,
+				FixedVersion:     vuln.FixedVersion,
+			})
+		}
+	}
+	return builder.report(), nil
+}
+
+type grypeReport struct {
+	Matches []struct {
+		Vulnerability struct {
+			ID       string `json:"id"`
+			Severity string `json:"severity"`
+			Fix      struct {
+				Versions []string `json:"versions"`
+			} `json:"fix"`
+		} `json:"vulnerability"`
+		Artifact struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"artifact"`
+	} `json:"matches"`
+	Descriptor struct {
+		Version string `json:"version"`
+	} `json:"descriptor"`
+}
+
+func ParseGrype(data []byte) (Report, error) {
+	var raw grypeReport
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return Report{}, fmt.Errorf("decode grype report: %w", err)
+	}
+	if raw.Matches == nil {
+		return Report{}, errors.New("grype report has no matches")
+	}
+	builder := newReportBuilder(FormatGrype, raw.Descriptor.Version)
+	for _, match := range raw.Matches {
+		builder.add(Finding{
+			ID:               match.Vulnerability.ID,
+			Severity:         match.Vulnerability.Severity,
+			Package:          match.Artifact.Name,
+			InstalledVersion: match.Artifact.Version,
+			FixedVersion:     strings.Join(match.Vulnerability.Fix.Versions, ", "),
+		})
+	}
+	return builder.report(), nil
+}
+
+// normalizeSeverity maps scanner severities onto ours; Grype's Negligible
+// counts as low.
+func normalizeSeverity(severity string) string {
+	switch strings.ToLower(strings.TrimSpace(severity)) {
+	case "critical":
+		return SeverityCritical
+	case "high":
+		return SeverityHigh
+	case "medium":
+		return SeverityMedium
+	case "low", "negligible":
+		return SeverityLow
+	default:
+		return SeverityUnknown
+	}
+}
+
+type reportBuilder struct {
+	out  Report
+	seen map[string]struct{}
+}
+
+func newReportBuilder(scanner, version string) *reportBuilder {
+	return &reportBuilder{
+		out:  Report{Scanner: scanner, ScannerVersion: strings.TrimSpace(version), Findings: []Finding{}},
+		seen: map[string]struct{}{},
+	}
+}
+
+// add counts a finding once per vulnerability, package and version: Trivy
+// repeats findings across targets of the same image.
+func (b *reportBuilder) add(finding Finding) {
+	finding.ID = strings.TrimSpace(finding.ID)
+	if finding.ID == "" {
+		return
+	}
+	finding.Severity = normalizeSeverity(finding.Severity)
+	key := finding.ID + "\x00" + finding.Package + "\x00" + finding.InstalledVersion
+	if _, ok := b.seen[key]; ok {
+		return
+	}
+	b.seen[key] = struct{}{}
+
+	switch finding.Severity {
+	case SeverityCritical:
+		b.out.Counts.Critical++
+	case SeverityHigh:
+		b.out.Counts.High++
+	case SeverityMedium:
+		b.out.Counts.Medium++
+	case SeverityLow:
+		b.out.Counts.Low++
+	default:
+		b.out.Counts.Unknown++
+	}
+	if finding.Severity == SeverityCritical || finding.Severity == SeverityHigh {
+		b.out.Findings = append(b.out.Findings, finding)
+	}
+}
+
+func (b *reportBuilder) report() Report {
+	findings := b.out.Findings
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Severity != findings[j].Severity {
+			return findings[i].Severity == SeverityCritical
+		}
+		if findings[i].ID != findings[j].ID {
+			return findings[i].ID < findings[j].ID
+		}
+		return findings[i].Package < findings[j].Package
+	})
+	if len(findings) > MaxFindings {
+		b.out.Findings = findings[:MaxFindings]
+	}
+	return b.out
+}
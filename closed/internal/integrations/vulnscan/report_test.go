@@ -0,0 +1,64 @@
+package vulnscan
+
+import "testing"
+
+const trivyFixture = `{
+  "Trivy": {"Version": "0.50.1"},
+  "Results": [
+    {"Target": "debian", "Vulnerabilities": [
+      {"VulnerabilityID": "CVE-2024-0001", "PkgName": "openssl", "InstalledVersion": "3.0.1", "FixedVersion": "3.0.2", "Severity": "CRITICAL"},
+      {"VulnerabilityID": "CVE-2024-0002", "PkgName": "zlib", "InstalledVersion": "1.2", "Severity": "MEDIUM"}
+    ]},
+    {"Target": "python", "Vulnerabilities": [
+      {"VulnerabilityID": "CVE-2024-0001", "PkgName": "openssl", "InstalledVersion": "3.0.1", "Severity": "CRITICAL"},
+      {"VulnerabilityID": "GHSA-aaaa", "PkgName": "requests", "InstalledVersion": "2.0", "Severity": "HIGH"}
+    ]},
+    {"Target": "clean"}
+  ]
+}`
+
+const grypeFixture = `{
+  "descriptor": {"version": "0.74.0"},
+  "matches": [
+    {"vulnerability": {"id": "CVE-2024-0003", "severity": "High", "fix": {"versions": ["1.1", "2.1"]}}, "artifact": {"name": "libx", "version": "1.0"}},
+    {"vulnerability": {"id": "CVE-2024-0004", "severity": "Negligible"}, "artifact": {"name": "liby", "version": "1.0"}},
+    {"vulnerability": {"id": "CVE-2024-0005", "severity": "Unknown"}, "artifact": {"name": "libz", "version": "1.0"}}
+  ]
+}`
+
+func TestParseTrivy(t *testing.T) {
+	report, err := Parse("Trivy", []byte(trivyFixture))
+	if err != nil {
+		t.Fatalf("parse trivy: %v", err)
+	}
+	want := Counts{Critical: 1, High: 1, Medium: 1}
+	if report.Counts != want || report.Scanner != FormatTrivy || report.ScannerVersion != "0.50.1" {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	if len(report.Findings) != 2 || report.Findings[0].ID != "CVE-2024-0001" || report.Findings[0].FixedVersion != "3.0.2" {
+		t.Fatalf("unexpected findings %+v", report.Findings)
+	}
+}
+
+func TestParseGrype(t *testing.T) {
+	report, err := Parse(FormatGrype, []byte(grypeFixture))
+	if err != nil {
+		t.Fatalf("parse grype: %v", err)
+	}
+	want := Counts{High: 1, Low: 1, Unknown: 1}
+	if report.Counts != want || report.Counts.Total() != 3 {
+		t.Fatalf("unexpected counts %+v", report.Counts)
+	}
+	if len(report.Findings) != 1 || report.Findings[0].FixedVersion != "1.1, 2.1" {
+		t.Fatalf("unexpected findings %+v", report.Findings)
+	}
+}
+
+func TestParseRejectsWrongShape(t *testing.T) {
+	if _, err := Parse(FormatTrivy, []byte(grypeFixture)); err == nil {
+		t.Fatalf("expected error for grype report parsed as trivy")
+	}
+	if _, err := Parse("clair", []byte(trivyFixture)); err == nil {
+		t.Fatalf("expected error for unsupported format")
+	}
+}
@@ -0,0 +1,109 @@
+package vulnscan
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/env"
+)
+
+// Config points at a scanner service that scans an image on request. An
+// empty URL disables pulling; scan results can still be pushed.
+type Config struct {
+	URL     string
+	Format  string
+	Timeout time.Duration
+}
+
+func ConfigFromEnv() (Config, error) {
+	timeout, err := env.Duration("ANIMUS_VULN_SCANNER_TIMEOUT", 2*time.Minute)
+	if err != nil {
+		return Config{}, err
+	}
+	cfg := Config{
+		URL:     strings.TrimRight(strings.TrimSpace(env.String("ANIMUS_VULN_SCANNER_URL", "")), "/"),
+		Format:  strings.ToLower(strings.TrimSpace(env.String("ANIMUS_VULN_SCANNER_FORMAT", FormatTrivy))),
+		Timeout: timeout,
+	}
+	return cfg, cfg.Validate()
+}
+
+func (c Config) Validate() error {
+	if c.URL == "" {
+		return nil
+	}
+	if !strings.HasPrefix(c.URL, "http://") && !strings.HasPrefix(c.URL, "https://") {
+		return fmt.Errorf("ANIMUS_VULN_SCANNER_URL must be an http(s) URL")
+	}
+	if _, err := NormalizeFormat(c.Format); err != nil {
+		return fmt.Errorf("ANIMUS_VULN_SCANNER_FORMAT: %w", err)
+	}
+	if c.Timeout <= 0 {
+		return errors.New("ANIMUS_VULN_SCANNER_TIMEOUT must be positive")
+	}
+	return nil
+}
+
+// Scanner produces a report for a digest-pinned image reference.
+type Scanner interface {
+	Scan(ctx context.Context, imageDigestRef string) (Report, error)
+}
+
+// HTTPScanner posts {"image": ref} to a scanner service, such as a thin
+// wrapper around `trivy image -f json` or `grype -o json`, and parses the
+// report it returns in the configured format.
+type HTTPScanner struct {
+	url    string
+	format string
+	http   *http.Client
+}
+
+// NewHTTPScanner returns nil when cfg has no URL.
+func NewHTTPScanner(cfg Config) *HTTPScanner {
+	url := strings.TrimRight(strings.TrimSpace(cfg.URL), "/")
+	if url == "" {
+		return nil
+	}
+	format, err := NormalizeFormat(cfg.Format)
+	if err != nil {
+		format = FormatTrivy
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 2 * time.Minute
+	}
+	return &HTTPScanner{url: url, format: format, http: &http.Client{Timeout: timeout}}
+}
+
+func (s *HTTPScanner) Scan(ctx context.Context, imageDigestRef string) (Report, error) {
+	payload, err := json.Marshal(map[string]string{"image": strings.TrimSpace(imageDigestRef)})
+	if err != nil {
+		return Report{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(payload))
+	if err != nil {
+		return Report{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return Report{}, fmt.Errorf("scan %s: %w", imageDigestRef, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
+		return Report{}, fmt.Errorf("scan %s failed: status %d: %s", imageDigestRef, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 32<<20))
+	if err != nil {
+		return Report{}, fmt.Errorf("scan %s: read response: %w", imageDigestRef, err)
+	}
+	return Parse(s.format, body)
+}
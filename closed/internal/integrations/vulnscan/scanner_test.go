@@ -0,0 +1,56 @@
+package vulnscan
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHTTPScanner(t *testing.T) {
+	var requested string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		requested = body["image"]
+		_, _ = w.Write([]byte(trivyFixture))
+	}))
+	defer srv.Close()
+
+	scanner := NewHTTPScanner(Config{URL: srv.URL, Format: FormatTrivy, Timeout: time.Second})
+	report, err := scanner.Scan(context.Background(), "ghcr.io/acme/train@sha256:abc")
+	if err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+	if requested != "ghcr.io/acme/train@sha256:abc" || report.Counts.Critical != 1 {
+		t.Fatalf("unexpected scan: requested=%q report=%+v", requested, report)
+	}
+}
+
+func TestHTTPScannerError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "registry unreachable", http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	scanner := NewHTTPScanner(Config{URL: srv.URL, Format: FormatTrivy, Timeout: time.Second})
+	if _, err := scanner.Scan(context.Background(), "ghcr.io/acme/train@sha256:abc"); err == nil {
+		t.Fatalf("expected scanner error")
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	if NewHTTPScanner(Config{}) != nil {
+		t.Fatalf("empty url must disable the scanner")
+	}
+	if err := (Config{URL: "ftp://scanner", Format: FormatTrivy, Timeout: time.Second}).Validate(); err == nil {
+		t.Fatalf("expected error for non-http url")
+	}
+	if err := (Config{URL: "http://scanner", Format: "clair", Timeout: time.Second}).Validate(); err == nil {
+		t.Fatalf("expected error for unsupported format")
+	}
+}
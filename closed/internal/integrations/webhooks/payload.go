@@ -93,6 +93,150 @@ func DatasetVersionCreatedPayload(projectID, datasetVersionID string, emittedAt
 	}, nil
 }
 
+// DataContractBreachedPayload is keyed by the dataset version, so each breaching
+// version notifies a consumer at most once.
+func DataContractBreachedPayload(projectID, datasetVersionID, contractID string, emittedAt time.Time) (Payload, error) {
+	if strings.TrimSpace(datasetVersionID) == "" || strings.TrimSpace(contractID) == "" {
+		return Payload{}, fmt.Errorf("dataset_version_id and contract_id are required")
+	}
+	if emittedAt.IsZero() {
+		emittedAt = time.Now().UTC()
+	}
+	eventID, err := EventID(EventDataContractBreached, projectID, datasetVersionID)
+	if err != nil {
+		return Payload{}, err
+	}
+	return Payload{
+		EventID:   eventID,
+		EventType: EventDataContractBreached,
+		EmittedAt: emittedAt.UTC(),
+		ProjectID: strings.TrimSpace(projectID),
+		Subject: SubjectRef{
+			DatasetVersionID: strings.TrimSpace(datasetVersionID),
+			DataContractID:   strings.TrimSpace(contractID),
+		},
+		Links: map[string]string{
+			"dataset_version":           fmt.Sprintf("/dataset-versions/%s", strings.TrimSpace(datasetVersionID)),
+			"data_contract_evaluations": fmt.Sprintf("/dataset-versions/%s/contract-evaluations", strings.TrimSpace(datasetVersionID)),
+		},
+	}, nil
+}
+
+// DatasetStalePayload is keyed by the freshness breach, so a dataset that goes
+// stale again after recovering notifies subscribers again.
+func DatasetStalePayload(projectID, datasetID, breachID string, emittedAt time.Time) (Payload, error) {
+	if strings.TrimSpace(datasetID) == "" || strings.TrimSpace(breachID) == "" {
+		return Payload{}, fmt.Errorf("dataset_id and breach_id are required")
+	}
+	if emittedAt.IsZero() {
+		emittedAt = time.Now().UTC()
+	}
+	eventID, err := EventID(EventDatasetStale, projectID, breachID)
+	if err != nil {
+		return Payload{}, err
+	}
+	return Payload{
+		EventID:   eventID,
+		EventType: EventDatasetStale,
+		EmittedAt: emittedAt.UTC(),
+		ProjectID: strings.TrimSpace(projectID),
+		Subject: SubjectRef{
+			DatasetID:         strings.TrimSpace(datasetID),
+			FreshnessBreachID: strings.TrimSpace(breachID),
+		},
+		Links: map[string]string{
+			"dataset":           fmt.Sprintf("/datasets/%s", strings.TrimSpace(datasetID)),
+			"dataset_freshness": fmt.Sprintf("/datasets/%s/freshness", strings.TrimSpace(datasetID)),
+		},
+	}, nil
+}
+
+// NotificationDigestPayload is keyed by the digest and the end of the period
+// it covers, so a period is delivered at most once per subscription.
+func NotificationDigestPayload(projectID, digestID string, periodEnd time.Time, digest json.RawMessage, emittedAt time.Time) (Payload, error) {
+	if strings.TrimSpace(digestID) == "" || periodEnd.IsZero() {
+		return Payload{}, fmt.Errorf("digest_id and period_end are required")
+	}
+	if len(digest) == 0 {
+		return Payload{}, fmt.Errorf("digest is required")
+	}
+	if emittedAt.IsZero() {
+		emittedAt = time.Now().UTC()
+	}
+	eventID, err := EventID(EventNotificationDigest, projectID, strings.TrimSpace(digestID)+"@"+periodEnd.UTC().Format(time.RFC3339))
+	if err != nil {
+		return Payload{}, err
+	}
+	return Payload{
+		EventID:   eventID,
+		EventType: EventNotificationDigest,
+		EmittedAt: emittedAt.UTC(),
+		ProjectID: strings.TrimSpace(projectID),
+		Subject:   SubjectRef{DigestID: strings.TrimSpace(digestID)},
+		Links: map[string]string{
+			"digest": fmt.Sprintf("/projects/%s/notification-digests/%s", strings.TrimSpace(projectID), strings.TrimSpace(digestID)),
+		},
+		Digest: digest,
+	}, nil
+}
+
+// PolicyApprovalEscalatedPayload is keyed by the approval and stage, so each
+// overdue stage notifies a subscriber once.
+func PolicyApprovalEscalatedPayload(projectID, approvalID, runID, stage string, emittedAt time.Time) (Payload, error) {
+	if strings.TrimSpace(approvalID) == "" || strings.TrimSpace(stage) == "" {
+		return Payload{}, fmt.Errorf("approval_id and stage are required")
+	}
+	if emittedAt.IsZero() {
+		emittedAt = time.Now().UTC()
+	}
+	eventID, err := EventID(EventPolicyApprovalEscalated, projectID, strings.TrimSpace(approvalID)+"@"+strings.TrimSpace(stage))
+	if err != nil {
+		return Payload{}, err
+	}
+	return Payload{
+		EventID:   eventID,
+		EventType: EventPolicyApprovalEscalated,
+		EmittedAt: emittedAt.UTC(),
+		ProjectID: strings.TrimSpace(projectID),
+		Subject: SubjectRef{
+			RunID:            strings.TrimSpace(runID),
+			PolicyApprovalID: strings.TrimSpace(approvalID),
+			ApprovalStage:    strings.TrimSpace(stage),
+		},
+		Links: map[string]string{
+			"policy_approval": fmt.Sprintf("/policy-approvals/%s", strings.TrimSpace(approvalID)),
+		},
+	}, nil
+}
+
+// AuditAlertRaisedPayload is keyed by the alert, so an alert that keeps
+// absorbing events notifies a subscriber once.
+func AuditAlertRaisedPayload(projectID, alertID, rule string, emittedAt time.Time) (Payload, error) {
+	if strings.TrimSpace(alertID) == "" || strings.TrimSpace(rule) == "" {
+		return Payload{}, fmt.Errorf("alert_id and rule are required")
+	}
+	if emittedAt.IsZero() {
+		emittedAt = time.Now().UTC()
+	}
+	eventID, err := EventID(EventAuditAlertRaised, projectID, strings.TrimSpace(alertID))
+	if err != nil {
+		return Payload{}, err
+	}
+	return Payload{
+		EventID:   eventID,
+		EventType: EventAuditAlertRaised,
+		EmittedAt: emittedAt.UTC(),
+		ProjectID: strings.TrimSpace(projectID),
+		Subject: SubjectRef{
+			AuditAlertID:   strings.TrimSpace(alertID),
+			AuditAlertRule: strings.TrimSpace(rule),
+		},
+		Links: map[string]string{
+			"audit_alert": fmt.Sprintf("/alerts/%s", strings.TrimSpace(alertID)),
+		},
+	}, nil
+}
+
 func PayloadJSON(payload Payload) ([]byte, error) {
 	return json.Marshal(payload)
 }
@@ -1,6 +1,7 @@
 package webhooks
 
 import (
+	"encoding/json"
 	"fmt"
 	"sort"
 	"strings"
@@ -10,9 +11,14 @@ import (
 type EventType string
 
 const (
-	EventRunFinished           EventType = "RunFinished"
-	EventModelApproved         EventType = "ModelApproved"
-	EventDatasetVersionCreated EventType = "DatasetVersionCreated"
+	EventRunFinished             EventType = "RunFinished"
+	EventModelApproved           EventType = "ModelApproved"
+	EventDatasetVersionCreated   EventType = "DatasetVersionCreated"
+	EventDataContractBreached    EventType = "DataContractBreached"
+	EventDatasetStale            EventType = "DatasetStale"
+	EventNotificationDigest      EventType = "NotificationDigest"
+	EventPolicyApprovalEscalated EventType = "PolicyApprovalEscalated"
+	EventAuditAlertRaised        EventType = "AuditAlertRaised"
 )
 
 type DeliveryStatus string
@@ -33,9 +39,17 @@ const (
 )
 
 type SubjectRef struct {
-	RunID            string `json:"run_id,omitempty"`
-	ModelVersionID   string `json:"model_version_id,omitempty"`
-	DatasetVersionID string `json:"dataset_version_id,omitempty"`
+	RunID             string `json:"run_id,omitempty"`
+	ModelVersionID    string `json:"model_version_id,omitempty"`
+	DatasetVersionID  string `json:"dataset_version_id,omitempty"`
+	DataContractID    string `json:"data_contract_id,omitempty"`
+	DatasetID         string `json:"dataset_id,omitempty"`
+	FreshnessBreachID string `json:"freshness_breach_id,omitempty"`
+	DigestID          string `json:"digest_id,omitempty"`
+	PolicyApprovalID  string `json:"policy_approval_id,omitempty"`
+	ApprovalStage     string `json:"approval_stage,omitempty"`
+	AuditAlertID      string `json:"audit_alert_id,omitempty"`
+	AuditAlertRule    string `json:"audit_alert_rule,omitempty"`
 }
 
 type Payload struct {
@@ -45,6 +59,8 @@ type Payload struct {
 	ProjectID string            `json:"project_id"`
 	Subject   SubjectRef        `json:"subject"`
 	Links     map[string]string `json:"api_links,omitempty"`
+	// Digest carries the compiled summary of NotificationDigest events.
+	Digest json.RawMessage `json:"digest,omitempty"`
 }
 
 type Subscription struct {
@@ -100,7 +116,7 @@ func (t EventType) String() string {
 
 func (t EventType) Valid() bool {
 	switch t {
-	case EventRunFinished, EventModelApproved, EventDatasetVersionCreated:
+	case EventRunFinished, EventModelApproved, EventDatasetVersionCreated, EventDataContractBreached, EventDatasetStale, EventNotificationDigest, EventPolicyApprovalEscalated, EventAuditAlertRaised:
 		return true
 	default:
 		return false
@@ -12,9 +12,15 @@ import (
 	"strings"
 	"time"
 
+	"github.com/animus-labs/animus-go/closed/internal/platform/buildinfo"
+	"github.com/animus-labs/animus-go/closed/internal/platform/outbox"
 	"github.com/animus-labs/animus-go/closed/internal/platform/redaction"
 )
 
+// BuildVersionKey is the payload field recording the build that wrote an
+// event, so incident analysis can tie behavior to a deployed version.
+const BuildVersionKey = "build_version"
+
 type Event struct {
 	OccurredAt   time.Time
 	Actor        string
@@ -54,11 +60,61 @@ func Insert(ctx context.Context, q QueryRower, event Event) (int64, error) {
 	if q == nil {
 		return 0, errors.New("queryer is required")
 	}
+	r, err := prepare(event)
+	if err != nil {
+		return 0, err
+	}
+	return insertRow(ctx, q, r)
+}
+
+// Enqueue records event in the outbox instead of audit_events; the outbox
+// relay inserts it later. Pass the transaction of the change being audited
+// so the event commits or rolls back with it.
+func Enqueue(ctx context.Context, q QueryRower, event Event) error {
+	if q == nil {
+		return errors.New("queryer is required")
+	}
+	r, err := prepare(event)
+	if err != nil {
+		return err
+	}
+	if _, err := outbox.Append(ctx, q, outbox.KindAudit, r.IntegritySHA256, r); err != nil {
+		return fmt.Errorf("enqueue audit event: %w", err)
+	}
+	return nil
+}
+
+// DeliverOutbox is the outbox.DeliverFunc for outbox.KindAudit.
+func DeliverOutbox(ctx context.Context, tx *sql.Tx, record json.RawMessage) (int64, error) {
+	var r row
+	if err := json.Unmarshal(record, &r); err != nil {
+		return 0, fmt.Errorf("decode audit outbox record: %w", err)
+	}
+	return insertRow(ctx, tx, r)
+}
+
+// row is an audit event as stored: trimmed fields, the redacted payload and
+// its integrity hash. It is also the outbox record, so a relayed event keeps
+// the hash computed when it was enqueued.
+type row struct {
+	OccurredAt      time.Time       `json:"occurred_at"`
+	Actor           string          `json:"actor"`
+	Action          string          `json:"action"`
+	ResourceType    string          `json:"resource_type"`
+	ResourceID      string          `json:"resource_id"`
+	RequestID       string          `json:"request_id,omitempty"`
+	IP              string          `json:"ip,omitempty"`
+	UserAgent       string          `json:"user_agent,omitempty"`
+	Payload         json.RawMessage `json:"payload"`
+	IntegritySHA256 string          `json:"integrity_sha256"`
+}
+
+func prepare(event Event) (row, error) {
 	if event.OccurredAt.IsZero() {
 		event.OccurredAt = time.Now().UTC()
 	}
 	if err := event.Validate(); err != nil {
-		return 0, err
+		return row{}, err
 	}
 
 	payload := event.Payload
@@ -67,31 +123,35 @@ func Insert(ctx context.Context, q QueryRower, event Event) (int64, error) {
 	}
 	payloadJSON, err := json.Marshal(payload)
 	if err != nil {
-		return 0, fmt.Errorf("marshal payload: %w", err)
+		return row{}, fmt.Errorf("marshal payload: %w", err)
 	}
-	payloadJSON = redaction.RedactJSON(payloadJSON)
+	payloadJSON = redaction.RedactJSON(withBuildVersion(payloadJSON))
 
-	ipStr := strings.TrimSpace(event.IP.String())
 	integrity, err := ComputeIntegritySHA256(event, payloadJSON)
 	if err != nil {
-		return 0, err
-	}
-
-	var requestID sql.NullString
-	if strings.TrimSpace(event.RequestID) != "" {
-		requestID = sql.NullString{String: strings.TrimSpace(event.RequestID), Valid: true}
-	}
-	var ip sql.NullString
-	if ipStr != "" && ipStr != "<nil>" {
-		ip = sql.NullString{String: ipStr, Valid: true}
+		return row{}, err
 	}
-	var userAgent sql.NullString
-	if strings.TrimSpace(event.UserAgent) != "" {
-		userAgent = sql.NullString{String: strings.TrimSpace(event.UserAgent), Valid: true}
+	ipStr := strings.TrimSpace(event.IP.String())
+	if ipStr == "<nil>" {
+		ipStr = ""
 	}
+	return row{
+		OccurredAt:      event.OccurredAt.UTC(),
+		Actor:           strings.TrimSpace(event.Actor),
+		Action:          strings.TrimSpace(event.Action),
+		ResourceType:    strings.TrimSpace(event.ResourceType),
+		ResourceID:      strings.TrimSpace(event.ResourceID),
+		RequestID:       strings.TrimSpace(event.RequestID),
+		IP:              ipStr,
+		UserAgent:       strings.TrimSpace(event.UserAgent),
+		Payload:         payloadJSON,
+		IntegritySHA256: integrity,
+	}, nil
+}
 
+func insertRow(ctx context.Context, q QueryRower, r row) (int64, error) {
 	var id int64
-	err = q.QueryRowContext(
+	err := q.QueryRowContext(
 		ctx,
 		`INSERT INTO audit_events (
 			occurred_at,
@@ -106,16 +166,16 @@ func Insert(ctx context.Context, q QueryRower, event Event) (int64, error) {
 			integrity_sha256
 		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)
 		RETURNING event_id`,
-		event.OccurredAt.UTC(),
-		strings.TrimSpace(event.Actor),
-		strings.TrimSpace(event.Action),
-		strings.TrimSpace(event.ResourceType),
-		strings.TrimSpace(event.ResourceID),
-		requestID,
-		ip,
-		userAgent,
-		payloadJSON,
-		integrity,
+		r.OccurredAt,
+		r.Actor,
+		r.Action,
+		r.ResourceType,
+		r.ResourceID,
+		nullString(r.RequestID),
+		nullString(r.IP),
+		nullString(r.UserAgent),
+		[]byte(r.Payload),
+		r.IntegritySHA256,
 	).Scan(&id)
 	if err != nil {
 		return 0, fmt.Errorf("insert audit event: %w", err)
@@ -123,6 +183,35 @@ func Insert(ctx context.Context, q QueryRower, event Event) (int64, error) {
 	return id, nil
 }
 
+func nullString(v string) sql.NullString {
+	if v == "" {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: v, Valid: true}
+}
+
+// withBuildVersion adds BuildVersionKey to object payloads that do not carry
+// it yet; other payloads are stored as given.
+func withBuildVersion(payloadJSON []byte) []byte {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(payloadJSON, &fields); err != nil || fields == nil {
+		return payloadJSON
+	}
+	if _, ok := fields[BuildVersionKey]; ok {
+		return payloadJSON
+	}
+	version, err := json.Marshal(buildinfo.Get().Version)
+	if err != nil {
+		return payloadJSON
+	}
+	fields[BuildVersionKey] = version
+	out, err := json.Marshal(fields)
+	if err != nil {
+		return payloadJSON
+	}
+	return out
+}
+
 func ComputeIntegritySHA256(event Event, payloadJSON []byte) (string, error) {
 	type integrityInput struct {
 		OccurredAt   time.Time       `json:"occurred_at"`
@@ -1,9 +1,12 @@
 package auditlog
 
 import (
+	"encoding/json"
 	"net"
 	"testing"
 	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/buildinfo"
 )
 
 func TestComputeIntegritySHA256_Deterministic(t *testing.T) {
@@ -55,3 +58,44 @@ func TestComputeIntegritySHA256_ChangesOnPayload(t *testing.T) {
 		t.Fatalf("expected integrity to differ")
 	}
 }
+
+func TestWithBuildVersion(t *testing.T) {
+	got := string(withBuildVersion([]byte(`{"a":1}`)))
+	if got != `{"a":1,"build_version":"`+buildinfo.Get().Version+`"}` {
+		t.Fatalf("unexpected payload %s", got)
+	}
+	for _, payload := range []string{`{"build_version":"v0.9.0"}`, `[1,2]`, `null`} {
+		if got := string(withBuildVersion([]byte(payload))); got != payload {
+			t.Fatalf("%s rewritten to %s", payload, got)
+		}
+	}
+}
+
+func TestOutboxRecordRoundTrip(t *testing.T) {
+	r, err := prepare(Event{
+		OccurredAt:   time.Unix(1700000000, 123456789).UTC(),
+		Actor:        "alice",
+		Action:       "dataset_version.download",
+		ResourceType: "dataset_version",
+		ResourceID:   "dv-1",
+		IP:           net.ParseIP("192.0.2.1"),
+		Payload:      map[string]any{"token": "secret", "a": 1},
+	})
+	if err != nil {
+		t.Fatalf("prepare() err=%v", err)
+	}
+	blob, err := json.Marshal(r)
+	if err != nil {
+		t.Fatalf("marshal err=%v", err)
+	}
+	var decoded row
+	if err := json.Unmarshal(blob, &decoded); err != nil {
+		t.Fatalf("unmarshal err=%v", err)
+	}
+	if !decoded.OccurredAt.Equal(r.OccurredAt) || decoded.IP != "192.0.2.1" || decoded.IntegritySHA256 != r.IntegritySHA256 {
+		t.Fatalf("round trip mismatch: %+v vs %+v", decoded, r)
+	}
+	if string(decoded.Payload) != string(r.Payload) {
+		t.Fatalf("payload changed across the outbox: %s vs %s", decoded.Payload, r.Payload)
+	}
+}
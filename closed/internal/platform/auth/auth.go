@@ -3,6 +3,7 @@ package auth
 import (
 	"errors"
 	"fmt"
+	"net/url"
 	"strings"
 	"time"
 
@@ -12,12 +13,28 @@ import (
 type Mode string
 
 const (
-	ModeOIDC     Mode = "oidc"
-	ModeSAML     Mode = "saml"
+	ModeOIDC Mode = "oidc"
+	ModeSAML Mode = "saml"
+	// ModeLDAP and ModeStatic accept username/password at POST /auth/login,
+	// verified by an LDAP bind or a bcrypt-hashed user file, for deployments
+	// without an OIDC provider.
+	ModeLDAP     Mode = "ldap"
+	ModeStatic   Mode = "static"
 	ModeDev      Mode = "dev"
 	ModeDisabled Mode = "disabled"
 )
 
+// SessionMode selects how browser clients hold their login. In bearer mode
+// the session cookie is one credential among Authorization: Bearer tokens;
+// cookie mode requires the server-side session store and CSRF tokens on
+// mutating calls so browser code never handles a bearer token.
+type SessionMode string
+
+const (
+	SessionModeBearer SessionMode = "bearer"
+	SessionModeCookie SessionMode = "cookie"
+)
+
 var ErrUnauthenticated = errors.New("unauthenticated")
 
 type Config struct {
@@ -27,13 +44,17 @@ type Config struct {
 	GroupsClaim string
 	EmailClaim  string
 
+	SessionMode           SessionMode
 	SessionCookieName     string
 	SessionCookieSecure   bool
 	SessionCookieMaxAge   time.Duration
 	SessionCookieSameSite string
 	SessionMaxConcurrent  int
+	SessionRefresh        bool
+	SessionRefreshWindow  time.Duration
 	RBACAllowDirectRoles  bool
 	GroupRoleMap          map[string]string
+	ClaimRoleRules        []ClaimRoleRule
 
 	OIDCIssuerURL    string
 	OIDCClientID     string
@@ -44,6 +65,21 @@ type Config struct {
 	PublicBaseURL          string
 	AllowedReturnToOrigins []string
 
+	StaticUsersFile string
+
+	LDAPURL            string
+	LDAPBindDNTemplate string
+	LDAPCAFile         string
+	LDAPAllowPlaintext bool
+	LDAPGroupAttribute string
+	LDAPEmailAttribute string
+	LDAPDefaultRoles   []string
+	LDAPTimeout        time.Duration
+
+	LoginMaxFailures      int
+	LoginMaxFailuresPerIP int
+	LoginLockout          time.Duration
+
 	DevSubject string
 	DevEmail   string
 	DevRoles   []string
@@ -57,12 +93,16 @@ func ConfigFromEnv() (Config, error) {
 		mode = ModeOIDC
 	case string(ModeSAML):
 		mode = ModeSAML
+	case string(ModeLDAP):
+		mode = ModeLDAP
+	case string(ModeStatic):
+		mode = ModeStatic
 	case string(ModeDev):
 		mode = ModeDev
 	case string(ModeDisabled):
 		mode = ModeDisabled
 	default:
-		return Config{}, fmt.Errorf("AUTH_MODE must be one of: oidc, dev, disabled (got %q)", modeRaw)
+		return Config{}, fmt.Errorf("AUTH_MODE must be one of: oidc, saml, ldap, static, dev, disabled (got %q)", modeRaw)
 	}
 
 	sessionCookieSecure, err := env.Bool("AUTH_SESSION_COOKIE_SECURE", true)
@@ -77,17 +117,52 @@ func ConfigFromEnv() (Config, error) {
 	if err != nil {
 		return Config{}, err
 	}
+	claimRoleRules, err := loadClaimRoleRules(env.String("AUTH_CLAIM_ROLE_MAP", ""), env.String("AUTH_CLAIM_ROLE_MAP_FILE", ""))
+	if err != nil {
+		return Config{}, err
+	}
+	sessionRefresh, err := env.Bool("AUTH_SESSION_REFRESH", false)
+	if err != nil {
+		return Config{}, err
+	}
+	refreshWindowSeconds, err := env.Int("AUTH_SESSION_REFRESH_WINDOW_SECONDS", 300)
+	if err != nil {
+		return Config{}, err
+	}
 	rbacAllowDirect, err := env.Bool("AUTH_RBAC_ALLOW_DIRECT_ROLES", true)
 	if err != nil {
 		return Config{}, err
 	}
+	ldapAllowPlaintext, err := env.Bool("AUTH_LDAP_ALLOW_PLAINTEXT", false)
+	if err != nil {
+		return Config{}, err
+	}
+	ldapTimeout, err := env.Duration("AUTH_LDAP_TIMEOUT", 5*time.Second)
+	if err != nil {
+		return Config{}, err
+	}
+	loginMaxFailures, err := env.Int("AUTH_LOGIN_MAX_FAILURES", 5)
+	if err != nil {
+		return Config{}, err
+	}
+	loginMaxFailuresPerIP, err := env.Int("AUTH_LOGIN_MAX_FAILURES_PER_IP", 20)
+	if err != nil {
+		return Config{}, err
+	}
+	loginLockout, err := env.Duration("AUTH_LOGIN_LOCKOUT", 15*time.Minute)
+	if err != nil {
+		return Config{}, err
+	}
 	groupRoleMap, err := parseGroupRoleMap(env.String("AUTH_GROUP_ROLE_MAP", ""))
 	if err != nil {
 		return Config{}, err
 	}
 
+	sessionMode := SessionMode(strings.ToLower(strings.TrimSpace(env.String("AUTH_SESSION_MODE", string(SessionModeBearer)))))
+
 	cfg := Config{
 		Mode:                   mode,
+		SessionMode:            sessionMode,
 		RolesClaim:             env.String("AUTH_ROLES_CLAIM", "roles"),
 		GroupsClaim:            env.String("AUTH_GROUPS_CLAIM", "groups"),
 		EmailClaim:             env.String("AUTH_EMAIL_CLAIM", "email"),
@@ -96,8 +171,11 @@ func ConfigFromEnv() (Config, error) {
 		SessionCookieMaxAge:    time.Duration(maxAgeSeconds) * time.Second,
 		SessionCookieSameSite:  env.String("AUTH_SESSION_COOKIE_SAMESITE", "Lax"),
 		SessionMaxConcurrent:   sessionMaxConcurrent,
+		SessionRefresh:         sessionRefresh,
+		SessionRefreshWindow:   time.Duration(refreshWindowSeconds) * time.Second,
 		RBACAllowDirectRoles:   rbacAllowDirect,
 		GroupRoleMap:           groupRoleMap,
+		ClaimRoleRules:         claimRoleRules,
 		OIDCIssuerURL:          env.String("OIDC_ISSUER_URL", ""),
 		OIDCClientID:           env.String("OIDC_CLIENT_ID", ""),
 		OIDCClientSecret:       env.String("OIDC_CLIENT_SECRET", ""),
@@ -105,6 +183,18 @@ func ConfigFromEnv() (Config, error) {
 		OIDCScopes:             parseScopes(env.String("OIDC_SCOPES", "openid profile email")),
 		PublicBaseURL:          strings.TrimSpace(env.String("ANIMUS_PUBLIC_BASE_URL", "")),
 		AllowedReturnToOrigins: parseCSV(env.String("ANIMUS_ALLOWED_RETURN_TO_ORIGINS", "")),
+		StaticUsersFile:        strings.TrimSpace(env.String("AUTH_STATIC_USERS_FILE", "")),
+		LDAPURL:                strings.TrimSpace(env.String("AUTH_LDAP_URL", "")),
+		LDAPBindDNTemplate:     strings.TrimSpace(env.String("AUTH_LDAP_BIND_DN_TEMPLATE", "")),
+		LDAPCAFile:             strings.TrimSpace(env.String("AUTH_LDAP_CA_FILE", "")),
+		LDAPAllowPlaintext:     ldapAllowPlaintext,
+		LDAPGroupAttribute:     strings.TrimSpace(env.String("AUTH_LDAP_GROUP_ATTRIBUTE", "memberOf")),
+		LDAPEmailAttribute:     strings.TrimSpace(env.String("AUTH_LDAP_EMAIL_ATTRIBUTE", "mail")),
+		LDAPDefaultRoles:       parseCSV(env.String("AUTH_LDAP_DEFAULT_ROLES", RoleViewer)),
+		LDAPTimeout:            ldapTimeout,
+		LoginMaxFailures:       loginMaxFailures,
+		LoginMaxFailuresPerIP:  loginMaxFailuresPerIP,
+		LoginLockout:           loginLockout,
 		DevSubject:             env.String("DEV_AUTH_SUBJECT", "dev-user"),
 		DevEmail:               env.String("DEV_AUTH_EMAIL", "dev-user@example.local"),
 		DevRoles:               parseCSV(env.String("DEV_AUTH_ROLES", "admin")),
@@ -115,6 +205,20 @@ func ConfigFromEnv() (Config, error) {
 	return cfg, nil
 }
 
+// CookieSessions reports whether browsers authenticate with the session
+// cookie, so that mutating calls need CSRF tokens. SAML and the password
+// modes always hold the login in the cookie; OIDC does in cookie mode.
+func (c Config) CookieSessions() bool {
+	switch c.Mode {
+	case ModeSAML, ModeLDAP, ModeStatic:
+		return true
+	case ModeOIDC:
+		return c.SessionMode == SessionModeCookie
+	default:
+		return false
+	}
+}
+
 func (c Config) Validate() error {
 	if strings.TrimSpace(string(c.Mode)) == "" {
 		return errors.New("AUTH_MODE is required")
@@ -140,6 +244,30 @@ func (c Config) Validate() error {
 	if strings.TrimSpace(c.SessionCookieSameSite) == "" {
 		return errors.New("AUTH_SESSION_COOKIE_SAMESITE is required")
 	}
+	if c.SessionRefresh {
+		if c.Mode != ModeOIDC {
+			return fmt.Errorf("AUTH_SESSION_REFRESH requires AUTH_MODE=oidc (got %q)", c.Mode)
+		}
+		if c.SessionRefreshWindow <= 0 {
+			return errors.New("AUTH_SESSION_REFRESH_WINDOW_SECONDS must be positive")
+		}
+	}
+	switch c.SessionMode {
+	case "", SessionModeBearer:
+	case SessionModeCookie:
+		if c.Mode == ModeDev || c.Mode == ModeDisabled {
+			return fmt.Errorf("AUTH_SESSION_MODE=cookie requires AUTH_MODE=oidc, saml, ldap or static (got %q)", c.Mode)
+		}
+	default:
+		return fmt.Errorf("AUTH_SESSION_MODE must be one of: bearer, cookie (got %q)", c.SessionMode)
+	}
+
+	if c.LoginMaxFailures < 0 || c.LoginMaxFailuresPerIP < 0 {
+		return errors.New("AUTH_LOGIN_MAX_FAILURES and AUTH_LOGIN_MAX_FAILURES_PER_IP must be >= 0")
+	}
+	if (c.LoginMaxFailures > 0 || c.LoginMaxFailuresPerIP > 0) && c.LoginLockout <= 0 {
+		return errors.New("AUTH_LOGIN_LOCKOUT must be positive")
+	}
 
 	switch c.Mode {
 	case ModeOIDC:
@@ -151,6 +279,24 @@ func (c Config) Validate() error {
 		}
 	case ModeSAML:
 		// SAML is optional; handlers may be stubbed until configured.
+	case ModeStatic:
+		if c.StaticUsersFile == "" {
+			return errors.New("AUTH_STATIC_USERS_FILE is required when AUTH_MODE=static")
+		}
+	case ModeLDAP:
+		u, err := url.Parse(c.LDAPURL)
+		if err != nil || u.Host == "" || (u.Scheme != "ldaps" && u.Scheme != "ldap") {
+			return fmt.Errorf("AUTH_LDAP_URL must be an ldaps:// or ldap:// URL (got %q)", c.LDAPURL)
+		}
+		if u.Scheme == "ldap" && !c.LDAPAllowPlaintext {
+			return errors.New("AUTH_LDAP_URL uses plaintext ldap://; use ldaps:// or set AUTH_LDAP_ALLOW_PLAINTEXT=true")
+		}
+		if !strings.Contains(c.LDAPBindDNTemplate, "{username}") {
+			return errors.New("AUTH_LDAP_BIND_DN_TEMPLATE must contain {username}")
+		}
+		if c.LDAPTimeout <= 0 {
+			return errors.New("AUTH_LDAP_TIMEOUT must be positive")
+		}
 	case ModeDev:
 		if strings.TrimSpace(c.DevSubject) == "" {
 			return errors.New("DEV_AUTH_SUBJECT is required when AUTH_MODE=dev")
@@ -209,7 +355,7 @@ func parseGroupRoleMap(raw string) (map[string]string, error) {
 			return nil, fmt.Errorf("invalid group-role mapping: %q", entry)
 		}
 		switch role {
-		case RoleViewer, RoleEditor, RoleAdmin:
+		case RoleViewer, RoleEditor, RoleAdmin, RoleAuditor:
 			// ok
 		default:
 			return nil, fmt.Errorf("invalid group-role mapping role: %q", role)
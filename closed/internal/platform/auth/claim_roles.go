@@ -0,0 +1,134 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ClaimRoleRule grants Role when the claim at Claim (a dotted path into
+// nested claims, e.g. resource_access.animus.roles) holds a value matching
+// Value. Value is a path.Match pattern, so Keycloak group paths can be
+// matched as "/ml/*". A non-empty Issuer limits the rule to tokens from that
+// IdP, letting one mapping serve several providers.
+type ClaimRoleRule struct {
+	Issuer string `yaml:"issuer"`
+	Claim  string `yaml:"claim"`
+	Value  string `yaml:"value"`
+	Role   string `yaml:"role"`
+}
+
+type claimRoleMap struct {
+	Rules []ClaimRoleRule `yaml:"rules"`
+}
+
+// loadClaimRoleRules reads AUTH_CLAIM_ROLE_MAP (inline) or the file named by
+// AUTH_CLAIM_ROLE_MAP_FILE. Both accept YAML or JSON of the form
+// {"rules": [{"issuer", "claim", "value", "role"}]}.
+func loadClaimRoleRules(inline, file string) ([]ClaimRoleRule, error) {
+	inline = strings.TrimSpace(inline)
+	file = strings.TrimSpace(file)
+	if inline != "" && file != "" {
+		return nil, errors.New("AUTH_CLAIM_ROLE_MAP and AUTH_CLAIM_ROLE_MAP_FILE are mutually exclusive")
+	}
+	raw := []byte(inline)
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("AUTH_CLAIM_ROLE_MAP_FILE: %w", err)
+		}
+		raw = data
+	}
+	if len(strings.TrimSpace(string(raw))) == 0 {
+		return nil, nil
+	}
+	return parseClaimRoleRules(raw)
+}
+
+func parseClaimRoleRules(raw []byte) ([]ClaimRoleRule, error) {
+	var parsed claimRoleMap
+	if err := yaml.Unmarshal(raw, &parsed); err != nil {
+		return nil, fmt.Errorf("invalid claim-role mapping: %w", err)
+	}
+	out := make([]ClaimRoleRule, 0, len(parsed.Rules))
+	for i, rule := range parsed.Rules {
+		rule.Issuer = strings.TrimSpace(rule.Issuer)
+		rule.Claim = strings.TrimSpace(rule.Claim)
+		rule.Value = strings.ToLower(strings.TrimSpace(rule.Value))
+		rule.Role = strings.ToLower(strings.TrimSpace(rule.Role))
+		if rule.Claim == "" || rule.Value == "" {
+			return nil, fmt.Errorf("invalid claim-role mapping rule %d: claim and value are required", i)
+		}
+		if _, err := path.Match(rule.Value, ""); err != nil {
+			return nil, fmt.Errorf("invalid claim-role mapping rule %d: value: %w", i, err)
+		}
+		switch rule.Role {
+		case RoleViewer, RoleEditor, RoleAdmin, RoleAuditor:
+		default:
+			return nil, fmt.Errorf("invalid claim-role mapping rule %d role: %q", i, rule.Role)
+		}
+		out = append(out, rule)
+	}
+	return out, nil
+}
+
+func mapClaimsToRoles(claims map[string]any, rules []ClaimRoleRule) []string {
+	if len(rules) == 0 {
+		return nil
+	}
+	issuer := extractStringClaim(claims, "iss")
+	out := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		if rule.Issuer != "" && strings.TrimRight(rule.Issuer, "/") != strings.TrimRight(issuer, "/") {
+			continue
+		}
+		for _, value := range claimValues(lookupClaim(claims, rule.Claim)) {
+			if ok, _ := path.Match(rule.Value, value); ok {
+				out = append(out, rule.Role)
+				break
+			}
+		}
+	}
+	return mergeUnique(out)
+}
+
+// lookupClaim resolves a claim name. Names that exist verbatim win, so
+// namespaced claims such as "https://example.com/roles" keep working;
+// otherwise the name is walked as a dotted path through nested objects.
+func lookupClaim(claims map[string]any, name string) any {
+	if v, ok := claims[name]; ok {
+		return v
+	}
+	if !strings.Contains(name, ".") {
+		return nil
+	}
+	var current any = claims
+	for _, part := range strings.Split(name, ".") {
+		obj, ok := current.(map[string]any)
+		if !ok {
+			return nil
+		}
+		if current, ok = obj[part]; !ok {
+			return nil
+		}
+	}
+	return current
+}
+
+// claimValues flattens a claim into lower-cased strings: string lists,
+// comma-separated strings, booleans and numbers.
+func claimValues(value any) []string {
+	switch typed := value.(type) {
+	case bool:
+		return []string{strconv.FormatBool(typed)}
+	case float64:
+		return []string{strconv.FormatFloat(typed, 'f', -1, 64)}
+	default:
+		return extractRolesValue(value)
+	}
+}
@@ -2,6 +2,7 @@ package auth
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -56,3 +57,40 @@ func TestParseGroupRoleMap(t *testing.T) {
 		t.Fatalf("expected error for invalid role")
 	}
 }
+
+func TestLoadClaimRoleRules(t *testing.T) {
+	rules, err := loadClaimRoleRules(`{"rules":[{"claim":"groups","value":"/ML/*","role":"Admin"}]}`, "")
+	if err != nil {
+		t.Fatalf("inline json: %v", err)
+	}
+	if len(rules) != 1 || rules[0].Value != "/ml/*" || rules[0].Role != RoleAdmin {
+		t.Fatalf("rules=%+v", rules)
+	}
+
+	file := filepath.Join(t.TempDir(), "roles.yaml")
+	content := "rules:\n  - issuer: https://keycloak.test/realms/ml\n    claim: realm_access.roles\n    value: ml-editor\n    role: editor\n"
+	if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	rules, err = loadClaimRoleRules("", file)
+	if err != nil {
+		t.Fatalf("yaml file: %v", err)
+	}
+	if len(rules) != 1 || rules[0].Claim != "realm_access.roles" || rules[0].Issuer == "" {
+		t.Fatalf("rules=%+v", rules)
+	}
+
+	for _, raw := range []string{
+		`{"rules":[{"claim":"groups","value":"x","role":"owner"}]}`,
+		`{"rules":[{"claim":"","value":"x","role":"admin"}]}`,
+		`{"rules":[{"claim":"groups","value":"[","role":"admin"}]}`,
+		`rules: {`,
+	} {
+		if _, err := loadClaimRoleRules(raw, ""); err == nil {
+			t.Errorf("%s: expected error", raw)
+		}
+	}
+	if _, err := loadClaimRoleRules("rules: []", file); err == nil {
+		t.Error("inline and file accepted together")
+	}
+}
@@ -0,0 +1,130 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+	"strings"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+)
+
+const (
+	// CSRFHeader carries the CSRF token on mutating calls made with the
+	// session cookie.
+	CSRFHeader = "X-CSRF-Token"
+	// CSRFCookieName is the readable cookie the token is double-submitted
+	// from.
+	CSRFCookieName = "animus_csrf"
+)
+
+// CSRFToken derives the CSRF token of a session. Being a hash of the session
+// ID it is bound to the session without server-side state and does not
+// reveal the HttpOnly session cookie to scripts that can read it.
+func CSRFToken(sessionID string) string {
+	sum := sha256.Sum256([]byte("animus-csrf:" + sessionID))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// CSRFHandler serves GET /auth/csrf for the OIDC login.
+func (s *OIDCService) CSRFHandler() http.HandlerFunc {
+	return CSRFHandler(s.cfg, s.sessions)
+}
+
+// RequireCSRF wraps next with the CSRF check of the OIDC login; see
+// RequireCSRF.
+func (s *OIDCService) RequireCSRF(next http.Handler) http.Handler {
+	return RequireCSRF(s.cfg, next)
+}
+
+// CSRFHandler serves GET /auth/csrf: it returns the CSRF token of the
+// current session and sets it as the CSRFCookieName cookie.
+func CSRFHandler(cfg Config, sessions *SessionManager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			httpapi.WriteError(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
+			return
+		}
+		sessionID := tokenFromCookie(r, cfg.SessionCookieName)
+		if sessionID == "" || sessions == nil {
+			httpapi.WriteError(w, r, http.StatusUnauthorized, "unauthenticated")
+			return
+		}
+		if _, err := sessions.GetSession(r.Context(), sessionID); err != nil {
+			httpapi.WriteError(w, r, http.StatusUnauthorized, "unauthenticated")
+			return
+		}
+		token := CSRFToken(sessionID)
+		setCSRFCookie(w, token, cfg)
+		w.Header().Set("Cache-Control", "no-store")
+		httpapi.WriteJSON(w, http.StatusOK, map[string]any{
+			"csrf_token": token,
+			"header":     CSRFHeader,
+		})
+	}
+}
+
+// RequireCSRF enforces double-submit CSRF tokens whenever browsers hold the
+// login in the session cookie (Config.CookieSessions): a mutating /api or
+// /auth call that authenticates with the session cookie must send
+// CSRFHeader equal to both the CSRFCookieName cookie and the session's
+// token. Bearer-authenticated calls are not affected, and without cookie
+// sessions next is returned unchanged.
+func RequireCSRF(cfg Config, next http.Handler) http.Handler {
+	if !cfg.CookieSessions() {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !csrfProtected(r) || tokenFromHeader(r) != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		sessionID := tokenFromCookie(r, cfg.SessionCookieName)
+		if sessionID == "" {
+			// Not cookie-authenticated; the auth middleware answers.
+			next.ServeHTTP(w, r)
+			return
+		}
+		header := strings.TrimSpace(r.Header.Get(CSRFHeader))
+		cookie := tokenFromCookie(r, CSRFCookieName)
+		if header == "" || cookie == "" {
+			httpapi.WriteError(w, r, http.StatusForbidden, "csrf_token_missing")
+			return
+		}
+		want := CSRFToken(sessionID)
+		if subtle.ConstantTimeCompare([]byte(header), []byte(cookie)) != 1 ||
+			subtle.ConstantTimeCompare([]byte(header), []byte(want)) != 1 {
+			httpapi.WriteError(w, r, http.StatusForbidden, "csrf_token_invalid")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func csrfProtected(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return false
+	}
+	if r.URL.Path == "/auth/login" {
+		// Signing in opens a session rather than acting on one; a stale
+		// session cookie must not block it.
+		return false
+	}
+	return strings.HasPrefix(r.URL.Path, "/api/") || strings.HasPrefix(r.URL.Path, "/auth/")
+}
+
+// setCSRFCookie sets the token cookie. Unlike the session cookie it is not
+// HttpOnly: the browser client reads it to echo it in CSRFHeader.
+func setCSRFCookie(w http.ResponseWriter, token string, cfg Config) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     CSRFCookieName,
+		Value:    token,
+		Path:     "/",
+		MaxAge:   int(cfg.SessionCookieMaxAge.Seconds()),
+		Secure:   cfg.SessionCookieSecure,
+		SameSite: parseSameSite(cfg.SessionCookieSameSite),
+	})
+}
@@ -0,0 +1,170 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+)
+
+func csrfTestService(mode SessionMode) *OIDCService {
+	return &OIDCService{
+		cfg: Config{
+			Mode:                  ModeOIDC,
+			SessionMode:           mode,
+			SessionCookieName:     "animus_session",
+			SessionCookieMaxAge:   time.Hour,
+			SessionCookieSameSite: "Lax",
+		},
+		sessions: &SessionManager{Store: &stubSessionStore{record: repo.SessionRecord{
+			SessionID: "sess-1",
+			Subject:   "user-1",
+			ExpiresAt: time.Now().Add(time.Hour),
+		}}},
+	}
+}
+
+func TestRequireCSRF(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) })
+	token := CSRFToken("sess-1")
+	cases := []struct {
+		name    string
+		method  string
+		path    string
+		bearer  bool
+		session bool
+		header  string
+		cookie  string
+		want    int
+		code    string
+	}{
+		{name: "read", method: http.MethodGet, path: "/api/experiments/experiments", session: true, want: http.StatusNoContent},
+		{name: "missing", method: http.MethodPost, path: "/api/experiments/experiments", session: true, want: http.StatusForbidden, code: "csrf_token_missing"},
+		{name: "header without cookie", method: http.MethodPost, path: "/api/experiments/experiments", session: true, header: token, want: http.StatusForbidden, code: "csrf_token_missing"},
+		{name: "mismatch", method: http.MethodDelete, path: "/api/experiments/experiments/e1", session: true, header: token, cookie: "other", want: http.StatusForbidden, code: "csrf_token_invalid"},
+		{name: "other session", method: http.MethodPost, path: "/auth/logout", session: true, header: CSRFToken("sess-2"), cookie: CSRFToken("sess-2"), want: http.StatusForbidden, code: "csrf_token_invalid"},
+		{name: "valid", method: http.MethodPost, path: "/api/experiments/experiments", session: true, header: token, cookie: token, want: http.StatusNoContent},
+		{name: "bearer", method: http.MethodPost, path: "/api/experiments/experiments", session: true, bearer: true, want: http.StatusNoContent},
+		{name: "no session", method: http.MethodPost, path: "/api/experiments/experiments", want: http.StatusNoContent},
+		{name: "outside api", method: http.MethodPost, path: "/share/dataset-shares/t1", session: true, want: http.StatusNoContent},
+		{name: "login", method: http.MethodPost, path: "/auth/login", session: true, want: http.StatusNoContent},
+	}
+	h := csrfTestService(SessionModeCookie).RequireCSRF(ok)
+	for _, tc := range cases {
+		req := httptest.NewRequest(tc.method, tc.path, nil)
+		if tc.session {
+			req.AddCookie(&http.Cookie{Name: "animus_session", Value: "sess-1"})
+		}
+		if tc.bearer {
+			req.Header.Set("Authorization", "Bearer token")
+		}
+		if tc.header != "" {
+			req.Header.Set(CSRFHeader, tc.header)
+		}
+		if tc.cookie != "" {
+			req.AddCookie(&http.Cookie{Name: CSRFCookieName, Value: tc.cookie})
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != tc.want || (tc.code != "" && !strings.Contains(rec.Body.String(), tc.code)) {
+			t.Errorf("%s: status=%d body=%s", tc.name, rec.Code, rec.Body)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/experiments/experiments", nil)
+	req.AddCookie(&http.Cookie{Name: "animus_session", Value: "sess-1"})
+	csrfTestService(SessionModeBearer).RequireCSRF(ok).ServeHTTP(rec, req)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("bearer mode status=%d", rec.Code)
+	}
+}
+
+func TestRequireCSRFPasswordAndSAML(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) })
+	for _, mode := range []Mode{ModeSAML, ModeLDAP, ModeStatic} {
+		cfg := Config{Mode: mode, SessionMode: SessionModeBearer, SessionCookieName: "animus_session"}
+		if !cfg.CookieSessions() {
+			t.Fatalf("%s: cookie sessions not reported", mode)
+		}
+		req := httptest.NewRequest(http.MethodPost, "/api/experiments/experiments", nil)
+		req.AddCookie(&http.Cookie{Name: "animus_session", Value: "sess-1"})
+		rec := httptest.NewRecorder()
+		RequireCSRF(cfg, ok).ServeHTTP(rec, req)
+		if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), "csrf_token_missing") {
+			t.Fatalf("%s: status=%d body=%s", mode, rec.Code, rec.Body)
+		}
+	}
+	for _, cfg := range []Config{{Mode: ModeOIDC}, {Mode: ModeDev}, {Mode: ModeDisabled}} {
+		if cfg.CookieSessions() {
+			t.Fatalf("%s: unexpected cookie sessions", cfg.Mode)
+		}
+	}
+}
+
+func TestCSRFHandler(t *testing.T) {
+	svc := csrfTestService(SessionModeCookie)
+
+	rec := httptest.NewRecorder()
+	svc.CSRFHandler()(rec, httptest.NewRequest(http.MethodGet, "/auth/csrf", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("without session status=%d", rec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/auth/csrf", nil)
+	req.AddCookie(&http.Cookie{Name: "animus_session", Value: "sess-1"})
+	rec = httptest.NewRecorder()
+	svc.CSRFHandler()(rec, req)
+	var body struct {
+		CSRFToken string `json:"csrf_token"`
+		Header    string `json:"header"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if rec.Code != http.StatusOK || body.CSRFToken != CSRFToken("sess-1") || body.Header != CSRFHeader {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body)
+	}
+	var cookie *http.Cookie
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == CSRFCookieName {
+			cookie = c
+		}
+	}
+	if cookie == nil || cookie.Value != body.CSRFToken || cookie.HttpOnly {
+		t.Fatalf("csrf cookie=%+v", cookie)
+	}
+}
+
+func TestConfigSessionMode(t *testing.T) {
+	cfg := Config{
+		Mode:                  ModeDev,
+		RolesClaim:            "roles",
+		EmailClaim:            "email",
+		SessionCookieName:     "animus_session",
+		SessionCookieMaxAge:   time.Hour,
+		SessionCookieSameSite: "Lax",
+		DevSubject:            "dev",
+		DevRoles:              []string{"admin"},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("default session mode: %v", err)
+	}
+	cfg.SessionMode = SessionModeCookie
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("cookie mode accepted with AUTH_MODE=dev")
+	}
+	cfg.Mode = ModeStatic
+	cfg.StaticUsersFile = "/etc/animus/users.yaml"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("cookie mode with AUTH_MODE=static: %v", err)
+	}
+	cfg.SessionMode = "jwt"
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("unknown session mode accepted")
+	}
+}
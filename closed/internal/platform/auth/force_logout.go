@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"net/http"
 	"strings"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
 )
 
 type forceLogoutRequest struct {
@@ -15,7 +17,7 @@ type forceLogoutRequest struct {
 func ForceLogoutHandler(manager *SessionManager) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if manager == nil {
-			writeJSON(w, http.StatusServiceUnavailable, map[string]any{"error": "session_unavailable"})
+			httpapi.WriteError(w, r, http.StatusServiceUnavailable, "session_unavailable")
 			return
 		}
 		actor := ""
@@ -27,7 +29,7 @@ func ForceLogoutHandler(manager *SessionManager) http.HandlerFunc {
 		dec := json.NewDecoder(r.Body)
 		dec.DisallowUnknownFields()
 		if err := dec.Decode(&req); err != nil {
-			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid_json"})
+			httpapi.WriteDecodeError(w, r, err)
 			return
 		}
 
@@ -35,7 +37,7 @@ func ForceLogoutHandler(manager *SessionManager) http.HandlerFunc {
 		subject := strings.TrimSpace(req.Subject)
 		reason := strings.TrimSpace(req.Reason)
 		if sessionID == "" && subject == "" {
-			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "session_id_or_subject_required"})
+			httpapi.WriteError(w, r, http.StatusBadRequest, "session_id_or_subject_required")
 			return
 		}
 
@@ -52,14 +54,14 @@ func ForceLogoutHandler(manager *SessionManager) http.HandlerFunc {
 			}
 			updated, err := manager.RevokeSession(r.Context(), sessionID, "admin", reason, meta)
 			if err != nil {
-				writeJSON(w, http.StatusInternalServerError, map[string]any{"error": "force_logout_failed"})
+				httpapi.WriteError(w, r, http.StatusInternalServerError, "force_logout_failed")
 				return
 			}
 			revoked := 0
 			if updated {
 				revoked = 1
 			}
-			writeJSON(w, http.StatusOK, map[string]any{"revoked": revoked})
+			httpapi.WriteJSON(w, http.StatusOK, map[string]any{"revoked": revoked})
 			return
 		}
 
@@ -68,9 +70,9 @@ func ForceLogoutHandler(manager *SessionManager) http.HandlerFunc {
 		}
 		count, err := manager.RevokeBySubject(r.Context(), subject, "admin", reason, meta)
 		if err != nil {
-			writeJSON(w, http.StatusInternalServerError, map[string]any{"error": "force_logout_failed"})
+			httpapi.WriteError(w, r, http.StatusInternalServerError, "force_logout_failed")
 			return
 		}
-		writeJSON(w, http.StatusOK, map[string]any{"revoked": count})
+		httpapi.WriteJSON(w, http.StatusOK, map[string]any{"revoked": count})
 	}
 }
@@ -9,17 +9,29 @@ import (
 )
 
 type GatewayHeadersAuthenticator struct {
-	Secret  string
-	MaxSkew time.Duration
+	Secret string
+	// PreviousSecrets are accepted alongside Secret while the internal auth
+	// secret is being rotated.
+	PreviousSecrets []string
+	MaxSkew         time.Duration
+	// Peers, when set, also requires a verified mTLS client certificate
+	// from an allowed workload. The identity headers must still carry a
+	// valid HMAC signature: any workload of the trust domain holds a
+	// certificate, only callers with the secret may assert identities.
+	Peers *SPIFFEPeerVerifier
 }
 
+// NewGatewayHeadersAuthenticator accepts the raw ANIMUS_INTERNAL_AUTH_SECRET
+// value, which may list several comma-separated secrets (newest first).
 func NewGatewayHeadersAuthenticator(secret string) (*GatewayHeadersAuthenticator, error) {
-	if strings.TrimSpace(secret) == "" {
+	secrets := ParseInternalAuthSecrets(secret)
+	if len(secrets) == 0 {
 		return nil, errors.New("ANIMUS_INTERNAL_AUTH_SECRET is required")
 	}
 	return &GatewayHeadersAuthenticator{
-		Secret:  secret,
-		MaxSkew: 5 * time.Minute,
+		Secret:          secrets.Current(),
+		PreviousSecrets: secrets.Previous(),
+		MaxSkew:         5 * time.Minute,
 	}, nil
 }
 
@@ -32,6 +44,12 @@ func (a *GatewayHeadersAuthenticator) Authenticate(ctx context.Context, r *http.
 	email := strings.TrimSpace(r.Header.Get(HeaderEmail))
 	rolesRaw := strings.TrimSpace(r.Header.Get(HeaderRoles))
 
+	if a.Peers != nil {
+		if _, err := a.Peers.VerifyRequest(r); err != nil {
+			return Identity{}, err
+		}
+	}
+
 	ts := strings.TrimSpace(r.Header.Get(HeaderInternalAuthTimestamp))
 	sig := strings.TrimSpace(r.Header.Get(HeaderInternalAuthSignature))
 	if ts == "" || sig == "" {
@@ -41,18 +59,25 @@ func (a *GatewayHeadersAuthenticator) Authenticate(ctx context.Context, r *http.
 	if err := VerifyInternalAuthTimestamp(ts, time.Now().UTC(), a.MaxSkew); err != nil {
 		return Identity{}, err
 	}
-	if err := VerifyInternalAuthSignature(
-		a.Secret,
-		ts,
-		r.Method,
-		r.URL.Path,
-		r.Header.Get("X-Request-Id"),
-		subject,
-		email,
-		rolesRaw,
-		sig,
-	); err != nil {
-		return Identity{}, err
+	var verifyErr error
+	for _, secret := range append([]string{a.Secret}, a.PreviousSecrets...) {
+		verifyErr = VerifyInternalAuthSignature(
+			secret,
+			ts,
+			r.Method,
+			r.URL.Path,
+			r.Header.Get("X-Request-Id"),
+			subject,
+			email,
+			rolesRaw,
+			sig,
+		)
+		if verifyErr == nil {
+			break
+		}
+	}
+	if verifyErr != nil {
+		return Identity{}, verifyErr
 	}
 
 	return Identity{
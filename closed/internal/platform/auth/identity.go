@@ -8,6 +8,10 @@ type Identity struct {
 	Subject string
 	Email   string
 	Roles   []string
+	// ProjectID pins the identity to a single project (service-account tokens); empty means unscoped.
+	ProjectID string
+	// Scopes narrows run-token identities to specific writes; empty for other identities.
+	Scopes []string
 }
 
 type ctxKeyIdentity struct{}
@@ -20,6 +20,39 @@ const (
 	HeaderInternalAuthSignature = "X-Animus-Auth-Sig"
 )
 
+// InternalAuthSecrets is ANIMUS_INTERNAL_AUTH_SECRET split on commas. The
+// first entry is the current secret and is used for signing; the remaining
+// entries are previous secrets that verifiers keep accepting so the secret
+// can be rotated without restarting every service at once.
+type InternalAuthSecrets []string
+
+func ParseInternalAuthSecrets(raw string) InternalAuthSecrets {
+	var out InternalAuthSecrets
+	for _, part := range strings.Split(raw, ",") {
+		if secret := strings.TrimSpace(part); secret != "" {
+			out = append(out, secret)
+		}
+	}
+	return out
+}
+
+// Current returns the secret used to sign outgoing requests.
+func (s InternalAuthSecrets) Current() string {
+	if len(s) == 0 {
+		return ""
+	}
+	return s[0]
+}
+
+// Previous returns the secrets that are still accepted but no longer used
+// for signing.
+func (s InternalAuthSecrets) Previous() []string {
+	if len(s) < 2 {
+		return nil
+	}
+	return append([]string(nil), s[1:]...)
+}
+
 func ComputeInternalAuthSignature(secret string, ts string, method string, path string, requestID string, subject string, email string, roles string) (string, error) {
 	if strings.TrimSpace(secret) == "" {
 		return "", errors.New("internal auth secret is required")
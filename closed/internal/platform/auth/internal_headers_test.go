@@ -72,3 +72,47 @@ func TestGatewayHeadersAuthenticator(t *testing.T) {
 		t.Fatalf("Roles=%v, want 2 roles", identity.Roles)
 	}
 }
+
+func TestGatewayHeadersAuthenticatorAcceptsPreviousSecrets(t *testing.T) {
+	authn, err := NewGatewayHeadersAuthenticator(" new-secret , old-secret,")
+	if err != nil {
+		t.Fatalf("NewGatewayHeadersAuthenticator() err=%v", err)
+	}
+	if authn.Secret != "new-secret" {
+		t.Fatalf("Secret=%q, want new-secret", authn.Secret)
+	}
+
+	signed := func(secret string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "http://example.test/datasets", nil)
+		req.Header.Set(HeaderSubject, "alice")
+		ts := strconv.FormatInt(time.Now().UTC().Unix(), 10)
+		sig, err := ComputeInternalAuthSignature(secret, ts, req.Method, req.URL.Path, "", "alice", "", "")
+		if err != nil {
+			t.Fatalf("ComputeInternalAuthSignature() err=%v", err)
+		}
+		req.Header.Set(HeaderInternalAuthTimestamp, ts)
+		req.Header.Set(HeaderInternalAuthSignature, sig)
+		return req
+	}
+
+	for _, secret := range []string{"new-secret", "old-secret"} {
+		req := signed(secret)
+		if _, err := authn.Authenticate(req.Context(), req); err != nil {
+			t.Fatalf("Authenticate() with %s err=%v", secret, err)
+		}
+	}
+	req := signed("retired-secret")
+	if _, err := authn.Authenticate(req.Context(), req); err == nil {
+		t.Fatalf("expected signature with unknown secret to be rejected")
+	}
+}
+
+func TestParseInternalAuthSecrets(t *testing.T) {
+	secrets := ParseInternalAuthSecrets("a, b ,,c")
+	if secrets.Current() != "a" || len(secrets.Previous()) != 2 || secrets.Previous()[1] != "c" {
+		t.Fatalf("unexpected secrets: %v", secrets)
+	}
+	if ParseInternalAuthSecrets(" , ").Current() != "" {
+		t.Fatalf("expected no secrets")
+	}
+}
@@ -0,0 +1,370 @@
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/env"
+)
+
+// InternalMTLSConfig configures mutual TLS between the gateway and internal
+// services. Workload identities are SPIFFE IDs carried as URI SANs, e.g.
+// spiffe://animus.internal/gateway. mTLS authenticates the workloads on both
+// ends; the identity headers stay HMAC-signed either way.
+type InternalMTLSConfig struct {
+	Enabled     bool
+	CertFile    string
+	KeyFile     string
+	CAFile      string
+	TrustDomain string
+	AllowedIDs  []string
+	// ServerIDs pins the SPIFFE ID expected from an upstream host. Hosts
+	// not listed must present spiffe://<trust domain>/<first DNS label>,
+	// e.g. spiffe://animus.internal/experiments for experiments.animus.svc.
+	ServerIDs map[string]string
+}
+
+func InternalMTLSConfigFromEnv() (InternalMTLSConfig, error) {
+	enabled, err := env.Bool("ANIMUS_INTERNAL_MTLS_ENABLED", false)
+	if err != nil {
+		return InternalMTLSConfig{}, err
+	}
+	cfg := InternalMTLSConfig{
+		Enabled:     enabled,
+		CertFile:    strings.TrimSpace(env.String("ANIMUS_INTERNAL_MTLS_CERT_FILE", "")),
+		KeyFile:     strings.TrimSpace(env.String("ANIMUS_INTERNAL_MTLS_KEY_FILE", "")),
+		CAFile:      strings.TrimSpace(env.String("ANIMUS_INTERNAL_MTLS_CA_FILE", "")),
+		TrustDomain: strings.ToLower(strings.TrimSpace(env.String("ANIMUS_INTERNAL_MTLS_TRUST_DOMAIN", "animus.internal"))),
+	}
+	for _, part := range strings.Split(env.String("ANIMUS_INTERNAL_MTLS_ALLOWED_IDS", ""), ",") {
+		if id := strings.TrimSpace(part); id != "" {
+			cfg.AllowedIDs = append(cfg.AllowedIDs, id)
+		}
+	}
+	for _, part := range strings.Split(env.String("ANIMUS_INTERNAL_MTLS_SERVER_IDS", ""), ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		host, id, ok := strings.Cut(part, "=")
+		if !ok || strings.TrimSpace(host) == "" {
+			return InternalMTLSConfig{}, fmt.Errorf("ANIMUS_INTERNAL_MTLS_SERVER_IDS entry %q must be host=spiffe-id", part)
+		}
+		if cfg.ServerIDs == nil {
+			cfg.ServerIDs = map[string]string{}
+		}
+		cfg.ServerIDs[strings.ToLower(strings.TrimSpace(host))] = strings.TrimSpace(id)
+	}
+	if err := cfg.Validate(); err != nil {
+		return InternalMTLSConfig{}, err
+	}
+	return cfg, nil
+}
+
+func (c InternalMTLSConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.CertFile == "" || c.KeyFile == "" || c.CAFile == "" {
+		return errors.New("ANIMUS_INTERNAL_MTLS_CERT_FILE, ANIMUS_INTERNAL_MTLS_KEY_FILE, and ANIMUS_INTERNAL_MTLS_CA_FILE are required when mTLS is enabled")
+	}
+	if c.TrustDomain == "" || strings.ContainsAny(c.TrustDomain, "/:") {
+		return fmt.Errorf("ANIMUS_INTERNAL_MTLS_TRUST_DOMAIN is invalid: %q", c.TrustDomain)
+	}
+	for _, id := range c.AllowedIDs {
+		domain, ok := spiffeTrustDomain(id)
+		if !ok || domain != c.TrustDomain {
+			return fmt.Errorf("ANIMUS_INTERNAL_MTLS_ALLOWED_IDS entry %q is not a SPIFFE ID in trust domain %q", id, c.TrustDomain)
+		}
+	}
+	for host, id := range c.ServerIDs {
+		domain, ok := spiffeTrustDomain(id)
+		if !ok || domain != c.TrustDomain {
+			return fmt.Errorf("ANIMUS_INTERNAL_MTLS_SERVER_IDS entry for %q is not a SPIFFE ID in trust domain %q", host, c.TrustDomain)
+		}
+	}
+	return nil
+}
+
+// ServerID returns the SPIFFE ID the upstream host must present.
+func (c InternalMTLSConfig) ServerID(host string) (string, error) {
+	host = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(host), "."))
+	if id, ok := c.ServerIDs[host]; ok {
+		return id, nil
+	}
+	if host == "" || net.ParseIP(host) != nil {
+		return "", fmt.Errorf("no SPIFFE ID pinned for upstream %q; set ANIMUS_INTERNAL_MTLS_SERVER_IDS", host)
+	}
+	label, _, _ := strings.Cut(host, ".")
+	return "spiffe://" + c.TrustDomain + "/" + label, nil
+}
+
+// ServerTLSConfig returns the listener config for an internal service, or nil
+// when mTLS is disabled. Client certificates are verified when presented but
+// not demanded at the handshake, so unauthenticated probes such as /healthz
+// keep working; GatewayHeadersAuthenticator enforces the peer identity.
+func (c InternalMTLSConfig) ServerTLSConfig() (*tls.Config, error) {
+	if !c.Enabled {
+		return nil, nil
+	}
+	pool, err := loadCertPool(c.CAFile)
+	if err != nil {
+		return nil, err
+	}
+	reloader, err := newCertReloader(c.CertFile, c.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		ClientAuth:     tls.VerifyClientCertIfGiven,
+		ClientCAs:      pool,
+		GetCertificate: reloader.getCertificate,
+	}, nil
+}
+
+// ClientTLSConfig returns the config used when calling the internal service
+// at host, or nil when mTLS is disabled. SPIFFE certificates carry no DNS
+// names, so the server is verified against the CA and its pinned SPIFFE ID
+// (ServerID) instead of the hostname.
+func (c InternalMTLSConfig) ClientTLSConfig(host string) (*tls.Config, error) {
+	client, err := c.newMTLSClient()
+	if err != nil || client == nil {
+		return nil, err
+	}
+	return client.config(host)
+}
+
+// Transport returns an HTTP transport presenting the client certificate and
+// pinning each upstream's SPIFFE ID, or nil (use the default transport) when
+// mTLS is disabled.
+func (c InternalMTLSConfig) Transport() (http.RoundTripper, error) {
+	client, err := c.newMTLSClient()
+	if err != nil || client == nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialTLSContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig, err := client.config(host)
+		if err != nil {
+			return nil, err
+		}
+		return (&tls.Dialer{Config: tlsConfig}).DialContext(ctx, network, addr)
+	}
+	return transport, nil
+}
+
+type mtlsClient struct {
+	cfg      InternalMTLSConfig
+	pool     *x509.CertPool
+	reloader *certReloader
+}
+
+func (c InternalMTLSConfig) newMTLSClient() (*mtlsClient, error) {
+	if !c.Enabled {
+		return nil, nil
+	}
+	pool, err := loadCertPool(c.CAFile)
+	if err != nil {
+		return nil, err
+	}
+	reloader, err := newCertReloader(c.CertFile, c.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	return &mtlsClient{cfg: c, pool: pool, reloader: reloader}, nil
+}
+
+func (m *mtlsClient) config(host string) (*tls.Config, error) {
+	expected, err := m.cfg.ServerID(host)
+	if err != nil {
+		return nil, err
+	}
+	pool := m.pool
+	return &tls.Config{
+		MinVersion:           tls.VersionTLS12,
+		ServerName:           host,
+		GetClientCertificate: m.reloader.getClientCertificate,
+		InsecureSkipVerify:   true,
+		VerifyConnection: func(cs tls.ConnectionState) error {
+			if len(cs.PeerCertificates) == 0 {
+				return errors.New("server presented no certificate")
+			}
+			intermediates := x509.NewCertPool()
+			for _, cert := range cs.PeerCertificates[1:] {
+				intermediates.AddCert(cert)
+			}
+			if _, err := cs.PeerCertificates[0].Verify(x509.VerifyOptions{
+				Roots:         pool,
+				Intermediates: intermediates,
+				KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+			}); err != nil {
+				return fmt.Errorf("verify server certificate: %w", err)
+			}
+			id, ok := SPIFFEIDFromCertificate(cs.PeerCertificates[0])
+			if !ok {
+				return errors.New("server certificate has no SPIFFE ID")
+			}
+			if id != expected {
+				return fmt.Errorf("server SPIFFE ID %q, want %q", id, expected)
+			}
+			return nil
+		},
+	}, nil
+}
+
+// PeerVerifier returns the verifier that authenticates callers by certificate,
+// or nil when mTLS is disabled.
+func (c InternalMTLSConfig) PeerVerifier() *SPIFFEPeerVerifier {
+	if !c.Enabled {
+		return nil
+	}
+	verifier := &SPIFFEPeerVerifier{TrustDomain: c.TrustDomain}
+	if len(c.AllowedIDs) > 0 {
+		verifier.AllowedIDs = make(map[string]struct{}, len(c.AllowedIDs))
+		for _, id := range c.AllowedIDs {
+			verifier.AllowedIDs[id] = struct{}{}
+		}
+	}
+	return verifier
+}
+
+// SPIFFEPeerVerifier accepts requests whose verified client certificate
+// carries a SPIFFE ID in TrustDomain and, if AllowedIDs is set, listed there.
+type SPIFFEPeerVerifier struct {
+	TrustDomain string
+	AllowedIDs  map[string]struct{}
+}
+
+func (v *SPIFFEPeerVerifier) VerifyRequest(r *http.Request) (string, error) {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return "", ErrUnauthenticated
+	}
+	id, ok := SPIFFEIDFromCertificate(r.TLS.VerifiedChains[0][0])
+	if !ok {
+		return "", ErrUnauthenticated
+	}
+	if domain, _ := spiffeTrustDomain(id); domain != v.TrustDomain {
+		return "", ErrUnauthenticated
+	}
+	if v.AllowedIDs != nil {
+		if _, ok := v.AllowedIDs[id]; !ok {
+			return "", ErrUnauthenticated
+		}
+	}
+	return id, nil
+}
+
+// SPIFFEIDFromCertificate returns the single spiffe:// URI SAN of cert.
+func SPIFFEIDFromCertificate(cert *x509.Certificate) (string, bool) {
+	if cert == nil {
+		return "", false
+	}
+	found := ""
+	for _, uri := range cert.URIs {
+		if uri == nil || uri.Scheme != "spiffe" {
+			continue
+		}
+		if found != "" {
+			return "", false
+		}
+		found = uri.String()
+	}
+	return found, found != ""
+}
+
+func spiffeTrustDomain(id string) (string, bool) {
+	parsed, err := url.Parse(id)
+	if err != nil || parsed.Scheme != "spiffe" || parsed.Host == "" || parsed.User != nil || parsed.Port() != "" {
+		return "", false
+	}
+	return strings.ToLower(parsed.Host), true
+}
+
+func loadCertPool(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read mtls ca: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("mtls ca %s contains no certificates", path)
+	}
+	return pool, nil
+}
+
+// certReloader serves the key pair from disk and picks up rotated files, so
+// short-lived workload certificates can be renewed without a restart.
+type certReloader struct {
+	certFile string
+	keyFile  string
+
+	mu       sync.Mutex
+	cert     *tls.Certificate
+	modTime  time.Time
+	checked  time.Time
+	interval time.Duration
+}
+
+func newCertReloader(certFile, keyFile string) (*certReloader, error) {
+	r := &certReloader{certFile: certFile, keyFile: keyFile, interval: 10 * time.Second}
+	if _, err := r.current(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *certReloader) current() (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if r.cert != nil && now.Sub(r.checked) < r.interval {
+		return r.cert, nil
+	}
+	r.checked = now
+	info, err := os.Stat(r.certFile)
+	if err != nil {
+		if r.cert != nil {
+			return r.cert, nil
+		}
+		return nil, fmt.Errorf("stat mtls cert: %w", err)
+	}
+	if r.cert != nil && info.ModTime().Equal(r.modTime) {
+		return r.cert, nil
+	}
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		if r.cert != nil {
+			// Cert and key are often written separately; keep serving the
+			// previous pair until both halves match again.
+			return r.cert, nil
+		}
+		return nil, fmt.Errorf("load mtls key pair: %w", err)
+	}
+	r.cert = &cert
+	r.modTime = info.ModTime()
+	return r.cert, nil
+}
+
+func (r *certReloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.current()
+}
+
+func (r *certReloader) getClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return r.current()
+}
@@ -0,0 +1,272 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	dir  string
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate ca key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "animus test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create ca: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	ca := &testCA{cert: cert, key: key, dir: t.TempDir()}
+	writePEM(t, filepath.Join(ca.dir, "ca.pem"), "CERTIFICATE", der)
+	return ca
+}
+
+// issue writes a leaf certificate for spiffeID and returns its cert and key paths.
+func (ca *testCA) issue(t *testing.T, name, spiffeID string) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	id, _ := url.Parse(spiffeID)
+	serial, _ := rand.Int(rand.Reader, big.NewInt(1<<62))
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		URIs:         []*url.URL{id},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatalf("create cert: %v", err)
+	}
+	keyDER, _ := x509.MarshalECPrivateKey(key)
+	certPath := filepath.Join(ca.dir, name+".pem")
+	keyPath := filepath.Join(ca.dir, name+"-key.pem")
+	writePEM(t, certPath, "CERTIFICATE", der)
+	writePEM(t, keyPath, "EC PRIVATE KEY", keyDER)
+	return certPath, keyPath
+}
+
+func (ca *testCA) config(certPath, keyPath string) InternalMTLSConfig {
+	return InternalMTLSConfig{
+		Enabled:     true,
+		CertFile:    certPath,
+		KeyFile:     keyPath,
+		CAFile:      filepath.Join(ca.dir, "ca.pem"),
+		TrustDomain: "animus.internal",
+		ServerIDs:   map[string]string{"127.0.0.1": "spiffe://animus.internal/experiments"},
+	}
+}
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+func startMTLSServer(t *testing.T, cfg InternalMTLSConfig) string {
+	t.Helper()
+	tlsConfig, err := cfg.ServerTLSConfig()
+	if err != nil {
+		t.Fatalf("server tls: %v", err)
+	}
+	authenticator := &GatewayHeadersAuthenticator{Secret: testInternalSecret, MaxSkew: time.Minute, Peers: cfg.PeerVerifier()}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity, err := authenticator.Authenticate(r.Context(), r)
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("X-Subject", identity.Subject)
+		w.WriteHeader(http.StatusOK)
+	}), ErrorLog: log.New(io.Discard, "", 0)}
+	go func() { _ = srv.Serve(tls.NewListener(ln, tlsConfig)) }()
+	t.Cleanup(func() { _ = srv.Close() })
+	return "https://" + ln.Addr().String()
+}
+
+const testInternalSecret = "internal-secret"
+
+// mtlsGet calls target with identity headers signed by secret, or unsigned
+// when secret is empty.
+func mtlsGet(t *testing.T, transport http.RoundTripper, target string, secret string) (*http.Response, error) {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodGet, target+"/projects", nil)
+	req.Header.Set(HeaderSubject, "user-1")
+	req.Header.Set(HeaderRoles, "viewer")
+	if secret != "" {
+		ts := strconv.FormatInt(time.Now().Unix(), 10)
+		sig, err := ComputeInternalAuthSignature(secret, ts, http.MethodGet, "/projects", "", "user-1", "", "viewer")
+		if err != nil {
+			t.Fatalf("sign: %v", err)
+		}
+		req.Header.Set(HeaderInternalAuthTimestamp, ts)
+		req.Header.Set(HeaderInternalAuthSignature, sig)
+	}
+	resp, err := (&http.Client{Transport: transport, Timeout: 5 * time.Second}).Do(req)
+	if err == nil {
+		_ = resp.Body.Close()
+	}
+	return resp, err
+}
+
+func TestInternalMTLSAuthenticatesSPIFFEPeer(t *testing.T) {
+	ca := newTestCA(t)
+	serverCert, serverKey := ca.issue(t, "experiments", "spiffe://animus.internal/experiments")
+	gatewayCert, gatewayKey := ca.issue(t, "gateway", "spiffe://animus.internal/gateway")
+	foreignCert, foreignKey := ca.issue(t, "foreign", "spiffe://other.example/gateway")
+
+	serverCfg := ca.config(serverCert, serverKey)
+	serverCfg.AllowedIDs = []string{"spiffe://animus.internal/gateway"}
+	target := startMTLSServer(t, serverCfg)
+
+	gateway, err := ca.config(gatewayCert, gatewayKey).Transport()
+	if err != nil {
+		t.Fatalf("gateway transport: %v", err)
+	}
+	resp, err := mtlsGet(t, gateway, target, testInternalSecret)
+	if err != nil {
+		t.Fatalf("gateway request: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("X-Subject") != "user-1" {
+		t.Fatalf("expected gateway peer to be trusted, status=%d", resp.StatusCode)
+	}
+
+	// A certificate from the right CA but the wrong trust domain is rejected.
+	foreign, _ := ca.config(foreignCert, foreignKey).Transport()
+	if resp, err := mtlsGet(t, foreign, target, testInternalSecret); err != nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected foreign trust domain to be rejected, err=%v", err)
+	}
+
+	// A peer in the trust domain that is not on the allow list is rejected.
+	other, _ := ca.config(serverCert, serverKey).Transport()
+	if resp, err := mtlsGet(t, other, target, testInternalSecret); err != nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected peer outside allow list to be rejected, err=%v", err)
+	}
+
+	// A trusted peer still cannot assert identities without the secret.
+	if resp, err := mtlsGet(t, gateway, target, ""); err != nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected unsigned headers from a trusted peer to be rejected, err=%v", err)
+	}
+
+	// Without a client certificate the handshake succeeds but the request does not.
+	anonymous := &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
+	if resp, err := mtlsGet(t, anonymous, target, testInternalSecret); err != nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected anonymous caller to be rejected, err=%v", err)
+	}
+}
+
+func TestInternalMTLSClientRejectsServerOutsideTrustDomain(t *testing.T) {
+	ca := newTestCA(t)
+	serverCert, serverKey := ca.issue(t, "rogue", "spiffe://other.example/experiments")
+	gatewayCert, gatewayKey := ca.issue(t, "gateway", "spiffe://animus.internal/gateway")
+
+	serverCfg := ca.config(serverCert, serverKey)
+	serverCfg.TrustDomain = "other.example"
+	target := startMTLSServer(t, serverCfg)
+
+	gateway, _ := ca.config(gatewayCert, gatewayKey).Transport()
+	if _, err := mtlsGet(t, gateway, target, testInternalSecret); err == nil {
+		t.Fatalf("expected handshake to fail for server outside trust domain")
+	}
+}
+
+func TestInternalMTLSClientPinsServerID(t *testing.T) {
+	ca := newTestCA(t)
+	// A workload of the trust domain other than the one the client expects.
+	serverCert, serverKey := ca.issue(t, "dataplane", "spiffe://animus.internal/dataplane")
+	gatewayCert, gatewayKey := ca.issue(t, "gateway", "spiffe://animus.internal/gateway")
+	target := startMTLSServer(t, ca.config(serverCert, serverKey))
+
+	gateway, _ := ca.config(gatewayCert, gatewayKey).Transport()
+	if _, err := mtlsGet(t, gateway, target, testInternalSecret); err == nil {
+		t.Fatalf("expected handshake to fail for a server with another SPIFFE ID")
+	}
+}
+
+func TestInternalMTLSServerID(t *testing.T) {
+	cfg := InternalMTLSConfig{TrustDomain: "animus.internal", ServerIDs: map[string]string{"10.0.0.5": "spiffe://animus.internal/lineage"}}
+	for host, want := range map[string]string{
+		"experiments.animus.svc.cluster.local": "spiffe://animus.internal/experiments",
+		"Dataplane":                            "spiffe://animus.internal/dataplane",
+		"10.0.0.5":                             "spiffe://animus.internal/lineage",
+	} {
+		if got, err := cfg.ServerID(host); err != nil || got != want {
+			t.Fatalf("ServerID(%q) = %q, %v; want %q", host, got, err, want)
+		}
+	}
+	if _, err := cfg.ServerID("10.0.0.6"); err == nil {
+		t.Fatalf("expected an unpinned IP upstream to be rejected")
+	}
+}
+
+func TestInternalMTLSConfigValidate(t *testing.T) {
+	if err := (InternalMTLSConfig{}).Validate(); err != nil {
+		t.Fatalf("disabled config should be valid: %v", err)
+	}
+	if err := (InternalMTLSConfig{Enabled: true, TrustDomain: "animus.internal"}).Validate(); err == nil {
+		t.Fatalf("expected missing files to be rejected")
+	}
+	cfg := InternalMTLSConfig{
+		Enabled:     true,
+		CertFile:    "cert.pem",
+		KeyFile:     "key.pem",
+		CAFile:      "ca.pem",
+		TrustDomain: "animus.internal",
+		AllowedIDs:  []string{"spiffe://other.example/gateway"},
+	}
+	if err := cfg.Validate(); err == nil {
+		t.Fatalf("expected allowed id outside trust domain to be rejected")
+	}
+}
+
+func TestGatewayHeadersAuthenticatorFallsBackToHMAC(t *testing.T) {
+	authenticator, err := NewGatewayHeadersAuthenticator("secret")
+	if err != nil {
+		t.Fatalf("new authenticator: %v", err)
+	}
+	req, _ := http.NewRequest(http.MethodGet, "/projects", nil)
+	req.Header.Set(HeaderSubject, "user-1")
+	if _, err := authenticator.Authenticate(req.Context(), req); err == nil {
+		t.Fatalf("expected unsigned request to be rejected without mTLS")
+	}
+}
@@ -0,0 +1,449 @@
+package auth
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// LDAPVerifier checks passwords with an LDAPv3 simple bind as the user, then
+// reads the user's own entry for e-mail and group membership. Only bind,
+// base-object search and unbind are spoken, so no LDAP client library is
+// needed.
+type LDAPVerifier struct {
+	address        string
+	tlsConfig      *tls.Config
+	bindDNTemplate string
+	groupAttribute string
+	emailAttribute string
+	defaultRoles   []string
+	groupRoleMap   map[string]string
+	timeout        time.Duration
+}
+
+func NewLDAPVerifier(cfg Config) (*LDAPVerifier, error) {
+	u, err := url.Parse(cfg.LDAPURL)
+	if err != nil {
+		return nil, fmt.Errorf("AUTH_LDAP_URL: %w", err)
+	}
+	v := &LDAPVerifier{
+		address:        u.Host,
+		bindDNTemplate: cfg.LDAPBindDNTemplate,
+		groupAttribute: cfg.LDAPGroupAttribute,
+		emailAttribute: cfg.LDAPEmailAttribute,
+		defaultRoles:   cfg.LDAPDefaultRoles,
+		groupRoleMap:   cfg.GroupRoleMap,
+		timeout:        cfg.LDAPTimeout,
+	}
+	switch u.Scheme {
+	case "ldaps":
+		if u.Port() == "" {
+			v.address = net.JoinHostPort(u.Hostname(), "636")
+		}
+		v.tlsConfig = &tls.Config{ServerName: u.Hostname(), MinVersion: tls.VersionTLS12}
+		if cfg.LDAPCAFile != "" {
+			pem, err := os.ReadFile(cfg.LDAPCAFile)
+			if err != nil {
+				return nil, fmt.Errorf("AUTH_LDAP_CA_FILE: %w", err)
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, errors.New("AUTH_LDAP_CA_FILE contains no certificates")
+			}
+			v.tlsConfig.RootCAs = pool
+		}
+	case "ldap":
+		if u.Port() == "" {
+			v.address = net.JoinHostPort(u.Hostname(), "389")
+		}
+	default:
+		return nil, fmt.Errorf("AUTH_LDAP_URL: unsupported scheme %q", u.Scheme)
+	}
+	return v, nil
+}
+
+func (v *LDAPVerifier) Verify(ctx context.Context, username, password string) (Identity, error) {
+	username = strings.TrimSpace(username)
+	// An empty password is an unauthenticated bind (RFC 4513 §5.1.2), which
+	// most servers accept: never let it through as a login.
+	if username == "" || password == "" {
+		return Identity{}, ErrInvalidCredentials
+	}
+	dn := strings.ReplaceAll(v.bindDNTemplate, "{username}", escapeDNValue(username))
+
+	ctx, cancel := context.WithTimeout(ctx, v.timeout)
+	defer cancel()
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", v.address)
+	if err != nil {
+		return Identity{}, fmt.Errorf("ldap dial: %w", err)
+	}
+	if v.tlsConfig != nil {
+		tlsConn := tls.Client(conn, v.tlsConfig)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			_ = conn.Close()
+			return Identity{}, fmt.Errorf("ldap tls: %w", err)
+		}
+		conn = tlsConn
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	c := &ldapConn{rw: conn, r: bufio.NewReader(conn)}
+	if err := c.bind(dn, password); err != nil {
+		return Identity{}, err
+	}
+	attrs, err := c.readEntry(dn, []string{v.groupAttribute, v.emailAttribute})
+	if err != nil {
+		return Identity{}, err
+	}
+	c.unbind()
+
+	var groups []string
+	for _, value := range attrs[strings.ToLower(v.groupAttribute)] {
+		groups = append(groups, strings.ToLower(value), groupCommonName(value))
+	}
+	groups = mergeUnique(groups)
+	var email string
+	if values := attrs[strings.ToLower(v.emailAttribute)]; len(values) > 0 {
+		email = values[0]
+	}
+	return Identity{
+		Subject: strings.ToLower(username),
+		Email:   email,
+		Roles:   mergeUnique(v.defaultRoles, groups, mapGroupsToRoles(groups, v.groupRoleMap)),
+	}, nil
+}
+
+// LDAP result codes (RFC 4511 §4.1.9). Only invalidCredentials counts as a
+// rejected login; any other failure is a server problem.
+const (
+	ldapResultSuccess            = 0
+	ldapResultInvalidCredentials = 49
+)
+
+// BER identifiers of the LDAP messages used here.
+const (
+	berInteger     = 0x02
+	berOctetString = 0x04
+	berEnumerated  = 0x0a
+	berBoolean     = 0x01
+	berSequence    = 0x30
+
+	ldapBindRequest       = 0x60
+	ldapBindResponse      = 0x61
+	ldapUnbindRequest     = 0x42
+	ldapSearchRequest     = 0x63
+	ldapSearchResultEntry = 0x64
+	ldapSearchResultDone  = 0x65
+	ldapSearchResultRef   = 0x73
+	ldapSimpleAuth        = 0x80
+	ldapFilterPresent     = 0x87
+)
+
+type ldapConn struct {
+	rw     io.ReadWriter
+	r      *bufio.Reader
+	nextID int
+}
+
+func (c *ldapConn) send(op []byte) (int, error) {
+	c.nextID++
+	msg := berTLV(berSequence, append(berInt(berInteger, c.nextID), op...))
+	_, err := c.rw.Write(msg)
+	return c.nextID, err
+}
+
+// receive reads the next message for id and returns its protocol op tag and
+// contents.
+func (c *ldapConn) receive(id int) (byte, []byte, error) {
+	for {
+		tag, body, err := readBER(c.r)
+		if err != nil {
+			return 0, nil, fmt.Errorf("ldap read: %w", err)
+		}
+		if tag != berSequence {
+			return 0, nil, errors.New("ldap: malformed message")
+		}
+		_, idBytes, rest, err := parseBER(body)
+		if err != nil {
+			return 0, nil, err
+		}
+		opTag, op, _, err := parseBER(rest)
+		if err != nil {
+			return 0, nil, err
+		}
+		if berToInt(idBytes) != id {
+			continue
+		}
+		return opTag, op, nil
+	}
+}
+
+func (c *ldapConn) bind(dn, password string) error {
+	op := berTLV(ldapBindRequest, concatBytes(
+		berInt(berInteger, 3),
+		berTLV(berOctetString, []byte(dn)),
+		berTLV(ldapSimpleAuth, []byte(password)),
+	))
+	id, err := c.send(op)
+	if err != nil {
+		return fmt.Errorf("ldap bind: %w", err)
+	}
+	tag, body, err := c.receive(id)
+	if err != nil {
+		return err
+	}
+	if tag != ldapBindResponse {
+		return errors.New("ldap: unexpected bind response")
+	}
+	code, err := ldapResultCode(body)
+	if err != nil {
+		return err
+	}
+	switch code {
+	case ldapResultSuccess:
+		return nil
+	case ldapResultInvalidCredentials:
+		return ErrInvalidCredentials
+	default:
+		return fmt.Errorf("ldap bind: result code %d", code)
+	}
+}
+
+// readEntry returns the requested attributes of dn (lower-cased names).
+func (c *ldapConn) readEntry(dn string, attributes []string) (map[string][]string, error) {
+	var attrList []byte
+	for _, attr := range attributes {
+		if attr != "" {
+			attrList = append(attrList, berTLV(berOctetString, []byte(attr))...)
+		}
+	}
+	op := berTLV(ldapSearchRequest, concatBytes(
+		berTLV(berOctetString, []byte(dn)),
+		berInt(berEnumerated, 0), // baseObject
+		berInt(berEnumerated, 0), // neverDerefAliases
+		berInt(berInteger, 1),    // sizeLimit
+		berInt(berInteger, 0),    // timeLimit
+		berTLV(berBoolean, []byte{0}),
+		berTLV(ldapFilterPresent, []byte("objectClass")),
+		berTLV(berSequence, attrList),
+	))
+	id, err := c.send(op)
+	if err != nil {
+		return nil, fmt.Errorf("ldap search: %w", err)
+	}
+	out := make(map[string][]string)
+	for {
+		tag, body, err := c.receive(id)
+		if err != nil {
+			return nil, err
+		}
+		switch tag {
+		case ldapSearchResultEntry:
+			if err := parseSearchEntry(body, out); err != nil {
+				return nil, err
+			}
+		case ldapSearchResultRef:
+		case ldapSearchResultDone:
+			code, err := ldapResultCode(body)
+			if err != nil {
+				return nil, err
+			}
+			if code != ldapResultSuccess {
+				return nil, fmt.Errorf("ldap search: result code %d", code)
+			}
+			return out, nil
+		default:
+			return nil, errors.New("ldap: unexpected search response")
+		}
+	}
+}
+
+func (c *ldapConn) unbind() {
+	_, _ = c.send(berTLV(ldapUnbindRequest, nil))
+}
+
+func parseSearchEntry(body []byte, out map[string][]string) error {
+	_, _, rest, err := parseBER(body) // objectName
+	if err != nil {
+		return err
+	}
+	_, attrs, _, err := parseBER(rest)
+	if err != nil {
+		return err
+	}
+	for len(attrs) > 0 {
+		var attr []byte
+		if _, attr, attrs, err = parseBER(attrs); err != nil {
+			return err
+		}
+		_, name, valueSet, err := parseBER(attr)
+		if err != nil {
+			return err
+		}
+		_, values, _, err := parseBER(valueSet)
+		if err != nil {
+			return err
+		}
+		key := strings.ToLower(string(name))
+		for len(values) > 0 {
+			var value []byte
+			if _, value, values, err = parseBER(values); err != nil {
+				return err
+			}
+			out[key] = append(out[key], string(value))
+		}
+	}
+	return nil
+}
+
+func ldapResultCode(body []byte) (int, error) {
+	tag, code, _, err := parseBER(body)
+	if err != nil {
+		return 0, err
+	}
+	if tag != berEnumerated {
+		return 0, errors.New("ldap: malformed result")
+	}
+	return berToInt(code), nil
+}
+
+func berTLV(tag byte, content []byte) []byte {
+	out := []byte{tag}
+	n := len(content)
+	switch {
+	case n < 0x80:
+		out = append(out, byte(n))
+	case n <= 0xff:
+		out = append(out, 0x81, byte(n))
+	case n <= 0xffff:
+		out = append(out, 0x82, byte(n>>8), byte(n))
+	default:
+		out = append(out, 0x84, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	}
+	return append(out, content...)
+}
+
+func berInt(tag byte, v int) []byte {
+	var content []byte
+	for {
+		content = append([]byte{byte(v)}, content...)
+		v >>= 8
+		if v == 0 && content[0] < 0x80 {
+			break
+		}
+	}
+	return berTLV(tag, content)
+}
+
+func berToInt(content []byte) int {
+	v := 0
+	for _, b := range content {
+		v = v<<8 | int(b)
+	}
+	return v
+}
+
+// parseBER splits one definite-length element off data. Long-form lengths of
+// any width are accepted, as Active Directory always sends four-byte ones.
+func parseBER(data []byte) (byte, []byte, []byte, error) {
+	if len(data) < 2 {
+		return 0, nil, nil, errors.New("ldap: truncated element")
+	}
+	tag := data[0]
+	length, header := int(data[1]), 2
+	if length&0x80 != 0 {
+		width := length & 0x7f
+		if width == 0 || width > 4 || len(data) < 2+width {
+			return 0, nil, nil, errors.New("ldap: unsupported length")
+		}
+		length = 0
+		for _, b := range data[2 : 2+width] {
+			length = length<<8 | int(b)
+		}
+		header += width
+	}
+	if length < 0 || len(data)-header < length {
+		return 0, nil, nil, errors.New("ldap: truncated element")
+	}
+	return tag, data[header : header+length], data[header+length:], nil
+}
+
+// readBER reads one element from the stream.
+func readBER(r *bufio.Reader) (byte, []byte, error) {
+	head := make([]byte, 2)
+	if _, err := io.ReadFull(r, head); err != nil {
+		return 0, nil, err
+	}
+	length := int(head[1])
+	if length&0x80 != 0 {
+		width := length & 0x7f
+		if width == 0 || width > 4 {
+			return 0, nil, errors.New("ldap: unsupported length")
+		}
+		lengthBytes := make([]byte, width)
+		if _, err := io.ReadFull(r, lengthBytes); err != nil {
+			return 0, nil, err
+		}
+		length = berToInt(lengthBytes)
+	}
+	const maxMessage = 1 << 20
+	if length > maxMessage {
+		return 0, nil, errors.New("ldap: message too large")
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+	return head[0], body, nil
+}
+
+func concatBytes(parts ...[]byte) []byte {
+	var out []byte
+	for _, part := range parts {
+		out = append(out, part...)
+	}
+	return out
+}
+
+// escapeDNValue escapes an attribute value for a DN (RFC 4514 §2.4).
+func escapeDNValue(value string) string {
+	var b strings.Builder
+	for i, r := range value {
+		switch {
+		case strings.ContainsRune(`,+"\<>;=`, r),
+			i == 0 && (r == ' ' || r == '#'),
+			i == len(value)-1 && r == ' ':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x20:
+			fmt.Fprintf(&b, "\\%02x", r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// groupCommonName returns the first RDN value of a group DN, so
+// "cn=ml-admins,ou=groups,dc=lab" maps as "ml-admins".
+func groupCommonName(dn string) string {
+	first, _, _ := strings.Cut(dn, ",")
+	_, value, ok := strings.Cut(first, "=")
+	if !ok {
+		return strings.ToLower(strings.TrimSpace(dn))
+	}
+	return strings.ToLower(strings.TrimSpace(value))
+}
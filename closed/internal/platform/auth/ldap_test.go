@@ -0,0 +1,161 @@
+package auth
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+// serveLDAP answers binds for uid=alice with password "s3cret" and a
+// base-object search with her mail and groups, using four-byte lengths like
+// Active Directory.
+func serveLDAP(t *testing.T, ln net.Listener) {
+	t.Helper()
+	long := func(tag byte, content []byte) []byte {
+		n := len(content)
+		return append([]byte{tag, 0x84, byte(n >> 24), byte(n >> 16), byte(n >> 8), byte(n)}, content...)
+	}
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		go func(conn net.Conn) {
+			defer conn.Close()
+			r := bufio.NewReader(conn)
+			for {
+				_, body, err := readBER(r)
+				if err != nil {
+					return
+				}
+				_, id, rest, _ := parseBER(body)
+				tag, op, _, _ := parseBER(rest)
+				reply := func(opTag byte, content []byte) {
+					_, _ = conn.Write(long(berSequence, concatBytes(berTLV(berInteger, id), long(opTag, content))))
+				}
+				result := func(code int) []byte {
+					return concatBytes(berInt(berEnumerated, code), berTLV(berOctetString, nil), berTLV(berOctetString, nil))
+				}
+				switch tag {
+				case ldapBindRequest:
+					_, _, rest, _ := parseBER(op)
+					_, dn, rest, _ := parseBER(rest)
+					_, password, _, _ := parseBER(rest)
+					code := ldapResultInvalidCredentials
+					if string(dn) == "uid=alice,ou=people,dc=lab" && string(password) == "s3cret" {
+						code = ldapResultSuccess
+					}
+					reply(ldapBindResponse, result(code))
+				case ldapSearchRequest:
+					_, dn, _, _ := parseBER(op)
+					attrs := concatBytes(
+						berTLV(berSequence, concatBytes(berTLV(berOctetString, []byte("mail")),
+							berTLV(0x31, berTLV(berOctetString, []byte("alice@lab.local"))))),
+						berTLV(berSequence, concatBytes(berTLV(berOctetString, []byte("memberOf")),
+							berTLV(0x31, concatBytes(
+								berTLV(berOctetString, []byte("CN=ML-Admins,OU=Groups,DC=lab")),
+								berTLV(berOctetString, []byte("cn=ml-team,ou=groups,dc=lab")),
+							)))),
+					)
+					reply(ldapSearchResultEntry, concatBytes(berTLV(berOctetString, dn), berTLV(berSequence, attrs)))
+					reply(ldapSearchResultDone, result(ldapResultSuccess))
+				case ldapUnbindRequest:
+					return
+				}
+			}
+		}(conn)
+	}
+}
+
+func TestLDAPVerifier(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go serveLDAP(t, ln)
+
+	cfg := Config{
+		Mode:               ModeLDAP,
+		LDAPURL:            "ldap://" + ln.Addr().String(),
+		LDAPAllowPlaintext: true,
+		LDAPBindDNTemplate: "uid={username},ou=people,dc=lab",
+		LDAPGroupAttribute: "memberOf",
+		LDAPEmailAttribute: "mail",
+		LDAPDefaultRoles:   []string{RoleViewer},
+		LDAPTimeout:        5 * time.Second,
+		GroupRoleMap:       map[string]string{"ml-admins": RoleAdmin},
+	}
+	verifier, err := NewLDAPVerifier(cfg)
+	if err != nil {
+		t.Fatalf("NewLDAPVerifier: %v", err)
+	}
+
+	identity, err := verifier.Verify(context.Background(), "alice", "s3cret")
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if identity.Subject != "alice" || identity.Email != "alice@lab.local" {
+		t.Fatalf("identity=%+v", identity)
+	}
+	for _, want := range []string{"viewer", "ml-admins", "ml-team", "admin"} {
+		if !contains(identity.Roles, want) {
+			t.Fatalf("roles=%v, want %s", identity.Roles, want)
+		}
+	}
+
+	for _, creds := range [][2]string{{"alice", "wrong"}, {"alice", ""}, {"alice,ou=admins", "s3cret"}} {
+		if _, err := verifier.Verify(context.Background(), creds[0], creds[1]); !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("%v: err=%v", creds, err)
+		}
+	}
+}
+
+func TestEscapeDNValue(t *testing.T) {
+	cases := map[string]string{
+		"alice":       "alice",
+		"a,b=c":       `a\,b\=c`,
+		" #lead":      `\ #lead`,
+		"#x":          `\#x`,
+		"trail ":      `trail\ `,
+		`back\slash+`: `back\\slash\+`,
+	}
+	for in, want := range cases {
+		if got := escapeDNValue(in); got != want {
+			t.Errorf("escapeDNValue(%q)=%q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestConfigPasswordModes(t *testing.T) {
+	base := Config{
+		RolesClaim:            "roles",
+		EmailClaim:            "email",
+		SessionCookieName:     "animus_session",
+		SessionCookieMaxAge:   time.Hour,
+		SessionCookieSameSite: "Lax",
+		LDAPTimeout:           time.Second,
+	}
+	cfg := base
+	cfg.Mode = ModeStatic
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("static mode without users file accepted")
+	}
+	cfg.Mode = ModeLDAP
+	cfg.LDAPURL = "ldap://ldap.lab:389"
+	cfg.LDAPBindDNTemplate = "uid={username},dc=lab"
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("plaintext ldap accepted without AUTH_LDAP_ALLOW_PLAINTEXT")
+	}
+	cfg.LDAPURL = "ldaps://ldap.lab"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("ldaps config: %v", err)
+	}
+	cfg.LDAPBindDNTemplate = "uid=alice,dc=lab"
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("bind DN template without {username} accepted")
+	}
+}
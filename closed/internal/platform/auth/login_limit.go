@@ -0,0 +1,121 @@
+package auth
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// loginLimiterMaxKeys bounds the failure table; past it expired entries are
+// dropped before a new one is added.
+const loginLimiterMaxKeys = 10000
+
+// loginLimiter counts failed password logins per username and per client IP
+// and locks the key out once its limit is reached within the lockout
+// window. State is per gateway process: with several replicas an attacker
+// gets the limit on each of them.
+type loginLimiter struct {
+	maxUser int
+	maxIP   int
+	lockout time.Duration
+	now     func() time.Time
+
+	mu       sync.Mutex
+	failures map[string]loginFailures
+}
+
+type loginFailures struct {
+	count int
+	first time.Time
+}
+
+func newLoginLimiter(cfg Config, now func() time.Time) *loginLimiter {
+	if cfg.LoginMaxFailures <= 0 && cfg.LoginMaxFailuresPerIP <= 0 {
+		return nil
+	}
+	return &loginLimiter{
+		maxUser:  cfg.LoginMaxFailures,
+		maxIP:    cfg.LoginMaxFailuresPerIP,
+		lockout:  cfg.LoginLockout,
+		now:      now,
+		failures: map[string]loginFailures{},
+	}
+}
+
+// Allow reports whether a login for username from ip may be attempted and,
+// if not, how long the caller should wait.
+func (l *loginLimiter) Allow(username, ip string) (time.Duration, bool) {
+	if l == nil {
+		return 0, true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	var wait time.Duration
+	for _, key := range l.keys(username, ip) {
+		entry, ok := l.failures[key.name]
+		if !ok || now.Sub(entry.first) >= l.lockout || entry.count < key.max {
+			continue
+		}
+		if remaining := entry.first.Add(l.lockout).Sub(now); remaining > wait {
+			wait = remaining
+		}
+	}
+	return wait, wait == 0
+}
+
+// Fail records a rejected password for username from ip.
+func (l *loginLimiter) Fail(username, ip string) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	for _, key := range l.keys(username, ip) {
+		entry, ok := l.failures[key.name]
+		if !ok || now.Sub(entry.first) >= l.lockout {
+			if !ok && len(l.failures) >= loginLimiterMaxKeys {
+				l.pruneLocked(now)
+			}
+			entry = loginFailures{first: now}
+		}
+		entry.count++
+		l.failures[key.name] = entry
+	}
+}
+
+// Succeed clears the failures of username. The IP counter is kept so that
+// one valid account does not reset guessing against the others.
+func (l *loginLimiter) Succeed(username string) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.failures, "user:"+strings.ToLower(username))
+}
+
+type loginLimitKey struct {
+	name string
+	max  int
+}
+
+func (l *loginLimiter) keys(username, ip string) []loginLimitKey {
+	keys := make([]loginLimitKey, 0, 2)
+	if l.maxUser > 0 {
+		keys = append(keys, loginLimitKey{name: "user:" + strings.ToLower(username), max: l.maxUser})
+	}
+	if l.maxIP > 0 && ip != "" {
+		keys = append(keys, loginLimitKey{name: "ip:" + ip, max: l.maxIP})
+	}
+	return keys
+}
+
+func (l *loginLimiter) pruneLocked(now time.Time) {
+	for name, entry := range l.failures {
+		if now.Sub(entry.first) >= l.lockout {
+			delete(l.failures, name)
+		}
+	}
+}
@@ -2,12 +2,13 @@ package auth
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"log/slog"
 	"net/http"
 	"strings"
 	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
 )
 
 type AuthorizeFunc func(r *http.Request, identity Identity) error
@@ -52,18 +53,12 @@ func (m Middleware) Wrap(next http.Handler) http.Handler {
 			if errors.Is(err, ErrUnauthenticated) {
 				m.logDeny(r, http.StatusUnauthorized, "unauthenticated", err)
 				m.auditDeny(r, Identity{}, http.StatusUnauthorized, "unauthenticated", err)
-				writeJSON(w, http.StatusUnauthorized, map[string]any{
-					"error":      "unauthorized",
-					"request_id": r.Header.Get("X-Request-Id"),
-				})
+				httpapi.WriteError(w, r, http.StatusUnauthorized, "unauthorized")
 				return
 			}
 			m.logDeny(r, http.StatusUnauthorized, "invalid_token", err)
 			m.auditDeny(r, Identity{}, http.StatusUnauthorized, "invalid_token", err)
-			writeJSON(w, http.StatusUnauthorized, map[string]any{
-				"error":      "invalid_token",
-				"request_id": r.Header.Get("X-Request-Id"),
-			})
+			httpapi.WriteError(w, r, http.StatusUnauthorized, "invalid_token")
 			return
 		}
 
@@ -78,10 +73,7 @@ func (m Middleware) Wrap(next http.Handler) http.Handler {
 				}
 				m.logDeny(r, status, reason, err, "subject", identity.Subject)
 				m.auditDeny(r, identity, status, reason, err)
-				writeJSON(w, status, map[string]any{
-					"error":      reason,
-					"request_id": r.Header.Get("X-Request-Id"),
-				})
+				httpapi.WriteError(w, r, status, reason)
 				return
 			}
 			if strings.TrimSpace(projectID) != "" {
@@ -94,18 +86,12 @@ func (m Middleware) Wrap(next http.Handler) http.Handler {
 				if errors.Is(err, ErrForbidden) {
 					m.logDeny(r, http.StatusForbidden, "forbidden", err, "subject", identity.Subject)
 					m.auditDeny(r, identity, http.StatusForbidden, "forbidden", err)
-					writeJSON(w, http.StatusForbidden, map[string]any{
-						"error":      "forbidden",
-						"request_id": r.Header.Get("X-Request-Id"),
-					})
+					httpapi.WriteError(w, r, http.StatusForbidden, "forbidden")
 					return
 				}
 				m.logDeny(r, http.StatusForbidden, "forbidden", err, "subject", identity.Subject)
 				m.auditDeny(r, identity, http.StatusForbidden, "forbidden", err)
-				writeJSON(w, http.StatusForbidden, map[string]any{
-					"error":      "forbidden",
-					"request_id": r.Header.Get("X-Request-Id"),
-				})
+				httpapi.WriteError(w, r, http.StatusForbidden, "forbidden")
 				return
 			}
 		}
@@ -159,20 +145,20 @@ func (m Middleware) logDeny(r *http.Request, status int, reason string, err erro
 	m.Logger.Warn("auth deny", fields...)
 }
 
-func writeJSON(w http.ResponseWriter, status int, body any) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	enc := json.NewEncoder(w)
-	enc.SetEscapeHTML(true)
-	_ = enc.Encode(body)
-}
-
-func MethodRoleAuthorizer() AuthorizeFunc {
+// MethodRoleAuthorizer requires viewer for reads and editor for writes.
+// Auditors pass read-only requests for which auditorScope reports true; a nil
+// scope denies them everything.
+func MethodRoleAuthorizer(auditorScope func(*http.Request) bool) AuthorizeFunc {
 	return func(r *http.Request, identity Identity) error {
 		required := RequiredRoleForRequest(r)
 		if HasAtLeast(identity.Roles, required) {
 			return nil
 		}
+		// Reads need only viewer, so failing it above leaves auditors with no
+		// other role.
+		if auditorScope != nil && IsReadOnlyMethod(r.Method) && AuditorOnly(identity.Roles) && auditorScope(r) {
+			return nil
+		}
 		return ErrForbidden
 	}
 }
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -82,7 +83,7 @@ func TestMiddleware_Forbidden(t *testing.T) {
 	authn := &testAuthenticator{identity: Identity{Subject: "alice", Roles: []string{"viewer"}}}
 	h := Middleware{
 		Authenticator: authn,
-		Authorize:     MethodRoleAuthorizer(),
+		Authorize:     MethodRoleAuthorizer(nil),
 	}.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	}))
@@ -153,3 +154,38 @@ func TestMiddleware_AuditOnDeny(t *testing.T) {
 		t.Fatalf("RequestID=%q, want rid-4", got.RequestID)
 	}
 }
+
+func TestMethodRoleAuthorizerAuditor(t *testing.T) {
+	authorize := MethodRoleAuthorizer(func(r *http.Request) bool {
+		return strings.HasSuffix(r.URL.Path, "/status")
+	})
+	auditor := Identity{Subject: "ext-auditor", Roles: []string{RoleAuditor}}
+
+	cases := []struct {
+		method string
+		path   string
+		allow  bool
+	}{
+		{http.MethodGet, "/runs/r-1/status", true},
+		{http.MethodGet, "/runs/r-1/logs", false},
+		{http.MethodPost, "/runs/r-1/status", false},
+	}
+	for _, tc := range cases {
+		err := authorize(httptest.NewRequest(tc.method, "http://example.test"+tc.path, nil), auditor)
+		if (err == nil) != tc.allow {
+			t.Fatalf("%s %s: err=%v, want allow=%v", tc.method, tc.path, err, tc.allow)
+		}
+	}
+	if err := MethodRoleAuthorizer(nil)(httptest.NewRequest(http.MethodGet, "http://example.test/runs/r-1/status", nil), auditor); err == nil {
+		t.Fatal("expected nil scope to deny auditors")
+	}
+}
+
+func TestAuditorOnly(t *testing.T) {
+	if !AuditorOnly([]string{" Auditor "}) {
+		t.Fatal("expected auditor-only")
+	}
+	if AuditorOnly([]string{RoleAuditor, RoleViewer}) || AuditorOnly(nil) {
+		t.Fatal("expected hierarchy roles to clear auditor-only")
+	}
+}
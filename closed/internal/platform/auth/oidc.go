@@ -10,10 +10,13 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/coreos/go-oidc/v3/oidc"
 	"golang.org/x/oauth2"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
 )
 
 type OIDCService struct {
@@ -22,6 +25,7 @@ type OIDCService struct {
 	verifier     *oidc.IDTokenVerifier
 	oauth2Config oauth2.Config
 	sessions     *SessionManager
+	refreshMu    sync.Mutex
 }
 
 func NewOIDCService(ctx context.Context, cfg Config, sessions *SessionManager) (*OIDCService, error) {
@@ -31,6 +35,12 @@ func NewOIDCService(ctx context.Context, cfg Config, sessions *SessionManager) (
 	if cfg.Mode != ModeOIDC {
 		return nil, fmt.Errorf("auth mode must be oidc (got %q)", cfg.Mode)
 	}
+	if cfg.SessionMode == SessionModeCookie && sessions == nil {
+		return nil, errors.New("AUTH_SESSION_MODE=cookie requires a session store")
+	}
+	if cfg.SessionRefresh && !sessions.RefreshEnabled() {
+		return nil, errors.New("AUTH_SESSION_REFRESH requires a session store and ANIMUS_ENCRYPTION_PROVIDER")
+	}
 
 	provider, err := oidc.NewProvider(ctx, cfg.OIDCIssuerURL)
 	if err != nil {
@@ -106,17 +116,17 @@ func (s *OIDCService) LoginHandler() (http.HandlerFunc, error) {
 
 		state, err := randomBase64URL(32)
 		if err != nil {
-			writeJSON(w, http.StatusInternalServerError, map[string]any{"error": "internal_error"})
+			httpapi.WriteError(w, r, http.StatusInternalServerError, "internal_error")
 			return
 		}
 		verifier, err := randomBase64URL(32)
 		if err != nil {
-			writeJSON(w, http.StatusInternalServerError, map[string]any{"error": "internal_error"})
+			httpapi.WriteError(w, r, http.StatusInternalServerError, "internal_error")
 			return
 		}
 		nonce, err := randomBase64URL(32)
 		if err != nil {
-			writeJSON(w, http.StatusInternalServerError, map[string]any{"error": "internal_error"})
+			httpapi.WriteError(w, r, http.StatusInternalServerError, "internal_error")
 			return
 		}
 		challenge := pkceS256Challenge(verifier)
@@ -126,9 +136,13 @@ func (s *OIDCService) LoginHandler() (http.HandlerFunc, error) {
 		setShortCookie(w, "animus_oidc_nonce", nonce, s.cfg)
 		setShortCookie(w, "animus_return_to", returnTo, s.cfg)
 
+		accessType := oauth2.AccessTypeOnline
+		if s.cfg.SessionRefresh {
+			accessType = oauth2.AccessTypeOffline
+		}
 		redirectURL := s.oauth2Config.AuthCodeURL(
 			state,
-			oauth2.AccessTypeOnline,
+			accessType,
 			oauth2.SetAuthURLParam("code_challenge", challenge),
 			oauth2.SetAuthURLParam("code_challenge_method", "S256"),
 			oauth2.SetAuthURLParam("nonce", nonce),
@@ -150,13 +164,13 @@ func (s *OIDCService) CallbackHandler() (http.HandlerFunc, error) {
 		stateQuery := r.URL.Query().Get("state")
 		code := r.URL.Query().Get("code")
 		if stateQuery == "" || code == "" {
-			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "missing_code_or_state"})
+			httpapi.WriteError(w, r, http.StatusBadRequest, "missing_code_or_state")
 			return
 		}
 
 		stateCookie := tokenFromCookie(r, "animus_oidc_state")
 		if stateCookie == "" || stateCookie != stateQuery {
-			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid_state"})
+			httpapi.WriteError(w, r, http.StatusBadRequest, "invalid_state")
 			return
 		}
 
@@ -164,7 +178,7 @@ func (s *OIDCService) CallbackHandler() (http.HandlerFunc, error) {
 		nonceCookie := tokenFromCookie(r, "animus_oidc_nonce")
 		returnTo := SafeReturnTo(tokenFromCookie(r, "animus_return_to"), s.cfg)
 		if codeVerifier == "" || nonceCookie == "" {
-			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "missing_pkce_or_nonce"})
+			httpapi.WriteError(w, r, http.StatusBadRequest, "missing_pkce_or_nonce")
 			return
 		}
 
@@ -173,19 +187,19 @@ func (s *OIDCService) CallbackHandler() (http.HandlerFunc, error) {
 
 		token, err := s.oauth2Config.Exchange(exchangeCtx, code, oauth2.SetAuthURLParam("code_verifier", codeVerifier))
 		if err != nil {
-			writeJSON(w, http.StatusUnauthorized, map[string]any{"error": "token_exchange_failed"})
+			httpapi.WriteError(w, r, http.StatusUnauthorized, "token_exchange_failed")
 			return
 		}
 
 		rawIDToken, ok := token.Extra("id_token").(string)
 		if !ok || rawIDToken == "" {
-			writeJSON(w, http.StatusUnauthorized, map[string]any{"error": "missing_id_token"})
+			httpapi.WriteError(w, r, http.StatusUnauthorized, "missing_id_token")
 			return
 		}
 
 		idToken, err := s.verifier.Verify(exchangeCtx, rawIDToken)
 		if err != nil {
-			writeJSON(w, http.StatusUnauthorized, map[string]any{"error": "invalid_id_token"})
+			httpapi.WriteError(w, r, http.StatusUnauthorized, "invalid_id_token")
 			return
 		}
 
@@ -193,17 +207,17 @@ func (s *OIDCService) CallbackHandler() (http.HandlerFunc, error) {
 			Nonce string `json:"nonce"`
 		}
 		if err := idToken.Claims(&nonceClaim); err != nil {
-			writeJSON(w, http.StatusUnauthorized, map[string]any{"error": "invalid_id_token_claims"})
+			httpapi.WriteError(w, r, http.StatusUnauthorized, "invalid_id_token_claims")
 			return
 		}
 		if nonceClaim.Nonce == "" || nonceClaim.Nonce != nonceCookie {
-			writeJSON(w, http.StatusUnauthorized, map[string]any{"error": "invalid_nonce"})
+			httpapi.WriteError(w, r, http.StatusUnauthorized, "invalid_nonce")
 			return
 		}
 
 		var claims map[string]any
 		if err := idToken.Claims(&claims); err != nil {
-			writeJSON(w, http.StatusUnauthorized, map[string]any{"error": "invalid_id_token_claims"})
+			httpapi.WriteError(w, r, http.StatusUnauthorized, "invalid_id_token_claims")
 			return
 		}
 
@@ -226,13 +240,19 @@ func (s *OIDCService) CallbackHandler() (http.HandlerFunc, error) {
 			identity := identityFromClaims(s.cfg, claims)
 			session, err := s.sessions.CreateSession(r.Context(), identity, s.cfg.OIDCIssuerURL, expiresAt, TokenSHA256(rawIDToken), meta)
 			if err != nil {
-				writeJSON(w, http.StatusUnauthorized, map[string]any{"error": "session_create_failed"})
+				httpapi.WriteError(w, r, http.StatusUnauthorized, "session_create_failed")
 				return
 			}
+			if s.cfg.SessionRefresh && token.RefreshToken != "" {
+				if _, err := s.sessions.AttachRefreshToken(r.Context(), session, token.RefreshToken); err != nil {
+					httpapi.WriteError(w, r, http.StatusUnauthorized, "session_create_failed")
+					return
+				}
+			}
 			sessionID = session.SessionID
 		}
 
-		setSessionCookie(w, s.cfg.SessionCookieName, sessionID, s.cfg)
+		s.setSessionCookies(w, sessionID)
 		clearCookie(w, "animus_oidc_state", s.cfg)
 		clearCookie(w, "animus_oidc_verifier", s.cfg)
 		clearCookie(w, "animus_oidc_nonce", s.cfg)
@@ -255,7 +275,10 @@ func (s *OIDCService) LogoutHandler() http.HandlerFunc {
 			}
 		}
 		clearCookie(w, s.cfg.SessionCookieName, s.cfg)
-		writeJSON(w, http.StatusOK, map[string]any{"status": "ok"})
+		if s.cfg.CookieSessions() {
+			clearCookie(w, CSRFCookieName, s.cfg)
+		}
+		httpapi.WriteJSON(w, http.StatusOK, map[string]any{"status": "ok"})
 	}
 }
 
@@ -264,13 +287,13 @@ func (s *OIDCService) SessionHandler() http.HandlerFunc {
 		identity, err := s.Authenticate(r.Context(), r)
 		if err != nil {
 			if errors.Is(err, ErrUnauthenticated) {
-				writeJSON(w, http.StatusUnauthorized, map[string]any{"error": "unauthorized"})
+				httpapi.WriteError(w, r, http.StatusUnauthorized, "unauthorized")
 				return
 			}
-			writeJSON(w, http.StatusUnauthorized, map[string]any{"error": "invalid_token"})
+			httpapi.WriteError(w, r, http.StatusUnauthorized, "invalid_token")
 			return
 		}
-		writeJSON(w, http.StatusOK, map[string]any{
+		httpapi.WriteJSON(w, http.StatusOK, map[string]any{
 			"subject": identity.Subject,
 			"email":   identity.Email,
 			"roles":   identity.Roles,
@@ -458,7 +481,7 @@ func resolveRolesFromClaims(cfg Config, claims map[string]any) []string {
 	}
 	groups := extractRolesClaim(claims, cfg.GroupsClaim)
 	mapped := mapGroupsToRoles(groups, cfg.GroupRoleMap)
-	return mergeUnique(roles, groups, mapped)
+	return mergeUnique(roles, groups, mapped, mapClaimsToRoles(claims, cfg.ClaimRoleRules))
 }
 
 func mapGroupsToRoles(groups []string, mapping map[string]string) []string {
@@ -523,11 +546,7 @@ func extractSubjectClaim(claims map[string]any) string {
 }
 
 func extractRolesClaim(claims map[string]any, key string) []string {
-	v, ok := claims[key]
-	if !ok {
-		return nil
-	}
-	return extractRolesValue(v)
+	return extractRolesValue(lookupClaim(claims, key))
 }
 
 func extractRealmAccessRoles(claims map[string]any) []string {
@@ -30,6 +30,10 @@ type oidcTestTransport struct {
 	email    string
 	roles    []string
 	expiry   time.Time
+
+	refreshToken  string
+	refreshGrants []string
+	refreshFails  bool
 }
 
 func (t *oidcTestTransport) RoundTrip(req *http.Request) (*http.Response, error) {
@@ -49,16 +53,26 @@ func (t *oidcTestTransport) RoundTrip(req *http.Request) (*http.Response, error)
 		}
 		return jsonResponse(req, http.StatusOK, json.RawMessage(jwks))
 	case "/token":
+		if err := req.ParseForm(); err == nil && req.PostForm.Get("grant_type") == "refresh_token" {
+			t.refreshGrants = append(t.refreshGrants, req.PostForm.Get("refresh_token"))
+			if t.refreshFails {
+				return jsonResponse(req, http.StatusBadRequest, map[string]any{"error": "invalid_grant"})
+			}
+		}
 		idToken, err := t.signedIDToken()
 		if err != nil {
 			return jsonResponse(req, http.StatusInternalServerError, map[string]any{"error": "token_failed"})
 		}
-		return jsonResponse(req, http.StatusOK, map[string]any{
+		payload := map[string]any{
 			"access_token": "access-token",
 			"token_type":   "Bearer",
 			"expires_in":   3600,
 			"id_token":     idToken,
-		})
+		}
+		if t.refreshToken != "" {
+			payload["refresh_token"] = t.refreshToken
+		}
+		return jsonResponse(req, http.StatusOK, payload)
 	default:
 		return jsonResponse(req, http.StatusNotFound, map[string]any{"error": "not_found"})
 	}
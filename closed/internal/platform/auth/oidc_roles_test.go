@@ -42,3 +42,35 @@ func TestResolveRolesFromClaimsFallsBackToRealmAccess(t *testing.T) {
 		t.Fatalf("expected realm_access roles, got %v", roles)
 	}
 }
+
+func TestResolveRolesFromClaimsNestedClaimAndRules(t *testing.T) {
+	cfg := Config{
+		RolesClaim:  "resource_access.animus.roles",
+		GroupsClaim: "groups",
+		ClaimRoleRules: []ClaimRoleRule{
+			{Claim: "groups", Value: "/ml/admins", Role: "admin"},
+			{Claim: "groups", Value: "/ml/*", Role: "viewer"},
+			{Issuer: "https://other.test/realms/ml", Claim: "groups", Value: "/ml/*", Role: "editor"},
+			{Claim: "https://animus.dev/auditor", Value: "true", Role: "auditor"},
+		},
+	}
+	claims := map[string]any{
+		"iss":                        "https://keycloak.test/realms/ml/",
+		"resource_access":            map[string]any{"animus": map[string]any{"roles": []any{"Editor"}}},
+		"groups":                     []any{"/ml/admins"},
+		"https://animus.dev/auditor": true,
+	}
+
+	roles := resolveRolesFromClaims(cfg, claims)
+	for _, want := range []string{"editor", "admin", "viewer", "auditor"} {
+		if !contains(roles, want) {
+			t.Fatalf("expected %s role, got %v", want, roles)
+		}
+	}
+
+	cfg.ClaimRoleRules = cfg.ClaimRoleRules[2:3]
+	roles = resolveRolesFromClaims(cfg, map[string]any{"iss": "https://keycloak.test/realms/ml", "groups": []any{"/ml/team"}})
+	if contains(roles, "editor") {
+		t.Fatalf("rule for another issuer applied: %v", roles)
+	}
+}
@@ -0,0 +1,266 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/domain"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+)
+
+// ErrInvalidCredentials is returned by a PasswordVerifier for an unknown user
+// or a wrong password; the two are deliberately indistinguishable.
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// PasswordVerifier checks a username and password and returns the identity
+// they belong to.
+type PasswordVerifier interface {
+	Verify(ctx context.Context, username, password string) (Identity, error)
+}
+
+// PasswordService serves the password grant for AUTH_MODE=ldap|static. A
+// successful login creates a server-side session whose ID is both the
+// session cookie and the bearer token returned to the client. Repeated
+// failures lock the username and the client IP out for
+// AUTH_LOGIN_LOCKOUT.
+type PasswordService struct {
+	cfg      Config
+	verifier PasswordVerifier
+	sessions *SessionManager
+	limiter  *loginLimiter
+}
+
+func NewPasswordService(cfg Config, verifier PasswordVerifier, sessions *SessionManager) (*PasswordService, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg.Mode != ModeLDAP && cfg.Mode != ModeStatic {
+		return nil, fmt.Errorf("auth mode must be ldap or static (got %q)", cfg.Mode)
+	}
+	if verifier == nil {
+		return nil, errors.New("password verifier is required")
+	}
+	if sessions == nil || sessions.Store == nil {
+		return nil, errors.New("password login requires a session store")
+	}
+	return &PasswordService{
+		cfg:      cfg,
+		verifier: verifier,
+		sessions: sessions,
+		limiter:  newLoginLimiter(cfg, sessions.now),
+	}, nil
+}
+
+// NewPasswordVerifier builds the verifier selected by cfg.Mode.
+func NewPasswordVerifier(cfg Config) (PasswordVerifier, error) {
+	switch cfg.Mode {
+	case ModeStatic:
+		return LoadStaticUsers(cfg.StaticUsersFile, cfg.GroupRoleMap)
+	case ModeLDAP:
+		return NewLDAPVerifier(cfg)
+	default:
+		return nil, fmt.Errorf("auth mode %q has no password verifier", cfg.Mode)
+	}
+}
+
+func (s *PasswordService) Authenticate(ctx context.Context, r *http.Request) (Identity, error) {
+	sessionID := tokenFromHeader(r)
+	if sessionID == "" {
+		sessionID = tokenFromCookie(r, s.cfg.SessionCookieName)
+	}
+	if sessionID == "" {
+		return Identity{}, ErrUnauthenticated
+	}
+	session, err := s.sessions.GetSession(ctx, sessionID)
+	if err != nil {
+		return Identity{}, ErrUnauthenticated
+	}
+	return Identity{
+		Subject: session.Subject,
+		Email:   session.Email,
+		Roles:   session.Roles,
+	}, nil
+}
+
+type passwordGrantRequest struct {
+	GrantType string `json:"grant_type"`
+	Username  string `json:"username"`
+	Password  string `json:"password"`
+}
+
+var loginPage = template.Must(template.New("login").Parse(`<!doctype html>
+<html><head><meta charset="utf-8"><title>Animus sign in</title></head>
+<body><form method="post" action="/auth/login">
+{{if .Locked}}<p>Too many failed sign-in attempts. Try again later.</p>{{else if .Failed}}<p>Invalid username or password.</p>{{end}}
+<input type="hidden" name="return_to" value="{{.ReturnTo}}">
+<label>Username <input name="username" autocomplete="username" required autofocus></label>
+<label>Password <input name="password" type="password" autocomplete="current-password" required></label>
+<button type="submit">Sign in</button>
+</form></body></html>
+`))
+
+// LoginHandler serves /auth/login. GET renders a plain sign-in form for the
+// console. POST takes JSON or form-encoded {grant_type: password, username,
+// password} (grant_type may be omitted) and answers with the session token;
+// a form post carrying return_to is redirected there instead. A locked-out
+// username or client IP gets 429 before the verifier is consulted.
+func (s *PasswordService) LoginHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Header().Set("Cache-Control", "no-store")
+			_ = loginPage.Execute(w, map[string]any{
+				"ReturnTo": SafeReturnTo(r.URL.Query().Get("return_to"), s.cfg),
+				"Failed":   r.URL.Query().Get("error") != "",
+				"Locked":   r.URL.Query().Get("error") == "locked",
+			})
+			return
+		case http.MethodPost:
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			httpapi.WriteError(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
+			return
+		}
+		var req passwordGrantRequest
+		returnTo := ""
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+			if err := r.ParseForm(); err != nil {
+				httpapi.WriteError(w, r, http.StatusBadRequest, "invalid_request")
+				return
+			}
+			req = passwordGrantRequest{
+				GrantType: r.PostForm.Get("grant_type"),
+				Username:  r.PostForm.Get("username"),
+				Password:  r.PostForm.Get("password"),
+			}
+			if raw := r.PostForm.Get("return_to"); raw != "" {
+				returnTo = SafeReturnTo(raw, s.cfg)
+			}
+		} else if err := httpapi.DecodeJSON(r, &req); err != nil {
+			httpapi.WriteDecodeError(w, r, err)
+			return
+		}
+		if req.GrantType != "" && req.GrantType != "password" {
+			httpapi.WriteError(w, r, http.StatusBadRequest, "unsupported_grant_type")
+			return
+		}
+		username := strings.TrimSpace(req.Username)
+		if username == "" || req.Password == "" {
+			httpapi.WriteError(w, r, http.StatusBadRequest, "username_and_password_required")
+			return
+		}
+
+		meta := SessionRequestMeta{
+			RequestID: r.Header.Get("X-Request-Id"),
+			UserAgent: r.UserAgent(),
+			RemoteIP:  ParseRemoteIP(r.RemoteAddr),
+			Actor:     username,
+		}
+		if wait, ok := s.limiter.Allow(username, meta.RemoteIP); !ok {
+			s.auditLoginFailed(r.Context(), username, "rate_limited", meta)
+			if returnTo != "" {
+				http.Redirect(w, r, "/auth/login?error=locked&return_to="+url.QueryEscape(returnTo), http.StatusSeeOther)
+				return
+			}
+			w.Header().Set("Retry-After", strconv.Itoa(int((wait+time.Second-1)/time.Second)))
+			httpapi.WriteError(w, r, http.StatusTooManyRequests, "too_many_login_attempts")
+			return
+		}
+		identity, err := s.verifier.Verify(r.Context(), username, req.Password)
+		if err != nil {
+			reason := "invalid_credentials"
+			if !errors.Is(err, ErrInvalidCredentials) {
+				reason = "verifier_error"
+			} else {
+				s.limiter.Fail(username, meta.RemoteIP)
+			}
+			s.auditLoginFailed(r.Context(), username, reason, meta)
+			if returnTo != "" && reason == "invalid_credentials" {
+				http.Redirect(w, r, "/auth/login?error=1&return_to="+url.QueryEscape(returnTo), http.StatusSeeOther)
+				return
+			}
+			if reason == "verifier_error" {
+				httpapi.WriteError(w, r, http.StatusServiceUnavailable, "login_unavailable")
+				return
+			}
+			httpapi.WriteError(w, r, http.StatusUnauthorized, "invalid_credentials")
+			return
+		}
+
+		expiresAt := s.sessions.now().Add(s.cfg.SessionCookieMaxAge)
+		session, err := s.sessions.CreateSession(r.Context(), identity, string(s.cfg.Mode), expiresAt, "", meta)
+		if err != nil {
+			httpapi.WriteError(w, r, http.StatusInternalServerError, "session_create_failed")
+			return
+		}
+		s.limiter.Succeed(username)
+		setSessionCookie(w, s.cfg.SessionCookieName, session.SessionID, s.cfg)
+		if s.cfg.CookieSessions() {
+			setCSRFCookie(w, CSRFToken(session.SessionID), s.cfg)
+		}
+		if returnTo != "" {
+			http.Redirect(w, r, returnTo, http.StatusSeeOther)
+			return
+		}
+		w.Header().Set("Cache-Control", "no-store")
+		httpapi.WriteJSON(w, http.StatusOK, map[string]any{
+			"access_token": session.SessionID,
+			"token_type":   "Bearer",
+			"expires_in":   int(time.Until(session.ExpiresAt).Seconds()),
+			"expires_at":   session.ExpiresAt,
+			"subject":      identity.Subject,
+			"email":        identity.Email,
+			"roles":        identity.Roles,
+		})
+	}
+}
+
+func (s *PasswordService) LogoutHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sessionID := tokenFromCookie(r, s.cfg.SessionCookieName)
+		if sessionID == "" {
+			sessionID = tokenFromHeader(r)
+		}
+		if sessionID != "" {
+			_, _ = s.sessions.RevokeSession(r.Context(), sessionID, "user", "logout", SessionRequestMeta{
+				RequestID: r.Header.Get("X-Request-Id"),
+				UserAgent: r.UserAgent(),
+				RemoteIP:  ParseRemoteIP(r.RemoteAddr),
+			})
+		}
+		clearCookie(w, s.cfg.SessionCookieName, s.cfg)
+		clearCookie(w, CSRFCookieName, s.cfg)
+		httpapi.WriteJSON(w, http.StatusOK, map[string]any{"status": "ok"})
+	}
+}
+
+// auditLoginFailed records a rejected password grant. The password is never
+// part of the payload.
+func (s *PasswordService) auditLoginFailed(ctx context.Context, username, reason string, meta SessionRequestMeta) {
+	if s.sessions.Audit == nil {
+		return
+	}
+	_, _ = s.sessions.Audit.Append(ctx, domain.AuditEvent{
+		OccurredAt:   s.sessions.now(),
+		Actor:        username,
+		Action:       "auth.login_failed",
+		ResourceType: "user",
+		ResourceID:   username,
+		RequestID:    strings.TrimSpace(meta.RequestID),
+		IP:           parseIP(meta.RemoteIP),
+		UserAgent:    strings.TrimSpace(meta.UserAgent),
+		Payload: domain.Metadata{
+			"username": username,
+			"mode":     string(s.cfg.Mode),
+			"reason":   reason,
+		},
+	})
+}
@@ -0,0 +1,229 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func staticUsersFixture(t *testing.T) *StaticUsers {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte("s3cret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	users, err := ParseStaticUsers([]byte(`
+users:
+  - username: Alice
+    password_hash: `+string(hash)+`
+    email: alice@lab.local
+    roles: [editor]
+    groups: [ML-Admins]
+`), map[string]string{"ml-admins": RoleAdmin})
+	if err != nil {
+		t.Fatalf("ParseStaticUsers: %v", err)
+	}
+	return users
+}
+
+func TestStaticUsersVerify(t *testing.T) {
+	users := staticUsersFixture(t)
+	identity, err := users.Verify(context.Background(), "alice", "s3cret")
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if identity.Subject != "alice" || identity.Email != "alice@lab.local" {
+		t.Fatalf("identity=%+v", identity)
+	}
+	for _, want := range []string{"editor", "ml-admins", "admin"} {
+		if !contains(identity.Roles, want) {
+			t.Fatalf("roles=%v, want %s", identity.Roles, want)
+		}
+	}
+	for _, creds := range [][2]string{{"alice", "wrong"}, {"bob", "s3cret"}} {
+		if _, err := users.Verify(context.Background(), creds[0], creds[1]); !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("%v: err=%v", creds, err)
+		}
+	}
+
+	for _, raw := range []string{
+		"users: []",
+		"users:\n  - username: a\n    password_hash: plain\n",
+		"users:\n  - username: ''\n    password_hash: x\n",
+	} {
+		if _, err := ParseStaticUsers([]byte(raw), nil); err == nil {
+			t.Errorf("%q: expected error", raw)
+		}
+	}
+}
+
+func TestPasswordLogin(t *testing.T) {
+	cfg := Config{
+		Mode:                  ModeStatic,
+		RolesClaim:            "roles",
+		EmailClaim:            "email",
+		SessionCookieName:     "animus_session",
+		SessionCookieMaxAge:   time.Hour,
+		SessionCookieSameSite: "Lax",
+		StaticUsersFile:       "/etc/animus/users.yaml",
+	}
+	store := &stubSessionStore{}
+	audit := &recordingAuditAppender{}
+	svc, err := NewPasswordService(cfg, staticUsersFixture(t), &SessionManager{Store: store, Audit: audit})
+	if err != nil {
+		t.Fatalf("NewPasswordService: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(`{"grant_type":"password","username":"alice","password":"wrong"}`))
+	svc.LoginHandler()(rec, req)
+	if rec.Code != http.StatusUnauthorized || !strings.Contains(rec.Body.String(), "invalid_credentials") {
+		t.Fatalf("wrong password: status=%d body=%s", rec.Code, rec.Body)
+	}
+	if audit.count("auth.login_failed") != 1 || audit.events[0].Payload["password"] != nil {
+		t.Fatalf("audit=%+v", audit.events)
+	}
+
+	form := url.Values{"grant_type": {"password"}, "username": {"alice"}, "password": {"s3cret"}}
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	svc.LoginHandler()(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("login: status=%d body=%s", rec.Code, rec.Body)
+	}
+	var body struct {
+		AccessToken string   `json:"access_token"`
+		TokenType   string   `json:"token_type"`
+		Roles       []string `json:"roles"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.AccessToken == "" || body.AccessToken != store.record.SessionID || body.TokenType != "Bearer" || !contains(body.Roles, "admin") {
+		t.Fatalf("body=%s", rec.Body)
+	}
+	if cookieValue(rec.Result().Cookies(), "animus_session") != body.AccessToken {
+		t.Fatal("session cookie not set")
+	}
+
+	store.record.ExpiresAt = time.Now().Add(time.Hour)
+	req = httptest.NewRequest(http.MethodGet, "/api/auth/me", nil)
+	req.Header.Set("Authorization", "Bearer "+body.AccessToken)
+	identity, err := svc.Authenticate(context.Background(), req)
+	if err != nil || identity.Subject != "alice" {
+		t.Fatalf("Authenticate: identity=%+v err=%v", identity, err)
+	}
+
+	rec = httptest.NewRecorder()
+	svc.LoginHandler()(rec, httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(`{"grant_type":"client_credentials"}`)))
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "unsupported_grant_type") {
+		t.Fatalf("grant type: status=%d body=%s", rec.Code, rec.Body)
+	}
+}
+
+func TestPasswordLoginForm(t *testing.T) {
+	cfg := Config{
+		Mode:                  ModeStatic,
+		RolesClaim:            "roles",
+		EmailClaim:            "email",
+		SessionCookieName:     "animus_session",
+		SessionCookieMaxAge:   time.Hour,
+		SessionCookieSameSite: "Lax",
+		StaticUsersFile:       "/etc/animus/users.yaml",
+	}
+	svc, err := NewPasswordService(cfg, staticUsersFixture(t), &SessionManager{Store: &stubSessionStore{}})
+	if err != nil {
+		t.Fatalf("NewPasswordService: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	svc.LoginHandler()(rec, httptest.NewRequest(http.MethodGet, "/auth/login?return_to=%2Fconsole%2Fruns%22%3E", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `value="/console/runs&#34;&gt;"`) {
+		t.Fatalf("form: status=%d body=%s", rec.Code, rec.Body)
+	}
+
+	post := func(password string) *httptest.ResponseRecorder {
+		form := url.Values{"username": {"alice"}, "password": {password}, "return_to": {"https://evil.test/x"}}
+		req := httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		svc.LoginHandler()(rec, req)
+		return rec
+	}
+	if rec := post("s3cret"); rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/console" {
+		t.Fatalf("form login: status=%d location=%q", rec.Code, rec.Header().Get("Location"))
+	}
+	if rec := post("wrong"); rec.Code != http.StatusSeeOther || !strings.HasPrefix(rec.Header().Get("Location"), "/auth/login?error=1") {
+		t.Fatalf("form failure: status=%d location=%q", rec.Code, rec.Header().Get("Location"))
+	}
+}
+
+func TestPasswordLoginRateLimit(t *testing.T) {
+	cfg := Config{
+		Mode:                  ModeStatic,
+		RolesClaim:            "roles",
+		EmailClaim:            "email",
+		SessionCookieName:     "animus_session",
+		SessionCookieMaxAge:   time.Hour,
+		SessionCookieSameSite: "Lax",
+		StaticUsersFile:       "/etc/animus/users.yaml",
+		LoginMaxFailures:      2,
+		LoginMaxFailuresPerIP: 3,
+		LoginLockout:          time.Minute,
+	}
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	audit := &recordingAuditAppender{}
+	svc, err := NewPasswordService(cfg, staticUsersFixture(t), &SessionManager{
+		Store: &stubSessionStore{},
+		Audit: audit,
+		Now:   func() time.Time { return now },
+	})
+	if err != nil {
+		t.Fatalf("NewPasswordService: %v", err)
+	}
+	login := func(username, password, remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(`{"username":"`+username+`","password":"`+password+`"}`))
+		req.RemoteAddr = remoteAddr
+		rec := httptest.NewRecorder()
+		svc.LoginHandler()(rec, req)
+		return rec
+	}
+
+	for i := 0; i < 2; i++ {
+		if rec := login("alice", "wrong", "10.0.0.1:1000"); rec.Code != http.StatusUnauthorized {
+			t.Fatalf("attempt %d: status=%d", i, rec.Code)
+		}
+	}
+	rec := login("Alice", "s3cret", "10.0.0.2:1000")
+	if rec.Code != http.StatusTooManyRequests || !strings.Contains(rec.Body.String(), "too_many_login_attempts") {
+		t.Fatalf("locked username: status=%d body=%s", rec.Code, rec.Body)
+	}
+	if rec.Header().Get("Retry-After") != "60" {
+		t.Fatalf("Retry-After=%q", rec.Header().Get("Retry-After"))
+	}
+	if audit.events[len(audit.events)-1].Payload["reason"] != "rate_limited" {
+		t.Fatalf("audit=%+v", audit.events)
+	}
+
+	// The IP limit applies across usernames.
+	if rec := login("bob", "x", "10.0.0.1:1000"); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("third failure from ip: status=%d", rec.Code)
+	}
+	if rec := login("carol", "x", "10.0.0.1:1000"); rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("locked ip: status=%d", rec.Code)
+	}
+
+	now = now.Add(time.Minute)
+	if rec := login("alice", "s3cret", "10.0.0.1:1000"); rec.Code != http.StatusOK {
+		t.Fatalf("after lockout: status=%d body=%s", rec.Code, rec.Body)
+	}
+}
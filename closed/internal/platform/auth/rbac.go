@@ -12,6 +12,10 @@ const (
 	RoleViewer = "viewer"
 	RoleEditor = "editor"
 	RoleAdmin  = "admin"
+
+	// RoleAuditor is outside the viewer/editor/admin hierarchy: it only grants
+	// read access to the routes each service exposes to auditors.
+	RoleAuditor = "auditor"
 )
 
 var roleLevels = map[string]int{
@@ -35,6 +39,33 @@ func HasAtLeast(roles []string, required string) bool {
 	return maxLevel >= requiredLevel
 }
 
+// AuditorOnly reports whether roles hold the auditor role and nothing from the
+// viewer/editor/admin hierarchy. Handlers serving raw data use it as a second
+// check behind the authorizer.
+func AuditorOnly(roles []string) bool {
+	auditor := false
+	for _, role := range roles {
+		role = strings.ToLower(strings.TrimSpace(role))
+		if roleLevels[role] > 0 {
+			return false
+		}
+		if role == RoleAuditor {
+			auditor = true
+		}
+	}
+	return auditor
+}
+
+// IsReadOnlyMethod reports whether method never mutates state.
+func IsReadOnlyMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
+
 func RequiredRoleForRequest(r *http.Request) string {
 	switch r.Method {
 	case http.MethodGet, http.MethodHead, http.MethodOptions:
@@ -0,0 +1,242 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/envelope"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+)
+
+var (
+	errNoRefreshToken       = errors.New("session has no refresh token")
+	errSessionRefreshFailed = errors.New("session refresh failed")
+)
+
+// RefreshEnabled reports whether refresh tokens can be stored: they are only
+// ever persisted encrypted.
+func (m *SessionManager) RefreshEnabled() bool {
+	return m != nil && m.Store != nil && m.RefreshTokens.Enabled()
+}
+
+// AttachRefreshToken stores the refresh token issued at login.
+func (m *SessionManager) AttachRefreshToken(ctx context.Context, record repo.SessionRecord, refreshToken string) (repo.SessionRecord, error) {
+	return m.updateRefresh(ctx, record, record.ExpiresAt, refreshToken)
+}
+
+// RenewSession extends a session after a successful refresh grant and
+// replaces its refresh token with the (possibly rotated) one.
+func (m *SessionManager) RenewSession(ctx context.Context, record repo.SessionRecord, expiresAt time.Time, refreshToken string, meta SessionRequestMeta) (repo.SessionRecord, error) {
+	renewed, err := m.updateRefresh(ctx, record, expiresAt, refreshToken)
+	if err != nil {
+		return repo.SessionRecord{}, err
+	}
+	m.auditEvent(ctx, renewed, "auth.session_refreshed", meta, "")
+	return renewed, nil
+}
+
+func (m *SessionManager) updateRefresh(ctx context.Context, record repo.SessionRecord, expiresAt time.Time, refreshToken string) (repo.SessionRecord, error) {
+	if !m.RefreshEnabled() {
+		return repo.SessionRecord{}, errors.New("refresh tokens require a session store and an encryption provider")
+	}
+	sealed, err := m.sealRefreshToken(ctx, refreshToken)
+	if err != nil {
+		return repo.SessionRecord{}, err
+	}
+	now := m.now()
+	updated, err := m.Store.UpdateRefresh(ctx, record.SessionID, expiresAt, sealed, now)
+	if err != nil {
+		return repo.SessionRecord{}, err
+	}
+	if !updated {
+		return repo.SessionRecord{}, ErrUnauthenticated
+	}
+	record.ExpiresAt = expiresAt.UTC()
+	record.RefreshToken = sealed
+	record.RefreshedAt = &now
+	return record, nil
+}
+
+func (m *SessionManager) sealRefreshToken(ctx context.Context, refreshToken string) (repo.SealedRefreshToken, error) {
+	key, err := m.RefreshTokens.NewObjectKey(ctx)
+	if err != nil {
+		return repo.SealedRefreshToken{}, fmt.Errorf("refresh token key: %w", err)
+	}
+	ciphertext, err := envelope.Seal(key.DataKey, []byte(refreshToken))
+	if err != nil {
+		return repo.SealedRefreshToken{}, fmt.Errorf("seal refresh token: %w", err)
+	}
+	return repo.SealedRefreshToken{
+		Ciphertext: ciphertext,
+		Algorithm:  key.Algorithm,
+		KeyID:      key.KeyID,
+		WrappedKey: key.WrappedKey,
+	}, nil
+}
+
+func (m *SessionManager) openRefreshToken(ctx context.Context, sealed repo.SealedRefreshToken) (string, error) {
+	if len(sealed.Ciphertext) == 0 {
+		return "", errNoRefreshToken
+	}
+	dataKey, err := m.RefreshTokens.DataKey(ctx, sealed.Algorithm, sealed.KeyID, sealed.WrappedKey)
+	if err != nil {
+		return "", fmt.Errorf("refresh token key: %w", err)
+	}
+	plaintext, err := envelope.Open(dataKey, sealed.Ciphertext)
+	if err != nil {
+		return "", fmt.Errorf("open refresh token: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+func (m *SessionManager) now() time.Time {
+	if m.Now != nil {
+		return m.Now().UTC()
+	}
+	return time.Now().UTC()
+}
+
+// RefreshHandler serves POST /auth/refresh: it redeems the session's refresh
+// token at the provider, extends the session and re-issues its cookies.
+func (s *OIDCService) RefreshHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			httpapi.WriteError(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
+			return
+		}
+		sessionID := tokenFromCookie(r, s.cfg.SessionCookieName)
+		if sessionID == "" || !s.sessions.RefreshEnabled() {
+			httpapi.WriteError(w, r, http.StatusUnauthorized, "unauthenticated")
+			return
+		}
+		record, err := s.sessions.GetSession(r.Context(), sessionID)
+		if err != nil {
+			httpapi.WriteError(w, r, http.StatusUnauthorized, "unauthenticated")
+			return
+		}
+		if len(record.RefreshToken.Ciphertext) == 0 {
+			httpapi.WriteError(w, r, http.StatusConflict, "session_refresh_unavailable")
+			return
+		}
+		renewed, err := s.refreshSession(r, record)
+		if err != nil {
+			httpapi.WriteError(w, r, http.StatusUnauthorized, "session_refresh_failed")
+			return
+		}
+		s.setSessionCookies(w, renewed.SessionID)
+		w.Header().Set("Cache-Control", "no-store")
+		httpapi.WriteJSON(w, http.StatusOK, map[string]any{
+			"expires_at":   renewed.ExpiresAt,
+			"refreshed_at": renewed.RefreshedAt,
+		})
+	}
+}
+
+// RenewSessions transparently refreshes cookie-authenticated sessions on /api
+// calls once they are within SessionRefreshWindow of expiry. A failed
+// refresh is not an error: the session stays valid until it expires. With
+// refresh disabled next is returned unchanged.
+func (s *OIDCService) RenewSessions(next http.Handler) http.Handler {
+	if !s.cfg.SessionRefresh || !s.sessions.RefreshEnabled() {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/api/") && tokenFromHeader(r) == "" {
+			if sessionID := tokenFromCookie(r, s.cfg.SessionCookieName); sessionID != "" {
+				if renewed, ok := s.renewIfExpiring(r, sessionID); ok {
+					s.setSessionCookies(w, renewed.SessionID)
+				}
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *OIDCService) renewIfExpiring(r *http.Request, sessionID string) (repo.SessionRecord, bool) {
+	if _, ok := s.expiringSession(r.Context(), sessionID); !ok {
+		return repo.SessionRecord{}, false
+	}
+	// Serialize renewals and re-read under the lock: concurrent requests of
+	// one session must not redeem a rotating refresh token twice.
+	s.refreshMu.Lock()
+	defer s.refreshMu.Unlock()
+	record, ok := s.expiringSession(r.Context(), sessionID)
+	if !ok {
+		return repo.SessionRecord{}, false
+	}
+	renewed, err := s.refreshSession(r, record)
+	if err != nil {
+		return repo.SessionRecord{}, false
+	}
+	return renewed, true
+}
+
+func (s *OIDCService) expiringSession(ctx context.Context, sessionID string) (repo.SessionRecord, bool) {
+	record, err := s.sessions.Store.Get(ctx, sessionID)
+	if err != nil || record.RevokedAt != nil || len(record.RefreshToken.Ciphertext) == 0 {
+		return repo.SessionRecord{}, false
+	}
+	remaining := record.ExpiresAt.Sub(s.sessions.now())
+	if remaining <= 0 || remaining > s.cfg.SessionRefreshWindow {
+		return repo.SessionRecord{}, false
+	}
+	return record, true
+}
+
+// refreshSession redeems the session's refresh token. The new expiry is
+// bounded by AUTH_SESSION_MAX_AGE_SECONDS and by the refreshed ID token (or
+// access token when the provider returns no ID token on refresh); the ID
+// token must still name the session's subject.
+func (s *OIDCService) refreshSession(r *http.Request, record repo.SessionRecord) (repo.SessionRecord, error) {
+	refreshToken, err := s.sessions.openRefreshToken(r.Context(), record.RefreshToken)
+	if err != nil {
+		return repo.SessionRecord{}, err
+	}
+
+	exchangeCtx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	token, err := s.oauth2Config.TokenSource(exchangeCtx, &oauth2.Token{RefreshToken: refreshToken}).Token()
+	if err != nil {
+		return repo.SessionRecord{}, fmt.Errorf("%w: %v", errSessionRefreshFailed, err)
+	}
+
+	expiresAt := s.sessions.now().Add(s.cfg.SessionCookieMaxAge)
+	if rawIDToken, ok := token.Extra("id_token").(string); ok && rawIDToken != "" {
+		idToken, err := s.verifier.Verify(exchangeCtx, rawIDToken)
+		if err != nil {
+			return repo.SessionRecord{}, fmt.Errorf("%w: %v", errSessionRefreshFailed, err)
+		}
+		if idToken.Subject != record.Subject {
+			return repo.SessionRecord{}, fmt.Errorf("%w: subject changed", errSessionRefreshFailed)
+		}
+		if !idToken.Expiry.IsZero() && idToken.Expiry.Before(expiresAt) {
+			expiresAt = idToken.Expiry.UTC()
+		}
+	} else if !token.Expiry.IsZero() && token.Expiry.Before(expiresAt) {
+		expiresAt = token.Expiry.UTC()
+	}
+
+	return s.sessions.RenewSession(r.Context(), record, expiresAt, token.RefreshToken, SessionRequestMeta{
+		RequestID: r.Header.Get("X-Request-Id"),
+		UserAgent: r.UserAgent(),
+		RemoteIP:  ParseRemoteIP(r.RemoteAddr),
+	})
+}
+
+// setSessionCookies re-issues the session cookie (and the CSRF cookie in
+// cookie mode) so their lifetime covers the extended session.
+func (s *OIDCService) setSessionCookies(w http.ResponseWriter, sessionID string) {
+	setSessionCookie(w, s.cfg.SessionCookieName, sessionID, s.cfg)
+	if s.cfg.SessionMode == SessionModeCookie {
+		setCSRFCookie(w, CSRFToken(sessionID), s.cfg)
+	}
+}
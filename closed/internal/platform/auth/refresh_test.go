@@ -0,0 +1,184 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/envelope"
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+)
+
+func refreshTestService(t *testing.T) (*OIDCService, *oidcTestTransport, *stubSessionStore, *http.Client) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	issuer := "https://issuer.test"
+	transport := &oidcTestTransport{
+		issuer:       issuer,
+		clientID:     "client-1",
+		key:          key,
+		keyID:        "kid-1",
+		email:        "user@example.com",
+		roles:        []string{"viewer"},
+		expiry:       time.Now().UTC().Add(15 * time.Minute).Truncate(time.Second),
+		refreshToken: "refresh-2",
+	}
+	client := &http.Client{Transport: transport}
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, client)
+
+	wrapper, err := envelope.NewLocalKeyWrapper("k1", map[string][]byte{"k1": bytes.Repeat([]byte{7}, 32)})
+	if err != nil {
+		t.Fatalf("key wrapper: %v", err)
+	}
+	store := &stubSessionStore{}
+	sessions := &SessionManager{Store: store, RefreshTokens: envelope.NewWithWrapper(wrapper)}
+	svc, err := NewOIDCService(ctx, Config{
+		Mode:                  ModeOIDC,
+		RolesClaim:            "roles",
+		EmailClaim:            "email",
+		SessionCookieName:     "animus_session",
+		SessionCookieMaxAge:   time.Hour,
+		SessionCookieSameSite: "Lax",
+		SessionRefresh:        true,
+		SessionRefreshWindow:  5 * time.Minute,
+		OIDCIssuerURL:         issuer,
+		OIDCClientID:          "client-1",
+		OIDCClientSecret:      "secret",
+		OIDCRedirectURL:       "https://gateway.test/auth/callback",
+	}, sessions)
+	if err != nil {
+		t.Fatalf("NewOIDCService: %v", err)
+	}
+
+	store.record = repo.SessionRecord{SessionID: "sess-1", Subject: "user-1", ExpiresAt: time.Now().UTC().Add(2 * time.Minute)}
+	if _, err := sessions.AttachRefreshToken(ctx, store.record, "refresh-1"); err != nil {
+		t.Fatalf("AttachRefreshToken: %v", err)
+	}
+	if bytes.Contains(store.record.RefreshToken.Ciphertext, []byte("refresh-1")) {
+		t.Fatal("refresh token stored in plaintext")
+	}
+	return svc, transport, store, client
+}
+
+func refreshTestRequest(client *http.Client, method, path string) *http.Request {
+	req := httptest.NewRequest(method, path, nil)
+	req = req.WithContext(context.WithValue(req.Context(), oauth2.HTTPClient, client))
+	req.AddCookie(&http.Cookie{Name: "animus_session", Value: "sess-1"})
+	return req
+}
+
+func TestRenewSessions(t *testing.T) {
+	svc, transport, store, client := refreshTestService(t)
+	calls := 0
+	h := svc.RenewSessions(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, refreshTestRequest(client, http.MethodGet, "/api/experiments/experiments"))
+	if rec.Code != http.StatusNoContent || calls != 1 {
+		t.Fatalf("status=%d calls=%d", rec.Code, calls)
+	}
+	if len(transport.refreshGrants) != 1 || transport.refreshGrants[0] != "refresh-1" {
+		t.Fatalf("refresh grants=%v", transport.refreshGrants)
+	}
+	if !store.record.ExpiresAt.Equal(transport.expiry) || store.record.RefreshedAt == nil {
+		t.Fatalf("expires_at=%s refreshed_at=%v, want %s", store.record.ExpiresAt, store.record.RefreshedAt, transport.expiry)
+	}
+	if cookieValue(rec.Result().Cookies(), "animus_session") != "sess-1" {
+		t.Fatal("session cookie not re-issued")
+	}
+	rotated, err := svc.sessions.openRefreshToken(context.Background(), store.record.RefreshToken)
+	if err != nil || rotated != "refresh-2" {
+		t.Fatalf("stored refresh token=%q err=%v", rotated, err)
+	}
+
+	// No longer within the window: the next call passes through untouched.
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, refreshTestRequest(client, http.MethodGet, "/api/experiments/experiments"))
+	if len(transport.refreshGrants) != 1 || len(rec.Result().Cookies()) != 0 {
+		t.Fatalf("unexpected renewal: grants=%v cookies=%v", transport.refreshGrants, rec.Result().Cookies())
+	}
+
+	// A failed grant leaves the session as it was.
+	store.record.ExpiresAt = time.Now().UTC().Add(time.Minute)
+	transport.refreshFails = true
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, refreshTestRequest(client, http.MethodGet, "/api/experiments/experiments"))
+	if rec.Code != http.StatusNoContent || calls != 3 || len(rec.Result().Cookies()) != 0 {
+		t.Fatalf("failed refresh: status=%d calls=%d cookies=%v", rec.Code, calls, rec.Result().Cookies())
+	}
+}
+
+func TestRefreshHandler(t *testing.T) {
+	svc, transport, store, client := refreshTestService(t)
+
+	rec := httptest.NewRecorder()
+	svc.RefreshHandler()(rec, refreshTestRequest(client, http.MethodPost, "/auth/refresh"))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "expires_at") {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body)
+	}
+	if !store.record.ExpiresAt.Equal(transport.expiry) {
+		t.Fatalf("expires_at=%s, want %s", store.record.ExpiresAt, transport.expiry)
+	}
+
+	transport.refreshFails = true
+	rec = httptest.NewRecorder()
+	svc.RefreshHandler()(rec, refreshTestRequest(client, http.MethodPost, "/auth/refresh"))
+	if rec.Code != http.StatusUnauthorized || !strings.Contains(rec.Body.String(), "session_refresh_failed") {
+		t.Fatalf("failed grant: status=%d body=%s", rec.Code, rec.Body)
+	}
+
+	store.record.RefreshToken = repo.SealedRefreshToken{}
+	rec = httptest.NewRecorder()
+	svc.RefreshHandler()(rec, refreshTestRequest(client, http.MethodPost, "/auth/refresh"))
+	if rec.Code != http.StatusConflict || !strings.Contains(rec.Body.String(), "session_refresh_unavailable") {
+		t.Fatalf("without refresh token: status=%d body=%s", rec.Code, rec.Body)
+	}
+
+	rec = httptest.NewRecorder()
+	svc.RefreshHandler()(rec, httptest.NewRequest(http.MethodPost, "/auth/refresh", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("without session: status=%d", rec.Code)
+	}
+}
+
+func TestConfigSessionRefresh(t *testing.T) {
+	cfg := Config{
+		Mode:                  ModeDev,
+		RolesClaim:            "roles",
+		EmailClaim:            "email",
+		SessionCookieName:     "animus_session",
+		SessionCookieMaxAge:   time.Hour,
+		SessionCookieSameSite: "Lax",
+		SessionRefresh:        true,
+		SessionRefreshWindow:  5 * time.Minute,
+		DevSubject:            "dev",
+		DevRoles:              []string{"admin"},
+	}
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("session refresh accepted with AUTH_MODE=dev")
+	}
+	cfg.Mode = ModeOIDC
+	cfg.OIDCIssuerURL = "https://issuer.test"
+	cfg.OIDCClientID = "client-1"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("oidc refresh config: %v", err)
+	}
+	cfg.SessionRefreshWindow = 0
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("zero refresh window accepted")
+	}
+}
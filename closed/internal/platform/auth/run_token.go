@@ -18,11 +18,40 @@ var (
 	ErrRunTokenExpired = errors.New("run token is expired")
 )
 
+// Run token scopes limit which run-scoped writes a token may perform.
+const (
+	RunTokenScopeMetricsWrite   = "metrics:write"
+	RunTokenScopeArtifactsWrite = "artifacts:write"
+	RunTokenScopeEventsWrite    = "events:write"
+)
+
+var runTokenScopes = []string{
+	RunTokenScopeMetricsWrite,
+	RunTokenScopeArtifactsWrite,
+	RunTokenScopeEventsWrite,
+}
+
 type RunTokenClaims struct {
-	RunID            string `json:"run_id"`
-	DatasetVersionID string `json:"dataset_version_id,omitempty"`
-	IssuedAtUnix     int64  `json:"iat"`
-	ExpiresAtUnix    int64  `json:"exp"`
+	RunID            string   `json:"run_id"`
+	DatasetVersionID string   `json:"dataset_version_id,omitempty"`
+	Scopes           []string `json:"scopes,omitempty"`
+	IssuedAtUnix     int64    `json:"iat"`
+	ExpiresAtUnix    int64    `json:"exp"`
+}
+
+func ValidRunTokenScope(scope string) bool {
+	for _, known := range runTokenScopes {
+		if scope == known {
+			return true
+		}
+	}
+	return false
+}
+
+// EffectiveScopes returns the scopes granted by the token. A token without
+// scopes grants no writes.
+func (c RunTokenClaims) EffectiveScopes() []string {
+	return append([]string(nil), c.Scopes...)
 }
 
 func RunTokenSubject(claims RunTokenClaims) string {
@@ -74,6 +103,11 @@ func GenerateRunToken(secret string, claims RunTokenClaims, now time.Time) (stri
 	if claims.RunID == "" {
 		return "", errors.New("run_id is required")
 	}
+	scopes, err := normalizeRunTokenScopes(claims.Scopes)
+	if err != nil {
+		return "", err
+	}
+	claims.Scopes = scopes
 
 	if now.IsZero() {
 		now = time.Now().UTC()
@@ -152,6 +186,9 @@ func VerifyRunToken(secret string, token string, now time.Time) (RunTokenClaims,
 	if claims.RunID == "" || claims.ExpiresAtUnix == 0 {
 		return RunTokenClaims{}, ErrRunTokenInvalid
 	}
+	if claims.Scopes, err = normalizeRunTokenScopes(claims.Scopes); err != nil {
+		return RunTokenClaims{}, ErrRunTokenInvalid
+	}
 
 	if now.IsZero() {
 		now = time.Now().UTC()
@@ -163,6 +200,26 @@ func VerifyRunToken(secret string, token string, now time.Time) (RunTokenClaims,
 	return claims, nil
 }
 
+func normalizeRunTokenScopes(scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return nil, nil
+	}
+	seen := make(map[string]struct{}, len(scopes))
+	out := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		scope = strings.TrimSpace(scope)
+		if !ValidRunTokenScope(scope) {
+			return nil, fmt.Errorf("unknown run token scope %q", scope)
+		}
+		if _, ok := seen[scope]; ok {
+			continue
+		}
+		seen[scope] = struct{}{}
+		out = append(out, scope)
+	}
+	return out, nil
+}
+
 func computeRunTokenSignature(secret string, payloadB64 string) (string, error) {
 	payloadB64 = strings.TrimSpace(payloadB64)
 	if payloadB64 == "" {
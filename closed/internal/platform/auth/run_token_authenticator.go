@@ -5,12 +5,20 @@ import (
 	"net/http"
 	"strings"
 	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/repo"
 )
 
 type RunTokenAuthenticator struct {
 	Secret string
-	Next   Authenticator
-	Now    func() time.Time
+	// PreviousSecrets keep tokens minted before a secret rotation valid
+	// until they expire.
+	PreviousSecrets []string
+	// Revocations, when set, is consulted for every verified token; lookup
+	// failures reject the token.
+	Revocations repo.RunTokenRevocationRepository
+	Next        Authenticator
+	Now         func() time.Time
 }
 
 func (a RunTokenAuthenticator) Authenticate(ctx context.Context, r *http.Request) (Identity, error) {
@@ -22,13 +30,29 @@ func (a RunTokenAuthenticator) Authenticate(ctx context.Context, r *http.Request
 			if a.Now != nil {
 				now = a.Now().UTC()
 			}
-			claims, err := VerifyRunToken(a.Secret, token, now)
+			var (
+				claims RunTokenClaims
+				err    error
+			)
+			for _, secret := range append([]string{a.Secret}, a.PreviousSecrets...) {
+				claims, err = VerifyRunToken(secret, token, now)
+				if err == nil {
+					break
+				}
+			}
 			if err != nil {
 				return Identity{}, ErrUnauthenticated
 			}
+			if a.Revocations != nil {
+				revoked, err := a.Revocations.IsRevoked(ctx, claims.RunID, TokenSHA256(token), time.Unix(claims.IssuedAtUnix, 0))
+				if err != nil || revoked {
+					return Identity{}, ErrUnauthenticated
+				}
+			}
 			return Identity{
 				Subject: RunTokenSubject(claims),
 				Roles:   []string{RoleEditor},
+				Scopes:  claims.EffectiveScopes(),
 			}, nil
 		}
 	}
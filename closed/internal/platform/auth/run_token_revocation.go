@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/domain"
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+	"github.com/google/uuid"
+)
+
+var ErrRunTokenHashInvalid = errors.New("run token sha256 is invalid")
+
+// RunTokenRevocationSpec selects what to revoke. With neither Token nor
+// TokenSHA256 set, every token issued for the run so far is revoked.
+type RunTokenRevocationSpec struct {
+	RunID       string
+	Token       string
+	TokenSHA256 string
+	Reason      string
+}
+
+type RunTokenRevocationManager struct {
+	Store repo.RunTokenRevocationRepository
+	Audit repo.AuditEventAppender
+	Now   func() time.Time
+}
+
+func (m *RunTokenRevocationManager) now() time.Time {
+	if m.Now != nil {
+		return m.Now().UTC()
+	}
+	return time.Now().UTC()
+}
+
+func (m *RunTokenRevocationManager) Revoke(ctx context.Context, spec RunTokenRevocationSpec, meta SessionRequestMeta) (repo.RunTokenRevocationRecord, error) {
+	if m == nil || m.Store == nil {
+		return repo.RunTokenRevocationRecord{}, errors.New("run token revocation manager not initialized")
+	}
+	tokenSHA256 := strings.ToLower(strings.TrimSpace(spec.TokenSHA256))
+	if token := strings.TrimSpace(spec.Token); token != "" {
+		tokenSHA256 = TokenSHA256(token)
+	}
+	if tokenSHA256 != "" && !validSHA256Hex(tokenSHA256) {
+		return repo.RunTokenRevocationRecord{}, ErrRunTokenHashInvalid
+	}
+	reason := strings.TrimSpace(spec.Reason)
+	if reason == "" {
+		reason = "revoked"
+	}
+	record := repo.RunTokenRevocationRecord{
+		RevocationID: uuid.NewString(),
+		RunID:        strings.TrimSpace(spec.RunID),
+		TokenSHA256:  tokenSHA256,
+		Reason:       reason,
+		RevokedAt:    m.now(),
+		RevokedBy:    strings.TrimSpace(meta.Actor),
+	}
+	if err := m.Store.Create(ctx, record); err != nil {
+		return repo.RunTokenRevocationRecord{}, err
+	}
+	m.audit(ctx, record, meta)
+	return record, nil
+}
+
+func (m *RunTokenRevocationManager) audit(ctx context.Context, record repo.RunTokenRevocationRecord, meta SessionRequestMeta) {
+	if m.Audit == nil {
+		return
+	}
+	scope := "run"
+	if record.TokenSHA256 != "" {
+		scope = "token"
+	}
+	payload := domain.Metadata{
+		"revocation_id": record.RevocationID,
+		"run_id":        record.RunID,
+		"scope":         scope,
+		"reason":        record.Reason,
+	}
+	if record.TokenSHA256 != "" {
+		payload["token_sha256"] = record.TokenSHA256
+	}
+	if strings.TrimSpace(meta.UserAgent) != "" {
+		payload["user_agent"] = strings.TrimSpace(meta.UserAgent)
+	}
+	_, _ = m.Audit.Append(ctx, domain.AuditEvent{
+		OccurredAt:   record.RevokedAt,
+		Actor:        record.RevokedBy,
+		Action:       "auth.run_token_revoked",
+		ResourceType: "experiment_run",
+		ResourceID:   record.RunID,
+		RequestID:    strings.TrimSpace(meta.RequestID),
+		IP:           parseIP(meta.RemoteIP),
+		UserAgent:    strings.TrimSpace(meta.UserAgent),
+		Payload:      payload,
+	})
+}
+
+func validSHA256Hex(value string) bool {
+	if len(value) != 64 {
+		return false
+	}
+	_, err := hex.DecodeString(value)
+	return err == nil
+}
@@ -0,0 +1,109 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+)
+
+type createRunTokenRevocationRequest struct {
+	RunID       string `json:"run_id"`
+	Token       string `json:"token,omitempty"`
+	TokenSHA256 string `json:"token_sha256,omitempty"`
+	Reason      string `json:"reason,omitempty"`
+}
+
+type runTokenRevocationResponse struct {
+	RevocationID string    `json:"revocation_id"`
+	RunID        string    `json:"run_id"`
+	TokenSHA256  string    `json:"token_sha256,omitempty"`
+	Reason       string    `json:"reason"`
+	RevokedAt    time.Time `json:"revoked_at"`
+	RevokedBy    string    `json:"revoked_by"`
+}
+
+// RunTokenRevocationsHandler serves the admin API under /auth/run-tokens/revocations.
+// Callers are expected to wrap it with an admin-only authorizer.
+func RunTokenRevocationsHandler(manager *RunTokenRevocationManager) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /auth/run-tokens/revocations", func(w http.ResponseWriter, r *http.Request) {
+		limit := 100
+		if raw := strings.TrimSpace(r.URL.Query().Get("limit")); raw != "" {
+			parsed, err := strconv.Atoi(raw)
+			if err != nil || parsed <= 0 || parsed > 1000 {
+				httpapi.WriteError(w, r, http.StatusBadRequest, "limit_invalid")
+				return
+			}
+			limit = parsed
+		}
+		records, err := manager.Store.List(r.Context(), r.URL.Query().Get("run_id"), limit)
+		if err != nil {
+			httpapi.WriteError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+		out := make([]runTokenRevocationResponse, 0, len(records))
+		for _, record := range records {
+			out = append(out, toRunTokenRevocationResponse(record))
+		}
+		httpapi.WriteJSON(w, http.StatusOK, map[string]any{"revocations": out})
+	})
+	mux.HandleFunc("POST /auth/run-tokens/revocations", func(w http.ResponseWriter, r *http.Request) {
+		var req createRunTokenRevocationRequest
+		if !decodeServiceAccountRequest(w, r, &req) {
+			return
+		}
+		if strings.TrimSpace(req.RunID) == "" {
+			httpapi.WriteError(w, r, http.StatusBadRequest, "run_id_required")
+			return
+		}
+		if strings.TrimSpace(req.Token) != "" && strings.TrimSpace(req.TokenSHA256) != "" {
+			httpapi.WriteError(w, r, http.StatusBadRequest, "token_and_sha256_exclusive")
+			return
+		}
+		if token := strings.TrimSpace(req.Token); token != "" {
+			if !strings.HasPrefix(token, runTokenPrefix+".") {
+				httpapi.WriteError(w, r, http.StatusBadRequest, "token_invalid")
+				return
+			}
+		}
+		record, err := manager.Revoke(r.Context(), RunTokenRevocationSpec{
+			RunID:       req.RunID,
+			Token:       req.Token,
+			TokenSHA256: req.TokenSHA256,
+			Reason:      req.Reason,
+		}, serviceAccountRequestMeta(r))
+		if err != nil {
+			if errors.Is(err, ErrRunTokenHashInvalid) {
+				httpapi.WriteError(w, r, http.StatusBadRequest, "token_sha256_invalid")
+				return
+			}
+			httpapi.WriteError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+		httpapi.WriteJSON(w, http.StatusCreated, toRunTokenRevocationResponse(record))
+	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if manager == nil || manager.Store == nil {
+			httpapi.WriteError(w, r, http.StatusServiceUnavailable, "run_token_revocations_unavailable")
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+func toRunTokenRevocationResponse(record repo.RunTokenRevocationRecord) runTokenRevocationResponse {
+	return runTokenRevocationResponse{
+		RevocationID: record.RevocationID,
+		RunID:        record.RunID,
+		TokenSHA256:  record.TokenSHA256,
+		Reason:       record.Reason,
+		RevokedAt:    record.RevokedAt,
+		RevokedBy:    record.RevokedBy,
+	}
+}
@@ -0,0 +1,130 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+)
+
+type stubRunTokenRevocationStore struct {
+	records []repo.RunTokenRevocationRecord
+}
+
+func (s *stubRunTokenRevocationStore) Create(ctx context.Context, record repo.RunTokenRevocationRecord) error {
+	s.records = append(s.records, record)
+	return nil
+}
+
+func (s *stubRunTokenRevocationStore) List(ctx context.Context, runID string, limit int) ([]repo.RunTokenRevocationRecord, error) {
+	out := make([]repo.RunTokenRevocationRecord, 0)
+	for _, record := range s.records {
+		if runID == "" || record.RunID == runID {
+			out = append(out, record)
+		}
+	}
+	return out, nil
+}
+
+func (s *stubRunTokenRevocationStore) IsRevoked(ctx context.Context, runID, tokenSHA256 string, issuedAt time.Time) (bool, error) {
+	for _, record := range s.records {
+		if record.TokenSHA256 != "" && record.TokenSHA256 == tokenSHA256 {
+			return true, nil
+		}
+		if record.TokenSHA256 == "" && record.RunID == runID && !record.RevokedAt.Before(issuedAt) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func runTokenRequest(t *testing.T, token string) *http.Request {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/api/experiments/experiment-runs/run-1/metrics", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	return req
+}
+
+func TestRunTokenRevocationBlocksToken(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	store := &stubRunTokenRevocationStore{}
+	audit := &recordingAuditAppender{}
+	manager := &RunTokenRevocationManager{Store: store, Audit: audit, Now: func() time.Time { return now }}
+	authn := RunTokenAuthenticator{Secret: "secret", Revocations: store, Now: func() time.Time { return now }}
+
+	mint := func(issuedAt time.Time) string {
+		token, err := GenerateRunToken("secret", RunTokenClaims{
+			RunID:         "run-1",
+			Scopes:        []string{RunTokenScopeMetricsWrite},
+			IssuedAtUnix:  issuedAt.Unix(),
+			ExpiresAtUnix: now.Add(time.Hour).Unix(),
+		}, now)
+		if err != nil {
+			t.Fatalf("GenerateRunToken: %v", err)
+		}
+		return token
+	}
+	first := mint(now.Add(-time.Minute))
+	second := mint(now.Add(-2 * time.Minute))
+
+	identity, err := authn.Authenticate(context.Background(), runTokenRequest(t, first))
+	if err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+	if len(identity.Scopes) != 1 || identity.Scopes[0] != RunTokenScopeMetricsWrite {
+		t.Fatalf("Scopes=%v", identity.Scopes)
+	}
+
+	if _, err := manager.Revoke(context.Background(), RunTokenRevocationSpec{RunID: "run-1", Token: first}, SessionRequestMeta{Actor: "admin"}); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	if _, err := authn.Authenticate(context.Background(), runTokenRequest(t, first)); err == nil {
+		t.Fatalf("expected revoked token to be rejected")
+	}
+	if _, err := authn.Authenticate(context.Background(), runTokenRequest(t, second)); err != nil {
+		t.Fatalf("expected other token of the run to stay valid: %v", err)
+	}
+
+	if _, err := manager.Revoke(context.Background(), RunTokenRevocationSpec{RunID: "run-1", Reason: "compromised"}, SessionRequestMeta{Actor: "admin"}); err != nil {
+		t.Fatalf("Revoke run: %v", err)
+	}
+	if _, err := authn.Authenticate(context.Background(), runTokenRequest(t, second)); err == nil {
+		t.Fatalf("expected run-wide revocation to reject earlier tokens")
+	}
+	now = now.Add(time.Minute)
+	if _, err := authn.Authenticate(context.Background(), runTokenRequest(t, mint(now))); err != nil {
+		t.Fatalf("expected token issued after run revocation to be valid: %v", err)
+	}
+	if got := audit.count("auth.run_token_revoked"); got != 2 {
+		t.Fatalf("audit events=%d want 2", got)
+	}
+}
+
+func TestRunTokenRevocationsHandlerValidatesRequest(t *testing.T) {
+	store := &stubRunTokenRevocationStore{}
+	handler := RunTokenRevocationsHandler(&RunTokenRevocationManager{Store: store})
+
+	for body, want := range map[string]int{
+		`{}`:                                    http.StatusBadRequest,
+		`{"run_id":"run-1","token_sha256":"x"}`: http.StatusBadRequest,
+		`{"run_id":"run-1","token":"animus_sa_v1.a.b"}`:                       http.StatusBadRequest,
+		`{"run_id":"run-1","token":"animus_run_v1.a.b","token_sha256":"abc"}`: http.StatusBadRequest,
+		`{"run_id":"run-1","reason":"compromised"}`:                           http.StatusCreated,
+	} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/auth/run-tokens/revocations", bytes.NewBufferString(body)))
+		if rec.Code != want {
+			t.Fatalf("body %s: status=%d want %d", body, rec.Code, want)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/auth/run-tokens/revocations?run_id=run-1", nil))
+	if rec.Code != http.StatusOK || !bytes.Contains(rec.Body.Bytes(), []byte(`"reason":"compromised"`)) {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+}
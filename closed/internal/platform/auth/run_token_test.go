@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"net/http/httptest"
 	"testing"
 	"time"
 )
@@ -67,3 +68,62 @@ func TestRunTokenSubject_Parse(t *testing.T) {
 		t.Fatalf("datasetVersionID=%q, want %q", datasetVersionID, "dv-456")
 	}
 }
+
+func TestRunTokenAuthenticator_PreviousSecret(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	token, err := GenerateRunToken("old-secret", RunTokenClaims{
+		RunID:            "run-123",
+		DatasetVersionID: "dv-456",
+		ExpiresAtUnix:    now.Add(30 * time.Minute).Unix(),
+	}, now)
+	if err != nil {
+		t.Fatalf("GenerateRunToken: %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/api/dataset-registry/datasets", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	authn := RunTokenAuthenticator{Secret: "new-secret", Now: func() time.Time { return now }}
+	if _, err := authn.Authenticate(req.Context(), req); err == nil {
+		t.Fatalf("expected token signed with retired secret to be rejected")
+	}
+	authn.PreviousSecrets = []string{"old-secret"}
+	identity, err := authn.Authenticate(req.Context(), req)
+	if err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+	if identity.Subject != RunTokenSubject(RunTokenClaims{RunID: "run-123", DatasetVersionID: "dv-456"}) {
+		t.Fatalf("Subject=%q", identity.Subject)
+	}
+}
+
+func TestRunToken_Scopes(t *testing.T) {
+	secret := "test-secret"
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	exp := now.Add(30 * time.Minute).Unix()
+
+	if _, err := GenerateRunToken(secret, RunTokenClaims{RunID: "run-123", Scopes: []string{"runs:admin"}, ExpiresAtUnix: exp}, now); err == nil {
+		t.Fatalf("expected unknown scope to be rejected")
+	}
+
+	token, err := GenerateRunToken(secret, RunTokenClaims{
+		RunID:         "run-123",
+		Scopes:        []string{RunTokenScopeMetricsWrite, " metrics:write "},
+		ExpiresAtUnix: exp,
+	}, now)
+	if err != nil {
+		t.Fatalf("GenerateRunToken: %v", err)
+	}
+	claims, err := VerifyRunToken(secret, token, now)
+	if err != nil {
+		t.Fatalf("VerifyRunToken: %v", err)
+	}
+	if got := claims.EffectiveScopes(); len(got) != 1 || got[0] != RunTokenScopeMetricsWrite {
+		t.Fatalf("EffectiveScopes=%v", got)
+	}
+
+	unscoped := RunTokenClaims{RunID: "run-123"}
+	if got := unscoped.EffectiveScopes(); len(got) != 0 {
+		t.Fatalf("expected a token without scopes to grant none, got %v", got)
+	}
+}
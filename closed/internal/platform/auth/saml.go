@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"net/http"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
 )
 
 type SAMLService struct {
@@ -42,13 +44,13 @@ func (s *SAMLService) Authenticate(ctx context.Context, r *http.Request) (Identi
 
 func (s *SAMLService) LoginHandler() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		writeJSON(w, http.StatusNotImplemented, map[string]any{"error": "saml_not_configured"})
+		httpapi.WriteError(w, r, http.StatusNotImplemented, "saml_not_configured")
 	}
 }
 
 func (s *SAMLService) CallbackHandler() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		writeJSON(w, http.StatusNotImplemented, map[string]any{"error": "saml_not_configured"})
+		httpapi.WriteError(w, r, http.StatusNotImplemented, "saml_not_configured")
 	}
 }
 
@@ -65,7 +67,8 @@ func (s *SAMLService) LogoutHandler() http.HandlerFunc {
 			}
 		}
 		clearCookie(w, s.cfg.SessionCookieName, s.cfg)
-		writeJSON(w, http.StatusOK, map[string]any{"status": "ok"})
+		clearCookie(w, CSRFCookieName, s.cfg)
+		httpapi.WriteJSON(w, http.StatusOK, map[string]any{"status": "ok"})
 	}
 }
 
@@ -74,13 +77,13 @@ func (s *SAMLService) SessionHandler() http.HandlerFunc {
 		identity, err := s.Authenticate(r.Context(), r)
 		if err != nil {
 			if errors.Is(err, ErrUnauthenticated) {
-				writeJSON(w, http.StatusUnauthorized, map[string]any{"error": "unauthorized"})
+				httpapi.WriteError(w, r, http.StatusUnauthorized, "unauthorized")
 				return
 			}
-			writeJSON(w, http.StatusUnauthorized, map[string]any{"error": "invalid_token"})
+			httpapi.WriteError(w, r, http.StatusUnauthorized, "invalid_token")
 			return
 		}
-		writeJSON(w, http.StatusOK, map[string]any{
+		httpapi.WriteJSON(w, http.StatusOK, map[string]any{
 			"subject": identity.Subject,
 			"email":   identity.Email,
 			"roles":   identity.Roles,
@@ -0,0 +1,378 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/domain"
+	"github.com/animus-labs/animus-go/closed/internal/platform/env"
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+	"github.com/google/uuid"
+)
+
+const (
+	serviceAccountTokenPrefix  = "animus_sa_v1"
+	serviceAccountSubjectScope = "sa:"
+)
+
+var (
+	ErrServiceAccountTokenRevoked = errors.New("service account token is revoked")
+	ErrServiceAccountTokenExpired = errors.New("service account token is expired")
+
+	serviceAccountNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{1,62}$`)
+)
+
+type ServiceAccountConfig struct {
+	DefaultTTL         time.Duration
+	MaxTTL             time.Duration
+	UsageAuditInterval time.Duration
+}
+
+func ServiceAccountConfigFromEnv() (ServiceAccountConfig, error) {
+	defaultTTL, err := env.Duration("AUTH_SERVICE_ACCOUNT_TOKEN_DEFAULT_TTL", 90*24*time.Hour)
+	if err != nil {
+		return ServiceAccountConfig{}, err
+	}
+	maxTTL, err := env.Duration("AUTH_SERVICE_ACCOUNT_TOKEN_MAX_TTL", 365*24*time.Hour)
+	if err != nil {
+		return ServiceAccountConfig{}, err
+	}
+	usageInterval, err := env.Duration("AUTH_SERVICE_ACCOUNT_USAGE_AUDIT_INTERVAL", 5*time.Minute)
+	if err != nil {
+		return ServiceAccountConfig{}, err
+	}
+	cfg := ServiceAccountConfig{
+		DefaultTTL:         defaultTTL,
+		MaxTTL:             maxTTL,
+		UsageAuditInterval: usageInterval,
+	}
+	if cfg.DefaultTTL <= 0 || cfg.MaxTTL <= 0 {
+		return ServiceAccountConfig{}, errors.New("AUTH_SERVICE_ACCOUNT_TOKEN_DEFAULT_TTL and AUTH_SERVICE_ACCOUNT_TOKEN_MAX_TTL must be positive")
+	}
+	if cfg.DefaultTTL > cfg.MaxTTL {
+		return ServiceAccountConfig{}, errors.New("AUTH_SERVICE_ACCOUNT_TOKEN_DEFAULT_TTL must be <= AUTH_SERVICE_ACCOUNT_TOKEN_MAX_TTL")
+	}
+	if cfg.UsageAuditInterval < 0 {
+		return ServiceAccountConfig{}, errors.New("AUTH_SERVICE_ACCOUNT_USAGE_AUDIT_INTERVAL must be >= 0")
+	}
+	return cfg, nil
+}
+
+// ServiceAccountSubject is the identity subject used for requests made with a service-account token.
+func ServiceAccountSubject(accountName string) string {
+	return serviceAccountSubjectScope + strings.TrimSpace(accountName)
+}
+
+func ParseServiceAccountSubject(subject string) (string, bool) {
+	name, ok := strings.CutPrefix(strings.TrimSpace(subject), serviceAccountSubjectScope)
+	if !ok || strings.TrimSpace(name) == "" {
+		return "", false
+	}
+	return name, true
+}
+
+func ValidServiceAccountName(name string) bool {
+	return serviceAccountNamePattern.MatchString(strings.TrimSpace(name))
+}
+
+// ServiceAccountTokenSpec describes the scope of a token to issue.
+type ServiceAccountTokenSpec struct {
+	Name      string
+	Role      string
+	ProjectID string
+	TTL       time.Duration
+}
+
+type ServiceAccountManager struct {
+	Store  repo.ServiceAccountRepository
+	Audit  repo.AuditEventAppender
+	Config ServiceAccountConfig
+	Now    func() time.Time
+}
+
+func (m *ServiceAccountManager) now() time.Time {
+	if m.Now != nil {
+		return m.Now().UTC()
+	}
+	return time.Now().UTC()
+}
+
+func (m *ServiceAccountManager) CreateAccount(ctx context.Context, name, description string, meta SessionRequestMeta) (repo.ServiceAccountRecord, error) {
+	if m == nil || m.Store == nil {
+		return repo.ServiceAccountRecord{}, errors.New("service account manager not initialized")
+	}
+	record := repo.ServiceAccountRecord{
+		AccountID:   uuid.NewString(),
+		Name:        strings.TrimSpace(name),
+		Description: strings.TrimSpace(description),
+		CreatedAt:   m.now(),
+		CreatedBy:   strings.TrimSpace(meta.Actor),
+	}
+	if err := m.Store.CreateAccount(ctx, record); err != nil {
+		return repo.ServiceAccountRecord{}, err
+	}
+	m.audit(ctx, "auth.service_account_created", "service_account", record.AccountID, meta, domain.Metadata{
+		"account_id": record.AccountID,
+		"name":       record.Name,
+	})
+	return record, nil
+}
+
+// IssueToken creates a token for the account and returns its plaintext value,
+// which is never stored and cannot be retrieved again.
+func (m *ServiceAccountManager) IssueToken(ctx context.Context, accountID string, spec ServiceAccountTokenSpec, meta SessionRequestMeta) (string, repo.ServiceAccountTokenRecord, error) {
+	token, record, err := m.issueToken(ctx, accountID, spec, "", meta)
+	if err != nil {
+		return "", repo.ServiceAccountTokenRecord{}, err
+	}
+	m.audit(ctx, "auth.service_account_token_created", "service_account_token", record.TokenID, meta, tokenAuditPayload(record))
+	return token, record, nil
+}
+
+// RotateToken issues a replacement with the same scope and lifetime, then revokes the old token.
+func (m *ServiceAccountManager) RotateToken(ctx context.Context, accountID, tokenID string, meta SessionRequestMeta) (string, repo.ServiceAccountTokenRecord, error) {
+	if m == nil || m.Store == nil {
+		return "", repo.ServiceAccountTokenRecord{}, errors.New("service account manager not initialized")
+	}
+	old, err := m.accountToken(ctx, accountID, tokenID)
+	if err != nil {
+		return "", repo.ServiceAccountTokenRecord{}, err
+	}
+	now := m.now()
+	if old.RevokedAt != nil {
+		return "", repo.ServiceAccountTokenRecord{}, ErrServiceAccountTokenRevoked
+	}
+	if !old.ExpiresAt.After(now) {
+		return "", repo.ServiceAccountTokenRecord{}, ErrServiceAccountTokenExpired
+	}
+
+	token, record, err := m.issueToken(ctx, old.AccountID, ServiceAccountTokenSpec{
+		Name:      old.Name,
+		Role:      old.Role,
+		ProjectID: old.ProjectID,
+		TTL:       old.ExpiresAt.Sub(old.CreatedAt),
+	}, old.TokenID, meta)
+	if err != nil {
+		return "", repo.ServiceAccountTokenRecord{}, err
+	}
+	if _, err := m.Store.RevokeToken(ctx, old.TokenID, meta.Actor, "rotated", now); err != nil {
+		return "", repo.ServiceAccountTokenRecord{}, err
+	}
+	payload := tokenAuditPayload(record)
+	payload["rotated_from"] = old.TokenID
+	m.audit(ctx, "auth.service_account_token_rotated", "service_account_token", record.TokenID, meta, payload)
+	return token, record, nil
+}
+
+func (m *ServiceAccountManager) RevokeToken(ctx context.Context, accountID, tokenID, reason string, meta SessionRequestMeta) (bool, error) {
+	if m == nil || m.Store == nil {
+		return false, errors.New("service account manager not initialized")
+	}
+	record, err := m.accountToken(ctx, accountID, tokenID)
+	if err != nil {
+		return false, err
+	}
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		reason = "revoked"
+	}
+	updated, err := m.Store.RevokeToken(ctx, record.TokenID, meta.Actor, reason, m.now())
+	if err != nil {
+		return false, err
+	}
+	if updated {
+		payload := tokenAuditPayload(record)
+		payload["reason"] = reason
+		m.audit(ctx, "auth.service_account_token_revoked", "service_account_token", record.TokenID, meta, payload)
+	}
+	return updated, nil
+}
+
+// Authenticate resolves a presented token to an identity. Usage is recorded at
+// most once per UsageAuditInterval per token to keep audit volume bounded.
+func (m *ServiceAccountManager) Authenticate(ctx context.Context, token string, meta SessionRequestMeta) (Identity, error) {
+	if m == nil || m.Store == nil {
+		return Identity{}, ErrUnauthenticated
+	}
+	tokenID, ok := parseServiceAccountToken(token)
+	if !ok {
+		return Identity{}, ErrUnauthenticated
+	}
+	record, err := m.Store.GetToken(ctx, tokenID)
+	if err != nil {
+		return Identity{}, ErrUnauthenticated
+	}
+	if subtle.ConstantTimeCompare([]byte(record.TokenSHA256), []byte(TokenSHA256(token))) != 1 {
+		return Identity{}, ErrUnauthenticated
+	}
+
+	now := m.now()
+	if record.RevokedAt != nil || !record.ExpiresAt.After(now) {
+		reason := "revoked"
+		if record.RevokedAt == nil {
+			reason = "expired"
+		}
+		if meta.Actor == "" {
+			meta.Actor = "service_account_token:" + record.TokenID
+		}
+		payload := tokenAuditPayload(record)
+		payload["reason"] = reason
+		m.audit(ctx, "auth.service_account_token_rejected", "service_account_token", record.TokenID, meta, payload)
+		return Identity{}, ErrUnauthenticated
+	}
+	account, err := m.Store.GetAccount(ctx, record.AccountID)
+	if err != nil {
+		return Identity{}, ErrUnauthenticated
+	}
+
+	if record.LastUsedAt == nil || now.Sub(*record.LastUsedAt) >= m.Config.UsageAuditInterval {
+		if err := m.Store.TouchToken(ctx, record.TokenID, now); err == nil {
+			if meta.Actor == "" {
+				meta.Actor = ServiceAccountSubject(account.Name)
+			}
+			m.audit(ctx, "auth.service_account_token_used", "service_account_token", record.TokenID, meta, tokenAuditPayload(record))
+		}
+	}
+
+	return Identity{
+		Subject:   ServiceAccountSubject(account.Name),
+		Roles:     []string{record.Role},
+		ProjectID: record.ProjectID,
+	}, nil
+}
+
+// accountToken loads a token and hides tokens that belong to another account.
+func (m *ServiceAccountManager) accountToken(ctx context.Context, accountID, tokenID string) (repo.ServiceAccountTokenRecord, error) {
+	record, err := m.Store.GetToken(ctx, tokenID)
+	if err != nil {
+		return repo.ServiceAccountTokenRecord{}, err
+	}
+	if record.AccountID != strings.TrimSpace(accountID) {
+		return repo.ServiceAccountTokenRecord{}, repo.ErrNotFound
+	}
+	return record, nil
+}
+
+func (m *ServiceAccountManager) issueToken(ctx context.Context, accountID string, spec ServiceAccountTokenSpec, rotatedFrom string, meta SessionRequestMeta) (string, repo.ServiceAccountTokenRecord, error) {
+	if m == nil || m.Store == nil {
+		return "", repo.ServiceAccountTokenRecord{}, errors.New("service account manager not initialized")
+	}
+	account, err := m.Store.GetAccount(ctx, accountID)
+	if err != nil {
+		return "", repo.ServiceAccountTokenRecord{}, err
+	}
+	ttl := spec.TTL
+	if ttl <= 0 {
+		ttl = m.Config.DefaultTTL
+	}
+	if m.Config.MaxTTL > 0 && ttl > m.Config.MaxTTL {
+		ttl = m.Config.MaxTTL
+	}
+	if ttl <= 0 {
+		return "", repo.ServiceAccountTokenRecord{}, errors.New("token ttl must be positive")
+	}
+
+	tokenID := uuid.NewString()
+	token, err := generateServiceAccountToken(tokenID)
+	if err != nil {
+		return "", repo.ServiceAccountTokenRecord{}, err
+	}
+	now := m.now()
+	record := repo.ServiceAccountTokenRecord{
+		TokenID:     tokenID,
+		AccountID:   account.AccountID,
+		Name:        strings.TrimSpace(spec.Name),
+		TokenSHA256: TokenSHA256(token),
+		Role:        strings.ToLower(strings.TrimSpace(spec.Role)),
+		ProjectID:   strings.TrimSpace(spec.ProjectID),
+		CreatedAt:   now,
+		CreatedBy:   strings.TrimSpace(meta.Actor),
+		ExpiresAt:   now.Add(ttl),
+		RotatedFrom: rotatedFrom,
+	}
+	if err := m.Store.CreateToken(ctx, record); err != nil {
+		return "", repo.ServiceAccountTokenRecord{}, err
+	}
+	return token, record, nil
+}
+
+func (m *ServiceAccountManager) audit(ctx context.Context, action, resourceType, resourceID string, meta SessionRequestMeta, payload domain.Metadata) {
+	if m == nil || m.Audit == nil {
+		return
+	}
+	if strings.TrimSpace(meta.UserAgent) != "" {
+		payload["user_agent"] = strings.TrimSpace(meta.UserAgent)
+	}
+	_, _ = m.Audit.Append(ctx, domain.AuditEvent{
+		OccurredAt:   m.now(),
+		Actor:        strings.TrimSpace(meta.Actor),
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		RequestID:    strings.TrimSpace(meta.RequestID),
+		IP:           parseIP(meta.RemoteIP),
+		UserAgent:    strings.TrimSpace(meta.UserAgent),
+		Payload:      payload,
+	})
+}
+
+func tokenAuditPayload(record repo.ServiceAccountTokenRecord) domain.Metadata {
+	return domain.Metadata{
+		"token_id":   record.TokenID,
+		"account_id": record.AccountID,
+		"role":       record.Role,
+		"project_id": record.ProjectID,
+		"expires_at": record.ExpiresAt.UTC().Format(time.RFC3339),
+	}
+}
+
+func generateServiceAccountToken(tokenID string) (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return strings.Join([]string{serviceAccountTokenPrefix, tokenID, base64.RawURLEncoding.EncodeToString(secret)}, "."), nil
+}
+
+func parseServiceAccountToken(token string) (string, bool) {
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if len(parts) != 3 || parts[0] != serviceAccountTokenPrefix {
+		return "", false
+	}
+	tokenID := strings.TrimSpace(parts[1])
+	if tokenID == "" || strings.TrimSpace(parts[2]) == "" {
+		return "", false
+	}
+	return tokenID, true
+}
+
+type ServiceAccountAuthenticator struct {
+	Manager *ServiceAccountManager
+	Next    Authenticator
+}
+
+func (a ServiceAccountAuthenticator) Authenticate(ctx context.Context, r *http.Request) (Identity, error) {
+	authz := strings.TrimSpace(r.Header.Get("Authorization"))
+	if strings.HasPrefix(strings.ToLower(authz), "bearer ") {
+		token := strings.TrimSpace(authz[len("bearer "):])
+		if strings.HasPrefix(token, serviceAccountTokenPrefix+".") {
+			return a.Manager.Authenticate(ctx, token, SessionRequestMeta{
+				RequestID: r.Header.Get("X-Request-Id"),
+				UserAgent: r.UserAgent(),
+				RemoteIP:  ParseRemoteIP(r.RemoteAddr),
+			})
+		}
+	}
+
+	if a.Next == nil {
+		return Identity{}, ErrUnauthenticated
+	}
+	return a.Next.Authenticate(ctx, r)
+}
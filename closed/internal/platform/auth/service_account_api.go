@@ -0,0 +1,242 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+)
+
+type createServiceAccountRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+type createServiceAccountTokenRequest struct {
+	Name             string `json:"name,omitempty"`
+	Role             string `json:"role"`
+	ProjectID        string `json:"project_id,omitempty"`
+	ExpiresInSeconds int64  `json:"expires_in_seconds,omitempty"`
+}
+
+type revokeServiceAccountTokenRequest struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+type serviceAccountResponse struct {
+	AccountID   string    `json:"account_id"`
+	Name        string    `json:"name"`
+	Subject     string    `json:"subject"`
+	Description string    `json:"description,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	CreatedBy   string    `json:"created_by"`
+}
+
+type serviceAccountTokenResponse struct {
+	TokenID      string     `json:"token_id"`
+	AccountID    string     `json:"account_id"`
+	Name         string     `json:"name,omitempty"`
+	Role         string     `json:"role"`
+	ProjectID    string     `json:"project_id,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	CreatedBy    string     `json:"created_by"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	LastUsedAt   *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+	RevokedBy    string     `json:"revoked_by,omitempty"`
+	RevokeReason string     `json:"revoke_reason,omitempty"`
+	RotatedFrom  string     `json:"rotated_from,omitempty"`
+	Token        string     `json:"token,omitempty"`
+}
+
+// ServiceAccountsHandler serves the admin API under /auth/service-accounts.
+// Callers are expected to wrap it with an admin-only authorizer.
+func ServiceAccountsHandler(manager *ServiceAccountManager) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /auth/service-accounts", func(w http.ResponseWriter, r *http.Request) {
+		records, err := manager.Store.ListAccounts(r.Context())
+		if err != nil {
+			httpapi.WriteError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+		out := make([]serviceAccountResponse, 0, len(records))
+		for _, record := range records {
+			out = append(out, toServiceAccountResponse(record))
+		}
+		httpapi.WriteJSON(w, http.StatusOK, map[string]any{"service_accounts": out})
+	})
+	mux.HandleFunc("POST /auth/service-accounts", func(w http.ResponseWriter, r *http.Request) {
+		var req createServiceAccountRequest
+		if !decodeServiceAccountRequest(w, r, &req) {
+			return
+		}
+		name := strings.TrimSpace(req.Name)
+		if !ValidServiceAccountName(name) {
+			httpapi.WriteError(w, r, http.StatusBadRequest, "name_invalid")
+			return
+		}
+		record, err := manager.CreateAccount(r.Context(), name, req.Description, serviceAccountRequestMeta(r))
+		if err != nil {
+			if errors.Is(err, repo.ErrConflict) {
+				httpapi.WriteError(w, r, http.StatusConflict, "service_account_exists")
+				return
+			}
+			httpapi.WriteError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+		httpapi.WriteJSON(w, http.StatusCreated, toServiceAccountResponse(record))
+	})
+	mux.HandleFunc("GET /auth/service-accounts/{account_id}/tokens", func(w http.ResponseWriter, r *http.Request) {
+		accountID := strings.TrimSpace(r.PathValue("account_id"))
+		if _, err := manager.Store.GetAccount(r.Context(), accountID); err != nil {
+			writeServiceAccountError(w, r, err)
+			return
+		}
+		records, err := manager.Store.ListTokens(r.Context(), accountID)
+		if err != nil {
+			httpapi.WriteError(w, r, http.StatusInternalServerError, "internal_error")
+			return
+		}
+		out := make([]serviceAccountTokenResponse, 0, len(records))
+		for _, record := range records {
+			out = append(out, toServiceAccountTokenResponse(record, ""))
+		}
+		httpapi.WriteJSON(w, http.StatusOK, map[string]any{"tokens": out})
+	})
+	mux.HandleFunc("POST /auth/service-accounts/{account_id}/tokens", func(w http.ResponseWriter, r *http.Request) {
+		var req createServiceAccountTokenRequest
+		if !decodeServiceAccountRequest(w, r, &req) {
+			return
+		}
+		role := strings.ToLower(strings.TrimSpace(req.Role))
+		if _, ok := roleLevels[role]; !ok && role != RoleAuditor {
+			httpapi.WriteError(w, r, http.StatusBadRequest, "role_invalid")
+			return
+		}
+		if req.ExpiresInSeconds < 0 {
+			httpapi.WriteError(w, r, http.StatusBadRequest, "expires_in_invalid")
+			return
+		}
+		ttl := time.Duration(req.ExpiresInSeconds) * time.Second
+		if manager.Config.MaxTTL > 0 && ttl > manager.Config.MaxTTL {
+			httpapi.WriteError(w, r, http.StatusBadRequest, "expires_in_too_long")
+			return
+		}
+		token, record, err := manager.IssueToken(r.Context(), r.PathValue("account_id"), ServiceAccountTokenSpec{
+			Name:      req.Name,
+			Role:      role,
+			ProjectID: req.ProjectID,
+			TTL:       ttl,
+		}, serviceAccountRequestMeta(r))
+		if err != nil {
+			writeServiceAccountError(w, r, err)
+			return
+		}
+		httpapi.WriteJSON(w, http.StatusCreated, toServiceAccountTokenResponse(record, token))
+	})
+	mux.HandleFunc("POST /auth/service-accounts/{account_id}/tokens/{token_id}/rotate", func(w http.ResponseWriter, r *http.Request) {
+		token, record, err := manager.RotateToken(r.Context(), r.PathValue("account_id"), r.PathValue("token_id"), serviceAccountRequestMeta(r))
+		if err != nil {
+			writeServiceAccountError(w, r, err)
+			return
+		}
+		httpapi.WriteJSON(w, http.StatusCreated, toServiceAccountTokenResponse(record, token))
+	})
+	mux.HandleFunc("POST /auth/service-accounts/{account_id}/tokens/{token_id}/revoke", func(w http.ResponseWriter, r *http.Request) {
+		var req revokeServiceAccountTokenRequest
+		if r.ContentLength != 0 && !decodeServiceAccountRequest(w, r, &req) {
+			return
+		}
+		updated, err := manager.RevokeToken(r.Context(), r.PathValue("account_id"), r.PathValue("token_id"), req.Reason, serviceAccountRequestMeta(r))
+		if err != nil {
+			writeServiceAccountError(w, r, err)
+			return
+		}
+		revoked := 0
+		if updated {
+			revoked = 1
+		}
+		httpapi.WriteJSON(w, http.StatusOK, map[string]any{"revoked": revoked})
+	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if manager == nil || manager.Store == nil {
+			httpapi.WriteError(w, r, http.StatusServiceUnavailable, "service_accounts_unavailable")
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+func decodeServiceAccountRequest(w http.ResponseWriter, r *http.Request, out any) bool {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(out); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
+		return false
+	}
+	return true
+}
+
+func writeServiceAccountError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, repo.ErrNotFound):
+		httpapi.WriteError(w, r, http.StatusNotFound, "not_found")
+	case errors.Is(err, ErrServiceAccountTokenRevoked):
+		httpapi.WriteError(w, r, http.StatusConflict, "token_revoked")
+	case errors.Is(err, ErrServiceAccountTokenExpired):
+		httpapi.WriteError(w, r, http.StatusConflict, "token_expired")
+	default:
+		httpapi.WriteError(w, r, http.StatusInternalServerError, "internal_error")
+	}
+}
+
+func serviceAccountRequestMeta(r *http.Request) SessionRequestMeta {
+	actor := ""
+	if identity, ok := IdentityFromContext(r.Context()); ok {
+		actor = strings.TrimSpace(identity.Subject)
+	}
+	if actor == "" {
+		actor = "system"
+	}
+	return SessionRequestMeta{
+		RequestID: r.Header.Get("X-Request-Id"),
+		UserAgent: r.UserAgent(),
+		RemoteIP:  ParseRemoteIP(r.RemoteAddr),
+		Actor:     actor,
+	}
+}
+
+func toServiceAccountResponse(record repo.ServiceAccountRecord) serviceAccountResponse {
+	return serviceAccountResponse{
+		AccountID:   record.AccountID,
+		Name:        record.Name,
+		Subject:     ServiceAccountSubject(record.Name),
+		Description: record.Description,
+		CreatedAt:   record.CreatedAt,
+		CreatedBy:   record.CreatedBy,
+	}
+}
+
+func toServiceAccountTokenResponse(record repo.ServiceAccountTokenRecord, token string) serviceAccountTokenResponse {
+	return serviceAccountTokenResponse{
+		TokenID:      record.TokenID,
+		AccountID:    record.AccountID,
+		Name:         record.Name,
+		Role:         record.Role,
+		ProjectID:    record.ProjectID,
+		CreatedAt:    record.CreatedAt,
+		CreatedBy:    record.CreatedBy,
+		ExpiresAt:    record.ExpiresAt,
+		LastUsedAt:   record.LastUsedAt,
+		RevokedAt:    record.RevokedAt,
+		RevokedBy:    record.RevokedBy,
+		RevokeReason: record.RevokeReason,
+		RotatedFrom:  record.RotatedFrom,
+		Token:        token,
+	}
+}
@@ -0,0 +1,262 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/domain"
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+)
+
+type stubServiceAccountStore struct {
+	accounts map[string]repo.ServiceAccountRecord
+	tokens   map[string]repo.ServiceAccountTokenRecord
+}
+
+func newStubServiceAccountStore() *stubServiceAccountStore {
+	return &stubServiceAccountStore{
+		accounts: map[string]repo.ServiceAccountRecord{},
+		tokens:   map[string]repo.ServiceAccountTokenRecord{},
+	}
+}
+
+func (s *stubServiceAccountStore) CreateAccount(ctx context.Context, record repo.ServiceAccountRecord) error {
+	for _, existing := range s.accounts {
+		if existing.Name == record.Name {
+			return repo.ErrConflict
+		}
+	}
+	s.accounts[record.AccountID] = record
+	return nil
+}
+
+func (s *stubServiceAccountStore) GetAccount(ctx context.Context, accountID string) (repo.ServiceAccountRecord, error) {
+	record, ok := s.accounts[accountID]
+	if !ok {
+		return repo.ServiceAccountRecord{}, repo.ErrNotFound
+	}
+	return record, nil
+}
+
+func (s *stubServiceAccountStore) ListAccounts(ctx context.Context) ([]repo.ServiceAccountRecord, error) {
+	out := make([]repo.ServiceAccountRecord, 0, len(s.accounts))
+	for _, record := range s.accounts {
+		out = append(out, record)
+	}
+	return out, nil
+}
+
+func (s *stubServiceAccountStore) CreateToken(ctx context.Context, record repo.ServiceAccountTokenRecord) error {
+	s.tokens[record.TokenID] = record
+	return nil
+}
+
+func (s *stubServiceAccountStore) GetToken(ctx context.Context, tokenID string) (repo.ServiceAccountTokenRecord, error) {
+	record, ok := s.tokens[tokenID]
+	if !ok {
+		return repo.ServiceAccountTokenRecord{}, repo.ErrNotFound
+	}
+	return record, nil
+}
+
+func (s *stubServiceAccountStore) ListTokens(ctx context.Context, accountID string) ([]repo.ServiceAccountTokenRecord, error) {
+	out := make([]repo.ServiceAccountTokenRecord, 0)
+	for _, record := range s.tokens {
+		if record.AccountID == accountID {
+			out = append(out, record)
+		}
+	}
+	return out, nil
+}
+
+func (s *stubServiceAccountStore) TouchToken(ctx context.Context, tokenID string, at time.Time) error {
+	record := s.tokens[tokenID]
+	record.LastUsedAt = &at
+	s.tokens[tokenID] = record
+	return nil
+}
+
+func (s *stubServiceAccountStore) RevokeToken(ctx context.Context, tokenID, revokedBy, reason string, at time.Time) (bool, error) {
+	record, ok := s.tokens[tokenID]
+	if !ok || record.RevokedAt != nil {
+		return false, nil
+	}
+	record.RevokedAt = &at
+	record.RevokedBy = revokedBy
+	record.RevokeReason = reason
+	s.tokens[tokenID] = record
+	return true, nil
+}
+
+type recordingAuditAppender struct {
+	events []domain.AuditEvent
+}
+
+func (a *recordingAuditAppender) Append(ctx context.Context, event domain.AuditEvent) (int64, error) {
+	a.events = append(a.events, event)
+	return int64(len(a.events)), nil
+}
+
+func (a *recordingAuditAppender) count(action string) int {
+	n := 0
+	for _, event := range a.events {
+		if event.Action == action {
+			n++
+		}
+	}
+	return n
+}
+
+func newTestServiceAccountManager(now *time.Time) (*ServiceAccountManager, *stubServiceAccountStore, *recordingAuditAppender) {
+	store := newStubServiceAccountStore()
+	audit := &recordingAuditAppender{}
+	return &ServiceAccountManager{
+		Store: store,
+		Audit: audit,
+		Config: ServiceAccountConfig{
+			DefaultTTL:         24 * time.Hour,
+			MaxTTL:             48 * time.Hour,
+			UsageAuditInterval: time.Minute,
+		},
+		Now: func() time.Time { return *now },
+	}, store, audit
+}
+
+func TestServiceAccountTokenAuthenticates(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	manager, _, audit := newTestServiceAccountManager(&now)
+	meta := SessionRequestMeta{Actor: "admin-1"}
+
+	account, err := manager.CreateAccount(context.Background(), "ci-pipeline", "", meta)
+	if err != nil {
+		t.Fatalf("create account: %v", err)
+	}
+	token, record, err := manager.IssueToken(context.Background(), account.AccountID, ServiceAccountTokenSpec{Role: RoleEditor, ProjectID: "proj-1"}, meta)
+	if err != nil {
+		t.Fatalf("issue token: %v", err)
+	}
+	if record.TokenSHA256 == "" || strings.Contains(record.TokenSHA256, token) {
+		t.Fatalf("expected only the token hash to be stored")
+	}
+	if !record.ExpiresAt.Equal(now.Add(24 * time.Hour)) {
+		t.Fatalf("expected default ttl, got %v", record.ExpiresAt)
+	}
+
+	authenticator := ServiceAccountAuthenticator{Manager: manager}
+	req := httptest.NewRequest(http.MethodGet, "/api/experiments/experiments", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	identity, err := authenticator.Authenticate(context.Background(), req)
+	if err != nil {
+		t.Fatalf("authenticate: %v", err)
+	}
+	if identity.Subject != "sa:ci-pipeline" || identity.ProjectID != "proj-1" || len(identity.Roles) != 1 || identity.Roles[0] != RoleEditor {
+		t.Fatalf("unexpected identity: %+v", identity)
+	}
+
+	now = now.Add(10 * time.Second)
+	if _, err := authenticator.Authenticate(context.Background(), req); err != nil {
+		t.Fatalf("authenticate again: %v", err)
+	}
+	if got := audit.count("auth.service_account_token_used"); got != 1 {
+		t.Fatalf("expected usage audit to be throttled, got %d events", got)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token+"x")
+	if _, err := authenticator.Authenticate(context.Background(), req); !errors.Is(err, ErrUnauthenticated) {
+		t.Fatalf("expected tampered token to be rejected, got %v", err)
+	}
+}
+
+func TestServiceAccountTokenRotateAndRevoke(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	manager, _, audit := newTestServiceAccountManager(&now)
+	meta := SessionRequestMeta{Actor: "admin-1"}
+	ctx := context.Background()
+
+	account, _ := manager.CreateAccount(ctx, "ci-pipeline", "", meta)
+	oldToken, oldRecord, _ := manager.IssueToken(ctx, account.AccountID, ServiceAccountTokenSpec{Role: RoleViewer, TTL: time.Hour}, meta)
+
+	if _, _, err := manager.RotateToken(ctx, "other-account", oldRecord.TokenID, meta); !errors.Is(err, repo.ErrNotFound) {
+		t.Fatalf("expected token of another account to be hidden, got %v", err)
+	}
+	newToken, newRecord, err := manager.RotateToken(ctx, account.AccountID, oldRecord.TokenID, meta)
+	if err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+	if newRecord.RotatedFrom != oldRecord.TokenID || newRecord.Role != RoleViewer || !newRecord.ExpiresAt.Equal(now.Add(time.Hour)) {
+		t.Fatalf("unexpected rotated record: %+v", newRecord)
+	}
+	if _, err := manager.Authenticate(ctx, oldToken, SessionRequestMeta{}); !errors.Is(err, ErrUnauthenticated) {
+		t.Fatalf("expected rotated-out token to be rejected, got %v", err)
+	}
+	if audit.count("auth.service_account_token_rejected") != 1 {
+		t.Fatalf("expected rejected token use to be audited")
+	}
+
+	if _, err := manager.RevokeToken(ctx, account.AccountID, newRecord.TokenID, "", meta); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	if _, err := manager.Authenticate(ctx, newToken, SessionRequestMeta{}); !errors.Is(err, ErrUnauthenticated) {
+		t.Fatalf("expected revoked token to be rejected, got %v", err)
+	}
+	if _, _, err := manager.RotateToken(ctx, account.AccountID, newRecord.TokenID, meta); !errors.Is(err, ErrServiceAccountTokenRevoked) {
+		t.Fatalf("expected rotate of revoked token to fail, got %v", err)
+	}
+}
+
+func TestServiceAccountTokenExpires(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	manager, _, _ := newTestServiceAccountManager(&now)
+	ctx := context.Background()
+
+	account, _ := manager.CreateAccount(ctx, "ci-pipeline", "", SessionRequestMeta{Actor: "admin-1"})
+	token, _, _ := manager.IssueToken(ctx, account.AccountID, ServiceAccountTokenSpec{Role: RoleViewer, TTL: time.Hour}, SessionRequestMeta{Actor: "admin-1"})
+
+	now = now.Add(2 * time.Hour)
+	if _, err := manager.Authenticate(ctx, token, SessionRequestMeta{}); !errors.Is(err, ErrUnauthenticated) {
+		t.Fatalf("expected expired token to be rejected, got %v", err)
+	}
+}
+
+func TestServiceAccountsHandlerValidatesTokenRequest(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	manager, _, _ := newTestServiceAccountManager(&now)
+	handler := ServiceAccountsHandler(manager)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/auth/service-accounts", bytes.NewBufferString(`{"name":"CI Pipeline"}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected invalid name to be rejected, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/auth/service-accounts", bytes.NewBufferString(`{"name":"ci-pipeline"}`)))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status=%d want 201 body=%s", rec.Code, rec.Body.String())
+	}
+	var accountID string
+	for id := range manager.Store.(*stubServiceAccountStore).accounts {
+		accountID = id
+	}
+
+	for body, want := range map[string]int{
+		`{"role":"owner"}`: http.StatusBadRequest,
+		`{"role":"editor","expires_in_seconds":31536000}`: http.StatusBadRequest,
+		`{"role":"editor","project_id":"proj-1"}`:         http.StatusCreated,
+	} {
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/auth/service-accounts/"+accountID+"/tokens", bytes.NewBufferString(body)))
+		if rec.Code != want {
+			t.Fatalf("body %s: status=%d want %d", body, rec.Code, want)
+		}
+		if want == http.StatusCreated && !strings.Contains(rec.Body.String(), `"token":"animus_sa_v1.`) {
+			t.Fatalf("expected plaintext token in create response: %s", rec.Body.String())
+		}
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/animus-labs/animus-go/closed/internal/domain"
+	"github.com/animus-labs/animus-go/closed/internal/platform/envelope"
 	"github.com/animus-labs/animus-go/closed/internal/repo"
 	"github.com/google/uuid"
 )
@@ -18,6 +19,9 @@ type SessionManager struct {
 	Audit         repo.AuditEventAppender
 	MaxConcurrent int
 	Now           func() time.Time
+	// RefreshTokens encrypts OIDC refresh tokens at rest; without it no
+	// refresh token is stored.
+	RefreshTokens *envelope.Encrypter
 }
 
 type SessionRequestMeta struct {
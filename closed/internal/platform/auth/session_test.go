@@ -43,6 +43,16 @@ func (s *stubSessionStore) UpdateLastSeen(ctx context.Context, sessionID string,
 	return nil
 }
 
+func (s *stubSessionStore) UpdateRefresh(ctx context.Context, sessionID string, expiresAt time.Time, token repo.SealedRefreshToken, at time.Time) (bool, error) {
+	if s.revoked {
+		return false, nil
+	}
+	s.record.ExpiresAt = expiresAt
+	s.record.RefreshToken = token
+	s.record.RefreshedAt = &at
+	return true, nil
+}
+
 func (s *stubSessionStore) Revoke(ctx context.Context, sessionID, revokedBy, reason string, at time.Time) (bool, error) {
 	s.revoked = true
 	s.revokedBy = revokedBy
@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+	"gopkg.in/yaml.v3"
+)
+
+// StaticUsers verifies passwords against a bcrypt-hashed user file:
+//
+//	users:
+//	  - username: alice
+//	    password_hash: $2a$12$...   # htpasswd -nbBC 12 "" '<password>' | cut -d: -f2
+//	    email: alice@lab.local
+//	    roles: [editor]
+//	    groups: [ml-team]
+//
+// Groups are kept as roles (for project bindings with subject_type=group) and
+// mapped through AUTH_GROUP_ROLE_MAP like IdP groups.
+type StaticUsers struct {
+	users map[string]staticUser
+	// dummyHash is compared for unknown users so response timing does not
+	// reveal which usernames exist.
+	dummyHash []byte
+}
+
+type staticUser struct {
+	Username     string   `yaml:"username"`
+	PasswordHash string   `yaml:"password_hash"`
+	Email        string   `yaml:"email"`
+	Roles        []string `yaml:"roles"`
+	Groups       []string `yaml:"groups"`
+}
+
+// LoadStaticUsers reads the user file. It is read once at startup.
+func LoadStaticUsers(path string, groupRoleMap map[string]string) (*StaticUsers, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("AUTH_STATIC_USERS_FILE: %w", err)
+	}
+	return ParseStaticUsers(raw, groupRoleMap)
+}
+
+func ParseStaticUsers(raw []byte, groupRoleMap map[string]string) (*StaticUsers, error) {
+	var parsed struct {
+		Users []staticUser `yaml:"users"`
+	}
+	if err := yaml.Unmarshal(raw, &parsed); err != nil {
+		return nil, fmt.Errorf("invalid static users file: %w", err)
+	}
+	users := make(map[string]staticUser, len(parsed.Users))
+	for i, user := range parsed.Users {
+		user.Username = strings.ToLower(strings.TrimSpace(user.Username))
+		if user.Username == "" {
+			return nil, fmt.Errorf("static user %d: username is required", i)
+		}
+		if _, ok := users[user.Username]; ok {
+			return nil, fmt.Errorf("static user %q is defined twice", user.Username)
+		}
+		if _, err := bcrypt.Cost([]byte(user.PasswordHash)); err != nil {
+			return nil, fmt.Errorf("static user %q: password_hash must be a bcrypt hash", user.Username)
+		}
+		groups := extractRolesValue(user.Groups)
+		user.Roles = mergeUnique(extractRolesValue(user.Roles), groups, mapGroupsToRoles(groups, groupRoleMap))
+		users[user.Username] = user
+	}
+	if len(users) == 0 {
+		return nil, fmt.Errorf("static users file defines no users")
+	}
+	dummyHash, err := bcrypt.GenerateFromPassword([]byte("animus-static-users"), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	return &StaticUsers{users: users, dummyHash: dummyHash}, nil
+}
+
+func (s *StaticUsers) Verify(_ context.Context, username, password string) (Identity, error) {
+	user, ok := s.users[strings.ToLower(strings.TrimSpace(username))]
+	if !ok {
+		_ = bcrypt.CompareHashAndPassword(s.dummyHash, []byte(password))
+		return Identity{}, ErrInvalidCredentials
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return Identity{}, ErrInvalidCredentials
+	}
+	return Identity{
+		Subject: user.Username,
+		Email:   strings.TrimSpace(user.Email),
+		Roles:   user.Roles,
+	}, nil
+}
@@ -0,0 +1,84 @@
+// Package buildinfo identifies the running build. Release images stamp it at
+// link time:
+//
+//	-ldflags "-X github.com/animus-labs/animus-go/closed/internal/platform/buildinfo.Version=v1.4.0
+//	          -X github.com/animus-labs/animus-go/closed/internal/platform/buildinfo.Commit=<sha>
+//	          -X github.com/animus-labs/animus-go/closed/internal/platform/buildinfo.Date=<RFC 3339>"
+//
+// Unstamped builds fall back to the VCS settings the Go toolchain records.
+package buildinfo
+
+import (
+	"runtime/debug"
+	"sync"
+)
+
+// Set by -ldflags -X; empty in unstamped builds.
+var (
+	Version string
+	Commit  string
+	Date    string
+)
+
+// Info is the build metadata reported by /version and recorded in audit
+// events.
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	Date      string `json:"build_date,omitempty"`
+	GoVersion string `json:"go_version"`
+}
+
+// Get returns the stamped metadata, completed from the toolchain's VCS
+// settings. Version is never empty: it falls back to the short commit, then
+// "dev".
+func Get() Info {
+	info := vcs()
+	if Version != "" {
+		info.Version = Version
+	}
+	if Commit != "" {
+		info.Commit = Commit
+	}
+	if Date != "" {
+		info.Date = Date
+	}
+	if info.Version == "" {
+		info.Version = shortCommit(info.Commit)
+	}
+	if info.Version == "" {
+		info.Version = "dev"
+	}
+	return info
+}
+
+var vcs = sync.OnceValue(func() Info {
+	var info Info
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	info.GoVersion = bi.GoVersion
+	modified := false
+	for _, setting := range bi.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			info.Commit = setting.Value
+		case "vcs.time":
+			info.Date = setting.Value
+		case "vcs.modified":
+			modified = setting.Value == "true"
+		}
+	}
+	if modified && info.Commit != "" {
+		info.Version = shortCommit(info.Commit) + "-dirty"
+	}
+	return info
+})
+
+func shortCommit(commit string) string {
+	if len(commit) > 12 {
+		return commit[:12]
+	}
+	return commit
+}
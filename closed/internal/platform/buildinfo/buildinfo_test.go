@@ -0,0 +1,24 @@
+package buildinfo
+
+import "testing"
+
+func TestGetPrefersStampedValues(t *testing.T) {
+	prev := [3]string{Version, Commit, Date}
+	t.Cleanup(func() { Version, Commit, Date = prev[0], prev[1], prev[2] })
+
+	Version, Commit, Date = "v1.2.3", "0123456789abcdef", "2026-01-02T03:04:05Z"
+	info := Get()
+	if info.Version != "v1.2.3" || info.Commit != "0123456789abcdef" || info.Date != "2026-01-02T03:04:05Z" || info.GoVersion == "" {
+		t.Fatalf("unexpected info %+v", info)
+	}
+
+	Version = ""
+	if got := Get().Version; got != "0123456789ab" && got != vcs().Version {
+		t.Fatalf("expected the short commit, got %q", got)
+	}
+
+	Version, Commit = "", ""
+	if Get().Version == "" {
+		t.Fatal("version must never be empty")
+	}
+}
@@ -0,0 +1,75 @@
+package dataprofile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ReadParquetColumns returns the leaf column names of a Parquet stream,
+// nested columns as dotted paths. Only the footer is kept in memory.
+func ReadParquetColumns(r io.Reader) ([]string, error) {
+	tail := newParquetTail(maxParquetFooterBytes)
+	buf := make([]byte, 32<<10)
+	for {
+		n, err := r.Read(buf)
+		tail.write(buf[:n])
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	meta, err := tail.metadata()
+	if err != nil {
+		return nil, err
+	}
+	leaves, err := meta.leaves()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]string, 0, len(leaves))
+	for _, leaf := range leaves {
+		out = append(out, leaf.path)
+	}
+	return out, nil
+}
+
+// JSONLKeyReport counts JSON Lines records and, per required key, the records
+// that lack it.
+type JSONLKeyReport struct {
+	Records int64            `json:"records"`
+	Missing map[string]int64 `json:"missing,omitempty"`
+}
+
+// CheckJSONLKeys streams JSON Lines records and counts those missing any of
+// keys. A key holding null counts as present. Every record must be an object.
+func CheckJSONLKeys(r io.Reader, keys []string) (JSONLKeyReport, error) {
+	out := JSONLKeyReport{Missing: map[string]int64{}}
+	decoder := json.NewDecoder(r)
+	for {
+		var record map[string]json.RawMessage
+		err := decoder.Decode(&record)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return JSONLKeyReport{}, fmt.Errorf("jsonl record %d: %w", out.Records+1, err)
+		}
+		if record == nil {
+			return JSONLKeyReport{}, fmt.Errorf("jsonl record %d: not an object", out.Records+1)
+		}
+		out.Records++
+		for _, key := range keys {
+			if _, ok := record[key]; !ok {
+				out.Missing[key]++
+			}
+		}
+	}
+	if len(out.Missing) == 0 {
+		out.Missing = nil
+	}
+	return out, nil
+}
@@ -0,0 +1,294 @@
+package dataprofile
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var errCSVAborted = errors.New("csv profiling aborted")
+
+var csvTimeLayouts = []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02"}
+
+const (
+	// reservoirSize bounds the numeric values sampled per column; quantiles
+	// are computed from the sample.
+	reservoirSize = 1024
+	// QuantileCount is the number of quantiles kept per numeric column, from
+	// the minimum to the maximum in steps of 5%.
+	QuantileCount = 21
+	// MaxFrequencyValues bounds the distinct values counted per column;
+	// columns with more values have no frequencies.
+	MaxFrequencyValues = 64
+)
+
+// csvProfiler parses the stream in a goroutine fed through a pipe. After a
+// parse error the goroutine keeps draining the pipe so writers never block.
+type csvProfiler struct {
+	pw     *io.PipeWriter
+	done   chan struct{}
+	result Profile
+	err    error
+}
+
+type csvColumn struct {
+	name    string
+	nonNull int64
+	nulls   int64
+
+	canInt   bool
+	canFloat bool
+	canBool  bool
+	canTime  bool
+
+	minInt, maxInt     int64
+	minFloat, maxFloat float64
+	minTime, maxTime   time.Time
+	minStr, maxStr     string
+	hasTrue, hasFalse  bool
+
+	// reservoir samples the float values; sampled counts those offered.
+	// The generator is seeded per column so profiles are reproducible.
+	reservoir []float64
+	sampled   int64
+	rng       uint64
+	// frequencies is nil once the column exceeds MaxFrequencyValues.
+	frequencies map[string]int64
+}
+
+func newCSVProfiler() *csvProfiler {
+	pr, pw := io.Pipe()
+	p := &csvProfiler{pw: pw, done: make(chan struct{})}
+	go func() {
+		defer close(p.done)
+		p.result, p.err = profileCSV(pr)
+		_, _ = io.Copy(io.Discard, pr)
+	}()
+	return p
+}
+
+func (p *csvProfiler) write(b []byte) {
+	_, _ = p.pw.Write(b)
+}
+
+func (p *csvProfiler) finish() (Profile, error) {
+	_ = p.pw.Close()
+	<-p.done
+	return p.result, p.err
+}
+
+func (p *csvProfiler) abort() {
+	_ = p.pw.CloseWithError(errCSVAborted)
+	<-p.done
+}
+
+func profileCSV(r io.Reader) (Profile, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return Profile{}, errors.New("csv is empty")
+		}
+		return Profile{}, err
+	}
+	if len(header) > MaxColumns {
+		return Profile{}, ErrTooManyColumns
+	}
+	columns := make([]*csvColumn, len(header))
+	for i, name := range header {
+		if i == 0 {
+			name = string(bytes.TrimPrefix([]byte(name), []byte("\xef\xbb\xbf")))
+		}
+		columns[i] = &csvColumn{
+			name:        strings.TrimSpace(name),
+			canInt:      true,
+			canFloat:    true,
+			canBool:     true,
+			canTime:     true,
+			rng:         uint64(i)*0x9e3779b97f4a7c15 + 1,
+			frequencies: map[string]int64{},
+		}
+	}
+
+	var rows int64
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return Profile{}, err
+		}
+		rows++
+		for i, column := range columns {
+			if i >= len(record) {
+				column.nulls++
+				continue
+			}
+			column.observe(record[i])
+		}
+	}
+
+	out := Profile{RowCount: rows, Columns: make([]Column, 0, len(columns))}
+	for _, column := range columns {
+		out.Columns = append(out.Columns, column.column())
+	}
+	return out, nil
+}
+
+func (c *csvColumn) observe(raw string) {
+	value := strings.TrimSpace(raw)
+	if value == "" {
+		c.nulls++
+		return
+	}
+	first := c.nonNull == 0
+	c.nonNull++
+
+	if first || value < c.minStr {
+		c.minStr = value
+	}
+	if first || value > c.maxStr {
+		c.maxStr = value
+	}
+	if c.canInt {
+		if v, err := strconv.ParseInt(value, 10, 64); err == nil {
+			if first || v < c.minInt {
+				c.minInt = v
+			}
+			if first || v > c.maxInt {
+				c.maxInt = v
+			}
+		} else {
+			c.canInt = false
+		}
+	}
+	if c.canFloat {
+		if v, err := strconv.ParseFloat(value, 64); err == nil && !math.IsNaN(v) && !math.IsInf(v, 0) {
+			if first || v < c.minFloat {
+				c.minFloat = v
+			}
+			if first || v > c.maxFloat {
+				c.maxFloat = v
+			}
+			c.sample(v)
+		} else {
+			c.canFloat = false
+			c.reservoir = nil
+		}
+	}
+	if c.frequencies != nil {
+		key := truncateStat(value)
+		if c.canBool {
+			key = strings.ToLower(key)
+		}
+		c.frequencies[key]++
+		if len(c.frequencies) > MaxFrequencyValues {
+			c.frequencies = nil
+		}
+	}
+	if c.canBool {
+		switch {
+		case strings.EqualFold(value, "true"):
+			c.hasTrue = true
+		case strings.EqualFold(value, "false"):
+			c.hasFalse = true
+		default:
+			c.canBool = false
+		}
+	}
+	if c.canTime {
+		if v, ok := parseCSVTime(value); ok {
+			if first || v.Before(c.minTime) {
+				c.minTime = v
+			}
+			if first || v.After(c.maxTime) {
+				c.maxTime = v
+			}
+		} else {
+			c.canTime = false
+		}
+	}
+}
+
+// sample keeps a uniform sample of the column's values (reservoir sampling,
+// algorithm R) driven by a xorshift generator.
+func (c *csvColumn) sample(v float64) {
+	c.sampled++
+	if len(c.reservoir) < reservoirSize {
+		c.reservoir = append(c.reservoir, v)
+		return
+	}
+	c.rng ^= c.rng << 13
+	c.rng ^= c.rng >> 7
+	c.rng ^= c.rng << 17
+	if j := c.rng % uint64(c.sampled); j < reservoirSize {
+		c.reservoir[j] = v
+	}
+}
+
+// quantiles returns QuantileCount evenly spaced quantiles of values, which
+// it sorts in place.
+func quantiles(values []float64) []float64 {
+	if len(values) == 0 {
+		return nil
+	}
+	sort.Float64s(values)
+	out := make([]float64, QuantileCount)
+	last := float64(len(values) - 1)
+	for i := range out {
+		pos := last * float64(i) / float64(QuantileCount-1)
+		lo := int(math.Floor(pos))
+		hi := int(math.Ceil(pos))
+		out[i] = values[lo] + (values[hi]-values[lo])*(pos-float64(lo))
+	}
+	return out
+}
+
+func parseCSVTime(value string) (time.Time, bool) {
+	for _, layout := range csvTimeLayouts {
+		if v, err := time.Parse(layout, value); err == nil {
+			return v.UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// column picks the narrowest type every non-empty value parses as. Columns
+// without values are reported as strings.
+func (c *csvColumn) column() Column {
+	nulls := c.nulls
+	out := Column{Name: c.name, Type: TypeString, NullCount: &nulls}
+	if c.nonNull == 0 {
+		return out
+	}
+	switch {
+	case c.canInt:
+		out.Type, out.Min, out.Max = TypeInteger, c.minInt, c.maxInt
+		out.Quantiles = quantiles(c.reservoir)
+	case c.canFloat:
+		out.Type, out.Min, out.Max = TypeNumber, c.minFloat, c.maxFloat
+		out.Quantiles = quantiles(c.reservoir)
+	case c.canBool:
+		out.Type, out.Min, out.Max = TypeBoolean, !c.hasFalse, c.hasTrue
+		out.Frequencies = c.frequencies
+	case c.canTime:
+		out.Type = TypeTimestamp
+		out.Min = c.minTime.Format(time.RFC3339Nano)
+		out.Max = c.maxTime.Format(time.RFC3339Nano)
+	default:
+		out.Min, out.Max = truncateStat(c.minStr), truncateStat(c.maxStr)
+		out.Frequencies = c.frequencies
+	}
+	return out
+}
@@ -0,0 +1,413 @@
+package dataprofile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestDetectFormat(t *testing.T) {
+	cases := []struct {
+		filename, contentType, want string
+	}{
+		{"data.CSV", "", FormatCSV},
+		{"upload", "text/csv; charset=utf-8", FormatCSV},
+		{"part-0.parquet", "application/octet-stream", FormatParquet},
+		{"upload", "application/vnd.apache.parquet", FormatParquet},
+		{"data.json", "application/json", ""},
+	}
+	for _, tc := range cases {
+		if got := DetectFormat(tc.filename, tc.contentType); got != tc.want {
+			t.Fatalf("DetectFormat(%q, %q) = %q, want %q", tc.filename, tc.contentType, got, tc.want)
+		}
+	}
+	if New("") != nil {
+		t.Fatalf("expected nil profiler for unsupported format")
+	}
+}
+
+func TestProfileCSV(t *testing.T) {
+	input := "\xef\xbb\xbfid,price,active,seen_at,name,empty\n" +
+		"1,2.5,true,2026-01-02,bob,\n" +
+		"3,-1,FALSE,2026-01-01T10:00:00Z,alice,\n" +
+		"2,,true,,carol\n"
+
+	p := New(FormatCSV)
+	// Small writes exercise record boundaries split across chunks.
+	for _, chunk := range strings.SplitAfter(input, ",") {
+		_, _ = p.Write([]byte(chunk))
+	}
+	profile, err := p.Finish()
+	if err != nil {
+		t.Fatalf("Finish: %v", err)
+	}
+	if profile.Format != FormatCSV || profile.RowCount != 3 || len(profile.Columns) != 6 {
+		t.Fatalf("unexpected profile: %+v", profile)
+	}
+
+	want := []struct {
+		name, typ string
+		nulls     int64
+		min, max  any
+	}{
+		{"id", TypeInteger, 0, int64(1), int64(3)},
+		{"price", TypeNumber, 1, -1.0, 2.5},
+		{"active", TypeBoolean, 0, false, true},
+		{"seen_at", TypeTimestamp, 1, "2026-01-01T10:00:00Z", "2026-01-02T00:00:00Z"},
+		{"name", TypeString, 0, "alice", "carol"},
+		{"empty", TypeString, 3, nil, nil},
+	}
+	for i, w := range want {
+		got := profile.Columns[i]
+		if got.Name != w.name || got.Type != w.typ || got.NullCount == nil || *got.NullCount != w.nulls || got.Min != w.min || got.Max != w.max {
+			t.Fatalf("column %d = %+v (nulls %v), want %+v", i, got, got.NullCount, w)
+		}
+	}
+}
+
+func TestProfileCSVErrors(t *testing.T) {
+	p := New(FormatCSV)
+	if _, err := p.Finish(); err == nil {
+		t.Fatalf("expected error for empty csv")
+	}
+
+	p = New(FormatCSV)
+	_, _ = p.Write([]byte(strings.Repeat("c,", MaxColumns) + "c\n"))
+	if _, err := p.Finish(); err != ErrTooManyColumns {
+		t.Fatalf("expected ErrTooManyColumns, got %v", err)
+	}
+
+	// Abort must not block even when the parser already stopped.
+	p = New(FormatCSV)
+	_, _ = p.Write([]byte("a,b\n1,2\n"))
+	p.Abort()
+}
+
+// compactWriter encodes the subset of the thrift compact protocol needed to
+// build a Parquet footer.
+type compactWriter struct {
+	buf  bytes.Buffer
+	last []int16
+}
+
+func (w *compactWriter) begin() { w.last = append(w.last, 0) }
+
+func (w *compactWriter) end() {
+	w.buf.WriteByte(ctStop)
+	w.last = w.last[:len(w.last)-1]
+}
+
+func (w *compactWriter) field(id int16, typ byte) {
+	last := &w.last[len(w.last)-1]
+	w.buf.WriteByte(byte(id-*last)<<4 | typ)
+	*last = id
+}
+
+func (w *compactWriter) varint(v int64) {
+	w.buf.Write(binary.AppendUvarint(nil, uint64(v<<1)^uint64(v>>63)))
+}
+
+func (w *compactWriter) i32(id int16, v int32) {
+	w.field(id, ctI32)
+	w.varint(int64(v))
+}
+
+func (w *compactWriter) i64(id int16, v int64) {
+	w.field(id, ctI64)
+	w.varint(v)
+}
+
+func (w *compactWriter) binary(id int16, b []byte) {
+	w.field(id, ctBinary)
+	w.rawBinary(b)
+}
+
+func (w *compactWriter) rawBinary(b []byte) {
+	w.buf.Write(binary.AppendUvarint(nil, uint64(len(b))))
+	w.buf.Write(b)
+}
+
+func (w *compactWriter) list(id int16, elemType byte, n int) {
+	w.field(id, ctList)
+	w.buf.WriteByte(byte(n)<<4 | elemType)
+}
+
+func (w *compactWriter) structField(id int16) {
+	w.field(id, ctStruct)
+	w.begin()
+}
+
+type testLeaf struct {
+	name     string
+	physical int32
+	annotate func(*compactWriter)
+}
+
+type testStats struct {
+	min, max []byte
+	nulls    int64
+}
+
+func le32(v int32) []byte { return binary.LittleEndian.AppendUint32(nil, uint32(v)) }
+func le64(v int64) []byte { return binary.LittleEndian.AppendUint64(nil, uint64(v)) }
+
+func buildParquet(leaves []testLeaf, groups [][]testStats, rows int64) []byte {
+	w := &compactWriter{}
+	w.begin()
+	w.list(2, ctStruct, len(leaves)+1)
+	w.begin()
+	w.binary(4, []byte("schema"))
+	w.i32(5, int32(len(leaves)))
+	w.end()
+	for _, leaf := range leaves {
+		w.begin()
+		w.i32(1, leaf.physical)
+		w.i32(3, 1)
+		w.binary(4, []byte(leaf.name))
+		if leaf.annotate != nil {
+			leaf.annotate(w)
+		}
+		w.end()
+	}
+	w.i64(3, rows)
+	w.list(4, ctStruct, len(groups))
+	for _, group := range groups {
+		w.begin()
+		w.list(1, ctStruct, len(group))
+		for i, stats := range group {
+			w.begin()
+			w.i64(2, 4)
+			w.structField(3)
+			w.i32(1, leaves[i].physical)
+			w.list(3, ctBinary, 1)
+			w.rawBinary([]byte(leaves[i].name))
+			w.structField(12)
+			w.i64(3, stats.nulls)
+			w.binary(5, stats.max)
+			w.binary(6, stats.min)
+			w.end()
+			w.end()
+			w.end()
+		}
+		w.i64(3, rows/int64(len(groups)))
+		w.end()
+	}
+	w.end()
+
+	footer := w.buf.Bytes()
+	out := append([]byte("PAR1"), bytes.Repeat([]byte{0xAA}, 100)...)
+	out = append(out, footer...)
+	out = binary.LittleEndian.AppendUint32(out, uint32(len(footer)))
+	return append(out, "PAR1"...)
+}
+
+func TestProfileParquet(t *testing.T) {
+	stringType := func(w *compactWriter) {
+		w.structField(10)
+		w.structField(1)
+		w.end()
+		w.end()
+	}
+	dateType := func(w *compactWriter) { w.i32(6, pqConvertedDate) }
+	leaves := []testLeaf{
+		{name: "id", physical: pqInt64},
+		{name: "score", physical: pqDouble},
+		{name: "name", physical: pqByteArray, annotate: stringType},
+		{name: "day", physical: pqInt32, annotate: dateType},
+	}
+	groups := [][]testStats{
+		{
+			{min: le64(5), max: le64(9)},
+			{min: le64(int64(math.Float64bits(0.5))), max: le64(int64(math.Float64bits(1.5))), nulls: 1},
+			{min: []byte("bob"), max: []byte("dave")},
+			{min: le32(20454), max: le32(20455)},
+		},
+		{
+			{min: le64(-2), max: le64(4)},
+			{min: le64(int64(math.Float64bits(-3))), max: le64(int64(math.Float64bits(1))), nulls: 2},
+			{min: []byte("alice"), max: []byte("carol")},
+			{min: le32(20450), max: le32(20451)},
+		},
+	}
+	data := buildParquet(leaves, groups, 10)
+
+	p := New(FormatParquet)
+	for i := 0; i < len(data); i += 7 {
+		_, _ = p.Write(data[i:min(i+7, len(data))])
+	}
+	profile, err := p.Finish()
+	if err != nil {
+		t.Fatalf("Finish: %v", err)
+	}
+	if profile.Format != FormatParquet || profile.RowCount != 10 || len(profile.Columns) != 4 {
+		t.Fatalf("unexpected profile: %+v", profile)
+	}
+	want := []struct {
+		name, typ string
+		nulls     int64
+		min, max  any
+	}{
+		{"id", TypeInteger, 0, int64(-2), int64(9)},
+		{"score", TypeNumber, 3, -3.0, 1.5},
+		{"name", TypeString, 0, "alice", "dave"},
+		{"day", TypeTimestamp, 0, "2025-12-28", "2026-01-02"},
+	}
+	for i, w := range want {
+		got := profile.Columns[i]
+		if got.Name != w.name || got.Type != w.typ || got.NullCount == nil || *got.NullCount != w.nulls || got.Min != w.min || got.Max != w.max {
+			t.Fatalf("column %d = %+v, want %+v", i, got, w)
+		}
+	}
+}
+
+func TestProfileParquetRejectsInvalidInput(t *testing.T) {
+	p := New(FormatParquet)
+	_, _ = p.Write([]byte("id,name\n1,bob\n"))
+	if _, err := p.Finish(); err == nil {
+		t.Fatalf("expected error for non-parquet input")
+	}
+
+	data := buildParquet([]testLeaf{{name: "id", physical: pqInt64}}, [][]testStats{{{min: le64(1), max: le64(2)}}}, 1)
+	copy(data[len(data)-4:], "PARE")
+	p = New(FormatParquet)
+	_, _ = p.Write(data)
+	if _, err := p.Finish(); err == nil || !strings.Contains(err.Error(), "encrypted") {
+		t.Fatalf("expected encrypted footer error, got %v", err)
+	}
+
+	// A footer length pointing past the buffered tail is reported, not panicked on.
+	data = buildParquet([]testLeaf{{name: "id", physical: pqInt64}}, nil, 0)
+	binary.LittleEndian.PutUint32(data[len(data)-8:], uint32(len(data)))
+	p = New(FormatParquet)
+	_, _ = p.Write(data)
+	if _, err := p.Finish(); err == nil {
+		t.Fatalf("expected error for oversized footer length")
+	}
+}
+
+func TestReadParquetColumns(t *testing.T) {
+	data := buildParquet([]testLeaf{{name: "id", physical: pqInt64}, {name: "label", physical: pqByteArray}}, nil, 0)
+	columns, err := ReadParquetColumns(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("ReadParquetColumns: %v", err)
+	}
+	if strings.Join(columns, ",") != "id,label" {
+		t.Fatalf("unexpected columns: %v", columns)
+	}
+	if _, err := ReadParquetColumns(strings.NewReader("id,label\n")); err == nil {
+		t.Fatalf("expected error for non-parquet input")
+	}
+}
+
+func TestCheckJSONLKeys(t *testing.T) {
+	input := `{"id":1,"label":"a"}
+{"id":2,"label":null}
+
+{"id":3}
+`
+	report, err := CheckJSONLKeys(strings.NewReader(input), []string{"id", "label"})
+	if err != nil {
+		t.Fatalf("CheckJSONLKeys: %v", err)
+	}
+	if report.Records != 3 || len(report.Missing) != 1 || report.Missing["label"] != 1 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	for _, input := range []string{"{\"id\":1}\n[1,2]\n", "null\n", "{\"id\":1}\n{broken\n"} {
+		if _, err := CheckJSONLKeys(strings.NewReader(input), []string{"id"}); err == nil {
+			t.Fatalf("expected error for %q", input)
+		}
+	}
+}
+
+func TestPIIScannerCSV(t *testing.T) {
+	input := "id,email,phone,notes\n" +
+		"1,alice@example.com,+7 (495) 123-45-67,card 4111 1111 1111 1111\n" +
+		"2,bob@example.org,415-555-0199,ssn 123-45-6789\n" +
+		"3,,,order 4111 1111 1111 1112 total 1234567890\n"
+	scanner, err := NewPIIScanner(PIIScanConfig{})
+	if err != nil {
+		t.Fatalf("NewPIIScanner: %v", err)
+	}
+	report, err := scanner.ScanCSV(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ScanCSV: %v", err)
+	}
+	if report.Rows != 3 || !report.Passed {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	want := map[string]map[string]int64{
+		"email": {PIIDetectorEmail: 2},
+		"phone": {PIIDetectorPhone: 2},
+		"notes": {PIIDetectorCreditCard: 1, PIIDetectorNationalID: 1},
+	}
+	if len(report.Columns) != len(want) {
+		t.Fatalf("unexpected columns: %v", report.Columns)
+	}
+	for column, counts := range want {
+		for detector, n := range counts {
+			if report.Columns[column][detector] != n {
+				t.Fatalf("%s/%s: expected %d, got %v", column, detector, n, report.Columns[column])
+			}
+		}
+	}
+	if report.Matches != 6 {
+		t.Fatalf("expected 6 matches, got %d", report.Matches)
+	}
+
+	limit := int64(5)
+	gated, err := NewPIIScanner(PIIScanConfig{MaxMatches: &limit})
+	if err != nil {
+		t.Fatalf("NewPIIScanner: %v", err)
+	}
+	report, err = gated.ScanCSV(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ScanCSV: %v", err)
+	}
+	if report.Passed {
+		t.Fatalf("expected scan above max_matches to fail")
+	}
+}
+
+func TestPIIScannerJSONL(t *testing.T) {
+	input := `{"user":{"email":"a@example.com","tags":["x","b@example.com"]},"token":"q8Zr2vLx9TkPw4NyB7mJc3Hs","card":4111111111111111}
+{"user":{"email":null},"token":"aaaaaaaaaaaaaaaaaaaaaaaa"}
+`
+	scanner, err := NewPIIScanner(PIIScanConfig{
+		Detectors: []string{PIIDetectorEmail, PIIDetectorCreditCard, PIIDetectorHighEntropy},
+		Patterns:  map[string]string{"employee_id": `\bEMP-\d{6}\b`},
+		Columns:   []string{"user.email", "user.tags", "token", "card"},
+	})
+	if err != nil {
+		t.Fatalf("NewPIIScanner: %v", err)
+	}
+	report, err := scanner.ScanJSONL(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ScanJSONL: %v", err)
+	}
+	if report.Rows != 2 || report.Columns["user.email"][PIIDetectorEmail] != 1 || report.Columns["user.tags"][PIIDetectorEmail] != 1 {
+		t.Fatalf("unexpected email counts: %+v", report)
+	}
+	if report.Columns["token"][PIIDetectorHighEntropy] != 1 || report.Columns["card"][PIIDetectorCreditCard] != 1 {
+		t.Fatalf("unexpected token/card counts: %+v", report.Columns)
+	}
+	if _, err := scanner.ScanJSONL(strings.NewReader("[1]\n")); err == nil {
+		t.Fatalf("expected error for non-object record")
+	}
+}
+
+func TestNewPIIScannerRejectsInvalidConfig(t *testing.T) {
+	negative := int64(-1)
+	for _, cfg := range []PIIScanConfig{
+		{Detectors: []string{"passport"}},
+		{Patterns: map[string]string{"bad": "("}},
+		{Detectors: []string{PIIDetectorEmail}, Patterns: map[string]string{"Email": `x`}},
+		{MaxMatches: &negative},
+	} {
+		if _, err := NewPIIScanner(cfg); err == nil {
+			t.Fatalf("expected error for %+v", cfg)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package dataprofile
+
+import (
+	"math"
+	"sort"
+)
+
+// Column drift statuses. Only compared columns contribute to the score.
+const (
+	DriftCompared       = "compared"
+	DriftAdded          = "added"
+	DriftRemoved        = "removed"
+	DriftTypeChanged    = "type_changed"
+	DriftNoDistribution = "no_distribution"
+)
+
+// psiEpsilon replaces empty bin shares so PSI stays finite.
+const psiEpsilon = 1e-4
+
+// psiBins is the number of baseline quantile bins numeric PSI uses.
+const psiBins = 10
+
+type ColumnDrift struct {
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Status string `json:"status"`
+	// PSI is the population stability index against the baseline; KS the
+	// Kolmogorov–Smirnov statistic of numeric columns.
+	PSI *float64 `json:"psi,omitempty"`
+	KS  *float64 `json:"ks,omitempty"`
+	// FrequencyShift is the total variation distance between category
+	// shares; CategoryShifts the change of each category's share.
+	FrequencyShift *float64           `json:"frequency_shift,omitempty"`
+	CategoryShifts map[string]float64 `json:"category_shifts,omitempty"`
+}
+
+// Drift compares a profile to a baseline. Score is the largest column PSI.
+type Drift struct {
+	Score            float64       `json:"score"`
+	BaselineRowCount int64         `json:"baseline_row_count"`
+	RowCount         int64         `json:"row_count"`
+	Columns          []ColumnDrift `json:"columns"`
+}
+
+// CompareProfiles computes per-column distribution drift of current against
+// baseline. Numeric columns are compared through their quantiles, string and
+// boolean columns through their frequencies; columns are matched by name.
+func CompareProfiles(baseline, current Profile) Drift {
+	out := Drift{BaselineRowCount: baseline.RowCount, RowCount: current.RowCount, Columns: []ColumnDrift{}}
+	byName := make(map[string]Column, len(baseline.Columns))
+	for _, column := range baseline.Columns {
+		byName[column.Name] = column
+	}
+	seen := make(map[string]bool, len(current.Columns))
+	for _, column := range current.Columns {
+		seen[column.Name] = true
+		base, ok := byName[column.Name]
+		if !ok {
+			out.Columns = append(out.Columns, ColumnDrift{Name: column.Name, Type: column.Type, Status: DriftAdded})
+			continue
+		}
+		drift := compareColumns(base, column)
+		if drift.PSI != nil && *drift.PSI > out.Score {
+			out.Score = *drift.PSI
+		}
+		out.Columns = append(out.Columns, drift)
+	}
+	for _, column := range baseline.Columns {
+		if !seen[column.Name] {
+			out.Columns = append(out.Columns, ColumnDrift{Name: column.Name, Type: column.Type, Status: DriftRemoved})
+		}
+	}
+	return out
+}
+
+func compareColumns(base, current Column) ColumnDrift {
+	out := ColumnDrift{Name: current.Name, Type: current.Type, Status: DriftNoDistribution}
+	if numericType(base.Type) != numericType(current.Type) || (!numericType(base.Type) && base.Type != current.Type) {
+		out.Status = DriftTypeChanged
+		return out
+	}
+	switch {
+	case len(base.Quantiles) > 1 && len(current.Quantiles) > 1:
+		psi := roundDrift(quantilePSI(base.Quantiles, current.Quantiles))
+		ks := roundDrift(quantileKS(base.Quantiles, current.Quantiles))
+		out.Status, out.PSI, out.KS = DriftCompared, &psi, &ks
+	case len(base.Frequencies) > 0 && len(current.Frequencies) > 0:
+		psi, shift, shifts := frequencyDrift(base.Frequencies, current.Frequencies)
+		out.Status, out.PSI, out.FrequencyShift, out.CategoryShifts = DriftCompared, &psi, &shift, shifts
+	}
+	return out
+}
+
+// numericType treats integer and number columns as comparable: a column
+// that gained decimals has not changed meaning.
+func numericType(columnType string) bool {
+	return columnType == TypeInteger || columnType == TypeNumber
+}
+
+// quantileCDF approximates the share of values at or below x by linear
+// interpolation between evenly spaced quantiles.
+func quantileCDF(q []float64, x float64) float64 {
+	n := len(q)
+	if x < q[0] {
+		return 0
+	}
+	if x >= q[n-1] {
+		return 1
+	}
+	i := sort.Search(n, func(i int) bool { return q[i] > x }) - 1
+	step := 1 / float64(n-1)
+	return float64(i)*step + step*(x-q[i])/(q[i+1]-q[i])
+}
+
+// quantileBinShares splits the value range at edges and returns each bin's
+// share under the distribution described by q.
+func quantileBinShares(q []float64, edges []float64) []float64 {
+	out := make([]float64, 0, len(edges)+1)
+	prev := 0.0
+	for _, edge := range edges {
+		cdf := quantileCDF(q, edge)
+		out = append(out, cdf-prev)
+		prev = cdf
+	}
+	return append(out, 1-prev)
+}
+
+// quantilePSI bins both distributions at the baseline deciles.
+func quantilePSI(base, current []float64) float64 {
+	edges := []float64{}
+	for i := 1; i < psiBins; i++ {
+		edge := base[i*(len(base)-1)/psiBins]
+		if len(edges) == 0 || edge > edges[len(edges)-1] {
+			edges = append(edges, edge)
+		}
+	}
+	return psi(quantileBinShares(base, edges), quantileBinShares(current, edges))
+}
+
+// quantileKS is the largest CDF gap, checked at every quantile of either
+// distribution.
+func quantileKS(base, current []float64) float64 {
+	out := 0.0
+	for _, points := range [][]float64{base, current} {
+		for _, x := range points {
+			if gap := math.Abs(quantileCDF(base, x) - quantileCDF(current, x)); gap > out {
+				out = gap
+			}
+		}
+	}
+	return out
+}
+
+// frequencyDrift compares category shares. Categories missing on one side
+// count as a zero share.
+func frequencyDrift(base, current map[string]int64) (float64, float64, map[string]float64) {
+	baseTotal, currentTotal := frequencyTotal(base), frequencyTotal(current)
+	keys := make([]string, 0, len(base)+len(current))
+	for key := range base {
+		keys = append(keys, key)
+	}
+	for key := range current {
+		if _, ok := base[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	expected := make([]float64, len(keys))
+	actual := make([]float64, len(keys))
+	shifts := map[string]float64{}
+	distance := 0.0
+	for i, key := range keys {
+		expected[i] = float64(base[key]) / baseTotal
+		actual[i] = float64(current[key]) / currentTotal
+		delta := actual[i] - expected[i]
+		distance += math.Abs(delta)
+		if rounded := roundDrift(delta); rounded != 0 {
+			shifts[key] = rounded
+		}
+	}
+	if len(shifts) == 0 {
+		shifts = nil
+	}
+	return roundDrift(psi(expected, actual)), roundDrift(distance / 2), shifts
+}
+
+func frequencyTotal(frequencies map[string]int64) float64 {
+	var total int64
+	for _, count := range frequencies {
+		total += count
+	}
+	return float64(total)
+}
+
+func psi(expected, actual []float64) float64 {
+	out := 0.0
+	for i := range expected {
+		e := math.Max(expected[i], psiEpsilon)
+		a := math.Max(actual[i], psiEpsilon)
+		out += (a - e) * math.Log(a/e)
+	}
+	return out
+}
+
+// roundDrift keeps six decimals so stored reports do not carry float noise.
+func roundDrift(v float64) float64 {
+	return math.Round(v*1e6) / 1e6
+}
@@ -0,0 +1,83 @@
+package dataprofile
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func profileOf(t *testing.T, input string) Profile {
+	t.Helper()
+	p := New(FormatCSV)
+	_, _ = p.Write([]byte(input))
+	profile, err := p.Finish()
+	if err != nil {
+		t.Fatalf("Finish: %v", err)
+	}
+	return profile
+}
+
+func driftCSV(offset int, colors ...string) string {
+	var b strings.Builder
+	b.WriteString("value,color\n")
+	for i := 0; i < 2000; i++ {
+		fmt.Fprintf(&b, "%d,%s\n", i%100+offset, colors[i%len(colors)])
+	}
+	return b.String()
+}
+
+func TestProfileCSVDistributions(t *testing.T) {
+	profile := profileOf(t, driftCSV(0, "red", "blue"))
+	value, color := profile.Columns[0], profile.Columns[1]
+	if len(value.Quantiles) != QuantileCount || value.Quantiles[0] != 0 || value.Quantiles[QuantileCount-1] != 99 {
+		t.Fatalf("quantiles=%v", value.Quantiles)
+	}
+	if value.Frequencies != nil {
+		t.Fatalf("numeric column has frequencies: %v", value.Frequencies)
+	}
+	if !reflect.DeepEqual(color.Frequencies, map[string]int64{"red": 1000, "blue": 1000}) {
+		t.Fatalf("frequencies=%v", color.Frequencies)
+	}
+
+	var b strings.Builder
+	b.WriteString("id\n")
+	for i := 0; i <= MaxFrequencyValues; i++ {
+		fmt.Fprintf(&b, "id-%d\n", i)
+	}
+	if wide := profileOf(t, b.String()); wide.Columns[0].Frequencies != nil {
+		t.Fatalf("expected high-cardinality column to drop frequencies")
+	}
+}
+
+func TestCompareProfiles(t *testing.T) {
+	baseline := profileOf(t, driftCSV(0, "red", "blue"))
+	same := CompareProfiles(baseline, profileOf(t, driftCSV(0, "blue", "red")))
+	if same.Score != 0 || same.Columns[0].Status != DriftCompared || *same.Columns[0].KS != 0 || same.Columns[1].CategoryShifts != nil {
+		t.Fatalf("identical profiles drifted: %+v", same)
+	}
+
+	shifted := CompareProfiles(baseline, profileOf(t, driftCSV(50, "red", "red", "red", "green")))
+	value, color := shifted.Columns[0], shifted.Columns[1]
+	if *value.KS < 0.4 || *value.PSI < 0.25 {
+		t.Fatalf("numeric drift too small: psi=%v ks=%v", *value.PSI, *value.KS)
+	}
+	if *color.FrequencyShift != 0.5 || color.CategoryShifts["blue"] != -0.5 || color.CategoryShifts["green"] != 0.25 {
+		t.Fatalf("category drift: %+v", color)
+	}
+	if shifted.Score < *value.PSI || shifted.Score < *color.PSI {
+		t.Fatalf("score %v below a column psi", shifted.Score)
+	}
+
+	changed := CompareProfiles(
+		Profile{Columns: []Column{{Name: "a", Type: TypeString}, {Name: "gone", Type: TypeInteger}}},
+		Profile{Columns: []Column{{Name: "a", Type: TypeInteger}, {Name: "new", Type: TypeNumber}}},
+	)
+	var statuses []string
+	for _, column := range changed.Columns {
+		statuses = append(statuses, column.Name+":"+column.Status)
+	}
+	if want := []string{"a:type_changed", "new:added", "gone:removed"}; !reflect.DeepEqual(statuses, want) {
+		t.Fatalf("statuses=%v", statuses)
+	}
+}
@@ -0,0 +1,271 @@
+package dataprofile
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Check types of animus.quality.rule.v1 that read the content of a dataset
+// version. The quality evaluator hands every check whose type is registered
+// here to EvaluateContentCheck with a reader over the version's object; its
+// own checks (object_size_bytes, csv_header_has_columns, ...) stay there.
+const (
+	CheckParquetSchemaHasColumns = "parquet_schema_has_columns"
+	CheckJSONLRequiredKeys       = "jsonl_required_keys"
+	CheckPIIScan                 = "pii_scan"
+)
+
+// FormatJSONL is JSON Lines content, one object per line.
+const FormatJSONL = "jsonl"
+
+// Check statuses, as in the evaluation report.
+const (
+	CheckPass  = "pass"
+	CheckFail  = "fail"
+	CheckError = "error"
+)
+
+// CheckSpec is one content check of a rule; fields a type does not use are
+// ignored. The pii_scan fields are those of PIIScanConfig, with Columns
+// restricting the scan.
+type CheckSpec struct {
+	ID               string            `json:"id"`
+	Type             string            `json:"type"`
+	Columns          []string          `json:"columns,omitempty"`
+	Keys             []string          `json:"keys,omitempty"`
+	Detectors        []string          `json:"detectors,omitempty"`
+	Patterns         map[string]string `json:"patterns,omitempty"`
+	EntropyMinLength int               `json:"entropy_min_length,omitempty"`
+	EntropyThreshold float64           `json:"entropy_threshold,omitempty"`
+	MaxMatches       *int64            `json:"max_matches,omitempty"`
+}
+
+// CheckResult is the outcome of one content check. Error is set for
+// status error, when the content could not be read as the check requires.
+type CheckResult struct {
+	ID             string          `json:"id"`
+	Type           string          `json:"type"`
+	Status         string          `json:"status"`
+	Error          string          `json:"error,omitempty"`
+	MissingColumns []string        `json:"missing_columns,omitempty"`
+	JSONL          *JSONLKeyReport `json:"jsonl,omitempty"`
+	PII            *PIIScanReport  `json:"pii,omitempty"`
+}
+
+type contentCheck struct {
+	validate func(spec CheckSpec) error
+	run      func(spec CheckSpec, format string, r io.Reader) (CheckResult, error)
+}
+
+var contentChecks = map[string]contentCheck{
+	CheckParquetSchemaHasColumns: {validate: requireColumns, run: runParquetSchemaHasColumns},
+	CheckJSONLRequiredKeys:       {validate: requireKeys, run: runJSONLRequiredKeys},
+	CheckPIIScan:                 {validate: validatePIIScan, run: runPIIScan},
+}
+
+// IsContentCheck reports whether checkType is evaluated by
+// EvaluateContentCheck.
+func IsContentCheck(checkType string) bool {
+	_, ok := contentChecks[checkType]
+	return ok
+}
+
+// ContentCheckTypes lists the registered content check types.
+func ContentCheckTypes() []string {
+	out := make([]string, 0, len(contentChecks))
+	for checkType := range contentChecks {
+		out = append(out, checkType)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// ValidateCheckSpec checks a content check when its rule is created.
+func ValidateCheckSpec(spec CheckSpec) error {
+	if strings.TrimSpace(spec.ID) == "" {
+		return errors.New("check id is required")
+	}
+	check, ok := contentChecks[spec.Type]
+	if !ok {
+		return fmt.Errorf("unknown content check type %q", spec.Type)
+	}
+	return check.validate(spec)
+}
+
+// DetectCheckFormat returns the content format of a dataset object: csv,
+// parquet, jsonl, or "" when unknown.
+func DetectCheckFormat(filename, contentType string) string {
+	if format := DetectFormat(filename, contentType); format != "" {
+		return format
+	}
+	ext := strings.ToLower(path.Ext(filename))
+	contentType = strings.ToLower(contentType)
+	if ext == ".jsonl" || ext == ".ndjson" || strings.Contains(contentType, "jsonl") || strings.Contains(contentType, "ndjson") {
+		return FormatJSONL
+	}
+	return ""
+}
+
+// EvaluateContentCheck runs spec over r, content of the given format. Read
+// and format errors yield status error rather than fail.
+func EvaluateContentCheck(spec CheckSpec, format string, r io.Reader) CheckResult {
+	check, ok := contentChecks[spec.Type]
+	if !ok {
+		return CheckResult{ID: spec.ID, Type: spec.Type, Status: CheckError, Error: fmt.Sprintf("unknown content check type %q", spec.Type)}
+	}
+	if err := check.validate(spec); err != nil {
+		return CheckResult{ID: spec.ID, Type: spec.Type, Status: CheckError, Error: err.Error()}
+	}
+	result, err := check.run(spec, format, r)
+	if err != nil {
+		return CheckResult{ID: spec.ID, Type: spec.Type, Status: CheckError, Error: err.Error()}
+	}
+	result.ID, result.Type = spec.ID, spec.Type
+	return result
+}
+
+// EvaluationSummary aggregates check results into the evaluation summary.
+// PIIMatches holds the match counts of each pii_scan check that ran, by
+// check id, then column and detector.
+type EvaluationSummary struct {
+	ChecksTotal     int                                    `json:"checks_total"`
+	ChecksPass      int                                    `json:"checks_pass"`
+	ChecksFail      int                                    `json:"checks_fail"`
+	ChecksError     int                                    `json:"checks_error"`
+	FailingCheckIDs []string                               `json:"failing_check_ids,omitempty"`
+	PIIMatches      map[string]map[string]map[string]int64 `json:"pii_matches,omitempty"`
+}
+
+// Summarize counts results by status; failing ids include errored checks.
+func Summarize(results []CheckResult) EvaluationSummary {
+	out := EvaluationSummary{ChecksTotal: len(results)}
+	for _, result := range results {
+		if result.PII != nil {
+			if out.PIIMatches == nil {
+				out.PIIMatches = map[string]map[string]map[string]int64{}
+			}
+			columns := result.PII.Columns
+			if columns == nil {
+				columns = map[string]map[string]int64{}
+			}
+			out.PIIMatches[result.ID] = columns
+		}
+		switch result.Status {
+		case CheckPass:
+			out.ChecksPass++
+			continue
+		case CheckFail:
+			out.ChecksFail++
+		default:
+			out.ChecksError++
+		}
+		out.FailingCheckIDs = append(out.FailingCheckIDs, result.ID)
+	}
+	return out
+}
+
+func requireColumns(spec CheckSpec) error {
+	if len(nonEmpty(spec.Columns)) == 0 {
+		return fmt.Errorf("%s requires columns", spec.Type)
+	}
+	return nil
+}
+
+func requireKeys(spec CheckSpec) error {
+	if len(nonEmpty(spec.Keys)) == 0 {
+		return fmt.Errorf("%s requires keys", spec.Type)
+	}
+	return nil
+}
+
+func nonEmpty(values []string) []string {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func runParquetSchemaHasColumns(spec CheckSpec, format string, r io.Reader) (CheckResult, error) {
+	if format != FormatParquet {
+		return CheckResult{}, fmt.Errorf("%s needs parquet content, got %q", spec.Type, format)
+	}
+	columns, err := ReadParquetColumns(r)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	have := make(map[string]bool, len(columns))
+	for _, column := range columns {
+		have[column] = true
+	}
+	out := CheckResult{Status: CheckPass}
+	for _, column := range nonEmpty(spec.Columns) {
+		if !have[column] {
+			out.MissingColumns = append(out.MissingColumns, column)
+		}
+	}
+	if len(out.MissingColumns) > 0 {
+		out.Status = CheckFail
+	}
+	return out, nil
+}
+
+func runJSONLRequiredKeys(spec CheckSpec, format string, r io.Reader) (CheckResult, error) {
+	if format != FormatJSONL {
+		return CheckResult{}, fmt.Errorf("%s needs jsonl content, got %q", spec.Type, format)
+	}
+	report, err := CheckJSONLKeys(r, nonEmpty(spec.Keys))
+	if err != nil {
+		return CheckResult{}, err
+	}
+	out := CheckResult{Status: CheckPass, JSONL: &report}
+	if len(report.Missing) > 0 {
+		out.Status = CheckFail
+	}
+	return out, nil
+}
+
+func (spec CheckSpec) piiScanConfig() PIIScanConfig {
+	return PIIScanConfig{
+		Detectors:        spec.Detectors,
+		Patterns:         spec.Patterns,
+		Columns:          nonEmpty(spec.Columns),
+		EntropyMinLength: spec.EntropyMinLength,
+		EntropyThreshold: spec.EntropyThreshold,
+		MaxMatches:       spec.MaxMatches,
+	}
+}
+
+func validatePIIScan(spec CheckSpec) error {
+	if _, err := NewPIIScanner(spec.piiScanConfig()); err != nil {
+		return fmt.Errorf("%s: %w", spec.Type, err)
+	}
+	return nil
+}
+
+// runPIIScan fails when matches exceed max_matches; without it the check
+// only reports counts.
+func runPIIScan(spec CheckSpec, format string, r io.Reader) (CheckResult, error) {
+	if format != FormatCSV && format != FormatJSONL {
+		return CheckResult{}, fmt.Errorf("%s needs csv or jsonl content, got %q", spec.Type, format)
+	}
+	scanner, err := NewPIIScanner(spec.piiScanConfig())
+	if err != nil {
+		return CheckResult{}, err
+	}
+	report, err := scanner.Scan(format, r)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	out := CheckResult{Status: CheckPass, PII: &report}
+	if !report.Passed {
+		out.Status = CheckFail
+	}
+	return out, nil
+}
@@ -0,0 +1,92 @@
+package dataprofile
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestEvaluateContentChecks(t *testing.T) {
+	parquet := buildParquet([]testLeaf{{name: "id", physical: pqInt64}, {name: "label", physical: pqByteArray}}, nil, 0)
+	jsonl := "{\"id\":1,\"label\":\"a\"}\n{\"id\":2}\n"
+
+	results := []CheckResult{
+		EvaluateContentCheck(CheckSpec{ID: "pq", Type: CheckParquetSchemaHasColumns, Columns: []string{"id", "label"}}, FormatParquet, bytes.NewReader(parquet)),
+		EvaluateContentCheck(CheckSpec{ID: "pq-missing", Type: CheckParquetSchemaHasColumns, Columns: []string{"id", "score"}}, FormatParquet, bytes.NewReader(parquet)),
+		EvaluateContentCheck(CheckSpec{ID: "keys", Type: CheckJSONLRequiredKeys, Keys: []string{"id"}}, FormatJSONL, strings.NewReader(jsonl)),
+		EvaluateContentCheck(CheckSpec{ID: "keys-missing", Type: CheckJSONLRequiredKeys, Keys: []string{"id", "label"}}, FormatJSONL, strings.NewReader(jsonl)),
+		EvaluateContentCheck(CheckSpec{ID: "wrong-format", Type: CheckParquetSchemaHasColumns, Columns: []string{"id"}}, FormatCSV, strings.NewReader("id\n1\n")),
+	}
+	wantStatus := []string{CheckPass, CheckFail, CheckPass, CheckFail, CheckError}
+	for i, result := range results {
+		if result.Status != wantStatus[i] {
+			t.Fatalf("check %s status=%s (%s), want %s", result.ID, result.Status, result.Error, wantStatus[i])
+		}
+	}
+	if strings.Join(results[1].MissingColumns, ",") != "score" {
+		t.Fatalf("missing columns = %v", results[1].MissingColumns)
+	}
+	if results[3].JSONL == nil || results[3].JSONL.Missing["label"] != 1 {
+		t.Fatalf("jsonl report = %+v", results[3].JSONL)
+	}
+
+	summary := Summarize(results)
+	if summary.ChecksTotal != 5 || summary.ChecksPass != 2 || summary.ChecksFail != 2 || summary.ChecksError != 1 ||
+		strings.Join(summary.FailingCheckIDs, ",") != "pq-missing,keys-missing,wrong-format" {
+		t.Fatalf("summary = %+v", summary)
+	}
+}
+
+func TestContentCheckRegistry(t *testing.T) {
+	for _, checkType := range []string{CheckParquetSchemaHasColumns, CheckJSONLRequiredKeys, CheckPIIScan} {
+		if !IsContentCheck(checkType) {
+			t.Fatalf("%s not registered", checkType)
+		}
+	}
+	if IsContentCheck("csv_header_has_columns") {
+		t.Fatalf("evaluator-owned check registered as content check")
+	}
+	if err := ValidateCheckSpec(CheckSpec{ID: "keys", Type: CheckJSONLRequiredKeys}); err == nil {
+		t.Fatalf("expected error for jsonl_required_keys without keys")
+	}
+	if got := DetectCheckFormat("events.ndjson", ""); got != FormatJSONL {
+		t.Fatalf("DetectCheckFormat = %q", got)
+	}
+}
+
+func TestPIIScanCheckSummary(t *testing.T) {
+	csvData := "name,email,phone\nann,ann@example.com,415-555-0199\nbob,bob@example.org,\n"
+	jsonl := "{\"user\":{\"email\":\"eve@example.com\"}}\n{\"user\":{\"email\":\"none\"}}\n"
+	zero := int64(0)
+
+	results := []CheckResult{
+		EvaluateContentCheck(CheckSpec{ID: "csv-pii", Type: CheckPIIScan}, FormatCSV, strings.NewReader(csvData)),
+		EvaluateContentCheck(CheckSpec{ID: "jsonl-pii", Type: CheckPIIScan, Detectors: []string{PIIDetectorEmail}, Columns: []string{"user.email"}, MaxMatches: &zero}, FormatJSONL, strings.NewReader(jsonl)),
+		EvaluateContentCheck(CheckSpec{ID: "parquet-pii", Type: CheckPIIScan}, FormatParquet, strings.NewReader("")),
+	}
+	wantStatus := []string{CheckPass, CheckFail, CheckError}
+	for i, result := range results {
+		if result.Status != wantStatus[i] {
+			t.Fatalf("check %s status=%s (%s), want %s", result.ID, result.Status, result.Error, wantStatus[i])
+		}
+	}
+
+	summary := Summarize(results)
+	if strings.Join(summary.FailingCheckIDs, ",") != "jsonl-pii,parquet-pii" {
+		t.Fatalf("failing checks = %v", summary.FailingCheckIDs)
+	}
+	if len(summary.PIIMatches) != 2 {
+		t.Fatalf("pii matches = %+v", summary.PIIMatches)
+	}
+	csvCounts := summary.PIIMatches["csv-pii"]
+	if csvCounts["email"][PIIDetectorEmail] != 2 || csvCounts["phone"][PIIDetectorPhone] != 1 || len(csvCounts["name"]) != 0 {
+		t.Fatalf("csv counts = %+v", csvCounts)
+	}
+	if summary.PIIMatches["jsonl-pii"]["user.email"][PIIDetectorEmail] != 1 {
+		t.Fatalf("jsonl counts = %+v", summary.PIIMatches["jsonl-pii"])
+	}
+
+	if err := ValidateCheckSpec(CheckSpec{ID: "bad", Type: CheckPIIScan, Detectors: []string{"passport"}}); err == nil {
+		t.Fatalf("expected error for unknown detector")
+	}
+}
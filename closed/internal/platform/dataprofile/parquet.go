@@ -0,0 +1,599 @@
+package dataprofile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// maxParquetFooterBytes bounds the tail kept in memory while streaming; the
+// footer holds schema and statistics, not data.
+const maxParquetFooterBytes = 16 << 20
+
+var parquetMagic = []byte("PAR1")
+
+// Parquet physical types.
+const (
+	pqBoolean int32 = iota
+	pqInt32
+	pqInt64
+	pqInt96
+	pqFloat
+	pqDouble
+	pqByteArray
+	pqFixedLenByteArray
+)
+
+// Parquet converted types used for type mapping.
+const (
+	pqConvertedUTF8            int32 = 0
+	pqConvertedEnum            int32 = 4
+	pqConvertedDecimal         int32 = 5
+	pqConvertedDate            int32 = 6
+	pqConvertedTimestampMillis int32 = 9
+	pqConvertedTimestampMicros int32 = 10
+	pqConvertedUint8           int32 = 11
+	pqConvertedUint64          int32 = 14
+	pqConvertedJSON            int32 = 19
+)
+
+// Parquet logical type union members.
+const (
+	pqLogicalString    int16 = 1
+	pqLogicalEnum      int16 = 4
+	pqLogicalDecimal   int16 = 5
+	pqLogicalDate      int16 = 6
+	pqLogicalTimestamp int16 = 8
+	pqLogicalInteger   int16 = 10
+	pqLogicalJSON      int16 = 12
+)
+
+// parquetTail keeps the first bytes (for the leading magic) and the last
+// limit+8 bytes of the stream.
+type parquetTail struct {
+	limit int
+	head  []byte
+	tail  []byte
+	size  int64
+}
+
+func newParquetTail(limit int) *parquetTail {
+	return &parquetTail{limit: limit}
+}
+
+func (t *parquetTail) write(b []byte) {
+	t.size += int64(len(b))
+	if need := len(parquetMagic) - len(t.head); need > 0 {
+		t.head = append(t.head, b[:min(need, len(b))]...)
+	}
+	keep := t.limit + 8
+	if len(b) >= keep {
+		t.tail = append(t.tail[:0], b[len(b)-keep:]...)
+		return
+	}
+	t.tail = append(t.tail, b...)
+	// Compact only once the buffer doubles to keep writes amortized O(n).
+	if len(t.tail) > 2*keep {
+		t.tail = append(t.tail[:0], t.tail[len(t.tail)-keep:]...)
+	}
+}
+
+func (t *parquetTail) profile() (Profile, error) {
+	meta, err := t.metadata()
+	if err != nil {
+		return Profile{}, err
+	}
+	return meta.profile()
+}
+
+func (t *parquetTail) metadata() (pqFileMetaData, error) {
+	if t.size < int64(2*len(parquetMagic)+4) || !bytes.Equal(t.head, parquetMagic) {
+		return pqFileMetaData{}, errors.New("not a parquet file")
+	}
+	tail := t.tail
+	if !bytes.Equal(tail[len(tail)-4:], parquetMagic) {
+		if bytes.Equal(tail[len(tail)-4:], []byte("PARE")) {
+			return pqFileMetaData{}, errors.New("encrypted parquet footers are not supported")
+		}
+		return pqFileMetaData{}, errors.New("not a parquet file")
+	}
+	footerLen := int(binary.LittleEndian.Uint32(tail[len(tail)-8:]))
+	if footerLen > t.limit {
+		return pqFileMetaData{}, fmt.Errorf("parquet footer exceeds %d bytes", t.limit)
+	}
+	if footerLen > len(tail)-8 || int64(footerLen) > t.size-int64(2*len(parquetMagic)+4) {
+		return pqFileMetaData{}, errThriftTruncated
+	}
+	return parseParquetFooter(tail[len(tail)-8-footerLen : len(tail)-8])
+}
+
+type pqSchemaElement struct {
+	name         string
+	physical     int32
+	numChildren  int32
+	converted    int32
+	hasConverted bool
+	logical      int16
+	timeUnit     int16
+	unsigned     bool
+	scale        int32
+}
+
+type pqStats struct {
+	hasNulls  bool
+	nullCount int64
+	min, max  []byte
+	legacyMin []byte
+	legacyMax []byte
+}
+
+type pqChunk struct {
+	path  string
+	stats *pqStats
+}
+
+type pqFileMetaData struct {
+	schema    []pqSchemaElement
+	numRows   int64
+	rowGroups [][]pqChunk
+}
+
+func parseParquetFooter(footer []byte) (pqFileMetaData, error) {
+	r := &thriftReader{buf: footer}
+	var meta pqFileMetaData
+	err := r.readStruct(func(id int16, typ byte) error {
+		switch {
+		case id == 2 && typ == ctList:
+			return r.readList(func(byte) error {
+				element, err := readSchemaElement(r)
+				meta.schema = append(meta.schema, element)
+				return err
+			})
+		case id == 3 && typ == ctI64:
+			v, err := r.varint()
+			meta.numRows = v
+			return err
+		case id == 4 && typ == ctList:
+			return r.readList(func(byte) error {
+				chunks, err := readRowGroup(r)
+				meta.rowGroups = append(meta.rowGroups, chunks)
+				return err
+			})
+		default:
+			return r.skip(typ)
+		}
+	})
+	if err != nil {
+		return pqFileMetaData{}, fmt.Errorf("invalid parquet footer: %w", err)
+	}
+	return meta, nil
+}
+
+func readSchemaElement(r *thriftReader) (pqSchemaElement, error) {
+	var out pqSchemaElement
+	err := r.readStruct(func(id int16, typ byte) error {
+		var err error
+		switch {
+		case id == 1 && typ == ctI32:
+			out.physical, err = r.i32()
+		case id == 4 && typ == ctBinary:
+			var name []byte
+			name, err = r.binary()
+			out.name = string(name)
+		case id == 5 && typ == ctI32:
+			out.numChildren, err = r.i32()
+		case id == 6 && typ == ctI32:
+			out.converted, err = r.i32()
+			out.hasConverted = true
+		case id == 7 && typ == ctI32:
+			out.scale, err = r.i32()
+		case id == 10 && typ == ctStruct:
+			err = readLogicalType(r, &out)
+		default:
+			err = r.skip(typ)
+		}
+		return err
+	})
+	return out, err
+}
+
+// readLogicalType records the union member and, for timestamps and integers,
+// the unit and signedness needed to decode statistics.
+func readLogicalType(r *thriftReader, out *pqSchemaElement) error {
+	return r.readStruct(func(id int16, typ byte) error {
+		out.logical = id
+		if typ != ctStruct {
+			return r.skip(typ)
+		}
+		return r.readStruct(func(field int16, typ byte) error {
+			switch {
+			case id == pqLogicalTimestamp && field == 2 && typ == ctStruct:
+				return r.readStruct(func(unit int16, typ byte) error {
+					out.timeUnit = unit
+					return r.skip(typ)
+				})
+			case id == pqLogicalInteger && field == 2:
+				out.unsigned = typ == ctBoolFalse
+				return r.skip(typ)
+			case id == pqLogicalDecimal && field == 1 && typ == ctI32:
+				v, err := r.i32()
+				out.scale = v
+				return err
+			default:
+				return r.skip(typ)
+			}
+		})
+	})
+}
+
+func readRowGroup(r *thriftReader) ([]pqChunk, error) {
+	var out []pqChunk
+	err := r.readStruct(func(id int16, typ byte) error {
+		if id == 1 && typ == ctList {
+			return r.readList(func(byte) error {
+				chunk, err := readColumnChunk(r)
+				out = append(out, chunk)
+				return err
+			})
+		}
+		return r.skip(typ)
+	})
+	return out, err
+}
+
+func readColumnChunk(r *thriftReader) (pqChunk, error) {
+	var out pqChunk
+	err := r.readStruct(func(id int16, typ byte) error {
+		if id != 3 || typ != ctStruct {
+			return r.skip(typ)
+		}
+		return r.readStruct(func(id int16, typ byte) error {
+			switch {
+			case id == 3 && typ == ctList:
+				var parts []string
+				err := r.readList(func(byte) error {
+					part, err := r.binary()
+					parts = append(parts, string(part))
+					return err
+				})
+				out.path = strings.Join(parts, ".")
+				return err
+			case id == 12 && typ == ctStruct:
+				stats, err := readStatistics(r)
+				out.stats = &stats
+				return err
+			default:
+				return r.skip(typ)
+			}
+		})
+	})
+	return out, err
+}
+
+func readStatistics(r *thriftReader) (pqStats, error) {
+	var out pqStats
+	err := r.readStruct(func(id int16, typ byte) error {
+		var err error
+		switch {
+		case id == 1 && typ == ctBinary:
+			out.legacyMax, err = r.binary()
+		case id == 2 && typ == ctBinary:
+			out.legacyMin, err = r.binary()
+		case id == 3 && typ == ctI64:
+			out.nullCount, err = r.varint()
+			out.hasNulls = true
+		case id == 5 && typ == ctBinary:
+			out.max, err = r.binary()
+		case id == 6 && typ == ctBinary:
+			out.min, err = r.binary()
+		default:
+			err = r.skip(typ)
+		}
+		return err
+	})
+	return out, err
+}
+
+type pqLeaf struct {
+	path    string
+	element pqSchemaElement
+}
+
+// leaves flattens the schema tree; nested columns are named by their dotted
+// path, matching path_in_schema of the column chunks.
+func (m pqFileMetaData) leaves() ([]pqLeaf, error) {
+	if len(m.schema) == 0 {
+		return nil, errors.New("parquet schema is empty")
+	}
+	var out []pqLeaf
+	var walk func(index int, prefix string) (int, error)
+	walk = func(index int, prefix string) (int, error) {
+		if index >= len(m.schema) {
+			return 0, errors.New("parquet schema is truncated")
+		}
+		element := m.schema[index]
+		name := element.name
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+		next := index + 1
+		if element.numChildren <= 0 {
+			out = append(out, pqLeaf{path: name, element: element})
+			return next, nil
+		}
+		for i := int32(0); i < element.numChildren; i++ {
+			var err error
+			if next, err = walk(next, name); err != nil {
+				return 0, err
+			}
+		}
+		return next, nil
+	}
+	root := m.schema[0]
+	next := 1
+	for i := int32(0); i < root.numChildren; i++ {
+		var err error
+		if next, err = walk(next, ""); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+func (m pqFileMetaData) profile() (Profile, error) {
+	leaves, err := m.leaves()
+	if err != nil {
+		return Profile{}, err
+	}
+	if len(leaves) > MaxColumns {
+		return Profile{}, ErrTooManyColumns
+	}
+	out := Profile{RowCount: m.numRows, Columns: make([]Column, 0, len(leaves))}
+	for _, leaf := range leaves {
+		column := Column{Name: leaf.path, Type: leaf.element.columnType()}
+		var (
+			nulls      int64
+			nullsKnown = len(m.rowGroups) > 0
+			minValue   any
+			maxValue   any
+		)
+		for _, group := range m.rowGroups {
+			stats := chunkStats(group, leaf.path)
+			if stats == nil || !stats.hasNulls {
+				nullsKnown = false
+			} else {
+				nulls += stats.nullCount
+			}
+			if stats == nil {
+				continue
+			}
+			minRaw, maxRaw := stats.min, stats.max
+			if minRaw == nil && maxRaw == nil && leaf.element.legacyStatsOrdered() {
+				minRaw, maxRaw = stats.legacyMin, stats.legacyMax
+			}
+			if v, ok := leaf.element.decode(minRaw); ok && (minValue == nil || compareStat(v, minValue) < 0) {
+				minValue = v
+			}
+			if v, ok := leaf.element.decode(maxRaw); ok && (maxValue == nil || compareStat(v, maxValue) > 0) {
+				maxValue = v
+			}
+		}
+		if nullsKnown {
+			column.NullCount = &nulls
+		}
+		column.Min = leaf.element.display(minValue)
+		column.Max = leaf.element.display(maxValue)
+		out.Columns = append(out.Columns, column)
+	}
+	return out, nil
+}
+
+func chunkStats(group []pqChunk, path string) *pqStats {
+	for _, chunk := range group {
+		if chunk.path == path {
+			return chunk.stats
+		}
+	}
+	return nil
+}
+
+func (e pqSchemaElement) isString() bool {
+	switch e.logical {
+	case pqLogicalString, pqLogicalEnum, pqLogicalJSON:
+		return true
+	}
+	if e.hasConverted {
+		switch e.converted {
+		case pqConvertedUTF8, pqConvertedEnum, pqConvertedJSON:
+			return true
+		}
+	}
+	return false
+}
+
+func (e pqSchemaElement) isDate() bool {
+	return e.logical == pqLogicalDate || (e.hasConverted && e.converted == pqConvertedDate)
+}
+
+func (e pqSchemaElement) isDecimal() bool {
+	return e.logical == pqLogicalDecimal || (e.hasConverted && e.converted == pqConvertedDecimal)
+}
+
+// timestampUnit returns the number of units per second, or 0 when the
+// column is not a timestamp.
+func (e pqSchemaElement) timestampUnit() int64 {
+	if e.logical == pqLogicalTimestamp {
+		switch e.timeUnit {
+		case 1:
+			return 1_000
+		case 2:
+			return 1_000_000
+		case 3:
+			return 1_000_000_000
+		}
+	}
+	if e.hasConverted {
+		switch e.converted {
+		case pqConvertedTimestampMillis:
+			return 1_000
+		case pqConvertedTimestampMicros:
+			return 1_000_000
+		}
+	}
+	return 0
+}
+
+func (e pqSchemaElement) isUnsigned() bool {
+	return e.unsigned || (e.hasConverted && e.converted >= pqConvertedUint8 && e.converted <= pqConvertedUint64)
+}
+
+func (e pqSchemaElement) columnType() string {
+	switch {
+	case e.isString():
+		return TypeString
+	case e.isDate(), e.timestampUnit() > 0, e.physical == pqInt96:
+		return TypeTimestamp
+	case e.isDecimal():
+		return TypeNumber
+	}
+	switch e.physical {
+	case pqBoolean:
+		return TypeBoolean
+	case pqInt32, pqInt64:
+		return TypeInteger
+	case pqFloat, pqDouble:
+		return TypeNumber
+	default:
+		return TypeBinary
+	}
+}
+
+// legacyStatsOrdered reports whether the deprecated min/max fields can be
+// trusted; writers ordered byte arrays and unsigned values inconsistently.
+func (e pqSchemaElement) legacyStatsOrdered() bool {
+	switch e.physical {
+	case pqBoolean, pqInt32, pqInt64, pqFloat, pqDouble:
+		return !e.isUnsigned()
+	default:
+		return false
+	}
+}
+
+// decode turns a raw statistic into a comparable value: bool, int64, uint64,
+// float64 or string. Decimals are scaled to float64.
+func (e pqSchemaElement) decode(raw []byte) (any, bool) {
+	if raw == nil {
+		return nil, false
+	}
+	switch e.physical {
+	case pqBoolean:
+		if len(raw) != 1 {
+			return nil, false
+		}
+		return raw[0] != 0, true
+	case pqInt32, pqInt64:
+		var (
+			signed   int64
+			unsigned uint64
+		)
+		switch {
+		case e.physical == pqInt32 && len(raw) == 4:
+			unsigned = uint64(binary.LittleEndian.Uint32(raw))
+			signed = int64(int32(binary.LittleEndian.Uint32(raw)))
+		case e.physical == pqInt64 && len(raw) == 8:
+			unsigned = binary.LittleEndian.Uint64(raw)
+			signed = int64(unsigned)
+		default:
+			return nil, false
+		}
+		switch {
+		case e.isDecimal():
+			return float64(signed) / math.Pow10(int(e.scale)), true
+		case e.isUnsigned():
+			return unsigned, true
+		default:
+			return signed, true
+		}
+	case pqFloat:
+		if len(raw) != 4 {
+			return nil, false
+		}
+		v := float64(math.Float32frombits(binary.LittleEndian.Uint32(raw)))
+		return v, !math.IsNaN(v)
+	case pqDouble:
+		if len(raw) != 8 {
+			return nil, false
+		}
+		v := math.Float64frombits(binary.LittleEndian.Uint64(raw))
+		return v, !math.IsNaN(v)
+	case pqByteArray, pqFixedLenByteArray:
+		if !e.isString() {
+			return nil, false
+		}
+		return string(raw), true
+	default:
+		return nil, false
+	}
+}
+
+// display formats dates and timestamps and truncates strings.
+func (e pqSchemaElement) display(value any) any {
+	switch v := value.(type) {
+	case int64:
+		if e.isDate() {
+			return time.Unix(v*86400, 0).UTC().Format("2006-01-02")
+		}
+		if unit := e.timestampUnit(); unit > 0 {
+			return time.Unix(v/unit, (v%unit)*(1_000_000_000/unit)).UTC().Format(time.RFC3339Nano)
+		}
+		return v
+	case string:
+		return truncateStat(v)
+	default:
+		return value
+	}
+}
+
+func compareStat(a, b any) int {
+	switch x := a.(type) {
+	case bool:
+		y, _ := b.(bool)
+		switch {
+		case x == y:
+			return 0
+		case !x:
+			return -1
+		default:
+			return 1
+		}
+	case int64:
+		y, _ := b.(int64)
+		return cmpOrdered(x, y)
+	case uint64:
+		y, _ := b.(uint64)
+		return cmpOrdered(x, y)
+	case float64:
+		y, _ := b.(float64)
+		return cmpOrdered(x, y)
+	case string:
+		y, _ := b.(string)
+		return strings.Compare(x, y)
+	default:
+		return 0
+	}
+}
+
+func cmpOrdered[T int64 | uint64 | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,363 @@
+package dataprofile
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Built-in detectors of the pii_scan check. high_entropy flags long
+// token-like strings (keys, secrets) and only runs when listed explicitly.
+const (
+	PIIDetectorEmail       = "email"
+	PIIDetectorPhone       = "phone"
+	PIIDetectorNationalID  = "national_id"
+	PIIDetectorCreditCard  = "credit_card"
+	PIIDetectorHighEntropy = "high_entropy"
+)
+
+const (
+	defaultEntropyMinLength = 20
+	defaultEntropyThreshold = 4.0
+
+	// maxPIICellBytes bounds the part of a cell the detectors look at.
+	maxPIICellBytes = 64 << 10
+	maxPIIPatterns  = 32
+)
+
+var defaultPIIDetectors = []string{PIIDetectorEmail, PIIDetectorPhone, PIIDetectorNationalID, PIIDetectorCreditCard}
+
+var (
+	piiEmailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}`)
+	// Phone numbers need separators between groups so plain numeric ids and
+	// amounts do not match: +7 (495) 123-45-67, 415-555-0199.
+	piiPhonePattern = regexp.MustCompile(`(?:\+\d{1,3}[ .-]?)?(?:\(\d{2,4}\)[ .-]?|\b\d{2,4}[ .-])\d{3}[ .-]?\d{2}[ .-]?\d{2}\b`)
+	// National ids: US SSN (123-45-6789) and RU SNILS (123-456-789 01).
+	piiNationalIDPattern = regexp.MustCompile(`\b(?:\d{3}-\d{2}-\d{4}|\d{3}-\d{3}-\d{3}[ -]\d{2})\b`)
+	// Card candidates are confirmed with the Luhn checksum.
+	piiCardPattern    = regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`)
+	piiEntropyPattern = regexp.MustCompile(`[A-Za-z0-9+/=_-]+`)
+)
+
+// PIIScanConfig configures the pii_scan check. Detectors lists built-in
+// detectors (all but high_entropy when empty); Patterns adds named regular
+// expressions. Columns restricts the scan, JSONL keys as dotted paths. When
+// MaxMatches is set the scan fails once total matches exceed it.
+type PIIScanConfig struct {
+	Detectors        []string          `json:"detectors,omitempty"`
+	Patterns         map[string]string `json:"patterns,omitempty"`
+	Columns          []string          `json:"columns,omitempty"`
+	EntropyMinLength int               `json:"entropy_min_length,omitempty"`
+	EntropyThreshold float64           `json:"entropy_threshold,omitempty"`
+	MaxMatches       *int64            `json:"max_matches,omitempty"`
+}
+
+// PIIScanReport holds match counts per column and detector.
+type PIIScanReport struct {
+	Rows       int64                       `json:"rows"`
+	Matches    int64                       `json:"matches"`
+	Columns    map[string]map[string]int64 `json:"columns,omitempty"`
+	MaxMatches *int64                      `json:"max_matches,omitempty"`
+	Passed     bool                        `json:"passed"`
+}
+
+type piiDetector struct {
+	name  string
+	count func(value string) int64
+}
+
+// PIIScanner runs the configured detectors over CSV or JSON Lines content.
+type PIIScanner struct {
+	detectors  []piiDetector
+	columns    map[string]bool
+	maxMatches *int64
+}
+
+// NewPIIScanner validates cfg and compiles its detectors.
+func NewPIIScanner(cfg PIIScanConfig) (*PIIScanner, error) {
+	if cfg.MaxMatches != nil && *cfg.MaxMatches < 0 {
+		return nil, errors.New("max_matches must be non-negative")
+	}
+	if cfg.EntropyMinLength < 0 || cfg.EntropyThreshold < 0 {
+		return nil, errors.New("entropy settings must be non-negative")
+	}
+	if len(cfg.Patterns) > maxPIIPatterns {
+		return nil, fmt.Errorf("at most %d patterns allowed", maxPIIPatterns)
+	}
+	minLength := cfg.EntropyMinLength
+	if minLength == 0 {
+		minLength = defaultEntropyMinLength
+	}
+	threshold := cfg.EntropyThreshold
+	if threshold == 0 {
+		threshold = defaultEntropyThreshold
+	}
+
+	names := cfg.Detectors
+	if len(names) == 0 && len(cfg.Patterns) == 0 {
+		names = defaultPIIDetectors
+	}
+	s := &PIIScanner{maxMatches: cfg.MaxMatches}
+	seen := map[string]bool{}
+	for _, raw := range names {
+		name := strings.ToLower(strings.TrimSpace(raw))
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		switch name {
+		case PIIDetectorEmail:
+			s.detectors = append(s.detectors, piiDetector{name: name, count: regexpCounter(piiEmailPattern)})
+		case PIIDetectorPhone:
+			s.detectors = append(s.detectors, piiDetector{name: name, count: regexpCounter(piiPhonePattern)})
+		case PIIDetectorNationalID:
+			s.detectors = append(s.detectors, piiDetector{name: name, count: regexpCounter(piiNationalIDPattern)})
+		case PIIDetectorCreditCard:
+			s.detectors = append(s.detectors, piiDetector{name: name, count: countCreditCards})
+		case PIIDetectorHighEntropy:
+			s.detectors = append(s.detectors, piiDetector{name: name, count: entropyCounter(minLength, threshold)})
+		default:
+			return nil, fmt.Errorf("unknown detector %q", raw)
+		}
+	}
+
+	patternNames := make([]string, 0, len(cfg.Patterns))
+	for name := range cfg.Patterns {
+		patternNames = append(patternNames, name)
+	}
+	sort.Strings(patternNames)
+	for _, name := range patternNames {
+		key := strings.ToLower(strings.TrimSpace(name))
+		if key == "" || seen[key] {
+			return nil, fmt.Errorf("invalid or duplicate pattern name %q", name)
+		}
+		seen[key] = true
+		pattern, err := regexp.Compile(cfg.Patterns[name])
+		if err != nil {
+			return nil, fmt.Errorf("pattern %q: %w", name, err)
+		}
+		s.detectors = append(s.detectors, piiDetector{name: key, count: regexpCounter(pattern)})
+	}
+
+	if len(cfg.Columns) > 0 {
+		s.columns = make(map[string]bool, len(cfg.Columns))
+		for _, column := range cfg.Columns {
+			s.columns[strings.TrimSpace(column)] = true
+		}
+	}
+	return s, nil
+}
+
+// Scan dispatches on format: csv, or jsonl for JSON Lines.
+func (s *PIIScanner) Scan(format string, r io.Reader) (PIIScanReport, error) {
+	switch format {
+	case FormatCSV:
+		return s.ScanCSV(r)
+	case FormatJSONL:
+		return s.ScanJSONL(r)
+	default:
+		return PIIScanReport{}, fmt.Errorf("pii scan does not support format %q", format)
+	}
+}
+
+// ScanCSV streams CSV records; the first record is the header.
+func (s *PIIScanner) ScanCSV(r io.Reader) (PIIScanReport, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return PIIScanReport{}, errors.New("csv is empty")
+		}
+		return PIIScanReport{}, err
+	}
+	if len(header) > MaxColumns {
+		return PIIScanReport{}, ErrTooManyColumns
+	}
+	names := make([]string, len(header))
+	for i, name := range header {
+		if i == 0 {
+			name = string(bytes.TrimPrefix([]byte(name), []byte("\xef\xbb\xbf")))
+		}
+		names[i] = strings.TrimSpace(name)
+	}
+
+	out := s.newReport()
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return PIIScanReport{}, err
+		}
+		out.Rows++
+		for i, value := range record {
+			if i < len(names) {
+				s.observe(&out, names[i], value)
+			}
+		}
+	}
+	return s.finish(out), nil
+}
+
+// ScanJSONL streams JSON Lines records. String values are scanned under
+// their dotted key path; numbers are scanned as written so unquoted card
+// numbers are found too.
+func (s *PIIScanner) ScanJSONL(r io.Reader) (PIIScanReport, error) {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+	out := s.newReport()
+	for {
+		var record map[string]any
+		err := decoder.Decode(&record)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return PIIScanReport{}, fmt.Errorf("jsonl record %d: %w", out.Rows+1, err)
+		}
+		if record == nil {
+			return PIIScanReport{}, fmt.Errorf("jsonl record %d: not an object", out.Rows+1)
+		}
+		out.Rows++
+		s.observeJSON(&out, "", record)
+	}
+	return s.finish(out), nil
+}
+
+func (s *PIIScanner) newReport() PIIScanReport {
+	return PIIScanReport{Columns: map[string]map[string]int64{}, MaxMatches: s.maxMatches}
+}
+
+func (s *PIIScanner) observeJSON(out *PIIScanReport, path string, value any) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+			s.observeJSON(out, childPath, child)
+		}
+	case []any:
+		for _, child := range v {
+			s.observeJSON(out, path, child)
+		}
+	case string:
+		s.observe(out, path, v)
+	case json.Number:
+		s.observe(out, path, v.String())
+	}
+}
+
+func (s *PIIScanner) observe(out *PIIScanReport, column, value string) {
+	if value == "" || (s.columns != nil && !s.columns[column]) {
+		return
+	}
+	if len(value) > maxPIICellBytes {
+		value = value[:maxPIICellBytes]
+	}
+	for _, detector := range s.detectors {
+		n := detector.count(value)
+		if n == 0 {
+			continue
+		}
+		counts := out.Columns[column]
+		if counts == nil {
+			if len(out.Columns) >= MaxColumns {
+				continue
+			}
+			counts = map[string]int64{}
+			out.Columns[column] = counts
+		}
+		counts[detector.name] += n
+		out.Matches += n
+	}
+}
+
+func (s *PIIScanner) finish(out PIIScanReport) PIIScanReport {
+	if len(out.Columns) == 0 {
+		out.Columns = nil
+	}
+	out.Passed = s.maxMatches == nil || out.Matches <= *s.maxMatches
+	return out
+}
+
+func regexpCounter(pattern *regexp.Regexp) func(string) int64 {
+	return func(value string) int64 {
+		return int64(len(pattern.FindAllStringIndex(value, -1)))
+	}
+}
+
+func countCreditCards(value string) int64 {
+	var n int64
+	for _, candidate := range piiCardPattern.FindAllString(value, -1) {
+		digits := strings.NewReplacer(" ", "", "-", "").Replace(candidate)
+		if len(digits) >= 13 && len(digits) <= 19 && luhnValid(digits) {
+			n++
+		}
+	}
+	return n
+}
+
+func luhnValid(digits string) bool {
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d, err := strconv.Atoi(digits[i : i+1])
+		if err != nil {
+			return false
+		}
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+func entropyCounter(minLength int, threshold float64) func(string) int64 {
+	return func(value string) int64 {
+		var n int64
+		for _, token := range piiEntropyPattern.FindAllString(value, -1) {
+			if len(token) >= minLength && shannonEntropy(token) >= threshold {
+				n++
+			}
+		}
+		return n
+	}
+}
+
+// shannonEntropy returns bits per byte of value.
+func shannonEntropy(value string) float64 {
+	var freq [256]int
+	for i := 0; i < len(value); i++ {
+		freq[value[i]]++
+	}
+	var entropy float64
+	length := float64(len(value))
+	for _, count := range freq {
+		if count == 0 {
+			continue
+		}
+		p := float64(count) / length
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
@@ -0,0 +1,134 @@
+// Package dataprofile infers a column profile (names, types, row count,
+// min/max and null counts) from CSV and Parquet content while it is uploaded,
+// and compares profiles for drift.
+package dataprofile
+
+import (
+	"errors"
+	"path"
+	"strings"
+)
+
+const (
+	FormatCSV     = "csv"
+	FormatParquet = "parquet"
+)
+
+// Column types share the vocabulary of data contract schemas, plus binary for
+// Parquet byte arrays without a string annotation.
+const (
+	TypeString    = "string"
+	TypeInteger   = "integer"
+	TypeNumber    = "number"
+	TypeBoolean   = "boolean"
+	TypeTimestamp = "timestamp"
+	TypeBinary    = "binary"
+)
+
+// MaxColumns bounds the profile size; wider files are not profiled.
+const MaxColumns = 1024
+
+// maxStringStat truncates string min/max values kept in the profile.
+const maxStringStat = 64
+
+var ErrTooManyColumns = errors.New("too many columns")
+
+type Column struct {
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	NullCount *int64 `json:"null_count,omitempty"`
+	Min       any    `json:"min,omitempty"`
+	Max       any    `json:"max,omitempty"`
+	// Quantiles (numeric columns) and Frequencies (string and boolean
+	// columns of low cardinality) describe the value distribution for drift
+	// detection. Parquet profiles are read from the footer and have neither.
+	Quantiles   []float64        `json:"quantiles,omitempty"`
+	Frequencies map[string]int64 `json:"frequencies,omitempty"`
+}
+
+type Profile struct {
+	Format   string   `json:"format"`
+	RowCount int64    `json:"row_count"`
+	Columns  []Column `json:"columns"`
+}
+
+// DetectFormat returns the profile format for an upload, or "" when the file
+// is neither CSV nor Parquet.
+func DetectFormat(filename, contentType string) string {
+	ext := strings.ToLower(path.Ext(filename))
+	contentType = strings.ToLower(contentType)
+	switch {
+	case ext == ".csv" || strings.Contains(contentType, "csv"):
+		return FormatCSV
+	case ext == ".parquet" || strings.Contains(contentType, "parquet"):
+		return FormatParquet
+	default:
+		return ""
+	}
+}
+
+// Profiler consumes the upload stream through Write. Finish must be called
+// once the stream ends; Abort releases resources of an abandoned upload.
+type Profiler struct {
+	format  string
+	csv     *csvProfiler
+	parquet *parquetTail
+}
+
+// New returns nil for unsupported formats.
+func New(format string) *Profiler {
+	switch format {
+	case FormatCSV:
+		return &Profiler{format: format, csv: newCSVProfiler()}
+	case FormatParquet:
+		return &Profiler{format: format, parquet: newParquetTail(maxParquetFooterBytes)}
+	default:
+		return nil
+	}
+}
+
+func (p *Profiler) Format() string {
+	return p.format
+}
+
+// Write never fails, so a profiling problem cannot abort the upload.
+func (p *Profiler) Write(b []byte) (int, error) {
+	if p.csv != nil {
+		p.csv.write(b)
+	}
+	if p.parquet != nil {
+		p.parquet.write(b)
+	}
+	return len(b), nil
+}
+
+func (p *Profiler) Finish() (Profile, error) {
+	var (
+		out Profile
+		err error
+	)
+	if p.csv != nil {
+		out, err = p.csv.finish()
+	} else {
+		out, err = p.parquet.profile()
+	}
+	if err != nil {
+		return Profile{}, err
+	}
+	out.Format = p.format
+	return out, nil
+}
+
+func (p *Profiler) Abort() {
+	if p != nil && p.csv != nil {
+		p.csv.abort()
+	}
+}
+
+func truncateStat(value string) string {
+	runes := []rune(value)
+	if len(runes) <= maxStringStat {
+		return value
+	}
+	return string(runes[:maxStringStat])
+}
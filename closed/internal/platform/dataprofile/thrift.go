@@ -0,0 +1,213 @@
+package dataprofile
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// Thrift compact protocol types, as used by the Parquet footer.
+const (
+	ctStop      byte = 0
+	ctBoolTrue  byte = 1
+	ctBoolFalse byte = 2
+	ctByte      byte = 3
+	ctI16       byte = 4
+	ctI32       byte = 5
+	ctI64       byte = 6
+	ctDouble    byte = 7
+	ctBinary    byte = 8
+	ctList      byte = 9
+	ctSet       byte = 10
+	ctMap       byte = 11
+	ctStruct    byte = 12
+)
+
+const maxThriftDepth = 64
+
+var errThriftTruncated = errors.New("parquet footer truncated")
+
+// thriftReader decodes just enough of the compact protocol to walk the
+// Parquet FileMetaData; unknown fields are skipped.
+type thriftReader struct {
+	buf   []byte
+	pos   int
+	depth int
+}
+
+func (r *thriftReader) readByte() (byte, error) {
+	if r.pos >= len(r.buf) {
+		return 0, errThriftTruncated
+	}
+	b := r.buf[r.pos]
+	r.pos++
+	return b, nil
+}
+
+func (r *thriftReader) uvarint() (uint64, error) {
+	v, n := binary.Uvarint(r.buf[r.pos:])
+	if n <= 0 {
+		return 0, errThriftTruncated
+	}
+	r.pos += n
+	return v, nil
+}
+
+func (r *thriftReader) varint() (int64, error) {
+	v, err := r.uvarint()
+	if err != nil {
+		return 0, err
+	}
+	return int64(v>>1) ^ -int64(v&1), nil
+}
+
+func (r *thriftReader) i32() (int32, error) {
+	v, err := r.varint()
+	if err != nil {
+		return 0, err
+	}
+	if v < math.MinInt32 || v > math.MaxInt32 {
+		return 0, errors.New("parquet footer: i32 out of range")
+	}
+	return int32(v), nil
+}
+
+func (r *thriftReader) binary() ([]byte, error) {
+	n, err := r.uvarint()
+	if err != nil {
+		return nil, err
+	}
+	if n > uint64(len(r.buf)-r.pos) {
+		return nil, errThriftTruncated
+	}
+	out := r.buf[r.pos : r.pos+int(n)]
+	r.pos += int(n)
+	return out, nil
+}
+
+func (r *thriftReader) listHeader() (byte, int, error) {
+	b, err := r.readByte()
+	if err != nil {
+		return 0, 0, err
+	}
+	size := uint64(b >> 4)
+	if size == 15 {
+		if size, err = r.uvarint(); err != nil {
+			return 0, 0, err
+		}
+	}
+	// Every element takes at least one byte.
+	if size > uint64(len(r.buf)-r.pos) {
+		return 0, 0, errThriftTruncated
+	}
+	return b & 0x0f, int(size), nil
+}
+
+// readStruct calls fn for every field; fn must consume the value, typically
+// by delegating unknown fields to skip. Boolean fields carry their value in
+// the type and have nothing to consume.
+func (r *thriftReader) readStruct(fn func(id int16, typ byte) error) error {
+	r.depth++
+	defer func() { r.depth-- }()
+	if r.depth > maxThriftDepth {
+		return errors.New("parquet footer nested too deeply")
+	}
+	var last int16
+	for {
+		b, err := r.readByte()
+		if err != nil {
+			return err
+		}
+		typ := b & 0x0f
+		if typ == ctStop {
+			return nil
+		}
+		id := last + int16(b>>4)
+		if b>>4 == 0 {
+			v, err := r.varint()
+			if err != nil {
+				return err
+			}
+			id = int16(v)
+		}
+		last = id
+		if err := fn(id, typ); err != nil {
+			return err
+		}
+	}
+}
+
+func (r *thriftReader) readList(fn func(elemType byte) error) error {
+	elemType, n, err := r.listHeader()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < n; i++ {
+		if err := fn(elemType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *thriftReader) skip(typ byte) error {
+	switch typ {
+	case ctBoolTrue, ctBoolFalse:
+		return nil
+	case ctByte:
+		_, err := r.readByte()
+		return err
+	case ctI16, ctI32, ctI64:
+		_, err := r.uvarint()
+		return err
+	case ctDouble:
+		if len(r.buf)-r.pos < 8 {
+			return errThriftTruncated
+		}
+		r.pos += 8
+		return nil
+	case ctBinary:
+		_, err := r.binary()
+		return err
+	case ctList, ctSet:
+		return r.readList(r.skipElement)
+	case ctMap:
+		n, err := r.uvarint()
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return nil
+		}
+		if n > uint64(len(r.buf)-r.pos) {
+			return errThriftTruncated
+		}
+		kv, err := r.readByte()
+		if err != nil {
+			return err
+		}
+		for i := uint64(0); i < n; i++ {
+			if err := r.skipElement(kv >> 4); err != nil {
+				return err
+			}
+			if err := r.skipElement(kv & 0x0f); err != nil {
+				return err
+			}
+		}
+		return nil
+	case ctStruct:
+		return r.readStruct(func(_ int16, typ byte) error { return r.skip(typ) })
+	default:
+		return errors.New("parquet footer: unknown thrift type")
+	}
+}
+
+// skipElement skips a collection element; booleans inside collections take
+// a byte, unlike boolean fields.
+func (r *thriftReader) skipElement(typ byte) error {
+	if typ == ctBoolTrue || typ == ctBoolFalse {
+		_, err := r.readByte()
+		return err
+	}
+	return r.skip(typ)
+}
@@ -46,3 +46,14 @@ func Int(key string, def int) (int, error) {
 	}
 	return def, nil
 }
+
+func Float(key string, def float64) (float64, error) {
+	if v, ok := os.LookupEnv(key); ok {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return 0, fmt.Errorf("parse %s: %w", key, err)
+		}
+		return f, nil
+	}
+	return def, nil
+}
@@ -107,3 +107,32 @@ func TestInt_Invalid(t *testing.T) {
 		t.Fatalf("Int() expected error")
 	}
 }
+
+func TestFloat_Default(t *testing.T) {
+	got, err := Float("ENV_FLOAT_DOES_NOT_EXIST", 1.5)
+	if err != nil {
+		t.Fatalf("Float() err=%v", err)
+	}
+	if got != 1.5 {
+		t.Fatalf("Float()=%v, want 1.5", got)
+	}
+}
+
+func TestFloat_Override(t *testing.T) {
+	t.Setenv("ENV_FLOAT_KEY", "0.25")
+	got, err := Float("ENV_FLOAT_KEY", 1.5)
+	if err != nil {
+		t.Fatalf("Float() err=%v", err)
+	}
+	if got != 0.25 {
+		t.Fatalf("Float()=%v, want 0.25", got)
+	}
+}
+
+func TestFloat_Invalid(t *testing.T) {
+	t.Setenv("ENV_FLOAT_KEY_INVALID", "nope")
+	_, err := Float("ENV_FLOAT_KEY_INVALID", 1.5)
+	if err == nil {
+		t.Fatalf("Float() expected error")
+	}
+}
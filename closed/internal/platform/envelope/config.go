@@ -0,0 +1,113 @@
+package envelope
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/env"
+)
+
+const (
+	ProviderNone         = "none"
+	ProviderLocal        = "local"
+	ProviderVaultTransit = "vault_transit"
+)
+
+type Config struct {
+	Provider          string
+	LocalActiveKeyID  string
+	LocalKeys         map[string][]byte
+	VaultAddr         string
+	VaultToken        string
+	VaultTransitMount string
+	VaultTransitKey   string
+	VaultNamespace    string
+	VaultTimeout      time.Duration
+}
+
+func ConfigFromEnv() (Config, error) {
+	localKeys, err := parseLocalKeys(env.String("ANIMUS_ENCRYPTION_LOCAL_KEYS", ""))
+	if err != nil {
+		return Config{}, err
+	}
+	vaultTimeout, err := env.Duration("ANIMUS_ENCRYPTION_VAULT_TIMEOUT", 5*time.Second)
+	if err != nil {
+		return Config{}, err
+	}
+	cfg := Config{
+		Provider:          strings.ToLower(strings.TrimSpace(env.String("ANIMUS_ENCRYPTION_PROVIDER", ProviderNone))),
+		LocalActiveKeyID:  strings.TrimSpace(env.String("ANIMUS_ENCRYPTION_LOCAL_ACTIVE_KEY_ID", "")),
+		LocalKeys:         localKeys,
+		VaultAddr:         strings.TrimSpace(env.String("ANIMUS_ENCRYPTION_VAULT_ADDR", "")),
+		VaultToken:        strings.TrimSpace(env.String("ANIMUS_ENCRYPTION_VAULT_TOKEN", "")),
+		VaultTransitMount: strings.TrimSpace(env.String("ANIMUS_ENCRYPTION_VAULT_TRANSIT_MOUNT", "transit")),
+		VaultTransitKey:   strings.TrimSpace(env.String("ANIMUS_ENCRYPTION_VAULT_TRANSIT_KEY", "")),
+		VaultNamespace:    strings.TrimSpace(env.String("ANIMUS_ENCRYPTION_VAULT_NAMESPACE", "")),
+		VaultTimeout:      vaultTimeout,
+	}
+	return cfg, cfg.Validate()
+}
+
+func (c Config) Validate() error {
+	switch c.provider() {
+	case ProviderNone:
+		return nil
+	case ProviderLocal:
+		if strings.TrimSpace(c.LocalActiveKeyID) == "" {
+			return fmt.Errorf("local active key id is required")
+		}
+		if _, ok := c.LocalKeys[strings.TrimSpace(c.LocalActiveKeyID)]; !ok {
+			return fmt.Errorf("local active key %q is not configured", c.LocalActiveKeyID)
+		}
+		return nil
+	case ProviderVaultTransit:
+		if strings.TrimSpace(c.VaultAddr) == "" {
+			return fmt.Errorf("vault addr is required")
+		}
+		if strings.TrimSpace(c.VaultToken) == "" {
+			return fmt.Errorf("vault token is required")
+		}
+		if strings.TrimSpace(c.VaultTransitKey) == "" {
+			return fmt.Errorf("vault transit key is required")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported encryption provider: %s", c.Provider)
+	}
+}
+
+func (c Config) provider() string {
+	provider := strings.ToLower(strings.TrimSpace(c.Provider))
+	if provider == "" {
+		return ProviderNone
+	}
+	return provider
+}
+
+// parseLocalKeys reads "id=base64key,id2=base64key". Keeping retired keys in
+// the list lets objects wrapped by them still be decrypted.
+func parseLocalKeys(raw string) (map[string][]byte, error) {
+	out := map[string][]byte{}
+	for _, item := range strings.Split(raw, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		id, encoded, ok := strings.Cut(item, "=")
+		id = strings.TrimSpace(id)
+		if !ok || id == "" {
+			return nil, fmt.Errorf("invalid ANIMUS_ENCRYPTION_LOCAL_KEYS entry")
+		}
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil {
+			return nil, fmt.Errorf("invalid ANIMUS_ENCRYPTION_LOCAL_KEYS key %q: %w", id, err)
+		}
+		if len(key) != 32 {
+			return nil, fmt.Errorf("ANIMUS_ENCRYPTION_LOCAL_KEYS key %q must be 32 bytes", id)
+		}
+		out[id] = key
+	}
+	return out, nil
+}
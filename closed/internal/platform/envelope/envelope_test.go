@@ -0,0 +1,205 @@
+package envelope
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func testKey(t *testing.T) []byte {
+	t.Helper()
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatalf("rand: %v", err)
+	}
+	return key
+}
+
+func TestStreamRoundTrip(t *testing.T) {
+	dataKey := testKey(t)
+	for _, size := range []int{0, 1, chunkSize - 1, chunkSize, chunkSize + 1, 3*chunkSize + 17} {
+		plaintext := bytes.Repeat([]byte{0x5a}, size)
+		sealed, err := Seal(dataKey, plaintext)
+		if err != nil {
+			t.Fatalf("size=%d: seal: %v", size, err)
+		}
+		if int64(len(sealed)) != EncryptedSize(int64(size)) {
+			t.Fatalf("size=%d: len=%d, want %d", size, len(sealed), EncryptedSize(int64(size)))
+		}
+		opened, err := Open(dataKey, sealed)
+		if err != nil {
+			t.Fatalf("size=%d: open: %v", size, err)
+		}
+		if !bytes.Equal(opened, plaintext) {
+			t.Fatalf("size=%d: plaintext mismatch", size)
+		}
+	}
+}
+
+func TestDecryptSeekerRanges(t *testing.T) {
+	dataKey := testKey(t)
+	plaintext := make([]byte, 2*chunkSize+100)
+	if _, err := rand.Read(plaintext); err != nil {
+		t.Fatalf("rand: %v", err)
+	}
+	sealed, err := Seal(dataKey, plaintext)
+	if err != nil {
+		t.Fatalf("seal: %v", err)
+	}
+	seeker, err := NewDecryptSeeker(dataKey, bytes.NewReader(sealed), int64(len(plaintext)))
+	if err != nil {
+		t.Fatalf("seeker: %v", err)
+	}
+	size, err := seeker.Seek(0, io.SeekEnd)
+	if err != nil || size != int64(len(plaintext)) {
+		t.Fatalf("size=%d err=%v", size, err)
+	}
+	for _, r := range [][2]int{{0, 10}, {chunkSize - 5, chunkSize + 5}, {2 * chunkSize, len(plaintext)}, {0, len(plaintext)}} {
+		if _, err := seeker.Seek(int64(r[0]), io.SeekStart); err != nil {
+			t.Fatalf("seek %d: %v", r[0], err)
+		}
+		got := make([]byte, r[1]-r[0])
+		if _, err := io.ReadFull(seeker, got); err != nil {
+			t.Fatalf("range %v: %v", r, err)
+		}
+		if !bytes.Equal(got, plaintext[r[0]:r[1]]) {
+			t.Fatalf("range %v: plaintext mismatch", r)
+		}
+	}
+	if _, err := seeker.Read(make([]byte, 1)); err != io.EOF {
+		t.Fatalf("expected EOF at end, got %v", err)
+	}
+
+	tampered := append([]byte(nil), sealed...)
+	tampered[len(tampered)-1] ^= 0x01
+	seeker, err = NewDecryptSeeker(dataKey, bytes.NewReader(tampered), int64(len(plaintext)))
+	if err != nil {
+		t.Fatalf("seeker: %v", err)
+	}
+	if _, err := seeker.Seek(2*chunkSize, io.SeekStart); err != nil {
+		t.Fatalf("seek: %v", err)
+	}
+	if _, err := seeker.Read(make([]byte, 1)); !errors.Is(err, ErrCiphertextInvalid) {
+		t.Fatalf("expected ErrCiphertextInvalid, got %v", err)
+	}
+}
+
+func TestStreamRejectsTamperingAndTruncation(t *testing.T) {
+	dataKey := testKey(t)
+	sealed, err := Seal(dataKey, bytes.Repeat([]byte("row,"), chunkSize))
+	if err != nil {
+		t.Fatalf("seal: %v", err)
+	}
+
+	tampered := append([]byte(nil), sealed...)
+	tampered[len(streamMagic)+10] ^= 0x01
+	if _, err := Open(dataKey, tampered); !errors.Is(err, ErrCiphertextInvalid) {
+		t.Fatalf("expected ErrCiphertextInvalid for tampered data, got %v", err)
+	}
+
+	// Dropping the final chunk leaves a full, non-final chunk at the end.
+	truncated := sealed[:len(streamMagic)+chunkSize+tagSize]
+	if _, err := Open(dataKey, truncated); !errors.Is(err, ErrCiphertextInvalid) {
+		t.Fatalf("expected ErrCiphertextInvalid for truncated data, got %v", err)
+	}
+
+	if _, err := Open(testKey(t), sealed); !errors.Is(err, ErrCiphertextInvalid) {
+		t.Fatalf("expected ErrCiphertextInvalid for wrong key, got %v", err)
+	}
+}
+
+func TestLocalKeyWrapperRotation(t *testing.T) {
+	oldKey, newKey := testKey(t), testKey(t)
+	before, err := New(Config{Provider: ProviderLocal, LocalActiveKeyID: "k1", LocalKeys: map[string][]byte{"k1": oldKey}})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	objectKey, err := before.NewObjectKey(context.Background())
+	if err != nil {
+		t.Fatalf("NewObjectKey: %v", err)
+	}
+	if objectKey.KeyID != "local:k1" || objectKey.Algorithm != AlgorithmAES256GCMChunked {
+		t.Fatalf("unexpected object key: %+v", objectKey)
+	}
+
+	after, err := New(Config{Provider: ProviderLocal, LocalActiveKeyID: "k2", LocalKeys: map[string][]byte{"k1": oldKey, "k2": newKey}})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	dataKey, err := after.DataKey(context.Background(), objectKey.Algorithm, objectKey.KeyID, objectKey.WrappedKey)
+	if err != nil {
+		t.Fatalf("DataKey: %v", err)
+	}
+	if !bytes.Equal(dataKey, objectKey.DataKey) {
+		t.Fatalf("unwrapped data key mismatch")
+	}
+
+	retired, err := New(Config{Provider: ProviderLocal, LocalActiveKeyID: "k2", LocalKeys: map[string][]byte{"k2": newKey}})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if _, err := retired.DataKey(context.Background(), objectKey.Algorithm, objectKey.KeyID, objectKey.WrappedKey); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("expected ErrKeyNotFound, got %v", err)
+	}
+}
+
+func TestVaultTransitKeyWrapper(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		switch r.URL.Path {
+		case "/v1/transit/encrypt/datasets":
+			_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]string{"ciphertext": "vault:v1:" + body["plaintext"]}})
+		case "/v1/transit/decrypt/datasets":
+			_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]string{"plaintext": strings.TrimPrefix(body["ciphertext"], "vault:v1:")}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	encrypter, err := New(Config{Provider: ProviderVaultTransit, VaultAddr: server.URL, VaultToken: "token", VaultTransitKey: "datasets"})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	objectKey, err := encrypter.NewObjectKey(context.Background())
+	if err != nil {
+		t.Fatalf("NewObjectKey: %v", err)
+	}
+	wrapped, _ := base64.StdEncoding.DecodeString(objectKey.WrappedKey)
+	if !strings.HasPrefix(string(wrapped), "vault:v1:") || objectKey.KeyID != "vault-transit:transit/datasets" {
+		t.Fatalf("unexpected object key: %+v", objectKey)
+	}
+	dataKey, err := encrypter.DataKey(context.Background(), objectKey.Algorithm, objectKey.KeyID, objectKey.WrappedKey)
+	if err != nil {
+		t.Fatalf("DataKey: %v", err)
+	}
+	if !bytes.Equal(dataKey, objectKey.DataKey) {
+		t.Fatalf("unwrapped data key mismatch")
+	}
+}
+
+func TestNewDisabled(t *testing.T) {
+	encrypter, err := New(Config{})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if encrypter.Enabled() {
+		t.Fatalf("expected encryption disabled")
+	}
+	if _, err := encrypter.DataKey(context.Background(), AlgorithmAES256GCMChunked, "local:k1", ""); err == nil {
+		t.Fatalf("expected error without provider")
+	}
+}
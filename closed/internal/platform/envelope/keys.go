@@ -0,0 +1,195 @@
+package envelope
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var ErrKeyNotFound = errors.New("envelope: key encryption key not found")
+
+// KeyWrapper protects data keys with a key encryption key held outside the
+// object store. Unwrap is given the key ID returned by Wrap, so rotated keys
+// keep decrypting older objects.
+type KeyWrapper interface {
+	Wrap(ctx context.Context, dataKey []byte) (keyID string, wrapped []byte, err error)
+	Unwrap(ctx context.Context, keyID string, wrapped []byte) ([]byte, error)
+}
+
+// LocalKeyWrapper wraps data keys with AES-256-GCM master keys from config.
+// Only the active key wraps; every configured key can unwrap.
+type LocalKeyWrapper struct {
+	activeKeyID string
+	keys        map[string]cipher.AEAD
+}
+
+func NewLocalKeyWrapper(activeKeyID string, keys map[string][]byte) (*LocalKeyWrapper, error) {
+	activeKeyID = strings.TrimSpace(activeKeyID)
+	if activeKeyID == "" {
+		return nil, errors.New("active key id is required")
+	}
+	if _, ok := keys[activeKeyID]; !ok {
+		return nil, fmt.Errorf("active key %q is not configured", activeKeyID)
+	}
+	aeads := make(map[string]cipher.AEAD, len(keys))
+	for id, key := range keys {
+		if len(key) != 32 {
+			return nil, fmt.Errorf("master key %q must be 32 bytes", id)
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		aeads[id] = aead
+	}
+	return &LocalKeyWrapper{activeKeyID: activeKeyID, keys: aeads}, nil
+}
+
+func (w *LocalKeyWrapper) Wrap(_ context.Context, dataKey []byte) (string, []byte, error) {
+	aead := w.keys[w.activeKeyID]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", nil, err
+	}
+	keyID := "local:" + w.activeKeyID
+	return keyID, aead.Seal(nonce, nonce, dataKey, []byte(keyID)), nil
+}
+
+func (w *LocalKeyWrapper) Unwrap(_ context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	id, ok := strings.CutPrefix(keyID, "local:")
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	aead, ok := w.keys[id]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	if len(wrapped) < aead.NonceSize() {
+		return nil, ErrCiphertextInvalid
+	}
+	nonce, sealed := wrapped[:aead.NonceSize()], wrapped[aead.NonceSize():]
+	dataKey, err := aead.Open(nil, nonce, sealed, []byte(keyID))
+	if err != nil {
+		return nil, ErrCiphertextInvalid
+	}
+	return dataKey, nil
+}
+
+// VaultTransitKeyWrapper wraps data keys with a Vault transit key. Vault keeps
+// the key version inside its ciphertext, so rotation needs no extra state here.
+type VaultTransitKeyWrapper struct {
+	addr      string
+	mount     string
+	key       string
+	token     string
+	namespace string
+	http      *http.Client
+}
+
+func NewVaultTransitKeyWrapper(cfg Config) (*VaultTransitKeyWrapper, error) {
+	addr := strings.TrimRight(strings.TrimSpace(cfg.VaultAddr), "/")
+	if addr == "" {
+		return nil, errors.New("vault addr is required")
+	}
+	key := strings.TrimSpace(cfg.VaultTransitKey)
+	if key == "" {
+		return nil, errors.New("vault transit key is required")
+	}
+	if strings.TrimSpace(cfg.VaultToken) == "" {
+		return nil, errors.New("vault token is required")
+	}
+	mount := strings.Trim(strings.TrimSpace(cfg.VaultTransitMount), "/")
+	if mount == "" {
+		mount = "transit"
+	}
+	timeout := cfg.VaultTimeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	return &VaultTransitKeyWrapper{
+		addr:      addr,
+		mount:     mount,
+		key:       key,
+		token:     strings.TrimSpace(cfg.VaultToken),
+		namespace: strings.TrimSpace(cfg.VaultNamespace),
+		http:      &http.Client{Timeout: timeout},
+	}, nil
+}
+
+func (w *VaultTransitKeyWrapper) keyID() string {
+	return "vault-transit:" + w.mount + "/" + w.key
+}
+
+func (w *VaultTransitKeyWrapper) Wrap(ctx context.Context, dataKey []byte) (string, []byte, error) {
+	var resp struct {
+		Data struct {
+			Ciphertext string `json:"ciphertext"`
+		} `json:"data"`
+	}
+	if err := w.call(ctx, "encrypt", map[string]string{"plaintext": base64.StdEncoding.EncodeToString(dataKey)}, &resp); err != nil {
+		return "", nil, err
+	}
+	if strings.TrimSpace(resp.Data.Ciphertext) == "" {
+		return "", nil, errors.New("vault transit returned empty ciphertext")
+	}
+	return w.keyID(), []byte(resp.Data.Ciphertext), nil
+}
+
+func (w *VaultTransitKeyWrapper) Unwrap(ctx context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	if keyID != w.keyID() {
+		return nil, ErrKeyNotFound
+	}
+	var resp struct {
+		Data struct {
+			Plaintext string `json:"plaintext"`
+		} `json:"data"`
+	}
+	if err := w.call(ctx, "decrypt", map[string]string{"ciphertext": string(wrapped)}, &resp); err != nil {
+		return nil, err
+	}
+	dataKey, err := base64.StdEncoding.DecodeString(resp.Data.Plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("vault transit plaintext: %w", err)
+	}
+	return dataKey, nil
+}
+
+func (w *VaultTransitKeyWrapper) call(ctx context.Context, op string, body map[string]string, out any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/v1/%s/%s/%s", w.addr, w.mount, op, w.key), bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Vault-Token", w.token)
+	if w.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", w.namespace)
+	}
+	resp, err := w.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
+		return fmt.Errorf("vault transit %s failed: status %d: %s", op, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
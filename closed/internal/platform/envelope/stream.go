@@ -0,0 +1,270 @@
+package envelope
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// AlgorithmAES256GCMChunked is AES-256-GCM over 64 KiB chunks. Each chunk's
+// nonce is its index plus a final-chunk flag, so reordering, dropping or
+// truncating chunks fails authentication. Nonces never repeat because every
+// object gets a fresh data key.
+const AlgorithmAES256GCMChunked = "aes-256-gcm-chunked-v1"
+
+const (
+	DataKeySize = 32
+
+	chunkSize = 64 << 10
+	tagSize   = 16
+)
+
+var streamMagic = []byte("ANVE1\x00")
+
+var ErrCiphertextInvalid = errors.New("envelope: ciphertext is invalid")
+
+// EncryptedSize is the stored size of a plaintext of the given size.
+func EncryptedSize(plaintextSize int64) int64 {
+	chunks := plaintextSize / chunkSize
+	if plaintextSize%chunkSize != 0 || plaintextSize == 0 {
+		chunks++
+	}
+	return int64(len(streamMagic)) + plaintextSize + chunks*tagSize
+}
+
+// Seal encrypts an in-memory object.
+func Seal(dataKey []byte, plaintext []byte) ([]byte, error) {
+	reader, err := EncryptReader(dataKey, bytes.NewReader(plaintext))
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(reader)
+}
+
+// Open decrypts an in-memory object.
+func Open(dataKey []byte, ciphertext []byte) ([]byte, error) {
+	reader, err := DecryptReader(dataKey, bytes.NewReader(ciphertext))
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(reader)
+}
+
+// EncryptReader returns a reader yielding the ciphertext of src.
+func EncryptReader(dataKey []byte, src io.Reader) (io.Reader, error) {
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return &streamReader{
+		aead:    aead,
+		src:     bufio.NewReaderSize(src, chunkSize),
+		seal:    true,
+		in:      make([]byte, chunkSize),
+		pending: append([]byte(nil), streamMagic...),
+	}, nil
+}
+
+// DecryptReader returns a reader yielding the plaintext of src. Read fails with
+// ErrCiphertextInvalid as soon as a chunk does not authenticate; callers that
+// stream to a client may already have sent earlier chunks.
+func DecryptReader(dataKey []byte, src io.Reader) (io.Reader, error) {
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return &streamReader{
+		aead: aead,
+		src:  bufio.NewReaderSize(src, chunkSize+tagSize),
+		in:   make([]byte, chunkSize+tagSize),
+	}, nil
+}
+
+func newAEAD(dataKey []byte) (cipher.AEAD, error) {
+	if len(dataKey) != DataKeySize {
+		return nil, fmt.Errorf("envelope: data key must be %d bytes", DataKeySize)
+	}
+	block, err := aes.NewCipher(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+type streamReader struct {
+	aead    cipher.AEAD
+	src     *bufio.Reader
+	seal    bool
+	in      []byte
+	pending []byte
+	index   uint64
+	started bool
+	done    bool
+	err     error
+}
+
+func (s *streamReader) Read(p []byte) (int, error) {
+	for len(s.pending) == 0 {
+		if s.err != nil {
+			return 0, s.err
+		}
+		if s.done {
+			return 0, io.EOF
+		}
+		s.err = s.next()
+	}
+	n := copy(p, s.pending)
+	s.pending = s.pending[n:]
+	return n, nil
+}
+
+func (s *streamReader) next() error {
+	if !s.seal && !s.started {
+		header := make([]byte, len(streamMagic))
+		if _, err := io.ReadFull(s.src, header); err != nil || !bytes.Equal(header, streamMagic) {
+			return ErrCiphertextInvalid
+		}
+	}
+	s.started = true
+
+	n, err := io.ReadFull(s.src, s.in)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return err
+	}
+	// A chunk is final when nothing follows it; this also marks a full last
+	// chunk, and an empty plaintext still produces one (empty) final chunk.
+	final := false
+	if n < len(s.in) {
+		final = true
+	} else if _, peekErr := s.src.Peek(1); peekErr != nil {
+		if !errors.Is(peekErr, io.EOF) {
+			return peekErr
+		}
+		final = true
+	}
+
+	nonce := chunkNonce(s.index, final)
+	if s.seal {
+		s.pending = s.aead.Seal(nil, nonce, s.in[:n], nil)
+	} else {
+		if n < tagSize {
+			return ErrCiphertextInvalid
+		}
+		plain, openErr := s.aead.Open(nil, nonce, s.in[:n], nil)
+		if openErr != nil {
+			return ErrCiphertextInvalid
+		}
+		s.pending = plain
+	}
+	s.index++
+	s.done = final
+	return nil
+}
+
+func chunkNonce(index uint64, final bool) []byte {
+	nonce := make([]byte, 12)
+	binary.BigEndian.PutUint64(nonce[3:11], index)
+	if final {
+		nonce[11] = 1
+	}
+	return nonce
+}
+
+// NewDecryptSeeker returns a seekable plaintext view of src, which must hold an
+// object of plaintextSize bytes sealed with dataKey. Only the chunks covering a
+// read are fetched and authenticated, so byte ranges can be served without
+// decrypting the object from the start.
+func NewDecryptSeeker(dataKey []byte, src io.ReadSeeker, plaintextSize int64) (io.ReadSeeker, error) {
+	if plaintextSize < 0 {
+		return nil, ErrCiphertextInvalid
+	}
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return &seekReader{
+		aead:  aead,
+		src:   src,
+		size:  plaintextSize,
+		in:    make([]byte, chunkSize+tagSize),
+		index: -1,
+	}, nil
+}
+
+type seekReader struct {
+	aead    cipher.AEAD
+	src     io.ReadSeeker
+	size    int64
+	offset  int64
+	in      []byte
+	plain   []byte
+	index   int64
+	checked bool
+}
+
+func (s *seekReader) Read(p []byte) (int, error) {
+	if s.offset >= s.size {
+		return 0, io.EOF
+	}
+	index := s.offset / chunkSize
+	if index != s.index {
+		if err := s.load(index); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, s.plain[s.offset-index*chunkSize:])
+	s.offset += int64(n)
+	return n, nil
+}
+
+func (s *seekReader) load(index int64) error {
+	if !s.checked {
+		header := make([]byte, len(streamMagic))
+		if _, err := s.src.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		if _, err := io.ReadFull(s.src, header); err != nil || !bytes.Equal(header, streamMagic) {
+			return ErrCiphertextInvalid
+		}
+		s.checked = true
+	}
+	plainLen := min(int64(chunkSize), s.size-index*chunkSize)
+	if _, err := s.src.Seek(int64(len(streamMagic))+index*(chunkSize+tagSize), io.SeekStart); err != nil {
+		return err
+	}
+	sealed := s.in[:plainLen+tagSize]
+	if _, err := io.ReadFull(s.src, sealed); err != nil {
+		return ErrCiphertextInvalid
+	}
+	final := (index+1)*chunkSize >= s.size
+	plain, err := s.aead.Open(s.plain[:0], chunkNonce(uint64(index), final), sealed, nil)
+	if err != nil {
+		s.index = -1
+		return ErrCiphertextInvalid
+	}
+	s.plain = plain
+	s.index = index
+	return nil
+}
+
+func (s *seekReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += s.offset
+	case io.SeekEnd:
+		offset += s.size
+	default:
+		return 0, errors.New("envelope: invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("envelope: negative position")
+	}
+	s.offset = offset
+	return offset, nil
+}
@@ -0,0 +1,332 @@
+// Package httpapi holds the JSON request and response helpers shared by the
+// control plane services, so error shapes, paging and validators stay uniform.
+package httpapi
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/animus-labs/animus-go/core/contracts/errorcatalog"
+)
+
+// MaxBodyBytes bounds JSON request bodies read by DecodeJSON.
+const MaxBodyBytes = 1 << 20
+
+const (
+	ContentTypeJSON    = "application/json"
+	ContentTypeProblem = "application/problem+json"
+)
+
+func WriteJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", ContentTypeJSON)
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(true)
+	_ = enc.Encode(body)
+}
+
+// WriteError writes {"error": code, "message": title, "request_id": ...}, or
+// an RFC 9457 problem document when the client asks for
+// application/problem+json. Codes and titles come from core/contracts/errorcatalog.
+func WriteError(w http.ResponseWriter, r *http.Request, status int, code string) {
+	writeError(w, r, status, code, "", nil, nil)
+}
+
+func WriteErrorWithDetails(w http.ResponseWriter, r *http.Request, status int, code string, details any) {
+	writeError(w, r, status, code, "", details, nil)
+}
+
+// WriteDecodeError writes the 400 invalid_json response for a DecodeJSON
+// error, naming the offending field for type mismatches and unknown fields.
+func WriteDecodeError(w http.ResponseWriter, r *http.Request, err error) {
+	detail, fields := describeDecodeError(err)
+	writeError(w, r, http.StatusBadRequest, "invalid_json", detail, nil, fields)
+}
+
+// WriteValidationError writes the 400 validation_failed response listing
+// every invalid field of the request.
+func WriteValidationError(w http.ResponseWriter, r *http.Request, fields []FieldError) {
+	writeError(w, r, http.StatusBadRequest, "validation_failed", "", nil, fields)
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, status int, code, detail string, details any, fields []FieldError) {
+	requestID := r.Header.Get("X-Request-Id")
+	title := errorcatalog.Title(code)
+	if WantsProblem(r) {
+		problem := Problem{
+			Type:      errorcatalog.TypeURI(code),
+			Title:     title,
+			Status:    status,
+			Detail:    detail,
+			Instance:  r.URL.Path,
+			Error:     code,
+			RequestID: requestID,
+			Errors:    fields,
+			Details:   details,
+		}
+		if problem.Title == "" {
+			problem.Title = http.StatusText(status)
+		}
+		w.Header().Set("Content-Type", ContentTypeProblem)
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(problem)
+		return
+	}
+	body := map[string]any{
+		"error":      code,
+		"request_id": requestID,
+	}
+	if message := firstNonEmpty(detail, title); message != "" {
+		body["message"] = message
+	}
+	if len(fields) > 0 {
+		body["errors"] = fields
+	}
+	if details != nil {
+		body["details"] = details
+	}
+	WriteJSON(w, status, body)
+}
+
+// Problem is an RFC 9457 problem document. Type is urn:animus:error:<code>;
+// the error code and request ID are extension members named as in the plain
+// JSON error body.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Error     string       `json:"error"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+	Details   any          `json:"details,omitempty"`
+}
+
+// FieldError names one invalid request field. Field is the dotted JSON path
+// and Pointer the same location as an RFC 6901 JSON pointer; Code is a
+// field_errors entry of the catalog.
+type FieldError struct {
+	Field   string `json:"field"`
+	Pointer string `json:"pointer,omitempty"`
+	Code    string `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+// WantsProblem reports whether the Accept header lists application/problem+json.
+func WantsProblem(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err == nil && mediaType == ContentTypeProblem {
+			return true
+		}
+	}
+	return false
+}
+
+var errMultipleValues = errors.New("multiple JSON values")
+
+// DecodeJSON decodes exactly one JSON value of at most MaxBodyBytes and
+// rejects unknown fields.
+func DecodeJSON(r *http.Request, dst any) error {
+	dec := json.NewDecoder(io.LimitReader(r.Body, MaxBodyBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(dst); err != nil {
+		return err
+	}
+	if err := dec.Decode(&struct{}{}); err != io.EOF {
+		return errMultipleValues
+	}
+	return nil
+}
+
+// describeDecodeError turns a DecodeJSON error into a message safe to return
+// to the client and, where the decoder names one, the offending field.
+func describeDecodeError(err error) (string, []FieldError) {
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	switch {
+	case err == nil:
+		return "", nil
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		message := "must be " + jsonKind(typeErr.Type)
+		return typeErr.Field + " " + message, []FieldError{{Field: typeErr.Field, Pointer: fieldPointer(typeErr.Field), Code: "invalid_type", Message: message}}
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field, unquoteErr := strconv.Unquote(strings.TrimPrefix(err.Error(), "json: unknown field "))
+		if unquoteErr != nil {
+			return "request body has an unknown field", nil
+		}
+		return "unknown field " + field, []FieldError{{Field: field, Pointer: fieldPointer(field), Code: "unknown_field", Message: "unknown field"}}
+	case errors.As(err, &syntaxErr):
+		return "malformed JSON at offset " + strconv.FormatInt(syntaxErr.Offset, 10), nil
+	case errors.Is(err, io.EOF):
+		return "request body is empty", nil
+	case errors.Is(err, errMultipleValues):
+		return "request body must hold a single JSON value", nil
+	default:
+		return "malformed JSON", nil
+	}
+}
+
+// fieldPointer turns a dotted decoder field path into a JSON pointer.
+func fieldPointer(field string) string {
+	field = strings.ReplaceAll(field, "~", "~0")
+	return "/" + strings.ReplaceAll(strings.ReplaceAll(field, "/", "~1"), ".", "/")
+}
+
+func jsonKind(t reflect.Type) string {
+	if t == nil {
+		return "a different type"
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Map, reflect.Struct:
+		return "an object"
+	default:
+		return "a " + t.String()
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func RequestIP(remoteAddr string) net.IP {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return nil
+	}
+	return net.ParseIP(host)
+}
+
+// NormalizeJSON maps empty and null documents to {}.
+func NormalizeJSON(raw []byte) json.RawMessage {
+	raw = []byte(strings.TrimSpace(string(raw)))
+	if len(raw) == 0 || string(raw) == "null" {
+		return []byte("{}")
+	}
+	return raw
+}
+
+// ParseIntQuery returns def when the parameter is missing or malformed.
+func ParseIntQuery(r *http.Request, key string, def int) int {
+	v := strings.TrimSpace(r.URL.Query().Get(key))
+	if v == "" {
+		return def
+	}
+	parsed, err := strconv.Atoi(v)
+	if err != nil {
+		return def
+	}
+	return parsed
+}
+
+func ParseInt64Query(r *http.Request, key string, def int64) int64 {
+	v := strings.TrimSpace(r.URL.Query().Get(key))
+	if v == "" {
+		return def
+	}
+	parsed, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return def
+	}
+	return parsed
+}
+
+func ClampInt(v int, min int, max int) int {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
+
+// Limit reads the "limit" page size, defaulting to def and clamped to [1, max].
+func Limit(r *http.Request, def, max int) int {
+	return ClampInt(ParseIntQuery(r, "limit", def), 1, max)
+}
+
+// ErrInvalidCursor is returned by DecodeCursor for a token it did not issue;
+// handlers answer 400 invalid_cursor.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// EncodeCursor turns a page position into the opaque "cursor" token of a
+// list response (next_cursor). Clients pass it back unchanged; its content
+// is not part of the API.
+func EncodeCursor(position any) string {
+	raw, err := json.Marshal(position)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeCursor reads a token made by EncodeCursor into position. An empty
+// token leaves position untouched.
+func DecodeCursor(token string, position any) error {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return ErrInvalidCursor
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(position); err != nil {
+		return ErrInvalidCursor
+	}
+	return nil
+}
+
+// ETag returns a strong entity tag for an opaque validator such as a content hash.
+func ETag(validator string) string {
+	return strconv.Quote(validator)
+}
+
+// NotModified reports whether If-None-Match matches etag; GET and HEAD
+// handlers answer 304 in that case.
+func NotModified(r *http.Request, etag string) bool {
+	header := strings.TrimSpace(r.Header.Get("If-None-Match"))
+	if header == "" || etag == "" {
+		return false
+	}
+	if header == "*" {
+		return true
+	}
+	want := strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		// If-None-Match uses weak comparison.
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == want {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,188 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWriteError(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/datasets/d1", nil)
+	r.Header.Set("X-Request-Id", "req-1")
+	w := httptest.NewRecorder()
+	WriteError(w, r, http.StatusNotFound, "not_found")
+
+	if w.Code != http.StatusNotFound || w.Header().Get("Content-Type") != ContentTypeJSON {
+		t.Fatalf("unexpected response: %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+	var body map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if body["error"] != "not_found" || body["message"] != "Not found" || body["request_id"] != "req-1" || len(body) != 3 {
+		t.Fatalf("unexpected body: %v", body)
+	}
+}
+
+func TestWriteErrorProblem(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/datasets/d1/versions/upload", nil)
+	r.Header.Set("X-Request-Id", "req-2")
+	r.Header.Set("Accept", "application/json, application/problem+json;q=0.9")
+	w := httptest.NewRecorder()
+	WriteErrorWithDetails(w, r, http.StatusRequestEntityTooLarge, "upload_too_large", map[string]any{"max_bytes": 10})
+
+	if w.Header().Get("Content-Type") != ContentTypeProblem {
+		t.Fatalf("unexpected content type %q", w.Header().Get("Content-Type"))
+	}
+	var problem Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if problem.Status != http.StatusRequestEntityTooLarge || problem.Type != "urn:animus:error:upload_too_large" ||
+		problem.Title != "Upload is too large" ||
+		problem.Error != "upload_too_large" || problem.RequestID != "req-2" ||
+		problem.Instance != "/datasets/d1/versions/upload" || problem.Details == nil {
+		t.Fatalf("unexpected problem: %+v", problem)
+	}
+}
+
+func TestWriteErrorUncataloguedCodeFallsBackToStatusText(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Accept", ContentTypeProblem)
+	w := httptest.NewRecorder()
+	WriteError(w, r, http.StatusBadGateway, "upstream_specific")
+
+	var problem Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if problem.Type != "urn:animus:error:upstream_specific" || problem.Title != "Bad Gateway" {
+		t.Fatalf("unexpected problem: %+v", problem)
+	}
+}
+
+func TestWriteDecodeError(t *testing.T) {
+	var dst struct {
+		Name   string `json:"name"`
+		Limits struct {
+			CPU int `json:"cpu"`
+		} `json:"limits"`
+	}
+	cases := []struct {
+		body    string
+		message string
+		field   FieldError
+	}{
+		{`{"limits":{"cpu":"2"}}`, "limits.cpu must be an integer", FieldError{Field: "limits.cpu", Pointer: "/limits/cpu", Code: "invalid_type", Message: "must be an integer"}},
+		{`{"nmae":"a"}`, "unknown field nmae", FieldError{Field: "nmae", Pointer: "/nmae", Code: "unknown_field", Message: "unknown field"}},
+		{`{"name":`, "malformed JSON", FieldError{}},
+		{`{"name":}`, "malformed JSON at offset 9", FieldError{}},
+		{``, "request body is empty", FieldError{}},
+	}
+	for _, tc := range cases {
+		r := httptest.NewRequest(http.MethodPost, "/datasets", strings.NewReader(tc.body))
+		w := httptest.NewRecorder()
+		WriteDecodeError(w, r, DecodeJSON(r, &dst))
+
+		var body struct {
+			Error   string       `json:"error"`
+			Message string       `json:"message"`
+			Errors  []FieldError `json:"errors"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if w.Code != http.StatusBadRequest || body.Error != "invalid_json" || body.Message != tc.message {
+			t.Fatalf("%q: status=%d body=%s", tc.body, w.Code, w.Body)
+		}
+		if (tc.field == FieldError{}) != (len(body.Errors) == 0) || (len(body.Errors) == 1 && body.Errors[0] != tc.field) {
+			t.Fatalf("%q: errors=%+v", tc.body, body.Errors)
+		}
+	}
+}
+
+func TestDecodeJSON(t *testing.T) {
+	var dst struct {
+		Name string `json:"name"`
+	}
+	cases := map[string]bool{
+		`{"name":"a"}`:           true,
+		`{"name":"a"} `:          true,
+		`{"name":"a","extra":1}`: false,
+		`{"name":"a"}{}`:         false,
+		`{"name":"a"} x`:         false,
+	}
+	for body, ok := range cases {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		if err := DecodeJSON(r, &dst); (err == nil) != ok {
+			t.Fatalf("DecodeJSON(%q) error = %v, want ok=%v", body, err, ok)
+		}
+	}
+}
+
+func TestLimit(t *testing.T) {
+	cases := map[string]int{"": 100, "?limit=5": 5, "?limit=0": 1, "?limit=9999": 500, "?limit=abc": 100}
+	for query, want := range cases {
+		r := httptest.NewRequest(http.MethodGet, "/items"+query, nil)
+		if got := Limit(r, 100, 500); got != want {
+			t.Fatalf("Limit(%q) = %d, want %d", query, got, want)
+		}
+	}
+}
+
+func TestCursor(t *testing.T) {
+	type position struct {
+		BeforeID int64 `json:"before_id"`
+	}
+	token := EncodeCursor(position{BeforeID: 42})
+	var got position
+	if err := DecodeCursor(token, &got); err != nil || got.BeforeID != 42 {
+		t.Fatalf("DecodeCursor(%q) = %+v, %v", token, got, err)
+	}
+	got = position{BeforeID: 7}
+	if err := DecodeCursor("", &got); err != nil || got.BeforeID != 7 {
+		t.Fatalf("empty cursor: %+v, %v", got, err)
+	}
+	for _, token := range []string{"%%%", EncodeCursor(map[string]int{"other": 1}), EncodeCursor("x")} {
+		if err := DecodeCursor(token, &got); !errors.Is(err, ErrInvalidCursor) {
+			t.Fatalf("DecodeCursor(%q) error = %v", token, err)
+		}
+	}
+}
+
+func TestNormalizeJSON(t *testing.T) {
+	for _, raw := range []string{"", " null ", "\n"} {
+		if got := string(NormalizeJSON([]byte(raw))); got != "{}" {
+			t.Fatalf("NormalizeJSON(%q) = %q", raw, got)
+		}
+	}
+	if got := string(NormalizeJSON([]byte(` {"a":1} `))); got != `{"a":1}` {
+		t.Fatalf("NormalizeJSON trimmed to %q", got)
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	etag := ETag("abc")
+	cases := map[string]bool{
+		"":                 false,
+		`"abc"`:            true,
+		`W/"abc"`:          true,
+		`"xyz", "abc"`:     true,
+		`"xyz"`:            false,
+		"*":                true,
+		`"abc-suffix"`:     false,
+		` W/"xyz" , "abc"`: true,
+	}
+	for header, want := range cases {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if header != "" {
+			r.Header.Set("If-None-Match", header)
+		}
+		if got := NotModified(r, etag); got != want {
+			t.Fatalf("NotModified(%q) = %v, want %v", header, got, want)
+		}
+	}
+}
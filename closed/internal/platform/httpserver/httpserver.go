@@ -3,7 +3,7 @@ package httpserver
 import (
 	"bufio"
 	"context"
-	"encoding/json"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"io"
@@ -13,6 +13,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/animus-labs/animus-go/closed/internal/platform/buildinfo"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
 	"github.com/animus-labs/animus-go/closed/internal/platform/requestid"
 )
 
@@ -20,6 +22,9 @@ type Config struct {
 	Service         string
 	Addr            string
 	ShutdownTimeout time.Duration
+	// TLSConfig enables TLS on the listener; certificates must be supplied
+	// through Certificates or GetCertificate.
+	TLSConfig *tls.Config
 }
 
 func Wrap(logger *slog.Logger, service string, next http.Handler) http.Handler {
@@ -45,11 +50,16 @@ func Run(ctx context.Context, logger *slog.Logger, cfg Config, handler http.Hand
 		ReadTimeout:       15 * time.Minute,
 		WriteTimeout:      0,
 		IdleTimeout:       60 * time.Second,
+		TLSConfig:         cfg.TLSConfig,
 	}
 
 	errCh := make(chan error, 1)
 	go func() {
-		logger.Info("http server listening", "service", cfg.Service, "addr", cfg.Addr)
+		logger.Info("http server listening", "service", cfg.Service, "addr", cfg.Addr, "tls", cfg.TLSConfig != nil)
+		if cfg.TLSConfig != nil {
+			errCh <- srv.ListenAndServeTLS("", "")
+			return
+		}
 		errCh <- srv.ListenAndServe()
 	}()
 
@@ -68,18 +78,34 @@ func Run(ctx context.Context, logger *slog.Logger, cfg Config, handler http.Hand
 
 func Healthz(service string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		writeJSON(w, http.StatusOK, map[string]any{
+		httpapi.WriteJSON(w, http.StatusOK, map[string]any{
 			"service": service,
 			"status":  "ok",
+			"version": buildinfo.Get().Version,
+		})
+	}
+}
+
+// BuildInfo serves GET /version: the service name and its build metadata.
+func BuildInfo(service string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		info := buildinfo.Get()
+		httpapi.WriteJSON(w, http.StatusOK, map[string]any{
+			"service":    service,
+			"version":    info.Version,
+			"commit":     info.Commit,
+			"build_date": info.Date,
+			"go_version": info.GoVersion,
 		})
 	}
 }
 
 func Readyz(service string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		writeJSON(w, http.StatusOK, map[string]any{
+		httpapi.WriteJSON(w, http.StatusOK, map[string]any{
 			"service": service,
 			"status":  "ready",
+			"version": buildinfo.Get().Version,
 		})
 	}
 }
@@ -120,29 +146,23 @@ func ReadyzWithChecks(service string, checks ...ReadinessCheck) http.HandlerFunc
 		}
 
 		if overallOK {
-			writeJSON(w, http.StatusOK, map[string]any{
+			httpapi.WriteJSON(w, http.StatusOK, map[string]any{
 				"service": service,
 				"status":  "ready",
+				"version": buildinfo.Get().Version,
 				"checks":  results,
 			})
 			return
 		}
-		writeJSON(w, http.StatusServiceUnavailable, map[string]any{
+		httpapi.WriteJSON(w, http.StatusServiceUnavailable, map[string]any{
 			"service": service,
 			"status":  "not_ready",
+			"version": buildinfo.Get().Version,
 			"checks":  results,
 		})
 	}
 }
 
-func writeJSON(w http.ResponseWriter, status int, body any) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	enc := json.NewEncoder(w)
-	enc.SetEscapeHTML(true)
-	_ = enc.Encode(body)
-}
-
 type ctxKeyRequestID struct{}
 
 func RequestIDFromContext(ctx context.Context) (string, bool) {
@@ -237,10 +257,7 @@ func recoverMiddleware(logger *slog.Logger, next http.Handler) http.Handler {
 			if v := recover(); v != nil {
 				requestID, _ := RequestIDFromContext(r.Context())
 				logger.Error("panic recovered", "request_id", requestID, "panic", v)
-				writeJSON(w, http.StatusInternalServerError, map[string]any{
-					"error":      "internal_server_error",
-					"request_id": requestID,
-				})
+				httpapi.WriteError(w, r, http.StatusInternalServerError, "internal_server_error")
 			}
 		}()
 		next.ServeHTTP(w, r)
@@ -2,12 +2,15 @@ package httpserver
 
 import (
 	"context"
+	"encoding/json"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"strings"
 	"testing"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/buildinfo"
 )
 
 func TestWrap_SetsRequestIDHeader_WhenMissing(t *testing.T) {
@@ -77,6 +80,22 @@ func TestReadyzWithChecks_OK(t *testing.T) {
 	if !strings.Contains(rec.Body.String(), "\"status\":\"ready\"") {
 		t.Fatalf("expected ready status in response: %s", rec.Body.String())
 	}
+	if !strings.Contains(rec.Body.String(), "\"version\":\""+buildinfo.Get().Version+"\"") {
+		t.Fatalf("expected version in response: %s", rec.Body.String())
+	}
+}
+
+func TestBuildInfo(t *testing.T) {
+	rec := httptest.NewRecorder()
+	BuildInfo("testsvc").ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "http://example.test/version", nil))
+
+	var body map[string]string
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if rec.Code != http.StatusOK || body["service"] != "testsvc" || body["version"] != buildinfo.Get().Version || body["go_version"] == "" {
+		t.Fatalf("status=%d body=%v", rec.Code, body)
+	}
 }
 
 func TestReadyzWithChecks_Fail(t *testing.T) {
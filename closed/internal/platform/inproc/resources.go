@@ -0,0 +1,42 @@
+package inproc
+
+import (
+	"database/sql"
+	"sync"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/migrate"
+	"github.com/animus-labs/animus-go/closed/internal/platform/objectstore"
+	"github.com/animus-labs/animus-go/closed/internal/platform/postgres"
+	"github.com/minio/minio-go/v7"
+)
+
+// Resources are the clients a service handler is built on. A standalone
+// binary fills them for itself; cmd/animus passes one value to every service
+// so they share the pool and the object store client.
+type Resources struct {
+	DB *sql.DB
+	// Reads routes heavy reads to the replica; nil for services that only
+	// use DB.
+	Reads    *postgres.Replica
+	Migrator *migrate.Migrator
+	// Services holds the services mounted in the process; nil when each
+	// service runs as its own binary.
+	Services *Transport
+
+	storeOnce sync.Once
+	store     *minio.Client
+	storeCfg  objectstore.Config
+	storeErr  error
+}
+
+// ObjectStore returns the MinIO client configured from ANIMUS_MINIO_*,
+// creating it on first use.
+func (r *Resources) ObjectStore() (*minio.Client, objectstore.Config, error) {
+	r.storeOnce.Do(func() {
+		r.storeCfg, r.storeErr = objectstore.ConfigFromEnv()
+		if r.storeErr == nil {
+			r.store, r.storeErr = objectstore.NewMinIOClient(r.storeCfg)
+		}
+	})
+	return r.store, r.storeCfg, r.storeErr
+}
@@ -0,0 +1,163 @@
+// Package inproc composes several services in one process (cmd/animus): the
+// gateway keeps its reverse proxies, but requests to a mounted service are
+// served by that service's handler instead of going over the network.
+package inproc
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// Scheme marks upstream URLs served by a Transport, e.g. inproc://audit.
+const Scheme = "inproc"
+
+// URL is the upstream base URL of a service registered under name.
+func URL(name string) string {
+	return Scheme + "://" + name
+}
+
+// Transport is an http.RoundTripper that dispatches inproc:// requests to the
+// registered handlers and every other request to Fallback.
+type Transport struct {
+	// Fallback carries requests for services outside the process; nil uses
+	// http.DefaultTransport.
+	Fallback http.RoundTripper
+
+	mu       sync.RWMutex
+	handlers map[string]http.Handler
+}
+
+func NewTransport(fallback http.RoundTripper) *Transport {
+	return &Transport{Fallback: fallback, handlers: map[string]http.Handler{}}
+}
+
+// Register mounts handler as inproc://name.
+func (t *Transport) Register(name string, handler http.Handler) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.handlers[name] = handler
+}
+
+// Serves reports whether name is mounted in the process.
+func (t *Transport) Serves(name string) bool {
+	if t == nil {
+		return false
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	_, ok := t.handlers[name]
+	return ok
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme != Scheme {
+		if t.Fallback != nil {
+			return t.Fallback.RoundTrip(req)
+		}
+		return http.DefaultTransport.RoundTrip(req)
+	}
+	t.mu.RLock()
+	handler, ok := t.handlers[req.URL.Host]
+	t.mu.RUnlock()
+	if !ok {
+		closeBody(req)
+		return nil, fmt.Errorf("inproc: no service %q", req.URL.Host)
+	}
+
+	// The handler sees the request as a server would.
+	inbound := req.Clone(req.Context())
+	inbound.RequestURI = req.URL.RequestURI()
+	inbound.RemoteAddr = "inproc"
+	if inbound.Body == nil {
+		inbound.Body = http.NoBody
+	}
+	if inbound.Host == "" {
+		inbound.Host = req.URL.Host
+	}
+
+	pr, pw := io.Pipe()
+	rw := &responseWriter{header: http.Header{}, body: pw, ready: make(chan struct{})}
+	go func() {
+		defer func() {
+			if v := recover(); v != nil {
+				if !rw.sent() {
+					rw.WriteHeader(http.StatusInternalServerError)
+				}
+				_ = pw.CloseWithError(fmt.Errorf("inproc: %s panicked: %v", req.URL.Host, v))
+				return
+			}
+			rw.WriteHeader(http.StatusOK)
+			_ = pw.Close()
+		}()
+		handler.ServeHTTP(rw, inbound)
+	}()
+
+	select {
+	case <-rw.ready:
+	case <-req.Context().Done():
+		_ = pr.CloseWithError(req.Context().Err())
+		return nil, req.Context().Err()
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rw.status, http.StatusText(rw.status)),
+		StatusCode:    rw.status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        rw.snapshot,
+		Body:          pr,
+		ContentLength: -1,
+		Request:       req,
+	}, nil
+}
+
+func closeBody(req *http.Request) {
+	if req.Body != nil {
+		_ = req.Body.Close()
+	}
+}
+
+// responseWriter streams the handler's body through a pipe; the response is
+// handed to the caller once the status line is known, so SSE and large
+// downloads are not buffered.
+type responseWriter struct {
+	header   http.Header
+	body     *io.PipeWriter
+	ready    chan struct{}
+	once     sync.Once
+	status   int
+	snapshot http.Header
+}
+
+func (w *responseWriter) Header() http.Header { return w.header }
+
+func (w *responseWriter) WriteHeader(status int) {
+	w.once.Do(func() {
+		w.status = status
+		w.snapshot = w.header.Clone()
+		close(w.ready)
+	})
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	// Fails with io.ErrClosedPipe once the caller stops reading, as a write
+	// to a disconnected client would.
+	return w.body.Write(p)
+}
+
+// Flush commits the headers; written bytes reach the reader immediately.
+func (w *responseWriter) Flush() {
+	w.WriteHeader(http.StatusOK)
+}
+
+func (w *responseWriter) sent() bool {
+	select {
+	case <-w.ready:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,110 @@
+package inproc
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestTransportServesRegisteredHandler(t *testing.T) {
+	transport := NewTransport(nil)
+	transport.Register("audit", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("X-Path", r.URL.Path)
+		w.Header().Set("X-Request-URI", r.RequestURI)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write(append([]byte(r.Method+" "), body...))
+	}))
+	if !transport.Serves("audit") || transport.Serves("lineage") {
+		t.Fatal("unexpected Serves result")
+	}
+
+	target, _ := url.Parse(URL("audit"))
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	proxy.Transport = transport
+	rec := httptest.NewRecorder()
+	proxy.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/events?limit=5", strings.NewReader("payload")))
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body.String())
+	}
+	if rec.Body.String() != "POST payload" {
+		t.Fatalf("body=%q", rec.Body.String())
+	}
+	if rec.Header().Get("X-Path") != "/events" || rec.Header().Get("X-Request-URI") != "/events?limit=5" {
+		t.Fatalf("headers=%v", rec.Header())
+	}
+}
+
+func TestTransportStreamsBeforeHandlerReturns(t *testing.T) {
+	release := make(chan struct{})
+	transport := NewTransport(nil)
+	transport.Register("experiments", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = io.WriteString(w, "data: first\n")
+		w.(http.Flusher).Flush()
+		<-release
+		_, _ = io.WriteString(w, "data: second\n")
+	}))
+
+	req, _ := http.NewRequest(http.MethodGet, URL("experiments")+"/stream", nil)
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	reader := bufio.NewReader(resp.Body)
+	line, err := reader.ReadString('\n')
+	if err != nil || line != "data: first\n" {
+		t.Fatalf("first line=%q err=%v", line, err)
+	}
+	close(release)
+	rest, err := io.ReadAll(reader)
+	if err != nil || string(rest) != "data: second\n" {
+		t.Fatalf("rest=%q err=%v", rest, err)
+	}
+}
+
+func TestTransportRoutesOtherSchemesToFallback(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "remote")
+	}))
+	defer upstream.Close()
+
+	transport := NewTransport(nil)
+	client := &http.Client{Transport: transport}
+	resp, err := client.Get(upstream.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if string(body) != "remote" {
+		t.Fatalf("body=%q", body)
+	}
+
+	if _, err := client.Get(URL("quality") + "/readyz"); err == nil || !strings.Contains(err.Error(), "no service") {
+		t.Fatalf("unmounted service err=%v", err)
+	}
+}
+
+func TestTransportReportsPanicAsServerError(t *testing.T) {
+	transport := NewTransport(nil)
+	transport.Register("lineage", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+	req, _ := http.NewRequest(http.MethodGet, URL("lineage")+"/events", nil)
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("status=%d", resp.StatusCode)
+	}
+}
@@ -10,7 +10,9 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -183,7 +185,8 @@ func (c *Client) DeleteJob(ctx context.Context, namespace string, name string) e
 	if name == "" {
 		return errors.New("job name is required")
 	}
-	path := fmt.Sprintf("/apis/batch/v1/namespaces/%s/jobs/%s", namespace, name)
+	// Jobs orphan their pods on delete unless propagation is requested.
+	path := fmt.Sprintf("/apis/batch/v1/namespaces/%s/jobs/%s?propagationPolicy=Background", namespace, name)
 	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, c.baseURL+path, nil)
 	if err != nil {
 		return err
@@ -208,42 +211,107 @@ func (c *Client) DeleteService(ctx context.Context, namespace string, name strin
 	return c.do(req, nil)
 }
 
+func (c *Client) ListPods(ctx context.Context, namespace string, labelSelector string) ([]Pod, error) {
+	namespace = strings.TrimSpace(namespace)
+	if namespace == "" {
+		namespace = c.namespace
+	}
+	path := fmt.Sprintf("/api/v1/namespaces/%s/pods", namespace)
+	if selector := strings.TrimSpace(labelSelector); selector != "" {
+		path += "?labelSelector=" + url.QueryEscape(selector)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	var out PodList
+	if err := c.do(req, &out); err != nil {
+		return nil, err
+	}
+	return out.Items, nil
+}
+
+func (c *Client) GetPodLogs(ctx context.Context, namespace string, name string, opts PodLogOptions) ([]byte, error) {
+	namespace = strings.TrimSpace(namespace)
+	if namespace == "" {
+		namespace = c.namespace
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("pod name is required")
+	}
+	query := url.Values{}
+	if container := strings.TrimSpace(opts.Container); container != "" {
+		query.Set("container", container)
+	}
+	if !opts.SinceTime.IsZero() {
+		query.Set("sinceTime", opts.SinceTime.UTC().Format(time.RFC3339))
+	}
+	if opts.Timestamps {
+		query.Set("timestamps", "true")
+	}
+	if opts.LimitBytes > 0 {
+		query.Set("limitBytes", strconv.FormatInt(opts.LimitBytes, 10))
+	}
+	path := fmt.Sprintf("/api/v1/namespaces/%s/pods/%s/log", namespace, name)
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/plain")
+	return c.doRaw(req)
+}
+
 func (c *Client) do(req *http.Request, out any) error {
 	if req == nil {
 		return errors.New("request is required")
 	}
 	req.Header.Set("Accept", "application/json")
+	body, err := c.doRaw(req)
+	if err != nil {
+		return err
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("decode kubernetes response: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) doRaw(req *http.Request) ([]byte, error) {
+	if req == nil {
+		return nil, errors.New("request is required")
+	}
 	req.Header.Set("Authorization", "Bearer "+c.token)
 
 	resp, err := c.http.Do(req)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(io.LimitReader(resp.Body, 2<<20))
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	switch resp.StatusCode {
 	case http.StatusOK, http.StatusCreated, http.StatusAccepted, http.StatusNoContent:
-		if out == nil {
-			return nil
-		}
-		if err := json.Unmarshal(body, out); err != nil {
-			return fmt.Errorf("decode kubernetes response: %w", err)
-		}
-		return nil
+		return body, nil
 	case http.StatusConflict:
-		return ErrAlreadyExists
+		return nil, ErrAlreadyExists
 	case http.StatusNotFound:
-		return ErrNotFound
+		return nil, ErrNotFound
 	case http.StatusUnauthorized:
-		return ErrUnauthorized
+		return nil, ErrUnauthorized
 	case http.StatusForbidden:
-		return ErrForbidden
+		return nil, ErrForbidden
 	default:
-		return &APIError{StatusCode: resp.StatusCode, Body: string(body)}
+		return nil, &APIError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 }
@@ -35,9 +35,33 @@ type EmptyDirVolumeSource struct {
 	SizeLimit string `json:"sizeLimit,omitempty"`
 }
 
+type HostPathVolumeSource struct {
+	Path string `json:"path"`
+	Type string `json:"type,omitempty"`
+}
+
+type PersistentVolumeClaimVolumeSource struct {
+	ClaimName string `json:"claimName"`
+	ReadOnly  bool   `json:"readOnly,omitempty"`
+}
+
 type Volume struct {
-	Name     string                `json:"name"`
-	EmptyDir *EmptyDirVolumeSource `json:"emptyDir,omitempty"`
+	Name                  string                             `json:"name"`
+	EmptyDir              *EmptyDirVolumeSource              `json:"emptyDir,omitempty"`
+	HostPath              *HostPathVolumeSource              `json:"hostPath,omitempty"`
+	PersistentVolumeClaim *PersistentVolumeClaimVolumeSource `json:"persistentVolumeClaim,omitempty"`
+}
+
+type Toleration struct {
+	Key               string `json:"key,omitempty"`
+	Operator          string `json:"operator,omitempty"`
+	Value             string `json:"value,omitempty"`
+	Effect            string `json:"effect,omitempty"`
+	TolerationSeconds *int64 `json:"tolerationSeconds,omitempty"`
+}
+
+type LocalObjectReference struct {
+	Name string `json:"name"`
 }
 
 type Container struct {
@@ -52,11 +76,15 @@ type Container struct {
 }
 
 type PodSpec struct {
-	RestartPolicy      string      `json:"restartPolicy,omitempty"`
-	ServiceAccountName string      `json:"serviceAccountName,omitempty"`
-	InitContainers     []Container `json:"initContainers,omitempty"`
-	Containers         []Container `json:"containers"`
-	Volumes            []Volume    `json:"volumes,omitempty"`
+	RestartPolicy      string                 `json:"restartPolicy,omitempty"`
+	ServiceAccountName string                 `json:"serviceAccountName,omitempty"`
+	NodeSelector       map[string]string      `json:"nodeSelector,omitempty"`
+	Tolerations        []Toleration           `json:"tolerations,omitempty"`
+	PriorityClassName  string                 `json:"priorityClassName,omitempty"`
+	ImagePullSecrets   []LocalObjectReference `json:"imagePullSecrets,omitempty"`
+	InitContainers     []Container            `json:"initContainers,omitempty"`
+	Containers         []Container            `json:"containers"`
+	Volumes            []Volume               `json:"volumes,omitempty"`
 }
 
 type PodTemplateSpec struct {
@@ -0,0 +1,34 @@
+package k8s
+
+import "time"
+
+type ContainerStatus struct {
+	Name         string `json:"name"`
+	RestartCount int32  `json:"restartCount,omitempty"`
+}
+
+type PodStatus struct {
+	Phase             string            `json:"phase,omitempty"`
+	StartTime         *time.Time        `json:"startTime,omitempty"`
+	ContainerStatuses []ContainerStatus `json:"containerStatuses,omitempty"`
+}
+
+type Pod struct {
+	Metadata ObjectMeta `json:"metadata"`
+	Spec     PodSpec    `json:"spec"`
+	Status   PodStatus  `json:"status,omitempty"`
+}
+
+type PodList struct {
+	Items []Pod `json:"items"`
+}
+
+// PodLogOptions selects a window of container logs. SinceTime has second
+// precision on the API server, so callers that resume from it should
+// request Timestamps and drop lines they already saw.
+type PodLogOptions struct {
+	Container  string
+	SinceTime  time.Time
+	Timestamps bool
+	LimitBytes int64
+}
@@ -0,0 +1,17 @@
+package lineageevent
+
+import "strings"
+
+// Column-level lineage nodes. A dataset_column belongs to a dataset version
+// and a run_feature to the run that produced it.
+const (
+	NodeDatasetColumn = "dataset_column"
+	NodeRunFeature    = "run_feature"
+)
+
+// ColumnNodeID is the node ID of column in the version or run ownerID. The
+// owner comes first and '#' separates it from the column, which may contain
+// any character; owner IDs never contain '#'.
+func ColumnNodeID(ownerID, column string) string {
+	return strings.TrimSpace(ownerID) + "#" + column
+}
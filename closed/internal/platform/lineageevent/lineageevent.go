@@ -10,6 +10,8 @@ import (
 	"fmt"
 	"strings"
 	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/outbox"
 )
 
 type Event struct {
@@ -57,11 +59,59 @@ func Insert(ctx context.Context, q QueryRower, event Event) (int64, error) {
 	if q == nil {
 		return 0, errors.New("queryer is required")
 	}
+	r, err := prepare(event)
+	if err != nil {
+		return 0, err
+	}
+	return insertRow(ctx, q, r)
+}
+
+// Enqueue records event in the outbox instead of lineage_events; the outbox
+// relay inserts it later, so the edge appears only if the transaction passed
+// as q commits.
+func Enqueue(ctx context.Context, q QueryRower, event Event) error {
+	if q == nil {
+		return errors.New("queryer is required")
+	}
+	r, err := prepare(event)
+	if err != nil {
+		return err
+	}
+	if _, err := outbox.Append(ctx, q, outbox.KindLineage, r.IntegritySHA256, r); err != nil {
+		return fmt.Errorf("enqueue lineage event: %w", err)
+	}
+	return nil
+}
+
+// DeliverOutbox is the outbox.DeliverFunc for outbox.KindLineage.
+func DeliverOutbox(ctx context.Context, tx *sql.Tx, record json.RawMessage) (int64, error) {
+	var r row
+	if err := json.Unmarshal(record, &r); err != nil {
+		return 0, fmt.Errorf("decode lineage outbox record: %w", err)
+	}
+	return insertRow(ctx, tx, r)
+}
+
+// row is a lineage event as stored and as carried by the outbox.
+type row struct {
+	OccurredAt      time.Time       `json:"occurred_at"`
+	Actor           string          `json:"actor"`
+	RequestID       string          `json:"request_id,omitempty"`
+	SubjectType     string          `json:"subject_type"`
+	SubjectID       string          `json:"subject_id"`
+	Predicate       string          `json:"predicate"`
+	ObjectType      string          `json:"object_type"`
+	ObjectID        string          `json:"object_id"`
+	Metadata        json.RawMessage `json:"metadata"`
+	IntegritySHA256 string          `json:"integrity_sha256"`
+}
+
+func prepare(event Event) (row, error) {
 	if event.OccurredAt.IsZero() {
 		event.OccurredAt = time.Now().UTC()
 	}
 	if err := event.Validate(); err != nil {
-		return 0, err
+		return row{}, err
 	}
 
 	metadata := event.Metadata
@@ -70,21 +120,35 @@ func Insert(ctx context.Context, q QueryRower, event Event) (int64, error) {
 	}
 	metadataJSON, err := json.Marshal(metadata)
 	if err != nil {
-		return 0, fmt.Errorf("marshal metadata: %w", err)
+		return row{}, fmt.Errorf("marshal metadata: %w", err)
 	}
 
 	integrity, err := ComputeIntegritySHA256(event, metadataJSON)
 	if err != nil {
-		return 0, err
-	}
+		return row{}, err
+	}
+	return row{
+		OccurredAt:      event.OccurredAt.UTC(),
+		Actor:           strings.TrimSpace(event.Actor),
+		RequestID:       strings.TrimSpace(event.RequestID),
+		SubjectType:     strings.TrimSpace(event.SubjectType),
+		SubjectID:       strings.TrimSpace(event.SubjectID),
+		Predicate:       strings.TrimSpace(event.Predicate),
+		ObjectType:      strings.TrimSpace(event.ObjectType),
+		ObjectID:        strings.TrimSpace(event.ObjectID),
+		Metadata:        metadataJSON,
+		IntegritySHA256: integrity,
+	}, nil
+}
 
+func insertRow(ctx context.Context, q QueryRower, r row) (int64, error) {
 	var requestID sql.NullString
-	if strings.TrimSpace(event.RequestID) != "" {
-		requestID = sql.NullString{String: strings.TrimSpace(event.RequestID), Valid: true}
+	if r.RequestID != "" {
+		requestID = sql.NullString{String: r.RequestID, Valid: true}
 	}
 
 	var id int64
-	err = q.QueryRowContext(
+	err := q.QueryRowContext(
 		ctx,
 		`INSERT INTO lineage_events (
 			occurred_at,
@@ -99,16 +163,16 @@ func Insert(ctx context.Context, q QueryRower, event Event) (int64, error) {
 			integrity_sha256
 		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)
 		RETURNING event_id`,
-		event.OccurredAt.UTC(),
-		strings.TrimSpace(event.Actor),
+		r.OccurredAt,
+		r.Actor,
 		requestID,
-		strings.TrimSpace(event.SubjectType),
-		strings.TrimSpace(event.SubjectID),
-		strings.TrimSpace(event.Predicate),
-		strings.TrimSpace(event.ObjectType),
-		strings.TrimSpace(event.ObjectID),
-		metadataJSON,
-		integrity,
+		r.SubjectType,
+		r.SubjectID,
+		r.Predicate,
+		r.ObjectType,
+		r.ObjectID,
+		[]byte(r.Metadata),
+		r.IntegritySHA256,
 	).Scan(&id)
 	if err != nil {
 		return 0, fmt.Errorf("insert lineage event: %w", err)
@@ -1,6 +1,7 @@
 package lineageevent
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 )
@@ -56,3 +57,38 @@ func TestComputeIntegritySHA256_ChangesOnMetadata(t *testing.T) {
 		t.Fatalf("expected integrity to differ")
 	}
 }
+
+func TestOutboxRecordRoundTrip(t *testing.T) {
+	event := Event{
+		OccurredAt:  time.Unix(1700000000, 123456789).UTC(),
+		Actor:       " alice ",
+		SubjectType: "dataset_version",
+		SubjectID:   "dv-1",
+		Predicate:   "used_by",
+		ObjectType:  "experiment_run",
+		ObjectID:    "run-1",
+		Metadata:    map[string]any{"a": 1},
+	}
+	r, err := prepare(event)
+	if err != nil {
+		t.Fatalf("prepare() err=%v", err)
+	}
+	blob, err := json.Marshal(r)
+	if err != nil {
+		t.Fatalf("marshal err=%v", err)
+	}
+	var decoded row
+	if err := json.Unmarshal(blob, &decoded); err != nil {
+		t.Fatalf("unmarshal err=%v", err)
+	}
+	if !decoded.OccurredAt.Equal(r.OccurredAt) || decoded.Actor != "alice" || string(decoded.Metadata) != string(r.Metadata) {
+		t.Fatalf("round trip mismatch: %+v vs %+v", decoded, r)
+	}
+	integrity, err := ComputeIntegritySHA256(event, decoded.Metadata)
+	if err != nil {
+		t.Fatalf("ComputeIntegritySHA256() err=%v", err)
+	}
+	if decoded.IntegritySHA256 != integrity {
+		t.Fatalf("integrity changed across the outbox: %q vs %q", decoded.IntegritySHA256, integrity)
+	}
+}
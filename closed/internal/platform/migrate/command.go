@@ -0,0 +1,70 @@
+package migrate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/env"
+)
+
+// Startup handles migrations in a service binary. `<service> migrate ...`
+// runs the subcommand and reports done, telling the caller to exit instead
+// of serving. Otherwise pending migrations are applied before serving when
+// ANIMUS_MIGRATE_ON_START is true.
+func Startup(ctx context.Context, m *Migrator, args []string, stdout io.Writer) (done bool, err error) {
+	if len(args) > 0 && args[0] == "migrate" {
+		return true, Command(ctx, m, args[1:], stdout)
+	}
+	onStart, err := env.Bool("ANIMUS_MIGRATE_ON_START", false)
+	if err != nil || !onStart {
+		return false, err
+	}
+	_, err = m.Up(ctx)
+	return false, err
+}
+
+// Command runs `migrate up`, `migrate down [N]` (default 1) or
+// `migrate status`; status prints the Status document as JSON.
+func Command(ctx context.Context, m *Migrator, args []string, stdout io.Writer) error {
+	action := "up"
+	if len(args) > 0 {
+		action = args[0]
+	}
+	switch action {
+	case "up":
+		applied, err := m.Up(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "applied %d migration(s)\n", len(applied))
+		return nil
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("migrate down: invalid step count %q", args[1])
+			}
+			steps = n
+		}
+		reverted, err := m.Down(ctx, steps)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "reverted %d migration(s)\n", len(reverted))
+		return nil
+	case "status":
+		st, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(st)
+	default:
+		return fmt.Errorf("migrate: unknown action %q (want up, down [N] or status)", action)
+	}
+}
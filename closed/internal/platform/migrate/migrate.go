@@ -0,0 +1,333 @@
+// Package migrate applies the versioned SQL files of closed/migrations
+// (NNNNNN_name.up.sql / NNNNNN_name.down.sql) and records them in
+// schema_migrations. Every run holds a Postgres advisory lock, so replicas
+// started together apply each migration once.
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const lockName = "animus:schema_migrations"
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+	// Checksum is the sha256 of the up script, recorded when applied.
+	Checksum string
+}
+
+// Load reads migrations from the root of fsys ordered by version. A version
+// without an up script or with two scripts of the same direction is an
+// error.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		file := entry.Name()
+		if entry.IsDir() || path.Ext(file) != ".sql" {
+			continue
+		}
+		base, direction := "", ""
+		switch {
+		case strings.HasSuffix(file, ".up.sql"):
+			base, direction = strings.TrimSuffix(file, ".up.sql"), "up"
+		case strings.HasSuffix(file, ".down.sql"):
+			base, direction = strings.TrimSuffix(file, ".down.sql"), "down"
+		default:
+			return nil, fmt.Errorf("migration %s: expected .up.sql or .down.sql", file)
+		}
+		rawVersion, _, ok := strings.Cut(base, "_")
+		version, err := strconv.ParseInt(rawVersion, 10, 64)
+		if !ok || err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %s: expected NNNNNN_name", file)
+		}
+		content, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: base}
+			byVersion[version] = m
+		}
+		if m.Name != base {
+			return nil, fmt.Errorf("migration version %d: conflicting names %s and %s", version, m.Name, base)
+		}
+		if direction == "up" {
+			if m.Up != "" {
+				return nil, fmt.Errorf("migration %s: duplicate up script", base)
+			}
+			m.Up = string(content)
+			sum := sha256.Sum256(content)
+			m.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			if m.Down != "" {
+				return nil, fmt.Errorf("migration %s: duplicate down script", base)
+			}
+			m.Down = string(content)
+		}
+	}
+	out := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %s: missing up script", m.Name)
+		}
+		out = append(out, *m)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Version < out[j].Version })
+	return out, nil
+}
+
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+	logger     *slog.Logger
+}
+
+func New(db *sql.DB, fsys fs.FS, logger *slog.Logger) (*Migrator, error) {
+	if db == nil {
+		return nil, errors.New("database is required")
+	}
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Migrator{db: db, migrations: migrations, logger: logger}, nil
+}
+
+// Applied is a schema_migrations row. Checksum is empty for versions applied
+// before checksums were recorded.
+type Applied struct {
+	Version   int64     `json:"version"`
+	Name      string    `json:"name"`
+	Checksum  string    `json:"checksum,omitempty"`
+	AppliedAt time.Time `json:"applied_at"`
+}
+
+// Status compares schema_migrations with the embedded files.
+type Status struct {
+	// Current is the highest applied version, 0 on an empty database.
+	Current int64     `json:"current_version"`
+	Latest  int64     `json:"latest_version"`
+	Applied []Applied `json:"applied"`
+	Pending []Pending `json:"pending"`
+	// Modified lists applied versions whose up script changed since.
+	Modified []int64 `json:"modified"`
+	// Unknown lists applied versions this binary has no file for, e.g. when
+	// the database was migrated by a newer release.
+	Unknown []int64 `json:"unknown"`
+}
+
+type Pending struct {
+	Version int64  `json:"version"`
+	Name    string `json:"name"`
+}
+
+// Status is read-only: it neither creates schema_migrations nor waits for
+// the migration lock.
+func (m *Migrator) Status(ctx context.Context) (Status, error) {
+	var exists bool
+	if err := m.db.QueryRowContext(ctx, `SELECT to_regclass('schema_migrations') IS NOT NULL`).Scan(&exists); err != nil {
+		return Status{}, err
+	}
+	if !exists {
+		return m.status([]Applied{}), nil
+	}
+	applied, err := listApplied(ctx, m.db)
+	if err != nil {
+		return Status{}, err
+	}
+	return m.status(applied), nil
+}
+
+func (m *Migrator) status(applied []Applied) Status {
+	st := Status{Applied: applied, Pending: []Pending{}, Modified: []int64{}, Unknown: []int64{}}
+	known := make(map[int64]Migration, len(m.migrations))
+	for _, mig := range m.migrations {
+		known[mig.Version] = mig
+		st.Latest = mig.Version
+	}
+	done := make(map[int64]bool, len(applied))
+	for _, a := range applied {
+		done[a.Version] = true
+		if a.Version > st.Current {
+			st.Current = a.Version
+		}
+		mig, ok := known[a.Version]
+		switch {
+		case !ok:
+			st.Unknown = append(st.Unknown, a.Version)
+		case a.Checksum != "" && a.Checksum != mig.Checksum:
+			st.Modified = append(st.Modified, a.Version)
+		}
+	}
+	for _, mig := range m.migrations {
+		if !done[mig.Version] {
+			st.Pending = append(st.Pending, Pending{Version: mig.Version, Name: mig.Name})
+		}
+	}
+	return st
+}
+
+// Up applies every pending migration in version order, each in its own
+// transaction, and returns the applied versions.
+func (m *Migrator) Up(ctx context.Context) ([]int64, error) {
+	var done []int64
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		applied, err := listApplied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, pending := range m.status(applied).Pending {
+			mig := m.find(pending.Version)
+			started := time.Now()
+			if err := apply(ctx, conn, mig.Up, func(tx *sql.Tx) error {
+				_, err := tx.ExecContext(ctx,
+					`INSERT INTO schema_migrations (version, name, checksum) VALUES ($1, $2, $3)`,
+					mig.Version, mig.Name, mig.Checksum)
+				return err
+			}); err != nil {
+				return fmt.Errorf("apply %s: %w", mig.Name, err)
+			}
+			m.logger.Info("migration applied", "version", mig.Version, "name", mig.Name, "duration", time.Since(started))
+			done = append(done, mig.Version)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down reverts the latest steps applied migrations, newest first.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]int64, error) {
+	if steps < 1 {
+		return nil, errors.New("steps must be >= 1")
+	}
+	var done []int64
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		applied, err := listApplied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for i := len(applied) - 1; i >= 0 && len(done) < steps; i-- {
+			mig := m.find(applied[i].Version)
+			if mig == nil || mig.Down == "" {
+				return fmt.Errorf("revert version %d: no down script", applied[i].Version)
+			}
+			if err := apply(ctx, conn, mig.Down, func(tx *sql.Tx) error {
+				_, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = $1`, mig.Version)
+				return err
+			}); err != nil {
+				return fmt.Errorf("revert %s: %w", mig.Name, err)
+			}
+			m.logger.Info("migration reverted", "version", mig.Version, "name", mig.Name)
+			done = append(done, mig.Version)
+		}
+		return nil
+	})
+	return done, err
+}
+
+func (m *Migrator) find(version int64) *Migration {
+	for i := range m.migrations {
+		if m.migrations[i].Version == version {
+			return &m.migrations[i]
+		}
+	}
+	return nil
+}
+
+// locked runs fn on a dedicated connection holding the session advisory lock.
+// Concurrent callers wait for the lock and then see the work already done.
+func (m *Migrator) locked(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close() }()
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock(hashtext($1))`, lockName); err != nil {
+		return fmt.Errorf("acquire migration lock: %w", err)
+	}
+	defer func() {
+		_, _ = conn.ExecContext(context.WithoutCancel(ctx), `SELECT pg_advisory_unlock(hashtext($1))`, lockName)
+	}()
+	if err := ensureTable(ctx, conn); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+func apply(ctx context.Context, conn *sql.Conn, script string, record func(tx *sql.Tx) error) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return err
+	}
+	if err := record(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+type execQuerier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// ensureTable creates schema_migrations in the layout the Helm migration job
+// used, adding the checksum column on databases created by it.
+func ensureTable(ctx context.Context, db execQuerier) error {
+	_, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+  version BIGINT PRIMARY KEY,
+  name TEXT NOT NULL,
+  applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+ALTER TABLE schema_migrations ADD COLUMN IF NOT EXISTS checksum TEXT`)
+	if err != nil {
+		return fmt.Errorf("ensure schema_migrations: %w", err)
+	}
+	return nil
+}
+
+func listApplied(ctx context.Context, db execQuerier) ([]Applied, error) {
+	// to_jsonb tolerates tables the Helm job created without checksum.
+	rows, err := db.QueryContext(ctx, `SELECT version, name, COALESCE(to_jsonb(m)->>'checksum', ''), applied_at
+		FROM schema_migrations m ORDER BY version`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	applied := []Applied{}
+	for rows.Next() {
+		var a Applied
+		if err := rows.Scan(&a.Version, &a.Name, &a.Checksum, &a.AppliedAt); err != nil {
+			return nil, err
+		}
+		a.AppliedAt = a.AppliedAt.UTC()
+		applied = append(applied, a)
+	}
+	return applied, rows.Err()
+}
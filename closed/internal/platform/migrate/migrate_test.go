@@ -0,0 +1,90 @@
+package migrate
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/migrations"
+)
+
+func TestLoadEmbeddedMigrations(t *testing.T) {
+	loaded, err := Load(migrations.Files)
+	if err != nil {
+		t.Fatalf("Load(embedded): %v", err)
+	}
+	if len(loaded) == 0 || loaded[0].Version != 1 {
+		t.Fatalf("unexpected migrations: %d", len(loaded))
+	}
+	for i := 1; i < len(loaded); i++ {
+		if loaded[i].Version <= loaded[i-1].Version {
+			t.Fatalf("migrations out of order at %s", loaded[i].Name)
+		}
+	}
+}
+
+func TestLoadRejectsMalformed(t *testing.T) {
+	for name, fsys := range map[string]fstest.MapFS{
+		"missing up":   {"000001_a.down.sql": {Data: []byte("DROP TABLE a;")}},
+		"bad version":  {"v1_a.up.sql": {Data: []byte("SELECT 1;")}},
+		"name clash":   {"000001_a.up.sql": {Data: []byte("SELECT 1;")}, "000001_b.down.sql": {Data: []byte("SELECT 1;")}},
+		"no direction": {"000001_a.sql": {Data: []byte("SELECT 1;")}},
+	} {
+		if _, err := Load(fsys); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestStatusDetectsDrift(t *testing.T) {
+	fsys := fstest.MapFS{
+		"000001_a.up.sql":   {Data: []byte("CREATE TABLE a (id INT);")},
+		"000001_a.down.sql": {Data: []byte("DROP TABLE a;")},
+		"000002_b.up.sql":   {Data: []byte("CREATE TABLE b (id INT);")},
+		"000003_c.up.sql":   {Data: []byte("CREATE TABLE c (id INT);")},
+		"README.md":         {Data: []byte("ignored")},
+	}
+	loaded, err := Load(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &Migrator{migrations: loaded}
+	at := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	st := m.status([]Applied{
+		{Version: 1, Name: "000001_a", AppliedAt: at},
+		{Version: 2, Name: "000002_b", Checksum: "0000", AppliedAt: at},
+		{Version: 4, Name: "000004_d", AppliedAt: at},
+	})
+	if st.Current != 4 || st.Latest != 3 {
+		t.Fatalf("current=%d latest=%d", st.Current, st.Latest)
+	}
+	if len(st.Pending) != 1 || st.Pending[0].Name != "000003_c" {
+		t.Fatalf("pending=%+v", st.Pending)
+	}
+	if len(st.Modified) != 1 || st.Modified[0] != 2 {
+		t.Fatalf("modified=%v", st.Modified)
+	}
+	if len(st.Unknown) != 1 || st.Unknown[0] != 4 {
+		t.Fatalf("unknown=%v", st.Unknown)
+	}
+}
+
+func TestCommandRejectsBadArguments(t *testing.T) {
+	m := &Migrator{}
+	var out bytes.Buffer
+	for _, args := range [][]string{{"sideways"}, {"down", "0"}, {"down", "x"}} {
+		err := Command(context.Background(), m, args, &out)
+		if err == nil || !strings.Contains(err.Error(), "migrate") {
+			t.Errorf("args=%v: err=%v", args, err)
+		}
+	}
+
+	t.Setenv("ANIMUS_MIGRATE_ON_START", "false")
+	done, err := Startup(context.Background(), m, []string{"serve"}, &out)
+	if done || err != nil {
+		t.Fatalf("Startup without migrate subcommand: done=%v err=%v", done, err)
+	}
+}
@@ -62,3 +62,39 @@ func (c Config) Validate() error {
 	}
 	return nil
 }
+
+const objectURIScheme = "s3://"
+
+// ParseObjectURI splits an s3://bucket/key URI. The key may contain slashes
+// but no empty, "." or ".." segments.
+func ParseObjectURI(uri string) (bucket, key string, ok bool) {
+	rest, found := strings.CutPrefix(strings.TrimSpace(uri), objectURIScheme)
+	if !found {
+		return "", "", false
+	}
+	bucket, key, found = strings.Cut(rest, "/")
+	if !found || bucket == "" || key == "" {
+		return "", "", false
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return "", "", false
+		}
+	}
+	return bucket, key, true
+}
+
+// ObjectURI is the inverse of ParseObjectURI.
+func ObjectURI(bucket, key string) string {
+	return objectURIScheme + bucket + "/" + key
+}
+
+// DatasetObject locates a dataset version's content. Reference versions
+// store an s3:// URI as their object key; all others a key in the datasets
+// bucket.
+func (c Config) DatasetObject(objectKey string) (bucket, key string) {
+	if bucket, key, ok := ParseObjectURI(objectKey); ok {
+		return bucket, key
+	}
+	return c.BucketDatasets, objectKey
+}
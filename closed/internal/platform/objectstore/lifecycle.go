@@ -0,0 +1,116 @@
+package objectstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/env"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/lifecycle"
+	"github.com/minio/minio-go/v7/pkg/tags"
+)
+
+const (
+	// ArchivedTagKey marks objects of archived datasets; the archive lifecycle
+	// rule transitions only tagged objects.
+	ArchivedTagKey   = "animus-lifecycle"
+	ArchivedTagValue = "archived"
+
+	archiveRuleID = "animus-archived-datasets"
+)
+
+// ArchiveTierConfig selects the storage tier archived dataset versions move
+// to. For MinIO the storage class is the name of a remote tier.
+type ArchiveTierConfig struct {
+	StorageClass   string
+	TransitionDays int
+}
+
+func ArchiveTierConfigFromEnv() (ArchiveTierConfig, error) {
+	days, err := env.Int("ANIMUS_MINIO_ARCHIVE_TRANSITION_DAYS", 1)
+	if err != nil {
+		return ArchiveTierConfig{}, err
+	}
+	cfg := ArchiveTierConfig{
+		StorageClass:   strings.TrimSpace(env.String("ANIMUS_MINIO_ARCHIVE_STORAGE_CLASS", "")),
+		TransitionDays: days,
+	}
+	if cfg.Enabled() && cfg.TransitionDays < 1 {
+		return ArchiveTierConfig{}, errors.New("ANIMUS_MINIO_ARCHIVE_TRANSITION_DAYS must be at least 1")
+	}
+	return cfg, nil
+}
+
+func (c ArchiveTierConfig) Enabled() bool {
+	return c.StorageClass != ""
+}
+
+func archiveLifecycleRule(cfg ArchiveTierConfig) lifecycle.Rule {
+	return lifecycle.Rule{
+		ID:         archiveRuleID,
+		Status:     "Enabled",
+		RuleFilter: lifecycle.Filter{Tag: lifecycle.Tag{Key: ArchivedTagKey, Value: ArchivedTagValue}},
+		Transition: lifecycle.Transition{
+			StorageClass: cfg.StorageClass,
+			Days:         lifecycle.ExpirationDays(cfg.TransitionDays),
+		},
+	}
+}
+
+// withLifecycleRule replaces the rule with the same ID, keeping rules that
+// operators configured on the bucket themselves.
+func withLifecycleRule(config *lifecycle.Configuration, rule lifecycle.Rule) *lifecycle.Configuration {
+	out := lifecycle.NewConfiguration()
+	if config != nil {
+		for _, existing := range config.Rules {
+			if existing.ID != rule.ID {
+				out.Rules = append(out.Rules, existing)
+			}
+		}
+	}
+	out.Rules = append(out.Rules, rule)
+	return out
+}
+
+// EnsureArchiveLifecycle installs the transition rule for archived objects.
+// It is a no-op when no archive tier is configured.
+func EnsureArchiveLifecycle(ctx context.Context, client *minio.Client, bucket string, cfg ArchiveTierConfig) error {
+	if !cfg.Enabled() {
+		return nil
+	}
+	current, err := client.GetBucketLifecycle(ctx, bucket)
+	if err != nil {
+		if minio.ToErrorResponse(err).Code != "NoSuchLifecycleConfiguration" {
+			return fmt.Errorf("get bucket lifecycle: %w", err)
+		}
+		current = nil
+	}
+	if err := client.SetBucketLifecycle(ctx, bucket, withLifecycleRule(current, archiveLifecycleRule(cfg))); err != nil {
+		return fmt.Errorf("set bucket lifecycle: %w", err)
+	}
+	return nil
+}
+
+// SetArchivedTag adds or removes the archive tag, preserving other tags.
+func SetArchivedTag(ctx context.Context, client *minio.Client, bucket, key string, archived bool) error {
+	current, err := client.GetObjectTagging(ctx, bucket, key, minio.GetObjectTaggingOptions{})
+	if err != nil {
+		return err
+	}
+	values := current.ToMap()
+	if archived {
+		values[ArchivedTagKey] = ArchivedTagValue
+	} else {
+		delete(values, ArchivedTagKey)
+	}
+	if len(values) == 0 {
+		return client.RemoveObjectTagging(ctx, bucket, key, minio.RemoveObjectTaggingOptions{})
+	}
+	next, err := tags.MapToObjectTags(values)
+	if err != nil {
+		return err
+	}
+	return client.PutObjectTagging(ctx, bucket, key, next, minio.PutObjectTaggingOptions{})
+}
@@ -83,3 +83,27 @@ func newTransport() *http.Transport {
 		ExpectContinueTimeout: 1 * time.Second,
 	}
 }
+
+// EnsureLockedBucket creates bucket with object locking when it is missing and
+// reports whether object lock is enabled on it. Stores without object lock
+// support report false rather than an error.
+func EnsureLockedBucket(ctx context.Context, client *minio.Client, bucket string, region string) (bool, error) {
+	exists, err := client.BucketExists(ctx, bucket)
+	if err != nil {
+		return false, err
+	}
+	if !exists {
+		if err := client.MakeBucket(ctx, bucket, minio.MakeBucketOptions{Region: region, ObjectLocking: true}); err != nil {
+			// Stores without object lock reject the option; fall back to a
+			// plain bucket.
+			if err := client.MakeBucket(ctx, bucket, minio.MakeBucketOptions{Region: region}); err != nil {
+				return false, err
+			}
+		}
+	}
+	status, _, _, _, err := client.GetObjectLockConfig(ctx, bucket)
+	if err != nil {
+		return false, nil
+	}
+	return status == "Enabled", nil
+}
@@ -1,6 +1,10 @@
 package objectstore
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/minio/minio-go/v7/pkg/lifecycle"
+)
 
 func TestConfigValidate(t *testing.T) {
 	valid := Config{
@@ -22,3 +26,41 @@ func TestConfigValidate(t *testing.T) {
 		t.Fatalf("Validate() expected error for scheme in endpoint")
 	}
 }
+
+func TestParseObjectURI(t *testing.T) {
+	bucket, key, ok := ParseObjectURI("s3://team-lake/raw/2026/events.parquet")
+	if !ok || bucket != "team-lake" || key != "raw/2026/events.parquet" {
+		t.Fatalf("got %q %q %v", bucket, key, ok)
+	}
+	if ObjectURI(bucket, key) != "s3://team-lake/raw/2026/events.parquet" {
+		t.Fatalf("ObjectURI round trip failed")
+	}
+	for _, bad := range []string{"team-lake/raw", "s3://team-lake", "s3://team-lake/", "s3:///raw", "s3://b/a//c", "s3://b/a/../c", "https://b/a"} {
+		if _, _, ok := ParseObjectURI(bad); ok {
+			t.Errorf("ParseObjectURI(%q) accepted", bad)
+		}
+	}
+
+	cfg := Config{BucketDatasets: "datasets"}
+	if bucket, key := cfg.DatasetObject("ds-1/v-1/train.csv"); bucket != "datasets" || key != "ds-1/v-1/train.csv" {
+		t.Fatalf("uploaded object = %q %q", bucket, key)
+	}
+	if bucket, key := cfg.DatasetObject("s3://team-lake/raw/a.csv"); bucket != "team-lake" || key != "raw/a.csv" {
+		t.Fatalf("reference object = %q %q", bucket, key)
+	}
+}
+
+func TestWithLifecycleRuleKeepsOperatorRules(t *testing.T) {
+	operator := lifecycle.Rule{ID: "expire-tmp", Status: "Enabled", RuleFilter: lifecycle.Filter{Prefix: "tmp/"}}
+	stale := archiveLifecycleRule(ArchiveTierConfig{StorageClass: "COLD", TransitionDays: 30})
+	current := &lifecycle.Configuration{Rules: []lifecycle.Rule{operator, stale}}
+
+	next := withLifecycleRule(current, archiveLifecycleRule(ArchiveTierConfig{StorageClass: "GLACIER", TransitionDays: 1}))
+	if len(next.Rules) != 2 || next.Rules[0].ID != "expire-tmp" {
+		t.Fatalf("unexpected rules: %+v", next.Rules)
+	}
+	archive := next.Rules[1]
+	if archive.Transition.StorageClass != "GLACIER" || archive.Transition.Days != 1 || archive.RuleFilter.Tag.Key != ArchivedTagKey {
+		t.Fatalf("unexpected archive rule: %+v", archive)
+	}
+}
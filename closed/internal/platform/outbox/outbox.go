@@ -0,0 +1,60 @@
+// Package outbox stores audit, lineage and message bus events in event_outbox
+// inside the transaction of the change that produced them; a Relay later
+// copies them to their tables or publishes them. Handlers that cannot write
+// events in their own transaction enqueue them here so a failed secondary
+// write never loses or repeats an event.
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	KindAudit   = "audit"
+	KindLineage = "lineage"
+	// KindBus records are published to the message bus; they have no event id.
+	KindBus = "bus"
+)
+
+type QueryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+const appendQuery = `INSERT INTO event_outbox (kind, dedupe_key, record)
+	VALUES ($1,$2,$3)
+	ON CONFLICT (kind, dedupe_key) DO NOTHING
+	RETURNING outbox_id`
+
+// Append stores record for the relay. A record already enqueued under the
+// same kind and dedupeKey is kept and Append returns 0.
+func Append(ctx context.Context, q QueryRower, kind, dedupeKey string, record any) (int64, error) {
+	if q == nil {
+		return 0, errors.New("queryer is required")
+	}
+	kind = strings.TrimSpace(kind)
+	if kind != KindAudit && kind != KindLineage && kind != KindBus {
+		return 0, fmt.Errorf("unsupported outbox kind %q", kind)
+	}
+	dedupeKey = strings.TrimSpace(dedupeKey)
+	if dedupeKey == "" {
+		return 0, errors.New("dedupe key is required")
+	}
+	blob, err := json.Marshal(record)
+	if err != nil {
+		return 0, fmt.Errorf("marshal outbox record: %w", err)
+	}
+
+	var id int64
+	if err := q.QueryRowContext(ctx, appendQuery, kind, dedupeKey, blob).Scan(&id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("insert outbox record: %w", err)
+	}
+	return id, nil
+}
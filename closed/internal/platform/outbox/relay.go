@@ -0,0 +1,288 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/env"
+)
+
+// DeliverFunc writes one outbox record to its destination table inside tx
+// and returns the new event id, or 0 when the destination has none.
+type DeliverFunc func(ctx context.Context, tx *sql.Tx, record json.RawMessage) (int64, error)
+
+type Config struct {
+	Interval       time.Duration
+	BatchSize      int
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+	// Retention is how long delivered records are kept; zero keeps them.
+	Retention time.Duration
+}
+
+func ConfigFromEnv() (Config, error) {
+	interval, err := env.Duration("ANIMUS_OUTBOX_RELAY_INTERVAL", time.Second)
+	if err != nil {
+		return Config{}, err
+	}
+	batchSize, err := env.Int("ANIMUS_OUTBOX_BATCH_SIZE", 100)
+	if err != nil {
+		return Config{}, err
+	}
+	retryBase, err := env.Duration("ANIMUS_OUTBOX_RETRY_BASE", time.Second)
+	if err != nil {
+		return Config{}, err
+	}
+	retryMax, err := env.Duration("ANIMUS_OUTBOX_RETRY_MAX", 5*time.Minute)
+	if err != nil {
+		return Config{}, err
+	}
+	retention, err := env.Duration("ANIMUS_OUTBOX_RETENTION", 7*24*time.Hour)
+	if err != nil {
+		return Config{}, err
+	}
+	cfg := Config{
+		Interval:       interval,
+		BatchSize:      batchSize,
+		RetryBaseDelay: retryBase,
+		RetryMaxDelay:  retryMax,
+		Retention:      retention,
+	}
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+func (c Config) Validate() error {
+	if c.Interval <= 0 {
+		return fmt.Errorf("outbox relay interval must be positive")
+	}
+	if c.BatchSize <= 0 {
+		return fmt.Errorf("outbox batch size must be positive")
+	}
+	if c.RetryBaseDelay <= 0 {
+		return fmt.Errorf("outbox retry base must be positive")
+	}
+	if c.RetryMaxDelay < c.RetryBaseDelay {
+		return fmt.Errorf("outbox retry max must be >= base")
+	}
+	if c.Retention < 0 {
+		return fmt.Errorf("outbox retention must not be negative")
+	}
+	return nil
+}
+
+const (
+	claimQuery = `SELECT outbox_id, kind, record, attempts
+		FROM event_outbox
+		WHERE delivered_at IS NULL AND next_attempt_at <= $1 AND kind = ANY($3)
+		ORDER BY outbox_id
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED`
+	markDeliveredQuery = `UPDATE event_outbox
+		SET delivered_at = $2, event_id = NULLIF($3, 0), attempts = attempts + 1, last_error = NULL
+		WHERE outbox_id = $1`
+	markFailedQuery = `UPDATE event_outbox
+		SET attempts = attempts + 1, last_error = $2, next_attempt_at = $3
+		WHERE outbox_id = $1`
+	pruneQuery = `DELETE FROM event_outbox
+		WHERE delivered_at IS NOT NULL AND delivered_at < $1`
+)
+
+// Relay moves pending outbox records to their tables. Claiming uses SKIP
+// LOCKED, so every service with a relay may run one against the same
+// database; each relay claims only the kinds it can deliver.
+type Relay struct {
+	db      *sql.DB
+	logger  *slog.Logger
+	cfg     Config
+	deliver map[string]DeliverFunc
+	kinds   []string
+	now     func() time.Time
+}
+
+func NewRelay(db *sql.DB, logger *slog.Logger, cfg Config, deliver map[string]DeliverFunc) *Relay {
+	kinds := make([]string, 0, len(deliver))
+	for kind, fn := range deliver {
+		if fn != nil {
+			kinds = append(kinds, kind)
+		}
+	}
+	slices.Sort(kinds)
+	return &Relay{
+		db:      db,
+		logger:  logger,
+		cfg:     cfg,
+		deliver: deliver,
+		kinds:   kinds,
+		now:     time.Now,
+	}
+}
+
+// Kinds returns the record kinds the relay claims.
+func (r *Relay) Kinds() []string {
+	return slices.Clone(r.kinds)
+}
+
+func (r *Relay) Run(ctx context.Context) {
+	if r == nil || r.db == nil {
+		return
+	}
+	interval := r.cfg.Interval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// Drain the backlog before waiting for the next tick.
+			for {
+				delivered, err := r.RelayOnce(ctx)
+				if err != nil {
+					if ctx.Err() == nil && r.logger != nil {
+						r.logger.Warn("outbox relay failed", "error", err)
+					}
+					break
+				}
+				if delivered < r.batchSize() {
+					break
+				}
+			}
+		}
+	}
+}
+
+type entry struct {
+	id       int64
+	kind     string
+	record   json.RawMessage
+	attempts int
+}
+
+// RelayOnce delivers one batch in a single transaction and returns the number
+// of records it claimed. Each record is delivered under a savepoint: a failed
+// record is rescheduled with backoff while the rest of the batch commits, and
+// a delivered record is marked in the same transaction as its event row.
+func (r *Relay) RelayOnce(ctx context.Context) (int, error) {
+	if r == nil || r.db == nil {
+		return 0, fmt.Errorf("outbox relay not initialized")
+	}
+	if len(r.kinds) == 0 {
+		return 0, nil
+	}
+	now := r.now().UTC()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin outbox tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	batch, err := claim(ctx, tx, now, r.batchSize(), r.kinds)
+	if err != nil {
+		return 0, err
+	}
+	for _, e := range batch {
+		if err := r.deliverEntry(ctx, tx, now, e); err != nil {
+			return 0, err
+		}
+	}
+	if r.cfg.Retention > 0 {
+		if _, err := tx.ExecContext(ctx, pruneQuery, now.Add(-r.cfg.Retention)); err != nil {
+			return 0, fmt.Errorf("prune outbox: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit outbox tx: %w", err)
+	}
+	return len(batch), nil
+}
+
+func claim(ctx context.Context, tx *sql.Tx, now time.Time, limit int, kinds []string) ([]entry, error) {
+	rows, err := tx.QueryContext(ctx, claimQuery, now, limit, kinds)
+	if err != nil {
+		return nil, fmt.Errorf("claim outbox: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]entry, 0, limit)
+	for rows.Next() {
+		var e entry
+		if err := rows.Scan(&e.id, &e.kind, &e.record, &e.attempts); err != nil {
+			return nil, fmt.Errorf("scan outbox: %w", err)
+		}
+		out = append(out, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("claim outbox: %w", err)
+	}
+	return out, nil
+}
+
+// deliverEntry returns an error only when the transaction itself can no
+// longer be used; delivery failures are recorded on the row.
+func (r *Relay) deliverEntry(ctx context.Context, tx *sql.Tx, now time.Time, e entry) error {
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT outbox_entry"); err != nil {
+		return fmt.Errorf("outbox savepoint: %w", err)
+	}
+	eventID, deliverErr := r.deliverRecord(ctx, tx, e)
+	if deliverErr != nil {
+		if _, err := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT outbox_entry"); err != nil {
+			return fmt.Errorf("outbox rollback to savepoint: %w", err)
+		}
+		next := now.Add(backoffDelay(e.attempts+1, r.cfg.RetryBaseDelay, r.cfg.RetryMaxDelay))
+		if _, err := tx.ExecContext(ctx, markFailedQuery, e.id, deliverErr.Error(), next); err != nil {
+			return fmt.Errorf("mark outbox failed: %w", err)
+		}
+		if r.logger != nil {
+			r.logger.Warn("outbox delivery failed", "outbox_id", e.id, "kind", e.kind, "attempts", e.attempts+1, "error", deliverErr)
+		}
+	} else if _, err := tx.ExecContext(ctx, markDeliveredQuery, e.id, now, eventID); err != nil {
+		return fmt.Errorf("mark outbox delivered: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT outbox_entry"); err != nil {
+		return fmt.Errorf("outbox release savepoint: %w", err)
+	}
+	return nil
+}
+
+func (r *Relay) deliverRecord(ctx context.Context, tx *sql.Tx, e entry) (int64, error) {
+	deliver := r.deliver[e.kind]
+	if deliver == nil {
+		return 0, fmt.Errorf("no delivery for outbox kind %q", e.kind)
+	}
+	return deliver(ctx, tx, e.record)
+}
+
+func (r *Relay) batchSize() int {
+	if r.cfg.BatchSize <= 0 {
+		return 100
+	}
+	return r.cfg.BatchSize
+}
+
+func backoffDelay(attempt int, base, max time.Duration) time.Duration {
+	if base <= 0 {
+		base = time.Second
+	}
+	if max <= 0 {
+		max = 5 * time.Minute
+	}
+	delay := base
+	for i := 1; i < attempt && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+	return delay
+}
@@ -0,0 +1,91 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBackoffDelay(t *testing.T) {
+	base := time.Second
+	max := 10 * time.Second
+	cases := map[int]time.Duration{
+		1: time.Second,
+		2: 2 * time.Second,
+		3: 4 * time.Second,
+		4: 8 * time.Second,
+		5: max,
+		9: max,
+	}
+	for attempt, want := range cases {
+		if got := backoffDelay(attempt, base, max); got != want {
+			t.Fatalf("backoffDelay(%d)=%s, want %s", attempt, got, want)
+		}
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	valid := Config{Interval: time.Second, BatchSize: 10, RetryBaseDelay: time.Second, RetryMaxDelay: time.Minute}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("Validate() err=%v", err)
+	}
+	broken := map[string]func(*Config){
+		"interval":  func(c *Config) { c.Interval = 0 },
+		"batch":     func(c *Config) { c.BatchSize = 0 },
+		"base":      func(c *Config) { c.RetryBaseDelay = 0 },
+		"max":       func(c *Config) { c.RetryMaxDelay = time.Millisecond },
+		"retention": func(c *Config) { c.Retention = -time.Hour },
+	}
+	for name, mutate := range broken {
+		cfg := valid
+		mutate(&cfg)
+		if err := cfg.Validate(); err == nil {
+			t.Fatalf("%s: expected validation error", name)
+		}
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("ANIMUS_OUTBOX_BATCH_SIZE", "25")
+	t.Setenv("ANIMUS_OUTBOX_RETENTION", "0")
+	cfg, err := ConfigFromEnv()
+	if err != nil {
+		t.Fatalf("ConfigFromEnv() err=%v", err)
+	}
+	if cfg.BatchSize != 25 || cfg.Retention != 0 || cfg.Interval != time.Second {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+}
+
+func TestAppendRejectsInvalidInput(t *testing.T) {
+	if _, err := Append(context.Background(), nil, KindAudit, "k", map[string]any{}); err == nil {
+		t.Fatalf("expected error without queryer")
+	}
+}
+
+func TestRelayQueriesClaimWithSkipLocked(t *testing.T) {
+	if !strings.Contains(claimQuery, "FOR UPDATE SKIP LOCKED") {
+		t.Fatalf("claim query must skip locked rows: %s", claimQuery)
+	}
+	if !strings.Contains(claimQuery, "delivered_at IS NULL") {
+		t.Fatalf("claim query must skip delivered rows: %s", claimQuery)
+	}
+	if !strings.Contains(claimQuery, "kind = ANY($3)") {
+		t.Fatalf("claim query must filter on deliverable kinds: %s", claimQuery)
+	}
+	if !strings.Contains(appendQuery, "ON CONFLICT (kind, dedupe_key) DO NOTHING") {
+		t.Fatalf("append query must dedupe: %s", appendQuery)
+	}
+}
+
+func TestRelayClaimsOnlyDeliverableKinds(t *testing.T) {
+	deliver := func(context.Context, *sql.Tx, json.RawMessage) (int64, error) { return 0, nil }
+	relay := NewRelay(nil, nil, Config{}, map[string]DeliverFunc{KindLineage: deliver, KindAudit: deliver, KindBus: nil})
+	if got := relay.Kinds(); !slices.Equal(got, []string{KindAudit, KindLineage}) {
+		t.Fatalf("Kinds()=%v", got)
+	}
+}
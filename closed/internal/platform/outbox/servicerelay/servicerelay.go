@@ -0,0 +1,71 @@
+// Package servicerelay wires the outbox of a service: Start runs the relay
+// that delivers its audit, lineage and message bus records, and Bus enqueues
+// its bus events. It is separate from outbox because auditlog, lineageevent
+// and eventbus, whose delivery functions it uses, import outbox.
+package servicerelay
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/integrations/eventbus"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
+	"github.com/animus-labs/animus-go/closed/internal/platform/lineageevent"
+	"github.com/animus-labs/animus-go/closed/internal/platform/outbox"
+)
+
+// Deliveries returns the delivery functions of a service relay: audit and
+// lineage always, bus records only when publisher is set.
+func Deliveries(publisher eventbus.Publisher, topicPrefix string) map[string]outbox.DeliverFunc {
+	deliver := map[string]outbox.DeliverFunc{
+		outbox.KindAudit:   auditlog.DeliverOutbox,
+		outbox.KindLineage: lineageevent.DeliverOutbox,
+	}
+	if publisher != nil {
+		deliver[outbox.KindBus] = eventbus.Deliver(publisher, topicPrefix)
+	}
+	return deliver
+}
+
+// Start runs the relay of a service until ctx ends and then closes
+// publisher. Without a publisher the relay leaves bus records to a service
+// that has one.
+func Start(ctx context.Context, logger *slog.Logger, db *sql.DB, cfg outbox.Config, publisher eventbus.Publisher, topicPrefix string) {
+	if db == nil {
+		return
+	}
+	if publisher != nil {
+		go func() {
+			<-ctx.Done()
+			_ = publisher.Close()
+		}()
+	}
+	relay := outbox.NewRelay(db, logger, cfg, Deliveries(publisher, topicPrefix))
+	go relay.Run(ctx)
+	if logger != nil {
+		logger.Info("outbox relay started", "interval", cfg.Interval, "kinds", relay.Kinds())
+	}
+}
+
+// Bus enqueues the message bus events of a service. The zero value enqueues
+// nothing.
+type Bus struct {
+	// Enabled is set when an event bus backend is configured.
+	Enabled bool
+}
+
+// Enqueue stores an event for the message bus in the outbox through q, the
+// transaction of the change; it does nothing unless b is enabled. sourceID
+// is the id of the row recording the change.
+func (b Bus) Enqueue(ctx context.Context, q outbox.QueryRower, eventType eventbus.EventType, sourceID, projectID string, occurredAt time.Time, subject map[string]string, data any) error {
+	if !b.Enabled {
+		return nil
+	}
+	event, err := eventbus.NewEvent(eventType, sourceID, projectID, occurredAt, subject, data)
+	if err != nil {
+		return err
+	}
+	return eventbus.Enqueue(ctx, q, event)
+}
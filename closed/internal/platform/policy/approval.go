@@ -0,0 +1,130 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	EscalationDeny   = "deny"
+	EscalationNotify = "notify"
+)
+
+const maxApprovalStages = 10
+
+// ApprovalWorkflow is the sign-off a require_approval rule asks for. Stages
+// run in order; a run is released once every stage has its approvers, and a
+// single deny in any stage denies the whole approval.
+type ApprovalWorkflow struct {
+	Stages []ApprovalStage `json:"stages" yaml:"stages"`
+}
+
+type ApprovalStage struct {
+	Name         string `json:"name" yaml:"name"`
+	MinApprovers int    `json:"min_approvers,omitempty" yaml:"min_approvers,omitempty"`
+	// Roles and Groups restrict who may vote in the stage; identity groups
+	// are merged into roles at login, so both match against roles. A stage
+	// with neither is open to admins.
+	Roles      []string            `json:"roles,omitempty" yaml:"roles,omitempty"`
+	Groups     []string            `json:"groups,omitempty" yaml:"groups,omitempty"`
+	Escalation *ApprovalEscalation `json:"escalation,omitempty" yaml:"escalation,omitempty"`
+}
+
+// ApprovalEscalation fires when a stage stays open longer than After.
+type ApprovalEscalation struct {
+	After  string `json:"after" yaml:"after"`
+	Action string `json:"action" yaml:"action"`
+}
+
+// DefaultApprovalWorkflow is the single admin review used by rules without
+// an approval block.
+func DefaultApprovalWorkflow() ApprovalWorkflow {
+	return ApprovalWorkflow{Stages: []ApprovalStage{{Name: "review", MinApprovers: 1}}}
+}
+
+func (w ApprovalWorkflow) Validate() error {
+	if len(w.Stages) == 0 {
+		return errors.New("stages must be non-empty")
+	}
+	if len(w.Stages) > maxApprovalStages {
+		return fmt.Errorf("stages must have at most %d entries", maxApprovalStages)
+	}
+	seen := make(map[string]struct{}, len(w.Stages))
+	for i, stage := range w.Stages {
+		name := strings.TrimSpace(stage.Name)
+		if name == "" {
+			return fmt.Errorf("stages[%d].name is required", i)
+		}
+		if _, ok := seen[name]; ok {
+			return fmt.Errorf("stages[%d].name must be unique (duplicate %q)", i, name)
+		}
+		seen[name] = struct{}{}
+		if stage.MinApprovers < 0 || stage.MinApprovers > 10 {
+			return fmt.Errorf("stages[%d].min_approvers must be between 1 and 10", i)
+		}
+		if stage.Escalation == nil {
+			continue
+		}
+		after, err := time.ParseDuration(strings.TrimSpace(stage.Escalation.After))
+		if err != nil || after <= 0 {
+			return fmt.Errorf("stages[%d].escalation.after must be a positive duration", i)
+		}
+		switch strings.ToLower(strings.TrimSpace(stage.Escalation.Action)) {
+		case EscalationDeny, EscalationNotify:
+		default:
+			return fmt.Errorf("stages[%d].escalation.action unsupported: %q", i, stage.Escalation.Action)
+		}
+	}
+	return nil
+}
+
+// Approvers is the number of approvals that completes the stage.
+func (s ApprovalStage) Approvers() int {
+	if s.MinApprovers <= 0 {
+		return 1
+	}
+	return s.MinApprovers
+}
+
+// Restricted reports whether the stage names roles or groups.
+func (s ApprovalStage) Restricted() bool {
+	return len(trimNonEmpty(s.Roles)) > 0 || len(trimNonEmpty(s.Groups)) > 0
+}
+
+// Allows reports whether an identity with roles may vote in a restricted
+// stage. Callers check the admin role for unrestricted stages.
+func (s ApprovalStage) Allows(roles []string) bool {
+	allowed := append(trimNonEmpty(s.Roles), trimNonEmpty(s.Groups)...)
+	for _, role := range roles {
+		role = strings.TrimSpace(role)
+		for _, want := range allowed {
+			if strings.EqualFold(role, want) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// EscalatesAt returns when a stage opened at startedAt escalates, and false
+// when the stage has no escalation.
+func (s ApprovalStage) EscalatesAt(startedAt time.Time) (time.Time, bool) {
+	if s.Escalation == nil {
+		return time.Time{}, false
+	}
+	after, err := time.ParseDuration(strings.TrimSpace(s.Escalation.After))
+	if err != nil || after <= 0 {
+		return time.Time{}, false
+	}
+	return startedAt.Add(after), true
+}
+
+// EscalationAction is deny or notify; empty when the stage has no escalation.
+func (s ApprovalStage) EscalationAction() string {
+	if s.Escalation == nil {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSpace(s.Escalation.Action))
+}
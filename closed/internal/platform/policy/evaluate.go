@@ -1,6 +1,7 @@
 package policy
 
 import (
+	"errors"
 	"fmt"
 	"regexp"
 	"strconv"
@@ -13,6 +14,8 @@ type Context struct {
 	Experiment ExperimentContext      `json:"experiment"`
 	Git        GitContext             `json:"git"`
 	Image      ImageContext           `json:"image"`
+	CI         CIContext              `json:"ci"`
+	Deployment DeploymentContext      `json:"deployment"`
 	Resources  map[string]any         `json:"resources,omitempty"`
 	Labels     map[string]string      `json:"labels,omitempty"`
 	Meta       map[string]interface{} `json:"meta,omitempty"`
@@ -28,6 +31,21 @@ type DatasetContext struct {
 	DatasetID string `json:"dataset_id,omitempty"`
 	VersionID string `json:"version_id,omitempty"`
 	SHA256    string `json:"sha256,omitempty"`
+	// AgeDays is the age of the dataset version; Stale is set only when the
+	// dataset has a freshness expectation.
+	AgeDays *float64 `json:"age_days,omitempty"`
+	Stale   *bool    `json:"stale,omitempty"`
+	// DriftScore is the score of the version's latest drift report; nil when
+	// no report exists.
+	DriftScore *float64 `json:"drift_score,omitempty"`
+	// Data protection posture; Encrypted and Recalled are nil when the caller
+	// did not load it.
+	Encrypted       *bool    `json:"encrypted,omitempty"`
+	EncryptionKeyID string   `json:"encryption_key_id,omitempty"`
+	Residency       string   `json:"residency,omitempty"`
+	Classification  []string `json:"classification,omitempty"`
+	Recalled        *bool    `json:"recalled,omitempty"`
+	LifecycleState  string   `json:"lifecycle_state,omitempty"`
 }
 
 type ExperimentContext struct {
@@ -35,15 +53,76 @@ type ExperimentContext struct {
 	RunID        string `json:"run_id,omitempty"`
 }
 
+// DeploymentContext describes a model version being registered as deployed
+// to a serving endpoint; it is empty outside deployment registration.
+type DeploymentContext struct {
+	Environment    string `json:"environment,omitempty"`
+	EndpointURL    string `json:"endpoint_url,omitempty"`
+	Replicas       *int   `json:"replicas,omitempty"`
+	ModelID        string `json:"model_id,omitempty"`
+	ModelVersionID string `json:"model_version_id,omitempty"`
+	ModelStatus    string `json:"model_status,omitempty"`
+}
+
 type GitContext struct {
 	Repo   string `json:"repo,omitempty"`
 	Commit string `json:"commit,omitempty"`
 	Ref    string `json:"ref,omitempty"`
 }
 
+// CIContext carries the latest CI report for the commit or image being run.
+// Status is empty when the caller did not load CI results and "missing" when
+// no report was ingested.
+type CIContext struct {
+	ReportID string `json:"report_id,omitempty"`
+	Status   string `json:"status,omitempty"`
+	// Checks maps check names to their status; Kinds aggregates them per kind
+	// (test, coverage, security_scan, ...).
+	Checks map[string]string `json:"checks,omitempty"`
+	Kinds  map[string]string `json:"kinds,omitempty"`
+}
+
 type ImageContext struct {
 	Ref    string `json:"ref,omitempty"`
 	Digest string `json:"digest,omitempty"`
+	// Supply-chain verification results; nil when the caller did not verify
+	// the image.
+	Signed            *bool  `json:"signed,omitempty"`
+	Verified          *bool  `json:"verified,omitempty"`
+	SBOM              *bool  `json:"sbom,omitempty"`
+	SBOMPredicateType string `json:"sbom_predicate_type,omitempty"`
+	// Scanned is nil when the caller did not load vulnerability scans and
+	// false when the digest was never scanned; Vulnerabilities holds the
+	// severity counts of the latest scan.
+	Scanned         *bool                `json:"scanned,omitempty"`
+	Vulnerabilities *VulnerabilityCounts `json:"vulnerabilities,omitempty"`
+}
+
+type VulnerabilityCounts struct {
+	Critical int `json:"critical"`
+	High     int `json:"high"`
+	Medium   int `json:"medium"`
+	Low      int `json:"low"`
+	Unknown  int `json:"unknown"`
+}
+
+func (v *VulnerabilityCounts) count(severity string) (any, bool) {
+	if v == nil {
+		return nil, false
+	}
+	switch severity {
+	case "critical":
+		return v.Critical, true
+	case "high":
+		return v.High, true
+	case "medium":
+		return v.Medium, true
+	case "low":
+		return v.Low, true
+	case "unknown":
+		return v.Unknown, true
+	}
+	return nil, false
 }
 
 type Decision struct {
@@ -51,12 +130,25 @@ type Decision struct {
 	RuleID      string `json:"rule_id,omitempty"`
 	Description string `json:"description,omitempty"`
 	Reason      string `json:"reason,omitempty"`
+	// BundleRevision is the Rego bundle revision reported by OPA; empty for
+	// built-in rules.
+	BundleRevision string `json:"bundle_revision,omitempty"`
+	// Approval is the workflow of a matched require_approval rule; nil means
+	// the default single review.
+	Approval *ApprovalWorkflow `json:"approval,omitempty"`
 }
 
+// ErrOPASpec is returned by Evaluate for specs that only an OPA sidecar can
+// decide; use Evaluator instead.
+var ErrOPASpec = errors.New("policy spec requires the OPA backend")
+
 func Evaluate(spec Spec, ctx Context) (Decision, error) {
 	if err := spec.Validate(); err != nil {
 		return Decision{}, err
 	}
+	if spec.IsOPA() {
+		return Decision{}, ErrOPASpec
+	}
 	for _, rule := range spec.Rules {
 		if ruleMatches(rule, ctx) {
 			return Decision{
@@ -64,6 +156,7 @@ func Evaluate(spec Spec, ctx Context) (Decision, error) {
 				RuleID:      strings.TrimSpace(rule.ID),
 				Description: strings.TrimSpace(rule.Description),
 				Reason:      "rule_match",
+				Approval:    rule.Approval,
 			}, nil
 		}
 	}
@@ -156,6 +249,39 @@ func (c Context) Field(name string) (any, bool) {
 		return c.Dataset.VersionID, strings.TrimSpace(c.Dataset.VersionID) != ""
 	case "dataset.sha256", "dataset.content_sha256":
 		return c.Dataset.SHA256, strings.TrimSpace(c.Dataset.SHA256) != ""
+	case "dataset.age_days":
+		if c.Dataset.AgeDays == nil {
+			return nil, false
+		}
+		return *c.Dataset.AgeDays, true
+	case "dataset.stale":
+		if c.Dataset.Stale == nil {
+			return nil, false
+		}
+		return *c.Dataset.Stale, true
+	case "dataset.drift_score":
+		if c.Dataset.DriftScore == nil {
+			return nil, false
+		}
+		return *c.Dataset.DriftScore, true
+	case "dataset.encrypted":
+		if c.Dataset.Encrypted == nil {
+			return nil, false
+		}
+		return *c.Dataset.Encrypted, true
+	case "dataset.encryption_key_id":
+		return c.Dataset.EncryptionKeyID, strings.TrimSpace(c.Dataset.EncryptionKeyID) != ""
+	case "dataset.residency":
+		return c.Dataset.Residency, strings.TrimSpace(c.Dataset.Residency) != ""
+	case "dataset.classification", "dataset.classification_labels":
+		return c.Dataset.Classification, len(c.Dataset.Classification) > 0
+	case "dataset.recalled":
+		if c.Dataset.Recalled == nil {
+			return nil, false
+		}
+		return *c.Dataset.Recalled, true
+	case "dataset.lifecycle_state":
+		return c.Dataset.LifecycleState, strings.TrimSpace(c.Dataset.LifecycleState) != ""
 	case "experiment.id", "experiment.experiment_id", "experiment_id":
 		return c.Experiment.ExperimentID, strings.TrimSpace(c.Experiment.ExperimentID) != ""
 	case "experiment.run_id", "run_id":
@@ -170,6 +296,58 @@ func (c Context) Field(name string) (any, bool) {
 		return c.Image.Ref, strings.TrimSpace(c.Image.Ref) != ""
 	case "image.digest", "image.sha256":
 		return c.Image.Digest, strings.TrimSpace(c.Image.Digest) != ""
+	case "image.signed":
+		if c.Image.Signed == nil {
+			return nil, false
+		}
+		return *c.Image.Signed, true
+	case "image.verified":
+		if c.Image.Verified == nil {
+			return nil, false
+		}
+		return *c.Image.Verified, true
+	case "image.sbom":
+		if c.Image.SBOM == nil {
+			return nil, false
+		}
+		return *c.Image.SBOM, true
+	case "image.sbom_predicate_type":
+		return c.Image.SBOMPredicateType, strings.TrimSpace(c.Image.SBOMPredicateType) != ""
+	case "image.scanned":
+		if c.Image.Scanned == nil {
+			return nil, false
+		}
+		return *c.Image.Scanned, true
+	case "image.vulnerabilities.critical", "image.vulnerabilities.high", "image.vulnerabilities.medium",
+		"image.vulnerabilities.low", "image.vulnerabilities.unknown":
+		return c.Image.Vulnerabilities.count(strings.TrimPrefix(key, "image.vulnerabilities."))
+	case "ci.status":
+		return c.CI.Status, strings.TrimSpace(c.CI.Status) != ""
+	case "ci.report_id":
+		return c.CI.ReportID, strings.TrimSpace(c.CI.ReportID) != ""
+	case "deployment.environment":
+		return c.Deployment.Environment, strings.TrimSpace(c.Deployment.Environment) != ""
+	case "deployment.endpoint_url":
+		return c.Deployment.EndpointURL, strings.TrimSpace(c.Deployment.EndpointURL) != ""
+	case "deployment.replicas":
+		if c.Deployment.Replicas == nil {
+			return nil, false
+		}
+		return *c.Deployment.Replicas, true
+	case "deployment.model_id", "model.id":
+		return c.Deployment.ModelID, strings.TrimSpace(c.Deployment.ModelID) != ""
+	case "deployment.model_version_id", "model.version_id":
+		return c.Deployment.ModelVersionID, strings.TrimSpace(c.Deployment.ModelVersionID) != ""
+	case "deployment.model_status", "model.status":
+		return c.Deployment.ModelStatus, strings.TrimSpace(c.Deployment.ModelStatus) != ""
+	}
+	if strings.HasPrefix(key, "ci.checks.") {
+		value, ok := resolveStringMapPath(c.CI.Checks, strings.TrimPrefix(key, "ci.checks."))
+		return value, ok
+	}
+	if strings.HasPrefix(key, "ci.") {
+		value, ok := resolveStringMapPath(c.CI.Kinds, strings.TrimPrefix(key, "ci."))
+		return value, ok
 	}
 	if strings.HasPrefix(key, "resources.") {
 		value, ok := resolveMapPath(c.Resources, strings.TrimPrefix(key, "resources."))
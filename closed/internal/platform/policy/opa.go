@@ -0,0 +1,214 @@
+package policy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/env"
+)
+
+// OPAConfig points at an OPA sidecar. An empty URL disables the backend and
+// specs with schema animus.policy.opa.v1 are rejected.
+type OPAConfig struct {
+	URL     string
+	Timeout time.Duration
+}
+
+func OPAConfigFromEnv() (OPAConfig, error) {
+	timeout, err := env.Duration("ANIMUS_POLICY_OPA_TIMEOUT", 2*time.Second)
+	if err != nil {
+		return OPAConfig{}, err
+	}
+	cfg := OPAConfig{
+		URL:     strings.TrimRight(strings.TrimSpace(env.String("ANIMUS_POLICY_OPA_URL", "")), "/"),
+		Timeout: timeout,
+	}
+	return cfg, cfg.Validate()
+}
+
+func (c OPAConfig) Validate() error {
+	if c.URL == "" {
+		return nil
+	}
+	if !strings.HasPrefix(c.URL, "http://") && !strings.HasPrefix(c.URL, "https://") {
+		return fmt.Errorf("ANIMUS_POLICY_OPA_URL must be an http(s) URL")
+	}
+	if c.Timeout <= 0 {
+		return errors.New("ANIMUS_POLICY_OPA_TIMEOUT must be positive")
+	}
+	return nil
+}
+
+// OPAClient queries the OPA data API. The policy.Context is sent as input
+// unchanged, so Rego rules see the same field names as built-in conditions
+// (input.dataset.residency, input.actor.roles, ...).
+type OPAClient struct {
+	url  string
+	http *http.Client
+}
+
+// NewOPAClient returns nil when cfg has no URL.
+func NewOPAClient(cfg OPAConfig) *OPAClient {
+	url := strings.TrimRight(strings.TrimSpace(cfg.URL), "/")
+	if url == "" {
+		return nil
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 2 * time.Second
+	}
+	return &OPAClient{url: url, http: &http.Client{Timeout: timeout}}
+}
+
+type opaResponse struct {
+	Result     *json.RawMessage `json:"result"`
+	Provenance struct {
+		Revision string `json:"revision"`
+		Bundles  map[string]struct {
+			Revision string `json:"revision"`
+		} `json:"bundles"`
+	} `json:"provenance"`
+}
+
+// Decide evaluates the decision document at path. The result may be a
+// boolean (allow/deny), an effect string, or an object with effect, rule_id,
+// description, reason and approval (a workflow for require_approval). An
+// undefined result falls back to defaultEffect.
+func (c *OPAClient) Decide(ctx context.Context, path string, input Context, defaultEffect string) (Decision, error) {
+	if c == nil {
+		return Decision{}, errors.New("opa backend is not configured")
+	}
+	payload, err := json.Marshal(map[string]any{"input": input})
+	if err != nil {
+		return Decision{}, err
+	}
+	endpoint := fmt.Sprintf("%s/v1/data/%s?provenance=true", c.url, strings.Trim(strings.TrimSpace(path), "/"))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return Decision{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return Decision{}, fmt.Errorf("opa query %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
+		return Decision{}, fmt.Errorf("opa query %s failed: status %d: %s", path, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	var out opaResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&out); err != nil {
+		return Decision{}, fmt.Errorf("opa query %s: decode response: %w", path, err)
+	}
+
+	decision, err := opaDecision(out.Result, defaultEffect)
+	if err != nil {
+		return Decision{}, fmt.Errorf("opa query %s: %w", path, err)
+	}
+	decision.BundleRevision = out.bundleRevision()
+	return decision, nil
+}
+
+func opaDecision(result *json.RawMessage, defaultEffect string) (Decision, error) {
+	if result == nil || string(*result) == "null" {
+		effect := normalizeEffect(defaultEffect)
+		if effect == "" {
+			effect = EffectDeny
+		}
+		return Decision{Effect: effect, Reason: "default"}, nil
+	}
+
+	var (
+		allowed bool
+		effect  string
+		object  struct {
+			Effect      string            `json:"effect"`
+			RuleID      string            `json:"rule_id"`
+			Description string            `json:"description"`
+			Reason      string            `json:"reason"`
+			Approval    *ApprovalWorkflow `json:"approval"`
+		}
+	)
+	decision := Decision{Reason: "rule_match"}
+	switch {
+	case json.Unmarshal(*result, &allowed) == nil:
+		decision.Effect = EffectDeny
+		if allowed {
+			decision.Effect = EffectAllow
+		}
+	case json.Unmarshal(*result, &effect) == nil:
+		decision.Effect = effect
+	case json.Unmarshal(*result, &object) == nil:
+		decision.Effect = object.Effect
+		decision.RuleID = strings.TrimSpace(object.RuleID)
+		decision.Description = strings.TrimSpace(object.Description)
+		if reason := strings.TrimSpace(object.Reason); reason != "" {
+			decision.Reason = reason
+		}
+		decision.Approval = object.Approval
+	default:
+		return Decision{}, errors.New("unsupported result type")
+	}
+
+	if !isEffectAllowed(decision.Effect) {
+		return Decision{}, fmt.Errorf("unsupported effect %q", decision.Effect)
+	}
+	decision.Effect = normalizeEffect(decision.Effect)
+	if decision.Approval != nil {
+		if decision.Effect != EffectRequireApproval {
+			decision.Approval = nil
+		} else if err := decision.Approval.Validate(); err != nil {
+			return Decision{}, fmt.Errorf("approval.%w", err)
+		}
+	}
+	return decision, nil
+}
+
+// bundleRevision identifies the Rego that produced the decision: the single
+// bundle's revision, or "name=revision" pairs when several are loaded.
+func (r opaResponse) bundleRevision() string {
+	bundles := r.Provenance.Bundles
+	switch len(bundles) {
+	case 0:
+		return strings.TrimSpace(r.Provenance.Revision)
+	case 1:
+		for _, bundle := range bundles {
+			return strings.TrimSpace(bundle.Revision)
+		}
+	}
+	names := make([]string, 0, len(bundles))
+	for name := range bundles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, name+"="+strings.TrimSpace(bundles[name].Revision))
+	}
+	return strings.Join(parts, ",")
+}
+
+// Evaluator dispatches specs to the built-in engine or, for OPA specs, to
+// the sidecar. A nil OPA client fails OPA specs closed with an error.
+type Evaluator struct {
+	OPA *OPAClient
+}
+
+func (e Evaluator) Evaluate(ctx context.Context, spec Spec, input Context) (Decision, error) {
+	if !spec.IsOPA() {
+		return Evaluate(spec, input)
+	}
+	if err := spec.Validate(); err != nil {
+		return Decision{}, err
+	}
+	return e.OPA.Decide(ctx, spec.OPA.Path, input, spec.DefaultEffect)
+}
@@ -0,0 +1,104 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSpecValidateOPA(t *testing.T) {
+	spec := Spec{Schema: SpecSchemaOPAV1, OPA: &OPASource{Path: "animus/runs/decision"}}
+	if err := spec.Validate(); err != nil {
+		t.Fatalf("Validate() err=%v", err)
+	}
+
+	for name, invalid := range map[string]Spec{
+		"missing path": {Schema: SpecSchemaOPAV1},
+		"bad path":     {Schema: SpecSchemaOPAV1, OPA: &OPASource{Path: "animus/../x"}},
+		"with rules":   {Schema: SpecSchemaOPAV1, OPA: spec.OPA, Rules: []Rule{{ID: "r"}}},
+		"opa on v1":    {Schema: SpecSchemaV1, OPA: spec.OPA},
+	} {
+		if err := invalid.Validate(); err == nil {
+			t.Fatalf("%s: expected validation error", name)
+		}
+	}
+
+	if _, err := Evaluate(spec, Context{}); !errors.Is(err, ErrOPASpec) {
+		t.Fatalf("Evaluate() err=%v, want ErrOPASpec", err)
+	}
+}
+
+func TestOPAClientDecide(t *testing.T) {
+	var (
+		gotPath  string
+		gotInput Context
+	)
+	responses := map[string]string{
+		"bool":      `{"result": true, "provenance": {"bundles": {"animus": {"revision": "sha256:abc"}}}}`,
+		"string":    `{"result": "require_approval", "provenance": {"revision": "r1"}}`,
+		"object":    `{"result": {"effect": "DENY", "rule_id": "residency", "description": "EU only"}, "provenance": {"bundles": {"b": {"revision": "2"}, "a": {"revision": "1"}}}}`,
+		"undefined": `{"provenance": {}}`,
+		"bad":       `{"result": "maybe"}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		if r.URL.Query().Get("provenance") != "true" {
+			t.Errorf("provenance not requested")
+		}
+		var body struct {
+			Input Context `json:"input"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode input: %v", err)
+		}
+		gotInput = body.Input
+		_, _ = w.Write([]byte(responses[body.Input.Labels["case"]]))
+	}))
+	defer server.Close()
+
+	client := NewOPAClient(OPAConfig{URL: server.URL + "/"})
+	decide := func(name string) (Decision, error) {
+		input := Context{Actor: ActorContext{Subject: "alice"}, Labels: map[string]string{"case": name}}
+		return client.Decide(context.Background(), "animus/runs/decision", input, "")
+	}
+
+	decision, err := decide("bool")
+	if err != nil || decision.Effect != EffectAllow || decision.Reason != "rule_match" || decision.BundleRevision != "sha256:abc" {
+		t.Fatalf("bool: decision=%+v err=%v", decision, err)
+	}
+	if gotPath != "/v1/data/animus/runs/decision" || gotInput.Actor.Subject != "alice" {
+		t.Fatalf("unexpected request path=%q input=%+v", gotPath, gotInput)
+	}
+
+	decision, err = decide("string")
+	if err != nil || decision.Effect != EffectRequireApproval || decision.BundleRevision != "r1" {
+		t.Fatalf("string: decision=%+v err=%v", decision, err)
+	}
+
+	decision, err = decide("object")
+	if err != nil || decision.Effect != EffectDeny || decision.RuleID != "residency" || decision.BundleRevision != "a=1,b=2" {
+		t.Fatalf("object: decision=%+v err=%v", decision, err)
+	}
+
+	decision, err = decide("undefined")
+	if err != nil || decision.Effect != EffectDeny || decision.Reason != "default" {
+		t.Fatalf("undefined: decision=%+v err=%v", decision, err)
+	}
+
+	if _, err := decide("bad"); err == nil {
+		t.Fatalf("expected error for unsupported effect")
+	}
+}
+
+func TestEvaluatorWithoutOPA(t *testing.T) {
+	spec := Spec{Schema: SpecSchemaOPAV1, OPA: &OPASource{Path: "animus/decision"}}
+	if _, err := (Evaluator{}).Evaluate(context.Background(), spec, Context{}); err == nil {
+		t.Fatalf("expected error when OPA is not configured")
+	}
+	if NewOPAClient(OPAConfig{}) != nil {
+		t.Fatalf("expected nil client for empty URL")
+	}
+}
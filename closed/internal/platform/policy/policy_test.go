@@ -1,6 +1,9 @@
 package policy
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 func TestSpecValidate(t *testing.T) {
 	spec := Spec{
@@ -26,6 +29,21 @@ func TestSpecValidate(t *testing.T) {
 	if err := invalid.Validate(); err == nil {
 		t.Fatalf("expected schema error")
 	}
+
+	limited := spec
+	limited.MaxRunDuration = "6h"
+	if err := limited.Validate(); err != nil || limited.RunDurationLimit() != 6*time.Hour {
+		t.Fatalf("expected 6h run limit, got %v err=%v", limited.RunDurationLimit(), err)
+	}
+	for _, raw := range []string{"soon", "500ms", "-1h"} {
+		limited.MaxRunDuration = raw
+		if err := limited.Validate(); err == nil {
+			t.Fatalf("expected max_run_duration %q to be rejected", raw)
+		}
+	}
+	if spec.RunDurationLimit() != 0 {
+		t.Fatalf("expected no run limit by default")
+	}
 }
 
 func TestEvaluateRuleOrder(t *testing.T) {
@@ -102,3 +120,324 @@ func TestEvaluateDefaultEffect(t *testing.T) {
 		t.Fatalf("RuleID=%s, want empty", decision.RuleID)
 	}
 }
+
+func TestEvaluateDatasetAge(t *testing.T) {
+	spec := Spec{
+		Schema:        SpecSchemaV1,
+		DefaultEffect: EffectAllow,
+		Rules: []Rule{
+			{
+				ID:     "deny-old-datasets",
+				Effect: EffectDeny,
+				When: ConditionGroup{
+					Any: []Condition{
+						{Field: "dataset.age_days", Op: "gt", Value: "30"},
+						{Field: "dataset.stale", Op: "eq", Value: "true"},
+						{Field: "dataset.drift_score", Op: "gte", Value: "0.2"},
+					},
+				},
+			},
+		},
+	}
+
+	age := func(days float64) *float64 { return &days }
+	stale := func(v bool) *bool { return &v }
+	cases := []struct {
+		name    string
+		dataset DatasetContext
+		want    string
+	}{
+		{name: "fresh", dataset: DatasetContext{AgeDays: age(3), Stale: stale(false)}, want: EffectAllow},
+		{name: "old", dataset: DatasetContext{AgeDays: age(45)}, want: EffectDeny},
+		{name: "stale", dataset: DatasetContext{AgeDays: age(2), Stale: stale(true)}, want: EffectDeny},
+		{name: "unknown age", dataset: DatasetContext{}, want: EffectAllow},
+		{name: "stable", dataset: DatasetContext{AgeDays: age(1), DriftScore: age(0.05)}, want: EffectAllow},
+		{name: "drifted", dataset: DatasetContext{AgeDays: age(1), DriftScore: age(0.31)}, want: EffectDeny},
+	}
+	for _, tc := range cases {
+		decision, err := Evaluate(spec, Context{Dataset: tc.dataset})
+		if err != nil {
+			t.Fatalf("%s: Evaluate() err=%v", tc.name, err)
+		}
+		if decision.Effect != tc.want {
+			t.Fatalf("%s: Effect=%s, want %s", tc.name, decision.Effect, tc.want)
+		}
+	}
+}
+
+func TestEvaluateDataProtection(t *testing.T) {
+	spec := Spec{
+		Schema:        SpecSchemaV1,
+		DefaultEffect: EffectAllow,
+		Rules: []Rule{
+			{
+				ID:     "deny-unprotected-pii",
+				Effect: EffectDeny,
+				When: ConditionGroup{
+					All: []Condition{
+						{Field: "dataset.classification", Op: "contains", Value: "pii"},
+						{Field: "dataset.encrypted", Op: "eq", Value: "false"},
+					},
+				},
+			},
+			{
+				ID:     "deny-outside-eu",
+				Effect: EffectDeny,
+				When: ConditionGroup{
+					All: []Condition{{Field: "dataset.residency", Op: "not_in", Values: []string{"eu"}}},
+				},
+			},
+			{
+				ID:     "deny-recalled",
+				Effect: EffectDeny,
+				When: ConditionGroup{
+					All: []Condition{{Field: "dataset.recalled", Op: "eq", Value: "true"}},
+				},
+			},
+		},
+	}
+
+	flag := func(v bool) *bool { return &v }
+	cases := []struct {
+		name    string
+		dataset DatasetContext
+		want    string
+	}{
+		{name: "encrypted pii", dataset: DatasetContext{Encrypted: flag(true), Residency: "eu", Classification: []string{"pii"}, Recalled: flag(false)}, want: ""},
+		{name: "plaintext pii", dataset: DatasetContext{Encrypted: flag(false), Residency: "eu", Classification: []string{"internal", "PII"}}, want: "deny-unprotected-pii"},
+		{name: "wrong residency", dataset: DatasetContext{Encrypted: flag(true), Residency: "us"}, want: "deny-outside-eu"},
+		{name: "recalled", dataset: DatasetContext{Encrypted: flag(true), Residency: "eu", Recalled: flag(true)}, want: "deny-recalled"},
+		{name: "posture not loaded", dataset: DatasetContext{Classification: []string{"pii"}}, want: ""},
+	}
+	for _, tc := range cases {
+		decision, err := Evaluate(spec, Context{Dataset: tc.dataset})
+		if err != nil {
+			t.Fatalf("%s: Evaluate() err=%v", tc.name, err)
+		}
+		if decision.RuleID != tc.want {
+			t.Fatalf("%s: RuleID=%q, want %q", tc.name, decision.RuleID, tc.want)
+		}
+	}
+}
+
+func TestEvaluateImageSupplyChain(t *testing.T) {
+	spec := Spec{
+		Schema:        SpecSchemaV1,
+		DefaultEffect: EffectAllow,
+		Rules: []Rule{
+			{
+				ID:     "deny-unsigned-images",
+				Effect: EffectDeny,
+				When: ConditionGroup{
+					Any: []Condition{
+						{Field: "image.signed", Op: "eq", Value: "false"},
+						{Field: "image.sbom", Op: "eq", Value: "false"},
+					},
+				},
+			},
+		},
+	}
+
+	flag := func(v bool) *bool { return &v }
+	cases := []struct {
+		name  string
+		image ImageContext
+		want  string
+	}{
+		{name: "signed with sbom", image: ImageContext{Signed: flag(true), Verified: flag(true), SBOM: flag(true)}, want: ""},
+		{name: "unsigned", image: ImageContext{Signed: flag(false), Verified: flag(false), SBOM: flag(true)}, want: "deny-unsigned-images"},
+		{name: "no sbom", image: ImageContext{Signed: flag(true), Verified: flag(true), SBOM: flag(false)}, want: "deny-unsigned-images"},
+		{name: "not verified", image: ImageContext{Ref: "ghcr.io/acme/train"}, want: ""},
+	}
+	for _, tc := range cases {
+		decision, err := Evaluate(spec, Context{Image: tc.image})
+		if err != nil {
+			t.Fatalf("%s: Evaluate() err=%v", tc.name, err)
+		}
+		if decision.RuleID != tc.want {
+			t.Fatalf("%s: RuleID=%q, want %q", tc.name, decision.RuleID, tc.want)
+		}
+	}
+}
+
+func TestSpecValidateApproval(t *testing.T) {
+	rule := Rule{
+		ID:     "gpu",
+		Effect: EffectRequireApproval,
+		When:   ConditionGroup{All: []Condition{{Field: "resources.gpu", Op: "gt", Value: "0"}}},
+		Approval: &ApprovalWorkflow{Stages: []ApprovalStage{
+			{Name: "security", Groups: []string{"security"}, Escalation: &ApprovalEscalation{After: "24h", Action: EscalationNotify}},
+			{Name: "data-owner", MinApprovers: 2, Roles: []string{"data-owner"}, Escalation: &ApprovalEscalation{After: "72h", Action: EscalationDeny}},
+		}},
+	}
+	spec := Spec{Schema: SpecSchemaV1, Rules: []Rule{rule}}
+	if err := spec.Validate(); err != nil {
+		t.Fatalf("Validate() err=%v", err)
+	}
+	decision, err := Evaluate(spec, Context{Resources: map[string]any{"gpu": 1}})
+	if err != nil || decision.Approval == nil || len(decision.Approval.Stages) != 2 {
+		t.Fatalf("expected workflow on decision, got %+v err=%v", decision, err)
+	}
+
+	for name, mutate := range map[string]func(*Rule){
+		"allow effect":    func(r *Rule) { r.Effect = EffectAllow },
+		"no stages":       func(r *Rule) { r.Approval = &ApprovalWorkflow{} },
+		"duplicate stage": func(r *Rule) { r.Approval.Stages[1].Name = "security" },
+		"bad duration":    func(r *Rule) { r.Approval.Stages[0].Escalation.After = "soon" },
+		"bad action":      func(r *Rule) { r.Approval.Stages[0].Escalation.Action = "page" },
+		"too many":        func(r *Rule) { r.Approval.Stages[1].MinApprovers = 11 },
+	} {
+		invalid := rule
+		workflow := ApprovalWorkflow{Stages: make([]ApprovalStage, len(rule.Approval.Stages))}
+		for i, stage := range rule.Approval.Stages {
+			escalation := *stage.Escalation
+			stage.Escalation = &escalation
+			workflow.Stages[i] = stage
+		}
+		invalid.Approval = &workflow
+		mutate(&invalid)
+		if err := (Spec{Schema: SpecSchemaV1, Rules: []Rule{invalid}}).Validate(); err == nil {
+			t.Fatalf("%s: expected validation error", name)
+		}
+	}
+}
+
+func TestApprovalStage(t *testing.T) {
+	stage := ApprovalStage{Name: "security", Groups: []string{"Security"}, Escalation: &ApprovalEscalation{After: "2h", Action: "Notify"}}
+	if !stage.Restricted() || !stage.Allows([]string{"editor", "security"}) || stage.Allows([]string{"admin"}) {
+		t.Fatalf("unexpected stage membership")
+	}
+	if stage.Approvers() != 1 || stage.EscalationAction() != EscalationNotify {
+		t.Fatalf("unexpected defaults: %d %q", stage.Approvers(), stage.EscalationAction())
+	}
+	start := time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)
+	if at, ok := stage.EscalatesAt(start); !ok || !at.Equal(start.Add(2*time.Hour)) {
+		t.Fatalf("unexpected escalation time %v %v", at, ok)
+	}
+	if _, ok := (ApprovalStage{Name: "review"}).EscalatesAt(start); ok {
+		t.Fatalf("expected no escalation")
+	}
+}
+
+func TestEvaluateCIChecks(t *testing.T) {
+	spec := Spec{
+		Schema:        SpecSchemaV1,
+		DefaultEffect: EffectAllow,
+		Rules: []Rule{
+			{
+				ID:     "require-security-scan",
+				Effect: EffectDeny,
+				When: ConditionGroup{
+					All: []Condition{{Field: "ci.security_scan", Op: "neq", Value: "passed"}},
+				},
+			},
+			{
+				ID:     "require-unit-tests",
+				Effect: EffectDeny,
+				When: ConditionGroup{
+					All: []Condition{{Field: "ci.checks.unit-tests", Op: "eq", Value: "failed"}},
+				},
+			},
+		},
+	}
+
+	cases := []struct {
+		name string
+		ci   CIContext
+		want string
+	}{
+		{name: "scan passed", ci: CIContext{Status: "passed", Checks: map[string]string{"unit-tests": "passed"}, Kinds: map[string]string{"security_scan": "passed"}}, want: ""},
+		{name: "scan failed", ci: CIContext{Status: "failed", Kinds: map[string]string{"security_scan": "failed"}}, want: "require-security-scan"},
+		{name: "no report", ci: CIContext{Status: "missing", Kinds: map[string]string{"security_scan": "missing"}}, want: "require-security-scan"},
+		{name: "tests failed", ci: CIContext{Status: "failed", Checks: map[string]string{"unit-tests": "failed"}, Kinds: map[string]string{"security_scan": "passed"}}, want: "require-unit-tests"},
+		{name: "not loaded", ci: CIContext{}, want: ""},
+	}
+	for _, tc := range cases {
+		decision, err := Evaluate(spec, Context{CI: tc.ci})
+		if err != nil {
+			t.Fatalf("%s: Evaluate() err=%v", tc.name, err)
+		}
+		if decision.RuleID != tc.want {
+			t.Fatalf("%s: RuleID=%q, want %q", tc.name, decision.RuleID, tc.want)
+		}
+	}
+}
+
+func TestEvaluateImageVulnerabilities(t *testing.T) {
+	spec := Spec{
+		Schema:        SpecSchemaV1,
+		DefaultEffect: EffectAllow,
+		Rules: []Rule{
+			{
+				ID:     "deny-critical-cves",
+				Effect: EffectDeny,
+				When: ConditionGroup{
+					Any: []Condition{
+						{Field: "image.vulnerabilities.critical", Op: "gt", Value: "0"},
+						{Field: "image.scanned", Op: "eq", Value: "false"},
+					},
+				},
+			},
+		},
+	}
+
+	flag := func(v bool) *bool { return &v }
+	cases := []struct {
+		name  string
+		image ImageContext
+		want  string
+	}{
+		{name: "clean", image: ImageContext{Scanned: flag(true), Vulnerabilities: &VulnerabilityCounts{High: 4}}, want: ""},
+		{name: "critical", image: ImageContext{Scanned: flag(true), Vulnerabilities: &VulnerabilityCounts{Critical: 1}}, want: "deny-critical-cves"},
+		{name: "never scanned", image: ImageContext{Scanned: flag(false)}, want: "deny-critical-cves"},
+		{name: "not loaded", image: ImageContext{}, want: ""},
+	}
+	for _, tc := range cases {
+		decision, err := Evaluate(spec, Context{Image: tc.image})
+		if err != nil {
+			t.Fatalf("%s: Evaluate() err=%v", tc.name, err)
+		}
+		if decision.RuleID != tc.want {
+			t.Fatalf("%s: RuleID=%q, want %q", tc.name, decision.RuleID, tc.want)
+		}
+	}
+}
+
+func TestEvaluateDeployment(t *testing.T) {
+	spec := Spec{
+		Schema:        SpecSchemaV1,
+		DefaultEffect: EffectAllow,
+		Rules: []Rule{
+			{
+				ID:     "production-needs-approval",
+				Effect: EffectDeny,
+				When: ConditionGroup{
+					All: []Condition{
+						{Field: "deployment.environment", Op: "eq", Value: "production"},
+						{Field: "deployment.model_status", Op: "neq", Value: "approved"},
+					},
+				},
+			},
+		},
+	}
+
+	cases := []struct {
+		name       string
+		deployment DeploymentContext
+		want       string
+	}{
+		{name: "approved", deployment: DeploymentContext{Environment: "production", ModelStatus: "approved"}, want: ""},
+		{name: "validated", deployment: DeploymentContext{Environment: "production", ModelStatus: "validated"}, want: "production-needs-approval"},
+		{name: "staging", deployment: DeploymentContext{Environment: "staging", ModelStatus: "draft"}, want: ""},
+		{name: "not a deployment", deployment: DeploymentContext{}, want: ""},
+	}
+	for _, tc := range cases {
+		decision, err := Evaluate(spec, Context{Deployment: tc.deployment})
+		if err != nil {
+			t.Fatalf("%s: Evaluate() err=%v", tc.name, err)
+		}
+		if decision.RuleID != tc.want {
+			t.Fatalf("%s: RuleID=%q, want %q", tc.name, decision.RuleID, tc.want)
+		}
+	}
+}
@@ -4,13 +4,19 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"regexp"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
 const SpecSchemaV1 = "animus.policy.v1"
 
+// SpecSchemaOPAV1 specs carry no rules: they name a Rego decision that the
+// OPA sidecar evaluates.
+const SpecSchemaOPAV1 = "animus.policy.opa.v1"
+
 const (
 	EffectAllow           = "allow"
 	EffectDeny            = "deny"
@@ -22,9 +28,37 @@ const (
 )
 
 type Spec struct {
-	Schema        string `json:"schema" yaml:"schema"`
-	DefaultEffect string `json:"default_effect,omitempty" yaml:"default_effect,omitempty"`
-	Rules         []Rule `json:"rules" yaml:"rules"`
+	Schema        string     `json:"schema" yaml:"schema"`
+	DefaultEffect string     `json:"default_effect,omitempty" yaml:"default_effect,omitempty"`
+	Rules         []Rule     `json:"rules" yaml:"rules"`
+	OPA           *OPASource `json:"opa,omitempty" yaml:"opa,omitempty"`
+	// MaxRunDuration caps how long a dispatched run may execute, as a Go
+	// duration. It applies to every run while the policy is active.
+	MaxRunDuration string `json:"max_run_duration,omitempty" yaml:"max_run_duration,omitempty"`
+}
+
+// OPASource names the decision document queried at /v1/data/{path}.
+type OPASource struct {
+	Path string `json:"path" yaml:"path"`
+}
+
+var opaPathPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(/[A-Za-z_][A-Za-z0-9_]*)*$`)
+
+func (s Spec) IsOPA() bool {
+	return strings.TrimSpace(s.Schema) == SpecSchemaOPAV1
+}
+
+// RunDurationLimit returns MaxRunDuration, or 0 when the spec sets none.
+func (s Spec) RunDurationLimit() time.Duration {
+	raw := strings.TrimSpace(s.MaxRunDuration)
+	if raw == "" {
+		return 0
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d <= 0 {
+		return 0
+	}
+	return d
 }
 
 type Rule struct {
@@ -32,6 +66,8 @@ type Rule struct {
 	Description string         `json:"description,omitempty" yaml:"description,omitempty"`
 	Effect      string         `json:"effect" yaml:"effect"`
 	When        ConditionGroup `json:"when" yaml:"when"`
+	// Approval replaces the single admin review of require_approval rules.
+	Approval *ApprovalWorkflow `json:"approval,omitempty" yaml:"approval,omitempty"`
 }
 
 type ConditionGroup struct {
@@ -63,11 +99,9 @@ func (s Spec) MarshalJSON() ([]byte, error) {
 }
 
 func (s Spec) Validate() error {
-	if strings.TrimSpace(s.Schema) != SpecSchemaV1 {
-		return fmt.Errorf("spec.schema must be %q", SpecSchemaV1)
-	}
-	if len(s.Rules) == 0 {
-		return errors.New("spec.rules must be non-empty")
+	schema := strings.TrimSpace(s.Schema)
+	if schema != SpecSchemaV1 && schema != SpecSchemaOPAV1 {
+		return fmt.Errorf("spec.schema must be %q or %q", SpecSchemaV1, SpecSchemaOPAV1)
 	}
 
 	defaultEffect := strings.ToLower(strings.TrimSpace(s.DefaultEffect))
@@ -75,6 +109,28 @@ func (s Spec) Validate() error {
 		return fmt.Errorf("spec.default_effect unsupported: %q", s.DefaultEffect)
 	}
 
+	if raw := strings.TrimSpace(s.MaxRunDuration); raw != "" {
+		if d, err := time.ParseDuration(raw); err != nil || d < time.Second {
+			return fmt.Errorf("spec.max_run_duration must be a duration of at least 1s, got %q", s.MaxRunDuration)
+		}
+	}
+
+	if s.IsOPA() {
+		if len(s.Rules) > 0 {
+			return fmt.Errorf("spec.rules is not allowed with %q", SpecSchemaOPAV1)
+		}
+		if s.OPA == nil || !opaPathPattern.MatchString(strings.TrimSpace(s.OPA.Path)) {
+			return errors.New("spec.opa.path must be a slash-separated Rego package path, e.g. animus/runs/decision")
+		}
+		return nil
+	}
+	if s.OPA != nil {
+		return fmt.Errorf("spec.opa requires schema %q", SpecSchemaOPAV1)
+	}
+	if len(s.Rules) == 0 {
+		return errors.New("spec.rules must be non-empty")
+	}
+
 	seen := make(map[string]struct{}, len(s.Rules))
 	for i, rule := range s.Rules {
 		ruleID := strings.TrimSpace(rule.ID)
@@ -93,6 +149,14 @@ func (s Spec) Validate() error {
 		if !isEffectAllowed(effect) {
 			return fmt.Errorf("spec.rules[%d].effect unsupported: %q", i, rule.Effect)
 		}
+		if rule.Approval != nil {
+			if effect != EffectRequireApproval {
+				return fmt.Errorf("spec.rules[%d].approval requires effect %q", i, EffectRequireApproval)
+			}
+			if err := rule.Approval.Validate(); err != nil {
+				return fmt.Errorf("spec.rules[%d].approval.%w", i, err)
+			}
+		}
 
 		if len(rule.When.All) == 0 && len(rule.When.Any) == 0 {
 			return fmt.Errorf("spec.rules[%d].when must include all or any", i)
@@ -0,0 +1,230 @@
+package postgres
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	embeddedScheme      = "embedded"
+	embeddedDefaultPort = 54329
+	embeddedUser        = "animus"
+	embeddedDatabase    = "animus"
+	embeddedLockStale   = 2 * time.Minute
+)
+
+// EmbeddedConfig describes a developer-mode Postgres cluster kept in a local
+// directory and started from the initdb/pg_ctl binaries of the host. It
+// covers Postgres only; object storage still needs an S3 endpoint.
+type EmbeddedConfig struct {
+	Dir  string
+	Port int
+	// BinDir holds initdb and pg_ctl; empty searches PATH.
+	BinDir string
+}
+
+// driverFor picks the database/sql driver for a DSN. The services' SQL is
+// written for Postgres, so postgres:// and the local embedded:// cluster are
+// the only backends. A DSN without a scheme is a libpq key/value string
+// (host=... user=... dbname=...), which pgx also parses.
+func driverFor(dsn string) (string, error) {
+	scheme, _, found := strings.Cut(dsn, ":")
+	if !found || strings.ContainsAny(scheme, "= \t") {
+		return "pgx", nil
+	}
+	switch strings.ToLower(scheme) {
+	case "postgres", "postgresql":
+		return "pgx", nil
+	case embeddedScheme:
+		return embeddedScheme, nil
+	case "sqlite", "sqlite3", "file":
+		return "", errors.New("DATABASE_URL: SQLite is not supported, the schema and queries use the Postgres dialect; use embedded://<dir> for a local cluster without Docker")
+	default:
+		return "", fmt.Errorf("DATABASE_URL: unsupported scheme %q", scheme)
+	}
+}
+
+// ParseEmbeddedURL reads embedded://<dir>[?port=N&bin=<dir>]. A relative dir
+// is resolved against the working directory.
+func ParseEmbeddedURL(raw string) (EmbeddedConfig, error) {
+	rest, ok := strings.CutPrefix(raw, embeddedScheme+"://")
+	if !ok {
+		return EmbeddedConfig{}, fmt.Errorf("embedded url must start with %s://", embeddedScheme)
+	}
+	dir, rawQuery, _ := strings.Cut(rest, "?")
+	if strings.TrimSpace(dir) == "" {
+		return EmbeddedConfig{}, errors.New("embedded url: data directory is required")
+	}
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return EmbeddedConfig{}, fmt.Errorf("embedded url: %w", err)
+	}
+	cfg := EmbeddedConfig{Port: embeddedDefaultPort, BinDir: query.Get("bin")}
+	if cfg.Dir, err = filepath.Abs(dir); err != nil {
+		return EmbeddedConfig{}, err
+	}
+	if raw := query.Get("port"); raw != "" {
+		if cfg.Port, err = strconv.Atoi(raw); err != nil || cfg.Port < 1 || cfg.Port > 65535 {
+			return EmbeddedConfig{}, fmt.Errorf("embedded url: invalid port %q", raw)
+		}
+	}
+	return cfg, nil
+}
+
+// DSN is the connection string of the running cluster for the password
+// initdb generated; an empty password is for clusters that predate it and
+// still use trust auth.
+func (c EmbeddedConfig) DSN(password string) string {
+	return c.dsn(embeddedDatabase, password)
+}
+
+func (c EmbeddedConfig) dsn(database, password string) string {
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.User(embeddedUser),
+		Host:     fmt.Sprintf("127.0.0.1:%d", c.Port),
+		Path:     "/" + database,
+		RawQuery: "sslmode=disable",
+	}
+	if password != "" {
+		u.User = url.UserPassword(embeddedUser, password)
+	}
+	return u.String()
+}
+
+// passwordFile keeps the generated superuser password next to the data
+// directory: initdb refuses a non-empty directory.
+func (c EmbeddedConfig) passwordFile() string {
+	return filepath.Join(filepath.Dir(c.Dir), "."+filepath.Base(c.Dir)+".password")
+}
+
+// StartEmbedded initialises the cluster on first use, starts it unless it is
+// already running and creates the animus database. initdb sets a random
+// password with scram-sha-256 auth, so other local users cannot connect to
+// the loopback port; the password file is readable by the owner only.
+// Services sharing the directory reuse one cluster; it keeps running after
+// they exit and is stopped with `pg_ctl -D <dir> stop`.
+func StartEmbedded(ctx context.Context, cfg EmbeddedConfig) (string, error) {
+	if err := os.MkdirAll(cfg.Dir, 0o700); err != nil {
+		return "", err
+	}
+	unlock, err := lockEmbedded(ctx, filepath.Join(filepath.Dir(cfg.Dir), "."+filepath.Base(cfg.Dir)+".lock"))
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
+	if _, err := os.Stat(filepath.Join(cfg.Dir, "PG_VERSION")); errors.Is(err, os.ErrNotExist) {
+		if err := writeEmbeddedPassword(cfg.passwordFile()); err != nil {
+			return "", err
+		}
+		if err := cfg.run(ctx, "initdb", "-D", cfg.Dir, "-U", embeddedUser, "--auth=scram-sha-256", "--pwfile="+cfg.passwordFile(), "-E", "UTF8", "--no-locale"); err != nil {
+			return "", err
+		}
+	}
+	password, err := readEmbeddedPassword(cfg.passwordFile())
+	if err != nil {
+		return "", err
+	}
+	if cfg.run(ctx, "pg_ctl", "-D", cfg.Dir, "status") != nil {
+		options := fmt.Sprintf("-p %d -c listen_addresses=127.0.0.1 -c unix_socket_directories=''", cfg.Port)
+		if err := cfg.run(ctx, "pg_ctl", "-D", cfg.Dir, "-l", filepath.Join(cfg.Dir, "postgres.log"), "-w", "-o", options, "start"); err != nil {
+			return "", err
+		}
+	}
+
+	admin, err := sql.Open("pgx", cfg.dsn("postgres", password))
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = admin.Close() }()
+	var exists bool
+	if err := admin.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM pg_database WHERE datname = $1)`, embeddedDatabase).Scan(&exists); err != nil {
+		return "", fmt.Errorf("embedded postgres: %w", err)
+	}
+	if !exists {
+		if _, err := admin.ExecContext(ctx, `CREATE DATABASE `+embeddedDatabase); err != nil {
+			return "", fmt.Errorf("embedded postgres: %w", err)
+		}
+	}
+	return cfg.DSN(password), nil
+}
+
+func writeEmbeddedPassword(path string) error {
+	raw := make([]byte, 24)
+	if _, err := rand.Read(raw); err != nil {
+		return fmt.Errorf("embedded postgres: password: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(hex.EncodeToString(raw)+"\n"), 0o600); err != nil {
+		return fmt.Errorf("embedded postgres: password: %w", err)
+	}
+	return nil
+}
+
+// readEmbeddedPassword returns "" for a cluster initialised before the
+// password file existed.
+func readEmbeddedPassword(path string) (string, error) {
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("embedded postgres: password: %w", err)
+	}
+	return strings.TrimSpace(string(raw)), nil
+}
+
+func (c EmbeddedConfig) run(ctx context.Context, name string, args ...string) error {
+	bin := name
+	if c.BinDir != "" {
+		bin = filepath.Join(c.BinDir, name)
+	} else if found, err := exec.LookPath(name); err == nil {
+		bin = found
+	} else {
+		return fmt.Errorf("embedded postgres: %s not found in PATH; install Postgres or set ?bin=<dir>", name)
+	}
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, bin, args...)
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("embedded postgres: %s: %w: %s", name, err, strings.TrimSpace(out.String()))
+	}
+	return nil
+}
+
+// lockEmbedded serialises initdb/start between services launched together.
+// A lock file older than embeddedLockStale is left over from a crash.
+func lockEmbedded(ctx context.Context, path string) (func(), error) {
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err == nil {
+			_ = f.Close()
+			return func() { _ = os.Remove(path) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		if info, statErr := os.Stat(path); statErr == nil && time.Since(info.ModTime()) > embeddedLockStale {
+			_ = os.Remove(path)
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(200 * time.Millisecond):
+		}
+	}
+}
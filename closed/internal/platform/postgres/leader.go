@@ -0,0 +1,83 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"sync"
+)
+
+// Leader elects one process per name with a session-level advisory lock held
+// on a dedicated connection. Postgres drops the lock together with the
+// connection, so another process takes over on its next Acquire when the
+// leader dies.
+type Leader struct {
+	db   *sql.DB
+	name string
+
+	mu   sync.Mutex
+	conn *sql.Conn
+}
+
+func NewLeader(db *sql.DB, name string) *Leader {
+	name = strings.TrimSpace(name)
+	if db == nil || name == "" {
+		return nil
+	}
+	return &Leader{db: db, name: "leader:" + name}
+}
+
+// Acquire reports whether this process leads, taking the lock when it is
+// free. A leader whose connection broke loses leadership and competes again.
+func (l *Leader) Acquire(ctx context.Context) (bool, error) {
+	if l == nil {
+		return false, errors.New("leader not initialized")
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn != nil {
+		if err := l.conn.PingContext(ctx); err == nil {
+			return true, nil
+		}
+		l.closeLocked(ctx)
+	}
+
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return false, err
+	}
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock(hashtext($1))`, l.name).Scan(&acquired); err != nil {
+		_ = conn.Close()
+		return false, err
+	}
+	if !acquired {
+		_ = conn.Close()
+		return false, nil
+	}
+	l.conn = conn
+	return true, nil
+}
+
+// Release gives up leadership, if held.
+func (l *Leader) Release(ctx context.Context) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.closeLocked(ctx)
+}
+
+// closeLocked unlocks before closing: a healthy connection goes back to the
+// pool and must not keep the lock.
+func (l *Leader) closeLocked(ctx context.Context) {
+	if l.conn == nil {
+		return
+	}
+	_, _ = l.conn.ExecContext(ctx, `SELECT pg_advisory_unlock(hashtext($1))`, l.name)
+	_ = l.conn.Close()
+	l.conn = nil
+}
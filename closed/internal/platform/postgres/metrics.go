@@ -0,0 +1,66 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// PrometheusMetrics reports sql.DBStats of the named pools (primary, replica)
+// of one service for /metrics.
+func PrometheusMetrics(service string, pools map[string]*sql.DB) func(io.Writer) {
+	service = strings.TrimSpace(service)
+	names := make([]string, 0, len(pools))
+	for name, db := range pools {
+		if db != nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return func(w io.Writer) {
+		if w == nil {
+			return
+		}
+		stats := make([]sql.DBStats, len(names))
+		for i, name := range names {
+			stats[i] = pools[name].Stats()
+		}
+		series := func(metric, kind, help string, value func(sql.DBStats) float64) {
+			fmt.Fprintf(w, "# HELP %s %s\n", metric, help)
+			fmt.Fprintf(w, "# TYPE %s %s\n", metric, kind)
+			for i, name := range names {
+				fmt.Fprintf(w, "%s{service=%q,pool=%q} %g\n", metric, service, name, value(stats[i]))
+			}
+		}
+
+		series("animus_db_pool_max_open_connections", "gauge", "Configured maximum of open connections.",
+			func(s sql.DBStats) float64 { return float64(s.MaxOpenConnections) })
+		series("animus_db_pool_open_connections", "gauge", "Open connections, in use and idle.",
+			func(s sql.DBStats) float64 { return float64(s.OpenConnections) })
+		series("animus_db_pool_in_use_connections", "gauge", "Connections currently in use.",
+			func(s sql.DBStats) float64 { return float64(s.InUse) })
+		series("animus_db_pool_idle_connections", "gauge", "Idle connections.",
+			func(s sql.DBStats) float64 { return float64(s.Idle) })
+		series("animus_db_pool_wait_count_total", "counter", "Connections waited for because the pool was exhausted.",
+			func(s sql.DBStats) float64 { return float64(s.WaitCount) })
+		series("animus_db_pool_wait_duration_seconds_total", "counter", "Time spent waiting for a connection.",
+			func(s sql.DBStats) float64 { return s.WaitDuration.Seconds() })
+
+		fmt.Fprint(w, "# HELP animus_db_pool_closed_connections_total Connections closed by pool limits.\n")
+		fmt.Fprint(w, "# TYPE animus_db_pool_closed_connections_total counter\n")
+		for i, name := range names {
+			for _, closed := range []struct {
+				reason string
+				count  int64
+			}{
+				{"max_idle", stats[i].MaxIdleClosed},
+				{"max_idle_time", stats[i].MaxIdleTimeClosed},
+				{"max_lifetime", stats[i].MaxLifetimeClosed},
+			} {
+				fmt.Fprintf(w, "animus_db_pool_closed_connections_total{service=%q,pool=%q,reason=%q} %d\n", service, name, closed.reason, closed.count)
+			}
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/animus-labs/animus-go/closed/internal/platform/env"
@@ -20,25 +21,29 @@ type Config struct {
 	ConnMaxIdleTime time.Duration
 }
 
-func ConfigFromEnv() (Config, error) {
+// ConfigFromEnv reads DATABASE_*. Pool settings can be overridden for one
+// service with the same name prefixed by the service, for example
+// AUDIT_DATABASE_MAX_OPEN_CONNS.
+func ConfigFromEnv(service string) (Config, error) {
+	prefix := servicePrefix(service)
 	pingTimeout, err := env.Duration("DATABASE_PING_TIMEOUT", 2*time.Second)
 	if err != nil {
 		return Config{}, err
 	}
 
-	maxOpenConns, err := env.Int("DATABASE_MAX_OPEN_CONNS", 10)
+	maxOpenConns, err := serviceInt(prefix, "DATABASE_MAX_OPEN_CONNS", 10)
 	if err != nil {
 		return Config{}, err
 	}
-	maxIdleConns, err := env.Int("DATABASE_MAX_IDLE_CONNS", 5)
+	maxIdleConns, err := serviceInt(prefix, "DATABASE_MAX_IDLE_CONNS", 5)
 	if err != nil {
 		return Config{}, err
 	}
-	connMaxLifetime, err := env.Duration("DATABASE_CONN_MAX_LIFETIME", 30*time.Minute)
+	connMaxLifetime, err := serviceDuration(prefix, "DATABASE_CONN_MAX_LIFETIME", 30*time.Minute)
 	if err != nil {
 		return Config{}, err
 	}
-	connMaxIdleTime, err := env.Duration("DATABASE_CONN_MAX_IDLE_TIME", 5*time.Minute)
+	connMaxIdleTime, err := serviceDuration(prefix, "DATABASE_CONN_MAX_IDLE_TIME", 5*time.Minute)
 	if err != nil {
 		return Config{}, err
 	}
@@ -57,10 +62,33 @@ func ConfigFromEnv() (Config, error) {
 	return cfg, nil
 }
 
+func servicePrefix(service string) string {
+	return strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(service), "-", "_"))
+}
+
+func serviceInt(prefix, name string, def int) (int, error) {
+	value, err := env.Int(name, def)
+	if err != nil || prefix == "" {
+		return value, err
+	}
+	return env.Int(prefix+"_"+name, value)
+}
+
+func serviceDuration(prefix, name string, def time.Duration) (time.Duration, error) {
+	value, err := env.Duration(name, def)
+	if err != nil || prefix == "" {
+		return value, err
+	}
+	return env.Duration(prefix+"_"+name, value)
+}
+
 func (c Config) Validate() error {
 	if c.URL == "" {
 		return errors.New("DATABASE_URL is required")
 	}
+	if _, err := driverFor(c.URL); err != nil {
+		return err
+	}
 	if c.PingTimeout <= 0 {
 		return errors.New("DATABASE_PING_TIMEOUT must be positive")
 	}
@@ -82,11 +110,23 @@ func (c Config) Validate() error {
 	return nil
 }
 
+// Open connects and pings; an embedded:// URL starts the local developer
+// cluster first (see StartEmbedded).
 func Open(ctx context.Context, cfg Config) (*sql.DB, error) {
 	if err := cfg.Validate(); err != nil {
 		return nil, err
 	}
 
+	if driver, _ := driverFor(cfg.URL); driver == embeddedScheme {
+		embedded, err := ParseEmbeddedURL(cfg.URL)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.URL, err = StartEmbedded(ctx, embedded); err != nil {
+			return nil, err
+		}
+	}
+
 	db, err := sql.Open("pgx", cfg.URL)
 	if err != nil {
 		return nil, fmt.Errorf("open: %w", err)
@@ -1,9 +1,15 @@
 package postgres
 
-import "testing"
+import (
+	"bytes"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+)
 
 func TestConfigValidate(t *testing.T) {
-	cfg, err := ConfigFromEnv()
+	cfg, err := ConfigFromEnv("")
 	if err != nil {
 		t.Fatalf("ConfigFromEnv() err=%v", err)
 	}
@@ -11,3 +17,55 @@ func TestConfigValidate(t *testing.T) {
 		t.Fatalf("Validate() err=%v", err)
 	}
 }
+
+func TestConfigFromEnvServiceOverrides(t *testing.T) {
+	t.Setenv("DATABASE_MAX_OPEN_CONNS", "20")
+	t.Setenv("AUDIT_DATABASE_MAX_OPEN_CONNS", "40")
+	t.Setenv("AUDIT_DATABASE_CONN_MAX_LIFETIME", "10m")
+
+	cfg, err := ConfigFromEnv("audit")
+	if err != nil {
+		t.Fatalf("ConfigFromEnv(audit) err=%v", err)
+	}
+	if cfg.MaxOpenConns != 40 || cfg.ConnMaxLifetime != 10*time.Minute || cfg.MaxIdleConns != 5 {
+		t.Fatalf("audit cfg=%+v", cfg)
+	}
+	cfg, err = ConfigFromEnv("lineage")
+	if err != nil {
+		t.Fatalf("ConfigFromEnv(lineage) err=%v", err)
+	}
+	if cfg.MaxOpenConns != 20 || cfg.ConnMaxLifetime != 30*time.Minute {
+		t.Fatalf("lineage cfg=%+v", cfg)
+	}
+
+	t.Setenv("DATASET_REGISTRY_DATABASE_MAX_IDLE_CONNS", "50")
+	if _, err := ConfigFromEnv("dataset-registry"); err == nil {
+		t.Fatal("expected idle above open to be rejected")
+	}
+}
+
+func TestPrometheusMetrics(t *testing.T) {
+	db, err := sql.Open("pgx", "postgres://primary/animus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(7)
+
+	var buf bytes.Buffer
+	PrometheusMetrics("audit", map[string]*sql.DB{"primary": db, "replica": nil})(&buf)
+	out := buf.String()
+	for _, want := range []string{
+		"# TYPE animus_db_pool_max_open_connections gauge",
+		`animus_db_pool_max_open_connections{service="audit",pool="primary"} 7`,
+		`animus_db_pool_wait_count_total{service="audit",pool="primary"} 0`,
+		`animus_db_pool_closed_connections_total{service="audit",pool="primary",reason="max_lifetime"} 0`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("missing %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, `pool="replica"`) {
+		t.Fatalf("nil pool reported:\n%s", out)
+	}
+}
@@ -0,0 +1,278 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/env"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Querier is the read-only subset of *sql.DB. Both *sql.DB and *Replica
+// satisfy it, so handlers do not care whether a replica is configured.
+type Querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+type ReplicaConfig struct {
+	// URL is the read-only DSN; empty routes every read to the primary.
+	URL           string
+	MaxLag        time.Duration
+	CheckInterval time.Duration
+	// RetryAfter is how long a failed replica is skipped before the next try.
+	RetryAfter time.Duration
+}
+
+// ReplicaConfigFromEnv reads DATABASE_READ_*. A service overrides the shared
+// DSN with <SERVICE>_DATABASE_READ_URL or opts out with
+// <SERVICE>_DATABASE_READ_ROUTING=false.
+func ReplicaConfigFromEnv(service string) (ReplicaConfig, error) {
+	prefix := servicePrefix(service)
+	routing := true
+	if prefix != "" {
+		var err error
+		if routing, err = env.Bool(prefix+"_DATABASE_READ_ROUTING", true); err != nil {
+			return ReplicaConfig{}, err
+		}
+	}
+	maxLag, err := env.Duration("DATABASE_READ_MAX_LAG", 10*time.Second)
+	if err != nil {
+		return ReplicaConfig{}, err
+	}
+	checkInterval, err := env.Duration("DATABASE_READ_CHECK_INTERVAL", 5*time.Second)
+	if err != nil {
+		return ReplicaConfig{}, err
+	}
+	retryAfter, err := env.Duration("DATABASE_READ_RETRY_AFTER", 30*time.Second)
+	if err != nil {
+		return ReplicaConfig{}, err
+	}
+
+	cfg := ReplicaConfig{
+		MaxLag:        maxLag,
+		CheckInterval: checkInterval,
+		RetryAfter:    retryAfter,
+	}
+	if routing {
+		cfg.URL = env.String("DATABASE_READ_URL", "")
+		if prefix != "" {
+			cfg.URL = env.String(prefix+"_DATABASE_READ_URL", cfg.URL)
+		}
+	}
+	if err := cfg.Validate(); err != nil {
+		return ReplicaConfig{}, err
+	}
+	return cfg, nil
+}
+
+func (c ReplicaConfig) Validate() error {
+	if c.URL == "" {
+		return nil
+	}
+	if driver, err := driverFor(c.URL); err != nil || driver != "pgx" {
+		return errors.New("DATABASE_READ_URL must be a Postgres DSN")
+	}
+	if c.MaxLag <= 0 {
+		return errors.New("DATABASE_READ_MAX_LAG must be positive")
+	}
+	if c.CheckInterval <= 0 {
+		return errors.New("DATABASE_READ_CHECK_INTERVAL must be positive")
+	}
+	if c.RetryAfter <= 0 {
+		return errors.New("DATABASE_READ_RETRY_AFTER must be positive")
+	}
+	return nil
+}
+
+// Replica routes read-only queries to a read replica and falls back to the
+// primary while the replica is unreachable or lags more than MaxLag.
+// Reads that must see the caller's own writes belong on the primary.
+type Replica struct {
+	primary *sql.DB
+	replica *sql.DB
+	cfg     ReplicaConfig
+	logger  *slog.Logger
+	now     func() time.Time
+
+	mu        sync.Mutex
+	downUntil time.Time
+	reason    string
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// OpenReplica opens the replica pool with the primary's pool limits. Without
+// a replica URL it returns a Replica that always uses the primary. The
+// replica is not pinged here: an unavailable replica must not block startup.
+func OpenReplica(primary *sql.DB, pool Config, cfg ReplicaConfig, logger *slog.Logger) (*Replica, error) {
+	if primary == nil {
+		return nil, errors.New("primary database is required")
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	r := &Replica{primary: primary, cfg: cfg, logger: logger, now: time.Now}
+	if cfg.URL == "" {
+		return r, nil
+	}
+
+	replica, err := sql.Open("pgx", cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("open replica: %w", err)
+	}
+	replica.SetMaxOpenConns(pool.MaxOpenConns)
+	replica.SetMaxIdleConns(pool.MaxIdleConns)
+	replica.SetConnMaxLifetime(pool.ConnMaxLifetime)
+	replica.SetConnMaxIdleTime(pool.ConnMaxIdleTime)
+	r.replica = replica
+	r.stop = make(chan struct{})
+	r.done = make(chan struct{})
+	go r.monitor()
+	return r, nil
+}
+
+// Close stops the lag monitor and closes the replica pool; the primary is
+// owned by the caller.
+func (r *Replica) Close() error {
+	if r == nil || r.replica == nil {
+		return nil
+	}
+	close(r.stop)
+	<-r.done
+	return r.replica.Close()
+}
+
+// Primary returns the primary pool.
+func (r *Replica) Primary() *sql.DB {
+	return r.primary
+}
+
+func (r *Replica) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	if db := r.pick(); db != nil {
+		rows, err := db.QueryContext(ctx, query, args...)
+		if err == nil || !r.fallback(ctx, err) {
+			return rows, err
+		}
+	}
+	return r.primary.QueryContext(ctx, query, args...)
+}
+
+func (r *Replica) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	if db := r.pick(); db != nil {
+		row := db.QueryRowContext(ctx, query, args...)
+		if err := row.Err(); err == nil || !r.fallback(ctx, err) {
+			return row
+		}
+	}
+	return r.primary.QueryRowContext(ctx, query, args...)
+}
+
+// pick returns the replica pool when it is usable, nil otherwise.
+func (r *Replica) pick() *sql.DB {
+	if r.replica == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.now().Before(r.downUntil) {
+		return nil
+	}
+	return r.replica
+}
+
+// fallback reports whether a replica error should be retried on the primary,
+// marking the replica down when it is. SQL errors the primary would raise too
+// are returned as is.
+func (r *Replica) fallback(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, sql.ErrNoRows) {
+		return false
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && !replicaUnavailableCode(pgErr.Code) {
+		return false
+	}
+	r.markDown("query failed: " + err.Error())
+	return true
+}
+
+// replicaUnavailableCode matches connection failures (08), recovery
+// conflicts (40001) and shutdown or cancellation on a standby (57).
+func replicaUnavailableCode(code string) bool {
+	return strings.HasPrefix(code, "08") || strings.HasPrefix(code, "57") || code == "40001"
+}
+
+func (r *Replica) markDown(reason string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	wasUp := !r.now().Before(r.downUntil)
+	r.downUntil = r.now().Add(r.cfg.RetryAfter)
+	r.reason = reason
+	if wasUp {
+		r.logger.Warn("read replica unavailable, reading from primary", "reason", reason, "retry_after", r.cfg.RetryAfter)
+	}
+}
+
+func (r *Replica) markUp() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.reason != "" {
+		r.logger.Info("read replica restored")
+	}
+	r.downUntil = time.Time{}
+	r.reason = ""
+}
+
+func (r *Replica) monitor() {
+	defer close(r.done)
+	ticker := time.NewTicker(r.cfg.CheckInterval)
+	defer ticker.Stop()
+	for {
+		r.check()
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// check measures replay lag. A standby that has replayed everything it
+// received reports zero lag, so an idle primary does not look like lag.
+func (r *Replica) check() {
+	ctx, cancel := context.WithTimeout(context.Background(), r.cfg.CheckInterval)
+	defer cancel()
+	var lagSeconds float64
+	err := r.replica.QueryRowContext(ctx, `SELECT CASE
+		WHEN NOT pg_is_in_recovery() OR pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+		ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+	END`).Scan(&lagSeconds)
+	switch {
+	case err != nil:
+		r.markDown("health check failed: " + err.Error())
+	case time.Duration(lagSeconds*float64(time.Second)) > r.cfg.MaxLag:
+		r.markDown(fmt.Sprintf("replication lag %.1fs exceeds %s", lagSeconds, r.cfg.MaxLag))
+	default:
+		r.markUp()
+	}
+}
+
+// Pools returns the pools for metrics: the primary and, when configured, the
+// replica.
+func (r *Replica) Pools() map[string]*sql.DB {
+	pools := map[string]*sql.DB{"primary": r.primary}
+	if r.replica != nil {
+		pools["replica"] = r.replica
+	}
+	return pools
+}
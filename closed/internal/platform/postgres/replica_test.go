@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestReplicaConfigFromEnv(t *testing.T) {
+	t.Setenv("DATABASE_READ_URL", "postgres://replica/animus")
+	cfg, err := ReplicaConfigFromEnv("audit")
+	if err != nil {
+		t.Fatalf("ReplicaConfigFromEnv: %v", err)
+	}
+	if cfg.URL != "postgres://replica/animus" || cfg.MaxLag != 10*time.Second {
+		t.Fatalf("cfg=%+v", cfg)
+	}
+
+	t.Setenv("DATASET_REGISTRY_DATABASE_READ_URL", "postgres://reports/animus")
+	if cfg, _ := ReplicaConfigFromEnv("dataset-registry"); cfg.URL != "postgres://reports/animus" {
+		t.Fatalf("per-service url not applied: %+v", cfg)
+	}
+	t.Setenv("LINEAGE_DATABASE_READ_ROUTING", "false")
+	if cfg, _ := ReplicaConfigFromEnv("lineage"); cfg.URL != "" {
+		t.Fatalf("routing opt-out ignored: %+v", cfg)
+	}
+
+	t.Setenv("DATABASE_READ_MAX_LAG", "0s")
+	if _, err := ReplicaConfigFromEnv("audit"); err == nil {
+		t.Fatal("expected non-positive max lag to be rejected")
+	}
+}
+
+func TestReplicaFallback(t *testing.T) {
+	primary, err := sql.Open("pgx", "postgres://primary/animus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer primary.Close()
+	replica, err := sql.Open("pgx", "postgres://replica/animus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer replica.Close()
+
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	r := &Replica{
+		primary: primary,
+		replica: replica,
+		cfg:     ReplicaConfig{RetryAfter: 30 * time.Second},
+		logger:  slog.New(slog.NewTextHandler(io.Discard, nil)),
+		now:     func() time.Time { return now },
+	}
+	ctx := context.Background()
+
+	if r.fallback(ctx, &pgconn.PgError{Code: "42P01"}) || r.pick() != replica {
+		t.Fatal("sql errors must not fall back or mark the replica down")
+	}
+	if !r.fallback(ctx, &pgconn.PgError{Code: "40001"}) || r.pick() != nil {
+		t.Fatal("recovery conflict must fall back to primary")
+	}
+	now = now.Add(31 * time.Second)
+	if r.pick() != replica {
+		t.Fatal("replica must be retried after RetryAfter")
+	}
+	if !r.fallback(ctx, errors.New("dial tcp: connection refused")) || r.pick() != nil {
+		t.Fatal("connection errors must fall back to primary")
+	}
+	r.markUp()
+	if r.pick() != replica {
+		t.Fatal("healthy check must restore the replica")
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if r.fallback(cancelled, context.Canceled) {
+		t.Fatal("cancelled requests must not be retried")
+	}
+
+	primaryOnly, err := OpenReplica(primary, Config{}, ReplicaConfig{}, nil)
+	if err != nil || primaryOnly.pick() != nil || primaryOnly.Close() != nil {
+		t.Fatalf("primary-only replica: err=%v", err)
+	}
+}
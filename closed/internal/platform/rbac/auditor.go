@@ -0,0 +1,79 @@
+package rbac
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+)
+
+// AuditorScopeFunc reports whether a read-only request is visible to the
+// auditor role. The method has already been checked when it is called.
+type AuditorScopeFunc func(r *http.Request) bool
+
+// AuditorReadAll exposes every read-only route of a service to auditors.
+func AuditorReadAll(*http.Request) bool { return true }
+
+// AuditorRoutes exposes the read-only routes matching any of the patterns
+// (see MatchRoutePattern) to auditors.
+func AuditorRoutes(patterns ...string) AuditorScopeFunc {
+	return func(r *http.Request) bool {
+		for _, pattern := range patterns {
+			if MatchRoutePattern(pattern, r.URL.Path) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// auditorDeniedRoutes serve raw dataset and artifact content. They stay
+// closed to auditors whatever a service's AuditorScope says.
+var auditorDeniedRoutes = []string{
+	"/datasets/*/versions/*/download",
+	"/dataset-versions/*/download",
+	"/projects/*/artifacts/*/download",
+	"/experiment-runs/*/artifacts/**",
+}
+
+// AuditorDeniedRoute reports whether requestPath serves raw data auditors may
+// never read.
+func AuditorDeniedRoute(requestPath string) bool {
+	for _, pattern := range auditorDeniedRoutes {
+		if MatchRoutePattern(pattern, requestPath) {
+			return true
+		}
+	}
+	return false
+}
+
+func (a Authorizer) grantsAuditor(r *http.Request, identity auth.Identity, bindings []repo.RoleBindingRecord) bool {
+	if a.AuditorScope == nil || r == nil || !auth.IsReadOnlyMethod(r.Method) {
+		return false
+	}
+	if AuditorDeniedRoute(r.URL.Path) {
+		return false
+	}
+	if !hasAuditorRole(identity, bindings, a.AllowDirect) {
+		return false
+	}
+	return a.AuditorScope(r)
+}
+
+func hasAuditorRole(identity auth.Identity, bindings []repo.RoleBindingRecord, allowDirect bool) bool {
+	for _, binding := range bindings {
+		if strings.EqualFold(strings.TrimSpace(binding.Role), auth.RoleAuditor) {
+			return true
+		}
+	}
+	if !allowDirect {
+		return false
+	}
+	for _, role := range identity.Roles {
+		if strings.EqualFold(strings.TrimSpace(role), auth.RoleAuditor) {
+			return true
+		}
+	}
+	return false
+}
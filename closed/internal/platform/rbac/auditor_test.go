@@ -0,0 +1,97 @@
+package rbac
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+)
+
+func TestAuthorizerAuditorReadsScopedRoutesOnly(t *testing.T) {
+	authorizer := Authorizer{
+		AllowDirect: true,
+		RequiredRoleFor: func(r *http.Request) string {
+			return auth.RoleAdmin
+		},
+		AuditorScope: AuditorRoutes("/policies/**", "/experiment-runs/*/evidence-bundles/**"),
+	}
+	auditor := auth.Identity{Subject: "ext-auditor", Roles: []string{auth.RoleAuditor}}
+
+	cases := []struct {
+		method string
+		path   string
+		allow  bool
+	}{
+		{http.MethodGet, "/policies", true},
+		{http.MethodGet, "/policies/p-1/versions", true},
+		{http.MethodGet, "/experiment-runs/run-1/evidence-bundles/b-1/download", true},
+		{http.MethodPost, "/policies", false},
+		{http.MethodDelete, "/policies/p-1", false},
+		{http.MethodGet, "/experiment-runs/run-1/artifacts", false},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(tc.method, "http://example.test"+tc.path, nil)
+		err := authorizer.Authorize(req, auditor)
+		if tc.allow && err != nil {
+			t.Fatalf("%s %s: expected allow, got %v", tc.method, tc.path, err)
+		}
+		if !tc.allow && err == nil {
+			t.Fatalf("%s %s: expected forbidden", tc.method, tc.path)
+		}
+	}
+}
+
+func TestAuthorizerAuditorRequiresScopeAndRole(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "http://example.test/events", nil)
+	auditor := auth.Identity{Subject: "ext-auditor", Roles: []string{auth.RoleAuditor}}
+
+	if err := (Authorizer{AllowDirect: true}).Authorize(req, auditor); err == nil {
+		t.Fatalf("expected auditor to be denied without an auditor scope")
+	}
+	if err := (Authorizer{AuditorScope: AuditorReadAll}).Authorize(req, auditor); err == nil {
+		t.Fatalf("expected direct auditor role to be ignored when direct roles are disabled")
+	}
+
+	req = req.WithContext(auth.ContextWithProjectID(req.Context(), "proj-1"))
+	bound := Authorizer{
+		Store:        stubBindingStore{bindings: []repo.RoleBindingRecord{{Role: auth.RoleAuditor}}},
+		AuditorScope: AuditorReadAll,
+	}
+	if err := bound.Authorize(req, auth.Identity{Subject: "ext-auditor"}); err != nil {
+		t.Fatalf("expected auditor binding to grant read, got %v", err)
+	}
+}
+
+func TestAuthorizerAuditorNeverDownloadsRawData(t *testing.T) {
+	authorizer := Authorizer{
+		AllowDirect: true,
+		RequiredRoleFor: func(r *http.Request) string {
+			return auth.RoleAdmin
+		},
+		AuditorScope: AuditorReadAll,
+	}
+	auditor := auth.Identity{Subject: "ext-auditor", Roles: []string{auth.RoleAuditor}}
+	for _, path := range []string{
+		"/datasets/ds-1/versions/v-1/download",
+		"/dataset-versions/v-1/download",
+		"/projects/p-1/artifacts/a-1/download",
+		"/experiment-runs/run-1/artifacts/a-1/download",
+	} {
+		req := httptest.NewRequest(http.MethodGet, "http://example.test"+path, nil)
+		if err := authorizer.Authorize(req, auditor); err == nil {
+			t.Fatalf("GET %s: expected forbidden", path)
+		}
+	}
+	req := httptest.NewRequest(http.MethodGet, "http://example.test/dataset-versions/v-1", nil)
+	if err := authorizer.Authorize(req, auditor); err != nil {
+		t.Fatalf("expected metadata read, got %v", err)
+	}
+}
+
+func TestValidRoleNameRejectsAuditor(t *testing.T) {
+	if ValidRoleName("auditor") {
+		t.Fatalf("auditor is built in and must not be redefined as a custom role")
+	}
+}
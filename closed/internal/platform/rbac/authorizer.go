@@ -20,6 +20,10 @@ type Authorizer struct {
 	AllowDirect     bool
 	Now             func() time.Time
 	RequiredRoleFor RequiredRoleFunc
+	Permissions     *PermissionCache
+	// AuditorScope selects the read-only routes granted to the auditor role;
+	// nil denies auditors everything beyond their other roles.
+	AuditorScope AuditorScopeFunc
 }
 
 func (a Authorizer) Authorize(r *http.Request, identity auth.Identity) error {
@@ -44,13 +48,19 @@ func (a Authorizer) Authorize(r *http.Request, identity auth.Identity) error {
 	}
 
 	projectID := projectFromRequest(r)
-	role, _, err := ResolveRole(r.Context(), a.Store, projectID, identity, a.AllowDirect)
+	role, bindings, err := ResolveRole(r.Context(), a.Store, projectID, identity, a.AllowDirect)
 	if err != nil {
 		return auth.ErrForbidden
 	}
 	if HasAtLeast(role, required) {
 		return nil
 	}
+	if a.grantsPermission(r, identity, bindings) {
+		return nil
+	}
+	if a.grantsAuditor(r, identity, bindings) {
+		return nil
+	}
 	auditAccessDenied(r.Context(), a.Audit, r, identity, projectID, role, required, a.Now)
 	return auth.ErrForbidden
 }
@@ -0,0 +1,273 @@
+package rbac
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+)
+
+// PermissionWildcard grants every permission when assigned to a custom role.
+const PermissionWildcard = "*"
+
+var (
+	roleNamePattern   = regexp.MustCompile(`^[a-z][a-z0-9_-]{1,62}$`)
+	permissionPattern = regexp.MustCompile(`^[a-z][a-z0-9_.-]*:([a-z][a-z0-9_.-]*|\*)$`)
+)
+
+// PermissionStore loads custom role definitions and per-route permission mappings.
+type PermissionStore interface {
+	ListRoles(ctx context.Context) ([]repo.RBACRoleRecord, error)
+	ListRoutePermissions(ctx context.Context, service string) ([]repo.RBACRoutePermissionRecord, error)
+}
+
+// RoutePermission maps a method and path pattern to the permission it requires.
+type RoutePermission struct {
+	Method      string
+	PathPattern string
+	Permission  string
+}
+
+// PermissionSnapshot is an immutable view of custom roles and route mappings.
+type PermissionSnapshot struct {
+	roles  map[string][]string
+	routes []RoutePermission
+}
+
+// PermissionCache keeps a periodically refreshed PermissionSnapshot for one service.
+type PermissionCache struct {
+	Store   PermissionStore
+	Service string
+	TTL     time.Duration
+	Now     func() time.Time
+
+	mu       sync.Mutex
+	loaded   bool
+	loadedAt time.Time
+	snapshot PermissionSnapshot
+}
+
+func NewPermissionCache(store PermissionStore, service string, ttl time.Duration) *PermissionCache {
+	if ttl <= 0 {
+		ttl = 30 * time.Second
+	}
+	return &PermissionCache{
+		Store:   store,
+		Service: strings.ToLower(strings.TrimSpace(service)),
+		TTL:     ttl,
+	}
+}
+
+// Snapshot returns the cached permissions, reloading them once the TTL has elapsed.
+func (c *PermissionCache) Snapshot(ctx context.Context) (PermissionSnapshot, error) {
+	if c == nil || c.Store == nil {
+		return PermissionSnapshot{}, errors.New("permission cache not initialized")
+	}
+	now := time.Now().UTC()
+	if c.Now != nil {
+		now = c.Now().UTC()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.loaded && now.Sub(c.loadedAt) < c.TTL {
+		return c.snapshot, nil
+	}
+
+	roles, err := c.Store.ListRoles(ctx)
+	if err != nil {
+		return PermissionSnapshot{}, err
+	}
+	routes, err := c.Store.ListRoutePermissions(ctx, c.Service)
+	if err != nil {
+		return PermissionSnapshot{}, err
+	}
+	c.snapshot = NewPermissionSnapshot(roles, routes)
+	c.loaded = true
+	c.loadedAt = now
+	return c.snapshot, nil
+}
+
+// Invalidate forces the next Snapshot call to reload from the store.
+func (c *PermissionCache) Invalidate() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.loaded = false
+	c.mu.Unlock()
+}
+
+func NewPermissionSnapshot(roles []repo.RBACRoleRecord, routes []repo.RBACRoutePermissionRecord) PermissionSnapshot {
+	snapshot := PermissionSnapshot{
+		roles:  make(map[string][]string, len(roles)),
+		routes: make([]RoutePermission, 0, len(routes)),
+	}
+	for _, role := range roles {
+		name := strings.ToLower(strings.TrimSpace(role.Name))
+		if name == "" {
+			continue
+		}
+		snapshot.roles[name] = append([]string(nil), role.Permissions...)
+	}
+	for _, route := range routes {
+		snapshot.routes = append(snapshot.routes, RoutePermission{
+			Method:      strings.ToUpper(strings.TrimSpace(route.Method)),
+			PathPattern: strings.TrimSpace(route.PathPattern),
+			Permission:  strings.ToLower(strings.TrimSpace(route.Permission)),
+		})
+	}
+	sort.SliceStable(snapshot.routes, func(i, j int) bool {
+		return routeSpecificity(snapshot.routes[i]) > routeSpecificity(snapshot.routes[j])
+	})
+	return snapshot
+}
+
+// PermissionFor returns the permission mapped to the request, or "" when no route matches.
+func (s PermissionSnapshot) PermissionFor(method, requestPath string) string {
+	method = strings.ToUpper(strings.TrimSpace(method))
+	for _, route := range s.routes {
+		if route.Method != "*" && route.Method != method {
+			continue
+		}
+		if MatchRoutePattern(route.PathPattern, requestPath) {
+			return route.Permission
+		}
+	}
+	return ""
+}
+
+// Grants reports whether any of the given custom roles carries the permission.
+func (s PermissionSnapshot) Grants(roles []string, permission string) bool {
+	permission = strings.ToLower(strings.TrimSpace(permission))
+	if permission == "" {
+		return false
+	}
+	for _, role := range roles {
+		for _, granted := range s.roles[strings.ToLower(strings.TrimSpace(role))] {
+			if permissionMatches(granted, permission) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// HasRole reports whether the snapshot defines the custom role.
+func (s PermissionSnapshot) HasRole(role string) bool {
+	_, ok := s.roles[strings.ToLower(strings.TrimSpace(role))]
+	return ok
+}
+
+// MatchRoutePattern matches a request path against a pattern whose segments are
+// path.Match globs; a trailing "**" segment matches any remaining segments.
+func MatchRoutePattern(pattern, requestPath string) bool {
+	patternSegments := strings.Split(strings.Trim(strings.TrimSpace(pattern), "/"), "/")
+	pathSegments := strings.Split(strings.Trim(strings.TrimSpace(requestPath), "/"), "/")
+	for i, segment := range patternSegments {
+		if segment == "**" {
+			return i == len(patternSegments)-1
+		}
+		if i >= len(pathSegments) {
+			return false
+		}
+		ok, err := path.Match(segment, pathSegments[i])
+		if err != nil || !ok {
+			return false
+		}
+	}
+	return len(patternSegments) == len(pathSegments)
+}
+
+// ValidRoleName reports whether name is usable as a custom role name.
+func ValidRoleName(name string) bool {
+	name = strings.TrimSpace(name)
+	if _, builtin := roleLevels[strings.ToLower(name)]; builtin || strings.EqualFold(name, auth.RoleAuditor) {
+		return false
+	}
+	return roleNamePattern.MatchString(name)
+}
+
+// ValidPermission reports whether permission has the "resource:action" form or is the wildcard.
+func ValidPermission(permission string) bool {
+	permission = strings.TrimSpace(permission)
+	return permission == PermissionWildcard || permissionPattern.MatchString(permission)
+}
+
+// ValidRoutePattern reports whether pattern is an absolute path with valid globs.
+func ValidRoutePattern(pattern string) bool {
+	pattern = strings.TrimSpace(pattern)
+	if !strings.HasPrefix(pattern, "/") {
+		return false
+	}
+	segments := strings.Split(strings.Trim(pattern, "/"), "/")
+	for i, segment := range segments {
+		if segment == "**" {
+			if i != len(segments)-1 {
+				return false
+			}
+			continue
+		}
+		if _, err := path.Match(segment, ""); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func (a Authorizer) grantsPermission(r *http.Request, identity auth.Identity, bindings []repo.RoleBindingRecord) bool {
+	if a.Permissions == nil || r == nil {
+		return false
+	}
+	snapshot, err := a.Permissions.Snapshot(r.Context())
+	if err != nil {
+		return false
+	}
+	permission := snapshot.PermissionFor(r.Method, r.URL.Path)
+	if permission == "" {
+		return false
+	}
+	roles := make([]string, 0, len(bindings)+len(identity.Roles))
+	for _, binding := range bindings {
+		roles = append(roles, binding.Role)
+	}
+	if a.AllowDirect {
+		roles = append(roles, identity.Roles...)
+	}
+	return snapshot.Grants(roles, permission)
+}
+
+func permissionMatches(granted, required string) bool {
+	granted = strings.ToLower(strings.TrimSpace(granted))
+	if granted == "" {
+		return false
+	}
+	if granted == PermissionWildcard || granted == required {
+		return true
+	}
+	if resource, ok := strings.CutSuffix(granted, ":*"); ok {
+		return strings.HasPrefix(required, resource+":")
+	}
+	return false
+}
+
+func routeSpecificity(route RoutePermission) int {
+	score := 0
+	for _, ch := range route.PathPattern {
+		if ch != '*' && ch != '?' && ch != '[' && ch != ']' {
+			score++
+		}
+	}
+	if route.Method != "*" {
+		score++
+	}
+	return score
+}
@@ -0,0 +1,123 @@
+package rbac
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+)
+
+type stubPermissionStore struct {
+	roles  []repo.RBACRoleRecord
+	routes []repo.RBACRoutePermissionRecord
+	loads  int
+}
+
+func (s *stubPermissionStore) ListRoles(ctx context.Context) ([]repo.RBACRoleRecord, error) {
+	s.loads++
+	return s.roles, nil
+}
+
+func (s *stubPermissionStore) ListRoutePermissions(ctx context.Context, service string) ([]repo.RBACRoutePermissionRecord, error) {
+	return s.routes, nil
+}
+
+func dataStewardStore() *stubPermissionStore {
+	return &stubPermissionStore{
+		roles: []repo.RBACRoleRecord{{Name: "data-steward", Permissions: []string{"quality_rules:create"}}},
+		routes: []repo.RBACRoutePermissionRecord{
+			{Method: "POST", PathPattern: "/quality-rules", Permission: "quality_rules:create"},
+			{Method: "POST", PathPattern: "/policy-approvals/*/approve", Permission: "policies:approve"},
+		},
+	}
+}
+
+func TestAuthorizerGrantsCustomRolePermission(t *testing.T) {
+	store := stubBindingStore{bindings: []repo.RoleBindingRecord{{Role: "data-steward"}}}
+	authorizer := Authorizer{Store: store, Permissions: NewPermissionCache(dataStewardStore(), "experiments", time.Minute)}
+
+	req := httptest.NewRequest(http.MethodPost, "http://example.test/quality-rules", nil)
+	req = req.WithContext(auth.ContextWithProjectID(req.Context(), "proj-1"))
+	if err := authorizer.Authorize(req, auth.Identity{Subject: "user-1"}); err != nil {
+		t.Fatalf("expected allow via custom role, got %v", err)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "http://example.test/policy-approvals/appr-1/approve", nil)
+	req = req.WithContext(auth.ContextWithProjectID(req.Context(), "proj-1"))
+	if err := authorizer.Authorize(req, auth.Identity{Subject: "user-1"}); err == nil {
+		t.Fatalf("expected forbidden for unmapped permission")
+	}
+}
+
+func TestAuthorizerIgnoresDirectCustomRolesWhenDisallowed(t *testing.T) {
+	authorizer := Authorizer{Permissions: NewPermissionCache(dataStewardStore(), "experiments", time.Minute)}
+	req := httptest.NewRequest(http.MethodPost, "http://example.test/quality-rules", nil)
+
+	if err := authorizer.Authorize(req, auth.Identity{Subject: "user-1", Roles: []string{"data-steward"}}); err == nil {
+		t.Fatalf("expected forbidden when direct roles are disabled")
+	}
+	authorizer.AllowDirect = true
+	if err := authorizer.Authorize(req, auth.Identity{Subject: "user-1", Roles: []string{"data-steward"}}); err != nil {
+		t.Fatalf("expected allow via direct custom role, got %v", err)
+	}
+}
+
+func TestPermissionCacheHonoursTTL(t *testing.T) {
+	store := dataStewardStore()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := NewPermissionCache(store, "experiments", time.Minute)
+	cache.Now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if _, err := cache.Snapshot(context.Background()); err != nil {
+			t.Fatalf("snapshot: %v", err)
+		}
+	}
+	if store.loads != 1 {
+		t.Fatalf("expected 1 load within ttl, got %d", store.loads)
+	}
+	now = now.Add(2 * time.Minute)
+	_, _ = cache.Snapshot(context.Background())
+	cache.Invalidate()
+	_, _ = cache.Snapshot(context.Background())
+	if store.loads != 3 {
+		t.Fatalf("expected reload after ttl and invalidate, got %d", store.loads)
+	}
+}
+
+func TestMatchRoutePattern(t *testing.T) {
+	cases := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"/quality-rules", "/quality-rules", true},
+		{"/projects/*/runs", "/projects/p1/runs", true},
+		{"/projects/*/runs", "/projects/p1/runs/r1", false},
+		{"/model-versions/*:approve", "/model-versions/mv-1:approve", true},
+		{"/projects/**", "/projects/p1/runs/r1", true},
+		{"/projects/**", "/datasets", false},
+	}
+	for _, tc := range cases {
+		if got := MatchRoutePattern(tc.pattern, tc.path); got != tc.want {
+			t.Fatalf("MatchRoutePattern(%q, %q)=%v want %v", tc.pattern, tc.path, got, tc.want)
+		}
+	}
+}
+
+func TestPermissionSnapshotPrefersSpecificRoutes(t *testing.T) {
+	snapshot := NewPermissionSnapshot(nil, []repo.RBACRoutePermissionRecord{
+		{Method: "*", PathPattern: "/policies/**", Permission: "policies:manage"},
+		{Method: "POST", PathPattern: "/policies/*/versions", Permission: "policies:version"},
+	})
+	if got := snapshot.PermissionFor(http.MethodPost, "/policies/p1/versions"); got != "policies:version" {
+		t.Fatalf("expected specific route permission, got %q", got)
+	}
+	if !(PermissionSnapshot{roles: map[string][]string{"ops": {"policies:*"}}}).Grants([]string{"ops"}, "policies:version") {
+		t.Fatalf("expected resource wildcard to grant action")
+	}
+}
@@ -0,0 +1,263 @@
+// Package validation checks a decoded request body as a whole and collects
+// every invalid field, so a handler can answer with all of them in one 400
+// instead of stopping at the first.
+package validation
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	"github.com/google/uuid"
+)
+
+// Field error codes; each is a field_errors entry of the error catalog.
+const (
+	CodeRequired         = "required"
+	CodeInvalidType      = "invalid_type"
+	CodeInvalidUUID      = "invalid_uuid"
+	CodeInvalidImageRef  = "invalid_image_ref"
+	CodeInvalidCommitSHA = "invalid_commit_sha"
+	CodeInvalidRepoURL   = "invalid_repo_url"
+	CodeInvalidQuantity  = "invalid_quantity"
+	CodeOutOfRange       = "out_of_range"
+	CodeDuplicate        = "duplicate"
+	CodeTooMany          = "too_many"
+	CodeEmptyKey         = "empty_key"
+	CodeInvalidCron      = "invalid_cron"
+	CodeInvalidEnum      = "invalid_enum"
+	CodeUnknownField     = "unknown_field"
+)
+
+// maxImageRefLength bounds image references as the OCI distribution spec
+// bounds repository names.
+const maxImageRefLength = 255
+
+var (
+	// imageRefPattern follows the distribution reference grammar:
+	// [host[:port]/]path[:tag][@algorithm:hex].
+	imageRefPattern = regexp.MustCompile(`^` +
+		`(?:(?:[a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9-]*[a-zA-Z0-9])(?:\.(?:[a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9-]*[a-zA-Z0-9]))*(?::[0-9]+)?/)?` +
+		`[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*)*` +
+		`(?::[\w][\w.-]{0,127})?` +
+		`(?:@[A-Za-z][A-Za-z0-9]*(?:[-_+.][A-Za-z][A-Za-z0-9]*)*:[0-9a-fA-F]{32,})?` +
+		`$`)
+	// quantityPattern accepts Kubernetes resource quantities such as 500m,
+	// 2, 1.5 or 4Gi.
+	quantityPattern = regexp.MustCompile(`^(?:[0-9]+(?:\.[0-9]*)?|\.[0-9]+)(?:[eE][+-]?[0-9]+|[numkMGTPE]|[KMGTPE]i)?$`)
+)
+
+// Errors collects the invalid fields of one request. The zero value is ready
+// to use.
+type Errors struct {
+	fields []httpapi.FieldError
+}
+
+// Add records a violation at pointer, an RFC 6901 JSON pointer into the
+// request body.
+func (e *Errors) Add(pointer, code, message string) {
+	e.fields = append(e.fields, httpapi.FieldError{
+		Field:   fieldName(pointer),
+		Pointer: pointer,
+		Code:    code,
+		Message: message,
+	})
+}
+
+// Empty reports whether no violation was recorded.
+func (e *Errors) Empty() bool {
+	return len(e.fields) == 0
+}
+
+// Fields returns the recorded violations in the order they were added.
+func (e *Errors) Fields() []httpapi.FieldError {
+	return e.fields
+}
+
+// Write writes a 400 validation_failed response listing every violation and
+// reports whether it did; it writes nothing when e is empty.
+func (e *Errors) Write(w http.ResponseWriter, r *http.Request) bool {
+	if e.Empty() {
+		return false
+	}
+	httpapi.WriteValidationError(w, r, e.fields)
+	return true
+}
+
+// Required records a violation when value is blank.
+func (e *Errors) Required(pointer, value string) bool {
+	if strings.TrimSpace(value) == "" {
+		e.Add(pointer, CodeRequired, "is required")
+		return false
+	}
+	return true
+}
+
+// UUID records a violation when value is set and is not a UUID.
+func (e *Errors) UUID(pointer, value string) bool {
+	value = strings.TrimSpace(value)
+	if value == "" || IsUUID(value) {
+		return true
+	}
+	e.Add(pointer, CodeInvalidUUID, "must be a UUID")
+	return false
+}
+
+// ImageRef records a violation when value is set and is not a container
+// image reference.
+func (e *Errors) ImageRef(pointer, value string) bool {
+	value = strings.TrimSpace(value)
+	if value == "" || IsImageRef(value) {
+		return true
+	}
+	e.Add(pointer, CodeInvalidImageRef, "must be an image reference such as registry/repo:tag or repo@sha256:<digest>")
+	return false
+}
+
+// CommitSHA records a violation when value is set and is not a hex commit
+// SHA of 7 to 64 characters.
+func (e *Errors) CommitSHA(pointer, value string) bool {
+	value = strings.TrimSpace(value)
+	if value == "" || IsCommitSHA(value) {
+		return true
+	}
+	e.Add(pointer, CodeInvalidCommitSHA, "must be a hex commit SHA of 7 to 64 characters")
+	return false
+}
+
+// RepoURL records a violation when value is set and is neither an absolute
+// URL nor a git@host:path address.
+func (e *Errors) RepoURL(pointer, value string) bool {
+	value = strings.TrimSpace(value)
+	if value == "" || IsRepoURL(value) {
+		return true
+	}
+	e.Add(pointer, CodeInvalidRepoURL, "must be an absolute URL or git@host:path")
+	return false
+}
+
+// Quantity records a violation when value is not a string holding a
+// Kubernetes resource quantity.
+func (e *Errors) Quantity(pointer string, value any) bool {
+	s, ok := value.(string)
+	if !ok {
+		e.Add(pointer, CodeInvalidType, "must be a string")
+		return false
+	}
+	if !IsQuantity(strings.TrimSpace(s)) {
+		e.Add(pointer, CodeInvalidQuantity, "must be a resource quantity such as 500m, 2 or 4Gi")
+		return false
+	}
+	return true
+}
+
+// Count records a violation when value is not a non-negative integer, given
+// as a JSON number or a numeric string.
+func (e *Errors) Count(pointer string, value any) bool {
+	var n float64
+	switch t := value.(type) {
+	case float64:
+		n = t
+	case string:
+		parsed, err := strconv.ParseInt(strings.TrimSpace(t), 10, 64)
+		if err != nil {
+			e.Add(pointer, CodeInvalidType, "must be an integer")
+			return false
+		}
+		n = float64(parsed)
+	default:
+		e.Add(pointer, CodeInvalidType, "must be an integer")
+		return false
+	}
+	if n != float64(int64(n)) {
+		e.Add(pointer, CodeInvalidType, "must be an integer")
+		return false
+	}
+	if n < 0 {
+		e.Add(pointer, CodeOutOfRange, "must not be negative")
+		return false
+	}
+	return true
+}
+
+// Scalar records a violation when value, decoded from JSON, is not a
+// string, number or boolean.
+func (e *Errors) Scalar(pointer string, value any) bool {
+	switch value.(type) {
+	case string, float64, bool:
+		return true
+	default:
+		e.Add(pointer, CodeInvalidType, "must be a string, number or boolean")
+		return false
+	}
+}
+
+// IsUUID reports whether s is a UUID in its canonical hyphenated form.
+func IsUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	_, err := uuid.Parse(s)
+	return err == nil
+}
+
+// IsImageRef reports whether s is a syntactically valid image reference.
+func IsImageRef(s string) bool {
+	return len(s) <= maxImageRefLength && imageRefPattern.MatchString(s)
+}
+
+// IsCommitSHA reports whether s is a hex commit SHA of 7 to 64 characters.
+func IsCommitSHA(s string) bool {
+	s = strings.TrimSpace(s)
+	if len(s) < 7 || len(s) > 64 {
+		return false
+	}
+	for _, r := range s {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') && (r < 'A' || r > 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+// IsRepoURL reports whether s is an absolute URL with a host or a
+// git@host:path address.
+func IsRepoURL(s string) bool {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return false
+	}
+	if strings.HasPrefix(s, "git@") {
+		host, path, ok := strings.Cut(strings.TrimPrefix(s, "git@"), ":")
+		return ok && strings.TrimSpace(host) != "" && strings.TrimSpace(path) != ""
+	}
+	parsed, err := url.Parse(s)
+	return err == nil && parsed.Scheme != "" && parsed.Host != ""
+}
+
+// IsQuantity reports whether s is a Kubernetes resource quantity.
+func IsQuantity(s string) bool {
+	return quantityPattern.MatchString(s)
+}
+
+// Pointer builds an RFC 6901 JSON pointer from reference tokens.
+func Pointer(tokens ...string) string {
+	var b strings.Builder
+	for _, token := range tokens {
+		b.WriteByte('/')
+		b.WriteString(strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1"))
+	}
+	return b.String()
+}
+
+// fieldName turns a JSON pointer into the dotted path used in Field.
+func fieldName(pointer string) string {
+	tokens := strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return strings.Join(tokens, ".")
+}
@@ -0,0 +1,120 @@
+package validation
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/animus-labs/animus-go/core/contracts/errorcatalog"
+)
+
+func TestIsImageRef(t *testing.T) {
+	valid := []string{
+		"busybox",
+		"library/busybox:1.36",
+		"ghcr.io/acme/train:latest",
+		"localhost:5000/acme/train",
+		"registry.example.com/acme/train@sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+		"acme/train:v1@sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+	}
+	for _, ref := range valid {
+		if !IsImageRef(ref) {
+			t.Errorf("%q: want valid", ref)
+		}
+	}
+	invalid := []string{
+		"",
+		"ghcr.io/Acme/train",
+		"acme/train:",
+		"acme//train",
+		"acme/train@sha256:abc",
+		"https://ghcr.io/acme/train",
+		"acme/train latest",
+	}
+	for _, ref := range invalid {
+		if IsImageRef(ref) {
+			t.Errorf("%q: want invalid", ref)
+		}
+	}
+}
+
+func TestChecks(t *testing.T) {
+	var errs Errors
+	errs.Required("/name", " ")
+	errs.UUID("/id", "")
+	errs.UUID("/parent_id", "not-a-uuid")
+	errs.CommitSHA("/commit", "deadbeef")
+	errs.RepoURL("/repo", "git@github.com:acme/repo.git")
+	errs.RepoURL("/mirror", "github.com/acme/repo")
+	errs.Quantity("/limits/cpu", 2.0)
+	errs.Quantity("/limits/memory", "4Gi")
+	errs.Count("/gpus", 1.5)
+	errs.Count("/replicas", "3")
+	errs.Scalar(Pointer("params", "a/b"), map[string]any{})
+
+	want := []struct{ field, pointer, code string }{
+		{"name", "/name", CodeRequired},
+		{"parent_id", "/parent_id", CodeInvalidUUID},
+		{"mirror", "/mirror", CodeInvalidRepoURL},
+		{"limits.cpu", "/limits/cpu", CodeInvalidType},
+		{"gpus", "/gpus", CodeInvalidType},
+		{"params.a/b", "/params/a~1b", CodeInvalidType},
+	}
+	got := errs.Fields()
+	if len(got) != len(want) {
+		t.Fatalf("fields=%+v", got)
+	}
+	for i, w := range want {
+		if got[i].Field != w.field || got[i].Pointer != w.pointer || got[i].Code != w.code || got[i].Message == "" {
+			t.Errorf("field %d = %+v, want %+v", i, got[i], w)
+		}
+	}
+}
+
+func TestWrite(t *testing.T) {
+	var errs Errors
+	rec := httptest.NewRecorder()
+	if errs.Write(rec, httptest.NewRequest(http.MethodPost, "/", nil)) || rec.Body.Len() != 0 {
+		t.Fatalf("empty errors wrote %q", rec.Body)
+	}
+
+	errs.Required("/a", "")
+	errs.Required("/b", "")
+	if !errs.Write(rec, httptest.NewRequest(http.MethodPost, "/", nil)) {
+		t.Fatal("Write reported nothing written")
+	}
+	var body struct {
+		Error  string `json:"error"`
+		Errors []any  `json:"errors"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if rec.Code != http.StatusBadRequest || body.Error != "validation_failed" || len(body.Errors) != 2 {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body)
+	}
+}
+
+func TestCodesAreCatalogued(t *testing.T) {
+	c, err := errorcatalog.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	catalogued := make(map[string]bool, len(c.FieldErrors))
+	for _, entry := range c.FieldErrors {
+		catalogued[entry.Code] = true
+	}
+	for _, code := range []string{
+		CodeRequired, CodeInvalidType, CodeInvalidUUID, CodeInvalidImageRef, CodeInvalidCommitSHA,
+		CodeInvalidRepoURL, CodeInvalidQuantity, CodeOutOfRange, CodeDuplicate, CodeTooMany, CodeEmptyKey,
+		CodeInvalidCron, CodeInvalidEnum, CodeUnknownField,
+	} {
+		if !catalogued[code] {
+			t.Errorf("%s is not in catalog field_errors", code)
+		}
+	}
+	if _, ok := errorcatalog.Lookup("validation_failed"); !ok {
+		t.Error("validation_failed is not in catalog errors")
+	}
+}
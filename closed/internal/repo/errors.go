@@ -3,3 +3,6 @@ package repo
 import "errors"
 
 var ErrNotFound = errors.New("not_found")
+
+// ErrConflict reports a write that collides with an existing unique record.
+var ErrConflict = errors.New("conflict")
@@ -0,0 +1,279 @@
+package repo
+
+import (
+	"context"
+	"time"
+)
+
+type ExperimentRecord struct {
+	ExperimentID    string
+	ProjectID       string
+	Name            string
+	Description     string
+	Metadata        []byte
+	CreatedAt       time.Time
+	CreatedBy       string
+	IntegritySHA256 string
+}
+
+type ExperimentFilter struct {
+	ProjectID string
+	// Name, when set, matches the experiment name exactly.
+	Name  string
+	Limit int
+}
+
+// RunSummary is an experiment run as the API lists it: Status and EndedAt
+// take the latest state event into account. Params and Metrics are the raw
+// JSON columns.
+type RunSummary struct {
+	RunID             string
+	ExperimentID      string
+	ProjectID         string
+	DatasetVersionID  string
+	DatasetVersionIDs []string
+	Status            string
+	StartedAt         time.Time
+	EndedAt           *time.Time
+	GitRepo           string
+	GitCommit         string
+	GitRef            string
+	Params            []byte
+	Metrics           []byte
+	ArtifactsPrefix   string
+}
+
+type RunSummaryFilter struct {
+	ProjectID    string
+	ExperimentID string
+	Status       string
+	// ActiveOnly keeps pending and running runs.
+	ActiveOnly bool
+	Limit      int
+}
+
+type RunEventRecord struct {
+	EventID         int64
+	RunID           string
+	OccurredAt      time.Time
+	Actor           string
+	Level           string
+	Message         string
+	Metadata        []byte
+	IntegritySHA256 string
+}
+
+type RunEventFilter struct {
+	RunID string
+	// BeforeEventID pages backwards; zero starts at the newest event.
+	BeforeEventID int64
+	Limit         int
+}
+
+type RunStateEventRecord struct {
+	StateID         string
+	RunID           string
+	Status          string
+	ObservedAt      time.Time
+	Details         []byte
+	IntegritySHA256 string
+}
+
+// RunDatasetGate is what the quality gate reads about a dataset version a
+// run consumes. ReferenceState is empty for versions that are not
+// references; the evaluations are nil when there is none.
+type RunDatasetGate struct {
+	DatasetID          string
+	QualityRuleID      string
+	ContentSHA256      string
+	ReferenceState     string
+	QualityEvaluation  *GateEvaluation
+	ContractEvaluation *GateEvaluation
+}
+
+// GateEvaluation is the latest evaluation of a quality rule or data
+// contract.
+type GateEvaluation struct {
+	EvaluationID string
+	Status       string
+}
+
+type MetricSampleRecord struct {
+	SampleID        string
+	RunID           string
+	RecordedAt      time.Time
+	RecordedBy      string
+	Step            int64
+	Name            string
+	Value           float64
+	Metadata        []byte
+	IntegritySHA256 string
+}
+
+type MetricSampleFilter struct {
+	RunID string
+	// Name selects one series ordered by step; empty returns the latest
+	// sample of every series.
+	Name  string
+	Limit int
+}
+
+type PolicyRecord struct {
+	PolicyID        string
+	Name            string
+	Description     string
+	CreatedAt       time.Time
+	CreatedBy       string
+	IntegritySHA256 string
+	// Latest is nil for a policy without versions.
+	Latest *PolicyVersionRecord
+}
+
+type PolicyVersionRecord struct {
+	PolicyVersionID string
+	PolicyID        string
+	Version         int
+	Status          string
+	SpecYAML        string
+	SpecJSON        []byte
+	SpecSHA256      string
+	CreatedAt       time.Time
+	CreatedBy       string
+	IntegritySHA256 string
+}
+
+type PolicyDecisionRecord struct {
+	DecisionID      string
+	RunID           string
+	PolicyID        string
+	PolicyName      string
+	PolicyVersionID string
+	PolicySHA256    string
+	ContextSHA256   string
+	Context         []byte
+	Decision        string
+	RuleID          string
+	Reason          string
+	BundleRevision  string
+	CreatedAt       time.Time
+	CreatedBy       string
+}
+
+type PolicyDecisionFilter struct {
+	RunID string
+	Limit int
+}
+
+// PolicyApprovalRecord is an approval with the decision it reviews.
+// Context is the decision context; only GetPolicyApproval reads it.
+type PolicyApprovalRecord struct {
+	ApprovalID      string
+	DecisionID      string
+	RunID           string
+	Status          string
+	RequestedAt     time.Time
+	RequestedBy     string
+	DecidedAt       *time.Time
+	DecidedBy       string
+	Reason          string
+	PolicyID        string
+	PolicyName      string
+	PolicyVersionID string
+	Decision        string
+	RuleID          string
+	Context         []byte
+	Workflow        []byte
+	CurrentStage    int
+	EscalateAt      *time.Time
+	AssignedTo      string
+}
+
+type PolicyApprovalFilter struct {
+	Status string
+	RunID  string
+	Limit  int
+}
+
+type PolicyApprovalVoteRecord struct {
+	VoteID     string
+	Stage      int
+	StageName  string
+	Voter      string
+	OnBehalfOf string
+	Vote       string
+	Reason     string
+	CreatedAt  time.Time
+}
+
+// EvidenceBundleRecord keeps the wrapped data keys and the integrity hash,
+// which never leave the service.
+type EvidenceBundleRecord struct {
+	BundleID         string
+	RunID            string
+	BundleObjectKey  string
+	ReportObjectKey  string
+	BundleSHA256     string
+	BundleSizeBytes  int64
+	ReportSHA256     string
+	ReportSizeBytes  int64
+	Signature        string
+	SignatureAlg     string
+	SigningKeyID     string
+	CreatedAt        time.Time
+	CreatedBy        string
+	EncryptionAlg    string
+	EncryptionKeyID  string
+	BundleWrappedKey string
+	ReportWrappedKey string
+	IntegritySHA256  string
+}
+
+type RunStore interface {
+	// CreateExperiment returns ErrConflict when the project already has an
+	// experiment of that name.
+	CreateExperiment(ctx context.Context, experiment ExperimentRecord) error
+	GetExperiment(ctx context.Context, projectID, experimentID string) (ExperimentRecord, error)
+	ListExperiments(ctx context.Context, filter ExperimentFilter) ([]ExperimentRecord, error)
+	ExperimentExists(ctx context.Context, experimentID string) (bool, error)
+	RunExists(ctx context.Context, runID string) (bool, error)
+	// RunProjectID returns "" for runs outside any project.
+	RunProjectID(ctx context.Context, runID string) (string, error)
+	GetRunSummary(ctx context.Context, runID string) (RunSummary, error)
+	ListRunSummaries(ctx context.Context, filter RunSummaryFilter) ([]RunSummary, error)
+	// InsertRunEvent returns the new event id, or ErrNotFound when the run
+	// does not exist.
+	InsertRunEvent(ctx context.Context, event RunEventRecord) (int64, error)
+	ListRunEvents(ctx context.Context, filter RunEventFilter) ([]RunEventRecord, error)
+	// InsertRunStateEvent reports false when the run already has an event
+	// for the status.
+	InsertRunStateEvent(ctx context.Context, event RunStateEventRecord) (bool, error)
+	GetRunDatasetGate(ctx context.Context, datasetVersionID string) (RunDatasetGate, error)
+}
+
+type MetricStore interface {
+	// InsertSample reports false when the run already has a sample for the
+	// same name and step.
+	InsertSample(ctx context.Context, sample MetricSampleRecord) (bool, error)
+	ListSamples(ctx context.Context, filter MetricSampleFilter) ([]MetricSampleRecord, error)
+}
+
+type PolicyStore interface {
+	ListPolicies(ctx context.Context, limit int) ([]PolicyRecord, error)
+	GetPolicy(ctx context.Context, policyID string) (PolicyRecord, error)
+	// CreatePolicy returns ErrConflict when the name is taken.
+	CreatePolicy(ctx context.Context, policy PolicyRecord) error
+	CreatePolicyVersion(ctx context.Context, version PolicyVersionRecord) error
+	ListPolicyVersions(ctx context.Context, policyID string, limit int) ([]PolicyVersionRecord, error)
+	ListPolicyDecisions(ctx context.Context, filter PolicyDecisionFilter) ([]PolicyDecisionRecord, error)
+	GetPolicyDecision(ctx context.Context, decisionID string) (PolicyDecisionRecord, error)
+	ListPolicyApprovals(ctx context.Context, filter PolicyApprovalFilter) ([]PolicyApprovalRecord, error)
+	GetPolicyApproval(ctx context.Context, approvalID string) (PolicyApprovalRecord, error)
+	ListPolicyApprovalVotes(ctx context.Context, approvalID string) ([]PolicyApprovalVoteRecord, error)
+	CountRunApprovals(ctx context.Context, runID, status string) (int, error)
+}
+
+type EvidenceStore interface {
+	CreateEvidenceBundle(ctx context.Context, bundle EvidenceBundleRecord) error
+	GetEvidenceBundle(ctx context.Context, runID, bundleID string) (EvidenceBundleRecord, error)
+	ListEvidenceBundles(ctx context.Context, runID string, limit int) ([]EvidenceBundleRecord, error)
+}
@@ -11,9 +11,10 @@ import (
 var ErrInvalidTransition = errors.New("invalid run state transition")
 
 type ProjectFilter struct {
-	Name      string
-	CreatedBy string
-	Limit     int
+	Name            string
+	CreatedBy       string
+	IncludeArchived bool
+	Limit           int
 }
 
 type DatasetFilter struct {
@@ -84,6 +85,28 @@ type RoleBindingRecord struct {
 	IntegritySHA string
 }
 
+type RBACRoleRecord struct {
+	Name         string
+	Description  string
+	Permissions  []string
+	CreatedAt    time.Time
+	CreatedBy    string
+	UpdatedAt    time.Time
+	UpdatedBy    string
+	IntegritySHA string
+}
+
+type RBACRoutePermissionRecord struct {
+	RouteID      string
+	Service      string
+	Method       string
+	PathPattern  string
+	Permission   string
+	CreatedAt    time.Time
+	CreatedBy    string
+	IntegritySHA string
+}
+
 type SessionRecord struct {
 	SessionID     string
 	Subject       string
@@ -100,6 +123,53 @@ type SessionRecord struct {
 	UserAgent     string
 	IP            string
 	Metadata      domain.Metadata
+	RefreshToken  SealedRefreshToken
+	RefreshedAt   *time.Time
+}
+
+// SealedRefreshToken is an OIDC refresh token encrypted with its own
+// envelope data key. The zero value means the session has none.
+type SealedRefreshToken struct {
+	Ciphertext []byte
+	Algorithm  string
+	KeyID      string
+	WrappedKey string
+}
+
+type ServiceAccountRecord struct {
+	AccountID   string
+	Name        string
+	Description string
+	CreatedAt   time.Time
+	CreatedBy   string
+}
+
+type ServiceAccountTokenRecord struct {
+	TokenID      string
+	AccountID    string
+	Name         string
+	TokenSHA256  string
+	Role         string
+	ProjectID    string
+	CreatedAt    time.Time
+	CreatedBy    string
+	ExpiresAt    time.Time
+	LastUsedAt   *time.Time
+	RevokedAt    *time.Time
+	RevokedBy    string
+	RevokeReason string
+	RotatedFrom  string
+}
+
+// RunTokenRevocationRecord blocks a single run token (TokenSHA256 set) or every
+// token of the run issued at or before RevokedAt (TokenSHA256 empty).
+type RunTokenRevocationRecord struct {
+	RevocationID string
+	RunID        string
+	TokenSHA256  string
+	Reason       string
+	RevokedAt    time.Time
+	RevokedBy    string
 }
 
 type PlanRecord struct {
@@ -130,6 +200,8 @@ type ProjectRepository interface {
 	Create(ctx context.Context, project domain.Project) error
 	Get(ctx context.Context, id string) (domain.Project, error)
 	List(ctx context.Context, filter ProjectFilter) ([]domain.Project, error)
+	Update(ctx context.Context, project domain.Project) error
+	Archive(ctx context.Context, id string, archivedBy string, archivedAt time.Time) error
 }
 
 // DatasetRepository manages datasets and immutable versions.
@@ -197,15 +269,45 @@ type RoleBindingRepository interface {
 	Delete(ctx context.Context, projectID, bindingID string) error
 }
 
+// RBACPermissionRepository manages custom roles and route permission mappings.
+type RBACPermissionRepository interface {
+	CreateRole(ctx context.Context, record RBACRoleRecord) (RBACRoleRecord, error)
+	UpdateRole(ctx context.Context, record RBACRoleRecord) (RBACRoleRecord, error)
+	GetRole(ctx context.Context, name string) (RBACRoleRecord, error)
+	ListRoles(ctx context.Context) ([]RBACRoleRecord, error)
+	DeleteRole(ctx context.Context, name string) error
+	CreateRoutePermission(ctx context.Context, record RBACRoutePermissionRecord) (RBACRoutePermissionRecord, error)
+	ListRoutePermissions(ctx context.Context, service string) ([]RBACRoutePermissionRecord, error)
+	DeleteRoutePermission(ctx context.Context, routeID string) error
+}
+
 type SessionRepository interface {
 	Create(ctx context.Context, record SessionRecord) error
 	Get(ctx context.Context, sessionID string) (SessionRecord, error)
 	ListActiveBySubject(ctx context.Context, subject string, limit int) ([]SessionRecord, error)
 	UpdateLastSeen(ctx context.Context, sessionID string, at time.Time) error
+	UpdateRefresh(ctx context.Context, sessionID string, expiresAt time.Time, token SealedRefreshToken, at time.Time) (bool, error)
 	Revoke(ctx context.Context, sessionID, revokedBy, reason string, at time.Time) (bool, error)
 	RevokeBySubject(ctx context.Context, subject, revokedBy, reason string, at time.Time) (int, error)
 }
 
+type ServiceAccountRepository interface {
+	CreateAccount(ctx context.Context, record ServiceAccountRecord) error
+	GetAccount(ctx context.Context, accountID string) (ServiceAccountRecord, error)
+	ListAccounts(ctx context.Context) ([]ServiceAccountRecord, error)
+	CreateToken(ctx context.Context, record ServiceAccountTokenRecord) error
+	GetToken(ctx context.Context, tokenID string) (ServiceAccountTokenRecord, error)
+	ListTokens(ctx context.Context, accountID string) ([]ServiceAccountTokenRecord, error)
+	TouchToken(ctx context.Context, tokenID string, at time.Time) error
+	RevokeToken(ctx context.Context, tokenID, revokedBy, reason string, at time.Time) (bool, error)
+}
+
+type RunTokenRevocationRepository interface {
+	Create(ctx context.Context, record RunTokenRevocationRecord) error
+	List(ctx context.Context, runID string, limit int) ([]RunTokenRevocationRecord, error)
+	IsRevoked(ctx context.Context, runID, tokenSHA256 string, issuedAt time.Time) (bool, error)
+}
+
 // AuditEventAppender ensures append-only audit writes.
 type AuditEventAppender interface {
 	Append(ctx context.Context, event domain.AuditEvent) (int64, error)
@@ -0,0 +1,144 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	selectArtifactBlobProjectQuery = `SELECT EXISTS (SELECT 1 FROM artifact_blob_projects WHERE project_id = $1 AND sha256 = $2)`
+	insertArtifactBlobQuery        = `INSERT INTO artifact_blobs (sha256, object_key, size_bytes, ref_count, created_at, last_referenced_at)
+		VALUES ($1, $2, $3, 0, $4, $4)
+		ON CONFLICT (sha256) DO NOTHING`
+	lockArtifactBlobQuery          = `SELECT object_key, size_bytes FROM artifact_blobs WHERE sha256 = $1 FOR UPDATE`
+	referenceArtifactBlobQuery     = `UPDATE artifact_blobs SET ref_count = ref_count + 1, last_referenced_at = $2 WHERE sha256 = $1`
+	insertArtifactBlobProjectQuery = `INSERT INTO artifact_blob_projects (project_id, sha256, created_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (project_id, sha256) DO NOTHING`
+	releaseArtifactBlobQuery = `UPDATE artifact_blobs
+		SET ref_count = GREATEST(ref_count - 1, 0), last_referenced_at = $2
+		WHERE sha256 = $1`
+	lockCollectableArtifactBlobQuery = `SELECT sha256, object_key
+		FROM artifact_blobs
+		WHERE ref_count = 0 AND last_referenced_at < $1
+		ORDER BY last_referenced_at ASC
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED`
+	deleteArtifactBlobQuery = `DELETE FROM artifact_blobs WHERE sha256 = $1`
+)
+
+// ArtifactBlobStore keeps the reference-counted, content-addressed blobs
+// artifacts point at and the projects that referenced each. Row locks taken
+// here last until the caller's transaction ends.
+type ArtifactBlobStore struct {
+	db DB
+}
+
+func NewArtifactBlobStore(db DB) *ArtifactBlobStore {
+	if db == nil {
+		return nil
+	}
+	return &ArtifactBlobStore{db: db}
+}
+
+// ProjectHolds reports whether projectID has referenced the blob before.
+func (s *ArtifactBlobStore) ProjectHolds(ctx context.Context, projectID, sha256Hex string) (bool, error) {
+	if s == nil || s.db == nil {
+		return false, fmt.Errorf("artifact blob store not initialized")
+	}
+	var held bool
+	if err := s.db.QueryRowContext(ctx, selectArtifactBlobProjectQuery, strings.TrimSpace(projectID), sha256Hex).Scan(&held); err != nil {
+		return false, fmt.Errorf("select artifact blob project: %w", err)
+	}
+	return held, nil
+}
+
+// Ensure creates the blob row with no references unless it exists.
+func (s *ArtifactBlobStore) Ensure(ctx context.Context, sha256Hex, objectKey string, sizeBytes int64, now time.Time) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("artifact blob store not initialized")
+	}
+	if _, err := s.db.ExecContext(ctx, insertArtifactBlobQuery, sha256Hex, objectKey, sizeBytes, normalizeTime(now)); err != nil {
+		return fmt.Errorf("insert artifact blob: %w", err)
+	}
+	return nil
+}
+
+// Lock locks the blob row and returns its object key and size; a missing
+// blob is repo.ErrNotFound.
+func (s *ArtifactBlobStore) Lock(ctx context.Context, sha256Hex string) (string, int64, error) {
+	if s == nil || s.db == nil {
+		return "", 0, fmt.Errorf("artifact blob store not initialized")
+	}
+	var (
+		objectKey string
+		sizeBytes int64
+	)
+	err := s.db.QueryRowContext(ctx, lockArtifactBlobQuery, sha256Hex).Scan(&objectKey, &sizeBytes)
+	if err != nil {
+		return "", 0, handleNotFound(err)
+	}
+	return objectKey, sizeBytes, nil
+}
+
+// Reference adds a reference to the blob and, for a non-empty projectID,
+// records that the project holds it.
+func (s *ArtifactBlobStore) Reference(ctx context.Context, projectID, sha256Hex string, now time.Time) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("artifact blob store not initialized")
+	}
+	now = normalizeTime(now)
+	if _, err := s.db.ExecContext(ctx, referenceArtifactBlobQuery, sha256Hex, now); err != nil {
+		return fmt.Errorf("reference artifact blob: %w", err)
+	}
+	projectID = strings.TrimSpace(projectID)
+	if projectID == "" {
+		return nil
+	}
+	if _, err := s.db.ExecContext(ctx, insertArtifactBlobProjectQuery, projectID, sha256Hex, now); err != nil {
+		return fmt.Errorf("insert artifact blob project: %w", err)
+	}
+	return nil
+}
+
+// Release drops one reference to the blob.
+func (s *ArtifactBlobStore) Release(ctx context.Context, sha256Hex string, now time.Time) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("artifact blob store not initialized")
+	}
+	if _, err := s.db.ExecContext(ctx, releaseArtifactBlobQuery, sha256Hex, normalizeTime(now)); err != nil {
+		return fmt.Errorf("release artifact blob: %w", err)
+	}
+	return nil
+}
+
+// LockCollectable locks the blob unreferenced the longest, if that was
+// before cutoff, skipping blobs another transaction holds; ok is false when
+// there is none.
+func (s *ArtifactBlobStore) LockCollectable(ctx context.Context, cutoff time.Time) (sha256Hex, objectKey string, ok bool, err error) {
+	if s == nil || s.db == nil {
+		return "", "", false, fmt.Errorf("artifact blob store not initialized")
+	}
+	err = s.db.QueryRowContext(ctx, lockCollectableArtifactBlobQuery, cutoff).Scan(&sha256Hex, &objectKey)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", "", false, nil
+	}
+	if err != nil {
+		return "", "", false, fmt.Errorf("select collectable artifact blob: %w", err)
+	}
+	return sha256Hex, objectKey, true, nil
+}
+
+func (s *ArtifactBlobStore) Delete(ctx context.Context, sha256Hex string) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("artifact blob store not initialized")
+	}
+	if _, err := s.db.ExecContext(ctx, deleteArtifactBlobQuery, sha256Hex); err != nil {
+		return fmt.Errorf("delete artifact blob: %w", err)
+	}
+	return nil
+}
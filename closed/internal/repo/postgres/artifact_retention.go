@@ -0,0 +1,338 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+)
+
+const (
+	artifactRetentionPolicyColumns     = `p.scope_type, p.scope_id, p.project_id, p.keep_last_runs, p.keep_best_metric, p.keep_best_order, p.keep_best_runs, p.updated_at, p.updated_by`
+	selectArtifactRetentionPolicyQuery = `SELECT ` + artifactRetentionPolicyColumns + `
+		FROM artifact_retention_policies p
+		WHERE p.scope_type = $1 AND p.scope_id = $2`
+	selectEffectiveArtifactRetentionPolicyQuery = `SELECT ` + artifactRetentionPolicyColumns + `
+		FROM experiments e
+		JOIN artifact_retention_policies p
+		  ON (p.scope_type = 'experiment' AND p.scope_id = e.experiment_id)
+		  OR (p.scope_type = 'project' AND p.scope_id = e.project_id)
+		WHERE e.experiment_id = $1
+		ORDER BY (p.scope_type = 'experiment') DESC
+		LIMIT 1`
+	upsertArtifactRetentionPolicyQuery = `INSERT INTO artifact_retention_policies (
+			scope_type, scope_id, project_id, keep_last_runs, keep_best_metric, keep_best_order, keep_best_runs, updated_at, updated_by
+		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
+		ON CONFLICT (scope_type, scope_id) DO UPDATE
+		SET keep_last_runs = EXCLUDED.keep_last_runs,
+			keep_best_metric = EXCLUDED.keep_best_metric,
+			keep_best_order = EXCLUDED.keep_best_order,
+			keep_best_runs = EXCLUDED.keep_best_runs,
+			updated_at = EXCLUDED.updated_at,
+			updated_by = EXCLUDED.updated_by`
+	deleteArtifactRetentionPolicyQuery = `DELETE FROM artifact_retention_policies WHERE scope_type = $1 AND scope_id = $2 RETURNING project_id`
+	selectRetainedExperimentsQuery     = `SELECT e.experiment_id
+		FROM experiments e
+		WHERE EXISTS (
+			SELECT 1 FROM artifact_retention_policies p
+			WHERE (p.scope_type = 'experiment' AND p.scope_id = e.experiment_id)
+			   OR (p.scope_type = 'project' AND p.scope_id = e.project_id)
+		)
+		ORDER BY e.experiment_id`
+	selectRetentionRunsQuery = `SELECT r.run_id,
+			r.started_at,
+			COALESCE(s.status, r.status) AS status,
+			EXISTS (SELECT 1 FROM model_versions mv WHERE mv.run_id = r.run_id)
+			  OR EXISTS (SELECT 1 FROM experiment_run_evidence_bundles b WHERE b.run_id = r.run_id) AS referenced,
+			CASE WHEN $2 = '' THEN NULL ELSE COALESCE(
+				m.value,
+				CASE WHEN jsonb_typeof(r.metrics -> $2) = 'number' THEN (r.metrics ->> $2)::double precision END
+			) END AS value
+		FROM experiment_runs r
+		LEFT JOIN LATERAL (
+			SELECT status
+			FROM experiment_run_state_events
+			WHERE run_id = r.run_id
+			ORDER BY observed_at DESC
+			LIMIT 1
+		) s ON true
+		LEFT JOIN LATERAL (
+			SELECT value
+			FROM experiment_run_metric_samples
+			WHERE run_id = r.run_id AND name = $2
+			ORDER BY step DESC
+			LIMIT 1
+		) m ON true
+		WHERE r.experiment_id = $1`
+	selectExpiredArtifactsQuery = `SELECT a.artifact_id, a.run_id, a.kind, a.name, a.object_key, a.sha256, a.size_bytes, a.blob_sha256
+		FROM experiment_run_artifacts a
+		WHERE a.run_id IN (SELECT jsonb_array_elements_text($1::jsonb))
+		  AND NOT EXISTS (SELECT 1 FROM model_version_artifacts mva WHERE mva.artifact_id = a.artifact_id)
+		  AND NOT EXISTS (SELECT 1 FROM model_versions mv WHERE mv.artifact_ids ? a.artifact_id)
+		  AND NOT EXISTS (SELECT 1 FROM run_checkpoints c WHERE c.artifact_id = a.artifact_id)
+		ORDER BY a.created_at ASC, a.artifact_id
+		LIMIT $2`
+	deleteRunArtifactQuery = `DELETE FROM experiment_run_artifacts WHERE artifact_id = $1`
+)
+
+// ArtifactRetentionStore keeps project and experiment artifact retention
+// policies and finds the artifacts they expire.
+type ArtifactRetentionStore struct {
+	db DB
+}
+
+func NewArtifactRetentionStore(db DB) *ArtifactRetentionStore {
+	if db == nil {
+		return nil
+	}
+	return &ArtifactRetentionStore{db: db}
+}
+
+// ArtifactRetentionPolicyRecord is a policy row. KeepBestMetric is empty
+// when the policy has no keep-best rule.
+type ArtifactRetentionPolicyRecord struct {
+	ScopeType      string
+	ScopeID        string
+	ProjectID      string
+	KeepLastRuns   *int
+	KeepBestMetric string
+	KeepBestOrder  string
+	KeepBestRuns   int
+	UpdatedAt      time.Time
+	UpdatedBy      string
+}
+
+// ArtifactRetentionRun is a run as retention weighs it. Status takes the
+// latest state event into account; MetricValue is the last sample of the
+// keep-best metric, or its value in the run's metrics.
+type ArtifactRetentionRun struct {
+	RunID       string
+	StartedAt   time.Time
+	Status      string
+	Referenced  bool
+	MetricValue *float64
+}
+
+type ArtifactRetentionCandidate struct {
+	ArtifactID string
+	RunID      string
+	Kind       string
+	Name       string
+	ObjectKey  string
+	SHA256     string
+	SizeBytes  int64
+	// BlobSHA256 is empty for artifacts stored before content addressing.
+	BlobSHA256 string
+}
+
+func (s *ArtifactRetentionStore) GetPolicy(ctx context.Context, scopeType, scopeID string) (ArtifactRetentionPolicyRecord, error) {
+	if s == nil || s.db == nil {
+		return ArtifactRetentionPolicyRecord{}, fmt.Errorf("artifact retention store not initialized")
+	}
+	record, err := scanArtifactRetentionPolicy(s.db.QueryRowContext(ctx, selectArtifactRetentionPolicyQuery, scopeType, scopeID))
+	if err != nil {
+		return ArtifactRetentionPolicyRecord{}, handleNotFound(err)
+	}
+	return record, nil
+}
+
+// EffectivePolicy is the experiment's own policy, or its project's policy
+// when it has none; repo.ErrNotFound when neither exists.
+func (s *ArtifactRetentionStore) EffectivePolicy(ctx context.Context, experimentID string) (ArtifactRetentionPolicyRecord, error) {
+	if s == nil || s.db == nil {
+		return ArtifactRetentionPolicyRecord{}, fmt.Errorf("artifact retention store not initialized")
+	}
+	record, err := scanArtifactRetentionPolicy(s.db.QueryRowContext(ctx, selectEffectiveArtifactRetentionPolicyQuery, experimentID))
+	if err != nil {
+		return ArtifactRetentionPolicyRecord{}, handleNotFound(err)
+	}
+	return record, nil
+}
+
+// UpsertPolicy creates or replaces the policy of its scope; a missing
+// project is repo.ErrNotFound.
+func (s *ArtifactRetentionStore) UpsertPolicy(ctx context.Context, record ArtifactRetentionPolicyRecord) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("artifact retention store not initialized")
+	}
+	var (
+		keepLast   sql.NullInt64
+		bestMetric sql.NullString
+		bestOrder  sql.NullString
+		bestRuns   sql.NullInt64
+	)
+	if record.KeepLastRuns != nil {
+		keepLast = sql.NullInt64{Int64: int64(*record.KeepLastRuns), Valid: true}
+	}
+	if record.KeepBestMetric != "" {
+		bestMetric = sql.NullString{String: record.KeepBestMetric, Valid: true}
+		bestOrder = sql.NullString{String: record.KeepBestOrder, Valid: true}
+		bestRuns = sql.NullInt64{Int64: int64(record.KeepBestRuns), Valid: true}
+	}
+	_, err := s.db.ExecContext(
+		ctx,
+		upsertArtifactRetentionPolicyQuery,
+		record.ScopeType,
+		record.ScopeID,
+		record.ProjectID,
+		keepLast,
+		bestMetric,
+		bestOrder,
+		bestRuns,
+		normalizeTime(record.UpdatedAt),
+		record.UpdatedBy,
+	)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return repo.ErrNotFound
+		}
+		return fmt.Errorf("upsert artifact retention policy: %w", err)
+	}
+	return nil
+}
+
+// DeletePolicy removes the policy of a scope and returns its project.
+func (s *ArtifactRetentionStore) DeletePolicy(ctx context.Context, scopeType, scopeID string) (string, error) {
+	if s == nil || s.db == nil {
+		return "", fmt.Errorf("artifact retention store not initialized")
+	}
+	var projectID string
+	if err := s.db.QueryRowContext(ctx, deleteArtifactRetentionPolicyQuery, scopeType, scopeID).Scan(&projectID); err != nil {
+		return "", handleNotFound(err)
+	}
+	return projectID, nil
+}
+
+// ListRetainedExperiments lists the experiments a policy applies to.
+func (s *ArtifactRetentionStore) ListRetainedExperiments(ctx context.Context) ([]string, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("artifact retention store not initialized")
+	}
+	rows, err := s.db.QueryContext(ctx, selectRetainedExperimentsQuery)
+	if err != nil {
+		return nil, fmt.Errorf("list retained experiments: %w", err)
+	}
+	defer rows.Close()
+
+	out := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan retained experiment: %w", err)
+		}
+		out = append(out, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate retained experiments: %w", err)
+	}
+	return out, nil
+}
+
+// ListRuns lists the runs of an experiment with metric's value, or none
+// when metric is empty.
+func (s *ArtifactRetentionStore) ListRuns(ctx context.Context, experimentID, metric string) ([]ArtifactRetentionRun, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("artifact retention store not initialized")
+	}
+	rows, err := s.db.QueryContext(ctx, selectRetentionRunsQuery, experimentID, metric)
+	if err != nil {
+		return nil, fmt.Errorf("list retention runs: %w", err)
+	}
+	defer rows.Close()
+
+	out := []ArtifactRetentionRun{}
+	for rows.Next() {
+		var (
+			run   ArtifactRetentionRun
+			value sql.NullFloat64
+		)
+		if err := rows.Scan(&run.RunID, &run.StartedAt, &run.Status, &run.Referenced, &value); err != nil {
+			return nil, fmt.Errorf("scan retention run: %w", err)
+		}
+		if value.Valid {
+			v := value.Float64
+			run.MetricValue = &v
+		}
+		out = append(out, run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate retention runs: %w", err)
+	}
+	return out, nil
+}
+
+// ListExpiredArtifacts lists up to limit artifacts of runIDs, oldest first,
+// leaving out artifacts a model version names directly and checkpoints a
+// run resumes from.
+func (s *ArtifactRetentionStore) ListExpiredArtifacts(ctx context.Context, runIDs []string, limit int) ([]ArtifactRetentionCandidate, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("artifact retention store not initialized")
+	}
+	if len(runIDs) == 0 {
+		return []ArtifactRetentionCandidate{}, nil
+	}
+	runIDsJSON, err := json.Marshal(runIDs)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.db.QueryContext(ctx, selectExpiredArtifactsQuery, runIDsJSON, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list expired artifacts: %w", err)
+	}
+	defer rows.Close()
+
+	out := []ArtifactRetentionCandidate{}
+	for rows.Next() {
+		var (
+			artifact   ArtifactRetentionCandidate
+			name       sql.NullString
+			blobSHA256 sql.NullString
+		)
+		if err := rows.Scan(&artifact.ArtifactID, &artifact.RunID, &artifact.Kind, &name, &artifact.ObjectKey, &artifact.SHA256, &artifact.SizeBytes, &blobSHA256); err != nil {
+			return nil, fmt.Errorf("scan expired artifact: %w", err)
+		}
+		artifact.Name = strings.TrimSpace(name.String)
+		artifact.BlobSHA256 = blobSHA256.String
+		out = append(out, artifact)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate expired artifacts: %w", err)
+	}
+	return out, nil
+}
+
+func (s *ArtifactRetentionStore) DeleteArtifact(ctx context.Context, artifactID string) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("artifact retention store not initialized")
+	}
+	if _, err := s.db.ExecContext(ctx, deleteRunArtifactQuery, artifactID); err != nil {
+		return fmt.Errorf("delete run artifact: %w", err)
+	}
+	return nil
+}
+
+func scanArtifactRetentionPolicy(row interface{ Scan(...any) error }) (ArtifactRetentionPolicyRecord, error) {
+	var (
+		record     ArtifactRetentionPolicyRecord
+		keepLast   sql.NullInt64
+		bestMetric sql.NullString
+		bestOrder  sql.NullString
+		bestRuns   sql.NullInt64
+	)
+	if err := row.Scan(&record.ScopeType, &record.ScopeID, &record.ProjectID, &keepLast, &bestMetric, &bestOrder, &bestRuns, &record.UpdatedAt, &record.UpdatedBy); err != nil {
+		return ArtifactRetentionPolicyRecord{}, err
+	}
+	if keepLast.Valid {
+		n := int(keepLast.Int64)
+		record.KeepLastRuns = &n
+	}
+	record.KeepBestMetric = bestMetric.String
+	record.KeepBestOrder = bestOrder.String
+	record.KeepBestRuns = int(bestRuns.Int64)
+	record.UpdatedAt = record.UpdatedAt.UTC()
+	return record, nil
+}
@@ -4,11 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"time"
 
 	"github.com/animus-labs/animus-go/closed/internal/auditexport"
 	"github.com/animus-labs/animus-go/closed/internal/domain"
 	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
+	"github.com/animus-labs/animus-go/closed/internal/platform/buildinfo"
 )
 
 type AuditAppender struct {
@@ -34,10 +36,16 @@ func (a *AuditAppender) Append(ctx context.Context, event domain.AuditEvent) (in
 	if event.OccurredAt.IsZero() {
 		event.OccurredAt = a.now().UTC()
 	}
-	payload := event.Payload
+	// Stamp the build here rather than leaving it to auditlog.Insert so the
+	// exported copy carries it too.
+	payload := maps.Clone(event.Payload)
 	if payload == nil {
 		payload = domain.Metadata{}
 	}
+	if _, ok := payload[auditlog.BuildVersionKey]; !ok {
+		payload[auditlog.BuildVersionKey] = buildinfo.Get().Version
+	}
+	event.Payload = payload
 	id, err := auditlog.Insert(ctx, a.db, auditlog.Event{
 		OccurredAt:   event.OccurredAt,
 		Actor:        event.Actor,
@@ -36,7 +36,8 @@ const (
 	) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15)`
 
 	selectSessionQuery = `SELECT session_id, subject, email, roles, issuer, created_at, expires_at,
-		last_seen_at, revoked_at, revoked_by, revoke_reason, id_token_sha256, user_agent, ip, metadata
+		last_seen_at, revoked_at, revoked_by, revoke_reason, id_token_sha256, user_agent, ip, metadata,
+		refresh_token_ciphertext, refresh_token_alg, refresh_token_key_id, refresh_token_wrapped_key, refreshed_at
 		FROM auth_sessions WHERE session_id = $1`
 )
 
@@ -140,7 +141,8 @@ func (s *SessionStore) ListActiveBySubject(ctx context.Context, subject string,
 	rows, err := s.db.QueryContext(
 		ctx,
 		`SELECT session_id, subject, email, roles, issuer, created_at, expires_at,
-			last_seen_at, revoked_at, revoked_by, revoke_reason, id_token_sha256, user_agent, ip, metadata
+			last_seen_at, revoked_at, revoked_by, revoke_reason, id_token_sha256, user_agent, ip, metadata,
+			refresh_token_ciphertext, refresh_token_alg, refresh_token_key_id, refresh_token_wrapped_key, refreshed_at
 		 FROM auth_sessions
 		 WHERE subject = $1 AND revoked_at IS NULL AND expires_at > $2
 		 ORDER BY created_at ASC
@@ -191,6 +193,54 @@ func (s *SessionStore) UpdateLastSeen(ctx context.Context, sessionID string, at
 	return nil
 }
 
+// UpdateRefresh extends an active session and replaces its sealed refresh
+// token. It reports false when the session is revoked or unknown.
+func (s *SessionStore) UpdateRefresh(ctx context.Context, sessionID string, expiresAt time.Time, token repo.SealedRefreshToken, at time.Time) (bool, error) {
+	if s == nil || s.db == nil {
+		return false, fmt.Errorf("session store not initialized")
+	}
+	sessionID = strings.TrimSpace(sessionID)
+	if sessionID == "" {
+		return false, fmt.Errorf("session_id is required")
+	}
+	if expiresAt.IsZero() {
+		return false, fmt.Errorf("expires_at is required")
+	}
+	if at.IsZero() {
+		at = time.Now().UTC()
+	}
+	var ciphertext []byte
+	if len(token.Ciphertext) > 0 {
+		ciphertext = token.Ciphertext
+	}
+	res, err := s.db.ExecContext(
+		ctx,
+		`UPDATE auth_sessions
+		 SET expires_at = $1,
+		     refresh_token_ciphertext = $2,
+		     refresh_token_alg = $3,
+		     refresh_token_key_id = $4,
+		     refresh_token_wrapped_key = $5,
+		     refreshed_at = $6
+		 WHERE session_id = $7 AND revoked_at IS NULL`,
+		expiresAt.UTC(),
+		ciphertext,
+		nullIfEmpty(token.Algorithm),
+		nullIfEmpty(token.KeyID),
+		nullIfEmpty(token.WrappedKey),
+		at.UTC(),
+		sessionID,
+	)
+	if err != nil {
+		return false, fmt.Errorf("update session refresh: %w", err)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("update session refresh: %w", err)
+	}
+	return rows > 0, nil
+}
+
 func (s *SessionStore) Revoke(ctx context.Context, sessionID, revokedBy, reason string, at time.Time) (bool, error) {
 	if s == nil || s.db == nil {
 		return false, fmt.Errorf("session store not initialized")
@@ -279,6 +329,12 @@ func scanSession(row sessionScanner) (repo.SessionRecord, error) {
 		userAgent sql.NullString
 		ip        sql.NullString
 		metadata  []byte
+
+		refreshCiphertext []byte
+		refreshAlg        sql.NullString
+		refreshKeyID      sql.NullString
+		refreshWrappedKey sql.NullString
+		refreshedAt       sql.NullTime
 	)
 	if err := row.Scan(
 		&record.SessionID,
@@ -296,6 +352,11 @@ func scanSession(row sessionScanner) (repo.SessionRecord, error) {
 		&userAgent,
 		&ip,
 		&metadata,
+		&refreshCiphertext,
+		&refreshAlg,
+		&refreshKeyID,
+		&refreshWrappedKey,
+		&refreshedAt,
 	); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return repo.SessionRecord{}, repo.ErrNotFound
@@ -331,6 +392,18 @@ func scanSession(row sessionScanner) (repo.SessionRecord, error) {
 	if ip.Valid {
 		record.IP = strings.TrimSpace(ip.String)
 	}
+	if len(refreshCiphertext) > 0 {
+		record.RefreshToken = repo.SealedRefreshToken{
+			Ciphertext: refreshCiphertext,
+			Algorithm:  strings.TrimSpace(refreshAlg.String),
+			KeyID:      strings.TrimSpace(refreshKeyID.String),
+			WrappedKey: strings.TrimSpace(refreshWrappedKey.String),
+		}
+	}
+	if refreshedAt.Valid {
+		t := refreshedAt.Time.UTC()
+		record.RefreshedAt = &t
+	}
 	if len(rolesRaw) > 0 {
 		var roles []string
 		if err := json.Unmarshal(rolesRaw, &roles); err == nil {
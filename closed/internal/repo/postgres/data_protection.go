@@ -0,0 +1,86 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/animus-labs/animus-go/closed/internal/domain"
+)
+
+// DataProtectionStore reads the data protection posture of dataset versions
+// for the gates in dataset-registry and experiments.
+type DataProtectionStore struct {
+	db DB
+}
+
+const selectDataProtectionQuery = `SELECT v.encryption_key_id,
+		p.classification_labels,
+		p.residency,
+		rc.dataset_id,
+		rc.reason,
+		rc.recalled_at,
+		rc.recalled_by
+	 FROM dataset_versions v
+	 LEFT JOIN dataset_protection p ON p.dataset_id = v.dataset_id
+	 LEFT JOIN dataset_version_recalls rc ON rc.version_id = v.version_id
+	 WHERE v.version_id = $1`
+
+func NewDataProtectionStore(db DB) *DataProtectionStore {
+	if db == nil {
+		return nil
+	}
+	return &DataProtectionStore{db: db}
+}
+
+// Get returns repo.ErrNotFound when the version does not exist.
+func (s *DataProtectionStore) Get(ctx context.Context, versionID string) (domain.DataProtection, error) {
+	if s == nil || s.db == nil {
+		return domain.DataProtection{}, fmt.Errorf("data protection store not initialized")
+	}
+	versionID = strings.TrimSpace(versionID)
+	var (
+		keyID         sql.NullString
+		labels        []byte
+		residency     sql.NullString
+		recallDataset sql.NullString
+		recallReason  sql.NullString
+		recalledAt    sql.NullTime
+		recalledBy    sql.NullString
+	)
+	err := s.db.QueryRowContext(ctx, selectDataProtectionQuery, versionID).Scan(
+		&keyID,
+		&labels,
+		&residency,
+		&recallDataset,
+		&recallReason,
+		&recalledAt,
+		&recalledBy,
+	)
+	if err != nil {
+		return domain.DataProtection{}, handleNotFound(err)
+	}
+
+	out := domain.DataProtection{
+		Encrypted:       strings.TrimSpace(keyID.String) != "",
+		EncryptionKeyID: strings.TrimSpace(keyID.String),
+		Residency:       strings.TrimSpace(residency.String),
+	}
+	if len(labels) > 0 {
+		if err := json.Unmarshal(labels, &out.ClassificationLabels); err != nil {
+			return domain.DataProtection{}, fmt.Errorf("decode classification labels: %w", err)
+		}
+	}
+	if recalledAt.Valid {
+		out.Recall = &domain.DatasetVersionRecall{
+			VersionID:  versionID,
+			DatasetID:  recallDataset.String,
+			Reason:     recallReason.String,
+			RecalledAt: recalledAt.Time.UTC(),
+			RecalledBy: recalledBy.String,
+		}
+	}
+	return out, nil
+}
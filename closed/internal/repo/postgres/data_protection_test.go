@@ -0,0 +1,14 @@
+package postgres
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDataProtectionQueryKeepsVersionsWithoutPosture(t *testing.T) {
+	for _, join := range []string{"LEFT JOIN dataset_protection", "LEFT JOIN dataset_version_recalls"} {
+		if !strings.Contains(selectDataProtectionQuery, join) {
+			t.Fatalf("expected %q in query: %s", join, selectDataProtectionQuery)
+		}
+	}
+}
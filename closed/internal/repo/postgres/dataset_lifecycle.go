@@ -0,0 +1,60 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/animus-labs/animus-go/closed/internal/domain"
+)
+
+// DatasetLifecycleStore reads dataset lifecycle states for the registry and
+// the run gate in experiments.
+type DatasetLifecycleStore struct {
+	db DB
+}
+
+const selectDatasetLifecycleQuery = `SELECT state, reason, updated_at, updated_by
+	 FROM dataset_lifecycle
+	 WHERE dataset_id = $1`
+
+func NewDatasetLifecycleStore(db DB) *DatasetLifecycleStore {
+	if db == nil {
+		return nil
+	}
+	return &DatasetLifecycleStore{db: db}
+}
+
+// Get reports datasets without a recorded transition as active.
+func (s *DatasetLifecycleStore) Get(ctx context.Context, datasetID string) (domain.DatasetLifecycle, error) {
+	if s == nil || s.db == nil {
+		return domain.DatasetLifecycle{}, fmt.Errorf("dataset lifecycle store not initialized")
+	}
+	datasetID = strings.TrimSpace(datasetID)
+	var (
+		state     string
+		reason    sql.NullString
+		updatedAt sql.NullTime
+		updatedBy sql.NullString
+	)
+	err := s.db.QueryRowContext(ctx, selectDatasetLifecycleQuery, datasetID).Scan(&state, &reason, &updatedAt, &updatedBy)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.DatasetLifecycle{DatasetID: datasetID, State: domain.DatasetLifecycleActive}, nil
+		}
+		return domain.DatasetLifecycle{}, err
+	}
+	out := domain.DatasetLifecycle{
+		DatasetID: datasetID,
+		State:     domain.DatasetLifecycleState(state),
+		Reason:    reason.String,
+		UpdatedBy: updatedBy.String,
+	}
+	if updatedAt.Valid {
+		t := updatedAt.Time.UTC()
+		out.UpdatedAt = &t
+	}
+	return out, nil
+}
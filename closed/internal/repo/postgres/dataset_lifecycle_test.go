@@ -0,0 +1,15 @@
+package postgres
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDatasetLifecycleStoreRequiresDB(t *testing.T) {
+	if NewDatasetLifecycleStore(nil) != nil {
+		t.Fatalf("expected nil store without db")
+	}
+	if !strings.Contains(selectDatasetLifecycleQuery, "WHERE dataset_id = $1") {
+		t.Fatalf("unexpected query: %s", selectDatasetLifecycleQuery)
+	}
+}
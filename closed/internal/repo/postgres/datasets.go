@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"strings"
 
@@ -179,8 +180,11 @@ func (s *DatasetStore) CreateDatasetVersion(ctx context.Context, version domain.
 			metadata,
 			created_at,
 			created_by,
-			integrity_sha256
-		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12)`,
+			integrity_sha256,
+			encryption_alg,
+			encryption_key_id,
+			encryption_wrapped_key
+		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15)`,
 		strings.TrimSpace(version.ID),
 		strings.TrimSpace(version.DatasetID),
 		strings.TrimSpace(version.ProjectID),
@@ -193,6 +197,9 @@ func (s *DatasetStore) CreateDatasetVersion(ctx context.Context, version domain.
 		createdAt,
 		strings.TrimSpace(version.CreatedBy),
 		strings.TrimSpace(version.IntegritySHA256),
+		nullIfEmpty(version.Encryption.Algorithm),
+		nullIfEmpty(version.Encryption.KeyID),
+		nullIfEmpty(version.Encryption.WrappedKey),
 	)
 	if err != nil {
 		return fmt.Errorf("insert dataset version: %w", err)
@@ -214,15 +221,16 @@ func (s *DatasetStore) GetDatasetVersion(ctx context.Context, projectID, id stri
 	}
 	var version domain.DatasetVersion
 	var metadataJSON []byte
+	var encAlg, encKeyID, encWrappedKey sql.NullString
 	row := s.db.QueryRowContext(
 		ctx,
-		`SELECT version_id, dataset_id, project_id, quality_rule_id, ordinal, content_sha256, object_key, size_bytes, metadata, created_at, created_by, integrity_sha256
+		`SELECT version_id, dataset_id, project_id, quality_rule_id, ordinal, content_sha256, object_key, size_bytes, metadata, created_at, created_by, integrity_sha256, encryption_alg, encryption_key_id, encryption_wrapped_key
 		 FROM dataset_versions
 		 WHERE project_id = $1 AND version_id = $2`,
 		projectID,
 		id,
 	)
-	if err := row.Scan(&version.ID, &version.DatasetID, &version.ProjectID, &version.QualityRuleID, &version.Ordinal, &version.ContentSHA256, &version.ObjectKey, &version.SizeBytes, &metadataJSON, &version.CreatedAt, &version.CreatedBy, &version.IntegritySHA256); err != nil {
+	if err := row.Scan(&version.ID, &version.DatasetID, &version.ProjectID, &version.QualityRuleID, &version.Ordinal, &version.ContentSHA256, &version.ObjectKey, &version.SizeBytes, &metadataJSON, &version.CreatedAt, &version.CreatedBy, &version.IntegritySHA256, &encAlg, &encKeyID, &encWrappedKey); err != nil {
 		return domain.DatasetVersion{}, handleNotFound(err)
 	}
 	meta, err := decodeMetadata(metadataJSON)
@@ -230,6 +238,7 @@ func (s *DatasetStore) GetDatasetVersion(ctx context.Context, projectID, id stri
 		return domain.DatasetVersion{}, fmt.Errorf("decode metadata: %w", err)
 	}
 	version.Metadata = meta
+	version.Encryption = scanObjectEncryption(encAlg, encKeyID, encWrappedKey)
 	return version, nil
 }
 
@@ -252,7 +261,7 @@ func (s *DatasetStore) ListDatasetVersions(ctx context.Context, filter repo.Data
 		clauses = append(clauses, fmt.Sprintf("dataset_id = $%d", len(args)))
 	}
 
-	query := `SELECT version_id, dataset_id, project_id, quality_rule_id, ordinal, content_sha256, object_key, size_bytes, metadata, created_at, created_by, integrity_sha256 FROM dataset_versions`
+	query := `SELECT version_id, dataset_id, project_id, quality_rule_id, ordinal, content_sha256, object_key, size_bytes, metadata, created_at, created_by, integrity_sha256, encryption_alg, encryption_key_id, encryption_wrapped_key FROM dataset_versions`
 	if len(clauses) > 0 {
 		query += " WHERE " + strings.Join(clauses, " AND ")
 	}
@@ -272,7 +281,8 @@ func (s *DatasetStore) ListDatasetVersions(ctx context.Context, filter repo.Data
 	for rows.Next() {
 		var version domain.DatasetVersion
 		var metadataJSON []byte
-		if err := rows.Scan(&version.ID, &version.DatasetID, &version.ProjectID, &version.QualityRuleID, &version.Ordinal, &version.ContentSHA256, &version.ObjectKey, &version.SizeBytes, &metadataJSON, &version.CreatedAt, &version.CreatedBy, &version.IntegritySHA256); err != nil {
+		var encAlg, encKeyID, encWrappedKey sql.NullString
+		if err := rows.Scan(&version.ID, &version.DatasetID, &version.ProjectID, &version.QualityRuleID, &version.Ordinal, &version.ContentSHA256, &version.ObjectKey, &version.SizeBytes, &metadataJSON, &version.CreatedAt, &version.CreatedBy, &version.IntegritySHA256, &encAlg, &encKeyID, &encWrappedKey); err != nil {
 			return nil, fmt.Errorf("scan dataset version: %w", err)
 		}
 		meta, err := decodeMetadata(metadataJSON)
@@ -280,6 +290,7 @@ func (s *DatasetStore) ListDatasetVersions(ctx context.Context, filter repo.Data
 			return nil, fmt.Errorf("decode metadata: %w", err)
 		}
 		version.Metadata = meta
+		version.Encryption = scanObjectEncryption(encAlg, encKeyID, encWrappedKey)
 		versions = append(versions, version)
 	}
 	if err := rows.Err(); err != nil {
@@ -312,3 +323,78 @@ func (s *DatasetStore) NextDatasetVersionOrdinal(ctx context.Context, projectID,
 	}
 	return ordinal, nil
 }
+
+// DatasetProjectID returns the project of a dataset, or of a dataset
+// version when version is true.
+func (s *DatasetStore) DatasetProjectID(ctx context.Context, id string, version bool) (string, error) {
+	if s == nil || s.db == nil {
+		return "", fmt.Errorf("dataset store not initialized")
+	}
+	query := `SELECT project_id FROM datasets WHERE dataset_id = $1`
+	if version {
+		query = `SELECT project_id FROM dataset_versions WHERE version_id = $1`
+	}
+	var projectID sql.NullString
+	if err := s.db.QueryRowContext(ctx, query, strings.TrimSpace(id)).Scan(&projectID); err != nil {
+		return "", handleNotFound(err)
+	}
+	return projectID.String, nil
+}
+
+// LatestDatasetVersionID returns the newest version of a dataset; with
+// passingOnly, the newest one whose latest evaluation of its quality rule
+// passed. repo.ErrNotFound when no version qualifies.
+func (s *DatasetStore) LatestDatasetVersionID(ctx context.Context, datasetID string, passingOnly bool) (string, error) {
+	if s == nil || s.db == nil {
+		return "", fmt.Errorf("dataset store not initialized")
+	}
+	query := `SELECT version_id
+		FROM dataset_versions
+		WHERE dataset_id = $1
+		ORDER BY created_at DESC, ordinal DESC
+		LIMIT 1`
+	if passingOnly {
+		query = `SELECT v.version_id
+			FROM dataset_versions v
+			WHERE v.dataset_id = $1
+			  AND v.quality_rule_id IS NOT NULL
+			  AND (SELECT e.status
+			       FROM quality_evaluations e
+			       WHERE e.dataset_version_id = v.version_id AND e.rule_id = v.quality_rule_id
+			       ORDER BY e.evaluated_at DESC
+			       LIMIT 1) = 'pass'
+			ORDER BY v.created_at DESC, v.ordinal DESC
+			LIMIT 1`
+	}
+	var versionID string
+	if err := s.db.QueryRowContext(ctx, query, strings.TrimSpace(datasetID)).Scan(&versionID); err != nil {
+		return "", handleNotFound(err)
+	}
+	return versionID, nil
+}
+
+// DatasetVersionContent returns the dataset and content hash of a version
+// of projectID; a version of another project is repo.ErrNotFound.
+func (s *DatasetStore) DatasetVersionContent(ctx context.Context, projectID, versionID string) (datasetID, contentSHA256 string, err error) {
+	if s == nil || s.db == nil {
+		return "", "", fmt.Errorf("dataset store not initialized")
+	}
+	err = s.db.QueryRowContext(
+		ctx,
+		`SELECT dataset_id, content_sha256 FROM dataset_versions WHERE version_id = $1 AND project_id = $2`,
+		strings.TrimSpace(versionID),
+		projectID,
+	).Scan(&datasetID, &contentSHA256)
+	if err != nil {
+		return "", "", handleNotFound(err)
+	}
+	return datasetID, contentSHA256, nil
+}
+
+func scanObjectEncryption(alg, keyID, wrappedKey sql.NullString) domain.ObjectEncryption {
+	return domain.ObjectEncryption{
+		Algorithm:  strings.TrimSpace(alg.String),
+		KeyID:      strings.TrimSpace(keyID.String),
+		WrappedKey: strings.TrimSpace(wrappedKey.String),
+	}
+}
@@ -46,20 +46,22 @@ const (
 			requested_at,
 			requested_by,
 			updated_at,
-			integrity_sha256
-		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12)
+			integrity_sha256,
+			max_duration_seconds
+		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13)
 		ON CONFLICT (project_id, idempotency_key) DO NOTHING
-		RETURNING dispatch_id, run_id, project_id, idempotency_key, dp_base_url, status, last_error, spec_hash, requested_at, requested_by, updated_at, integrity_sha256`
-	selectRunDispatchByIdempotencyQuery = `SELECT dispatch_id, run_id, project_id, idempotency_key, dp_base_url, status, last_error, spec_hash, requested_at, requested_by, updated_at, integrity_sha256
+		RETURNING dispatch_id, run_id, project_id, idempotency_key, dp_base_url, status, last_error, spec_hash, requested_at, requested_by, updated_at, integrity_sha256, max_duration_seconds`
+	selectRunDispatchByIdempotencyQuery = `SELECT dispatch_id, run_id, project_id, idempotency_key, dp_base_url, status, last_error, spec_hash, requested_at, requested_by, updated_at, integrity_sha256, max_duration_seconds
 		FROM run_dispatches
 		WHERE project_id = $1 AND idempotency_key = $2`
-	selectRunDispatchByRunIDQuery = `SELECT dispatch_id, run_id, project_id, idempotency_key, dp_base_url, status, last_error, spec_hash, requested_at, requested_by, updated_at, integrity_sha256
+	selectRunDispatchByRunIDQuery = `SELECT dispatch_id, run_id, project_id, idempotency_key, dp_base_url, status, last_error, spec_hash, requested_at, requested_by, updated_at, integrity_sha256, max_duration_seconds
 		FROM run_dispatches
 		WHERE project_id = $1 AND run_id = $2`
+	lockRunDispatchQuery         = `SELECT status FROM run_dispatches WHERE dispatch_id = $1 FOR UPDATE`
 	updateRunDispatchStatusQuery = `UPDATE run_dispatches
 		SET status = $1, last_error = $2, updated_at = $3
 		WHERE dispatch_id = $4`
-	selectRunDispatchesByStatusBase = `SELECT dispatch_id, run_id, project_id, idempotency_key, dp_base_url, status, last_error, spec_hash, requested_at, requested_by, updated_at, integrity_sha256
+	selectRunDispatchesByStatusBase = `SELECT dispatch_id, run_id, project_id, idempotency_key, dp_base_url, status, last_error, spec_hash, requested_at, requested_by, updated_at, integrity_sha256, max_duration_seconds
 		FROM run_dispatches`
 )
 
@@ -177,6 +179,8 @@ type RunDispatchRecord struct {
 	RequestedBy    string
 	UpdatedAt      time.Time
 	IntegritySHA   string
+	// MaxDurationSeconds bounds the run from requested_at; null is unbounded.
+	MaxDurationSeconds sql.NullInt64
 }
 
 func (s *DPEventStore) CreateDispatch(ctx context.Context, record RunDispatchRecord) (RunDispatchRecord, bool, error) {
@@ -222,9 +226,10 @@ func (s *DPEventStore) CreateDispatch(ctx context.Context, record RunDispatchRec
 		record.RequestedBy,
 		updatedAt,
 		record.IntegritySHA,
+		record.MaxDurationSeconds,
 	)
 	var out RunDispatchRecord
-	if err := row.Scan(&out.DispatchID, &out.RunID, &out.ProjectID, &out.IdempotencyKey, &out.DPBaseURL, &out.Status, &out.LastError, &out.SpecHash, &out.RequestedAt, &out.RequestedBy, &out.UpdatedAt, &out.IntegritySHA); err != nil {
+	if err := row.Scan(&out.DispatchID, &out.RunID, &out.ProjectID, &out.IdempotencyKey, &out.DPBaseURL, &out.Status, &out.LastError, &out.SpecHash, &out.RequestedAt, &out.RequestedBy, &out.UpdatedAt, &out.IntegritySHA, &out.MaxDurationSeconds); err != nil {
 		if err != sql.ErrNoRows {
 			return RunDispatchRecord{}, false, fmt.Errorf("insert run dispatch: %w", err)
 		}
@@ -248,7 +253,7 @@ func (s *DPEventStore) GetDispatchByIdempotencyKey(ctx context.Context, projectI
 	}
 	row := s.db.QueryRowContext(ctx, selectRunDispatchByIdempotencyQuery, projectID, idempotencyKey)
 	var record RunDispatchRecord
-	if err := row.Scan(&record.DispatchID, &record.RunID, &record.ProjectID, &record.IdempotencyKey, &record.DPBaseURL, &record.Status, &record.LastError, &record.SpecHash, &record.RequestedAt, &record.RequestedBy, &record.UpdatedAt, &record.IntegritySHA); err != nil {
+	if err := row.Scan(&record.DispatchID, &record.RunID, &record.ProjectID, &record.IdempotencyKey, &record.DPBaseURL, &record.Status, &record.LastError, &record.SpecHash, &record.RequestedAt, &record.RequestedBy, &record.UpdatedAt, &record.IntegritySHA, &record.MaxDurationSeconds); err != nil {
 		return RunDispatchRecord{}, handleNotFound(err)
 	}
 	return record, nil
@@ -265,12 +270,25 @@ func (s *DPEventStore) GetDispatchByRunID(ctx context.Context, projectID, runID
 	}
 	row := s.db.QueryRowContext(ctx, selectRunDispatchByRunIDQuery, projectID, runID)
 	var record RunDispatchRecord
-	if err := row.Scan(&record.DispatchID, &record.RunID, &record.ProjectID, &record.IdempotencyKey, &record.DPBaseURL, &record.Status, &record.LastError, &record.SpecHash, &record.RequestedAt, &record.RequestedBy, &record.UpdatedAt, &record.IntegritySHA); err != nil {
+	if err := row.Scan(&record.DispatchID, &record.RunID, &record.ProjectID, &record.IdempotencyKey, &record.DPBaseURL, &record.Status, &record.LastError, &record.SpecHash, &record.RequestedAt, &record.RequestedBy, &record.UpdatedAt, &record.IntegritySHA, &record.MaxDurationSeconds); err != nil {
 		return RunDispatchRecord{}, handleNotFound(err)
 	}
 	return record, nil
 }
 
+// LockDispatch locks the dispatch row until the caller's transaction ends
+// and returns its current status.
+func (s *DPEventStore) LockDispatch(ctx context.Context, dispatchID string) (string, error) {
+	if s == nil || s.db == nil {
+		return "", fmt.Errorf("dp event store not initialized")
+	}
+	var status string
+	if err := s.db.QueryRowContext(ctx, lockRunDispatchQuery, strings.TrimSpace(dispatchID)).Scan(&status); err != nil {
+		return "", handleNotFound(err)
+	}
+	return status, nil
+}
+
 func (s *DPEventStore) UpdateDispatchStatus(ctx context.Context, dispatchID, status, lastError string, updatedAt time.Time) error {
 	if s == nil || s.db == nil {
 		return fmt.Errorf("dp event store not initialized")
@@ -332,7 +350,7 @@ func (s *DPEventStore) ListDispatchesByStatus(ctx context.Context, statuses []st
 	out := make([]RunDispatchRecord, 0)
 	for rows.Next() {
 		var record RunDispatchRecord
-		if err := rows.Scan(&record.DispatchID, &record.RunID, &record.ProjectID, &record.IdempotencyKey, &record.DPBaseURL, &record.Status, &record.LastError, &record.SpecHash, &record.RequestedAt, &record.RequestedBy, &record.UpdatedAt, &record.IntegritySHA); err != nil {
+		if err := rows.Scan(&record.DispatchID, &record.RunID, &record.ProjectID, &record.IdempotencyKey, &record.DPBaseURL, &record.Status, &record.LastError, &record.SpecHash, &record.RequestedAt, &record.RequestedBy, &record.UpdatedAt, &record.IntegritySHA, &record.MaxDurationSeconds); err != nil {
 			return nil, fmt.Errorf("scan run dispatch: %w", err)
 		}
 		out = append(out, record)
@@ -0,0 +1,369 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+)
+
+const (
+	evaluationSuiteColumns = `SELECT suite_id, project_id, name, description, metrics, created_at, created_by
+		FROM evaluation_suites`
+	insertEvaluationSuiteQuery = `INSERT INTO evaluation_suites (suite_id, project_id, name, description, metrics, created_at, created_by)
+		VALUES ($1,$2,$3,$4,$5,$6,$7)`
+	selectEvaluationSuiteQuery     = evaluationSuiteColumns + ` WHERE suite_id = $1 AND project_id = $2`
+	selectEvaluationSuiteListQuery = evaluationSuiteColumns + `
+		WHERE project_id = $1
+		ORDER BY name ASC
+		LIMIT $2`
+
+	evaluationResultColumns = `SELECT result_id, suite_id, project_id, schema_version, run_id, COALESCE(model_version_id, ''), COALESCE(evaluation_id, ''), metrics, sample_count, created_at, created_by, integrity_sha256
+		FROM evaluation_results`
+	insertEvaluationResultQuery = `INSERT INTO evaluation_results (
+			result_id, suite_id, project_id, schema_version, run_id, model_version_id, evaluation_id, metrics, sample_count, created_at, created_by, integrity_sha256
+		) VALUES ($1,$2,$3,$4,$5,NULLIF($6,''),NULLIF($7,''),$8,0,$9,$10,$11)`
+	selectEvaluationResultQuery     = evaluationResultColumns + ` WHERE result_id = $1 AND project_id = $2`
+	selectEvaluationResultListQuery = evaluationResultColumns + `
+		WHERE suite_id = $1
+		  AND ($2 = '' OR model_version_id = $2)
+		  AND ($3 = '' OR run_id = $3)
+		ORDER BY created_at DESC, result_id ASC
+		LIMIT $4`
+	selectEvaluationResultLatestQuery = evaluationResultColumns + `
+		WHERE suite_id = $1 AND model_version_id = $2
+		ORDER BY created_at DESC, result_id ASC
+		LIMIT 1`
+
+	insertEvaluationSampleQuery = `INSERT INTO evaluation_result_samples (result_id, sample_id, metrics, passed, created_at)
+		VALUES ($1,$2,$3,$4,$5)
+		ON CONFLICT (result_id, sample_id) DO NOTHING`
+	updateEvaluationSampleCountQuery = `UPDATE evaluation_results SET sample_count = sample_count + $2 WHERE result_id = $1`
+	selectEvaluationSampleListQuery  = `SELECT sample_id, metrics, passed
+		FROM evaluation_result_samples
+		WHERE result_id = $1 AND sample_id > $2
+		ORDER BY sample_id ASC
+		LIMIT $3`
+
+	selectEvaluationRunQuery           = `SELECT EXISTS (SELECT 1 FROM experiment_runs WHERE run_id = $1 AND project_id = $2)`
+	selectEvaluationModelVersionQuery  = `SELECT EXISTS (SELECT 1 FROM model_versions WHERE model_version_id = $1 AND project_id = $2)`
+	selectEvaluationRunEvaluationQuery = `SELECT EXISTS (SELECT 1 FROM experiment_run_evaluations WHERE evaluation_id = $1 AND run_id = $2)`
+)
+
+// EvaluationStore keeps evaluation suites, the results reported against
+// them and the per-sample metrics of each result.
+type EvaluationStore struct {
+	db DB
+}
+
+func NewEvaluationStore(db DB) *EvaluationStore {
+	if db == nil {
+		return nil
+	}
+	return &EvaluationStore{db: db}
+}
+
+// EvaluationSuiteRecord is one suite. Metrics is the raw JSON column.
+type EvaluationSuiteRecord struct {
+	SuiteID     string
+	ProjectID   string
+	Name        string
+	Description string
+	Metrics     []byte
+	CreatedAt   time.Time
+	CreatedBy   string
+}
+
+// EvaluationResultRecord is one result. Metrics is the raw JSON column.
+type EvaluationResultRecord struct {
+	ResultID        string
+	SuiteID         string
+	ProjectID       string
+	SchemaVersion   string
+	RunID           string
+	ModelVersionID  string
+	EvaluationID    string
+	Metrics         []byte
+	SampleCount     int
+	CreatedAt       time.Time
+	CreatedBy       string
+	IntegritySHA256 string
+}
+
+type EvaluationResultFilter struct {
+	SuiteID        string
+	ModelVersionID string
+	RunID          string
+	Limit          int
+}
+
+// EvaluationSampleRecord is one sample of a result. Metrics is the raw JSON
+// column.
+type EvaluationSampleRecord struct {
+	SampleID string
+	Metrics  []byte
+	Passed   *bool
+}
+
+// CreateSuite inserts a suite; a taken name is repo.ErrConflict and a
+// missing project repo.ErrNotFound.
+func (s *EvaluationStore) CreateSuite(ctx context.Context, record EvaluationSuiteRecord) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("evaluation store not initialized")
+	}
+	_, err := s.db.ExecContext(
+		ctx,
+		insertEvaluationSuiteQuery,
+		record.SuiteID,
+		record.ProjectID,
+		record.Name,
+		record.Description,
+		record.Metrics,
+		normalizeTime(record.CreatedAt),
+		record.CreatedBy,
+	)
+	if err != nil {
+		switch {
+		case isUniqueViolation(err):
+			return repo.ErrConflict
+		case isForeignKeyViolation(err):
+			return repo.ErrNotFound
+		}
+		return fmt.Errorf("insert evaluation suite: %w", err)
+	}
+	return nil
+}
+
+func (s *EvaluationStore) GetSuite(ctx context.Context, projectID, suiteID string) (EvaluationSuiteRecord, error) {
+	if s == nil || s.db == nil {
+		return EvaluationSuiteRecord{}, fmt.Errorf("evaluation store not initialized")
+	}
+	record, err := scanEvaluationSuite(s.db.QueryRowContext(ctx, selectEvaluationSuiteQuery, strings.TrimSpace(suiteID), projectID))
+	if err != nil {
+		return EvaluationSuiteRecord{}, handleNotFound(err)
+	}
+	return record, nil
+}
+
+func (s *EvaluationStore) ListSuites(ctx context.Context, projectID string, limit int) ([]EvaluationSuiteRecord, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("evaluation store not initialized")
+	}
+	rows, err := s.db.QueryContext(ctx, selectEvaluationSuiteListQuery, projectID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list evaluation suites: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]EvaluationSuiteRecord, 0)
+	for rows.Next() {
+		record, err := scanEvaluationSuite(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan evaluation suite: %w", err)
+		}
+		out = append(out, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate evaluation suites: %w", err)
+	}
+	return out, nil
+}
+
+// LinksExist reports whether runID belongs to projectID, whether
+// modelVersionID does and whether evaluationID evaluated the run. An empty
+// modelVersionID or evaluationID counts as existing.
+func (s *EvaluationStore) LinksExist(ctx context.Context, projectID, runID, modelVersionID, evaluationID string) (run, modelVersion, evaluation bool, err error) {
+	if s == nil || s.db == nil {
+		return false, false, false, fmt.Errorf("evaluation store not initialized")
+	}
+	if err := s.db.QueryRowContext(ctx, selectEvaluationRunQuery, runID, projectID).Scan(&run); err != nil {
+		return false, false, false, fmt.Errorf("select evaluation run: %w", err)
+	}
+	modelVersion, evaluation = true, true
+	if modelVersionID != "" {
+		if err := s.db.QueryRowContext(ctx, selectEvaluationModelVersionQuery, modelVersionID, projectID).Scan(&modelVersion); err != nil {
+			return false, false, false, fmt.Errorf("select evaluation model version: %w", err)
+		}
+	}
+	if evaluationID != "" {
+		if err := s.db.QueryRowContext(ctx, selectEvaluationRunEvaluationQuery, evaluationID, runID).Scan(&evaluation); err != nil {
+			return false, false, false, fmt.Errorf("select run evaluation: %w", err)
+		}
+	}
+	return run, modelVersion, evaluation, nil
+}
+
+// CreateResult inserts a result without samples.
+func (s *EvaluationStore) CreateResult(ctx context.Context, record EvaluationResultRecord) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("evaluation store not initialized")
+	}
+	if err := requireIntegrity(record.IntegritySHA256); err != nil {
+		return err
+	}
+	_, err := s.db.ExecContext(
+		ctx,
+		insertEvaluationResultQuery,
+		record.ResultID,
+		record.SuiteID,
+		record.ProjectID,
+		record.SchemaVersion,
+		record.RunID,
+		record.ModelVersionID,
+		record.EvaluationID,
+		record.Metrics,
+		normalizeTime(record.CreatedAt),
+		record.CreatedBy,
+		record.IntegritySHA256,
+	)
+	if err != nil {
+		return fmt.Errorf("insert evaluation result: %w", err)
+	}
+	return nil
+}
+
+func (s *EvaluationStore) GetResult(ctx context.Context, projectID, resultID string) (EvaluationResultRecord, error) {
+	if s == nil || s.db == nil {
+		return EvaluationResultRecord{}, fmt.Errorf("evaluation store not initialized")
+	}
+	record, err := scanEvaluationResult(s.db.QueryRowContext(ctx, selectEvaluationResultQuery, strings.TrimSpace(resultID), projectID))
+	if err != nil {
+		return EvaluationResultRecord{}, handleNotFound(err)
+	}
+	return record, nil
+}
+
+// LatestResult returns the newest result of modelVersionID in the suite;
+// none is repo.ErrNotFound.
+func (s *EvaluationStore) LatestResult(ctx context.Context, suiteID, modelVersionID string) (EvaluationResultRecord, error) {
+	if s == nil || s.db == nil {
+		return EvaluationResultRecord{}, fmt.Errorf("evaluation store not initialized")
+	}
+	record, err := scanEvaluationResult(s.db.QueryRowContext(ctx, selectEvaluationResultLatestQuery, suiteID, modelVersionID))
+	if err != nil {
+		return EvaluationResultRecord{}, handleNotFound(err)
+	}
+	return record, nil
+}
+
+func (s *EvaluationStore) ListResults(ctx context.Context, filter EvaluationResultFilter) ([]EvaluationResultRecord, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("evaluation store not initialized")
+	}
+	rows, err := s.db.QueryContext(
+		ctx,
+		selectEvaluationResultListQuery,
+		filter.SuiteID,
+		strings.TrimSpace(filter.ModelVersionID),
+		strings.TrimSpace(filter.RunID),
+		filter.Limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list evaluation results: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]EvaluationResultRecord, 0)
+	for rows.Next() {
+		record, err := scanEvaluationResult(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan evaluation result: %w", err)
+		}
+		out = append(out, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate evaluation results: %w", err)
+	}
+	return out, nil
+}
+
+// AddSamples stores samples of resultID and bumps its sample count. Samples
+// whose sample_id already exists are skipped; the number of new samples is
+// returned.
+func (s *EvaluationStore) AddSamples(ctx context.Context, resultID string, samples []EvaluationSampleRecord, now time.Time) (int, error) {
+	if s == nil || s.db == nil {
+		return 0, fmt.Errorf("evaluation store not initialized")
+	}
+	now = normalizeTime(now)
+	inserted := 0
+	for _, sample := range samples {
+		res, err := s.db.ExecContext(ctx, insertEvaluationSampleQuery, resultID, sample.SampleID, sample.Metrics, sample.Passed, now)
+		if err != nil {
+			return 0, fmt.Errorf("insert evaluation sample: %w", err)
+		}
+		affected, _ := res.RowsAffected()
+		inserted += int(affected)
+	}
+	if inserted > 0 {
+		if _, err := s.db.ExecContext(ctx, updateEvaluationSampleCountQuery, resultID, inserted); err != nil {
+			return 0, fmt.Errorf("update evaluation sample count: %w", err)
+		}
+	}
+	return inserted, nil
+}
+
+// ListSamples returns up to limit samples of resultID after afterSampleID in
+// sample_id order.
+func (s *EvaluationStore) ListSamples(ctx context.Context, resultID, afterSampleID string, limit int) ([]EvaluationSampleRecord, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("evaluation store not initialized")
+	}
+	rows, err := s.db.QueryContext(ctx, selectEvaluationSampleListQuery, resultID, afterSampleID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list evaluation samples: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]EvaluationSampleRecord, 0)
+	for rows.Next() {
+		var (
+			record EvaluationSampleRecord
+			passed sql.NullBool
+		)
+		if err := rows.Scan(&record.SampleID, &record.Metrics, &passed); err != nil {
+			return nil, fmt.Errorf("scan evaluation sample: %w", err)
+		}
+		if passed.Valid {
+			record.Passed = &passed.Bool
+		}
+		out = append(out, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate evaluation samples: %w", err)
+	}
+	return out, nil
+}
+
+func scanEvaluationSuite(row interface{ Scan(...any) error }) (EvaluationSuiteRecord, error) {
+	var out EvaluationSuiteRecord
+	if err := row.Scan(&out.SuiteID, &out.ProjectID, &out.Name, &out.Description, &out.Metrics, &out.CreatedAt, &out.CreatedBy); err != nil {
+		return EvaluationSuiteRecord{}, err
+	}
+	out.CreatedAt = out.CreatedAt.UTC()
+	return out, nil
+}
+
+func scanEvaluationResult(row interface{ Scan(...any) error }) (EvaluationResultRecord, error) {
+	var out EvaluationResultRecord
+	if err := row.Scan(
+		&out.ResultID,
+		&out.SuiteID,
+		&out.ProjectID,
+		&out.SchemaVersion,
+		&out.RunID,
+		&out.ModelVersionID,
+		&out.EvaluationID,
+		&out.Metrics,
+		&out.SampleCount,
+		&out.CreatedAt,
+		&out.CreatedBy,
+		&out.IntegritySHA256,
+	); err != nil {
+		return EvaluationResultRecord{}, err
+	}
+	out.CreatedAt = out.CreatedAt.UTC()
+	return out, nil
+}
@@ -0,0 +1,191 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+)
+
+type EvidenceStore struct {
+	db DB
+}
+
+const (
+	insertEvidenceBundleQuery = `INSERT INTO experiment_run_evidence_bundles (
+			bundle_id,
+			run_id,
+			bundle_object_key,
+			report_object_key,
+			bundle_sha256,
+			bundle_size_bytes,
+			report_sha256,
+			report_size_bytes,
+			signature,
+			signature_alg,
+			created_at,
+			created_by,
+			integrity_sha256,
+			encryption_alg,
+			encryption_key_id,
+			bundle_wrapped_key,
+			report_wrapped_key,
+			signing_key_id
+		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18)`
+	evidenceBundleColumns = `SELECT bundle_id,
+			run_id,
+			bundle_object_key,
+			report_object_key,
+			bundle_sha256,
+			bundle_size_bytes,
+			report_sha256,
+			report_size_bytes,
+			signature,
+			signature_alg,
+			signing_key_id,
+			created_at,
+			created_by,
+			encryption_alg,
+			encryption_key_id,
+			bundle_wrapped_key,
+			report_wrapped_key,
+			integrity_sha256
+		FROM experiment_run_evidence_bundles`
+	selectEvidenceBundleQuery = evidenceBundleColumns + `
+		WHERE run_id = $1 AND bundle_id = $2`
+	selectEvidenceBundleListQuery = evidenceBundleColumns + `
+		WHERE run_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2`
+)
+
+func NewEvidenceStore(db DB) *EvidenceStore {
+	if db == nil {
+		return nil
+	}
+	return &EvidenceStore{db: db}
+}
+
+func (s *EvidenceStore) CreateEvidenceBundle(ctx context.Context, bundle repo.EvidenceBundleRecord) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("evidence store not initialized")
+	}
+	if strings.TrimSpace(bundle.BundleID) == "" {
+		return fmt.Errorf("bundle id is required")
+	}
+	if strings.TrimSpace(bundle.RunID) == "" {
+		return fmt.Errorf("run id is required")
+	}
+	if err := requireIntegrity(bundle.IntegritySHA256); err != nil {
+		return err
+	}
+	_, err := s.db.ExecContext(
+		ctx,
+		insertEvidenceBundleQuery,
+		bundle.BundleID,
+		bundle.RunID,
+		bundle.BundleObjectKey,
+		bundle.ReportObjectKey,
+		bundle.BundleSHA256,
+		bundle.BundleSizeBytes,
+		bundle.ReportSHA256,
+		bundle.ReportSizeBytes,
+		bundle.Signature,
+		bundle.SignatureAlg,
+		normalizeTime(bundle.CreatedAt),
+		bundle.CreatedBy,
+		bundle.IntegritySHA256,
+		nullIfEmpty(bundle.EncryptionAlg),
+		nullIfEmpty(bundle.EncryptionKeyID),
+		nullIfEmpty(bundle.BundleWrappedKey),
+		nullIfEmpty(bundle.ReportWrappedKey),
+		nullIfEmpty(bundle.SigningKeyID),
+	)
+	if err != nil {
+		return fmt.Errorf("insert evidence bundle: %w", err)
+	}
+	return nil
+}
+
+func (s *EvidenceStore) GetEvidenceBundle(ctx context.Context, runID, bundleID string) (repo.EvidenceBundleRecord, error) {
+	if s == nil || s.db == nil {
+		return repo.EvidenceBundleRecord{}, fmt.Errorf("evidence store not initialized")
+	}
+	record, err := scanEvidenceBundle(s.db.QueryRowContext(ctx, selectEvidenceBundleQuery, strings.TrimSpace(runID), strings.TrimSpace(bundleID)))
+	if err != nil {
+		return repo.EvidenceBundleRecord{}, handleNotFound(err)
+	}
+	return record, nil
+}
+
+func (s *EvidenceStore) ListEvidenceBundles(ctx context.Context, runID string, limit int) ([]repo.EvidenceBundleRecord, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("evidence store not initialized")
+	}
+	runID = strings.TrimSpace(runID)
+	if runID == "" {
+		return nil, fmt.Errorf("run id is required")
+	}
+	if limit <= 0 {
+		limit = 100
+	}
+	rows, err := s.db.QueryContext(ctx, selectEvidenceBundleListQuery, runID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list evidence bundles: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]repo.EvidenceBundleRecord, 0, limit)
+	for rows.Next() {
+		record, err := scanEvidenceBundle(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan evidence bundle: %w", err)
+		}
+		out = append(out, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list evidence bundles: %w", err)
+	}
+	return out, nil
+}
+
+func scanEvidenceBundle(row rowScanner) (repo.EvidenceBundleRecord, error) {
+	var (
+		record        repo.EvidenceBundleRecord
+		signingKeyID  sql.NullString
+		encryptionAlg sql.NullString
+		encryptionKey sql.NullString
+		bundleWrapped sql.NullString
+		reportWrapped sql.NullString
+	)
+	if err := row.Scan(
+		&record.BundleID,
+		&record.RunID,
+		&record.BundleObjectKey,
+		&record.ReportObjectKey,
+		&record.BundleSHA256,
+		&record.BundleSizeBytes,
+		&record.ReportSHA256,
+		&record.ReportSizeBytes,
+		&record.Signature,
+		&record.SignatureAlg,
+		&signingKeyID,
+		&record.CreatedAt,
+		&record.CreatedBy,
+		&encryptionAlg,
+		&encryptionKey,
+		&bundleWrapped,
+		&reportWrapped,
+		&record.IntegritySHA256,
+	); err != nil {
+		return repo.EvidenceBundleRecord{}, err
+	}
+	record.SigningKeyID = signingKeyID.String
+	record.EncryptionAlg = encryptionAlg.String
+	record.EncryptionKeyID = encryptionKey.String
+	record.BundleWrappedKey = bundleWrapped.String
+	record.ReportWrappedKey = reportWrapped.String
+	return record, nil
+}
@@ -0,0 +1,370 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+)
+
+const (
+	selectArchiveArtifactsQuery = `SELECT a.artifact_id, a.run_id, a.kind, a.name, a.filename, a.content_type, a.object_key, a.sha256, a.size_bytes, a.metadata, a.created_at, a.created_by
+		FROM experiment_run_artifacts a
+		JOIN experiment_runs r ON r.run_id = a.run_id
+		WHERE r.experiment_id = $1
+		ORDER BY a.created_at ASC, a.artifact_id ASC`
+	selectArchiveRunDatasetsQuery = `SELECT d.run_id, d.dataset_version_id
+		FROM experiment_run_datasets d
+		JOIN experiment_runs r ON r.run_id = d.run_id
+		WHERE r.experiment_id = $1
+		ORDER BY d.run_id ASC, d.position ASC`
+	selectArchiveRunsQuery = `SELECT run_id, status, started_at, ended_at, git_repo, git_commit, git_ref, params, metrics, dataset_version_id, integrity_sha256
+		FROM experiment_runs
+		WHERE experiment_id = $1
+		ORDER BY started_at ASC, run_id ASC`
+	selectArchiveMetricsQuery = `SELECT m.run_id, m.recorded_at, m.recorded_by, m.step, m.name, m.value, m.metadata
+		FROM experiment_run_metric_samples m
+		JOIN experiment_runs r ON r.run_id = m.run_id
+		WHERE r.experiment_id = $1
+		ORDER BY m.run_id ASC, m.name ASC, m.step ASC`
+	selectArchiveEventsQuery = `SELECT e.run_id, e.occurred_at, e.actor, e.level, e.message, e.metadata
+		FROM experiment_run_events e
+		JOIN experiment_runs r ON r.run_id = e.run_id
+		WHERE r.experiment_id = $1
+		ORDER BY e.event_id ASC`
+	selectArchiveDecisionsQuery = `SELECT d.decision_id, d.run_id, d.policy_id, d.policy_version_id, d.policy_sha256, d.context, d.context_sha256,
+		       d.decision, d.rule_id, d.reason, d.bundle_revision, d.created_at, d.created_by, d.integrity_sha256
+		FROM policy_decisions d
+		JOIN experiment_runs r ON r.run_id = d.run_id
+		WHERE r.experiment_id = $1
+		ORDER BY d.created_at ASC, d.decision_id ASC`
+	selectArchiveConfigsQuery = `SELECT c.run_id, c.manifest, c.manifest_sha256, c.signature, c.signature_alg, c.signing_key_id, c.created_at
+		FROM experiment_run_config_manifests c
+		JOIN experiment_runs r ON r.run_id = c.run_id
+		WHERE r.experiment_id = $1
+		ORDER BY c.run_id ASC`
+
+	insertArchiveArtifactQuery = `INSERT INTO experiment_run_artifacts (
+			artifact_id, run_id, kind, name, filename, content_type, object_key, sha256, size_bytes, metadata, created_at, created_by, integrity_sha256, blob_sha256
+		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14)`
+	insertExperimentImportQuery = `INSERT INTO experiment_imports (
+			import_id, project_id, experiment_id, source_experiment_id, source_project_id, manifest_sha256, archive_object_key, summary, created_at, created_by, integrity_sha256
+		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)`
+	selectExperimentImportSummaryQuery = `SELECT summary FROM experiment_imports WHERE experiment_id = $1 AND project_id = $2`
+)
+
+// ExperimentArchiveStore reads everything an experiment export contains and
+// records what an import recreated. The Each methods stream rows to fn in
+// export order, so an archive of any size is written without holding it in
+// memory.
+type ExperimentArchiveStore struct {
+	db DB
+}
+
+func NewExperimentArchiveStore(db DB) *ExperimentArchiveStore {
+	if db == nil {
+		return nil
+	}
+	return &ExperimentArchiveStore{db: db}
+}
+
+// ExperimentArchiveArtifactRecord is one run artifact. Metadata is the raw
+// JSON column; BlobSHA256 is only written.
+type ExperimentArchiveArtifactRecord struct {
+	ArtifactID      string
+	RunID           string
+	Kind            string
+	Name            string
+	Filename        string
+	ContentType     string
+	ObjectKey       string
+	SHA256          string
+	SizeBytes       int64
+	Metadata        []byte
+	CreatedAt       time.Time
+	CreatedBy       string
+	IntegritySHA256 string
+	BlobSHA256      string
+}
+
+// ExperimentArchiveRunRecord is one run. Params and Metrics are the raw
+// JSON columns; DatasetVersionID is the run's first dataset version.
+type ExperimentArchiveRunRecord struct {
+	RunID            string
+	Status           string
+	StartedAt        time.Time
+	EndedAt          *time.Time
+	GitRepo          string
+	GitCommit        string
+	GitRef           string
+	Params           []byte
+	Metrics          []byte
+	DatasetVersionID string
+	IntegritySHA256  string
+}
+
+type ExperimentArchiveMetricRecord struct {
+	RunID      string
+	RecordedAt time.Time
+	RecordedBy string
+	Step       int64
+	Name       string
+	Value      float64
+	Metadata   []byte
+}
+
+type ExperimentArchiveDecisionRecord struct {
+	DecisionID      string
+	RunID           string
+	PolicyID        string
+	PolicyVersionID string
+	PolicySHA256    string
+	Context         []byte
+	ContextSHA256   string
+	Decision        string
+	RuleID          string
+	Reason          string
+	BundleRevision  string
+	CreatedAt       time.Time
+	CreatedBy       string
+	IntegritySHA256 string
+}
+
+// ExperimentArchiveConfigRecord is a signed run config manifest. Manifest
+// keeps the exact signed bytes.
+type ExperimentArchiveConfigRecord struct {
+	RunID        string
+	Manifest     string
+	SHA256       string
+	Signature    string
+	SignatureAlg string
+	SigningKeyID string
+	CreatedAt    time.Time
+}
+
+// ExperimentImportRecord is one import. Summary is the JSON returned by the
+// import endpoints.
+type ExperimentImportRecord struct {
+	ImportID           string
+	ProjectID          string
+	ExperimentID       string
+	SourceExperimentID string
+	SourceProjectID    string
+	ManifestSHA256     string
+	ArchiveObjectKey   string
+	Summary            []byte
+	CreatedAt          time.Time
+	CreatedBy          string
+	IntegritySHA256    string
+}
+
+func (s *ExperimentArchiveStore) ListArtifacts(ctx context.Context, experimentID string) ([]ExperimentArchiveArtifactRecord, error) {
+	out := make([]ExperimentArchiveArtifactRecord, 0)
+	err := s.each(ctx, selectArchiveArtifactsQuery, experimentID, func(rows *sql.Rows) error {
+		var (
+			record                      ExperimentArchiveArtifactRecord
+			name, filename, contentType sql.NullString
+		)
+		if err := rows.Scan(&record.ArtifactID, &record.RunID, &record.Kind, &name, &filename, &contentType, &record.ObjectKey, &record.SHA256, &record.SizeBytes, &record.Metadata, &record.CreatedAt, &record.CreatedBy); err != nil {
+			return err
+		}
+		record.Name = name.String
+		record.Filename = filename.String
+		record.ContentType = contentType.String
+		record.CreatedAt = record.CreatedAt.UTC()
+		out = append(out, record)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list experiment artifacts: %w", err)
+	}
+	return out, nil
+}
+
+// RunDatasetVersions returns the dataset versions of every run of the
+// experiment in position order, keyed by run.
+func (s *ExperimentArchiveStore) RunDatasetVersions(ctx context.Context, experimentID string) (map[string][]string, error) {
+	out := make(map[string][]string)
+	err := s.each(ctx, selectArchiveRunDatasetsQuery, experimentID, func(rows *sql.Rows) error {
+		var runID, versionID string
+		if err := rows.Scan(&runID, &versionID); err != nil {
+			return err
+		}
+		out[runID] = append(out[runID], versionID)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list experiment run datasets: %w", err)
+	}
+	return out, nil
+}
+
+func (s *ExperimentArchiveStore) EachRun(ctx context.Context, experimentID string, fn func(ExperimentArchiveRunRecord) error) error {
+	return s.each(ctx, selectArchiveRunsQuery, experimentID, func(rows *sql.Rows) error {
+		var (
+			record                                       ExperimentArchiveRunRecord
+			endedAt                                      sql.NullTime
+			gitRepo, gitCommit, gitRef, datasetVersionID sql.NullString
+		)
+		if err := rows.Scan(&record.RunID, &record.Status, &record.StartedAt, &endedAt, &gitRepo, &gitCommit, &gitRef, &record.Params, &record.Metrics, &datasetVersionID, &record.IntegritySHA256); err != nil {
+			return err
+		}
+		record.StartedAt = record.StartedAt.UTC()
+		record.EndedAt = timePtr(endedAt)
+		record.GitRepo = gitRepo.String
+		record.GitCommit = gitCommit.String
+		record.GitRef = gitRef.String
+		record.DatasetVersionID = datasetVersionID.String
+		return fn(record)
+	})
+}
+
+func (s *ExperimentArchiveStore) EachMetric(ctx context.Context, experimentID string, fn func(ExperimentArchiveMetricRecord) error) error {
+	return s.each(ctx, selectArchiveMetricsQuery, experimentID, func(rows *sql.Rows) error {
+		var record ExperimentArchiveMetricRecord
+		if err := rows.Scan(&record.RunID, &record.RecordedAt, &record.RecordedBy, &record.Step, &record.Name, &record.Value, &record.Metadata); err != nil {
+			return err
+		}
+		record.RecordedAt = record.RecordedAt.UTC()
+		return fn(record)
+	})
+}
+
+// EachEvent streams the run events; EventID and IntegritySHA256 are not
+// set.
+func (s *ExperimentArchiveStore) EachEvent(ctx context.Context, experimentID string, fn func(repo.RunEventRecord) error) error {
+	return s.each(ctx, selectArchiveEventsQuery, experimentID, func(rows *sql.Rows) error {
+		var record repo.RunEventRecord
+		if err := rows.Scan(&record.RunID, &record.OccurredAt, &record.Actor, &record.Level, &record.Message, &record.Metadata); err != nil {
+			return err
+		}
+		record.OccurredAt = record.OccurredAt.UTC()
+		return fn(record)
+	})
+}
+
+func (s *ExperimentArchiveStore) EachDecision(ctx context.Context, experimentID string, fn func(ExperimentArchiveDecisionRecord) error) error {
+	return s.each(ctx, selectArchiveDecisionsQuery, experimentID, func(rows *sql.Rows) error {
+		var (
+			record                         ExperimentArchiveDecisionRecord
+			ruleID, reason, bundleRevision sql.NullString
+		)
+		if err := rows.Scan(&record.DecisionID, &record.RunID, &record.PolicyID, &record.PolicyVersionID, &record.PolicySHA256, &record.Context, &record.ContextSHA256,
+			&record.Decision, &ruleID, &reason, &bundleRevision, &record.CreatedAt, &record.CreatedBy, &record.IntegritySHA256); err != nil {
+			return err
+		}
+		record.RuleID = ruleID.String
+		record.Reason = reason.String
+		record.BundleRevision = bundleRevision.String
+		record.CreatedAt = record.CreatedAt.UTC()
+		return fn(record)
+	})
+}
+
+func (s *ExperimentArchiveStore) EachConfig(ctx context.Context, experimentID string, fn func(ExperimentArchiveConfigRecord) error) error {
+	return s.each(ctx, selectArchiveConfigsQuery, experimentID, func(rows *sql.Rows) error {
+		var (
+			record       ExperimentArchiveConfigRecord
+			signingKeyID sql.NullString
+		)
+		if err := rows.Scan(&record.RunID, &record.Manifest, &record.SHA256, &record.Signature, &record.SignatureAlg, &signingKeyID, &record.CreatedAt); err != nil {
+			return err
+		}
+		record.SigningKeyID = signingKeyID.String
+		record.CreatedAt = record.CreatedAt.UTC()
+		return fn(record)
+	})
+}
+
+// CreateArtifact inserts an imported artifact.
+func (s *ExperimentArchiveStore) CreateArtifact(ctx context.Context, record ExperimentArchiveArtifactRecord) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("experiment archive store not initialized")
+	}
+	if err := requireIntegrity(record.IntegritySHA256); err != nil {
+		return err
+	}
+	_, err := s.db.ExecContext(
+		ctx,
+		insertArchiveArtifactQuery,
+		record.ArtifactID,
+		record.RunID,
+		record.Kind,
+		nullString(record.Name),
+		nullString(record.Filename),
+		nullString(record.ContentType),
+		record.ObjectKey,
+		record.SHA256,
+		record.SizeBytes,
+		record.Metadata,
+		normalizeTime(record.CreatedAt),
+		record.CreatedBy,
+		record.IntegritySHA256,
+		record.BlobSHA256,
+	)
+	if err != nil {
+		return fmt.Errorf("insert experiment artifact: %w", err)
+	}
+	return nil
+}
+
+func (s *ExperimentArchiveStore) CreateImport(ctx context.Context, record ExperimentImportRecord) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("experiment archive store not initialized")
+	}
+	if err := requireIntegrity(record.IntegritySHA256); err != nil {
+		return err
+	}
+	_, err := s.db.ExecContext(
+		ctx,
+		insertExperimentImportQuery,
+		record.ImportID,
+		record.ProjectID,
+		record.ExperimentID,
+		record.SourceExperimentID,
+		nullString(record.SourceProjectID),
+		record.ManifestSHA256,
+		record.ArchiveObjectKey,
+		record.Summary,
+		normalizeTime(record.CreatedAt),
+		record.CreatedBy,
+		record.IntegritySHA256,
+	)
+	if err != nil {
+		return fmt.Errorf("insert experiment import: %w", err)
+	}
+	return nil
+}
+
+// ImportSummary returns the summary of the import that created the
+// experiment; an experiment that was not imported is repo.ErrNotFound.
+func (s *ExperimentArchiveStore) ImportSummary(ctx context.Context, projectID, experimentID string) ([]byte, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("experiment archive store not initialized")
+	}
+	var summary []byte
+	if err := s.db.QueryRowContext(ctx, selectExperimentImportSummaryQuery, strings.TrimSpace(experimentID), projectID).Scan(&summary); err != nil {
+		return nil, handleNotFound(err)
+	}
+	return summary, nil
+}
+
+func (s *ExperimentArchiveStore) each(ctx context.Context, query, experimentID string, scan func(*sql.Rows) error) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("experiment archive store not initialized")
+	}
+	rows, err := s.db.QueryContext(ctx, query, strings.TrimSpace(experimentID))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
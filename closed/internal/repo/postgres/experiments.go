@@ -0,0 +1,152 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+)
+
+const (
+	insertExperimentQuery = `INSERT INTO experiments (
+			experiment_id,
+			project_id,
+			name,
+			description,
+			metadata,
+			created_at,
+			created_by,
+			integrity_sha256
+		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8)`
+	experimentColumns = `SELECT experiment_id, project_id, name, description, metadata, created_at, created_by, integrity_sha256
+		FROM experiments`
+	selectExperimentQuery = experimentColumns + `
+		WHERE experiment_id = $1 AND project_id = $2`
+	selectExperimentListQuery = experimentColumns + `
+		WHERE project_id = $1 AND ($2 = '' OR name = $2)
+		ORDER BY created_at DESC
+		LIMIT $3`
+	selectExperimentExistsQuery = `SELECT 1 FROM experiments WHERE experiment_id = $1`
+)
+
+func (s *RunStore) CreateExperiment(ctx context.Context, experiment repo.ExperimentRecord) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("run store not initialized")
+	}
+	if strings.TrimSpace(experiment.ExperimentID) == "" {
+		return fmt.Errorf("experiment id is required")
+	}
+	if strings.TrimSpace(experiment.ProjectID) == "" {
+		return fmt.Errorf("project id is required")
+	}
+	if strings.TrimSpace(experiment.Name) == "" {
+		return fmt.Errorf("experiment name is required")
+	}
+	if err := requireIntegrity(experiment.IntegritySHA256); err != nil {
+		return err
+	}
+	_, err := s.db.ExecContext(
+		ctx,
+		insertExperimentQuery,
+		experiment.ExperimentID,
+		experiment.ProjectID,
+		experiment.Name,
+		nullIfEmpty(experiment.Description),
+		experiment.Metadata,
+		normalizeTime(experiment.CreatedAt),
+		experiment.CreatedBy,
+		experiment.IntegritySHA256,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return repo.ErrConflict
+		}
+		return fmt.Errorf("insert experiment: %w", err)
+	}
+	return nil
+}
+
+func (s *RunStore) GetExperiment(ctx context.Context, projectID, experimentID string) (repo.ExperimentRecord, error) {
+	if s == nil || s.db == nil {
+		return repo.ExperimentRecord{}, fmt.Errorf("run store not initialized")
+	}
+	experimentID = strings.TrimSpace(experimentID)
+	if experimentID == "" {
+		return repo.ExperimentRecord{}, fmt.Errorf("experiment id is required")
+	}
+	record, err := scanExperiment(s.db.QueryRowContext(ctx, selectExperimentQuery, experimentID, strings.TrimSpace(projectID)))
+	if err != nil {
+		return repo.ExperimentRecord{}, handleNotFound(err)
+	}
+	return record, nil
+}
+
+func (s *RunStore) ListExperiments(ctx context.Context, filter repo.ExperimentFilter) ([]repo.ExperimentRecord, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("run store not initialized")
+	}
+	projectID := strings.TrimSpace(filter.ProjectID)
+	if projectID == "" {
+		return nil, fmt.Errorf("project id is required")
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	rows, err := s.db.QueryContext(ctx, selectExperimentListQuery, projectID, strings.TrimSpace(filter.Name), limit)
+	if err != nil {
+		return nil, fmt.Errorf("list experiments: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]repo.ExperimentRecord, 0, limit)
+	for rows.Next() {
+		record, err := scanExperiment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan experiment: %w", err)
+		}
+		out = append(out, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list experiments: %w", err)
+	}
+	return out, nil
+}
+
+func (s *RunStore) ExperimentExists(ctx context.Context, experimentID string) (bool, error) {
+	if s == nil || s.db == nil {
+		return false, fmt.Errorf("run store not initialized")
+	}
+	var one int
+	if err := s.db.QueryRowContext(ctx, selectExperimentExistsQuery, strings.TrimSpace(experimentID)).Scan(&one); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func scanExperiment(row rowScanner) (repo.ExperimentRecord, error) {
+	var (
+		record      repo.ExperimentRecord
+		description sql.NullString
+	)
+	if err := row.Scan(
+		&record.ExperimentID,
+		&record.ProjectID,
+		&record.Name,
+		&description,
+		&record.Metadata,
+		&record.CreatedAt,
+		&record.CreatedBy,
+		&record.IntegritySHA256,
+	); err != nil {
+		return repo.ExperimentRecord{}, err
+	}
+	record.Description = description.String
+	return record, nil
+}
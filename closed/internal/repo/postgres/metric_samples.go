@@ -0,0 +1,138 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+)
+
+type MetricStore struct {
+	db DB
+}
+
+const (
+	insertMetricSampleQuery = `INSERT INTO experiment_run_metric_samples (
+			sample_id,
+			run_id,
+			recorded_at,
+			recorded_by,
+			step,
+			name,
+			value,
+			metadata,
+			integrity_sha256
+		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
+		ON CONFLICT (run_id, name, step) DO NOTHING`
+	selectMetricSeriesQuery = `SELECT sample_id, run_id, recorded_at, recorded_by, step, name, value, metadata, integrity_sha256
+		FROM experiment_run_metric_samples
+		WHERE run_id = $1 AND name = $2
+		ORDER BY step DESC
+		LIMIT $3`
+	selectMetricLatestQuery = `SELECT DISTINCT ON (name) sample_id, run_id, recorded_at, recorded_by, step, name, value, metadata, integrity_sha256
+		FROM experiment_run_metric_samples
+		WHERE run_id = $1
+		ORDER BY name, step DESC
+		LIMIT $2`
+)
+
+func NewMetricStore(db DB) *MetricStore {
+	if db == nil {
+		return nil
+	}
+	return &MetricStore{db: db}
+}
+
+func (s *MetricStore) InsertSample(ctx context.Context, sample repo.MetricSampleRecord) (bool, error) {
+	if s == nil || s.db == nil {
+		return false, fmt.Errorf("metric store not initialized")
+	}
+	if strings.TrimSpace(sample.RunID) == "" {
+		return false, fmt.Errorf("run id is required")
+	}
+	if strings.TrimSpace(sample.Name) == "" {
+		return false, fmt.Errorf("metric name is required")
+	}
+	if err := requireIntegrity(sample.IntegritySHA256); err != nil {
+		return false, err
+	}
+	metadata := sample.Metadata
+	if len(metadata) == 0 {
+		metadata = []byte("{}")
+	}
+	res, err := s.db.ExecContext(
+		ctx,
+		insertMetricSampleQuery,
+		sample.SampleID,
+		sample.RunID,
+		normalizeTime(sample.RecordedAt),
+		sample.RecordedBy,
+		sample.Step,
+		sample.Name,
+		sample.Value,
+		metadata,
+		sample.IntegritySHA256,
+	)
+	if err != nil {
+		return false, fmt.Errorf("insert metric sample: %w", err)
+	}
+	affected, _ := res.RowsAffected()
+	return affected > 0, nil
+}
+
+// ListSamples returns one series in ascending step order, or the latest
+// sample of each series ordered by name.
+func (s *MetricStore) ListSamples(ctx context.Context, filter repo.MetricSampleFilter) ([]repo.MetricSampleRecord, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("metric store not initialized")
+	}
+	runID := strings.TrimSpace(filter.RunID)
+	if runID == "" {
+		return nil, fmt.Errorf("run id is required")
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 200
+	}
+	name := strings.TrimSpace(filter.Name)
+
+	query, args := selectMetricLatestQuery, []any{runID, limit}
+	if name != "" {
+		query, args = selectMetricSeriesQuery, []any{runID, name, limit}
+	}
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list metric samples: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]repo.MetricSampleRecord, 0, limit)
+	for rows.Next() {
+		var sample repo.MetricSampleRecord
+		if err := rows.Scan(
+			&sample.SampleID,
+			&sample.RunID,
+			&sample.RecordedAt,
+			&sample.RecordedBy,
+			&sample.Step,
+			&sample.Name,
+			&sample.Value,
+			&sample.Metadata,
+			&sample.IntegritySHA256,
+		); err != nil {
+			return nil, fmt.Errorf("scan metric sample: %w", err)
+		}
+		out = append(out, sample)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list metric samples: %w", err)
+	}
+	// The series query takes the newest steps; return them oldest first.
+	if name != "" {
+		for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+			out[i], out[j] = out[j], out[i]
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,288 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/integrations/digests"
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+)
+
+type NotificationDigestStore struct {
+	db DB
+}
+
+const (
+	notificationDigestColumns = `digest_id, project_id, name, subject, cadence, hour_utc, weekday, channel, recipients, enabled, next_run_at, last_sent_at, created_at, created_by, updated_at`
+
+	insertNotificationDigestQuery = `INSERT INTO notification_digests (
+			digest_id,
+			project_id,
+			name,
+			subject,
+			cadence,
+			hour_utc,
+			weekday,
+			channel,
+			recipients,
+			enabled,
+			next_run_at,
+			created_at,
+			created_by,
+			updated_at
+		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14)
+		RETURNING ` + notificationDigestColumns
+	updateNotificationDigestQuery = `UPDATE notification_digests
+		SET name = $3,
+			cadence = $4,
+			hour_utc = $5,
+			weekday = $6,
+			channel = $7,
+			recipients = $8,
+			enabled = $9,
+			next_run_at = $10,
+			updated_at = $11
+		WHERE project_id = $1 AND digest_id = $2
+		RETURNING ` + notificationDigestColumns
+	selectNotificationDigestQuery = `SELECT ` + notificationDigestColumns + `
+		FROM notification_digests
+		WHERE project_id = $1 AND digest_id = $2`
+	listNotificationDigestsQuery = `SELECT ` + notificationDigestColumns + `
+		FROM notification_digests
+		WHERE project_id = $1
+		ORDER BY created_at ASC, digest_id ASC
+		LIMIT $2`
+	deleteNotificationDigestQuery = `DELETE FROM notification_digests
+		WHERE project_id = $1 AND digest_id = $2`
+	listDueNotificationDigestsQuery = `SELECT ` + notificationDigestColumns + `
+		FROM notification_digests
+		WHERE enabled = true AND next_run_at <= $1
+		ORDER BY next_run_at ASC
+		LIMIT $2`
+	// The compare-and-set on next_run_at lets only one replica claim a period.
+	claimNotificationDigestQuery = `UPDATE notification_digests
+		SET next_run_at = $3,
+			last_sent_at = $4
+		WHERE digest_id = $1 AND next_run_at = $2`
+)
+
+func NewNotificationDigestStore(db DB) *NotificationDigestStore {
+	if db == nil {
+		return nil
+	}
+	return &NotificationDigestStore{db: db}
+}
+
+func (s *NotificationDigestStore) Create(ctx context.Context, record digests.Digest) (digests.Digest, error) {
+	if s == nil || s.db == nil {
+		return digests.Digest{}, fmt.Errorf("notification digest store not initialized")
+	}
+	record.ID = strings.TrimSpace(record.ID)
+	record.ProjectID = strings.TrimSpace(record.ProjectID)
+	record.Name = strings.TrimSpace(record.Name)
+	if record.ID == "" || record.ProjectID == "" || record.Name == "" {
+		return digests.Digest{}, fmt.Errorf("id, project_id, name are required")
+	}
+	row := s.db.QueryRowContext(
+		ctx,
+		insertNotificationDigestQuery,
+		record.ID,
+		record.ProjectID,
+		record.Name,
+		nullString(record.Subject),
+		string(record.Cadence),
+		record.HourUTC,
+		int(record.Weekday),
+		string(record.Channel),
+		encodeRecipients(record.Recipients),
+		record.Enabled,
+		record.NextRunAt.UTC(),
+		normalizeTime(record.CreatedAt),
+		record.CreatedBy,
+		normalizeTime(record.UpdatedAt),
+	)
+	return scanNotificationDigest(row)
+}
+
+// Update rewrites the schedule and delivery settings; the subject of a digest
+// is fixed at creation.
+func (s *NotificationDigestStore) Update(ctx context.Context, record digests.Digest) (digests.Digest, error) {
+	if s == nil || s.db == nil {
+		return digests.Digest{}, fmt.Errorf("notification digest store not initialized")
+	}
+	record.ID = strings.TrimSpace(record.ID)
+	record.ProjectID = strings.TrimSpace(record.ProjectID)
+	record.Name = strings.TrimSpace(record.Name)
+	if record.ID == "" || record.ProjectID == "" || record.Name == "" {
+		return digests.Digest{}, fmt.Errorf("id, project_id, name are required")
+	}
+	row := s.db.QueryRowContext(
+		ctx,
+		updateNotificationDigestQuery,
+		record.ProjectID,
+		record.ID,
+		record.Name,
+		string(record.Cadence),
+		record.HourUTC,
+		int(record.Weekday),
+		string(record.Channel),
+		encodeRecipients(record.Recipients),
+		record.Enabled,
+		record.NextRunAt.UTC(),
+		normalizeTime(record.UpdatedAt),
+	)
+	out, err := scanNotificationDigest(row)
+	if err != nil {
+		return digests.Digest{}, handleNotFound(err)
+	}
+	return out, nil
+}
+
+func (s *NotificationDigestStore) Get(ctx context.Context, projectID, digestID string) (digests.Digest, error) {
+	if s == nil || s.db == nil {
+		return digests.Digest{}, fmt.Errorf("notification digest store not initialized")
+	}
+	projectID = strings.TrimSpace(projectID)
+	digestID = strings.TrimSpace(digestID)
+	if projectID == "" || digestID == "" {
+		return digests.Digest{}, fmt.Errorf("project_id and digest_id are required")
+	}
+	record, err := scanNotificationDigest(s.db.QueryRowContext(ctx, selectNotificationDigestQuery, projectID, digestID))
+	if err != nil {
+		return digests.Digest{}, handleNotFound(err)
+	}
+	return record, nil
+}
+
+func (s *NotificationDigestStore) List(ctx context.Context, projectID string, limit int) ([]digests.Digest, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("notification digest store not initialized")
+	}
+	projectID = strings.TrimSpace(projectID)
+	if projectID == "" {
+		return nil, fmt.Errorf("project_id is required")
+	}
+	if limit <= 0 {
+		limit = 100
+	}
+	return s.query(ctx, listNotificationDigestsQuery, projectID, limit)
+}
+
+// ListDue returns enabled digests whose next run is at or before now.
+func (s *NotificationDigestStore) ListDue(ctx context.Context, now time.Time, limit int) ([]digests.Digest, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("notification digest store not initialized")
+	}
+	if limit <= 0 {
+		limit = 50
+	}
+	return s.query(ctx, listDueNotificationDigestsQuery, now.UTC(), limit)
+}
+
+func (s *NotificationDigestStore) Delete(ctx context.Context, projectID, digestID string) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("notification digest store not initialized")
+	}
+	res, err := s.db.ExecContext(ctx, deleteNotificationDigestQuery, strings.TrimSpace(projectID), strings.TrimSpace(digestID))
+	if err != nil {
+		return err
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return repo.ErrNotFound
+	}
+	return nil
+}
+
+// Claim advances a due digest to its next run. It reports false when another
+// replica already claimed the period.
+func (s *NotificationDigestStore) Claim(ctx context.Context, digestID string, dueAt, nextRunAt, sentAt time.Time) (bool, error) {
+	if s == nil || s.db == nil {
+		return false, fmt.Errorf("notification digest store not initialized")
+	}
+	res, err := s.db.ExecContext(ctx, claimNotificationDigestQuery, strings.TrimSpace(digestID), dueAt.UTC(), nextRunAt.UTC(), sentAt.UTC())
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+func (s *NotificationDigestStore) query(ctx context.Context, query string, args ...any) ([]digests.Digest, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := make([]digests.Digest, 0)
+	for rows.Next() {
+		record, err := scanNotificationDigest(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func encodeRecipients(input []string) []string {
+	if input == nil {
+		return []string{}
+	}
+	return input
+}
+
+func scanNotificationDigest(row rowScanner) (digests.Digest, error) {
+	var (
+		record     digests.Digest
+		subject    sql.NullString
+		cadence    string
+		weekday    int
+		channel    string
+		recipients []string
+		lastSentAt sql.NullTime
+	)
+	if err := row.Scan(
+		&record.ID,
+		&record.ProjectID,
+		&record.Name,
+		&subject,
+		&cadence,
+		&record.HourUTC,
+		&weekday,
+		&channel,
+		&recipients,
+		&record.Enabled,
+		&record.NextRunAt,
+		&lastSentAt,
+		&record.CreatedAt,
+		&record.CreatedBy,
+		&record.UpdatedAt,
+	); err != nil {
+		return digests.Digest{}, err
+	}
+	record.Subject = strings.TrimSpace(subject.String)
+	record.Cadence = digests.Cadence(cadence)
+	record.Weekday = time.Weekday(weekday)
+	record.Channel = digests.Channel(channel)
+	record.Recipients = recipients
+	if record.Recipients == nil {
+		record.Recipients = []string{}
+	}
+	record.NextRunAt = record.NextRunAt.UTC()
+	if lastSentAt.Valid {
+		t := lastSentAt.Time.UTC()
+		record.LastSentAt = &t
+	}
+	record.CreatedAt = record.CreatedAt.UTC()
+	record.UpdatedAt = record.UpdatedAt.UTC()
+	return record, nil
+}
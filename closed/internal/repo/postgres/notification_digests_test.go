@@ -0,0 +1,32 @@
+package postgres
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNotificationDigestQueriesProjectScoped(t *testing.T) {
+	queries := []string{
+		updateNotificationDigestQuery,
+		selectNotificationDigestQuery,
+		listNotificationDigestsQuery,
+		deleteNotificationDigestQuery,
+	}
+	for _, query := range queries {
+		if !strings.Contains(query, "project_id = $1") {
+			t.Fatalf("expected project scoping in query: %s", query)
+		}
+	}
+}
+
+func TestNotificationDigestClaimComparesNextRun(t *testing.T) {
+	if !strings.Contains(claimNotificationDigestQuery, "next_run_at = $2") {
+		t.Fatalf("expected compare-and-set on next_run_at: %s", claimNotificationDigestQuery)
+	}
+}
+
+func TestNewNotificationDigestStoreNilDB(t *testing.T) {
+	if NewNotificationDigestStore(nil) != nil {
+		t.Fatalf("expected nil store for nil db")
+	}
+}
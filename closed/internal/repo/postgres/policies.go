@@ -0,0 +1,377 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+)
+
+type PolicyStore struct {
+	db DB
+}
+
+const (
+	policyWithLatestColumns = `SELECT p.policy_id,
+			p.name,
+			p.description,
+			p.created_at,
+			p.created_by,
+			p.integrity_sha256,
+			v.policy_version_id,
+			v.version,
+			v.status,
+			v.spec_sha256,
+			v.created_at,
+			v.created_by
+		FROM policies p
+		LEFT JOIN LATERAL (
+			SELECT policy_version_id, version, status, spec_sha256, created_at, created_by
+			FROM policy_versions
+			WHERE policy_id = p.policy_id
+			ORDER BY version DESC
+			LIMIT 1
+		) v ON true`
+	selectPolicyListQuery = policyWithLatestColumns + `
+		ORDER BY p.created_at DESC
+		LIMIT $1`
+	selectPolicyByIDQuery = policyWithLatestColumns + `
+		WHERE p.policy_id = $1`
+	insertPolicyQuery = `INSERT INTO policies (
+			policy_id,
+			name,
+			description,
+			created_at,
+			created_by,
+			integrity_sha256
+		) VALUES ($1,$2,$3,$4,$5,$6)`
+	insertPolicyVersionQuery = `INSERT INTO policy_versions (
+			policy_version_id,
+			policy_id,
+			version,
+			status,
+			spec_yaml,
+			spec_json,
+			spec_sha256,
+			created_at,
+			created_by,
+			integrity_sha256
+		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)`
+	selectPolicyVersionListQuery = `SELECT policy_version_id,
+			policy_id,
+			version,
+			status,
+			spec_yaml,
+			spec_json,
+			spec_sha256,
+			created_at,
+			created_by,
+			integrity_sha256
+		FROM policy_versions
+		WHERE policy_id = $1
+		ORDER BY version DESC
+		LIMIT $2`
+	policyDecisionColumns = `SELECT d.decision_id,
+			d.run_id,
+			d.policy_id,
+			p.name,
+			d.policy_version_id,
+			d.policy_sha256,
+			d.context_sha256,
+			d.context,
+			d.decision,
+			d.rule_id,
+			d.reason,
+			d.bundle_revision,
+			d.created_at,
+			d.created_by
+		FROM policy_decisions d
+		JOIN policies p ON p.policy_id = d.policy_id`
+	selectPolicyDecisionListQuery = policyDecisionColumns + `
+		WHERE ($1 = '' OR d.run_id = $1)
+		ORDER BY d.created_at DESC
+		LIMIT $2`
+	selectPolicyDecisionByIDQuery = policyDecisionColumns + `
+		WHERE d.decision_id = $1`
+)
+
+func NewPolicyStore(db DB) *PolicyStore {
+	if db == nil {
+		return nil
+	}
+	return &PolicyStore{db: db}
+}
+
+func (s *PolicyStore) ListPolicies(ctx context.Context, limit int) ([]repo.PolicyRecord, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("policy store not initialized")
+	}
+	if limit <= 0 {
+		limit = 100
+	}
+	rows, err := s.db.QueryContext(ctx, selectPolicyListQuery, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list policies: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]repo.PolicyRecord, 0, limit)
+	for rows.Next() {
+		record, err := scanPolicy(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan policy: %w", err)
+		}
+		out = append(out, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list policies: %w", err)
+	}
+	return out, nil
+}
+
+func (s *PolicyStore) GetPolicy(ctx context.Context, policyID string) (repo.PolicyRecord, error) {
+	if s == nil || s.db == nil {
+		return repo.PolicyRecord{}, fmt.Errorf("policy store not initialized")
+	}
+	policyID = strings.TrimSpace(policyID)
+	if policyID == "" {
+		return repo.PolicyRecord{}, fmt.Errorf("policy id is required")
+	}
+	record, err := scanPolicy(s.db.QueryRowContext(ctx, selectPolicyByIDQuery, policyID))
+	if err != nil {
+		return repo.PolicyRecord{}, handleNotFound(err)
+	}
+	return record, nil
+}
+
+func (s *PolicyStore) CreatePolicy(ctx context.Context, policy repo.PolicyRecord) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("policy store not initialized")
+	}
+	if strings.TrimSpace(policy.PolicyID) == "" {
+		return fmt.Errorf("policy id is required")
+	}
+	if strings.TrimSpace(policy.Name) == "" {
+		return fmt.Errorf("policy name is required")
+	}
+	if err := requireIntegrity(policy.IntegritySHA256); err != nil {
+		return err
+	}
+	_, err := s.db.ExecContext(
+		ctx,
+		insertPolicyQuery,
+		policy.PolicyID,
+		policy.Name,
+		nullIfEmpty(policy.Description),
+		normalizeTime(policy.CreatedAt),
+		policy.CreatedBy,
+		policy.IntegritySHA256,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return repo.ErrConflict
+		}
+		return fmt.Errorf("insert policy: %w", err)
+	}
+	return nil
+}
+
+func (s *PolicyStore) CreatePolicyVersion(ctx context.Context, version repo.PolicyVersionRecord) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("policy store not initialized")
+	}
+	if strings.TrimSpace(version.PolicyID) == "" {
+		return fmt.Errorf("policy id is required")
+	}
+	if version.Version <= 0 {
+		return fmt.Errorf("policy version must be positive")
+	}
+	if err := requireIntegrity(version.IntegritySHA256); err != nil {
+		return err
+	}
+	_, err := s.db.ExecContext(
+		ctx,
+		insertPolicyVersionQuery,
+		version.PolicyVersionID,
+		version.PolicyID,
+		version.Version,
+		version.Status,
+		version.SpecYAML,
+		version.SpecJSON,
+		version.SpecSHA256,
+		normalizeTime(version.CreatedAt),
+		version.CreatedBy,
+		version.IntegritySHA256,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return repo.ErrConflict
+		}
+		return fmt.Errorf("insert policy version: %w", err)
+	}
+	return nil
+}
+
+func (s *PolicyStore) ListPolicyVersions(ctx context.Context, policyID string, limit int) ([]repo.PolicyVersionRecord, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("policy store not initialized")
+	}
+	policyID = strings.TrimSpace(policyID)
+	if policyID == "" {
+		return nil, fmt.Errorf("policy id is required")
+	}
+	if limit <= 0 {
+		limit = 100
+	}
+	rows, err := s.db.QueryContext(ctx, selectPolicyVersionListQuery, policyID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list policy versions: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]repo.PolicyVersionRecord, 0, limit)
+	for rows.Next() {
+		var version repo.PolicyVersionRecord
+		if err := rows.Scan(
+			&version.PolicyVersionID,
+			&version.PolicyID,
+			&version.Version,
+			&version.Status,
+			&version.SpecYAML,
+			&version.SpecJSON,
+			&version.SpecSHA256,
+			&version.CreatedAt,
+			&version.CreatedBy,
+			&version.IntegritySHA256,
+		); err != nil {
+			return nil, fmt.Errorf("scan policy version: %w", err)
+		}
+		version.Status = strings.TrimSpace(version.Status)
+		version.SpecSHA256 = strings.TrimSpace(version.SpecSHA256)
+		out = append(out, version)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list policy versions: %w", err)
+	}
+	return out, nil
+}
+
+func (s *PolicyStore) ListPolicyDecisions(ctx context.Context, filter repo.PolicyDecisionFilter) ([]repo.PolicyDecisionRecord, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("policy store not initialized")
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	rows, err := s.db.QueryContext(ctx, selectPolicyDecisionListQuery, strings.TrimSpace(filter.RunID), limit)
+	if err != nil {
+		return nil, fmt.Errorf("list policy decisions: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]repo.PolicyDecisionRecord, 0, limit)
+	for rows.Next() {
+		record, err := scanPolicyDecision(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan policy decision: %w", err)
+		}
+		out = append(out, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list policy decisions: %w", err)
+	}
+	return out, nil
+}
+
+func (s *PolicyStore) GetPolicyDecision(ctx context.Context, decisionID string) (repo.PolicyDecisionRecord, error) {
+	if s == nil || s.db == nil {
+		return repo.PolicyDecisionRecord{}, fmt.Errorf("policy store not initialized")
+	}
+	decisionID = strings.TrimSpace(decisionID)
+	if decisionID == "" {
+		return repo.PolicyDecisionRecord{}, fmt.Errorf("decision id is required")
+	}
+	record, err := scanPolicyDecision(s.db.QueryRowContext(ctx, selectPolicyDecisionByIDQuery, decisionID))
+	if err != nil {
+		return repo.PolicyDecisionRecord{}, handleNotFound(err)
+	}
+	return record, nil
+}
+
+func scanPolicy(row rowScanner) (repo.PolicyRecord, error) {
+	var (
+		record           repo.PolicyRecord
+		description      sql.NullString
+		versionID        sql.NullString
+		versionNum       sql.NullInt64
+		versionStatus    sql.NullString
+		specSHA          sql.NullString
+		versionCreatedAt sql.NullTime
+		versionCreatedBy sql.NullString
+	)
+	if err := row.Scan(
+		&record.PolicyID,
+		&record.Name,
+		&description,
+		&record.CreatedAt,
+		&record.CreatedBy,
+		&record.IntegritySHA256,
+		&versionID,
+		&versionNum,
+		&versionStatus,
+		&specSHA,
+		&versionCreatedAt,
+		&versionCreatedBy,
+	); err != nil {
+		return repo.PolicyRecord{}, err
+	}
+	record.Description = strings.TrimSpace(description.String)
+	if versionID.Valid && versionNum.Valid && versionStatus.Valid && specSHA.Valid && versionCreatedAt.Valid && versionCreatedBy.Valid {
+		record.Latest = &repo.PolicyVersionRecord{
+			PolicyVersionID: versionID.String,
+			PolicyID:        record.PolicyID,
+			Version:         int(versionNum.Int64),
+			Status:          strings.TrimSpace(versionStatus.String),
+			SpecSHA256:      strings.TrimSpace(specSHA.String),
+			CreatedAt:       versionCreatedAt.Time.UTC(),
+			CreatedBy:       strings.TrimSpace(versionCreatedBy.String),
+		}
+	}
+	return record, nil
+}
+
+func scanPolicyDecision(row rowScanner) (repo.PolicyDecisionRecord, error) {
+	var (
+		record    repo.PolicyDecisionRecord
+		runID     sql.NullString
+		ruleID    sql.NullString
+		reason    sql.NullString
+		bundleRev sql.NullString
+	)
+	if err := row.Scan(
+		&record.DecisionID,
+		&runID,
+		&record.PolicyID,
+		&record.PolicyName,
+		&record.PolicyVersionID,
+		&record.PolicySHA256,
+		&record.ContextSHA256,
+		&record.Context,
+		&record.Decision,
+		&ruleID,
+		&reason,
+		&bundleRev,
+		&record.CreatedAt,
+		&record.CreatedBy,
+	); err != nil {
+		return repo.PolicyDecisionRecord{}, err
+	}
+	record.RunID = strings.TrimSpace(runID.String)
+	record.RuleID = strings.TrimSpace(ruleID.String)
+	record.Reason = strings.TrimSpace(reason.String)
+	record.BundleRevision = strings.TrimSpace(bundleRev.String)
+	return record, nil
+}
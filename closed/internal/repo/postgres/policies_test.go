@@ -0,0 +1,28 @@
+package postgres
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPolicyQueriesSelectLatestVersion(t *testing.T) {
+	for _, query := range []string{selectPolicyListQuery, selectPolicyByIDQuery} {
+		if !strings.Contains(query, "ORDER BY version DESC") || !strings.Contains(query, "LIMIT 1") {
+			t.Fatalf("expected latest version join in query: %s", query)
+		}
+	}
+}
+
+func TestPolicyDecisionListRunFilterIsOptional(t *testing.T) {
+	if !strings.Contains(selectPolicyDecisionListQuery, "($1 = '' OR d.run_id = $1)") {
+		t.Fatalf("expected optional run filter in query: %s", selectPolicyDecisionListQuery)
+	}
+}
+
+func TestPolicyApprovalListFiltersAreOptional(t *testing.T) {
+	for _, clause := range []string{"($1 = '' OR a.status = $1)", "($2 = '' OR a.run_id = $2)", "LIMIT $3"} {
+		if !strings.Contains(selectPolicyApprovalListQuery, clause) {
+			t.Fatalf("expected %q in query: %s", clause, selectPolicyApprovalListQuery)
+		}
+	}
+}
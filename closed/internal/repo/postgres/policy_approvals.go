@@ -0,0 +1,192 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+)
+
+const (
+	policyApprovalColumns = `SELECT a.approval_id,
+			a.decision_id,
+			a.run_id,
+			a.status,
+			a.requested_at,
+			a.requested_by,
+			a.decided_at,
+			a.decided_by,
+			a.reason,
+			d.policy_id,
+			p.name,
+			d.policy_version_id,
+			d.decision,
+			d.rule_id,
+			d.context,
+			a.workflow,
+			a.current_stage,
+			a.escalate_at,
+			a.assigned_to
+		FROM policy_approvals a
+		JOIN policy_decisions d ON d.decision_id = a.decision_id
+		JOIN policies p ON p.policy_id = d.policy_id`
+	selectPolicyApprovalListQuery = policyApprovalColumns + `
+		WHERE ($1 = '' OR a.status = $1)
+			AND ($2 = '' OR a.run_id = $2)
+		ORDER BY a.requested_at DESC
+		LIMIT $3`
+	selectPolicyApprovalByIDQuery = policyApprovalColumns + `
+		WHERE a.approval_id = $1`
+	selectPolicyApprovalVotesQuery = `SELECT vote_id, stage, stage_name, voter, on_behalf_of, vote, reason, created_at
+		FROM policy_approval_votes
+		WHERE approval_id = $1
+		ORDER BY stage, created_at`
+	countRunApprovalsQuery = `SELECT COUNT(1)
+		FROM policy_approvals
+		WHERE run_id = $1 AND status = $2`
+)
+
+func (s *PolicyStore) ListPolicyApprovals(ctx context.Context, filter repo.PolicyApprovalFilter) ([]repo.PolicyApprovalRecord, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("policy store not initialized")
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	rows, err := s.db.QueryContext(
+		ctx,
+		selectPolicyApprovalListQuery,
+		strings.ToLower(strings.TrimSpace(filter.Status)),
+		strings.TrimSpace(filter.RunID),
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list policy approvals: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]repo.PolicyApprovalRecord, 0, limit)
+	for rows.Next() {
+		record, err := scanPolicyApproval(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan policy approval: %w", err)
+		}
+		// Listings leave out the decision context.
+		record.Context = nil
+		out = append(out, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list policy approvals: %w", err)
+	}
+	return out, nil
+}
+
+func (s *PolicyStore) GetPolicyApproval(ctx context.Context, approvalID string) (repo.PolicyApprovalRecord, error) {
+	if s == nil || s.db == nil {
+		return repo.PolicyApprovalRecord{}, fmt.Errorf("policy store not initialized")
+	}
+	approvalID = strings.TrimSpace(approvalID)
+	if approvalID == "" {
+		return repo.PolicyApprovalRecord{}, fmt.Errorf("approval id is required")
+	}
+	record, err := scanPolicyApproval(s.db.QueryRowContext(ctx, selectPolicyApprovalByIDQuery, approvalID))
+	if err != nil {
+		return repo.PolicyApprovalRecord{}, handleNotFound(err)
+	}
+	return record, nil
+}
+
+func (s *PolicyStore) ListPolicyApprovalVotes(ctx context.Context, approvalID string) ([]repo.PolicyApprovalVoteRecord, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("policy store not initialized")
+	}
+	rows, err := s.db.QueryContext(ctx, selectPolicyApprovalVotesQuery, strings.TrimSpace(approvalID))
+	if err != nil {
+		return nil, fmt.Errorf("list policy approval votes: %w", err)
+	}
+	defer rows.Close()
+
+	out := []repo.PolicyApprovalVoteRecord{}
+	for rows.Next() {
+		var (
+			vote       repo.PolicyApprovalVoteRecord
+			onBehalfOf sql.NullString
+			reason     sql.NullString
+		)
+		if err := rows.Scan(&vote.VoteID, &vote.Stage, &vote.StageName, &vote.Voter, &onBehalfOf, &vote.Vote, &reason, &vote.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan policy approval vote: %w", err)
+		}
+		vote.OnBehalfOf = strings.TrimSpace(onBehalfOf.String)
+		vote.Reason = strings.TrimSpace(reason.String)
+		out = append(out, vote)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list policy approval votes: %w", err)
+	}
+	return out, nil
+}
+
+func (s *PolicyStore) CountRunApprovals(ctx context.Context, runID, status string) (int, error) {
+	if s == nil || s.db == nil {
+		return 0, fmt.Errorf("policy store not initialized")
+	}
+	var count int
+	if err := s.db.QueryRowContext(ctx, countRunApprovalsQuery, strings.TrimSpace(runID), status).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count run approvals: %w", err)
+	}
+	return count, nil
+}
+
+func scanPolicyApproval(row rowScanner) (repo.PolicyApprovalRecord, error) {
+	var (
+		record     repo.PolicyApprovalRecord
+		runID      sql.NullString
+		decidedAt  sql.NullTime
+		decidedBy  sql.NullString
+		reason     sql.NullString
+		ruleID     sql.NullString
+		escalateAt sql.NullTime
+		assignedTo sql.NullString
+	)
+	if err := row.Scan(
+		&record.ApprovalID,
+		&record.DecisionID,
+		&runID,
+		&record.Status,
+		&record.RequestedAt,
+		&record.RequestedBy,
+		&decidedAt,
+		&decidedBy,
+		&reason,
+		&record.PolicyID,
+		&record.PolicyName,
+		&record.PolicyVersionID,
+		&record.Decision,
+		&ruleID,
+		&record.Context,
+		&record.Workflow,
+		&record.CurrentStage,
+		&escalateAt,
+		&assignedTo,
+	); err != nil {
+		return repo.PolicyApprovalRecord{}, err
+	}
+	record.RunID = strings.TrimSpace(runID.String)
+	record.Status = strings.TrimSpace(record.Status)
+	if decidedAt.Valid && !decidedAt.Time.IsZero() {
+		t := decidedAt.Time.UTC()
+		record.DecidedAt = &t
+	}
+	record.DecidedBy = strings.TrimSpace(decidedBy.String)
+	record.Reason = strings.TrimSpace(reason.String)
+	record.RuleID = strings.TrimSpace(ruleID.String)
+	if escalateAt.Valid {
+		t := escalateAt.Time.UTC()
+		record.EscalateAt = &t
+	}
+	record.AssignedTo = strings.TrimSpace(assignedTo.String)
+	return record, nil
+}
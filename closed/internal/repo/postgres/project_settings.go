@@ -0,0 +1,118 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/domain"
+)
+
+// ProjectSettingsStore reads and writes project defaults for the registry
+// (uploads, artifacts) and experiments (runs).
+type ProjectSettingsStore struct {
+	db DB
+}
+
+func NewProjectSettingsStore(db DB) *ProjectSettingsStore {
+	if db == nil {
+		return nil
+	}
+	return &ProjectSettingsStore{db: db}
+}
+
+// Get returns empty settings for projects that never stored any.
+func (s *ProjectSettingsStore) Get(ctx context.Context, projectID string) (domain.ProjectSettings, error) {
+	if s == nil || s.db == nil {
+		return domain.ProjectSettings{}, fmt.Errorf("project settings store not initialized")
+	}
+	projectID = strings.TrimSpace(projectID)
+	var (
+		qualityRuleID sql.NullString
+		policyIDsJSON []byte
+		retentionDays sql.NullInt64
+		updatedAt     sql.NullTime
+		updatedBy     string
+	)
+	err := s.db.QueryRowContext(
+		ctx,
+		`SELECT default_quality_rule_id, default_policy_ids, artifact_retention_days, updated_at, updated_by
+		 FROM project_settings
+		 WHERE project_id = $1`,
+		projectID,
+	).Scan(&qualityRuleID, &policyIDsJSON, &retentionDays, &updatedAt, &updatedBy)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ProjectSettings{ProjectID: projectID}, nil
+		}
+		return domain.ProjectSettings{}, err
+	}
+	out := domain.ProjectSettings{
+		ProjectID:             projectID,
+		DefaultQualityRuleID:  qualityRuleID.String,
+		ArtifactRetentionDays: int(retentionDays.Int64),
+		UpdatedBy:             updatedBy,
+	}
+	if len(policyIDsJSON) > 0 {
+		if err := json.Unmarshal(policyIDsJSON, &out.DefaultPolicyIDs); err != nil {
+			return domain.ProjectSettings{}, fmt.Errorf("decode default policy ids: %w", err)
+		}
+	}
+	if updatedAt.Valid {
+		t := updatedAt.Time.UTC()
+		out.UpdatedAt = &t
+	}
+	return out, nil
+}
+
+// Put replaces the stored settings of a project.
+func (s *ProjectSettingsStore) Put(ctx context.Context, settings domain.ProjectSettings) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("project settings store not initialized")
+	}
+	policyIDs := settings.DefaultPolicyIDs
+	if policyIDs == nil {
+		policyIDs = []string{}
+	}
+	policyIDsJSON, err := json.Marshal(policyIDs)
+	if err != nil {
+		return fmt.Errorf("encode default policy ids: %w", err)
+	}
+	var retentionDays *int
+	if settings.ArtifactRetentionDays > 0 {
+		retentionDays = &settings.ArtifactRetentionDays
+	}
+	var qualityRuleID *string
+	if v := strings.TrimSpace(settings.DefaultQualityRuleID); v != "" {
+		qualityRuleID = &v
+	}
+	updatedAt := time.Now().UTC()
+	if settings.UpdatedAt != nil {
+		updatedAt = normalizeTime(*settings.UpdatedAt)
+	}
+	_, err = s.db.ExecContext(
+		ctx,
+		`INSERT INTO project_settings (project_id, default_quality_rule_id, default_policy_ids, artifact_retention_days, updated_at, updated_by)
+		 VALUES ($1,$2,$3,$4,$5,$6)
+		 ON CONFLICT (project_id) DO UPDATE
+		 SET default_quality_rule_id = EXCLUDED.default_quality_rule_id,
+			 default_policy_ids = EXCLUDED.default_policy_ids,
+			 artifact_retention_days = EXCLUDED.artifact_retention_days,
+			 updated_at = EXCLUDED.updated_at,
+			 updated_by = EXCLUDED.updated_by`,
+		strings.TrimSpace(settings.ProjectID),
+		qualityRuleID,
+		policyIDsJSON,
+		retentionDays,
+		updatedAt,
+		strings.TrimSpace(settings.UpdatedBy),
+	)
+	if err != nil {
+		return fmt.Errorf("put project settings: %w", err)
+	}
+	return nil
+}
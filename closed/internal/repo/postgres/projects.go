@@ -2,8 +2,10 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/animus-labs/animus-go/closed/internal/domain"
 	"github.com/animus-labs/animus-go/closed/internal/repo"
@@ -13,6 +15,8 @@ type ProjectStore struct {
 	db DB
 }
 
+const projectColumns = `project_id, name, description, metadata, created_at, created_by, updated_at, updated_by, archived_at, archived_by, integrity_sha256`
+
 func NewProjectStore(db DB) *ProjectStore {
 	if db == nil {
 		return nil
@@ -68,25 +72,11 @@ func (s *ProjectStore) Get(ctx context.Context, id string) (domain.Project, erro
 	if id == "" {
 		return domain.Project{}, fmt.Errorf("project id is required")
 	}
-	var (
-		project      domain.Project
-		metadataJSON []byte
-	)
-	row := s.db.QueryRowContext(
-		ctx,
-		`SELECT project_id, name, description, metadata, created_at, created_by, integrity_sha256
-		 FROM projects
-		 WHERE project_id = $1`,
-		id,
-	)
-	if err := row.Scan(&project.ID, &project.Name, &project.Description, &metadataJSON, &project.CreatedAt, &project.CreatedBy, &project.IntegritySHA256); err != nil {
-		return domain.Project{}, handleNotFound(err)
-	}
-	meta, err := decodeMetadata(metadataJSON)
+	row := s.db.QueryRowContext(ctx, `SELECT `+projectColumns+` FROM projects WHERE project_id = $1`, id)
+	project, err := scanProject(row)
 	if err != nil {
-		return domain.Project{}, fmt.Errorf("decode metadata: %w", err)
+		return domain.Project{}, handleNotFound(err)
 	}
-	project.Metadata = meta
 	return project, nil
 }
 
@@ -106,7 +96,11 @@ func (s *ProjectStore) List(ctx context.Context, filter repo.ProjectFilter) ([]d
 		clauses = append(clauses, fmt.Sprintf("created_by = $%d", len(args)))
 	}
 
-	query := `SELECT project_id, name, description, metadata, created_at, created_by, integrity_sha256 FROM projects`
+	if !filter.IncludeArchived {
+		clauses = append(clauses, "archived_at IS NULL")
+	}
+
+	query := `SELECT ` + projectColumns + ` FROM projects`
 	if len(clauses) > 0 {
 		query += " WHERE " + strings.Join(clauses, " AND ")
 	}
@@ -124,16 +118,10 @@ func (s *ProjectStore) List(ctx context.Context, filter repo.ProjectFilter) ([]d
 
 	projects := make([]domain.Project, 0)
 	for rows.Next() {
-		var p domain.Project
-		var metadataJSON []byte
-		if err := rows.Scan(&p.ID, &p.Name, &p.Description, &metadataJSON, &p.CreatedAt, &p.CreatedBy, &p.IntegritySHA256); err != nil {
-			return nil, fmt.Errorf("scan project: %w", err)
-		}
-		meta, err := decodeMetadata(metadataJSON)
+		p, err := scanProject(rows)
 		if err != nil {
-			return nil, fmt.Errorf("decode metadata: %w", err)
+			return nil, fmt.Errorf("scan project: %w", err)
 		}
-		p.Metadata = meta
 		projects = append(projects, p)
 	}
 	if err := rows.Err(); err != nil {
@@ -141,3 +129,116 @@ func (s *ProjectStore) List(ctx context.Context, filter repo.ProjectFilter) ([]d
 	}
 	return projects, nil
 }
+
+// Update rewrites the mutable fields of an active project together with its
+// recomputed integrity hash.
+func (s *ProjectStore) Update(ctx context.Context, project domain.Project) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("project store not initialized")
+	}
+	if err := project.Validate(); err != nil {
+		return err
+	}
+	metadataJSON, err := encodeMetadata(project.Metadata)
+	if err != nil {
+		return fmt.Errorf("encode metadata: %w", err)
+	}
+	var updatedAt *time.Time
+	if project.UpdatedAt != nil {
+		t := normalizeTime(*project.UpdatedAt)
+		updatedAt = &t
+	}
+	res, err := s.db.ExecContext(
+		ctx,
+		`UPDATE projects
+		 SET name = $2,
+			 description = $3,
+			 metadata = $4,
+			 updated_at = $5,
+			 updated_by = $6,
+			 integrity_sha256 = $7
+		 WHERE project_id = $1 AND archived_at IS NULL`,
+		strings.TrimSpace(project.ID),
+		strings.TrimSpace(project.Name),
+		strings.TrimSpace(project.Description),
+		metadataJSON,
+		updatedAt,
+		strings.TrimSpace(project.UpdatedBy),
+		strings.TrimSpace(project.IntegritySHA256),
+	)
+	if err != nil {
+		return fmt.Errorf("update project: %w", err)
+	}
+	rows, err := res.RowsAffected()
+	if err == nil && rows == 0 {
+		return repo.ErrNotFound
+	}
+	return nil
+}
+
+// Archive marks an active project as deleted; rows stay for audit and
+// lineage, so foreign keys from datasets and runs remain valid.
+func (s *ProjectStore) Archive(ctx context.Context, id string, archivedBy string, archivedAt time.Time) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("project store not initialized")
+	}
+	res, err := s.db.ExecContext(
+		ctx,
+		`UPDATE projects SET archived_at = $2, archived_by = $3 WHERE project_id = $1 AND archived_at IS NULL`,
+		strings.TrimSpace(id),
+		normalizeTime(archivedAt),
+		strings.TrimSpace(archivedBy),
+	)
+	if err != nil {
+		return fmt.Errorf("archive project: %w", err)
+	}
+	rows, err := res.RowsAffected()
+	if err == nil && rows == 0 {
+		return repo.ErrNotFound
+	}
+	return nil
+}
+
+func scanProject(row rowScanner) (domain.Project, error) {
+	var (
+		project      domain.Project
+		description  sql.NullString
+		metadataJSON []byte
+		updatedAt    sql.NullTime
+		updatedBy    sql.NullString
+		archivedAt   sql.NullTime
+		archivedBy   sql.NullString
+	)
+	if err := row.Scan(
+		&project.ID,
+		&project.Name,
+		&description,
+		&metadataJSON,
+		&project.CreatedAt,
+		&project.CreatedBy,
+		&updatedAt,
+		&updatedBy,
+		&archivedAt,
+		&archivedBy,
+		&project.IntegritySHA256,
+	); err != nil {
+		return domain.Project{}, err
+	}
+	meta, err := decodeMetadata(metadataJSON)
+	if err != nil {
+		return domain.Project{}, fmt.Errorf("decode metadata: %w", err)
+	}
+	project.Description = description.String
+	project.Metadata = meta
+	project.UpdatedBy = updatedBy.String
+	project.ArchivedBy = archivedBy.String
+	if updatedAt.Valid {
+		t := updatedAt.Time.UTC()
+		project.UpdatedAt = &t
+	}
+	if archivedAt.Valid {
+		t := archivedAt.Time.UTC()
+		project.ArchivedAt = &t
+	}
+	return project, nil
+}
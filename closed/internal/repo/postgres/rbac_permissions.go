@@ -0,0 +1,363 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+	"github.com/google/uuid"
+)
+
+type RBACPermissionStore struct {
+	db DB
+}
+
+const (
+	insertRBACRoleQuery = `INSERT INTO rbac_roles (
+		role_name,
+		description,
+		permissions,
+		created_at,
+		created_by,
+		updated_at,
+		updated_by,
+		integrity_sha256
+	) VALUES ($1,$2,$3,$4,$5,$6,$7,$8)`
+
+	updateRBACRoleQuery = `UPDATE rbac_roles
+		SET description = $1,
+			permissions = $2,
+			updated_at = $3,
+			updated_by = $4,
+			integrity_sha256 = $5
+		WHERE role_name = $6`
+
+	selectRBACRoleQuery = `SELECT role_name, description, permissions,
+		created_at, created_by, updated_at, updated_by, integrity_sha256
+		FROM rbac_roles
+		WHERE role_name = $1`
+
+	listRBACRolesQuery = `SELECT role_name, description, permissions,
+		created_at, created_by, updated_at, updated_by, integrity_sha256
+		FROM rbac_roles
+		ORDER BY role_name ASC`
+
+	insertRBACRoutePermissionQuery = `INSERT INTO rbac_route_permissions (
+		route_id,
+		service,
+		method,
+		path_pattern,
+		permission,
+		created_at,
+		created_by,
+		integrity_sha256
+	) VALUES ($1,$2,$3,$4,$5,$6,$7,$8)`
+
+	listRBACRoutePermissionsQuery = `SELECT route_id, service, method, path_pattern, permission,
+		created_at, created_by, integrity_sha256
+		FROM rbac_route_permissions
+		WHERE ($1 = '' OR service = $1)
+		ORDER BY service ASC, path_pattern ASC, method ASC`
+)
+
+func NewRBACPermissionStore(db DB) *RBACPermissionStore {
+	if db == nil {
+		return nil
+	}
+	return &RBACPermissionStore{db: db}
+}
+
+func (s *RBACPermissionStore) CreateRole(ctx context.Context, record repo.RBACRoleRecord) (repo.RBACRoleRecord, error) {
+	if s == nil || s.db == nil {
+		return repo.RBACRoleRecord{}, fmt.Errorf("rbac permission store not initialized")
+	}
+	record, err := normalizeRBACRole(record)
+	if err != nil {
+		return repo.RBACRoleRecord{}, err
+	}
+	if record.CreatedBy == "" {
+		return repo.RBACRoleRecord{}, fmt.Errorf("created_by is required")
+	}
+	if record.CreatedAt.IsZero() {
+		record.CreatedAt = time.Now().UTC()
+	}
+	if record.UpdatedAt.IsZero() {
+		record.UpdatedAt = record.CreatedAt
+	}
+	if record.UpdatedBy == "" {
+		record.UpdatedBy = record.CreatedBy
+	}
+	permissionsJSON, err := json.Marshal(record.Permissions)
+	if err != nil {
+		return repo.RBACRoleRecord{}, fmt.Errorf("marshal permissions: %w", err)
+	}
+	_, err = s.db.ExecContext(
+		ctx,
+		insertRBACRoleQuery,
+		record.Name,
+		nullIfEmpty(record.Description),
+		permissionsJSON,
+		record.CreatedAt.UTC(),
+		record.CreatedBy,
+		record.UpdatedAt.UTC(),
+		record.UpdatedBy,
+		record.IntegritySHA,
+	)
+	if err != nil {
+		return repo.RBACRoleRecord{}, fmt.Errorf("insert rbac role: %w", err)
+	}
+	return s.GetRole(ctx, record.Name)
+}
+
+func (s *RBACPermissionStore) UpdateRole(ctx context.Context, record repo.RBACRoleRecord) (repo.RBACRoleRecord, error) {
+	if s == nil || s.db == nil {
+		return repo.RBACRoleRecord{}, fmt.Errorf("rbac permission store not initialized")
+	}
+	record, err := normalizeRBACRole(record)
+	if err != nil {
+		return repo.RBACRoleRecord{}, err
+	}
+	if record.UpdatedBy == "" {
+		return repo.RBACRoleRecord{}, fmt.Errorf("updated_by is required")
+	}
+	if record.UpdatedAt.IsZero() {
+		record.UpdatedAt = time.Now().UTC()
+	}
+	permissionsJSON, err := json.Marshal(record.Permissions)
+	if err != nil {
+		return repo.RBACRoleRecord{}, fmt.Errorf("marshal permissions: %w", err)
+	}
+	res, err := s.db.ExecContext(
+		ctx,
+		updateRBACRoleQuery,
+		nullIfEmpty(record.Description),
+		permissionsJSON,
+		record.UpdatedAt.UTC(),
+		record.UpdatedBy,
+		record.IntegritySHA,
+		record.Name,
+	)
+	if err != nil {
+		return repo.RBACRoleRecord{}, fmt.Errorf("update rbac role: %w", err)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return repo.RBACRoleRecord{}, fmt.Errorf("update rbac role: %w", err)
+	}
+	if rows == 0 {
+		return repo.RBACRoleRecord{}, repo.ErrNotFound
+	}
+	return s.GetRole(ctx, record.Name)
+}
+
+func (s *RBACPermissionStore) GetRole(ctx context.Context, name string) (repo.RBACRoleRecord, error) {
+	if s == nil || s.db == nil {
+		return repo.RBACRoleRecord{}, fmt.Errorf("rbac permission store not initialized")
+	}
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return repo.RBACRoleRecord{}, fmt.Errorf("role_name is required")
+	}
+	return scanRBACRole(s.db.QueryRowContext(ctx, selectRBACRoleQuery, name))
+}
+
+func (s *RBACPermissionStore) ListRoles(ctx context.Context) ([]repo.RBACRoleRecord, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("rbac permission store not initialized")
+	}
+	rows, err := s.db.QueryContext(ctx, listRBACRolesQuery)
+	if err != nil {
+		return nil, fmt.Errorf("list rbac roles: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]repo.RBACRoleRecord, 0)
+	for rows.Next() {
+		rec, err := scanRBACRole(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, rec)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list rbac roles: %w", err)
+	}
+	return out, nil
+}
+
+func (s *RBACPermissionStore) DeleteRole(ctx context.Context, name string) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("rbac permission store not initialized")
+	}
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return fmt.Errorf("role_name is required")
+	}
+	res, err := s.db.ExecContext(ctx, `DELETE FROM rbac_roles WHERE role_name = $1`, name)
+	if err != nil {
+		return fmt.Errorf("delete rbac role: %w", err)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("delete rbac role: %w", err)
+	}
+	if rows == 0 {
+		return repo.ErrNotFound
+	}
+	return nil
+}
+
+func (s *RBACPermissionStore) CreateRoutePermission(ctx context.Context, record repo.RBACRoutePermissionRecord) (repo.RBACRoutePermissionRecord, error) {
+	if s == nil || s.db == nil {
+		return repo.RBACRoutePermissionRecord{}, fmt.Errorf("rbac permission store not initialized")
+	}
+	record.RouteID = strings.TrimSpace(record.RouteID)
+	record.Service = strings.ToLower(strings.TrimSpace(record.Service))
+	record.Method = strings.ToUpper(strings.TrimSpace(record.Method))
+	record.PathPattern = strings.TrimSpace(record.PathPattern)
+	record.Permission = strings.ToLower(strings.TrimSpace(record.Permission))
+	record.CreatedBy = strings.TrimSpace(record.CreatedBy)
+	record.IntegritySHA = strings.TrimSpace(record.IntegritySHA)
+	if record.Service == "" || record.Method == "" || record.PathPattern == "" || record.Permission == "" {
+		return repo.RBACRoutePermissionRecord{}, fmt.Errorf("service, method, path_pattern, permission are required")
+	}
+	if record.CreatedBy == "" {
+		return repo.RBACRoutePermissionRecord{}, fmt.Errorf("created_by is required")
+	}
+	if err := requireIntegrity(record.IntegritySHA); err != nil {
+		return repo.RBACRoutePermissionRecord{}, err
+	}
+	if record.RouteID == "" {
+		record.RouteID = uuid.NewString()
+	}
+	record.CreatedAt = normalizeTime(record.CreatedAt)
+
+	_, err := s.db.ExecContext(
+		ctx,
+		insertRBACRoutePermissionQuery,
+		record.RouteID,
+		record.Service,
+		record.Method,
+		record.PathPattern,
+		record.Permission,
+		record.CreatedAt,
+		record.CreatedBy,
+		record.IntegritySHA,
+	)
+	if err != nil {
+		return repo.RBACRoutePermissionRecord{}, fmt.Errorf("insert rbac route permission: %w", err)
+	}
+	return record, nil
+}
+
+func (s *RBACPermissionStore) ListRoutePermissions(ctx context.Context, service string) ([]repo.RBACRoutePermissionRecord, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("rbac permission store not initialized")
+	}
+	service = strings.ToLower(strings.TrimSpace(service))
+	rows, err := s.db.QueryContext(ctx, listRBACRoutePermissionsQuery, service)
+	if err != nil {
+		return nil, fmt.Errorf("list rbac route permissions: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]repo.RBACRoutePermissionRecord, 0)
+	for rows.Next() {
+		var rec repo.RBACRoutePermissionRecord
+		if err := rows.Scan(
+			&rec.RouteID,
+			&rec.Service,
+			&rec.Method,
+			&rec.PathPattern,
+			&rec.Permission,
+			&rec.CreatedAt,
+			&rec.CreatedBy,
+			&rec.IntegritySHA,
+		); err != nil {
+			return nil, fmt.Errorf("scan rbac route permission: %w", err)
+		}
+		out = append(out, rec)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list rbac route permissions: %w", err)
+	}
+	return out, nil
+}
+
+func (s *RBACPermissionStore) DeleteRoutePermission(ctx context.Context, routeID string) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("rbac permission store not initialized")
+	}
+	routeID = strings.TrimSpace(routeID)
+	if routeID == "" {
+		return fmt.Errorf("route_id is required")
+	}
+	res, err := s.db.ExecContext(ctx, `DELETE FROM rbac_route_permissions WHERE route_id = $1`, routeID)
+	if err != nil {
+		return fmt.Errorf("delete rbac route permission: %w", err)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("delete rbac route permission: %w", err)
+	}
+	if rows == 0 {
+		return repo.ErrNotFound
+	}
+	return nil
+}
+
+func normalizeRBACRole(record repo.RBACRoleRecord) (repo.RBACRoleRecord, error) {
+	record.Name = strings.ToLower(strings.TrimSpace(record.Name))
+	record.Description = strings.TrimSpace(record.Description)
+	record.CreatedBy = strings.TrimSpace(record.CreatedBy)
+	record.UpdatedBy = strings.TrimSpace(record.UpdatedBy)
+	record.IntegritySHA = strings.TrimSpace(record.IntegritySHA)
+	record.Permissions = normalizeRoles(record.Permissions)
+	if record.Permissions == nil {
+		record.Permissions = []string{}
+	}
+	if record.Name == "" {
+		return repo.RBACRoleRecord{}, fmt.Errorf("role_name is required")
+	}
+	if err := requireIntegrity(record.IntegritySHA); err != nil {
+		return repo.RBACRoleRecord{}, err
+	}
+	return record, nil
+}
+
+func scanRBACRole(row roleBindingScanner) (repo.RBACRoleRecord, error) {
+	var (
+		record         repo.RBACRoleRecord
+		description    sql.NullString
+		permissionsRaw []byte
+	)
+	err := row.Scan(
+		&record.Name,
+		&description,
+		&permissionsRaw,
+		&record.CreatedAt,
+		&record.CreatedBy,
+		&record.UpdatedAt,
+		&record.UpdatedBy,
+		&record.IntegritySHA,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return repo.RBACRoleRecord{}, repo.ErrNotFound
+		}
+		return repo.RBACRoleRecord{}, fmt.Errorf("scan rbac role: %w", err)
+	}
+	record.Description = description.String
+	record.Permissions = []string{}
+	if len(permissionsRaw) > 0 {
+		if err := json.Unmarshal(permissionsRaw, &record.Permissions); err != nil {
+			return repo.RBACRoleRecord{}, fmt.Errorf("decode rbac role permissions: %w", err)
+		}
+	}
+	return record, nil
+}
@@ -0,0 +1,18 @@
+package postgres
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRBACRoutePermissionListFiltersByService(t *testing.T) {
+	if !strings.Contains(listRBACRoutePermissionsQuery, "service = $1") {
+		t.Fatalf("expected service filter in query: %s", listRBACRoutePermissionsQuery)
+	}
+}
+
+func TestRBACRoleUpdateIsKeyedByName(t *testing.T) {
+	if !strings.Contains(updateRBACRoleQuery, "WHERE role_name = $6") {
+		t.Fatalf("expected role_name predicate in update: %s", updateRBACRoleQuery)
+	}
+}
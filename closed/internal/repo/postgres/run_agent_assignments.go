@@ -0,0 +1,125 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	insertRunAgentAssignmentQuery = `INSERT INTO run_agent_assignments (run_id, project_id, labels, requested_at, requested_by)
+		VALUES ($1, $2, $3::jsonb, $4, $5)
+		ON CONFLICT (run_id) DO NOTHING`
+	selectRunAgentAssignmentQuery = `SELECT claimed_at FROM run_agent_assignments WHERE run_id = $1`
+	selectNextAgentDispatchQuery  = `SELECT d.dispatch_id, d.run_id, d.project_id, d.requested_by, d.max_duration_seconds
+		FROM run_agent_assignments a
+		JOIN run_dispatches d ON d.run_id = a.run_id
+		WHERE a.agent_id IS NULL
+		  AND a.labels <@ $1::jsonb
+		  AND d.dp_base_url = $2
+		  AND d.status = $3
+		ORDER BY d.requested_at ASC
+		LIMIT 1
+		FOR UPDATE OF a SKIP LOCKED`
+	updateRunAgentClaimQuery = `UPDATE run_agent_assignments SET agent_id = $2, claimed_at = $3 WHERE run_id = $1`
+)
+
+// RunAgentStore keeps which runs wait for a remote agent with given labels
+// and which agent claimed them.
+type RunAgentStore struct {
+	db DB
+}
+
+func NewRunAgentStore(db DB) *RunAgentStore {
+	if db == nil {
+		return nil
+	}
+	return &RunAgentStore{db: db}
+}
+
+// RunAgentClaim is the dispatch an agent claimed.
+type RunAgentClaim struct {
+	DispatchID         string
+	RunID              string
+	ProjectID          string
+	RequestedBy        string
+	MaxDurationSeconds int64
+}
+
+// Assign records that runID runs on an agent carrying all of labels. A run
+// keeps the labels of its first assignment.
+func (s *RunAgentStore) Assign(ctx context.Context, runID, projectID string, labels []string, requestedBy string, requestedAt time.Time) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("run agent store not initialized")
+	}
+	runID = strings.TrimSpace(runID)
+	if runID == "" {
+		return fmt.Errorf("run id is required")
+	}
+	if labels == nil {
+		labels = []string{}
+	}
+	labelsJSON, err := json.Marshal(labels)
+	if err != nil {
+		return err
+	}
+	if _, err := s.db.ExecContext(ctx, insertRunAgentAssignmentQuery, runID, strings.TrimSpace(projectID), labelsJSON, normalizeTime(requestedAt), requestedBy); err != nil {
+		return fmt.Errorf("insert run agent assignment: %w", err)
+	}
+	return nil
+}
+
+// Assignment reports whether runID was assigned to agents and, once an
+// agent claimed it, when.
+func (s *RunAgentStore) Assignment(ctx context.Context, runID string) (assigned bool, claimedAt time.Time, claimed bool, err error) {
+	if s == nil || s.db == nil {
+		return false, time.Time{}, false, fmt.Errorf("run agent store not initialized")
+	}
+	var at sql.NullTime
+	err = s.db.QueryRowContext(ctx, selectRunAgentAssignmentQuery, strings.TrimSpace(runID)).Scan(&at)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, time.Time{}, false, nil
+	}
+	if err != nil {
+		return false, time.Time{}, false, fmt.Errorf("select run agent assignment: %w", err)
+	}
+	return true, at.Time, at.Valid, nil
+}
+
+// Claim assigns the oldest dispatch waiting at target in status for an agent
+// whose labels cover the run's to agentID. It locks the assignment, so it
+// must run in the transaction that accepts the dispatch; ok is false when no
+// run waits.
+func (s *RunAgentStore) Claim(ctx context.Context, agentID string, labels []string, target, status string, claimedAt time.Time) (RunAgentClaim, bool, error) {
+	if s == nil || s.db == nil {
+		return RunAgentClaim{}, false, fmt.Errorf("run agent store not initialized")
+	}
+	if labels == nil {
+		labels = []string{}
+	}
+	labelsJSON, err := json.Marshal(labels)
+	if err != nil {
+		return RunAgentClaim{}, false, err
+	}
+	var (
+		claim       RunAgentClaim
+		maxDuration sql.NullInt64
+	)
+	err = s.db.QueryRowContext(ctx, selectNextAgentDispatchQuery, labelsJSON, target, status).
+		Scan(&claim.DispatchID, &claim.RunID, &claim.ProjectID, &claim.RequestedBy, &maxDuration)
+	if errors.Is(err, sql.ErrNoRows) {
+		return RunAgentClaim{}, false, nil
+	}
+	if err != nil {
+		return RunAgentClaim{}, false, fmt.Errorf("select agent dispatch: %w", err)
+	}
+	claim.MaxDurationSeconds = maxDuration.Int64
+	if _, err := s.db.ExecContext(ctx, updateRunAgentClaimQuery, claim.RunID, agentID, normalizeTime(claimedAt)); err != nil {
+		return RunAgentClaim{}, false, fmt.Errorf("update run agent claim: %w", err)
+	}
+	return claim, true, nil
+}
@@ -0,0 +1,12 @@
+package postgres
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNextAgentDispatchSkipsLockedAssignments(t *testing.T) {
+	if !strings.Contains(selectNextAgentDispatchQuery, "FOR UPDATE OF a SKIP LOCKED") || !strings.Contains(selectNextAgentDispatchQuery, "a.agent_id IS NULL") {
+		t.Fatalf("expected unclaimed assignments locked without waiting: %s", selectNextAgentDispatchQuery)
+	}
+}
@@ -0,0 +1,202 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+)
+
+const (
+	insertRunEventQuery = `INSERT INTO experiment_run_events (
+			run_id,
+			occurred_at,
+			actor,
+			level,
+			message,
+			metadata,
+			integrity_sha256
+		) VALUES ($1,$2,$3,$4,$5,$6,$7)
+		RETURNING event_id`
+	selectRunEventListQuery = `SELECT event_id, run_id, occurred_at, actor, level, message, metadata, integrity_sha256
+		FROM experiment_run_events
+		WHERE run_id = $1 AND ($2::bigint = 0 OR event_id < $2)
+		ORDER BY event_id DESC
+		LIMIT $3`
+	insertRunStateEventQuery = `INSERT INTO experiment_run_state_events (state_id, run_id, status, observed_at, details, integrity_sha256)
+		VALUES ($1,$2,$3,$4,$5,$6)
+		ON CONFLICT (run_id, status) DO NOTHING`
+	selectRunProjectIDQuery   = `SELECT project_id FROM experiment_runs WHERE run_id = $1`
+	selectRunDatasetGateQuery = `SELECT v.dataset_id, v.quality_rule_id, v.content_sha256, s.state
+		FROM dataset_versions v
+		LEFT JOIN dataset_version_reference_status s ON s.version_id = v.version_id
+		WHERE v.version_id = $1`
+	selectLatestQualityEvaluationQuery = `SELECT evaluation_id, status
+		FROM quality_evaluations
+		WHERE dataset_version_id = $1 AND rule_id = $2
+		ORDER BY evaluated_at DESC
+		LIMIT 1`
+	selectLatestContractEvaluationQuery = `SELECT evaluation_id, status
+		FROM data_contract_evaluations
+		WHERE dataset_version_id = $1
+		ORDER BY evaluated_at DESC
+		LIMIT 1`
+)
+
+func (s *RunStore) RunProjectID(ctx context.Context, runID string) (string, error) {
+	if s == nil || s.db == nil {
+		return "", fmt.Errorf("run store not initialized")
+	}
+	var projectID sql.NullString
+	if err := s.db.QueryRowContext(ctx, selectRunProjectIDQuery, strings.TrimSpace(runID)).Scan(&projectID); err != nil {
+		return "", handleNotFound(err)
+	}
+	return projectID.String, nil
+}
+
+func (s *RunStore) InsertRunEvent(ctx context.Context, event repo.RunEventRecord) (int64, error) {
+	if s == nil || s.db == nil {
+		return 0, fmt.Errorf("run store not initialized")
+	}
+	if strings.TrimSpace(event.RunID) == "" {
+		return 0, fmt.Errorf("run id is required")
+	}
+	if err := requireIntegrity(event.IntegritySHA256); err != nil {
+		return 0, err
+	}
+	var eventID int64
+	err := s.db.QueryRowContext(
+		ctx,
+		insertRunEventQuery,
+		event.RunID,
+		normalizeTime(event.OccurredAt),
+		event.Actor,
+		event.Level,
+		event.Message,
+		event.Metadata,
+		event.IntegritySHA256,
+	).Scan(&eventID)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return 0, repo.ErrNotFound
+		}
+		return 0, fmt.Errorf("insert run event: %w", err)
+	}
+	return eventID, nil
+}
+
+func (s *RunStore) ListRunEvents(ctx context.Context, filter repo.RunEventFilter) ([]repo.RunEventRecord, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("run store not initialized")
+	}
+	runID := strings.TrimSpace(filter.RunID)
+	if runID == "" {
+		return nil, fmt.Errorf("run id is required")
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 200
+	}
+	rows, err := s.db.QueryContext(ctx, selectRunEventListQuery, runID, max(filter.BeforeEventID, 0), limit)
+	if err != nil {
+		return nil, fmt.Errorf("list run events: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]repo.RunEventRecord, 0, limit)
+	for rows.Next() {
+		var event repo.RunEventRecord
+		if err := rows.Scan(
+			&event.EventID,
+			&event.RunID,
+			&event.OccurredAt,
+			&event.Actor,
+			&event.Level,
+			&event.Message,
+			&event.Metadata,
+			&event.IntegritySHA256,
+		); err != nil {
+			return nil, fmt.Errorf("scan run event: %w", err)
+		}
+		out = append(out, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list run events: %w", err)
+	}
+	return out, nil
+}
+
+func (s *RunStore) InsertRunStateEvent(ctx context.Context, event repo.RunStateEventRecord) (bool, error) {
+	if s == nil || s.db == nil {
+		return false, fmt.Errorf("run store not initialized")
+	}
+	if strings.TrimSpace(event.RunID) == "" || strings.TrimSpace(event.Status) == "" {
+		return false, fmt.Errorf("run id and status are required")
+	}
+	if err := requireIntegrity(event.IntegritySHA256); err != nil {
+		return false, err
+	}
+	res, err := s.db.ExecContext(
+		ctx,
+		insertRunStateEventQuery,
+		event.StateID,
+		event.RunID,
+		event.Status,
+		normalizeTime(event.ObservedAt),
+		event.Details,
+		event.IntegritySHA256,
+	)
+	if err != nil {
+		return false, fmt.Errorf("insert run state event: %w", err)
+	}
+	affected, _ := res.RowsAffected()
+	return affected > 0, nil
+}
+
+func (s *RunStore) GetRunDatasetGate(ctx context.Context, datasetVersionID string) (repo.RunDatasetGate, error) {
+	if s == nil || s.db == nil {
+		return repo.RunDatasetGate{}, fmt.Errorf("run store not initialized")
+	}
+	datasetVersionID = strings.TrimSpace(datasetVersionID)
+	if datasetVersionID == "" {
+		return repo.RunDatasetGate{}, fmt.Errorf("dataset version id is required")
+	}
+	var (
+		gate           repo.RunDatasetGate
+		qualityRuleID  sql.NullString
+		referenceState sql.NullString
+	)
+	if err := s.db.QueryRowContext(ctx, selectRunDatasetGateQuery, datasetVersionID).Scan(&gate.DatasetID, &qualityRuleID, &gate.ContentSHA256, &referenceState); err != nil {
+		return repo.RunDatasetGate{}, handleNotFound(err)
+	}
+	gate.QualityRuleID = strings.TrimSpace(qualityRuleID.String)
+	gate.ContentSHA256 = strings.TrimSpace(gate.ContentSHA256)
+	gate.ReferenceState = referenceState.String
+
+	var err error
+	if gate.QualityRuleID != "" {
+		gate.QualityEvaluation, err = s.latestGateEvaluation(ctx, selectLatestQualityEvaluationQuery, datasetVersionID, gate.QualityRuleID)
+		if err != nil {
+			return repo.RunDatasetGate{}, fmt.Errorf("latest quality evaluation: %w", err)
+		}
+	}
+	gate.ContractEvaluation, err = s.latestGateEvaluation(ctx, selectLatestContractEvaluationQuery, datasetVersionID)
+	if err != nil {
+		return repo.RunDatasetGate{}, fmt.Errorf("latest data contract evaluation: %w", err)
+	}
+	return gate, nil
+}
+
+func (s *RunStore) latestGateEvaluation(ctx context.Context, query string, args ...any) (*repo.GateEvaluation, error) {
+	var evaluation repo.GateEvaluation
+	if err := s.db.QueryRowContext(ctx, query, args...).Scan(&evaluation.EvaluationID, &evaluation.Status); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &evaluation, nil
+}
@@ -0,0 +1,333 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+)
+
+const (
+	insertRunScheduleQuery = `INSERT INTO experiment_run_schedules (
+			schedule_id, project_id, experiment_id, name, cron_expr, template, state, next_run_at,
+			created_at, created_by, updated_at, updated_by, integrity_sha256
+		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$9,$10,$11)`
+	runScheduleColumns = `SELECT schedule_id, project_id, experiment_id, name, cron_expr, template, state, next_run_at,
+			last_run_at, last_run_id, last_status, last_error, created_at, created_by, updated_at, updated_by
+		FROM experiment_run_schedules`
+	selectRunScheduleQuery          = runScheduleColumns + ` WHERE schedule_id = $1`
+	selectRunScheduleForUpdateQuery = selectRunScheduleQuery + ` FOR UPDATE`
+	selectRunScheduleListQuery      = runScheduleColumns + `
+		WHERE experiment_id = $1 AND ($2 = '' OR state = $2)
+		ORDER BY created_at DESC, schedule_id DESC
+		LIMIT $3`
+	selectDueRunSchedulesQuery = runScheduleColumns + `
+		WHERE state = 'active' AND next_run_at <= $1
+		ORDER BY next_run_at
+		LIMIT $2`
+	updateRunScheduleStateQuery = `UPDATE experiment_run_schedules
+		SET state = $2, next_run_at = $3, updated_at = $4, updated_by = $5
+		WHERE schedule_id = $1`
+	claimRunScheduleQuery = `UPDATE experiment_run_schedules
+		SET next_run_at = $3, state = $4, last_run_at = $5
+		WHERE schedule_id = $1 AND state = 'active' AND next_run_at = $2`
+	insertRunScheduleFiringQuery = `INSERT INTO experiment_run_schedule_firings (firing_id, schedule_id, scheduled_for, fired_at, status, run_id, dataset_version_id, error_code)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8)`
+	updateRunScheduleLastFiringQuery = `UPDATE experiment_run_schedules
+		SET last_run_id = COALESCE($2, last_run_id), last_status = $3, last_error = $4
+		WHERE schedule_id = $1`
+	selectRunScheduleFiringsQuery = `SELECT firing_id, schedule_id, scheduled_for, fired_at, status, run_id, dataset_version_id, error_code
+		FROM experiment_run_schedule_firings
+		WHERE schedule_id = $1
+		ORDER BY scheduled_for DESC
+		LIMIT $2`
+)
+
+// RunScheduleStore keeps cron schedules that create experiment runs and the
+// record of each firing.
+type RunScheduleStore struct {
+	db DB
+}
+
+func NewRunScheduleStore(db DB) *RunScheduleStore {
+	if db == nil {
+		return nil
+	}
+	return &RunScheduleStore{db: db}
+}
+
+// RunScheduleRecord is a schedule row; Template is the raw JSON column.
+type RunScheduleRecord struct {
+	ScheduleID      string
+	ProjectID       string
+	ExperimentID    string
+	Name            string
+	Cron            string
+	Template        []byte
+	State           string
+	NextRunAt       *time.Time
+	LastRunAt       *time.Time
+	LastRunID       string
+	LastStatus      string
+	LastError       string
+	CreatedAt       time.Time
+	CreatedBy       string
+	UpdatedAt       time.Time
+	UpdatedBy       string
+	IntegritySHA256 string
+}
+
+type RunScheduleFilter struct {
+	ExperimentID string
+	// State, when set, keeps schedules in that state.
+	State string
+	Limit int
+}
+
+type RunScheduleFiringRecord struct {
+	FiringID         string
+	ScheduleID       string
+	ScheduledFor     time.Time
+	FiredAt          time.Time
+	Status           string
+	RunID            string
+	DatasetVersionID string
+	ErrorCode        string
+}
+
+// Create inserts an active schedule; a missing experiment or project is
+// repo.ErrNotFound.
+func (s *RunScheduleStore) Create(ctx context.Context, record RunScheduleRecord) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("run schedule store not initialized")
+	}
+	if strings.TrimSpace(record.ScheduleID) == "" {
+		return fmt.Errorf("schedule id is required")
+	}
+	if err := requireIntegrity(record.IntegritySHA256); err != nil {
+		return err
+	}
+	_, err := s.db.ExecContext(
+		ctx,
+		insertRunScheduleQuery,
+		record.ScheduleID,
+		record.ProjectID,
+		record.ExperimentID,
+		record.Name,
+		record.Cron,
+		record.Template,
+		record.State,
+		record.NextRunAt,
+		normalizeTime(record.CreatedAt),
+		record.CreatedBy,
+		record.IntegritySHA256,
+	)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return repo.ErrNotFound
+		}
+		return fmt.Errorf("insert run schedule: %w", err)
+	}
+	return nil
+}
+
+func (s *RunScheduleStore) Get(ctx context.Context, scheduleID string) (RunScheduleRecord, error) {
+	return s.get(ctx, selectRunScheduleQuery, scheduleID)
+}
+
+// GetForUpdate is Get that locks the schedule until the transaction ends.
+func (s *RunScheduleStore) GetForUpdate(ctx context.Context, scheduleID string) (RunScheduleRecord, error) {
+	return s.get(ctx, selectRunScheduleForUpdateQuery, scheduleID)
+}
+
+func (s *RunScheduleStore) get(ctx context.Context, query, scheduleID string) (RunScheduleRecord, error) {
+	if s == nil || s.db == nil {
+		return RunScheduleRecord{}, fmt.Errorf("run schedule store not initialized")
+	}
+	record, err := scanRunSchedule(s.db.QueryRowContext(ctx, query, strings.TrimSpace(scheduleID)))
+	if err != nil {
+		return RunScheduleRecord{}, handleNotFound(err)
+	}
+	return record, nil
+}
+
+func (s *RunScheduleStore) List(ctx context.Context, filter RunScheduleFilter) ([]RunScheduleRecord, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("run schedule store not initialized")
+	}
+	return s.list(ctx, selectRunScheduleListQuery, strings.TrimSpace(filter.ExperimentID), filter.State, filter.Limit)
+}
+
+// ListDue lists up to limit active schedules whose next firing is at or
+// before now, earliest first.
+func (s *RunScheduleStore) ListDue(ctx context.Context, now time.Time, limit int) ([]RunScheduleRecord, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("run schedule store not initialized")
+	}
+	return s.list(ctx, selectDueRunSchedulesQuery, now, limit)
+}
+
+func (s *RunScheduleStore) list(ctx context.Context, query string, args ...any) ([]RunScheduleRecord, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list run schedules: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]RunScheduleRecord, 0)
+	for rows.Next() {
+		record, err := scanRunSchedule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan run schedule: %w", err)
+		}
+		out = append(out, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate run schedules: %w", err)
+	}
+	return out, nil
+}
+
+// SetState pauses or resumes a schedule; nextRunAt is nil while paused.
+func (s *RunScheduleStore) SetState(ctx context.Context, scheduleID, state string, nextRunAt *time.Time, updatedAt time.Time, updatedBy string) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("run schedule store not initialized")
+	}
+	if _, err := s.db.ExecContext(ctx, updateRunScheduleStateQuery, scheduleID, state, nextRunAt, normalizeTime(updatedAt), updatedBy); err != nil {
+		return fmt.Errorf("update run schedule state: %w", err)
+	}
+	return nil
+}
+
+// Claim moves an active schedule due at dueAt to nextRunAt and state. It
+// reports false when another replica claimed the period first.
+func (s *RunScheduleStore) Claim(ctx context.Context, scheduleID string, dueAt time.Time, nextRunAt *time.Time, state string, now time.Time) (bool, error) {
+	if s == nil || s.db == nil {
+		return false, fmt.Errorf("run schedule store not initialized")
+	}
+	res, err := s.db.ExecContext(ctx, claimRunScheduleQuery, scheduleID, dueAt, nextRunAt, state, normalizeTime(now))
+	if err != nil {
+		return false, fmt.Errorf("claim run schedule: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+// RecordFiring inserts a firing and makes it the schedule's last one.
+func (s *RunScheduleStore) RecordFiring(ctx context.Context, firing RunScheduleFiringRecord) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("run schedule store not initialized")
+	}
+	_, err := s.db.ExecContext(
+		ctx,
+		insertRunScheduleFiringQuery,
+		firing.FiringID,
+		firing.ScheduleID,
+		firing.ScheduledFor,
+		firing.FiredAt,
+		firing.Status,
+		nullIfEmpty(firing.RunID),
+		nullIfEmpty(firing.DatasetVersionID),
+		nullIfEmpty(firing.ErrorCode),
+	)
+	if err != nil {
+		return fmt.Errorf("insert run schedule firing: %w", err)
+	}
+	_, err = s.db.ExecContext(
+		ctx,
+		updateRunScheduleLastFiringQuery,
+		firing.ScheduleID,
+		nullIfEmpty(firing.RunID),
+		firing.Status,
+		nullIfEmpty(firing.ErrorCode),
+	)
+	if err != nil {
+		return fmt.Errorf("update run schedule last firing: %w", err)
+	}
+	return nil
+}
+
+func (s *RunScheduleStore) ListFirings(ctx context.Context, scheduleID string, limit int) ([]RunScheduleFiringRecord, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("run schedule store not initialized")
+	}
+	rows, err := s.db.QueryContext(ctx, selectRunScheduleFiringsQuery, strings.TrimSpace(scheduleID), limit)
+	if err != nil {
+		return nil, fmt.Errorf("list run schedule firings: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]RunScheduleFiringRecord, 0)
+	for rows.Next() {
+		var (
+			firing           RunScheduleFiringRecord
+			runID            sql.NullString
+			datasetVersionID sql.NullString
+			errorCode        sql.NullString
+		)
+		if err := rows.Scan(&firing.FiringID, &firing.ScheduleID, &firing.ScheduledFor, &firing.FiredAt, &firing.Status, &runID, &datasetVersionID, &errorCode); err != nil {
+			return nil, fmt.Errorf("scan run schedule firing: %w", err)
+		}
+		firing.ScheduledFor = firing.ScheduledFor.UTC()
+		firing.FiredAt = firing.FiredAt.UTC()
+		firing.RunID = runID.String
+		firing.DatasetVersionID = datasetVersionID.String
+		firing.ErrorCode = errorCode.String
+		out = append(out, firing)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate run schedule firings: %w", err)
+	}
+	return out, nil
+}
+
+func scanRunSchedule(row interface{ Scan(...any) error }) (RunScheduleRecord, error) {
+	var (
+		out        RunScheduleRecord
+		nextRunAt  sql.NullTime
+		lastRunAt  sql.NullTime
+		lastRunID  sql.NullString
+		lastStatus sql.NullString
+		lastError  sql.NullString
+	)
+	if err := row.Scan(
+		&out.ScheduleID,
+		&out.ProjectID,
+		&out.ExperimentID,
+		&out.Name,
+		&out.Cron,
+		&out.Template,
+		&out.State,
+		&nextRunAt,
+		&lastRunAt,
+		&lastRunID,
+		&lastStatus,
+		&lastError,
+		&out.CreatedAt,
+		&out.CreatedBy,
+		&out.UpdatedAt,
+		&out.UpdatedBy,
+	); err != nil {
+		return RunScheduleRecord{}, err
+	}
+	if nextRunAt.Valid {
+		t := nextRunAt.Time.UTC()
+		out.NextRunAt = &t
+	}
+	if lastRunAt.Valid {
+		t := lastRunAt.Time.UTC()
+		out.LastRunAt = &t
+	}
+	out.LastRunID = lastRunID.String
+	out.LastStatus = lastStatus.String
+	out.LastError = lastError.String
+	out.CreatedAt = out.CreatedAt.UTC()
+	out.UpdatedAt = out.UpdatedAt.UTC()
+	return out, nil
+}
@@ -0,0 +1,310 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	selectRunStepAttemptsQuery = `SELECT attempt_id, step_name, attempt, status, not_before, inputs, job_name, dispatched_at, heartbeat_at, finished_at, reason, exit_code
+		FROM run_step_attempts
+		WHERE run_id = $1
+		ORDER BY step_name, attempt`
+	selectRunStepStaleQuery = `SELECT step_name, attempt
+		FROM run_step_attempts
+		WHERE dispatch_id = $1 AND status IN ('dispatched', 'running') AND COALESCE(heartbeat_at, dispatched_at) < $2`
+	selectRunStepDispatchExistsQuery = `SELECT EXISTS (SELECT 1 FROM run_step_attempts WHERE dispatch_id = $1)`
+	selectActivePipelineRunsQuery    = `SELECT DISTINCT d.project_id, d.run_id
+		FROM run_dispatches d
+		JOIN run_step_attempts a ON a.dispatch_id = d.dispatch_id
+		WHERE d.status IN ('requested', 'accepted', 'running')
+		LIMIT $1`
+	insertRunStepAttemptQuery = `INSERT INTO run_step_attempts (attempt_id, project_id, run_id, dispatch_id, step_name, attempt, status, not_before, created_at, updated_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$9)
+		ON CONFLICT (run_id, step_name, attempt) DO NOTHING`
+	claimDueRunStepAttemptsQuery = `UPDATE run_step_attempts
+		SET status = $3, dispatched_at = $2, updated_at = $2
+		WHERE run_id = $1 AND status = 'pending' AND not_before <= $2
+		RETURNING attempt_id, step_name, attempt`
+	// A dispatched attempt may already have finished when its terminal event
+	// beat the dispatch outcome; only a still dispatched attempt is changed.
+	updateRunStepDispatchQuery = `UPDATE run_step_attempts
+		SET status = $2,
+		    job_name = $3,
+		    reason = $4,
+		    inputs = $5,
+		    dispatched_at = CASE WHEN $2 = 'pending' THEN NULL ELSE dispatched_at END,
+		    finished_at = CASE WHEN $2 = 'failed' THEN $6 ELSE NULL END,
+		    updated_at = $6
+		WHERE attempt_id = $1 AND status = 'dispatched'`
+	updateRunStepRunningQuery = `UPDATE run_step_attempts
+		SET status = 'running', heartbeat_at = $4, updated_at = $4
+		WHERE run_id = $1 AND step_name = $2 AND attempt = $3 AND status IN ('dispatched', 'running')`
+	updateRunStepFinishedQuery = `UPDATE run_step_attempts
+		SET status = $4, reason = $5, exit_code = $6, finished_at = $7, updated_at = now()
+		WHERE run_id = $1 AND step_name = $2 AND attempt = $3 AND status IN ('pending', 'dispatched', 'running')`
+	selectRunStepOutputQuery = `SELECT artifact_id, sha256, size_bytes, object_key
+		FROM experiment_run_artifacts
+		WHERE run_id = $1 AND name = $2 AND metadata->>'step' = $3
+		ORDER BY created_at DESC
+		LIMIT 1`
+)
+
+// RunStepAttemptStore keeps the attempts of the steps of pipeline runs.
+type RunStepAttemptStore struct {
+	db DB
+}
+
+func NewRunStepAttemptStore(db DB) *RunStepAttemptStore {
+	if db == nil {
+		return nil
+	}
+	return &RunStepAttemptStore{db: db}
+}
+
+// RunStepAttemptRecord is one attempt of a step. Inputs is the raw JSON
+// column.
+type RunStepAttemptRecord struct {
+	AttemptID    string
+	StepName     string
+	Attempt      int
+	Status       string
+	NotBefore    time.Time
+	Inputs       []byte
+	JobName      string
+	DispatchedAt *time.Time
+	HeartbeatAt  *time.Time
+	FinishedAt   *time.Time
+	Reason       string
+	ExitCode     *int
+}
+
+// RunStepOutput is an artifact a step committed for downstream steps.
+type RunStepOutput struct {
+	ArtifactID string
+	SHA256     string
+	SizeBytes  int64
+	ObjectKey  string
+}
+
+func (s *RunStepAttemptStore) List(ctx context.Context, runID string) ([]RunStepAttemptRecord, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("run step attempt store not initialized")
+	}
+	rows, err := s.db.QueryContext(ctx, selectRunStepAttemptsQuery, strings.TrimSpace(runID))
+	if err != nil {
+		return nil, fmt.Errorf("list run step attempts: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]RunStepAttemptRecord, 0)
+	for rows.Next() {
+		var (
+			record       RunStepAttemptRecord
+			jobName      sql.NullString
+			dispatchedAt sql.NullTime
+			heartbeatAt  sql.NullTime
+			finishedAt   sql.NullTime
+			reason       sql.NullString
+			exitCode     sql.NullInt64
+		)
+		if err := rows.Scan(&record.AttemptID, &record.StepName, &record.Attempt, &record.Status, &record.NotBefore, &record.Inputs, &jobName, &dispatchedAt, &heartbeatAt, &finishedAt, &reason, &exitCode); err != nil {
+			return nil, fmt.Errorf("scan run step attempt: %w", err)
+		}
+		record.NotBefore = record.NotBefore.UTC()
+		record.JobName = jobName.String
+		record.DispatchedAt = timePtr(dispatchedAt)
+		record.HeartbeatAt = timePtr(heartbeatAt)
+		record.FinishedAt = timePtr(finishedAt)
+		record.Reason = reason.String
+		if exitCode.Valid {
+			code := int(exitCode.Int64)
+			record.ExitCode = &code
+		}
+		out = append(out, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate run step attempts: %w", err)
+	}
+	return out, nil
+}
+
+// ListStale returns the dispatch's in-flight attempts without a heartbeat
+// since staleBefore, with only StepName and Attempt set.
+func (s *RunStepAttemptStore) ListStale(ctx context.Context, dispatchID string, staleBefore time.Time) ([]RunStepAttemptRecord, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("run step attempt store not initialized")
+	}
+	rows, err := s.db.QueryContext(ctx, selectRunStepStaleQuery, strings.TrimSpace(dispatchID), normalizeTime(staleBefore))
+	if err != nil {
+		return nil, fmt.Errorf("list stale run step attempts: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]RunStepAttemptRecord, 0)
+	for rows.Next() {
+		var record RunStepAttemptRecord
+		if err := rows.Scan(&record.StepName, &record.Attempt); err != nil {
+			return nil, fmt.Errorf("scan run step attempt: %w", err)
+		}
+		out = append(out, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate run step attempts: %w", err)
+	}
+	return out, nil
+}
+
+// DispatchHasAttempts reports whether the dispatch runs a multi-step run.
+func (s *RunStepAttemptStore) DispatchHasAttempts(ctx context.Context, dispatchID string) (bool, error) {
+	if s == nil || s.db == nil {
+		return false, fmt.Errorf("run step attempt store not initialized")
+	}
+	var exists bool
+	if err := s.db.QueryRowContext(ctx, selectRunStepDispatchExistsQuery, strings.TrimSpace(dispatchID)).Scan(&exists); err != nil {
+		return false, fmt.Errorf("select run step dispatch: %w", err)
+	}
+	return exists, nil
+}
+
+// PipelineRun identifies a multi-step run.
+type PipelineRun struct {
+	ProjectID string
+	RunID     string
+}
+
+// ListActiveRuns returns up to limit pipeline runs whose dispatch has not
+// finished.
+func (s *RunStepAttemptStore) ListActiveRuns(ctx context.Context, limit int) ([]PipelineRun, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("run step attempt store not initialized")
+	}
+	rows, err := s.db.QueryContext(ctx, selectActivePipelineRunsQuery, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list active pipeline runs: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]PipelineRun, 0)
+	for rows.Next() {
+		var run PipelineRun
+		if err := rows.Scan(&run.ProjectID, &run.RunID); err != nil {
+			return nil, fmt.Errorf("scan active pipeline run: %w", err)
+		}
+		out = append(out, run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate active pipeline runs: %w", err)
+	}
+	return out, nil
+}
+
+// CreatePending inserts a pending attempt due at notBefore unless the step
+// already has that attempt.
+func (s *RunStepAttemptStore) CreatePending(ctx context.Context, attemptID, projectID, runID, dispatchID, stepName string, attempt int, status string, notBefore, now time.Time) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("run step attempt store not initialized")
+	}
+	if _, err := s.db.ExecContext(ctx, insertRunStepAttemptQuery, attemptID, projectID, runID, dispatchID, stepName, attempt, status, normalizeTime(notBefore), normalizeTime(now)); err != nil {
+		return fmt.Errorf("insert run step attempt: %w", err)
+	}
+	return nil
+}
+
+// ClaimDue moves the run's pending attempts due by now to status and
+// returns them with only AttemptID, StepName and Attempt set.
+func (s *RunStepAttemptStore) ClaimDue(ctx context.Context, runID, status string, now time.Time) ([]RunStepAttemptRecord, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("run step attempt store not initialized")
+	}
+	rows, err := s.db.QueryContext(ctx, claimDueRunStepAttemptsQuery, strings.TrimSpace(runID), normalizeTime(now), status)
+	if err != nil {
+		return nil, fmt.Errorf("claim run step attempts: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]RunStepAttemptRecord, 0)
+	for rows.Next() {
+		record := RunStepAttemptRecord{Status: status}
+		if err := rows.Scan(&record.AttemptID, &record.StepName, &record.Attempt); err != nil {
+			return nil, fmt.Errorf("scan run step attempt: %w", err)
+		}
+		out = append(out, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate run step attempts: %w", err)
+	}
+	return out, nil
+}
+
+// RecordDispatch stores the outcome of sending a dispatched attempt.
+func (s *RunStepAttemptStore) RecordDispatch(ctx context.Context, attemptID, status, jobName, reason string, inputs []byte, now time.Time) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("run step attempt store not initialized")
+	}
+	if _, err := s.db.ExecContext(ctx, updateRunStepDispatchQuery, attemptID, status, nullString(jobName), nullString(reason), inputs, normalizeTime(now)); err != nil {
+		return fmt.Errorf("update run step dispatch: %w", err)
+	}
+	return nil
+}
+
+// MarkRunning records a heartbeat of an attempt in flight.
+func (s *RunStepAttemptStore) MarkRunning(ctx context.Context, runID, stepName string, attempt int, at time.Time) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("run step attempt store not initialized")
+	}
+	if _, err := s.db.ExecContext(ctx, updateRunStepRunningQuery, runID, stepName, attempt, normalizeTime(at)); err != nil {
+		return fmt.Errorf("update run step running: %w", err)
+	}
+	return nil
+}
+
+// Finish records the terminal status of an attempt and reports whether it
+// was still in flight.
+func (s *RunStepAttemptStore) Finish(ctx context.Context, runID, stepName string, attempt int, status, reason string, exitCode *int, finishedAt time.Time) (bool, error) {
+	if s == nil || s.db == nil {
+		return false, fmt.Errorf("run step attempt store not initialized")
+	}
+	var code sql.NullInt64
+	if exitCode != nil {
+		code = sql.NullInt64{Int64: int64(*exitCode), Valid: true}
+	}
+	res, err := s.db.ExecContext(ctx, updateRunStepFinishedQuery, runID, stepName, attempt, status, nullString(reason), code, normalizeTime(finishedAt))
+	if err != nil {
+		return false, fmt.Errorf("update run step finished: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("update run step finished: %w", err)
+	}
+	return affected == 1, nil
+}
+
+// Output returns the newest artifact named artifact that fromStep committed
+// in the run; none is repo.ErrNotFound.
+func (s *RunStepAttemptStore) Output(ctx context.Context, runID, artifact, fromStep string) (RunStepOutput, error) {
+	if s == nil || s.db == nil {
+		return RunStepOutput{}, fmt.Errorf("run step attempt store not initialized")
+	}
+	var out RunStepOutput
+	err := s.db.QueryRowContext(ctx, selectRunStepOutputQuery, runID, artifact, fromStep).Scan(&out.ArtifactID, &out.SHA256, &out.SizeBytes, &out.ObjectKey)
+	if errors.Is(err, sql.ErrNoRows) {
+		return RunStepOutput{}, handleNotFound(err)
+	}
+	if err != nil {
+		return RunStepOutput{}, fmt.Errorf("select run step output: %w", err)
+	}
+	return out, nil
+}
+
+func timePtr(value sql.NullTime) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+	t := value.Time.UTC()
+	return &t
+}
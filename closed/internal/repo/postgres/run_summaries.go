@@ -0,0 +1,166 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+)
+
+const (
+	// runSummaryColumns derives status and ended_at from the latest state
+	// event; runs created before any event keep their stored values.
+	runSummaryColumns = `SELECT r.run_id,
+			r.experiment_id,
+			r.project_id,
+			r.dataset_version_id,
+			(SELECT COALESCE(json_agg(d.dataset_version_id ORDER BY d.position), '[]'::json)
+			   FROM experiment_run_datasets d
+			  WHERE d.run_id = r.run_id) AS dataset_version_ids,
+			COALESCE(s.status, r.status) AS status,
+			r.started_at,
+			COALESCE(r.ended_at, CASE WHEN s.status IN ('succeeded','failed','canceled') THEN s.observed_at END) AS ended_at,
+			r.git_repo,
+			r.git_commit,
+			r.git_ref,
+			r.params,
+			r.metrics,
+			r.artifacts_prefix
+		FROM experiment_runs r
+		LEFT JOIN LATERAL (
+			SELECT status, observed_at
+			FROM experiment_run_state_events
+			WHERE run_id = r.run_id
+			ORDER BY observed_at DESC
+			LIMIT 1
+		) s ON true`
+	selectRunSummaryQuery = runSummaryColumns + `
+		WHERE r.run_id = $1`
+	selectRunSummaryListQuery = runSummaryColumns + `
+		WHERE ($1 = '' OR r.project_id = $1)
+			AND ($2 = '' OR r.experiment_id = $2)
+			AND ($3::bool IS false OR COALESCE(s.status, r.status) IN ('pending','running'))
+			AND ($4 = '' OR COALESCE(s.status, r.status) = $4)
+		ORDER BY r.started_at DESC
+		LIMIT $5`
+	selectRunExistsQuery = `SELECT 1 FROM experiment_runs WHERE run_id = $1`
+)
+
+func (s *RunStore) RunExists(ctx context.Context, runID string) (bool, error) {
+	if s == nil || s.db == nil {
+		return false, fmt.Errorf("run store not initialized")
+	}
+	var one int
+	if err := s.db.QueryRowContext(ctx, selectRunExistsQuery, strings.TrimSpace(runID)).Scan(&one); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *RunStore) GetRunSummary(ctx context.Context, runID string) (repo.RunSummary, error) {
+	if s == nil || s.db == nil {
+		return repo.RunSummary{}, fmt.Errorf("run store not initialized")
+	}
+	runID = strings.TrimSpace(runID)
+	if runID == "" {
+		return repo.RunSummary{}, fmt.Errorf("run id is required")
+	}
+	summary, err := scanRunSummary(s.db.QueryRowContext(ctx, selectRunSummaryQuery, runID))
+	if err != nil {
+		return repo.RunSummary{}, handleNotFound(err)
+	}
+	return summary, nil
+}
+
+func (s *RunStore) ListRunSummaries(ctx context.Context, filter repo.RunSummaryFilter) ([]repo.RunSummary, error) {
+	if s == nil || s.reads == nil {
+		return nil, fmt.Errorf("run store not initialized")
+	}
+	projectID := strings.TrimSpace(filter.ProjectID)
+	experimentID := strings.TrimSpace(filter.ExperimentID)
+	if projectID == "" && experimentID == "" {
+		return nil, fmt.Errorf("project id or experiment id is required")
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	rows, err := s.reads.QueryContext(ctx, selectRunSummaryListQuery,
+		projectID,
+		experimentID,
+		filter.ActiveOnly,
+		strings.TrimSpace(filter.Status),
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list run summaries: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]repo.RunSummary, 0, limit)
+	for rows.Next() {
+		summary, err := scanRunSummary(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan run summary: %w", err)
+		}
+		out = append(out, summary)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list run summaries: %w", err)
+	}
+	return out, nil
+}
+
+func scanRunSummary(row rowScanner) (repo.RunSummary, error) {
+	var (
+		summary           repo.RunSummary
+		datasetVersionID  sql.NullString
+		datasetVersionIDs []byte
+		endedAt           sql.NullTime
+		gitRepo           sql.NullString
+		gitCommit         sql.NullString
+		gitRef            sql.NullString
+		artifactsPrefix   sql.NullString
+	)
+	if err := row.Scan(
+		&summary.RunID,
+		&summary.ExperimentID,
+		&summary.ProjectID,
+		&datasetVersionID,
+		&datasetVersionIDs,
+		&summary.Status,
+		&summary.StartedAt,
+		&endedAt,
+		&gitRepo,
+		&gitCommit,
+		&gitRef,
+		&summary.Params,
+		&summary.Metrics,
+		&artifactsPrefix,
+	); err != nil {
+		return repo.RunSummary{}, err
+	}
+	summary.DatasetVersionID = strings.TrimSpace(datasetVersionID.String)
+	if len(datasetVersionIDs) > 0 {
+		var ids []string
+		if err := json.Unmarshal(datasetVersionIDs, &ids); err == nil && len(ids) > 0 {
+			summary.DatasetVersionIDs = ids
+		}
+	}
+	if endedAt.Valid && !endedAt.Time.IsZero() {
+		ended := endedAt.Time.UTC()
+		summary.EndedAt = &ended
+	}
+	summary.GitRepo = strings.TrimSpace(gitRepo.String)
+	summary.GitCommit = strings.TrimSpace(gitCommit.String)
+	summary.GitRef = strings.TrimSpace(gitRef.String)
+	summary.ArtifactsPrefix = strings.TrimSpace(artifactsPrefix.String)
+	return summary, nil
+}
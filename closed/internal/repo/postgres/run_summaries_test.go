@@ -0,0 +1,26 @@
+package postgres
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRunSummaryQueriesUseLatestStateEvent(t *testing.T) {
+	for _, query := range []string{selectRunSummaryQuery, selectRunSummaryListQuery} {
+		if !strings.Contains(query, "COALESCE(s.status, r.status)") || !strings.Contains(query, "experiment_run_state_events") {
+			t.Fatalf("expected derived status in query: %s", query)
+		}
+	}
+}
+
+func TestRunSummaryListQueryIsBounded(t *testing.T) {
+	if !strings.Contains(selectRunSummaryListQuery, "LIMIT $5") {
+		t.Fatalf("expected limit in query: %s", selectRunSummaryListQuery)
+	}
+}
+
+func TestRunEventListPagesBackwards(t *testing.T) {
+	if !strings.Contains(selectRunEventListQuery, "($2::bigint = 0 OR event_id < $2)") || !strings.Contains(selectRunEventListQuery, "ORDER BY event_id DESC") {
+		t.Fatalf("expected optional before cursor in query: %s", selectRunEventListQuery)
+	}
+}
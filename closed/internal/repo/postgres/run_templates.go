@@ -0,0 +1,172 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+)
+
+const (
+	lockRunTemplateNameQuery     = `SELECT pg_advisory_xact_lock(hashtext($1))`
+	selectRunTemplateLatestQuery = `SELECT COALESCE(MAX(version), 0) FROM run_templates WHERE project_id = $1 AND name = $2`
+	insertRunTemplateQuery       = `INSERT INTO run_templates (
+			template_id, project_id, name, version, description, template, params_schema, created_at, created_by, integrity_sha256
+		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)`
+	runTemplateColumns = `SELECT template_id, project_id, name, version, description, template, params_schema, created_at, created_by
+		FROM run_templates t`
+	selectRunTemplateQuery     = runTemplateColumns + ` WHERE template_id = $1`
+	selectRunTemplateListQuery = runTemplateColumns + `
+		WHERE project_id = $1
+		  AND ($2 = '' OR name = $2)
+		  AND (NOT $3 OR version = (SELECT MAX(version) FROM run_templates l WHERE l.project_id = t.project_id AND l.name = t.name))
+		ORDER BY name, version DESC
+		LIMIT $4`
+)
+
+// RunTemplateStore keeps immutable, versioned run templates.
+type RunTemplateStore struct {
+	db DB
+}
+
+func NewRunTemplateStore(db DB) *RunTemplateStore {
+	if db == nil {
+		return nil
+	}
+	return &RunTemplateStore{db: db}
+}
+
+// RunTemplateRecord is one template version. Template and ParamsSchema are
+// the raw JSON columns.
+type RunTemplateRecord struct {
+	TemplateID      string
+	ProjectID       string
+	Name            string
+	Version         int
+	Description     string
+	Template        []byte
+	ParamsSchema    []byte
+	CreatedAt       time.Time
+	CreatedBy       string
+	IntegritySHA256 string
+}
+
+type RunTemplateFilter struct {
+	ProjectID string
+	Name      string
+	// LatestOnly keeps the highest version of each name.
+	LatestOnly bool
+	Limit      int
+}
+
+// NextVersion locks the template name for the rest of the transaction and
+// returns the version its next save gets, so concurrent saves get
+// consecutive versions instead of a unique violation.
+func (s *RunTemplateStore) NextVersion(ctx context.Context, projectID, name string) (int, error) {
+	if s == nil || s.db == nil {
+		return 0, fmt.Errorf("run template store not initialized")
+	}
+	if _, err := s.db.ExecContext(ctx, lockRunTemplateNameQuery, "run_template:"+projectID+"/"+name); err != nil {
+		return 0, fmt.Errorf("lock run template name: %w", err)
+	}
+	var latest int
+	if err := s.db.QueryRowContext(ctx, selectRunTemplateLatestQuery, projectID, name).Scan(&latest); err != nil {
+		return 0, fmt.Errorf("select run template version: %w", err)
+	}
+	return latest + 1, nil
+}
+
+// Create inserts a template version; a missing project is repo.ErrNotFound.
+func (s *RunTemplateStore) Create(ctx context.Context, record RunTemplateRecord) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("run template store not initialized")
+	}
+	if strings.TrimSpace(record.TemplateID) == "" {
+		return fmt.Errorf("template id is required")
+	}
+	if err := requireIntegrity(record.IntegritySHA256); err != nil {
+		return err
+	}
+	_, err := s.db.ExecContext(
+		ctx,
+		insertRunTemplateQuery,
+		record.TemplateID,
+		record.ProjectID,
+		record.Name,
+		record.Version,
+		nullIfEmpty(record.Description),
+		record.Template,
+		record.ParamsSchema,
+		normalizeTime(record.CreatedAt),
+		record.CreatedBy,
+		record.IntegritySHA256,
+	)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return repo.ErrNotFound
+		}
+		return fmt.Errorf("insert run template: %w", err)
+	}
+	return nil
+}
+
+func (s *RunTemplateStore) Get(ctx context.Context, templateID string) (RunTemplateRecord, error) {
+	if s == nil || s.db == nil {
+		return RunTemplateRecord{}, fmt.Errorf("run template store not initialized")
+	}
+	record, err := scanRunTemplate(s.db.QueryRowContext(ctx, selectRunTemplateQuery, strings.TrimSpace(templateID)))
+	if err != nil {
+		return RunTemplateRecord{}, handleNotFound(err)
+	}
+	return record, nil
+}
+
+func (s *RunTemplateStore) List(ctx context.Context, filter RunTemplateFilter) ([]RunTemplateRecord, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("run template store not initialized")
+	}
+	rows, err := s.db.QueryContext(ctx, selectRunTemplateListQuery, filter.ProjectID, strings.TrimSpace(filter.Name), filter.LatestOnly, filter.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("list run templates: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]RunTemplateRecord, 0)
+	for rows.Next() {
+		record, err := scanRunTemplate(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan run template: %w", err)
+		}
+		out = append(out, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate run templates: %w", err)
+	}
+	return out, nil
+}
+
+func scanRunTemplate(row interface{ Scan(...any) error }) (RunTemplateRecord, error) {
+	var (
+		out         RunTemplateRecord
+		description sql.NullString
+	)
+	if err := row.Scan(
+		&out.TemplateID,
+		&out.ProjectID,
+		&out.Name,
+		&out.Version,
+		&description,
+		&out.Template,
+		&out.ParamsSchema,
+		&out.CreatedAt,
+		&out.CreatedBy,
+	); err != nil {
+		return RunTemplateRecord{}, err
+	}
+	out.Description = description.String
+	out.CreatedAt = out.CreatedAt.UTC()
+	return out, nil
+}
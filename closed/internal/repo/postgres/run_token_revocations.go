@@ -0,0 +1,126 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+)
+
+type RunTokenRevocationStore struct {
+	db DB
+}
+
+const (
+	insertRunTokenRevocationQuery = `INSERT INTO run_token_revocations (
+		revocation_id,
+		run_id,
+		token_sha256,
+		reason,
+		revoked_at,
+		revoked_by
+	) VALUES ($1,$2,$3,$4,$5,$6)`
+
+	listRunTokenRevocationsQuery = `SELECT revocation_id, run_id, token_sha256, reason, revoked_at, revoked_by
+		FROM run_token_revocations
+		WHERE ($1 = '' OR run_id = $1)
+		ORDER BY revoked_at DESC
+		LIMIT $2`
+
+	runTokenRevokedQuery = `SELECT EXISTS (
+		SELECT 1 FROM run_token_revocations
+		WHERE token_sha256 = $2
+		   OR (run_id = $1 AND token_sha256 IS NULL AND revoked_at >= $3)
+	)`
+)
+
+func NewRunTokenRevocationStore(db DB) *RunTokenRevocationStore {
+	if db == nil {
+		return nil
+	}
+	return &RunTokenRevocationStore{db: db}
+}
+
+func (s *RunTokenRevocationStore) Create(ctx context.Context, record repo.RunTokenRevocationRecord) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("run token revocation store not initialized")
+	}
+	record.RevocationID = strings.TrimSpace(record.RevocationID)
+	record.RunID = strings.TrimSpace(record.RunID)
+	record.TokenSHA256 = strings.ToLower(strings.TrimSpace(record.TokenSHA256))
+	record.Reason = strings.TrimSpace(record.Reason)
+	record.RevokedBy = strings.TrimSpace(record.RevokedBy)
+	if record.RevocationID == "" || record.RunID == "" || record.Reason == "" || record.RevokedBy == "" {
+		return fmt.Errorf("revocation_id, run_id, reason, and revoked_by are required")
+	}
+
+	_, err := s.db.ExecContext(
+		ctx,
+		insertRunTokenRevocationQuery,
+		record.RevocationID,
+		record.RunID,
+		nullIfEmpty(record.TokenSHA256),
+		record.Reason,
+		normalizeTime(record.RevokedAt),
+		record.RevokedBy,
+	)
+	if err != nil {
+		return fmt.Errorf("insert run token revocation: %w", err)
+	}
+	return nil
+}
+
+func (s *RunTokenRevocationStore) List(ctx context.Context, runID string, limit int) ([]repo.RunTokenRevocationRecord, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("run token revocation store not initialized")
+	}
+	if limit <= 0 {
+		limit = 100
+	}
+	rows, err := s.db.QueryContext(ctx, listRunTokenRevocationsQuery, strings.TrimSpace(runID), limit)
+	if err != nil {
+		return nil, fmt.Errorf("list run token revocations: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]repo.RunTokenRevocationRecord, 0)
+	for rows.Next() {
+		var (
+			record      repo.RunTokenRevocationRecord
+			tokenSHA256 sql.NullString
+		)
+		if err := rows.Scan(&record.RevocationID, &record.RunID, &tokenSHA256, &record.Reason, &record.RevokedAt, &record.RevokedBy); err != nil {
+			return nil, fmt.Errorf("scan run token revocation: %w", err)
+		}
+		record.TokenSHA256 = strings.TrimSpace(tokenSHA256.String)
+		record.RevokedAt = record.RevokedAt.UTC()
+		out = append(out, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list run token revocations: %w", err)
+	}
+	return out, nil
+}
+
+// IsRevoked reports whether the token is blocked by its hash or by a run-wide
+// revocation recorded at or after the token was issued.
+func (s *RunTokenRevocationStore) IsRevoked(ctx context.Context, runID, tokenSHA256 string, issuedAt time.Time) (bool, error) {
+	if s == nil || s.db == nil {
+		return false, fmt.Errorf("run token revocation store not initialized")
+	}
+	var revoked bool
+	err := s.db.QueryRowContext(
+		ctx,
+		runTokenRevokedQuery,
+		strings.TrimSpace(runID),
+		strings.ToLower(strings.TrimSpace(tokenSHA256)),
+		issuedAt.UTC(),
+	).Scan(&revoked)
+	if err != nil {
+		return false, fmt.Errorf("check run token revocation: %w", err)
+	}
+	return revoked, nil
+}
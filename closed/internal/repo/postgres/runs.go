@@ -13,13 +13,23 @@ import (
 
 type RunStore struct {
 	db DB
+	// reads serves ListRunSummaries; it defaults to db.
+	reads Querier
 }
 
 func NewRunStore(db DB) *RunStore {
 	if db == nil {
 		return nil
 	}
-	return &RunStore{db: db}
+	return &RunStore{db: db, reads: db}
+}
+
+// WithReads returns a copy of the store that lists runs through reads.
+func (s *RunStore) WithReads(reads Querier) *RunStore {
+	if s == nil || reads == nil {
+		return s
+	}
+	return &RunStore{db: s.db, reads: reads}
 }
 
 func (s *RunStore) CreateRun(ctx context.Context, run domain.Run) error {
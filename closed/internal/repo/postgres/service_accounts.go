@@ -0,0 +1,318 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/repo"
+)
+
+type ServiceAccountStore struct {
+	db DB
+}
+
+const (
+	insertServiceAccountQuery = `INSERT INTO service_accounts (
+		account_id,
+		name,
+		description,
+		created_at,
+		created_by
+	) VALUES ($1,$2,$3,$4,$5)`
+
+	selectServiceAccountQuery = `SELECT account_id, name, description, created_at, created_by
+		FROM service_accounts WHERE account_id = $1`
+
+	listServiceAccountsQuery = `SELECT account_id, name, description, created_at, created_by
+		FROM service_accounts ORDER BY name ASC`
+
+	insertServiceAccountTokenQuery = `INSERT INTO service_account_tokens (
+		token_id,
+		account_id,
+		name,
+		token_sha256,
+		role,
+		project_id,
+		created_at,
+		created_by,
+		expires_at,
+		rotated_from
+	) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)`
+
+	serviceAccountTokenColumns = `token_id, account_id, name, token_sha256, role, project_id, created_at, created_by,
+		expires_at, last_used_at, revoked_at, revoked_by, revoke_reason, rotated_from`
+
+	selectServiceAccountTokenQuery = `SELECT ` + serviceAccountTokenColumns + `
+		FROM service_account_tokens WHERE token_id = $1`
+
+	listServiceAccountTokensQuery = `SELECT ` + serviceAccountTokenColumns + `
+		FROM service_account_tokens WHERE account_id = $1 ORDER BY created_at DESC`
+
+	touchServiceAccountTokenQuery = `UPDATE service_account_tokens SET last_used_at = $1 WHERE token_id = $2`
+
+	revokeServiceAccountTokenQuery = `UPDATE service_account_tokens
+		SET revoked_at = $1,
+		    revoked_by = $2,
+		    revoke_reason = $3
+		WHERE token_id = $4 AND revoked_at IS NULL`
+)
+
+func NewServiceAccountStore(db DB) *ServiceAccountStore {
+	if db == nil {
+		return nil
+	}
+	return &ServiceAccountStore{db: db}
+}
+
+func (s *ServiceAccountStore) CreateAccount(ctx context.Context, record repo.ServiceAccountRecord) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("service account store not initialized")
+	}
+	record.AccountID = strings.TrimSpace(record.AccountID)
+	record.Name = strings.TrimSpace(record.Name)
+	record.Description = strings.TrimSpace(record.Description)
+	record.CreatedBy = strings.TrimSpace(record.CreatedBy)
+	if record.AccountID == "" || record.Name == "" || record.CreatedBy == "" {
+		return fmt.Errorf("account_id, name, and created_by are required")
+	}
+
+	_, err := s.db.ExecContext(
+		ctx,
+		insertServiceAccountQuery,
+		record.AccountID,
+		record.Name,
+		nullIfEmpty(record.Description),
+		normalizeTime(record.CreatedAt),
+		record.CreatedBy,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return repo.ErrConflict
+		}
+		return fmt.Errorf("insert service account: %w", err)
+	}
+	return nil
+}
+
+func (s *ServiceAccountStore) GetAccount(ctx context.Context, accountID string) (repo.ServiceAccountRecord, error) {
+	if s == nil || s.db == nil {
+		return repo.ServiceAccountRecord{}, fmt.Errorf("service account store not initialized")
+	}
+	accountID = strings.TrimSpace(accountID)
+	if accountID == "" {
+		return repo.ServiceAccountRecord{}, fmt.Errorf("account_id is required")
+	}
+	return scanServiceAccount(s.db.QueryRowContext(ctx, selectServiceAccountQuery, accountID))
+}
+
+func (s *ServiceAccountStore) ListAccounts(ctx context.Context) ([]repo.ServiceAccountRecord, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("service account store not initialized")
+	}
+	rows, err := s.db.QueryContext(ctx, listServiceAccountsQuery)
+	if err != nil {
+		return nil, fmt.Errorf("list service accounts: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]repo.ServiceAccountRecord, 0)
+	for rows.Next() {
+		record, err := scanServiceAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list service accounts: %w", err)
+	}
+	return out, nil
+}
+
+func (s *ServiceAccountStore) CreateToken(ctx context.Context, record repo.ServiceAccountTokenRecord) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("service account store not initialized")
+	}
+	record.TokenID = strings.TrimSpace(record.TokenID)
+	record.AccountID = strings.TrimSpace(record.AccountID)
+	record.Name = strings.TrimSpace(record.Name)
+	record.TokenSHA256 = strings.TrimSpace(record.TokenSHA256)
+	record.Role = strings.ToLower(strings.TrimSpace(record.Role))
+	record.ProjectID = strings.TrimSpace(record.ProjectID)
+	record.CreatedBy = strings.TrimSpace(record.CreatedBy)
+	record.RotatedFrom = strings.TrimSpace(record.RotatedFrom)
+	if record.TokenID == "" || record.AccountID == "" || record.TokenSHA256 == "" || record.Role == "" || record.CreatedBy == "" {
+		return fmt.Errorf("token_id, account_id, token_sha256, role, and created_by are required")
+	}
+	if record.ExpiresAt.IsZero() {
+		return fmt.Errorf("expires_at is required")
+	}
+
+	_, err := s.db.ExecContext(
+		ctx,
+		insertServiceAccountTokenQuery,
+		record.TokenID,
+		record.AccountID,
+		nullIfEmpty(record.Name),
+		record.TokenSHA256,
+		record.Role,
+		nullIfEmpty(record.ProjectID),
+		normalizeTime(record.CreatedAt),
+		record.CreatedBy,
+		record.ExpiresAt.UTC(),
+		nullIfEmpty(record.RotatedFrom),
+	)
+	if err != nil {
+		return fmt.Errorf("insert service account token: %w", err)
+	}
+	return nil
+}
+
+func (s *ServiceAccountStore) GetToken(ctx context.Context, tokenID string) (repo.ServiceAccountTokenRecord, error) {
+	if s == nil || s.db == nil {
+		return repo.ServiceAccountTokenRecord{}, fmt.Errorf("service account store not initialized")
+	}
+	tokenID = strings.TrimSpace(tokenID)
+	if tokenID == "" {
+		return repo.ServiceAccountTokenRecord{}, fmt.Errorf("token_id is required")
+	}
+	return scanServiceAccountToken(s.db.QueryRowContext(ctx, selectServiceAccountTokenQuery, tokenID))
+}
+
+func (s *ServiceAccountStore) ListTokens(ctx context.Context, accountID string) ([]repo.ServiceAccountTokenRecord, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("service account store not initialized")
+	}
+	accountID = strings.TrimSpace(accountID)
+	if accountID == "" {
+		return nil, fmt.Errorf("account_id is required")
+	}
+	rows, err := s.db.QueryContext(ctx, listServiceAccountTokensQuery, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("list service account tokens: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]repo.ServiceAccountTokenRecord, 0)
+	for rows.Next() {
+		record, err := scanServiceAccountToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list service account tokens: %w", err)
+	}
+	return out, nil
+}
+
+func (s *ServiceAccountStore) TouchToken(ctx context.Context, tokenID string, at time.Time) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("service account store not initialized")
+	}
+	tokenID = strings.TrimSpace(tokenID)
+	if tokenID == "" {
+		return fmt.Errorf("token_id is required")
+	}
+	if _, err := s.db.ExecContext(ctx, touchServiceAccountTokenQuery, normalizeTime(at), tokenID); err != nil {
+		return fmt.Errorf("update service account token last_used: %w", err)
+	}
+	return nil
+}
+
+func (s *ServiceAccountStore) RevokeToken(ctx context.Context, tokenID, revokedBy, reason string, at time.Time) (bool, error) {
+	if s == nil || s.db == nil {
+		return false, fmt.Errorf("service account store not initialized")
+	}
+	tokenID = strings.TrimSpace(tokenID)
+	if tokenID == "" {
+		return false, fmt.Errorf("token_id is required")
+	}
+	res, err := s.db.ExecContext(
+		ctx,
+		revokeServiceAccountTokenQuery,
+		normalizeTime(at),
+		nullIfEmpty(strings.TrimSpace(revokedBy)),
+		nullIfEmpty(strings.TrimSpace(reason)),
+		tokenID,
+	)
+	if err != nil {
+		return false, fmt.Errorf("revoke service account token: %w", err)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("revoke service account token: %w", err)
+	}
+	return rows > 0, nil
+}
+
+func scanServiceAccount(row sessionScanner) (repo.ServiceAccountRecord, error) {
+	var (
+		record      repo.ServiceAccountRecord
+		description sql.NullString
+	)
+	if err := row.Scan(&record.AccountID, &record.Name, &description, &record.CreatedAt, &record.CreatedBy); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return repo.ServiceAccountRecord{}, repo.ErrNotFound
+		}
+		return repo.ServiceAccountRecord{}, fmt.Errorf("scan service account: %w", err)
+	}
+	record.Description = strings.TrimSpace(description.String)
+	record.CreatedAt = record.CreatedAt.UTC()
+	return record, nil
+}
+
+func scanServiceAccountToken(row sessionScanner) (repo.ServiceAccountTokenRecord, error) {
+	var (
+		record      repo.ServiceAccountTokenRecord
+		name        sql.NullString
+		projectID   sql.NullString
+		lastUsed    sql.NullTime
+		revokedAt   sql.NullTime
+		revokedBy   sql.NullString
+		reason      sql.NullString
+		rotatedFrom sql.NullString
+	)
+	if err := row.Scan(
+		&record.TokenID,
+		&record.AccountID,
+		&name,
+		&record.TokenSHA256,
+		&record.Role,
+		&projectID,
+		&record.CreatedAt,
+		&record.CreatedBy,
+		&record.ExpiresAt,
+		&lastUsed,
+		&revokedAt,
+		&revokedBy,
+		&reason,
+		&rotatedFrom,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return repo.ServiceAccountTokenRecord{}, repo.ErrNotFound
+		}
+		return repo.ServiceAccountTokenRecord{}, fmt.Errorf("scan service account token: %w", err)
+	}
+	record.Name = strings.TrimSpace(name.String)
+	record.ProjectID = strings.TrimSpace(projectID.String)
+	record.RevokedBy = strings.TrimSpace(revokedBy.String)
+	record.RevokeReason = strings.TrimSpace(reason.String)
+	record.RotatedFrom = strings.TrimSpace(rotatedFrom.String)
+	record.CreatedAt = record.CreatedAt.UTC()
+	record.ExpiresAt = record.ExpiresAt.UTC()
+	if lastUsed.Valid {
+		t := lastUsed.Time.UTC()
+		record.LastUsedAt = &t
+	}
+	if revokedAt.Valid {
+		t := revokedAt.Time.UTC()
+		record.RevokedAt = &t
+	}
+	return record, nil
+}
@@ -0,0 +1,18 @@
+package postgres
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestServiceAccountTokenRevokeIsIdempotent(t *testing.T) {
+	if !strings.Contains(revokeServiceAccountTokenQuery, "revoked_at IS NULL") {
+		t.Fatalf("expected revoke to skip already revoked tokens: %s", revokeServiceAccountTokenQuery)
+	}
+}
+
+func TestServiceAccountTokenListIsScopedToAccount(t *testing.T) {
+	if !strings.Contains(listServiceAccountTokensQuery, "WHERE account_id = $1") {
+		t.Fatalf("expected account filter in query: %s", listServiceAccountTokensQuery)
+	}
+}
@@ -0,0 +1,380 @@
+package runtimeexec
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const AWSBatchKind = "aws_batch"
+
+// AWSBatchExecutor submits container jobs to an AWS Batch job queue. Batch
+// cannot override the image of a job definition, so every image digest gets
+// its own animus-* job definition, registered on first use and reused while
+// it stays ACTIVE.
+type AWSBatchExecutor struct {
+	endpoint      string
+	region        string
+	jobQueue      string
+	jobRoleARN    string
+	defaultVCPUs  int
+	defaultMemory int64
+	creds         awsCredentials
+	http          *http.Client
+	now           func() time.Time
+}
+
+// newAWSBatchPlugin reads the settings region and job_queue, and optionally
+// job_role_arn, default_vcpus (1), default_memory_mib (2048), endpoint (for
+// VPC endpoints), timeout and access_key_id/secret_access_key/session_token,
+// which default to the standard AWS_* environment variables.
+func newAWSBatchPlugin(settings map[string]string) (Plugin, error) {
+	region := strings.TrimSpace(settings["region"])
+	if region == "" {
+		region = strings.TrimSpace(os.Getenv("AWS_REGION"))
+	}
+	if region == "" {
+		return nil, errors.New("aws batch region is required")
+	}
+	jobQueue := strings.TrimSpace(settings["job_queue"])
+	if jobQueue == "" {
+		return nil, errors.New("aws batch job queue is required")
+	}
+	endpoint := strings.TrimRight(strings.TrimSpace(settings["endpoint"]), "/")
+	if endpoint == "" {
+		endpoint = "https://batch." + region + ".amazonaws.com"
+	}
+	if u, err := url.Parse(endpoint); err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, errors.New("aws batch endpoint must be an http(s) URL")
+	}
+	creds := awsCredentials{
+		AccessKeyID:     settingOrEnv(settings, "access_key_id", "AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: settingOrEnv(settings, "secret_access_key", "AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    settingOrEnv(settings, "session_token", "AWS_SESSION_TOKEN"),
+	}
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return nil, errors.New("aws credentials are required")
+	}
+	vcpus := 1
+	if raw := strings.TrimSpace(settings["default_vcpus"]); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			return nil, errors.New("aws batch default_vcpus must be a positive integer")
+		}
+		vcpus = parsed
+	}
+	memory := int64(2048)
+	if raw := strings.TrimSpace(settings["default_memory_mib"]); raw != "" {
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || parsed <= 0 {
+			return nil, errors.New("aws batch default_memory_mib must be a positive integer")
+		}
+		memory = parsed
+	}
+	timeout := 30 * time.Second
+	if raw := strings.TrimSpace(settings["timeout"]); raw != "" {
+		parsed, err := time.ParseDuration(raw)
+		if err != nil || parsed <= 0 {
+			return nil, errors.New("aws batch timeout must be a positive duration")
+		}
+		timeout = parsed
+	}
+	return &AWSBatchExecutor{
+		endpoint:      endpoint,
+		region:        region,
+		jobQueue:      jobQueue,
+		jobRoleARN:    strings.TrimSpace(settings["job_role_arn"]),
+		defaultVCPUs:  vcpus,
+		defaultMemory: memory,
+		creds:         creds,
+		http:          &http.Client{Timeout: timeout},
+		now:           time.Now,
+	}, nil
+}
+
+func settingOrEnv(settings map[string]string, key, envKey string) string {
+	if value := strings.TrimSpace(settings[key]); value != "" {
+		return value
+	}
+	return strings.TrimSpace(os.Getenv(envKey))
+}
+
+func (e *AWSBatchExecutor) Kind() string {
+	return AWSBatchKind
+}
+
+func (e *AWSBatchExecutor) Submit(ctx context.Context, spec JobSpec) error {
+	_, err := e.Launch(ctx, spec)
+	return err
+}
+
+type batchKeyValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type batchResourceRequirement struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+func (e *AWSBatchExecutor) Launch(ctx context.Context, spec JobSpec) (Execution, error) {
+	if strings.TrimSpace(spec.RunID) == "" {
+		return Execution{}, errors.New("run id is required")
+	}
+	imageRef := strings.TrimSpace(spec.ImageRef)
+	if imageRef == "" {
+		return Execution{}, errors.New("image ref is required")
+	}
+	requirements, err := batchResourceRequirements(spec.Resources)
+	if err != nil {
+		return Execution{}, err
+	}
+	definition, err := e.jobDefinition(ctx, imageRef)
+	if err != nil {
+		return Execution{}, err
+	}
+
+	environment := []batchKeyValue{}
+	for _, kv := range jobEnvironment(spec) {
+		environment = append(environment, batchKeyValue{Name: kv[0], Value: kv[1]})
+	}
+	overrides := map[string]any{"environment": environment}
+	if len(requirements) > 0 {
+		overrides["resourceRequirements"] = requirements
+	}
+	var resp struct {
+		JobID string `json:"jobId"`
+	}
+	err = e.call(ctx, "/v1/submitjob", map[string]any{
+		"jobName":            pluginJobName(spec),
+		"jobQueue":           e.jobQueue,
+		"jobDefinition":      definition,
+		"containerOverrides": overrides,
+		"tags":               map[string]string{"animus.run_id": spec.RunID},
+		"propagateTags":      true,
+	}, &resp)
+	if err != nil {
+		return Execution{}, err
+	}
+	if strings.TrimSpace(resp.JobID) == "" {
+		return Execution{}, errors.New("aws batch: submit returned no job id")
+	}
+	return Execution{
+		RunID:      spec.RunID,
+		Executor:   AWSBatchKind,
+		ExternalID: resp.JobID,
+	}, nil
+}
+
+// jobDefinition returns the ARN of the ACTIVE animus job definition for
+// imageRef, registering it when there is none.
+func (e *AWSBatchExecutor) jobDefinition(ctx context.Context, imageRef string) (string, error) {
+	sum := sha256.Sum256([]byte(imageRef))
+	name := "animus-" + hex.EncodeToString(sum[:12])
+
+	var described struct {
+		JobDefinitions []struct {
+			JobDefinitionArn    string `json:"jobDefinitionArn"`
+			ContainerProperties struct {
+				Image string `json:"image"`
+			} `json:"containerProperties"`
+		} `json:"jobDefinitions"`
+	}
+	if err := e.call(ctx, "/v1/describejobdefinitions", map[string]any{"jobDefinitionName": name, "status": "ACTIVE"}, &described); err != nil {
+		return "", err
+	}
+	for _, def := range described.JobDefinitions {
+		if def.ContainerProperties.Image == imageRef {
+			return def.JobDefinitionArn, nil
+		}
+	}
+
+	properties := map[string]any{
+		"image": imageRef,
+		"resourceRequirements": []batchResourceRequirement{
+			{Type: "VCPU", Value: strconv.Itoa(e.defaultVCPUs)},
+			{Type: "MEMORY", Value: strconv.FormatInt(e.defaultMemory, 10)},
+		},
+	}
+	if e.jobRoleARN != "" {
+		properties["jobRoleArn"] = e.jobRoleARN
+	}
+	var registered struct {
+		JobDefinitionArn string `json:"jobDefinitionArn"`
+	}
+	err := e.call(ctx, "/v1/registerjobdefinition", map[string]any{
+		"jobDefinitionName":   name,
+		"type":                "container",
+		"containerProperties": properties,
+		"tags":                map[string]string{"animus.managed": "true"},
+	}, &registered)
+	if err != nil {
+		return "", err
+	}
+	return registered.JobDefinitionArn, nil
+}
+
+// Find returns the newest job in the queue with the job name of spec. Batch
+// lists jobs by name for as long as it retains them, about a week after
+// they finish.
+func (e *AWSBatchExecutor) Find(ctx context.Context, spec JobSpec) (Execution, error) {
+	name := pluginJobName(spec)
+	var resp struct {
+		JobSummaryList []struct {
+			JobID     string `json:"jobId"`
+			JobName   string `json:"jobName"`
+			CreatedAt int64  `json:"createdAt"`
+		} `json:"jobSummaryList"`
+	}
+	err := e.call(ctx, "/v1/listjobs", map[string]any{
+		"jobQueue": e.jobQueue,
+		"filters":  []map[string]any{{"name": "JOB_NAME", "values": []string{name}}},
+	}, &resp)
+	if err != nil {
+		return Execution{}, err
+	}
+	var (
+		jobID   string
+		created int64
+	)
+	for _, job := range resp.JobSummaryList {
+		if job.JobName != name || strings.TrimSpace(job.JobID) == "" {
+			continue
+		}
+		if jobID == "" || job.CreatedAt > created {
+			jobID, created = job.JobID, job.CreatedAt
+		}
+	}
+	if jobID == "" {
+		return Execution{}, ErrExecutionNotFound
+	}
+	return Execution{
+		RunID:      spec.RunID,
+		Executor:   AWSBatchKind,
+		ExternalID: jobID,
+	}, nil
+}
+
+func (e *AWSBatchExecutor) Inspect(ctx context.Context, execution Execution) (Observation, error) {
+	jobID := strings.TrimSpace(execution.ExternalID)
+	if jobID == "" {
+		return Observation{}, errors.New("aws batch job id is required")
+	}
+	var resp struct {
+		Jobs []struct {
+			Status       string `json:"status"`
+			StatusReason string `json:"statusReason"`
+			Container    struct {
+				ExitCode *int   `json:"exitCode"`
+				Reason   string `json:"reason"`
+			} `json:"container"`
+		} `json:"jobs"`
+	}
+	if err := e.call(ctx, "/v1/describejobs", map[string]any{"jobs": []string{jobID}}, &resp); err != nil {
+		return Observation{}, err
+	}
+	if len(resp.Jobs) == 0 {
+		return Observation{Status: StatusPending, Message: "job_not_found"}, nil
+	}
+
+	job := resp.Jobs[0]
+	status := StatusPending
+	switch job.Status {
+	case "RUNNING":
+		status = StatusRunning
+	case "SUCCEEDED":
+		status = StatusSucceeded
+	case "FAILED":
+		status = StatusFailed
+	}
+	message := strings.TrimSpace(job.StatusReason)
+	if message == "" {
+		message = strings.TrimSpace(job.Container.Reason)
+	}
+	if message == "" {
+		message = strings.ToLower(job.Status)
+	}
+	details := map[string]any{
+		"aws_batch_job_id": jobID,
+		"aws_batch_status": job.Status,
+	}
+	if job.Container.ExitCode != nil {
+		details["exit_code"] = *job.Container.ExitCode
+	}
+	return Observation{Status: status, Message: message, Details: details}, nil
+}
+
+func (e *AWSBatchExecutor) Cancel(ctx context.Context, execution Execution) error {
+	jobID := strings.TrimSpace(execution.ExternalID)
+	if jobID == "" {
+		return errors.New("aws batch job id is required")
+	}
+	return e.call(ctx, "/v1/terminatejob", map[string]any{"jobId": jobID, "reason": "canceled by animus"}, nil)
+}
+
+func (e *AWSBatchExecutor) call(ctx context.Context, path string, body any, out any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint+path, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	signAWSRequestV4(req, payload, "batch", e.region, e.creds, e.now())
+	resp, err := e.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		_ = json.Unmarshal(respBody, &apiErr)
+		if apiErr.Message == "" {
+			apiErr.Message = strings.TrimSpace(string(respBody))
+		}
+		return fmt.Errorf("aws batch %s status %d: %s", strings.TrimPrefix(path, "/v1/"), resp.StatusCode, apiErr.Message)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(respBody, out)
+}
+
+func batchResourceRequirements(resources map[string]any) ([]batchResourceRequirement, error) {
+	out := []batchResourceRequirement{}
+	if cpu, ok := resources["cpu"].(string); ok && strings.TrimSpace(cpu) != "" {
+		vcpus, err := wholeCPUs(cpu)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, batchResourceRequirement{Type: "VCPU", Value: strconv.Itoa(vcpus)})
+	}
+	if memory, ok := resources["memory"].(string); ok && strings.TrimSpace(memory) != "" {
+		mib, err := memoryMiB(memory)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, batchResourceRequirement{Type: "MEMORY", Value: strconv.FormatInt(mib, 10)})
+	}
+	if gpus := parseIntResource(resources, "gpus"); gpus > 0 {
+		out = append(out, batchResourceRequirement{Type: "GPU", Value: strconv.Itoa(gpus)})
+	}
+	return out, nil
+}
@@ -6,12 +6,20 @@ import (
 	"errors"
 	"fmt"
 	"os/exec"
+	"path"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
 )
 
+// DockerManagedLabel marks containers started by DockerExecutor; ReapExited
+// only considers containers carrying it.
+const DockerManagedLabel = "animus.managed"
+
+// DockerRunContainerPrefix is the name prefix of run containers.
+const DockerRunContainerPrefix = "animus-run-"
+
 type DockerExecutor struct {
 	dockerBin string
 }
@@ -58,18 +66,29 @@ func (e *DockerExecutor) ResolveImageID(ctx context.Context, imageRef string) (s
 }
 
 func (e *DockerExecutor) Submit(ctx context.Context, spec JobSpec) error {
+	args, err := dockerRunArgs(spec)
+	if err != nil {
+		return err
+	}
+	cmd := exec.CommandContext(ctx, e.dockerBin, args...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("docker run failed: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// dockerRunArgs builds the docker run arguments for spec. Resources map
+// onto --cpus, --memory and --gpus; labels and environment are emitted in
+// key order so the same spec always yields the same command.
+func dockerRunArgs(spec JobSpec) ([]string, error) {
 	name := strings.TrimSpace(spec.DockerName)
 	if name == "" {
-		return errors.New("docker container name is required")
+		return nil, errors.New("docker container name is required")
 	}
 	imageRef := strings.TrimSpace(spec.ImageRef)
 	if imageRef == "" {
-		return errors.New("image ref is required")
-	}
-
-	jobKind := strings.TrimSpace(spec.JobKind)
-	if jobKind == "" {
-		jobKind = "training"
+		return nil, errors.New("image ref is required")
 	}
 
 	args := []string{
@@ -77,48 +96,79 @@ func (e *DockerExecutor) Submit(ctx context.Context, spec JobSpec) error {
 		"--detach",
 		"--name", name,
 		"--network", "host",
-		"-e", "RUN_ID=" + spec.RunID,
-		"-e", "DATASET_VERSION_ID=" + spec.DatasetVersionID,
-		"-e", "DATAPILOT_URL=" + spec.DatapilotURL,
-		"-e", "TOKEN=" + spec.Token,
-		"-e", "ANIMUS_JOB_KIND=" + jobKind,
-	}
-
-	if len(spec.Env) > 0 {
-		keys := make([]string, 0, len(spec.Env))
-		for k := range spec.Env {
-			key := strings.TrimSpace(k)
-			if key == "" || isReservedJobEnvKey(key) {
-				continue
-			}
-			keys = append(keys, key)
+	}
+	for _, kv := range dockerLabels(spec) {
+		args = append(args, "--label", kv[0]+"="+kv[1])
+	}
+	for _, kv := range jobEnvironment(spec) {
+		args = append(args, "-e", kv[0]+"="+kv[1])
+	}
+	for _, mount := range spec.Mounts {
+		source, target := strings.TrimSpace(mount.Source), strings.TrimSpace(mount.Target)
+		if !path.IsAbs(source) || !path.IsAbs(target) || strings.Contains(source+target, ",") {
+			return nil, fmt.Errorf("invalid mount %q:%q", mount.Source, mount.Target)
 		}
-		sort.Strings(keys)
-		for _, key := range keys {
-			args = append(args, "-e", key+"="+spec.Env[key])
+		value := "type=bind,source=" + source + ",target=" + target
+		if mount.ReadOnly {
+			value += ",readonly"
 		}
+		args = append(args, "--mount", value)
 	}
 
-	if gpus := parseIntResource(spec.Resources, "gpus"); gpus > 0 {
-		args = append(args, "--gpus", strconv.Itoa(gpus))
-	}
 	if cpu, ok := spec.Resources["cpu"].(string); ok && strings.TrimSpace(cpu) != "" {
-		if parsed, err := strconv.ParseFloat(strings.TrimSpace(cpu), 64); err == nil && parsed > 0 {
-			args = append(args, "--cpus", fmt.Sprintf("%g", parsed))
+		cpus, err := cpuCores(cpu)
+		if err != nil {
+			return nil, err
 		}
+		args = append(args, "--cpus", strconv.FormatFloat(cpus, 'f', -1, 64))
 	}
-	if mem, ok := spec.Resources["memory"].(string); ok && strings.TrimSpace(mem) != "" {
-		args = append(args, "--memory", strings.TrimSpace(mem))
+	if memory, ok := spec.Resources["memory"].(string); ok && strings.TrimSpace(memory) != "" {
+		mib, err := memoryMiB(memory)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, "--memory", strconv.FormatInt(mib, 10)+"m")
+	}
+	if gpus := parseIntResource(spec.Resources, "gpus"); gpus > 0 {
+		args = append(args, "--gpus", strconv.Itoa(gpus))
 	}
 
+	if len(spec.Command) > 0 {
+		args = append(args, "--entrypoint", spec.Command[0])
+	}
 	args = append(args, imageRef)
+	if len(spec.Command) > 1 {
+		args = append(args, spec.Command[1:]...)
+	}
+	args = append(args, spec.Args...)
+	return args, nil
+}
 
-	cmd := exec.CommandContext(ctx, e.dockerBin, args...)
-	out, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("docker run failed: %w: %s", err, strings.TrimSpace(string(out)))
+// dockerLabels are the container labels of spec sorted by key. spec.Labels
+// cannot override the animus.* labels derived from the spec itself.
+func dockerLabels(spec JobSpec) [][2]string {
+	labels := map[string]string{}
+	for key, value := range spec.Labels {
+		if key = strings.TrimSpace(key); key != "" {
+			labels[key] = value
+		}
 	}
-	return nil
+	labels[DockerManagedLabel] = "true"
+	labels["animus.run_id"] = strings.TrimSpace(spec.RunID)
+	labels["animus.job_kind"] = jobKindOf(spec)
+	if id := strings.TrimSpace(spec.DatasetVersionID); id != "" {
+		labels["animus.dataset_version_id"] = id
+	}
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	out := make([][2]string, 0, len(keys))
+	for _, key := range keys {
+		out = append(out, [2]string{key, labels[key]})
+	}
+	return out
 }
 
 type dockerInspectState struct {
@@ -147,7 +197,10 @@ func (e *DockerExecutor) Inspect(ctx context.Context, execution Execution) (Obse
 	if err := json.Unmarshal(out, &state); err != nil {
 		return Observation{}, fmt.Errorf("parse docker inspect: %w", err)
 	}
+	return dockerObservation(name, state), nil
+}
 
+func dockerObservation(name string, state dockerInspectState) Observation {
 	status := "pending"
 	switch strings.ToLower(strings.TrimSpace(state.Status)) {
 	case "running":
@@ -168,5 +221,136 @@ func (e *DockerExecutor) Inspect(ctx context.Context, execution Execution) (Obse
 			"exit_code":        state.ExitCode,
 			"finished_at":      state.FinishedAt,
 		},
-	}, nil
+	}
+}
+
+// Cancel force-removes the container; a container that is already gone is
+// not an error.
+func (e *DockerExecutor) Cancel(ctx context.Context, execution Execution) error {
+	name := strings.TrimSpace(execution.DockerContainer)
+	if name == "" {
+		return errors.New("docker container name is required")
+	}
+	cmd := exec.CommandContext(ctx, e.dockerBin, "rm", "--force", name)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		text := strings.TrimSpace(string(out))
+		if strings.Contains(text, "No such container") || strings.Contains(text, "not found") {
+			return nil
+		}
+		return fmt.Errorf("docker rm failed: %w: %s", err, text)
+	}
+	return nil
+}
+
+// Logs returns the container's stdout and stderr written after since, each
+// line prefixed with its RFC 3339 timestamp like kubelet logs.
+func (e *DockerExecutor) Logs(ctx context.Context, execution Execution, since time.Time) ([]byte, error) {
+	name := strings.TrimSpace(execution.DockerContainer)
+	if name == "" {
+		return nil, errors.New("docker container name is required")
+	}
+	args := []string{"logs", "--timestamps"}
+	if !since.IsZero() {
+		args = append(args, "--since", since.UTC().Format(time.RFC3339Nano))
+	}
+	args = append(args, name)
+	cmd := exec.CommandContext(ctx, e.dockerBin, args...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("docker logs failed: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return out, nil
+}
+
+// ReapedContainer is an exited run container found by ReapExited.
+type ReapedContainer struct {
+	Execution   Execution
+	Observation Observation
+	Labels      map[string]string
+}
+
+type dockerContainerInfo struct {
+	State  dockerInspectState `json:"State"`
+	Config struct {
+		Labels map[string]string `json:"Labels"`
+	} `json:"Config"`
+}
+
+// ReapExited removes managed animus-run-* containers that exited at least
+// olderThan ago and carry all of labels. Each container is passed to report
+// first and kept when report fails, so its exit code is not lost. It returns
+// the number of containers removed.
+func (e *DockerExecutor) ReapExited(ctx context.Context, olderThan time.Duration, labels map[string]string, report func(context.Context, ReapedContainer) error) (int, error) {
+	args := []string{"ps", "--all", "--no-trunc", "--filter", "status=exited", "--filter", "label=" + DockerManagedLabel + "=true"}
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		args = append(args, "--filter", "label="+key+"="+labels[key])
+	}
+	args = append(args, "--format", "{{.Names}}")
+	cmd := exec.CommandContext(ctx, e.dockerBin, args...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return 0, fmt.Errorf("docker ps failed: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+
+	now := time.Now()
+	reaped := 0
+	var errs []error
+	for _, name := range strings.Fields(string(out)) {
+		if !strings.HasPrefix(name, DockerRunContainerPrefix) {
+			continue
+		}
+		info, ok, err := e.inspectContainer(ctx, name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !ok || info.State.FinishedAt.IsZero() || now.Sub(info.State.FinishedAt) < olderThan {
+			continue
+		}
+		execution := Execution{
+			RunID:           info.Config.Labels["animus.run_id"],
+			Executor:        e.Kind(),
+			DockerContainer: name,
+		}
+		if report != nil {
+			if err := report(ctx, ReapedContainer{
+				Execution:   execution,
+				Observation: dockerObservation(name, info.State),
+				Labels:      info.Config.Labels,
+			}); err != nil {
+				errs = append(errs, fmt.Errorf("report %s: %w", name, err))
+				continue
+			}
+		}
+		if err := e.Cancel(ctx, execution); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		reaped++
+	}
+	return reaped, errors.Join(errs...)
+}
+
+// inspectContainer returns false when the container no longer exists.
+func (e *DockerExecutor) inspectContainer(ctx context.Context, name string) (dockerContainerInfo, bool, error) {
+	cmd := exec.CommandContext(ctx, e.dockerBin, "inspect", "--format", "{{json .}}", name)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		text := strings.TrimSpace(string(out))
+		if strings.Contains(text, "No such object") || strings.Contains(text, "not found") {
+			return dockerContainerInfo{}, false, nil
+		}
+		return dockerContainerInfo{}, false, fmt.Errorf("docker inspect failed: %w: %s", err, text)
+	}
+	var info dockerContainerInfo
+	if err := json.Unmarshal(out, &info); err != nil {
+		return dockerContainerInfo{}, false, fmt.Errorf("parse docker inspect: %w", err)
+	}
+	return info, true, nil
 }
@@ -0,0 +1,110 @@
+package runtimeexec
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDockerRunArgs(t *testing.T) {
+	args, err := dockerRunArgs(JobSpec{
+		RunID:            "run-1",
+		DatasetVersionID: "dv-1",
+		ImageRef:         "trainer@sha256:abc",
+		DockerName:       "animus-run-run-1",
+		Resources:        map[string]any{"cpu": "500m", "memory": "2Gi", "gpus": 1},
+		Env:              map[string]string{"B": "2", "A": "1"},
+		Labels:           map[string]string{"animus.run_id": "spoofed", "team": "vision"},
+		Command:          []string{"/bin/train", "--fast"},
+		Args:             []string{"--epochs=3"},
+		Mounts:           []Mount{{Source: "/var/cache/animus", Target: "/animus/dataset-cache"}},
+	})
+	if err != nil {
+		t.Fatalf("dockerRunArgs() err=%v", err)
+	}
+	got := strings.Join(args, " ")
+	want := "run --detach --name animus-run-run-1 --network host" +
+		" --label animus.dataset_version_id=dv-1 --label animus.job_kind=training --label animus.managed=true --label animus.run_id=run-1 --label team=vision" +
+		" -e RUN_ID=run-1 -e DATASET_VERSION_ID=dv-1 -e DATAPILOT_URL= -e TOKEN= -e ANIMUS_JOB_KIND=training -e A=1 -e B=2" +
+		" --mount type=bind,source=/var/cache/animus,target=/animus/dataset-cache" +
+		" --cpus 0.5 --memory 2048m --gpus 1 --entrypoint /bin/train trainer@sha256:abc --fast --epochs=3"
+	if got != want {
+		t.Fatalf("args=%s\nwant=%s", got, want)
+	}
+
+	for _, resources := range []map[string]any{{"cpu": "lots"}, {"memory": "2Gb"}} {
+		if _, err := dockerRunArgs(JobSpec{ImageRef: "trainer", DockerName: "c", Resources: resources}); err == nil {
+			t.Fatalf("expected %v to be rejected", resources)
+		}
+	}
+	for _, mount := range []Mount{{Source: "cache", Target: "/c"}, {Source: "/a,b", Target: "/c"}} {
+		if _, err := dockerRunArgs(JobSpec{ImageRef: "trainer", DockerName: "c", Mounts: []Mount{mount}}); err == nil {
+			t.Fatalf("expected mount %+v to be rejected", mount)
+		}
+	}
+}
+
+// fakeDocker installs a docker stand-in that answers ps and inspect from
+// fixed output and appends each invocation to the returned log.
+func fakeDocker(t *testing.T, finishedAt time.Time) (*DockerExecutor, string) {
+	t.Helper()
+	dir := t.TempDir()
+	log := filepath.Join(dir, "calls")
+	script := `#!/bin/sh
+echo "$*" >> ` + log + `
+case "$1" in
+ps) printf 'animus-run-old\nanimus-run-new\nsomething-else\n' ;;
+inspect)
+  case "$4" in
+  animus-run-old) echo '{"State":{"Status":"exited","ExitCode":2,"FinishedAt":"` + finishedAt.Format(time.RFC3339Nano) + `"},"Config":{"Labels":{"animus.run_id":"run-old","animus.project_id":"proj-1"}}}' ;;
+  *) echo '{"State":{"Status":"exited","ExitCode":0,"FinishedAt":"` + time.Now().UTC().Format(time.RFC3339Nano) + `"},"Config":{"Labels":{}}}' ;;
+  esac ;;
+esac
+`
+	bin := filepath.Join(dir, "docker")
+	if err := os.WriteFile(bin, []byte(script), 0o755); err != nil {
+		t.Fatalf("write fake docker: %v", err)
+	}
+	executor, err := NewDockerExecutor(bin)
+	if err != nil {
+		t.Fatalf("NewDockerExecutor() err=%v", err)
+	}
+	return executor, log
+}
+
+func TestDockerReapExited(t *testing.T) {
+	executor, log := fakeDocker(t, time.Now().Add(-2*time.Hour))
+
+	var reported []ReapedContainer
+	reaped, err := executor.ReapExited(context.Background(), time.Hour, map[string]string{"animus.agent_id": "agent-1"}, func(_ context.Context, c ReapedContainer) error {
+		reported = append(reported, c)
+		return nil
+	})
+	if err != nil || reaped != 1 {
+		t.Fatalf("ReapExited() reaped=%d err=%v", reaped, err)
+	}
+	if len(reported) != 1 || reported[0].Execution.RunID != "run-old" || reported[0].Observation.Status != StatusFailed ||
+		reported[0].Observation.Details["exit_code"] != 2 || reported[0].Labels["animus.project_id"] != "proj-1" {
+		t.Fatalf("reported=%+v", reported)
+	}
+	calls, _ := os.ReadFile(log)
+	if !strings.Contains(string(calls), "--filter label=animus.agent_id=agent-1") || !strings.Contains(string(calls), "rm --force animus-run-old") ||
+		strings.Contains(string(calls), "rm --force animus-run-new") || strings.Contains(string(calls), "something-else") {
+		t.Fatalf("calls:\n%s", calls)
+	}
+
+	_ = os.Remove(log)
+	reaped, err = executor.ReapExited(context.Background(), time.Hour, nil, func(context.Context, ReapedContainer) error {
+		return errors.New("control plane down")
+	})
+	if err == nil || reaped != 0 {
+		t.Fatalf("ReapExited() reaped=%d err=%v", reaped, err)
+	}
+	if calls, _ := os.ReadFile(log); strings.Contains(string(calls), "rm ") {
+		t.Fatalf("container removed after failed report:\n%s", calls)
+	}
+}
@@ -1,6 +1,8 @@
 package runtimeexec
 
 import (
+	"fmt"
+	"math"
 	"strconv"
 	"strings"
 )
@@ -39,3 +41,55 @@ func isReservedJobEnvKey(key string) bool {
 		return false
 	}
 }
+
+// cpuCores parses a Kubernetes CPU quantity ("2", "1.5", "500m").
+func cpuCores(quantity string) (float64, error) {
+	quantity = strings.TrimSpace(quantity)
+	var cpus float64
+	var err error
+	if milli, ok := strings.CutSuffix(quantity, "m"); ok {
+		cpus, err = strconv.ParseFloat(milli, 64)
+		cpus /= 1000
+	} else {
+		cpus, err = strconv.ParseFloat(quantity, 64)
+	}
+	if err != nil || cpus <= 0 {
+		return 0, fmt.Errorf("invalid cpu resource %q", quantity)
+	}
+	return cpus, nil
+}
+
+// wholeCPUs rounds a Kubernetes CPU quantity ("2", "500m") up to whole CPUs.
+func wholeCPUs(quantity string) (int, error) {
+	cpus, err := cpuCores(quantity)
+	if err != nil {
+		return 0, err
+	}
+	return int(math.Ceil(cpus)), nil
+}
+
+// memoryMiB converts a Kubernetes memory quantity ("8Gi", "512M") to MiB,
+// rounding up.
+func memoryMiB(quantity string) (int64, error) {
+	quantity = strings.TrimSpace(quantity)
+	units := []struct {
+		suffix string
+		bytes  float64
+	}{
+		{"Ki", 1 << 10}, {"Mi", 1 << 20}, {"Gi", 1 << 30}, {"Ti", 1 << 40},
+		{"K", 1e3}, {"M", 1e6}, {"G", 1e9}, {"T", 1e12},
+	}
+	multiplier := 1.0
+	number := quantity
+	for _, unit := range units {
+		if trimmed, ok := strings.CutSuffix(quantity, unit.suffix); ok {
+			number, multiplier = trimmed, unit.bytes
+			break
+		}
+	}
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil || value <= 0 {
+		return 0, fmt.Errorf("invalid memory resource %q", quantity)
+	}
+	return int64(math.Ceil(value * multiplier / (1 << 20))), nil
+}
@@ -16,9 +16,10 @@ type KubernetesJobExecutor struct {
 	namespace         string
 	jobTTLSeconds     int32
 	jobServiceAccount string
+	options           KubernetesJobOptions
 }
 
-func NewKubernetesJobExecutor(client *k8s.Client, namespace string, jobTTLSeconds int32, jobServiceAccount string) (*KubernetesJobExecutor, error) {
+func NewKubernetesJobExecutor(client *k8s.Client, namespace string, jobTTLSeconds int32, jobServiceAccount string, options KubernetesJobOptions) (*KubernetesJobExecutor, error) {
 	if client == nil {
 		return nil, errors.New("k8s client is required")
 	}
@@ -32,11 +33,15 @@ func NewKubernetesJobExecutor(client *k8s.Client, namespace string, jobTTLSecond
 	if jobTTLSeconds < 0 {
 		return nil, errors.New("job ttl must be non-negative")
 	}
+	if err := options.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid job options: %w", err)
+	}
 	return &KubernetesJobExecutor{
 		client:            client,
 		namespace:         namespace,
 		jobTTLSeconds:     jobTTLSeconds,
 		jobServiceAccount: strings.TrimSpace(jobServiceAccount),
+		options:           options,
 	}, nil
 }
 
@@ -45,15 +50,27 @@ func (e *KubernetesJobExecutor) Kind() string {
 }
 
 func (e *KubernetesJobExecutor) Submit(ctx context.Context, spec JobSpec) error {
+	job, err := e.buildJob(spec)
+	if err != nil {
+		return err
+	}
+	err = e.client.CreateJob(ctx, job.Metadata.Namespace, job)
+	if err == nil || errors.Is(err, k8s.ErrAlreadyExists) {
+		return nil
+	}
+	return err
+}
+
+func (e *KubernetesJobExecutor) buildJob(spec JobSpec) (k8s.Job, error) {
 	jobName := strings.TrimSpace(spec.K8sJobName)
 	if jobName == "" {
-		return errors.New("k8s job name is required")
+		return k8s.Job{}, errors.New("k8s job name is required")
 	}
 	if strings.TrimSpace(spec.RunID) == "" {
-		return errors.New("run id is required")
+		return k8s.Job{}, errors.New("run id is required")
 	}
 	if strings.TrimSpace(spec.ImageRef) == "" {
-		return errors.New("image ref is required")
+		return k8s.Job{}, errors.New("image ref is required")
 	}
 
 	jobKind := strings.TrimSpace(spec.JobKind)
@@ -118,6 +135,9 @@ func (e *KubernetesJobExecutor) Submit(ctx context.Context, spec JobSpec) error
 	if e.jobServiceAccount != "" {
 		podSpec.ServiceAccountName = e.jobServiceAccount
 	}
+	if err := e.options.applyScheduling(&podSpec, &podSpec.Containers[0], spec.Scheduling, parseIntResource(spec.Resources, "gpus")); err != nil {
+		return k8s.Job{}, err
+	}
 
 	job := k8s.Job{
 		Metadata: k8s.ObjectMeta{
@@ -134,12 +154,7 @@ func (e *KubernetesJobExecutor) Submit(ctx context.Context, spec JobSpec) error
 			TTLSecondsAfterFinished: ttl,
 		},
 	}
-
-	err := e.client.CreateJob(ctx, namespace, job)
-	if err == nil || errors.Is(err, k8s.ErrAlreadyExists) {
-		return nil
-	}
-	return err
+	return job, nil
 }
 
 func (e *KubernetesJobExecutor) Inspect(ctx context.Context, execution Execution) (Observation, error) {
@@ -216,26 +231,17 @@ func applyResourceHints(container *k8s.Container, resources map[string]any) {
 		return
 	}
 
-	gpus := int64(0)
-	if v, ok := resources["gpus"]; ok {
-		switch t := v.(type) {
-		case float64:
-			gpus = int64(t)
-		case int64:
-			gpus = t
-		case int:
-			gpus = int64(t)
-		case string:
-			if parsed, err := strconv.ParseInt(strings.TrimSpace(t), 10, 64); err == nil {
-				gpus = parsed
-			}
-		}
-	}
-	if gpus > 0 {
+	// Extended resources cannot be overcommitted, so the GPU request must
+	// equal the limit.
+	if gpus := parseIntResource(resources, "gpus"); gpus > 0 {
 		if container.Resources.Limits == nil {
 			container.Resources.Limits = map[string]string{}
 		}
-		container.Resources.Limits["nvidia.com/gpu"] = fmt.Sprintf("%d", gpus)
+		if container.Resources.Requests == nil {
+			container.Resources.Requests = map[string]string{}
+		}
+		container.Resources.Limits[gpuResourceName] = strconv.Itoa(gpus)
+		container.Resources.Requests[gpuResourceName] = strconv.Itoa(gpus)
 	}
 
 	if cpu, ok := resources["cpu"].(string); ok && strings.TrimSpace(cpu) != "" {
@@ -0,0 +1,121 @@
+package runtimeexec
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/k8s"
+)
+
+func testKubernetesExecutor(t *testing.T, options KubernetesJobOptions) *KubernetesJobExecutor {
+	t.Helper()
+	if err := options.Validate(); err != nil {
+		t.Fatalf("Validate() err=%v", err)
+	}
+	return &KubernetesJobExecutor{namespace: "training", options: options}
+}
+
+func TestBuildJobAppliesSchedulingOptions(t *testing.T) {
+	exec := testKubernetesExecutor(t, KubernetesJobOptions{
+		NodeSelector:            map[string]string{"pool": "train"},
+		Tolerations:             []k8s.Toleration{{Key: "dedicated", Operator: "Equal", Value: "ml", Effect: "NoSchedule"}},
+		PriorityClassName:       "batch",
+		ImagePullSecrets:        []string{"registry"},
+		AllowedNodeSelectorKeys: []string{"gpu-type"},
+		AllowedTolerationKeys:   []string{"nvidia.com/gpu"},
+		AllowedPriorityClasses:  []string{"urgent"},
+		MaxGPUs:                 4,
+		Volumes: []JobVolume{
+			{Name: "scratch", MountPath: "/scratch", EmptyDir: true, Default: true},
+			{Name: "dataset-cache", MountPath: "/cache", ReadOnly: true, PersistentVolumeClaim: "datasets"},
+		},
+	})
+
+	job, err := exec.buildJob(JobSpec{
+		RunID:      "run-1",
+		ImageRef:   "trainer@sha256:abc",
+		K8sJobName: "animus-run-1",
+		Resources:  map[string]any{"gpus": float64(2)},
+		Scheduling: &JobScheduling{
+			NodeSelector:      map[string]string{"gpu-type": "a100"},
+			Tolerations:       []k8s.Toleration{{Key: "nvidia.com/gpu", Operator: "Exists", Effect: "NoSchedule"}},
+			PriorityClassName: "urgent",
+			Volumes:           []string{"dataset-cache"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("buildJob() err=%v", err)
+	}
+	pod := job.Spec.Template.Spec
+	if pod.NodeSelector["pool"] != "train" || pod.NodeSelector["gpu-type"] != "a100" {
+		t.Fatalf("node selector=%v", pod.NodeSelector)
+	}
+	if len(pod.Tolerations) != 2 || pod.PriorityClassName != "urgent" {
+		t.Fatalf("tolerations=%v priority=%q", pod.Tolerations, pod.PriorityClassName)
+	}
+	if len(pod.ImagePullSecrets) != 1 || pod.ImagePullSecrets[0].Name != "registry" {
+		t.Fatalf("image pull secrets=%v", pod.ImagePullSecrets)
+	}
+	if len(pod.Volumes) != 2 || pod.Volumes[0].Name != "dataset-cache" || pod.Volumes[0].PersistentVolumeClaim.ClaimName != "datasets" {
+		t.Fatalf("volumes=%+v", pod.Volumes)
+	}
+	container := pod.Containers[0]
+	if len(container.VolumeMounts) != 2 || container.VolumeMounts[0].MountPath != "/cache" || !container.VolumeMounts[0].ReadOnly {
+		t.Fatalf("volume mounts=%+v", container.VolumeMounts)
+	}
+	if container.Resources.Requests[gpuResourceName] != "2" || container.Resources.Limits[gpuResourceName] != "2" {
+		t.Fatalf("resources=%+v", container.Resources)
+	}
+	if exec.options.NodeSelector["gpu-type"] != "" {
+		t.Fatalf("run override leaked into executor options")
+	}
+}
+
+func TestBuildJobRejectsOverridesOutsidePolicy(t *testing.T) {
+	exec := testKubernetesExecutor(t, KubernetesJobOptions{
+		AllowedNodeSelectorKeys: []string{"gpu-type"},
+		MaxGPUs:                 1,
+		Volumes:                 []JobVolume{{Name: "cache", MountPath: "/cache", HostPath: "/var/cache/animus"}},
+	})
+	base := JobSpec{RunID: "run-1", ImageRef: "trainer@sha256:abc", K8sJobName: "animus-run-1"}
+
+	cases := map[string]func(*JobSpec){
+		"node selector":  func(s *JobSpec) { s.Scheduling = &JobScheduling{NodeSelector: map[string]string{"zone": "a"}} },
+		"toleration":     func(s *JobSpec) { s.Scheduling = &JobScheduling{Tolerations: []k8s.Toleration{{Key: "dedicated"}}} },
+		"priority class": func(s *JobSpec) { s.Scheduling = &JobScheduling{PriorityClassName: "system-node-critical"} },
+		"pull secret":    func(s *JobSpec) { s.Scheduling = &JobScheduling{ImagePullSecrets: []string{"other"}} },
+		"volume":         func(s *JobSpec) { s.Scheduling = &JobScheduling{Volumes: []string{"missing"}} },
+		"gpus":           func(s *JobSpec) { s.Resources = map[string]any{"gpus": "2"} },
+	}
+	for name, mutate := range cases {
+		spec := base
+		mutate(&spec)
+		if _, err := exec.buildJob(spec); !errors.Is(err, ErrSchedulingOverrideDenied) {
+			t.Fatalf("%s: expected ErrSchedulingOverrideDenied, got %v", name, err)
+		}
+	}
+
+	job, err := exec.buildJob(base)
+	if err != nil {
+		t.Fatalf("buildJob() err=%v", err)
+	}
+	if len(job.Spec.Template.Spec.Volumes) != 0 {
+		t.Fatalf("non-default volume mounted without request: %+v", job.Spec.Template.Spec.Volumes)
+	}
+}
+
+func TestKubernetesJobOptionsValidate(t *testing.T) {
+	invalid := []KubernetesJobOptions{
+		{Volumes: []JobVolume{{Name: "a", MountPath: "relative", EmptyDir: true}}},
+		{Volumes: []JobVolume{{Name: "a", MountPath: "/a"}}},
+		{Volumes: []JobVolume{{Name: "a", MountPath: "/a", EmptyDir: true, HostPath: "/x"}}},
+		{Volumes: []JobVolume{{Name: "a", MountPath: "/a", EmptyDir: true}, {Name: "a", MountPath: "/b", EmptyDir: true}}},
+		{Tolerations: []k8s.Toleration{{Key: "k", Operator: "Exists", Value: "v"}}},
+		{MaxGPUs: -1},
+	}
+	for i, options := range invalid {
+		if err := options.Validate(); err == nil {
+			t.Fatalf("case %d: expected validation error", i)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package runtimeexec
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/env"
+	"github.com/animus-labs/animus-go/closed/internal/platform/k8s"
+)
+
+const gpuResourceName = "nvidia.com/gpu"
+
+var ErrSchedulingOverrideDenied = errors.New("scheduling_override_denied")
+
+// KubernetesJobOptions applies to the pods of every Job the executor
+// submits. The Allowed* lists and MaxGPUs are the policy for per-run
+// overrides in JobSpec.Scheduling: anything outside them is rejected
+// rather than silently dropped.
+type KubernetesJobOptions struct {
+	NodeSelector      map[string]string `json:"node_selector,omitempty"`
+	Tolerations       []k8s.Toleration  `json:"tolerations,omitempty"`
+	PriorityClassName string            `json:"priority_class_name,omitempty"`
+	ImagePullSecrets  []string          `json:"image_pull_secrets,omitempty"`
+	Volumes           []JobVolume       `json:"volumes,omitempty"`
+
+	AllowedNodeSelectorKeys []string `json:"allowed_node_selector_keys,omitempty"`
+	AllowedTolerationKeys   []string `json:"allowed_toleration_keys,omitempty"`
+	AllowedPriorityClasses  []string `json:"allowed_priority_classes,omitempty"`
+	AllowedImagePullSecrets []string `json:"allowed_image_pull_secrets,omitempty"`
+	// MaxGPUs caps Resources["gpus"] per run; 0 means no cap.
+	MaxGPUs int `json:"max_gpus,omitempty"`
+}
+
+// KubernetesJobOptionsFromEnv reads ANIMUS_K8S_JOB_OPTIONS_JSON, a JSON
+// encoding of KubernetesJobOptions; unset means no options.
+func KubernetesJobOptionsFromEnv() (KubernetesJobOptions, error) {
+	var options KubernetesJobOptions
+	raw := strings.TrimSpace(env.String("ANIMUS_K8S_JOB_OPTIONS_JSON", ""))
+	if raw == "" {
+		return options, nil
+	}
+	if err := json.Unmarshal([]byte(raw), &options); err != nil {
+		return KubernetesJobOptions{}, fmt.Errorf("invalid ANIMUS_K8S_JOB_OPTIONS_JSON: %w", err)
+	}
+	return options, options.Validate()
+}
+
+// JobVolume is a volume the trainer container can mount, typically a dataset
+// cache. Default volumes are mounted on every Job; the others only when a run
+// asks for them by name. Exactly one source must be set.
+type JobVolume struct {
+	Name      string `json:"name"`
+	MountPath string `json:"mount_path"`
+	ReadOnly  bool   `json:"read_only,omitempty"`
+	Default   bool   `json:"default,omitempty"`
+
+	PersistentVolumeClaim string `json:"persistent_volume_claim,omitempty"`
+	HostPath              string `json:"host_path,omitempty"`
+	EmptyDirSizeLimit     string `json:"empty_dir_size_limit,omitempty"`
+	EmptyDir              bool   `json:"empty_dir,omitempty"`
+}
+
+// JobScheduling holds per-run overrides of KubernetesJobOptions.
+type JobScheduling struct {
+	NodeSelector      map[string]string `json:"node_selector,omitempty"`
+	Tolerations       []k8s.Toleration  `json:"tolerations,omitempty"`
+	PriorityClassName string            `json:"priority_class_name,omitempty"`
+	ImagePullSecrets  []string          `json:"image_pull_secrets,omitempty"`
+	// Volumes names non-default JobVolumes to mount.
+	Volumes []string `json:"volumes,omitempty"`
+}
+
+func (o KubernetesJobOptions) Validate() error {
+	seen := map[string]struct{}{}
+	for _, volume := range o.Volumes {
+		name := strings.TrimSpace(volume.Name)
+		if name == "" {
+			return errors.New("volume name is required")
+		}
+		if _, ok := seen[name]; ok {
+			return fmt.Errorf("duplicate volume %q", name)
+		}
+		seen[name] = struct{}{}
+		if !path.IsAbs(strings.TrimSpace(volume.MountPath)) {
+			return fmt.Errorf("volume %q mount path must be absolute", name)
+		}
+		sources := 0
+		if strings.TrimSpace(volume.PersistentVolumeClaim) != "" {
+			sources++
+		}
+		if strings.TrimSpace(volume.HostPath) != "" {
+			sources++
+		}
+		if volume.EmptyDir || strings.TrimSpace(volume.EmptyDirSizeLimit) != "" {
+			sources++
+		}
+		if sources != 1 {
+			return fmt.Errorf("volume %q must have exactly one source", name)
+		}
+	}
+	for _, toleration := range o.Tolerations {
+		if err := validateToleration(toleration); err != nil {
+			return err
+		}
+	}
+	if o.MaxGPUs < 0 {
+		return errors.New("max gpus must be non-negative")
+	}
+	return nil
+}
+
+func validateToleration(toleration k8s.Toleration) error {
+	switch toleration.Operator {
+	case "", "Equal":
+	case "Exists":
+		if toleration.Value != "" {
+			return errors.New("toleration with operator Exists must not set a value")
+		}
+	default:
+		return fmt.Errorf("unsupported toleration operator %q", toleration.Operator)
+	}
+	switch toleration.Effect {
+	case "", "NoSchedule", "PreferNoSchedule", "NoExecute":
+	default:
+		return fmt.Errorf("unsupported toleration effect %q", toleration.Effect)
+	}
+	return nil
+}
+
+// applyScheduling fills the scheduling fields of podSpec and mounts the
+// selected volumes on container, enforcing the override policy.
+func (o KubernetesJobOptions) applyScheduling(podSpec *k8s.PodSpec, container *k8s.Container, run *JobScheduling, gpus int) error {
+	if o.MaxGPUs > 0 && gpus > o.MaxGPUs {
+		return fmt.Errorf("%w: %d gpus requested, at most %d allowed", ErrSchedulingOverrideDenied, gpus, o.MaxGPUs)
+	}
+	if run == nil {
+		run = &JobScheduling{}
+	}
+
+	nodeSelector := maps.Clone(o.NodeSelector)
+	for key, value := range run.NodeSelector {
+		key = strings.TrimSpace(key)
+		if !slices.Contains(o.AllowedNodeSelectorKeys, key) {
+			return fmt.Errorf("%w: node selector %q", ErrSchedulingOverrideDenied, key)
+		}
+		if nodeSelector == nil {
+			nodeSelector = map[string]string{}
+		}
+		nodeSelector[key] = strings.TrimSpace(value)
+	}
+	podSpec.NodeSelector = nodeSelector
+
+	tolerations := slices.Clone(o.Tolerations)
+	for _, toleration := range run.Tolerations {
+		if !slices.Contains(o.AllowedTolerationKeys, toleration.Key) {
+			return fmt.Errorf("%w: toleration %q", ErrSchedulingOverrideDenied, toleration.Key)
+		}
+		if err := validateToleration(toleration); err != nil {
+			return fmt.Errorf("%w: %v", ErrSchedulingOverrideDenied, err)
+		}
+		tolerations = append(tolerations, toleration)
+	}
+	podSpec.Tolerations = tolerations
+
+	podSpec.PriorityClassName = strings.TrimSpace(o.PriorityClassName)
+	if class := strings.TrimSpace(run.PriorityClassName); class != "" {
+		if !slices.Contains(o.AllowedPriorityClasses, class) {
+			return fmt.Errorf("%w: priority class %q", ErrSchedulingOverrideDenied, class)
+		}
+		podSpec.PriorityClassName = class
+	}
+
+	secrets := slices.Clone(o.ImagePullSecrets)
+	for _, secret := range run.ImagePullSecrets {
+		secret = strings.TrimSpace(secret)
+		if !slices.Contains(o.AllowedImagePullSecrets, secret) {
+			return fmt.Errorf("%w: image pull secret %q", ErrSchedulingOverrideDenied, secret)
+		}
+		if !slices.Contains(secrets, secret) {
+			secrets = append(secrets, secret)
+		}
+	}
+	podSpec.ImagePullSecrets = nil
+	for _, secret := range secrets {
+		podSpec.ImagePullSecrets = append(podSpec.ImagePullSecrets, k8s.LocalObjectReference{Name: secret})
+	}
+
+	requested := map[string]bool{}
+	for _, name := range run.Volumes {
+		name = strings.TrimSpace(name)
+		idx := slices.IndexFunc(o.Volumes, func(v JobVolume) bool { return v.Name == name })
+		if idx < 0 {
+			return fmt.Errorf("%w: volume %q", ErrSchedulingOverrideDenied, name)
+		}
+		requested[name] = true
+	}
+	volumes := make([]JobVolume, 0, len(o.Volumes))
+	for _, volume := range o.Volumes {
+		if volume.Default || requested[volume.Name] {
+			volumes = append(volumes, volume)
+		}
+	}
+	sort.Slice(volumes, func(i, j int) bool { return volumes[i].Name < volumes[j].Name })
+	for _, volume := range volumes {
+		podSpec.Volumes = append(podSpec.Volumes, volume.podVolume())
+		container.VolumeMounts = append(container.VolumeMounts, k8s.VolumeMount{
+			Name:      volume.Name,
+			MountPath: volume.MountPath,
+			ReadOnly:  volume.ReadOnly,
+		})
+	}
+	return nil
+}
+
+func (v JobVolume) podVolume() k8s.Volume {
+	out := k8s.Volume{Name: v.Name}
+	switch {
+	case strings.TrimSpace(v.PersistentVolumeClaim) != "":
+		out.PersistentVolumeClaim = &k8s.PersistentVolumeClaimVolumeSource{
+			ClaimName: strings.TrimSpace(v.PersistentVolumeClaim),
+			ReadOnly:  v.ReadOnly,
+		}
+	case strings.TrimSpace(v.HostPath) != "":
+		out.HostPath = &k8s.HostPathVolumeSource{Path: strings.TrimSpace(v.HostPath), Type: "DirectoryOrCreate"}
+	default:
+		out.EmptyDir = &k8s.EmptyDirVolumeSource{SizeLimit: strings.TrimSpace(v.EmptyDirSizeLimit)}
+	}
+	return out
+}
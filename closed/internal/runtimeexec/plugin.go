@@ -0,0 +1,154 @@
+package runtimeexec
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Observation.Status values. Plugins must map their backend states onto
+// these so the run syncer treats every executor alike.
+const (
+	StatusPending   = "pending"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// Plugin is the contract for executor backends that assign their own job ids,
+// such as Slurm or AWS Batch. Launch returns the Execution to persist with
+// the run, ExternalID included; Inspect and Cancel get it back unchanged.
+// Launch must be safe to retry for the same RunID as far as the backend
+// allows, and Cancel of a finished job is not an error.
+//
+// Find returns the most recent job Launch started under the job name of
+// spec, or ErrExecutionNotFound, so a caller that lost the Execution can
+// recover it instead of launching the job again.
+type Plugin interface {
+	Executor
+	Launch(ctx context.Context, spec JobSpec) (Execution, error)
+	Find(ctx context.Context, spec JobSpec) (Execution, error)
+	Cancel(ctx context.Context, execution Execution) error
+}
+
+// PluginFactory builds a plugin from its settings, see PluginSettingsFromEnv.
+type PluginFactory func(settings map[string]string) (Plugin, error)
+
+var (
+	pluginsMu sync.RWMutex
+	plugins   = map[string]PluginFactory{}
+)
+
+func init() {
+	RegisterPlugin(SlurmKind, newSlurmPlugin)
+	RegisterPlugin(AWSBatchKind, newAWSBatchPlugin)
+}
+
+// RegisterPlugin makes a plugin available under kind. It panics when kind is
+// registered twice, like database/sql drivers.
+func RegisterPlugin(kind string, factory PluginFactory) {
+	kind = strings.TrimSpace(kind)
+	if kind == "" || factory == nil {
+		panic("runtimeexec: plugin kind and factory are required")
+	}
+	pluginsMu.Lock()
+	defer pluginsMu.Unlock()
+	if _, ok := plugins[kind]; ok {
+		panic("runtimeexec: plugin " + kind + " registered twice")
+	}
+	plugins[kind] = factory
+}
+
+func NewPlugin(kind string, settings map[string]string) (Plugin, error) {
+	pluginsMu.RLock()
+	factory, ok := plugins[strings.TrimSpace(kind)]
+	pluginsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown executor plugin %q", kind)
+	}
+	return factory(settings)
+}
+
+func PluginKinds() []string {
+	pluginsMu.RLock()
+	defer pluginsMu.RUnlock()
+	out := make([]string, 0, len(plugins))
+	for kind := range plugins {
+		out = append(out, kind)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// PluginSettingsFromEnv collects ANIMUS_EXECUTOR_<KIND>_<NAME> variables
+// into settings keyed by lower-case name, e.g. ANIMUS_EXECUTOR_SLURM_URL
+// becomes "url".
+func PluginSettingsFromEnv(kind string) map[string]string {
+	prefix := "ANIMUS_EXECUTOR_" + strings.ToUpper(strings.TrimSpace(kind)) + "_"
+	settings := map[string]string{}
+	for _, kv := range os.Environ() {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok || !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		settings[strings.ToLower(strings.TrimPrefix(key, prefix))] = strings.TrimSpace(value)
+	}
+	return settings
+}
+
+// pluginJobName is the backend job name of spec: letters, digits, '-' and
+// '_' only, at most 128 characters.
+func pluginJobName(spec JobSpec) string {
+	name := strings.TrimSpace(spec.JobName)
+	if name == "" {
+		name = "animus-" + jobKindOf(spec) + "-" + strings.TrimSpace(spec.RunID)
+	}
+	out := make([]rune, 0, len(name))
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			out = append(out, r)
+		default:
+			out = append(out, '-')
+		}
+	}
+	if len(out) > 128 {
+		out = out[:128]
+	}
+	return string(out)
+}
+
+func jobKindOf(spec JobSpec) string {
+	if kind := strings.TrimSpace(spec.JobKind); kind != "" {
+		return kind
+	}
+	return "training"
+}
+
+// jobEnvironment is the trainer environment of spec: the reserved variables
+// followed by spec.Env sorted by key.
+func jobEnvironment(spec JobSpec) [][2]string {
+	out := [][2]string{
+		{"RUN_ID", spec.RunID},
+		{"DATASET_VERSION_ID", spec.DatasetVersionID},
+		{"DATAPILOT_URL", spec.DatapilotURL},
+		{"TOKEN", spec.Token},
+		{"ANIMUS_JOB_KIND", jobKindOf(spec)},
+	}
+	keys := make([]string, 0, len(spec.Env))
+	for k := range spec.Env {
+		key := strings.TrimSpace(k)
+		if key == "" || isReservedJobEnvKey(key) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		out = append(out, [2]string{key, spec.Env[key]})
+	}
+	return out
+}
@@ -0,0 +1,220 @@
+package runtimeexec
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignAWSRequestV4(t *testing.T) {
+	// get-vanilla from the AWS Signature Version 4 test suite.
+	req, _ := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	signAWSRequestV4(req, nil, "service", "us-east-1", awsCredentials{
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+	}, time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Fatalf("Authorization=%q", got)
+	}
+}
+
+func TestPluginRegistry(t *testing.T) {
+	kinds := PluginKinds()
+	if len(kinds) < 2 || kinds[0] != AWSBatchKind || kinds[1] != SlurmKind {
+		t.Fatalf("PluginKinds()=%v", kinds)
+	}
+	if _, err := NewPlugin("nomad", nil); err == nil {
+		t.Fatalf("expected unknown plugin error")
+	}
+	if _, err := NewPlugin(SlurmKind, map[string]string{"url": "slurm:6820"}); err == nil {
+		t.Fatalf("expected invalid url error")
+	}
+
+	t.Setenv("ANIMUS_EXECUTOR_SLURM_URL", "http://slurm:6820")
+	t.Setenv("ANIMUS_EXECUTOR_SLURM_USER", "animus")
+	settings := PluginSettingsFromEnv(SlurmKind)
+	if settings["url"] != "http://slurm:6820" || settings["user"] != "animus" {
+		t.Fatalf("settings=%v", settings)
+	}
+}
+
+func TestSlurmExecutor(t *testing.T) {
+	var submitted struct {
+		Script string         `json:"script"`
+		Job    map[string]any `json:"job"`
+	}
+	state := `["RUNNING"]`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-SLURM-USER-NAME") != "animus" || r.Header.Get("X-SLURM-USER-TOKEN") != "jwt" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/slurm/v0.0.40/job/submit":
+			_ = json.NewDecoder(r.Body).Decode(&submitted)
+			_, _ = io.WriteString(w, `{"job_id":42,"errors":[]}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/slurm/v0.0.40/jobs":
+			_, _ = io.WriteString(w, `{"jobs":[{"job_id":41,"name":"animus-training-run-1","user_name":"animus"},{"job_id":42,"name":"animus-training-run-1","user_name":"animus"},{"job_id":43,"name":"animus-training-run-1","user_name":"other"}]}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/slurm/v0.0.40/job/42":
+			_, _ = io.WriteString(w, `{"jobs":[{"job_state":`+state+`,"state_reason":"None","nodes":"gpu01"}]}`)
+		case r.URL.Path == "/slurm/v0.0.40/job/7":
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = io.WriteString(w, `{"errors":[{"error":"Invalid job id specified"}]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	plugin, err := NewPlugin(SlurmKind, map[string]string{"url": srv.URL, "user": "animus", "token": "jwt", "partition": "gpu"})
+	if err != nil {
+		t.Fatalf("NewPlugin() err=%v", err)
+	}
+	execution, err := plugin.Launch(context.Background(), JobSpec{
+		RunID:     "run-1",
+		ImageRef:  "registry/trainer@sha256:abc",
+		Resources: map[string]any{"gpus": 2, "cpu": "1500m", "memory": "8Gi"},
+		Env:       map[string]string{"EPOCHS": "3", "TOKEN": "spoofed"},
+	})
+	if err != nil {
+		t.Fatalf("Launch() err=%v", err)
+	}
+	if execution.ExternalID != "42" || execution.Executor != SlurmKind {
+		t.Fatalf("execution=%+v", execution)
+	}
+	for _, want := range []string{"#SBATCH --gres=gpu:2", "#SBATCH --cpus-per-task=2", "#SBATCH --mem=8192M", "apptainer run --nv 'docker://registry/trainer@sha256:abc'"} {
+		if !strings.Contains(submitted.Script, want) {
+			t.Fatalf("script missing %q:\n%s", want, submitted.Script)
+		}
+	}
+	if submitted.Job["partition"] != "gpu" || submitted.Job["name"] != "animus-training-run-1" {
+		t.Fatalf("job=%v", submitted.Job)
+	}
+	env, _ := json.Marshal(submitted.Job["environment"])
+	if !strings.Contains(string(env), `"EPOCHS=3"`) || strings.Contains(string(env), "spoofed") {
+		t.Fatalf("environment=%s", env)
+	}
+
+	found, err := plugin.Find(context.Background(), JobSpec{RunID: "run-1"})
+	if err != nil || found != execution {
+		t.Fatalf("Find() execution=%+v err=%v", found, err)
+	}
+	if _, err := plugin.Find(context.Background(), JobSpec{RunID: "run-1", JobName: "animus-run-run-1-step"}); !errors.Is(err, ErrExecutionNotFound) {
+		t.Fatalf("Find(other name) err=%v", err)
+	}
+
+	obs, err := plugin.Inspect(context.Background(), execution)
+	if err != nil || obs.Status != StatusRunning {
+		t.Fatalf("Inspect() obs=%+v err=%v", obs, err)
+	}
+	state = `"COMPLETED"`
+	if obs, _ := plugin.Inspect(context.Background(), execution); obs.Status != StatusSucceeded {
+		t.Fatalf("Inspect() obs=%+v", obs)
+	}
+	state = `["OUT_OF_MEMORY"]`
+	if obs, _ := plugin.Inspect(context.Background(), execution); obs.Status != StatusFailed {
+		t.Fatalf("Inspect() obs=%+v", obs)
+	}
+	if obs, err := plugin.Inspect(context.Background(), Execution{ExternalID: "7"}); err != nil || obs.Message != "job_not_found" {
+		t.Fatalf("Inspect(purged) obs=%+v err=%v", obs, err)
+	}
+	if err := plugin.Cancel(context.Background(), Execution{ExternalID: "7"}); err != nil {
+		t.Fatalf("Cancel(purged) err=%v", err)
+	}
+}
+
+func TestAWSBatchExecutor(t *testing.T) {
+	var (
+		registered int
+		submitted  map[string]any
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") ||
+			!strings.Contains(r.Header.Get("Authorization"), "/eu-west-1/batch/aws4_request") {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = io.WriteString(w, `{"message":"bad signature"}`)
+			return
+		}
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		switch r.URL.Path {
+		case "/v1/describejobdefinitions":
+			if registered == 0 {
+				_, _ = io.WriteString(w, `{"jobDefinitions":[]}`)
+				return
+			}
+			_, _ = io.WriteString(w, `{"jobDefinitions":[{"jobDefinitionArn":"arn:def:1","containerProperties":{"image":"trainer@sha256:abc"}}]}`)
+		case "/v1/registerjobdefinition":
+			registered++
+			_, _ = io.WriteString(w, `{"jobDefinitionArn":"arn:def:1"}`)
+		case "/v1/submitjob":
+			submitted = body
+			_, _ = io.WriteString(w, `{"jobId":"job-1","jobName":"x"}`)
+		case "/v1/listjobs":
+			filters, _ := json.Marshal(body["filters"])
+			if body["jobQueue"] != "training" || !strings.Contains(string(filters), `"values":["animus-training-run-1"]`) {
+				_, _ = io.WriteString(w, `{"jobSummaryList":[]}`)
+				return
+			}
+			_, _ = io.WriteString(w, `{"jobSummaryList":[{"jobId":"job-0","jobName":"animus-training-run-1","createdAt":1},{"jobId":"job-1","jobName":"animus-training-run-1","createdAt":2}]}`)
+		case "/v1/describejobs":
+			_, _ = io.WriteString(w, `{"jobs":[{"status":"FAILED","statusReason":"Essential container in task exited","container":{"exitCode":137}}]}`)
+		case "/v1/terminatejob":
+			_, _ = io.WriteString(w, `{}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	plugin, err := NewPlugin(AWSBatchKind, map[string]string{
+		"region":            "eu-west-1",
+		"job_queue":         "training",
+		"endpoint":          srv.URL,
+		"access_key_id":     "AKID",
+		"secret_access_key": "secret",
+	})
+	if err != nil {
+		t.Fatalf("NewPlugin() err=%v", err)
+	}
+	spec := JobSpec{RunID: "run-1", ImageRef: "trainer@sha256:abc", Resources: map[string]any{"gpus": "1", "memory": "512Mi"}}
+	for range 2 {
+		execution, err := plugin.Launch(context.Background(), spec)
+		if err != nil || execution.ExternalID != "job-1" {
+			t.Fatalf("Launch() execution=%+v err=%v", execution, err)
+		}
+	}
+	if registered != 1 {
+		t.Fatalf("job definition registered %d times", registered)
+	}
+	if submitted["jobQueue"] != "training" || submitted["jobDefinition"] != "arn:def:1" {
+		t.Fatalf("submitted=%v", submitted)
+	}
+	overrides, _ := json.Marshal(submitted["containerOverrides"])
+	if !strings.Contains(string(overrides), `{"type":"GPU","value":"1"}`) || !strings.Contains(string(overrides), `{"type":"MEMORY","value":"512"}`) {
+		t.Fatalf("overrides=%s", overrides)
+	}
+
+	found, err := plugin.Find(context.Background(), spec)
+	if err != nil || found.ExternalID != "job-1" || found.Executor != AWSBatchKind {
+		t.Fatalf("Find() execution=%+v err=%v", found, err)
+	}
+	if _, err := plugin.Find(context.Background(), JobSpec{RunID: "run-2"}); !errors.Is(err, ErrExecutionNotFound) {
+		t.Fatalf("Find(run-2) err=%v", err)
+	}
+
+	obs, err := plugin.Inspect(context.Background(), Execution{ExternalID: "job-1"})
+	if err != nil || obs.Status != StatusFailed || obs.Details["exit_code"] != 137 {
+		t.Fatalf("Inspect() obs=%+v err=%v", obs, err)
+	}
+	if err := plugin.Cancel(context.Background(), Execution{ExternalID: "job-1"}); err != nil {
+		t.Fatalf("Cancel() err=%v", err)
+	}
+}
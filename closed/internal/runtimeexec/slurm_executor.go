@@ -0,0 +1,338 @@
+package runtimeexec
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const SlurmKind = "slurm"
+
+// SlurmExecutor submits batch jobs through slurmrestd. The trainer image runs
+// under Apptainer on the allocated node, so compute nodes need apptainer and
+// registry access; Resources map onto --gres, --cpus-per-task and --mem.
+type SlurmExecutor struct {
+	baseURL    string
+	apiVersion string
+	user       string
+	token      string
+	partition  string
+	account    string
+	workDir    string
+	http       *http.Client
+}
+
+// newSlurmPlugin reads the settings url, user and token (a slurmrestd JWT),
+// and optionally api_version (default v0.0.40), partition, account,
+// working_dir (default /tmp) and timeout.
+func newSlurmPlugin(settings map[string]string) (Plugin, error) {
+	baseURL := strings.TrimRight(strings.TrimSpace(settings["url"]), "/")
+	u, err := url.Parse(baseURL)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, errors.New("slurm url must be an http(s) URL")
+	}
+	user := strings.TrimSpace(settings["user"])
+	if user == "" {
+		return nil, errors.New("slurm user is required")
+	}
+	timeout := 30 * time.Second
+	if raw := strings.TrimSpace(settings["timeout"]); raw != "" {
+		timeout, err = time.ParseDuration(raw)
+		if err != nil || timeout <= 0 {
+			return nil, errors.New("slurm timeout must be a positive duration")
+		}
+	}
+	apiVersion := strings.TrimSpace(settings["api_version"])
+	if apiVersion == "" {
+		apiVersion = "v0.0.40"
+	}
+	workDir := strings.TrimSpace(settings["working_dir"])
+	if workDir == "" {
+		workDir = "/tmp"
+	}
+	return &SlurmExecutor{
+		baseURL:    baseURL,
+		apiVersion: apiVersion,
+		user:       user,
+		token:      strings.TrimSpace(settings["token"]),
+		partition:  strings.TrimSpace(settings["partition"]),
+		account:    strings.TrimSpace(settings["account"]),
+		workDir:    workDir,
+		http:       &http.Client{Timeout: timeout},
+	}, nil
+}
+
+func (e *SlurmExecutor) Kind() string {
+	return SlurmKind
+}
+
+func (e *SlurmExecutor) Submit(ctx context.Context, spec JobSpec) error {
+	_, err := e.Launch(ctx, spec)
+	return err
+}
+
+type slurmError struct {
+	Error       string `json:"error"`
+	Description string `json:"description"`
+}
+
+func slurmErrors(errs []slurmError) error {
+	for _, e := range errs {
+		msg := strings.TrimSpace(e.Description)
+		if msg == "" {
+			msg = strings.TrimSpace(e.Error)
+		}
+		if msg != "" {
+			return fmt.Errorf("slurm: %s", msg)
+		}
+	}
+	return nil
+}
+
+func (e *SlurmExecutor) Launch(ctx context.Context, spec JobSpec) (Execution, error) {
+	if strings.TrimSpace(spec.RunID) == "" {
+		return Execution{}, errors.New("run id is required")
+	}
+	if strings.TrimSpace(spec.ImageRef) == "" {
+		return Execution{}, errors.New("image ref is required")
+	}
+	script, err := slurmScript(spec)
+	if err != nil {
+		return Execution{}, err
+	}
+	environment := []string{}
+	for _, kv := range jobEnvironment(spec) {
+		environment = append(environment, kv[0]+"="+kv[1])
+	}
+	job := map[string]any{
+		"name":                      pluginJobName(spec),
+		"current_working_directory": e.workDir,
+		"environment":               environment,
+	}
+	if e.partition != "" {
+		job["partition"] = e.partition
+	}
+	if e.account != "" {
+		job["account"] = e.account
+	}
+
+	var resp struct {
+		JobID  int64        `json:"job_id"`
+		Errors []slurmError `json:"errors"`
+	}
+	if err := e.do(ctx, http.MethodPost, "/job/submit", map[string]any{"script": script, "job": job}, &resp); err != nil {
+		return Execution{}, err
+	}
+	if err := slurmErrors(resp.Errors); err != nil {
+		return Execution{}, err
+	}
+	if resp.JobID <= 0 {
+		return Execution{}, errors.New("slurm: submit returned no job id")
+	}
+	return Execution{
+		RunID:      spec.RunID,
+		Executor:   SlurmKind,
+		ExternalID: strconv.FormatInt(resp.JobID, 10),
+	}, nil
+}
+
+// Find lists the jobs slurmctld still knows and returns the newest one of
+// this user with the job name of spec. Jobs older than the cluster's
+// MinJobAge are purged from that list and not found.
+func (e *SlurmExecutor) Find(ctx context.Context, spec JobSpec) (Execution, error) {
+	name := pluginJobName(spec)
+	var resp struct {
+		Jobs []struct {
+			JobID    int64  `json:"job_id"`
+			Name     string `json:"name"`
+			UserName string `json:"user_name"`
+		} `json:"jobs"`
+		Errors []slurmError `json:"errors"`
+	}
+	if err := e.do(ctx, http.MethodGet, "/jobs", nil, &resp); err != nil {
+		return Execution{}, err
+	}
+	if err := slurmErrors(resp.Errors); err != nil {
+		return Execution{}, err
+	}
+	var jobID int64
+	for _, job := range resp.Jobs {
+		if job.Name != name || (job.UserName != "" && job.UserName != e.user) {
+			continue
+		}
+		jobID = max(jobID, job.JobID)
+	}
+	if jobID <= 0 {
+		return Execution{}, ErrExecutionNotFound
+	}
+	return Execution{
+		RunID:      spec.RunID,
+		Executor:   SlurmKind,
+		ExternalID: strconv.FormatInt(jobID, 10),
+	}, nil
+}
+
+func (e *SlurmExecutor) Inspect(ctx context.Context, execution Execution) (Observation, error) {
+	jobID := strings.TrimSpace(execution.ExternalID)
+	if jobID == "" {
+		return Observation{}, errors.New("slurm job id is required")
+	}
+	var resp struct {
+		Jobs []struct {
+			JobState    json.RawMessage `json:"job_state"`
+			StateReason string          `json:"state_reason"`
+			NodeList    string          `json:"nodes"`
+		} `json:"jobs"`
+		Errors []slurmError `json:"errors"`
+	}
+	err := e.do(ctx, http.MethodGet, "/job/"+url.PathEscape(jobID), nil, &resp)
+	if err != nil && !errors.Is(err, errSlurmNotFound) {
+		return Observation{}, err
+	}
+	if errors.Is(err, errSlurmNotFound) || len(resp.Jobs) == 0 {
+		return Observation{Status: StatusPending, Message: "job_not_found"}, nil
+	}
+	if err := slurmErrors(resp.Errors); err != nil {
+		return Observation{}, err
+	}
+
+	job := resp.Jobs[0]
+	state := slurmJobState(job.JobState)
+	status := StatusPending
+	switch state {
+	case "RUNNING", "COMPLETING", "STAGE_OUT":
+		status = StatusRunning
+	case "COMPLETED":
+		status = StatusSucceeded
+	case "FAILED", "CANCELLED", "TIMEOUT", "NODE_FAIL", "OUT_OF_MEMORY", "PREEMPTED", "BOOT_FAIL", "DEADLINE":
+		status = StatusFailed
+	}
+	message := strings.TrimSpace(job.StateReason)
+	if message == "" || message == "None" {
+		message = strings.ToLower(state)
+	}
+	return Observation{
+		Status:  status,
+		Message: message,
+		Details: map[string]any{
+			"slurm_job_id":    jobID,
+			"slurm_job_state": state,
+			"slurm_nodes":     job.NodeList,
+		},
+	}, nil
+}
+
+func (e *SlurmExecutor) Cancel(ctx context.Context, execution Execution) error {
+	jobID := strings.TrimSpace(execution.ExternalID)
+	if jobID == "" {
+		return errors.New("slurm job id is required")
+	}
+	var resp struct {
+		Errors []slurmError `json:"errors"`
+	}
+	err := e.do(ctx, http.MethodDelete, "/job/"+url.PathEscape(jobID), nil, &resp)
+	if errors.Is(err, errSlurmNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return slurmErrors(resp.Errors)
+}
+
+var errSlurmNotFound = errors.New("slurm job not found")
+
+func (e *SlurmExecutor) do(ctx context.Context, method, path string, body any, out any) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, e.baseURL+"/slurm/"+e.apiVersion+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("X-SLURM-USER-NAME", e.user)
+	if e.token != "" {
+		req.Header.Set("X-SLURM-USER-TOKEN", e.token)
+	}
+	resp, err := e.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode == http.StatusNotFound || (resp.StatusCode >= 400 && strings.Contains(string(respBody), "Invalid job id")) {
+		return errSlurmNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("slurmrestd status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	if out == nil || len(respBody) == 0 {
+		return nil
+	}
+	return json.Unmarshal(respBody, out)
+}
+
+// slurmJobState reads job_state, a string before API v0.0.40 and a list of
+// flags with the base state first since.
+func slurmJobState(raw json.RawMessage) string {
+	var single string
+	if err := json.Unmarshal(raw, &single); err == nil {
+		return strings.ToUpper(strings.TrimSpace(single))
+	}
+	var list []string
+	if err := json.Unmarshal(raw, &list); err == nil && len(list) > 0 {
+		return strings.ToUpper(strings.TrimSpace(list[0]))
+	}
+	return ""
+}
+
+func slurmScript(spec JobSpec) (string, error) {
+	var b strings.Builder
+	b.WriteString("#!/bin/bash\n")
+	gpus := parseIntResource(spec.Resources, "gpus")
+	if gpus > 0 {
+		fmt.Fprintf(&b, "#SBATCH --gres=gpu:%d\n", gpus)
+	}
+	if cpu, ok := spec.Resources["cpu"].(string); ok && strings.TrimSpace(cpu) != "" {
+		cpus, err := wholeCPUs(cpu)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "#SBATCH --cpus-per-task=%d\n", cpus)
+	}
+	if memory, ok := spec.Resources["memory"].(string); ok && strings.TrimSpace(memory) != "" {
+		mib, err := memoryMiB(memory)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "#SBATCH --mem=%dM\n", mib)
+	}
+	b.WriteString("exec apptainer run ")
+	if gpus > 0 {
+		b.WriteString("--nv ")
+	}
+	b.WriteString(shellQuote("docker://" + strings.TrimSpace(spec.ImageRef)))
+	b.WriteString("\n")
+	return b.String(), nil
+}
+
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
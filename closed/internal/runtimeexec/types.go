@@ -29,6 +29,29 @@ type JobSpec struct {
 	DockerName       string
 	JobKind          string
 	Env              map[string]string
+	// Command and Args replace the image entrypoint and command; only the
+	// Docker executor honours them.
+	Command []string
+	Args    []string
+	// Labels are extra Docker container labels; they cannot replace the
+	// animus.* labels the executor sets itself.
+	Labels map[string]string
+	// Scheduling overrides the Kubernetes executor's pod options for this
+	// run, within the limits of KubernetesJobOptions.
+	Scheduling *JobScheduling
+	// Mounts are host directories bind-mounted into the container; only
+	// the Docker executor honours them.
+	Mounts []Mount
+	// JobName names the job on plugin backends, so Plugin.Find can look it
+	// up again; empty derives it from JobKind and RunID.
+	JobName string
+}
+
+// Mount bind-mounts the absolute host path Source at Target.
+type Mount struct {
+	Source   string
+	Target   string
+	ReadOnly bool
 }
 
 type Execution struct {
@@ -37,6 +60,8 @@ type Execution struct {
 	K8sNamespace    string
 	K8sJobName      string
 	DockerContainer string
+	// ExternalID is the job id a Plugin backend assigned at Launch.
+	ExternalID string
 }
 
 type Observation struct {
@@ -46,4 +71,8 @@ type Observation struct {
 }
 
 var ErrImageRefNotFound = errors.New("image_ref_not_found")
+
+// ErrExecutionNotFound is returned by Plugin.Find when the backend has no
+// job for the spec.
+var ErrExecutionNotFound = errors.New("execution_not_found")
 var ErrImageRefDigestRequired = errors.New("image_ref_digest_required")
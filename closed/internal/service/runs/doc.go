@@ -2,6 +2,7 @@
 //
 // States:
 //   - created -> planned -> dryrun_running -> dryrun_succeeded | dryrun_failed
+//   - dryrun_succeeded | dryrun_failed -> dryrun_running when a single step is re-run
 //
 // Transitions are derived from the stored ExecutionPlan and step_executions via
 // DeriveAndPersistWithAudit. Read-only callers should use Derive, which does not
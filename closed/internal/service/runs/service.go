@@ -55,7 +55,7 @@ func NewAuditAppender(q auditlog.QueryRower) AuditAppender {
 		return nil
 	}
 	return auditAppenderFunc(func(ctx context.Context, event auditlog.Event) error {
-		_, err := auditlog.Insert(ctx, q, event)
+		err := auditlog.Enqueue(ctx, q, event)
 		return err
 	})
 }
@@ -1,4 +1,4 @@
-package main
+package lineage
 
 import (
 	"context"
@@ -11,20 +11,26 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	"github.com/animus-labs/animus-go/closed/internal/platform/postgres"
 )
 
 type lineageAPI struct {
-	logger       *slog.Logger
-	db           *sql.DB
-	edgesForNode func(ctx context.Context, node lineageNode, limit int) ([]lineageEvent, error)
+	logger *slog.Logger
+	// db serves every lineage read; it is the read replica when configured.
+	db postgres.Querier
+	// writes is the primary, used by event ingestion; nil disables it.
+	writes        *sql.DB
+	edgesForNodes func(ctx context.Context, nodes []lineageNode, asOf time.Time, perNode, limit int) ([]lineageEvent, error)
 }
 
-func newLineageAPI(logger *slog.Logger, db *sql.DB) *lineageAPI {
+func newLineageAPI(logger *slog.Logger, db postgres.Querier) *lineageAPI {
 	api := &lineageAPI{
 		logger: logger,
 		db:     db,
 	}
-	api.edgesForNode = api.queryEdgesForNode
+	api.edgesForNodes = api.queryEdgesForNodes
 	return api
 }
 
@@ -37,6 +43,9 @@ func (api *lineageAPI) register(mux *http.ServeMux) {
 	mux.HandleFunc("GET /subgraphs/git-commits/{commit}", api.handleCommitSubgraph)
 	mux.HandleFunc("GET /runs/{run_id}", api.handleRunSubgraph)
 	mux.HandleFunc("GET /model-versions/{model_version_id}", api.handleModelVersionSubgraph)
+
+	api.registerColumns(mux)
+	api.registerIngest(mux)
 }
 
 type lineageEvent struct {
@@ -57,9 +66,22 @@ type lineageNode struct {
 	ID   string `json:"id"`
 }
 
+// lineageEventCursor is the page position behind the cursor of GET /events.
+type lineageEventCursor struct {
+	BeforeEventID int64 `json:"before_event_id"`
+}
+
 func (api *lineageAPI) handleListEvents(w http.ResponseWriter, r *http.Request) {
-	limit := clampInt(parseIntQuery(r, "limit", 100), 1, 500)
-	beforeID := parseInt64Query(r, "before_event_id", 0)
+	limit := httpapi.Limit(r, 100, 500)
+	beforeID := httpapi.ParseInt64Query(r, "before_event_id", 0)
+	if raw := r.URL.Query().Get("cursor"); raw != "" {
+		var pos lineageEventCursor
+		if httpapi.DecodeCursor(raw, &pos) != nil || pos.BeforeEventID <= 0 {
+			api.writeError(w, r, http.StatusBadRequest, "invalid_cursor")
+			return
+		}
+		beforeID = pos.BeforeEventID
+	}
 
 	subjectType := strings.TrimSpace(r.URL.Query().Get("subject_type"))
 	subjectID := strings.TrimSpace(r.URL.Query().Get("subject_id"))
@@ -122,7 +144,7 @@ func (api *lineageAPI) handleListEvents(w http.ResponseWriter, r *http.Request)
 			return
 		}
 		ev.RequestID = strings.TrimSpace(requestID.String)
-		ev.Metadata = normalizeJSON(metadataRaw)
+		ev.Metadata = httpapi.NormalizeJSON(metadataRaw)
 		events = append(events, ev)
 	}
 	if err := rows.Err(); err != nil {
@@ -133,6 +155,7 @@ func (api *lineageAPI) handleListEvents(w http.ResponseWriter, r *http.Request)
 	resp := map[string]any{"events": events}
 	if len(events) > 0 {
 		resp["next_before_event_id"] = events[len(events)-1].EventID
+		resp["next_cursor"] = httpapi.EncodeCursor(lineageEventCursor{BeforeEventID: events[len(events)-1].EventID})
 	}
 	api.writeJSON(w, http.StatusOK, resp)
 }
@@ -182,19 +205,45 @@ func (api *lineageAPI) handleCommitSubgraph(w http.ResponseWriter, r *http.Reque
 	api.handleSubgraph(w, r, lineageNode{Type: "git_commit", ID: commit})
 }
 
-func (api *lineageAPI) queryEdgesForNode(ctx context.Context, node lineageNode, limit int) ([]lineageEvent, error) {
+// queryEdgesForNodes returns, for each node in order, its newest edges up to
+// perNode, and at most limit edges overall; a non-zero asOf drops edges that
+// occurred after it. One query serves a whole traversal level through
+// lineage_adjacency. An edge between two of the nodes is returned for each.
+func (api *lineageAPI) queryEdgesForNodes(ctx context.Context, nodes []lineageNode, asOf time.Time, perNode, limit int) ([]lineageEvent, error) {
 	if api == nil || api.db == nil {
 		return nil, errors.New("lineage store unavailable")
 	}
+	type frontierNode struct {
+		Ord  int    `json:"ord"`
+		Type string `json:"type"`
+		ID   string `json:"id"`
+	}
+	frontier := make([]frontierNode, 0, len(nodes))
+	for i, node := range nodes {
+		frontier = append(frontier, frontierNode{Ord: i, Type: strings.TrimSpace(node.Type), ID: strings.TrimSpace(node.ID)})
+	}
+	frontierJSON, err := json.Marshal(frontier)
+	if err != nil {
+		return nil, err
+	}
 	rows, err := api.db.QueryContext(
 		ctx,
-		`SELECT event_id, occurred_at, actor, request_id, subject_type, subject_id, predicate, object_type, object_id, metadata
-			 FROM lineage_events
-			 WHERE (subject_type = $1 AND subject_id = $2) OR (object_type = $1 AND object_id = $2)
-			 ORDER BY event_id DESC
-			 LIMIT $3`,
-		strings.TrimSpace(node.Type),
-		strings.TrimSpace(node.ID),
+		`SELECT e.event_id, e.occurred_at, e.actor, e.request_id, e.subject_type, e.subject_id, e.predicate, e.object_type, e.object_id, e.metadata
+			 FROM jsonb_to_recordset($1::jsonb) AS f(ord INT, type TEXT, id TEXT)
+			 CROSS JOIN LATERAL (
+			   SELECT a.event_id
+			   FROM lineage_adjacency a
+			   WHERE a.node_type = f.type AND a.node_id = f.id
+			     AND ($2::timestamptz IS NULL OR a.occurred_at <= $2)
+			   ORDER BY a.event_id DESC
+			   LIMIT $3
+			 ) a
+			 JOIN lineage_events e ON e.event_id = a.event_id
+			 ORDER BY f.ord, e.event_id DESC
+			 LIMIT $4`,
+		frontierJSON,
+		asOfArg(asOf),
+		perNode,
 		limit,
 	)
 	if err != nil {
@@ -202,7 +251,7 @@ func (api *lineageAPI) queryEdgesForNode(ctx context.Context, node lineageNode,
 	}
 	defer rows.Close()
 
-	events := make([]lineageEvent, 0, limit)
+	events := make([]lineageEvent, 0, 64)
 	for rows.Next() {
 		var (
 			ev          lineageEvent
@@ -213,7 +262,7 @@ func (api *lineageAPI) queryEdgesForNode(ctx context.Context, node lineageNode,
 			return nil, err
 		}
 		ev.RequestID = strings.TrimSpace(requestID.String)
-		ev.Metadata = normalizeJSON(metadataRaw)
+		ev.Metadata = httpapi.NormalizeJSON(metadataRaw)
 		events = append(events, ev)
 	}
 	if err := rows.Err(); err != nil {
@@ -223,10 +272,15 @@ func (api *lineageAPI) queryEdgesForNode(ctx context.Context, node lineageNode,
 }
 
 func (api *lineageAPI) handleSubgraph(w http.ResponseWriter, r *http.Request, root lineageNode) {
-	depth := clampInt(parseIntQuery(r, "depth", 3), 1, 5)
-	maxEdges := clampInt(parseIntQuery(r, "max_edges", 2000), 1, 5000)
+	depth := httpapi.ClampInt(httpapi.ParseIntQuery(r, "depth", 3), 1, 5)
+	maxEdges := httpapi.ClampInt(httpapi.ParseIntQuery(r, "max_edges", 2000), 1, 5000)
+	asOf, ok := parseAsOf(r)
+	if !ok {
+		api.writeError(w, r, http.StatusBadRequest, "as_of_invalid")
+		return
+	}
 
-	graph, err := api.buildSubgraph(r.Context(), root, depth, maxEdges)
+	graph, err := api.buildSubgraph(r.Context(), root, asOf, depth, maxEdges)
 	if err != nil {
 		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
 		return
@@ -236,75 +290,85 @@ func (api *lineageAPI) handleSubgraph(w http.ResponseWriter, r *http.Request, ro
 
 type subgraphResponse struct {
 	Root  lineageNode    `json:"root"`
+	AsOf  *time.Time     `json:"as_of,omitempty"`
 	Nodes []lineageNode  `json:"nodes"`
 	Edges []lineageEvent `json:"edges"`
 }
 
+// parseAsOf reads the optional as_of query parameter, an RFC 3339 instant
+// the graph is reconstructed at; absent, it is the zero time (today's view).
+func parseAsOf(r *http.Request) (time.Time, bool) {
+	raw := strings.TrimSpace(r.URL.Query().Get("as_of"))
+	if raw == "" {
+		return time.Time{}, true
+	}
+	asOf, err := time.Parse(time.RFC3339Nano, raw)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return asOf.UTC(), true
+}
+
+// asOfArg binds asOf as a nullable timestamptz parameter.
+func asOfArg(asOf time.Time) any {
+	if asOf.IsZero() {
+		return nil
+	}
+	return asOf
+}
+
 type nodeKey struct {
 	Type string
 	ID   string
 }
 
-func (api *lineageAPI) buildSubgraph(ctx context.Context, root lineageNode, depth int, maxEdges int) (subgraphResponse, error) {
+func (api *lineageAPI) buildSubgraph(ctx context.Context, root lineageNode, asOf time.Time, depth int, maxEdges int) (subgraphResponse, error) {
 	rootKey := nodeKey{Type: strings.TrimSpace(root.Type), ID: strings.TrimSpace(root.ID)}
 	if rootKey.Type == "" || rootKey.ID == "" {
 		return subgraphResponse{}, errors.New("root is required")
 	}
 
-	type queueItem struct {
-		Node  nodeKey
-		Depth int
-	}
-
 	nodes := map[nodeKey]struct{}{rootKey: {}}
-	visited := map[nodeKey]struct{}{rootKey: {}}
 	edgesByID := make(map[int64]struct{})
 	edges := make([]lineageEvent, 0, 64)
 
-	queue := []queueItem{{Node: rootKey, Depth: 0}}
-	for len(queue) > 0 && len(edges) < maxEdges {
-		item := queue[0]
-		queue = queue[1:]
-
-		if item.Depth >= depth {
-			continue
-		}
-
+	// Breadth-first, one query per level: every node first reached at a
+	// level is expanded together at the next.
+	frontier := []lineageNode{{Type: rootKey.Type, ID: rootKey.ID}}
+	for level := 0; level < depth && len(frontier) > 0 && len(edges) < maxEdges; level++ {
 		remaining := maxEdges - len(edges)
 		perNodeLimit := remaining
 		if perNodeLimit > 500 {
 			perNodeLimit = 500
 		}
 
-		if api.edgesForNode == nil {
+		if api.edgesForNodes == nil {
 			return subgraphResponse{}, errors.New("lineage edges provider not initialized")
 		}
-		nodeEdges, err := api.edgesForNode(ctx, lineageNode{Type: item.Node.Type, ID: item.Node.ID}, perNodeLimit)
+		// Edges already collected come back for nodes on both ends of
+		// them; over-fetch by those so the new ones still fill remaining.
+		levelEdges, err := api.edgesForNodes(ctx, frontier, asOf, perNodeLimit, remaining+len(edges))
 		if err != nil {
 			return subgraphResponse{}, err
 		}
-		for _, ev := range nodeEdges {
+		next := make([]lineageNode, 0, len(levelEdges))
+		for _, ev := range levelEdges {
 			if _, ok := edgesByID[ev.EventID]; ok {
 				continue
 			}
 			edgesByID[ev.EventID] = struct{}{}
 			edges = append(edges, ev)
 
-			subj := nodeKey{Type: strings.TrimSpace(ev.SubjectType), ID: strings.TrimSpace(ev.SubjectID)}
-			obj := nodeKey{Type: strings.TrimSpace(ev.ObjectType), ID: strings.TrimSpace(ev.ObjectID)}
-
-			if subj.Type != "" && subj.ID != "" {
-				nodes[subj] = struct{}{}
-				if _, ok := visited[subj]; !ok {
-					visited[subj] = struct{}{}
-					queue = append(queue, queueItem{Node: subj, Depth: item.Depth + 1})
+			for _, end := range []nodeKey{
+				{Type: strings.TrimSpace(ev.SubjectType), ID: strings.TrimSpace(ev.SubjectID)},
+				{Type: strings.TrimSpace(ev.ObjectType), ID: strings.TrimSpace(ev.ObjectID)},
+			} {
+				if end.Type == "" || end.ID == "" {
+					continue
 				}
-			}
-			if obj.Type != "" && obj.ID != "" {
-				nodes[obj] = struct{}{}
-				if _, ok := visited[obj]; !ok {
-					visited[obj] = struct{}{}
-					queue = append(queue, queueItem{Node: obj, Depth: item.Depth + 1})
+				if _, ok := nodes[end]; !ok {
+					nodes[end] = struct{}{}
+					next = append(next, lineageNode{Type: end.Type, ID: end.ID})
 				}
 			}
 
@@ -312,6 +376,7 @@ func (api *lineageAPI) buildSubgraph(ctx context.Context, root lineageNode, dept
 				break
 			}
 		}
+		frontier = next
 	}
 
 	nodeList := make([]lineageNode, 0, len(nodes))
@@ -326,70 +391,21 @@ func (api *lineageAPI) buildSubgraph(ctx context.Context, root lineageNode, dept
 	})
 	sort.Slice(edges, func(i, j int) bool { return edges[i].EventID > edges[j].EventID })
 
-	return subgraphResponse{
+	out := subgraphResponse{
 		Root:  root,
 		Nodes: nodeList,
 		Edges: edges,
-	}, nil
-}
-
-func (api *lineageAPI) writeJSON(w http.ResponseWriter, status int, body any) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	enc := json.NewEncoder(w)
-	enc.SetEscapeHTML(true)
-	_ = enc.Encode(body)
-}
-
-func (api *lineageAPI) writeError(w http.ResponseWriter, r *http.Request, status int, code string) {
-	api.writeJSON(w, status, map[string]any{
-		"error":      code,
-		"request_id": r.Header.Get("X-Request-Id"),
-	})
-}
-
-func normalizeJSON(raw []byte) json.RawMessage {
-	raw = bytesTrimSpace(raw)
-	if len(raw) == 0 || string(raw) == "null" {
-		return []byte("{}")
 	}
-	return raw
-}
-
-func bytesTrimSpace(in []byte) []byte {
-	return []byte(strings.TrimSpace(string(in)))
-}
-
-func parseIntQuery(r *http.Request, key string, def int) int {
-	v := strings.TrimSpace(r.URL.Query().Get(key))
-	if v == "" {
-		return def
+	if !asOf.IsZero() {
+		out.AsOf = &asOf
 	}
-	parsed, err := strconv.Atoi(v)
-	if err != nil {
-		return def
-	}
-	return parsed
+	return out, nil
 }
 
-func parseInt64Query(r *http.Request, key string, def int64) int64 {
-	v := strings.TrimSpace(r.URL.Query().Get(key))
-	if v == "" {
-		return def
-	}
-	parsed, err := strconv.ParseInt(v, 10, 64)
-	if err != nil {
-		return def
-	}
-	return parsed
+func (api *lineageAPI) writeJSON(w http.ResponseWriter, status int, body any) {
+	httpapi.WriteJSON(w, status, body)
 }
 
-func clampInt(v int, min int, max int) int {
-	if v < min {
-		return min
-	}
-	if v > max {
-		return max
-	}
-	return v
+func (api *lineageAPI) writeError(w http.ResponseWriter, r *http.Request, status int, code string) {
+	httpapi.WriteError(w, r, status, code)
 }
@@ -1,4 +1,4 @@
-package main
+package lineage
 
 import (
 	"context"
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"sort"
+	"strconv"
 	"testing"
 	"time"
 )
@@ -36,22 +37,10 @@ func TestBuildSubgraphRunToModelArtifact(t *testing.T) {
 	}
 
 	api := &lineageAPI{
-		edgesForNode: func(ctx context.Context, node lineageNode, limit int) ([]lineageEvent, error) {
-			out := make([]lineageEvent, 0, len(events))
-			for _, ev := range events {
-				if (ev.SubjectType == node.Type && ev.SubjectID == node.ID) || (ev.ObjectType == node.Type && ev.ObjectID == node.ID) {
-					out = append(out, ev)
-				}
-			}
-			sort.Slice(out, func(i, j int) bool { return out[i].EventID > out[j].EventID })
-			if len(out) > limit {
-				out = out[:limit]
-			}
-			return out, nil
-		},
+		edgesForNodes: stubEdgesForNodes(events),
 	}
 
-	graph, err := api.buildSubgraph(context.Background(), lineageNode{Type: "experiment_run", ID: "run-1"}, 3, 10)
+	graph, err := api.buildSubgraph(context.Background(), lineageNode{Type: "experiment_run", ID: "run-1"}, time.Time{}, 3, 10)
 	if err != nil {
 		t.Fatalf("buildSubgraph: %v", err)
 	}
@@ -70,6 +59,45 @@ func TestBuildSubgraphRunToModelArtifact(t *testing.T) {
 	}
 }
 
+func TestBuildSubgraphQueriesOncePerLevel(t *testing.T) {
+	now := time.Now().UTC()
+	// A chain ds -> dv-0 -> ... -> dv-5 plus a fan-out from dv-0.
+	events := []lineageEvent{{EventID: 1, OccurredAt: now, SubjectType: "dataset", SubjectID: "ds", Predicate: "has_version", ObjectType: "dataset_version", ObjectID: "dv-0"}}
+	for i := 0; i < 5; i++ {
+		events = append(events, lineageEvent{EventID: int64(len(events) + 1), OccurredAt: now, SubjectType: "dataset_version", SubjectID: "dv-" + strconv.Itoa(i), Predicate: "derived_into", ObjectType: "dataset_version", ObjectID: "dv-" + strconv.Itoa(i+1)})
+	}
+	for i := 0; i < 3; i++ {
+		events = append(events, lineageEvent{EventID: int64(len(events) + 1), OccurredAt: now, SubjectType: "dataset_version", SubjectID: "dv-0", Predicate: "used_by", ObjectType: "experiment_run", ObjectID: "run-" + strconv.Itoa(i)})
+	}
+
+	calls := 0
+	stub := stubEdgesForNodes(events)
+	api := &lineageAPI{edgesForNodes: func(ctx context.Context, nodes []lineageNode, asOf time.Time, perNode, limit int) ([]lineageEvent, error) {
+		calls++
+		return stub(ctx, nodes, asOf, perNode, limit)
+	}}
+
+	graph, err := api.buildSubgraph(context.Background(), lineageNode{Type: "dataset", ID: "ds"}, time.Time{}, 4, 100)
+	if err != nil {
+		t.Fatalf("buildSubgraph: %v", err)
+	}
+	if calls != 4 {
+		t.Fatalf("queries=%d, want one per level", calls)
+	}
+	// Four hops reach dv-3 and the runs; dv-3 -> dv-4 would be the fifth.
+	if len(graph.Edges) != 7 || !containsNode(graph.Nodes, lineageNode{Type: "dataset_version", ID: "dv-3"}) || containsNode(graph.Nodes, lineageNode{Type: "dataset_version", ID: "dv-4"}) {
+		t.Fatalf("edges=%d nodes=%v", len(graph.Edges), graph.Nodes)
+	}
+
+	graph, err = api.buildSubgraph(context.Background(), lineageNode{Type: "dataset", ID: "ds"}, time.Time{}, 4, 3)
+	if err != nil {
+		t.Fatalf("buildSubgraph: %v", err)
+	}
+	if len(graph.Edges) != 3 {
+		t.Fatalf("edges=%d, want max_edges=3", len(graph.Edges))
+	}
+}
+
 func TestHandleRunSubgraph(t *testing.T) {
 	api := lineageAPIWithStubEdges()
 	w := httptest.NewRecorder()
@@ -110,6 +138,35 @@ func TestHandleModelVersionSubgraph(t *testing.T) {
 	}
 }
 
+func TestHandleSubgraphAsOf(t *testing.T) {
+	api := lineageAPIWithStubEdges()
+	asOf := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/model-versions/mv-1?as_of="+asOf.Format(time.RFC3339), nil)
+	req.SetPathValue("model_version_id", "mv-1")
+
+	api.handleModelVersionSubgraph(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status=%d want 200", w.Code)
+	}
+	var resp subgraphResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.AsOf == nil || !resp.AsOf.Equal(asOf) || len(resp.Edges) != 0 {
+		t.Fatalf("as_of=%v edges=%d, want %v and none", resp.AsOf, len(resp.Edges), asOf)
+	}
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/model-versions/mv-1?as_of=yesterday", nil)
+	req.SetPathValue("model_version_id", "mv-1")
+	api.handleModelVersionSubgraph(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("invalid as_of status=%d want 400", w.Code)
+	}
+}
+
 func lineageAPIWithStubEdges() *lineageAPI {
 	events := []lineageEvent{
 		{
@@ -134,19 +191,7 @@ func lineageAPIWithStubEdges() *lineageAPI {
 		},
 	}
 	return &lineageAPI{
-		edgesForNode: func(ctx context.Context, node lineageNode, limit int) ([]lineageEvent, error) {
-			out := make([]lineageEvent, 0, len(events))
-			for _, ev := range events {
-				if (ev.SubjectType == node.Type && ev.SubjectID == node.ID) || (ev.ObjectType == node.Type && ev.ObjectID == node.ID) {
-					out = append(out, ev)
-				}
-			}
-			sort.Slice(out, func(i, j int) bool { return out[i].EventID > out[j].EventID })
-			if len(out) > limit {
-				out = out[:limit]
-			}
-			return out, nil
-		},
+		edgesForNodes: stubEdgesForNodes(events),
 	}
 }
 
@@ -158,3 +203,31 @@ func containsNode(nodes []lineageNode, needle lineageNode) bool {
 	}
 	return false
 }
+
+// stubEdgesForNodes serves events the way queryEdgesForNodes reads them
+// from lineage_adjacency.
+func stubEdgesForNodes(events []lineageEvent) func(context.Context, []lineageNode, time.Time, int, int) ([]lineageEvent, error) {
+	return func(ctx context.Context, nodes []lineageNode, asOf time.Time, perNode, limit int) ([]lineageEvent, error) {
+		out := make([]lineageEvent, 0, len(events))
+		for _, node := range nodes {
+			matched := make([]lineageEvent, 0, len(events))
+			for _, ev := range events {
+				if !asOf.IsZero() && ev.OccurredAt.After(asOf) {
+					continue
+				}
+				if (ev.SubjectType == node.Type && ev.SubjectID == node.ID) || (ev.ObjectType == node.Type && ev.ObjectID == node.ID) {
+					matched = append(matched, ev)
+				}
+			}
+			sort.Slice(matched, func(i, j int) bool { return matched[i].EventID > matched[j].EventID })
+			if len(matched) > perNode {
+				matched = matched[:perNode]
+			}
+			out = append(out, matched...)
+		}
+		if len(out) > limit {
+			out = out[:limit]
+		}
+		return out, nil
+	}
+}
@@ -0,0 +1,198 @@
+package lineage
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	"github.com/animus-labs/animus-go/closed/internal/platform/lineageevent"
+)
+
+func (api *lineageAPI) registerColumns(mux *http.ServeMux) {
+	mux.HandleFunc("GET /subgraphs/dataset-columns/{version_id}/{column}", api.handleDatasetColumnSubgraph)
+	mux.HandleFunc("GET /impact/datasets/{dataset_id}/columns/{column}", api.handleColumnImpact)
+}
+
+func (api *lineageAPI) handleDatasetColumnSubgraph(w http.ResponseWriter, r *http.Request) {
+	versionID := strings.TrimSpace(r.PathValue("version_id"))
+	column := r.PathValue("column")
+	if versionID == "" || column == "" {
+		api.writeError(w, r, http.StatusBadRequest, "column_required")
+		return
+	}
+	api.handleSubgraph(w, r, lineageNode{Type: lineageevent.NodeDatasetColumn, ID: lineageevent.ColumnNodeID(versionID, column)})
+}
+
+// columnImpact lists what consumed a column across every version of a
+// dataset: the runs that declared reading it and the columns or features
+// derived from it.
+type columnImpact struct {
+	DatasetID         string             `json:"dataset_id"`
+	Column            string             `json:"column"`
+	AsOf              *time.Time         `json:"as_of,omitempty"`
+	DatasetVersionIDs []string           `json:"dataset_version_ids"`
+	Runs              []columnImpactRun  `json:"runs"`
+	Derived           []columnImpactNode `json:"derived"`
+	Truncated         bool               `json:"truncated"`
+}
+
+type columnImpactRun struct {
+	RunID            string    `json:"run_id"`
+	DatasetVersionID string    `json:"dataset_version_id"`
+	DeclaredAt       time.Time `json:"declared_at"`
+}
+
+type columnImpactNode struct {
+	lineageNode
+	DatasetVersionID string `json:"dataset_version_id"`
+	RunID            string `json:"run_id,omitempty"`
+}
+
+type columnImpactEdge struct {
+	DatasetVersionID string
+	Predicate        string
+	ObjectType       string
+	ObjectID         string
+	RunID            string
+	OccurredAt       time.Time
+}
+
+// summarizeColumnImpact folds the edges leaving the column nodes into the
+// impact response; edges arrive newest first and each run or derived node
+// is reported once.
+func summarizeColumnImpact(datasetID, column string, versions []string, edges []columnImpactEdge) columnImpact {
+	out := columnImpact{
+		DatasetID:         datasetID,
+		Column:            column,
+		DatasetVersionIDs: versions,
+		Runs:              []columnImpactRun{},
+		Derived:           []columnImpactNode{},
+	}
+	runs := make(map[string]struct{})
+	derived := make(map[lineageNode]struct{})
+	for _, edge := range edges {
+		switch edge.Predicate {
+		case "used_by":
+			if _, ok := runs[edge.ObjectID]; ok {
+				continue
+			}
+			runs[edge.ObjectID] = struct{}{}
+			out.Runs = append(out.Runs, columnImpactRun{RunID: edge.ObjectID, DatasetVersionID: edge.DatasetVersionID, DeclaredAt: edge.OccurredAt})
+		case "derived_into":
+			node := lineageNode{Type: edge.ObjectType, ID: edge.ObjectID}
+			if _, ok := derived[node]; ok {
+				continue
+			}
+			derived[node] = struct{}{}
+			out.Derived = append(out.Derived, columnImpactNode{lineageNode: node, DatasetVersionID: edge.DatasetVersionID, RunID: edge.RunID})
+		}
+	}
+	sort.Slice(out.Runs, func(i, j int) bool { return out.Runs[i].RunID < out.Runs[j].RunID })
+	sort.Slice(out.Derived, func(i, j int) bool {
+		if out.Derived[i].Type == out.Derived[j].Type {
+			return out.Derived[i].ID < out.Derived[j].ID
+		}
+		return out.Derived[i].Type < out.Derived[j].Type
+	})
+	return out
+}
+
+func (api *lineageAPI) handleColumnImpact(w http.ResponseWriter, r *http.Request) {
+	datasetID := strings.TrimSpace(r.PathValue("dataset_id"))
+	column := r.PathValue("column")
+	if datasetID == "" || column == "" {
+		api.writeError(w, r, http.StatusBadRequest, "column_required")
+		return
+	}
+	maxEdges := httpapi.ClampInt(httpapi.ParseIntQuery(r, "max_edges", 2000), 1, 5000)
+	asOf, ok := parseAsOf(r)
+	if !ok {
+		api.writeError(w, r, http.StatusBadRequest, "as_of_invalid")
+		return
+	}
+
+	versions, err := api.columnVersions(r.Context(), datasetID, column, asOf)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	edges, err := api.columnImpactEdges(r.Context(), datasetID, column, asOf, maxEdges+1)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	truncated := len(edges) > maxEdges
+	if truncated {
+		edges = edges[:maxEdges]
+	}
+	out := summarizeColumnImpact(datasetID, column, versions, edges)
+	out.Truncated = truncated
+	if !asOf.IsZero() {
+		out.AsOf = &asOf
+	}
+	api.writeJSON(w, http.StatusOK, out)
+}
+
+func (api *lineageAPI) columnVersions(ctx context.Context, datasetID, column string, asOf time.Time) ([]string, error) {
+	rows, err := api.db.QueryContext(
+		ctx,
+		`SELECT dataset_version_id
+		 FROM lineage_columns
+		 WHERE node_type = $1 AND dataset_id = $2 AND column_name = $3
+		   AND ($4::timestamptz IS NULL OR created_at <= $4)
+		 ORDER BY dataset_version_id`,
+		lineageevent.NodeDatasetColumn,
+		datasetID,
+		column,
+		asOfArg(asOf),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []string{}
+	for rows.Next() {
+		var versionID string
+		if err := rows.Scan(&versionID); err != nil {
+			return nil, err
+		}
+		out = append(out, versionID)
+	}
+	return out, rows.Err()
+}
+
+func (api *lineageAPI) columnImpactEdges(ctx context.Context, datasetID, column string, asOf time.Time, limit int) ([]columnImpactEdge, error) {
+	rows, err := api.db.QueryContext(
+		ctx,
+		`SELECT c.dataset_version_id, e.predicate, e.object_type, e.object_id, COALESCE(e.metadata->>'run_id', ''), e.occurred_at
+		 FROM lineage_columns c
+		 JOIN lineage_events e ON e.subject_type = c.node_type AND e.subject_id = c.node_id
+		 WHERE c.node_type = $1 AND c.dataset_id = $2 AND c.column_name = $3
+		   AND e.predicate IN ('used_by', 'derived_into')
+		   AND ($4::timestamptz IS NULL OR e.occurred_at <= $4)
+		 ORDER BY e.event_id DESC
+		 LIMIT $5`,
+		lineageevent.NodeDatasetColumn,
+		datasetID,
+		column,
+		asOfArg(asOf),
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []columnImpactEdge{}
+	for rows.Next() {
+		var edge columnImpactEdge
+		if err := rows.Scan(&edge.DatasetVersionID, &edge.Predicate, &edge.ObjectType, &edge.ObjectID, &edge.RunID, &edge.OccurredAt); err != nil {
+			return nil, err
+		}
+		edge.OccurredAt = edge.OccurredAt.UTC()
+		out = append(out, edge)
+	}
+	return out, rows.Err()
+}
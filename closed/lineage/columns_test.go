@@ -0,0 +1,23 @@
+package lineage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSummarizeColumnImpact(t *testing.T) {
+	now := time.Now().UTC()
+	edges := []columnImpactEdge{
+		{DatasetVersionID: "dv-2", Predicate: "used_by", ObjectType: "experiment_run", ObjectID: "run-2", OccurredAt: now},
+		{DatasetVersionID: "dv-1", Predicate: "derived_into", ObjectType: "run_feature", ObjectID: "run-1#risk_score", RunID: "run-1", OccurredAt: now},
+		{DatasetVersionID: "dv-1", Predicate: "used_by", ObjectType: "experiment_run", ObjectID: "run-1", OccurredAt: now},
+		{DatasetVersionID: "dv-2", Predicate: "used_by", ObjectType: "experiment_run", ObjectID: "run-1", OccurredAt: now},
+	}
+	got := summarizeColumnImpact("ds-1", "ssn", []string{"dv-1", "dv-2"}, edges)
+	if len(got.Runs) != 2 || got.Runs[0].RunID != "run-1" || got.Runs[1].RunID != "run-2" {
+		t.Fatalf("runs = %+v", got.Runs)
+	}
+	if len(got.Derived) != 1 || got.Derived[0].ID != "run-1#risk_score" || got.Derived[0].RunID != "run-1" {
+		t.Fatalf("derived = %+v", got.Derived)
+	}
+}
@@ -0,0 +1,340 @@
+package lineage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/auditlog"
+	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
+	"github.com/animus-labs/animus-go/closed/internal/platform/httpapi"
+	"github.com/animus-labs/animus-go/closed/internal/platform/lineageevent"
+	"github.com/animus-labs/animus-go/closed/internal/platform/rbac"
+)
+
+const (
+	// ingestSource marks events contributed through POST /events in their
+	// metadata, so readers can tell them from edges written by the platform.
+	ingestSource = "external"
+
+	maxIngestIDLength       = 512
+	maxIngestKeyLength      = 200
+	maxIngestMetadataBytes  = 16 << 10
+	maxIngestClockSkew      = 5 * time.Minute
+	ingestAuditAction       = "lineage_event.ingest"
+	ingestIdempotencyHeader = "Idempotency-Key"
+)
+
+var ingestTermPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+
+func (api *lineageAPI) registerIngest(mux *http.ServeMux) {
+	mux.HandleFunc("POST /events", api.handleIngestEvent)
+}
+
+// isIngestRequest reports whether r is the one write route of the service,
+// which editors may call; every other route stays admin-only.
+func isIngestRequest(r *http.Request) bool {
+	return r.Method == http.MethodPost && strings.TrimSuffix(r.URL.Path, "/") == "/events"
+}
+
+type ingestEventRequest struct {
+	OccurredAt  *time.Time      `json:"occurred_at,omitempty"`
+	SubjectType string          `json:"subject_type"`
+	SubjectID   string          `json:"subject_id"`
+	Predicate   string          `json:"predicate"`
+	ObjectType  string          `json:"object_type"`
+	ObjectID    string          `json:"object_id"`
+	Metadata    json.RawMessage `json:"metadata,omitempty"`
+}
+
+type ingestEventResponse struct {
+	Event   lineageEvent `json:"event"`
+	Created bool         `json:"created"`
+}
+
+// ingestEvent is a validated request. OccurredAt stays nil when the client
+// left it to the server, so a retry hashes the same as the original.
+type ingestEvent struct {
+	OccurredAt  *time.Time     `json:"occurred_at,omitempty"`
+	SubjectType string         `json:"subject_type"`
+	SubjectID   string         `json:"subject_id"`
+	Predicate   string         `json:"predicate"`
+	ObjectType  string         `json:"object_type"`
+	ObjectID    string         `json:"object_id"`
+	Metadata    map[string]any `json:"metadata"`
+}
+
+// normalizeIngestEvent validates req against the ingestion schema and
+// returns the error code of the first violation.
+func normalizeIngestEvent(req ingestEventRequest, now time.Time) (ingestEvent, string) {
+	out := ingestEvent{
+		SubjectType: strings.TrimSpace(req.SubjectType),
+		SubjectID:   strings.TrimSpace(req.SubjectID),
+		Predicate:   strings.TrimSpace(req.Predicate),
+		ObjectType:  strings.TrimSpace(req.ObjectType),
+		ObjectID:    strings.TrimSpace(req.ObjectID),
+	}
+	switch {
+	case !ingestTermPattern.MatchString(out.SubjectType):
+		return ingestEvent{}, "subject_type_invalid"
+	case !validIngestID(out.SubjectID):
+		return ingestEvent{}, "subject_id_invalid"
+	case !ingestTermPattern.MatchString(out.Predicate):
+		return ingestEvent{}, "predicate_invalid"
+	case !ingestTermPattern.MatchString(out.ObjectType):
+		return ingestEvent{}, "object_type_invalid"
+	case !validIngestID(out.ObjectID):
+		return ingestEvent{}, "object_id_invalid"
+	}
+	if req.OccurredAt != nil {
+		at := req.OccurredAt.UTC()
+		if at.IsZero() || at.After(now.Add(maxIngestClockSkew)) {
+			return ingestEvent{}, "occurred_at_invalid"
+		}
+		out.OccurredAt = &at
+	}
+
+	out.Metadata = map[string]any{}
+	raw := bytes.TrimSpace(req.Metadata)
+	if len(raw) > maxIngestMetadataBytes {
+		return ingestEvent{}, "metadata_invalid"
+	}
+	if len(raw) > 0 && !bytes.Equal(raw, []byte("null")) {
+		if raw[0] != '{' || json.Unmarshal(raw, &out.Metadata) != nil {
+			return ingestEvent{}, "metadata_invalid"
+		}
+	}
+	if _, reserved := out.Metadata["source"]; reserved {
+		return ingestEvent{}, "metadata_invalid"
+	}
+	return out, ""
+}
+
+func validIngestID(id string) bool {
+	if id == "" || len(id) > maxIngestIDLength {
+		return false
+	}
+	for _, r := range id {
+		if unicode.IsControl(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// requestSHA256 hashes the validated request as the producer sent it; it
+// is what an idempotent retry must reproduce.
+func (e ingestEvent) requestSHA256() (string, error) {
+	raw, err := json.Marshal(e)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func (api *lineageAPI) handleIngestEvent(w http.ResponseWriter, r *http.Request) {
+	identity, ok := auth.IdentityFromContext(r.Context())
+	if !ok || strings.TrimSpace(identity.Subject) == "" {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if rbac.IsRunToken(identity) {
+		api.writeError(w, r, http.StatusForbidden, "forbidden")
+		return
+	}
+	if api.writes == nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	idempotencyKey := strings.TrimSpace(r.Header.Get(ingestIdempotencyHeader))
+	if idempotencyKey == "" {
+		api.writeError(w, r, http.StatusBadRequest, "idempotency_key_required")
+		return
+	}
+	if len(idempotencyKey) > maxIngestKeyLength {
+		api.writeError(w, r, http.StatusBadRequest, "idempotency_key_invalid")
+		return
+	}
+
+	var req ingestEventRequest
+	if err := httpapi.DecodeJSON(r, &req); err != nil {
+		httpapi.WriteDecodeError(w, r, err)
+		return
+	}
+	now := time.Now().UTC()
+	event, code := normalizeIngestEvent(req, now)
+	if code != "" {
+		api.writeError(w, r, http.StatusBadRequest, code)
+		return
+	}
+	requestSHA, err := event.requestSHA256()
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+
+	producer := strings.TrimSpace(identity.Subject)
+	if api.writeReplay(w, r, producer, idempotencyKey, requestSHA) {
+		return
+	}
+
+	occurredAt := now
+	if event.OccurredAt != nil {
+		occurredAt = *event.OccurredAt
+	}
+	metadata := make(map[string]any, len(event.Metadata)+1)
+	for k, v := range event.Metadata {
+		metadata[k] = v
+	}
+	metadata["source"] = ingestSource
+
+	tx, err := api.writes.BeginTx(r.Context(), nil)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	eventID, err := lineageevent.Insert(r.Context(), tx, lineageevent.Event{
+		OccurredAt:  occurredAt,
+		Actor:       producer,
+		RequestID:   r.Header.Get("X-Request-Id"),
+		SubjectType: event.SubjectType,
+		SubjectID:   event.SubjectID,
+		Predicate:   event.Predicate,
+		ObjectType:  event.ObjectType,
+		ObjectID:    event.ObjectID,
+		Metadata:    metadata,
+	})
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	res, err := tx.ExecContext(
+		r.Context(),
+		`INSERT INTO lineage_event_ingestions (producer, idempotency_key, event_id, request_sha256, created_at)
+		 VALUES ($1, $2, $3, $4, $5)
+		 ON CONFLICT (producer, idempotency_key) DO NOTHING`,
+		producer,
+		idempotencyKey,
+		eventID,
+		requestSHA,
+		now,
+	)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		// A concurrent request with the same key won; answer as its retry.
+		_ = tx.Rollback()
+		if !api.writeReplay(w, r, producer, idempotencyKey, requestSHA) {
+			api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		}
+		return
+	}
+
+	if err := auditlog.Enqueue(r.Context(), tx, auditlog.Event{
+		OccurredAt:   now,
+		Actor:        producer,
+		Action:       ingestAuditAction,
+		ResourceType: "lineage_event",
+		ResourceID:   strconv.FormatInt(eventID, 10),
+		RequestID:    r.Header.Get("X-Request-Id"),
+		IP:           httpapi.RequestIP(r.RemoteAddr),
+		UserAgent:    r.UserAgent(),
+		Payload: map[string]any{
+			"service":         "lineage",
+			"subject_type":    event.SubjectType,
+			"subject_id":      event.SubjectID,
+			"predicate":       event.Predicate,
+			"object_type":     event.ObjectType,
+			"object_id":       event.ObjectID,
+			"idempotency_key": idempotencyKey,
+			"request_sha256":  requestSHA,
+		},
+	}); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "audit_failed")
+		return
+	}
+	stored, err := loadLineageEvent(r.Context(), tx, eventID)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	api.writeJSON(w, http.StatusCreated, ingestEventResponse{Event: stored, Created: true})
+}
+
+// writeReplay answers a request whose idempotency key the producer has
+// already used: the original event when the request matches it, 409
+// otherwise. It reports false, writing nothing, when the key is unused.
+func (api *lineageAPI) writeReplay(w http.ResponseWriter, r *http.Request, producer, key, requestSHA string) bool {
+	var (
+		eventID   int64
+		storedSHA string
+	)
+	err := api.writes.QueryRowContext(
+		r.Context(),
+		`SELECT event_id, request_sha256
+		 FROM lineage_event_ingestions
+		 WHERE producer = $1 AND idempotency_key = $2`,
+		producer,
+		key,
+	).Scan(&eventID, &storedSHA)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false
+	}
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return true
+	}
+	if storedSHA != requestSHA {
+		api.writeError(w, r, http.StatusConflict, "idempotency_conflict")
+		return true
+	}
+	ev, err := loadLineageEvent(r.Context(), api.writes, eventID)
+	if err != nil {
+		api.writeError(w, r, http.StatusInternalServerError, "internal_error")
+		return true
+	}
+	api.writeJSON(w, http.StatusOK, ingestEventResponse{Event: ev, Created: false})
+	return true
+}
+
+func loadLineageEvent(ctx context.Context, q lineageevent.QueryRower, eventID int64) (lineageEvent, error) {
+	var (
+		ev          lineageEvent
+		requestID   sql.NullString
+		metadataRaw []byte
+	)
+	err := q.QueryRowContext(
+		ctx,
+		`SELECT event_id, occurred_at, actor, request_id, subject_type, subject_id, predicate, object_type, object_id, metadata
+		 FROM lineage_events
+		 WHERE event_id = $1`,
+		eventID,
+	).Scan(&ev.EventID, &ev.OccurredAt, &ev.Actor, &requestID, &ev.SubjectType, &ev.SubjectID, &ev.Predicate, &ev.ObjectType, &ev.ObjectID, &metadataRaw)
+	if err != nil {
+		return lineageEvent{}, err
+	}
+	ev.OccurredAt = ev.OccurredAt.UTC()
+	ev.RequestID = strings.TrimSpace(requestID.String)
+	ev.Metadata = httpapi.NormalizeJSON(metadataRaw)
+	return ev, nil
+}
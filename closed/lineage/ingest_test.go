@@ -0,0 +1,74 @@
+package lineage
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNormalizeIngestEvent(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	base := ingestEventRequest{
+		SubjectType: "etl_job",
+		SubjectID:   " nightly-orders ",
+		Predicate:   "produced",
+		ObjectType:  "dataset_version",
+		ObjectID:    "dv-1",
+		Metadata:    json.RawMessage(`{"run":"2026-03-01"}`),
+	}
+	got, code := normalizeIngestEvent(base, now)
+	if code != "" || got.SubjectID != "nightly-orders" || got.OccurredAt != nil || got.Metadata["run"] != "2026-03-01" {
+		t.Fatalf("normalize = %+v, %q", got, code)
+	}
+
+	future := now.Add(time.Hour)
+	cases := map[string]func(*ingestEventRequest){
+		"subject_type_invalid": func(r *ingestEventRequest) { r.SubjectType = "ETL Job" },
+		"subject_id_invalid":   func(r *ingestEventRequest) { r.SubjectID = "a\nb" },
+		"predicate_invalid":    func(r *ingestEventRequest) { r.Predicate = "" },
+		"object_type_invalid":  func(r *ingestEventRequest) { r.ObjectType = "1dataset" },
+		"object_id_invalid":    func(r *ingestEventRequest) { r.ObjectID = " " },
+		"occurred_at_invalid":  func(r *ingestEventRequest) { r.OccurredAt = &future },
+		"metadata_invalid":     func(r *ingestEventRequest) { r.Metadata = json.RawMessage(`[1]`) },
+	}
+	for want, mutate := range cases {
+		req := base
+		mutate(&req)
+		if _, code := normalizeIngestEvent(req, now); code != want {
+			t.Fatalf("%s: got %q", want, code)
+		}
+	}
+
+	reserved := base
+	reserved.Metadata = json.RawMessage(`{"source":"internal"}`)
+	if _, code := normalizeIngestEvent(reserved, now); code != "metadata_invalid" {
+		t.Fatalf("reserved source key accepted: %q", code)
+	}
+}
+
+func TestIngestRequestSHA256IgnoresServerDefaults(t *testing.T) {
+	req := ingestEventRequest{SubjectType: "etl_job", SubjectID: "a", Predicate: "produced", ObjectType: "dataset", ObjectID: "b"}
+	first, _ := normalizeIngestEvent(req, time.Now())
+	retry, _ := normalizeIngestEvent(req, time.Now().Add(time.Minute))
+	a, _ := first.requestSHA256()
+	b, _ := retry.requestSHA256()
+	if a != b {
+		t.Fatalf("retry hashed differently: %s != %s", a, b)
+	}
+
+	req.Metadata = json.RawMessage(`{"rows":10}`)
+	changed, _ := normalizeIngestEvent(req, time.Now())
+	if c, _ := changed.requestSHA256(); c == a {
+		t.Fatalf("metadata change not reflected in hash")
+	}
+}
+
+func TestIsIngestRequest(t *testing.T) {
+	if !isIngestRequest(httptest.NewRequest("POST", "/events", nil)) {
+		t.Fatalf("POST /events not recognised")
+	}
+	if isIngestRequest(httptest.NewRequest("GET", "/events", nil)) || isIngestRequest(httptest.NewRequest("POST", "/events/1", nil)) {
+		t.Fatalf("other routes treated as ingestion")
+	}
+}
@@ -1,4 +1,4 @@
-package main
+package lineage
 
 import (
 	"context"
@@ -14,12 +14,19 @@ import (
 	"github.com/animus-labs/animus-go/closed/internal/platform/auth"
 	"github.com/animus-labs/animus-go/closed/internal/platform/env"
 	"github.com/animus-labs/animus-go/closed/internal/platform/httpserver"
+	"github.com/animus-labs/animus-go/closed/internal/platform/inproc"
+	"github.com/animus-labs/animus-go/closed/internal/platform/migrate"
+	"github.com/animus-labs/animus-go/closed/internal/platform/outbox"
+	"github.com/animus-labs/animus-go/closed/internal/platform/outbox/servicerelay"
 	"github.com/animus-labs/animus-go/closed/internal/platform/postgres"
 	"github.com/animus-labs/animus-go/closed/internal/platform/rbac"
 	repopg "github.com/animus-labs/animus-go/closed/internal/repo/postgres"
+	"github.com/animus-labs/animus-go/closed/migrations"
+	"github.com/animus-labs/animus-go/core/contracts/openapi"
 )
 
-func main() {
+// Main runs lineage as a standalone service.
+func Main() {
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
 
 	ctx := context.Background()
@@ -32,13 +39,8 @@ func main() {
 		logger.Error("invalid env", "error", err)
 		os.Exit(2)
 	}
-	rbacAllowDirect, err := env.Bool("AUTH_RBAC_ALLOW_DIRECT_ROLES", true)
-	if err != nil {
-		logger.Error("invalid env", "error", err)
-		os.Exit(2)
-	}
 
-	dbCfg, err := postgres.ConfigFromEnv()
+	dbCfg, err := postgres.ConfigFromEnv("lineage")
 	if err != nil {
 		logger.Error("invalid database config", "error", err)
 		os.Exit(2)
@@ -50,24 +52,108 @@ func main() {
 	}
 	defer func() { _ = db.Close() }()
 
-	internalAuthSecret := env.String("ANIMUS_INTERNAL_AUTH_SECRET", "")
-	headersAuth, err := auth.NewGatewayHeadersAuthenticator(internalAuthSecret)
+	migrator, err := migrate.New(db, migrations.Files, logger)
+	if err != nil {
+		logger.Error("invalid migrations", "error", err)
+		os.Exit(2)
+	}
+	if done, err := migrate.Startup(ctx, migrator, os.Args[1:], os.Stdout); err != nil {
+		logger.Error("migrations failed", "error", err)
+		os.Exit(1)
+	} else if done {
+		return
+	}
+
+	replicaCfg, err := postgres.ReplicaConfigFromEnv("lineage")
+	if err != nil {
+		logger.Error("invalid read replica config", "error", err)
+		os.Exit(2)
+	}
+	reads, err := postgres.OpenReplica(db, dbCfg, replicaCfg, logger)
+	if err != nil {
+		logger.Error("read replica init failed", "error", err)
+		os.Exit(2)
+	}
+	defer func() { _ = reads.Close() }()
+	httpserver.RegisterMetricsProvider(postgres.PrometheusMetrics("lineage", reads.Pools()))
+
+	internalMTLSCfg, err := auth.InternalMTLSConfigFromEnv()
+	if err != nil {
+		logger.Error("invalid internal mtls config", "error", err)
+		os.Exit(2)
+	}
+	internalServerTLS, err := internalMTLSCfg.ServerTLSConfig()
+	if err != nil {
+		logger.Error("internal mtls init failed", "error", err)
+		os.Exit(2)
+	}
+	handler := NewHandler(ctx, logger, &inproc.Resources{DB: db, Reads: reads, Migrator: migrator})
+
+	cfg := httpserver.Config{
+		Service:         "lineage",
+		Addr:            addr,
+		ShutdownTimeout: shutdownTimeout,
+		TLSConfig:       internalServerTLS,
+	}
+
+	if err := httpserver.Run(ctx, logger, cfg, httpserver.Wrap(logger, "lineage", handler)); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Error("server failed", "error", err)
+		os.Exit(1)
+	}
+}
+
+// NewHandler builds the lineage API on res. Invalid configuration exits the
+// process, as it does in Main.
+func NewHandler(ctx context.Context, logger *slog.Logger, res *inproc.Resources) http.Handler {
+	db, reads := res.DB, res.Reads
+	rbacAllowDirect, err := env.Bool("AUTH_RBAC_ALLOW_DIRECT_ROLES", true)
+	if err != nil {
+		logger.Error("invalid env", "error", err)
+		os.Exit(2)
+	}
+	headersAuth, err := auth.NewGatewayHeadersAuthenticator(env.String("ANIMUS_INTERNAL_AUTH_SECRET", ""))
 	if err != nil {
 		logger.Error("invalid internal auth config", "error", err)
 		os.Exit(2)
 	}
+	internalMTLSCfg, err := auth.InternalMTLSConfigFromEnv()
+	if err != nil {
+		logger.Error("invalid internal mtls config", "error", err)
+		os.Exit(2)
+	}
+	headersAuth.Peers = internalMTLSCfg.PeerVerifier()
+
+	outboxCfg, err := outbox.ConfigFromEnv()
+	if err != nil {
+		logger.Error("invalid outbox config", "error", err)
+		os.Exit(2)
+	}
+	servicerelay.Start(ctx, logger, db, outboxCfg, nil, "")
+
+	rbacPermissionsTTL, err := env.Duration("AUTH_RBAC_PERMISSIONS_CACHE_TTL", 30*time.Second)
+	if err != nil {
+		logger.Error("invalid env", "error", err)
+		os.Exit(2)
+	}
 
 	auditAppender := repopg.NewAuditAppender(db, nil)
 	authorizer := rbac.Authorizer{
 		Audit:       auditAppender,
 		AllowDirect: rbacAllowDirect,
 		RequiredRoleFor: func(r *http.Request) string {
+			if isIngestRequest(r) {
+				return auth.RoleEditor
+			}
 			return auth.RoleAdmin
 		},
+		Permissions:  rbac.NewPermissionCache(repopg.NewRBACPermissionStore(db), "lineage", rbacPermissionsTTL),
+		AuditorScope: rbac.AuditorReadAll,
 	}
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/healthz", httpserver.Healthz("lineage"))
+	mux.HandleFunc("/version", httpserver.BuildInfo("lineage"))
+	mux.Handle("GET "+openapi.ServicePath, openapi.Handler(openapi.Lineage))
 	mux.HandleFunc(
 		"/readyz",
 		httpserver.ReadyzWithChecks(
@@ -84,10 +170,11 @@ func main() {
 	)
 	httpserver.RegisterMetrics(mux, "lineage")
 
-	api := newLineageAPI(logger, db)
+	api := newLineageAPI(logger, reads)
+	api.writes = db
 	api.register(mux)
 
-	handler := auth.Middleware{
+	return auth.Middleware{
 		Logger:        logger,
 		Authenticator: headersAuth,
 		Authorize:     authorizer.Authorize,
@@ -96,17 +183,6 @@ func main() {
 			defer cancel()
 			return auditlog.InsertAuthDeny(auditCtx, db, "lineage", event)
 		},
-		SkipPrefixes: []string{"/healthz", "/readyz"},
+		SkipPrefixes: []string{"/healthz", "/readyz", "/version", openapi.ServicePath},
 	}.Wrap(mux)
-
-	cfg := httpserver.Config{
-		Service:         "lineage",
-		Addr:            addr,
-		ShutdownTimeout: shutdownTimeout,
-	}
-
-	if err := httpserver.Run(ctx, logger, cfg, httpserver.Wrap(logger, "lineage", handler)); err != nil && !errors.Is(err, http.ErrServerClosed) {
-		logger.Error("server failed", "error", err)
-		os.Exit(1)
-	}
 }
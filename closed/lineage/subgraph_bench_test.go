@@ -0,0 +1,106 @@
+package lineage
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/animus-labs/animus-go/closed/internal/platform/migrate"
+	"github.com/animus-labs/animus-go/closed/migrations"
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+// BenchmarkSubgraphDepth4 traverses four levels of a graph of millions of
+// edges in a scratch database named by ANIMUS_LINEAGE_BENCH_DATABASE_URL
+// (migrated and seeded on first use; ANIMUS_LINEAGE_BENCH_EDGES sets the
+// size, 2,000,000 by default). It fails when a traversal averages 100 ms or
+// more:
+//
+//	ANIMUS_LINEAGE_BENCH_DATABASE_URL=postgres://... go test ./closed/lineage -run '^$' -bench SubgraphDepth4
+func BenchmarkSubgraphDepth4(b *testing.B) {
+	dsn := os.Getenv("ANIMUS_LINEAGE_BENCH_DATABASE_URL")
+	if dsn == "" {
+		b.Skip("ANIMUS_LINEAGE_BENCH_DATABASE_URL not set")
+	}
+	edges := 2_000_000
+	if raw := os.Getenv("ANIMUS_LINEAGE_BENCH_EDGES"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			b.Fatalf("ANIMUS_LINEAGE_BENCH_EDGES=%q", raw)
+		}
+		edges = n
+	}
+
+	ctx := context.Background()
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		b.Fatalf("open db: %v", err)
+	}
+	defer db.Close()
+	migrator, err := migrate.New(db, migrations.Files, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		b.Fatalf("migrations: %v", err)
+	}
+	if _, err := migrator.Up(ctx); err != nil {
+		b.Fatalf("migrate: %v", err)
+	}
+	seedBenchLineage(b, db, edges)
+
+	api := newLineageAPI(slog.New(slog.NewTextHandler(io.Discard, nil)), db)
+	root := lineageNode{Type: "dataset_version", ID: "bench-dv-1"}
+
+	b.ResetTimer()
+	start := time.Now()
+	for i := 0; i < b.N; i++ {
+		if _, err := api.buildSubgraph(ctx, root, time.Time{}, 4, 2000); err != nil {
+			b.Fatalf("buildSubgraph: %v", err)
+		}
+	}
+	perOp := time.Since(start) / time.Duration(b.N)
+	b.ReportMetric(float64(perOp.Microseconds())/1000, "ms/traversal")
+	if perOp >= 100*time.Millisecond {
+		b.Errorf("depth-4 traversal took %s, want under 100ms", perOp)
+	}
+}
+
+// seedBenchLineage tops the benchmark graph up to edges edges: dataset
+// versions used by runs, about ten runs per version and four versions per
+// run, so four levels from a version exceed the 2000 edge cap.
+func seedBenchLineage(b *testing.B, db *sql.DB, edges int) {
+	b.Helper()
+	ctx := context.Background()
+	var have int
+	if err := db.QueryRowContext(ctx, `SELECT count(*) FROM lineage_events WHERE actor = 'bench'`).Scan(&have); err != nil {
+		b.Fatalf("count bench edges: %v", err)
+	}
+	if have >= edges {
+		return
+	}
+	versions, runs := edges/10+1, edges/4+1
+	if _, err := db.ExecContext(
+		ctx,
+		`INSERT INTO lineage_events (occurred_at, actor, subject_type, subject_id, predicate, object_type, object_id, metadata, integrity_sha256)
+		 SELECT now() - make_interval(secs => g), 'bench',
+		        'dataset_version', 'bench-dv-' || (g % $3),
+		        'used_by',
+		        'experiment_run', 'bench-run-' || ((g * 7919) % $4),
+		        '{}'::jsonb, md5(g::text)
+		 FROM generate_series($1::bigint + 1, $2::bigint) AS g`,
+		have,
+		edges,
+		versions,
+		runs,
+	); err != nil {
+		b.Fatalf("seed bench edges: %v", err)
+	}
+	for _, table := range []string{"lineage_events", "lineage_adjacency"} {
+		if _, err := db.ExecContext(ctx, "ANALYZE "+table); err != nil {
+			b.Fatalf("analyze %s: %v", table, err)
+		}
+	}
+}
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS rbac_route_permissions;
+DROP TABLE IF EXISTS rbac_roles;
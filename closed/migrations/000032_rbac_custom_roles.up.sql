@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS rbac_roles (
+  role_name TEXT PRIMARY KEY,
+  description TEXT,
+  permissions JSONB NOT NULL DEFAULT '[]'::jsonb,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  created_by TEXT NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_by TEXT NOT NULL,
+  integrity_sha256 TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS rbac_route_permissions (
+  route_id TEXT PRIMARY KEY,
+  service TEXT NOT NULL,
+  method TEXT NOT NULL,
+  path_pattern TEXT NOT NULL,
+  permission TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  created_by TEXT NOT NULL,
+  integrity_sha256 TEXT NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_rbac_route_permissions_unique
+  ON rbac_route_permissions (service, method, path_pattern);
+CREATE INDEX IF NOT EXISTS idx_rbac_route_permissions_service
+  ON rbac_route_permissions (service);
@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS service_accounts (
+  account_id TEXT PRIMARY KEY,
+  name TEXT NOT NULL,
+  description TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  created_by TEXT NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_service_accounts_name_unique
+  ON service_accounts (name);
+
+CREATE TABLE IF NOT EXISTS service_account_tokens (
+  token_id TEXT PRIMARY KEY,
+  account_id TEXT NOT NULL REFERENCES service_accounts(account_id),
+  name TEXT,
+  token_sha256 TEXT NOT NULL,
+  role TEXT NOT NULL,
+  project_id TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  created_by TEXT NOT NULL,
+  expires_at TIMESTAMPTZ NOT NULL,
+  last_used_at TIMESTAMPTZ,
+  revoked_at TIMESTAMPTZ,
+  revoked_by TEXT,
+  revoke_reason TEXT,
+  rotated_from TEXT
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_service_account_tokens_sha_unique
+  ON service_account_tokens (token_sha256);
+CREATE INDEX IF NOT EXISTS idx_service_account_tokens_account
+  ON service_account_tokens (account_id, created_at DESC);
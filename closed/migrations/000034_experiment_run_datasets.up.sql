@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS experiment_run_datasets (
+  run_id TEXT NOT NULL REFERENCES experiment_runs(run_id) ON DELETE CASCADE,
+  dataset_version_id TEXT NOT NULL REFERENCES dataset_versions(version_id),
+  position INTEGER NOT NULL,
+  dataset_id TEXT NOT NULL,
+  content_sha256 TEXT NOT NULL,
+  quality_rule_id TEXT,
+  quality_evaluation_id TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (run_id, dataset_version_id),
+  UNIQUE (run_id, position)
+);
+
+CREATE INDEX IF NOT EXISTS idx_experiment_run_datasets_version
+  ON experiment_run_datasets (dataset_version_id);
+
+INSERT INTO experiment_run_datasets (run_id, dataset_version_id, position, dataset_id, content_sha256, created_at)
+SELECT r.run_id, r.dataset_version_id, 0, v.dataset_id, v.content_sha256, r.started_at
+  FROM experiment_runs r
+  JOIN dataset_versions v ON v.version_id = r.dataset_version_id
+ WHERE r.dataset_version_id IS NOT NULL
+ON CONFLICT DO NOTHING;
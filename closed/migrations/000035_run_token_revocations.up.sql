@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS run_token_revocations (
+  revocation_id TEXT PRIMARY KEY,
+  run_id TEXT NOT NULL,
+  token_sha256 TEXT,
+  reason TEXT NOT NULL,
+  revoked_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  revoked_by TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_run_token_revocations_run
+  ON run_token_revocations (run_id, revoked_at DESC);
+CREATE INDEX IF NOT EXISTS idx_run_token_revocations_sha
+  ON run_token_revocations (token_sha256)
+  WHERE token_sha256 IS NOT NULL;
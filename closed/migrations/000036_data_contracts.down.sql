@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS data_contract_evaluations;
+DROP TABLE IF EXISTS data_contracts;
@@ -0,0 +1,50 @@
+CREATE TABLE IF NOT EXISTS data_contracts (
+  contract_id TEXT PRIMARY KEY,
+  project_id TEXT NOT NULL REFERENCES projects(project_id),
+  dataset_id TEXT NOT NULL REFERENCES datasets(dataset_id),
+  version INTEGER NOT NULL CHECK (version > 0),
+  status TEXT NOT NULL CHECK (status IN ('pending','approved','rejected','superseded')),
+  schema JSONB NOT NULL,
+  freshness_slo_seconds BIGINT CHECK (freshness_slo_seconds IS NULL OR freshness_slo_seconds > 0),
+  owner TEXT NOT NULL,
+  consumers JSONB NOT NULL DEFAULT '[]'::jsonb,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  created_by TEXT NOT NULL,
+  decided_at TIMESTAMPTZ,
+  decided_by TEXT,
+  decision_reason TEXT,
+  integrity_sha256 TEXT NOT NULL,
+  UNIQUE (dataset_id, version)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_data_contracts_dataset_approved
+  ON data_contracts (dataset_id)
+  WHERE status = 'approved';
+CREATE INDEX IF NOT EXISTS idx_data_contracts_project ON data_contracts (project_id, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS data_contract_evaluations (
+  evaluation_id TEXT PRIMARY KEY,
+  contract_id TEXT NOT NULL REFERENCES data_contracts(contract_id),
+  project_id TEXT NOT NULL,
+  dataset_id TEXT NOT NULL,
+  dataset_version_id TEXT NOT NULL REFERENCES dataset_versions(version_id),
+  status TEXT NOT NULL CHECK (status IN ('pass','breach')),
+  violations JSONB NOT NULL DEFAULT '[]'::jsonb,
+  evaluated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  evaluated_by TEXT NOT NULL,
+  integrity_sha256 TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_data_contract_evaluations_version
+  ON data_contract_evaluations (dataset_version_id, evaluated_at DESC);
+CREATE INDEX IF NOT EXISTS idx_data_contract_evaluations_contract
+  ON data_contract_evaluations (contract_id, evaluated_at DESC);
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'trg_data_contract_evaluations_immutable') THEN
+    CREATE TRIGGER trg_data_contract_evaluations_immutable
+      BEFORE UPDATE OR DELETE ON data_contract_evaluations
+      FOR EACH ROW EXECUTE FUNCTION prevent_update_delete();
+  END IF;
+END $$;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS dataset_freshness_breaches;
+DROP TABLE IF EXISTS dataset_freshness_expectations;
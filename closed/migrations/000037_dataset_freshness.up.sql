@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS dataset_freshness_expectations (
+  dataset_id TEXT PRIMARY KEY REFERENCES datasets(dataset_id),
+  project_id TEXT NOT NULL REFERENCES projects(project_id),
+  max_age_days INTEGER NOT NULL CHECK (max_age_days > 0),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_by TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_dataset_freshness_expectations_project
+  ON dataset_freshness_expectations (project_id);
+
+CREATE TABLE IF NOT EXISTS dataset_freshness_breaches (
+  breach_id TEXT PRIMARY KEY,
+  project_id TEXT NOT NULL REFERENCES projects(project_id),
+  dataset_id TEXT NOT NULL REFERENCES datasets(dataset_id),
+  max_age_days INTEGER NOT NULL CHECK (max_age_days > 0),
+  last_version_id TEXT REFERENCES dataset_versions(version_id),
+  last_version_at TIMESTAMPTZ,
+  detected_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  resolved_at TIMESTAMPTZ,
+  resolved_version_id TEXT REFERENCES dataset_versions(version_id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_dataset_freshness_breaches_open
+  ON dataset_freshness_breaches (dataset_id)
+  WHERE resolved_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_dataset_freshness_breaches_project
+  ON dataset_freshness_breaches (project_id, detected_at DESC);
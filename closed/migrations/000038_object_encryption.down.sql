@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS idx_dataset_versions_encryption_key;
+
+ALTER TABLE experiment_run_evidence_bundles
+  DROP COLUMN IF EXISTS report_wrapped_key,
+  DROP COLUMN IF EXISTS bundle_wrapped_key,
+  DROP COLUMN IF EXISTS encryption_key_id,
+  DROP COLUMN IF EXISTS encryption_alg;
+
+ALTER TABLE dataset_versions
+  DROP COLUMN IF EXISTS encryption_wrapped_key,
+  DROP COLUMN IF EXISTS encryption_key_id,
+  DROP COLUMN IF EXISTS encryption_alg;
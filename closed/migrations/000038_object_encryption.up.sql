@@ -0,0 +1,14 @@
+ALTER TABLE dataset_versions
+  ADD COLUMN IF NOT EXISTS encryption_alg TEXT,
+  ADD COLUMN IF NOT EXISTS encryption_key_id TEXT,
+  ADD COLUMN IF NOT EXISTS encryption_wrapped_key TEXT;
+
+ALTER TABLE experiment_run_evidence_bundles
+  ADD COLUMN IF NOT EXISTS encryption_alg TEXT,
+  ADD COLUMN IF NOT EXISTS encryption_key_id TEXT,
+  ADD COLUMN IF NOT EXISTS bundle_wrapped_key TEXT,
+  ADD COLUMN IF NOT EXISTS report_wrapped_key TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_dataset_versions_encryption_key
+  ON dataset_versions (encryption_key_id)
+  WHERE encryption_key_id IS NOT NULL;
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS experiment_run_fencing (
+  experiment_id TEXT PRIMARY KEY REFERENCES experiments(experiment_id),
+  mode TEXT NOT NULL CHECK (mode IN ('exclusive', 'dedupe')),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_by TEXT NOT NULL
+);
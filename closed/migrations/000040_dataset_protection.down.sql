@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_dataset_version_recalls_dataset;
+DROP TABLE IF EXISTS dataset_version_recalls;
+DROP TABLE IF EXISTS dataset_protection;
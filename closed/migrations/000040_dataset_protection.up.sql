@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS dataset_protection (
+  dataset_id TEXT PRIMARY KEY REFERENCES datasets(dataset_id),
+  project_id TEXT NOT NULL REFERENCES projects(project_id),
+  classification_labels JSONB NOT NULL DEFAULT '[]'::jsonb,
+  residency TEXT,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_by TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS dataset_version_recalls (
+  version_id TEXT PRIMARY KEY REFERENCES dataset_versions(version_id),
+  dataset_id TEXT NOT NULL REFERENCES datasets(dataset_id),
+  project_id TEXT NOT NULL REFERENCES projects(project_id),
+  reason TEXT NOT NULL,
+  recalled_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  recalled_by TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_dataset_version_recalls_dataset
+  ON dataset_version_recalls (dataset_id, recalled_at DESC);
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS dataset_version_shares (
+  share_id TEXT PRIMARY KEY,
+  version_id TEXT NOT NULL REFERENCES dataset_versions(version_id),
+  dataset_id TEXT NOT NULL REFERENCES datasets(dataset_id),
+  project_id TEXT NOT NULL REFERENCES projects(project_id),
+  one_time BOOLEAN NOT NULL DEFAULT false,
+  token_sha256 TEXT UNIQUE,
+  expires_at TIMESTAMPTZ NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  created_by TEXT NOT NULL,
+  redeemed_at TIMESTAMPTZ,
+  CHECK (one_time = (token_sha256 IS NOT NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_dataset_version_shares_version
+  ON dataset_version_shares (version_id, created_at DESC);
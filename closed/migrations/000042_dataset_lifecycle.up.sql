@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS dataset_lifecycle (
+  dataset_id TEXT PRIMARY KEY REFERENCES datasets(dataset_id),
+  project_id TEXT NOT NULL REFERENCES projects(project_id),
+  state TEXT NOT NULL CHECK (state IN ('active', 'deprecated', 'archived')),
+  reason TEXT,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_by TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_dataset_lifecycle_project_state
+  ON dataset_lifecycle (project_id, state);
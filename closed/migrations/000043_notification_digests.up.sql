@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS notification_digests (
+  digest_id TEXT PRIMARY KEY,
+  project_id TEXT NOT NULL REFERENCES projects(project_id),
+  name TEXT NOT NULL,
+  subject TEXT,
+  cadence TEXT NOT NULL CHECK (cadence IN ('daily', 'weekly')),
+  hour_utc INTEGER NOT NULL CHECK (hour_utc BETWEEN 0 AND 23),
+  weekday INTEGER NOT NULL DEFAULT 1 CHECK (weekday BETWEEN 0 AND 6),
+  channel TEXT NOT NULL CHECK (channel IN ('webhook', 'email')),
+  recipients TEXT[] NOT NULL DEFAULT '{}',
+  enabled BOOLEAN NOT NULL DEFAULT true,
+  next_run_at TIMESTAMPTZ NOT NULL,
+  last_sent_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  created_by TEXT NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_digests_project
+  ON notification_digests (project_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_notification_digests_due
+  ON notification_digests (next_run_at) WHERE enabled;
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS dataset_version_profiles (
+  version_id TEXT PRIMARY KEY REFERENCES dataset_versions(version_id),
+  dataset_id TEXT NOT NULL REFERENCES datasets(dataset_id),
+  project_id TEXT NOT NULL REFERENCES projects(project_id),
+  format TEXT NOT NULL CHECK (format IN ('csv', 'parquet')),
+  status TEXT NOT NULL CHECK (status IN ('ok', 'failed')),
+  profile JSONB,
+  error TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CHECK ((status = 'ok') = (profile IS NOT NULL))
+);
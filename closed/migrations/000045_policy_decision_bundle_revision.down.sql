@@ -0,0 +1 @@
+ALTER TABLE policy_decisions DROP COLUMN IF EXISTS bundle_revision;
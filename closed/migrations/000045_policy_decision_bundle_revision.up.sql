@@ -0,0 +1,2 @@
+ALTER TABLE policy_decisions
+  ADD COLUMN IF NOT EXISTS bundle_revision TEXT;
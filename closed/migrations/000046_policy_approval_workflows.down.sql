@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS policy_approval_votes;
+ALTER TABLE policy_approvals
+  DROP COLUMN IF EXISTS escalate_at,
+  DROP COLUMN IF EXISTS stage_started_at,
+  DROP COLUMN IF EXISTS current_stage,
+  DROP COLUMN IF EXISTS workflow;
@@ -0,0 +1,23 @@
+ALTER TABLE policy_approvals
+  ADD COLUMN IF NOT EXISTS workflow JSONB,
+  ADD COLUMN IF NOT EXISTS current_stage INTEGER NOT NULL DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS stage_started_at TIMESTAMPTZ,
+  ADD COLUMN IF NOT EXISTS escalate_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_policy_approvals_escalate_at ON policy_approvals (escalate_at)
+  WHERE status = 'pending' AND escalate_at IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS policy_approval_votes (
+  vote_id TEXT PRIMARY KEY,
+  approval_id TEXT NOT NULL REFERENCES policy_approvals(approval_id),
+  stage INTEGER NOT NULL,
+  stage_name TEXT NOT NULL,
+  voter TEXT NOT NULL,
+  vote TEXT NOT NULL CHECK (vote IN ('approve','deny')),
+  reason TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  integrity_sha256 TEXT NOT NULL,
+  UNIQUE (approval_id, voter)
+);
+
+CREATE INDEX IF NOT EXISTS idx_policy_approval_votes_stage ON policy_approval_votes (approval_id, stage);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS policy_approval_delegations;
+ALTER TABLE policy_approval_votes DROP COLUMN IF EXISTS on_behalf_of;
+ALTER TABLE policy_approvals DROP COLUMN IF EXISTS assigned_to;
@@ -0,0 +1,24 @@
+ALTER TABLE policy_approvals
+  ADD COLUMN IF NOT EXISTS assigned_to TEXT;
+
+ALTER TABLE policy_approval_votes
+  ADD COLUMN IF NOT EXISTS on_behalf_of TEXT;
+
+CREATE TABLE IF NOT EXISTS policy_approval_delegations (
+  delegation_id TEXT PRIMARY KEY,
+  delegator TEXT NOT NULL,
+  delegate TEXT NOT NULL,
+  starts_at TIMESTAMPTZ NOT NULL,
+  ends_at TIMESTAMPTZ NOT NULL,
+  reason TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  created_by TEXT NOT NULL,
+  revoked_at TIMESTAMPTZ,
+  revoked_by TEXT,
+  integrity_sha256 TEXT NOT NULL,
+  CHECK (delegator <> delegate),
+  CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_policy_approval_delegations_delegator ON policy_approval_delegations (delegator, ends_at DESC);
+CREATE INDEX IF NOT EXISTS idx_policy_approval_delegations_delegate ON policy_approval_delegations (delegate, ends_at DESC);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_policy_approvals_decided_notifications;
+DROP INDEX IF EXISTS idx_policy_approvals_pending_notifications;
+ALTER TABLE policy_approvals
+  DROP COLUMN IF EXISTS decided_notified_at,
+  DROP COLUMN IF EXISTS reminded_at,
+  DROP COLUMN IF EXISTS requested_notified_at;
@@ -0,0 +1,18 @@
+ALTER TABLE policy_approvals
+  ADD COLUMN IF NOT EXISTS requested_notified_at TIMESTAMPTZ,
+  ADD COLUMN IF NOT EXISTS reminded_at TIMESTAMPTZ,
+  ADD COLUMN IF NOT EXISTS decided_notified_at TIMESTAMPTZ;
+
+-- Approvals that predate notifications are not announced retroactively;
+-- pending ones still get the overdue reminder.
+UPDATE policy_approvals SET requested_notified_at = requested_at WHERE requested_notified_at IS NULL;
+UPDATE policy_approvals
+  SET decided_notified_at = COALESCE(decided_at, requested_at)
+  WHERE status <> 'pending' AND decided_notified_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_policy_approvals_pending_notifications
+  ON policy_approvals (requested_at)
+  WHERE status = 'pending' AND (requested_notified_at IS NULL OR reminded_at IS NULL);
+CREATE INDEX IF NOT EXISTS idx_policy_approvals_decided_notifications
+  ON policy_approvals (decided_at)
+  WHERE status <> 'pending' AND decided_notified_at IS NULL;
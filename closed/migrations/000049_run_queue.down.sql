@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_run_dispatches_active;
+DROP TABLE IF EXISTS run_queue;
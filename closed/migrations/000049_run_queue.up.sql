@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS run_queue (
+  run_id TEXT PRIMARY KEY REFERENCES runs(run_id),
+  project_id TEXT NOT NULL REFERENCES projects(project_id),
+  priority TEXT NOT NULL CHECK (priority IN ('high', 'normal', 'low')),
+  priority_rank INT NOT NULL,
+  idempotency_key TEXT NOT NULL,
+  status TEXT NOT NULL CHECK (status IN ('queued', 'submitted', 'canceled')),
+  enqueued_at TIMESTAMPTZ NOT NULL,
+  enqueued_by TEXT NOT NULL,
+  request_id TEXT,
+  dequeued_at TIMESTAMPTZ,
+  dispatch_id TEXT,
+  integrity_sha256 TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_run_queue_order
+  ON run_queue (priority_rank DESC, enqueued_at)
+  WHERE status = 'queued';
+CREATE INDEX IF NOT EXISTS idx_run_queue_project
+  ON run_queue (project_id, enqueued_at DESC);
+CREATE INDEX IF NOT EXISTS idx_run_dispatches_active
+  ON run_dispatches (project_id)
+  WHERE status IN ('requested', 'accepted', 'running');
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_run_dispatches_deadline;
+ALTER TABLE run_queue DROP COLUMN IF EXISTS max_duration_seconds;
+ALTER TABLE run_dispatches DROP COLUMN IF EXISTS max_duration_seconds;
@@ -0,0 +1,8 @@
+ALTER TABLE run_dispatches
+  ADD COLUMN IF NOT EXISTS max_duration_seconds BIGINT CHECK (max_duration_seconds > 0);
+ALTER TABLE run_queue
+  ADD COLUMN IF NOT EXISTS max_duration_seconds BIGINT CHECK (max_duration_seconds > 0);
+
+CREATE INDEX IF NOT EXISTS idx_run_dispatches_deadline
+  ON run_dispatches (requested_at)
+  WHERE max_duration_seconds IS NOT NULL AND status IN ('requested', 'accepted', 'running');
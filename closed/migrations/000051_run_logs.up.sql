@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS run_logs (
+  log_id BIGSERIAL PRIMARY KEY,
+  event_id TEXT NOT NULL UNIQUE,
+  run_id TEXT NOT NULL,
+  project_id TEXT NOT NULL,
+  pod_name TEXT NOT NULL,
+  container TEXT NOT NULL,
+  content TEXT,
+  object_key TEXT,
+  size_bytes BIGINT NOT NULL,
+  first_line_at TIMESTAMPTZ NOT NULL,
+  last_line_at TIMESTAMPTZ NOT NULL,
+  emitted_at TIMESTAMPTZ NOT NULL,
+  received_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  integrity_sha256 TEXT NOT NULL,
+  CHECK (content IS NOT NULL OR object_key IS NOT NULL)
+);
+
+CREATE INDEX IF NOT EXISTS idx_run_logs_run ON run_logs (run_id, log_id);
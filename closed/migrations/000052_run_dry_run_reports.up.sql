@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS run_dry_run_reports (
+  report_id TEXT PRIMARY KEY,
+  project_id TEXT NOT NULL,
+  run_id TEXT NOT NULL,
+  spec_hash TEXT NOT NULL,
+  pipeline_name TEXT,
+  status TEXT NOT NULL,
+  report JSONB NOT NULL,
+  report_sha256 TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL,
+  created_by TEXT NOT NULL,
+  UNIQUE (project_id, run_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_run_dry_run_reports_baseline ON run_dry_run_reports (project_id, status, created_at DESC);
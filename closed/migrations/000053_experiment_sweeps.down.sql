@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS experiment_sweep_runs;
+DROP TABLE IF EXISTS experiment_sweeps;
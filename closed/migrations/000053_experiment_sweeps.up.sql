@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS experiment_sweeps (
+  sweep_id TEXT PRIMARY KEY,
+  experiment_id TEXT NOT NULL REFERENCES experiments(experiment_id),
+  project_id TEXT NOT NULL,
+  objective_metric TEXT,
+  objective_goal TEXT,
+  spec JSONB NOT NULL,
+  run_count INTEGER NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  created_by TEXT NOT NULL,
+  integrity_sha256 TEXT NOT NULL,
+  CHECK (objective_goal IS NULL OR objective_goal IN ('maximize', 'minimize'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_experiment_sweeps_experiment ON experiment_sweeps (experiment_id, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS experiment_sweep_runs (
+  sweep_id TEXT NOT NULL REFERENCES experiment_sweeps(sweep_id),
+  position INTEGER NOT NULL,
+  run_id TEXT NOT NULL REFERENCES experiment_runs(run_id),
+  params JSONB NOT NULL,
+  reused BOOLEAN NOT NULL DEFAULT false,
+  PRIMARY KEY (sweep_id, position)
+);
+
+CREATE INDEX IF NOT EXISTS idx_experiment_sweep_runs_run ON experiment_sweep_runs (run_id);
@@ -0,0 +1,3 @@
+CREATE INDEX IF NOT EXISTS idx_experiment_run_metric_samples_run_name_step_desc ON experiment_run_metric_samples (run_id, name, step DESC);
+
+DROP INDEX IF EXISTS idx_experiment_run_metric_samples_run_name_step_value;
@@ -0,0 +1,6 @@
+-- Leaderboards read the latest sample of one metric per run; carrying the
+-- value in the index lets that lookup skip the table.
+CREATE INDEX IF NOT EXISTS idx_experiment_run_metric_samples_run_name_step_value
+  ON experiment_run_metric_samples (run_id, name, step DESC) INCLUDE (value);
+
+DROP INDEX IF EXISTS idx_experiment_run_metric_samples_run_name_step_desc;
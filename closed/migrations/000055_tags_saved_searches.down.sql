@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS saved_searches;
+DROP TABLE IF EXISTS resource_tags;
+DROP TABLE IF EXISTS tags;
@@ -0,0 +1,37 @@
+CREATE TABLE IF NOT EXISTS tags (
+  tag_id TEXT PRIMARY KEY,
+  project_id TEXT NOT NULL,
+  name TEXT NOT NULL,
+  description TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  created_by TEXT NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_project_name_unique ON tags (project_id, name);
+
+CREATE TABLE IF NOT EXISTS resource_tags (
+  tag_id TEXT NOT NULL REFERENCES tags(tag_id) ON DELETE CASCADE,
+  project_id TEXT NOT NULL,
+  resource_type TEXT NOT NULL,
+  resource_id TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  created_by TEXT NOT NULL,
+  PRIMARY KEY (tag_id, resource_type, resource_id),
+  CHECK (resource_type IN ('experiment', 'experiment_run', 'dataset', 'model'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_resource_tags_resource ON resource_tags (project_id, resource_type, resource_id);
+
+CREATE TABLE IF NOT EXISTS saved_searches (
+  search_id TEXT PRIMARY KEY,
+  project_id TEXT NOT NULL,
+  name TEXT NOT NULL,
+  resource_type TEXT NOT NULL,
+  query JSONB NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  created_by TEXT NOT NULL,
+  CHECK (resource_type IN ('experiment', 'experiment_run', 'dataset', 'model'))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_saved_searches_project_name_unique ON saved_searches (project_id, name);
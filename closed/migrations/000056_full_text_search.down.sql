@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_audit_events_search;
+DROP INDEX IF EXISTS idx_policies_search;
+DROP INDEX IF EXISTS idx_quality_rules_search;
+DROP INDEX IF EXISTS idx_datasets_search;
+DROP INDEX IF EXISTS idx_experiment_runs_search;
+DROP INDEX IF EXISTS idx_experiments_search;
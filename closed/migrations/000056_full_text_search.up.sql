@@ -0,0 +1,19 @@
+-- Expression indexes for gateway full-text search. The expressions must match
+-- closed/gateway/search.go exactly for the planner to use them.
+CREATE INDEX IF NOT EXISTS idx_experiments_search ON experiments
+  USING GIN (to_tsvector('simple', name || ' ' || coalesce(description, '')));
+
+CREATE INDEX IF NOT EXISTS idx_experiment_runs_search ON experiment_runs
+  USING GIN ((to_tsvector('simple', run_id || ' ' || coalesce(git_repo, '') || ' ' || coalesce(git_ref, '') || ' ' || coalesce(git_commit, '')) || jsonb_to_tsvector('simple', params, '["string"]')));
+
+CREATE INDEX IF NOT EXISTS idx_datasets_search ON datasets
+  USING GIN (to_tsvector('simple', name || ' ' || coalesce(description, '')));
+
+CREATE INDEX IF NOT EXISTS idx_quality_rules_search ON quality_rules
+  USING GIN (to_tsvector('simple', name || ' ' || coalesce(description, '')));
+
+CREATE INDEX IF NOT EXISTS idx_policies_search ON policies
+  USING GIN (to_tsvector('simple', name || ' ' || coalesce(description, '')));
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_search ON audit_events
+  USING GIN ((to_tsvector('simple', action || ' ' || resource_type || ' ' || resource_id) || jsonb_to_tsvector('simple', payload, '["string"]')));
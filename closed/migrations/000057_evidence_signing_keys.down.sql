@@ -0,0 +1,4 @@
+ALTER TABLE experiment_run_evidence_bundles
+  DROP COLUMN IF EXISTS signing_key_id;
+
+DROP TABLE IF EXISTS evidence_signing_keys;
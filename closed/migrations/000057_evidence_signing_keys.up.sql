@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS evidence_signing_keys (
+  key_id TEXT PRIMARY KEY,
+  algorithm TEXT NOT NULL,
+  public_key_pem TEXT NOT NULL,
+  certificate_chain_pem TEXT,
+  certificate_sha256 TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  last_registered_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+ALTER TABLE experiment_run_evidence_bundles
+  ADD COLUMN IF NOT EXISTS signing_key_id TEXT REFERENCES evidence_signing_keys(key_id);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS ci_report_checks;
+DROP TABLE IF EXISTS ci_reports;
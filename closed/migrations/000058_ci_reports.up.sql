@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS ci_reports (
+  report_id TEXT PRIMARY KEY,
+  schema_version TEXT NOT NULL,
+  repo TEXT NOT NULL,
+  commit_sha TEXT NOT NULL,
+  image_digest TEXT,
+  pipeline_id TEXT NOT NULL,
+  provider TEXT,
+  status TEXT NOT NULL,
+  received_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  received_by TEXT NOT NULL,
+  signature_ts BIGINT NOT NULL,
+  signature TEXT NOT NULL,
+  payload JSONB NOT NULL,
+  payload_sha256 TEXT NOT NULL,
+  integrity_sha256 TEXT NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ci_reports_pipeline_unique ON ci_reports (repo, commit_sha, pipeline_id);
+CREATE INDEX IF NOT EXISTS idx_ci_reports_commit_sha ON ci_reports (commit_sha, received_at DESC);
+CREATE INDEX IF NOT EXISTS idx_ci_reports_image_digest ON ci_reports (image_digest, received_at DESC) WHERE image_digest IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS ci_report_checks (
+  report_id TEXT NOT NULL REFERENCES ci_reports(report_id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  kind TEXT NOT NULL,
+  tool TEXT NOT NULL,
+  status TEXT NOT NULL,
+  summary JSONB NOT NULL DEFAULT '{}'::jsonb,
+  url TEXT,
+  PRIMARY KEY (report_id, name)
+);
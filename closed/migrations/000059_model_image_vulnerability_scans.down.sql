@@ -0,0 +1 @@
+DROP TABLE IF EXISTS model_image_vulnerability_scans;
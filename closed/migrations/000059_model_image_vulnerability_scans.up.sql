@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS model_image_vulnerability_scans (
+  scan_id TEXT PRIMARY KEY,
+  image_digest TEXT NOT NULL,
+  scanner TEXT NOT NULL,
+  scanner_version TEXT,
+  source TEXT NOT NULL,
+  critical_count INTEGER NOT NULL,
+  high_count INTEGER NOT NULL,
+  medium_count INTEGER NOT NULL,
+  low_count INTEGER NOT NULL,
+  unknown_count INTEGER NOT NULL,
+  findings JSONB NOT NULL DEFAULT '[]'::jsonb,
+  report_sha256 TEXT NOT NULL,
+  scanned_at TIMESTAMPTZ NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  created_by TEXT NOT NULL,
+  integrity_sha256 TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_model_image_vulnerability_scans_digest ON model_image_vulnerability_scans (image_digest, scanned_at DESC);
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS project_settings;
+
+ALTER TABLE projects
+  DROP COLUMN IF EXISTS archived_by,
+  DROP COLUMN IF EXISTS archived_at,
+  DROP COLUMN IF EXISTS updated_by,
+  DROP COLUMN IF EXISTS updated_at;
@@ -0,0 +1,14 @@
+ALTER TABLE projects
+  ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ,
+  ADD COLUMN IF NOT EXISTS updated_by TEXT,
+  ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ,
+  ADD COLUMN IF NOT EXISTS archived_by TEXT;
+
+CREATE TABLE IF NOT EXISTS project_settings (
+  project_id TEXT PRIMARY KEY REFERENCES projects(project_id),
+  default_quality_rule_id TEXT REFERENCES quality_rules(rule_id),
+  default_policy_ids JSONB NOT NULL DEFAULT '[]'::jsonb,
+  artifact_retention_days INTEGER CHECK (artifact_retention_days IS NULL OR artifact_retention_days > 0),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_by TEXT NOT NULL
+);
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS policy_bindings (
+  binding_id TEXT PRIMARY KEY,
+  policy_id TEXT NOT NULL REFERENCES policies(policy_id),
+  policy_version_id TEXT REFERENCES policy_versions(policy_version_id),
+  scope_type TEXT NOT NULL CHECK (scope_type IN ('project', 'experiment', 'dataset_class')),
+  scope_id TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  created_by TEXT NOT NULL,
+  integrity_sha256 TEXT NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_policy_bindings_scope_unique
+  ON policy_bindings (policy_id, scope_type, scope_id);
+CREATE INDEX IF NOT EXISTS idx_policy_bindings_scope
+  ON policy_bindings (scope_type, scope_id);
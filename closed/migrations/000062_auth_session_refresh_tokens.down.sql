@@ -0,0 +1,6 @@
+ALTER TABLE auth_sessions
+  DROP COLUMN IF EXISTS refreshed_at,
+  DROP COLUMN IF EXISTS refresh_token_wrapped_key,
+  DROP COLUMN IF EXISTS refresh_token_key_id,
+  DROP COLUMN IF EXISTS refresh_token_alg,
+  DROP COLUMN IF EXISTS refresh_token_ciphertext;
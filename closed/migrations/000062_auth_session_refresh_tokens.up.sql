@@ -0,0 +1,6 @@
+ALTER TABLE auth_sessions
+  ADD COLUMN IF NOT EXISTS refresh_token_ciphertext BYTEA,
+  ADD COLUMN IF NOT EXISTS refresh_token_alg TEXT,
+  ADD COLUMN IF NOT EXISTS refresh_token_key_id TEXT,
+  ADD COLUMN IF NOT EXISTS refresh_token_wrapped_key TEXT,
+  ADD COLUMN IF NOT EXISTS refreshed_at TIMESTAMPTZ;
@@ -0,0 +1,3 @@
+CREATE INDEX IF NOT EXISTS idx_audit_events_payload ON audit_events USING GIN (payload jsonb_path_ops);
+CREATE INDEX IF NOT EXISTS idx_audit_events_actor ON audit_events (actor, event_id DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_events_action ON audit_events (action text_pattern_ops, event_id DESC);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_audit_events_ip;
+DROP TABLE IF EXISTS audit_alert_cursor;
+DROP TABLE IF EXISTS audit_alerts;
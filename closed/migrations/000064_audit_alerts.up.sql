@@ -0,0 +1,34 @@
+CREATE TABLE IF NOT EXISTS audit_alerts (
+  alert_id TEXT PRIMARY KEY,
+  rule TEXT NOT NULL,
+  severity TEXT NOT NULL,
+  group_key TEXT NOT NULL,
+  project_id TEXT NOT NULL DEFAULT '',
+  status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'acknowledged')),
+  event_count INTEGER NOT NULL,
+  first_event_id BIGINT NOT NULL,
+  last_event_id BIGINT NOT NULL,
+  first_seen_at TIMESTAMPTZ NOT NULL,
+  last_seen_at TIMESTAMPTZ NOT NULL,
+  details JSONB NOT NULL DEFAULT '{}'::jsonb,
+  created_at TIMESTAMPTZ NOT NULL,
+  acknowledged_at TIMESTAMPTZ,
+  acknowledged_by TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_alerts_created ON audit_alerts (created_at DESC, alert_id DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_alerts_rule_key ON audit_alerts (rule, group_key, last_seen_at DESC);
+
+-- The rules engine tails audit_events from this cursor. It starts at the
+-- current end of the log, so history is not alerted on retroactively.
+CREATE TABLE IF NOT EXISTS audit_alert_cursor (
+  id SMALLINT PRIMARY KEY CHECK (id = 1),
+  last_event_id BIGINT NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL
+);
+
+INSERT INTO audit_alert_cursor (id, last_event_id, updated_at)
+SELECT 1, COALESCE(MAX(event_id), 0), now() FROM audit_events
+ON CONFLICT (id) DO NOTHING;
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_ip ON audit_events (ip, occurred_at DESC) WHERE ip IS NOT NULL;
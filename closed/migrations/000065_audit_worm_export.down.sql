@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS audit_worm_state;
+DROP TABLE IF EXISTS audit_worm_batches;
+DROP TABLE IF EXISTS audit_worm_signing_keys;
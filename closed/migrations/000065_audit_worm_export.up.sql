@@ -0,0 +1,39 @@
+CREATE TABLE IF NOT EXISTS audit_worm_signing_keys (
+  key_id TEXT PRIMARY KEY,
+  algorithm TEXT NOT NULL,
+  public_key_pem TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Each batch is one object holding consecutive audit events. chain_sha256
+-- covers the batch content and the previous batch's chain hash, so removing or
+-- rewriting a batch breaks every later link.
+CREATE TABLE IF NOT EXISTS audit_worm_batches (
+  seq BIGINT PRIMARY KEY,
+  first_event_id BIGINT NOT NULL,
+  last_event_id BIGINT NOT NULL,
+  event_count INTEGER NOT NULL,
+  bucket TEXT NOT NULL,
+  object_key TEXT NOT NULL,
+  version_id TEXT NOT NULL DEFAULT '',
+  content_sha256 TEXT NOT NULL,
+  prev_chain_sha256 TEXT NOT NULL,
+  chain_sha256 TEXT NOT NULL,
+  signature TEXT NOT NULL,
+  key_id TEXT NOT NULL REFERENCES audit_worm_signing_keys (key_id),
+  object_locked BOOLEAN NOT NULL,
+  retain_until TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS audit_worm_state (
+  id SMALLINT PRIMARY KEY CHECK (id = 1),
+  last_seq BIGINT NOT NULL,
+  last_event_id BIGINT NOT NULL,
+  chain_sha256 TEXT NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL
+);
+
+INSERT INTO audit_worm_state (id, last_seq, last_event_id, chain_sha256, updated_at)
+VALUES (1, 0, 0, '', now())
+ON CONFLICT (id) DO NOTHING;
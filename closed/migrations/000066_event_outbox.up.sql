@@ -0,0 +1,20 @@
+-- Audit and lineage events written with the business change that caused them
+-- and copied to audit_events / lineage_events by the outbox relay. record is
+-- the prepared row, integrity hash included, so a retry inserts the same
+-- event; dedupe_key collapses repeated enqueues of one event.
+CREATE TABLE IF NOT EXISTS event_outbox (
+  outbox_id BIGSERIAL PRIMARY KEY,
+  kind TEXT NOT NULL CHECK (kind IN ('audit', 'lineage')),
+  dedupe_key TEXT NOT NULL,
+  record JSONB NOT NULL,
+  enqueued_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  attempts INTEGER NOT NULL DEFAULT 0,
+  next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  last_error TEXT,
+  delivered_at TIMESTAMPTZ,
+  event_id BIGINT
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_event_outbox_dedupe ON event_outbox (kind, dedupe_key);
+CREATE INDEX IF NOT EXISTS idx_event_outbox_pending ON event_outbox (next_attempt_at, outbox_id) WHERE delivered_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_event_outbox_delivered ON event_outbox (delivered_at) WHERE delivered_at IS NOT NULL;
@@ -0,0 +1,3 @@
+DELETE FROM event_outbox WHERE kind = 'bus';
+ALTER TABLE event_outbox DROP CONSTRAINT IF EXISTS event_outbox_kind_check;
+ALTER TABLE event_outbox ADD CONSTRAINT event_outbox_kind_check CHECK (kind IN ('audit', 'lineage'));
@@ -0,0 +1,4 @@
+-- Message bus events share the outbox with audit and lineage events; the
+-- relay publishes them to NATS or Kafka instead of inserting a row.
+ALTER TABLE event_outbox DROP CONSTRAINT IF EXISTS event_outbox_kind_check;
+ALTER TABLE event_outbox ADD CONSTRAINT event_outbox_kind_check CHECK (kind IN ('audit', 'lineage', 'bus'));
@@ -0,0 +1,15 @@
+-- Runs executed by remote agents instead of the data plane. labels are the
+-- labels an agent must carry to claim the run; agent_id and claimed_at are
+-- set once, by the claim that moves the dispatch to accepted.
+CREATE TABLE IF NOT EXISTS run_agent_assignments (
+  run_id TEXT PRIMARY KEY REFERENCES runs(run_id),
+  project_id TEXT NOT NULL,
+  labels JSONB NOT NULL DEFAULT '[]'::jsonb,
+  requested_at TIMESTAMPTZ NOT NULL,
+  requested_by TEXT NOT NULL,
+  agent_id TEXT,
+  claimed_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_run_agent_assignments_unclaimed
+  ON run_agent_assignments (requested_at) WHERE agent_id IS NULL;
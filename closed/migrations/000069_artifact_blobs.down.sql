@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_experiment_run_artifacts_blob_sha256;
+ALTER TABLE experiment_run_artifacts DROP COLUMN IF EXISTS blob_sha256;
+DROP TABLE IF EXISTS artifact_blobs;
@@ -0,0 +1,23 @@
+-- Content-addressed artifact bodies. Each blob is stored once under its
+-- SHA-256; ref_count is the number of experiment_run_artifacts rows that
+-- reference it, and blobs at zero for longer than the GC grace period are
+-- removed together with their object.
+CREATE TABLE IF NOT EXISTS artifact_blobs (
+  sha256 TEXT PRIMARY KEY,
+  object_key TEXT NOT NULL,
+  size_bytes BIGINT NOT NULL,
+  ref_count BIGINT NOT NULL DEFAULT 0 CHECK (ref_count >= 0),
+  created_at TIMESTAMPTZ NOT NULL,
+  last_referenced_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_artifact_blobs_unreferenced
+  ON artifact_blobs (last_referenced_at) WHERE ref_count = 0;
+
+-- Artifacts uploaded before content addressing keep a NULL blob_sha256 and
+-- their per-artifact object_key.
+ALTER TABLE experiment_run_artifacts
+  ADD COLUMN IF NOT EXISTS blob_sha256 TEXT REFERENCES artifact_blobs(sha256);
+
+CREATE INDEX IF NOT EXISTS idx_experiment_run_artifacts_blob_sha256
+  ON experiment_run_artifacts (blob_sha256) WHERE blob_sha256 IS NOT NULL;
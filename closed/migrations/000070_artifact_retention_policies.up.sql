@@ -0,0 +1,21 @@
+-- Artifact retention rules of a project or of one experiment; an
+-- experiment policy replaces the project policy for that experiment. Runs
+-- outside every keep rule lose their artifacts; runs referenced by a model
+-- version or an evidence bundle are always kept.
+CREATE TABLE IF NOT EXISTS artifact_retention_policies (
+  scope_type TEXT NOT NULL CHECK (scope_type IN ('project', 'experiment')),
+  scope_id TEXT NOT NULL,
+  project_id TEXT NOT NULL REFERENCES projects(project_id),
+  keep_last_runs INTEGER CHECK (keep_last_runs IS NULL OR keep_last_runs >= 0),
+  keep_best_metric TEXT,
+  keep_best_order TEXT CHECK (keep_best_order IS NULL OR keep_best_order IN ('asc', 'desc')),
+  keep_best_runs INTEGER CHECK (keep_best_runs IS NULL OR keep_best_runs > 0),
+  updated_at TIMESTAMPTZ NOT NULL,
+  updated_by TEXT NOT NULL,
+  PRIMARY KEY (scope_type, scope_id),
+  CHECK (keep_last_runs IS NOT NULL OR keep_best_metric IS NOT NULL),
+  CHECK ((keep_best_metric IS NULL) = (keep_best_runs IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_artifact_retention_policies_project
+  ON artifact_retention_policies (project_id);
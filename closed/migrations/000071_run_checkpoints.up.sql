@@ -0,0 +1,13 @@
+-- Checkpoint a run resumes from. The artifact cannot be deleted while a run
+-- resumes from it, so artifact retention skips it.
+CREATE TABLE IF NOT EXISTS run_checkpoints (
+  run_id TEXT PRIMARY KEY REFERENCES runs(run_id),
+  project_id TEXT NOT NULL,
+  source_run_id TEXT NOT NULL REFERENCES experiment_runs(run_id),
+  artifact_id TEXT NOT NULL REFERENCES experiment_run_artifacts(artifact_id),
+  sha256 TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL,
+  created_by TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_run_checkpoints_artifact_id ON run_checkpoints (artifact_id);
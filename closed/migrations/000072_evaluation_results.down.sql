@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS evaluation_result_samples;
+DROP TABLE IF EXISTS evaluation_results;
+DROP TABLE IF EXISTS evaluation_suites;
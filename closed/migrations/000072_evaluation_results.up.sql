@@ -0,0 +1,42 @@
+-- Evaluation suites define the metrics of an evaluation; results follow the
+-- animus.eval.result.v1 schema and link an experiment run and optionally a
+-- model version to aggregate and per-sample metrics.
+CREATE TABLE IF NOT EXISTS evaluation_suites (
+  suite_id TEXT PRIMARY KEY,
+  project_id TEXT NOT NULL REFERENCES projects(project_id),
+  name TEXT NOT NULL,
+  description TEXT NOT NULL DEFAULT '',
+  metrics JSONB NOT NULL DEFAULT '[]'::jsonb,
+  created_at TIMESTAMPTZ NOT NULL,
+  created_by TEXT NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_evaluation_suites_project_name ON evaluation_suites (project_id, name);
+
+CREATE TABLE IF NOT EXISTS evaluation_results (
+  result_id TEXT PRIMARY KEY,
+  suite_id TEXT NOT NULL REFERENCES evaluation_suites(suite_id),
+  project_id TEXT NOT NULL,
+  schema_version TEXT NOT NULL,
+  run_id TEXT NOT NULL REFERENCES experiment_runs(run_id),
+  model_version_id TEXT REFERENCES model_versions(model_version_id),
+  evaluation_id TEXT REFERENCES experiment_run_evaluations(evaluation_id),
+  metrics JSONB NOT NULL DEFAULT '{}'::jsonb,
+  sample_count INTEGER NOT NULL DEFAULT 0,
+  created_at TIMESTAMPTZ NOT NULL,
+  created_by TEXT NOT NULL,
+  integrity_sha256 TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_evaluation_results_suite_created ON evaluation_results (suite_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_evaluation_results_model_version ON evaluation_results (model_version_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_evaluation_results_run_id ON evaluation_results (run_id);
+
+CREATE TABLE IF NOT EXISTS evaluation_result_samples (
+  result_id TEXT NOT NULL REFERENCES evaluation_results(result_id),
+  sample_id TEXT NOT NULL,
+  metrics JSONB NOT NULL DEFAULT '{}'::jsonb,
+  passed BOOLEAN,
+  created_at TIMESTAMPTZ NOT NULL,
+  PRIMARY KEY (result_id, sample_id)
+);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS evaluation_preview_samples;
@@ -0,0 +1,18 @@
+-- Preview inputs and outputs of an evaluation, stored redacted in the object
+-- store for qualitative review. ordinal orders the previews of an evaluation.
+CREATE TABLE IF NOT EXISTS evaluation_preview_samples (
+  preview_id TEXT PRIMARY KEY,
+  evaluation_id TEXT NOT NULL REFERENCES experiment_run_evaluations(evaluation_id),
+  run_id TEXT NOT NULL REFERENCES experiment_runs(run_id),
+  sample_id TEXT NOT NULL,
+  ordinal INTEGER NOT NULL,
+  object_key TEXT NOT NULL,
+  sha256 TEXT NOT NULL,
+  size_bytes BIGINT NOT NULL,
+  redacted_paths JSONB NOT NULL DEFAULT '[]'::jsonb,
+  created_at TIMESTAMPTZ NOT NULL,
+  created_by TEXT NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_evaluation_preview_samples_sample ON evaluation_preview_samples (evaluation_id, sample_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_evaluation_preview_samples_ordinal ON evaluation_preview_samples (evaluation_id, ordinal);
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS dataset_drift_reports (
+  report_id TEXT PRIMARY KEY,
+  project_id TEXT NOT NULL REFERENCES projects(project_id),
+  dataset_id TEXT NOT NULL REFERENCES datasets(dataset_id),
+  version_id TEXT NOT NULL REFERENCES dataset_versions(version_id),
+  baseline_version_id TEXT NOT NULL REFERENCES dataset_versions(version_id),
+  baseline_model_version_id TEXT REFERENCES model_versions(model_version_id),
+  score DOUBLE PRECISION NOT NULL CHECK (score >= 0),
+  threshold DOUBLE PRECISION NOT NULL CHECK (threshold > 0),
+  status TEXT NOT NULL CHECK (status IN ('stable', 'drifted')),
+  report JSONB NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  created_by TEXT NOT NULL,
+  integrity_sha256 TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_dataset_drift_reports_version ON dataset_drift_reports (version_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_dataset_drift_reports_dataset ON dataset_drift_reports (dataset_id, created_at DESC);
@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS model_deployments (
+  deployment_id TEXT PRIMARY KEY,
+  project_id TEXT NOT NULL REFERENCES projects(project_id),
+  model_id TEXT NOT NULL REFERENCES models(model_id),
+  model_version_id TEXT NOT NULL REFERENCES model_versions(model_version_id),
+  run_id TEXT NOT NULL,
+  environment TEXT NOT NULL,
+  endpoint_url TEXT NOT NULL,
+  replicas INTEGER NOT NULL CHECK (replicas >= 0),
+  replica_info JSONB NOT NULL DEFAULT '{}'::jsonb,
+  status TEXT NOT NULL CHECK (status IN ('active', 'superseded')),
+  deployed_at TIMESTAMPTZ NOT NULL,
+  deployed_by TEXT NOT NULL,
+  superseded_at TIMESTAMPTZ,
+  superseded_by TEXT REFERENCES model_deployments(deployment_id) DEFERRABLE INITIALLY DEFERRED,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  created_by TEXT NOT NULL,
+  integrity_sha256 TEXT NOT NULL,
+  CHECK ((status = 'superseded') = (superseded_at IS NOT NULL))
+);
+
+-- One active deployment per endpoint: registering a new one supersedes it.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_model_deployments_active_endpoint
+  ON model_deployments (project_id, environment, endpoint_url)
+  WHERE status = 'active';
+CREATE INDEX IF NOT EXISTS idx_model_deployments_project_deployed_at ON model_deployments (project_id, deployed_at DESC);
+CREATE INDEX IF NOT EXISTS idx_model_deployments_model_version ON model_deployments (model_version_id, deployed_at DESC);
+CREATE INDEX IF NOT EXISTS idx_model_deployments_run ON model_deployments (run_id);
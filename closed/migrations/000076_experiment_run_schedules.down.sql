@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS experiment_run_schedule_firings;
+DROP TABLE IF EXISTS experiment_run_schedules;
@@ -0,0 +1,38 @@
+CREATE TABLE IF NOT EXISTS experiment_run_schedules (
+  schedule_id TEXT PRIMARY KEY,
+  project_id TEXT NOT NULL REFERENCES projects(project_id),
+  experiment_id TEXT NOT NULL REFERENCES experiments(experiment_id),
+  name TEXT NOT NULL,
+  cron_expr TEXT NOT NULL,
+  template JSONB NOT NULL,
+  state TEXT NOT NULL CHECK (state IN ('active', 'paused')),
+  next_run_at TIMESTAMPTZ,
+  last_run_at TIMESTAMPTZ,
+  last_run_id TEXT REFERENCES experiment_runs(run_id),
+  last_status TEXT CHECK (last_status IS NULL OR last_status IN ('created', 'blocked', 'failed')),
+  last_error TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  created_by TEXT NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_by TEXT NOT NULL,
+  integrity_sha256 TEXT NOT NULL,
+  CHECK (state <> 'active' OR next_run_at IS NOT NULL)
+);
+
+CREATE INDEX IF NOT EXISTS idx_experiment_run_schedules_experiment ON experiment_run_schedules (experiment_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_experiment_run_schedules_due ON experiment_run_schedules (next_run_at) WHERE state = 'active';
+
+CREATE TABLE IF NOT EXISTS experiment_run_schedule_firings (
+  firing_id TEXT PRIMARY KEY,
+  schedule_id TEXT NOT NULL REFERENCES experiment_run_schedules(schedule_id),
+  scheduled_for TIMESTAMPTZ NOT NULL,
+  fired_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  status TEXT NOT NULL CHECK (status IN ('created', 'blocked', 'failed')),
+  run_id TEXT REFERENCES experiment_runs(run_id),
+  dataset_version_id TEXT,
+  error_code TEXT,
+  UNIQUE (schedule_id, scheduled_for),
+  CHECK ((status = 'created') = (run_id IS NOT NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_experiment_run_schedule_firings_schedule ON experiment_run_schedule_firings (schedule_id, scheduled_for DESC);
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS run_step_attempts (
+  attempt_id TEXT PRIMARY KEY,
+  project_id TEXT NOT NULL,
+  run_id TEXT NOT NULL,
+  dispatch_id TEXT NOT NULL REFERENCES run_dispatches(dispatch_id),
+  step_name TEXT NOT NULL,
+  attempt INTEGER NOT NULL CHECK (attempt >= 1),
+  status TEXT NOT NULL CHECK (status IN ('pending', 'dispatched', 'running', 'succeeded', 'failed')),
+  not_before TIMESTAMPTZ NOT NULL,
+  inputs JSONB NOT NULL DEFAULT '[]'::jsonb,
+  job_name TEXT,
+  dispatched_at TIMESTAMPTZ,
+  heartbeat_at TIMESTAMPTZ,
+  finished_at TIMESTAMPTZ,
+  reason TEXT,
+  exit_code INTEGER,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (run_id, step_name, attempt),
+  CHECK (status NOT IN ('succeeded', 'failed') OR finished_at IS NOT NULL)
+);
+
+CREATE INDEX IF NOT EXISTS idx_run_step_attempts_run ON run_step_attempts (project_id, run_id, step_name, attempt);
+CREATE INDEX IF NOT EXISTS idx_run_step_attempts_active ON run_step_attempts (not_before) WHERE status IN ('pending', 'dispatched', 'running');
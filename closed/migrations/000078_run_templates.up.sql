@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS run_templates (
+  template_id TEXT PRIMARY KEY,
+  project_id TEXT NOT NULL REFERENCES projects(project_id),
+  name TEXT NOT NULL,
+  version INTEGER NOT NULL CHECK (version >= 1),
+  description TEXT,
+  template JSONB NOT NULL,
+  params_schema JSONB NOT NULL DEFAULT '{}'::jsonb,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  created_by TEXT NOT NULL,
+  integrity_sha256 TEXT NOT NULL,
+  UNIQUE (project_id, name, version)
+);
+
+CREATE INDEX IF NOT EXISTS idx_run_templates_project ON run_templates (project_id, name, version DESC);
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS experiment_run_config_manifests (
+  run_id TEXT PRIMARY KEY REFERENCES experiment_runs(run_id),
+  manifest TEXT NOT NULL,
+  manifest_sha256 TEXT NOT NULL,
+  signature TEXT NOT NULL,
+  signature_alg TEXT NOT NULL,
+  signing_key_id TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'trg_experiment_run_config_manifests_immutable') THEN
+    CREATE TRIGGER trg_experiment_run_config_manifests_immutable
+      BEFORE UPDATE OR DELETE ON experiment_run_config_manifests
+      FOR EACH ROW EXECUTE FUNCTION prevent_update_delete();
+  END IF;
+END $$;
@@ -0,0 +1,17 @@
+-- One row per imported experiment. summary is the import response; the
+-- archive itself is kept at archive_object_key.
+CREATE TABLE IF NOT EXISTS experiment_imports (
+  import_id TEXT PRIMARY KEY,
+  project_id TEXT NOT NULL REFERENCES projects(project_id),
+  experiment_id TEXT NOT NULL UNIQUE REFERENCES experiments(experiment_id),
+  source_experiment_id TEXT NOT NULL,
+  source_project_id TEXT,
+  manifest_sha256 TEXT NOT NULL,
+  archive_object_key TEXT NOT NULL,
+  summary JSONB NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  created_by TEXT NOT NULL,
+  integrity_sha256 TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_experiment_imports_source ON experiment_imports (source_experiment_id);
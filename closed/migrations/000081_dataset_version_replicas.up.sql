@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS dataset_version_replicas (
+  replica_id TEXT PRIMARY KEY,
+  version_id TEXT NOT NULL UNIQUE REFERENCES dataset_versions(version_id),
+  dataset_id TEXT NOT NULL REFERENCES datasets(dataset_id),
+  project_id TEXT NOT NULL REFERENCES projects(project_id),
+  source_instance_id TEXT NOT NULL,
+  source_version_id TEXT NOT NULL,
+  manifest TEXT NOT NULL,
+  manifest_sha256 TEXT NOT NULL,
+  signature TEXT NOT NULL,
+  signature_alg TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  created_by TEXT NOT NULL,
+  integrity_sha256 TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_dataset_version_replicas_source
+  ON dataset_version_replicas (source_instance_id, source_version_id);
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'trg_dataset_version_replicas_immutable') THEN
+    CREATE TRIGGER trg_dataset_version_replicas_immutable
+      BEFORE UPDATE OR DELETE ON dataset_version_replicas
+      FOR EACH ROW EXECUTE FUNCTION prevent_update_delete();
+  END IF;
+END $$;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS experiment_run_key_tags;
+DROP TABLE IF EXISTS experiment_run_logged_params;
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS experiment_run_logged_params (
+  run_id TEXT NOT NULL REFERENCES experiment_runs(run_id),
+  key TEXT NOT NULL,
+  value TEXT NOT NULL,
+  recorded_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  recorded_by TEXT NOT NULL,
+  integrity_sha256 TEXT NOT NULL,
+  PRIMARY KEY (run_id, key)
+);
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'trg_experiment_run_logged_params_immutable') THEN
+    CREATE TRIGGER trg_experiment_run_logged_params_immutable
+      BEFORE UPDATE OR DELETE ON experiment_run_logged_params
+      FOR EACH ROW EXECUTE FUNCTION prevent_update_delete();
+  END IF;
+END $$;
+
+CREATE TABLE IF NOT EXISTS experiment_run_key_tags (
+  run_id TEXT NOT NULL REFERENCES experiment_runs(run_id),
+  key TEXT NOT NULL,
+  value TEXT NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_by TEXT NOT NULL,
+  PRIMARY KEY (run_id, key)
+);
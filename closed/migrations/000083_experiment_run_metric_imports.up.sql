@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS experiment_run_metric_imports (
+  import_id TEXT PRIMARY KEY,
+  run_id TEXT NOT NULL REFERENCES experiment_runs(run_id),
+  format TEXT NOT NULL CHECK (format IN ('tensorboard', 'wandb')),
+  filename TEXT,
+  object_key TEXT NOT NULL,
+  sha256 TEXT NOT NULL,
+  size_bytes BIGINT NOT NULL,
+  status TEXT NOT NULL CHECK (status IN ('queued', 'running', 'succeeded', 'failed')),
+  bytes_processed BIGINT NOT NULL DEFAULT 0,
+  samples_read BIGINT NOT NULL DEFAULT 0,
+  samples_inserted BIGINT NOT NULL DEFAULT 0,
+  samples_duplicate BIGINT NOT NULL DEFAULT 0,
+  samples_invalid BIGINT NOT NULL DEFAULT 0,
+  error TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  created_by TEXT NOT NULL,
+  started_at TIMESTAMPTZ,
+  finished_at TIMESTAMPTZ,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_experiment_run_metric_imports_run_created
+  ON experiment_run_metric_imports (run_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_experiment_run_metric_imports_pending
+  ON experiment_run_metric_imports (created_at)
+  WHERE status IN ('queued', 'running');
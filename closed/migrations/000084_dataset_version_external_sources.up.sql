@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS dataset_version_external_sources (
+  source_id TEXT PRIMARY KEY,
+  version_id TEXT NOT NULL UNIQUE REFERENCES dataset_versions(version_id),
+  dataset_id TEXT NOT NULL REFERENCES datasets(dataset_id),
+  project_id TEXT NOT NULL REFERENCES projects(project_id),
+  kind TEXT NOT NULL CHECK (kind IN ('dvc', 'git-lfs')),
+  source_url TEXT NOT NULL,
+  revision TEXT NOT NULL,
+  path TEXT NOT NULL DEFAULT '',
+  remote_url TEXT NOT NULL,
+  pointer TEXT NOT NULL,
+  digest_alg TEXT NOT NULL,
+  digest TEXT NOT NULL,
+  size_bytes BIGINT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  created_by TEXT NOT NULL,
+  integrity_sha256 TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_dataset_version_external_sources_digest
+  ON dataset_version_external_sources (digest_alg, digest);
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'trg_dataset_version_external_sources_immutable') THEN
+    CREATE TRIGGER trg_dataset_version_external_sources_immutable
+      BEFORE UPDATE OR DELETE ON dataset_version_external_sources
+      FOR EACH ROW EXECUTE FUNCTION prevent_update_delete();
+  END IF;
+END $$;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS dataset_version_reference_status;
+DROP TABLE IF EXISTS dataset_version_references;
@@ -0,0 +1,43 @@
+CREATE TABLE IF NOT EXISTS dataset_version_references (
+  reference_id TEXT PRIMARY KEY,
+  version_id TEXT NOT NULL UNIQUE REFERENCES dataset_versions(version_id),
+  dataset_id TEXT NOT NULL REFERENCES datasets(dataset_id),
+  project_id TEXT NOT NULL REFERENCES projects(project_id),
+  uri TEXT NOT NULL,
+  bucket TEXT NOT NULL,
+  object_key TEXT NOT NULL,
+  etag TEXT NOT NULL,
+  store_version_id TEXT NOT NULL DEFAULT '',
+  size_bytes BIGINT NOT NULL,
+  last_modified TIMESTAMPTZ NOT NULL,
+  content_sha256 TEXT NOT NULL,
+  checksum_source TEXT NOT NULL CHECK (checksum_source IN ('object_checksum', 'object_metadata', 'declared')),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  created_by TEXT NOT NULL,
+  integrity_sha256 TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_dataset_version_references_object
+  ON dataset_version_references (bucket, object_key);
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'trg_dataset_version_references_immutable') THEN
+    CREATE TRIGGER trg_dataset_version_references_immutable
+      BEFORE UPDATE OR DELETE ON dataset_version_references
+      FOR EACH ROW EXECUTE FUNCTION prevent_update_delete();
+  END IF;
+END $$;
+
+-- Result of the latest re-verification; changed and missing are final.
+CREATE TABLE IF NOT EXISTS dataset_version_reference_status (
+  version_id TEXT PRIMARY KEY REFERENCES dataset_version_references(version_id),
+  state TEXT NOT NULL CHECK (state IN ('verified', 'changed', 'missing')),
+  checked_at TIMESTAMPTZ NOT NULL,
+  changed_at TIMESTAMPTZ,
+  detail JSONB
+);
+
+CREATE INDEX IF NOT EXISTS idx_dataset_version_reference_status_due
+  ON dataset_version_reference_status (checked_at)
+  WHERE state = 'verified';
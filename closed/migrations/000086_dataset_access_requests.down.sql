@@ -0,0 +1,8 @@
+ALTER TABLE dataset_version_shares
+  DROP COLUMN IF EXISTS access_bypass,
+  DROP COLUMN IF EXISTS access_grant_id;
+
+DROP TABLE IF EXISTS dataset_access_grants;
+DROP TABLE IF EXISTS dataset_access_request_votes;
+DROP TABLE IF EXISTS dataset_access_requests;
+DROP TABLE IF EXISTS dataset_access_policies;
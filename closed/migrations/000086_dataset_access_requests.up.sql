@@ -0,0 +1,79 @@
+-- Access policy of a dataset. Restricted datasets are readable only by admins
+-- and by subjects holding an active grant.
+CREATE TABLE IF NOT EXISTS dataset_access_policies (
+  dataset_id TEXT PRIMARY KEY REFERENCES datasets(dataset_id),
+  project_id TEXT NOT NULL REFERENCES projects(project_id),
+  restricted BOOLEAN NOT NULL DEFAULT false,
+  workflow JSONB,
+  max_duration_seconds BIGINT NOT NULL CHECK (max_duration_seconds > 0),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_by TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS dataset_access_requests (
+  request_id TEXT PRIMARY KEY,
+  dataset_id TEXT NOT NULL REFERENCES datasets(dataset_id),
+  project_id TEXT NOT NULL REFERENCES projects(project_id),
+  requested_by TEXT NOT NULL,
+  justification TEXT NOT NULL,
+  duration_seconds BIGINT NOT NULL CHECK (duration_seconds > 0),
+  workflow JSONB NOT NULL,
+  current_stage INTEGER NOT NULL DEFAULT 0,
+  status TEXT NOT NULL CHECK (status IN ('pending', 'approved', 'rejected')),
+  requested_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  decided_at TIMESTAMPTZ,
+  decided_by TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_dataset_access_requests_dataset
+  ON dataset_access_requests (dataset_id, requested_at DESC);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_dataset_access_requests_pending
+  ON dataset_access_requests (dataset_id, requested_by)
+  WHERE status = 'pending';
+
+CREATE TABLE IF NOT EXISTS dataset_access_request_votes (
+  vote_id TEXT PRIMARY KEY,
+  request_id TEXT NOT NULL REFERENCES dataset_access_requests(request_id),
+  stage INTEGER NOT NULL,
+  stage_name TEXT NOT NULL,
+  voter TEXT NOT NULL,
+  vote TEXT NOT NULL CHECK (vote IN ('approve', 'reject')),
+  reason TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  integrity_sha256 TEXT NOT NULL,
+  UNIQUE (request_id, voter)
+);
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'trg_dataset_access_request_votes_immutable') THEN
+    CREATE TRIGGER trg_dataset_access_request_votes_immutable
+      BEFORE UPDATE OR DELETE ON dataset_access_request_votes
+      FOR EACH ROW EXECUTE FUNCTION prevent_update_delete();
+  END IF;
+END $$;
+
+-- A grant is issued when the last stage of a request approves; it expires
+-- on its own and can be revoked earlier.
+CREATE TABLE IF NOT EXISTS dataset_access_grants (
+  grant_id TEXT PRIMARY KEY,
+  request_id TEXT NOT NULL UNIQUE REFERENCES dataset_access_requests(request_id),
+  dataset_id TEXT NOT NULL REFERENCES datasets(dataset_id),
+  project_id TEXT NOT NULL REFERENCES projects(project_id),
+  subject TEXT NOT NULL,
+  granted_at TIMESTAMPTZ NOT NULL,
+  expires_at TIMESTAMPTZ NOT NULL,
+  revoked_at TIMESTAMPTZ,
+  revoked_by TEXT,
+  revoke_reason TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_dataset_access_grants_subject
+  ON dataset_access_grants (dataset_id, subject, expires_at DESC);
+
+-- One-time shares of restricted datasets remember what let their creator
+-- read the version, so redemption stops when that grant ends.
+ALTER TABLE dataset_version_shares
+  ADD COLUMN IF NOT EXISTS access_grant_id TEXT REFERENCES dataset_access_grants(grant_id),
+  ADD COLUMN IF NOT EXISTS access_bypass BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS lineage_columns;
+DROP TABLE IF EXISTS run_column_lineage;
@@ -0,0 +1,36 @@
+-- Column-level lineage declared by a run: which input columns it consumed
+-- and which output columns or features it produced from them. The mapping
+-- is stored as declared; the edges go to lineage_events.
+CREATE TABLE IF NOT EXISTS run_column_lineage (
+  run_id TEXT PRIMARY KEY REFERENCES experiment_runs(run_id),
+  mapping JSONB NOT NULL,
+  declared_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  declared_by TEXT NOT NULL,
+  integrity_sha256 TEXT NOT NULL
+);
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'trg_run_column_lineage_immutable') THEN
+    CREATE TRIGGER trg_run_column_lineage_immutable
+      BEFORE UPDATE OR DELETE ON run_column_lineage
+      FOR EACH ROW EXECUTE FUNCTION prevent_update_delete();
+  END IF;
+END $$;
+
+-- Column nodes of the lineage graph: columns of dataset versions and
+-- features produced by runs, indexed for impact analysis by column name.
+CREATE TABLE IF NOT EXISTS lineage_columns (
+  node_type TEXT NOT NULL CHECK (node_type IN ('dataset_column', 'run_feature')),
+  node_id TEXT NOT NULL,
+  column_name TEXT NOT NULL,
+  dataset_id TEXT,
+  dataset_version_id TEXT,
+  run_id TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (node_type, node_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_lineage_columns_dataset
+  ON lineage_columns (dataset_id, column_name)
+  WHERE node_type = 'dataset_column';
@@ -0,0 +1,21 @@
+-- Lineage events contributed by external producers through POST /events.
+-- Each row binds a producer's idempotency key to the event it created and
+-- to the hash of the request, so a retry replays the same event and a
+-- different payload under the same key is refused.
+CREATE TABLE IF NOT EXISTS lineage_event_ingestions (
+  producer TEXT NOT NULL,
+  idempotency_key TEXT NOT NULL,
+  event_id BIGINT NOT NULL REFERENCES lineage_events(event_id),
+  request_sha256 TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (producer, idempotency_key)
+);
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'trg_lineage_event_ingestions_immutable') THEN
+    CREATE TRIGGER trg_lineage_event_ingestions_immutable
+      BEFORE UPDATE OR DELETE ON lineage_event_ingestions
+      FOR EACH ROW EXECUTE FUNCTION prevent_update_delete();
+  END IF;
+END $$;
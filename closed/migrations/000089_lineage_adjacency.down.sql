@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS trg_lineage_events_adjacency ON lineage_events;
+DROP FUNCTION IF EXISTS lineage_adjacency_insert();
+DROP TABLE IF EXISTS lineage_adjacency;
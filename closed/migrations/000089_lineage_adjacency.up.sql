@@ -0,0 +1,38 @@
+-- Adjacency of the lineage graph: one row per endpoint of each edge, so the
+-- edges touching a node are a single index range scan in event_id order
+-- rather than an OR over the subject and object indexes of lineage_events.
+-- Subgraph traversal expands a whole depth level per query against it.
+-- Rows are written by trigger together with the event.
+CREATE TABLE IF NOT EXISTS lineage_adjacency (
+  node_type TEXT NOT NULL,
+  node_id TEXT NOT NULL,
+  event_id BIGINT NOT NULL REFERENCES lineage_events(event_id) ON DELETE CASCADE,
+  occurred_at TIMESTAMPTZ NOT NULL,
+  PRIMARY KEY (node_type, node_id, event_id) INCLUDE (occurred_at)
+);
+
+CREATE OR REPLACE FUNCTION lineage_adjacency_insert() RETURNS trigger AS $$
+BEGIN
+  INSERT INTO lineage_adjacency (node_type, node_id, event_id, occurred_at)
+  VALUES
+    (NEW.subject_type, NEW.subject_id, NEW.event_id, NEW.occurred_at),
+    (NEW.object_type, NEW.object_id, NEW.event_id, NEW.occurred_at)
+  ON CONFLICT DO NOTHING;
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'trg_lineage_events_adjacency') THEN
+    CREATE TRIGGER trg_lineage_events_adjacency
+      AFTER INSERT ON lineage_events
+      FOR EACH ROW EXECUTE FUNCTION lineage_adjacency_insert();
+  END IF;
+END $$;
+
+INSERT INTO lineage_adjacency (node_type, node_id, event_id, occurred_at)
+SELECT subject_type, subject_id, event_id, occurred_at FROM lineage_events
+UNION ALL
+SELECT object_type, object_id, event_id, occurred_at FROM lineage_events
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,4 @@
+-- Readers below admin search audit events without their payload, so the
+-- gateway needs an index over action and resource alone.
+CREATE INDEX IF NOT EXISTS idx_audit_events_search_redacted ON audit_events
+  USING GIN (to_tsvector('simple', action || ' ' || resource_type || ' ' || resource_id));
@@ -0,0 +1,17 @@
+-- Projects that have uploaded or otherwise proven possession of a blob. A
+-- reference by digest alone is accepted only when the caller's project is
+-- listed, so one project cannot attach another project's content by
+-- knowing its SHA-256.
+CREATE TABLE IF NOT EXISTS artifact_blob_projects (
+  project_id TEXT NOT NULL,
+  sha256 TEXT NOT NULL REFERENCES artifact_blobs(sha256) ON DELETE CASCADE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (project_id, sha256)
+);
+
+INSERT INTO artifact_blob_projects (project_id, sha256)
+SELECT DISTINCT r.project_id, a.blob_sha256
+FROM experiment_run_artifacts a
+JOIN experiment_runs r ON r.run_id = a.run_id
+WHERE a.blob_sha256 IS NOT NULL AND COALESCE(r.project_id, '') <> ''
+ON CONFLICT DO NOTHING;
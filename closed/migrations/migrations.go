@@ -0,0 +1,8 @@
+// Package migrations embeds the versioned SQL schema, the persistence source
+// of truth, so every service binary can apply it (see platform/migrate).
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var Files embed.FS
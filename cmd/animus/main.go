@@ -0,0 +1,9 @@
+// Command animus runs the whole control plane in one process; see
+// closed/allinone.
+package main
+
+import "github.com/animus-labs/animus-go/closed/allinone"
+
+func main() {
+	allinone.Main()
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/animus-labs/animus-go/pkg/animus/client"
+)
+
+// config is the file written by login.
+type config struct {
+	Gateway   string    `json:"gateway,omitempty"`
+	ProjectID string    `json:"project_id,omitempty"`
+	Issuer    string    `json:"issuer,omitempty"`
+	ClientID  string    `json:"client_id,omitempty"`
+	Token     string    `json:"token,omitempty"`
+	ExpiresAt time.Time `json:"expires_at,omitzero"`
+	Subject   string    `json:"subject,omitempty"`
+}
+
+func defaultConfigPath() string {
+	if path := strings.TrimSpace(os.Getenv("ANIMUS_CONFIG")); path != "" {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "animus", "animusctl.json")
+}
+
+func loadConfig(path string) (config, error) {
+	var cfg config
+	if path == "" {
+		return cfg, nil
+	}
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return cfg, fmt.Errorf("config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// saveConfig writes the config readable by the owner only; it holds a
+// bearer token.
+func saveConfig(path string, cfg config) error {
+	if path == "" {
+		return errors.New("no config path; set ANIMUS_CONFIG")
+	}
+	raw, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(raw, '\n'), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// app holds the settings shared by all commands.
+type app struct {
+	stdout io.Writer
+	stderr io.Writer
+
+	configPath string
+	gateway    string
+	token      string
+	projectID  string
+	output     string
+
+	cfg config
+}
+
+func (a *app) globalFlags(fs *flag.FlagSet) {
+	fs.StringVar(&a.configPath, "config", defaultConfigPath(), "Config file (ANIMUS_CONFIG)")
+	fs.StringVar(&a.gateway, "gateway", os.Getenv("ANIMUS_GATEWAY_URL"), "Gateway base URL (ANIMUS_GATEWAY_URL)")
+	fs.StringVar(&a.token, "token", os.Getenv("ANIMUS_BEARER_TOKEN"), "Bearer token (ANIMUS_BEARER_TOKEN)")
+	fs.StringVar(&a.projectID, "project", os.Getenv("ANIMUS_PROJECT_ID"), "Project ID (ANIMUS_PROJECT_ID)")
+	fs.StringVar(&a.output, "o", "table", "Output format: table or json")
+}
+
+// init fills unset settings from the config file.
+func (a *app) init() error {
+	switch a.output {
+	case "table", "json":
+	default:
+		return fmt.Errorf("unknown output format %q", a.output)
+	}
+	cfg, err := loadConfig(a.configPath)
+	if err != nil {
+		return err
+	}
+	a.cfg = cfg
+	if strings.TrimSpace(a.gateway) == "" {
+		a.gateway = cfg.Gateway
+	}
+	if strings.TrimSpace(a.token) == "" {
+		a.token = cfg.Token
+	}
+	if strings.TrimSpace(a.projectID) == "" {
+		a.projectID = cfg.ProjectID
+	}
+	return nil
+}
+
+func (a *app) client() (*client.Client, error) {
+	if strings.TrimSpace(a.gateway) == "" {
+		return nil, errors.New("gateway is not set; pass --gateway or run login")
+	}
+	if a.token == a.cfg.Token && !a.cfg.ExpiresAt.IsZero() && time.Now().After(a.cfg.ExpiresAt) {
+		return nil, errors.New("saved token has expired; run login")
+	}
+	return client.New(client.Config{
+		BaseURL:   a.gateway,
+		Token:     a.token,
+		ProjectID: a.projectID,
+		UserAgent: "animusctl",
+	})
+}
+
+// print writes v as JSON, or as a table of headers and rows.
+func (a *app) print(v any, headers []string, rows [][]string) error {
+	if a.output == "json" {
+		enc := json.NewEncoder(a.stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+	return writeTable(a.stdout, headers, rows)
+}
+
+func writeTable(w io.Writer, headers []string, rows [][]string) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(headers, "\t"))
+	for _, row := range rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cells[i] = strings.NewReplacer("\t", " ", "\n", " ").Replace(cell)
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func orDash(s string) string {
+	if strings.TrimSpace(s) == "" {
+		return "-"
+	}
+	return s
+}
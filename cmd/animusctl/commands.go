@@ -0,0 +1,546 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/animus-labs/animus-go/pkg/animus/client"
+	"gopkg.in/yaml.v3"
+)
+
+func datasetCommand() *command {
+	var (
+		datasetID   string
+		ruleID      string
+		contentType string
+		metadata    string
+	)
+	return &command{
+		name:    "dataset",
+		summary: "Manage datasets",
+		subs: []*command{{
+			name:    "upload",
+			usage:   "--dataset <id> [flags] <file>",
+			summary: "Upload a file as a new dataset version",
+			flags: func(fs *flag.FlagSet) {
+				fs.StringVar(&datasetID, "dataset", "", "Dataset ID")
+				fs.StringVar(&ruleID, "rule", "", "Quality rule to bind the version to")
+				fs.StringVar(&contentType, "content-type", "", "Content type; guessed from the extension when empty")
+				fs.StringVar(&metadata, "metadata", "", "Version metadata as a JSON object")
+			},
+			run: func(ctx context.Context, a *app, args []string) error {
+				if err := requireArgs(args, 1, "animusctl dataset upload --dataset <id> <file>"); err != nil {
+					return err
+				}
+				if strings.TrimSpace(datasetID) == "" {
+					return errors.New("--dataset is required")
+				}
+				var meta map[string]any
+				if strings.TrimSpace(metadata) != "" {
+					if err := json.Unmarshal([]byte(metadata), &meta); err != nil {
+						return fmt.Errorf("--metadata: %w", err)
+					}
+				}
+				if contentType == "" {
+					contentType = mime.TypeByExtension(filepath.Ext(args[0]))
+				}
+				c, err := a.client()
+				if err != nil {
+					return err
+				}
+				f, err := os.Open(args[0])
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				version, err := c.UploadDatasetVersion(ctx, datasetID, client.UploadDatasetVersionRequest{
+					Filename:      args[0],
+					ContentType:   contentType,
+					Content:       f,
+					Metadata:      meta,
+					QualityRuleID: ruleID,
+				})
+				if err != nil {
+					return err
+				}
+				return a.print(version, []string{"VERSION", "DATASET", "ORDINAL", "SHA256", "CREATED"}, [][]string{{
+					version.VersionID, version.DatasetID, strconv.FormatInt(version.Ordinal, 10), version.ContentSHA256, formatTime(version.CreatedAt),
+				}})
+			},
+		}},
+	}
+}
+
+func ruleCommand() *command {
+	var (
+		name        string
+		description string
+		versionID   string
+		ruleID      string
+	)
+	return &command{
+		name:    "rule",
+		summary: "Manage quality rules",
+		subs: []*command{
+			{
+				name:    "create",
+				usage:   "--name <name> [flags] <spec.yaml>",
+				summary: "Create a quality rule from an animus.quality.rule.v1 YAML or JSON file",
+				flags: func(fs *flag.FlagSet) {
+					fs.StringVar(&name, "name", "", "Rule name")
+					fs.StringVar(&description, "description", "", "Rule description")
+				},
+				run: func(ctx context.Context, a *app, args []string) error {
+					if err := requireArgs(args, 1, "animusctl rule create --name <name> <spec.yaml>"); err != nil {
+						return err
+					}
+					if strings.TrimSpace(name) == "" {
+						return errors.New("--name is required")
+					}
+					var spec client.QualityRuleSpec
+					if err := readDocument(args[0], &spec); err != nil {
+						return err
+					}
+					c, err := a.client()
+					if err != nil {
+						return err
+					}
+					rule, err := c.CreateQualityRule(ctx, client.CreateQualityRuleRequest{Name: name, Description: description, Spec: spec})
+					if err != nil {
+						return err
+					}
+					return a.print(rule, []string{"RULE", "NAME", "CHECKS", "CREATED"}, [][]string{{
+						rule.RuleID, rule.Name, strconv.Itoa(len(rule.Spec.Checks)), formatTime(rule.CreatedAt),
+					}})
+				},
+			},
+			{
+				name:    "evaluate",
+				usage:   "--version <dataset_version_id> [--rule <id>]",
+				summary: "Evaluate a dataset version; exits 2 unless it passes",
+				flags: func(fs *flag.FlagSet) {
+					fs.StringVar(&versionID, "version", "", "Dataset version ID")
+					fs.StringVar(&ruleID, "rule", "", "Rule ID; defaults to the rule bound at upload")
+				},
+				run: func(ctx context.Context, a *app, args []string) error {
+					if err := requireArgs(args, 0, "animusctl rule evaluate --version <id>"); err != nil {
+						return err
+					}
+					if strings.TrimSpace(versionID) == "" {
+						return errors.New("--version is required")
+					}
+					c, err := a.client()
+					if err != nil {
+						return err
+					}
+					eval, err := c.CreateEvaluation(ctx, client.CreateEvaluationRequest{DatasetVersionID: versionID, RuleID: ruleID})
+					if err != nil {
+						return err
+					}
+					s := eval.Summary
+					if err := a.print(eval, []string{"EVALUATION", "STATUS", "PASS", "FAIL", "ERROR", "FAILING"}, [][]string{{
+						eval.EvaluationID, eval.Status, strconv.Itoa(s.ChecksPass), strconv.Itoa(s.ChecksFail), strconv.Itoa(s.ChecksError), orDash(strings.Join(s.FailingCheckIDs, ",")),
+					}}); err != nil {
+						return err
+					}
+					if eval.Status != "pass" {
+						return errGate
+					}
+					return nil
+				},
+			},
+		},
+	}
+}
+
+func runCommand() *command {
+	var (
+		watch       bool
+		interval    time.Duration
+		priority    string
+		maxDuration time.Duration
+		reason      string
+	)
+	watchFlags := func(fs *flag.FlagSet) {
+		fs.DurationVar(&interval, "interval", 2*time.Second, "Poll interval")
+	}
+	return &command{
+		name:    "run",
+		summary: "Execute and follow project runs",
+		subs: []*command{
+			{
+				name:    "execute",
+				usage:   "[flags] <run.json>",
+				summary: "Create a run from a run request file and dispatch it",
+				flags: func(fs *flag.FlagSet) {
+					fs.BoolVar(&watch, "watch", false, "Wait for the run to finish; exits 2 unless it succeeds")
+					fs.StringVar(&priority, "priority", "", "Queue priority: high, normal or low")
+					fs.DurationVar(&maxDuration, "max-duration", 0, "Maximum run duration")
+					watchFlags(fs)
+				},
+				run: func(ctx context.Context, a *app, args []string) error {
+					if err := requireArgs(args, 1, "animusctl run execute <run.json>"); err != nil {
+						return err
+					}
+					var req client.CreateRunRequest
+					if err := readDocument(args[0], &req); err != nil {
+						return err
+					}
+					c, err := a.client()
+					if err != nil {
+						return err
+					}
+					created, err := c.CreateRun(ctx, req)
+					if err != nil {
+						return err
+					}
+					dispatched, err := c.DispatchRun(ctx, created.RunID, client.DispatchRunRequest{
+						Priority:           priority,
+						MaxDurationSeconds: int64(maxDuration / time.Second),
+					})
+					if err != nil {
+						return fmt.Errorf("run %s created but not dispatched: %w", created.RunID, err)
+					}
+					if !watch {
+						return a.print(dispatched, []string{"RUN", "DISPATCH", "STATUS", "QUEUE"}, [][]string{{
+							dispatched.RunID, orDash(dispatched.DispatchID), dispatched.Status, queuePosition(dispatched.QueuePosition),
+						}})
+					}
+					return watchRun(ctx, a, c, created.RunID, interval)
+				},
+			},
+			{
+				name:    "watch",
+				usage:   "[flags] <run_id>",
+				summary: "Follow a run until it finishes; exits 2 unless it succeeds",
+				flags:   watchFlags,
+				run: func(ctx context.Context, a *app, args []string) error {
+					if err := requireArgs(args, 1, "animusctl run watch <run_id>"); err != nil {
+						return err
+					}
+					c, err := a.client()
+					if err != nil {
+						return err
+					}
+					return watchRun(ctx, a, c, args[0], interval)
+				},
+			},
+			{
+				name:    "cancel",
+				usage:   "[--reason <text>] <run_id>",
+				summary: "Cancel an unfinished run",
+				flags: func(fs *flag.FlagSet) {
+					fs.StringVar(&reason, "reason", "", "Cancel reason recorded in the audit log")
+				},
+				run: func(ctx context.Context, a *app, args []string) error {
+					if err := requireArgs(args, 1, "animusctl run cancel <run_id>"); err != nil {
+						return err
+					}
+					c, err := a.client()
+					if err != nil {
+						return err
+					}
+					out, err := c.CancelRun(ctx, args[0], reason)
+					if err != nil {
+						return err
+					}
+					return a.print(out, []string{"RUN", "STATUS", "JOB_CANCELED"}, [][]string{{
+						out.RunID, out.Status, strconv.FormatBool(out.JobCanceled),
+					}})
+				},
+			},
+		},
+	}
+}
+
+// watchRun prints state changes as table rows, or the final run as JSON.
+func watchRun(ctx context.Context, a *app, c *client.Client, runID string, interval time.Duration) error {
+	onChange := func(run client.Run) {
+		if a.output == "table" {
+			fmt.Fprintf(a.stdout, "%s  %s  %s\n", time.Now().UTC().Format(time.RFC3339), run.RunID, run.State)
+		}
+	}
+	run, err := c.WatchRun(ctx, runID, interval, onChange)
+	if err != nil {
+		return err
+	}
+	if a.output == "json" {
+		if err := a.print(run, nil, nil); err != nil {
+			return err
+		}
+	}
+	if run.State != "succeeded" {
+		return errGate
+	}
+	return nil
+}
+
+func queuePosition(pos int) string {
+	if pos <= 0 {
+		return "-"
+	}
+	return strconv.Itoa(pos)
+}
+
+func approvalCommand() *command {
+	var (
+		status string
+		runID  string
+		limit  int
+		reason string
+	)
+	return &command{
+		name:    "approval",
+		summary: "Review policy approvals",
+		subs: []*command{
+			{
+				name:    "list",
+				usage:   "[--status pending] [--run <run_id>]",
+				summary: "List policy approvals",
+				flags: func(fs *flag.FlagSet) {
+					fs.StringVar(&status, "status", "pending", "Status filter; empty lists all")
+					fs.StringVar(&runID, "run", "", "Run ID filter")
+					fs.IntVar(&limit, "limit", 100, "Maximum approvals")
+				},
+				run: func(ctx context.Context, a *app, args []string) error {
+					if err := requireArgs(args, 0, "animusctl approval list"); err != nil {
+						return err
+					}
+					c, err := a.client()
+					if err != nil {
+						return err
+					}
+					approvals, err := c.ListPolicyApprovals(ctx, client.ApprovalQuery{Status: status, RunID: runID, Limit: limit})
+					if err != nil {
+						return err
+					}
+					rows := make([][]string, 0, len(approvals))
+					for _, ap := range approvals {
+						rows = append(rows, []string{
+							ap.ApprovalID, ap.Status, orDash(ap.RunID), ap.PolicyName, orDash(ap.StageName), orDash(ap.AssignedTo), ap.RequestedBy, formatTime(ap.RequestedAt),
+						})
+					}
+					return a.print(approvals, []string{"APPROVAL", "STATUS", "RUN", "POLICY", "STAGE", "ASSIGNED", "REQUESTED_BY", "REQUESTED"}, rows)
+				},
+			},
+			{
+				name:    "approve",
+				usage:   "[--reason <text>] <approval_id>",
+				summary: "Vote to approve a pending approval",
+				flags: func(fs *flag.FlagSet) {
+					fs.StringVar(&reason, "reason", "", "Reason recorded with the vote")
+				},
+				run: func(ctx context.Context, a *app, args []string) error {
+					if err := requireArgs(args, 1, "animusctl approval approve <approval_id>"); err != nil {
+						return err
+					}
+					c, err := a.client()
+					if err != nil {
+						return err
+					}
+					out, err := c.ApprovePolicyApproval(ctx, args[0], reason)
+					if err != nil {
+						return err
+					}
+					return a.print(out, []string{"APPROVAL", "STATUS", "RUN", "STAGE", "DISPATCH_REQUIRED"}, [][]string{{
+						out.ApprovalID, out.ApprovalStatus, orDash(out.RunID), orDash(out.Stage), strconv.FormatBool(out.DispatchRequired),
+					}})
+				},
+			},
+		},
+	}
+}
+
+func evidenceCommand() *command {
+	var (
+		runID  string
+		output string
+	)
+	runFlag := func(fs *flag.FlagSet) {
+		fs.StringVar(&runID, "run", "", "Experiment run ID")
+	}
+	return &command{
+		name:    "evidence",
+		summary: "Download and verify evidence bundles",
+		subs: []*command{
+			{
+				name:    "download",
+				usage:   "--run <run_id> [--out <file>] <bundle_id>",
+				summary: "Download an evidence bundle zip",
+				flags: func(fs *flag.FlagSet) {
+					runFlag(fs)
+					fs.StringVar(&output, "out", "", "Output file; defaults to <bundle_id>.zip, - writes to stdout")
+				},
+				run: func(ctx context.Context, a *app, args []string) error {
+					if err := requireArgs(args, 1, "animusctl evidence download --run <run_id> <bundle_id>"); err != nil {
+						return err
+					}
+					if strings.TrimSpace(runID) == "" {
+						return errors.New("--run is required")
+					}
+					c, err := a.client()
+					if err != nil {
+						return err
+					}
+					body, err := c.DownloadEvidenceBundle(ctx, runID, args[0])
+					if err != nil {
+						return err
+					}
+					defer body.Close()
+					if output == "-" {
+						_, err := io.Copy(a.stdout, body)
+						return err
+					}
+					if output == "" {
+						output = args[0] + ".zip"
+					}
+					n, err := writeFile(output, body)
+					if err != nil {
+						return err
+					}
+					fmt.Fprintf(a.stderr, "wrote %s (%d bytes)\n", output, n)
+					return nil
+				},
+			},
+			{
+				name:    "verify",
+				usage:   "--run <run_id> <bundle_id>",
+				summary: "Verify an evidence bundle on the server; exits 2 if it is invalid",
+				flags:   runFlag,
+				run: func(ctx context.Context, a *app, args []string) error {
+					if err := requireArgs(args, 1, "animusctl evidence verify --run <run_id> <bundle_id>"); err != nil {
+						return err
+					}
+					if strings.TrimSpace(runID) == "" {
+						return errors.New("--run is required")
+					}
+					c, err := a.client()
+					if err != nil {
+						return err
+					}
+					report, err := c.VerifyEvidenceBundle(ctx, runID, args[0])
+					if err != nil {
+						return err
+					}
+					rows := make([][]string, 0, len(report.Checks)+len(report.Files))
+					for _, check := range report.Checks {
+						rows = append(rows, []string{"check", check.Name, okString(check.OK), orDash(check.Detail)})
+					}
+					for _, file := range report.Files {
+						rows = append(rows, []string{"file", file.Name, okString(file.OK), orDash(file.Detail)})
+					}
+					if err := a.print(report, []string{"KIND", "NAME", "RESULT", "DETAIL"}, rows); err != nil {
+						return err
+					}
+					if !report.Valid {
+						return errGate
+					}
+					return nil
+				},
+			},
+		},
+	}
+}
+
+func okString(ok bool) string {
+	if ok {
+		return "ok"
+	}
+	return "FAILED"
+}
+
+func auditCommand() *command {
+	var (
+		q     client.AuditQuery
+		limit int
+	)
+	return &command{
+		name:    "audit",
+		summary: "Query the audit log",
+		subs: []*command{{
+			name:    "query",
+			usage:   "[flags]",
+			summary: "List audit events, newest first",
+			flags: func(fs *flag.FlagSet) {
+				fs.StringVar(&q.Actor, "actor", "", "Actor filter")
+				fs.StringVar(&q.Action, "action", "", "Action filter")
+				fs.StringVar(&q.ResourceType, "resource-type", "", "Resource type filter")
+				fs.StringVar(&q.ResourceID, "resource-id", "", "Resource ID filter")
+				fs.StringVar(&q.RequestID, "request-id", "", "Request ID filter")
+				fs.IntVar(&limit, "limit", 100, "Maximum events; pages are fetched as needed")
+			},
+			run: func(ctx context.Context, a *app, args []string) error {
+				if err := requireArgs(args, 0, "animusctl audit query [flags]"); err != nil {
+					return err
+				}
+				c, err := a.client()
+				if err != nil {
+					return err
+				}
+				q.PageSize = min(limit, 500)
+				events := []client.AuditEvent{}
+				for event, err := range c.AuditEvents(ctx, q) {
+					if err != nil {
+						return err
+					}
+					events = append(events, event)
+					if len(events) >= limit {
+						break
+					}
+				}
+				rows := make([][]string, 0, len(events))
+				for _, event := range events {
+					rows = append(rows, []string{
+						strconv.FormatInt(event.EventID, 10), formatTime(event.OccurredAt), event.Actor, event.Action, event.ResourceType, event.ResourceID, orDash(event.RequestID),
+					})
+				}
+				return a.print(events, []string{"EVENT", "OCCURRED", "ACTOR", "ACTION", "RESOURCE_TYPE", "RESOURCE_ID", "REQUEST_ID"}, rows)
+			},
+		}},
+	}
+}
+
+// readDocument decodes a YAML or JSON file into out; JSON is valid YAML.
+func readDocument(path string, out any) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var doc any
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	normalized, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if err := json.Unmarshal(normalized, out); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+func writeFile(path string, r io.Reader) (int64, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(f, r)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(path)
+	}
+	return n, err
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const deviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+type loginFlags struct {
+	issuer   string
+	clientID string
+	scopes   string
+}
+
+// loginCommand signs in with the OAuth 2.0 device authorization grant
+// (RFC 8628) against the gateway's OIDC issuer. The gateway verifies bearer
+// tokens as ID tokens for its client ID, so the ID token is what is saved.
+func loginCommand() *command {
+	var lf loginFlags
+	return &command{
+		name:    "login",
+		usage:   "[flags]",
+		summary: "Sign in with the OIDC device flow and save the token",
+		flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&lf.issuer, "issuer", os.Getenv("ANIMUS_OIDC_ISSUER_URL"), "OIDC issuer URL (ANIMUS_OIDC_ISSUER_URL)")
+			fs.StringVar(&lf.clientID, "client-id", os.Getenv("ANIMUS_OIDC_CLIENT_ID"), "OIDC client ID of the gateway (ANIMUS_OIDC_CLIENT_ID)")
+			fs.StringVar(&lf.scopes, "scopes", "openid profile email", "Requested scopes")
+		},
+		run: func(ctx context.Context, a *app, args []string) error {
+			if err := requireArgs(args, 0, "animusctl login [flags]"); err != nil {
+				return err
+			}
+			issuer := firstNonEmpty(lf.issuer, a.cfg.Issuer)
+			clientID := firstNonEmpty(lf.clientID, a.cfg.ClientID)
+			if issuer == "" || clientID == "" {
+				return errors.New("--issuer and --client-id are required")
+			}
+			if strings.TrimSpace(a.gateway) == "" {
+				return errors.New("--gateway is required")
+			}
+			flow := &deviceFlow{http: &http.Client{Timeout: 30 * time.Second}, sleep: sleepContext}
+			token, err := flow.login(ctx, issuer, clientID, lf.scopes, func(auth deviceAuthorization) {
+				fmt.Fprintf(a.stderr, "Open %s and enter code %s\n", auth.VerificationURI, auth.UserCode)
+				if auth.VerificationURIComplete != "" {
+					fmt.Fprintf(a.stderr, "or open %s\n", auth.VerificationURIComplete)
+				}
+			})
+			if err != nil {
+				return err
+			}
+
+			a.token = token.IDToken
+			c, err := a.client()
+			if err != nil {
+				return err
+			}
+			me, err := c.Me(ctx)
+			if err != nil {
+				return fmt.Errorf("gateway rejected the token: %w", err)
+			}
+			cfg := a.cfg
+			cfg.Gateway = strings.TrimRight(strings.TrimSpace(a.gateway), "/")
+			cfg.ProjectID = a.projectID
+			cfg.Issuer = issuer
+			cfg.ClientID = clientID
+			cfg.Token = token.IDToken
+			cfg.ExpiresAt = token.ExpiresAt
+			cfg.Subject = me.UserID
+			if err := saveConfig(a.configPath, cfg); err != nil {
+				return fmt.Errorf("save config: %w", err)
+			}
+			return a.print(me, []string{"USER", "EMAIL", "ROLES", "EXPIRES"}, [][]string{{
+				me.UserID, orDash(me.Email), orDash(strings.Join(me.Roles, ",")), formatTime(token.ExpiresAt),
+			}})
+		},
+	}
+}
+
+type deviceAuthorization struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+type deviceToken struct {
+	IDToken   string
+	ExpiresAt time.Time
+}
+
+type deviceFlow struct {
+	http  *http.Client
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+func (f *deviceFlow) login(ctx context.Context, issuer, clientID, scopes string, prompt func(deviceAuthorization)) (deviceToken, error) {
+	var discovery struct {
+		DeviceAuthorizationEndpoint string `json:"device_authorization_endpoint"`
+		TokenEndpoint               string `json:"token_endpoint"`
+	}
+	discoveryURL := strings.TrimRight(strings.TrimSpace(issuer), "/") + "/.well-known/openid-configuration"
+	if err := f.getJSON(ctx, discoveryURL, &discovery); err != nil {
+		return deviceToken{}, fmt.Errorf("oidc discovery: %w", err)
+	}
+	if discovery.DeviceAuthorizationEndpoint == "" || discovery.TokenEndpoint == "" {
+		return deviceToken{}, errors.New("issuer does not support the device authorization grant")
+	}
+
+	var auth deviceAuthorization
+	status, err := f.postForm(ctx, discovery.DeviceAuthorizationEndpoint, url.Values{
+		"client_id": {clientID},
+		"scope":     {scopes},
+	}, &auth)
+	if err != nil {
+		return deviceToken{}, fmt.Errorf("device authorization: %w", err)
+	}
+	if status != http.StatusOK || auth.DeviceCode == "" {
+		return deviceToken{}, fmt.Errorf("device authorization: status %d", status)
+	}
+	prompt(auth)
+
+	interval := time.Duration(auth.Interval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	expiresIn := time.Duration(auth.ExpiresIn) * time.Second
+	if expiresIn <= 0 {
+		expiresIn = 10 * time.Minute
+	}
+	deadline := time.Now().Add(expiresIn)
+	for {
+		if time.Now().After(deadline) {
+			return deviceToken{}, errors.New("device code expired before sign-in completed")
+		}
+		if err := f.sleep(ctx, interval); err != nil {
+			return deviceToken{}, err
+		}
+		var resp struct {
+			IDToken   string `json:"id_token"`
+			ExpiresIn int    `json:"expires_in"`
+			Error     string `json:"error"`
+		}
+		if _, err := f.postForm(ctx, discovery.TokenEndpoint, url.Values{
+			"grant_type":  {deviceCodeGrantType},
+			"device_code": {auth.DeviceCode},
+			"client_id":   {clientID},
+		}, &resp); err != nil {
+			return deviceToken{}, fmt.Errorf("token: %w", err)
+		}
+		switch resp.Error {
+		case "":
+		case "authorization_pending":
+			continue
+		case "slow_down":
+			interval += 5 * time.Second
+			continue
+		case "access_denied":
+			return deviceToken{}, errors.New("sign-in was denied")
+		case "expired_token":
+			return deviceToken{}, errors.New("device code expired before sign-in completed")
+		default:
+			return deviceToken{}, fmt.Errorf("token: %s", resp.Error)
+		}
+		if resp.IDToken == "" {
+			return deviceToken{}, errors.New("token response has no id_token; request the openid scope")
+		}
+		expiresAt := idTokenExpiry(resp.IDToken)
+		if expiresAt.IsZero() && resp.ExpiresIn > 0 {
+			expiresAt = time.Now().Add(time.Duration(resp.ExpiresIn) * time.Second).UTC()
+		}
+		return deviceToken{IDToken: resp.IDToken, ExpiresAt: expiresAt}, nil
+	}
+}
+
+func (f *deviceFlow) getJSON(ctx context.Context, endpoint string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := f.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: status %d", endpoint, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(out)
+}
+
+// postForm posts form and decodes the JSON body whatever the status; token
+// endpoints report pending sign-ins as 400 with an error code.
+func (f *deviceFlow) postForm(ctx context.Context, endpoint string, form url.Values, out any) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	resp, err := f.http.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(out); err != nil {
+		return resp.StatusCode, fmt.Errorf("POST %s: status %d: %w", endpoint, resp.StatusCode, err)
+	}
+	return resp.StatusCode, nil
+}
+
+// idTokenExpiry reads the exp claim without verifying the token; the
+// gateway does the verification.
+func idTokenExpiry(token string) time.Time {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}
+	}
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(raw, &claims); err != nil || claims.Exp <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(claims.Exp, 0).UTC()
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,174 @@
+// Command animusctl is a command-line client for the Animus DataPilot API.
+//
+//	animusctl login --issuer https://idp.example.com --client-id animus
+//	animusctl dataset upload --dataset <id> --rule <rule_id> data.csv
+//	animusctl run execute --watch run.json
+//	animusctl audit query --action run.canceled -o json
+//
+// Settings come from flags, then ANIMUS_* environment variables, then the
+// config file written by login.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+)
+
+// exitGate is the exit code when a command succeeded but its result is a
+// failed gate: a failing evaluation, an invalid bundle or a run that did not
+// succeed.
+const exitGate = 2
+
+var errGate = errors.New("gate failed")
+
+// command is a node of the command tree; leaves have run set.
+type command struct {
+	name    string
+	usage   string
+	summary string
+	subs    []*command
+	run     func(ctx context.Context, app *app, args []string) error
+	flags   func(fs *flag.FlagSet)
+}
+
+func (c *command) find(name string) *command {
+	for _, sub := range c.subs {
+		if sub.name == name {
+			return sub
+		}
+	}
+	return nil
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := execute(ctx, os.Args[1:], os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
+
+func execute(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	root := rootCommand()
+	cmd, path, rest := resolve(root, args)
+	if cmd.run == nil {
+		printHelp(stderr, cmd, path)
+		if len(rest) > 0 && rest[0] != "-h" && rest[0] != "--help" && rest[0] != "help" {
+			fmt.Fprintf(stderr, "\nunknown command %q\n", rest[0])
+			return 1
+		}
+		return 0
+	}
+
+	a := &app{stdout: stdout, stderr: stderr}
+	fs := flag.NewFlagSet("animusctl "+strings.Join(path, " "), flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	a.globalFlags(fs)
+	if cmd.flags != nil {
+		cmd.flags(fs)
+	}
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: animusctl %s %s\n\n%s\n\nFlags:\n", strings.Join(path, " "), cmd.usage, cmd.summary)
+		fs.PrintDefaults()
+	}
+	positional, err := parseInterspersed(fs, rest)
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	if err != nil {
+		return 1
+	}
+	if err := a.init(); err != nil {
+		fmt.Fprintf(stderr, "animusctl: %v\n", err)
+		return 1
+	}
+	if err := cmd.run(ctx, a, positional); err != nil {
+		if errors.Is(err, errGate) {
+			return exitGate
+		}
+		fmt.Fprintf(stderr, "animusctl: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// resolve walks args down the command tree and returns the deepest command,
+// its name path and the remaining arguments.
+func resolve(root *command, args []string) (*command, []string, []string) {
+	cmd := root
+	var path []string
+	for len(args) > 0 && cmd.run == nil {
+		next := cmd.find(args[0])
+		if next == nil {
+			break
+		}
+		cmd = next
+		path = append(path, next.name)
+		args = args[1:]
+	}
+	return cmd, path, args
+}
+
+// parseInterspersed parses flags placed before, between or after positional
+// arguments; "--" ends flag parsing.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		if args[0] == "--" {
+			return append(positional, args[1:]...), nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+func printHelp(w io.Writer, cmd *command, path []string) {
+	prefix := strings.TrimSpace("animusctl " + strings.Join(path, " "))
+	if cmd.summary != "" {
+		fmt.Fprintf(w, "%s\n\n", cmd.summary)
+	}
+	fmt.Fprintf(w, "Usage: %s <command> [flags]\n\nCommands:\n", prefix)
+	subs := append([]*command(nil), cmd.subs...)
+	sort.Slice(subs, func(i, j int) bool { return subs[i].name < subs[j].name })
+	for _, sub := range subs {
+		fmt.Fprintf(w, "  %-10s %s\n", sub.name, sub.summary)
+	}
+}
+
+func rootCommand() *command {
+	return &command{
+		summary: "animusctl drives the Animus DataPilot API through the gateway.",
+		subs: []*command{
+			loginCommand(),
+			datasetCommand(),
+			ruleCommand(),
+			runCommand(),
+			approvalCommand(),
+			evidenceCommand(),
+			auditCommand(),
+		},
+	}
+}
+
+// requireArgs checks the positional argument count against the command's
+// usage line.
+func requireArgs(args []string, n int, usage string) error {
+	if len(args) != n {
+		return fmt.Errorf("usage: %s", usage)
+	}
+	return nil
+}
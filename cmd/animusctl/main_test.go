@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseInterspersed(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	reason := fs.String("reason", "", "")
+	args, err := parseInterspersed(fs, []string{"run-1", "--reason", "stale data", "--", "-x"})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if *reason != "stale data" || len(args) != 2 || args[0] != "run-1" || args[1] != "-x" {
+		t.Fatalf("reason=%q args=%v", *reason, args)
+	}
+}
+
+func TestDeviceFlowPollsUntilToken(t *testing.T) {
+	exp := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	claims, _ := json.Marshal(map[string]any{"sub": "alice", "exp": exp.Unix()})
+	idToken := "e30." + base64.RawURLEncoding.EncodeToString(claims) + ".sig"
+
+	polls := 0
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			_ = json.NewEncoder(w).Encode(map[string]string{
+				"device_authorization_endpoint": srv.URL + "/device",
+				"token_endpoint":                srv.URL + "/token",
+			})
+		case "/device":
+			if r.FormValue("client_id") != "animus" {
+				t.Errorf("client_id %q", r.FormValue("client_id"))
+			}
+			_, _ = w.Write([]byte(`{"device_code":"dc","user_code":"ABCD","verification_uri":"https://idp/activate","interval":1,"expires_in":60}`))
+		case "/token":
+			if r.FormValue("grant_type") != deviceCodeGrantType || r.FormValue("device_code") != "dc" {
+				t.Errorf("token form %v", r.Form)
+			}
+			polls++
+			if polls < 3 {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"error":"authorization_pending"}`))
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"id_token": idToken, "access_token": "at"})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	var prompted string
+	flow := &deviceFlow{http: srv.Client(), sleep: func(context.Context, time.Duration) error { return nil }}
+	token, err := flow.login(context.Background(), srv.URL, "animus", "openid", func(auth deviceAuthorization) {
+		prompted = auth.UserCode
+	})
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	if token.IDToken != idToken || !token.ExpiresAt.Equal(exp) || prompted != "ABCD" || polls != 3 {
+		t.Fatalf("token=%+v prompted=%q polls=%d", token, prompted, polls)
+	}
+}
+
+func TestDeviceFlowDenied(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			_ = json.NewEncoder(w).Encode(map[string]string{
+				"device_authorization_endpoint": srv.URL + "/device",
+				"token_endpoint":                srv.URL + "/token",
+			})
+		case "/device":
+			_, _ = w.Write([]byte(`{"device_code":"dc","user_code":"ABCD","verification_uri":"https://idp/activate"}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"access_denied"}`))
+		}
+	}))
+	defer srv.Close()
+
+	flow := &deviceFlow{http: srv.Client(), sleep: func(context.Context, time.Duration) error { return nil }}
+	if _, err := flow.login(context.Background(), srv.URL, "animus", "openid", func(deviceAuthorization) {}); err == nil || !strings.Contains(err.Error(), "denied") {
+		t.Fatalf("expected denied error, got %v", err)
+	}
+}
+
+func newGateway(t *testing.T, handler http.HandlerFunc) string {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func run(t *testing.T, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	args = append(args, "--config", filepath.Join(t.TempDir(), "animusctl.json"))
+	code := execute(context.Background(), args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRunCancelTableAndJSON(t *testing.T) {
+	gateway := newGateway(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/experiments/projects/proj-1/runs/run-1/cancel" {
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["reason"] != "wrong data" {
+			t.Errorf("reason %q", body["reason"])
+		}
+		_, _ = w.Write([]byte(`{"runId":"run-1","projectId":"proj-1","status":"canceled","jobCanceled":true}`))
+	})
+
+	code, stdout, stderr := run(t, "run", "cancel", "run-1", "--reason", "wrong data", "--gateway", gateway, "--project", "proj-1")
+	if code != 0 {
+		t.Fatalf("exit %d: %s", code, stderr)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "RUN") || !strings.Contains(lines[1], "canceled") || !strings.Contains(lines[1], "true") {
+		t.Fatalf("unexpected table:\n%s", stdout)
+	}
+
+	code, stdout, _ = run(t, "run", "cancel", "-o", "json", "run-1", "--reason", "wrong data", "--gateway", gateway, "--project", "proj-1")
+	var out map[string]any
+	if code != 0 || json.Unmarshal([]byte(stdout), &out) != nil || out["jobCanceled"] != true {
+		t.Fatalf("exit %d json %q", code, stdout)
+	}
+}
+
+func TestRunCommandsRequireProject(t *testing.T) {
+	code, _, stderr := run(t, "run", "cancel", "run-1", "--gateway", "http://127.0.0.1:1")
+	if code != 1 || !strings.Contains(stderr, "project id is required") {
+		t.Fatalf("exit %d stderr %q", code, stderr)
+	}
+}
+
+func TestEvaluateFailingExitsWithGateCode(t *testing.T) {
+	gateway := newGateway(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"evaluation_id":"ev-1","dataset_version_id":"ver-1","rule_id":"rule-1","status":"fail","summary":{"checks_total":2,"checks_pass":1,"checks_fail":1,"failing_check_ids":["csv"]}}`))
+	})
+	code, stdout, _ := run(t, "rule", "evaluate", "--version", "ver-1", "--gateway", gateway)
+	if code != exitGate || !strings.Contains(stdout, "csv") {
+		t.Fatalf("exit %d stdout %q", code, stdout)
+	}
+}
+
+func TestAuditQueryStopsAtLimit(t *testing.T) {
+	requests := 0
+	gateway := newGateway(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Query().Get("action") != "run.canceled" || r.URL.Query().Get("limit") != "3" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		_, _ = w.Write([]byte(`{"events":[{"event_id":9,"action":"run.canceled"},{"event_id":8,"action":"run.canceled"},{"event_id":7,"action":"run.canceled"}],"next_before_event_id":7}`))
+	})
+	code, stdout, stderr := run(t, "audit", "query", "--action", "run.canceled", "--limit", "3", "-o", "json", "--gateway", gateway)
+	if code != 0 {
+		t.Fatalf("exit %d: %s", code, stderr)
+	}
+	var events []map[string]any
+	if err := json.Unmarshal([]byte(stdout), &events); err != nil || len(events) != 3 || requests != 1 {
+		t.Fatalf("events=%d requests=%d err=%v", len(events), requests, err)
+	}
+}
+
+func TestUnknownCommand(t *testing.T) {
+	code, _, stderr := run(t, "runs")
+	if code != 1 || !strings.Contains(stderr, `unknown command "runs"`) {
+		t.Fatalf("exit %d stderr %q", code, stderr)
+	}
+}
@@ -0,0 +1,7 @@
+package main
+
+import "github.com/animus-labs/animus-go/closed/dataplane"
+
+func main() {
+	dataplane.Main()
+}
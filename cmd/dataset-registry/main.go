@@ -0,0 +1,7 @@
+package main
+
+import datasetregistry "github.com/animus-labs/animus-go/closed/dataset-registry"
+
+func main() {
+	datasetregistry.Main()
+}
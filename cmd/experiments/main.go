@@ -0,0 +1,7 @@
+package main
+
+import "github.com/animus-labs/animus-go/closed/experiments"
+
+func main() {
+	experiments.Main()
+}
@@ -0,0 +1,7 @@
+package main
+
+import "github.com/animus-labs/animus-go/closed/lineage"
+
+func main() {
+	lineage.Main()
+}
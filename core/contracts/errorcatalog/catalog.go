@@ -0,0 +1,112 @@
+// Package errorcatalog embeds catalog.yaml, the list of error codes Animus
+// services return, and serves it so clients can branch on codes and show
+// their titles without hard-coding either.
+package errorcatalog
+
+import (
+	"bytes"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed catalog.yaml
+var catalogYAML []byte
+
+// TypePrefix prefixes a code to form its problem+json type URI.
+const TypePrefix = "urn:animus:error:"
+
+// ServicePath is where the gateway serves the catalog.
+const ServicePath = "/api/errors"
+
+// Entry is one catalogued code. Status lists the HTTP statuses the code is
+// returned with; it is empty for codes whose status depends on the caller.
+type Entry struct {
+	Code   string `yaml:"code" json:"code"`
+	Type   string `yaml:"-" json:"type"`
+	Title  string `yaml:"title" json:"title"`
+	Status []int  `yaml:"status,omitempty" json:"status,omitempty"`
+}
+
+// Catalog is the parsed catalog.yaml. FieldErrors are the codes used inside
+// the errors array of a response, one per offending request field.
+type Catalog struct {
+	Errors      []Entry `yaml:"errors" json:"errors"`
+	FieldErrors []Entry `yaml:"field_errors" json:"field_errors"`
+
+	byCode map[string]Entry
+}
+
+var load = sync.OnceValues(func() (*Catalog, error) {
+	var c Catalog
+	if err := yaml.Unmarshal(catalogYAML, &c); err != nil {
+		return nil, fmt.Errorf("errorcatalog: %w", err)
+	}
+	for _, entries := range [][]Entry{c.Errors, c.FieldErrors} {
+		for i := range entries {
+			entries[i].Type = TypeURI(entries[i].Code)
+		}
+	}
+	c.byCode = make(map[string]Entry, len(c.Errors))
+	for _, entry := range c.Errors {
+		c.byCode[entry.Code] = entry
+	}
+	return &c, nil
+})
+
+// Load returns the embedded catalog.
+func Load() (*Catalog, error) {
+	return load()
+}
+
+// TypeURI returns the problem+json type URI for code.
+func TypeURI(code string) string {
+	return TypePrefix + code
+}
+
+// Lookup returns the catalog entry for an error code.
+func Lookup(code string) (Entry, bool) {
+	c, err := load()
+	if err != nil {
+		return Entry{}, false
+	}
+	entry, ok := c.byCode[code]
+	return entry, ok
+}
+
+// Title returns the catalogued title for code, or "" when it is not listed.
+func Title(code string) string {
+	entry, _ := Lookup(code)
+	return entry.Title
+}
+
+// Handler serves the catalog as JSON with an ETag. It panics when the
+// embedded catalog does not parse so the mistake fails at startup.
+func Handler() http.Handler {
+	c, err := load()
+	if err != nil {
+		panic(err)
+	}
+	body, err := json.Marshal(c)
+	if err != nil {
+		panic(err)
+	}
+	etag := fmt.Sprintf(`"%x"`, sha256.Sum256(body))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("ETag", etag)
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(body))
+	})
+}
@@ -0,0 +1,135 @@
+package errorcatalog
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestCatalogIsSortedAndUnique(t *testing.T) {
+	c, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, entries := range map[string][]Entry{"errors": c.Errors, "field_errors": c.FieldErrors} {
+		if len(entries) == 0 {
+			t.Fatalf("%s is empty", name)
+		}
+		for i, entry := range entries {
+			if entry.Code == "" || entry.Title == "" {
+				t.Errorf("%s[%d]: code and title are required: %+v", name, i, entry)
+			}
+			if i > 0 && entries[i-1].Code >= entry.Code {
+				t.Errorf("%s: %q is out of order or duplicated", name, entry.Code)
+			}
+			for _, status := range entry.Status {
+				if http.StatusText(status) == "" || status < 400 {
+					t.Errorf("%s: %q has invalid status %d", name, entry.Code, status)
+				}
+			}
+		}
+	}
+	if entry, ok := Lookup("not_found"); !ok || entry.Type != "urn:animus:error:not_found" {
+		t.Fatalf("lookup not_found: %+v %v", entry, ok)
+	}
+}
+
+// TestWrittenCodesAreCatalogued checks every string literal code passed to
+// WriteError, WriteErrorWithDetails or a service writeError helper under
+// closed/ against the catalog.
+func TestWrittenCodesAreCatalogued(t *testing.T) {
+	codes := writtenCodes(t, filepath.Join("..", "..", "..", "closed"))
+	if len(codes) == 0 {
+		t.Fatal("no error codes found")
+	}
+	var missing []string
+	for code, where := range codes {
+		if _, ok := Lookup(code); !ok {
+			missing = append(missing, code+" ("+where+")")
+		}
+	}
+	sort.Strings(missing)
+	for _, m := range missing {
+		t.Errorf("%s is not in catalog.yaml", m)
+	}
+}
+
+// writtenCodes returns the literal codes passed as the fourth argument of
+// (w, r, status, code) error writers, keyed to the first file using each.
+func writtenCodes(t *testing.T, root string) map[string]string {
+	t.Helper()
+	codes := make(map[string]string)
+	fset := token.NewFileSet()
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return err
+		}
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return err
+		}
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) < 4 {
+				return true
+			}
+			var name string
+			switch fun := call.Fun.(type) {
+			case *ast.SelectorExpr:
+				name = fun.Sel.Name
+			case *ast.Ident:
+				name = fun.Name
+			}
+			switch name {
+			case "WriteError", "WriteErrorWithDetails", "writeError", "writeErrorWithDetails":
+			default:
+				return true
+			}
+			lit, ok := call.Args[3].(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				return true
+			}
+			if code, err := strconv.Unquote(lit.Value); err == nil {
+				if _, seen := codes[code]; !seen {
+					codes[code] = fset.Position(lit.Pos()).String()
+				}
+			}
+			return true
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("walk %s: %v", root, err)
+	}
+	return codes
+}
+
+func TestHandlerServesJSONWithETag(t *testing.T) {
+	h := Handler()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, ServicePath, nil))
+	var body Catalog
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("status=%d type=%q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || len(body.Errors) == 0 || body.Errors[0].Type == "" {
+		t.Fatalf("unexpected body: %v %s", err, rec.Body)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, ServicePath, nil)
+	req.Header.Set("If-None-Match", rec.Header().Get("ETag"))
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified {
+		t.Fatalf("revalidation status %d", rec.Code)
+	}
+}
@@ -0,0 +1,190 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Mount places a service contract under a gateway path prefix.
+type Mount struct {
+	// Contract is the embedded contract name, e.g. Experiments.
+	Contract string
+	// Prefix is the gateway path the service is proxied under, e.g.
+	// /api/experiments.
+	Prefix string
+}
+
+// GatewayMounts are the services the gateway proxies.
+var GatewayMounts = []Mount{
+	{Contract: DatasetRegistry, Prefix: "/api/dataset-registry"},
+	{Contract: Quality, Prefix: "/api/quality"},
+	{Contract: Experiments, Prefix: "/api/experiments"},
+	{Contract: Lineage, Prefix: "/api/lineage"},
+	{Contract: Audit, Prefix: "/api/audit"},
+}
+
+// componentSections are the components whose names are namespaced per
+// service; refs into them are rewritten to match.
+var componentSections = []string{"schemas", "responses", "parameters", "examples", "requestBodies", "headers", "links", "callbacks"}
+
+// probePaths are served by every service and documented once by the gateway.
+var probePaths = map[string]bool{"/healthz": true, "/readyz": true, "/version": true, ServicePath: true}
+
+// Aggregate builds one contract for the gateway: the gateway's own paths and
+// components, plus every mounted service's paths under its prefix, replacing
+// the generic {proxyPath} entries. Service components are renamed with a
+// CamelCase service prefix (Experiments + Run → ExperimentsRun) so names
+// never collide, each operation is tagged with its service and, unless it
+// declares its own, gets the gateway's bearer security requirement.
+func Aggregate(mounts []Mount) (map[string]any, error) {
+	doc, err := Document(Gateway)
+	if err != nil {
+		return nil, err
+	}
+	paths := mapAt(doc, "paths")
+	components := mapAt(doc, "components")
+	security := doc["security"]
+	if _, ok := mapAt(components, "securitySchemes")["bearerAuth"]; ok && security == nil {
+		security = []any{map[string]any{"bearerAuth": []any{}}}
+	}
+
+	var tags []any
+	if existing, ok := doc["tags"].([]any); ok {
+		tags = existing
+	}
+	for _, mount := range mounts {
+		prefix := "/" + strings.Trim(mount.Prefix, "/")
+		delete(paths, prefix+"/{proxyPath}")
+
+		svc, err := Document(mount.Contract)
+		if err != nil {
+			return nil, err
+		}
+		namespace := componentPrefix(mount.Contract)
+		svcComponents := mapAt(svc, "components")
+		renames := make(map[string]string)
+		for _, section := range componentSections {
+			for name := range mapAt(svcComponents, section) {
+				renames["#/components/"+section+"/"+name] = "#/components/" + section + "/" + namespace + name
+			}
+		}
+		rewriteRefs(svcComponents, renames)
+		rewriteRefs(svc["paths"], renames)
+
+		for _, section := range componentSections {
+			target := mapAt(components, section)
+			for name, value := range mapAt(svcComponents, section) {
+				if _, exists := target[namespace+name]; exists {
+					return nil, fmt.Errorf("openapi: %s: component %s/%s collides", mount.Contract, section, namespace+name)
+				}
+				target[namespace+name] = value
+			}
+		}
+
+		for path, item := range mapAt(svc, "paths") {
+			if probePaths[path] {
+				continue
+			}
+			target := prefix + path
+			if _, exists := paths[target]; exists {
+				return nil, fmt.Errorf("openapi: %s: path %s collides", mount.Contract, target)
+			}
+			if ops, ok := item.(map[string]any); ok {
+				for method, op := range ops {
+					operation, ok := op.(map[string]any)
+					if !ok || !isOperation(method) {
+						continue
+					}
+					operation["tags"] = append([]any{mount.Contract}, anySlice(operation["tags"])...)
+					if _, ok := operation["security"]; !ok && security != nil {
+						operation["security"] = security
+					}
+				}
+			}
+			paths[target] = item
+		}
+		info := mapAt(svc, "info")
+		tags = append(tags, map[string]any{
+			"name":        mount.Contract,
+			"description": fmt.Sprintf("%s, proxied under %s.", info["title"], prefix),
+		})
+	}
+	for _, section := range componentSections {
+		if len(mapAt(components, section)) == 0 {
+			delete(components, section)
+		}
+	}
+	doc["tags"] = tags
+	return doc, nil
+}
+
+// AggregateHandler serves Aggregate(mounts) as JSON. It panics if the
+// contracts do not merge, so a bad contract fails the gateway at startup.
+func AggregateHandler(mounts []Mount) http.Handler {
+	doc, err := Aggregate(mounts)
+	if err != nil {
+		panic(err)
+	}
+	body, err := json.Marshal(doc)
+	if err != nil {
+		panic(err)
+	}
+	return staticJSON(body)
+}
+
+// componentPrefix turns dataset-registry into DatasetRegistry.
+func componentPrefix(name string) string {
+	var b strings.Builder
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool { return r == '-' || r == '_' }) {
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+// rewriteRefs replaces local $ref values found in renames, in place.
+func rewriteRefs(v any, renames map[string]string) {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, val := range t {
+			if ref, ok := val.(string); ok && k == "$ref" {
+				if renamed, ok := renames[ref]; ok {
+					t[k] = renamed
+				}
+				continue
+			}
+			rewriteRefs(val, renames)
+		}
+	case []any:
+		for _, val := range t {
+			rewriteRefs(val, renames)
+		}
+	}
+}
+
+func isOperation(method string) bool {
+	switch method {
+	case "get", "put", "post", "delete", "options", "head", "patch", "trace":
+		return true
+	default:
+		return false
+	}
+}
+
+// mapAt returns m[key] as a map, creating it when missing.
+func mapAt(m map[string]any, key string) map[string]any {
+	if existing, ok := m[key].(map[string]any); ok {
+		return existing
+	}
+	created := make(map[string]any)
+	m[key] = created
+	return created
+}
+
+func anySlice(v any) []any {
+	if s, ok := v.([]any); ok {
+		return s
+	}
+	return nil
+}
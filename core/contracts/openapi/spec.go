@@ -0,0 +1,174 @@
+// Package openapi embeds the service OpenAPI contracts in this directory and
+// serves them as JSON, per service and aggregated behind the gateway.
+//
+// The YAML files stay the source of truth; they are linted by
+// cmd/openapi-lint and checked against the baselines by make openapi-compat.
+package openapi
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed *.yaml
+var files embed.FS
+
+// Contract names, the YAML file names without extension.
+const (
+	Audit           = "audit"
+	Dataplane       = "dataplane_internal"
+	DatasetRegistry = "dataset-registry"
+	Experiments     = "experiments"
+	Gateway         = "gateway"
+	Lineage         = "lineage"
+	Quality         = "quality"
+)
+
+// ServicePath is where every service serves its contract; the gateway serves
+// the aggregated one there.
+const ServicePath = "/openapi.json"
+
+// Names lists the embedded contracts.
+func Names() []string {
+	entries, _ := files.ReadDir(".")
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if name, ok := strings.CutSuffix(entry.Name(), ".yaml"); ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// YAML returns the contract as written.
+func YAML(name string) ([]byte, error) {
+	raw, err := files.ReadFile(name + ".yaml")
+	if err != nil {
+		return nil, fmt.Errorf("openapi: unknown contract %q", name)
+	}
+	return raw, nil
+}
+
+var documents = sync.OnceValues(func() (map[string]map[string]any, error) {
+	out := make(map[string]map[string]any)
+	for _, name := range Names() {
+		raw, err := YAML(name)
+		if err != nil {
+			return nil, err
+		}
+		var doc any
+		if err := yaml.Unmarshal(raw, &doc); err != nil {
+			return nil, fmt.Errorf("openapi: %s: %w", name, err)
+		}
+		normalized, ok := normalize(doc).(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("openapi: %s: document is not a mapping", name)
+		}
+		out[name] = normalized
+	}
+	return out, nil
+})
+
+// Document returns a fresh copy of the decoded contract that the caller may
+// modify.
+func Document(name string) (map[string]any, error) {
+	docs, err := documents()
+	if err != nil {
+		return nil, err
+	}
+	doc, ok := docs[name]
+	if !ok {
+		return nil, fmt.Errorf("openapi: unknown contract %q", name)
+	}
+	return deepCopy(doc).(map[string]any), nil
+}
+
+// JSON returns the contract encoded as JSON.
+func JSON(name string) ([]byte, error) {
+	doc, err := Document(name)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(doc)
+}
+
+// Handler serves the named contract as JSON. It panics on an unknown name so
+// a misspelled registration fails at startup.
+func Handler(name string) http.Handler {
+	body, err := JSON(name)
+	if err != nil {
+		panic(err)
+	}
+	return staticJSON(body)
+}
+
+// staticJSON serves body with an ETag so clients can revalidate cheaply.
+func staticJSON(body []byte) http.Handler {
+	etag := fmt.Sprintf(`"%x"`, sha256.Sum256(body))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("ETag", etag)
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(body))
+	})
+}
+
+// normalize turns YAML decoder maps with non-string keys (unquoted status
+// codes) into string-keyed maps that encode as JSON.
+func normalize(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, val := range t {
+			t[k] = normalize(val)
+		}
+		return t
+	case map[any]any:
+		out := make(map[string]any, len(t))
+		for k, val := range t {
+			out[fmt.Sprint(k)] = normalize(val)
+		}
+		return out
+	case []any:
+		for i, val := range t {
+			t[i] = normalize(val)
+		}
+		return t
+	default:
+		return v
+	}
+}
+
+func deepCopy(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(t))
+		for k, val := range t {
+			out[k] = deepCopy(val)
+		}
+		return out
+	case []any:
+		out := make([]any, len(t))
+		for i, val := range t {
+			out[i] = deepCopy(val)
+		}
+		return out
+	default:
+		return v
+	}
+}
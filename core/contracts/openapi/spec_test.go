@@ -0,0 +1,219 @@
+package openapi
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// danglingRefs returns the local $refs in doc that do not resolve.
+func danglingRefs(doc map[string]any) []string {
+	var dangling []string
+	var walk func(v any)
+	walk = func(v any) {
+		switch t := v.(type) {
+		case map[string]any:
+			for k, val := range t {
+				if ref, ok := val.(string); ok && k == "$ref" && strings.HasPrefix(ref, "#/") && !resolves(doc, ref) {
+					dangling = append(dangling, ref)
+				}
+				walk(val)
+			}
+		case []any:
+			for _, val := range t {
+				walk(val)
+			}
+		}
+	}
+	walk(doc)
+	return dangling
+}
+
+func resolves(doc map[string]any, ref string) bool {
+	var cur any = doc
+	for _, part := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return false
+		}
+		part = strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")
+		if cur, ok = m[part]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func TestContractsConvertToJSON(t *testing.T) {
+	names := Names()
+	if len(names) != 7 {
+		t.Fatalf("unexpected contracts %v", names)
+	}
+	for _, name := range names {
+		raw, err := JSON(name)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		var doc map[string]any
+		if err := json.Unmarshal(raw, &doc); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if doc["openapi"] != "3.0.3" || len(mapAt(doc, "paths")) == 0 {
+			t.Fatalf("%s: openapi=%v", name, doc["openapi"])
+		}
+		if refs := danglingRefs(doc); len(refs) > 0 {
+			t.Errorf("%s: dangling refs %v", name, refs)
+		}
+	}
+}
+
+func TestAggregateMergesServices(t *testing.T) {
+	doc, err := Aggregate(GatewayMounts)
+	if err != nil {
+		t.Fatalf("aggregate: %v", err)
+	}
+	if refs := danglingRefs(doc); len(refs) > 0 {
+		t.Fatalf("dangling refs %v", refs)
+	}
+	paths := mapAt(doc, "paths")
+	for _, mount := range GatewayMounts {
+		if _, ok := paths[mount.Prefix+"/{proxyPath}"]; ok {
+			t.Fatalf("proxy placeholder for %s kept", mount.Prefix)
+		}
+	}
+	item, _ := paths["/api/experiments/projects/{project_id}/runs/{run_id}/cancel"].(map[string]any)
+	post, _ := item["post"].(map[string]any)
+	if post == nil {
+		t.Fatal("missing proxied experiments operation")
+	}
+	if tags := anySlice(post["tags"]); len(tags) == 0 || tags[0] != Experiments || post["security"] == nil {
+		t.Fatalf("tags=%v security=%v", post["tags"], post["security"])
+	}
+	raw, _ := json.Marshal(post)
+	if !strings.Contains(string(raw), `"#/components/schemas/ExperimentsRunCancelResponse"`) {
+		t.Fatalf("refs not namespaced: %s", raw)
+	}
+	schemas := mapAt(mapAt(doc, "components"), "schemas")
+	if schemas["ErrorResponse"] == nil || schemas["DatasetRegistryErrorResponse"] == nil {
+		t.Fatal("gateway and service components must both be present")
+	}
+	if _, ok := paths["/api/audit/healthz"]; ok {
+		t.Fatal("service probes must not be proxied")
+	}
+	if _, ok := paths["/healthz"]; !ok {
+		t.Fatal("gateway probe missing")
+	}
+}
+
+func TestHandlerServesJSONWithETag(t *testing.T) {
+	h := Handler(Lineage)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, ServicePath, nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/json" || !json.Valid(rec.Body.Bytes()) {
+		t.Fatalf("status=%d type=%q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	etag := rec.Header().Get("ETag")
+
+	req := httptest.NewRequest(http.MethodGet, ServicePath, nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified {
+		t.Fatalf("revalidation status %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, ServicePath, nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("post status %d", rec.Code)
+	}
+}
+
+// undocumentedRoutes are registered but intentionally absent from the
+// contracts.
+var undocumentedRoutes = map[string]string{
+	"GET /devenv-sessions/{session_id}/proxy/{path...}":                                       "reverse proxy into the dev environment",
+	"POST /experiment-runs/{run_id}/evidence-bundles/{bundle_id}":                             "documented as {bundle_id}:verify",
+	"POST /experiments/{experiment_id}":                                                       "documented as {experiment_id}:export",
+	"PUT /projects/{project_id}/mlflow/api/2.0/mlflow-artifacts/artifacts/{artifact_path...}": "documented as {artifact_path}",
+}
+
+// TestRegisteredRoutesAreDocumented checks every method-qualified route a
+// service registers against its contract; /internal/cp routes are in the
+// data plane contract.
+func TestRegisteredRoutesAreDocumented(t *testing.T) {
+	services := map[string][]string{
+		"audit":            {Audit},
+		"dataplane":        {Dataplane},
+		"dataset-registry": {DatasetRegistry},
+		"experiments":      {Experiments, Dataplane},
+		"lineage":          {Lineage},
+	}
+	for dir, contracts := range services {
+		documented := make(map[string]bool)
+		for _, name := range contracts {
+			doc, err := Document(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for path, item := range mapAt(doc, "paths") {
+				for method := range item.(map[string]any) {
+					if isOperation(method) {
+						documented[strings.ToUpper(method)+" "+path] = true
+					}
+				}
+			}
+		}
+		for _, route := range registeredRoutes(t, filepath.Join("..", "..", "..", "closed", dir)) {
+			if !documented[route] && undocumentedRoutes[route] == "" {
+				t.Errorf("%s: %s is not in the contract", dir, route)
+			}
+		}
+	}
+}
+
+// registeredRoutes returns the "METHOD /path" patterns passed to
+// HandleFunc or Handle in the package sources of dir.
+func registeredRoutes(t *testing.T, dir string) []string {
+	t.Helper()
+	pkgs, err := parser.ParseDir(token.NewFileSet(), dir, func(fi fs.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		t.Fatalf("parse %s: %v", dir, err)
+	}
+	var routes []string
+	for _, pkg := range pkgs {
+		ast.Inspect(pkg, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) == 0 {
+				return true
+			}
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok || (sel.Sel.Name != "HandleFunc" && sel.Sel.Name != "Handle") {
+				return true
+			}
+			lit, ok := call.Args[0].(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				return true
+			}
+			pattern, err := strconv.Unquote(lit.Value)
+			if err == nil && strings.Contains(pattern, " /") {
+				routes = append(routes, pattern)
+			}
+			return true
+		})
+	}
+	if len(routes) == 0 {
+		t.Fatalf("no routes found in %s", dir)
+	}
+	return routes
+}
@@ -1,17 +0,0 @@
-CREATE TABLE IF NOT EXISTS audit_events (
-  event_id BIGSERIAL PRIMARY KEY,
-  occurred_at TIMESTAMPTZ NOT NULL DEFAULT now(),
-  actor TEXT NOT NULL,
-  action TEXT NOT NULL,
-  resource_type TEXT NOT NULL,
-  resource_id TEXT NOT NULL,
-  request_id TEXT,
-  ip INET,
-  user_agent TEXT,
-  payload JSONB NOT NULL,
-  integrity_sha256 TEXT NOT NULL
-);
-
-CREATE INDEX IF NOT EXISTS idx_audit_events_occurred_at ON audit_events (occurred_at);
-CREATE INDEX IF NOT EXISTS idx_audit_events_resource ON audit_events (resource_type, resource_id);
-
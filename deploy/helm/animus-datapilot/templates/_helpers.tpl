@@ -104,3 +104,11 @@ helm.sh/chart: {{ printf "%s-%s" .Chart.Name .Chart.Version | quote }}
 {{- define "animus-datapilot.gatewayServiceURL" -}}
 {{- printf "http://%s-gateway:%d" (include "animus-datapilot.fullname" .) (.Values.services.gateway.port | int) -}}
 {{- end -}}
+
+{{- define "animus-datapilot.gridFactors" -}}
+{{- $pairs := list -}}
+{{- range $region, $factor := .Values.energy.gridFactors -}}
+{{- $pairs = append $pairs (printf "%s=%v" $region $factor) -}}
+{{- end -}}
+{{- join "," $pairs -}}
+{{- end -}}
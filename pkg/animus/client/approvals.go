@@ -0,0 +1,89 @@
+package client
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// PolicyApproval is a pending or decided request for a human approval of a
+// policy decision.
+type PolicyApproval struct {
+	ApprovalID      string     `json:"approval_id"`
+	DecisionID      string     `json:"decision_id"`
+	RunID           string     `json:"run_id,omitempty"`
+	Status          string     `json:"status"`
+	RequestedAt     time.Time  `json:"requested_at"`
+	RequestedBy     string     `json:"requested_by"`
+	DecidedAt       *time.Time `json:"decided_at,omitempty"`
+	DecidedBy       string     `json:"decided_by,omitempty"`
+	Reason          string     `json:"reason,omitempty"`
+	PolicyID        string     `json:"policy_id"`
+	PolicyName      string     `json:"policy_name"`
+	PolicyVersionID string     `json:"policy_version_id"`
+	Decision        string     `json:"decision"`
+	RuleID          string     `json:"rule_id,omitempty"`
+	CurrentStage    int        `json:"current_stage"`
+	StageName       string     `json:"stage_name"`
+	EscalateAt      *time.Time `json:"escalate_at,omitempty"`
+	AssignedTo      string     `json:"assigned_to,omitempty"`
+}
+
+// ApprovalQuery filters approvals; empty fields match everything.
+type ApprovalQuery struct {
+	Status string
+	RunID  string
+	Limit  int
+}
+
+// ApprovalVoteResponse is the outcome of a vote. ApprovalStatus stays
+// pending while later workflow stages still need votes.
+type ApprovalVoteResponse struct {
+	ApprovalID       string `json:"approval_id"`
+	ApprovalStatus   string `json:"approval_status"`
+	RunID            string `json:"run_id"`
+	RunStatus        string `json:"run_status"`
+	Stage            string `json:"stage,omitempty"`
+	StageApprovals   int    `json:"stage_approvals,omitempty"`
+	StageRequired    int    `json:"stage_required,omitempty"`
+	DispatchRequired bool   `json:"dispatch_required,omitempty"`
+}
+
+func (c *Client) ListPolicyApprovals(ctx context.Context, q ApprovalQuery) ([]PolicyApproval, error) {
+	query := limitQuery(q.Limit)
+	if status := strings.TrimSpace(q.Status); status != "" {
+		query.Set("status", status)
+	}
+	if runID := strings.TrimSpace(q.RunID); runID != "" {
+		query.Set("run_id", runID)
+	}
+	var out struct {
+		Approvals []PolicyApproval `json:"approvals"`
+	}
+	err := c.getJSON(ctx, experimentsPrefix+"/policy-approvals", query, &out)
+	return out.Approvals, err
+}
+
+func (c *Client) GetPolicyApproval(ctx context.Context, approvalID string) (PolicyApproval, error) {
+	var out PolicyApproval
+	err := c.getJSON(ctx, experimentsPrefix+"/policy-approvals/"+pathEscape(approvalID), nil, &out)
+	return out, err
+}
+
+// ApprovePolicyApproval votes to approve; reason is optional.
+func (c *Client) ApprovePolicyApproval(ctx context.Context, approvalID, reason string) (ApprovalVoteResponse, error) {
+	return c.voteApproval(ctx, approvalID, "approve", reason)
+}
+
+// DenyPolicyApproval denies the approval and cancels its run.
+func (c *Client) DenyPolicyApproval(ctx context.Context, approvalID, reason string) (ApprovalVoteResponse, error) {
+	return c.voteApproval(ctx, approvalID, "deny", reason)
+}
+
+func (c *Client) voteApproval(ctx context.Context, approvalID, action, reason string) (ApprovalVoteResponse, error) {
+	var out ApprovalVoteResponse
+	err := c.postJSON(ctx, experimentsPrefix+"/policy-approvals/"+pathEscape(approvalID)+"/"+action, struct {
+		Reason string `json:"reason,omitempty"`
+	}{Reason: strings.TrimSpace(reason)}, &out)
+	return out, err
+}
@@ -0,0 +1,100 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"iter"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type AuditEvent struct {
+	EventID         int64           `json:"event_id"`
+	OccurredAt      time.Time       `json:"occurred_at"`
+	Actor           string          `json:"actor"`
+	Action          string          `json:"action"`
+	ResourceType    string          `json:"resource_type"`
+	ResourceID      string          `json:"resource_id"`
+	RequestID       string          `json:"request_id,omitempty"`
+	IP              string          `json:"ip,omitempty"`
+	UserAgent       string          `json:"user_agent,omitempty"`
+	Payload         json.RawMessage `json:"payload"`
+	IntegritySHA256 string          `json:"integrity_sha256"`
+}
+
+// AuditQuery filters audit events; empty fields match everything.
+type AuditQuery struct {
+	Actor        string
+	Action       string
+	ResourceType string
+	ResourceID   string
+	RequestID    string
+	// BeforeEventID starts the listing below this event.
+	BeforeEventID int64
+	// PageSize is the page limit; 0 uses the server default.
+	PageSize int
+}
+
+func (q AuditQuery) values() url.Values {
+	query := limitQuery(q.PageSize)
+	for key, value := range map[string]string{
+		"actor":         q.Actor,
+		"action":        q.Action,
+		"resource_type": q.ResourceType,
+		"resource_id":   q.ResourceID,
+		"request_id":    q.RequestID,
+	} {
+		if value = strings.TrimSpace(value); value != "" {
+			query.Set(key, value)
+		}
+	}
+	if q.BeforeEventID > 0 {
+		query.Set("before_event_id", strconv.FormatInt(q.BeforeEventID, 10))
+	}
+	return query
+}
+
+// AuditEventPage is one page of events, newest first. NextBeforeEventID
+// continues the listing; it is 0 on an empty page.
+type AuditEventPage struct {
+	Events            []AuditEvent `json:"events"`
+	NextBeforeEventID int64        `json:"next_before_event_id,omitempty"`
+}
+
+func (c *Client) ListAuditEvents(ctx context.Context, q AuditQuery) (AuditEventPage, error) {
+	var out AuditEventPage
+	err := c.getJSON(ctx, auditPrefix+"/events", q.values(), &out)
+	return out, err
+}
+
+func (c *Client) GetAuditEvent(ctx context.Context, eventID int64) (AuditEvent, error) {
+	var out AuditEvent
+	err := c.getJSON(ctx, auditPrefix+"/events/"+strconv.FormatInt(eventID, 10), nil, &out)
+	return out, err
+}
+
+// AuditEvents iterates over all matching events, newest first, fetching
+// pages as needed. Iteration stops after the first error, which is yielded
+// with a zero event.
+func (c *Client) AuditEvents(ctx context.Context, q AuditQuery) iter.Seq2[AuditEvent, error] {
+	return func(yield func(AuditEvent, error) bool) {
+		for {
+			page, err := c.ListAuditEvents(ctx, q)
+			if err != nil {
+				yield(AuditEvent{}, err)
+				return
+			}
+			for _, event := range page.Events {
+				if !yield(event, nil) {
+					return
+				}
+			}
+			if len(page.Events) == 0 || page.NextBeforeEventID <= 0 {
+				return
+			}
+			q.BeforeEventID = page.NextBeforeEventID
+		}
+	}
+}
@@ -0,0 +1,17 @@
+package client
+
+import "context"
+
+// Identity is the caller as the gateway authenticated it.
+type Identity struct {
+	UserID string   `json:"user_id"`
+	Email  string   `json:"email,omitempty"`
+	Roles  []string `json:"roles"`
+}
+
+// Me returns the identity of the client's token.
+func (c *Client) Me(ctx context.Context) (Identity, error) {
+	var out Identity
+	err := c.getJSON(ctx, "/api/auth/me", nil, &out)
+	return out, err
+}
@@ -0,0 +1,378 @@
+// Package client is a Go client for the Animus DataPilot API as exposed by the
+// gateway. It covers datasets and versions, quality rules and evaluations,
+// experiments, runs, metrics and artifacts, project runs, policies and
+// approvals, evidence bundles, run lineage and audit events.
+//
+// Idempotent requests are retried with exponential backoff on transport
+// errors, 429 and 502-504; list endpoints that page (audit events) are
+// exposed as iterators.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Service path prefixes on the gateway.
+const (
+	datasetRegistryPrefix = "/api/dataset-registry"
+	qualityPrefix         = "/api/quality"
+	experimentsPrefix     = "/api/experiments"
+	lineagePrefix         = "/api/lineage"
+	auditPrefix           = "/api/audit"
+)
+
+const maxResponseBytes = 8 << 20
+
+// Config configures a Client. Only BaseURL is required.
+type Config struct {
+	// BaseURL is the gateway URL, e.g. https://animus.example.com.
+	BaseURL string
+	// Token is sent as a bearer token when set.
+	Token string
+	// ProjectID is sent as X-Project-Id on every request; WithProject
+	// overrides it per client.
+	ProjectID string
+	// UserAgent defaults to animus-go-client.
+	UserAgent string
+	// HTTPClient defaults to a client with a 30s timeout.
+	HTTPClient *http.Client
+	// MaxRetries bounds retries of idempotent requests; 0 means 3, a
+	// negative value disables retries.
+	MaxRetries int
+	// RetryBaseDelay and RetryMaxDelay shape the exponential backoff; they
+	// default to 200ms and 5s.
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+}
+
+// Client calls the API. It is safe for concurrent use.
+type Client struct {
+	baseURL    string
+	token      string
+	projectID  string
+	userAgent  string
+	http       *http.Client
+	maxRetries int
+	baseDelay  time.Duration
+	maxDelay   time.Duration
+	sleep      func(ctx context.Context, d time.Duration) error
+}
+
+// New validates cfg and returns a Client.
+func New(cfg Config) (*Client, error) {
+	baseURL := strings.TrimRight(strings.TrimSpace(cfg.BaseURL), "/")
+	if baseURL == "" {
+		return nil, errors.New("client: base url is required")
+	}
+	parsed, err := url.Parse(baseURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("client: base url must be an absolute http(s) URL, got %q", cfg.BaseURL)
+	}
+	c := &Client{
+		baseURL:    baseURL,
+		token:      strings.TrimSpace(cfg.Token),
+		projectID:  strings.TrimSpace(cfg.ProjectID),
+		userAgent:  strings.TrimSpace(cfg.UserAgent),
+		http:       cfg.HTTPClient,
+		maxRetries: cfg.MaxRetries,
+		baseDelay:  cfg.RetryBaseDelay,
+		maxDelay:   cfg.RetryMaxDelay,
+		sleep:      sleepContext,
+	}
+	if c.userAgent == "" {
+		c.userAgent = "animus-go-client"
+	}
+	if c.http == nil {
+		c.http = &http.Client{Timeout: 30 * time.Second}
+	}
+	switch {
+	case c.maxRetries == 0:
+		c.maxRetries = 3
+	case c.maxRetries < 0:
+		c.maxRetries = 0
+	}
+	if c.baseDelay <= 0 {
+		c.baseDelay = 200 * time.Millisecond
+	}
+	if c.maxDelay <= 0 {
+		c.maxDelay = 5 * time.Second
+	}
+	return c, nil
+}
+
+// WithProject returns a copy of the client scoped to projectID.
+func (c *Client) WithProject(projectID string) *Client {
+	out := *c
+	out.projectID = strings.TrimSpace(projectID)
+	return &out
+}
+
+// Error is a non-2xx API response.
+type Error struct {
+	StatusCode int
+	// Code is the error field of the response body, e.g. not_found.
+	Code      string
+	RequestID string
+	Method    string
+	Path      string
+	// Body holds the raw body when it is not an error document.
+	Body string
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("animus: %s %s: status %d", e.Method, e.Path, e.StatusCode)
+	if e.Code != "" {
+		msg += ": " + e.Code
+	} else if e.Body != "" {
+		msg += ": " + e.Body
+	}
+	if e.RequestID != "" {
+		msg += " (request_id=" + e.RequestID + ")"
+	}
+	return msg
+}
+
+// IsNotFound reports whether err is a 404 API error.
+func IsNotFound(err error) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// request describes one API call. Body, when set, must be replayable.
+type request struct {
+	method      string
+	path        string
+	query       url.Values
+	body        []byte
+	contentType string
+	header      http.Header
+}
+
+func (c *Client) getJSON(ctx context.Context, path string, query url.Values, out any) error {
+	return c.doJSON(ctx, request{method: http.MethodGet, path: path, query: query}, out)
+}
+
+func (c *Client) postJSON(ctx context.Context, path string, in any, out any) error {
+	payload, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("client: encode request: %w", err)
+	}
+	return c.doJSON(ctx, request{method: http.MethodPost, path: path, body: payload, contentType: "application/json"}, out)
+}
+
+func (c *Client) doJSON(ctx context.Context, req request, out any) error {
+	body, err := c.do(ctx, req)
+	if err != nil {
+		return err
+	}
+	if out == nil || len(body) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("client: decode %s %s: %w", req.method, req.path, err)
+	}
+	return nil
+}
+
+// do sends req, retrying idempotent methods, and returns the response body.
+func (c *Client) do(ctx context.Context, req request) ([]byte, error) {
+	retries := 0
+	if idempotentMethod(req.method) {
+		retries = c.maxRetries
+	}
+	for attempt := 0; ; attempt++ {
+		body, retryAfter, err := c.send(ctx, req)
+		if err == nil {
+			return body, nil
+		}
+		if attempt >= retries || !retryable(err) || ctx.Err() != nil {
+			return nil, err
+		}
+		delay := backoffDelay(attempt+1, c.baseDelay, c.maxDelay)
+		if retryAfter > delay {
+			delay = retryAfter
+		}
+		if err := c.sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (c *Client) send(ctx context.Context, req request) ([]byte, time.Duration, error) {
+	httpReq, err := c.newRequest(ctx, req)
+	if err != nil {
+		return nil, 0, err
+	}
+	resp, err := c.http.Do(httpReq)
+	if err != nil {
+		return nil, 0, &transportError{err: err}
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return nil, 0, &transportError{err: err}
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		return respBody, 0, nil
+	}
+	return nil, retryAfter(resp.Header.Get("Retry-After")), responseError(req, resp, respBody)
+}
+
+// stream sends req once and returns the body of a 2xx response unread.
+func (c *Client) stream(ctx context.Context, req request) (*http.Response, error) {
+	httpReq, err := c.newRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.http.Do(httpReq)
+	if err != nil {
+		return nil, &transportError{err: err}
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	return nil, responseError(req, resp, respBody)
+}
+
+func (c *Client) newRequest(ctx context.Context, req request) (*http.Request, error) {
+	endpoint := c.baseURL + req.path
+	if len(req.query) > 0 {
+		endpoint += "?" + req.query.Encode()
+	}
+	var body io.Reader
+	if req.body != nil {
+		body = bytes.NewReader(req.body)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range req.header {
+		for _, value := range values {
+			httpReq.Header.Add(key, value)
+		}
+	}
+	if httpReq.Header.Get("Accept") == "" {
+		httpReq.Header.Set("Accept", "application/json")
+	}
+	httpReq.Header.Set("User-Agent", c.userAgent)
+	if req.contentType != "" {
+		httpReq.Header.Set("Content-Type", req.contentType)
+	}
+	if c.token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if c.projectID != "" {
+		httpReq.Header.Set("X-Project-Id", c.projectID)
+	}
+	return httpReq, nil
+}
+
+func responseError(req request, resp *http.Response, body []byte) error {
+	apiErr := &Error{
+		StatusCode: resp.StatusCode,
+		RequestID:  resp.Header.Get("X-Request-Id"),
+		Method:     req.method,
+		Path:       req.path,
+	}
+	var doc struct {
+		Error     string `json:"error"`
+		RequestID string `json:"request_id"`
+	}
+	if json.Unmarshal(body, &doc) == nil && doc.Error != "" {
+		apiErr.Code = doc.Error
+		if doc.RequestID != "" {
+			apiErr.RequestID = doc.RequestID
+		}
+	} else {
+		apiErr.Body = strings.TrimSpace(string(body))
+	}
+	return apiErr
+}
+
+type transportError struct {
+	err error
+}
+
+func (e *transportError) Error() string { return "animus: " + e.err.Error() }
+func (e *transportError) Unwrap() error { return e.err }
+
+func retryable(err error) bool {
+	var transport *transportError
+	if errors.As(err, &transport) {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+	}
+	return false
+}
+
+// idempotentMethod reports whether a request may be replayed. POSTs create
+// objects and are never retried.
+func idempotentMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func backoffDelay(attempt int, base, max time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempt && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		return max
+	}
+	return delay
+}
+
+// retryAfter parses a Retry-After header given in seconds.
+func retryAfter(raw string) time.Duration {
+	seconds, err := strconv.Atoi(strings.TrimSpace(raw))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func limitQuery(limit int) url.Values {
+	query := url.Values{}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	return query
+}
+
+func pathEscape(id string) string {
+	return url.PathEscape(strings.TrimSpace(id))
+}
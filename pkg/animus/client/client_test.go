@@ -0,0 +1,210 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	c, err := New(Config{BaseURL: srv.URL, Token: "tok", ProjectID: "proj-1"})
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	c.sleep = func(context.Context, time.Duration) error { return nil }
+	return c
+}
+
+func TestNewRequiresAbsoluteURL(t *testing.T) {
+	for _, raw := range []string{"", "localhost:8080", "ftp://example.com"} {
+		if _, err := New(Config{BaseURL: raw}); err == nil {
+			t.Fatalf("expected error for %q", raw)
+		}
+	}
+}
+
+func TestGetRetriesUnavailable(t *testing.T) {
+	var calls atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tok" || r.Header.Get("X-Project-Id") != "proj-1" {
+			t.Errorf("missing auth headers: %v", r.Header)
+		}
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"experiment_id":"exp-1","name":"demo","metadata":{},"created_at":"2026-01-02T03:04:05Z","created_by":"u"}`))
+	})
+	exp, err := c.GetExperiment(context.Background(), "exp-1")
+	if err != nil {
+		t.Fatalf("get experiment: %v", err)
+	}
+	if exp.ExperimentID != "exp-1" || calls.Load() != 3 {
+		t.Fatalf("experiment=%+v calls=%d", exp, calls.Load())
+	}
+}
+
+func TestPostIsNotRetried(t *testing.T) {
+	var calls atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"error":"db_unavailable","request_id":"req-9"}`))
+	})
+	_, err := c.CreateExperiment(context.Background(), CreateExperimentRequest{Name: "demo"})
+	apiErr, ok := err.(*Error)
+	if !ok {
+		t.Fatalf("expected *Error, got %T %v", err, err)
+	}
+	if apiErr.StatusCode != http.StatusServiceUnavailable || apiErr.Code != "db_unavailable" || apiErr.RequestID != "req-9" {
+		t.Fatalf("unexpected error %+v", apiErr)
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("expected one call, got %d", calls.Load())
+	}
+}
+
+func TestNotFoundIsNotRetried(t *testing.T) {
+	var calls atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":"not_found","request_id":"req-1"}`))
+	})
+	_, err := c.GetDataset(context.Background(), "ds-1")
+	if !IsNotFound(err) || calls.Load() != 1 {
+		t.Fatalf("err=%v calls=%d", err, calls.Load())
+	}
+}
+
+func TestBackoffDelay(t *testing.T) {
+	base, max := 100*time.Millisecond, time.Second
+	for attempt, want := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 4: 800 * time.Millisecond, 9: time.Second} {
+		if got := backoffDelay(attempt, base, max); got != want {
+			t.Fatalf("attempt %d: got %s want %s", attempt, got, want)
+		}
+	}
+	if got := retryAfter("3"); got != 3*time.Second {
+		t.Fatalf("retry-after: %s", got)
+	}
+}
+
+func TestAuditEventsIteratesPages(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/audit/events" || r.URL.Query().Get("action") != "run.create" {
+			t.Errorf("unexpected request %s", r.URL.String())
+		}
+		before, _ := strconv.Atoi(r.URL.Query().Get("before_event_id"))
+		if before == 0 {
+			before = 6
+		}
+		page := AuditEventPage{}
+		for id := before - 1; id > 0 && id > before-3; id-- {
+			page.Events = append(page.Events, AuditEvent{EventID: int64(id), Action: "run.create"})
+		}
+		if len(page.Events) > 0 {
+			page.NextBeforeEventID = page.Events[len(page.Events)-1].EventID
+		}
+		_ = json.NewEncoder(w).Encode(page)
+	})
+
+	var ids []int64
+	for event, err := range c.AuditEvents(context.Background(), AuditQuery{Action: "run.create", PageSize: 2}) {
+		if err != nil {
+			t.Fatalf("iterate: %v", err)
+		}
+		ids = append(ids, event.EventID)
+	}
+	if len(ids) != 5 || ids[0] != 5 || ids[4] != 1 {
+		t.Fatalf("unexpected ids %v", ids)
+	}
+}
+
+func TestUploadDatasetVersionMultipart(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/dataset-registry/datasets/ds-1/versions/upload" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("parse form: %v", err)
+		}
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			t.Errorf("form file: %v", err)
+			return
+		}
+		content, _ := io.ReadAll(file)
+		if header.Filename != "demo.csv" || string(content) != "a,b\n" || r.FormValue("quality_rule_id") != "rule-1" || !strings.Contains(r.FormValue("metadata"), `"source":"demo"`) {
+			t.Errorf("unexpected upload %s %q %v", header.Filename, content, r.MultipartForm.Value)
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"version_id":"ver-1","dataset_id":"ds-1","ordinal":1,"content_sha256":"abc","object_key":"k","metadata":{},"created_at":"2026-01-02T03:04:05Z","created_by":"u"}`))
+	})
+	version, err := c.UploadDatasetVersion(context.Background(), "ds-1", UploadDatasetVersionRequest{
+		Filename:      "data/demo.csv",
+		ContentType:   "text/csv",
+		Content:       strings.NewReader("a,b\n"),
+		Metadata:      map[string]any{"source": "demo"},
+		QualityRuleID: "rule-1",
+	})
+	if err != nil {
+		t.Fatalf("upload: %v", err)
+	}
+	if version.VersionID != "ver-1" || version.Ordinal != 1 {
+		t.Fatalf("unexpected version %+v", version)
+	}
+}
+
+func TestWithProjectOverridesHeader(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("X-Project-Id"); got != "proj-2" {
+			t.Errorf("project header %q", got)
+		}
+		_, _ = w.Write([]byte(`{"experiments":[]}`))
+	})
+	if _, err := c.WithProject("proj-2").ListExperiments(context.Background(), "", 10); err != nil {
+		t.Fatalf("list: %v", err)
+	}
+}
+
+func TestWatchRunReportsStateChanges(t *testing.T) {
+	states := []string{"planned", "running", "running", "succeeded"}
+	var calls atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/experiments/projects/proj-1/runs/run-1" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		state := states[min(int(calls.Add(1))-1, len(states)-1)]
+		_ = json.NewEncoder(w).Encode(Run{RunID: "run-1", State: state})
+	})
+	var seen []string
+	run, err := c.WatchRun(context.Background(), "run-1", time.Millisecond, func(run Run) {
+		seen = append(seen, run.State)
+	})
+	if err != nil {
+		t.Fatalf("watch: %v", err)
+	}
+	if run.State != "succeeded" || strings.Join(seen, ",") != "planned,running,succeeded" || calls.Load() != 4 {
+		t.Fatalf("run=%+v seen=%v calls=%d", run, seen, calls.Load())
+	}
+}
+
+func TestProjectRunsRequireProject(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s", r.URL.Path)
+	})
+	if _, err := c.WithProject("").CancelRun(context.Background(), "run-1", ""); err == nil {
+		t.Fatal("expected error without project")
+	}
+}
@@ -0,0 +1,165 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Dataset is a dataset registry entry.
+type Dataset struct {
+	DatasetID      string          `json:"dataset_id"`
+	Name           string          `json:"name"`
+	Description    string          `json:"description,omitempty"`
+	Metadata       map[string]any  `json:"metadata"`
+	CreatedAt      time.Time       `json:"created_at"`
+	CreatedBy      string          `json:"created_by"`
+	LifecycleState string          `json:"lifecycle_state"`
+	Freshness      json.RawMessage `json:"freshness,omitempty"`
+	Protection     json.RawMessage `json:"protection,omitempty"`
+}
+
+// CreateDatasetRequest creates a dataset in the client's project.
+type CreateDatasetRequest struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Metadata    map[string]any `json:"metadata,omitempty"`
+}
+
+// DatasetVersion is an immutable uploaded version of a dataset.
+type DatasetVersion struct {
+	VersionID              string          `json:"version_id"`
+	DatasetID              string          `json:"dataset_id"`
+	QualityRuleID          string          `json:"quality_rule_id,omitempty"`
+	Ordinal                int64           `json:"ordinal"`
+	ContentSHA256          string          `json:"content_sha256"`
+	ObjectKey              string          `json:"object_key"`
+	SizeBytes              int64           `json:"size_bytes,omitempty"`
+	Metadata               map[string]any  `json:"metadata"`
+	CreatedAt              time.Time       `json:"created_at"`
+	CreatedBy              string          `json:"created_by"`
+	DataContractEvaluation json.RawMessage `json:"data_contract_evaluation,omitempty"`
+	Encryption             json.RawMessage `json:"encryption,omitempty"`
+	Recall                 json.RawMessage `json:"recall,omitempty"`
+	Profile                json.RawMessage `json:"profile,omitempty"`
+}
+
+// UploadDatasetVersionRequest uploads Content as a new dataset version.
+type UploadDatasetVersionRequest struct {
+	// Filename is sent with the file part; its suffix matters to quality
+	// rules.
+	Filename    string
+	ContentType string
+	Content     io.Reader
+	Metadata    map[string]any
+	// QualityRuleID defaults to the project's default rule when empty.
+	QualityRuleID string
+}
+
+func (c *Client) CreateDataset(ctx context.Context, in CreateDatasetRequest) (Dataset, error) {
+	var out Dataset
+	err := c.postJSON(ctx, datasetRegistryPrefix+"/datasets", in, &out)
+	return out, err
+}
+
+func (c *Client) GetDataset(ctx context.Context, datasetID string) (Dataset, error) {
+	var out Dataset
+	err := c.getJSON(ctx, datasetRegistryPrefix+"/datasets/"+pathEscape(datasetID), nil, &out)
+	return out, err
+}
+
+// ListDatasets returns up to limit datasets, newest first; limit 0 uses the
+// server default.
+func (c *Client) ListDatasets(ctx context.Context, limit int) ([]Dataset, error) {
+	var out struct {
+		Datasets []Dataset `json:"datasets"`
+	}
+	err := c.getJSON(ctx, datasetRegistryPrefix+"/datasets", limitQuery(limit), &out)
+	return out.Datasets, err
+}
+
+func (c *Client) ListDatasetVersions(ctx context.Context, datasetID string, limit int) ([]DatasetVersion, error) {
+	var out struct {
+		Versions []DatasetVersion `json:"versions"`
+	}
+	err := c.getJSON(ctx, datasetRegistryPrefix+"/datasets/"+pathEscape(datasetID)+"/versions", limitQuery(limit), &out)
+	return out.Versions, err
+}
+
+func (c *Client) GetDatasetVersion(ctx context.Context, versionID string) (DatasetVersion, error) {
+	var out DatasetVersion
+	err := c.getJSON(ctx, datasetRegistryPrefix+"/dataset-versions/"+pathEscape(versionID), nil, &out)
+	return out, err
+}
+
+// UploadDatasetVersion buffers the content to build the multipart body; it is
+// not retried.
+func (c *Client) UploadDatasetVersion(ctx context.Context, datasetID string, in UploadDatasetVersionRequest) (DatasetVersion, error) {
+	fields := map[string]string{}
+	if in.Metadata != nil {
+		metadata, err := json.Marshal(in.Metadata)
+		if err != nil {
+			return DatasetVersion{}, fmt.Errorf("client: encode metadata: %w", err)
+		}
+		fields["metadata"] = string(metadata)
+	}
+	if rule := strings.TrimSpace(in.QualityRuleID); rule != "" {
+		fields["quality_rule_id"] = rule
+	}
+	body, contentType, err := multipartBody(fields, in.Filename, in.ContentType, in.Content)
+	if err != nil {
+		return DatasetVersion{}, err
+	}
+	var out DatasetVersion
+	err = c.doJSON(ctx, request{
+		method:      http.MethodPost,
+		path:        datasetRegistryPrefix + "/datasets/" + pathEscape(datasetID) + "/versions/upload",
+		body:        body,
+		contentType: contentType,
+	}, &out)
+	return out, err
+}
+
+// multipartBody encodes fields and a "file" part.
+func multipartBody(fields map[string]string, filename, contentType string, content io.Reader) ([]byte, string, error) {
+	if content == nil {
+		return nil, "", fmt.Errorf("client: content is required")
+	}
+	filename = filepath.Base(strings.TrimSpace(filename))
+	if filename == "" || filename == "." {
+		return nil, "", fmt.Errorf("client: filename is required")
+	}
+	if strings.TrimSpace(contentType) == "" {
+		contentType = "application/octet-stream"
+	}
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	for key, value := range fields {
+		if err := writer.WriteField(key, value); err != nil {
+			return nil, "", err
+		}
+	}
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename=%q`, filename))
+	header.Set("Content-Type", contentType)
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := io.Copy(part, content); err != nil {
+		return nil, "", fmt.Errorf("client: read content: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), writer.FormDataContentType(), nil
+}
@@ -0,0 +1,93 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+// EvidenceBundle is a signed evidence archive of a run.
+type EvidenceBundle struct {
+	BundleID        string    `json:"bundle_id"`
+	RunID           string    `json:"run_id"`
+	BundleSHA256    string    `json:"bundle_sha256"`
+	BundleSizeBytes int64     `json:"bundle_size_bytes"`
+	ReportSHA256    string    `json:"report_sha256"`
+	ReportSizeBytes int64     `json:"report_size_bytes"`
+	Signature       string    `json:"signature"`
+	SignatureAlg    string    `json:"signature_alg"`
+	SigningKeyID    string    `json:"signing_key_id,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+	CreatedBy       string    `json:"created_by"`
+}
+
+type EvidenceCheck struct {
+	Name     string `json:"name"`
+	OK       bool   `json:"ok"`
+	Expected string `json:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty"`
+	Detail   string `json:"detail,omitempty"`
+}
+
+type EvidenceFileCheck struct {
+	Name           string `json:"name"`
+	OK             bool   `json:"ok"`
+	ExpectedSHA256 string `json:"expected_sha256"`
+	ActualSHA256   string `json:"actual_sha256,omitempty"`
+	Detail         string `json:"detail,omitempty"`
+}
+
+// EvidenceVerification is the server-side verification report of a bundle.
+type EvidenceVerification struct {
+	BundleID     string              `json:"bundle_id"`
+	RunID        string              `json:"run_id"`
+	Valid        bool                `json:"valid"`
+	FailedChecks []string            `json:"failed_checks"`
+	Checks       []EvidenceCheck     `json:"checks"`
+	Files        []EvidenceFileCheck `json:"files"`
+	VerifiedAt   time.Time           `json:"verified_at"`
+	VerifiedBy   string              `json:"verified_by"`
+}
+
+func (c *Client) evidencePath(runID string) string {
+	return experimentsPrefix + "/experiment-runs/" + pathEscape(runID) + "/evidence-bundles"
+}
+
+func (c *Client) ListEvidenceBundles(ctx context.Context, runID string, limit int) ([]EvidenceBundle, error) {
+	var out struct {
+		Bundles []EvidenceBundle `json:"bundles"`
+	}
+	err := c.getJSON(ctx, c.evidencePath(runID), limitQuery(limit), &out)
+	return out.Bundles, err
+}
+
+func (c *Client) CreateEvidenceBundle(ctx context.Context, runID string) (EvidenceBundle, error) {
+	var out struct {
+		Bundle EvidenceBundle `json:"bundle"`
+	}
+	err := c.postJSON(ctx, c.evidencePath(runID), struct{}{}, &out)
+	return out.Bundle, err
+}
+
+// DownloadEvidenceBundle streams the bundle zip. The caller closes the
+// reader; the download is not retried.
+func (c *Client) DownloadEvidenceBundle(ctx context.Context, runID, bundleID string) (io.ReadCloser, error) {
+	resp, err := c.stream(ctx, request{
+		method: http.MethodGet,
+		path:   c.evidencePath(runID) + "/" + pathEscape(bundleID) + "/download",
+		header: http.Header{"Accept": []string{"*/*"}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// VerifyEvidenceBundle has the server re-check the bundle's hashes and
+// signature. An invalid bundle is not an error; check Valid.
+func (c *Client) VerifyEvidenceBundle(ctx context.Context, runID, bundleID string) (EvidenceVerification, error) {
+	var out EvidenceVerification
+	err := c.postJSON(ctx, c.evidencePath(runID)+"/"+pathEscape(bundleID)+":verify", struct{}{}, &out)
+	return out, err
+}
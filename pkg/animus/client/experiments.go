@@ -0,0 +1,248 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type Experiment struct {
+	ExperimentID string         `json:"experiment_id"`
+	Name         string         `json:"name"`
+	Description  string         `json:"description,omitempty"`
+	Metadata     map[string]any `json:"metadata"`
+	CreatedAt    time.Time      `json:"created_at"`
+	CreatedBy    string         `json:"created_by"`
+}
+
+type CreateExperimentRequest struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Metadata    map[string]any `json:"metadata,omitempty"`
+}
+
+// ExperimentRun is a run registered against an experiment.
+type ExperimentRun struct {
+	RunID             string         `json:"run_id"`
+	ExperimentID      string         `json:"experiment_id"`
+	DatasetVersionID  string         `json:"dataset_version_id,omitempty"`
+	DatasetVersionIDs []string       `json:"dataset_version_ids,omitempty"`
+	Status            string         `json:"status"`
+	StartedAt         time.Time      `json:"started_at"`
+	EndedAt           *time.Time     `json:"ended_at,omitempty"`
+	GitRepo           string         `json:"git_repo,omitempty"`
+	GitCommit         string         `json:"git_commit,omitempty"`
+	GitRef            string         `json:"git_ref,omitempty"`
+	Params            map[string]any `json:"params"`
+	Metrics           map[string]any `json:"metrics"`
+	ArtifactsPrefix   string         `json:"artifacts_prefix,omitempty"`
+}
+
+type CreateExperimentRunRequest struct {
+	DatasetVersionID  string         `json:"dataset_version_id,omitempty"`
+	DatasetVersionIDs []string       `json:"dataset_version_ids,omitempty"`
+	Status            string         `json:"status"`
+	StartedAt         *time.Time     `json:"started_at,omitempty"`
+	EndedAt           *time.Time     `json:"ended_at,omitempty"`
+	GitRepo           string         `json:"git_repo,omitempty"`
+	GitCommit         string         `json:"git_commit,omitempty"`
+	GitRef            string         `json:"git_ref,omitempty"`
+	Params            map[string]any `json:"params,omitempty"`
+	Metrics           map[string]any `json:"metrics,omitempty"`
+	ArtifactsPrefix   string         `json:"artifacts_prefix,omitempty"`
+}
+
+// IngestMetricsRequest records metric values for one training step.
+type IngestMetricsRequest struct {
+	Step     int64              `json:"step"`
+	Metrics  map[string]float64 `json:"metrics"`
+	Metadata map[string]any     `json:"metadata,omitempty"`
+}
+
+type MetricAnomaly struct {
+	Kind      string   `json:"kind"`
+	Metric    string   `json:"metric"`
+	Step      int64    `json:"step"`
+	Value     string   `json:"value"`
+	Reference *float64 `json:"reference,omitempty"`
+	SinceStep int64    `json:"since_step"`
+	Fail      bool     `json:"fail"`
+}
+
+type IngestMetricsResponse struct {
+	RunID     string          `json:"run_id"`
+	Step      int64           `json:"step"`
+	Inserted  int             `json:"inserted"`
+	Received  int             `json:"received"`
+	RequestID string          `json:"request_id"`
+	Anomalies []MetricAnomaly `json:"anomalies,omitempty"`
+	RunFailed bool            `json:"run_failed,omitempty"`
+}
+
+type MetricSample struct {
+	SampleID   string         `json:"sample_id"`
+	RunID      string         `json:"run_id"`
+	RecordedAt time.Time      `json:"recorded_at"`
+	RecordedBy string         `json:"recorded_by"`
+	Step       int64          `json:"step"`
+	Name       string         `json:"name"`
+	Value      float64        `json:"value"`
+	Metadata   map[string]any `json:"metadata"`
+}
+
+type Artifact struct {
+	ArtifactID  string         `json:"artifact_id"`
+	RunID       string         `json:"run_id"`
+	Kind        string         `json:"kind"`
+	Name        string         `json:"name,omitempty"`
+	Filename    string         `json:"filename,omitempty"`
+	ContentType string         `json:"content_type,omitempty"`
+	ObjectKey   string         `json:"object_key"`
+	SHA256      string         `json:"sha256"`
+	SizeBytes   int64          `json:"size_bytes"`
+	Metadata    map[string]any `json:"metadata"`
+	CreatedAt   time.Time      `json:"created_at"`
+	CreatedBy   string         `json:"created_by"`
+}
+
+// CreateArtifactRequest uploads Content as a run artifact of the given kind.
+type CreateArtifactRequest struct {
+	Kind        string
+	Name        string
+	Filename    string
+	ContentType string
+	Content     io.Reader
+	Metadata    map[string]any
+}
+
+func (c *Client) CreateExperiment(ctx context.Context, in CreateExperimentRequest) (Experiment, error) {
+	var out Experiment
+	err := c.postJSON(ctx, experimentsPrefix+"/experiments", in, &out)
+	return out, err
+}
+
+func (c *Client) GetExperiment(ctx context.Context, experimentID string) (Experiment, error) {
+	var out Experiment
+	err := c.getJSON(ctx, experimentsPrefix+"/experiments/"+pathEscape(experimentID), nil, &out)
+	return out, err
+}
+
+// ListExperiments lists experiments of the project, optionally filtered by
+// exact name.
+func (c *Client) ListExperiments(ctx context.Context, name string, limit int) ([]Experiment, error) {
+	query := limitQuery(limit)
+	if name = strings.TrimSpace(name); name != "" {
+		query.Set("name", name)
+	}
+	var out struct {
+		Experiments []Experiment `json:"experiments"`
+	}
+	err := c.getJSON(ctx, experimentsPrefix+"/experiments", query, &out)
+	return out.Experiments, err
+}
+
+func (c *Client) CreateExperimentRun(ctx context.Context, experimentID string, in CreateExperimentRunRequest) (ExperimentRun, error) {
+	var out ExperimentRun
+	err := c.postJSON(ctx, experimentsPrefix+"/experiments/"+pathEscape(experimentID)+"/runs", in, &out)
+	return out, err
+}
+
+func (c *Client) GetExperimentRun(ctx context.Context, runID string) (ExperimentRun, error) {
+	var out ExperimentRun
+	err := c.getJSON(ctx, experimentsPrefix+"/experiment-runs/"+pathEscape(runID), nil, &out)
+	return out, err
+}
+
+func (c *Client) ListExperimentRuns(ctx context.Context, experimentID string, limit int) ([]ExperimentRun, error) {
+	var out struct {
+		Runs []ExperimentRun `json:"runs"`
+	}
+	err := c.getJSON(ctx, experimentsPrefix+"/experiments/"+pathEscape(experimentID)+"/runs", limitQuery(limit), &out)
+	return out.Runs, err
+}
+
+// IngestMetrics is not retried; the server deduplicates a step's samples, so
+// callers may retry it themselves.
+func (c *Client) IngestMetrics(ctx context.Context, runID string, in IngestMetricsRequest) (IngestMetricsResponse, error) {
+	var out IngestMetricsResponse
+	err := c.postJSON(ctx, experimentsPrefix+"/experiment-runs/"+pathEscape(runID)+"/metrics", in, &out)
+	return out, err
+}
+
+// ListMetrics returns metric samples of a run, optionally of one metric.
+func (c *Client) ListMetrics(ctx context.Context, runID, name string, limit int) ([]MetricSample, error) {
+	query := limitQuery(limit)
+	if name = strings.TrimSpace(name); name != "" {
+		query.Set("name", name)
+	}
+	var out struct {
+		Samples []MetricSample `json:"samples"`
+	}
+	err := c.getJSON(ctx, experimentsPrefix+"/experiment-runs/"+pathEscape(runID)+"/metrics", query, &out)
+	return out.Samples, err
+}
+
+func (c *Client) CreateArtifact(ctx context.Context, runID string, in CreateArtifactRequest) (Artifact, error) {
+	fields := map[string]string{"kind": strings.TrimSpace(in.Kind)}
+	if name := strings.TrimSpace(in.Name); name != "" {
+		fields["name"] = name
+	}
+	if in.Metadata != nil {
+		metadata, err := json.Marshal(in.Metadata)
+		if err != nil {
+			return Artifact{}, fmt.Errorf("client: encode metadata: %w", err)
+		}
+		fields["metadata"] = string(metadata)
+	}
+	body, contentType, err := multipartBody(fields, in.Filename, in.ContentType, in.Content)
+	if err != nil {
+		return Artifact{}, err
+	}
+	var out struct {
+		Artifact Artifact `json:"artifact"`
+	}
+	err = c.doJSON(ctx, request{
+		method:      http.MethodPost,
+		path:        experimentsPrefix + "/experiment-runs/" + pathEscape(runID) + "/artifacts",
+		body:        body,
+		contentType: contentType,
+	}, &out)
+	return out.Artifact, err
+}
+
+func (c *Client) GetArtifact(ctx context.Context, runID, artifactID string) (Artifact, error) {
+	var out Artifact
+	err := c.getJSON(ctx, experimentsPrefix+"/experiment-runs/"+pathEscape(runID)+"/artifacts/"+pathEscape(artifactID), nil, &out)
+	return out, err
+}
+
+// ListArtifacts lists artifacts of a run, optionally of one kind.
+func (c *Client) ListArtifacts(ctx context.Context, runID, kind string, limit int) ([]Artifact, error) {
+	query := limitQuery(limit)
+	if kind = strings.TrimSpace(kind); kind != "" {
+		query.Set("kind", kind)
+	}
+	var out struct {
+		Artifacts []Artifact `json:"artifacts"`
+	}
+	err := c.getJSON(ctx, experimentsPrefix+"/experiment-runs/"+pathEscape(runID)+"/artifacts", query, &out)
+	return out.Artifacts, err
+}
+
+// DownloadArtifact streams the artifact content. The caller closes the
+// reader; the download is not retried.
+func (c *Client) DownloadArtifact(ctx context.Context, runID, artifactID string) (io.ReadCloser, error) {
+	resp, err := c.stream(ctx, request{
+		method: http.MethodGet,
+		path:   experimentsPrefix + "/experiment-runs/" + pathEscape(runID) + "/artifacts/" + pathEscape(artifactID) + "/download",
+		header: http.Header{"Accept": []string{"*/*"}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
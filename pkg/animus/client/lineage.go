@@ -0,0 +1,46 @@
+package client
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+type LineageNode struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+type LineageEdge struct {
+	EventID     int64          `json:"event_id"`
+	OccurredAt  time.Time      `json:"occurred_at"`
+	Actor       string         `json:"actor"`
+	RequestID   string         `json:"request_id,omitempty"`
+	SubjectType string         `json:"subject_type"`
+	SubjectID   string         `json:"subject_id"`
+	Predicate   string         `json:"predicate"`
+	ObjectType  string         `json:"object_type"`
+	ObjectID    string         `json:"object_id"`
+	Metadata    map[string]any `json:"metadata,omitempty"`
+}
+
+type LineageSubgraph struct {
+	Root  LineageNode   `json:"root"`
+	Nodes []LineageNode `json:"nodes"`
+	Edges []LineageEdge `json:"edges"`
+}
+
+// RunLineage returns the lineage subgraph around an experiment run; zero depth
+// or maxEdges use the server defaults.
+func (c *Client) RunLineage(ctx context.Context, runID string, depth, maxEdges int) (LineageSubgraph, error) {
+	query := limitQuery(0)
+	if depth > 0 {
+		query.Set("depth", strconv.Itoa(depth))
+	}
+	if maxEdges > 0 {
+		query.Set("max_edges", strconv.Itoa(maxEdges))
+	}
+	var out LineageSubgraph
+	err := c.getJSON(ctx, lineagePrefix+"/subgraphs/experiment-runs/"+pathEscape(runID), query, &out)
+	return out, err
+}
@@ -0,0 +1,79 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+type PolicyVersionSummary struct {
+	PolicyVersionID string    `json:"policy_version_id"`
+	Version         int       `json:"version"`
+	Status          string    `json:"status"`
+	SpecSHA256      string    `json:"spec_sha256"`
+	CreatedAt       time.Time `json:"created_at"`
+	CreatedBy       string    `json:"created_by"`
+}
+
+type Policy struct {
+	PolicyID      string                `json:"policy_id"`
+	Name          string                `json:"name"`
+	Description   string                `json:"description,omitempty"`
+	CreatedAt     time.Time             `json:"created_at"`
+	CreatedBy     string                `json:"created_by"`
+	LatestVersion *PolicyVersionSummary `json:"latest_version,omitempty"`
+}
+
+type PolicyVersion struct {
+	PolicyVersionID string          `json:"policy_version_id"`
+	PolicyID        string          `json:"policy_id"`
+	Version         int             `json:"version"`
+	Status          string          `json:"status"`
+	SpecYAML        string          `json:"spec_yaml"`
+	Spec            json.RawMessage `json:"spec"`
+	SpecSHA256      string          `json:"spec_sha256"`
+	CreatedAt       time.Time       `json:"created_at"`
+	CreatedBy       string          `json:"created_by"`
+}
+
+// CreatePolicyRequest creates a policy with its first version. Spec is the
+// animus.policy.v1 YAML document; Status defaults to active.
+type CreatePolicyRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Spec        string `json:"spec"`
+	Status      string `json:"status,omitempty"`
+}
+
+type CreatePolicyVersionRequest struct {
+	Spec   string `json:"spec"`
+	Status string `json:"status,omitempty"`
+}
+
+func (c *Client) CreatePolicy(ctx context.Context, in CreatePolicyRequest) (PolicyVersion, error) {
+	var out PolicyVersion
+	err := c.postJSON(ctx, experimentsPrefix+"/policies", in, &out)
+	return out, err
+}
+
+func (c *Client) ListPolicies(ctx context.Context, limit int) ([]Policy, error) {
+	var out struct {
+		Policies []Policy `json:"policies"`
+	}
+	err := c.getJSON(ctx, experimentsPrefix+"/policies", limitQuery(limit), &out)
+	return out.Policies, err
+}
+
+func (c *Client) CreatePolicyVersion(ctx context.Context, policyID string, in CreatePolicyVersionRequest) (PolicyVersion, error) {
+	var out PolicyVersion
+	err := c.postJSON(ctx, experimentsPrefix+"/policies/"+pathEscape(policyID)+"/versions", in, &out)
+	return out, err
+}
+
+func (c *Client) ListPolicyVersions(ctx context.Context, policyID string, limit int) ([]PolicyVersion, error) {
+	var out struct {
+		Versions []PolicyVersion `json:"versions"`
+	}
+	err := c.getJSON(ctx, experimentsPrefix+"/policies/"+pathEscape(policyID)+"/versions", limitQuery(limit), &out)
+	return out.Versions, err
+}
@@ -0,0 +1,104 @@
+package client
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// QualityRuleSpec is an animus.quality.rule.v1 document. Checks are kept as
+// maps because each check type has its own fields.
+type QualityRuleSpec struct {
+	Schema string           `json:"schema"`
+	Checks []map[string]any `json:"checks"`
+}
+
+type QualityRule struct {
+	RuleID      string          `json:"rule_id"`
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Spec        QualityRuleSpec `json:"spec"`
+	CreatedAt   time.Time       `json:"created_at"`
+	CreatedBy   string          `json:"created_by"`
+}
+
+type CreateQualityRuleRequest struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Spec        QualityRuleSpec `json:"spec"`
+}
+
+type EvaluationSummary struct {
+	ChecksTotal     int      `json:"checks_total"`
+	ChecksPass      int      `json:"checks_pass"`
+	ChecksFail      int      `json:"checks_fail"`
+	ChecksError     int      `json:"checks_error"`
+	FailingCheckIDs []string `json:"failing_check_ids,omitempty"`
+}
+
+// Evaluation is the result of running a quality rule over a dataset version;
+// Status is pass, fail or error.
+type Evaluation struct {
+	EvaluationID     string            `json:"evaluation_id"`
+	DatasetVersionID string            `json:"dataset_version_id"`
+	RuleID           string            `json:"rule_id"`
+	Status           string            `json:"status"`
+	EvaluatedAt      time.Time         `json:"evaluated_at"`
+	EvaluatedBy      string            `json:"evaluated_by"`
+	Summary          EvaluationSummary `json:"summary"`
+	ReportObjectKey  string            `json:"report_object_key"`
+	ReportSHA256     string            `json:"report_sha256"`
+	ReportSizeBytes  int64             `json:"report_size_bytes"`
+}
+
+// CreateEvaluationRequest evaluates a dataset version; RuleID defaults to the
+// rule the version was uploaded with.
+type CreateEvaluationRequest struct {
+	DatasetVersionID string `json:"dataset_version_id"`
+	RuleID           string `json:"rule_id,omitempty"`
+}
+
+func (c *Client) CreateQualityRule(ctx context.Context, in CreateQualityRuleRequest) (QualityRule, error) {
+	var out QualityRule
+	err := c.postJSON(ctx, qualityPrefix+"/rules", in, &out)
+	return out, err
+}
+
+func (c *Client) GetQualityRule(ctx context.Context, ruleID string) (QualityRule, error) {
+	var out QualityRule
+	err := c.getJSON(ctx, qualityPrefix+"/rules/"+pathEscape(ruleID), nil, &out)
+	return out, err
+}
+
+func (c *Client) ListQualityRules(ctx context.Context, limit int) ([]QualityRule, error) {
+	var out struct {
+		Rules []QualityRule `json:"rules"`
+	}
+	err := c.getJSON(ctx, qualityPrefix+"/rules", limitQuery(limit), &out)
+	return out.Rules, err
+}
+
+func (c *Client) CreateEvaluation(ctx context.Context, in CreateEvaluationRequest) (Evaluation, error) {
+	var out Evaluation
+	err := c.postJSON(ctx, qualityPrefix+"/evaluations", in, &out)
+	return out, err
+}
+
+func (c *Client) GetEvaluation(ctx context.Context, evaluationID string) (Evaluation, error) {
+	var out Evaluation
+	err := c.getJSON(ctx, qualityPrefix+"/evaluations/"+pathEscape(evaluationID), nil, &out)
+	return out, err
+}
+
+// ListEvaluations lists evaluations, optionally of one dataset version.
+func (c *Client) ListEvaluations(ctx context.Context, datasetVersionID string, limit int) ([]Evaluation, error) {
+	query := limitQuery(limit)
+	if id := strings.TrimSpace(datasetVersionID); id != "" {
+		query.Set("dataset_version_id", id)
+	}
+	var out struct {
+		Evaluations []Evaluation `json:"evaluations"`
+	}
+	err := c.getJSON(ctx, qualityPrefix+"/evaluations", query, &out)
+	return out.Evaluations, err
+}
@@ -0,0 +1,165 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// CreateRunRequest creates a project run from a pipeline spec. PipelineSpec
+// is the animus pipeline document as JSON.
+type CreateRunRequest struct {
+	IdempotencyKey  string            `json:"idempotencyKey,omitempty"`
+	PipelineSpec    json.RawMessage   `json:"pipelineSpec"`
+	DatasetBindings map[string]string `json:"datasetBindings"`
+	CodeRef         RunCodeRef        `json:"codeRef"`
+	EnvLock         RunEnvLockRef     `json:"envLock"`
+	Parameters      map[string]any    `json:"parameters,omitempty"`
+}
+
+type RunCodeRef struct {
+	RepoURL   string `json:"repoUrl"`
+	CommitSHA string `json:"commitSha"`
+	Path      string `json:"path,omitempty"`
+	SCMType   string `json:"scmType,omitempty"`
+}
+
+type RunEnvLockRef struct {
+	LockID string `json:"lockId"`
+}
+
+type CreateRunResponse struct {
+	RunID    string `json:"runId"`
+	Status   string `json:"status"`
+	Created  bool   `json:"created"`
+	SpecHash string `json:"specHash"`
+}
+
+// Run is a project run with its state derived from plan and step records.
+type Run struct {
+	RunID          string          `json:"runId"`
+	Status         string          `json:"status"`
+	State          string          `json:"state"`
+	SpecHash       string          `json:"specHash"`
+	CreatedAt      time.Time       `json:"createdAt"`
+	PlanExists     bool            `json:"planExists"`
+	AttemptsByStep map[string]int  `json:"attemptsByStep,omitempty"`
+	RunSpec        json.RawMessage `json:"runSpec,omitempty"`
+}
+
+// Terminal reports whether the run has finished.
+func (r Run) Terminal() bool {
+	switch r.State {
+	case "succeeded", "failed", "canceled", "dryrun_succeeded", "dryrun_failed":
+		return true
+	default:
+		return false
+	}
+}
+
+type DispatchRunRequest struct {
+	IdempotencyKey     string `json:"idempotencyKey,omitempty"`
+	Priority           string `json:"priority,omitempty"`
+	MaxDurationSeconds int64  `json:"maxDurationSeconds,omitempty"`
+}
+
+type DispatchRunResponse struct {
+	RunID              string `json:"runId"`
+	ProjectID          string `json:"projectId"`
+	DispatchID         string `json:"dispatchId,omitempty"`
+	Status             string `json:"status"`
+	Created            bool   `json:"created"`
+	Priority           string `json:"priority,omitempty"`
+	QueuePosition      int    `json:"queuePosition,omitempty"`
+	MaxDurationSeconds int64  `json:"maxDurationSeconds,omitempty"`
+}
+
+type CancelRunResponse struct {
+	RunID       string `json:"runId"`
+	ProjectID   string `json:"projectId"`
+	Status      string `json:"status"`
+	JobCanceled bool   `json:"jobCanceled"`
+}
+
+var errProjectRequired = errors.New("client: project id is required for project runs")
+
+func (c *Client) runsPath() (string, error) {
+	if c.projectID == "" {
+		return "", errProjectRequired
+	}
+	return experimentsPrefix + "/projects/" + pathEscape(c.projectID) + "/runs", nil
+}
+
+// CreateRun creates a run in the client's project.
+func (c *Client) CreateRun(ctx context.Context, in CreateRunRequest) (CreateRunResponse, error) {
+	path, err := c.runsPath()
+	if err != nil {
+		return CreateRunResponse{}, err
+	}
+	var out CreateRunResponse
+	err = c.postJSON(ctx, path, in, &out)
+	return out, err
+}
+
+func (c *Client) GetRun(ctx context.Context, runID string) (Run, error) {
+	path, err := c.runsPath()
+	if err != nil {
+		return Run{}, err
+	}
+	var out Run
+	err = c.getJSON(ctx, path+"/"+pathEscape(runID), nil, &out)
+	return out, err
+}
+
+// DispatchRun submits the run to the data plane, or queues it when
+// concurrency limits are reached.
+func (c *Client) DispatchRun(ctx context.Context, runID string, in DispatchRunRequest) (DispatchRunResponse, error) {
+	path, err := c.runsPath()
+	if err != nil {
+		return DispatchRunResponse{}, err
+	}
+	var out DispatchRunResponse
+	err = c.postJSON(ctx, path+"/"+pathEscape(runID)+":dispatch", in, &out)
+	return out, err
+}
+
+// CancelRun cancels an unfinished run; reason is optional.
+func (c *Client) CancelRun(ctx context.Context, runID, reason string) (CancelRunResponse, error) {
+	path, err := c.runsPath()
+	if err != nil {
+		return CancelRunResponse{}, err
+	}
+	var out CancelRunResponse
+	err = c.postJSON(ctx, path+"/"+pathEscape(runID)+"/cancel", struct {
+		Reason string `json:"reason,omitempty"`
+	}{Reason: reason}, &out)
+	return out, err
+}
+
+// WatchRun polls the run every interval until it is terminal, calling
+// onChange whenever its state changes. It returns the terminal run.
+func (c *Client) WatchRun(ctx context.Context, runID string, interval time.Duration, onChange func(Run)) (Run, error) {
+	if interval <= 0 {
+		interval = 2 * time.Second
+	}
+	last := ""
+	for {
+		run, err := c.GetRun(ctx, runID)
+		if err != nil {
+			return Run{}, err
+		}
+		if run.State != last {
+			last = run.State
+			if onChange != nil {
+				onChange(run)
+			}
+		}
+		if run.Terminal() {
+			return run, nil
+		}
+		if err := c.sleep(ctx, interval); err != nil {
+			return run, err
+		}
+	}
+}
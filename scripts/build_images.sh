@@ -78,6 +78,9 @@ build_service() {
     --platform "${IMAGE_PLATFORM}" \
     --build-arg GO_VERSION="${GO_VERSION}" \
     --build-arg SERVICE="${name}" \
+    --build-arg VERSION="${ANIMUS_VERSION}" \
+    --build-arg VCS_REF="${VCS_REF}" \
+    --build-arg BUILD_DATE="${BUILD_DATE}" \
     --build-arg SOURCE_DATE_EPOCH="${SOURCE_DATE_EPOCH}" \
     --label "org.opencontainers.image.version=${ANIMUS_VERSION}" \
     --label "org.opencontainers.image.revision=${VCS_REF}" \
@@ -64,7 +64,6 @@ mkdir -p "$CACHE_DIR"
 VALUES_FILE="${CACHE_DIR}/system_prod_values.yaml"
 CHART_DIR="${DEPLOY_DIR}/helm/animus-datapilot"
 CHART_WORK_DIR="${CACHE_DIR}/animus-datapilot-chart"
-MIGRATIONS_SRC="${ROOT_DIR}/closed/migrations"
 
 if [[ -z "$IMAGE_TAG" ]]; then
   if command -v git >/dev/null 2>&1 && git -C "$ROOT_DIR" rev-parse --short HEAD >/dev/null 2>&1; then
@@ -83,19 +82,11 @@ if [[ "$BUILD_IMAGES" == "1" ]]; then
   fi
 fi
 
-sync_chart_migrations() {
+# Migrations are embedded in the service images and applied by the chart's
+# migrate job, so the chart is used as is.
+prepare_chart() {
   rm -rf "$CHART_WORK_DIR"
   cp -R "$CHART_DIR" "$CHART_WORK_DIR"
-  mkdir -p "$CHART_WORK_DIR/migrations"
-  rm -f "$CHART_WORK_DIR/migrations/"*.up.sql
-  shopt -s nullglob
-  local files=("${MIGRATIONS_SRC}"/*_*.up.sql)
-  shopt -u nullglob
-  if [[ "${#files[@]}" -eq 0 ]]; then
-    echo "system-prod-up: no migrations found in ${MIGRATIONS_SRC}" >&2
-    exit 1
-  fi
-  cp "${files[@]}" "$CHART_WORK_DIR/migrations/"
 }
 
 stop_pf() {
@@ -195,7 +186,7 @@ if [[ "${ANIMUS_SYSTEM_LOAD_IMAGES:-1}" == "1" ]]; then
   done
 fi
 
-sync_chart_migrations
+prepare_chart
 
 helm upgrade --install "$DATAPILOT_RELEASE" "$CHART_WORK_DIR" \
   --namespace "$NAMESPACE" \